@@ -0,0 +1,177 @@
+// Package main is a device simulator that load tests the telemetry ingest pipeline end to end.
+// It streams RaceBox-style telemetry from many simulated devices to a running service.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// options holds the simulator's command line configuration
+type options struct {
+	baseURL     string
+	token       string
+	deviceKeys  string
+	devices     int
+	prefix      string
+	rate        float64
+	batchSize   int
+	gzip        bool
+	jitter      time.Duration
+	duration    time.Duration
+	timeout     time.Duration
+	latitude    float64
+	longitude   float64
+	seed        uint64
+	reportEvery time.Duration
+}
+
+// simDevice is a simulated device and the credentials it uploads with
+type simDevice struct {
+	deviceID  string
+	deviceKey string // Optional: empty uploads with the shared JWT
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.baseURL, "url", "http://localhost:8080", "base URL of the service")
+	flag.StringVar(&opts.token, "token", "", "user JWT to upload with (or set DEVICESIM_TOKEN)")
+	flag.StringVar(&opts.deviceKeys, "device-keys", "", "file of `deviceId key` lines; each line simulates one device uploading with its key")
+	flag.IntVar(&opts.devices, "devices", 10, "number of devices to simulate with -token")
+	flag.StringVar(&opts.prefix, "prefix", "SIM", "device ID prefix for devices simulated with -token")
+	flag.Float64Var(&opts.rate, "rate", 25, "records per second per device")
+	flag.IntVar(&opts.batchSize, "batch", 25, "records per upload; 1 uses the single record endpoint")
+	flag.BoolVar(&opts.gzip, "gzip", false, "gzip request bodies")
+	flag.DurationVar(&opts.jitter, "jitter", 100*time.Millisecond, "maximum random delay added to each upload")
+	flag.DurationVar(&opts.duration, "duration", time.Minute, "how long to run; 0 runs until interrupted")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "timeout of each upload request")
+	flag.Float64Var(&opts.latitude, "lat", 50.437, "latitude around which circuits are placed")
+	flag.Float64Var(&opts.longitude, "lon", 5.971, "longitude around which circuits are placed")
+	flag.Uint64Var(&opts.seed, "seed", uint64(time.Now().UnixNano()), "random seed for reproducible streams")
+	flag.DurationVar(&opts.reportEvery, "report", 5*time.Second, "interval between progress reports")
+	flag.Parse()
+
+	if opts.token == "" {
+		opts.token = os.Getenv("DEVICESIM_TOKEN")
+	}
+
+	devices, err := loadDevices(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "devicesim: %v\n", err)
+		os.Exit(2)
+	}
+	if err := validate(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "devicesim: %v\n", err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if opts.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.duration)
+		defer cancel()
+	}
+
+	slog.Info("Starting device simulation",
+		"url", opts.baseURL,
+		"devices", len(devices),
+		"rate", opts.rate,
+		"batch", opts.batchSize,
+		"duration", opts.duration)
+
+	stats := newStats()
+	client := newClient(opts.baseURL, opts.token, opts.timeout, opts.gzip)
+
+	var wg sync.WaitGroup
+	for i, device := range devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sim := newSimulator(client, stats, device, newTrack(device.deviceID, i, opts.latitude, opts.longitude, opts.seed), opts)
+			sim.run(ctx)
+		}()
+	}
+
+	go stats.reportEvery(ctx, opts.reportEvery)
+
+	wg.Wait()
+	stats.summary(os.Stdout)
+}
+
+// validate checks the options that do not depend on the device list
+func validate(opts options) error {
+	switch {
+	case opts.rate <= 0:
+		return errors.New("-rate must be positive")
+	case opts.batchSize < 1 || opts.batchSize > 1000:
+		return errors.New("-batch must be between 1 and 1000")
+	case opts.jitter < 0:
+		return errors.New("-jitter must not be negative")
+	case opts.timeout <= 0:
+		return errors.New("-timeout must be positive")
+	case opts.reportEvery <= 0:
+		return errors.New("-report must be positive")
+	}
+	return nil
+}
+
+// loadDevices returns the devices to simulate, read from -device-keys or generated for -token
+func loadDevices(opts options) ([]simDevice, error) {
+	if opts.deviceKeys == "" {
+		if opts.token == "" {
+			return nil, errors.New("either -token or -device-keys is required")
+		}
+		if opts.devices < 1 {
+			return nil, errors.New("-devices must be positive")
+		}
+		devices := make([]simDevice, opts.devices)
+		for i := range devices {
+			devices[i] = simDevice{deviceID: fmt.Sprintf("%s-%04d", opts.prefix, i+1)}
+		}
+		return devices, nil
+	}
+
+	file, err := os.Open(opts.deviceKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseDeviceKeys(file, opts.deviceKeys)
+}
+
+// parseDeviceKeys reads `deviceId key` lines, skipping blank lines and # comments
+func parseDeviceKeys(r io.Reader, name string) ([]simDevice, error) {
+	var devices []simDevice
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected `deviceId key`", name, line)
+		}
+		devices = append(devices, simDevice{deviceID: fields[0], deviceKey: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("%s: no devices", name)
+	}
+	return devices, nil
+}
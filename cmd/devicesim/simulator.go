@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+const (
+	// defaultRetryAfter is how long a throttled device waits when the response names no delay
+	defaultRetryAfter = time.Second
+
+	// maxPendingBatches bounds a throttled device's buffer; older records are dropped beyond it
+	maxPendingBatches = 100
+)
+
+// client uploads telemetry to the service
+type client struct {
+	baseURL string
+	token   string
+	gzip    bool
+	http    *http.Client
+}
+
+// newClient creates a client for the service at baseURL
+func newClient(baseURL, token string, timeout time.Duration, compress bool) *client {
+	return &client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		gzip:    compress,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// upload sends records for a device, using the single record endpoint for one record
+// It returns the response status and, for throttled uploads, how long to wait before retrying.
+func (c *client) upload(ctx context.Context, device simDevice, records []models.TelemetryData) (int, time.Duration, error) {
+	var payload any = records
+	path := "/api/v1/telemetry/batch"
+	if len(records) == 1 {
+		payload, path = records[0], "/api/v1/telemetry"
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, 0, err
+	}
+	if c.gzip {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write(body); err != nil {
+			return 0, 0, err
+		}
+		if err := writer.Close(); err != nil {
+			return 0, 0, err
+		}
+		body = compressed.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if device.deviceKey != "" {
+		req.Header.Set(middleware.DeviceKeyHeader, device.deviceKey)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	var retryAfter time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		retryAfter = defaultRetryAfter
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+	}
+	return resp.StatusCode, retryAfter, nil
+}
+
+// simulator records one device's telemetry at the configured rate and uploads it in batches
+// Records are buffered while an upload is in flight or throttled, as a device would.
+type simulator struct {
+	client    *client
+	stats     *stats
+	device    simDevice
+	track     *track
+	interval  time.Duration
+	batchSize int
+	jitter    time.Duration
+	rng       *rand.Rand
+}
+
+// newSimulator creates a simulator for the device
+func newSimulator(client *client, stats *stats, device simDevice, track *track, opts options) *simulator {
+	return &simulator{
+		client:    client,
+		stats:     stats,
+		device:    device,
+		track:     track,
+		interval:  time.Duration(float64(time.Second) / opts.rate),
+		batchSize: opts.batchSize,
+		jitter:    opts.jitter,
+		rng:       rand.New(rand.NewPCG(track.rng.Uint64(), track.rng.Uint64())),
+	}
+}
+
+// run records and uploads telemetry until ctx is done
+func (s *simulator) run(ctx context.Context) {
+	// Stagger device start-up so uploads do not arrive in lockstep
+	if !s.sleep(ctx, time.Duration(s.rng.Int64N(int64(s.interval)*int64(s.batchSize)))) {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval * time.Duration(s.batchSize))
+	defer ticker.Stop()
+
+	recorded := time.Now().Add(s.interval)
+	var pending []models.TelemetryData
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for ; !recorded.After(now); recorded = recorded.Add(s.interval) {
+				pending = append(pending, s.track.next(recorded.UTC(), s.interval))
+			}
+			if over := len(pending) - maxPendingBatches*s.batchSize; over > 0 {
+				s.stats.recordDropped(over)
+				pending = pending[over:]
+			}
+		}
+
+		if s.jitter > 0 && !s.sleep(ctx, time.Duration(s.rng.Int64N(int64(s.jitter)))) {
+			return
+		}
+
+		for len(pending) > 0 {
+			n := min(len(pending), s.batchSize)
+			retryAfter, ok := s.send(ctx, pending[:n])
+			if retryAfter > 0 {
+				// Keep the batch and try again once the service asks for it
+				if !s.sleep(ctx, retryAfter) {
+					return
+				}
+				break
+			}
+			if !ok && ctx.Err() != nil {
+				return
+			}
+			pending = pending[n:]
+		}
+	}
+}
+
+// send uploads one batch and records the outcome
+// It returns the delay the service asked for when throttled, and whether the batch was accepted.
+func (s *simulator) send(ctx context.Context, records []models.TelemetryData) (time.Duration, bool) {
+	start := time.Now()
+	status, retryAfter, err := s.client.upload(ctx, s.device, records)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.stats.recordError(fmt.Errorf("%s: %w", s.device.deviceID, err))
+		}
+		return 0, false
+	}
+	s.stats.recordResponse(status, len(records), time.Since(start))
+	return retryAfter, status < http.StatusMultipleChoices
+}
+
+// sleep waits for d, returning false if ctx is done first
+func (s *simulator) sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxErrorSamples bounds the transport errors kept for the summary
+const maxErrorSamples = 5
+
+// stats collects upload outcomes across all simulated devices
+type stats struct {
+	mu        sync.Mutex
+	started   time.Time
+	requests  int
+	records   int
+	accepted  int
+	dropped   int
+	errors    int
+	statuses  map[int]int
+	latencies []time.Duration
+	samples   []string
+}
+
+// newStats creates an empty collector starting now
+func newStats() *stats {
+	return &stats{
+		started:  time.Now(),
+		statuses: make(map[int]int),
+	}
+}
+
+// recordResponse counts an upload that received a response
+func (s *stats) recordResponse(status, records int, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	s.records += records
+	s.statuses[status]++
+	s.latencies = append(s.latencies, latency)
+	if status < 300 {
+		s.accepted += records
+	}
+}
+
+// recordError counts an upload that failed without a response
+func (s *stats) recordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	s.errors++
+	if len(s.samples) < maxErrorSamples {
+		s.samples = append(s.samples, err.Error())
+	}
+}
+
+// recordDropped counts records a throttled device discarded from its buffer
+func (s *stats) recordDropped(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dropped += n
+}
+
+// reportEvery logs progress at the given interval until ctx is done
+func (s *stats) reportEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			elapsed := time.Since(s.started).Seconds()
+			slog.Info("Simulation progress",
+				"requests", s.requests,
+				"accepted_records", s.accepted,
+				"records_per_second", int(float64(s.accepted)/elapsed),
+				"errors", s.errors,
+				"p95", percentile(s.latencies, 0.95))
+			s.mu.Unlock()
+		}
+	}
+}
+
+// summary writes the totals, status counts and latency percentiles
+func (s *stats) summary(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elapsed := time.Since(s.started)
+	fmt.Fprintf(w, "Duration:      %s\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "Requests:      %d (%.1f/s)\n", s.requests, float64(s.requests)/elapsed.Seconds())
+	fmt.Fprintf(w, "Records sent:  %d\n", s.records)
+	fmt.Fprintf(w, "Accepted:      %d (%.1f/s)\n", s.accepted, float64(s.accepted)/elapsed.Seconds())
+	fmt.Fprintf(w, "Dropped:       %d\n", s.dropped)
+	fmt.Fprintf(w, "Errors:        %d\n", s.errors)
+
+	for _, status := range slices.Sorted(maps.Keys(s.statuses)) {
+		fmt.Fprintf(w, "HTTP %d:      %d\n", status, s.statuses[status])
+	}
+
+	if len(s.latencies) > 0 {
+		fmt.Fprintf(w, "Latency:       p50 %s, p95 %s, p99 %s, max %s\n",
+			percentile(s.latencies, 0.50),
+			percentile(s.latencies, 0.95),
+			percentile(s.latencies, 0.99),
+			percentile(s.latencies, 1))
+	}
+	for _, sample := range s.samples {
+		fmt.Fprintf(w, "Error:         %s\n", sample)
+	}
+}
+
+// percentile returns the latency at quantile q (0-1), or zero without samples
+func percentile(latencies []time.Duration, q float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+	index := int(q * float64(len(sorted)-1))
+	return sorted[index].Round(time.Microsecond)
+}
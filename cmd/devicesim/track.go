@@ -0,0 +1,112 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// Circuit shape every simulated device laps, an ellipse with a long straight feel
+const (
+	circuitRadiusX   = 600.0 // meters
+	circuitRadiusY   = 250.0 // meters
+	metersPerDegree  = 111320.0
+	minCircuitSpeed  = 60.0  // km/h in the tightest corners
+	maxCircuitSpeed  = 210.0 // km/h at the end of the straights
+	gravity          = 9.80665
+	batteryDrainRate = 0.0005 // percent per record
+	gpsLeapSeconds   = 18
+)
+
+// gpsEpoch is the start of GPS time, from which iTOW is derived
+var gpsEpoch = time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC)
+
+// track generates RaceBox-style records for one device lapping an elliptical circuit
+// Each device starts at its own point of the lap and circuit center so streams differ.
+type track struct {
+	deviceID  string
+	centerLat float64
+	centerLon float64
+	angle     float64
+	battery   float64
+	rng       *rand.Rand
+}
+
+// newTrack creates a track for the device, offset from the base position by its index
+func newTrack(deviceID string, index int, baseLat, baseLon float64, seed uint64) *track {
+	rng := rand.New(rand.NewPCG(seed, uint64(index)))
+	return &track{
+		deviceID:  deviceID,
+		centerLat: baseLat + float64(index%100)*0.01,
+		centerLon: baseLon + float64(index/100)*0.01,
+		angle:     rng.Float64() * 2 * math.Pi,
+		battery:   60 + rng.Float64()*40,
+		rng:       rng,
+	}
+}
+
+// next advances the device by interval and returns the record it reports at the given time
+func (t *track) next(at time.Time, interval time.Duration) models.TelemetryData {
+	// Fast on the flat sides of the ellipse, slow around its tight ends
+	corner := math.Abs(math.Cos(t.angle))
+	speed := maxCircuitSpeed - (maxCircuitSpeed-minCircuitSpeed)*corner*corner
+	speed = math.Max(0, speed+t.rng.NormFloat64()*1.5)
+
+	// Advance along the ellipse by the distance covered at this speed
+	dx, dy := -circuitRadiusX*math.Sin(t.angle), circuitRadiusY*math.Cos(t.angle)
+	tangent := math.Hypot(dx, dy)
+	meters := speed / 3.6 * interval.Seconds()
+	t.angle = math.Mod(t.angle+meters/tangent, 2*math.Pi)
+
+	east, north := circuitRadiusX*math.Cos(t.angle), circuitRadiusY*math.Sin(t.angle)
+	lat := t.centerLat + north/metersPerDegree
+	lon := t.centerLon + east/(metersPerDegree*math.Cos(t.centerLat*math.Pi/180))
+
+	// Heading is clockwise from north, lateral load follows the curvature of the ellipse
+	heading := math.Mod(math.Atan2(dx, dy)*180/math.Pi+360, 360)
+	curvature := circuitRadiusX * circuitRadiusY / math.Pow(tangent, 3)
+	lateral := (speed / 3.6) * (speed / 3.6) * curvature / gravity
+
+	t.battery = math.Max(5, t.battery-batteryDrainRate)
+
+	return models.TelemetryData{
+		Timestamp: at,
+		DeviceID:  t.deviceID,
+		ITOW:      timeOfWeek(at),
+		GPS: models.GpsData{
+			Latitude:           lat,
+			Longitude:          lon,
+			WgsAltitude:        625 + t.rng.NormFloat64()*0.5,
+			MslAltitude:        590 + t.rng.NormFloat64()*0.5,
+			Speed:              speed,
+			Heading:            heading,
+			NumSatellites:      9 + t.rng.IntN(6),
+			FixStatus:          3,
+			HorizontalAccuracy: 0.8 + t.rng.Float64()*0.4,
+			VerticalAccuracy:   1.5 + t.rng.Float64()*0.6,
+			SpeedAccuracy:      0.5 + t.rng.Float64()*0.4,
+			HeadingAccuracy:    0.5 + t.rng.Float64()*2,
+			PDOP:               1.2 + t.rng.Float64(),
+			IsFixValid:         true,
+		},
+		Motion: models.MotionData{
+			GForceX:   t.rng.NormFloat64() * 0.05,
+			GForceY:   math.Min(lateral, 3) + t.rng.NormFloat64()*0.03,
+			GForceZ:   1 + t.rng.NormFloat64()*0.02,
+			RotationX: t.rng.NormFloat64() * 2,
+			RotationY: t.rng.NormFloat64() * 2,
+			RotationZ: speed / 3.6 * curvature * 180 / math.Pi,
+		},
+		Battery:       t.battery,
+		TimeAccuracy:  25,
+		ValidityFlags: 7,
+	}
+}
+
+// timeOfWeek returns the GPS time of week in milliseconds for a UTC time
+func timeOfWeek(at time.Time) int64 {
+	week := 7 * 24 * time.Hour
+	return int64((at.Sub(gpsEpoch) + gpsLeapSeconds*time.Second) % week / time.Millisecond)
+}
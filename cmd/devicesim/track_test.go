@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrack_RecordsPassValidation(t *testing.T) {
+	track := newTrack("SIM-0001", 0, 50.437, 5.971, 42)
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	interval := 40 * time.Millisecond
+
+	var minSpeed, maxSpeed float64 = 1000, 0
+	for i := range 10000 {
+		record := track.next(start.Add(time.Duration(i)*interval), interval)
+		require.NoError(t, record.Validate(), "record %d", i)
+		assert.Equal(t, "SIM-0001", record.DeviceID)
+		minSpeed = min(minSpeed, record.GPS.Speed)
+		maxSpeed = max(maxSpeed, record.GPS.Speed)
+	}
+
+	// 400 seconds covers several laps, through corners and down straights
+	assert.Less(t, minSpeed, 80.0)
+	assert.Greater(t, maxSpeed, 180.0)
+}
+
+func TestTimeOfWeek(t *testing.T) {
+	// Sunday midnight UTC is 18 seconds into the GPS week
+	assert.Equal(t, int64(18000), timeOfWeek(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, int64(18000+3_600_500), timeOfWeek(time.Date(2025, 6, 1, 1, 0, 0, 500_000_000, time.UTC)))
+}
+
+func TestParseDeviceKeys(t *testing.T) {
+	devices, err := parseDeviceKeys(strings.NewReader("# fleet\nRACEBOX-001 avt_dk_one\n\nRACEBOX-002  avt_dk_two\n"), "keys.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []simDevice{
+		{deviceID: "RACEBOX-001", deviceKey: "avt_dk_one"},
+		{deviceID: "RACEBOX-002", deviceKey: "avt_dk_two"},
+	}, devices)
+
+	_, err = parseDeviceKeys(strings.NewReader("RACEBOX-001\n"), "keys.txt")
+	assert.EqualError(t, err, "keys.txt:1: expected `deviceId key`")
+
+	_, err = parseDeviceKeys(strings.NewReader("# empty\n"), "keys.txt")
+	assert.Error(t, err)
+}
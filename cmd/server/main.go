@@ -2,67 +2,498 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sebasr/avt-service/internal/adoption"
+	"github.com/sebasr/avt-service/internal/aggregation"
+	"github.com/sebasr/avt-service/internal/alerts"
+	"github.com/sebasr/avt-service/internal/archive"
+	"github.com/sebasr/avt-service/internal/audit"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/cache"
 	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/corrections"
 	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/database/migrations"
+	"github.com/sebasr/avt-service/internal/database/policies"
+	"github.com/sebasr/avt-service/internal/devicehealth"
 	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/events"
+	"github.com/sebasr/avt-service/internal/geofence"
+	"github.com/sebasr/avt-service/internal/geoip"
+	"github.com/sebasr/avt-service/internal/handlers"
+	"github.com/sebasr/avt-service/internal/importer"
+	"github.com/sebasr/avt-service/internal/ingest"
+	"github.com/sebasr/avt-service/internal/logging"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/mqtt"
+	"github.com/sebasr/avt-service/internal/notify"
+	"github.com/sebasr/avt-service/internal/oidc"
+	"github.com/sebasr/avt-service/internal/presence"
+	"github.com/sebasr/avt-service/internal/push"
+	"github.com/sebasr/avt-service/internal/ratelimit"
+	"github.com/sebasr/avt-service/internal/reports"
 	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/sebasr/avt-service/internal/secrets"
 	"github.com/sebasr/avt-service/internal/server"
+	"github.com/sebasr/avt-service/internal/smoothing"
+	"github.com/sebasr/avt-service/internal/stream"
+	"github.com/sebasr/avt-service/internal/tracing"
+	"github.com/sebasr/avt-service/internal/upload"
+	"github.com/sebasr/avt-service/internal/webhook"
 )
 
+// shutdownTimeout bounds graceful shutdown, including the final ingest buffer flush
+const shutdownTimeout = 30 * time.Second
+
 func main() {
+	migrateUp := flag.Bool("migrate", false, "apply pending database migrations and exit")
+	migrateDown := flag.Int("migrate-down", 0, "revert the given number of database migrations and exit")
+	validateConfig := flag.Bool("validate-config", false, "strictly validate configuration, report every problem and exit")
+	flag.Parse()
+
+	if *validateConfig {
+		if err := config.Check(); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Configuration is valid")
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal("Failed to load configuration", err)
+	}
+
+	// Install the configured logger before anything else logs
+	if err := logging.Setup(cfg.Logging); err != nil {
+		fatal("Failed to set up logging", err)
+	}
+
+	// Read secrets kept in a secret manager before anything uses them
+	secretProvider, err := secrets.NewProvider(context.Background(), cfg.Secrets)
+	if err != nil {
+		fatal("Failed to configure the secret manager", err)
+	}
+	if err := secrets.Resolve(context.Background(), secretProvider, cfg); err != nil {
+		fatal("Failed to read secrets", err)
+	}
+
+	// Initialize tracing before the database so queries are traced from the start
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	if cfg.Tracing.Enabled {
+		slog.Info("Tracing enabled", "endpoint", cfg.Tracing.Endpoint)
+	}
+
+	// The SQLite backend serves a reduced feature set from a single file
+	if cfg.Database.Driver == "sqlite" {
+		if *migrateDown > 0 {
+			fatal("Failed to revert migrations", errors.New("the SQLite schema cannot be reverted"))
+		}
+		runSQLite(cfg, *migrateUp, shutdownTracing)
+		return
 	}
 
 	// Initialize database connection
 	db, err := database.New(&cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", err)
 	}
 	defer func() {
 		if err := db.Close(); err != nil {
-			log.Printf("Error closing database: %v", err)
+			slog.Error("Error closing database", "error", err)
 		}
 	}()
 
-	log.Println("Successfully connected to database")
+	slog.Info("Successfully connected to database")
+	if cfg.Database.ReplicaURL != "" {
+		slog.Info("Read replica configured", "healthy", db.ReplicaHealthy())
+	}
+
+	// Apply the schema migrations embedded in the binary
+	migrator, err := migrations.NewMigrator(db.DB)
+	if err != nil {
+		fatal("Failed to load migrations", err)
+	}
+	switch {
+	case *migrateDown > 0:
+		reverted, err := migrator.Down(context.Background(), *migrateDown)
+		if err != nil {
+			fatal("Failed to revert migrations", err)
+		}
+		slog.Info("Reverted migrations", "count", reverted)
+		return
+	case *migrateUp || cfg.Database.AutoMigrate:
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			fatal("Failed to apply migrations", err)
+		}
+		slog.Info("Database schema is up to date", "version", migrator.Latest(), "applied", applied)
+		if *migrateUp {
+			return
+		}
+	default:
+		version, dirty, err := migrator.Version(context.Background())
+		if err != nil {
+			fatal("Failed to read schema version", err)
+		}
+		if dirty || version < migrator.Latest() {
+			slog.Warn("Database schema is behind the binary; run with --migrate or set DB_AUTO_MIGRATE=true",
+				"version", version, "dirty", dirty, "expected", migrator.Latest())
+		}
+	}
+
+	// Apply telemetry compression and retention policies from configuration
+	policyManager := policies.NewManager(db.DB, cfg.Storage)
+	if err := policyManager.Apply(context.Background()); err != nil {
+		fatal("Failed to apply storage policies", err)
+	}
+
+	// Connect to Redis if rate limiting, caching or the access token denylist uses it
+	var redisClient *redis.Client
+	if (cfg.RateLimit.Enabled && cfg.RateLimit.Store == "redis") || (cfg.Cache.Enabled && cfg.Cache.Store == "redis") || cfg.Auth.DenylistStore == "redis" || cfg.Ingest.SignatureNonceStore == "redis" {
+		redisOpts, err := redis.ParseURL(cfg.Redis.URL)
+		if err != nil {
+			fatal("Invalid REDIS_URL", err)
+		}
+		redisClient = redis.NewClient(redisOpts)
+		defer func() {
+			if err := redisClient.Close(); err != nil {
+				slog.Error("Error closing Redis client", "error", err)
+			}
+		}()
+
+		if err := redisClient.Ping(context.Background()).Err(); err != nil {
+			fatal("Failed to connect to Redis", err)
+		}
+	}
+
+	// Initialize Redis-backed rate limiting if configured (defaults to in-memory)
+	// Rate limits can be changed while serving by a configuration reload
+	rateLimits := server.NewRateLimits(cfg.RateLimit)
+	var rateLimitStore ratelimit.Store
+	if cfg.RateLimit.Enabled && cfg.RateLimit.Store == "redis" {
+		rateLimitStore = ratelimit.NewRedisStore(redisClient)
+		slog.Info("Rate limiting initialized with Redis store")
+	}
+
+	// Share revoked access tokens between instances if configured (defaults to in-memory)
+	var denylistStore cache.Cache
+	if cfg.Auth.DenylistStore == "redis" {
+		denylistStore = cache.NewRedisCache(redisClient)
+		slog.Info("Access token denylist initialized with Redis store")
+	}
+
+	// Share used upload signature nonces between instances if configured (defaults to in-memory)
+	var nonceStore cache.Cache
+	if cfg.Ingest.SignatureNonceStore == "redis" {
+		nonceStore = cache.NewRedisCache(redisClient)
+		slog.Info("Upload signature nonces initialized with Redis store")
+	}
+
+	// Device signing secrets are stored encrypted; without a key, keys are minted without them
+	var signingSecrets *auth.SecretBox
+	if cfg.Ingest.SigningSecretKey != "" {
+		if signingSecrets, err = auth.NewSecretBox(cfg.Ingest.SigningSecretKey); err != nil {
+			fatal("Failed to set up device signing secrets", err)
+		}
+	}
 
 	// Create repositories
-	telemetryRepo := repository.NewPostgresRepository(db)
+	postgresTelemetryRepo := repository.NewPostgresRepository(db).WithDeduplication(cfg.Ingest.Deduplicate)
+	// Every ingest path writes through the quality checks, which flag impossible points and skewed clocks
+	clockPolicy := ingest.NewClockPolicy(cfg.Ingest.ClockSkewPolicy, cfg.Ingest.ClockSkewThreshold)
+	var telemetryRepo repository.TelemetryRepository = ingest.NewQualityChecker(derivedChannels(cfg, postgresTelemetryRepo)).WithClockPolicy(clockPolicy)
 	userRepo := repository.NewPostgresUserRepository(db)
 	refreshTokenRepo := repository.NewPostgresRefreshTokenRepository(db.DB)
-	deviceRepo := repository.NewPostgresDeviceRepository(db.DB)
+	loginAttemptRepo := repository.NewPostgresLoginAttemptRepository(db.DB)
+	usageRepo := repository.NewPostgresUsageRepository(db.DB)
+	var deviceRepo repository.DeviceRepository = repository.NewPostgresDeviceRepository(db.DB)
+	var sessionRepo repository.SessionRepository = repository.NewPostgresSessionRepository(db.DB)
+	deviceAPIKeyRepo := repository.NewPostgresDeviceAPIKeyRepository(db.DB)
+	trackRepo := repository.NewPostgresTrackRepository(db.DB)
+	circuitRepo := repository.NewPostgresCircuitRepository(db.DB)
+	geofenceRepo := repository.NewPostgresGeofenceRepository(db.DB)
+	alertRuleRepo := repository.NewPostgresAlertRuleRepository(db.DB)
+	importJobRepo := repository.NewPostgresImportJobRepository(db.DB)
+	orgRepo := repository.NewPostgresOrganizationRepository(db.DB)
+	deviceHealthRepo := repository.NewPostgresDeviceHealthRepository(db.DB)
+	registrationRepo := repository.NewPostgresDeviceRegistrationRepository(db.DB)
+	ingestAuditRepo := repository.NewPostgresIngestAuditRepository(db.DB)
+	loginEventRepo := repository.NewPostgresLoginEventRepository(db.DB)
+	uploadRepo := repository.NewPostgresUploadRepository(db.DB)
+	notificationRepo := repository.NewPostgresNotificationRepository(db.DB)
+	pushTokenRepo := repository.NewPostgresPushTokenRepository(db.DB)
+	ownershipRepo := repository.NewPostgresDeviceOwnershipRepository(db.DB)
+	backfillRepo := repository.NewPostgresDeviceBackfillRepository(db.DB)
+	deletionRepo := repository.NewPostgresTelemetryDeletionRepository(db.DB)
+	purgeRepo := repository.NewPostgresTelemetryPurgeRepository(db.DB)
+	identityRepo := repository.NewPostgresUserIdentityRepository(db.DB)
+	deviceConfigRepo := repository.NewPostgresDeviceConfigRepository(db.DB)
+	commandRepo := repository.NewPostgresDeviceCommandRepository(db.DB)
+	reportRepo := repository.NewPostgresReportRepository(db.DB)
+	accessTokenRepo := repository.NewPostgresPersonalAccessTokenRepository(db.DB)
+
+	// Create or drop the unique index backing telemetry deduplication
+	if err := postgresTelemetryRepo.ApplyDeduplication(context.Background()); err != nil {
+		fatal("Failed to apply telemetry deduplication", err)
+	}
+
+	// Cache hot queries (recent telemetry, device lists and sessions) if configured
+	if cfg.Cache.Enabled {
+		var queryCache cache.Cache = cache.NewMemoryCache()
+		if cfg.Cache.Store == "redis" {
+			queryCache = cache.NewRedisCache(redisClient)
+		}
+		telemetryRepo = repository.NewCachedTelemetryRepository(telemetryRepo, queryCache, cfg.Cache.TTL)
+		deviceRepo = repository.NewCachedDeviceRepository(deviceRepo, queryCache, cfg.Cache.TTL)
+		sessionRepo = repository.NewCachedSessionRepository(sessionRepo, queryCache, cfg.Cache.TTL)
+		slog.Info("Query cache enabled", "store", cfg.Cache.Store, "ttl", cfg.Cache.TTL)
+	}
 
 	// Initialize email service if configured
-	var emailService email.Service
-	switch cfg.Email.Provider {
-	case "mailgun":
-		if cfg.Email.MailgunAPIKey != "" {
-			emailService = email.NewMailgunService(
-				cfg.Email.MailgunDomain,
-				cfg.Email.MailgunAPIKey,
-				cfg.Email.FromAddress,
-				cfg.Email.FromName,
-				cfg.Email.AppURL,
-			)
-			log.Println("Email service initialized with Mailgun provider")
-		} else {
-			log.Println("Mailgun provider selected but API key not configured - emails disabled")
+	emailService := newEmailService(cfg.Email)
+
+	// Load the GeoIP database if configured
+	var geoIP *geoip.CSVProvider
+	if cfg.GeoIP.Provider == "csv" {
+		geoIP, err = geoip.LoadCSV(cfg.GeoIP.DatabasePath)
+		if err != nil {
+			fatal("Failed to load GeoIP database", err)
 		}
-	case "console":
-		// Console email service for local development - logs emails to stdout
-		emailService = email.NewConsoleService(
-			cfg.Email.FromAddress,
-			cfg.Email.FromName,
-			cfg.Email.AppURL,
-		)
-		log.Println("Email service initialized with Console provider (logs to stdout)")
-	default:
-		log.Println("Email service not configured - password reset emails will be disabled")
+		slog.Info("GeoIP database loaded", "path", cfg.GeoIP.DatabasePath, "networks", geoIP.Len())
+	}
+
+	// Keep notifications in the inbox and email or push them per user preference
+	notifier := notify.NewService(notificationRepo, userRepo)
+	if emailService != nil {
+		notifier = notifier.WithChannel(notify.NewEmailChannel(emailService))
+	}
+	pushSender, err := newPushSender(cfg.Push, pushTokenRepo)
+	if err != nil {
+		fatal("Failed to initialize push notifications", err)
+	}
+	if pushSender.Enabled() {
+		notifier = notifier.WithChannel(notify.NewPushChannel(pushSender))
+	}
+
+	// Start background workers (stopped when main returns)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// Sign tokens with rotated JWT secrets without a restart
+	jwtService := auth.NewJWTService(cfg.Auth.JWTSecret, cfg.Auth.JWTAccessTokenTTL, cfg.Auth.JWTRefreshTokenTTL)
+	if secretProvider != nil && cfg.Secrets.JWTSecretRef != "" && cfg.Secrets.RefreshInterval > 0 {
+		go secrets.NewWatcher(secretProvider, cfg.Secrets.JWTSecretRef, cfg.Auth.JWTSecret,
+			cfg.Secrets.RefreshInterval, jwtService.RotateSecret).Run(workerCtx)
+	}
+
+	sessionAggregator := aggregation.NewSessionAggregator(sessionRepo, cfg.Workers.SessionSummaryInterval).
+		WithNotifier(notifier)
+	go sessionAggregator.Run(workerCtx)
+
+	deviceBackfiller := adoption.NewBackfiller(registrationRepo, cfg.Workers.DeviceBackfillInterval)
+	go deviceBackfiller.Run(workerCtx)
+
+	claimBackfiller := adoption.NewClaimBackfiller(backfillRepo, cfg.Workers.DeviceBackfillInterval)
+	go claimBackfiller.Run(workerCtx)
+
+	// Purge trimmed and deleted telemetry once it can no longer be restored
+	go corrections.NewPurger(deletionRepo, cfg.Workers.TelemetryPurgeInterval).Run(workerCtx)
+
+	// Derive the channels of telemetry stored before they were enabled
+	if cfg.Ingest.DerivedChannels && cfg.Workers.DerivedBackfillInterval > 0 {
+		derivedRepo := repository.NewPostgresDerivedChannelRepository(db.DB)
+		go ingest.NewDerivedBackfiller(derivedRepo, cfg.Workers.DerivedBackfillInterval).Run(workerCtx)
+	}
+
+	var sessionSmoother *smoothing.Processor
+	if cfg.Workers.SmoothingEnabled {
+		processedRepo := repository.NewPostgresProcessedTelemetryRepository(db.DB)
+		sessionSmoother = smoothing.NewProcessor(telemetryRepo, processedRepo, cfg.Workers.SmoothingInterval)
+		go sessionSmoother.Run(workerCtx)
+		slog.Info("Session smoothing enabled", "interval", cfg.Workers.SmoothingInterval)
+	}
+
+	// Webhook URLs are chosen by users, so deliveries only reach internal hosts in the allowed networks
+	webhookNetworks, err := webhook.ParseNetworks(cfg.Webhooks.AllowedNetworks)
+	if err != nil {
+		fatal("Invalid webhook allowed networks", err)
+	}
+	webhookClient := webhook.NewClient(webhookNetworks...)
+
+	// Deliver domain events published by the handlers to the integrations that react to them
+	eventBus := events.NewBus(repository.NewPostgresEventOutboxRepository(db.DB), cfg.Events.PollInterval).
+		WithRetention(cfg.Events.RetainFor).
+		Subscribe(models.EventSessionEnded, "session-summary", events.EnqueueEndedSessions(sessionAggregator)).
+		Subscribe(models.EventUserLoggedIn, "login-alerts", events.LoginAlerts(notifier))
+	if sessionSmoother != nil {
+		eventBus = eventBus.Subscribe(models.EventSessionEnded, "session-smoothing", events.EnqueueEndedSessions(sessionSmoother))
+	}
+	if cfg.Events.WebhookURL != "" {
+		eventWebhook := events.Webhook(webhookClient, cfg.Events.WebhookURL)
+		for _, eventType := range models.EventTypes {
+			eventBus = eventBus.Subscribe(eventType, "webhook", eventWebhook)
+		}
+		slog.Info("Event webhook enabled")
+	}
+	if cfg.Stream.Enabled() {
+		streamPublisher, err := stream.New(cfg.Stream)
+		if err != nil {
+			fatal("Failed to configure telemetry streaming", err)
+		}
+		defer func() {
+			_ = streamPublisher.Close()
+		}()
+		eventBus = eventBus.Subscribe(models.EventTelemetryBatchSaved, "telemetry-stream", events.ForwardTelemetry(streamPublisher, cfg.Stream.Topic))
+		slog.Info("Telemetry streaming enabled", "driver", cfg.Stream.Driver, "topic", cfg.Stream.Topic)
+	}
+	go eventBus.Run(workerCtx)
+
+	// Sign users in through an OpenID Connect provider if configured
+	var oidcProvider handlers.OIDCProvider
+	if cfg.OIDC.Enabled() {
+		oidcProvider = oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDC.IssuerURL,
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  cfg.OIDC.RedirectURL,
+			Scopes:       cfg.OIDC.Scopes,
+			GroupsClaim:  cfg.OIDC.GroupsClaim,
+		})
+		slog.Info("Single sign-on enabled", "issuer", cfg.OIDC.IssuerURL)
+	}
+
+	var archiveReader *archive.Reader
+	if cfg.Archive.Enabled {
+		archiveStores, err := archive.NewS3Stores(cfg.Archive)
+		if err != nil {
+			fatal("Failed to configure telemetry archive storage", err)
+		}
+		archiveRepo := repository.NewPostgresArchiveRepository(db.DB)
+		go archive.NewArchiver(archiveRepo, archiveStores, cfg.Archive).Run(workerCtx)
+		archiveReader = archive.NewReader(archiveRepo, archiveStores)
+		slog.Info("Telemetry archival enabled", "after", cfg.Archive.After, "buckets", len(archiveStores))
+	}
+
+	geofenceEvaluator := geofence.NewEvaluator(geofenceRepo, userRepo).WithHTTPClient(webhookClient)
+	if emailService != nil {
+		geofenceEvaluator = geofenceEvaluator.WithEmailService(emailService)
+	}
+	go geofenceEvaluator.Run(workerCtx)
+
+	alertEvaluator := alerts.NewEvaluator(alertRuleRepo).WithNotifier(notifier).WithHTTPClient(webhookClient)
+	go alertEvaluator.Run(workerCtx)
+
+	var healthMonitor *devicehealth.Monitor
+	if cfg.Health.Enabled {
+		healthMonitor = devicehealth.NewMonitor(deviceHealthRepo, userRepo, cfg.Health).WithNotifier(notifier).WithHTTPClient(webhookClient)
+		if emailService != nil {
+			healthMonitor = healthMonitor.WithEmailService(emailService)
+		}
+		go healthMonitor.Run(workerCtx)
+	}
+
+	// Track devices coming online and going offline for the device event stream
+	devicePresence := presence.NewTracker(deviceRepo)
+	go devicePresence.Run(workerCtx)
+
+	// Fail imports interrupted by the last shutdown before accepting new ones
+	telemetryImporter := importer.NewImporter(importJobRepo, telemetryRepo, sessionRepo)
+	if err := telemetryImporter.FailInterrupted(context.Background()); err != nil {
+		slog.Error("Error failing interrupted import jobs", "error", err)
+	}
+	go telemetryImporter.Run(workerCtx)
+
+	// Fail reports interrupted by the last shutdown before accepting new ones
+	reportGenerator := reports.NewGenerator(reportRepo, cfg.Reports.SweepInterval)
+	if err := reportGenerator.FailInterrupted(context.Background()); err != nil {
+		slog.Error("Error failing interrupted reports", "error", err)
+	}
+	go reportGenerator.Run(workerCtx)
+
+	// Ingest completed resumable uploads, resuming any interrupted by the last shutdown
+	uploadProcessor := upload.NewProcessor(uploadRepo, telemetryRepo, handlers.DecodeTelemetry).
+		WithLenientValidation(cfg.Server.LenientValidation)
+	if cfg.Quota.Enabled {
+		uploadProcessor = uploadProcessor.WithUsage(usageRepo)
+	}
+	go uploadProcessor.Run(workerCtx)
+
+	// Start the write-behind ingest buffer if configured; it flushes remaining records when workers stop
+	var telemetryWriter *ingest.BufferedWriter
+	writerDone := make(chan struct{})
+	if cfg.Ingest.Buffered {
+		// Queued uploads publish telemetry.batch_saved with the write that stores them
+		telemetryWriter = ingest.NewBufferedWriter(telemetryRepo, cfg.Ingest).WithEvents(eventBus)
+		if cfg.Stream.Enabled() {
+			telemetryWriter = telemetryWriter.WithEventTelemetry()
+		}
+		go func() {
+			telemetryWriter.Run(workerCtx)
+			close(writerDone)
+		}()
+		slog.Info("Buffered ingest enabled",
+			"buffer_size", cfg.Ingest.BufferSize, "flush_size", cfg.Ingest.FlushSize, "flush_interval", cfg.Ingest.FlushInterval)
+	} else {
+		close(writerDone)
+	}
+
+	// Track ingest pressure so overloaded uploads are shed with a Retry-After hint
+	ingestPressure := ingest.NewMonitor(cfg.Ingest).WithPool(db.DB)
+	if telemetryWriter != nil {
+		ingestPressure = ingestPressure.WithWriter(telemetryWriter)
+	}
+	go ingestPressure.Run(workerCtx)
+
+	// Start the ingest audit log if enabled; it writes remaining entries when workers stop
+	var ingestAudit *audit.IngestLog
+	auditDone := make(chan struct{})
+	if cfg.Ingest.Audit {
+		ingestAudit = audit.NewIngestLog(ingestAuditRepo)
+		go func() {
+			ingestAudit.Run(workerCtx)
+			close(auditDone)
+		}()
+	} else {
+		close(auditDone)
+	}
+
+	// Start the MQTT ingestion bridge if configured
+	if cfg.MQTT.Enabled {
+		bridge := mqtt.NewBridge(cfg.MQTT, telemetryRepo, deviceRepo).
+			WithGeofenceEvaluator(geofenceEvaluator).
+			WithAlertEvaluator(alertEvaluator).
+			WithPresence(devicePresence).
+			WithLenientValidation(cfg.Server.LenientValidation)
+		if healthMonitor != nil {
+			bridge = bridge.WithHealthMonitor(healthMonitor)
+		}
+		if ingestAudit != nil {
+			bridge = bridge.WithAuditLog(ingestAudit)
+		}
+		go func() {
+			if err := bridge.Run(workerCtx); err != nil {
+				slog.Error("MQTT bridge stopped", "error", err)
+			}
+		}()
+		slog.Info("MQTT bridge enabled", "broker_url", cfg.MQTT.BrokerURL, "topic", cfg.MQTT.Topic)
 	}
 
 	// Create server dependencies
@@ -71,20 +502,293 @@ func main() {
 		TelemetryRepo:    telemetryRepo,
 		UserRepo:         userRepo,
 		RefreshTokenRepo: refreshTokenRepo,
+		LoginAttemptRepo: loginAttemptRepo,
+		UsageRepo:        usageRepo,
 		DeviceRepo:       deviceRepo,
+		SessionRepo:      sessionRepo,
+		DeviceAPIKeyRepo: deviceAPIKeyRepo,
+		TrackRepo:        trackRepo,
+		CircuitRepo:      circuitRepo,
+		GeofenceRepo:     geofenceRepo,
+		AlertRuleRepo:    alertRuleRepo,
+		ImportJobRepo:    importJobRepo,
+		OrganizationRepo: orgRepo,
+		RegistrationRepo: registrationRepo,
+		NotificationRepo: notificationRepo,
+		PushTokenRepo:    pushTokenRepo,
+		OwnershipRepo:    ownershipRepo,
+		BackfillRepo:     backfillRepo,
+		DeletionRepo:     deletionRepo,
+		PurgeRepo:        purgeRepo,
+		IdentityRepo:     identityRepo,
+		OIDC:             oidcProvider,
+		DeviceConfigRepo: deviceConfigRepo,
+		CommandRepo:      commandRepo,
+		ReportRepo:       reportRepo,
+		AccessTokenRepo:  accessTokenRepo,
 		EmailService:     emailService,
+		Notifier:         notifier,
+		PasswordPolicy:   auth.NewPasswordPolicy(cfg.Password),
+		JWTService:       jwtService,
+		RateLimitStore:   rateLimitStore,
+		RateLimits:       rateLimits,
+		DenylistStore:    denylistStore,
+		NonceStore:       nonceStore,
+		SigningSecrets:   signingSecrets,
+		Summarizer:       sessionAggregator,
+		PolicyInspector:  policyManager,
+		Geofences:        geofenceEvaluator,
+		Alerts:           alertEvaluator,
+		Presence:         devicePresence,
+		Importer:         telemetryImporter,
+		Backfiller:       deviceBackfiller,
+		ClaimBackfiller:  claimBackfiller,
+		IngestAuditRepo:  ingestAuditRepo,
+		LoginEventRepo:   loginEventRepo,
+		UploadRepo:       uploadRepo,
+		Uploads:          uploadProcessor,
+		Reports:          reportGenerator,
+		IngestPressure:   ingestPressure,
+		ClockPolicy:      clockPolicy,
+		Events:           eventBus,
+	}
+	if telemetryWriter != nil {
+		deps.TelemetryWriter = telemetryWriter
+	}
+	if ingestAudit != nil {
+		deps.IngestAudit = ingestAudit
+	}
+	if geoIP != nil {
+		deps.GeoIP = geoIP
+	}
+	if sessionSmoother != nil {
+		deps.PostProcessor = sessionSmoother
+	}
+	if archiveReader != nil {
+		deps.Archive = archiveReader
+	}
+	if healthMonitor != nil {
+		deps.DeviceHealthRepo = deviceHealthRepo
+		deps.HealthMonitor = healthMonitor
 	}
 
 	// Create and start the server
 	srv := server.New(deps)
 
 	if cfg.Server.DevMode {
-		log.Println("Development mode enabled - password reset UI available at /reset-password")
+		slog.Info("Development mode enabled - dev console available at /dev and password reset UI at /reset-password")
+	}
+
+	// Stop accepting requests and let in-flight uploads finish before flushing buffered telemetry
+	shutdownCtx, cancel := serve(cfg, srv, rateLimits)
+	defer cancel()
+
+	stopWorkers()
+	select {
+	case <-writerDone:
+	case <-shutdownCtx.Done():
+		slog.Warn("Timed out flushing buffered telemetry", "pending", telemetryWriter.Pending())
+	}
+	select {
+	case <-auditDone:
+	case <-shutdownCtx.Done():
+		slog.Warn("Timed out writing the ingest audit log")
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Error flushing traces", "error", err)
+	}
+}
+
+// runSQLite serves the API from a SQLite database file for single-instance and edge deployments
+// Telemetry, accounts and devices are stored in SQLite; sessions, tracks, geofences, imports,
+// organizations and the other PostgreSQL-only features are disabled. Redis is not used.
+func runSQLite(cfg *config.Config, migrateOnly bool, shutdownTracing func(context.Context) error) {
+	db, err := database.NewSQLite(&cfg.Database)
+	if err != nil {
+		fatal("Failed to open database", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			slog.Error("Error closing database", "error", err)
+		}
+	}()
+
+	slog.Info("Successfully opened SQLite database", "path", cfg.Database.SQLitePath)
+	if migrateOnly {
+		slog.Info("Database schema is up to date")
+		return
+	}
+
+	// Create repositories
+	clockPolicy := ingest.NewClockPolicy(cfg.Ingest.ClockSkewPolicy, cfg.Ingest.ClockSkewThreshold)
+	sqliteTelemetryRepo := repository.NewSQLiteRepository(db.DB).WithDeduplication(cfg.Ingest.Deduplicate)
+	if err := sqliteTelemetryRepo.ApplyDeduplication(context.Background()); err != nil {
+		fatal("Failed to apply telemetry deduplication", err)
+	}
+	userRepo := repository.NewSQLiteUserRepository(db.DB)
+	deviceRepo := repository.NewSQLiteDeviceRepository(db.DB)
+
+	// Start background workers (stopped when runSQLite returns)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	devicePresence := presence.NewTracker(deviceRepo)
+	go devicePresence.Run(workerCtx)
+
+	rateLimits := server.NewRateLimits(cfg.RateLimit)
+	srv := server.New(&server.Dependencies{
+		Config:           cfg,
+		TelemetryRepo:    ingest.NewQualityChecker(derivedChannels(cfg, sqliteTelemetryRepo)).WithClockPolicy(clockPolicy),
+		UserRepo:         userRepo,
+		RefreshTokenRepo: repository.NewSQLiteRefreshTokenRepository(db.DB),
+		DeviceRepo:       deviceRepo,
+		EmailService:     newEmailService(cfg.Email),
+		PasswordPolicy:   auth.NewPasswordPolicy(cfg.Password),
+		RateLimits:       rateLimits,
+		Presence:         devicePresence,
+		ClockPolicy:      clockPolicy,
+	})
+
+	shutdownCtx, cancel := serve(cfg, srv, rateLimits)
+	defer cancel()
+
+	stopWorkers()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Error flushing traces", "error", err)
+	}
+}
+
+// newEmailService creates the configured email service, or nil when emails are disabled
+func newEmailService(cfg config.EmailConfig) email.Service {
+	switch cfg.Provider {
+	case "mailgun":
+		if cfg.MailgunAPIKey == "" {
+			slog.Warn("Mailgun provider selected but API key not configured - emails disabled")
+			return nil
+		}
+		slog.Info("Email service initialized with Mailgun provider")
+		return email.NewMailgunService(cfg.MailgunDomain, cfg.MailgunAPIKey, cfg.FromAddress, cfg.FromName, cfg.AppURL)
+	case "console":
+		// Console email service for local development - logs emails to stdout
+		slog.Info("Email service initialized with Console provider (emails are logged)")
+		return email.NewConsoleService(cfg.FromAddress, cfg.FromName, cfg.AppURL)
+	default:
+		slog.Info("Email service not configured - password reset emails will be disabled")
+		return nil
+	}
+}
+
+// derivedChannels wraps repo with derived channels on ingest when they are enabled
+func derivedChannels(cfg *config.Config, repo repository.TelemetryRepository) repository.TelemetryRepository {
+	if !cfg.Ingest.DerivedChannels {
+		return repo
+	}
+	slog.Info("Derived telemetry channels enabled")
+	return ingest.NewChannelDeriver(repo)
+}
+
+// newPushSender creates the push sender with the providers that are configured
+func newPushSender(cfg config.PushConfig, tokens repository.PushTokenRepository) (*push.Sender, error) {
+	sender := push.NewSender(tokens)
+	if cfg.FCMEnabled() {
+		provider, err := push.NewFCMProvider([]byte(cfg.FCMCredentials), cfg.FCMProjectID)
+		if err != nil {
+			return nil, err
+		}
+		sender = sender.WithProvider(models.PushProviderFCM, provider)
+		slog.Info("Push notifications enabled for FCM")
+	}
+	if cfg.APNsEnabled() {
+		provider, err := push.NewAPNsProvider(cfg.APNsKey, cfg.APNsKeyID, cfg.APNsTeamID, cfg.APNsTopic, cfg.APNsSandbox)
+		if err != nil {
+			return nil, err
+		}
+		sender = sender.WithProvider(models.PushProviderAPNs, provider)
+		slog.Info("Push notifications enabled for APNs", "sandbox", cfg.APNsSandbox)
+	}
+	if !sender.Enabled() {
+		slog.Info("Push notifications not configured - registered tokens will not receive notifications")
+	}
+	return sender, nil
+}
+
+// serve runs the HTTP server until a shutdown signal or a server failure, reloading configuration on SIGHUP
+// It then stops accepting requests and waits for in-flight ones. The returned context bounds the
+// rest of the shutdown.
+func serve(cfg *config.Config, handler http.Handler, rateLimits *server.RateLimits) (context.Context, context.CancelFunc) {
+	httpServer := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", "port", cfg.Server.Port)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// Reload configuration on SIGHUP if enabled
+	reload := make(chan os.Signal, 1)
+	if cfg.Reload.OnSIGHUP {
+		signal.Notify(reload, syscall.SIGHUP)
+		slog.Info("Configuration reload on SIGHUP enabled", "file", cfg.Reload.File)
+	}
+
+	// Wait for a shutdown signal or a server failure
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	current := cfg
+wait:
+	for {
+		select {
+		case <-reload:
+			current = reloadConfig(current, rateLimits)
+		case sig := <-stop:
+			slog.Info("Received signal, shutting down", "signal", sig)
+			break wait
+		case err := <-serverErr:
+			slog.Error("Failed to start server", "error", err)
+			break wait
+		}
 	}
 
-	log.Printf("Starting server on port %s", cfg.Server.Port)
-	if err := srv.Run(":" + cfg.Server.Port); err != nil {
-		log.Printf("Failed to start server: %v", err)
-		panic(err) // Use panic instead of log.Fatalf to ensure defer runs
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error shutting down server", "error", err)
 	}
+
+	return shutdownCtx, cancel
+}
+
+// reloadConfig re-reads configuration and applies the settings that can change while serving
+// Invalid configuration is ignored. Returns the configuration now in effect.
+func reloadConfig(current *config.Config, rateLimits *server.RateLimits) *config.Config {
+	next, err := config.Load()
+	if err != nil {
+		slog.Error("Ignoring invalid configuration reload", "error", err)
+		return current
+	}
+
+	updated, applied, restart := current.Apply(next)
+	if len(restart) > 0 {
+		slog.Warn("Configuration changes need a restart to take effect", "settings", restart)
+	}
+	if err := logging.SetLevel(updated.Logging.Level); err != nil {
+		slog.Error("Ignoring invalid configuration reload", "error", err)
+		return current
+	}
+	rateLimits.Update(updated.RateLimit)
+
+	slog.Info("Configuration reloaded", "applied", applied)
+	return updated
+}
+
+// fatal logs an error that prevents the server from running and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
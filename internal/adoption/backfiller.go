@@ -0,0 +1,102 @@
+// Package adoption assigns the history of anonymously uploading devices to the users who adopt them.
+package adoption
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// DefaultSweepInterval is how often pending backfills are picked up
+	DefaultSweepInterval = 5 * time.Minute
+
+	// sweepBatchSize caps the number of devices backfilled per sweep
+	sweepBatchSize = 50
+
+	// queueSize bounds the number of devices waiting for an on-demand backfill
+	queueSize = 64
+)
+
+// Backfiller assigns an adopted device's anonymous telemetry, sessions and upload batches to its adopter
+// Devices are backfilled when enqueued (on adoption) and by a periodic sweep that catches any
+// adoptions missed by the queue, such as those interrupted by a restart.
+type Backfiller struct {
+	registrationRepo repository.DeviceRegistrationRepository
+	sweepInterval    time.Duration
+	queue            chan string
+}
+
+// NewBackfiller creates a new backfiller
+func NewBackfiller(registrationRepo repository.DeviceRegistrationRepository, sweepInterval time.Duration) *Backfiller {
+	if sweepInterval <= 0 {
+		sweepInterval = DefaultSweepInterval
+	}
+
+	return &Backfiller{
+		registrationRepo: registrationRepo,
+		sweepInterval:    sweepInterval,
+		queue:            make(chan string, queueSize),
+	}
+}
+
+// Enqueue schedules an adopted device for backfill without blocking
+// If the queue is full the device is left for the next sweep.
+func (b *Backfiller) Enqueue(deviceID string) {
+	select {
+	case b.queue <- deviceID:
+	default:
+		slog.Warn("Device backfill queue full, deferring device to next sweep", "device_id", deviceID)
+	}
+}
+
+// Run processes queued devices and periodic sweeps until the context is cancelled
+func (b *Backfiller) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.sweepInterval)
+	defer ticker.Stop()
+
+	// Catch up on adoptions that were not backfilled before the service stopped
+	b.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case deviceID := <-b.queue:
+			b.backfill(ctx, deviceID)
+		case <-ticker.C:
+			b.sweep(ctx)
+		}
+	}
+}
+
+// sweep backfills adopted devices whose history has not been assigned yet
+func (b *Backfiller) sweep(ctx context.Context) {
+	deviceIDs, err := b.registrationRepo.ListPendingBackfills(ctx, sweepBatchSize)
+	if err != nil {
+		slog.Error("Error listing pending device backfills", "error", err)
+		return
+	}
+
+	for _, deviceID := range deviceIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		b.backfill(ctx, deviceID)
+	}
+}
+
+// backfill assigns a single device's history, ignoring devices another run already finished
+func (b *Backfiller) backfill(ctx context.Context, deviceID string) {
+	records, err := b.registrationRepo.Backfill(ctx, deviceID)
+	switch {
+	case err == nil:
+		slog.Info("Device backfill: assigned telemetry records to the device's adopter", "device_id", deviceID, "count", records)
+	case errors.Is(err, repository.ErrDeviceRegistrationNotFound):
+	default:
+		slog.Error("Error backfilling device", "device_id", deviceID, "error", err)
+	}
+}
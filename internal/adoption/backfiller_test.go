@@ -0,0 +1,73 @@
+package adoption
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackfiller_RunProcessesSweepAndQueue(t *testing.T) {
+	var mu sync.Mutex
+	backfilled := make(map[string]bool)
+	done := make(chan struct{}, 2)
+
+	repo := repository.NewMockDeviceRegistrationRepository()
+	repo.ListPendingBackfillsFunc = func(_ context.Context, _ int) ([]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if backfilled["PENDING-001"] {
+			return []string{}, nil
+		}
+		return []string{"PENDING-001"}, nil
+	}
+	repo.BackfillFunc = func(_ context.Context, deviceID string) (int64, error) {
+		mu.Lock()
+		backfilled[deviceID] = true
+		mu.Unlock()
+		done <- struct{}{}
+		return 10, nil
+	}
+
+	backfiller := NewBackfiller(repo, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go backfiller.Run(ctx)
+	backfiller.Enqueue("QUEUED-001")
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for backfill")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, backfilled["PENDING-001"], "startup sweep should backfill pending devices")
+	assert.True(t, backfilled["QUEUED-001"], "queued device should be backfilled")
+}
+
+func TestBackfiller_EnqueueDoesNotBlockWhenFull(t *testing.T) {
+	backfiller := NewBackfiller(repository.NewMockDeviceRegistrationRepository(), 0)
+
+	finished := make(chan struct{})
+	go func() {
+		for i := 0; i < queueSize+10; i++ {
+			backfiller.Enqueue("DEVICE")
+		}
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue blocked on a full queue")
+	}
+	assert.Equal(t, DefaultSweepInterval, backfiller.sweepInterval)
+}
@@ -0,0 +1,137 @@
+package adoption
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// claimBackfillBatchSize is the number of telemetry records assigned per transaction
+	claimBackfillBatchSize = 5000
+
+	// claimSweepBatchSize caps the number of requested backfills picked up per sweep
+	claimSweepBatchSize = 20
+)
+
+// ClaimBackfiller runs the backfills owners request for devices they claimed after they uploaded anonymously
+// Records are assigned in batches and the progress is stored after each one, so clients can poll it.
+// Backfills are run when enqueued and by a periodic sweep, which also resumes those interrupted by a
+// restart; only records without an owner are assigned, so resuming is safe.
+type ClaimBackfiller struct {
+	backfillRepo  repository.DeviceBackfillRepository
+	sweepInterval time.Duration
+	batchSize     int
+	queue         chan uuid.UUID
+}
+
+// NewClaimBackfiller creates a new claim backfiller
+func NewClaimBackfiller(backfillRepo repository.DeviceBackfillRepository, sweepInterval time.Duration) *ClaimBackfiller {
+	if sweepInterval <= 0 {
+		sweepInterval = DefaultSweepInterval
+	}
+
+	return &ClaimBackfiller{
+		backfillRepo:  backfillRepo,
+		sweepInterval: sweepInterval,
+		batchSize:     claimBackfillBatchSize,
+		queue:         make(chan uuid.UUID, queueSize),
+	}
+}
+
+// Enqueue schedules a requested backfill without blocking
+// If the queue is full the backfill is left for the next sweep.
+func (b *ClaimBackfiller) Enqueue(id uuid.UUID) {
+	select {
+	case b.queue <- id:
+	default:
+		slog.Warn("Claim backfill queue full, deferring backfill to next sweep", "backfill_id", id)
+	}
+}
+
+// Run processes queued backfills and periodic sweeps until the context is cancelled
+func (b *ClaimBackfiller) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.sweepInterval)
+	defer ticker.Stop()
+
+	// Resume backfills interrupted when the service stopped
+	b.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-b.queue:
+			b.backfill(ctx, id)
+		case <-ticker.C:
+			b.sweep(ctx)
+		}
+	}
+}
+
+// sweep runs backfills that are pending or were interrupted
+func (b *ClaimBackfiller) sweep(ctx context.Context) {
+	ids, err := b.backfillRepo.ListIncomplete(ctx, claimSweepBatchSize)
+	if err != nil {
+		slog.Error("Error listing incomplete claim backfills", "error", err)
+		return
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		b.backfill(ctx, id)
+	}
+}
+
+// backfill assigns a device's anonymous history batch by batch, ignoring backfills another run finished
+// A backfill stopped by shutdown stays processing and is resumed by the next sweep.
+func (b *ClaimBackfiller) backfill(ctx context.Context, id uuid.UUID) {
+	backfill, err := b.backfillRepo.Start(ctx, id)
+	if err != nil {
+		if !errors.Is(err, repository.ErrDeviceBackfillNotFound) {
+			slog.Error("Error starting claim backfill", "backfill_id", id, "error", err)
+		}
+		return
+	}
+
+	assigned := backfill.AssignedRecords
+	for {
+		n, err := b.backfillRepo.AssignBatch(ctx, id, b.batchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			b.fail(ctx, id, err)
+			return
+		}
+		assigned += n
+		if n < int64(b.batchSize) {
+			break
+		}
+	}
+
+	if err := b.backfillRepo.Complete(ctx, id); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		b.fail(ctx, id, err)
+		return
+	}
+
+	slog.Info("Claim backfill: assigned telemetry records to the device's owner",
+		"backfill_id", id, "device_id", backfill.DeviceID, "count", assigned)
+}
+
+// fail records why a backfill stopped so its owner can request it again
+func (b *ClaimBackfiller) fail(ctx context.Context, id uuid.UUID, cause error) {
+	slog.Error("Error running claim backfill", "backfill_id", id, "error", cause)
+	if err := b.backfillRepo.Fail(ctx, id, "Failed to assign the device's history"); err != nil {
+		slog.Error("Error marking claim backfill failed", "backfill_id", id, "error", err)
+	}
+}
@@ -0,0 +1,117 @@
+package adoption
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimBackfiller_AssignsInBatches(t *testing.T) {
+	id := uuid.New()
+	remaining := int64(12)
+	var batches []int64
+	var completed bool
+
+	repo := repository.NewMockDeviceBackfillRepository()
+	repo.StartFunc = func(_ context.Context, got uuid.UUID) (*models.DeviceBackfill, error) {
+		assert.Equal(t, id, got)
+		return &models.DeviceBackfill{ID: id, Status: models.BackfillProcessing, TotalRecords: remaining}, nil
+	}
+	repo.AssignBatchFunc = func(_ context.Context, _ uuid.UUID, limit int) (int64, error) {
+		n := min(remaining, int64(limit))
+		remaining -= n
+		batches = append(batches, n)
+		return n, nil
+	}
+	repo.CompleteFunc = func(_ context.Context, _ uuid.UUID) error {
+		completed = true
+		return nil
+	}
+
+	backfiller := NewClaimBackfiller(repo, time.Hour)
+	backfiller.batchSize = 5
+	backfiller.backfill(context.Background(), id)
+
+	assert.Equal(t, []int64{5, 5, 2}, batches)
+	assert.True(t, completed)
+}
+
+func TestClaimBackfiller_FailsOnError(t *testing.T) {
+	var failed string
+
+	repo := repository.NewMockDeviceBackfillRepository()
+	repo.StartFunc = func(_ context.Context, id uuid.UUID) (*models.DeviceBackfill, error) {
+		return &models.DeviceBackfill{ID: id, Status: models.BackfillProcessing}, nil
+	}
+	repo.AssignBatchFunc = func(_ context.Context, _ uuid.UUID, _ int) (int64, error) {
+		return 0, errors.New("connection reset")
+	}
+	repo.CompleteFunc = func(_ context.Context, _ uuid.UUID) error {
+		t.Error("a failed backfill should not complete")
+		return nil
+	}
+	repo.FailFunc = func(_ context.Context, _ uuid.UUID, errMsg string) error {
+		failed = errMsg
+		return nil
+	}
+
+	NewClaimBackfiller(repo, time.Hour).backfill(context.Background(), uuid.New())
+
+	assert.NotEmpty(t, failed)
+}
+
+func TestClaimBackfiller_RunResumesAndProcessesQueue(t *testing.T) {
+	interrupted := uuid.New()
+	requested := uuid.New()
+
+	var mu sync.Mutex
+	completed := make(map[uuid.UUID]bool)
+	done := make(chan struct{}, 2)
+
+	repo := repository.NewMockDeviceBackfillRepository()
+	repo.ListIncompleteFunc = func(_ context.Context, _ int) ([]uuid.UUID, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if completed[interrupted] {
+			return []uuid.UUID{}, nil
+		}
+		return []uuid.UUID{interrupted}, nil
+	}
+	repo.StartFunc = func(_ context.Context, id uuid.UUID) (*models.DeviceBackfill, error) {
+		return &models.DeviceBackfill{ID: id, Status: models.BackfillProcessing}, nil
+	}
+	repo.CompleteFunc = func(_ context.Context, id uuid.UUID) error {
+		mu.Lock()
+		completed[id] = true
+		mu.Unlock()
+		done <- struct{}{}
+		return nil
+	}
+
+	backfiller := NewClaimBackfiller(repo, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go backfiller.Run(ctx)
+	backfiller.Enqueue(requested)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for backfill")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, completed[interrupted], "startup sweep should resume interrupted backfills")
+	assert.True(t, completed[requested], "queued backfill should run")
+}
@@ -0,0 +1,133 @@
+// Package aggregation computes derived statistics from raw telemetry.
+package aggregation
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/notify"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// DefaultSweepInterval is how often pending session summaries are picked up
+	DefaultSweepInterval = 5 * time.Minute
+
+	// sweepBatchSize caps the number of sessions summarized per sweep
+	sweepBatchSize = 100
+
+	// queueSize bounds the number of sessions waiting for on-demand aggregation
+	queueSize = 256
+)
+
+// Notifier stores a notification in its user's inbox and delivers it over the channels they chose
+type Notifier interface {
+	Notify(ctx context.Context, notification *models.Notification) error
+}
+
+// SessionAggregator computes session summaries from the telemetry hypertable
+// Sessions are summarized when enqueued (e.g., on session end) and by a
+// periodic sweep that catches any sessions missed by the queue.
+type SessionAggregator struct {
+	sessionRepo   repository.SessionRepository
+	notifier      Notifier // Optional: nil disables session summary notifications
+	sweepInterval time.Duration
+	queue         chan uuid.UUID
+}
+
+// NewSessionAggregator creates a new session aggregator
+func NewSessionAggregator(sessionRepo repository.SessionRepository, sweepInterval time.Duration) *SessionAggregator {
+	if sweepInterval <= 0 {
+		sweepInterval = DefaultSweepInterval
+	}
+
+	return &SessionAggregator{
+		sessionRepo:   sessionRepo,
+		sweepInterval: sweepInterval,
+		queue:         make(chan uuid.UUID, queueSize),
+	}
+}
+
+// WithNotifier sets the notifier told when the summary of an ended session is ready
+// Only sessions summarized from the queue are reported; sweeps also recompute stale summaries
+// after late uploads, which would notify the owner again.
+func (a *SessionAggregator) WithNotifier(notifier Notifier) *SessionAggregator {
+	a.notifier = notifier
+	return a
+}
+
+// Enqueue schedules a session for aggregation without blocking
+// If the queue is full the session is left for the next sweep.
+func (a *SessionAggregator) Enqueue(sessionID uuid.UUID) {
+	select {
+	case a.queue <- sessionID:
+	default:
+		slog.Warn("Session aggregation queue full, deferring session to next sweep", "session_id", sessionID)
+	}
+}
+
+// Summarize computes and stores the summary of a single session
+func (a *SessionAggregator) Summarize(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
+	return a.sessionRepo.UpdateSummary(ctx, sessionID)
+}
+
+// Run processes queued sessions and periodic sweeps until the context is cancelled
+func (a *SessionAggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.sweepInterval)
+	defer ticker.Stop()
+
+	// Catch up on sessions that ended while the service was down
+	a.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sessionID := <-a.queue:
+			session, err := a.Summarize(ctx, sessionID)
+			if err != nil {
+				slog.Error("Error summarizing session", "session_id", sessionID, "error", err)
+				continue
+			}
+			a.notify(ctx, session)
+		case <-ticker.C:
+			a.sweep(ctx)
+		}
+	}
+}
+
+// sweep summarizes ended sessions whose aggregates are missing or stale
+func (a *SessionAggregator) sweep(ctx context.Context) {
+	ids, err := a.sessionRepo.ListPendingSummaries(ctx, sweepBatchSize)
+	if err != nil {
+		slog.Error("Error listing pending session summaries", "error", err)
+		return
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := a.Summarize(ctx, id); err != nil {
+			slog.Error("Error summarizing session", "session_id", id, "error", err)
+		}
+	}
+
+	if len(ids) > 0 {
+		slog.Info("Session aggregation: summarized sessions", "count", len(ids))
+	}
+}
+
+// notify tells the owner of an ended session that its summary is ready
+func (a *SessionAggregator) notify(ctx context.Context, session *models.Session) {
+	if a.notifier == nil || session.UserID == nil || session.IsActive() {
+		return
+	}
+
+	if err := a.notifier.Notify(ctx, notify.SessionSummary(session)); err != nil {
+		slog.Error("Error sending session summary notification", "session_id", session.ID, "error", err)
+	}
+}
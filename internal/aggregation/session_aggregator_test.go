@@ -0,0 +1,128 @@
+package aggregation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionAggregator_RunProcessesSweepAndQueue(t *testing.T) {
+	pendingID := uuid.New()
+	queuedID := uuid.New()
+
+	var mu sync.Mutex
+	summarized := make(map[uuid.UUID]bool)
+	done := make(chan struct{}, 2)
+
+	repo := repository.NewMockSessionRepository()
+	repo.ListPendingSummariesFunc = func(_ context.Context, _ int) ([]uuid.UUID, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if summarized[pendingID] {
+			return []uuid.UUID{}, nil
+		}
+		return []uuid.UUID{pendingID}, nil
+	}
+	repo.UpdateSummaryFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		mu.Lock()
+		summarized[id] = true
+		mu.Unlock()
+		done <- struct{}{}
+		return &models.Session{ID: id}, nil
+	}
+
+	aggregator := NewSessionAggregator(repo, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go aggregator.Run(ctx)
+	aggregator.Enqueue(queuedID)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for aggregation")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, summarized[pendingID], "startup sweep should summarize pending sessions")
+	assert.True(t, summarized[queuedID], "queued session should be summarized")
+}
+
+func TestSessionAggregator_EnqueueDoesNotBlockWhenFull(t *testing.T) {
+	aggregator := NewSessionAggregator(repository.NewMockSessionRepository(), 0)
+
+	finished := make(chan struct{})
+	go func() {
+		for i := 0; i < queueSize+10; i++ {
+			aggregator.Enqueue(uuid.New())
+		}
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue blocked on a full queue")
+	}
+	assert.Equal(t, DefaultSweepInterval, aggregator.sweepInterval)
+}
+
+// notifierFunc adapts a function to the Notifier interface
+type notifierFunc func(ctx context.Context, notification *models.Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, notification *models.Notification) error {
+	return f(ctx, notification)
+}
+
+func TestSessionAggregator_NotifiesQueuedSessions(t *testing.T) {
+	userID := uuid.New()
+	queuedID := uuid.New()
+	endedAt := time.Now()
+	distance := 8200.0
+
+	repo := repository.NewMockSessionRepository()
+	repo.ListPendingSummariesFunc = func(_ context.Context, _ int) ([]uuid.UUID, error) {
+		return []uuid.UUID{uuid.New()}, nil
+	}
+	repo.UpdateSummaryFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{
+			ID:            id,
+			DeviceID:      "kart-7",
+			UserID:        &userID,
+			StartedAt:     endedAt.Add(-20 * time.Minute),
+			EndedAt:       &endedAt,
+			TotalDistance: &distance,
+		}, nil
+	}
+
+	notified := make(chan *models.Notification, 2)
+	aggregator := NewSessionAggregator(repo, time.Hour).WithNotifier(notifierFunc(func(_ context.Context, notification *models.Notification) error {
+		notified <- notification
+		return nil
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go aggregator.Run(ctx)
+	aggregator.Enqueue(queuedID)
+
+	select {
+	case notification := <-notified:
+		// Sessions summarized by the sweep are not reported
+		assert.Equal(t, queuedID.String(), notification.Data["sessionId"])
+		assert.Equal(t, userID, notification.UserID)
+		assert.Equal(t, models.NotificationSessionSummary, notification.Type)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the session summary notification")
+	}
+}
@@ -0,0 +1,196 @@
+// Package alerts evaluates ingested telemetry against users' speed and g-force alert rules.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/notify"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/sebasr/avt-service/internal/webhook"
+)
+
+// queueSize bounds the number of telemetry batches waiting for evaluation
+const queueSize = 256
+
+// batch is the telemetry of a single device and owner awaiting evaluation
+type batch struct {
+	userID   uuid.UUID
+	deviceID string
+	records  []*models.TelemetryData
+}
+
+// WebhookPayload is the JSON body POSTed to an alert rule's webhook URL
+type WebhookPayload struct {
+	RuleName string             `json:"ruleName"`
+	Event    *models.AlertEvent `json:"event"`
+}
+
+// Notifier stores a notification in its user's inbox and delivers it over the channels they chose
+type Notifier interface {
+	Notify(ctx context.Context, notification *models.Notification) error
+}
+
+// Evaluator checks device telemetry against its owner's active alert rules
+// Like geofence evaluation it runs in the background, so uploads never wait on rule lookups or
+// notification delivery.
+type Evaluator struct {
+	repo       repository.AlertRuleRepository
+	notifier   Notifier // Optional: nil only stores alerts and calls webhooks
+	httpClient *http.Client
+	queue      chan batch
+}
+
+// NewEvaluator creates a new alert rule evaluator
+func NewEvaluator(repo repository.AlertRuleRepository) *Evaluator {
+	return &Evaluator{
+		repo:       repo,
+		httpClient: webhook.NewClient(),
+		queue:      make(chan batch, queueSize),
+	}
+}
+
+// WithNotifier sets the notifier alerts are sent through
+func (e *Evaluator) WithNotifier(notifier Notifier) *Evaluator {
+	e.notifier = notifier
+	return e
+}
+
+// WithHTTPClient sets the HTTP client used for webhook delivery
+func (e *Evaluator) WithHTTPClient(client *http.Client) *Evaluator {
+	e.httpClient = client
+	return e
+}
+
+// Enqueue schedules saved telemetry for evaluation without blocking
+// Records without an owner or device and duplicates of stored records are skipped.
+// If the queue is full the batch is dropped.
+func (e *Evaluator) Enqueue(records []*models.TelemetryData) {
+	type key struct {
+		userID   uuid.UUID
+		deviceID string
+	}
+
+	groups := make(map[key]*batch)
+	var order []key
+	for _, record := range records {
+		if record.UserID == nil || record.DeviceID == "" || record.Duplicate {
+			continue
+		}
+		k := key{userID: *record.UserID, deviceID: record.DeviceID}
+		group, ok := groups[k]
+		if !ok {
+			group = &batch{userID: k.userID, deviceID: k.deviceID}
+			groups[k] = group
+			order = append(order, k)
+		}
+		group.records = append(group.records, record)
+	}
+
+	for _, k := range order {
+		select {
+		case e.queue <- *groups[k]:
+		default:
+			slog.Warn("Alert evaluation queue full, dropping records", "device_id", k.deviceID, "count", len(groups[k].records))
+		}
+	}
+}
+
+// Run evaluates queued telemetry until the context is cancelled
+func (e *Evaluator) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case b := <-e.queue:
+			if _, err := e.Evaluate(ctx, b.userID, b.deviceID, b.records); err != nil {
+				slog.Error("Error evaluating alert rules", "device_id", b.deviceID, "error", err)
+			}
+		}
+	}
+}
+
+// Evaluate replays a device's telemetry against its owner's active rules for the device, stores
+// the alerts raised and sends their notifications
+// A rule fires on the first record exceeding its threshold and then stays quiet for its cooldown.
+func (e *Evaluator) Evaluate(ctx context.Context, userID uuid.UUID, deviceID string, records []*models.TelemetryData) ([]*models.AlertEvent, error) {
+	rules, err := e.repo.ListActive(ctx, userID, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	if len(rules) == 0 || len(records) == 0 {
+		return nil, nil
+	}
+
+	sorted := make([]*models.TelemetryData, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var (
+		events []*models.AlertEvent
+		fired  = make(map[*models.AlertEvent]*models.AlertRule)
+	)
+	for _, record := range sorted {
+		for _, rule := range rules {
+			value, ok := rule.Metric.Value(record)
+			if !ok || value <= rule.Threshold || !rule.Ready(record.Timestamp) {
+				continue
+			}
+
+			recordedAt := record.Timestamp
+			rule.LastTriggeredAt = &recordedAt
+			event := &models.AlertEvent{
+				RuleID:     rule.ID,
+				UserID:     userID,
+				DeviceID:   deviceID,
+				SessionID:  record.SessionID,
+				Metric:     rule.Metric,
+				Threshold:  rule.Threshold,
+				Value:      value,
+				RecordedAt: recordedAt,
+			}
+			if record.GPS.IsFixValid {
+				latitude, longitude := record.GPS.Latitude, record.GPS.Longitude
+				event.Latitude, event.Longitude = &latitude, &longitude
+			}
+			events = append(events, event)
+			fired[event] = rule
+		}
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	if err := e.repo.RecordEvents(ctx, events); err != nil {
+		return nil, fmt.Errorf("failed to record alert events: %w", err)
+	}
+
+	for _, event := range events {
+		e.notify(ctx, fired[event], event)
+	}
+
+	return events, nil
+}
+
+// notify delivers the notification and webhook for an alert
+// Delivery failures are logged; the alert itself is already stored.
+func (e *Evaluator) notify(ctx context.Context, rule *models.AlertRule, event *models.AlertEvent) {
+	if e.notifier != nil {
+		if err := e.notifier.Notify(ctx, notify.ThresholdAlert(rule, event)); err != nil {
+			slog.Error("Error sending alert notification", "rule_id", rule.ID, "error", err)
+		}
+	}
+
+	if rule.WebhookURL != nil {
+		if err := webhook.Post(ctx, e.httpClient, *rule.WebhookURL, WebhookPayload{RuleName: rule.Name, Event: event}); err != nil {
+			slog.Error("Error delivering alert webhook", "rule_id", rule.ID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+// recordingNotifier captures the notifications sent through it
+type recordingNotifier struct {
+	notifications []*models.Notification
+	err           error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification *models.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return n.err
+}
+
+func speedRule(userID uuid.UUID, threshold float64, cooldown int) *models.AlertRule {
+	return &models.AlertRule{
+		ID:              uuid.New(),
+		UserID:          userID,
+		DeviceID:        "device-1",
+		Name:            "Speeding",
+		Metric:          models.AlertMetricSpeed,
+		Threshold:       threshold,
+		CooldownSeconds: cooldown,
+		IsActive:        true,
+	}
+}
+
+// telemetryAt returns a record with a valid fix at the given speed and lateral g-force
+func telemetryAt(userID uuid.UUID, elapsed time.Duration, speed, lateralG float64) *models.TelemetryData {
+	return &models.TelemetryData{
+		UserID:    &userID,
+		DeviceID:  "device-1",
+		Timestamp: start.Add(elapsed),
+		GPS:       models.GpsData{Latitude: 42.6977, Longitude: 23.3219, Speed: speed, IsFixValid: true},
+		Motion:    models.MotionData{GForceY: lateralG},
+	}
+}
+
+func TestEvaluator_FiresWithCooldown(t *testing.T) {
+	userID := uuid.New()
+	speeding := speedRule(userID, 180, 60)
+	cornering := &models.AlertRule{
+		ID: uuid.New(), UserID: userID, DeviceID: "device-1", Name: "Hard cornering",
+		Metric: models.AlertMetricLateralG, Threshold: 1.3, IsActive: true,
+	}
+
+	var recorded []*models.AlertEvent
+	repo := repository.NewMockAlertRuleRepository()
+	repo.ListActiveFunc = func(_ context.Context, gotUser uuid.UUID, deviceID string) ([]*models.AlertRule, error) {
+		assert.Equal(t, userID, gotUser)
+		assert.Equal(t, "device-1", deviceID)
+		return []*models.AlertRule{speeding, cornering}, nil
+	}
+	repo.RecordEventsFunc = func(_ context.Context, events []*models.AlertEvent) error {
+		recorded = events
+		return nil
+	}
+	notifier := &recordingNotifier{}
+	evaluator := NewEvaluator(repo).WithNotifier(notifier)
+
+	// Out of order on purpose: the evaluator replays records chronologically
+	records := []*models.TelemetryData{
+		telemetryAt(userID, 90*time.Second, 190, 0),    // Cooldown over: fires again
+		telemetryAt(userID, 0, 175, 0),                 // Below threshold
+		telemetryAt(userID, 10*time.Second, 185, 0),    // Fires
+		telemetryAt(userID, 20*time.Second, 200, -1.5), // Speed within cooldown; lateral g fires
+	}
+
+	events, err := evaluator.Evaluate(context.Background(), userID, "device-1", records)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, events, recorded)
+
+	assert.Equal(t, speeding.ID, events[0].RuleID)
+	assert.Equal(t, start.Add(10*time.Second), events[0].RecordedAt)
+	assert.Equal(t, 185.0, events[0].Value)
+	assert.Equal(t, 180.0, events[0].Threshold)
+	require.NotNil(t, events[0].Latitude)
+
+	assert.Equal(t, cornering.ID, events[1].RuleID)
+	assert.Equal(t, 1.5, events[1].Value, "g-force is compared by magnitude")
+
+	assert.Equal(t, speeding.ID, events[2].RuleID)
+	assert.Equal(t, start.Add(90*time.Second), events[2].RecordedAt)
+
+	require.Len(t, notifier.notifications, 3)
+	assert.Equal(t, models.NotificationThresholdAlert, notifier.notifications[0].Type)
+	assert.Equal(t, userID, notifier.notifications[0].UserID)
+	assert.Contains(t, notifier.notifications[0].Body, "185 km/h")
+	assert.Equal(t, speeding.ID.String(), notifier.notifications[0].Data["alertRuleId"])
+}
+
+func TestEvaluator_RespectsStoredCooldown(t *testing.T) {
+	userID := uuid.New()
+	rule := speedRule(userID, 180, 300)
+	lastTriggered := start
+	rule.LastTriggeredAt = &lastTriggered
+
+	repo := repository.NewMockAlertRuleRepository()
+	repo.ListActiveFunc = func(_ context.Context, _ uuid.UUID, _ string) ([]*models.AlertRule, error) {
+		return []*models.AlertRule{rule}, nil
+	}
+	repo.RecordEventsFunc = func(_ context.Context, _ []*models.AlertEvent) error {
+		t.Fatal("no alerts should be recorded")
+		return nil
+	}
+
+	events, err := NewEvaluator(repo).Evaluate(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, -time.Hour, 200, 0),    // Late upload from before the last alert
+		telemetryAt(userID, 4*time.Minute, 200, 0), // Within the cooldown
+	})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestEvaluator_SkipsSpeedWithoutFix(t *testing.T) {
+	userID := uuid.New()
+	repo := repository.NewMockAlertRuleRepository()
+	repo.ListActiveFunc = func(_ context.Context, _ uuid.UUID, _ string) ([]*models.AlertRule, error) {
+		return []*models.AlertRule{speedRule(userID, 180, 0)}, nil
+	}
+
+	record := telemetryAt(userID, 0, 250, 0)
+	record.GPS.IsFixValid = false
+
+	events, err := NewEvaluator(repo).Evaluate(context.Background(), userID, "device-1", []*models.TelemetryData{record})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestEvaluator_DeliversWebhook(t *testing.T) {
+	var payload WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	userID := uuid.New()
+	rule := speedRule(userID, 180, 0)
+	rule.WebhookURL = &server.URL
+	repo := repository.NewMockAlertRuleRepository()
+	repo.ListActiveFunc = func(_ context.Context, _ uuid.UUID, _ string) ([]*models.AlertRule, error) {
+		return []*models.AlertRule{rule}, nil
+	}
+
+	// Notification failures do not stop the webhook
+	notifier := &recordingNotifier{err: errors.New("inbox unavailable")}
+	events, err := NewEvaluator(repo).WithNotifier(notifier).WithHTTPClient(server.Client()).Evaluate(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 0, 200, 0),
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Speeding", payload.RuleName)
+	require.NotNil(t, payload.Event)
+	assert.Equal(t, 200.0, payload.Event.Value)
+}
+
+func TestEvaluator_RecordError(t *testing.T) {
+	userID := uuid.New()
+	repo := repository.NewMockAlertRuleRepository()
+	repo.ListActiveFunc = func(_ context.Context, _ uuid.UUID, _ string) ([]*models.AlertRule, error) {
+		return []*models.AlertRule{speedRule(userID, 180, 0)}, nil
+	}
+	repo.RecordEventsFunc = func(_ context.Context, _ []*models.AlertEvent) error {
+		return errors.New("database unavailable")
+	}
+	notifier := &recordingNotifier{}
+
+	_, err := NewEvaluator(repo).WithNotifier(notifier).Evaluate(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 0, 200, 0),
+	})
+	assert.Error(t, err)
+	assert.Empty(t, notifier.notifications, "alerts that were not stored are not sent")
+}
+
+func TestEvaluator_EnqueueSkipsUnownedAndDuplicates(t *testing.T) {
+	userID := uuid.New()
+	evaluator := NewEvaluator(repository.NewMockAlertRuleRepository())
+
+	owned := telemetryAt(userID, 0, 200, 0)
+	duplicate := telemetryAt(userID, time.Second, 200, 0)
+	duplicate.Duplicate = true
+	anonymous := telemetryAt(userID, 2*time.Second, 200, 0)
+	anonymous.UserID = nil
+
+	evaluator.Enqueue([]*models.TelemetryData{owned, duplicate, anonymous})
+
+	require.Len(t, evaluator.queue, 1)
+	queued := <-evaluator.queue
+	assert.Equal(t, []*models.TelemetryData{owned}, queued.records)
+}
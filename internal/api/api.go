@@ -0,0 +1,154 @@
+// Package api versions the JSON API.
+// Handlers are shared between versions and respond through the Serializer of the
+// request's version, which decides the shape of success and error bodies.
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// Version is a major version of the API
+type Version int
+
+// Supported API versions
+const (
+	V1 Version = 1 // Bare resources, list metadata beside the items, problem+json errors
+	V2 Version = 2 // Every body wrapped in the {data, meta, error} envelope
+)
+
+// Versions lists the versions the server mounts, oldest first
+var Versions = []Version{V1, V2}
+
+// serializerKey holds the request's Serializer, set by Middleware
+const serializerKey = "APISerializer"
+
+// String returns the version number
+func (v Version) String() string {
+	return strconv.Itoa(int(v))
+}
+
+// Path returns the route prefix of the version, e.g. "/api/v1"
+func (v Version) Path() string {
+	return "/api/v" + v.String()
+}
+
+// Serializer renders a version's response bodies
+type Serializer interface {
+	// Respond renders a single resource
+	Respond(c *gin.Context, status int, data any)
+
+	// List renders a collection under name, with its pagination metadata
+	List(c *gin.Context, status int, name string, items any, meta Meta)
+
+	// Problem renders an error body built by the problem package
+	Problem(c *gin.Context, status int, body map[string]any)
+}
+
+// Meta is the metadata of a response, e.g. a listing's total, limit, offset and nextCursor
+type Meta map[string]any
+
+// SerializerFor returns the serializer of a version, falling back to V1
+func SerializerFor(v Version) Serializer {
+	if v == V2 {
+		return v2Serializer{}
+	}
+	return v1Serializer{}
+}
+
+// VersionOf returns the version a request path addresses
+// Paths outside /api/vN, like the legacy /api/telemetry, are V1.
+func VersionOf(path string) Version {
+	for _, v := range Versions {
+		if path == v.Path() || strings.HasPrefix(path, v.Path()+"/") {
+			return v
+		}
+	}
+	return V1
+}
+
+// Middleware renders each response in the version its path addresses
+// It is global rather than per route group so that errors raised before routing,
+// like rate limiting and unknown routes, use the version's format as well.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serializer := SerializerFor(VersionOf(c.Request.URL.Path))
+		c.Set(serializerKey, serializer)
+		c.Set(problem.RendererKey, problem.Renderer(serializer.Problem))
+		c.Next()
+	}
+}
+
+// serializer returns the request's serializer, V1's when Middleware did not run
+func serializer(c *gin.Context) Serializer {
+	if s, ok := c.Value(serializerKey).(Serializer); ok {
+		return s
+	}
+	return v1Serializer{}
+}
+
+// Respond renders a single resource in the request's API version
+func Respond(c *gin.Context, status int, data any) {
+	serializer(c).Respond(c, status, data)
+}
+
+// List renders a collection in the request's API version
+func List(c *gin.Context, status int, name string, items any, meta Meta) {
+	serializer(c).List(c, status, name, items, meta)
+}
+
+// v1Serializer renders the original /api/v1 shapes
+type v1Serializer struct{}
+
+// Respond renders the resource as the body
+func (v1Serializer) Respond(c *gin.Context, status int, data any) {
+	c.JSON(status, data)
+}
+
+// List renders the items under name with the metadata beside them, e.g. {"devices": [...], "total": 3}
+func (v1Serializer) List(c *gin.Context, status int, name string, items any, meta Meta) {
+	body := make(gin.H, len(meta)+1)
+	for key, value := range meta {
+		body[key] = value
+	}
+	body[name] = items
+	c.JSON(status, body)
+}
+
+// Problem renders the error as application/problem+json
+func (v1Serializer) Problem(c *gin.Context, status int, body map[string]any) {
+	problem.Render(c, status, body)
+}
+
+// envelope is the body of every V2 response
+// Exactly one of Data and Error is set; Meta is always an object.
+type envelope struct {
+	Data  any            `json:"data"`
+	Meta  Meta           `json:"meta"`
+	Error map[string]any `json:"error"`
+}
+
+// v2Serializer renders the {data, meta, error} envelope
+type v2Serializer struct{}
+
+// Respond renders the resource as data
+func (v2Serializer) Respond(c *gin.Context, status int, data any) {
+	c.JSON(status, envelope{Data: data, Meta: Meta{}})
+}
+
+// List renders the items as data and the pagination metadata as meta
+func (v2Serializer) List(c *gin.Context, status int, name string, items any, meta Meta) {
+	if meta == nil {
+		meta = Meta{}
+	}
+	c.JSON(status, envelope{Data: items, Meta: meta})
+}
+
+// Problem renders the problem document as error
+// The envelope is plain application/json, so clients parse every V2 response the same way.
+func (v2Serializer) Problem(c *gin.Context, status int, body map[string]any) {
+	c.JSON(status, envelope{Meta: Meta{}, Error: body})
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRouter serves one resource, one listing and one error on every version
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware())
+	for _, version := range Versions {
+		routes := router.Group(version.Path())
+		routes.GET("/device", func(c *gin.Context) {
+			Respond(c, http.StatusOK, gin.H{"id": "abc"})
+		})
+		routes.GET("/devices", func(c *gin.Context) {
+			List(c, http.StatusOK, "devices", []string{"abc", "def"}, Meta{"total": 7, "limit": 2, "offset": 0})
+		})
+		routes.GET("/missing", func(c *gin.Context) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+		})
+	}
+	router.GET("/api/telemetry", func(c *gin.Context) {
+		Respond(c, http.StatusOK, gin.H{"id": "abc"})
+	})
+	return router
+}
+
+func get(t *testing.T, router *gin.Engine, path string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w, body
+}
+
+func TestVersionOf(t *testing.T) {
+	assert.Equal(t, V1, VersionOf("/api/v1/devices"))
+	assert.Equal(t, V2, VersionOf("/api/v2/devices"))
+	assert.Equal(t, V2, VersionOf("/api/v2"))
+	assert.Equal(t, V1, VersionOf("/api/v20/devices"))
+	assert.Equal(t, V1, VersionOf("/api/telemetry"))
+}
+
+func TestV1(t *testing.T) {
+	router := newTestRouter()
+
+	_, body := get(t, router, "/api/v1/device")
+	assert.Equal(t, map[string]any{"id": "abc"}, body)
+
+	_, body = get(t, router, "/api/v1/devices")
+	assert.Equal(t, []any{"abc", "def"}, body["devices"])
+	assert.Equal(t, float64(7), body["total"])
+	assert.Equal(t, float64(2), body["limit"])
+	assert.Equal(t, float64(0), body["offset"])
+
+	w, body := get(t, router, "/api/v1/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "device_not_found", body["code"])
+}
+
+func TestV2(t *testing.T) {
+	router := newTestRouter()
+
+	_, body := get(t, router, "/api/v2/device")
+	assert.Equal(t, map[string]any{"id": "abc"}, body["data"])
+	assert.Equal(t, map[string]any{}, body["meta"])
+	assert.Contains(t, body, "error")
+	assert.Nil(t, body["error"])
+
+	_, body = get(t, router, "/api/v2/devices")
+	assert.Equal(t, []any{"abc", "def"}, body["data"])
+	assert.Equal(t, map[string]any{"total": float64(7), "limit": float64(2), "offset": float64(0)}, body["meta"])
+
+	w, body := get(t, router, "/api/v2/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, body, "data")
+	assert.Nil(t, body["data"])
+	problemBody, ok := body["error"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "device_not_found", problemBody["code"])
+	assert.Equal(t, float64(http.StatusNotFound), problemBody["status"])
+	assert.Equal(t, "/api/v2/missing", problemBody["instance"])
+}
+
+func TestLegacyRoutesUseV1(t *testing.T) {
+	_, body := get(t, newTestRouter(), "/api/telemetry")
+	assert.Equal(t, map[string]any{"id": "abc"}, body)
+}
@@ -0,0 +1,195 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// sweepBatchSize caps the number of device days archived per sweep
+	sweepBatchSize = 50
+
+	// writeBatchSize is the number of rows encoded at a time
+	writeBatchSize = 1024
+
+	day = 24 * time.Hour
+)
+
+// Archiver periodically moves whole UTC days of old telemetry to Parquet files in every object
+// store and deletes them from the hypertable
+// Records are only deleted once every store holds a copy and the manifest is recorded.
+type Archiver struct {
+	repo     repository.ArchiveRepository
+	stores   []ObjectStore
+	after    time.Duration
+	interval time.Duration
+	prefix   string
+	now      func() time.Time
+}
+
+// NewArchiver creates a new archiver writing to stores
+func NewArchiver(repo repository.ArchiveRepository, stores []ObjectStore, cfg config.ArchiveConfig) *Archiver {
+	return &Archiver{
+		repo:     repo,
+		stores:   stores,
+		after:    cfg.After,
+		interval: cfg.Interval,
+		prefix:   cfg.Prefix,
+		now:      time.Now,
+	}
+}
+
+// Run archives old telemetry periodically until the context is cancelled
+func (a *Archiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	a.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.sweep(ctx)
+		}
+	}
+}
+
+// sweep archives the oldest device days that are entirely older than the archive age
+func (a *Archiver) sweep(ctx context.Context) {
+	before := a.now().UTC().Add(-a.after).Truncate(day)
+	chunks, err := a.repo.ListPendingChunks(ctx, before, sweepBatchSize)
+	if err != nil {
+		slog.Error("Error listing archivable telemetry", "error", err)
+		return
+	}
+
+	archived := 0
+	for _, chunk := range chunks {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := a.ArchiveChunk(ctx, chunk); err != nil {
+			slog.Error("Error archiving telemetry", "device_id", chunk.DeviceID, "day", chunk.Day.Format(time.DateOnly), "error", err)
+			continue
+		}
+		archived++
+	}
+
+	if archived > 0 {
+		slog.Info("Telemetry archival: archived device days", "count", archived)
+	}
+}
+
+// ArchiveChunk writes a device day to every store, then records the manifest and deletes the records
+// Returns a nil archive if the chunk no longer holds any records.
+func (a *Archiver) ArchiveChunk(ctx context.Context, chunk models.ArchiveChunk) (*models.TelemetryArchive, error) {
+	file, err := os.CreateTemp("", "avt-archive-*.parquet")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	archive, minID, err := a.write(ctx, chunk, file)
+	if err != nil || archive.RowCount == 0 {
+		return nil, err
+	}
+
+	archive.SizeBytes, err = file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to size archive: %w", err)
+	}
+	archive.ObjectKey = path.Join(
+		a.prefix, url.PathEscape(chunk.DeviceID), chunk.Day.Format(time.DateOnly),
+		fmt.Sprintf("%d-%d.parquet", minID, archive.MaxID),
+	)
+
+	for _, store := range a.stores {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind archive: %w", err)
+		}
+		if err := store.Put(ctx, archive.ObjectKey, file, archive.SizeBytes); err != nil {
+			return nil, err
+		}
+		archive.Regions = append(archive.Regions, store.Region())
+	}
+
+	if err := a.repo.Complete(ctx, archive); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// write encodes a chunk's records to w and returns the archive's manifest and lowest record ID
+func (a *Archiver) write(ctx context.Context, chunk models.ArchiveChunk, w io.Writer) (*models.TelemetryArchive, int64, error) {
+	it, err := a.repo.IterateChunk(ctx, chunk)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = it.Close() }()
+
+	archive := &models.TelemetryArchive{DeviceID: chunk.DeviceID, Day: chunk.Day}
+	writer := parquet.NewGenericWriter[archiveRow](w, parquet.Compression(&parquet.Zstd))
+	batch := make([]archiveRow, 0, writeBatchSize)
+	users := make(map[uuid.UUID]bool)
+	var minID int64
+
+	for it.Next() {
+		data := it.Telemetry()
+		row, err := toRow(data)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		batch = append(batch, row)
+		if len(batch) == writeBatchSize {
+			if _, err := writer.Write(batch); err != nil {
+				return nil, 0, fmt.Errorf("failed to write archive: %w", err)
+			}
+			batch = batch[:0]
+		}
+
+		if archive.RowCount == 0 {
+			archive.FirstRecordedAt = data.Timestamp
+			minID = data.ID
+		}
+		archive.RowCount++
+		archive.LastRecordedAt = data.Timestamp
+		archive.MaxID = max(archive.MaxID, data.ID)
+		minID = min(minID, data.ID)
+		if data.UserID != nil && !users[*data.UserID] {
+			users[*data.UserID] = true
+			archive.UserIDs = append(archive.UserIDs, *data.UserID)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read chunk telemetry: %w", err)
+	}
+
+	if _, err := writer.Write(batch); err != nil {
+		return nil, 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return archive, minID, nil
+}
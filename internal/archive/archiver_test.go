@@ -0,0 +1,191 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// memoryStore keeps archives in memory
+type memoryStore struct {
+	region string
+	err    error // Returned by every call when set
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore(region string) *memoryStore {
+	return &memoryStore{region: region, objects: make(map[string][]byte)}
+}
+
+func (s *memoryStore) Region() string { return s.region }
+
+func (s *memoryStore) Put(_ context.Context, key string, r io.Reader, size int64) error {
+	if s.err != nil {
+		return s.err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return errors.New("size mismatch")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memoryStore) Open(_ context.Context, key string) (Object, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return memoryObject{bytes.NewReader(data)}, nil
+}
+
+type memoryObject struct {
+	*bytes.Reader
+}
+
+func (memoryObject) Close() error { return nil }
+
+// sampleDay builds a day of telemetry for one device, alternating between two owners
+func sampleDay(day time.Time, owners ...uuid.UUID) []*models.TelemetryData {
+	sessionID := uuid.NewString()
+	rpm, gear := 6500.0, 3
+	records := make([]*models.TelemetryData, 6)
+	for i := range records {
+		owner := owners[i%len(owners)]
+		records[i] = &models.TelemetryData{
+			ID:        int64(100 + i),
+			Timestamp: day.Add(time.Duration(i) * time.Hour),
+			DeviceID:  "RACEBOX-001",
+			SessionID: &sessionID,
+			UserID:    &owner,
+			GPS:       models.GpsData{Latitude: 42.1, Longitude: 23.4, Speed: float64(100 + i), IsFixValid: true},
+		}
+	}
+	records[2].Vehicle = &models.VehicleData{RPM: &rpm, Gear: &gear}
+	records[3].Extras = map[string]interface{}{"wheelSpeed": 101.5}
+	records[4].QualityFlags = models.QualityImpossibleSpeed
+	return records
+}
+
+func TestArchiver_ArchiveChunk(t *testing.T) {
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	alice, bob := uuid.New(), uuid.New()
+	records := sampleDay(day, alice, bob)
+
+	repo := repository.NewMockArchiveRepository()
+	repo.IterateChunkFunc = func(_ context.Context, chunk models.ArchiveChunk) (repository.TelemetryIterator, error) {
+		assert.Equal(t, "RACEBOX-001", chunk.DeviceID)
+		return repository.NewSliceTelemetryIterator(records), nil
+	}
+	var completed *models.TelemetryArchive
+	repo.CompleteFunc = func(_ context.Context, archive *models.TelemetryArchive) error {
+		completed = archive
+		return nil
+	}
+
+	eu, us := newMemoryStore("eu-west-1"), newMemoryStore("us-east-1")
+	archiver := NewArchiver(repo, []ObjectStore{eu, us}, config.ArchiveConfig{Prefix: "telemetry"})
+
+	archive, err := archiver.ArchiveChunk(context.Background(), models.ArchiveChunk{DeviceID: "RACEBOX-001", Day: day})
+	require.NoError(t, err)
+	require.NotNil(t, completed)
+	assert.Same(t, archive, completed)
+
+	assert.Equal(t, "telemetry/RACEBOX-001/2026-01-05/100-105.parquet", archive.ObjectKey)
+	assert.Equal(t, []string{"eu-west-1", "us-east-1"}, archive.Regions)
+	assert.ElementsMatch(t, []uuid.UUID{alice, bob}, archive.UserIDs)
+	assert.Equal(t, int64(6), archive.RowCount)
+	assert.Equal(t, int64(105), archive.MaxID)
+	assert.Equal(t, records[0].Timestamp, archive.FirstRecordedAt)
+	assert.Equal(t, records[5].Timestamp, archive.LastRecordedAt)
+	assert.Equal(t, int64(len(eu.objects[archive.ObjectKey])), archive.SizeBytes)
+	assert.Equal(t, eu.objects[archive.ObjectKey], us.objects[archive.ObjectKey])
+
+	// The archive reads back unchanged
+	object, err := eu.Open(context.Background(), archive.ObjectKey)
+	require.NoError(t, err)
+	var restored []*models.TelemetryData
+	require.NoError(t, readRows(object, object.Size(), func(data *models.TelemetryData) bool {
+		restored = append(restored, data)
+		return true
+	}))
+	assert.Equal(t, records, restored)
+}
+
+func TestArchiver_ArchiveChunk_UploadFailureKeepsRecords(t *testing.T) {
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	records := sampleDay(day, uuid.New())
+
+	repo := repository.NewMockArchiveRepository()
+	repo.IterateChunkFunc = func(_ context.Context, _ models.ArchiveChunk) (repository.TelemetryIterator, error) {
+		return repository.NewSliceTelemetryIterator(records), nil
+	}
+	repo.CompleteFunc = func(_ context.Context, _ *models.TelemetryArchive) error {
+		t.Fatal("records should not be deleted unless every store holds a copy")
+		return nil
+	}
+
+	failing := newMemoryStore("us-east-1")
+	failing.err = errors.New("access denied")
+	archiver := NewArchiver(repo, []ObjectStore{newMemoryStore("eu-west-1"), failing}, config.ArchiveConfig{})
+
+	_, err := archiver.ArchiveChunk(context.Background(), models.ArchiveChunk{DeviceID: "RACEBOX-001", Day: day})
+	assert.Error(t, err)
+}
+
+func TestArchiver_ArchiveChunk_Empty(t *testing.T) {
+	repo := repository.NewMockArchiveRepository()
+	repo.CompleteFunc = func(_ context.Context, _ *models.TelemetryArchive) error {
+		t.Fatal("empty chunks should not be recorded")
+		return nil
+	}
+
+	store := newMemoryStore("eu-west-1")
+	archiver := NewArchiver(repo, []ObjectStore{store}, config.ArchiveConfig{})
+
+	archive, err := archiver.ArchiveChunk(context.Background(), models.ArchiveChunk{DeviceID: "RACEBOX-001", Day: time.Now()})
+	require.NoError(t, err)
+	assert.Nil(t, archive)
+	assert.Empty(t, store.objects)
+}
+
+func TestArchiver_SweepArchivesWholeDays(t *testing.T) {
+	now := time.Date(2026, 4, 10, 15, 30, 0, 0, time.UTC)
+
+	var before time.Time
+	repo := repository.NewMockArchiveRepository()
+	repo.ListPendingChunksFunc = func(_ context.Context, b time.Time, _ int) ([]models.ArchiveChunk, error) {
+		before = b
+		return []models.ArchiveChunk{}, nil
+	}
+
+	archiver := NewArchiver(repo, nil, config.ArchiveConfig{After: 30 * day})
+	archiver.now = func() time.Time { return now }
+	archiver.sweep(context.Background())
+
+	assert.Equal(t, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), before)
+}
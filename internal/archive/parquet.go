@@ -0,0 +1,257 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// readBatchSize is the number of rows decoded at a time
+const readBatchSize = 1024
+
+// archiveRow is the Parquet schema of archived telemetry, one column per telemetry column
+type archiveRow struct {
+	ID            int64     `parquet:"id"`
+	RecordedAt    time.Time `parquet:"recorded_at,timestamp(microsecond)"`
+	DeviceID      string    `parquet:"device_id,dict"`
+	SessionID     *string   `parquet:"session_id,optional,dict"`
+	UserID        string    `parquet:"user_id,dict"`
+	ClientID      *string   `parquet:"client_id,optional"`
+	SchemaVersion int32     `parquet:"schema_version"`
+	ITOW          int64     `parquet:"itow"`
+	TimeAccuracy  int64     `parquet:"time_accuracy"`
+	ValidityFlags int32     `parquet:"validity_flags"`
+	QualityFlags  int32     `parquet:"quality_flags"`
+
+	ReceivedAt       *time.Time `parquet:"received_at,optional"`
+	ClockSkewMs      *int64     `parquet:"clock_skew_ms,optional"`
+	DeviceRecordedAt *time.Time `parquet:"device_recorded_at,optional"`
+
+	Latitude           float64 `parquet:"latitude"`
+	Longitude          float64 `parquet:"longitude"`
+	WgsAltitude        float64 `parquet:"wgs_altitude"`
+	MslAltitude        float64 `parquet:"msl_altitude"`
+	Speed              float64 `parquet:"speed"`
+	Heading            float64 `parquet:"heading"`
+	NumSatellites      int32   `parquet:"num_satellites"`
+	FixStatus          int32   `parquet:"fix_status"`
+	IsFixValid         bool    `parquet:"is_fix_valid"`
+	HorizontalAccuracy float64 `parquet:"horizontal_accuracy"`
+	VerticalAccuracy   float64 `parquet:"vertical_accuracy"`
+	SpeedAccuracy      float64 `parquet:"speed_accuracy"`
+	HeadingAccuracy    float64 `parquet:"heading_accuracy"`
+	PDOP               float64 `parquet:"pdop"`
+
+	GForceX   float64 `parquet:"g_force_x"`
+	GForceY   float64 `parquet:"g_force_y"`
+	GForceZ   float64 `parquet:"g_force_z"`
+	RotationX float64 `parquet:"rotation_x"`
+	RotationY float64 `parquet:"rotation_y"`
+	RotationZ float64 `parquet:"rotation_z"`
+
+	Battery    float64 `parquet:"battery"`
+	IsCharging bool    `parquet:"is_charging"`
+
+	RPM           *float64 `parquet:"rpm,optional"`
+	Throttle      *float64 `parquet:"throttle,optional"`
+	BrakePressure *float64 `parquet:"brake_pressure,optional"`
+	CoolantTemp   *float64 `parquet:"coolant_temp,optional"`
+	Gear          *int32   `parquet:"gear,optional"`
+
+	Extras *string `parquet:"extras,optional"` // JSON object
+}
+
+// toRow converts a telemetry record to its archived form
+func toRow(data *models.TelemetryData) (archiveRow, error) {
+	row := archiveRow{
+		ID:            data.ID,
+		RecordedAt:    data.Timestamp,
+		DeviceID:      data.DeviceID,
+		SessionID:     data.SessionID,
+		SchemaVersion: int32(data.SchemaVersion),
+		ITOW:          data.ITOW,
+		TimeAccuracy:  data.TimeAccuracy,
+		ValidityFlags: int32(data.ValidityFlags),
+		QualityFlags:  int32(data.QualityFlags),
+
+		ReceivedAt:       data.ReceivedAt,
+		ClockSkewMs:      data.ClockSkewMs,
+		DeviceRecordedAt: data.DeviceTimestamp,
+
+		Latitude:           data.GPS.Latitude,
+		Longitude:          data.GPS.Longitude,
+		WgsAltitude:        data.GPS.WgsAltitude,
+		MslAltitude:        data.GPS.MslAltitude,
+		Speed:              data.GPS.Speed,
+		Heading:            data.GPS.Heading,
+		NumSatellites:      int32(data.GPS.NumSatellites),
+		FixStatus:          int32(data.GPS.FixStatus),
+		IsFixValid:         data.GPS.IsFixValid,
+		HorizontalAccuracy: data.GPS.HorizontalAccuracy,
+		VerticalAccuracy:   data.GPS.VerticalAccuracy,
+		SpeedAccuracy:      data.GPS.SpeedAccuracy,
+		HeadingAccuracy:    data.GPS.HeadingAccuracy,
+		PDOP:               data.GPS.PDOP,
+
+		GForceX:   data.Motion.GForceX,
+		GForceY:   data.Motion.GForceY,
+		GForceZ:   data.Motion.GForceZ,
+		RotationX: data.Motion.RotationX,
+		RotationY: data.Motion.RotationY,
+		RotationZ: data.Motion.RotationZ,
+
+		Battery:    data.Battery,
+		IsCharging: data.IsCharging,
+	}
+
+	if data.UserID != nil {
+		row.UserID = data.UserID.String()
+	}
+	if data.ClientID != nil {
+		clientID := data.ClientID.String()
+		row.ClientID = &clientID
+	}
+	if v := data.Vehicle; v != nil {
+		row.RPM, row.Throttle, row.BrakePressure, row.CoolantTemp = v.RPM, v.Throttle, v.BrakePressure, v.CoolantTemp
+		if v.Gear != nil {
+			gear := int32(*v.Gear)
+			row.Gear = &gear
+		}
+	}
+	if len(data.Extras) > 0 {
+		extras, err := json.Marshal(data.Extras)
+		if err != nil {
+			return row, fmt.Errorf("failed to encode extras of record %d: %w", data.ID, err)
+		}
+		encoded := string(extras)
+		row.Extras = &encoded
+	}
+
+	return row, nil
+}
+
+// fromRow converts an archived row back to a telemetry record
+func fromRow(row *archiveRow) (*models.TelemetryData, error) {
+	data := &models.TelemetryData{
+		ID:            row.ID,
+		Timestamp:     row.RecordedAt.UTC(),
+		DeviceID:      row.DeviceID,
+		SessionID:     row.SessionID,
+		SchemaVersion: int(row.SchemaVersion),
+		ITOW:          row.ITOW,
+		TimeAccuracy:  row.TimeAccuracy,
+		ValidityFlags: int(row.ValidityFlags),
+		QualityFlags:  int(row.QualityFlags),
+		ClockSkewMs:   row.ClockSkewMs,
+		GPS: models.GpsData{
+			Latitude:           row.Latitude,
+			Longitude:          row.Longitude,
+			WgsAltitude:        row.WgsAltitude,
+			MslAltitude:        row.MslAltitude,
+			Speed:              row.Speed,
+			Heading:            row.Heading,
+			NumSatellites:      int(row.NumSatellites),
+			FixStatus:          int(row.FixStatus),
+			IsFixValid:         row.IsFixValid,
+			HorizontalAccuracy: row.HorizontalAccuracy,
+			VerticalAccuracy:   row.VerticalAccuracy,
+			SpeedAccuracy:      row.SpeedAccuracy,
+			HeadingAccuracy:    row.HeadingAccuracy,
+			PDOP:               row.PDOP,
+		},
+		Motion: models.MotionData{
+			GForceX:   row.GForceX,
+			GForceY:   row.GForceY,
+			GForceZ:   row.GForceZ,
+			RotationX: row.RotationX,
+			RotationY: row.RotationY,
+			RotationZ: row.RotationZ,
+		},
+		Battery:    row.Battery,
+		IsCharging: row.IsCharging,
+	}
+
+	if row.ReceivedAt != nil {
+		receivedAt := row.ReceivedAt.UTC()
+		data.ReceivedAt = &receivedAt
+	}
+	if row.DeviceRecordedAt != nil {
+		deviceTimestamp := row.DeviceRecordedAt.UTC()
+		data.DeviceTimestamp = &deviceTimestamp
+	}
+
+	if row.UserID != "" {
+		userID, err := uuid.Parse(row.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID in archived record %d: %w", row.ID, err)
+		}
+		data.UserID = &userID
+	}
+	if row.ClientID != nil {
+		clientID, err := uuid.Parse(*row.ClientID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client ID in archived record %d: %w", row.ID, err)
+		}
+		data.ClientID = &clientID
+	}
+
+	vehicle := models.VehicleData{
+		RPM:           row.RPM,
+		Throttle:      row.Throttle,
+		BrakePressure: row.BrakePressure,
+		CoolantTemp:   row.CoolantTemp,
+	}
+	if row.Gear != nil {
+		gear := int(*row.Gear)
+		vehicle.Gear = &gear
+	}
+	if vehicle != (models.VehicleData{}) {
+		data.Vehicle = &vehicle
+	}
+
+	if row.Extras != nil {
+		if err := json.Unmarshal([]byte(*row.Extras), &data.Extras); err != nil {
+			return nil, fmt.Errorf("invalid extras in archived record %d: %w", row.ID, err)
+		}
+	}
+
+	return data, nil
+}
+
+// readRows decodes an archive file, calling fn for every record in file order until it returns false
+func readRows(r io.ReaderAt, size int64, fn func(*models.TelemetryData) bool) error {
+	file, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	reader := parquet.NewGenericReader[archiveRow](file)
+	defer func() { _ = reader.Close() }()
+
+	rows := make([]archiveRow, readBatchSize)
+	for {
+		n, err := reader.Read(rows)
+		for i := range n {
+			data, convErr := fromRow(&rows[i])
+			if convErr != nil {
+				return convErr
+			}
+			if !fn(data) {
+				return nil
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+	}
+}
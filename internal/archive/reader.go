@@ -0,0 +1,131 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Reader queries telemetry that has been moved to object storage
+type Reader struct {
+	repo   repository.ArchiveRepository
+	stores []ObjectStore
+}
+
+// NewReader creates a new archive reader
+// Each archive is read from the first store, in the order given, whose region holds a copy.
+func NewReader(repo repository.ArchiveRepository, stores []ObjectStore) *Reader {
+	return &Reader{
+		repo:   repo,
+		stores: stores,
+	}
+}
+
+// Query retrieves a user's archived telemetry matching the filter in chronological order
+// The filter's UserID, From and To are required. After and Processed are ignored.
+func (r *Reader) Query(ctx context.Context, filter repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+	if filter.From == nil || filter.To == nil {
+		return nil, errors.New("archived telemetry queries require from and to")
+	}
+
+	archives, err := r.repo.List(ctx, repository.ArchiveFilter{
+		UserID:   filter.UserID,
+		DeviceID: filter.DeviceID,
+		From:     *filter.From,
+		To:       *filter.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.TelemetryData, 0)
+	for _, archive := range archives {
+		// Archives are listed by their first record, so once the page is full none of the
+		// remaining ones can hold an earlier record
+		if filter.Limit > 0 && len(results) == filter.Limit &&
+			archive.FirstRecordedAt.After(results[len(results)-1].Timestamp) {
+			break
+		}
+
+		rows, err := r.read(ctx, archive, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, rows...)
+		sort.SliceStable(results, func(i, j int) bool {
+			if results[i].Timestamp.Equal(results[j].Timestamp) {
+				return results[i].ID < results[j].ID
+			}
+			return results[i].Timestamp.Before(results[j].Timestamp)
+		})
+		if filter.Limit > 0 && len(results) > filter.Limit {
+			results = results[:filter.Limit]
+		}
+	}
+
+	return results, nil
+}
+
+// read returns an archive's records matching the filter, trying each store holding a copy in turn
+func (r *Reader) read(ctx context.Context, archive *models.TelemetryArchive, filter repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+	var lastErr error
+	for _, store := range r.stores {
+		if !slices.Contains(archive.Regions, store.Region()) {
+			continue
+		}
+
+		rows, err := readMatching(ctx, store, archive, filter)
+		if err == nil {
+			return rows, nil
+		}
+		slog.Warn("Error reading telemetry archive", "key", archive.ObjectKey, "region", store.Region(), "error", err)
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("no configured store holds archive %s", archive.ObjectKey)
+	}
+	return nil, lastErr
+}
+
+// readMatching reads an archive's records matching the filter from one store
+func readMatching(ctx context.Context, store ObjectStore, archive *models.TelemetryArchive, filter repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+	object, err := store.Open(ctx, archive.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = object.Close() }()
+
+	var rows []*models.TelemetryData
+	err = readRows(object, object.Size(), func(data *models.TelemetryData) bool {
+		if matches(filter, data) {
+			rows = append(rows, data)
+		}
+		return !data.Timestamp.After(*filter.To)
+	})
+	return rows, err
+}
+
+// matches checks whether an archived record satisfies the filter
+func matches(filter repository.TelemetryFilter, data *models.TelemetryData) bool {
+	switch {
+	case data.UserID == nil || *data.UserID != filter.UserID:
+		return false
+	case filter.DeviceID != "" && data.DeviceID != filter.DeviceID:
+		return false
+	case filter.SessionID != "" && (data.SessionID == nil || *data.SessionID != filter.SessionID):
+		return false
+	case data.Timestamp.Before(*filter.From) || data.Timestamp.After(*filter.To):
+		return false
+	case filter.CleanOnly && data.QualityFlags != 0:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// archiveDays archives one sampleDay per day into the stores and returns the manifests
+func archiveDays(t *testing.T, stores []ObjectStore, owner uuid.UUID, days ...time.Time) []*models.TelemetryArchive {
+	t.Helper()
+
+	var archives []*models.TelemetryArchive
+	for i, d := range days {
+		records := sampleDay(d, owner)
+		for j, record := range records {
+			record.ID = int64(i*100 + j)
+		}
+
+		repo := repository.NewMockArchiveRepository()
+		repo.IterateChunkFunc = func(_ context.Context, _ models.ArchiveChunk) (repository.TelemetryIterator, error) {
+			return repository.NewSliceTelemetryIterator(records), nil
+		}
+
+		archive, err := NewArchiver(repo, stores, config.ArchiveConfig{}).
+			ArchiveChunk(context.Background(), models.ArchiveChunk{DeviceID: "RACEBOX-001", Day: d})
+		require.NoError(t, err)
+		archives = append(archives, archive)
+	}
+	return archives
+}
+
+func TestReader_Query(t *testing.T) {
+	owner := uuid.New()
+	day1 := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	store := newMemoryStore("eu-west-1")
+	archives := archiveDays(t, []ObjectStore{store}, owner, day1, day2)
+
+	var listed repository.ArchiveFilter
+	repo := repository.NewMockArchiveRepository()
+	repo.ListFunc = func(_ context.Context, filter repository.ArchiveFilter) ([]*models.TelemetryArchive, error) {
+		listed = filter
+		return archives, nil
+	}
+	reader := NewReader(repo, []ObjectStore{store})
+
+	from, to := day1.Add(3*time.Hour), day2.Add(2*time.Hour)
+	results, err := reader.Query(context.Background(), repository.TelemetryFilter{
+		UserID: owner, DeviceID: "RACEBOX-001", From: &from, To: &to,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.ArchiveFilter{UserID: owner, DeviceID: "RACEBOX-001", From: from, To: to}, listed)
+
+	// Hours 3-5 of the first day and 0-2 of the second, in order
+	require.Len(t, results, 6)
+	assert.Equal(t, from, results[0].Timestamp)
+	assert.Equal(t, to, results[5].Timestamp)
+	for i := 1; i < len(results); i++ {
+		assert.True(t, results[i].Timestamp.After(results[i-1].Timestamp))
+	}
+
+	// Limits stop at the earliest records; clean queries leave out flagged ones
+	results, err = reader.Query(context.Background(), repository.TelemetryFilter{
+		UserID: owner, From: &from, To: &to, Limit: 2, CleanOnly: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, day1.Add(3*time.Hour), results[0].Timestamp)
+	assert.Equal(t, day1.Add(5*time.Hour), results[1].Timestamp, "hour 4 is flagged")
+
+	// Records of other users sharing the archive are never returned
+	results, err = reader.Query(context.Background(), repository.TelemetryFilter{UserID: uuid.New(), From: &from, To: &to})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestReader_QueryFallsBackToOtherRegions(t *testing.T) {
+	owner := uuid.New()
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	eu, us := newMemoryStore("eu-west-1"), newMemoryStore("us-east-1")
+	archives := archiveDays(t, []ObjectStore{eu, us}, owner, day)
+
+	repo := repository.NewMockArchiveRepository()
+	repo.ListFunc = func(_ context.Context, _ repository.ArchiveFilter) ([]*models.TelemetryArchive, error) {
+		return archives, nil
+	}
+
+	from, to := day, day.Add(24*time.Hour)
+	eu.err = errors.New("region unavailable")
+	results, err := NewReader(repo, []ObjectStore{eu, us}).Query(context.Background(), repository.TelemetryFilter{
+		UserID: owner, From: &from, To: &to,
+	})
+	require.NoError(t, err)
+	assert.Len(t, results, 6)
+
+	us.err = errors.New("region unavailable")
+	_, err = NewReader(repo, []ObjectStore{eu, us}).Query(context.Background(), repository.TelemetryFilter{
+		UserID: owner, From: &from, To: &to,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+// Package archive moves old telemetry to Parquet files in S3-compatible storage and reads it back.
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+// parquetContentType is the media type archives are stored with
+const parquetContentType = "application/vnd.apache.parquet"
+
+// Object is an archive opened for random access
+type Object interface {
+	io.ReaderAt
+	io.Closer
+	Size() int64
+}
+
+// ObjectStore stores archive files in one bucket
+type ObjectStore interface {
+	// Region names the store in manifests
+	Region() string
+
+	// Put uploads size bytes from r under key
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+
+	// Open opens the object stored under key
+	Open(ctx context.Context, key string) (Object, error)
+}
+
+// S3Store stores archives in an S3 bucket
+type S3Store struct {
+	client *minio.Client
+	bucket config.ArchiveBucket
+}
+
+// NewS3Stores creates a store for every configured bucket
+func NewS3Stores(cfg config.ArchiveConfig) ([]ObjectStore, error) {
+	buckets, err := cfg.ParseBuckets()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.IAM{},
+	})
+	if cfg.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	}
+
+	stores := make([]ObjectStore, 0, len(buckets))
+	for _, bucket := range buckets {
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = "s3." + bucket.Region + ".amazonaws.com"
+		}
+
+		client, err := minio.New(endpoint, &minio.Options{
+			Creds:  creds,
+			Secure: cfg.UseSSL,
+			Region: bucket.Region,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 client for %s: %w", bucket.Region, err)
+		}
+		stores = append(stores, &S3Store{client: client, bucket: bucket})
+	}
+
+	return stores, nil
+}
+
+// Region implements ObjectStore.Region
+func (s *S3Store) Region() string {
+	return s.bucket.Region
+}
+
+// Put implements ObjectStore.Put
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, s.bucket.Name, key, r, size, minio.PutObjectOptions{
+		ContentType: parquetContentType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to %s: %w", key, s.bucket.Name, err)
+	}
+	return nil
+}
+
+// Open implements ObjectStore.Open
+func (s *S3Store) Open(ctx context.Context, key string) (Object, error) {
+	object, err := s.client.GetObject(ctx, s.bucket.Name, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s in %s: %w", key, s.bucket.Name, err)
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		_ = object.Close()
+		return nil, fmt.Errorf("failed to open %s in %s: %w", key, s.bucket.Name, err)
+	}
+
+	return &s3Object{Object: object, size: info.Size}, nil
+}
+
+// s3Object adds the size known from Stat to a minio object
+type s3Object struct {
+	*minio.Object
+	size int64
+}
+
+// Size implements Object.Size
+func (o *s3Object) Size() int64 {
+	return o.size
+}
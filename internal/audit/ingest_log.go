@@ -0,0 +1,100 @@
+// Package audit records ingest requests in the append-only audit log.
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// queueSize bounds the entries waiting to be written; entries beyond it are dropped
+	queueSize = 4096
+
+	// flushSize is the number of entries written per insert
+	flushSize = 200
+
+	// flushInterval is the maximum time an entry waits before being written
+	flushInterval = time.Second
+
+	// flushTimeout caps a single insert
+	flushTimeout = 10 * time.Second
+)
+
+// IngestLog writes ingest audit entries in the background
+// Entries are recorded from the ingest path without blocking and inserted in batches, so uploads
+// never wait on the audit log. If the database falls behind, entries are dropped rather than
+// slowing ingest down. Remaining entries are written on shutdown.
+type IngestLog struct {
+	repo  repository.IngestAuditRepository
+	queue chan *models.IngestAuditEntry
+}
+
+// NewIngestLog creates a new ingest audit log writer
+func NewIngestLog(repo repository.IngestAuditRepository) *IngestLog {
+	return &IngestLog{
+		repo:  repo,
+		queue: make(chan *models.IngestAuditEntry, queueSize),
+	}
+}
+
+// Record queues an entry for writing without blocking
+func (l *IngestLog) Record(entry *models.IngestAuditEntry) {
+	select {
+	case l.queue <- entry:
+	default:
+		slog.Warn("Ingest audit queue full, dropping entry", "source", entry.Source, "device_id", entry.DeviceID)
+	}
+}
+
+// Run writes queued entries until the context is cancelled, then writes the ones still queued
+func (l *IngestLog) Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*models.IngestAuditEntry, 0, flushSize)
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case entry := <-l.queue:
+					batch = append(batch, entry)
+					if len(batch) >= flushSize {
+						batch = l.flush(batch)
+					}
+				default:
+					l.flush(batch)
+					return
+				}
+			}
+		case entry := <-l.queue:
+			batch = append(batch, entry)
+			if len(batch) >= flushSize {
+				batch = l.flush(batch)
+			}
+		case <-ticker.C:
+			batch = l.flush(batch)
+		}
+	}
+}
+
+// flush writes the batch and returns it emptied
+// Writes run on their own context so the final flush still completes during shutdown.
+func (l *IngestLog) flush(batch []*models.IngestAuditEntry) []*models.IngestAuditEntry {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	if err := l.repo.InsertBatch(ctx, batch); err != nil {
+		slog.Error("Error writing ingest audit entries", "count", len(batch), "error", err)
+	}
+
+	clear(batch)
+	return batch[:0]
+}
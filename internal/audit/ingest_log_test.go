@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestLog(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		written []*models.IngestAuditEntry
+	)
+	repo := repository.NewMockIngestAuditRepository()
+	repo.InsertBatchFunc = func(_ context.Context, entries []*models.IngestAuditEntry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		written = append(written, entries...)
+		return nil
+	}
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(written)
+	}
+
+	log := NewIngestLog(repo)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		log.Run(ctx)
+		close(done)
+	}()
+
+	t.Run("writes recorded entries in the background", func(t *testing.T) {
+		log.Record(&models.IngestAuditEntry{Source: models.IngestSourceSingle, DeviceID: "DEV-1"})
+		assert.Eventually(t, func() bool { return count() == 1 }, 3*flushInterval, 10*time.Millisecond)
+	})
+
+	t.Run("writes queued entries on shutdown", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			log.Record(&models.IngestAuditEntry{Source: models.IngestSourceMQTT})
+		}
+		cancel()
+		<-done
+		require.Equal(t, 4, count())
+		assert.Equal(t, "DEV-1", written[0].DeviceID)
+	})
+}
+
+func TestIngestLog_RecordDropsWhenFull(t *testing.T) {
+	log := NewIngestLog(repository.NewMockIngestAuditRepository())
+	for i := 0; i < queueSize+10; i++ {
+		log.Record(&models.IngestAuditEntry{})
+	}
+	assert.Len(t, log.queue, queueSize)
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/cache"
+)
+
+// Denylist key prefixes
+const (
+	denylistTokenPrefix = "denylist:token:"
+	denylistUserPrefix  = "denylist:user:"
+)
+
+// Denylist rejects access tokens before they expire
+// Single tokens are revoked by their jti claim. Revoking a user rejects every access token issued
+// to them up to that moment, whichever client holds it. Entries only live as long as the tokens
+// they reject, so the store stays small. A nil Denylist revokes nothing.
+type Denylist struct {
+	store     cache.Cache
+	accessTTL time.Duration
+}
+
+// NewDenylist creates a denylist for access tokens that live for accessTTL, kept in store
+// An in-memory store forgets revocations on restart and is not shared between instances.
+func NewDenylist(store cache.Cache, accessTTL time.Duration) *Denylist {
+	return &Denylist{store: store, accessTTL: accessTTL}
+}
+
+// RevokeToken rejects a single access token until it expires
+func (d *Denylist) RevokeToken(ctx context.Context, claims *Claims) error {
+	if d == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return d.store.Set(ctx, denylistTokenPrefix+claims.ID, true, ttl)
+}
+
+// RevokeUser rejects every access token issued to the user so far
+// Token issue times have a resolution of one second, so a token issued in the same second as the
+// revocation is still accepted.
+func (d *Denylist) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	if d == nil {
+		return nil
+	}
+
+	// Tokens issued before the revocation have all expired once accessTTL has passed
+	return d.store.Set(ctx, denylistUserPrefix+userID.String(), time.Now().Unix(), d.accessTTL+time.Second)
+}
+
+// IsRevoked reports whether the access token has been revoked, individually or with all of its user's tokens
+func (d *Denylist) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	if d == nil {
+		return false, nil
+	}
+
+	if claims.ID != "" {
+		var revoked bool
+		found, err := d.store.Get(ctx, denylistTokenPrefix+claims.ID, &revoked)
+		if err != nil {
+			return false, err
+		}
+		if found {
+			return true, nil
+		}
+	}
+
+	var revokedAt int64
+	found, err := d.store.Get(ctx, denylistUserPrefix+claims.UserID, &revokedAt)
+	if err != nil || !found {
+		return false, err
+	}
+	return claims.IssuedAt == nil || claims.IssuedAt.Unix() < revokedAt, nil
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/cache"
+)
+
+// testClaims returns access token claims for the user issued at issuedAt
+func testClaims(userID uuid.UUID, issuedAt time.Time) *Claims {
+	return &Claims{
+		UserID: userID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(time.Hour)),
+		},
+	}
+}
+
+func TestDenylist_RevokeToken(t *testing.T) {
+	ctx := context.Background()
+	denylist := NewDenylist(cache.NewMemoryCache(), time.Hour)
+	userID := uuid.New()
+
+	revoked := testClaims(userID, time.Now())
+	other := testClaims(userID, time.Now())
+	require.NoError(t, denylist.RevokeToken(ctx, revoked))
+
+	isRevoked, err := denylist.IsRevoked(ctx, revoked)
+	require.NoError(t, err)
+	assert.True(t, isRevoked)
+
+	isRevoked, err = denylist.IsRevoked(ctx, other)
+	require.NoError(t, err)
+	assert.False(t, isRevoked, "other tokens of the user remain valid")
+}
+
+func TestDenylist_RevokeUser(t *testing.T) {
+	ctx := context.Background()
+	denylist := NewDenylist(cache.NewMemoryCache(), time.Hour)
+	userID := uuid.New()
+
+	issued := testClaims(userID, time.Now().Add(-time.Minute))
+	otherUser := testClaims(uuid.New(), time.Now().Add(-time.Minute))
+	require.NoError(t, denylist.RevokeUser(ctx, userID))
+
+	isRevoked, err := denylist.IsRevoked(ctx, issued)
+	require.NoError(t, err)
+	assert.True(t, isRevoked, "tokens issued before the revocation are rejected")
+
+	isRevoked, err = denylist.IsRevoked(ctx, otherUser)
+	require.NoError(t, err)
+	assert.False(t, isRevoked)
+
+	isRevoked, err = denylist.IsRevoked(ctx, testClaims(userID, time.Now().Add(time.Second)))
+	require.NoError(t, err)
+	assert.False(t, isRevoked, "tokens issued after logging in again are accepted")
+}
+
+func TestDenylist_Nil(t *testing.T) {
+	ctx := context.Background()
+	var denylist *Denylist
+	claims := testClaims(uuid.New(), time.Now())
+
+	require.NoError(t, denylist.RevokeToken(ctx, claims))
+	require.NoError(t, denylist.RevokeUser(ctx, uuid.New()))
+
+	isRevoked, err := denylist.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, isRevoked)
+}
@@ -1,8 +1,11 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,18 +19,31 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 	// ErrInvalidClaims is returned when the token claims are invalid
 	ErrInvalidClaims = errors.New("invalid token claims")
+	// ErrWrongTokenType is returned when a token is used for something it was not issued for
+	ErrWrongTokenType = errors.New("wrong token type")
+)
+
+// Token types carried in the typ claim
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
 )
 
 // Claims represents the JWT claims for authentication
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID string   `json:"user_id"`
+	Email  string   `json:"email"`
+	Role   string   `json:"role,omitempty"`   // Access tokens only; empty is treated as a regular user
+	Scopes []string `json:"scopes,omitempty"` // Access tokens only; empty grants the scopes of the role
+	Type   string   `json:"typ,omitempty"`    // TokenTypeAccess or TokenTypeRefresh
 	jwt.RegisteredClaims
 }
 
 // JWTService handles JWT token generation and validation
 type JWTService struct {
+	mu              sync.RWMutex
 	secret          []byte
+	previous        []byte // Secret before the last rotation; still accepted when validating
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
 }
@@ -41,13 +57,68 @@ func NewJWTService(secret string, accessTTL, refreshTTL time.Duration) *JWTServi
 	}
 }
 
-// GenerateAccessToken generates a new access token for a user
-func (s *JWTService) GenerateAccessToken(userID uuid.UUID, email string) (string, error) {
+// RotateSecret replaces the signing secret without a restart
+// Tokens signed with the previous secret stay valid until the following rotation, so sessions
+// survive a routine rotation; rotate twice to revoke every token signed with a leaked secret.
+func (s *JWTService) RotateSecret(secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if string(s.secret) == secret {
+		return
+	}
+	s.previous = s.secret
+	s.secret = []byte(secret)
+}
+
+// signingSecret returns the secret new tokens are signed with
+func (s *JWTService) signingSecret() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.secret
+}
+
+// verificationKey returns the key or keys tokens are validated against
+func (s *JWTService) verificationKey() interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.previous == nil {
+		return s.secret
+	}
+	return jwt.VerificationKeySet{Keys: []jwt.VerificationKey{s.secret, s.previous}}
+}
+
+// DeriveKeys returns the keys for purpose derived from the signing secret and, after a rotation,
+// from the previous one
+// The current key comes first. Values signed with a derived key, such as download links, stay
+// verifiable after RotateSecret until the following rotation, like tokens do.
+func (s *JWTService) DeriveKeys(purpose string) [][]byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := [][]byte{deriveKey(s.secret, purpose)}
+	if s.previous != nil {
+		keys = append(keys, deriveKey(s.previous, purpose))
+	}
+	return keys
+}
+
+// deriveKey derives the key for purpose from secret, independent of the tokens secret signs
+func deriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// GenerateAccessToken generates a new access token for a user with the given role and scopes
+func (s *JWTService) GenerateAccessToken(userID uuid.UUID, email, role string, scopes ...string) (string, error) {
 	now := time.Now()
 	claims := &Claims{
 		UserID: userID.String(),
 		Email:  email,
+		Role:   role,
+		Scopes: scopes,
+		Type:   TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(), // Unique JWT ID so the token can be revoked on its own
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
@@ -57,7 +128,7 @@ func (s *JWTService) GenerateAccessToken(userID uuid.UUID, email string) (string
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.secret)
+	tokenString, err := token.SignedString(s.signingSecret())
 	if err != nil {
 		return "", fmt.Errorf("failed to sign access token: %w", err)
 	}
@@ -68,12 +139,21 @@ func (s *JWTService) GenerateAccessToken(userID uuid.UUID, email string) (string
 // GenerateRefreshToken generates a new refresh token for a user
 // Returns the token string and its expiration time
 func (s *JWTService) GenerateRefreshToken(userID uuid.UUID, email string) (string, time.Time, error) {
-	now := time.Now()
-	expiresAt := now.Add(s.refreshTokenTTL)
+	expiresAt := time.Now().Add(s.refreshTokenTTL)
+	tokenString, err := s.GenerateRefreshTokenUntil(userID, email, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expiresAt, nil
+}
 
+// GenerateRefreshTokenUntil generates a new refresh token for a user that expires at expiresAt
+func (s *JWTService) GenerateRefreshTokenUntil(userID uuid.UUID, email string, expiresAt time.Time) (string, error) {
+	now := time.Now()
 	claims := &Claims{
 		UserID: userID.String(),
 		Email:  email,
+		Type:   TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.New().String(), // Unique JWT ID to prevent duplicate tokens
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
@@ -85,12 +165,12 @@ func (s *JWTService) GenerateRefreshToken(userID uuid.UUID, email string) (strin
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.secret)
+	tokenString, err := token.SignedString(s.signingSecret())
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to sign refresh token: %w", err)
+		return "", fmt.Errorf("failed to sign refresh token: %w", err)
 	}
 
-	return tokenString, expiresAt, nil
+	return tokenString, nil
 }
 
 // ValidateToken validates a JWT token and returns its claims
@@ -105,7 +185,7 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return s.secret, nil
+		return s.verificationKey(), nil
 	})
 
 	if err != nil {
@@ -129,6 +209,34 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// ValidateAccessToken validates a token presented as an access token
+// Refresh tokens and tokens issued before token types are rejected; the latter are short-lived
+// and clients replace them through the refresh endpoint.
+func (s *JWTService) ValidateAccessToken(tokenString string) (*Claims, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != TokenTypeAccess {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
+// ValidateRefreshToken validates a token presented as a refresh token
+// Refresh tokens issued before token types carry no typ claim and are still accepted, as every
+// refresh token is also looked up in the refresh token store.
+func (s *JWTService) ValidateRefreshToken(tokenString string) (*Claims, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != TokenTypeRefresh && claims.Type != "" {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
 // GetAccessTokenTTL returns the access token TTL
 func (s *JWTService) GetAccessTokenTTL() time.Duration {
 	return s.accessTokenTTL
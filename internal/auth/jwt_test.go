@@ -28,7 +28,7 @@ func TestGenerateAccessToken(t *testing.T) {
 	userID := uuid.New()
 	email := "test@example.com"
 
-	token, err := service.GenerateAccessToken(userID, email)
+	token, err := service.GenerateAccessToken(userID, email, "user")
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, token)
@@ -38,10 +38,33 @@ func TestGenerateAccessToken(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, userID.String(), claims.UserID)
 	assert.Equal(t, email, claims.Email)
+	assert.Equal(t, "user", claims.Role)
 	assert.Equal(t, "avt-service", claims.Issuer)
 	assert.Equal(t, userID.String(), claims.Subject)
 }
 
+func TestGenerateAccessToken_AdminRole(t *testing.T) {
+	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
+
+	token, err := service.GenerateAccessToken(uuid.New(), "admin@example.com", "admin")
+	require.NoError(t, err)
+
+	claims, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", claims.Role)
+}
+
+func TestGenerateAccessToken_Scopes(t *testing.T) {
+	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
+
+	token, err := service.GenerateAccessToken(uuid.New(), "test@example.com", "user", "telemetry:read", "devices:manage")
+	require.NoError(t, err)
+
+	claims, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"telemetry:read", "devices:manage"}, claims.Scopes)
+}
+
 func TestGenerateRefreshToken(t *testing.T) {
 	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
 	userID := uuid.New()
@@ -64,12 +87,34 @@ func TestGenerateRefreshToken(t *testing.T) {
 	assert.Equal(t, email, claims.Email)
 }
 
+func TestValidateTokenTypes(t *testing.T) {
+	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
+	userID := uuid.New()
+
+	access, err := service.GenerateAccessToken(userID, "test@example.com", "user")
+	require.NoError(t, err)
+	refresh, _, err := service.GenerateRefreshToken(userID, "test@example.com")
+	require.NoError(t, err)
+
+	claims, err := service.ValidateAccessToken(access)
+	require.NoError(t, err)
+	assert.Equal(t, TokenTypeAccess, claims.Type)
+	_, err = service.ValidateRefreshToken(access)
+	assert.ErrorIs(t, err, ErrWrongTokenType)
+
+	claims, err = service.ValidateRefreshToken(refresh)
+	require.NoError(t, err)
+	assert.Equal(t, TokenTypeRefresh, claims.Type)
+	_, err = service.ValidateAccessToken(refresh)
+	assert.ErrorIs(t, err, ErrWrongTokenType)
+}
+
 func TestValidateToken_ValidToken(t *testing.T) {
 	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
 	userID := uuid.New()
 	email := "test@example.com"
 
-	token, err := service.GenerateAccessToken(userID, email)
+	token, err := service.GenerateAccessToken(userID, email, "user")
 	require.NoError(t, err)
 
 	claims, err := service.ValidateToken(token)
@@ -105,7 +150,7 @@ func TestValidateToken_ExpiredToken(t *testing.T) {
 	userID := uuid.New()
 	email := "test@example.com"
 
-	token, err := service.GenerateAccessToken(userID, email)
+	token, err := service.GenerateAccessToken(userID, email, "user")
 	require.NoError(t, err)
 
 	claims, err := service.ValidateToken(token)
@@ -123,7 +168,7 @@ func TestValidateToken_WrongSecret(t *testing.T) {
 	email := "test@example.com"
 
 	// Generate token with service1
-	token, err := service1.GenerateAccessToken(userID, email)
+	token, err := service1.GenerateAccessToken(userID, email, "user")
 	require.NoError(t, err)
 
 	// Try to validate with service2 (different secret)
@@ -134,6 +179,53 @@ func TestValidateToken_WrongSecret(t *testing.T) {
 	assert.ErrorIs(t, err, ErrInvalidToken)
 }
 
+func TestRotateSecret(t *testing.T) {
+	service := NewJWTService("first-secret", time.Hour, 24*time.Hour)
+	userID := uuid.New()
+
+	first, err := service.GenerateAccessToken(userID, "test@example.com", "user")
+	require.NoError(t, err)
+
+	service.RotateSecret("second-secret")
+	second, err := service.GenerateAccessToken(userID, "test@example.com", "user")
+	require.NoError(t, err)
+
+	// New tokens use the new secret; tokens signed before the rotation remain valid
+	_, err = NewJWTService("second-secret", time.Hour, 24*time.Hour).ValidateToken(second)
+	assert.NoError(t, err)
+	_, err = service.ValidateToken(first)
+	assert.NoError(t, err)
+
+	// Rotating to the same secret keeps the previous one
+	service.RotateSecret("second-secret")
+	_, err = service.ValidateToken(first)
+	assert.NoError(t, err)
+
+	// A second rotation retires the first secret
+	service.RotateSecret("third-secret")
+	_, err = service.ValidateToken(first)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = service.ValidateToken(second)
+	assert.NoError(t, err)
+}
+
+func TestDeriveKeys(t *testing.T) {
+	service := NewJWTService("first-secret", time.Hour, 24*time.Hour)
+	first := service.DeriveKeys("purpose")
+	require.Len(t, first, 1)
+	assert.NotEqual(t, first[0], service.DeriveKeys("other")[0], "keys differ per purpose")
+
+	// The key derived before a rotation is kept until the following one
+	service.RotateSecret("second-secret")
+	keys := service.DeriveKeys("purpose")
+	require.Len(t, keys, 2)
+	assert.NotEqual(t, first[0], keys[0])
+	assert.Equal(t, first[0], keys[1])
+
+	service.RotateSecret("third-secret")
+	assert.NotContains(t, service.DeriveKeys("purpose"), first[0])
+}
+
 func TestValidateToken_InvalidSigningMethod(t *testing.T) {
 	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
 	userID := uuid.New()
@@ -190,13 +282,13 @@ func TestTokenUniqueness(t *testing.T) {
 	email := "test@example.com"
 
 	// Generate two access tokens for the same user
-	token1, err1 := service.GenerateAccessToken(userID, email)
+	token1, err1 := service.GenerateAccessToken(userID, email, "user")
 	require.NoError(t, err1)
 
 	// Sleep for more than 1 second to ensure different timestamps (JWT uses second precision)
 	time.Sleep(1100 * time.Millisecond)
 
-	token2, err2 := service.GenerateAccessToken(userID, email)
+	token2, err2 := service.GenerateAccessToken(userID, email, "user")
 	require.NoError(t, err2)
 
 	// Tokens should be different due to different issued-at times
@@ -232,7 +324,7 @@ func TestAccessAndRefreshTokenDifferentTTLs(t *testing.T) {
 	email := "test@example.com"
 
 	// Generate both token types
-	accessToken, err := service.GenerateAccessToken(userID, email)
+	accessToken, err := service.GenerateAccessToken(userID, email, "user")
 	require.NoError(t, err)
 
 	refreshToken, expiresAt, err := service.GenerateRefreshToken(userID, email)
@@ -259,7 +351,7 @@ func BenchmarkGenerateAccessToken(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = service.GenerateAccessToken(userID, email)
+		_, _ = service.GenerateAccessToken(userID, email, "user")
 	}
 }
 
@@ -278,7 +370,7 @@ func BenchmarkValidateToken(b *testing.B) {
 	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
 	userID := uuid.New()
 	email := "test@example.com"
-	token, _ := service.GenerateAccessToken(userID, email)
+	token, _ := service.GenerateAccessToken(userID, email, "user")
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+// minPasswordLength is the floor enforced by HashPassword regardless of configuration
+const minPasswordLength = 8
+
+// Password policy rules reported in violations
+const (
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RuleEntropy   = "min_entropy"
+	RuleCommon    = "common_password"
+	RuleBreached  = "breached"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// PolicyViolation describes a single password rule that was not met
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// BreachChecker reports how many times a password appears in known data breaches
+type BreachChecker interface {
+	BreachCount(ctx context.Context, password string) (int, error)
+}
+
+// PasswordPolicy validates new passwords against length, entropy, common password and breach rules
+type PasswordPolicy struct {
+	minLength      int
+	minEntropyBits float64
+	common         map[string]struct{} // Lowercased; nil disables the check
+	breachChecker  BreachChecker       // Optional: nil disables the breach check
+}
+
+// NewPasswordPolicy creates a password policy from configuration
+func NewPasswordPolicy(cfg config.PasswordPolicyConfig) *PasswordPolicy {
+	policy := &PasswordPolicy{
+		minLength:      max(cfg.MinLength, minPasswordLength),
+		minEntropyBits: cfg.MinEntropyBits,
+	}
+
+	if cfg.BlockCommon {
+		policy.common = make(map[string]struct{})
+		scanner := bufio.NewScanner(strings.NewReader(commonPasswordList))
+		for scanner.Scan() {
+			if word := strings.TrimSpace(scanner.Text()); word != "" && !strings.HasPrefix(word, "#") {
+				policy.common[strings.ToLower(word)] = struct{}{}
+			}
+		}
+	}
+
+	if cfg.CheckBreached {
+		policy.breachChecker = NewPwnedPasswordsClient(cfg.BreachAPIURL)
+	}
+
+	return policy
+}
+
+// WithBreachChecker sets the breach checker, replacing the configured one
+func (p *PasswordPolicy) WithBreachChecker(checker BreachChecker) *PasswordPolicy {
+	p.breachChecker = checker
+	return p
+}
+
+// Check returns every rule the password fails; an empty result means the password is acceptable
+// The breach check fails open: if the lookup errors the password is not rejected for it.
+func (p *PasswordPolicy) Check(ctx context.Context, password string) []PolicyViolation {
+	violations := []PolicyViolation{}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		violations = append(violations, PolicyViolation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("Password must be at least %d characters", p.minLength),
+		})
+	}
+	if len(password) > 72 {
+		violations = append(violations, PolicyViolation{
+			Rule:    RuleMaxLength,
+			Message: "Password must be at most 72 bytes",
+		})
+	}
+
+	if p.minEntropyBits > 0 && EstimateEntropy(password) < p.minEntropyBits {
+		violations = append(violations, PolicyViolation{
+			Rule:    RuleEntropy,
+			Message: "Password is too predictable; use a longer password or mix letters, digits and symbols",
+		})
+	}
+
+	if p.common != nil {
+		if _, ok := p.common[strings.ToLower(password)]; ok {
+			violations = append(violations, PolicyViolation{
+				Rule:    RuleCommon,
+				Message: "Password is too common",
+			})
+		}
+	}
+
+	if p.breachChecker != nil {
+		count, err := p.breachChecker.BreachCount(ctx, password)
+		if err != nil {
+			slog.Error("Error checking password against breach corpus", "error", err)
+		} else if count > 0 {
+			violations = append(violations, PolicyViolation{
+				Rule:    RuleBreached,
+				Message: fmt.Sprintf("Password has appeared in %d known data breaches", count),
+			})
+		}
+	}
+
+	return violations
+}
+
+// EstimateEntropy approximates the strength of a password in bits
+// Each character contributes log2 of the character pool it draws from; characters that
+// repeat the previous one or continue an ascending/descending run ("aaa", "123", "cba")
+// contribute a single bit, since they add little to an attacker's search space.
+func EstimateEntropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	if lower {
+		pool += 26
+	}
+	if upper {
+		pool += 26
+	}
+	if digit {
+		pool += 10
+	}
+	if symbol {
+		pool += 33
+	}
+	if other {
+		pool += 100
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	perChar := math.Log2(float64(pool))
+	bits := 0.0
+	var prev rune = -1
+	for _, r := range password {
+		if prev >= 0 && (r == prev || r == prev+1 || r == prev-1) {
+			bits++
+		} else {
+			bits += perChar
+		}
+		prev = r
+	}
+
+	return bits
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubBreachChecker returns a fixed breach count or error
+type stubBreachChecker struct {
+	count int
+	err   error
+}
+
+func (s stubBreachChecker) BreachCount(_ context.Context, _ string) (int, error) {
+	return s.count, s.err
+}
+
+func violatedRules(violations []PolicyViolation) []string {
+	rules := make([]string, len(violations))
+	for i, v := range violations {
+		rules[i] = v.Rule
+	}
+	return rules
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := NewPasswordPolicy(config.PasswordPolicyConfig{
+		MinLength:      10,
+		MinEntropyBits: 40,
+		BlockCommon:    true,
+	})
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"strong password", "Corner-Apex-Exit-7", []string{}},
+		{"too short", "K9#vx2Lq", []string{RuleMinLength}},
+		{"too long", strings.Repeat("Ab1!", 19), []string{RuleMaxLength}},
+		{"common password", "Password123", []string{RuleCommon}},
+		{"common and short", "password", []string{RuleMinLength, RuleEntropy, RuleCommon}},
+		{"repeated characters", "aaaaaaaaaaaaaaaa", []string{RuleEntropy}},
+		{"sequential digits", "12345678901234", []string{RuleEntropy}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := policy.Check(context.Background(), tt.password)
+			assert.Equal(t, tt.want, violatedRules(violations))
+			for _, v := range violations {
+				assert.NotEmpty(t, v.Message)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_MinLengthFloor(t *testing.T) {
+	policy := NewPasswordPolicy(config.PasswordPolicyConfig{MinLength: 4})
+
+	violations := policy.Check(context.Background(), "Kx9#vL2")
+	assert.Equal(t, []string{RuleMinLength}, violatedRules(violations))
+	assert.Contains(t, violations[0].Message, "at least 8 characters")
+}
+
+func TestPasswordPolicy_BreachCheck(t *testing.T) {
+	t.Run("breached password is rejected", func(t *testing.T) {
+		policy := NewPasswordPolicy(config.PasswordPolicyConfig{}).
+			WithBreachChecker(stubBreachChecker{count: 42})
+
+		violations := policy.Check(context.Background(), "Corner-Apex-Exit-7")
+		require.Len(t, violations, 1)
+		assert.Equal(t, RuleBreached, violations[0].Rule)
+		assert.Contains(t, violations[0].Message, "42")
+	})
+
+	t.Run("lookup errors fail open", func(t *testing.T) {
+		policy := NewPasswordPolicy(config.PasswordPolicyConfig{}).
+			WithBreachChecker(stubBreachChecker{err: errors.New("timeout")})
+
+		assert.Empty(t, policy.Check(context.Background(), "Corner-Apex-Exit-7"))
+	})
+}
+
+func TestEstimateEntropy(t *testing.T) {
+	assert.Zero(t, EstimateEntropy(""))
+	assert.Less(t, EstimateEntropy("aaaaaaaa"), EstimateEntropy("aqbzmxtr"))
+	assert.Less(t, EstimateEntropy("abcdefgh"), EstimateEntropy("aqbzmxtr"))
+	assert.Less(t, EstimateEntropy("aqbzmxtr"), EstimateEntropy("aBqZ3x!r"))
+	assert.InDelta(t, 8*4.7, EstimateEntropy("aqbzmxtr"), 0.01)
+}
+
+func TestPwnedPasswordsClient_BreachCount(t *testing.T) {
+	// SHA-1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	var requestedPath, padding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+		padding = r.Header.Get("Add-Padding")
+		_, _ = fmt.Fprint(w, "003D68EB55068C33ACE09247EE4C639306B:3\r\n"+
+			"1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n"+
+			"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:0\r\n")
+	}))
+	defer server.Close()
+
+	client := NewPwnedPasswordsClient(server.URL + "/")
+
+	count, err := client.BreachCount(context.Background(), "password")
+	require.NoError(t, err)
+	assert.Equal(t, 3861493, count)
+	assert.Equal(t, "/range/5BAA6", requestedPath, "only the hash prefix is sent")
+	assert.Equal(t, "true", padding)
+
+	count, err = client.BreachCount(context.Background(), "Corner-Apex-Exit-7")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestPwnedPasswordsClient_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewPwnedPasswordsClient(server.URL).BreachCount(context.Background(), "password")
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// SecretBoxKeyLength is the length in bytes of the key a SecretBox encrypts with (AES-256)
+const SecretBoxKeyLength = 32
+
+var (
+	// ErrInvalidSecretBoxKey is returned when a SecretBox key is not 32 base64-encoded bytes
+	ErrInvalidSecretBoxKey = errors.New("secret box key must be 32 base64-encoded bytes")
+	// ErrSecretDecryption is returned when a sealed secret is malformed or was sealed with another key
+	ErrSecretDecryption = errors.New("failed to decrypt secret")
+)
+
+// SecretBox encrypts secrets the server must read back later, unlike tokens it only compares hashes of
+// Secrets are sealed with AES-256-GCM under a random nonce and bound to associated data, such as the
+// ID of the row they are stored in, so a sealed value copied to another row does not open.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox creates a secret box from a base64-encoded 32-byte key
+func NewSecretBox(encodedKey string) (*SecretBox, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != SecretBoxKeyLength {
+		return nil, ErrInvalidSecretBoxKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal encrypts secret, returning the base64-encoded nonce and ciphertext
+func (b *SecretBox) Seal(secret string, associated []byte) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(secret), associated)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a secret sealed with the same key and associated data
+func (b *SecretBox) Open(sealed string, associated []byte) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", ErrSecretDecryption
+	}
+
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	secret, err := b.aead.Open(nil, nonce, ciphertext, associated)
+	if err != nil {
+		return "", ErrSecretDecryption
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSecretBox(t *testing.T) *SecretBox {
+	t.Helper()
+	key := make([]byte, SecretBoxKeyLength)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	box, err := NewSecretBox(base64.StdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+	return box
+}
+
+func TestNewSecretBox_InvalidKey(t *testing.T) {
+	for _, key := range []string{"", "not base64!", base64.StdEncoding.EncodeToString(make([]byte, 16))} {
+		_, err := NewSecretBox(key)
+		assert.ErrorIs(t, err, ErrInvalidSecretBoxKey, key)
+	}
+}
+
+func TestSecretBox_SealOpen(t *testing.T) {
+	box := newTestSecretBox(t)
+
+	sealed, err := box.Seal("avtds_secret", []byte("key-1"))
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "avtds_secret")
+
+	secret, err := box.Open(sealed, []byte("key-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "avtds_secret", secret)
+
+	again, err := box.Seal("avtds_secret", []byte("key-1"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "each seal uses a fresh nonce")
+}
+
+func TestSecretBox_OpenRejectsOtherKeyOrRow(t *testing.T) {
+	box := newTestSecretBox(t)
+	sealed, err := box.Seal("avtds_secret", []byte("key-1"))
+	require.NoError(t, err)
+
+	_, err = box.Open(sealed, []byte("key-2"))
+	assert.ErrorIs(t, err, ErrSecretDecryption, "a sealed secret is bound to its row")
+
+	_, err = newTestSecretBox(t).Open(sealed, []byte("key-1"))
+	assert.ErrorIs(t, err, ErrSecretDecryption)
+
+	_, err = box.Open("AAAA", []byte("key-1"))
+	assert.ErrorIs(t, err, ErrSecretDecryption)
+}
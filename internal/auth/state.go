@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// State identifies a state token and derives the values bound to it
+type State struct {
+	ID     string
+	secret []byte // Secret the token was signed with
+}
+
+// DeriveValue returns a URL-safe value derived from the state and a label
+// The same inputs give the same value, so a secret bound to a state token (e.g., a PKCE code
+// verifier) never has to leave the server. Values are derived from the secret the token was
+// signed with, so a flow started before RotateSecret still completes after it.
+func (st State) DeriveValue(label string) string {
+	mac := hmac.New(sha256.New, st.secret)
+	mac.Write([]byte(label + "\x00" + st.ID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateStateToken creates a short-lived signed token for a value that round-trips through a
+// third party, such as the OAuth state parameter
+// The audience keeps tokens of one flow from being accepted by another.
+func (s *JWTService) GenerateStateToken(audience string, ttl time.Duration) (string, State, error) {
+	now := time.Now()
+	claims := &jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		Issuer:    "avt-service",
+	}
+
+	secret := s.signingSecret()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(secret)
+	if err != nil {
+		return "", State{}, fmt.Errorf("failed to sign state token: %w", err)
+	}
+
+	return tokenString, State{ID: claims.ID, secret: secret}, nil
+}
+
+// ValidateStateToken validates a state token issued for audience
+// Tokens signed with the secret before the last rotation are accepted, and their values are
+// derived from that secret.
+func (s *JWTService) ValidateStateToken(tokenString, audience string) (State, error) {
+	if tokenString == "" {
+		return State{}, ErrInvalidToken
+	}
+
+	s.mu.RLock()
+	secrets := [][]byte{s.secret}
+	if s.previous != nil {
+		secrets = append(secrets, s.previous)
+	}
+	s.mu.RUnlock()
+
+	var (
+		claims jwt.RegisteredClaims
+		secret []byte
+		err    error
+	)
+	for _, secret = range secrets {
+		claims = jwt.RegisteredClaims{}
+		_, err = jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return secret, nil
+		}, jwt.WithAudience(audience), jwt.WithExpirationRequired())
+		if !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			break
+		}
+	}
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return State{}, ErrExpiredToken
+		}
+		return State{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.ID == "" {
+		return State{}, fmt.Errorf("%w: missing token ID", ErrInvalidClaims)
+	}
+
+	return State{ID: claims.ID, secret: secret}, nil
+}
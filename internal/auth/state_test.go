@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateToken(t *testing.T) {
+	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
+
+	token, state, err := service.GenerateStateToken("oidc", time.Minute)
+	require.NoError(t, err)
+	assert.NotEmpty(t, state.ID)
+
+	validated, err := service.ValidateStateToken(token, "oidc")
+	require.NoError(t, err)
+	assert.Equal(t, state.ID, validated.ID)
+
+	_, err = service.ValidateStateToken(token, "other-flow")
+	assert.ErrorIs(t, err, ErrInvalidToken, "tokens are bound to their audience")
+
+	_, err = service.ValidateToken(token)
+	assert.Error(t, err, "state tokens are not access tokens")
+
+	_, err = NewJWTService("other-secret", time.Hour, time.Hour).ValidateStateToken(token, "oidc")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestStateToken_Expired(t *testing.T) {
+	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
+
+	token, _, err := service.GenerateStateToken("oidc", -time.Minute)
+	require.NoError(t, err)
+
+	_, err = service.ValidateStateToken(token, "oidc")
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
+
+func TestStateToken_RotatedSecret(t *testing.T) {
+	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
+
+	token, state, err := service.GenerateStateToken("oidc", time.Minute)
+	require.NoError(t, err)
+
+	service.RotateSecret("new-secret")
+
+	validated, err := service.ValidateStateToken(token, "oidc")
+	require.NoError(t, err, "flows started before a rotation complete after it")
+	assert.Equal(t, state.ID, validated.ID)
+	assert.Equal(t, state.DeriveValue("pkce"), validated.DeriveValue("pkce"))
+
+	service.RotateSecret("newer-secret")
+
+	_, err = service.ValidateStateToken(token, "oidc")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestDeriveValue(t *testing.T) {
+	service := NewJWTService("test-secret", time.Hour, 24*time.Hour)
+
+	_, state, err := service.GenerateStateToken("oidc", time.Minute)
+	require.NoError(t, err)
+	_, other, err := service.GenerateStateToken("oidc", time.Minute)
+	require.NoError(t, err)
+
+	value := state.DeriveValue("pkce")
+	assert.Len(t, value, 43)
+	assert.Equal(t, value, state.DeriveValue("pkce"))
+	assert.NotEqual(t, value, state.DeriveValue("nonce"))
+	assert.NotEqual(t, value, other.DeriveValue("pkce"))
+	assert.NotEqual(t, value, State{ID: state.ID, secret: []byte("other-secret")}.DeriveValue("pkce"))
+}
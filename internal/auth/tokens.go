@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 const (
@@ -30,6 +31,12 @@ func HashToken(token string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// ClientFingerprint identifies the client a refresh token is issued to
+// It is the hex-encoded SHA256 hash of the User-Agent and the installation ID the app sends, which may be empty.
+func ClientFingerprint(userAgent, installationID string) string {
+	return HashToken(userAgent + "\x00" + installationID)
+}
+
 // GenerateSecureToken generates a cryptographically secure random token
 // Returns a base64 URL-encoded string of random bytes
 // Used for email verification tokens, password reset tokens, etc.
@@ -61,3 +68,90 @@ func VerifyTokenHash(token, hash string) bool {
 	computedHash := HashToken(token)
 	return computedHash == hash
 }
+
+// DeviceAPIKeyPrefix is prepended to every device API key so keys are easy to
+// recognise in logs and secret scanners
+const DeviceAPIKeyPrefix = "avtdk_"
+
+// deviceAPIKeyDisplayLength is the number of leading key characters kept for display
+const deviceAPIKeyDisplayLength = 12
+
+// GenerateDeviceAPIKey generates a new device API key
+// Returns the plain key (shown to the user once) and a short display prefix
+func GenerateDeviceAPIKey() (key string, displayPrefix string, err error) {
+	token, err := GenerateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	key = DeviceAPIKeyPrefix + token
+	return key, key[:deviceAPIKeyDisplayLength], nil
+}
+
+// DeviceSigningSecretPrefix is prepended to every device signing secret so it is not mistaken for an API key
+const DeviceSigningSecretPrefix = "avtds_"
+
+// GenerateDeviceSigningSecret generates the secret a device signs uploads with
+// Unlike the API key, the secret never travels with a request; it is shown to the user once.
+func GenerateDeviceSigningSecret() (string, error) {
+	token, err := GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+	return DeviceSigningSecretPrefix + token, nil
+}
+
+// PersonalAccessTokenPrefix is prepended to every personal access token so the auth middleware
+// can tell them from JWTs, and so they are easy to recognise in logs and secret scanners
+const PersonalAccessTokenPrefix = "avtpat_"
+
+// personalAccessTokenDisplayLength is the number of leading token characters kept for display
+const personalAccessTokenDisplayLength = 13
+
+// GeneratePersonalAccessToken generates a new personal access token
+// Returns the plain token (shown to the user once) and a short display prefix
+func GeneratePersonalAccessToken() (token string, displayPrefix string, err error) {
+	random, err := GenerateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	token = PersonalAccessTokenPrefix + random
+	return token, token[:personalAccessTokenDisplayLength], nil
+}
+
+// claimCodeAlphabet leaves out characters that are easily confused when read off a device screen
+const claimCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// claimCodeGroups and claimCodeGroupLength shape claim codes as XXXX-XXXX-XXXX-XXXX
+const (
+	claimCodeGroups      = 4
+	claimCodeGroupLength = 4
+)
+
+// GenerateDeviceClaimCode generates the code that proves possession of a pre-registered device
+// The code carries 80 bits of randomness and is formatted for reading aloud or typing.
+func GenerateDeviceClaimCode() (string, error) {
+	random := make([]byte, claimCodeGroups*claimCodeGroupLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+	}
+
+	var code strings.Builder
+	for i, b := range random {
+		if i > 0 && i%claimCodeGroupLength == 0 {
+			code.WriteByte('-')
+		}
+		// The alphabet has 32 characters, so the low five bits of each byte map uniformly onto it
+		code.WriteByte(claimCodeAlphabet[b&0x1f])
+	}
+	return code.String(), nil
+}
+
+// NormalizeDeviceClaimCode canonicalizes a claim code as typed by a user before it is hashed
+// Case, dashes and whitespace are ignored.
+func NormalizeDeviceClaimCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "", "\t", "").Replace(code)
+	return code
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"encoding/base64"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -86,6 +87,16 @@ func TestHashTokenUniqueness(t *testing.T) {
 	}
 }
 
+func TestClientFingerprint(t *testing.T) {
+	fingerprint := ClientFingerprint("AVT/2.1 (iOS 17.4)", "install-1")
+
+	assert.Len(t, fingerprint, 64)
+	assert.Equal(t, fingerprint, ClientFingerprint("AVT/2.1 (iOS 17.4)", "install-1"))
+	assert.NotEqual(t, fingerprint, ClientFingerprint("AVT/2.1 (iOS 17.4)", "install-2"))
+	assert.NotEqual(t, fingerprint, ClientFingerprint("curl/8.5.0", "install-1"))
+	assert.NotEqual(t, ClientFingerprint("ab", "c"), ClientFingerprint("a", "bc"), "the parts are kept apart")
+}
+
 func TestGenerateSecureToken(t *testing.T) {
 	token, err := GenerateSecureToken()
 
@@ -289,3 +300,58 @@ func BenchmarkVerifyTokenHash(b *testing.B) {
 		_ = VerifyTokenHash(token, hash)
 	}
 }
+
+func TestGenerateDeviceAPIKey(t *testing.T) {
+	key, prefix, err := GenerateDeviceAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, DeviceAPIKeyPrefix))
+	assert.True(t, strings.HasPrefix(key, prefix))
+	assert.Len(t, prefix, deviceAPIKeyDisplayLength)
+
+	other, _, err := GenerateDeviceAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestGenerateDeviceSigningSecret(t *testing.T) {
+	secret, err := GenerateDeviceSigningSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, DeviceSigningSecretPrefix))
+
+	other, err := GenerateDeviceSigningSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestGeneratePersonalAccessToken(t *testing.T) {
+	token, prefix, err := GeneratePersonalAccessToken()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(token, PersonalAccessTokenPrefix))
+	assert.True(t, strings.HasPrefix(token, prefix))
+	assert.Len(t, prefix, personalAccessTokenDisplayLength)
+
+	other, _, err := GeneratePersonalAccessToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestGenerateDeviceClaimCode(t *testing.T) {
+	code, err := GenerateDeviceClaimCode()
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^[A-HJ-NP-Z2-9]{4}(-[A-HJ-NP-Z2-9]{4}){3}$`, code)
+
+	other, err := GenerateDeviceClaimCode()
+	require.NoError(t, err)
+	assert.NotEqual(t, code, other)
+}
+
+func TestNormalizeDeviceClaimCode(t *testing.T) {
+	assert.Equal(t, "ABCD2345WXYZ6789", NormalizeDeviceClaimCode("abcd-2345 wxyz-6789"))
+	assert.Equal(t,
+		HashToken(NormalizeDeviceClaimCode("ABCD-2345-WXYZ-6789")),
+		HashToken(NormalizeDeviceClaimCode(" abcd2345wxyz6789 ")),
+	)
+}
@@ -0,0 +1,71 @@
+// Package cache provides a small TTL cache for hot repository queries.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Cache stores JSON-encoded values that expire after a TTL
+type Cache interface {
+	// Get decodes the value stored at key into dest, reporting whether it was found
+	Get(ctx context.Context, key string, dest interface{}) (bool, error)
+
+	// Set stores value at key; a zero ttl keeps it until it is deleted or evicted
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+
+	// Add stores value at key unless the key is already present, reporting whether it was stored
+	Add(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+
+	// Delete removes the given keys
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Namespace groups keys that are invalidated together, such as every page of a listing
+// Keys embed the namespace's current version, so invalidating it orphans all of them at once
+// and they expire on their own. A missing version starts at the current time, so a version
+// evicted from the cache never brings back keys from an older one.
+type Namespace struct {
+	cache Cache
+	name  string
+}
+
+// NewNamespace creates a namespace of keys stored in cache
+func NewNamespace(cache Cache, name string) Namespace {
+	return Namespace{cache: cache, name: name}
+}
+
+// Key returns the key for the given parts under the namespace's current version
+func (n Namespace) Key(ctx context.Context, parts ...string) (string, error) {
+	var version int64
+	found, err := n.cache.Get(ctx, n.versionKey(), &version)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		version = time.Now().UnixNano()
+		if err := n.cache.Set(ctx, n.versionKey(), version, 0); err != nil {
+			return "", err
+		}
+	}
+
+	key := n.name + ":" + strconv.FormatInt(version, 10)
+	for _, part := range parts {
+		key += ":" + part
+	}
+	return key, nil
+}
+
+// Invalidate moves the namespace to a new version
+func (n Namespace) Invalidate(ctx context.Context) error {
+	if err := n.cache.Set(ctx, n.versionKey(), time.Now().UnixNano(), 0); err != nil {
+		return fmt.Errorf("failed to invalidate %s: %w", n.name, err)
+	}
+	return nil
+}
+
+func (n Namespace) versionKey() string {
+	return n.name + ":version"
+}
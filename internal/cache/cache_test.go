@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	c := NewMemoryCache()
+	c.now = func() time.Time { return now }
+
+	type value struct {
+		Name string `json:"name"`
+	}
+
+	var got value
+	found, err := c.Get(ctx, "missing", &got)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, c.Set(ctx, "expiring", value{Name: "a"}, time.Minute))
+	require.NoError(t, c.Set(ctx, "forever", value{Name: "b"}, 0))
+
+	found, err = c.Get(ctx, "expiring", &got)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "a", got.Name)
+
+	now = now.Add(time.Minute)
+	found, _ = c.Get(ctx, "expiring", &got)
+	assert.False(t, found, "entries expire after their TTL")
+	found, _ = c.Get(ctx, "forever", &got)
+	assert.True(t, found, "entries without a TTL do not expire")
+
+	require.NoError(t, c.Delete(ctx, "forever"))
+	found, _ = c.Get(ctx, "forever", &got)
+	assert.False(t, found)
+
+	added, err := c.Add(ctx, "once", value{Name: "c"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, added)
+	added, err = c.Add(ctx, "once", value{Name: "d"}, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, added, "Add keeps an existing entry")
+	found, _ = c.Get(ctx, "once", &got)
+	assert.True(t, found)
+	assert.Equal(t, "c", got.Name)
+
+	now = now.Add(time.Minute)
+	added, _ = c.Add(ctx, "once", value{Name: "d"}, time.Minute)
+	assert.True(t, added, "Add replaces an expired entry")
+}
+
+func TestNamespace(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	devices := NewNamespace(c, "devices")
+
+	key, err := devices.Key(ctx, "user", "42")
+	require.NoError(t, err)
+	assert.Contains(t, key, "devices:")
+	assert.Contains(t, key, ":user:42")
+
+	same, err := devices.Key(ctx, "user", "42")
+	require.NoError(t, err)
+	assert.Equal(t, key, same, "keys are stable until the namespace is invalidated")
+
+	require.NoError(t, devices.Invalidate(ctx))
+	next, err := devices.Key(ctx, "user", "42")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, next)
+
+	other, err := NewNamespace(c, "sessions").Key(ctx, "user", "42")
+	require.NoError(t, err)
+	assert.NotEqual(t, next, other)
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// sweepInterval controls how often expired entries are evicted from memory
+const sweepInterval = time.Minute
+
+// entry is an encoded value and its expiry; a zero expiry never expires
+type entry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache is an in-process Cache suitable for single-instance deployments
+type MemoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryCache creates a new in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]entry),
+		now:     time.Now,
+	}
+}
+
+// Get implements Cache.Get
+func (c *MemoryCache) Get(_ context.Context, key string, dest interface{}) (bool, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && e.expired(c.now()) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(e.value, dest)
+}
+
+// Set implements Cache.Set
+func (c *MemoryCache) Set(_ context.Context, key string, value interface{}, ttl time.Duration) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.sweep(now)
+
+	e := entry{value: encoded}
+	if ttl > 0 {
+		e.expiresAt = now.Add(ttl)
+	}
+	c.entries[key] = e
+	return nil
+}
+
+// Add implements Cache.Add
+func (c *MemoryCache) Add(_ context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if e, ok := c.entries[key]; ok && !e.expired(now) {
+		return false, nil
+	}
+	c.sweep(now)
+
+	e := entry{value: encoded}
+	if ttl > 0 {
+		e.expiresAt = now.Add(ttl)
+	}
+	c.entries[key] = e
+	return true, nil
+}
+
+// Delete implements Cache.Delete
+func (c *MemoryCache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// sweep drops expired entries, at most once per sweepInterval
+func (c *MemoryCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < sweepInterval {
+		return
+	}
+	c.lastSweep = now
+
+	for key, e := range c.entries {
+		if e.expired(now) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
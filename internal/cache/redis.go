@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces cached values in Redis
+const redisKeyPrefix = "cache:"
+
+// RedisCache is a Cache backed by Redis, shared across service instances
+type RedisCache struct {
+	client redis.Cmdable
+}
+
+// NewRedisCache creates a new Redis-backed cache
+func NewRedisCache(client redis.Cmdable) *RedisCache {
+	return &RedisCache{client: client}
+}
+
+// Get implements Cache.Get
+func (c *RedisCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	value, err := c.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read cache: %w", err)
+	}
+	if err := json.Unmarshal(value, dest); err != nil {
+		return false, fmt.Errorf("failed to decode cached value: %w", err)
+	}
+	return true, nil
+}
+
+// Set implements Cache.Set
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cached value: %w", err)
+	}
+	if err := c.client.Set(ctx, redisKeyPrefix+key, encoded, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// Add implements Cache.Add
+func (c *RedisCache) Add(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode cached value: %w", err)
+	}
+	stored, err := c.client.SetNX(ctx, redisKeyPrefix+key, encoded, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to write cache: %w", err)
+	}
+	return stored, nil
+}
+
+// Delete implements Cache.Delete
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = redisKeyPrefix + key
+	}
+	if err := c.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("failed to delete cached values: %w", err)
+	}
+	return nil
+}
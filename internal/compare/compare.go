@@ -0,0 +1,155 @@
+// Package compare aligns two sessions' telemetry by distance travelled for overlay charts.
+package compare
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// MaxPoints caps the number of points in a comparison; the step is widened to stay within it
+const MaxPoints = 5000
+
+// sample is the time elapsed and speed at a distance along a trace
+type sample struct {
+	distance float64 // Meters from the start of the trace
+	elapsed  float64 // Seconds from the start of the trace
+	speed    float64 // km/h
+}
+
+// Trace records how far along its path a session was at each moment
+// Points without a valid GPS fix are skipped.
+type Trace struct {
+	start   time.Time
+	prev    *geo.Point
+	samples []sample
+}
+
+// NewTrace creates a trace measuring elapsed time from start
+// A zero start begins the trace at its first point.
+func NewTrace(start time.Time) *Trace {
+	return &Trace{start: start}
+}
+
+// Add appends the next telemetry point to the trace
+func (t *Trace) Add(telemetry *models.TelemetryData) {
+	if !telemetry.GPS.IsFixValid {
+		return
+	}
+	if t.start.IsZero() {
+		t.start = telemetry.Timestamp
+	}
+	if telemetry.Timestamp.Before(t.start) {
+		return
+	}
+
+	point := geo.Point{Latitude: telemetry.GPS.Latitude, Longitude: telemetry.GPS.Longitude}
+	distance := 0.0
+	if t.prev != nil {
+		distance = t.Distance() + geo.Distance(*t.prev, point)
+	}
+	t.prev = &point
+
+	t.samples = append(t.samples, sample{
+		distance: distance,
+		elapsed:  telemetry.Timestamp.Sub(t.start).Seconds(),
+		speed:    telemetry.GPS.Speed,
+	})
+}
+
+// Distance returns the distance covered by the trace in meters
+func (t *Trace) Distance() float64 {
+	if len(t.samples) == 0 {
+		return 0
+	}
+	return t.samples[len(t.samples)-1].distance
+}
+
+// Duration returns the time covered by the trace in seconds
+func (t *Trace) Duration() float64 {
+	if len(t.samples) == 0 {
+		return 0
+	}
+	return t.samples[len(t.samples)-1].elapsed
+}
+
+// at interpolates the elapsed time and speed when the trace first reached distance
+func (t *Trace) at(distance float64) (elapsed, speed float64) {
+	i := sort.Search(len(t.samples), func(i int) bool {
+		return t.samples[i].distance >= distance
+	})
+	if i == len(t.samples) {
+		i = len(t.samples) - 1
+	}
+	if i == 0 || t.samples[i].distance == distance {
+		return t.samples[i].elapsed, t.samples[i].speed
+	}
+
+	prev, next := t.samples[i-1], t.samples[i]
+	frac := (distance - prev.distance) / (next.distance - prev.distance)
+	return prev.elapsed + frac*(next.elapsed-prev.elapsed), prev.speed + frac*(next.speed-prev.speed)
+}
+
+// BuildTrace streams telemetry into a trace, keeping only points between from and to
+// A zero from starts the trace at the first point and a zero to runs it to the last.
+func BuildTrace(it repository.TelemetryIterator, from, to time.Time) (*Trace, error) {
+	trace := NewTrace(from)
+	for it.Next() {
+		telemetry := it.Telemetry()
+		if !to.IsZero() && telemetry.Timestamp.After(to) {
+			break
+		}
+		trace.Add(telemetry)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+// Point compares two traces at one distance from their starts
+type Point struct {
+	Distance  float64 `json:"distance"`  // Meters
+	TimeA     float64 `json:"timeA"`     // Seconds since the start of trace A
+	TimeB     float64 `json:"timeB"`     // Seconds since the start of trace B
+	DeltaTime float64 `json:"deltaTime"` // TimeB - TimeA; positive when B is behind
+	SpeedA    float64 `json:"speedA"`    // km/h
+	SpeedB    float64 `json:"speedB"`    // km/h
+	SpeedDiff float64 `json:"speedDiff"` // SpeedB - SpeedA
+}
+
+// Align samples both traces every step meters over the distance both of them covered
+// It returns the step actually used, which is widened when the traces would produce more than MaxPoints points.
+func Align(a, b *Trace, step float64) ([]Point, float64) {
+	if len(a.samples) == 0 || len(b.samples) == 0 {
+		return []Point{}, step
+	}
+
+	distance := math.Min(a.Distance(), b.Distance())
+	if distance/step >= MaxPoints {
+		step = distance / (MaxPoints - 1)
+	}
+
+	n := int(distance/step) + 1
+	points := make([]Point, 0, n)
+	for i := 0; i < n; i++ {
+		d := float64(i) * step
+		timeA, speedA := a.at(d)
+		timeB, speedB := b.at(d)
+		points = append(points, Point{
+			Distance:  d,
+			TimeA:     timeA,
+			TimeB:     timeB,
+			DeltaTime: timeB - timeA,
+			SpeedA:    speedA,
+			SpeedB:    speedB,
+			SpeedDiff: speedB - speedA,
+		})
+	}
+
+	return points, step
+}
@@ -0,0 +1,84 @@
+package compare
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	origin = geo.Point{Latitude: 42.6977, Longitude: 23.3219}
+	start  = time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+)
+
+// drive simulates a run due north at constant speed (km/h), sampled once per second
+func drive(speed float64, seconds int) []*models.TelemetryData {
+	points := make([]*models.TelemetryData, 0, seconds+1)
+	for i := 0; i <= seconds; i++ {
+		north := speed / 3.6 * float64(i)
+		points = append(points, &models.TelemetryData{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			GPS: models.GpsData{
+				Latitude:   origin.Latitude + north/geo.EarthRadius*180/math.Pi,
+				Longitude:  origin.Longitude,
+				Speed:      speed,
+				IsFixValid: true,
+			},
+		})
+	}
+	return points
+}
+
+func buildTrace(t *testing.T, telemetry []*models.TelemetryData, from, to time.Time) *Trace {
+	trace, err := BuildTrace(repository.NewSliceTelemetryIterator(telemetry), from, to)
+	require.NoError(t, err)
+	return trace
+}
+
+func TestAlign(t *testing.T) {
+	fast := buildTrace(t, drive(72, 60), time.Time{}, time.Time{}) // 20 m/s
+	slow := buildTrace(t, drive(36, 60), time.Time{}, time.Time{}) // 10 m/s
+
+	assert.InDelta(t, 1200, fast.Distance(), 0.5)
+	assert.InDelta(t, 600, slow.Distance(), 0.5)
+
+	points, step := Align(fast, slow, 100)
+	assert.Equal(t, 100.0, step)
+	require.Len(t, points, 6, "only the distance both traces covered is compared")
+
+	last := points[len(points)-1]
+	assert.Equal(t, 500.0, last.Distance)
+	assert.InDelta(t, 25, last.TimeA, 0.01)
+	assert.InDelta(t, 50, last.TimeB, 0.01)
+	assert.InDelta(t, 25, last.DeltaTime, 0.01)
+	assert.InDelta(t, -36, last.SpeedDiff, 0.01)
+}
+
+func TestAlign_WidensStep(t *testing.T) {
+	trace := buildTrace(t, drive(72, 600), time.Time{}, time.Time{})
+
+	points, step := Align(trace, trace, 0.5)
+	assert.LessOrEqual(t, len(points), MaxPoints)
+	assert.Greater(t, step, 0.5)
+	for _, p := range points {
+		assert.InDelta(t, 0, p.DeltaTime, 1e-9)
+	}
+}
+
+func TestBuildTrace_Window(t *testing.T) {
+	telemetry := drive(36, 60)
+	telemetry[5].GPS.IsFixValid = false
+
+	trace := buildTrace(t, telemetry, start.Add(10*time.Second), start.Add(20*time.Second))
+	assert.InDelta(t, 10, trace.Duration(), 0.001)
+	assert.InDelta(t, 100, trace.Distance(), 0.5)
+
+	points, _ := Align(trace, NewTrace(time.Time{}), 10)
+	assert.Empty(t, points)
+}
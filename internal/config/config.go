@@ -2,32 +2,111 @@
 package config
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"net/netip"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Auth     AuthConfig
-	Email    EmailConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Auth      AuthConfig
+	Secrets   SecretsConfig
+	Password  PasswordPolicyConfig
+	Lockout   LockoutConfig
+	Email     EmailConfig
+	Redis     RedisConfig
+	Cache     CacheConfig
+	RateLimit RateLimitConfig
+	Workers   WorkerConfig
+	MQTT      MQTTConfig
+	Storage   StorageConfig
+	Ingest    IngestConfig
+	Quota     QuotaConfig
+	CORS      CORSConfig
+	Security  SecurityHeadersConfig
+	Tracing   TracingConfig
+	Health    DeviceHealthConfig
+	Logging   LoggingConfig
+	GeoIP     GeoIPConfig
+	Push      PushConfig
+	Archive   ArchiveConfig
+	Reports   ReportConfig
+	Events    EventConfig
+	Webhooks  WebhookConfig
+	Stream    StreamConfig
+	OIDC      OIDCConfig
+	Reload    ReloadConfig
+
+	settings map[string]string // Raw value of every setting read, used to detect changes on reload
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	Port    string
-	DevMode bool // Enable development-only features (e.g., password reset UI)
+	Port              string
+	DevMode           bool  // Enable development-only features (e.g., password reset UI)
+	MaxBatchRecords   int   // Maximum records per batch upload; zero uses the built-in default of 1000
+	MaxBodyBytes      int64 // Maximum telemetry upload body size after decompression; zero disables the limit
+	LenientValidation bool  // Only require a timestamp and valid coordinates instead of range-checking every field
+
+	// TrustedProxies lists the IPs and CIDRs of the proxies in front of the service, whose
+	// ClientIPHeaders are trusted to carry the client's address. Empty trusts no proxy, so the
+	// client IP is the connection's remote address.
+	TrustedProxies  []string
+	ClientIPHeaders []string // Headers read for the client IP, in order, on requests from trusted proxies
 }
 
 // AuthConfig holds authentication-related configuration
 type AuthConfig struct {
 	JWTSecret          string
 	JWTAccessTokenTTL  time.Duration
-	JWTRefreshTokenTTL time.Duration
+	JWTRefreshTokenTTL time.Duration // Sliding refresh token lifetime; each refresh extends the session by this much
+	RememberMeTTL      time.Duration // Sliding refresh token lifetime for logins with rememberMe
+	SessionMaxAge      time.Duration // Absolute cap on a session from login, however often it is refreshed; zero disables
+	DenylistStore      string        // Revoked access token storage: "memory" (single instance) or "redis" (shared)
+
+	// RefreshTokenBinding binds refresh tokens to a fingerprint of the client they were issued to:
+	// "off", "optional" (only clients that send an installation ID) or "required" (every client)
+	RefreshTokenBinding string
+}
+
+// SecretsConfig holds the optional secret manager secrets are read from
+// A reference takes precedence over the plain setting (e.g. JWT_SECRET_REF over JWT_SECRET and JWT_SECRET_FILE).
+type SecretsConfig struct {
+	Provider        string        // none (the default), aws (AWS Secrets Manager) or vault (HashiCorp Vault KV v2)
+	AWSRegion       string        // Empty uses the region from the AWS environment or shared config
+	VaultAddress    string        // e.g. https://vault.example.com:8200
+	VaultToken      string        // Vault token with read access to the referenced secrets
+	VaultMount      string        // KV v2 mount path
+	JWTSecretRef    string        // Reference to the JWT signing secret
+	DBPasswordRef   string        // Reference to the database password
+	MailgunKeyRef   string        // Reference to the Mailgun API key
+	RefreshInterval time.Duration // How often the JWT secret is re-read to pick up rotations; zero disables
+}
+
+// PasswordPolicyConfig holds the rules new passwords must satisfy
+type PasswordPolicyConfig struct {
+	MinLength      int     // Minimum length in characters
+	MinEntropyBits float64 // Minimum estimated entropy; zero disables the check
+	BlockCommon    bool    // Reject passwords from the built-in common password list
+	CheckBreached  bool    // Reject passwords found in HaveIBeenPwned (k-anonymity range API)
+	BreachAPIURL   string  // Base URL of the Pwned Passwords range API
+}
+
+// LockoutConfig holds failed login tracking and temporary account lockout configuration
+type LockoutConfig struct {
+	Enabled          bool
+	MaxAttempts      int           // Failed logins within Window that lock the account
+	MaxAttemptsPerIP int           // Failed logins within Window after which an IP is throttled
+	Window           time.Duration // Sliding window failed attempts are counted over
+	Duration         time.Duration // How long an account stays locked
 }
 
 // EmailConfig holds email service configuration
@@ -39,10 +118,304 @@ type EmailConfig struct {
 	FromName      string        // Sender name
 	AppURL        string        // Frontend app URL for reset links
 	ResetTokenTTL time.Duration // Password reset token expiry
+	MagicLinkTTL  time.Duration // Passwordless sign-in link expiry
+}
+
+// RedisConfig holds Redis connection configuration
+type RedisConfig struct {
+	URL string // Redis connection URL (e.g., redis://localhost:6379/0); empty disables Redis
+}
+
+// CacheConfig holds query cache configuration
+type CacheConfig struct {
+	Enabled bool
+	Store   string        // Cache storage: "memory" (single instance) or "redis" (shared)
+	TTL     time.Duration // How long cached query results are served before they are re-read
+}
+
+// RateLimitConfig holds token bucket rate limiting configuration
+type RateLimitConfig struct {
+	Enabled                 bool
+	Store                   string // Bucket storage: "memory" (single instance) or "redis" (shared)
+	LoginPerMinute          int    // Per-IP login attempts refilled per minute
+	LoginBurst              int
+	ForgotPasswordPerMinute int // Per-IP password reset requests refilled per minute
+	ForgotPasswordBurst     int
+	IngestPerMinute         int // Per-user (or per-IP when anonymous) telemetry uploads refilled per minute
+	IngestBurst             int
+}
+
+// WorkerConfig holds background worker configuration
+type WorkerConfig struct {
+	SessionSummaryInterval time.Duration // How often ended sessions without a summary are aggregated
+	DeviceBackfillInterval time.Duration // How often adopted devices' anonymous history is assigned to their owner
+	SmoothingEnabled       bool          // Store a Kalman-smoothed copy of each ended session's telemetry
+	SmoothingInterval      time.Duration // How often ended sessions without smoothed telemetry are processed
+	TelemetryUndoWindow    time.Duration // How long trimmed or deleted telemetry can be restored before it is purged
+	TelemetryPurgeInterval time.Duration // How often telemetry past its undo window is purged
+
+	DerivedBackfillInterval time.Duration // How often telemetry stored without derived channels is backfilled; 0 disables
+}
+
+// MQTTConfig holds the optional MQTT ingestion bridge configuration
+type MQTTConfig struct {
+	Enabled   bool
+	BrokerURL string // Broker address (e.g., tcp://localhost:1883, ssl://broker:8883)
+	ClientID  string
+	Username  string
+	Password  string
+	Topic     string // Subscription filter; the device ID is the second topic level (avt/{deviceId}/telemetry)
+	QoS       int    // Subscription QoS: 0, 1 or 2
+}
+
+// StorageConfig holds TimescaleDB compression and retention policies for the telemetry and
+// ingest audit log hypertables
+// A zero duration disables the corresponding policy.
+type StorageConfig struct {
+	CompressAfter  time.Duration // Chunks older than this are compressed
+	RetainFor      time.Duration // Chunks older than this are dropped
+	AuditRetainFor time.Duration // Ingest audit log chunks older than this are dropped
+}
+
+// IngestConfig holds HTTP telemetry upload settings
+// When buffering is enabled, uploads are acknowledged once queued and written in batches by a background flusher.
+type IngestConfig struct {
+	StrictOwnership bool          // Reject anonymous uploads; every upload must carry a JWT or device API key
+	Audit           bool          // Record every upload request and MQTT message in the ingest audit log
+	Deduplicate     bool          // Skip records with the same device, iTOW and timestamp as a stored one (all ingest paths)
+	Buffered        bool          // Enable the write-behind buffer
+	BufferSize      int           // Maximum records waiting to be written; uploads beyond this are rejected
+	FlushSize       int           // Records per SaveBatch call
+	FlushInterval   time.Duration // Maximum time a record waits before being flushed
+	ShedThreshold   float64       // Buffer fill ratio at which uploads are rejected with 503; 0 waits until the buffer is full
+	MaxPoolWait     time.Duration // Average wait for a database connection at which uploads are rejected with 503; 0 disables
+
+	MinFirmwareVersion string // Oldest X-Firmware-Version uploads are accepted from without a warning; empty disables
+	RequireMinFirmware bool   // Reject uploads from older firmware with 426 instead of warning
+
+	ClockSkewPolicy    string        // Handling of skewed device clocks: "off", "flag" (quality flag) or "correct" (shift timestamps)
+	ClockSkewThreshold time.Duration // Skew beyond which records are flagged or corrected
+
+	SignatureMaxAge     time.Duration // How far X-Signature-Timestamp may be from the server clock; zero uses 5m
+	SignatureNonceStore string        // Used signature nonce storage: "memory" (single instance) or "redis" (shared)
+	SigningSecretKey    string        // Base64 32-byte key encrypting device signing secrets at rest; empty disables signed uploads
+
+	DerivedChannels bool // Store total g, speed delta, vertical speed and distance increment with every record
+}
+
+// QuotaConfig holds per-plan usage limits; a zero limit means unlimited
+type QuotaConfig struct {
+	Enabled bool
+	Free    PlanQuotaConfig
+	Pro     PlanQuotaConfig
+}
+
+// PlanQuotaConfig holds a single plan's usage limits
+type PlanQuotaConfig struct {
+	TelemetryPointsPerMonth int // Telemetry points a user may store per calendar month (UTC)
+	MaxDevices              int // Active devices a user may own
+}
+
+// CORSConfig holds cross-origin access rules for browser clients
+// An empty origin list disables CORS headers, so browsers only allow same-origin calls.
+type CORSConfig struct {
+	AllowedOrigins   []string      // Origins allowed to call the API; "*" allows any origin
+	AllowedMethods   []string      // Methods allowed in cross-origin requests
+	AllowedHeaders   []string      // Request headers browsers may send
+	AllowCredentials bool          // Allow cookies and HTTP authentication; requires explicit origins
+	MaxAge           time.Duration // How long browsers may cache preflight responses
+}
+
+// SecurityHeadersConfig holds the security headers added to every response
+// An empty value omits the corresponding header.
+type SecurityHeadersConfig struct {
+	Enabled               bool
+	HSTSMaxAge            time.Duration // Strict-Transport-Security max-age; zero omits the header
+	HSTSIncludeSubdomains bool
+	FrameOptions          string // X-Frame-Options (e.g., DENY, SAMEORIGIN)
+	ReferrerPolicy        string
+	ContentSecurityPolicy string
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration
+// Spans are exported over OTLP/HTTP; when disabled, instrumentation records nothing.
+type TracingConfig struct {
+	Enabled     bool
+	Endpoint    string  // OTLP/HTTP collector address (host:port)
+	Insecure    bool    // Export over plain HTTP instead of HTTPS
+	ServiceName string  // service.name resource attribute
+	SampleRatio float64 // Fraction of new traces recorded; spans follow their parent's decision
+}
+
+// LoggingConfig holds structured logging settings
+type LoggingConfig struct {
+	Level  string // Minimum level logged: debug, info (the default), warn or error
+	Format string // Output format: text (the default) or json
+
+	AccessLog        bool    // Log every HTTP request
+	AccessSampleRate float64 // Fraction of successful requests logged; failed requests are always logged
+}
+
+// GeoIPConfig holds IP geolocation settings
+// Locations are shown on login sessions and trigger an email when a user signs in from a new country.
+type GeoIPConfig struct {
+	Provider     string // none (the default) or csv
+	DatabasePath string // CSV network database read when Provider is csv
+}
+
+// PushConfig holds mobile push notification credentials
+// Each provider is enabled by configuring its signing key; without either, push preferences are
+// stored but nothing is sent.
+type PushConfig struct {
+	FCMCredentials string // Firebase service account JSON key
+	FCMProjectID   string // Empty uses the project of the service account
+	APNsKey        string // .p8 token signing key (PEM)
+	APNsKeyID      string
+	APNsTeamID     string
+	APNsTopic      string // App bundle ID
+	APNsSandbox    bool   // Use the development APNs environment
+}
+
+// FCMEnabled reports whether Firebase Cloud Messaging is configured
+func (c PushConfig) FCMEnabled() bool {
+	return c.FCMCredentials != ""
+}
+
+// APNsEnabled reports whether the Apple Push Notification service is configured
+func (c PushConfig) APNsEnabled() bool {
+	return c.APNsKey != ""
+}
+
+// ArchiveConfig holds settings for moving old telemetry to Parquet files in S3-compatible storage
+// Every archive is written to all buckets, so a copy survives the loss of a region.
+type ArchiveConfig struct {
+	Enabled         bool
+	After           time.Duration // Whole UTC days of telemetry older than this are archived
+	Interval        time.Duration // How often archivable days are looked for
+	Endpoint        string        // S3-compatible endpoint; empty uses AWS S3 in each bucket's region
+	UseSSL          bool
+	Buckets         []string // region=bucket pairs
+	Prefix          string   // Object key prefix
+	AccessKeyID     string   // Empty uses the AWS environment variables or the instance role
+	SecretAccessKey string
+}
+
+// ArchiveBucket is one bucket archives are written to
+type ArchiveBucket struct {
+	Region string
+	Name   string
+}
+
+// ParseBuckets parses the region=bucket pairs of Buckets
+func (c ArchiveConfig) ParseBuckets() ([]ArchiveBucket, error) {
+	buckets := make([]ArchiveBucket, 0, len(c.Buckets))
+	for _, pair := range c.Buckets {
+		region, name, ok := strings.Cut(pair, "=")
+		region, name = strings.TrimSpace(region), strings.TrimSpace(name)
+		if !ok || region == "" || name == "" {
+			return nil, fmt.Errorf("invalid ARCHIVE_S3_BUCKETS entry %q (must be region=bucket)", pair)
+		}
+		buckets = append(buckets, ArchiveBucket{Region: region, Name: name})
+	}
+	return buckets, nil
+}
+
+// ReportConfig holds fleet report settings
+type ReportConfig struct {
+	RetainFor     time.Duration // How long generated reports can be downloaded before they are deleted; zero uses 7 days
+	URLTTL        time.Duration // How long each signed download URL is valid; zero uses 15 minutes
+	SweepInterval time.Duration // How often expired reports are deleted
+}
+
+// EventConfig holds the domain event outbox settings
+type EventConfig struct {
+	PollInterval time.Duration // How often the outbox is checked for events due for delivery or retry; zero uses 10 seconds
+	RetainFor    time.Duration // How long delivered events are kept; zero keeps them forever
+	WebhookURL   string        // Optional URL every domain event is POSTed to
+}
+
+// WebhookConfig holds settings for webhook deliveries to user-configured URLs
+type WebhookConfig struct {
+	// AllowedNetworks lists the IPs and CIDRs of internal receivers webhooks may be delivered to
+	// Loopback, private and link-local addresses are refused unless they are listed.
+	AllowedNetworks []string
+}
+
+// StreamConfig holds settings for forwarding accepted telemetry to a message broker
+type StreamConfig struct {
+	Driver string // kafka or nats; empty disables streaming
+	URL    string // Kafka REST Proxy URL (http:// or https://) or NATS server URL (nats:// or tls://)
+	Topic  string // Kafka topic or NATS subject telemetry is published to
+}
+
+// Enabled reports whether telemetry is forwarded to a message broker
+func (c StreamConfig) Enabled() bool {
+	return c.Driver != ""
+}
+
+// OIDCConfig holds the OpenID Connect provider users can sign in with (e.g. Okta or Azure AD)
+// The provider's endpoints and signing keys are discovered from IssuerURL.
+type OIDCConfig struct {
+	IssuerURL    string // Empty disables single sign-on
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // Client URL the provider redirects to after sign-in; must be registered with the provider
+	Scopes       []string // Requested scopes; must include openid
+	GroupsClaim  string   // ID token claim listing the user's groups
+
+	// RoleMapping lists group=role pairs. When set, every sign-in assigns the admin role if any of the
+	// user's groups maps to it and the user role otherwise; when empty, roles are managed in the service.
+	RoleMapping []string
+}
+
+// Enabled reports whether single sign-on is configured
+func (c OIDCConfig) Enabled() bool {
+	return c.IssuerURL != ""
+}
+
+// ParseRoleMapping parses the group=role pairs of RoleMapping
+func (c OIDCConfig) ParseRoleMapping() (map[string]models.Role, error) {
+	roles := make(map[string]models.Role, len(c.RoleMapping))
+	for _, pair := range c.RoleMapping {
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || !models.Role(role).IsValid() {
+			return nil, fmt.Errorf("invalid OIDC_ROLE_MAPPING entry %q (must be group=user or group=admin)", pair)
+		}
+		roles[group] = models.Role(role)
+	}
+	return roles, nil
+}
+
+// ReloadConfig holds where settings are read from and whether they are reloaded while running
+// The process environment cannot change after startup, so reloads re-read File.
+type ReloadConfig struct {
+	File     string // Optional KEY=VALUE file read beneath the environment; environment variables take precedence
+	OnSIGHUP bool   // Re-read configuration on SIGHUP and apply the settings that do not need a restart
+}
+
+// DeviceHealthConfig holds device health monitoring settings
+// Battery levels are compared as percentages; devices reporting input voltage (RaceBox Micro)
+// should not be used with low-battery alerts.
+type DeviceHealthConfig struct {
+	Enabled           bool
+	LowBatteryPercent float64       // Battery level at or below which a low_battery event is emitted; zero disables
+	NoFixAfter        time.Duration // Time without a valid GPS fix before a no_fix event is emitted; zero disables
+	SnapshotInterval  time.Duration // Width of the stored health snapshot buckets
+	NotifyEmail       bool          // Email device owners about health events (requires the email service)
+	WebhookURL        string        // Optional URL every health event is POSTed to
 }
 
+// defaultJWTSecret signs tokens when JWT_SECRET is unset; only fit for development
+const defaultJWTSecret = "dev-secret-key-change-in-production"
+
+// minTelemetryRetention keeps raw data around until every continuous aggregate has been refreshed
+const minTelemetryRetention = 7 * 24 * time.Hour
+
 // DatabaseConfig holds database-related configuration
 type DatabaseConfig struct {
+	Driver                string // postgres or sqlite
+	SQLitePath            string // Database file used when Driver is sqlite
 	URL                   string
 	Host                  string
 	Port                  string
@@ -53,120 +426,693 @@ type DatabaseConfig struct {
 	MaxConnections        int
 	MaxIdleConnections    int
 	ConnectionMaxLifetime time.Duration
+	AutoMigrate           bool          // Apply pending embedded schema migrations on startup
+	ReplicaURL            string        // Optional read-only replica DSN for telemetry history reads
+	ReplicaHealthInterval time.Duration // How often the replica is pinged; reads use the primary while it fails
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from environment variables, falling back to CONFIG_FILE when set
 func Load() (*Config, error) {
+	l, err := newLoader()
+	if err != nil {
+		return nil, err
+	}
+	cfg := l.load()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// load reads every setting, recording the raw values on the configuration
+func (l *loader) load() *Config {
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:    getEnv("PORT", "8080"),
-			DevMode: getEnvAsBool("DEV_MODE", false),
+			Port:              l.getEnv("PORT", "8080"),
+			DevMode:           l.getEnvAsBool("DEV_MODE", false),
+			MaxBatchRecords:   l.getEnvAsInt("SERVER_MAX_BATCH_RECORDS", 1000),
+			MaxBodyBytes:      int64(l.getEnvAsInt("SERVER_MAX_BODY_BYTES", 10<<20)),
+			LenientValidation: l.getEnvAsBool("SERVER_LENIENT_VALIDATION", false),
+			TrustedProxies:    l.getEnvAsList("TRUSTED_PROXIES", ""),
+			ClientIPHeaders:   l.getEnvAsList("CLIENT_IP_HEADERS", "X-Forwarded-For,X-Real-IP"),
 		},
 		Database: DatabaseConfig{
-			URL:                   os.Getenv("DATABASE_URL"),
-			Host:                  getEnv("DB_HOST", "localhost"),
-			Port:                  getEnv("DB_PORT", "5432"),
-			Name:                  getEnv("DB_NAME", "telemetry_dev"),
-			User:                  getEnv("DB_USER", "telemetry_user"),
-			Password:              getEnv("DB_PASSWORD", "telemetry_pass"),
-			SSLMode:               getEnv("DB_SSLMODE", "disable"),
-			MaxConnections:        getEnvAsInt("DB_MAX_CONNECTIONS", 25),
-			MaxIdleConnections:    getEnvAsInt("DB_MAX_IDLE_CONNECTIONS", 5),
-			ConnectionMaxLifetime: getEnvAsDuration("DB_CONNECTION_MAX_LIFETIME", "5m"),
+			Driver:                l.getEnv("DB_DRIVER", "postgres"),
+			SQLitePath:            l.getEnv("DB_SQLITE_PATH", "avt.db"),
+			URL:                   l.getSecret("DATABASE_URL", ""),
+			Host:                  l.getEnv("DB_HOST", "localhost"),
+			Port:                  l.getEnv("DB_PORT", "5432"),
+			Name:                  l.getEnv("DB_NAME", "telemetry_dev"),
+			User:                  l.getEnv("DB_USER", "telemetry_user"),
+			Password:              l.getSecret("DB_PASSWORD", "telemetry_pass"),
+			SSLMode:               l.getEnv("DB_SSLMODE", "disable"),
+			MaxConnections:        l.getEnvAsInt("DB_MAX_CONNECTIONS", 25),
+			MaxIdleConnections:    l.getEnvAsInt("DB_MAX_IDLE_CONNECTIONS", 5),
+			ConnectionMaxLifetime: l.getEnvAsDuration("DB_CONNECTION_MAX_LIFETIME", "5m"),
+			AutoMigrate:           l.getEnvAsBool("DB_AUTO_MIGRATE", false),
+			ReplicaURL:            l.getEnv("DB_REPLICA_URL", ""),
+			ReplicaHealthInterval: l.getEnvAsDuration("DB_REPLICA_HEALTH_INTERVAL", "10s"),
 		},
 		Auth: AuthConfig{
-			JWTSecret:          GetSecret("JWT_SECRET", "dev-secret-key-change-in-production"),
-			JWTAccessTokenTTL:  getEnvAsDuration("JWT_ACCESS_TOKEN_TTL", "1h"),
-			JWTRefreshTokenTTL: getEnvAsDuration("JWT_REFRESH_TOKEN_TTL", "720h"), // 30 days
+			JWTSecret:           l.getSecret("JWT_SECRET", defaultJWTSecret),
+			JWTAccessTokenTTL:   l.getEnvAsDuration("JWT_ACCESS_TOKEN_TTL", "1h"),
+			JWTRefreshTokenTTL:  l.getEnvAsDuration("JWT_REFRESH_TOKEN_TTL", "720h"), // 30 days
+			RememberMeTTL:       l.getEnvAsDuration("JWT_REMEMBER_ME_TTL", "2160h"),  // 90 days
+			SessionMaxAge:       l.getEnvAsDuration("JWT_SESSION_MAX_AGE", "8760h"),  // 365 days
+			DenylistStore:       l.getEnv("JWT_DENYLIST_STORE", "memory"),
+			RefreshTokenBinding: strings.ToLower(l.getEnv("JWT_REFRESH_TOKEN_BINDING", "off")),
+		},
+		Secrets: SecretsConfig{
+			Provider:        strings.ToLower(l.getEnv("SECRETS_PROVIDER", "none")),
+			AWSRegion:       l.getEnv("SECRETS_AWS_REGION", ""),
+			VaultAddress:    l.getEnv("VAULT_ADDR", ""),
+			VaultToken:      l.getSecret("VAULT_TOKEN", ""),
+			VaultMount:      strings.Trim(l.getEnv("VAULT_KV_MOUNT", "secret"), "/"),
+			JWTSecretRef:    l.getEnv("JWT_SECRET_REF", ""),
+			DBPasswordRef:   l.getEnv("DB_PASSWORD_REF", ""),
+			MailgunKeyRef:   l.getEnv("MAILGUN_API_KEY_REF", ""),
+			RefreshInterval: l.getEnvAsDuration("SECRETS_REFRESH_INTERVAL", "5m"),
+		},
+		Password: PasswordPolicyConfig{
+			MinLength:      l.getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			MinEntropyBits: l.getEnvAsFloat("PASSWORD_MIN_ENTROPY_BITS", 40),
+			BlockCommon:    l.getEnvAsBool("PASSWORD_BLOCK_COMMON", true),
+			CheckBreached:  l.getEnvAsBool("PASSWORD_CHECK_BREACHED", false),
+			BreachAPIURL:   l.getEnv("PASSWORD_BREACH_API_URL", "https://api.pwnedpasswords.com"),
+		},
+		Lockout: LockoutConfig{
+			Enabled:          l.getEnvAsBool("LOGIN_LOCKOUT_ENABLED", true),
+			MaxAttempts:      l.getEnvAsInt("LOGIN_LOCKOUT_MAX_ATTEMPTS", 5),
+			MaxAttemptsPerIP: l.getEnvAsInt("LOGIN_LOCKOUT_MAX_ATTEMPTS_PER_IP", 20),
+			Window:           l.getEnvAsDuration("LOGIN_LOCKOUT_WINDOW", "15m"),
+			Duration:         l.getEnvAsDuration("LOGIN_LOCKOUT_DURATION", "15m"),
 		},
 		Email: EmailConfig{
-			Provider:      getEnv("EMAIL_PROVIDER", "mock"),
-			MailgunDomain: GetSecret("MAILGUN_DOMAIN", ""),
-			MailgunAPIKey: GetSecret("MAILGUN_API_KEY", ""),
-			FromAddress:   getEnv("EMAIL_FROM_ADDRESS", "noreply@example.com"),
-			FromName:      getEnv("EMAIL_FROM_NAME", "AVT Service"),
-			AppURL:        getEnv("APP_URL", "http://localhost:3000"),
-			ResetTokenTTL: getEnvAsDuration("RESET_TOKEN_TTL", "12h"),
+			Provider:      l.getEnv("EMAIL_PROVIDER", "mock"),
+			MailgunDomain: l.getSecret("MAILGUN_DOMAIN", ""),
+			MailgunAPIKey: l.getSecret("MAILGUN_API_KEY", ""),
+			FromAddress:   l.getEnv("EMAIL_FROM_ADDRESS", "noreply@example.com"),
+			FromName:      l.getEnv("EMAIL_FROM_NAME", "AVT Service"),
+			AppURL:        l.getEnv("APP_URL", "http://localhost:3000"),
+			ResetTokenTTL: l.getEnvAsDuration("RESET_TOKEN_TTL", "12h"),
+			MagicLinkTTL:  l.getEnvAsDuration("MAGIC_LINK_TTL", "15m"),
 		},
-	}
+		Redis: RedisConfig{
+			URL: l.getSecret("REDIS_URL", ""),
+		},
+		Cache: CacheConfig{
+			Enabled: l.getEnvAsBool("CACHE_ENABLED", false),
+			Store:   l.getEnv("CACHE_STORE", "redis"),
+			TTL:     l.getEnvAsDuration("CACHE_TTL", "30s"),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:                 l.getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			Store:                   l.getEnv("RATE_LIMIT_STORE", "memory"),
+			LoginPerMinute:          l.getEnvAsInt("RATE_LIMIT_LOGIN_PER_MINUTE", 10),
+			LoginBurst:              l.getEnvAsInt("RATE_LIMIT_LOGIN_BURST", 5),
+			ForgotPasswordPerMinute: l.getEnvAsInt("RATE_LIMIT_FORGOT_PASSWORD_PER_MINUTE", 3),
+			ForgotPasswordBurst:     l.getEnvAsInt("RATE_LIMIT_FORGOT_PASSWORD_BURST", 3),
+			IngestPerMinute:         l.getEnvAsInt("RATE_LIMIT_INGEST_PER_MINUTE", 600),
+			IngestBurst:             l.getEnvAsInt("RATE_LIMIT_INGEST_BURST", 120),
+		},
+		Workers: WorkerConfig{
+			SessionSummaryInterval: l.getEnvAsDuration("SESSION_SUMMARY_INTERVAL", "5m"),
+			DeviceBackfillInterval: l.getEnvAsDuration("DEVICE_BACKFILL_INTERVAL", "5m"),
+			SmoothingEnabled:       l.getEnvAsBool("SESSION_SMOOTHING_ENABLED", false),
+			SmoothingInterval:      l.getEnvAsDuration("SESSION_SMOOTHING_INTERVAL", "5m"),
+			TelemetryUndoWindow:    l.getEnvAsDuration("TELEMETRY_UNDO_WINDOW", "24h"),
+			TelemetryPurgeInterval: l.getEnvAsDuration("TELEMETRY_PURGE_INTERVAL", "15m"),
 
-	if err := cfg.Validate(); err != nil {
-		return nil, err
+			DerivedBackfillInterval: l.getEnvAsDuration("DERIVED_CHANNELS_BACKFILL_INTERVAL", "1m"),
+		},
+		MQTT: MQTTConfig{
+			Enabled:   l.getEnvAsBool("MQTT_ENABLED", false),
+			BrokerURL: l.getEnv("MQTT_BROKER_URL", "tcp://localhost:1883"),
+			ClientID:  l.getEnv("MQTT_CLIENT_ID", "avt-service"),
+			Username:  l.getEnv("MQTT_USERNAME", ""),
+			Password:  l.getSecret("MQTT_PASSWORD", ""),
+			Topic:     l.getEnv("MQTT_TOPIC", "avt/+/telemetry"),
+			QoS:       l.getEnvAsInt("MQTT_QOS", 1),
+		},
+		Storage: StorageConfig{
+			CompressAfter:  l.getEnvAsDuration("TELEMETRY_COMPRESS_AFTER", "168h"), // 7 days
+			RetainFor:      l.getEnvAsDuration("TELEMETRY_RETENTION", "0s"),
+			AuditRetainFor: l.getEnvAsDuration("INGEST_AUDIT_RETENTION", "720h"), // 30 days
+		},
+		Ingest: IngestConfig{
+			StrictOwnership: l.getEnvAsBool("INGEST_STRICT_OWNERSHIP", false),
+			Audit:           l.getEnvAsBool("INGEST_AUDIT_ENABLED", true),
+			Deduplicate:     l.getEnvAsBool("INGEST_DEDUPLICATE", false),
+			Buffered:        l.getEnvAsBool("INGEST_BUFFER_ENABLED", false),
+			BufferSize:      l.getEnvAsInt("INGEST_BUFFER_SIZE", 10000),
+			FlushSize:       l.getEnvAsInt("INGEST_FLUSH_SIZE", 500),
+			FlushInterval:   l.getEnvAsDuration("INGEST_FLUSH_INTERVAL", "200ms"),
+			ShedThreshold:   l.getEnvAsFloat("INGEST_SHED_THRESHOLD", 0.9),
+			MaxPoolWait:     l.getEnvAsDuration("INGEST_MAX_POOL_WAIT", "500ms"),
+
+			MinFirmwareVersion: l.getEnv("INGEST_MIN_FIRMWARE_VERSION", ""),
+			RequireMinFirmware: l.getEnvAsBool("INGEST_REQUIRE_MIN_FIRMWARE", false),
+
+			ClockSkewPolicy:    l.getEnv("INGEST_CLOCK_SKEW_POLICY", "flag"),
+			ClockSkewThreshold: l.getEnvAsDuration("INGEST_CLOCK_SKEW_THRESHOLD", "2s"),
+
+			SignatureMaxAge:     l.getEnvAsDuration("INGEST_SIGNATURE_MAX_AGE", "5m"),
+			SignatureNonceStore: l.getEnv("INGEST_SIGNATURE_NONCE_STORE", "memory"),
+			SigningSecretKey:    l.getSecret("INGEST_SIGNING_SECRET_KEY", ""),
+
+			DerivedChannels: l.getEnvAsBool("INGEST_DERIVED_CHANNELS", false),
+		},
+		Quota: QuotaConfig{
+			Enabled: l.getEnvAsBool("QUOTA_ENABLED", false),
+			Free: PlanQuotaConfig{
+				TelemetryPointsPerMonth: l.getEnvAsInt("QUOTA_FREE_POINTS_PER_MONTH", 1000000),
+				MaxDevices:              l.getEnvAsInt("QUOTA_FREE_MAX_DEVICES", 3),
+			},
+			Pro: PlanQuotaConfig{
+				TelemetryPointsPerMonth: l.getEnvAsInt("QUOTA_PRO_POINTS_PER_MONTH", 50000000),
+				MaxDevices:              l.getEnvAsInt("QUOTA_PRO_MAX_DEVICES", 25),
+			},
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   l.getEnvAsList("CORS_ALLOWED_ORIGINS", "*"),
+			AllowedMethods:   l.getEnvAsList("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"),
+			AllowedHeaders:   l.getEnvAsList("CORS_ALLOWED_HEADERS", "Content-Type,Content-Encoding,Authorization,X-Request-ID,X-Batch-ID,X-Device-Key,X-Installation-ID"),
+			AllowCredentials: l.getEnvAsBool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           l.getEnvAsDuration("CORS_MAX_AGE", "12h"),
+		},
+		Security: SecurityHeadersConfig{
+			Enabled:               l.getEnvAsBool("SECURITY_HEADERS_ENABLED", true),
+			HSTSMaxAge:            l.getEnvAsDuration("SECURITY_HSTS_MAX_AGE", "8760h"), // 1 year
+			HSTSIncludeSubdomains: l.getEnvAsBool("SECURITY_HSTS_INCLUDE_SUBDOMAINS", false),
+			FrameOptions:          l.getEnv("SECURITY_FRAME_OPTIONS", "DENY"),
+			ReferrerPolicy:        l.getEnv("SECURITY_REFERRER_POLICY", "no-referrer"),
+			ContentSecurityPolicy: l.getEnv("SECURITY_CONTENT_SECURITY_POLICY", "frame-ancestors 'none'"),
+		},
+		Tracing: TracingConfig{
+			Enabled:     l.getEnvAsBool("TRACING_ENABLED", false),
+			Endpoint:    l.getEnv("TRACING_OTLP_ENDPOINT", "localhost:4318"),
+			Insecure:    l.getEnvAsBool("TRACING_OTLP_INSECURE", false),
+			ServiceName: l.getEnv("TRACING_SERVICE_NAME", "avt-service"),
+			SampleRatio: l.getEnvAsFloat("TRACING_SAMPLE_RATIO", 1),
+		},
+		Health: DeviceHealthConfig{
+			Enabled:           l.getEnvAsBool("DEVICE_HEALTH_ENABLED", true),
+			LowBatteryPercent: l.getEnvAsFloat("DEVICE_HEALTH_LOW_BATTERY_PERCENT", 20),
+			NoFixAfter:        l.getEnvAsDuration("DEVICE_HEALTH_NO_FIX_AFTER", "10m"),
+			SnapshotInterval:  l.getEnvAsDuration("DEVICE_HEALTH_SNAPSHOT_INTERVAL", "15m"),
+			NotifyEmail:       l.getEnvAsBool("DEVICE_HEALTH_NOTIFY_EMAIL", true),
+			WebhookURL:        l.getEnv("DEVICE_HEALTH_WEBHOOK_URL", ""),
+		},
+		Logging: LoggingConfig{
+			Level:  strings.ToLower(l.getEnv("LOG_LEVEL", "info")),
+			Format: strings.ToLower(l.getEnv("LOG_FORMAT", "text")),
+
+			AccessLog:        l.getEnvAsBool("LOG_ACCESS_ENABLED", true),
+			AccessSampleRate: l.getEnvAsFloat("LOG_ACCESS_SAMPLE_RATE", 1),
+		},
+		GeoIP: GeoIPConfig{
+			Provider:     strings.ToLower(l.getEnv("GEOIP_PROVIDER", "none")),
+			DatabasePath: l.getEnv("GEOIP_DATABASE", ""),
+		},
+		Push: PushConfig{
+			FCMCredentials: l.getSecret("PUSH_FCM_CREDENTIALS", ""),
+			FCMProjectID:   l.getEnv("PUSH_FCM_PROJECT_ID", ""),
+			APNsKey:        l.getSecret("PUSH_APNS_KEY", ""),
+			APNsKeyID:      l.getEnv("PUSH_APNS_KEY_ID", ""),
+			APNsTeamID:     l.getEnv("PUSH_APNS_TEAM_ID", ""),
+			APNsTopic:      l.getEnv("PUSH_APNS_TOPIC", ""),
+			APNsSandbox:    l.getEnvAsBool("PUSH_APNS_SANDBOX", false),
+		},
+		Archive: ArchiveConfig{
+			Enabled:         l.getEnvAsBool("ARCHIVE_ENABLED", false),
+			After:           l.getEnvAsDuration("ARCHIVE_AFTER", "2160h"), // 90 days
+			Interval:        l.getEnvAsDuration("ARCHIVE_INTERVAL", "1h"),
+			Endpoint:        l.getEnv("ARCHIVE_S3_ENDPOINT", ""),
+			UseSSL:          l.getEnvAsBool("ARCHIVE_S3_USE_SSL", true),
+			Buckets:         l.getEnvAsList("ARCHIVE_S3_BUCKETS", ""),
+			Prefix:          strings.Trim(l.getEnv("ARCHIVE_S3_PREFIX", "telemetry"), "/"),
+			AccessKeyID:     l.getSecret("ARCHIVE_S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: l.getSecret("ARCHIVE_S3_SECRET_ACCESS_KEY", ""),
+		},
+		Reports: ReportConfig{
+			RetainFor:     l.getEnvAsDuration("REPORT_RETENTION", "168h"), // 7 days
+			URLTTL:        l.getEnvAsDuration("REPORT_URL_TTL", "15m"),
+			SweepInterval: l.getEnvAsDuration("REPORT_SWEEP_INTERVAL", "1h"),
+		},
+		Events: EventConfig{
+			PollInterval: l.getEnvAsDuration("EVENT_OUTBOX_POLL_INTERVAL", "10s"),
+			RetainFor:    l.getEnvAsDuration("EVENT_OUTBOX_RETENTION", "168h"), // 7 days
+			WebhookURL:   l.getEnv("EVENT_WEBHOOK_URL", ""),
+		},
+		Webhooks: WebhookConfig{
+			AllowedNetworks: l.getEnvAsList("WEBHOOK_ALLOWED_NETWORKS", ""),
+		},
+		Stream: StreamConfig{
+			Driver: l.getEnv("STREAM_DRIVER", ""),
+			URL:    l.getEnv("STREAM_URL", ""),
+			Topic:  l.getEnv("STREAM_TOPIC", "avt.telemetry"),
+		},
+		OIDC: OIDCConfig{
+			IssuerURL:    l.getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:     l.getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: l.getSecret("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  l.getEnv("OIDC_REDIRECT_URL", ""),
+			Scopes:       l.getEnvAsList("OIDC_SCOPES", "openid,email,profile"),
+			GroupsClaim:  l.getEnv("OIDC_GROUPS_CLAIM", "groups"),
+			RoleMapping:  l.getEnvAsList("OIDC_ROLE_MAPPING", ""),
+		},
+		Reload: ReloadConfig{
+			File:     l.file,
+			OnSIGHUP: l.getEnvAsBool("CONFIG_RELOAD_ON_SIGHUP", false),
+		},
+		settings: l.settings,
 	}
 
-	return cfg, nil
+	return cfg
 }
 
 // Validate validates the configuration and returns an error if invalid
 func (c *Config) Validate() error {
+	// Validate the database driver
+	switch c.Database.Driver {
+	case "", "postgres":
+	case "sqlite":
+		if c.Database.SQLitePath == "" {
+			return errors.New("DB_SQLITE_PATH is required when DB_DRIVER=sqlite")
+		}
+		if c.Database.ReplicaURL != "" {
+			return errors.New("DB_REPLICA_URL is not supported when DB_DRIVER=sqlite")
+		}
+	default:
+		return fmt.Errorf("invalid DB_DRIVER %q (must be postgres or sqlite)", c.Database.Driver)
+	}
+
+	// Validate read replica
+	if c.Database.ReplicaURL != "" && c.Database.ReplicaHealthInterval <= 0 {
+		return errors.New("DB_REPLICA_HEALTH_INTERVAL must be positive when DB_REPLICA_URL is set")
+	}
+
+	// Validate session lifetimes
+	if c.Auth.RememberMeTTL < 0 || c.Auth.SessionMaxAge < 0 {
+		return errors.New("JWT_REMEMBER_ME_TTL and JWT_SESSION_MAX_AGE must not be negative")
+	}
+
+	// Validate the access token denylist store
+	switch c.Auth.DenylistStore {
+	case "", "memory":
+	case "redis":
+		if c.Redis.URL == "" {
+			return errors.New("REDIS_URL is required when JWT_DENYLIST_STORE=redis")
+		}
+	default:
+		return fmt.Errorf("invalid JWT_DENYLIST_STORE %q (must be memory or redis)", c.Auth.DenylistStore)
+	}
+
+	// Validate refresh token binding
+	switch c.Auth.RefreshTokenBinding {
+	case "", "off", "optional", "required":
+	default:
+		return fmt.Errorf("invalid JWT_REFRESH_TOKEN_BINDING %q (must be off, optional or required)", c.Auth.RefreshTokenBinding)
+	}
+
+	// Validate the secret manager
+	switch c.Secrets.Provider {
+	case "", "none":
+		if c.Secrets.JWTSecretRef != "" || c.Secrets.DBPasswordRef != "" || c.Secrets.MailgunKeyRef != "" {
+			return errors.New("SECRETS_PROVIDER is required when JWT_SECRET_REF, DB_PASSWORD_REF or MAILGUN_API_KEY_REF is set")
+		}
+	case "aws":
+	case "vault":
+		if c.Secrets.VaultAddress == "" || c.Secrets.VaultToken == "" {
+			return errors.New("VAULT_ADDR and VAULT_TOKEN are required when SECRETS_PROVIDER=vault")
+		}
+	default:
+		return fmt.Errorf("invalid SECRETS_PROVIDER %q (must be none, aws or vault)", c.Secrets.Provider)
+	}
+	if c.Secrets.RefreshInterval < 0 {
+		return errors.New("SECRETS_REFRESH_INTERVAL must not be negative")
+	}
+
+	// Validate upload limits
+	if c.Server.MaxBatchRecords < 0 || c.Server.MaxBodyBytes < 0 {
+		return errors.New("SERVER_MAX_BATCH_RECORDS and SERVER_MAX_BODY_BYTES must not be negative")
+	}
+
+	// Validate trusted proxies
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(proxy); err != nil {
+			return fmt.Errorf("invalid TRUSTED_PROXIES entry %q (must be an IP address or CIDR)", proxy)
+		}
+	}
+	if len(c.Server.TrustedProxies) > 0 && len(c.Server.ClientIPHeaders) == 0 {
+		return errors.New("CLIENT_IP_HEADERS is required when TRUSTED_PROXIES is set")
+	}
+
+	// Validate webhook receiver networks
+	for _, network := range c.Webhooks.AllowedNetworks {
+		if _, err := netip.ParsePrefix(network); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(network); err != nil {
+			return fmt.Errorf("invalid WEBHOOK_ALLOWED_NETWORKS entry %q (must be an IP address or CIDR)", network)
+		}
+	}
+
 	// Validate email configuration when provider is mailgun
 	if c.Email.Provider == "mailgun" {
-		if c.Email.MailgunAPIKey == "" {
+		if c.Email.MailgunAPIKey == "" && c.Secrets.MailgunKeyRef == "" {
 			return errors.New("MAILGUN_API_KEY is required when EMAIL_PROVIDER=mailgun")
 		}
 		if c.Email.MailgunDomain == "" {
 			return errors.New("MAILGUN_DOMAIN is required when EMAIL_PROVIDER=mailgun")
 		}
 	}
-	return nil
-}
 
-// ConnectionString returns the database connection string
-func (d *DatabaseConfig) ConnectionString() string {
-	if d.URL != "" {
-		return d.URL
+	// Validate password policy
+	if c.Password.MinLength < 0 || c.Password.MinLength > 72 {
+		return fmt.Errorf("invalid PASSWORD_MIN_LENGTH %d (must be at most 72)", c.Password.MinLength)
+	}
+	if c.Password.MinEntropyBits < 0 {
+		return errors.New("PASSWORD_MIN_ENTROPY_BITS must not be negative")
+	}
+	if c.Password.CheckBreached && c.Password.BreachAPIURL == "" {
+		return errors.New("PASSWORD_BREACH_API_URL is required when PASSWORD_CHECK_BREACHED=true")
 	}
-	return fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		d.Host, d.Port, d.User, d.Password, d.Name, d.SSLMode,
-	)
-}
 
-// getEnv gets an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+	// Validate login lockout
+	if c.Lockout.Enabled {
+		if c.Lockout.MaxAttempts <= 0 || c.Lockout.MaxAttemptsPerIP <= 0 {
+			return errors.New("LOGIN_LOCKOUT_MAX_ATTEMPTS and LOGIN_LOCKOUT_MAX_ATTEMPTS_PER_IP must be positive when LOGIN_LOCKOUT_ENABLED=true")
+		}
+		if c.Lockout.Window <= 0 || c.Lockout.Duration <= 0 {
+			return errors.New("LOGIN_LOCKOUT_WINDOW and LOGIN_LOCKOUT_DURATION must be positive when LOGIN_LOCKOUT_ENABLED=true")
+		}
 	}
-	return value
-}
 
-// getEnvAsInt gets an environment variable as an integer or returns a default value
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
+	// Validate rate limiting configuration
+	if c.RateLimit.Enabled {
+		switch c.RateLimit.Store {
+		case "memory":
+		case "redis":
+			if c.Redis.URL == "" {
+				return errors.New("REDIS_URL is required when RATE_LIMIT_STORE=redis")
+			}
+		default:
+			return fmt.Errorf("invalid RATE_LIMIT_STORE %q (must be memory or redis)", c.RateLimit.Store)
+		}
+
+		limits := []int{
+			c.RateLimit.LoginPerMinute, c.RateLimit.LoginBurst,
+			c.RateLimit.ForgotPasswordPerMinute, c.RateLimit.ForgotPasswordBurst,
+			c.RateLimit.IngestPerMinute, c.RateLimit.IngestBurst,
+		}
+		for _, limit := range limits {
+			if limit <= 0 {
+				return errors.New("rate limits and bursts must be positive when RATE_LIMIT_ENABLED=true")
+			}
+		}
 	}
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
-		return defaultValue
+
+	// Validate query cache configuration
+	if c.Cache.Enabled {
+		switch c.Cache.Store {
+		case "memory":
+		case "redis":
+			if c.Redis.URL == "" {
+				return errors.New("REDIS_URL is required when CACHE_STORE=redis")
+			}
+		default:
+			return fmt.Errorf("invalid CACHE_STORE %q (must be memory or redis)", c.Cache.Store)
+		}
+		if c.Cache.TTL <= 0 {
+			return errors.New("CACHE_TTL must be positive when CACHE_ENABLED=true")
+		}
 	}
-	return value
-}
 
-// getEnvAsDuration gets an environment variable as a duration or returns a default value
-func getEnvAsDuration(key, defaultValue string) time.Duration {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		valueStr = defaultValue
+	// Validate MQTT bridge configuration
+	if c.MQTT.Enabled {
+		if c.MQTT.BrokerURL == "" {
+			return errors.New("MQTT_BROKER_URL is required when MQTT_ENABLED=true")
+		}
+		if c.MQTT.Topic == "" {
+			return errors.New("MQTT_TOPIC is required when MQTT_ENABLED=true")
+		}
+		if c.MQTT.QoS < 0 || c.MQTT.QoS > 2 {
+			return fmt.Errorf("invalid MQTT_QOS %d (must be 0, 1 or 2)", c.MQTT.QoS)
+		}
 	}
-	value, err := time.ParseDuration(valueStr)
-	if err != nil {
-		defaultDuration, _ := time.ParseDuration(defaultValue)
-		return defaultDuration
+
+	// Validate telemetry storage policies
+	if c.Storage.CompressAfter < 0 || c.Storage.RetainFor < 0 {
+		return errors.New("TELEMETRY_COMPRESS_AFTER and TELEMETRY_RETENTION must not be negative")
+	}
+	if c.Storage.AuditRetainFor < 0 {
+		return errors.New("INGEST_AUDIT_RETENTION must not be negative")
+	}
+	if c.Storage.RetainFor > 0 {
+		if c.Storage.RetainFor < minTelemetryRetention {
+			return fmt.Errorf("TELEMETRY_RETENTION must be at least %s so continuous aggregates are refreshed before data is dropped", minTelemetryRetention)
+		}
+		if c.Storage.CompressAfter > 0 && c.Storage.RetainFor <= c.Storage.CompressAfter {
+			return errors.New("TELEMETRY_RETENTION must be greater than TELEMETRY_COMPRESS_AFTER")
+		}
 	}
-	return value
-}
 
-// getEnvAsBool gets an environment variable as a boolean or returns a default value
-func getEnvAsBool(key string, defaultValue bool) bool {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
+	// Validate write-behind ingest buffer
+	if c.Ingest.Buffered {
+		if c.Ingest.BufferSize <= 0 || c.Ingest.FlushSize <= 0 || c.Ingest.FlushInterval <= 0 {
+			return errors.New("INGEST_BUFFER_SIZE, INGEST_FLUSH_SIZE and INGEST_FLUSH_INTERVAL must be positive when INGEST_BUFFER_ENABLED=true")
+		}
+		if c.Ingest.FlushSize > c.Ingest.BufferSize {
+			return errors.New("INGEST_FLUSH_SIZE must not exceed INGEST_BUFFER_SIZE")
+		}
 	}
-	value, err := strconv.ParseBool(valueStr)
-	if err != nil {
-		return defaultValue
+	if c.Ingest.ShedThreshold < 0 || c.Ingest.ShedThreshold > 1 {
+		return errors.New("INGEST_SHED_THRESHOLD must be between 0 and 1")
+	}
+	if c.Ingest.MaxPoolWait < 0 {
+		return errors.New("INGEST_MAX_POOL_WAIT must not be negative")
+	}
+	if c.Ingest.MinFirmwareVersion != "" {
+		if _, err := models.ParseFirmwareVersion(c.Ingest.MinFirmwareVersion); err != nil {
+			return fmt.Errorf("invalid INGEST_MIN_FIRMWARE_VERSION %q (expected MAJOR[.MINOR[.PATCH]])", c.Ingest.MinFirmwareVersion)
+		}
+	} else if c.Ingest.RequireMinFirmware {
+		return errors.New("INGEST_MIN_FIRMWARE_VERSION is required when INGEST_REQUIRE_MIN_FIRMWARE=true")
+	}
+	switch c.Ingest.ClockSkewPolicy {
+	case "", "off", "flag", "correct":
+	default:
+		return fmt.Errorf("invalid INGEST_CLOCK_SKEW_POLICY %q (must be off, flag or correct)", c.Ingest.ClockSkewPolicy)
+	}
+	if c.Ingest.ClockSkewThreshold < 0 {
+		return errors.New("INGEST_CLOCK_SKEW_THRESHOLD must not be negative")
+	}
+	if c.Ingest.SignatureMaxAge < 0 {
+		return errors.New("INGEST_SIGNATURE_MAX_AGE must not be negative")
+	}
+	switch c.Ingest.SignatureNonceStore {
+	case "", "memory":
+	case "redis":
+		if c.Redis.URL == "" {
+			return errors.New("REDIS_URL is required when INGEST_SIGNATURE_NONCE_STORE=redis")
+		}
+	default:
+		return fmt.Errorf("invalid INGEST_SIGNATURE_NONCE_STORE %q (must be memory or redis)", c.Ingest.SignatureNonceStore)
+	}
+	if c.Ingest.SigningSecretKey != "" {
+		if key, err := base64.StdEncoding.DecodeString(c.Ingest.SigningSecretKey); err != nil || len(key) != 32 {
+			return errors.New("INGEST_SIGNING_SECRET_KEY must be 32 base64-encoded bytes")
+		}
+	}
+
+	// Validate usage quotas
+	for _, plan := range []PlanQuotaConfig{c.Quota.Free, c.Quota.Pro} {
+		if plan.TelemetryPointsPerMonth < 0 || plan.MaxDevices < 0 {
+			return errors.New("QUOTA_*_POINTS_PER_MONTH and QUOTA_*_MAX_DEVICES must not be negative")
+		}
+	}
+
+	// Validate CORS origins
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if len(c.CORS.AllowedOrigins) > 1 {
+				return errors.New("CORS_ALLOWED_ORIGINS must not combine * with other origins")
+			}
+			if c.CORS.AllowCredentials {
+				return errors.New("CORS_ALLOW_CREDENTIALS requires explicit CORS_ALLOWED_ORIGINS, not *")
+			}
+			continue
+		}
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return fmt.Errorf("invalid CORS origin %q (must start with http:// or https://)", origin)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		return errors.New("CORS_MAX_AGE must not be negative")
+	}
+
+	// Validate security headers
+	if c.Security.HSTSMaxAge < 0 {
+		return errors.New("SECURITY_HSTS_MAX_AGE must not be negative")
+	}
+
+	// Validate tracing configuration
+	if c.Tracing.Enabled {
+		if c.Tracing.Endpoint == "" {
+			return errors.New("TRACING_OTLP_ENDPOINT is required when TRACING_ENABLED=true")
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("invalid TRACING_SAMPLE_RATIO %g (must be between 0 and 1)", c.Tracing.SampleRatio)
+		}
+	}
+
+	// Validate device health monitoring
+	if c.Health.Enabled {
+		if c.Health.LowBatteryPercent < 0 || c.Health.LowBatteryPercent > 100 {
+			return fmt.Errorf("invalid DEVICE_HEALTH_LOW_BATTERY_PERCENT %g (must be between 0 and 100)", c.Health.LowBatteryPercent)
+		}
+		if c.Health.NoFixAfter < 0 {
+			return errors.New("DEVICE_HEALTH_NO_FIX_AFTER must not be negative")
+		}
+		if c.Health.SnapshotInterval < time.Minute {
+			return errors.New("DEVICE_HEALTH_SNAPSHOT_INTERVAL must be at least 1m")
+		}
+		if c.Health.WebhookURL != "" && !strings.HasPrefix(c.Health.WebhookURL, "http://") && !strings.HasPrefix(c.Health.WebhookURL, "https://") {
+			return fmt.Errorf("invalid DEVICE_HEALTH_WEBHOOK_URL %q (must start with http:// or https://)", c.Health.WebhookURL)
+		}
 	}
-	return value
+
+	// Validate logging
+	switch c.Logging.Level {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("invalid LOG_LEVEL %q (must be debug, info, warn or error)", c.Logging.Level)
+	}
+	switch c.Logging.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT %q (must be text or json)", c.Logging.Format)
+	}
+	if c.Logging.AccessSampleRate < 0 || c.Logging.AccessSampleRate > 1 {
+		return errors.New("LOG_ACCESS_SAMPLE_RATE must be between 0 and 1")
+	}
+
+	// Validate session smoothing
+	if c.Workers.SmoothingEnabled && c.Workers.SmoothingInterval <= 0 {
+		return errors.New("SESSION_SMOOTHING_INTERVAL must be positive when SESSION_SMOOTHING_ENABLED=true")
+	}
+
+	// Validate telemetry corrections
+	if c.Workers.TelemetryUndoWindow < 0 {
+		return errors.New("TELEMETRY_UNDO_WINDOW must not be negative")
+	}
+
+	// Validate derived channels
+	if c.Workers.DerivedBackfillInterval < 0 {
+		return errors.New("DERIVED_CHANNELS_BACKFILL_INTERVAL must not be negative")
+	}
+
+	// Validate GeoIP
+	switch c.GeoIP.Provider {
+	case "", "none":
+	case "csv":
+		if c.GeoIP.DatabasePath == "" {
+			return errors.New("GEOIP_DATABASE is required when GEOIP_PROVIDER=csv")
+		}
+	default:
+		return fmt.Errorf("invalid GEOIP_PROVIDER %q (must be none or csv)", c.GeoIP.Provider)
+	}
+
+	// Validate push notifications
+	if c.Push.APNsEnabled() && (c.Push.APNsKeyID == "" || c.Push.APNsTeamID == "" || c.Push.APNsTopic == "") {
+		return errors.New("PUSH_APNS_KEY_ID, PUSH_APNS_TEAM_ID and PUSH_APNS_TOPIC are required with PUSH_APNS_KEY")
+	}
+
+	// Validate telemetry archival
+	if c.Archive.Enabled {
+		buckets, err := c.Archive.ParseBuckets()
+		if err != nil {
+			return err
+		}
+		if len(buckets) == 0 {
+			return errors.New("ARCHIVE_S3_BUCKETS is required when ARCHIVE_ENABLED=true")
+		}
+		if c.Archive.After < minTelemetryRetention {
+			return fmt.Errorf("ARCHIVE_AFTER must be at least %s so continuous aggregates are refreshed before data is archived", minTelemetryRetention)
+		}
+		if c.Storage.RetainFor > 0 && c.Archive.After >= c.Storage.RetainFor {
+			return errors.New("ARCHIVE_AFTER must be less than TELEMETRY_RETENTION")
+		}
+		if c.Archive.Interval <= 0 {
+			return errors.New("ARCHIVE_INTERVAL must be positive when ARCHIVE_ENABLED=true")
+		}
+		if (c.Archive.AccessKeyID == "") != (c.Archive.SecretAccessKey == "") {
+			return errors.New("ARCHIVE_S3_ACCESS_KEY_ID and ARCHIVE_S3_SECRET_ACCESS_KEY must be set together")
+		}
+	}
+
+	// Validate fleet reports
+	if c.Reports.RetainFor < 0 || c.Reports.URLTTL < 0 {
+		return errors.New("REPORT_RETENTION and REPORT_URL_TTL must not be negative")
+	}
+
+	// Validate the event outbox
+	if c.Events.PollInterval < 0 || c.Events.RetainFor < 0 {
+		return errors.New("EVENT_OUTBOX_POLL_INTERVAL and EVENT_OUTBOX_RETENTION must not be negative")
+	}
+	if c.Events.WebhookURL != "" && !strings.HasPrefix(c.Events.WebhookURL, "http://") && !strings.HasPrefix(c.Events.WebhookURL, "https://") {
+		return fmt.Errorf("invalid EVENT_WEBHOOK_URL %q (must start with http:// or https://)", c.Events.WebhookURL)
+	}
+
+	// Validate telemetry streaming
+	switch c.Stream.Driver {
+	case "":
+	case "kafka":
+		if !strings.HasPrefix(c.Stream.URL, "http://") && !strings.HasPrefix(c.Stream.URL, "https://") {
+			return fmt.Errorf("invalid STREAM_URL %q (must start with http:// or https:// for the Kafka REST Proxy)", c.Stream.URL)
+		}
+	case "nats":
+		if !strings.HasPrefix(c.Stream.URL, "nats://") && !strings.HasPrefix(c.Stream.URL, "tls://") {
+			return fmt.Errorf("invalid STREAM_URL %q (must start with nats:// or tls://)", c.Stream.URL)
+		}
+	default:
+		return fmt.Errorf("invalid STREAM_DRIVER %q (must be kafka or nats)", c.Stream.Driver)
+	}
+	if c.Stream.Enabled() && c.Stream.Topic == "" {
+		return errors.New("STREAM_TOPIC is required when STREAM_DRIVER is set")
+	}
+
+	// Validate single sign-on
+	if c.OIDC.Enabled() {
+		if !strings.HasPrefix(c.OIDC.IssuerURL, "https://") && !strings.HasPrefix(c.OIDC.IssuerURL, "http://") {
+			return fmt.Errorf("invalid OIDC_ISSUER_URL %q (must start with https:// or http://)", c.OIDC.IssuerURL)
+		}
+		if c.OIDC.ClientID == "" || c.OIDC.RedirectURL == "" {
+			return errors.New("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER_URL is set")
+		}
+		if !slices.Contains(c.OIDC.Scopes, "openid") {
+			return errors.New("OIDC_SCOPES must include openid")
+		}
+		if _, err := c.OIDC.ParseRoleMapping(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConnectionString returns the database connection string
+func (d *DatabaseConfig) ConnectionString() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		d.Host, d.Port, d.User, d.Password, d.Name, d.SSLMode,
+	)
 }
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configFileEnv names the optional KEY=VALUE file settings are also read from
+const configFileEnv = "CONFIG_FILE"
+
+// loader reads settings from the environment and the optional config file
+// It records every setting it reads and every malformed value, which Load ignores
+// in favour of defaults and Check reports. The zero value reads the environment only.
+type loader struct {
+	file     string            // Path of the config file, if any
+	values   map[string]string // Settings from the config file
+	settings map[string]string // Raw value of every setting read
+	secrets  map[string]bool   // Settings that may also be read from a _FILE path
+	problems []error
+}
+
+// newLoader creates a loader, reading CONFIG_FILE if it is set
+func newLoader() (*loader, error) {
+	l := &loader{file: os.Getenv(configFileEnv)}
+	if l.file == "" {
+		return l, nil
+	}
+
+	values, err := readConfigFile(l.file)
+	if err != nil {
+		return nil, err
+	}
+	l.values = values
+	return l, nil
+}
+
+// readConfigFile parses a file of KEY=VALUE lines
+// Blank lines and lines starting with # are skipped; an export prefix and quotes around values are allowed,
+// so the .env files used with docker compose and make run can be reused.
+func readConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configFileEnv, err)
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%s line %d: expected KEY=VALUE", path, line)
+		}
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configFileEnv, err)
+	}
+	return values, nil
+}
+
+// lookup returns the raw value of a setting, preferring the environment over the config file
+func (l *loader) lookup(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		value = l.values[key]
+	}
+	if l.settings == nil {
+		l.settings = make(map[string]string)
+	}
+	l.settings[key] = value
+	return value
+}
+
+// invalid records a malformed value that was replaced by its default
+func (l *loader) invalid(key, value, expected string) {
+	l.problems = append(l.problems, fmt.Errorf("invalid %s %q (must be %s)", key, value, expected))
+}
+
+// getEnv gets a setting or returns a default value
+func (l *loader) getEnv(key, defaultValue string) string {
+	value := l.lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsInt gets a setting as an integer or returns a default value
+func (l *loader) getEnvAsInt(key string, defaultValue int) int {
+	valueStr := l.lookup(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		l.invalid(key, valueStr, "an integer")
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsFloat gets a setting as a float or returns a default value
+func (l *loader) getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := l.lookup(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		l.invalid(key, valueStr, "a number")
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsDuration gets a setting as a duration or returns a default value
+func (l *loader) getEnvAsDuration(key, defaultValue string) time.Duration {
+	defaultDuration, _ := time.ParseDuration(defaultValue)
+	valueStr := l.lookup(key)
+	if valueStr == "" {
+		return defaultDuration
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		l.invalid(key, valueStr, "a duration such as 30s, 5m or 720h")
+		return defaultDuration
+	}
+	return value
+}
+
+// getEnvAsList gets a comma-separated setting as a list or returns a default value
+// Entries are trimmed and empty entries are dropped.
+func (l *loader) getEnvAsList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(l.getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvAsBool gets a setting as a boolean or returns a default value
+func (l *loader) getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := l.lookup(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		l.invalid(key, valueStr, "true or false")
+		return defaultValue
+	}
+	return value
+}
+
+// getSecret gets a secret directly or from the file named by its _FILE setting, or returns a default value
+func (l *loader) getSecret(key, defaultValue string) string {
+	if l.secrets == nil {
+		l.secrets = make(map[string]bool)
+	}
+	l.secrets[key] = true
+
+	if value := l.lookup(key); value != "" {
+		return value
+	}
+	if path := l.lookup(key + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return strings.TrimSpace(string(data))
+		}
+		l.problems = append(l.problems, fmt.Errorf("failed to read %s_FILE: %w", key, err))
+	}
+	return defaultValue
+}
+
+// unknown lists settings nothing reads: every unread key in the config file, and unread
+// environment variables sharing a prefix with at least two known settings (e.g. RATE_LIMIT_LOGIN_PER_MINUT)
+func (l *loader) unknown() []error {
+	prefixes := make(map[string]int)
+	for key := range l.settings {
+		prefix, _, _ := strings.Cut(key, "_")
+		prefixes[prefix]++
+	}
+
+	var keys []string
+	for key := range l.values {
+		if !l.known(key) {
+			keys = append(keys, key)
+		}
+	}
+	for _, env := range os.Environ() {
+		key, _, _ := strings.Cut(env, "=")
+		prefix, _, _ := strings.Cut(key, "_")
+		if !l.known(key) && key != configFileEnv && prefixes[prefix] >= 2 {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	problems := make([]error, 0, len(keys))
+	for _, key := range slices.Compact(keys) {
+		problems = append(problems, fmt.Errorf("unknown setting %s", key))
+	}
+	return problems
+}
+
+// known reports whether a setting is read, counting the _FILE variants of secrets
+func (l *loader) known(key string) bool {
+	if _, ok := l.settings[key]; ok {
+		return true
+	}
+	secret, ok := strings.CutSuffix(key, "_FILE")
+	return ok && l.secrets[secret]
+}
+
+// Check loads configuration strictly, reporting every problem at once instead of falling back to defaults:
+// malformed values, unknown settings, required settings left unset and everything Validate rejects.
+func Check() error {
+	l, err := newLoader()
+	if err != nil {
+		return err
+	}
+	cfg := l.load()
+
+	problems := append(l.problems, l.unknown()...)
+	if !cfg.Server.DevMode && cfg.Auth.JWTSecret == defaultJWTSecret && cfg.Secrets.JWTSecretRef == "" {
+		problems = append(problems, errors.New("JWT_SECRET or JWT_SECRET_REF is required unless DEV_MODE=true"))
+	}
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, err)
+	}
+	return errors.Join(problems...)
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes a config file and points CONFIG_FILE at it for the test
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "avt.env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	return path
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	writeConfigFile(t, `
+# Local overrides
+LOG_LEVEL=debug
+export RATE_LIMIT_LOGIN_BURST="9"
+CACHE_TTL='45s'
+RATE_LIMIT_INGEST_BURST=50
+`)
+	t.Setenv("RATE_LIMIT_INGEST_BURST", "70")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Logging.Level != "debug" {
+		t.Errorf("Logging.Level = %q, want debug", cfg.Logging.Level)
+	}
+	if cfg.RateLimit.LoginBurst != 9 {
+		t.Errorf("RateLimit.LoginBurst = %d, want 9", cfg.RateLimit.LoginBurst)
+	}
+	if cfg.Cache.TTL != 45*time.Second {
+		t.Errorf("Cache.TTL = %v, want 45s", cfg.Cache.TTL)
+	}
+	if cfg.RateLimit.IngestBurst != 70 {
+		t.Errorf("RateLimit.IngestBurst = %d, want 70 (environment takes precedence)", cfg.RateLimit.IngestBurst)
+	}
+}
+
+func TestLoad_MalformedConfigFile(t *testing.T) {
+	writeConfigFile(t, "LOG_LEVEL=debug\nnot a setting\n")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Load() error = %v, want an error naming line 2", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		envVars  map[string]string
+		wantErrs []string
+	}{
+		{
+			name:    "valid configuration",
+			envVars: map[string]string{"JWT_SECRET": "production-secret"},
+		},
+		{
+			name:    "development defaults",
+			envVars: map[string]string{"DEV_MODE": "true"},
+		},
+		{
+			name:     "missing JWT secret",
+			wantErrs: []string{"JWT_SECRET or JWT_SECRET_REF is required unless DEV_MODE=true"},
+		},
+		{
+			name: "malformed values",
+			envVars: map[string]string{
+				"DEV_MODE":                 "true",
+				"CACHE_TTL":                "30 seconds",
+				"DB_MAX_CONNECTIONS":       "many",
+				"INGEST_DEDUPLICATE":       "sometimes",
+				"INGEST_SHED_THRESHOLD":    "high",
+				"JWT_SECRET_FILE":          "/nonexistent/jwt_secret",
+				"SESSION_SUMMARY_INTERVAL": "5m",
+			},
+			wantErrs: []string{
+				`invalid CACHE_TTL "30 seconds"`,
+				`invalid DB_MAX_CONNECTIONS "many"`,
+				`invalid INGEST_DEDUPLICATE "sometimes"`,
+				`invalid INGEST_SHED_THRESHOLD "high"`,
+				"failed to read JWT_SECRET_FILE",
+			},
+		},
+		{
+			name: "unknown settings",
+			file: "DEV_MODE=true\nLOG_LEVL=debug\nMAILGUN_API_KEY_FILE=/run/secrets/mailgun\n",
+			envVars: map[string]string{
+				"RATE_LIMIT_LOGIN_PER_MINUT": "10",
+				"UNRELATED_VARIABLE":         "ignored",
+			},
+			wantErrs: []string{
+				"unknown setting LOG_LEVL",
+				"unknown setting RATE_LIMIT_LOGIN_PER_MINUT",
+				"failed to read MAILGUN_API_KEY_FILE",
+			},
+		},
+		{
+			name: "invalid configuration",
+			envVars: map[string]string{
+				"DEV_MODE":       "true",
+				"EMAIL_PROVIDER": "mailgun",
+			},
+			wantErrs: []string{"MAILGUN_API_KEY is required when EMAIL_PROVIDER=mailgun"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanEmailEnv()
+			t.Setenv("JWT_SECRET", "")
+			if tt.file != "" {
+				writeConfigFile(t, tt.file)
+			}
+			for key, value := range tt.envVars {
+				t.Setenv(key, value)
+			}
+
+			err := Check()
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("Check() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Check() returned no error, want %v", tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Check() error = %q, want it to contain %q", err.Error(), want)
+				}
+			}
+			if strings.Contains(err.Error(), "UNRELATED_VARIABLE") {
+				t.Errorf("Check() reported an unrelated environment variable: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfig_Apply(t *testing.T) {
+	path := writeConfigFile(t, "LOG_LEVEL=info\nRATE_LIMIT_LOGIN_BURST=5\nDB_MAX_CONNECTIONS=25\n")
+	current, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("LOG_LEVEL=debug\nRATE_LIMIT_LOGIN_BURST=8\nDB_MAX_CONNECTIONS=50\n"), 0o600); err != nil {
+		t.Fatalf("Failed to rewrite config file: %v", err)
+	}
+	next, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	updated, applied, restart := current.Apply(next)
+	if want := []string{"LOG_LEVEL", "RATE_LIMIT_LOGIN_BURST"}; !slices.Equal(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+	if want := []string{"DB_MAX_CONNECTIONS"}; !slices.Equal(restart, want) {
+		t.Errorf("restart = %v, want %v", restart, want)
+	}
+	if updated.Logging.Level != "debug" || updated.RateLimit.LoginBurst != 8 {
+		t.Errorf("Reloadable settings not applied: %+v %+v", updated.Logging, updated.RateLimit)
+	}
+	if updated.Database.MaxConnections != 25 {
+		t.Errorf("Database.MaxConnections = %d, want 25 until restart", updated.Database.MaxConnections)
+	}
+	if current.Logging.Level != "info" {
+		t.Error("Apply modified the current configuration")
+	}
+
+	// Reloading the same settings again applies nothing, but still reports the pending restart
+	_, applied, restart = updated.Apply(next)
+	if len(applied) != 0 || !slices.Equal(restart, []string{"DB_MAX_CONNECTIONS"}) {
+		t.Errorf("Second reload: applied = %v, restart = %v", applied, restart)
+	}
+}
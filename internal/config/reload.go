@@ -0,0 +1,51 @@
+package config
+
+import (
+	"maps"
+	"slices"
+)
+
+// reloadable maps the settings that can change while the server is running to the fields they set
+// Everything else is only read at startup.
+var reloadable = map[string]func(dst, src *Config){
+	"LOG_LEVEL": func(dst, src *Config) { dst.Logging.Level = src.Logging.Level },
+	"RATE_LIMIT_LOGIN_PER_MINUTE": func(dst, src *Config) {
+		dst.RateLimit.LoginPerMinute = src.RateLimit.LoginPerMinute
+	},
+	"RATE_LIMIT_LOGIN_BURST": func(dst, src *Config) { dst.RateLimit.LoginBurst = src.RateLimit.LoginBurst },
+	"RATE_LIMIT_FORGOT_PASSWORD_PER_MINUTE": func(dst, src *Config) {
+		dst.RateLimit.ForgotPasswordPerMinute = src.RateLimit.ForgotPasswordPerMinute
+	},
+	"RATE_LIMIT_FORGOT_PASSWORD_BURST": func(dst, src *Config) {
+		dst.RateLimit.ForgotPasswordBurst = src.RateLimit.ForgotPasswordBurst
+	},
+	"RATE_LIMIT_INGEST_PER_MINUTE": func(dst, src *Config) {
+		dst.RateLimit.IngestPerMinute = src.RateLimit.IngestPerMinute
+	},
+	"RATE_LIMIT_INGEST_BURST": func(dst, src *Config) { dst.RateLimit.IngestBurst = src.RateLimit.IngestBurst },
+}
+
+// Apply returns a copy of c with the reloadable settings that differ in next applied
+// It also lists the settings applied and the changed settings that only take effect after a restart,
+// which keep their current values. Settings are compared by raw value, so both configurations must come from Load.
+func (c *Config) Apply(next *Config) (updated *Config, applied, restart []string) {
+	copied := *c
+	copied.settings = make(map[string]string, len(c.settings))
+	maps.Copy(copied.settings, c.settings)
+
+	keys := slices.Sorted(maps.Keys(next.settings))
+	for _, key := range keys {
+		if next.settings[key] == c.settings[key] {
+			continue
+		}
+		apply, ok := reloadable[key]
+		if !ok {
+			restart = append(restart, key)
+			continue
+		}
+		apply(&copied, next)
+		copied.settings[key] = next.settings[key]
+		applied = append(applied, key)
+	}
+	return &copied, applied, restart
+}
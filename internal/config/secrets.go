@@ -1,11 +1,6 @@
 // Package config provides configuration management for the AVT service.
 package config
 
-import (
-	"os"
-	"strings"
-)
-
 // GetSecret retrieves a secret with multiple fallback sources.
 // Priority:
 //  1. Direct environment variable (e.g., MAILGUN_API_KEY)
@@ -16,18 +11,5 @@ import (
 //   - Environment variables (e.g., MAILGUN_API_KEY=xxx)
 //   - Docker secrets (e.g., MAILGUN_API_KEY_FILE=/run/secrets/mailgun_api_key)
 func GetSecret(envVar, defaultValue string) string {
-	// 1. Try direct environment variable
-	if value := os.Getenv(envVar); value != "" {
-		return value
-	}
-
-	// 2. Try file-based secret (for Docker secrets)
-	if filePath := os.Getenv(envVar + "_FILE"); filePath != "" {
-		if data, err := os.ReadFile(filePath); err == nil {
-			return strings.TrimSpace(string(data))
-		}
-	}
-
-	// 3. Return default value
-	return defaultValue
+	return (&loader{}).getSecret(envVar, defaultValue)
 }
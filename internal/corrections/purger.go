@@ -0,0 +1,86 @@
+// Package corrections purges telemetry deleted by corrections once it can no longer be restored.
+package corrections
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// DefaultSweepInterval is how often expired corrections are purged
+	DefaultSweepInterval = 15 * time.Minute
+
+	// sweepBatchSize caps the number of corrections purged per sweep
+	sweepBatchSize = 50
+)
+
+// Purger periodically deletes the records of corrections whose undo window has closed
+// Until then the records are only flagged, so the correction can be undone.
+type Purger struct {
+	repo     repository.TelemetryDeletionRepository
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewPurger creates a new purger
+func NewPurger(repo repository.TelemetryDeletionRepository, interval time.Duration) *Purger {
+	if interval <= 0 {
+		interval = DefaultSweepInterval
+	}
+
+	return &Purger{
+		repo:     repo,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run purges expired corrections periodically until the context is cancelled
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.sweep(ctx)
+		}
+	}
+}
+
+// sweep purges the corrections whose undo window closed first
+// Corrections undone since they were listed are skipped.
+func (p *Purger) sweep(ctx context.Context) {
+	ids, err := p.repo.ListExpired(ctx, p.now(), sweepBatchSize)
+	if err != nil {
+		slog.Error("Error listing expired telemetry deletions", "error", err)
+		return
+	}
+
+	var purged int64
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		n, err := p.repo.Purge(ctx, id)
+		if err != nil {
+			if !errors.Is(err, repository.ErrTelemetryDeletionNotFound) {
+				slog.Error("Error purging deleted telemetry", "deletion_id", id, "error", err)
+			}
+			continue
+		}
+		purged += n
+	}
+
+	if purged > 0 {
+		slog.Info("Telemetry corrections: purged deleted records", "count", purged)
+	}
+}
@@ -0,0 +1,46 @@
+package corrections
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurger_Sweep(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expired, undone, failing := uuid.New(), uuid.New(), uuid.New()
+	var purged []uuid.UUID
+
+	repo := repository.NewMockTelemetryDeletionRepository()
+	repo.ListExpiredFunc = func(_ context.Context, at time.Time, _ int) ([]uuid.UUID, error) {
+		assert.Equal(t, now, at)
+		return []uuid.UUID{expired, undone, failing}, nil
+	}
+	repo.PurgeFunc = func(_ context.Context, id uuid.UUID) (int64, error) {
+		switch id {
+		case undone:
+			return 0, repository.ErrTelemetryDeletionNotFound
+		case failing:
+			return 0, errors.New("connection reset")
+		}
+		purged = append(purged, id)
+		return 3, nil
+	}
+
+	purger := NewPurger(repo, time.Hour)
+	purger.now = func() time.Time { return now }
+	purger.sweep(context.Background())
+
+	assert.Equal(t, []uuid.UUID{expired}, purged)
+}
+
+func TestNewPurger_DefaultInterval(t *testing.T) {
+	purger := NewPurger(repository.NewMockTelemetryDeletionRepository(), 0)
+
+	assert.Equal(t, DefaultSweepInterval, purger.interval)
+}
@@ -6,40 +6,95 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
+	"github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/tracing"
 )
 
-// DB wraps the sql.DB connection pool
+// DB wraps the sql.DB connection pool of the primary and, optionally, a read replica
 type DB struct {
 	*sql.DB
+	replica *replica // Optional: nil sends every query to the primary
+}
+
+// replica is a read-only connection pool whose health is checked in the background
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+	stop    chan struct{}
+	done    chan struct{}
 }
 
 // New creates a new database connection pool
+// Every query is traced through the global OpenTelemetry provider, which records nothing
+// unless tracing is enabled. When a replica is configured it is opened alongside the primary;
+// an unreachable replica does not fail startup, reads fall back to the primary until it recovers.
 func New(cfg *config.DatabaseConfig) (*DB, error) {
-	db, err := sql.Open("pgx", cfg.ConnectionString())
+	db, err := open(cfg.ConnectionString(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	if cfg.ReplicaURL == "" {
+		return &DB{DB: db}, nil
+	}
+
+	replicaDB, err := open(cfg.ReplicaURL, cfg)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open replica: %w", err)
+	}
+	r := &replica{db: replicaDB, stop: make(chan struct{}), done: make(chan struct{})}
+	r.check()
+	go r.run(cfg.ReplicaHealthInterval)
+
+	return &DB{DB: db, replica: r}, nil
+}
+
+// open creates a traced connection pool for dsn with the configured pool limits
+func open(dsn string, cfg *config.DatabaseConfig) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	connConfig.Tracer = tracing.NewQueryTracer(nil)
+	db := stdlib.OpenDB(*connConfig)
 
 	// Configure connection pool
 	db.SetMaxOpenConns(cfg.MaxConnections)
 	db.SetMaxIdleConns(cfg.MaxIdleConnections)
 	db.SetConnMaxLifetime(cfg.ConnectionMaxLifetime)
 
-	// Verify connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	return db, nil
+}
 
-	if err := db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+// Reader returns the pool for read queries that tolerate replication lag
+// It is the replica while it passes health checks and the primary otherwise.
+func (db *DB) Reader() *sql.DB {
+	if db.replica != nil && db.replica.healthy.Load() {
+		return db.replica.db
 	}
+	return db.DB
+}
 
-	return &DB{db}, nil
+// ReplicaHealthy reports whether a replica is configured and currently serving reads
+func (db *DB) ReplicaHealthy() bool {
+	return db.replica != nil && db.replica.healthy.Load()
 }
 
 // HealthCheck checks if the database is healthy
@@ -54,17 +109,58 @@ func (db *DB) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
-// Close closes the database connection
+// Close closes the database connection pools
 func (db *DB) Close() error {
+	if db.replica != nil {
+		close(db.replica.stop)
+		<-db.replica.done
+		if err := db.replica.db.Close(); err != nil {
+			slog.Error("Error closing replica", "error", err)
+		}
+	}
 	return db.DB.Close()
 }
 
-// IsUniqueViolation checks if the error is a PostgreSQL unique constraint violation
+// run checks the replica's health every interval until stopped
+func (r *replica) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.check()
+		}
+	}
+}
+
+// check pings the replica and logs when it starts or stops serving reads
+func (r *replica) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := r.db.PingContext(ctx)
+	healthy := err == nil
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		slog.Info("Database replica is healthy, serving reads from it")
+	} else {
+		slog.Warn("Database replica is unhealthy, serving reads from the primary", "error", err)
+	}
+}
+
+// IsUniqueViolation checks if the error is a PostgreSQL or SQLite unique constraint violation
 func IsUniqueViolation(err error) bool {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
 		// 23505 is the PostgreSQL error code for unique_violation
 		return pgErr.Code == "23505"
 	}
-	return false
+	return isSQLiteUniqueViolation(err)
 }
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_FallsBackToPrimary(t *testing.T) {
+	primary, err := sql.Open("pgx", "postgres://user@127.0.0.1:1/primary")
+	require.NoError(t, err)
+	defer primary.Close()
+
+	// Nothing listens on port 1, so the replica fails its health check
+	replicaDB, err := sql.Open("pgx", "postgres://user@127.0.0.1:1/replica?connect_timeout=1")
+	require.NoError(t, err)
+	defer replicaDB.Close()
+
+	db := &DB{DB: primary}
+	assert.Same(t, primary, db.Reader())
+	assert.False(t, db.ReplicaHealthy())
+
+	r := &replica{db: replicaDB}
+	r.healthy.Store(true)
+	db.replica = r
+	assert.Same(t, replicaDB, db.Reader())
+
+	r.check()
+	assert.False(t, db.ReplicaHealthy())
+	assert.Same(t, primary, db.Reader())
+}
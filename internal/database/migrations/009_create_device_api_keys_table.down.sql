@@ -0,0 +1,4 @@
+-- Drop device_api_keys table and related objects
+DROP INDEX IF EXISTS idx_device_api_keys_hash;
+DROP INDEX IF EXISTS idx_device_api_keys_device;
+DROP TABLE IF EXISTS device_api_keys;
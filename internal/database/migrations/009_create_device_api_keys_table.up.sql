@@ -0,0 +1,16 @@
+-- Create device_api_keys table for unattended device authentication
+CREATE TABLE device_api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255), -- User-friendly label (e.g., "Garage logger")
+    key_prefix VARCHAR(16) NOT NULL, -- First characters of the key, for identification in listings
+    key_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA256 hash of the full key
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+-- Indexes for efficient queries
+CREATE INDEX idx_device_api_keys_device ON device_api_keys(device_id, created_at DESC);
+CREATE INDEX idx_device_api_keys_hash ON device_api_keys(key_hash) WHERE revoked_at IS NULL;
@@ -0,0 +1,3 @@
+-- Remove session summary tracking
+DROP INDEX IF EXISTS idx_sessions_summary_pending;
+ALTER TABLE sessions DROP COLUMN IF EXISTS summary_computed_at;
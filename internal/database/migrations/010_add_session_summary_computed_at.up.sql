@@ -0,0 +1,6 @@
+-- Track when cached session aggregates were last computed from telemetry
+ALTER TABLE sessions ADD COLUMN summary_computed_at TIMESTAMPTZ;
+
+-- Index to find ended sessions whose summary still needs computing
+CREATE INDEX idx_sessions_summary_pending ON sessions (ended_at)
+    WHERE ended_at IS NOT NULL AND summary_computed_at IS NULL;
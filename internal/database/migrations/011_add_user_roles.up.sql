@@ -0,0 +1,8 @@
+-- Add role-based access control to users
+-- Existing accounts default to the regular 'user' role; promote admins manually:
+--   UPDATE users SET role = 'admin' WHERE email = 'you@example.com';
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD CONSTRAINT chk_users_role CHECK (role IN ('user', 'admin'));
+
+-- Index for admin user listings filtered by role
+CREATE INDEX idx_users_role ON users (role);
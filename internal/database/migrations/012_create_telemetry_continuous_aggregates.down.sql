@@ -0,0 +1,4 @@
+-- Remove telemetry continuous aggregates (policies are dropped with the views)
+DROP MATERIALIZED VIEW IF EXISTS telemetry_10m;
+DROP MATERIALIZED VIEW IF EXISTS telemetry_1m;
+DROP MATERIALIZED VIEW IF EXISTS telemetry_1s;
@@ -0,0 +1,3 @@
+-- Drop tracks table
+DROP TRIGGER IF EXISTS update_tracks_updated_at ON tracks;
+DROP TABLE IF EXISTS tracks;
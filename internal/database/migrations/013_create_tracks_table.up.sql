@@ -0,0 +1,19 @@
+-- Create tracks table for user-defined circuits and their start/finish lines
+CREATE TABLE tracks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    -- Start/finish line endpoints (WGS84)
+    line_start_lat DOUBLE PRECISION NOT NULL,
+    line_start_lon DOUBLE PRECISION NOT NULL,
+    line_end_lat DOUBLE PRECISION NOT NULL,
+    line_end_lon DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_tracks_user ON tracks(user_id, created_at DESC);
+
+-- Trigger to automatically update updated_at timestamp
+CREATE TRIGGER update_tracks_updated_at BEFORE UPDATE ON tracks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,5 @@
+-- Drop geofence tables
+DROP TABLE IF EXISTS geofence_events;
+DROP TABLE IF EXISTS geofence_states;
+DROP TRIGGER IF EXISTS update_geofences_updated_at ON geofences;
+DROP TABLE IF EXISTS geofences;
@@ -0,0 +1,49 @@
+-- Create geofences table for user-defined circular and polygon areas
+CREATE TABLE geofences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL, -- 'circle' or 'polygon'
+    center_lat DOUBLE PRECISION, -- Circle center (circle only)
+    center_lon DOUBLE PRECISION,
+    radius_m DOUBLE PRECISION, -- Circle radius in meters (circle only)
+    polygon JSONB, -- Array of {latitude, longitude} vertices (polygon only)
+    notify_email BOOLEAN NOT NULL DEFAULT FALSE,
+    webhook_url TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_geofences_kind CHECK (kind IN ('circle', 'polygon'))
+);
+
+CREATE INDEX idx_geofences_user ON geofences(user_id, created_at DESC);
+CREATE INDEX idx_geofences_active ON geofences(user_id) WHERE is_active = TRUE;
+
+CREATE TRIGGER update_geofences_updated_at BEFORE UPDATE ON geofences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Last known inside/outside state per geofence and device, used to detect transitions
+CREATE TABLE geofence_states (
+    geofence_id UUID NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+    device_id VARCHAR(50) NOT NULL,
+    inside BOOLEAN NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL, -- Timestamp of the telemetry that set the state
+    PRIMARY KEY (geofence_id, device_id)
+);
+
+-- Enter/exit events emitted during ingestion
+CREATE TABLE geofence_events (
+    id BIGSERIAL PRIMARY KEY,
+    geofence_id UUID NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id VARCHAR(50) NOT NULL,
+    event_type VARCHAR(10) NOT NULL, -- 'enter' or 'exit'
+    recorded_at TIMESTAMPTZ NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_geofence_events_type CHECK (event_type IN ('enter', 'exit'))
+);
+
+CREATE INDEX idx_geofence_events_user ON geofence_events(user_id, recorded_at DESC);
+CREATE INDEX idx_geofence_events_geofence ON geofence_events(geofence_id, recorded_at DESC);
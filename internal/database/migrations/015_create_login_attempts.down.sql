@@ -0,0 +1,3 @@
+-- Drop login attempt tracking
+DROP TABLE IF EXISTS account_lockouts;
+DROP TABLE IF EXISTS login_attempts;
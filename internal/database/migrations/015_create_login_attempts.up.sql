@@ -0,0 +1,19 @@
+-- Track failed login attempts for account lockout and per-IP throttling
+CREATE TABLE login_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    -- NULL when the email does not belong to an account
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    ip_address INET,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_login_attempts_user ON login_attempts(user_id, attempted_at DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_login_attempts_ip ON login_attempts(ip_address, attempted_at DESC);
+
+-- Temporarily locked accounts; rows are removed on successful login or password reset
+CREATE TABLE account_lockouts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    locked_until TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
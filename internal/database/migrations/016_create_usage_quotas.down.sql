@@ -0,0 +1,3 @@
+-- Drop usage quotas
+DROP TABLE IF EXISTS usage_counters;
+DROP TABLE IF EXISTS user_plans;
@@ -0,0 +1,3 @@
+-- Drop the circuit catalog
+ALTER TABLE sessions DROP COLUMN IF EXISTS circuit_id;
+DROP TABLE IF EXISTS circuits;
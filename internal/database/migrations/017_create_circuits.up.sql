@@ -0,0 +1,27 @@
+-- Catalog of known circuits used to detect where a session was recorded
+CREATE TABLE circuits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    country VARCHAR(2) NOT NULL, -- ISO 3166-1 alpha-2
+    boundary GEOGRAPHY(POLYGON, 4326) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_circuits_boundary ON circuits USING GIST (boundary);
+
+-- Circuit detected from the session's telemetry when it was summarized
+ALTER TABLE sessions ADD COLUMN circuit_id UUID REFERENCES circuits(id) ON DELETE SET NULL;
+
+-- Seed well-known circuits; boundaries are approximate envelopes around each venue
+INSERT INTO circuits (name, country, boundary) VALUES
+    ('Circuit de Spa-Francorchamps', 'BE', ST_MakeEnvelope(5.9550, 50.4270, 5.9850, 50.4460, 4326)::geography),
+    ('Silverstone Circuit', 'GB', ST_MakeEnvelope(-1.0300, 52.0600, -0.9990, 52.0820, 4326)::geography),
+    ('Brands Hatch', 'GB', ST_MakeEnvelope(0.2550, 51.3540, 0.2680, 51.3620, 4326)::geography),
+    ('Nürburgring', 'DE', ST_MakeEnvelope(6.9200, 50.3200, 7.0100, 50.3850, 4326)::geography),
+    ('Autodromo Nazionale Monza', 'IT', ST_MakeEnvelope(9.2700, 45.6080, 9.2950, 45.6350, 4326)::geography),
+    ('Circuit de Barcelona-Catalunya', 'ES', ST_MakeEnvelope(2.2520, 41.5630, 2.2670, 41.5760, 4326)::geography),
+    ('Serres Racing Circuit', 'GR', ST_MakeEnvelope(23.5060, 41.0680, 23.5180, 41.0760, 4326)::geography),
+    ('WeatherTech Raceway Laguna Seca', 'US', ST_MakeEnvelope(-121.7600, 36.5800, -121.7480, 36.5900, 4326)::geography),
+    ('Circuit of the Americas', 'US', ST_MakeEnvelope(-97.6480, 30.1250, -97.6280, 30.1400, 4326)::geography),
+    ('Suzuka Circuit', 'JP', ST_MakeEnvelope(136.5280, 34.8380, 136.5450, 34.8500, 4326)::geography),
+    ('Sepang International Circuit', 'MY', ST_MakeEnvelope(101.7250, 2.7520, 101.7450, 2.7680, 4326)::geography);
@@ -0,0 +1,2 @@
+-- Drop import jobs
+DROP TABLE IF EXISTS import_jobs;
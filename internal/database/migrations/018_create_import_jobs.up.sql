@@ -0,0 +1,25 @@
+-- Background imports of historical recordings (e.g., RaceBox CSV exports)
+CREATE TABLE import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    device_id VARCHAR(50) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    total_rows INTEGER NOT NULL,
+    imported_rows INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_import_jobs_user ON import_jobs(user_id, created_at DESC);
+
+-- Index to find jobs left unfinished by a restart
+CREATE INDEX idx_import_jobs_incomplete ON import_jobs(status)
+    WHERE status IN ('pending', 'processing');
+
+-- Trigger to automatically update updated_at timestamp
+CREATE TRIGGER update_import_jobs_updated_at BEFORE UPDATE ON import_jobs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
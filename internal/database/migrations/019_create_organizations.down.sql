@@ -0,0 +1,9 @@
+-- Drop organization ownership and organizations
+DROP INDEX IF EXISTS idx_sessions_org;
+DROP INDEX IF EXISTS idx_devices_org;
+ALTER TABLE sessions DROP COLUMN IF EXISTS org_id;
+ALTER TABLE devices DROP COLUMN IF EXISTS org_id;
+
+DROP TABLE IF EXISTS organization_invitations;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,4 @@
+-- Drop device health tables
+DROP TABLE IF EXISTS device_health_events;
+DROP TABLE IF EXISTS device_health_snapshots;
+DROP TABLE IF EXISTS device_health_states;
@@ -0,0 +1,46 @@
+-- Create device health tables for battery and GPS fix monitoring
+
+-- Latest known health of each device, used to detect transitions across uploads
+CREATE TABLE device_health_states (
+    device_id VARCHAR(50) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    battery DOUBLE PRECISION NOT NULL,
+    is_charging BOOLEAN NOT NULL DEFAULT FALSE,
+    last_recorded_at TIMESTAMPTZ NOT NULL,
+    last_fix_at TIMESTAMPTZ,
+    no_fix_since TIMESTAMPTZ, -- Start of the current run of telemetry without a GPS fix
+    low_battery_alerted BOOLEAN NOT NULL DEFAULT FALSE,
+    no_fix_alerted BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Health aggregated over fixed time buckets; uploads covering the same bucket are merged
+CREATE TABLE device_health_snapshots (
+    device_id VARCHAR(50) NOT NULL,
+    bucket_start TIMESTAMPTZ NOT NULL,
+    samples INTEGER NOT NULL,
+    fix_samples INTEGER NOT NULL,
+    battery_min DOUBLE PRECISION NOT NULL,
+    battery_max DOUBLE PRECISION NOT NULL,
+    battery_last DOUBLE PRECISION NOT NULL,
+    is_charging BOOLEAN NOT NULL DEFAULT FALSE,
+    satellites_sum BIGINT NOT NULL DEFAULT 0,
+    horizontal_accuracy_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+    last_recorded_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (device_id, bucket_start)
+);
+
+-- Low battery and prolonged loss of GPS fix
+CREATE TABLE device_health_events (
+    id BIGSERIAL PRIMARY KEY,
+    device_id VARCHAR(50) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL, -- 'low_battery' or 'no_fix'
+    recorded_at TIMESTAMPTZ NOT NULL,
+    battery DOUBLE PRECISION NOT NULL,
+    no_fix_since TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_device_health_events_type CHECK (event_type IN ('low_battery', 'no_fix'))
+);
+
+CREATE INDEX idx_device_health_events_device ON device_health_events(device_id, recorded_at DESC);
@@ -0,0 +1,3 @@
+-- Remove cached session statistics
+ALTER TABLE sessions DROP COLUMN IF EXISTS stats_computed_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS stats;
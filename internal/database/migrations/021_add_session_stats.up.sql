@@ -0,0 +1,3 @@
+-- Cache detailed per-session telemetry statistics computed on demand
+ALTER TABLE sessions ADD COLUMN stats JSONB;
+ALTER TABLE sessions ADD COLUMN stats_computed_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- Drop device pre-registrations
+DROP TABLE IF EXISTS device_registrations;
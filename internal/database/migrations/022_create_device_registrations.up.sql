@@ -0,0 +1,16 @@
+-- Pre-registrations of devices that upload before they have an owner
+-- A device registers once and shows its claim code; a user adopts it by presenting the code,
+-- after which a background job assigns the device's anonymous history to them.
+CREATE TABLE device_registrations (
+    device_id VARCHAR(50) PRIMARY KEY, -- Hardware device ID
+    claim_code_hash VARCHAR(64) NOT NULL, -- SHA256 hash of the claim code
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    adopted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    adopted_at TIMESTAMPTZ,
+    backfilled_at TIMESTAMPTZ, -- When anonymous telemetry, sessions and batches were assigned to the adopter
+    backfilled_records BIGINT NOT NULL DEFAULT 0
+);
+
+-- Adopted registrations still waiting for their backfill
+CREATE INDEX idx_device_registrations_backfill_pending ON device_registrations(adopted_at)
+    WHERE adopted_at IS NOT NULL AND backfilled_at IS NULL;
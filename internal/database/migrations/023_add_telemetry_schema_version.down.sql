@@ -0,0 +1,3 @@
+-- Remove telemetry schema versioning
+ALTER TABLE telemetry DROP COLUMN IF EXISTS extras;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS schema_version;
@@ -0,0 +1,4 @@
+-- Track the upload schema version of each telemetry record
+-- Channels a record's version does not define are kept in extras instead of being rejected.
+ALTER TABLE telemetry ADD COLUMN schema_version SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE telemetry ADD COLUMN extras JSONB;
@@ -0,0 +1,92 @@
+-- Recreate the continuous aggregates without vehicle channels and remove them from telemetry
+-- refresh continuous aggregates: telemetry_1s, telemetry_1m, telemetry_10m
+DROP MATERIALIZED VIEW IF EXISTS telemetry_10m;
+DROP MATERIALIZED VIEW IF EXISTS telemetry_1m;
+DROP MATERIALIZED VIEW IF EXISTS telemetry_1s;
+
+CREATE MATERIALIZED VIEW telemetry_1s
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 second', recorded_at) AS bucket,
+    user_id,
+    device_id,
+    session_id,
+    COUNT(*) AS sample_count,
+    AVG(speed) AS avg_speed,
+    MAX(speed) AS max_speed,
+    AVG(g_force_x) AS avg_g_force_x,
+    AVG(g_force_y) AS avg_g_force_y,
+    AVG(g_force_z) AS avg_g_force_z,
+    MAX(SQRT(g_force_x * g_force_x + g_force_y * g_force_y)) AS max_g_force,
+    AVG(battery) AS avg_battery,
+    MIN(battery) AS min_battery
+FROM telemetry
+GROUP BY bucket, user_id, device_id, session_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW telemetry_1m
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 minute', recorded_at) AS bucket,
+    user_id,
+    device_id,
+    session_id,
+    COUNT(*) AS sample_count,
+    AVG(speed) AS avg_speed,
+    MAX(speed) AS max_speed,
+    AVG(g_force_x) AS avg_g_force_x,
+    AVG(g_force_y) AS avg_g_force_y,
+    AVG(g_force_z) AS avg_g_force_z,
+    MAX(SQRT(g_force_x * g_force_x + g_force_y * g_force_y)) AS max_g_force,
+    AVG(battery) AS avg_battery,
+    MIN(battery) AS min_battery
+FROM telemetry
+GROUP BY bucket, user_id, device_id, session_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW telemetry_10m
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '10 minutes', recorded_at) AS bucket,
+    user_id,
+    device_id,
+    session_id,
+    COUNT(*) AS sample_count,
+    AVG(speed) AS avg_speed,
+    MAX(speed) AS max_speed,
+    AVG(g_force_x) AS avg_g_force_x,
+    AVG(g_force_y) AS avg_g_force_y,
+    AVG(g_force_z) AS avg_g_force_z,
+    MAX(SQRT(g_force_x * g_force_x + g_force_y * g_force_y)) AS max_g_force,
+    AVG(battery) AS avg_battery,
+    MIN(battery) AS min_battery
+FROM telemetry
+GROUP BY bucket, user_id, device_id, session_id
+WITH NO DATA;
+
+-- Indexes for user-scoped chart queries
+CREATE INDEX idx_telemetry_1s_user ON telemetry_1s (user_id, bucket DESC);
+CREATE INDEX idx_telemetry_1m_user ON telemetry_1m (user_id, bucket DESC);
+CREATE INDEX idx_telemetry_10m_user ON telemetry_10m (user_id, bucket DESC);
+
+-- Refresh policies; windows stay inside the 7-day compression horizon
+SELECT add_continuous_aggregate_policy('telemetry_1s',
+    start_offset => INTERVAL '1 day',
+    end_offset => INTERVAL '1 minute',
+    schedule_interval => INTERVAL '1 minute');
+
+SELECT add_continuous_aggregate_policy('telemetry_1m',
+    start_offset => INTERVAL '3 days',
+    end_offset => INTERVAL '2 minutes',
+    schedule_interval => INTERVAL '5 minutes');
+
+SELECT add_continuous_aggregate_policy('telemetry_10m',
+    start_offset => INTERVAL '6 days',
+    end_offset => INTERVAL '20 minutes',
+    schedule_interval => INTERVAL '30 minutes');
+
+ALTER TABLE telemetry DROP COLUMN IF EXISTS gear;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS coolant_temp;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS brake_pressure;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS throttle;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS rpm;
@@ -0,0 +1,3 @@
+-- Remove client-generated telemetry record IDs
+DROP INDEX IF EXISTS idx_telemetry_client_id;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS client_id;
@@ -0,0 +1,8 @@
+-- Client-generated record IDs make single-point uploads idempotent
+-- A retried upload carries the same clientId and timestamp, so it conflicts with the stored row
+-- instead of duplicating it. The index includes recorded_at because unique indexes on a
+-- hypertable must include its time column.
+ALTER TABLE telemetry ADD COLUMN client_id UUID;
+
+CREATE UNIQUE INDEX idx_telemetry_client_id ON telemetry (client_id, recorded_at)
+    WHERE client_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Drop the ingest audit log
+DROP TABLE IF EXISTS ingest_audit_log;
+DROP FUNCTION IF EXISTS reject_ingest_audit_log_change();
@@ -0,0 +1,36 @@
+-- Create the append-only ingest audit log
+-- One row per upload request or MQTT message. There are no foreign keys, so entries
+-- outlive the users and devices they mention; old chunks are dropped by a retention policy.
+CREATE TABLE ingest_audit_log (
+    id BIGSERIAL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    source VARCHAR(20) NOT NULL, -- 'single', 'batch', 'stream' or 'mqtt'
+    user_id UUID,
+    device_id VARCHAR(50),
+    api_key_id UUID,
+    records INTEGER NOT NULL DEFAULT 0,
+    source_ip VARCHAR(45),
+    status INTEGER, -- HTTP status code; NULL for MQTT
+    result VARCHAR(20) NOT NULL, -- 'accepted', 'rejected' or 'failed'
+    error TEXT,
+    latency_ms DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (created_at, id),
+    CONSTRAINT chk_ingest_audit_log_result CHECK (result IN ('accepted', 'rejected', 'failed'))
+);
+
+SELECT create_hypertable('ingest_audit_log', 'created_at', chunk_time_interval => INTERVAL '1 day');
+
+CREATE INDEX idx_ingest_audit_log_user ON ingest_audit_log (user_id, created_at DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_ingest_audit_log_device ON ingest_audit_log (device_id, created_at DESC) WHERE device_id IS NOT NULL;
+CREATE INDEX idx_ingest_audit_log_ip ON ingest_audit_log (source_ip, created_at DESC);
+
+-- Entries are never changed once written; retention drops whole chunks instead of deleting rows
+CREATE FUNCTION reject_ingest_audit_log_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ingest_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_ingest_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON ingest_audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_ingest_audit_log_change();
@@ -0,0 +1,3 @@
+-- Drop resumable uploads
+DROP TABLE IF EXISTS upload_chunks;
+DROP TABLE IF EXISTS uploads;
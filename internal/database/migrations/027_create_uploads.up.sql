@@ -0,0 +1,37 @@
+-- Resumable uploads of large offline backlogs, assembled from chunks and ingested in the background
+CREATE TABLE uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL CHECK (size > 0),
+    received_bytes BIGINT NOT NULL DEFAULT 0,
+    processed_bytes BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('open', 'pending', 'processing', 'completed', 'failed')),
+    lines INTEGER NOT NULL DEFAULT 0,
+    accepted INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    rejected INTEGER NOT NULL DEFAULT 0,
+    line_errors JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_uploads_user ON uploads(user_id, created_at DESC);
+
+-- Index to find uploads waiting to be ingested and abandoned open uploads
+CREATE INDEX idx_uploads_unfinished ON uploads(status, updated_at)
+    WHERE status IN ('open', 'pending', 'processing');
+
+-- Trigger to automatically update updated_at timestamp
+CREATE TRIGGER update_uploads_updated_at BEFORE UPDATE ON uploads
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Received chunks, deleted once the upload is ingested
+CREATE TABLE upload_chunks (
+    upload_id UUID NOT NULL REFERENCES uploads(id) ON DELETE CASCADE,
+    byte_offset BIGINT NOT NULL,
+    data BYTEA NOT NULL,
+    PRIMARY KEY (upload_id, byte_offset)
+);
@@ -0,0 +1,3 @@
+-- Remove remember-me logins and session start times from refresh tokens
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS session_started_at;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS remember_me;
@@ -0,0 +1,10 @@
+-- Remember-me logins and the absolute cap on sliding refresh token expiry
+-- session_started_at is copied to each rotated token, so every token in a chain knows when its
+-- client logged in. Existing tokens count their session from their own creation.
+ALTER TABLE refresh_tokens ADD COLUMN remember_me BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE refresh_tokens ADD COLUMN session_started_at TIMESTAMPTZ;
+
+UPDATE refresh_tokens SET session_started_at = created_at;
+
+ALTER TABLE refresh_tokens ALTER COLUMN session_started_at SET NOT NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN session_started_at SET DEFAULT NOW();
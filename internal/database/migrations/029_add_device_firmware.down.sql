@@ -0,0 +1,5 @@
+-- Remove device firmware versions
+DROP INDEX IF EXISTS idx_devices_firmware_version;
+
+ALTER TABLE devices DROP COLUMN IF EXISTS firmware_updated_at;
+ALTER TABLE devices DROP COLUMN IF EXISTS firmware_version;
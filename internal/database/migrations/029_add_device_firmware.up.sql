@@ -0,0 +1,7 @@
+-- Firmware version last reported by each device in the X-Firmware-Version header
+-- firmware_updated_at changes only when the reported version does, so it shows when a device
+-- was upgraded. The index serves the admin firmware distribution report.
+ALTER TABLE devices ADD COLUMN firmware_version TEXT;
+ALTER TABLE devices ADD COLUMN firmware_updated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_devices_firmware_version ON devices(firmware_version);
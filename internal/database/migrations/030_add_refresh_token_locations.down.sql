@@ -0,0 +1,7 @@
+-- Remove GeoIP locations from refresh tokens
+DROP INDEX IF EXISTS idx_refresh_tokens_user_country;
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS geo_city;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS geo_region;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS geo_country;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS geo_country_code;
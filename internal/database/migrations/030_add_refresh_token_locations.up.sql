@@ -0,0 +1,8 @@
+-- Coarse GeoIP location of the address each refresh token was issued to
+-- Login alerts compare a new login's country with the countries of the user's stored tokens.
+ALTER TABLE refresh_tokens ADD COLUMN geo_country_code TEXT;
+ALTER TABLE refresh_tokens ADD COLUMN geo_country TEXT;
+ALTER TABLE refresh_tokens ADD COLUMN geo_region TEXT;
+ALTER TABLE refresh_tokens ADD COLUMN geo_city TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_country ON refresh_tokens(user_id, geo_country_code) WHERE geo_country_code IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Remove telemetry quality flags
+ALTER TABLE telemetry DROP COLUMN IF EXISTS quality_flags;
@@ -0,0 +1,4 @@
+-- Flag physically impossible telemetry instead of rejecting it
+-- quality_flags is a bit set (1: impossible speed, 2: position jump, 4: negative PDOP); zero marks
+-- a clean point. Queries with quality=clean only read rows where it is zero.
+ALTER TABLE telemetry ADD COLUMN quality_flags SMALLINT NOT NULL DEFAULT 0;
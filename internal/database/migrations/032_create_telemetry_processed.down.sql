@@ -0,0 +1,4 @@
+-- Remove smoothed telemetry
+DROP INDEX IF EXISTS idx_sessions_processing_pending;
+ALTER TABLE sessions DROP COLUMN IF EXISTS processed_at;
+DROP TABLE IF EXISTS telemetry_processed;
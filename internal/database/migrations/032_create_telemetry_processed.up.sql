@@ -0,0 +1,21 @@
+-- Smoothed telemetry produced by the post-processing job once a session ends
+-- Each row belongs to one raw telemetry record. Queries with processed=true read the filtered
+-- position and speed from here in place of the raw ones.
+CREATE TABLE telemetry_processed (
+    telemetry_id BIGINT NOT NULL,
+    telemetry_recorded_at TIMESTAMPTZ NOT NULL,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    speed DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (telemetry_id, telemetry_recorded_at)
+);
+
+CREATE INDEX idx_telemetry_processed_session ON telemetry_processed (session_id);
+
+-- Track when a session's telemetry was last smoothed
+ALTER TABLE sessions ADD COLUMN processed_at TIMESTAMPTZ;
+
+-- Index to find ended sessions that still need processing
+CREATE INDEX idx_sessions_processing_pending ON sessions (ended_at)
+    WHERE ended_at IS NOT NULL AND processed_at IS NULL;
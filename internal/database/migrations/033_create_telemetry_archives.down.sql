@@ -0,0 +1,3 @@
+-- Remove telemetry archive manifests
+-- The archived Parquet files are left in object storage.
+DROP TABLE IF EXISTS telemetry_archives;
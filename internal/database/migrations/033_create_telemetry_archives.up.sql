@@ -0,0 +1,19 @@
+-- Manifests of telemetry moved from the hypertable to Parquet files in object storage
+-- Each archive holds one device's owned telemetry for one UTC day. Telemetry recorded for an
+-- archived day after it was archived becomes another archive of the same day.
+CREATE TABLE telemetry_archives (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(50) NOT NULL,
+    day DATE NOT NULL,
+    object_key TEXT NOT NULL UNIQUE,
+    regions TEXT[] NOT NULL,        -- Regions of the buckets holding a copy
+    user_ids UUID[] NOT NULL,       -- Owners of the archived records
+    row_count BIGINT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    first_recorded_at TIMESTAMPTZ NOT NULL,
+    last_recorded_at TIMESTAMPTZ NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_telemetry_archives_device_day ON telemetry_archives (device_id, day);
+CREATE INDEX idx_telemetry_archives_users ON telemetry_archives USING GIN (user_ids);
@@ -0,0 +1,2 @@
+-- Remove device tags
+DROP TABLE IF EXISTS device_tags;
@@ -0,0 +1,10 @@
+-- Free-form labels for organizing devices into fleets (e.g., "kart", "rental-fleet-A")
+CREATE TABLE device_tags (
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    tag VARCHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (device_id, tag)
+);
+
+-- Index for tag-filtered device and telemetry queries
+CREATE INDEX idx_device_tags_tag ON device_tags(tag);
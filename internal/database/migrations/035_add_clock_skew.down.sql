@@ -0,0 +1,9 @@
+-- Remove clock skew tracking
+ALTER TABLE device_health_snapshots DROP COLUMN IF EXISTS skewed_samples;
+ALTER TABLE device_health_snapshots DROP COLUMN IF EXISTS skew_abs_max_ms;
+ALTER TABLE device_health_snapshots DROP COLUMN IF EXISTS skew_sum_ms;
+ALTER TABLE device_health_snapshots DROP COLUMN IF EXISTS skew_samples;
+
+ALTER TABLE telemetry DROP COLUMN IF EXISTS device_recorded_at;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS clock_skew_ms;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS received_at;
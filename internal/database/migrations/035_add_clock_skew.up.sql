@@ -0,0 +1,13 @@
+-- Record when telemetry was received and how far the device clock was off
+-- clock_skew_ms is the device timestamp minus GPS or receive time, positive when the device clock
+-- runs ahead. device_recorded_at keeps the reported timestamp of records whose recorded_at was
+-- corrected for skew; it is NULL otherwise.
+ALTER TABLE telemetry ADD COLUMN received_at TIMESTAMPTZ;
+ALTER TABLE telemetry ADD COLUMN clock_skew_ms INTEGER;
+ALTER TABLE telemetry ADD COLUMN device_recorded_at TIMESTAMPTZ;
+
+-- Clock skew statistics per device health snapshot, merged across uploads like the other sums
+ALTER TABLE device_health_snapshots ADD COLUMN skew_samples INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE device_health_snapshots ADD COLUMN skew_sum_ms BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE device_health_snapshots ADD COLUMN skew_abs_max_ms BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE device_health_snapshots ADD COLUMN skewed_samples INTEGER NOT NULL DEFAULT 0;
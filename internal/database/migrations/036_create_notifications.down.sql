@@ -0,0 +1,3 @@
+-- Remove notifications and their preferences
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,27 @@
+-- Notification inbox: every notification is kept here whatever channels it was delivered over
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL, -- 'session_summary', 'low_battery' or 'new_login'
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for listing a user's inbox, most recent first
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at DESC);
+
+-- Partial index for unread counts
+CREATE INDEX idx_notifications_user_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Per-type delivery channels; types without a row use the defaults
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    email BOOLEAN NOT NULL,
+    push BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, type)
+);
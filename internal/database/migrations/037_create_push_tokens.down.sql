@@ -0,0 +1,2 @@
+-- Remove push tokens
+DROP TABLE IF EXISTS push_tokens;
@@ -0,0 +1,14 @@
+-- Mobile app installations registered for push notifications
+-- A token identifies one app installation, so it belongs to whichever user registered it last.
+CREATE TABLE push_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(16) NOT NULL, -- 'fcm' or 'apns'
+    token TEXT NOT NULL UNIQUE,
+    device_name VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for sending to all of a user's installations
+CREATE INDEX idx_push_tokens_user ON push_tokens(user_id);
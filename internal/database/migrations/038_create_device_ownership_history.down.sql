@@ -0,0 +1,2 @@
+-- Remove device ownership history
+DROP TABLE IF EXISTS device_ownership_history;
@@ -0,0 +1,15 @@
+-- Every change of a device's owner, for auditing disputed devices
+-- User references are kept as NULL when the account is deleted so the history survives it.
+CREATE TABLE device_ownership_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    action VARCHAR(16) NOT NULL, -- 'claim', 'adopt' or 'reassign'
+    from_user_id UUID REFERENCES users(id) ON DELETE SET NULL, -- NULL for the first owner
+    to_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL, -- The user who made the change
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for listing a device's history, oldest first
+CREATE INDEX idx_device_ownership_history_device ON device_ownership_history(device_id, created_at);
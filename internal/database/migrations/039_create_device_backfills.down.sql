@@ -0,0 +1,2 @@
+-- Drop device backfills
+DROP TABLE IF EXISTS device_backfills;
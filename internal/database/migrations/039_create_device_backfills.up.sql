@@ -0,0 +1,24 @@
+-- Backfills assigning the anonymous history of claimed devices to their owners, with their progress
+CREATE TABLE device_backfills (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    total_records BIGINT NOT NULL DEFAULT 0, -- Anonymous records found when the backfill started
+    assigned_records BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+-- Index for reading a device's latest backfill
+CREATE INDEX idx_device_backfills_device ON device_backfills(device_id, created_at DESC);
+
+-- At most one unfinished backfill per device, so repeated requests return the running one
+CREATE UNIQUE INDEX idx_device_backfills_incomplete ON device_backfills(device_id)
+    WHERE status IN ('pending', 'processing');
+
+-- Trigger to automatically update updated_at timestamp
+CREATE TRIGGER update_device_backfills_updated_at BEFORE UPDATE ON device_backfills
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
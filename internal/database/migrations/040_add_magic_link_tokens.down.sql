@@ -0,0 +1,5 @@
+-- Remove passwordless sign-in tokens
+DROP INDEX IF EXISTS idx_users_magic_link_token;
+
+ALTER TABLE users DROP COLUMN IF EXISTS magic_link_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS magic_link_token;
@@ -0,0 +1,6 @@
+-- One-time passwordless sign-in tokens
+-- magic_link_token holds the SHA-256 hash of the emailed token, like reset_token.
+ALTER TABLE users ADD COLUMN magic_link_token VARCHAR(255);
+ALTER TABLE users ADD COLUMN magic_link_expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_magic_link_token ON users(magic_link_token) WHERE magic_link_token IS NOT NULL;
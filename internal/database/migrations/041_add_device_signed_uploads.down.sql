@@ -0,0 +1,2 @@
+-- Remove signed upload enforcement
+ALTER TABLE devices DROP COLUMN IF EXISTS require_signed_uploads;
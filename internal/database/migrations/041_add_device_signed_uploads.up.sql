@@ -0,0 +1,3 @@
+-- Per-device enforcement of HMAC-signed uploads
+-- When set, uploads authenticated with one of the device's API keys must carry a valid X-Signature.
+ALTER TABLE devices ADD COLUMN require_signed_uploads BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+-- Remove session markers
+DROP TABLE IF EXISTS session_markers;
@@ -0,0 +1,13 @@
+-- Points of interest within a session (pit stops, incidents, sector splits)
+CREATE TABLE session_markers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL, -- Who added the marker
+    type VARCHAR(32) NOT NULL, -- 'pit_stop', 'incident' or 'sector'
+    label VARCHAR(255),
+    marked_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for listing a session's markers in time order
+CREATE INDEX idx_session_markers_session_marked ON session_markers(session_id, marked_at);
@@ -0,0 +1,143 @@
+-- Telemetry corrections: session trims and deleted time ranges
+-- Affected records are flagged with the correction's ID and hidden from every query until the
+-- undo window closes, when they are purged. Undoing a correction clears the flag.
+CREATE TABLE telemetry_deletions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- Owner of the deleted records
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    device_id VARCHAR(50) NOT NULL, -- Hardware device ID
+    session_id UUID REFERENCES sessions(id) ON DELETE SET NULL, -- Set for session trims
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('trim', 'range')),
+    starts_at TIMESTAMPTZ, -- Trims keep records from here; ranges delete records from here
+    ends_at TIMESTAMPTZ,   -- Trims keep records up to here; ranges delete records up to here
+    points BIGINT NOT NULL DEFAULT 0, -- Records flagged as deleted
+    first_recorded_at TIMESTAMPTZ,
+    last_recorded_at TIMESTAMPTZ,
+    undo_until TIMESTAMPTZ NOT NULL,
+    undone_at TIMESTAMPTZ,
+    purged_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for listing a user's corrections, most recent first
+CREATE INDEX idx_telemetry_deletions_user ON telemetry_deletions(user_id, created_at DESC);
+
+-- Partial index for the purge sweep
+CREATE INDEX idx_telemetry_deletions_pending ON telemetry_deletions(undo_until)
+    WHERE undone_at IS NULL AND purged_at IS NULL;
+
+ALTER TABLE telemetry ADD COLUMN deletion_id UUID;
+
+-- Partial index for undoing and purging a correction's records
+CREATE INDEX idx_telemetry_deletion ON telemetry (deletion_id) WHERE deletion_id IS NOT NULL;
+
+-- Recreate the continuous aggregates without deleted records
+-- refresh continuous aggregates: telemetry_1s, telemetry_1m, telemetry_10m
+DROP MATERIALIZED VIEW IF EXISTS telemetry_10m;
+DROP MATERIALIZED VIEW IF EXISTS telemetry_1m;
+DROP MATERIALIZED VIEW IF EXISTS telemetry_1s;
+
+CREATE MATERIALIZED VIEW telemetry_1s
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 second', recorded_at) AS bucket,
+    user_id,
+    device_id,
+    session_id,
+    COUNT(*) AS sample_count,
+    AVG(speed) AS avg_speed,
+    MAX(speed) AS max_speed,
+    AVG(g_force_x) AS avg_g_force_x,
+    AVG(g_force_y) AS avg_g_force_y,
+    AVG(g_force_z) AS avg_g_force_z,
+    MAX(SQRT(g_force_x * g_force_x + g_force_y * g_force_y)) AS max_g_force,
+    AVG(battery) AS avg_battery,
+    MIN(battery) AS min_battery,
+    COUNT(rpm) AS rpm_count,
+    AVG(rpm) AS avg_rpm,
+    MAX(rpm) AS max_rpm,
+    COUNT(throttle) AS throttle_count,
+    AVG(throttle) AS avg_throttle,
+    MAX(brake_pressure) AS max_brake_pressure,
+    MAX(coolant_temp) AS max_coolant_temp
+FROM telemetry
+WHERE deletion_id IS NULL
+GROUP BY bucket, user_id, device_id, session_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW telemetry_1m
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 minute', recorded_at) AS bucket,
+    user_id,
+    device_id,
+    session_id,
+    COUNT(*) AS sample_count,
+    AVG(speed) AS avg_speed,
+    MAX(speed) AS max_speed,
+    AVG(g_force_x) AS avg_g_force_x,
+    AVG(g_force_y) AS avg_g_force_y,
+    AVG(g_force_z) AS avg_g_force_z,
+    MAX(SQRT(g_force_x * g_force_x + g_force_y * g_force_y)) AS max_g_force,
+    AVG(battery) AS avg_battery,
+    MIN(battery) AS min_battery,
+    COUNT(rpm) AS rpm_count,
+    AVG(rpm) AS avg_rpm,
+    MAX(rpm) AS max_rpm,
+    COUNT(throttle) AS throttle_count,
+    AVG(throttle) AS avg_throttle,
+    MAX(brake_pressure) AS max_brake_pressure,
+    MAX(coolant_temp) AS max_coolant_temp
+FROM telemetry
+WHERE deletion_id IS NULL
+GROUP BY bucket, user_id, device_id, session_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW telemetry_10m
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '10 minutes', recorded_at) AS bucket,
+    user_id,
+    device_id,
+    session_id,
+    COUNT(*) AS sample_count,
+    AVG(speed) AS avg_speed,
+    MAX(speed) AS max_speed,
+    AVG(g_force_x) AS avg_g_force_x,
+    AVG(g_force_y) AS avg_g_force_y,
+    AVG(g_force_z) AS avg_g_force_z,
+    MAX(SQRT(g_force_x * g_force_x + g_force_y * g_force_y)) AS max_g_force,
+    AVG(battery) AS avg_battery,
+    MIN(battery) AS min_battery,
+    COUNT(rpm) AS rpm_count,
+    AVG(rpm) AS avg_rpm,
+    MAX(rpm) AS max_rpm,
+    COUNT(throttle) AS throttle_count,
+    AVG(throttle) AS avg_throttle,
+    MAX(brake_pressure) AS max_brake_pressure,
+    MAX(coolant_temp) AS max_coolant_temp
+FROM telemetry
+WHERE deletion_id IS NULL
+GROUP BY bucket, user_id, device_id, session_id
+WITH NO DATA;
+
+-- Indexes for user-scoped chart queries
+CREATE INDEX idx_telemetry_1s_user ON telemetry_1s (user_id, bucket DESC);
+CREATE INDEX idx_telemetry_1m_user ON telemetry_1m (user_id, bucket DESC);
+CREATE INDEX idx_telemetry_10m_user ON telemetry_10m (user_id, bucket DESC);
+
+-- Refresh policies; windows stay inside the 7-day compression horizon
+SELECT add_continuous_aggregate_policy('telemetry_1s',
+    start_offset => INTERVAL '1 day',
+    end_offset => INTERVAL '1 minute',
+    schedule_interval => INTERVAL '1 minute');
+
+SELECT add_continuous_aggregate_policy('telemetry_1m',
+    start_offset => INTERVAL '3 days',
+    end_offset => INTERVAL '2 minutes',
+    schedule_interval => INTERVAL '5 minutes');
+
+SELECT add_continuous_aggregate_policy('telemetry_10m',
+    start_offset => INTERVAL '6 days',
+    end_offset => INTERVAL '20 minutes',
+    schedule_interval => INTERVAL '30 minutes');
@@ -0,0 +1,11 @@
+-- Desired configuration pushed to devices
+-- Owners set it through the API and devices poll it with their API key. The version is
+-- incremented on every change and served as the ETag, so unchanged polls return 304.
+CREATE TABLE device_configs (
+    device_id UUID PRIMARY KEY REFERENCES devices(id) ON DELETE CASCADE,
+    sample_rate_hz INTEGER NOT NULL,
+    upload_interval_seconds INTEGER NOT NULL,
+    version BIGINT NOT NULL DEFAULT 1,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
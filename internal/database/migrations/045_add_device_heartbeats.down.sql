@@ -0,0 +1,3 @@
+-- Remove device heartbeat tracking
+ALTER TABLE device_health_states DROP COLUMN IF EXISTS last_heartbeat_at;
+ALTER TABLE device_health_states DROP COLUMN IF EXISTS signal_dbm;
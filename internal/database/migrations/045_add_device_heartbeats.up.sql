@@ -0,0 +1,4 @@
+-- Track device heartbeats, status reports sent without telemetry, in the device health state
+-- signal_dbm is the cellular or radio signal strength of the latest heartbeat; telemetry does not carry it.
+ALTER TABLE device_health_states ADD COLUMN signal_dbm INTEGER;
+ALTER TABLE device_health_states ADD COLUMN last_heartbeat_at TIMESTAMPTZ;
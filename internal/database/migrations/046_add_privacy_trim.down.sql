@@ -0,0 +1,3 @@
+-- Remove the privacy trim default
+ALTER TABLE user_profiles DROP CONSTRAINT IF EXISTS chk_user_profiles_privacy_trim;
+ALTER TABLE user_profiles DROP COLUMN IF EXISTS privacy_trim_meters;
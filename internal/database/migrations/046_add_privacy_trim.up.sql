@@ -0,0 +1,4 @@
+-- Per-user default for hiding the start and end of tracks in shared sessions and exports
+-- The first and last privacy_trim_meters of a track are left out, so they do not reveal where the owner lives.
+ALTER TABLE user_profiles ADD COLUMN privacy_trim_meters INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_profiles ADD CONSTRAINT chk_user_profiles_privacy_trim CHECK (privacy_trim_meters BETWEEN 0 AND 5000);
@@ -0,0 +1,24 @@
+-- Fleet reports generated in the background
+-- A report summarizes the sessions of every device, or of an organization's devices, per week
+-- over a date range. The rendered file is kept in content until the report expires and is
+-- downloaded through signed URLs.
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    org_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    format VARCHAR(8) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    range_start DATE NOT NULL,
+    range_end DATE NOT NULL,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    content BYTEA,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT chk_reports_format CHECK (format IN ('csv', 'xlsx')),
+    CONSTRAINT chk_reports_status CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    CONSTRAINT chk_reports_range CHECK (range_end >= range_start)
+);
+
+CREATE INDEX idx_reports_expires ON reports(expires_at);
@@ -0,0 +1,4 @@
+-- Drop personal_access_tokens table and related objects
+DROP INDEX IF EXISTS idx_personal_access_tokens_hash;
+DROP INDEX IF EXISTS idx_personal_access_tokens_user;
+DROP TABLE IF EXISTS personal_access_tokens;
@@ -0,0 +1,18 @@
+-- Long-lived, scoped tokens users mint for scripts and integrations
+-- Only the SHA256 hash of each token is stored; the token itself is shown once when created.
+CREATE TABLE personal_access_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL, -- User-friendly label (e.g., "Grafana dashboard")
+    token_prefix VARCHAR(16) NOT NULL, -- First characters of the token, for identification in listings
+    token_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA256 hash of the full token
+    scopes TEXT[] NOT NULL, -- e.g. {telemetry:read}
+    expires_at TIMESTAMPTZ, -- NULL for tokens that never expire
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+-- Indexes for listing a user's tokens and authenticating requests
+CREATE INDEX idx_personal_access_tokens_user ON personal_access_tokens(user_id, created_at DESC);
+CREATE INDEX idx_personal_access_tokens_hash ON personal_access_tokens(token_hash) WHERE revoked_at IS NULL;
@@ -0,0 +1,3 @@
+-- Restore the original name of the upload scope
+UPDATE personal_access_tokens
+SET scopes = array_replace(scopes, 'telemetry:write', 'telemetry:ingest');
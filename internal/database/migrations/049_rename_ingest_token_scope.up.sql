@@ -0,0 +1,3 @@
+-- Personal access tokens share the scopes embedded in access tokens, where uploads are telemetry:write
+UPDATE personal_access_tokens
+SET scopes = array_replace(scopes, 'telemetry:ingest', 'telemetry:write');
@@ -0,0 +1,4 @@
+-- Drop event_outbox table and related objects
+DROP INDEX IF EXISTS idx_event_outbox_delivered;
+DROP INDEX IF EXISTS idx_event_outbox_due;
+DROP TABLE IF EXISTS event_outbox;
@@ -0,0 +1,18 @@
+-- Outbox of domain events waiting to be delivered to internal subscribers
+-- Handlers append an event once the change it describes is stored, and the dispatcher delivers
+-- due events at least once, retrying failed deliveries with backoff. Delivered events are kept
+-- until they pass the retention period.
+CREATE TABLE event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL, -- e.g. session.ended
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- 'infinity' once delivery is abandoned
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ
+);
+
+-- Indexes for picking up due events and purging delivered ones
+CREATE INDEX idx_event_outbox_due ON event_outbox(next_attempt_at, id) WHERE delivered_at IS NULL;
+CREATE INDEX idx_event_outbox_delivered ON event_outbox(delivered_at) WHERE delivered_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Drop alert rule tables
+DROP TABLE IF EXISTS alert_events;
+DROP TRIGGER IF EXISTS update_alert_rules_updated_at ON alert_rules;
+DROP TABLE IF EXISTS alert_rules;
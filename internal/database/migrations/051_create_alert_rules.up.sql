@@ -0,0 +1,42 @@
+-- Create alert rules raising alerts when a device's speed or g-force exceeds a threshold
+CREATE TABLE alert_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    metric VARCHAR(20) NOT NULL, -- 'speed', 'lateral_g', 'longitudinal_g' or 'g_force'
+    threshold DOUBLE PRECISION NOT NULL, -- km/h for speed, g otherwise
+    cooldown_seconds INTEGER NOT NULL DEFAULT 300,
+    webhook_url TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_triggered_at TIMESTAMPTZ, -- Timestamp of the telemetry that last fired the rule
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_alert_rules_metric CHECK (metric IN ('speed', 'lateral_g', 'longitudinal_g', 'g_force')),
+    CONSTRAINT chk_alert_rules_cooldown CHECK (cooldown_seconds >= 0)
+);
+
+CREATE INDEX idx_alert_rules_user ON alert_rules(user_id, created_at DESC);
+CREATE INDEX idx_alert_rules_active ON alert_rules(user_id, device_id) WHERE is_active = TRUE;
+
+CREATE TRIGGER update_alert_rules_updated_at BEFORE UPDATE ON alert_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Alerts raised during ingestion, kept as the rules' history
+CREATE TABLE alert_events (
+    id BIGSERIAL PRIMARY KEY,
+    rule_id UUID NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id VARCHAR(50) NOT NULL,
+    session_id UUID,
+    metric VARCHAR(20) NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_alert_events_user ON alert_events(user_id, recorded_at DESC);
+CREATE INDEX idx_alert_events_rule ON alert_events(rule_id, recorded_at DESC);
@@ -0,0 +1,2 @@
+-- Remove client fingerprints from refresh tokens
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS client_fingerprint;
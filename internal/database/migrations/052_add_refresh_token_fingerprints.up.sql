@@ -0,0 +1,3 @@
+-- Fingerprint of the client each refresh token was issued to, when token binding is enabled
+-- Refreshes from a client with a different fingerprint are rejected.
+ALTER TABLE refresh_tokens ADD COLUMN client_fingerprint TEXT;
@@ -0,0 +1,2 @@
+-- Drop the login audit trail
+DROP TABLE IF EXISTS login_events;
@@ -0,0 +1,20 @@
+-- Audit trail of every login attempt, successful or not
+-- Unlike login_attempts, which only holds failures until the next successful login, rows are kept
+-- for users reviewing their account activity and administrators investigating abuse.
+CREATE TABLE login_events (
+    id BIGSERIAL PRIMARY KEY,
+    -- NULL when the attempt does not identify an account
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255),
+    method VARCHAR(20) NOT NULL, -- 'password' or 'magic_link'
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(50),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_login_events_method CHECK (method IN ('password', 'magic_link'))
+);
+
+CREATE INDEX idx_login_events_user ON login_events(user_id, created_at DESC, id DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_login_events_ip ON login_events(ip_address, created_at DESC);
+CREATE INDEX idx_login_events_created ON login_events(created_at DESC, id DESC);
@@ -0,0 +1,5 @@
+DELETE FROM login_events WHERE method = 'oidc';
+ALTER TABLE login_events DROP CONSTRAINT chk_login_events_method;
+ALTER TABLE login_events ADD CONSTRAINT chk_login_events_method CHECK (method IN ('password', 'magic_link'));
+
+DROP TABLE IF EXISTS user_identities;
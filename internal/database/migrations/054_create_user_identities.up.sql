@@ -0,0 +1,19 @@
+-- Accounts at external OpenID Connect identity providers linked to users
+-- Single sign-on matches users by the provider's issuer and subject, which stay the same when the
+-- email address changes at the provider.
+CREATE TABLE user_identities (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255), -- Address the provider reported at the last sign-in
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ,
+    CONSTRAINT uq_user_identities_subject UNIQUE (issuer, subject)
+);
+
+CREATE INDEX idx_user_identities_user ON user_identities(user_id);
+
+-- Single sign-on attempts are recorded in the login audit trail
+ALTER TABLE login_events DROP CONSTRAINT chk_login_events_method;
+ALTER TABLE login_events ADD CONSTRAINT chk_login_events_method CHECK (method IN ('password', 'magic_link', 'oidc'));
@@ -0,0 +1,24 @@
+-- Remote actions queued for devices
+-- Owners enqueue commands through the API and devices poll them with their API key. Commands
+-- are redelivered on every poll until the device acknowledges them or they expire.
+CREATE TABLE device_commands (
+    id UUID PRIMARY KEY,
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    params JSONB,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    message TEXT, -- Reported by the device with its acknowledgement
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    delivered_at TIMESTAMPTZ, -- First poll that returned the command
+    acknowledged_at TIMESTAMPTZ,
+    CONSTRAINT chk_device_commands_type CHECK (type IN ('start_session', 'stop_session', 'flush_buffer', 'reboot')),
+    CONSTRAINT chk_device_commands_status CHECK (status IN ('pending', 'delivered', 'succeeded', 'failed', 'expired', 'cancelled'))
+);
+
+CREATE INDEX idx_device_commands_device ON device_commands(device_id, created_at DESC);
+
+-- Polls only read the commands still waiting for an acknowledgement
+CREATE INDEX idx_device_commands_outstanding ON device_commands(device_id, created_at)
+    WHERE status IN ('pending', 'delivered');
@@ -0,0 +1,5 @@
+-- Remove derived telemetry channels
+ALTER TABLE telemetry DROP COLUMN IF EXISTS distance_increment;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS vertical_speed;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS speed_delta;
+ALTER TABLE telemetry DROP COLUMN IF EXISTS total_g;
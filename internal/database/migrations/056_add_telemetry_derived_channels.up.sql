@@ -0,0 +1,7 @@
+-- Channels derived on ingest from each record and the previous record of its device
+-- They are NULL for records stored while derived channels were disabled, until the backfill
+-- reaches them. The deltas stay NULL for records without a previous record within five minutes.
+ALTER TABLE telemetry ADD COLUMN total_g DOUBLE PRECISION;
+ALTER TABLE telemetry ADD COLUMN speed_delta DOUBLE PRECISION;
+ALTER TABLE telemetry ADD COLUMN vertical_speed DOUBLE PRECISION;
+ALTER TABLE telemetry ADD COLUMN distance_increment DOUBLE PRECISION;
@@ -0,0 +1 @@
+-- Nothing to revert: the aggregates keep their materialized data
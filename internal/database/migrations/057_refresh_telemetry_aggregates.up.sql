@@ -0,0 +1,4 @@
+-- Materialize the telemetry continuous aggregates over all stored telemetry
+-- They were created WITH NO DATA and their refresh policies only cover recent windows, so
+-- telemetry older than those windows at the time they were created never reached them.
+-- refresh continuous aggregates: telemetry_1s, telemetry_1m, telemetry_10m
@@ -0,0 +1,2 @@
+-- Remove device signing secrets
+ALTER TABLE device_api_keys DROP COLUMN IF EXISTS signing_secret;
@@ -0,0 +1,3 @@
+-- Secret each device API key signs uploads with, encrypted with INGEST_SIGNING_SECRET_KEY
+-- Keys minted before this migration have none and must be replaced to sign uploads.
+ALTER TABLE device_api_keys ADD COLUMN signing_secret TEXT;
@@ -0,0 +1,9 @@
+// Package migrations embeds the versioned SQL schema migrations and applies them.
+package migrations
+
+import "embed"
+
+// files holds the NNN_name.up.sql and NNN_name.down.sql migration pairs
+//
+//go:embed *.sql
+var files embed.FS
@@ -0,0 +1,333 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// advisoryLockID serializes migration runs across server instances starting at the same time
+const advisoryLockID int64 = 4_774_657_043
+
+// fileNamePattern matches migration file names such as 001_create_telemetry_table.up.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// refreshPattern matches the directive naming the continuous aggregates a migration file creates
+// WITH NO DATA, e.g. "-- refresh continuous aggregates: telemetry_1s, telemetry_1m"
+var refreshPattern = regexp.MustCompile(`(?m)^-- refresh continuous aggregates: (\w+(?:, *\w+)*)\s*$`)
+
+// ErrDirty is returned when a previous migration failed part way and the schema needs manual repair
+var ErrDirty = errors.New("database schema is dirty")
+
+// Migration is a versioned schema change with the SQL to apply and revert it
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator applies the embedded migrations to a database
+// Applied versions are tracked in the schema_migrations table used by the golang-migrate CLI,
+// so databases migrated with either tool stay interchangeable.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration // Ordered by version
+}
+
+// NewMigrator creates a migrator for the migrations embedded in the binary
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the version of the newest known migration
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the currently applied version, zero when none is, and whether it is dirty
+func (m *Migrator) Version(ctx context.Context) (uint, bool, error) {
+	if err := ensureTable(ctx, m.db); err != nil {
+		return 0, false, err
+	}
+	return currentVersion(ctx, m.db)
+}
+
+// Up applies every pending migration in order and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		version, err := cleanVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= version {
+				continue
+			}
+			if err := apply(ctx, conn, migration.Version, migration.Version, migration.Up); err != nil {
+				return fmt.Errorf("failed to apply migration %03d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts up to steps of the most recently applied migrations and returns how many were reverted
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		version, err := cleanVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if migration.Version > version {
+				continue
+			}
+
+			var previous uint
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			if err := apply(ctx, conn, migration.Version, previous, migration.Down); err != nil {
+				return fmt.Errorf("failed to revert migration %03d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("Reverted migration", "version", migration.Version, "name", migration.Name)
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// The lock is also released when the session ends, so a failed unlock only logs
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockID); err != nil {
+			slog.Error("Error releasing migration lock", "error", err)
+		}
+	}()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// execer is implemented by *sql.DB and *sql.Conn
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ensureTable creates the version table if this is the first migration run
+func ensureTable(ctx context.Context, db execer) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			dirty BOOLEAN NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// currentVersion reads the applied version, zero when no migration has been applied
+func currentVersion(ctx context.Context, db execer) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// cleanVersion reads the applied version, failing with ErrDirty if the last run did not finish
+func cleanVersion(ctx context.Context, db execer) (uint, error) {
+	version, dirty, err := currentVersion(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w at version %d: repair it, then record the last complete version", ErrDirty, version)
+	}
+	return version, nil
+}
+
+// apply runs one migration's SQL, marking the target version dirty until it succeeds
+// The SQL is sent as a single simple query, so a multi-statement file runs in one implicit transaction.
+// Continuous aggregates named by a refresh directive are then materialized over all stored rows:
+// refreshing cannot run inside a transaction, and refresh policies only cover recent windows, so
+// older rows would otherwise never reach them.
+func apply(ctx context.Context, conn *sql.Conn, version, target uint, query string) error {
+	if err := setVersion(ctx, conn, target, true); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("version %d: %w", version, err)
+	}
+	for _, view := range refreshedAggregates(query) {
+		slog.Info("Refreshing continuous aggregate", "version", version, "view", view)
+		if err := refreshAggregate(ctx, conn, view); err != nil {
+			return fmt.Errorf("version %d: %w", version, err)
+		}
+	}
+	return setVersion(ctx, conn, target, false)
+}
+
+// refreshAggregate materializes a continuous aggregate from the oldest chunk of its hypertable on
+// Buckets older than that chunk are left alone: their raw rows were dropped, e.g. by the retention
+// policy, and refreshing a range without raw rows would delete them.
+func refreshAggregate(ctx context.Context, conn *sql.Conn, view string) error {
+	var start sql.NullTime
+	err := conn.QueryRowContext(ctx, `
+		SELECT MIN(c.range_start)
+		FROM timescaledb_information.continuous_aggregates a
+		JOIN timescaledb_information.chunks c
+			ON c.hypertable_schema = a.hypertable_schema AND c.hypertable_name = a.hypertable_name
+		WHERE a.view_name = $1
+	`, view).Scan(&start)
+	if err != nil {
+		return fmt.Errorf("failed to find the oldest chunk under %s: %w", view, err)
+	}
+	if !start.Valid {
+		return nil // No raw rows to materialize
+	}
+
+	if _, err := conn.ExecContext(ctx, `CALL refresh_continuous_aggregate($1::text::regclass, $2::timestamptz, NULL)`, view, start.Time); err != nil {
+		return fmt.Errorf("failed to refresh %s: %w", view, err)
+	}
+	return nil
+}
+
+// refreshedAggregates returns the continuous aggregates named by the refresh directives in query
+func refreshedAggregates(query string) []string {
+	var views []string
+	for _, match := range refreshPattern.FindAllStringSubmatch(query, -1) {
+		for _, view := range strings.Split(match[1], ",") {
+			views = append(views, strings.TrimSpace(view))
+		}
+	}
+	return views
+}
+
+// setVersion records the applied version; version zero clears it
+func setVersion(ctx context.Context, conn *sql.Conn, version uint, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("failed to clear schema version: %w", err)
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, int64(version), dirty); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schema version: %w", err)
+	}
+	return nil
+}
+
+// load reads the migration pairs from fsys, requiring an up and a down file for every version
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
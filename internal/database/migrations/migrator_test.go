@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_index.up.sql":      {Data: []byte("CREATE INDEX idx ON t (a);")},
+		"002_add_index.down.sql":    {Data: []byte("DROP INDEX idx;")},
+		"001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a INT);")},
+		"001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"README.md":                 {Data: []byte("not a migration")},
+	}
+
+	migrations, err := load(fsys)
+	require.NoError(t, err)
+
+	require.Len(t, migrations, 2)
+	assert.Equal(t, uint(1), migrations[0].Version)
+	assert.Equal(t, "create_table", migrations[0].Name)
+	assert.Equal(t, "CREATE TABLE t (a INT);", migrations[0].Up)
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.Equal(t, uint(2), migrations[1].Version)
+}
+
+func TestLoad_RequiresPairs(t *testing.T) {
+	_, err := load(fstest.MapFS{
+		"001_create_table.up.sql": {Data: []byte("CREATE TABLE t (a INT);")},
+	})
+	assert.EqualError(t, err, "migration 001_create_table needs both an up and a down file")
+
+	_, err = load(fstest.MapFS{
+		"001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a INT);")},
+		"001_create_other.down.sql": {Data: []byte("DROP TABLE other;")},
+	})
+	assert.Error(t, err)
+}
+
+func TestRefreshedAggregates(t *testing.T) {
+	query := `-- Recreate the aggregates
+-- refresh continuous aggregates: view_1s, view_1m
+CREATE MATERIALIZED VIEW view_1s AS SELECT 1 WITH NO DATA;
+-- refresh continuous aggregates: view_10m
+`
+	assert.Equal(t, []string{"view_1s", "view_1m", "view_10m"}, refreshedAggregates(query))
+	assert.Empty(t, refreshedAggregates("CREATE TABLE t (a INT);"))
+	assert.Empty(t, refreshedAggregates("-- refresh continuous aggregates: view'); DROP TABLE t; --"))
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrator, err := NewMigrator(nil)
+	require.NoError(t, err)
+
+	// Versions are sequential so a gap from a missing file is caught before deploy
+	for i, migration := range migrator.migrations {
+		assert.Equal(t, uint(i+1), migration.Version, migration.Name)
+	}
+	assert.Equal(t, uint(len(migrator.migrations)), migrator.Latest())
+
+	// Continuous aggregates created empty are refreshed, or they would never hold older telemetry
+	for _, migration := range migrator.migrations {
+		for direction, query := range map[string]string{"up": migration.Up, "down": migration.Down} {
+			if strings.Contains(query, "WITH NO DATA") {
+				assert.NotEmpty(t, refreshedAggregates(query), "%03d_%s.%s.sql", migration.Version, migration.Name, direction)
+			}
+		}
+	}
+}
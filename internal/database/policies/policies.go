@@ -0,0 +1,190 @@
+// Package policies applies and inspects TimescaleDB compression and retention policies.
+package policies
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+// Hypertables the storage policies manage
+const (
+	TelemetryHypertable   = "telemetry"
+	IngestAuditHypertable = "ingest_audit_log"
+)
+
+// Kind identifies a TimescaleDB policy type
+type Kind string
+
+// Supported policy kinds
+const (
+	KindCompression Kind = "compression"
+	KindRetention   Kind = "retention"
+)
+
+// policyProcs describes the TimescaleDB functions and job settings behind each policy kind
+var policyProcs = map[Kind]struct {
+	procName  string // timescaledb_information.jobs.proc_name
+	configKey string // Interval key in the job's config JSON
+	addFunc   string
+	dropFunc  string
+}{
+	KindCompression: {"policy_compression", "compress_after", "add_compression_policy", "remove_compression_policy"},
+	KindRetention:   {"policy_retention", "drop_after", "add_retention_policy", "remove_retention_policy"},
+}
+
+// Policy describes a background policy job registered on the hypertable
+type Policy struct {
+	JobID                int        `json:"jobId"`
+	Kind                 Kind       `json:"kind"`
+	After                string     `json:"after"`            // Age at which chunks are compressed or dropped
+	ScheduleInterval     string     `json:"scheduleInterval"` // How often the job runs
+	Scheduled            bool       `json:"scheduled"`
+	LastRunStatus        *string    `json:"lastRunStatus,omitempty"`
+	LastSuccessfulFinish *time.Time `json:"lastSuccessfulFinish,omitempty"`
+	NextStart            *time.Time `json:"nextStart,omitempty"`
+}
+
+// Status reports the configured and the currently registered policies
+type Status struct {
+	Hypertable    string   `json:"hypertable"`
+	CompressAfter string   `json:"compressAfter"` // Configured value; "0s" means disabled
+	RetainFor     string   `json:"retainFor"`     // Configured value; "0s" means keep forever
+	Policies      []Policy `json:"policies"`
+}
+
+// Manager keeps the telemetry and ingest audit log policies in line with the storage configuration
+type Manager struct {
+	db  *sql.DB
+	cfg config.StorageConfig
+}
+
+// NewManager creates a new policy manager
+func NewManager(db *sql.DB, cfg config.StorageConfig) *Manager {
+	return &Manager{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// Apply adds, replaces or removes the compression and retention policies to match the configuration
+// Policies that already match are left untouched so their job history is preserved.
+func (m *Manager) Apply(ctx context.Context) error {
+	if err := m.ensure(ctx, TelemetryHypertable, KindCompression, m.cfg.CompressAfter); err != nil {
+		return err
+	}
+	if err := m.ensure(ctx, TelemetryHypertable, KindRetention, m.cfg.RetainFor); err != nil {
+		return err
+	}
+	return m.ensure(ctx, IngestAuditHypertable, KindRetention, m.cfg.AuditRetainFor)
+}
+
+// ensure reconciles a single policy kind on a hypertable with the desired interval
+func (m *Manager) ensure(ctx context.Context, hypertable string, kind Kind, after time.Duration) error {
+	proc := policyProcs[kind]
+	interval := formatInterval(after)
+
+	var matches bool
+	err := m.db.QueryRowContext(ctx, `
+		SELECT (config->>$3)::interval = $4::interval
+		FROM timescaledb_information.jobs
+		WHERE proc_name = $1 AND hypertable_name = $2
+		LIMIT 1
+	`, proc.procName, hypertable, proc.configKey, interval).Scan(&matches)
+	exists := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read %s policy on %s: %w", kind, hypertable, err)
+	}
+
+	if exists && matches {
+		return nil
+	}
+
+	if exists {
+		query := fmt.Sprintf(`SELECT %s($1, if_exists => true)`, proc.dropFunc)
+		if _, err := m.db.ExecContext(ctx, query, hypertable); err != nil {
+			return fmt.Errorf("failed to remove %s policy on %s: %w", kind, hypertable, err)
+		}
+		slog.Info("Removed storage policy", "kind", kind, "hypertable", hypertable)
+	}
+
+	if after == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`SELECT %s($1, $2::interval)`, proc.addFunc)
+	if _, err := m.db.ExecContext(ctx, query, hypertable, interval); err != nil {
+		return fmt.Errorf("failed to add %s policy on %s: %w", kind, hypertable, err)
+	}
+	slog.Info("Applied storage policy", "kind", kind, "hypertable", hypertable, "after", after)
+
+	return nil
+}
+
+// Status returns the configured telemetry policies alongside the jobs registered in TimescaleDB
+func (m *Manager) Status(ctx context.Context) (*Status, error) {
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT j.job_id, j.proc_name, COALESCE(j.config->>'compress_after', j.config->>'drop_after', ''),
+			j.schedule_interval::text, j.scheduled,
+			s.last_run_status, s.last_successful_finish, s.next_start
+		FROM timescaledb_information.jobs j
+		LEFT JOIN timescaledb_information.job_stats s ON s.job_id = j.job_id
+		WHERE j.hypertable_name = $1 AND j.proc_name IN ($2, $3)
+		ORDER BY j.job_id
+	`, TelemetryHypertable, policyProcs[KindCompression].procName, policyProcs[KindRetention].procName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	status := &Status{
+		Hypertable:    TelemetryHypertable,
+		CompressAfter: m.cfg.CompressAfter.String(),
+		RetainFor:     m.cfg.RetainFor.String(),
+		Policies:      []Policy{},
+	}
+
+	for rows.Next() {
+		var (
+			policy   Policy
+			procName string
+		)
+		if err := rows.Scan(
+			&policy.JobID, &procName, &policy.After,
+			&policy.ScheduleInterval, &policy.Scheduled,
+			&policy.LastRunStatus, &policy.LastSuccessfulFinish, &policy.NextStart,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan policy: %w", err)
+		}
+		policy.Kind = kindForProc(procName)
+		status.Policies = append(status.Policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating policies: %w", err)
+	}
+
+	return status, nil
+}
+
+// kindForProc maps a TimescaleDB job procedure back to its policy kind
+func kindForProc(procName string) Kind {
+	for kind, proc := range policyProcs {
+		if proc.procName == procName {
+			return kind
+		}
+	}
+	return Kind(procName)
+}
+
+// formatInterval renders a duration as a PostgreSQL interval literal
+func formatInterval(d time.Duration) string {
+	return fmt.Sprintf("%d seconds", int64(d/time.Second))
+}
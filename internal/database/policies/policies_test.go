@@ -0,0 +1,20 @@
+package policies
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatInterval(t *testing.T) {
+	assert.Equal(t, "604800 seconds", formatInterval(7*24*time.Hour))
+	assert.Equal(t, "90 seconds", formatInterval(90*time.Second+500*time.Millisecond))
+	assert.Equal(t, "0 seconds", formatInterval(0))
+}
+
+func TestKindForProc(t *testing.T) {
+	assert.Equal(t, KindCompression, kindForProc("policy_compression"))
+	assert.Equal(t, KindRetention, kindForProc("policy_retention"))
+	assert.Equal(t, Kind("policy_reorder"), kindForProc("policy_reorder"))
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+// SQLiteTimeFormat is the layout timestamps are stored in by the SQLite backend
+// Values are UTC with a fixed-width fraction, so they compare and sort correctly as text.
+const SQLiteTimeFormat = "2006-01-02 15:04:05.000000000"
+
+// sqliteSchema holds the NNN_name.sql steps of the SQLite schema
+//
+//go:embed sqlite/*.sql
+var sqliteSchema embed.FS
+
+// sqliteSchemaPattern matches SQLite schema step names such as 001_create_schema.sql
+var sqliteSchemaPattern = regexp.MustCompile(`^(\d+)_\w+\.sql$`)
+
+// NewSQLite opens the SQLite database file at cfg.SQLitePath, creating it if needed, and brings its schema up to date
+// The SQLite backend is meant for single-instance and edge deployments. It implements the telemetry,
+// user, refresh token and device repositories; everything else needs PostgreSQL. The schema is
+// versioned with PRAGMA user_version instead of the PostgreSQL migrations, which rely on TimescaleDB.
+func NewSQLite(cfg *config.DatabaseConfig) (*DB, error) {
+	// Writers wait for each other instead of failing with SQLITE_BUSY; transactions take the write
+	// lock up front so they never deadlock upgrading from a read lock
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+cfg.SQLitePath+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxConnections)
+	db.SetMaxIdleConns(cfg.MaxIdleConnections)
+	db.SetConnMaxLifetime(cfg.ConnectionMaxLifetime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	if err := migrateSQLite(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &DB{DB: db}, nil
+}
+
+// migrateSQLite applies the schema steps newer than the database's user_version, each in its own transaction
+func migrateSQLite(ctx context.Context, db *sql.DB) error {
+	entries, err := fs.ReadDir(sqliteSchema, "sqlite")
+	if err != nil {
+		return fmt.Errorf("failed to read SQLite schema: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var current int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read SQLite schema version: %w", err)
+	}
+
+	for _, entry := range entries {
+		match := sqliteSchemaPattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return fmt.Errorf("invalid SQLite schema file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version <= current {
+			continue
+		}
+
+		statements, err := fs.ReadFile(sqliteSchema, "sqlite/"+entry.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read SQLite schema: %w", err)
+		}
+		if err := applySQLiteStep(ctx, db, version, string(statements)); err != nil {
+			return fmt.Errorf("failed to apply SQLite schema %s: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// applySQLiteStep runs one schema step and records its version atomically
+func applySQLiteStep(ctx context.Context, db *sql.DB, version int, statements string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return err
+	}
+	// PRAGMA does not take parameters; version comes from a file name matched as digits
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, version)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// isSQLiteUniqueViolation checks if the error is a SQLite unique or primary key constraint violation
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
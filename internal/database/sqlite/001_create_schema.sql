@@ -0,0 +1,138 @@
+-- Schema of the SQLite backend, covering users, devices and telemetry
+-- Timestamps are DATETIME text in UTC with a fixed-width fraction ("2006-01-02 15:04:05.000000000"),
+-- so they compare and sort correctly as strings. UUIDs are stored as text.
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    verification_token TEXT,
+    verification_token_expires_at DATETIME,
+    reset_token TEXT,
+    reset_token_expires_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    last_login_at DATETIME,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'))
+);
+
+CREATE INDEX idx_users_reset_token ON users (reset_token) WHERE reset_token IS NOT NULL;
+CREATE INDEX idx_users_role ON users (role);
+
+CREATE TABLE user_profiles (
+    user_id TEXT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    units_preference TEXT DEFAULT 'metric'
+);
+
+CREATE TABLE refresh_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    replaced_by TEXT,
+    user_agent TEXT,
+    ip_address TEXT,
+    remember_me BOOLEAN NOT NULL DEFAULT FALSE,
+    session_started_at DATETIME NOT NULL,
+    geo_country_code TEXT,
+    geo_country TEXT,
+    geo_region TEXT,
+    geo_city TEXT
+);
+
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens (user_id, expires_at DESC);
+CREATE INDEX idx_refresh_tokens_expires ON refresh_tokens (expires_at) WHERE revoked_at IS NULL;
+
+CREATE TABLE devices (
+    id TEXT PRIMARY KEY,
+    device_id TEXT NOT NULL UNIQUE,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    org_id TEXT,
+    device_name TEXT,
+    device_model TEXT,
+    claimed_at DATETIME NOT NULL,
+    last_seen_at DATETIME,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    metadata BLOB,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    firmware_version TEXT,
+    firmware_updated_at DATETIME
+);
+
+CREATE INDEX idx_devices_user ON devices (user_id, claimed_at DESC);
+CREATE INDEX idx_devices_last_seen ON devices (last_seen_at DESC);
+
+-- Device API keys are revoked when a device is reassigned to another owner
+CREATE TABLE device_api_keys (
+    id TEXT PRIMARY KEY,
+    device_id TEXT NOT NULL REFERENCES devices (id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT,
+    key_prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME,
+    revoked_at DATETIME
+);
+
+CREATE TABLE telemetry (
+    id INTEGER PRIMARY KEY,
+    recorded_at DATETIME NOT NULL,
+    device_id TEXT,
+    session_id TEXT,
+    user_id TEXT,
+    itow INTEGER,
+    time_accuracy INTEGER,
+    validity_flags INTEGER,
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    wgs_altitude REAL,
+    msl_altitude REAL,
+    speed REAL,
+    heading REAL,
+    num_satellites INTEGER,
+    fix_status INTEGER,
+    is_fix_valid BOOLEAN,
+    horizontal_accuracy REAL,
+    vertical_accuracy REAL,
+    speed_accuracy REAL,
+    heading_accuracy REAL,
+    pdop REAL,
+    g_force_x REAL,
+    g_force_y REAL,
+    g_force_z REAL,
+    rotation_x REAL,
+    rotation_y REAL,
+    rotation_z REAL,
+    battery REAL,
+    is_charging BOOLEAN,
+    schema_version INTEGER NOT NULL DEFAULT 1,
+    extras BLOB,
+    rpm REAL,
+    throttle REAL,
+    brake_pressure REAL,
+    coolant_temp REAL,
+    gear INTEGER,
+    client_id TEXT,
+    quality_flags INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_telemetry_time ON telemetry (recorded_at DESC);
+CREATE INDEX idx_telemetry_device_time ON telemetry (device_id, recorded_at DESC);
+CREATE INDEX idx_telemetry_session ON telemetry (session_id, recorded_at) WHERE session_id IS NOT NULL;
+CREATE INDEX idx_telemetry_user ON telemetry (user_id, recorded_at DESC) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_telemetry_client_id ON telemetry (client_id, recorded_at) WHERE client_id IS NOT NULL;
+
+CREATE TABLE upload_batches (
+    batch_id TEXT PRIMARY KEY,
+    record_count INTEGER NOT NULL,
+    uploaded_at DATETIME NOT NULL,
+    device_id TEXT,
+    session_id TEXT
+);
+
+CREATE INDEX idx_upload_batches_uploaded_at ON upload_batches (uploaded_at DESC);
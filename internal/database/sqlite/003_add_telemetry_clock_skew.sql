@@ -0,0 +1,4 @@
+-- Receive time and device clock skew of telemetry, as in PostgreSQL migration 035
+ALTER TABLE telemetry ADD COLUMN received_at DATETIME;
+ALTER TABLE telemetry ADD COLUMN clock_skew_ms INTEGER;
+ALTER TABLE telemetry ADD COLUMN device_recorded_at DATETIME;
@@ -0,0 +1,5 @@
+-- One-time passwordless sign-in tokens, as in PostgreSQL migration 040
+ALTER TABLE users ADD COLUMN magic_link_token TEXT;
+ALTER TABLE users ADD COLUMN magic_link_expires_at DATETIME;
+
+CREATE INDEX idx_users_magic_link_token ON users (magic_link_token) WHERE magic_link_token IS NOT NULL;
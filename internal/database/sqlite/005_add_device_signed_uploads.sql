@@ -0,0 +1,2 @@
+-- Per-device enforcement of HMAC-signed uploads, as in PostgreSQL migration 041
+ALTER TABLE devices ADD COLUMN require_signed_uploads BOOLEAN NOT NULL DEFAULT FALSE;
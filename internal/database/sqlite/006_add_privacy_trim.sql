@@ -0,0 +1,2 @@
+-- Per-user privacy trim default, as in PostgreSQL migration 046
+ALTER TABLE user_profiles ADD COLUMN privacy_trim_meters INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Profile time zone, as in PostgreSQL migration 005
+ALTER TABLE user_profiles ADD COLUMN timezone TEXT DEFAULT 'UTC';
@@ -0,0 +1,2 @@
+-- Refresh token client binding, as in PostgreSQL migration 052
+ALTER TABLE refresh_tokens ADD COLUMN client_fingerprint TEXT;
@@ -0,0 +1,5 @@
+-- Derived telemetry channels, as in PostgreSQL migration 056
+ALTER TABLE telemetry ADD COLUMN total_g REAL;
+ALTER TABLE telemetry ADD COLUMN speed_delta REAL;
+ALTER TABLE telemetry ADD COLUMN vertical_speed REAL;
+ALTER TABLE telemetry ADD COLUMN distance_increment REAL;
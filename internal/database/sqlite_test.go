@@ -0,0 +1,35 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+func TestNewSQLite_AppliesSchemaOnce(t *testing.T) {
+	cfg := &config.DatabaseConfig{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "avt.db"), MaxConnections: 1}
+
+	db, err := NewSQLite(cfg)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO upload_batches (batch_id, record_count, uploaded_at) VALUES ('batch-1', 1, '2026-01-01 00:00:00.000000000')`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO upload_batches (batch_id, record_count, uploaded_at) VALUES ('batch-1', 1, '2026-01-01 00:00:00.000000000')`)
+	assert.True(t, IsUniqueViolation(err))
+	require.NoError(t, db.Close())
+
+	// Reopening keeps the data and does not reapply the schema
+	db, err = NewSQLite(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var version, batches int
+	require.NoError(t, db.QueryRow(`PRAGMA user_version`).Scan(&version))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM upload_batches`).Scan(&batches))
+	assert.NotZero(t, version)
+	assert.Equal(t, 1, batches)
+}
@@ -0,0 +1,401 @@
+// Package devicehealth tracks battery and GPS fix quality from ingested telemetry and emits health alerts.
+package devicehealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/notify"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/sebasr/avt-service/internal/webhook"
+)
+
+const (
+	// queueSize bounds the number of telemetry batches waiting for processing
+	queueSize = 256
+
+	// batteryRearmMargin is how far above the threshold the battery must recover before
+	// another low_battery event can fire, so readings hovering at the threshold alert once
+	batteryRearmMargin = 5.0
+)
+
+// batch is the telemetry of a single device and owner awaiting processing, or a heartbeat
+type batch struct {
+	userID    uuid.UUID
+	deviceID  string
+	records   []*models.TelemetryData
+	heartbeat *models.DeviceHeartbeat
+}
+
+// WebhookPayload is the JSON body POSTed to the configured health webhook URL
+type WebhookPayload struct {
+	Event *models.DeviceHealthEvent `json:"event"`
+}
+
+// Notifier stores a notification in its user's inbox and delivers it over the channels they chose
+type Notifier interface {
+	Notify(ctx context.Context, notification *models.Notification) error
+}
+
+// Monitor maintains per-device health state and snapshots from ingested telemetry
+// Like geofence evaluation it runs in the background, so uploads never wait on it.
+type Monitor struct {
+	repo         repository.DeviceHealthRepository
+	userRepo     repository.UserRepository
+	cfg          config.DeviceHealthConfig
+	emailService email.Service // Optional: nil disables email notifications
+	notifier     Notifier      // Optional: nil emails low battery alerts like other alerts
+	httpClient   *http.Client
+	queue        chan batch
+}
+
+// NewMonitor creates a new device health monitor
+func NewMonitor(repo repository.DeviceHealthRepository, userRepo repository.UserRepository, cfg config.DeviceHealthConfig) *Monitor {
+	return &Monitor{
+		repo:       repo,
+		userRepo:   userRepo,
+		cfg:        cfg,
+		httpClient: webhook.NewClient(),
+		queue:      make(chan batch, queueSize),
+	}
+}
+
+// WithEmailService sets the email service used for health alert emails
+func (m *Monitor) WithEmailService(emailService email.Service) *Monitor {
+	m.emailService = emailService
+	return m
+}
+
+// WithNotifier sets the notifier low battery alerts are sent through
+// The notifier applies the owner's notification preferences, so NotifyEmail only covers other alerts.
+func (m *Monitor) WithNotifier(notifier Notifier) *Monitor {
+	m.notifier = notifier
+	return m
+}
+
+// WithHTTPClient sets the HTTP client used for webhook delivery
+func (m *Monitor) WithHTTPClient(client *http.Client) *Monitor {
+	m.httpClient = client
+	return m
+}
+
+// Enqueue schedules saved telemetry for processing without blocking
+// Records without an owner or device and duplicates of stored records are skipped.
+// If the queue is full the batch is dropped.
+func (m *Monitor) Enqueue(records []*models.TelemetryData) {
+	type key struct {
+		userID   uuid.UUID
+		deviceID string
+	}
+
+	groups := make(map[key]*batch)
+	var order []key
+	for _, record := range records {
+		if record.UserID == nil || record.DeviceID == "" || record.Duplicate {
+			continue
+		}
+		k := key{userID: *record.UserID, deviceID: record.DeviceID}
+		group, ok := groups[k]
+		if !ok {
+			group = &batch{userID: k.userID, deviceID: k.deviceID}
+			groups[k] = group
+			order = append(order, k)
+		}
+		group.records = append(group.records, record)
+	}
+
+	for _, k := range order {
+		select {
+		case m.queue <- *groups[k]:
+		default:
+			slog.Warn("Device health queue full, dropping records", "device_id", k.deviceID, "count", len(groups[k].records))
+		}
+	}
+}
+
+// EnqueueHeartbeat schedules a device heartbeat for processing without blocking
+// If the queue is full the heartbeat is dropped.
+func (m *Monitor) EnqueueHeartbeat(heartbeat *models.DeviceHeartbeat) {
+	select {
+	case m.queue <- batch{userID: heartbeat.UserID, deviceID: heartbeat.DeviceID, heartbeat: heartbeat}:
+	default:
+		slog.Warn("Device health queue full, dropping heartbeat", "device_id", heartbeat.DeviceID)
+	}
+}
+
+// Run processes queued telemetry and heartbeats until the context is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case b := <-m.queue:
+			var err error
+			if b.heartbeat != nil {
+				_, err = m.ProcessHeartbeat(ctx, b.heartbeat)
+			} else {
+				_, err = m.Process(ctx, b.userID, b.deviceID, b.records)
+			}
+			if err != nil {
+				slog.Error("Error processing device health", "device_id", b.deviceID, "error", err)
+			}
+		}
+	}
+}
+
+// Process folds a device's telemetry into its snapshots and health state, stores the events
+// produced by crossing alert thresholds and sends the configured notifications
+// Every record counts towards its snapshot bucket, but only records newer than the stored
+// state advance it, so late uploads cannot re-trigger or clear alerts.
+func (m *Monitor) Process(ctx context.Context, userID uuid.UUID, deviceID string, records []*models.TelemetryData) ([]*models.DeviceHealthEvent, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	sorted := make([]*models.TelemetryData, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	state, err := m.loadState(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	lastRecordedAt := state.LastRecordedAt
+	state.UserID = userID
+	state.UpdatedAt = time.Time{}
+
+	var (
+		events   []*models.DeviceHealthEvent
+		advanced bool
+	)
+	for _, record := range sorted {
+		if !record.Timestamp.After(lastRecordedAt) {
+			continue
+		}
+		advanced = true
+		for _, event := range m.advance(state, record) {
+			event.UserID = userID
+			events = append(events, event)
+		}
+	}
+	if !advanced {
+		state = nil
+	}
+
+	if err := m.repo.Record(ctx, state, m.snapshots(deviceID, sorted), events); err != nil {
+		return nil, fmt.Errorf("failed to record device health: %w", err)
+	}
+
+	if len(events) > 0 {
+		m.notify(ctx, userID, events)
+	}
+
+	return events, nil
+}
+
+// ProcessHeartbeat folds a device heartbeat into its health state and sends the notifications for
+// the low battery alert it may trigger
+// Heartbeats carry no GPS data, so they neither add to snapshots nor affect GPS fix tracking.
+// A heartbeat older than the stored state is ignored.
+func (m *Monitor) ProcessHeartbeat(ctx context.Context, heartbeat *models.DeviceHeartbeat) ([]*models.DeviceHealthEvent, error) {
+	state, err := m.loadState(ctx, heartbeat.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if !heartbeat.ReceivedAt.After(state.LastRecordedAt) {
+		return nil, nil
+	}
+
+	receivedAt := heartbeat.ReceivedAt
+	state.UserID = heartbeat.UserID
+	state.UpdatedAt = time.Time{}
+	state.SignalDbm = heartbeat.SignalDbm
+	state.LastHeartbeatAt = &receivedAt
+
+	var events []*models.DeviceHealthEvent
+	if event := m.advanceBattery(state, receivedAt, heartbeat.Battery, heartbeat.IsCharging); event != nil {
+		event.UserID = heartbeat.UserID
+		events = append(events, event)
+	}
+
+	if err := m.repo.Record(ctx, state, nil, events); err != nil {
+		return nil, fmt.Errorf("failed to record device heartbeat: %w", err)
+	}
+
+	if len(events) > 0 {
+		m.notify(ctx, heartbeat.UserID, events)
+	}
+
+	return events, nil
+}
+
+// loadState retrieves a device's stored health state, or an empty one if none was recorded yet
+func (m *Monitor) loadState(ctx context.Context, deviceID string) (*models.DeviceHealthState, error) {
+	state, err := m.repo.GetState(ctx, deviceID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrDeviceHealthNotFound) {
+			return nil, fmt.Errorf("failed to load device health: %w", err)
+		}
+		state = &models.DeviceHealthState{DeviceID: deviceID}
+	}
+	return state, nil
+}
+
+// advance applies one record to the state and returns the events it triggers
+func (m *Monitor) advance(state *models.DeviceHealthState, record *models.TelemetryData) []*models.DeviceHealthEvent {
+	var events []*models.DeviceHealthEvent
+	if event := m.advanceBattery(state, record.Timestamp, record.Battery, record.IsCharging); event != nil {
+		events = append(events, event)
+	}
+
+	if record.GPS.IsFixValid {
+		fixAt := record.Timestamp
+		state.LastFixAt = &fixAt
+		state.NoFixSince = nil
+		state.NoFixAlerted = false
+		return events
+	}
+
+	if state.NoFixSince == nil {
+		since := record.Timestamp
+		state.NoFixSince = &since
+	}
+	if m.cfg.NoFixAfter > 0 && !state.NoFixAlerted && record.Timestamp.Sub(*state.NoFixSince) >= m.cfg.NoFixAfter {
+		state.NoFixAlerted = true
+		since := *state.NoFixSince
+		events = append(events, &models.DeviceHealthEvent{
+			DeviceID:   state.DeviceID,
+			Type:       models.DeviceHealthNoFix,
+			RecordedAt: record.Timestamp,
+			Battery:    record.Battery,
+			NoFixSince: &since,
+		})
+	}
+
+	return events
+}
+
+// advanceBattery applies a battery reading to the state and returns the low_battery event it
+// triggers, if any
+func (m *Monitor) advanceBattery(state *models.DeviceHealthState, recordedAt time.Time, battery float64, isCharging bool) *models.DeviceHealthEvent {
+	state.Battery = battery
+	state.IsCharging = isCharging
+	state.LastRecordedAt = recordedAt
+
+	threshold := m.cfg.LowBatteryPercent
+	if threshold <= 0 {
+		return nil
+	}
+
+	switch {
+	case state.LowBatteryAlerted && (isCharging || battery > threshold+batteryRearmMargin):
+		state.LowBatteryAlerted = false
+	case !state.LowBatteryAlerted && !isCharging && battery <= threshold:
+		state.LowBatteryAlerted = true
+		return &models.DeviceHealthEvent{
+			DeviceID:   state.DeviceID,
+			Type:       models.DeviceHealthLowBattery,
+			RecordedAt: recordedAt,
+			Battery:    battery,
+		}
+	}
+	return nil
+}
+
+// snapshots aggregates sorted records into snapshot buckets of the configured width
+func (m *Monitor) snapshots(deviceID string, sorted []*models.TelemetryData) []*models.DeviceHealthSnapshot {
+	var (
+		snapshots []*models.DeviceHealthSnapshot
+		current   *models.DeviceHealthSnapshot
+	)
+	for _, record := range sorted {
+		bucket := record.Timestamp.UTC().Truncate(m.cfg.SnapshotInterval)
+		if current == nil || !current.BucketStart.Equal(bucket) {
+			current = &models.DeviceHealthSnapshot{
+				DeviceID:    deviceID,
+				BucketStart: bucket,
+				BatteryMin:  record.Battery,
+				BatteryMax:  record.Battery,
+			}
+			snapshots = append(snapshots, current)
+		}
+
+		current.Samples++
+		current.BatteryMin = min(current.BatteryMin, record.Battery)
+		current.BatteryMax = max(current.BatteryMax, record.Battery)
+		current.BatteryLast = record.Battery
+		current.IsCharging = record.IsCharging
+		current.SatellitesSum += int64(record.GPS.NumSatellites)
+		current.LastRecordedAt = record.Timestamp
+		if record.GPS.IsFixValid {
+			current.FixSamples++
+			current.HorizontalAccuracySum += record.GPS.HorizontalAccuracy
+		}
+		if record.ClockSkewMs != nil {
+			current.SkewSamples++
+			current.SkewSumMs += *record.ClockSkewMs
+			current.SkewAbsMaxMs = max(current.SkewAbsMaxMs, *record.ClockSkewMs, -*record.ClockSkewMs)
+		}
+		if record.DeviceTimestamp != nil || record.QualityFlags&models.QualityClockSkew != 0 {
+			current.SkewedSamples++
+		}
+	}
+	return snapshots
+}
+
+// notify delivers notifications, emails and webhooks for the given events
+func (m *Monitor) notify(ctx context.Context, userID uuid.UUID, events []*models.DeviceHealthEvent) {
+	var recipient string
+	if m.cfg.NotifyEmail && m.emailService != nil && slices.ContainsFunc(events, m.emailed) {
+		user, err := m.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			slog.Error("Error loading user for device health alert", "user_id", userID, "error", err)
+		} else {
+			recipient = user.Email
+		}
+	}
+
+	for _, event := range events {
+		if !m.emailed(event) {
+			if err := m.notifier.Notify(ctx, notify.LowBattery(event)); err != nil {
+				slog.Error("Error sending device health notification", "device_id", event.DeviceID, "error", err)
+			}
+		} else if recipient != "" {
+			alert := email.DeviceHealthAlert{
+				EventType:  string(event.Type),
+				DeviceID:   event.DeviceID,
+				RecordedAt: event.RecordedAt,
+				Battery:    event.Battery,
+			}
+			if event.NoFixSince != nil {
+				alert.NoFixSince = *event.NoFixSince
+			}
+			if err := m.emailService.SendDeviceHealthAlertEmail(ctx, recipient, alert); err != nil {
+				slog.Error("Error sending device health alert email", "device_id", event.DeviceID, "error", err)
+			}
+		}
+
+		if m.cfg.WebhookURL != "" {
+			if err := webhook.Post(ctx, m.httpClient, m.cfg.WebhookURL, WebhookPayload{Event: event}); err != nil {
+				slog.Error("Error delivering device health webhook", "device_id", event.DeviceID, "error", err)
+			}
+		}
+	}
+}
+
+// emailed reports whether an event is emailed directly rather than sent through the notifier
+func (m *Monitor) emailed(event *models.DeviceHealthEvent) bool {
+	return m.notifier == nil || event.Type != models.DeviceHealthLowBattery
+}
@@ -0,0 +1,343 @@
+package devicehealth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+func testConfig() config.DeviceHealthConfig {
+	return config.DeviceHealthConfig{
+		Enabled:           true,
+		LowBatteryPercent: 20,
+		NoFixAfter:        10 * time.Minute,
+		SnapshotInterval:  15 * time.Minute,
+		NotifyEmail:       true,
+	}
+}
+
+func telemetryAt(userID uuid.UUID, elapsed time.Duration, battery float64, fix bool) *models.TelemetryData {
+	return &models.TelemetryData{
+		UserID:    &userID,
+		DeviceID:  "device-1",
+		Timestamp: start.Add(elapsed),
+		Battery:   battery,
+		GPS: models.GpsData{
+			IsFixValid:         fix,
+			NumSatellites:      10,
+			HorizontalAccuracy: 1.5,
+		},
+	}
+}
+
+// recordingRepo captures what the monitor stores in a mock repository
+type recordingRepo struct {
+	*repository.MockDeviceHealthRepository
+	state     *models.DeviceHealthState
+	snapshots []*models.DeviceHealthSnapshot
+	events    []*models.DeviceHealthEvent
+}
+
+func newRecordingRepo() *recordingRepo {
+	r := &recordingRepo{MockDeviceHealthRepository: repository.NewMockDeviceHealthRepository()}
+	r.RecordFunc = func(_ context.Context, state *models.DeviceHealthState, snapshots []*models.DeviceHealthSnapshot, events []*models.DeviceHealthEvent) error {
+		r.state, r.snapshots, r.events = state, snapshots, events
+		return nil
+	}
+	return r
+}
+
+func TestMonitor_EmitsLowBatteryOnce(t *testing.T) {
+	userID := uuid.New()
+	repo := newRecordingRepo()
+	monitor := NewMonitor(repo, repository.NewMockUserRepository(), testConfig())
+
+	// Out of order on purpose: the monitor replays records chronologically
+	events, err := monitor.Process(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 2*time.Minute, 18, true),
+		telemetryAt(userID, 0, 30, true),
+		telemetryAt(userID, time.Minute, 19, true),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, models.DeviceHealthLowBattery, events[0].Type)
+	assert.Equal(t, 19.0, events[0].Battery)
+	assert.Equal(t, userID, events[0].UserID)
+
+	require.NotNil(t, repo.state)
+	assert.True(t, repo.state.LowBatteryAlerted)
+	assert.Equal(t, 18.0, repo.state.Battery)
+
+	require.Len(t, repo.snapshots, 1)
+	snapshot := repo.snapshots[0]
+	assert.Equal(t, start, snapshot.BucketStart)
+	assert.Equal(t, 3, snapshot.Samples)
+	assert.Equal(t, 3, snapshot.FixSamples)
+	assert.Equal(t, 18.0, snapshot.BatteryMin)
+	assert.Equal(t, 30.0, snapshot.BatteryMax)
+	assert.Equal(t, 18.0, snapshot.BatteryLast)
+	assert.InDelta(t, 10.0, snapshot.AvgSatellites(), 0.001)
+}
+
+func TestMonitor_RearmsLowBatteryAfterRecovery(t *testing.T) {
+	userID := uuid.New()
+	repo := newRecordingRepo()
+	repo.GetStateFunc = func(_ context.Context, deviceID string) (*models.DeviceHealthState, error) {
+		return &models.DeviceHealthState{
+			DeviceID:          deviceID,
+			UserID:            userID,
+			Battery:           15,
+			LastRecordedAt:    start.Add(-time.Minute),
+			LowBatteryAlerted: true,
+		}, nil
+	}
+	monitor := NewMonitor(repo, repository.NewMockUserRepository(), testConfig())
+
+	// Hovering just above the threshold keeps the alert latched; charging re-arms it
+	events, err := monitor.Process(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 0, 22, true),
+		telemetryAt(userID, time.Minute, 19, true),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	charging := telemetryAt(userID, 2*time.Minute, 19, true)
+	charging.IsCharging = true
+	events, err = monitor.Process(context.Background(), userID, "device-1", []*models.TelemetryData{
+		charging,
+		telemetryAt(userID, 3*time.Minute, 19, true),
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.DeviceHealthLowBattery, events[0].Type)
+}
+
+func TestMonitor_EmitsNoFixAfterThreshold(t *testing.T) {
+	userID := uuid.New()
+	repo := newRecordingRepo()
+	monitor := NewMonitor(repo, repository.NewMockUserRepository(), testConfig())
+
+	events, err := monitor.Process(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 0, 80, true),
+		telemetryAt(userID, time.Minute, 80, false),
+		telemetryAt(userID, 6*time.Minute, 80, false),
+		telemetryAt(userID, 11*time.Minute, 80, false),
+		telemetryAt(userID, 12*time.Minute, 80, false),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, models.DeviceHealthNoFix, events[0].Type)
+	require.NotNil(t, events[0].NoFixSince)
+	assert.Equal(t, start.Add(time.Minute), *events[0].NoFixSince)
+	assert.True(t, repo.state.NoFixAlerted)
+	assert.Equal(t, start, *repo.state.LastFixAt)
+
+	// A valid fix clears the condition
+	events, err = monitor.Process(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 13*time.Minute, 80, true),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Nil(t, repo.state.NoFixSince)
+	assert.False(t, repo.state.NoFixAlerted)
+}
+
+func TestMonitor_LateRecordsOnlyUpdateSnapshots(t *testing.T) {
+	userID := uuid.New()
+	repo := newRecordingRepo()
+	repo.GetStateFunc = func(_ context.Context, deviceID string) (*models.DeviceHealthState, error) {
+		return &models.DeviceHealthState{DeviceID: deviceID, UserID: userID, Battery: 90, LastRecordedAt: start.Add(time.Hour)}, nil
+	}
+	monitor := NewMonitor(repo, repository.NewMockUserRepository(), testConfig())
+
+	events, err := monitor.Process(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 0, 5, false),
+		telemetryAt(userID, 20*time.Minute, 5, false),
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, events)
+	assert.Nil(t, repo.state)
+	require.Len(t, repo.snapshots, 2)
+	assert.Equal(t, start.Add(15*time.Minute), repo.snapshots[1].BucketStart)
+}
+
+func TestMonitor_ProcessesHeartbeat(t *testing.T) {
+	userID := uuid.New()
+	noFixSince := start.Add(-time.Hour)
+	repo := newRecordingRepo()
+	repo.GetStateFunc = func(_ context.Context, deviceID string) (*models.DeviceHealthState, error) {
+		return &models.DeviceHealthState{DeviceID: deviceID, UserID: userID, Battery: 40, LastRecordedAt: start, NoFixSince: &noFixSince}, nil
+	}
+	monitor := NewMonitor(repo, repository.NewMockUserRepository(), testConfig())
+
+	signal := -95
+	heartbeat := &models.DeviceHeartbeat{DeviceID: "device-1", UserID: userID, Battery: 15, SignalDbm: &signal, ReceivedAt: start.Add(time.Minute)}
+	events, err := monitor.ProcessHeartbeat(context.Background(), heartbeat)
+	require.NoError(t, err)
+
+	// Battery alerts fire as for telemetry, while GPS fix tracking is left alone
+	require.Len(t, events, 1)
+	assert.Equal(t, models.DeviceHealthLowBattery, events[0].Type)
+	assert.Equal(t, userID, events[0].UserID)
+	require.NotNil(t, repo.state)
+	assert.Equal(t, 15.0, repo.state.Battery)
+	assert.Equal(t, -95, *repo.state.SignalDbm)
+	assert.Equal(t, start.Add(time.Minute), *repo.state.LastHeartbeatAt)
+	assert.Equal(t, noFixSince, *repo.state.NoFixSince)
+	assert.False(t, repo.state.NoFixAlerted)
+	assert.Empty(t, repo.snapshots)
+
+	// A heartbeat older than the stored state is ignored
+	repo.state = nil
+	heartbeat.ReceivedAt = start.Add(-time.Minute)
+	events, err = monitor.ProcessHeartbeat(context.Background(), heartbeat)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Nil(t, repo.state)
+}
+
+func TestMonitor_SnapshotsClockSkew(t *testing.T) {
+	userID := uuid.New()
+	repo := newRecordingRepo()
+	monitor := NewMonitor(repo, repository.NewMockUserRepository(), testConfig())
+
+	ahead, behind := int64(5000), int64(-800)
+	reported := start.Add(5 * time.Second)
+	records := []*models.TelemetryData{
+		telemetryAt(userID, 0, 90, true),
+		telemetryAt(userID, time.Minute, 90, true),
+		telemetryAt(userID, 2*time.Minute, 90, true),
+	}
+	records[1].ClockSkewMs, records[1].DeviceTimestamp = &ahead, &reported
+	records[2].ClockSkewMs = &behind
+
+	_, err := monitor.Process(context.Background(), userID, "device-1", records)
+	require.NoError(t, err)
+
+	require.Len(t, repo.snapshots, 1)
+	snapshot := repo.snapshots[0]
+	assert.Equal(t, 2, snapshot.SkewSamples)
+	assert.Equal(t, int64(4200), snapshot.SkewSumMs)
+	assert.Equal(t, int64(5000), snapshot.SkewAbsMaxMs)
+	assert.Equal(t, 1, snapshot.SkewedSamples)
+	assert.InDelta(t, 2100.0, snapshot.AvgClockSkewMs(), 0.001)
+}
+
+func TestMonitor_SendsNotifications(t *testing.T) {
+	userID := uuid.New()
+
+	var (
+		mu       sync.Mutex
+		payloads []WebhookPayload
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.WebhookURL = server.URL
+	userRepo := repository.NewMockUserRepository()
+	userRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+		return &models.User{ID: id, Email: "driver@example.com"}, nil
+	}
+	emailService := email.NewMockService()
+
+	monitor := NewMonitor(newRecordingRepo(), userRepo, cfg).WithEmailService(emailService).WithHTTPClient(server.Client())
+	_, err := monitor.Process(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 0, 10, true),
+	})
+	require.NoError(t, err)
+
+	emails := emailService.GetHealthAlertEmails()
+	require.Len(t, emails, 1)
+	assert.Equal(t, "driver@example.com", emails[0].To)
+	assert.Equal(t, "low_battery", emails[0].HealthAlert.EventType)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, payloads, 1)
+	assert.Equal(t, models.DeviceHealthLowBattery, payloads[0].Event.Type)
+}
+
+// recordingNotifier captures the notifications sent through it
+type recordingNotifier struct {
+	notifications []*models.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification *models.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func TestMonitor_SendsLowBatteryThroughNotifier(t *testing.T) {
+	userID := uuid.New()
+	userRepo := repository.NewMockUserRepository()
+	userRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+		return &models.User{ID: id, Email: "driver@example.com"}, nil
+	}
+	emailService := email.NewMockService()
+	notifier := &recordingNotifier{}
+
+	monitor := NewMonitor(newRecordingRepo(), userRepo, testConfig()).WithEmailService(emailService).WithNotifier(notifier)
+	_, err := monitor.Process(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 0, 10, false),
+		telemetryAt(userID, 11*time.Minute, 10, false),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, notifier.notifications, 1)
+	assert.Equal(t, models.NotificationLowBattery, notifier.notifications[0].Type)
+	assert.Equal(t, userID, notifier.notifications[0].UserID)
+
+	// Other alerts are still emailed directly
+	emails := emailService.GetHealthAlertEmails()
+	require.Len(t, emails, 1)
+	assert.Equal(t, "no_fix", emails[0].HealthAlert.EventType)
+}
+
+func TestMonitor_EnqueueGroupsAndSkipsUnowned(t *testing.T) {
+	userID := uuid.New()
+	monitor := NewMonitor(repository.NewMockDeviceHealthRepository(), repository.NewMockUserRepository(), testConfig())
+
+	anonymous := telemetryAt(userID, 0, 50, true)
+	anonymous.UserID = nil
+	duplicate := telemetryAt(userID, 0, 50, true)
+	duplicate.Duplicate = true
+	noFix := telemetryAt(userID, 0, 50, false)
+	otherDevice := telemetryAt(userID, 0, 50, true)
+	otherDevice.DeviceID = "device-2"
+
+	monitor.Enqueue([]*models.TelemetryData{
+		telemetryAt(userID, 0, 50, true), anonymous, duplicate, noFix, otherDevice,
+	})
+
+	require.Len(t, monitor.queue, 2)
+	first := <-monitor.queue
+	assert.Equal(t, "device-1", first.deviceID)
+	assert.Len(t, first.records, 2)
+	second := <-monitor.queue
+	assert.Equal(t, "device-2", second.deviceID)
+}
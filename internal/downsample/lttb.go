@@ -0,0 +1,53 @@
+// Package downsample reduces long series to a fixed number of representative points for charts.
+package downsample
+
+import "math"
+
+// LTTB picks threshold points from series using Largest-Triangle-Three-Buckets
+// The first and last points are always kept; every other bucket contributes the point forming the
+// largest triangle with the previously kept point and the average of the next bucket, which
+// preserves the peaks and troughs a chart needs. series must be ordered by x. It is returned
+// unchanged when it already fits or when threshold is below 3.
+func LTTB[T any](series []T, threshold int, x, y func(T) float64) []T {
+	if threshold < 3 || len(series) <= threshold {
+		return series
+	}
+
+	sampled := make([]T, 0, threshold)
+	sampled = append(sampled, series[0])
+
+	// Points between the first and last are split into threshold-2 buckets
+	bucketSize := float64(len(series)-2) / float64(threshold-2)
+	kept := 0
+
+	for i := 0; i < threshold-2; i++ {
+		start := int(float64(i)*bucketSize) + 1
+		end := int(float64(i+1)*bucketSize) + 1
+
+		// The next bucket's average stands in for the point not chosen yet
+		nextStart := end
+		nextEnd := min(int(float64(i+2)*bucketSize)+1, len(series))
+		var avgX, avgY float64
+		for _, p := range series[nextStart:nextEnd] {
+			avgX += x(p)
+			avgY += y(p)
+		}
+		n := float64(nextEnd - nextStart)
+		avgX /= n
+		avgY /= n
+
+		ax, ay := x(series[kept]), y(series[kept])
+		best, bestArea := start, -1.0
+		for j := start; j < end; j++ {
+			area := math.Abs((ax-avgX)*(y(series[j])-ay) - (ax-x(series[j]))*(avgY-ay))
+			if area > bestArea {
+				best, bestArea = j, area
+			}
+		}
+
+		sampled = append(sampled, series[best])
+		kept = best
+	}
+
+	return append(sampled, series[len(series)-1])
+}
@@ -0,0 +1,51 @@
+package downsample
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type point struct{ x, y float64 }
+
+func px(p point) float64 { return p.x }
+func py(p point) float64 { return p.y }
+
+func TestLTTB_KeepsEndpointsAndThreshold(t *testing.T) {
+	series := make([]point, 1000)
+	for i := range series {
+		series[i] = point{float64(i), math.Sin(float64(i) / 50)}
+	}
+
+	sampled := LTTB(series, 100, px, py)
+
+	require.Len(t, sampled, 100)
+	assert.Equal(t, series[0], sampled[0])
+	assert.Equal(t, series[999], sampled[99])
+	for i := 1; i < len(sampled); i++ {
+		assert.Greater(t, sampled[i].x, sampled[i-1].x)
+	}
+}
+
+func TestLTTB_PreservesSpikes(t *testing.T) {
+	series := make([]point, 500)
+	for i := range series {
+		series[i] = point{float64(i), 10}
+	}
+	series[123].y = 200
+	series[377].y = -50
+
+	sampled := LTTB(series, 20, px, py)
+
+	assert.Contains(t, sampled, series[123])
+	assert.Contains(t, sampled, series[377])
+}
+
+func TestLTTB_ReturnsShortSeriesUnchanged(t *testing.T) {
+	series := []point{{0, 1}, {1, 2}, {2, 3}}
+
+	assert.Equal(t, series, LTTB(series, 10, px, py))
+	assert.Equal(t, series, LTTB(series, 2, px, py))
+}
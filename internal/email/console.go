@@ -3,16 +3,35 @@ package email
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
+	"sync"
+	"time"
 )
 
+// maxSentEmails is how many of the most recent emails the console service keeps for inspection
+const maxSentEmails = 100
+
+// SentEmail is an email logged by the console service
+type SentEmail struct {
+	Kind    string            `json:"kind"`
+	To      string            `json:"to"`
+	From    string            `json:"from"`
+	Subject string            `json:"subject"`
+	Details map[string]string `json:"details,omitempty"` // e.g. reset_url or accept_url
+	SentAt  time.Time         `json:"sentAt"`
+}
+
 // ConsoleService is an email service that logs emails to the console
-// This is useful for local development and testing
+// This is useful for local development and testing. The most recent emails are also kept in
+// memory so the dev console can show them.
 type ConsoleService struct {
 	fromAddress string
 	fromName    string
 	appURL      string
+
+	mu   sync.Mutex
+	sent []SentEmail // Oldest first, at most maxSentEmails
 }
 
 // NewConsoleService creates a new console-based email service
@@ -28,37 +47,146 @@ func NewConsoleService(fromAddress, fromName, appURL string) *ConsoleService {
 func (s *ConsoleService) SendPasswordResetEmail(_ context.Context, toEmail, resetToken string) error {
 	resetURL := fmt.Sprintf("%s/reset-password?token=%s", strings.TrimSuffix(s.appURL, "/"), resetToken)
 
-	log.Println("========================================")
-	log.Println("📧 PASSWORD RESET EMAIL (Console Mode)")
-	log.Println("========================================")
-	log.Printf("To: %s", toEmail)
-	log.Printf("From: %s <%s>", s.fromName, s.fromAddress)
-	log.Println("Subject: Password Reset Request")
-	log.Println("----------------------------------------")
-	log.Println("You requested a password reset for your account.")
-	log.Println("")
-	log.Printf("Reset URL: %s", resetURL)
-	log.Printf("Reset Token: %s", resetToken)
-	log.Println("")
-	log.Println("This link will expire in 12 hours.")
-	log.Println("========================================")
+	s.log("password_reset", toEmail, "Password Reset Request",
+		"reset_url", resetURL,
+		"reset_token", resetToken,
+		"expires_in", "12h",
+	)
 
 	return nil
 }
 
 // SendPasswordChangedEmail logs the password changed notification to the console
 func (s *ConsoleService) SendPasswordChangedEmail(_ context.Context, toEmail string) error {
-	log.Println("========================================")
-	log.Println("📧 PASSWORD CHANGED EMAIL (Console Mode)")
-	log.Println("========================================")
-	log.Printf("To: %s", toEmail)
-	log.Printf("From: %s <%s>", s.fromName, s.fromAddress)
-	log.Println("Subject: Your Password Has Been Changed")
-	log.Println("----------------------------------------")
-	log.Println("Your password has been successfully changed.")
-	log.Println("")
-	log.Println("If you did not make this change, please contact support immediately.")
-	log.Println("========================================")
+	s.log("password_changed", toEmail, "Your Password Has Been Changed")
+
+	return nil
+}
+
+// SendMagicLinkEmail logs the magic sign-in link to the console
+func (s *ConsoleService) SendMagicLinkEmail(_ context.Context, toEmail, token string, expiresAt time.Time) error {
+	loginURL := fmt.Sprintf("%s/magic-login?token=%s", strings.TrimSuffix(s.appURL, "/"), token)
+
+	s.log("magic_link", toEmail, "Your sign-in link",
+		"login_url", loginURL,
+		"login_token", token,
+		"expires_at", expiresAt.UTC().Format(time.RFC3339),
+	)
 
 	return nil
 }
+
+// SendGeofenceAlertEmail logs the geofence alert to the console
+func (s *ConsoleService) SendGeofenceAlertEmail(_ context.Context, toEmail string, alert GeofenceAlert) error {
+	s.log("geofence_alert", toEmail, fmt.Sprintf("%s %s %s", alert.DeviceID, alert.Action(), alert.GeofenceName),
+		"device_id", alert.DeviceID,
+		"geofence", alert.GeofenceName,
+		"recorded_at", alert.RecordedAt.UTC().Format(time.RFC3339),
+		"latitude", alert.Latitude,
+		"longitude", alert.Longitude,
+	)
+
+	return nil
+}
+
+// SendDeviceHealthAlertEmail logs the device health alert to the console
+func (s *ConsoleService) SendDeviceHealthAlertEmail(_ context.Context, toEmail string, alert DeviceHealthAlert) error {
+	s.log("device_health_alert", toEmail, alert.Summary(),
+		"recorded_at", alert.RecordedAt.UTC().Format(time.RFC3339),
+		"battery", alert.Battery,
+	)
+
+	return nil
+}
+
+// SendAccountLockedEmail logs the account locked notification to the console
+func (s *ConsoleService) SendAccountLockedEmail(_ context.Context, toEmail, ipAddress string, lockedUntil time.Time) error {
+	s.log("account_locked", toEmail, "Your account has been temporarily locked",
+		"ip_address", ipAddress,
+		"locked_until", lockedUntil.UTC().Format(time.RFC3339),
+	)
+
+	return nil
+}
+
+// SendNewLoginLocationEmail logs the new login location notification to the console
+func (s *ConsoleService) SendNewLoginLocationEmail(_ context.Context, toEmail string, alert LoginAlert) error {
+	s.log("new_login_location", toEmail, "New sign-in from "+alert.Location,
+		"location", alert.Location,
+		"ip_address", alert.IPAddress,
+		"user_agent", alert.UserAgent,
+		"logged_in_at", alert.LoggedInAt.UTC().Format(time.RFC3339),
+	)
+
+	return nil
+}
+
+// SendOrganizationInvitationEmail logs the organization invitation to the console
+func (s *ConsoleService) SendOrganizationInvitationEmail(_ context.Context, toEmail, token string, invitation OrganizationInvitation) error {
+	acceptURL := fmt.Sprintf("%s/invitations/accept?token=%s", strings.TrimSuffix(s.appURL, "/"), token)
+
+	s.log("organization_invitation", toEmail, fmt.Sprintf("You've been invited to join %s", invitation.OrganizationName),
+		"inviter", invitation.InviterEmail,
+		"organization", invitation.OrganizationName,
+		"role", invitation.Role,
+		"accept_url", acceptURL,
+		"invitation_token", token,
+		"expires_at", invitation.ExpiresAt.UTC().Format(time.RFC3339),
+	)
+
+	return nil
+}
+
+// SendNotificationEmail logs the notification email to the console
+func (s *ConsoleService) SendNotificationEmail(_ context.Context, toEmail string, notification Notification) error {
+	attrs := []interface{}{"body", notification.Body}
+	if notification.Link != "" {
+		attrs = append(attrs, "url", strings.TrimSuffix(s.appURL, "/")+notification.Link)
+	}
+	s.log("notification", toEmail, notification.Title, attrs...)
+
+	return nil
+}
+
+// Sent returns the most recently logged emails, newest first
+func (s *ConsoleService) Sent() []SentEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent := make([]SentEmail, len(s.sent))
+	for i, e := range s.sent {
+		sent[len(s.sent)-1-i] = e
+	}
+	return sent
+}
+
+// log writes one email as a single log record and keeps it for Sent
+func (s *ConsoleService) log(kind, toEmail, subject string, attrs ...interface{}) {
+	from := fmt.Sprintf("%s <%s>", s.fromName, s.fromAddress)
+	args := append([]interface{}{
+		"kind", kind,
+		"to", toEmail,
+		"from", from,
+		"subject", subject,
+	}, attrs...)
+	slog.Info("Email (console mode)", args...)
+
+	details := make(map[string]string, len(attrs)/2)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		details[fmt.Sprint(attrs[i])] = fmt.Sprint(attrs[i+1])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.sent) == maxSentEmails {
+		s.sent = append(s.sent[:0], s.sent[1:]...)
+	}
+	s.sent = append(s.sent, SentEmail{
+		Kind:    kind,
+		To:      toEmail,
+		From:    from,
+		Subject: subject,
+		Details: details,
+		SentAt:  time.Now().UTC(),
+	})
+}
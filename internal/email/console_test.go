@@ -0,0 +1,40 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleService_Sent(t *testing.T) {
+	service := NewConsoleService("noreply@example.com", "AVT", "http://localhost:8080/")
+	ctx := context.Background()
+
+	require.NoError(t, service.SendPasswordResetEmail(ctx, "user@example.com", "token-123"))
+	require.NoError(t, service.SendPasswordChangedEmail(ctx, "user@example.com"))
+
+	sent := service.Sent()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "password_changed", sent[0].Kind)
+	assert.Equal(t, "password_reset", sent[1].Kind)
+	assert.Equal(t, "user@example.com", sent[1].To)
+	assert.Equal(t, "AVT <noreply@example.com>", sent[1].From)
+	assert.Equal(t, "http://localhost:8080/reset-password?token=token-123", sent[1].Details["reset_url"])
+	assert.False(t, sent[1].SentAt.IsZero())
+}
+
+func TestConsoleService_SentKeepsMostRecent(t *testing.T) {
+	service := NewConsoleService("noreply@example.com", "AVT", "http://localhost:8080")
+
+	for i := range maxSentEmails + 5 {
+		require.NoError(t, service.SendPasswordChangedEmail(context.Background(), fmt.Sprintf("user%d@example.com", i)))
+	}
+
+	sent := service.Sent()
+	require.Len(t, sent, maxSentEmails)
+	assert.Equal(t, fmt.Sprintf("user%d@example.com", maxSentEmails+4), sent[0].To)
+	assert.Equal(t, "user5@example.com", sent[maxSentEmails-1].To)
+}
@@ -1,7 +1,69 @@
 // Package email provides email service functionality for the AVT service.
 package email
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GeofenceAlert describes a device entering or leaving a geofence.
+type GeofenceAlert struct {
+	GeofenceName string
+	EventType    string // "enter" or "exit"
+	DeviceID     string
+	RecordedAt   time.Time
+	Latitude     float64
+	Longitude    float64
+}
+
+// Action returns the verb used in alert subjects ("entered" or "left").
+func (a GeofenceAlert) Action() string {
+	if a.EventType == "exit" {
+		return "left"
+	}
+	return "entered"
+}
+
+// DeviceHealthAlert describes a device reporting low battery or losing its GPS fix for too long.
+type DeviceHealthAlert struct {
+	EventType  string // "low_battery" or "no_fix"
+	DeviceID   string
+	RecordedAt time.Time
+	Battery    float64
+	NoFixSince time.Time // Only set for no_fix alerts
+}
+
+// Summary returns the one-line description used in alert subjects.
+func (a DeviceHealthAlert) Summary() string {
+	if a.EventType == "no_fix" {
+		return fmt.Sprintf("%s has had no GPS fix since %s", a.DeviceID, a.NoFixSince.UTC().Format(time.RFC1123))
+	}
+	return fmt.Sprintf("%s battery is low (%.0f)", a.DeviceID, a.Battery)
+}
+
+// OrganizationInvitation describes an invitation to join an organization.
+type OrganizationInvitation struct {
+	OrganizationName string
+	InviterEmail     string
+	Role             string
+	ExpiresAt        time.Time
+}
+
+// LoginAlert describes a login from a country the account was not used from before.
+type LoginAlert struct {
+	Location   string // e.g. "Lyon, Auvergne-Rhone-Alpes, France"
+	IPAddress  string
+	UserAgent  string
+	LoggedInAt time.Time
+}
+
+// Notification is an in-app notification delivered by email.
+type Notification struct {
+	Title string
+	Body  string // Plain text; paragraphs are separated by blank lines
+	Link  string // Optional: path within the app, e.g. "/sessions/<id>"
+}
 
 // Service defines the interface for sending emails.
 // Implementations include Mailgun for production and Mock for testing.
@@ -15,4 +77,35 @@ type Service interface {
 	// This is a security notification to alert users of potential unauthorized access.
 	// Returns an error if the email fails to send.
 	SendPasswordChangedEmail(ctx context.Context, to string) error
+
+	// SendMagicLinkEmail sends a one-time sign-in link to the user.
+	// The token is included in the email as part of the sign-in link.
+	// Returns an error if the email fails to send.
+	SendMagicLinkEmail(ctx context.Context, to, token string, expiresAt time.Time) error
+
+	// SendGeofenceAlertEmail notifies the user that one of their devices entered or left a geofence.
+	// Returns an error if the email fails to send.
+	SendGeofenceAlertEmail(ctx context.Context, to string, alert GeofenceAlert) error
+
+	// SendDeviceHealthAlertEmail notifies the user that one of their devices needs attention.
+	// Returns an error if the email fails to send.
+	SendDeviceHealthAlertEmail(ctx context.Context, to string, alert DeviceHealthAlert) error
+
+	// SendAccountLockedEmail notifies the user that their account was locked after repeated failed logins.
+	// ipAddress is the address of the attempt that triggered the lock.
+	// Returns an error if the email fails to send.
+	SendAccountLockedEmail(ctx context.Context, to, ipAddress string, lockedUntil time.Time) error
+
+	// SendNewLoginLocationEmail notifies the user of a login from a new location.
+	// Returns an error if the email fails to send.
+	SendNewLoginLocationEmail(ctx context.Context, to string, alert LoginAlert) error
+
+	// SendOrganizationInvitationEmail invites the recipient to join an organization.
+	// The token is included in the email as part of the acceptance link.
+	// Returns an error if the email fails to send.
+	SendOrganizationInvitationEmail(ctx context.Context, to, token string, invitation OrganizationInvitation) error
+
+	// SendNotificationEmail delivers an in-app notification to the user by email.
+	// Returns an error if the email fails to send.
+	SendNotificationEmail(ctx context.Context, to string, notification Notification) error
 }
@@ -3,6 +3,7 @@ package email
 import (
 	"context"
 	"fmt"
+	"html"
 	"os"
 	"strings"
 	"time"
@@ -154,3 +155,384 @@ This is an automated message, please do not reply.`
 
 	return nil
 }
+
+// SendMagicLinkEmail sends a one-time sign-in link to the user.
+func (s *MailgunService) SendMagicLinkEmail(ctx context.Context, to, token string, expiresAt time.Time) error {
+	loginLink := fmt.Sprintf("%s/magic-login?token=%s", s.appURL, token)
+	expires := expiresAt.UTC().Format(time.RFC1123)
+
+	subject := "Your sign-in link"
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f8f9fa; border-radius: 5px; padding: 30px; margin-bottom: 20px;">
+        <h2 style="color: #2c3e50; margin-top: 0;">Sign In</h2>
+        <p>Click the button below to sign in. No password needed:</p>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="%s" style="background-color: #007bff; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block; font-weight: bold;">Sign In</a>
+        </div>
+        <p style="color: #666; font-size: 14px;">Or copy and paste this link into your browser:</p>
+        <p style="word-break: break-all; background-color: #fff; padding: 10px; border-radius: 3px; font-size: 12px; border: 1px solid #ddd;">%s</p>
+        <p style="color: #666; font-size: 14px; margin-top: 30px;">This link can be used once and expires on %s.</p>
+        <p style="color: #666; font-size: 14px;">If you didn't request this, you can safely ignore this email.</p>
+    </div>
+    <p style="color: #999; font-size: 12px; text-align: center;">This is an automated message, please do not reply.</p>
+</body>
+</html>`, loginLink, loginLink, expires)
+
+	textBody := fmt.Sprintf(`Sign In
+
+Visit the link below to sign in. No password needed:
+
+%s
+
+This link can be used once and expires on %s.
+
+If you didn't request this, you can safely ignore this email.
+
+---
+This is an automated message, please do not reply.`, loginLink, expires)
+
+	sender := fmt.Sprintf("%s <%s>", s.fromName, s.fromAddress)
+	message := mailgun.NewMessage(s.domain, sender, subject, textBody, to)
+	message.SetHTML(htmlBody)
+
+	// Set timeout for the request
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.client.Send(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send magic link email: %w", err)
+	}
+
+	return nil
+}
+
+// SendGeofenceAlertEmail notifies the user that a device entered or left a geofence.
+func (s *MailgunService) SendGeofenceAlertEmail(ctx context.Context, to string, alert GeofenceAlert) error {
+	subject := fmt.Sprintf("%s %s %s", alert.DeviceID, alert.Action(), alert.GeofenceName)
+	recordedAt := alert.RecordedAt.UTC().Format(time.RFC1123)
+	mapLink := fmt.Sprintf("https://www.openstreetmap.org/?mlat=%.6f&mlon=%.6f#map=16/%.6f/%.6f",
+		alert.Latitude, alert.Longitude, alert.Latitude, alert.Longitude)
+
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f8f9fa; border-radius: 5px; padding: 30px; margin-bottom: 20px;">
+        <h2 style="color: #2c3e50; margin-top: 0;">Geofence Alert</h2>
+        <p>Device <strong>%s</strong> %s <strong>%s</strong>.</p>
+        <p style="color: #666; font-size: 14px;">Time: %s</p>
+        <p style="color: #666; font-size: 14px;">Position: <a href="%s">%.6f, %.6f</a></p>
+    </div>
+    <p style="color: #999; font-size: 12px; text-align: center;">This is an automated message, please do not reply.</p>
+</body>
+</html>`, html.EscapeString(alert.DeviceID), alert.Action(), html.EscapeString(alert.GeofenceName),
+		recordedAt, mapLink, alert.Latitude, alert.Longitude)
+
+	textBody := fmt.Sprintf(`Geofence Alert
+
+Device %s %s %s.
+
+Time: %s
+Position: %.6f, %.6f
+%s
+
+---
+This is an automated message, please do not reply.`, alert.DeviceID, alert.Action(), alert.GeofenceName,
+		recordedAt, alert.Latitude, alert.Longitude, mapLink)
+
+	sender := fmt.Sprintf("%s <%s>", s.fromName, s.fromAddress)
+	message := mailgun.NewMessage(s.domain, sender, subject, textBody, to)
+	message.SetHTML(htmlBody)
+
+	// Set timeout for the request
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.client.Send(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send geofence alert email: %w", err)
+	}
+
+	return nil
+}
+
+// SendDeviceHealthAlertEmail notifies the user that a device reported low battery or lost its GPS fix.
+func (s *MailgunService) SendDeviceHealthAlertEmail(ctx context.Context, to string, alert DeviceHealthAlert) error {
+	subject := alert.Summary()
+	recordedAt := alert.RecordedAt.UTC().Format(time.RFC1123)
+
+	advice := "Charge the device before your next session."
+	if alert.EventType == "no_fix" {
+		advice = "Check that the device has a clear view of the sky and that its antenna is connected."
+	}
+
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f8f9fa; border-radius: 5px; padding: 30px; margin-bottom: 20px;">
+        <h2 style="color: #2c3e50; margin-top: 0;">Device Health Alert</h2>
+        <p>%s.</p>
+        <p>%s</p>
+        <p style="color: #666; font-size: 14px;">Time: %s</p>
+        <p style="color: #666; font-size: 14px;">Battery: %.1f</p>
+    </div>
+    <p style="color: #999; font-size: 12px; text-align: center;">This is an automated message, please do not reply.</p>
+</body>
+</html>`, html.EscapeString(alert.Summary()), advice, recordedAt, alert.Battery)
+
+	textBody := fmt.Sprintf(`Device Health Alert
+
+%s.
+
+%s
+
+Time: %s
+Battery: %.1f
+
+---
+This is an automated message, please do not reply.`, alert.Summary(), advice, recordedAt, alert.Battery)
+
+	sender := fmt.Sprintf("%s <%s>", s.fromName, s.fromAddress)
+	message := mailgun.NewMessage(s.domain, sender, subject, textBody, to)
+	message.SetHTML(htmlBody)
+
+	// Set timeout for the request
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.client.Send(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send device health alert email: %w", err)
+	}
+
+	return nil
+}
+
+// SendAccountLockedEmail notifies the user that their account was temporarily locked.
+func (s *MailgunService) SendAccountLockedEmail(ctx context.Context, to, ipAddress string, lockedUntil time.Time) error {
+	subject := "Your account has been temporarily locked"
+	until := lockedUntil.UTC().Format(time.RFC1123)
+
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f8f9fa; border-radius: 5px; padding: 30px; margin-bottom: 20px;">
+        <h2 style="color: #2c3e50; margin-top: 0;">Account Locked</h2>
+        <p>We locked your account after several failed sign-in attempts, the last one from <strong>%s</strong>.</p>
+        <p>You can sign in again after <strong>%s</strong>.</p>
+        <p style="color: #e74c3c; font-size: 14px;"><strong>If this wasn't you,</strong> someone may be trying to guess your password. We recommend resetting your password.</p>
+    </div>
+    <p style="color: #999; font-size: 12px; text-align: center;">This is an automated message, please do not reply.</p>
+</body>
+</html>`, html.EscapeString(ipAddress), until)
+
+	textBody := fmt.Sprintf(`Account Locked
+
+We locked your account after several failed sign-in attempts, the last one from %s.
+
+You can sign in again after %s.
+
+If this wasn't you, someone may be trying to guess your password. We recommend resetting your password.
+
+---
+This is an automated message, please do not reply.`, ipAddress, until)
+
+	sender := fmt.Sprintf("%s <%s>", s.fromName, s.fromAddress)
+	message := mailgun.NewMessage(s.domain, sender, subject, textBody, to)
+	message.SetHTML(htmlBody)
+
+	// Set timeout for the request
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.client.Send(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send account locked email: %w", err)
+	}
+
+	return nil
+}
+
+// SendNewLoginLocationEmail notifies the user of a sign-in from a country the account was not used from before.
+func (s *MailgunService) SendNewLoginLocationEmail(ctx context.Context, to string, alert LoginAlert) error {
+	subject := "New sign-in from " + alert.Location
+	when := alert.LoggedInAt.UTC().Format(time.RFC1123)
+
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f8f9fa; border-radius: 5px; padding: 30px; margin-bottom: 20px;">
+        <h2 style="color: #2c3e50; margin-top: 0;">New Sign-in Location</h2>
+        <p>Your account was signed in to from <strong>%s</strong>, a country it was not used from before.</p>
+        <p style="color: #666; font-size: 14px;">Time: %s<br>IP address: %s<br>Device: %s</p>
+        <p style="color: #e74c3c; font-size: 14px;"><strong>If this wasn't you,</strong> change your password and sign out your other sessions.</p>
+    </div>
+    <p style="color: #999; font-size: 12px; text-align: center;">This is an automated message, please do not reply.</p>
+</body>
+</html>`, html.EscapeString(alert.Location), when, html.EscapeString(alert.IPAddress), html.EscapeString(alert.UserAgent))
+
+	textBody := fmt.Sprintf(`New Sign-in Location
+
+Your account was signed in to from %s, a country it was not used from before.
+
+Time: %s
+IP address: %s
+Device: %s
+
+If this wasn't you, change your password and sign out your other sessions.
+
+---
+This is an automated message, please do not reply.`, alert.Location, when, alert.IPAddress, alert.UserAgent)
+
+	sender := fmt.Sprintf("%s <%s>", s.fromName, s.fromAddress)
+	message := mailgun.NewMessage(s.domain, sender, subject, textBody, to)
+	message.SetHTML(htmlBody)
+
+	// Set timeout for the request
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.client.Send(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send new login location email: %w", err)
+	}
+
+	return nil
+}
+
+// SendOrganizationInvitationEmail sends an invitation link to join an organization.
+func (s *MailgunService) SendOrganizationInvitationEmail(ctx context.Context, to, token string, invitation OrganizationInvitation) error {
+	acceptLink := fmt.Sprintf("%s/invitations/accept?token=%s", s.appURL, token)
+	subject := fmt.Sprintf("You've been invited to join %s", invitation.OrganizationName)
+	expires := invitation.ExpiresAt.UTC().Format(time.RFC1123)
+
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f8f9fa; border-radius: 5px; padding: 30px; margin-bottom: 20px;">
+        <h2 style="color: #2c3e50; margin-top: 0;">Organization Invitation</h2>
+        <p><strong>%s</strong> invited you to join <strong>%s</strong> as %s.</p>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="%s" style="background-color: #3498db; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Accept Invitation</a>
+        </div>
+        <p style="color: #666; font-size: 14px;">This invitation expires on %s. Sign in with this email address to accept it.</p>
+        <p style="color: #666; font-size: 14px;">If you weren't expecting this invitation, you can ignore this email.</p>
+    </div>
+    <p style="color: #999; font-size: 12px; text-align: center;">This is an automated message, please do not reply.</p>
+</body>
+</html>`, html.EscapeString(invitation.InviterEmail), html.EscapeString(invitation.OrganizationName),
+		html.EscapeString(invitation.Role), acceptLink, expires)
+
+	textBody := fmt.Sprintf(`Organization Invitation
+
+%s invited you to join %s as %s.
+
+Accept the invitation:
+%s
+
+This invitation expires on %s. Sign in with this email address to accept it.
+
+If you weren't expecting this invitation, you can ignore this email.
+
+---
+This is an automated message, please do not reply.`, invitation.InviterEmail, invitation.OrganizationName,
+		invitation.Role, acceptLink, expires)
+
+	sender := fmt.Sprintf("%s <%s>", s.fromName, s.fromAddress)
+	message := mailgun.NewMessage(s.domain, sender, subject, textBody, to)
+	message.SetHTML(htmlBody)
+
+	// Set timeout for the request
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.client.Send(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send organization invitation email: %w", err)
+	}
+
+	return nil
+}
+
+// SendNotificationEmail delivers an in-app notification, linking to it in the app when it has a link.
+func (s *MailgunService) SendNotificationEmail(ctx context.Context, to string, notification Notification) error {
+	var htmlParagraphs strings.Builder
+	for _, paragraph := range strings.Split(notification.Body, "\n\n") {
+		htmlParagraphs.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>") + "</p>\n        ")
+	}
+
+	var htmlLink, textLink string
+	if notification.Link != "" {
+		link := s.appURL + notification.Link
+		htmlLink = fmt.Sprintf(`<div style="text-align: center; margin: 30px 0;">
+            <a href="%s" style="background-color: #3498db; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">View in AVT</a>
+        </div>`, html.EscapeString(link))
+		textLink = "\n\nView in AVT:\n" + link
+	}
+
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f8f9fa; border-radius: 5px; padding: 30px; margin-bottom: 20px;">
+        <h2 style="color: #2c3e50; margin-top: 0;">%s</h2>
+        %s%s
+        <p style="color: #666; font-size: 14px;">You can change which notifications are emailed to you in your notification settings.</p>
+    </div>
+    <p style="color: #999; font-size: 12px; text-align: center;">This is an automated message, please do not reply.</p>
+</body>
+</html>`, html.EscapeString(notification.Title), htmlParagraphs.String(), htmlLink)
+
+	textBody := fmt.Sprintf(`%s
+
+%s%s
+
+You can change which notifications are emailed to you in your notification settings.
+
+---
+This is an automated message, please do not reply.`, notification.Title, notification.Body, textLink)
+
+	sender := fmt.Sprintf("%s <%s>", s.fromName, s.fromAddress)
+	message := mailgun.NewMessage(s.domain, sender, notification.Title, textBody, to)
+	message.SetHTML(htmlBody)
+
+	// Set timeout for the request
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.client.Send(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send notification email: %w", err)
+	}
+
+	return nil
+}
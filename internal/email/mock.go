@@ -3,6 +3,7 @@ package email
 import (
 	"context"
 	"sync"
+	"time"
 )
 
 // MockService is a mock email service implementation for testing.
@@ -11,12 +12,27 @@ type MockService struct {
 	mu                    sync.Mutex
 	PasswordResetEmails   []MockEmail
 	PasswordChangedEmails []MockEmail
+	MagicLinkEmails       []MockEmail
+	GeofenceAlertEmails   []MockEmail
+	HealthAlertEmails     []MockEmail
+	AccountLockedEmails   []MockEmail
+	LoginAlertEmails      []MockEmail
+	InvitationEmails      []MockEmail
+	NotificationEmails    []MockEmail
 }
 
 // MockEmail represents an email that was sent by the mock service.
 type MockEmail struct {
-	To    string
-	Token string // Only populated for password reset emails
+	To            string
+	Token         string                  // Only populated for password reset, magic link and invitation emails
+	ExpiresAt     time.Time               // Only populated for magic link emails
+	GeofenceAlert *GeofenceAlert          // Only populated for geofence alert emails
+	HealthAlert   *DeviceHealthAlert      // Only populated for device health alert emails
+	IPAddress     string                  // Only populated for account locked and new login location emails
+	LockedUntil   time.Time               // Only populated for account locked emails
+	Invitation    *OrganizationInvitation // Only populated for invitation emails
+	LoginAlert    *LoginAlert             // Only populated for new login location emails
+	Notification  *Notification           // Only populated for notification emails
 }
 
 // NewMockService creates a new mock email service.
@@ -24,6 +40,13 @@ func NewMockService() *MockService {
 	return &MockService{
 		PasswordResetEmails:   make([]MockEmail, 0),
 		PasswordChangedEmails: make([]MockEmail, 0),
+		MagicLinkEmails:       make([]MockEmail, 0),
+		GeofenceAlertEmails:   make([]MockEmail, 0),
+		HealthAlertEmails:     make([]MockEmail, 0),
+		AccountLockedEmails:   make([]MockEmail, 0),
+		LoginAlertEmails:      make([]MockEmail, 0),
+		InvitationEmails:      make([]MockEmail, 0),
+		NotificationEmails:    make([]MockEmail, 0),
 	}
 }
 
@@ -48,12 +71,100 @@ func (s *MockService) SendPasswordChangedEmail(_ context.Context, to string) err
 	return nil
 }
 
+// SendMagicLinkEmail records a magic sign-in link email.
+func (s *MockService) SendMagicLinkEmail(_ context.Context, to, token string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MagicLinkEmails = append(s.MagicLinkEmails, MockEmail{
+		To:        to,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+	return nil
+}
+
+// SendGeofenceAlertEmail records a geofence alert email.
+func (s *MockService) SendGeofenceAlertEmail(_ context.Context, to string, alert GeofenceAlert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.GeofenceAlertEmails = append(s.GeofenceAlertEmails, MockEmail{
+		To:            to,
+		GeofenceAlert: &alert,
+	})
+	return nil
+}
+
+// SendDeviceHealthAlertEmail records a device health alert email.
+func (s *MockService) SendDeviceHealthAlertEmail(_ context.Context, to string, alert DeviceHealthAlert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.HealthAlertEmails = append(s.HealthAlertEmails, MockEmail{
+		To:          to,
+		HealthAlert: &alert,
+	})
+	return nil
+}
+
+// SendAccountLockedEmail records an account locked notification email.
+func (s *MockService) SendAccountLockedEmail(_ context.Context, to, ipAddress string, lockedUntil time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.AccountLockedEmails = append(s.AccountLockedEmails, MockEmail{
+		To:          to,
+		IPAddress:   ipAddress,
+		LockedUntil: lockedUntil,
+	})
+	return nil
+}
+
+// SendNewLoginLocationEmail records a new login location email.
+func (s *MockService) SendNewLoginLocationEmail(_ context.Context, to string, alert LoginAlert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.LoginAlertEmails = append(s.LoginAlertEmails, MockEmail{
+		To:         to,
+		IPAddress:  alert.IPAddress,
+		LoginAlert: &alert,
+	})
+	return nil
+}
+
+// SendOrganizationInvitationEmail records an organization invitation email.
+func (s *MockService) SendOrganizationInvitationEmail(_ context.Context, to, token string, invitation OrganizationInvitation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.InvitationEmails = append(s.InvitationEmails, MockEmail{
+		To:         to,
+		Token:      token,
+		Invitation: &invitation,
+	})
+	return nil
+}
+
+// SendNotificationEmail records a notification email.
+func (s *MockService) SendNotificationEmail(_ context.Context, to string, notification Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.NotificationEmails = append(s.NotificationEmails, MockEmail{
+		To:           to,
+		Notification: &notification,
+	})
+	return nil
+}
+
 // Reset clears all stored emails. Useful for test cleanup.
 func (s *MockService) Reset() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.PasswordResetEmails = make([]MockEmail, 0)
 	s.PasswordChangedEmails = make([]MockEmail, 0)
+	s.MagicLinkEmails = make([]MockEmail, 0)
+	s.GeofenceAlertEmails = make([]MockEmail, 0)
+	s.HealthAlertEmails = make([]MockEmail, 0)
+	s.AccountLockedEmails = make([]MockEmail, 0)
+	s.LoginAlertEmails = make([]MockEmail, 0)
+	s.InvitationEmails = make([]MockEmail, 0)
+	s.NotificationEmails = make([]MockEmail, 0)
 }
 
 // GetPasswordResetEmails returns a copy of all password reset emails sent.
@@ -73,3 +184,66 @@ func (s *MockService) GetPasswordChangedEmails() []MockEmail {
 	copy(emails, s.PasswordChangedEmails)
 	return emails
 }
+
+// GetMagicLinkEmails returns a copy of all magic link emails sent.
+func (s *MockService) GetMagicLinkEmails() []MockEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	emails := make([]MockEmail, len(s.MagicLinkEmails))
+	copy(emails, s.MagicLinkEmails)
+	return emails
+}
+
+// GetGeofenceAlertEmails returns a copy of all geofence alert emails sent.
+func (s *MockService) GetGeofenceAlertEmails() []MockEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	emails := make([]MockEmail, len(s.GeofenceAlertEmails))
+	copy(emails, s.GeofenceAlertEmails)
+	return emails
+}
+
+// GetHealthAlertEmails returns a copy of all device health alert emails sent.
+func (s *MockService) GetHealthAlertEmails() []MockEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	emails := make([]MockEmail, len(s.HealthAlertEmails))
+	copy(emails, s.HealthAlertEmails)
+	return emails
+}
+
+// GetAccountLockedEmails returns a copy of all account locked emails sent.
+func (s *MockService) GetAccountLockedEmails() []MockEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	emails := make([]MockEmail, len(s.AccountLockedEmails))
+	copy(emails, s.AccountLockedEmails)
+	return emails
+}
+
+// GetLoginAlertEmails returns a copy of all new login location emails sent.
+func (s *MockService) GetLoginAlertEmails() []MockEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	emails := make([]MockEmail, len(s.LoginAlertEmails))
+	copy(emails, s.LoginAlertEmails)
+	return emails
+}
+
+// GetInvitationEmails returns a copy of all organization invitation emails sent.
+func (s *MockService) GetInvitationEmails() []MockEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	emails := make([]MockEmail, len(s.InvitationEmails))
+	copy(emails, s.InvitationEmails)
+	return emails
+}
+
+// GetNotificationEmails returns a copy of all notification emails sent.
+func (s *MockService) GetNotificationEmails() []MockEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	emails := make([]MockEmail, len(s.NotificationEmails))
+	copy(emails, s.NotificationEmails)
+	return emails
+}
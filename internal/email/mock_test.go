@@ -3,6 +3,7 @@ package email
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 func TestMockService_SendPasswordResetEmail(t *testing.T) {
@@ -77,6 +78,84 @@ func TestMockService_SendPasswordChangedEmail(t *testing.T) {
 	}
 }
 
+func TestMockService_SendOrganizationInvitationEmail(t *testing.T) {
+	service := NewMockService()
+	invitation := OrganizationInvitation{
+		OrganizationName: "Team Racing",
+		InviterEmail:     "owner@example.com",
+		Role:             "member",
+	}
+
+	if err := service.SendOrganizationInvitationEmail(context.Background(), "invitee@example.com", "invite-token", invitation); err != nil {
+		t.Fatalf("SendOrganizationInvitationEmail() error = %v", err)
+	}
+
+	emails := service.GetInvitationEmails()
+	if len(emails) != 1 {
+		t.Fatalf("GetInvitationEmails() count = %d, want 1", len(emails))
+	}
+	if emails[0].To != "invitee@example.com" || emails[0].Token != "invite-token" {
+		t.Errorf("Email = %+v, want recipient and token recorded", emails[0])
+	}
+	if emails[0].Invitation == nil || emails[0].Invitation.OrganizationName != "Team Racing" {
+		t.Errorf("Email.Invitation = %+v, want organization recorded", emails[0].Invitation)
+	}
+}
+
+func TestMockService_SendDeviceHealthAlertEmail(t *testing.T) {
+	service := NewMockService()
+	alert := DeviceHealthAlert{EventType: "low_battery", DeviceID: "RB-001", Battery: 15}
+
+	if err := service.SendDeviceHealthAlertEmail(context.Background(), "owner@example.com", alert); err != nil {
+		t.Fatalf("SendDeviceHealthAlertEmail() error = %v", err)
+	}
+
+	emails := service.GetHealthAlertEmails()
+	if len(emails) != 1 {
+		t.Fatalf("GetHealthAlertEmails() count = %d, want 1", len(emails))
+	}
+	if emails[0].HealthAlert == nil || emails[0].HealthAlert.DeviceID != "RB-001" {
+		t.Errorf("Email.HealthAlert = %+v, want alert recorded", emails[0].HealthAlert)
+	}
+	if got, want := alert.Summary(), "RB-001 battery is low (15)"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestMockService_SendNewLoginLocationEmail(t *testing.T) {
+	service := NewMockService()
+	alert := LoginAlert{Location: "Lyon, France", IPAddress: "198.51.100.7", LoggedInAt: time.Now()}
+
+	if err := service.SendNewLoginLocationEmail(context.Background(), "user@example.com", alert); err != nil {
+		t.Fatalf("SendNewLoginLocationEmail() error = %v", err)
+	}
+
+	emails := service.GetLoginAlertEmails()
+	if len(emails) != 1 {
+		t.Fatalf("GetLoginAlertEmails() count = %d, want 1", len(emails))
+	}
+	if emails[0].IPAddress != "198.51.100.7" || emails[0].LoginAlert == nil || emails[0].LoginAlert.Location != "Lyon, France" {
+		t.Errorf("Email = %+v, want login alert recorded", emails[0])
+	}
+}
+
+func TestMockService_SendNotificationEmail(t *testing.T) {
+	service := NewMockService()
+	notification := Notification{Title: "Session summary ready", Body: "12.4 km", Link: "/sessions/abc"}
+
+	if err := service.SendNotificationEmail(context.Background(), "user@example.com", notification); err != nil {
+		t.Fatalf("SendNotificationEmail() error = %v", err)
+	}
+
+	emails := service.GetNotificationEmails()
+	if len(emails) != 1 {
+		t.Fatalf("GetNotificationEmails() count = %d, want 1", len(emails))
+	}
+	if emails[0].Notification == nil || *emails[0].Notification != notification {
+		t.Errorf("Email = %+v, want notification recorded", emails[0])
+	}
+}
+
 func TestMockService_Reset(t *testing.T) {
 	service := NewMockService()
 	ctx := context.Background()
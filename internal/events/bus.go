@@ -0,0 +1,196 @@
+// Package events delivers domain events to in-process subscribers through an outbox table.
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// DefaultPollInterval is how often the outbox is checked for due events
+	DefaultPollInterval = 10 * time.Second
+
+	// DefaultRetention is how long delivered events are kept in the outbox
+	DefaultRetention = 7 * 24 * time.Hour
+
+	// dispatchBatchSize caps the number of events claimed at once
+	dispatchBatchSize = 100
+
+	// deliveryLease hides claimed events from other dispatchers while they are delivered
+	deliveryLease = time.Minute
+
+	// maxAttempts is the number of deliveries after which a failing event is abandoned
+	maxAttempts = 10
+
+	// retryDelay is the wait after the first failed delivery; it doubles with every attempt up to maxRetryDelay
+	retryDelay    = 5 * time.Second
+	maxRetryDelay = time.Hour
+
+	// purgeInterval is how often delivered events past their retention are deleted
+	purgeInterval = time.Hour
+)
+
+// Handler handles an event delivered to a subscriber
+// Returning an error retries the delivery later, to every subscriber of the event.
+type Handler func(ctx context.Context, event *models.Event) error
+
+// subscription is a handler registered for one event type
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+// Bus publishes domain events to the outbox and dispatches them to subscribers
+// Repositories write events to the outbox in the transaction of the change they describe (see
+// repository.WithEvents), so an event is stored if and only if its change commits. Once stored,
+// events are delivered at least once: an event is redelivered after any of its subscribers fails,
+// or after a restart interrupts its delivery, so handlers must tolerate duplicates. Events with no
+// subscriber are marked delivered.
+type Bus struct {
+	repo          repository.EventOutboxRepository
+	subscriptions map[models.EventType][]subscription
+	pollInterval  time.Duration
+	retention     time.Duration
+	wake          chan struct{}
+}
+
+// NewBus creates a new event bus backed by the outbox
+func NewBus(repo repository.EventOutboxRepository, pollInterval time.Duration) *Bus {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	return &Bus{
+		repo:          repo,
+		subscriptions: make(map[models.EventType][]subscription),
+		pollInterval:  pollInterval,
+		retention:     DefaultRetention,
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// WithRetention sets how long delivered events are kept; zero keeps them forever
+func (b *Bus) WithRetention(retention time.Duration) *Bus {
+	b.retention = retention
+	return b
+}
+
+// Subscribe registers handler for events of the given type, identified by name in logs
+// Subscribers must be registered before Run is called.
+func (b *Bus) Subscribe(eventType models.EventType, name string, handler Handler) *Bus {
+	b.subscriptions[eventType] = append(b.subscriptions[eventType], subscription{name: name, handler: handler})
+	return b
+}
+
+// Notify wakes the dispatcher to deliver events a change just stored in the outbox
+func (b *Bus) Notify() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+		// A dispatch is already pending and picks these events up
+	}
+}
+
+// Run dispatches published and due events until the context is cancelled
+func (b *Bus) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+	purge := time.NewTicker(purgeInterval)
+	defer purge.Stop()
+
+	// Deliver events published before the service stopped
+	b.dispatch(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.wake:
+			b.dispatch(ctx)
+		case <-ticker.C:
+			b.dispatch(ctx)
+		case <-purge.C:
+			b.purge(ctx)
+		}
+	}
+}
+
+// dispatch delivers due events until none are left
+func (b *Bus) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := b.repo.ClaimDue(ctx, dispatchBatchSize, deliveryLease)
+		if err != nil {
+			slog.Error("Error claiming due events", "error", err)
+			return
+		}
+
+		for _, event := range events {
+			b.deliver(ctx, event)
+		}
+
+		if len(events) < dispatchBatchSize {
+			return
+		}
+	}
+}
+
+// deliver hands an event to its subscribers and records the outcome
+func (b *Bus) deliver(ctx context.Context, event *models.Event) {
+	var failures []string
+	for _, sub := range b.subscriptions[event.Type] {
+		if err := sub.handler(ctx, event); err != nil {
+			slog.Error("Error handling event",
+				"subscriber", sub.name, "event_id", event.ID, "type", event.Type, "attempt", event.Attempts, "error", err)
+			failures = append(failures, fmt.Sprintf("%s: %v", sub.name, err))
+		}
+	}
+
+	if len(failures) == 0 {
+		if err := b.repo.MarkDelivered(ctx, event.ID); err != nil {
+			slog.Error("Error marking event delivered", "event_id", event.ID, "error", err)
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if event.Attempts < maxAttempts {
+		next := time.Now().Add(backoff(event.Attempts))
+		retryAt = &next
+	} else {
+		slog.Warn("Abandoning event after repeated delivery failures", "event_id", event.ID, "type", event.Type, "attempts", event.Attempts)
+	}
+	if err := b.repo.MarkFailed(ctx, event.ID, strings.Join(failures, "; "), retryAt); err != nil {
+		slog.Error("Error recording failed event delivery", "event_id", event.ID, "error", err)
+	}
+}
+
+// backoff returns the wait before retrying an event that failed its attempts-th delivery
+func backoff(attempts int) time.Duration {
+	delay := retryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// purge deletes delivered events past their retention
+func (b *Bus) purge(ctx context.Context) {
+	if b.retention <= 0 {
+		return
+	}
+
+	deleted, err := b.repo.DeleteDelivered(ctx, time.Now().Add(-b.retention))
+	if err != nil {
+		slog.Error("Error purging delivered events", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("Event outbox: purged delivered events", "count", deleted)
+	}
+}
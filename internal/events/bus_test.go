@@ -0,0 +1,199 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outbox is an in-memory outbox recording how deliveries ended
+type outbox struct {
+	mu        sync.Mutex
+	pending   []*models.Event
+	delivered []int64
+	failed    map[int64]*time.Time // Retry time of failed events; nil when abandoned
+	errors    map[int64]string
+}
+
+func newOutbox() (*outbox, *repository.MockEventOutboxRepository) {
+	o := &outbox{failed: make(map[int64]*time.Time), errors: make(map[int64]string)}
+	repo := repository.NewMockEventOutboxRepository()
+	repo.AppendFunc = func(_ context.Context, _ *sql.Tx, events ...*models.Event) error {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		for _, event := range events {
+			event.ID = int64(len(o.pending) + len(o.delivered) + 1)
+			o.pending = append(o.pending, event)
+		}
+		return nil
+	}
+	repo.ClaimDueFunc = func(_ context.Context, limit int, _ time.Duration) ([]*models.Event, error) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		n := min(limit, len(o.pending))
+		claimed := o.pending[:n]
+		o.pending = o.pending[n:]
+		for _, event := range claimed {
+			event.Attempts++
+		}
+		return claimed, nil
+	}
+	repo.MarkDeliveredFunc = func(_ context.Context, id int64) error {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.delivered = append(o.delivered, id)
+		return nil
+	}
+	repo.MarkFailedFunc = func(_ context.Context, id int64, errMsg string, retryAt *time.Time) error {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.failed[id] = retryAt
+		o.errors[id] = errMsg
+		return nil
+	}
+	return o, repo
+}
+
+func newTestEvent(t *testing.T, eventType models.EventType, payload interface{}) *models.Event {
+	t.Helper()
+	event, err := models.NewEvent(eventType, payload)
+	require.NoError(t, err)
+	return event
+}
+
+func TestBus_NotifyDeliversToSubscribers(t *testing.T) {
+	o, repo := newOutbox()
+
+	received := make(chan *models.Event, 2)
+	var other []*models.Event
+	bus := NewBus(repo, time.Hour).
+		Subscribe(models.EventSessionEnded, "first", func(_ context.Context, event *models.Event) error {
+			received <- event
+			return nil
+		}).
+		Subscribe(models.EventDeviceClaimed, "other", func(_ context.Context, event *models.Event) error {
+			other = append(other, event)
+			return nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bus.Run(ctx)
+
+	require.NoError(t, repo.Append(ctx, nil, newTestEvent(t, models.EventSessionEnded, models.SessionEnded{DeviceID: "AVT-001"})))
+	bus.Notify()
+
+	select {
+	case event := <-received:
+		var ended models.SessionEnded
+		require.NoError(t, event.Decode(&ended))
+		assert.Equal(t, "AVT-001", ended.DeviceID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+
+	assert.Eventually(t, func() bool {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		return len(o.delivered) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Empty(t, other, "subscribers only receive their event type")
+}
+
+func TestBus_Deliver(t *testing.T) {
+	failing := errors.New("webhook returned status 502")
+
+	tests := []struct {
+		name          string
+		attempts      int  // Attempts after the event is claimed
+		fail          bool // Whether the second subscriber fails
+		expectRetry   bool
+		expectAbandon bool
+	}{
+		{name: "delivered", attempts: 1},
+		{name: "retried", attempts: 1, fail: true, expectRetry: true},
+		{name: "abandoned", attempts: maxAttempts, fail: true, expectAbandon: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, repo := newOutbox()
+			var calls int
+			bus := NewBus(repo, 0).
+				Subscribe(models.EventDeviceClaimed, "first", func(_ context.Context, _ *models.Event) error {
+					calls++
+					return nil
+				}).
+				Subscribe(models.EventDeviceClaimed, "second", func(_ context.Context, _ *models.Event) error {
+					calls++
+					if tt.fail {
+						return failing
+					}
+					return nil
+				})
+
+			event := newTestEvent(t, models.EventDeviceClaimed, models.DeviceClaimed{DeviceID: "AVT-001"})
+			event.ID, event.Attempts = 7, tt.attempts
+			bus.deliver(context.Background(), event)
+
+			assert.Equal(t, 2, calls, "every subscriber receives the event")
+			if !tt.fail {
+				assert.Equal(t, []int64{7}, o.delivered)
+				return
+			}
+			assert.Empty(t, o.delivered)
+			require.Contains(t, o.failed, int64(7))
+			assert.Equal(t, "second: webhook returned status 502", o.errors[7])
+			if tt.expectAbandon {
+				assert.Nil(t, o.failed[7])
+			}
+			if tt.expectRetry {
+				require.NotNil(t, o.failed[7])
+				assert.WithinDuration(t, time.Now().Add(retryDelay), *o.failed[7], time.Second)
+			}
+		})
+	}
+}
+
+func TestBus_DeliverWithoutSubscribers(t *testing.T) {
+	o, repo := newOutbox()
+	bus := NewBus(repo, 0)
+
+	event := newTestEvent(t, models.EventUserLoggedIn, models.UserLoggedIn{})
+	event.ID = 3
+	bus.deliver(context.Background(), event)
+
+	assert.Equal(t, []int64{3}, o.delivered)
+	assert.Equal(t, DefaultPollInterval, bus.pollInterval)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, retryDelay, backoff(1))
+	assert.Equal(t, 2*retryDelay, backoff(2))
+	assert.Equal(t, 8*retryDelay, backoff(4))
+	assert.Equal(t, maxRetryDelay, backoff(maxAttempts+5))
+}
+
+func TestBus_Purge(t *testing.T) {
+	_, repo := newOutbox()
+	var before time.Time
+	repo.DeleteDeliveredFunc = func(_ context.Context, t time.Time) (int64, error) {
+		before = t
+		return 4, nil
+	}
+
+	NewBus(repo, 0).WithRetention(time.Hour).purge(context.Background())
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), before, time.Second)
+
+	before = time.Time{}
+	NewBus(repo, 0).WithRetention(0).purge(context.Background())
+	assert.True(t, before.IsZero(), "zero retention keeps delivered events")
+}
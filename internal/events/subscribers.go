@@ -0,0 +1,83 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/notify"
+	"github.com/sebasr/avt-service/internal/webhook"
+)
+
+// Notifier stores a notification in its user's inbox and delivers it over the channels they chose
+type Notifier interface {
+	Notify(ctx context.Context, notification *models.Notification) error
+}
+
+// SessionQueue schedules background processing of a session, such as its summary
+type SessionQueue interface {
+	Enqueue(sessionID uuid.UUID)
+}
+
+// StreamPublisher sends messages to a topic of a message broker, such as Kafka or NATS
+type StreamPublisher interface {
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+}
+
+// Webhook returns a handler that POSTs every event it receives to url
+// The body is the event as {"id", "type", "createdAt", "data"}; receivers can use the ID to skip
+// redeliveries. Non-2xx responses fail the delivery, so it is retried.
+func Webhook(client *http.Client, url string) Handler {
+	return func(ctx context.Context, event *models.Event) error {
+		return webhook.Post(ctx, client, url, event)
+	}
+}
+
+// LoginAlerts returns a handler that notifies users of EventUserLoggedIn logins from a new country
+func LoginAlerts(notifier Notifier) Handler {
+	return func(ctx context.Context, event *models.Event) error {
+		var login models.UserLoggedIn
+		if err := event.Decode(&login); err != nil {
+			return err
+		}
+		if !login.NewCountry || login.Location == nil {
+			return nil
+		}
+		return notifier.Notify(ctx, notify.NewLogin(&login))
+	}
+}
+
+// EnqueueEndedSessions returns a handler that schedules the session of every EventSessionEnded on queue
+// Queues never fail: sessions they drop are left to their periodic sweep.
+func EnqueueEndedSessions(queue SessionQueue) Handler {
+	return func(_ context.Context, event *models.Event) error {
+		var ended models.SessionEnded
+		if err := event.Decode(&ended); err != nil {
+			return err
+		}
+		queue.Enqueue(ended.SessionID)
+		return nil
+	}
+}
+
+// ForwardTelemetry returns a handler that publishes every EventTelemetryBatchSaved to topic, keyed
+// by device ID
+// The message is the event as {"id", "type", "createdAt", "data"}, like webhook bodies; consumers
+// can use the ID to skip redeliveries. Batches carry their records only when the telemetry handler
+// includes them.
+func ForwardTelemetry(publisher StreamPublisher, topic string) Handler {
+	return func(ctx context.Context, event *models.Event) error {
+		var batch models.TelemetryBatchSaved
+		if err := event.Decode(&batch); err != nil {
+			return err
+		}
+		message, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode stream message: %w", err)
+		}
+		return publisher.Publish(ctx, topic, batch.DeviceID, message)
+	}
+}
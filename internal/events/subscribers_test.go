@@ -0,0 +1,131 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notifierFunc adapts a function to the Notifier interface
+type notifierFunc func(ctx context.Context, notification *models.Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, notification *models.Notification) error {
+	return f(ctx, notification)
+}
+
+// recordingQueue captures the sessions enqueued on it
+type recordingQueue struct {
+	enqueued []uuid.UUID
+}
+
+func (q *recordingQueue) Enqueue(sessionID uuid.UUID) {
+	q.enqueued = append(q.enqueued, sessionID)
+}
+
+// streamFunc adapts a function to the StreamPublisher interface
+type streamFunc func(ctx context.Context, topic, key string, payload []byte) error
+
+func (f streamFunc) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	return f(ctx, topic, key, payload)
+}
+
+func TestWebhook(t *testing.T) {
+	var received struct {
+		ID   int64                `json:"id"`
+		Type models.EventType     `json:"type"`
+		Data models.DeviceClaimed `json:"data"`
+	}
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	event := newTestEvent(t, models.EventDeviceClaimed, models.DeviceClaimed{DeviceID: "AVT-001", Source: models.DeviceClaimBulk})
+	event.ID = 42
+	handler := Webhook(server.Client(), server.URL)
+
+	require.NoError(t, handler(context.Background(), event))
+	assert.Equal(t, int64(42), received.ID)
+	assert.Equal(t, models.EventDeviceClaimed, received.Type)
+	assert.Equal(t, "AVT-001", received.Data.DeviceID)
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, handler(context.Background(), event), "failed deliveries are retried")
+}
+
+func TestLoginAlerts(t *testing.T) {
+	var sent []*models.Notification
+	handler := LoginAlerts(notifierFunc(func(_ context.Context, notification *models.Notification) error {
+		sent = append(sent, notification)
+		return nil
+	}))
+
+	userID := uuid.New()
+	location := &models.GeoLocation{CountryCode: "DE", Country: "Germany"}
+	for _, login := range []models.UserLoggedIn{
+		{UserID: userID, Location: location},
+		{UserID: userID, NewCountry: true},
+		{UserID: userID, Location: location, NewCountry: true, IPAddress: "203.0.113.7", LoggedInAt: time.Now()},
+	} {
+		require.NoError(t, handler(context.Background(), newTestEvent(t, models.EventUserLoggedIn, login)))
+	}
+
+	require.Len(t, sent, 1, "only located logins from a new country are reported")
+	assert.Equal(t, userID, sent[0].UserID)
+	assert.Equal(t, models.NotificationNewLogin, sent[0].Type)
+	assert.Contains(t, sent[0].Body, "203.0.113.7")
+}
+
+func TestEnqueueEndedSessions(t *testing.T) {
+	queue := &recordingQueue{}
+	sessionID := uuid.New()
+
+	err := EnqueueEndedSessions(queue)(context.Background(), newTestEvent(t, models.EventSessionEnded, models.SessionEnded{SessionID: sessionID}))
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{sessionID}, queue.enqueued)
+
+	malformed := &models.Event{Type: models.EventSessionEnded, Payload: json.RawMessage(`"not a session"`)}
+	assert.Error(t, EnqueueEndedSessions(queue)(context.Background(), malformed))
+}
+
+func TestForwardTelemetry(t *testing.T) {
+	var topic, key string
+	var message struct {
+		ID   int64                      `json:"id"`
+		Data models.TelemetryBatchSaved `json:"data"`
+	}
+	var publishErr error
+	handler := ForwardTelemetry(streamFunc(func(_ context.Context, gotTopic, gotKey string, payload []byte) error {
+		topic, key = gotTopic, gotKey
+		assert.NoError(t, json.Unmarshal(payload, &message))
+		return publishErr
+	}), "avt.telemetry")
+
+	event := newTestEvent(t, models.EventTelemetryBatchSaved, models.TelemetryBatchSaved{
+		DeviceID:  "AVT-001",
+		Records:   1,
+		Telemetry: []*models.TelemetryData{{DeviceID: "AVT-001", Battery: 87.5}},
+	})
+	event.ID = 7
+
+	require.NoError(t, handler(context.Background(), event))
+	assert.Equal(t, "avt.telemetry", topic)
+	assert.Equal(t, "AVT-001", key, "messages are keyed by device to keep each device in order")
+	assert.Equal(t, int64(7), message.ID)
+	require.Len(t, message.Data.Telemetry, 1)
+	assert.Equal(t, 87.5, message.Data.Telemetry[0].Battery)
+
+	publishErr = errors.New("broker unavailable")
+	assert.Error(t, handler(context.Background(), event), "failed publishes are retried")
+}
@@ -0,0 +1,201 @@
+// Package export renders telemetry streams in interchange formats such as GPX and CSV.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Format identifies an export file format
+type Format string
+
+const (
+	// FormatGPX is a GPX 1.1 track
+	FormatGPX Format = "gpx"
+
+	// FormatCSV is a comma-separated file with one row per telemetry point
+	FormatCSV Format = "csv"
+)
+
+// ParseFormat validates an export format name
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case FormatGPX, FormatCSV:
+		return Format(value), nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (must be gpx or csv)", value)
+	}
+}
+
+// ContentType returns the MIME type for the format
+func (f Format) ContentType() string {
+	if f == FormatGPX {
+		return "application/gpx+xml"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Write streams the iterator's telemetry to w in the given format, annotated with the session's markers
+// Markers must be in time order. Each one is attached to the first exported point at or after its
+// timestamp; markers after the last point are left out.
+func Write(w io.Writer, format Format, session *models.Session, markers []*models.SessionMarker, it repository.TelemetryIterator) error {
+	if format == FormatGPX {
+		return WriteGPX(w, session, markers, it)
+	}
+	return WriteCSV(w, markers, it)
+}
+
+// csvHeader lists the CSV export columns
+var csvHeader = []string{
+	"timestamp", "latitude", "longitude", "wgs_altitude", "msl_altitude",
+	"speed", "heading", "num_satellites", "fix_status", "is_fix_valid",
+	"horizontal_accuracy", "vertical_accuracy",
+	"g_force_x", "g_force_y", "g_force_z",
+	"rotation_x", "rotation_y", "rotation_z",
+	"battery", "markers",
+}
+
+// WriteCSV streams telemetry as CSV, one row per point including invalid fixes
+// The markers column lists the markers attached to each row, separated by semicolons.
+func WriteCSV(w io.Writer, markers []*models.SessionMarker, it repository.TelemetryIterator) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	pending := markerQueue(markers)
+	for it.Next() {
+		t := it.Telemetry()
+		record := []string{
+			t.Timestamp.UTC().Format(time.RFC3339Nano),
+			formatFloat(t.GPS.Latitude, 7),
+			formatFloat(t.GPS.Longitude, 7),
+			formatFloat(t.GPS.WgsAltitude, 3),
+			formatFloat(t.GPS.MslAltitude, 3),
+			formatFloat(t.GPS.Speed, 3),
+			formatFloat(t.GPS.Heading, 2),
+			strconv.Itoa(t.GPS.NumSatellites),
+			strconv.Itoa(t.GPS.FixStatus),
+			strconv.FormatBool(t.GPS.IsFixValid),
+			formatFloat(t.GPS.HorizontalAccuracy, 3),
+			formatFloat(t.GPS.VerticalAccuracy, 3),
+			formatFloat(t.Motion.GForceX, 3),
+			formatFloat(t.Motion.GForceY, 3),
+			formatFloat(t.Motion.GForceZ, 3),
+			formatFloat(t.Motion.RotationX, 2),
+			formatFloat(t.Motion.RotationY, 2),
+			formatFloat(t.Motion.RotationZ, 2),
+			formatFloat(t.Battery, 1),
+			strings.Join(markerNames(pending.due(t.Timestamp)), "; "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteGPX streams telemetry as a GPX 1.1 track
+// Points without a valid GPS fix are skipped since their coordinates are meaningless. Markers
+// name the track point they are attached to, with the marker type as the point's type.
+func WriteGPX(w io.Writer, session *models.Session, markers []*models.SessionMarker, it repository.TelemetryIterator) error {
+	bw := bufio.NewWriter(w)
+
+	name := "Session " + session.ID.String()
+	if session.Name != nil && *session.Name != "" {
+		name = *session.Name
+	}
+
+	if _, err := fmt.Fprintf(bw, "%s<gpx version=\"1.1\" creator=\"AVT Service\" xmlns=\"http://www.topografix.com/GPX/1/1\">\n"+
+		"  <metadata>\n    <name>%s</name>\n    <time>%s</time>\n  </metadata>\n"+
+		"  <trk>\n    <name>%s</name>\n    <trkseg>\n",
+		xml.Header, escape(name), session.StartedAt.UTC().Format(time.RFC3339), escape(name)); err != nil {
+		return err
+	}
+
+	pending := markerQueue(markers)
+	for it.Next() {
+		t := it.Telemetry()
+		if !t.GPS.IsFixValid {
+			continue
+		}
+
+		var annotation string
+		if due := pending.due(t.Timestamp); len(due) > 0 {
+			annotation = fmt.Sprintf("<name>%s</name><type>%s</type>",
+				escape(strings.Join(markerNames(due), "; ")), escape(string(due[0].Type)))
+		}
+
+		if _, err := fmt.Fprintf(bw, "      <trkpt lat=\"%s\" lon=\"%s\"><ele>%s</ele><time>%s</time>%s</trkpt>\n",
+			formatFloat(t.GPS.Latitude, 7),
+			formatFloat(t.GPS.Longitude, 7),
+			formatFloat(t.GPS.MslAltitude, 2),
+			t.Timestamp.UTC().Format(time.RFC3339Nano),
+			annotation); err != nil {
+			return err
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if _, err := bw.WriteString("    </trkseg>\n  </trk>\n</gpx>\n"); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// markerQueue hands out time-ordered markers as the export reaches their timestamps
+type markerQueue []*models.SessionMarker
+
+// due removes and returns the markers at or before timestamp
+func (q *markerQueue) due(timestamp time.Time) []*models.SessionMarker {
+	n := 0
+	for n < len(*q) && !(*q)[n].MarkedAt.After(timestamp) {
+		n++
+	}
+	due := (*q)[:n]
+	*q = (*q)[n:]
+	return due
+}
+
+// markerNames describes markers by their label, falling back to their type
+func markerNames(markers []*models.SessionMarker) []string {
+	names := make([]string, len(markers))
+	for i, marker := range markers {
+		names[i] = string(marker.Type)
+		if marker.Label != nil && *marker.Label != "" {
+			names[i] = *marker.Label
+		}
+	}
+	return names
+}
+
+// escape returns s with XML special characters escaped
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// formatFloat formats a float with fixed precision
+func formatFloat(value float64, precision int) string {
+	return strconv.FormatFloat(value, 'f', precision, 64)
+}
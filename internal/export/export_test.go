@@ -0,0 +1,107 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleTelemetry() []*models.TelemetryData {
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	return []*models.TelemetryData{
+		{Timestamp: start, GPS: models.GpsData{Latitude: 42.6719035, Longitude: 23.2887238, MslAltitude: 590.1, Speed: 120.5, IsFixValid: true}},
+		{Timestamp: start.Add(time.Second), GPS: models.GpsData{IsFixValid: false}},
+		{Timestamp: start.Add(2 * time.Second), GPS: models.GpsData{Latitude: 42.672, Longitude: 23.289, MslAltitude: 591.0, Speed: 125.0, IsFixValid: true}},
+	}
+}
+
+func sampleMarkers() []*models.SessionMarker {
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	label := "Box <box>"
+	return []*models.SessionMarker{
+		{Type: models.SessionMarkerSector, MarkedAt: start},
+		{Type: models.SessionMarkerPitStop, Label: &label, MarkedAt: start.Add(500 * time.Millisecond)},
+		{Type: models.SessionMarkerIncident, MarkedAt: start.Add(time.Minute)},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("gpx")
+	require.NoError(t, err)
+	assert.Equal(t, FormatGPX, format)
+	assert.Equal(t, "application/gpx+xml", format.ContentType())
+
+	format, err = ParseFormat("csv")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	_, err = ParseFormat("kml")
+	assert.Error(t, err)
+}
+
+func TestWriteCSV(t *testing.T) {
+	it := repository.NewSliceTelemetryIterator(sampleTelemetry())
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, sampleMarkers(), it))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4, "header plus every point, including invalid fixes")
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, "2024-01-10T08:00:00Z", records[1][0])
+	assert.Equal(t, "42.6719035", records[1][1])
+	assert.Equal(t, "120.500", records[1][5])
+	assert.Equal(t, "false", records[2][9])
+
+	// Markers go on the first row at or after their timestamp; later ones are left out
+	markers := len(csvHeader) - 1
+	assert.Equal(t, "sector", records[1][markers])
+	assert.Equal(t, "Box <box>", records[2][markers])
+	assert.Equal(t, "", records[3][markers])
+}
+
+func TestWriteGPX(t *testing.T) {
+	name := "Track <Day> & Practice"
+	session := &models.Session{ID: uuid.New(), Name: &name, StartedAt: time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)}
+	it := repository.NewSliceTelemetryIterator(sampleTelemetry())
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteGPX(&buf, session, sampleMarkers(), it))
+
+	var doc struct {
+		XMLName xml.Name `xml:"gpx"`
+		Version string   `xml:"version,attr"`
+		Track   struct {
+			Name   string `xml:"name"`
+			Points []struct {
+				Lat  float64 `xml:"lat,attr"`
+				Lon  float64 `xml:"lon,attr"`
+				Ele  float64 `xml:"ele"`
+				Time string  `xml:"time"`
+				Name string  `xml:"name"`
+				Type string  `xml:"type"`
+			} `xml:"trkseg>trkpt"`
+		} `xml:"trk"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc), buf.String())
+
+	assert.Equal(t, "1.1", doc.Version)
+	assert.Equal(t, name, doc.Track.Name)
+	require.Len(t, doc.Track.Points, 2, "invalid fixes are skipped")
+	assert.Equal(t, 42.6719035, doc.Track.Points[0].Lat)
+	assert.Equal(t, 591.0, doc.Track.Points[1].Ele)
+	assert.Equal(t, "sector", doc.Track.Points[0].Name)
+	assert.Equal(t, "Box <box>", doc.Track.Points[1].Name, "markers on skipped points move to the next fix")
+	assert.Equal(t, "pit_stop", doc.Track.Points[1].Type)
+	assert.True(t, strings.HasPrefix(buf.String(), "<?xml"))
+}
@@ -0,0 +1,91 @@
+// Package geo provides small geodesic helpers for working with GPS telemetry.
+package geo
+
+import "math"
+
+// EarthRadius is the mean Earth radius in meters
+const EarthRadius = 6371008.8
+
+// Point is a WGS84 coordinate in decimal degrees
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// IsValid checks that the coordinate is within WGS84 bounds
+func (p Point) IsValid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// Distance returns the great-circle (haversine) distance between two points in meters
+func Distance(a, b Point) float64 {
+	lat1 := toRadians(a.Latitude)
+	lat2 := toRadians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := toRadians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Projection maps coordinates to a local planar frame in meters (x east, y north)
+// It uses an equirectangular approximation, accurate to well under a meter over a few kilometers.
+type Projection struct {
+	origin Point
+	cosLat float64
+}
+
+// NewProjection creates a local projection centered on origin
+func NewProjection(origin Point) Projection {
+	return Projection{
+		origin: origin,
+		cosLat: math.Cos(toRadians(origin.Latitude)),
+	}
+}
+
+// Project returns the planar offset of p from the projection origin in meters
+func (pr Projection) Project(p Point) (x, y float64) {
+	x = toRadians(p.Longitude-pr.origin.Longitude) * pr.cosLat * EarthRadius
+	y = toRadians(p.Latitude-pr.origin.Latitude) * EarthRadius
+	return x, y
+}
+
+// Unproject returns the coordinate at the given planar offset from the projection origin
+func (pr Projection) Unproject(x, y float64) Point {
+	return Point{
+		Latitude:  pr.origin.Latitude + toDegrees(y/EarthRadius),
+		Longitude: pr.origin.Longitude + toDegrees(x/(pr.cosLat*EarthRadius)),
+	}
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func toDegrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// PolygonContains reports whether p lies inside the polygon (vertices in order, implicitly closed)
+// It uses ray casting in a local projection, so polygons should span no more than a few hundred kilometers.
+func PolygonContains(polygon []Point, p Point) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+
+	pr := NewProjection(polygon[0])
+	px, py := pr.Project(p)
+
+	inside := false
+	jx, jy := pr.Project(polygon[len(polygon)-1])
+	for _, vertex := range polygon {
+		ix, iy := pr.Project(vertex)
+		if (iy > py) != (jy > py) && px < (jx-ix)*(py-iy)/(jy-iy)+ix {
+			inside = !inside
+		}
+		jx, jy = ix, iy
+	}
+
+	return inside
+}
@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	sofia := Point{Latitude: 42.6977, Longitude: 23.3219}
+	plovdiv := Point{Latitude: 42.1354, Longitude: 24.7453}
+
+	assert.InDelta(t, 132700, Distance(sofia, plovdiv), 500)
+	assert.Equal(t, 0.0, Distance(sofia, sofia))
+
+	// One thousandth of a degree of latitude is about 111 m
+	assert.InDelta(t, 111.2, Distance(sofia, Point{Latitude: 42.6987, Longitude: 23.3219}), 0.1)
+}
+
+func TestProjection(t *testing.T) {
+	origin := Point{Latitude: 42.6977, Longitude: 23.3219}
+	pr := NewProjection(origin)
+
+	x, y := pr.Project(origin)
+	assert.Equal(t, 0.0, x)
+	assert.Equal(t, 0.0, y)
+
+	north := Point{Latitude: 42.6987, Longitude: 23.3219}
+	x, y = pr.Project(north)
+	assert.InDelta(t, 0, x, 1e-9)
+	assert.InDelta(t, Distance(origin, north), y, 0.01)
+
+	east := Point{Latitude: 42.6977, Longitude: 23.3229}
+	x, y = pr.Project(east)
+	assert.InDelta(t, Distance(origin, east), x, 0.01)
+	assert.InDelta(t, 0, y, 1e-9)
+
+	// Unproject reverses Project
+	back := pr.Unproject(pr.Project(Point{Latitude: 42.7011, Longitude: 23.3182}))
+	assert.InDelta(t, 42.7011, back.Latitude, 1e-9)
+	assert.InDelta(t, 23.3182, back.Longitude, 1e-9)
+}
+
+func TestPoint_IsValid(t *testing.T) {
+	assert.True(t, Point{Latitude: 42.7, Longitude: 23.3}.IsValid())
+	assert.False(t, Point{Latitude: 91, Longitude: 0}.IsValid())
+	assert.False(t, Point{Latitude: 0, Longitude: -181}.IsValid())
+}
+
+func TestPolygonContains(t *testing.T) {
+	// Roughly 1 km square around central Sofia
+	square := []Point{
+		{Latitude: 42.690, Longitude: 23.315},
+		{Latitude: 42.690, Longitude: 23.327},
+		{Latitude: 42.699, Longitude: 23.327},
+		{Latitude: 42.699, Longitude: 23.315},
+	}
+
+	assert.True(t, PolygonContains(square, Point{Latitude: 42.695, Longitude: 23.320}))
+	assert.False(t, PolygonContains(square, Point{Latitude: 42.705, Longitude: 23.320}))
+	assert.False(t, PolygonContains(square, Point{Latitude: 42.695, Longitude: 23.330}))
+
+	// Concave L-shape: the notch is outside
+	lShape := []Point{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 0.02},
+		{Latitude: 0.01, Longitude: 0.02},
+		{Latitude: 0.01, Longitude: 0.01},
+		{Latitude: 0.02, Longitude: 0.01},
+		{Latitude: 0.02, Longitude: 0},
+	}
+	assert.True(t, PolygonContains(lShape, Point{Latitude: 0.005, Longitude: 0.015}))
+	assert.True(t, PolygonContains(lShape, Point{Latitude: 0.015, Longitude: 0.005}))
+	assert.False(t, PolygonContains(lShape, Point{Latitude: 0.015, Longitude: 0.015}))
+
+	assert.False(t, PolygonContains(square[:2], Point{Latitude: 42.695, Longitude: 23.320}), "degenerate polygons contain nothing")
+}
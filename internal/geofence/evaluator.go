@@ -0,0 +1,240 @@
+// Package geofence evaluates ingested telemetry against user geofences and emits enter/exit events.
+package geofence
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/sebasr/avt-service/internal/webhook"
+)
+
+// queueSize bounds the number of telemetry batches waiting for evaluation
+const queueSize = 256
+
+// batch is the telemetry of a single device and owner awaiting evaluation
+type batch struct {
+	userID   uuid.UUID
+	deviceID string
+	records  []*models.TelemetryData
+}
+
+// WebhookPayload is the JSON body POSTed to a geofence's webhook URL
+type WebhookPayload struct {
+	GeofenceName string                `json:"geofenceName"`
+	Event        *models.GeofenceEvent `json:"event"`
+}
+
+// Evaluator checks device positions against their owner's active geofences
+// Telemetry is enqueued from the ingest path and evaluated in the background, so
+// uploads never wait on geofence lookups or notification delivery.
+type Evaluator struct {
+	repo         repository.GeofenceRepository
+	userRepo     repository.UserRepository
+	emailService email.Service // Optional: nil disables email notifications
+	httpClient   *http.Client
+	queue        chan batch
+}
+
+// NewEvaluator creates a new geofence evaluator
+func NewEvaluator(repo repository.GeofenceRepository, userRepo repository.UserRepository) *Evaluator {
+	return &Evaluator{
+		repo:       repo,
+		userRepo:   userRepo,
+		httpClient: webhook.NewClient(),
+		queue:      make(chan batch, queueSize),
+	}
+}
+
+// WithEmailService sets the email service used for geofence alert emails
+func (e *Evaluator) WithEmailService(emailService email.Service) *Evaluator {
+	e.emailService = emailService
+	return e
+}
+
+// WithHTTPClient sets the HTTP client used for webhook delivery
+func (e *Evaluator) WithHTTPClient(client *http.Client) *Evaluator {
+	e.httpClient = client
+	return e
+}
+
+// Enqueue schedules saved telemetry for evaluation without blocking
+// Records without an owner or device are skipped. If the queue is full the batch is dropped.
+func (e *Evaluator) Enqueue(records []*models.TelemetryData) {
+	type key struct {
+		userID   uuid.UUID
+		deviceID string
+	}
+
+	groups := make(map[key]*batch)
+	var order []key
+	for _, record := range records {
+		if record.UserID == nil || record.DeviceID == "" || !record.GPS.IsFixValid {
+			continue
+		}
+		k := key{userID: *record.UserID, deviceID: record.DeviceID}
+		group, ok := groups[k]
+		if !ok {
+			group = &batch{userID: k.userID, deviceID: k.deviceID}
+			groups[k] = group
+			order = append(order, k)
+		}
+		group.records = append(group.records, record)
+	}
+
+	for _, k := range order {
+		select {
+		case e.queue <- *groups[k]:
+		default:
+			slog.Warn("Geofence evaluation queue full, dropping records", "device_id", k.deviceID, "count", len(groups[k].records))
+		}
+	}
+}
+
+// Run evaluates queued telemetry until the context is cancelled
+func (e *Evaluator) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case b := <-e.queue:
+			if _, err := e.Evaluate(ctx, b.userID, b.deviceID, b.records); err != nil {
+				slog.Error("Error evaluating geofences", "device_id", b.deviceID, "error", err)
+			}
+		}
+	}
+}
+
+// Evaluate replays a device's telemetry against its owner's active geofences, stores the
+// resulting transitions and sends the configured notifications
+// A device with no stored state counts as outside, so a first position inside a fence emits an enter event.
+func (e *Evaluator) Evaluate(ctx context.Context, userID uuid.UUID, deviceID string, records []*models.TelemetryData) ([]*models.GeofenceEvent, error) {
+	fences, err := e.repo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list geofences: %w", err)
+	}
+	if len(fences) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(fences))
+	for i, fence := range fences {
+		ids[i] = fence.ID
+	}
+
+	inside, err := e.repo.GetStates(ctx, deviceID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load geofence states: %w", err)
+	}
+
+	sorted := make([]*models.TelemetryData, 0, len(records))
+	for _, record := range records {
+		if record.GPS.IsFixValid {
+			sorted = append(sorted, record)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var events []*models.GeofenceEvent
+	for _, record := range sorted {
+		p := geo.Point{Latitude: record.GPS.Latitude, Longitude: record.GPS.Longitude}
+		for _, fence := range fences {
+			in := fence.Contains(p)
+			if in == inside[fence.ID] {
+				continue
+			}
+			inside[fence.ID] = in
+
+			eventType := models.GeofenceExit
+			if in {
+				eventType = models.GeofenceEnter
+			}
+			events = append(events, &models.GeofenceEvent{
+				GeofenceID: fence.ID,
+				UserID:     userID,
+				DeviceID:   deviceID,
+				Type:       eventType,
+				RecordedAt: record.Timestamp,
+				Latitude:   p.Latitude,
+				Longitude:  p.Longitude,
+			})
+		}
+	}
+
+	lastSeen := sorted[len(sorted)-1].Timestamp
+	states := make([]models.GeofenceState, len(fences))
+	for i, fence := range fences {
+		states[i] = models.GeofenceState{
+			GeofenceID: fence.ID,
+			DeviceID:   deviceID,
+			Inside:     inside[fence.ID],
+			RecordedAt: lastSeen,
+		}
+	}
+
+	if err := e.repo.RecordTransitions(ctx, states, events); err != nil {
+		return nil, fmt.Errorf("failed to record geofence transitions: %w", err)
+	}
+
+	if len(events) > 0 {
+		e.notify(ctx, userID, fences, events)
+	}
+
+	return events, nil
+}
+
+// notify delivers email and webhook notifications for the given events
+// Delivery failures are logged; the events themselves are already stored.
+func (e *Evaluator) notify(ctx context.Context, userID uuid.UUID, fences []*models.Geofence, events []*models.GeofenceEvent) {
+	byID := make(map[uuid.UUID]*models.Geofence, len(fences))
+	for _, fence := range fences {
+		byID[fence.ID] = fence
+	}
+
+	var recipient string
+	for _, event := range events {
+		fence := byID[event.GeofenceID]
+
+		if fence.NotifyEmail && e.emailService != nil {
+			if recipient == "" {
+				user, err := e.userRepo.GetByID(ctx, userID)
+				if err != nil {
+					slog.Error("Error loading user for geofence alert", "user_id", userID, "error", err)
+				} else {
+					recipient = user.Email
+				}
+			}
+			if recipient != "" {
+				alert := email.GeofenceAlert{
+					GeofenceName: fence.Name,
+					EventType:    string(event.Type),
+					DeviceID:     event.DeviceID,
+					RecordedAt:   event.RecordedAt,
+					Latitude:     event.Latitude,
+					Longitude:    event.Longitude,
+				}
+				if err := e.emailService.SendGeofenceAlertEmail(ctx, recipient, alert); err != nil {
+					slog.Error("Error sending geofence alert email", "geofence_id", fence.ID, "error", err)
+				}
+			}
+		}
+
+		if fence.WebhookURL != nil {
+			if err := webhook.Post(ctx, e.httpClient, *fence.WebhookURL, WebhookPayload{GeofenceName: fence.Name, Event: event}); err != nil {
+				slog.Error("Error delivering geofence webhook", "geofence_id", fence.ID, "error", err)
+			}
+		}
+	}
+}
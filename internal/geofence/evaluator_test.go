@@ -0,0 +1,212 @@
+package geofence
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	fenceCenter = geo.Point{Latitude: 42.6977, Longitude: 23.3219}
+	start       = time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+)
+
+func circleFence(userID uuid.UUID) *models.Geofence {
+	radius := 100.0
+	return &models.Geofence{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Name:         "Paddock",
+		Kind:         models.GeofenceCircle,
+		Center:       &fenceCenter,
+		RadiusMeters: &radius,
+		IsActive:     true,
+	}
+}
+
+// telemetryAt returns a record dy degrees of latitude north of the fence center
+func telemetryAt(userID uuid.UUID, elapsed time.Duration, dy float64) *models.TelemetryData {
+	return &models.TelemetryData{
+		UserID:    &userID,
+		DeviceID:  "device-1",
+		Timestamp: start.Add(elapsed),
+		GPS: models.GpsData{
+			Latitude:   fenceCenter.Latitude + dy,
+			Longitude:  fenceCenter.Longitude,
+			IsFixValid: true,
+		},
+	}
+}
+
+func TestEvaluator_EmitsEnterAndExitEvents(t *testing.T) {
+	userID := uuid.New()
+	fence := circleFence(userID)
+
+	var (
+		recordedStates []models.GeofenceState
+		recordedEvents []*models.GeofenceEvent
+	)
+	repo := repository.NewMockGeofenceRepository()
+	repo.ListActiveByUserIDFunc = func(_ context.Context, _ uuid.UUID) ([]*models.Geofence, error) {
+		return []*models.Geofence{fence}, nil
+	}
+	repo.RecordTransitionsFunc = func(_ context.Context, states []models.GeofenceState, events []*models.GeofenceEvent) error {
+		recordedStates = states
+		recordedEvents = events
+		return nil
+	}
+
+	evaluator := NewEvaluator(repo, repository.NewMockUserRepository())
+
+	// Out of order on purpose: the evaluator replays records chronologically
+	records := []*models.TelemetryData{
+		telemetryAt(userID, 20*time.Second, 0.01), // ~1.1 km north: outside
+		telemetryAt(userID, 0, 0.01),
+		telemetryAt(userID, 10*time.Second, 0),
+		telemetryAt(userID, 15*time.Second, 0.0002),
+	}
+
+	events, err := evaluator.Evaluate(context.Background(), userID, "device-1", records)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, models.GeofenceEnter, events[0].Type)
+	assert.Equal(t, start.Add(10*time.Second), events[0].RecordedAt)
+	assert.Equal(t, models.GeofenceExit, events[1].Type)
+	assert.Equal(t, start.Add(20*time.Second), events[1].RecordedAt)
+	assert.Equal(t, fence.ID, events[0].GeofenceID)
+	assert.Equal(t, "device-1", events[0].DeviceID)
+
+	assert.Equal(t, recordedEvents, events)
+	require.Len(t, recordedStates, 1)
+	assert.False(t, recordedStates[0].Inside)
+	assert.Equal(t, start.Add(20*time.Second), recordedStates[0].RecordedAt)
+}
+
+func TestEvaluator_UsesStoredState(t *testing.T) {
+	userID := uuid.New()
+	fence := circleFence(userID)
+
+	repo := repository.NewMockGeofenceRepository()
+	repo.ListActiveByUserIDFunc = func(_ context.Context, _ uuid.UUID) ([]*models.Geofence, error) {
+		return []*models.Geofence{fence}, nil
+	}
+	repo.GetStatesFunc = func(_ context.Context, _ string, _ []uuid.UUID) (map[uuid.UUID]bool, error) {
+		return map[uuid.UUID]bool{fence.ID: true}, nil
+	}
+
+	evaluator := NewEvaluator(repo, repository.NewMockUserRepository())
+
+	// Already inside: no new enter event
+	events, err := evaluator.Evaluate(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 0, 0),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestEvaluator_SendsNotifications(t *testing.T) {
+	userID := uuid.New()
+
+	var (
+		mu       sync.Mutex
+		payloads []WebhookPayload
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	fence := circleFence(userID)
+	fence.NotifyEmail = true
+	fence.WebhookURL = &webhook.URL
+
+	repo := repository.NewMockGeofenceRepository()
+	repo.ListActiveByUserIDFunc = func(_ context.Context, _ uuid.UUID) ([]*models.Geofence, error) {
+		return []*models.Geofence{fence}, nil
+	}
+	userRepo := repository.NewMockUserRepository()
+	userRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+		return &models.User{ID: id, Email: "driver@example.com"}, nil
+	}
+	emailService := email.NewMockService()
+
+	evaluator := NewEvaluator(repo, userRepo).WithEmailService(emailService).WithHTTPClient(webhook.Client())
+	_, err := evaluator.Evaluate(context.Background(), userID, "device-1", []*models.TelemetryData{
+		telemetryAt(userID, 0, 0),
+	})
+	require.NoError(t, err)
+
+	emails := emailService.GetGeofenceAlertEmails()
+	require.Len(t, emails, 1)
+	assert.Equal(t, "driver@example.com", emails[0].To)
+	assert.Equal(t, "Paddock", emails[0].GeofenceAlert.GeofenceName)
+	assert.Equal(t, "enter", emails[0].GeofenceAlert.EventType)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "Paddock", payloads[0].GeofenceName)
+	assert.Equal(t, models.GeofenceEnter, payloads[0].Event.Type)
+}
+
+func TestEvaluator_EnqueueGroupsAndSkipsUnowned(t *testing.T) {
+	userID := uuid.New()
+	evaluator := NewEvaluator(repository.NewMockGeofenceRepository(), repository.NewMockUserRepository())
+
+	anonymous := telemetryAt(userID, 0, 0)
+	anonymous.UserID = nil
+	noFix := telemetryAt(userID, 0, 0)
+	noFix.GPS.IsFixValid = false
+	otherDevice := telemetryAt(userID, 0, 0)
+	otherDevice.DeviceID = "device-2"
+
+	evaluator.Enqueue([]*models.TelemetryData{
+		telemetryAt(userID, 0, 0), anonymous, noFix, otherDevice, telemetryAt(userID, time.Second, 0),
+	})
+
+	require.Len(t, evaluator.queue, 2)
+	first := <-evaluator.queue
+	assert.Equal(t, "device-1", first.deviceID)
+	assert.Len(t, first.records, 2)
+	second := <-evaluator.queue
+	assert.Equal(t, "device-2", second.deviceID)
+	assert.Len(t, second.records, 1)
+}
+
+func TestEvaluator_EnqueueDoesNotBlockWhenFull(t *testing.T) {
+	userID := uuid.New()
+	evaluator := NewEvaluator(repository.NewMockGeofenceRepository(), repository.NewMockUserRepository())
+
+	finished := make(chan struct{})
+	go func() {
+		for i := 0; i < queueSize+10; i++ {
+			evaluator.Enqueue([]*models.TelemetryData{telemetryAt(userID, 0, 0)})
+		}
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue blocked on a full queue")
+	}
+}
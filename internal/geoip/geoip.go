@@ -0,0 +1,115 @@
+// Package geoip resolves IP addresses to coarse locations for session listings and login alerts.
+package geoip
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// Provider looks up the location of an IP address
+// Lookup returns nil without an error when the address is not covered, e.g. for private networks.
+type Provider interface {
+	Lookup(ctx context.Context, ip string) (*models.GeoLocation, error)
+}
+
+// network is one range of a CSV database
+type network struct {
+	prefix   netip.Prefix
+	location *models.GeoLocation
+}
+
+// CSVProvider looks addresses up in an in-memory copy of a CSV network database
+// Each row holds network,country_code,country,region,city where network is an IPv4 or IPv6 CIDR,
+// e.g. "81.2.69.0/24,GB,United Kingdom,England,London". Only the network and country code are
+// required. A header row starting with "network" is skipped. Networks must not overlap, as in the
+// GeoLite2 block files.
+type CSVProvider struct {
+	networks []network // Sorted by first address
+}
+
+// LoadCSV reads a CSV network database from path
+func LoadCSV(path string) (*CSVProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return ParseCSV(f)
+}
+
+// ParseCSV reads a CSV network database
+func ParseCSV(r io.Reader) (*CSVProvider, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var networks []network
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GeoIP database: %w", err)
+		}
+		if line == 1 && strings.EqualFold(record[0], "network") {
+			continue
+		}
+		if len(record) < 2 || record[1] == "" {
+			return nil, fmt.Errorf("GeoIP database line %d: network and country code are required", line)
+		}
+
+		prefix, err := netip.ParsePrefix(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("GeoIP database line %d: %w", line, err)
+		}
+
+		location := &models.GeoLocation{CountryCode: strings.ToUpper(record[1])}
+		for i, field := range []*string{&location.Country, &location.Region, &location.City} {
+			if len(record) > i+2 {
+				*field = record[i+2]
+			}
+		}
+		networks = append(networks, network{prefix: prefix.Masked(), location: location})
+	}
+
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].prefix.Addr().Less(networks[j].prefix.Addr())
+	})
+	return &CSVProvider{networks: networks}, nil
+}
+
+// Lookup implements Provider.Lookup
+func (p *CSVProvider) Lookup(_ context.Context, ip string) (*models.GeoLocation, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP address %q: %w", ip, err)
+	}
+	addr = addr.Unmap()
+
+	// The only network that can contain addr is the last one starting at or before it
+	i := sort.Search(len(p.networks), func(i int) bool {
+		return addr.Less(p.networks[i].prefix.Addr())
+	})
+	if i == 0 || !p.networks[i-1].prefix.Contains(addr) {
+		return nil, nil
+	}
+
+	location := *p.networks[i-1].location
+	return &location, nil
+}
+
+// Len returns the number of networks in the database
+func (p *CSVProvider) Len() int {
+	return len(p.networks)
+}
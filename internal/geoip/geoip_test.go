@@ -0,0 +1,68 @@
+package geoip
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDatabase = `network,country_code,country,region,city
+81.2.69.0/24,GB,United Kingdom,England,London
+2.16.0.0/13,de,Germany
+2a02:908::/32,DE,Germany,North Rhine-Westphalia,Cologne
+1.0.0.0/24,AU
+`
+
+func TestCSVProvider_Lookup(t *testing.T) {
+	provider, err := ParseCSV(strings.NewReader(testDatabase))
+	require.NoError(t, err)
+	assert.Equal(t, 4, provider.Len())
+
+	tests := []struct {
+		ip      string
+		country string
+		want    string
+	}{
+		{"81.2.69.160", "GB", "London, England, United Kingdom"},
+		{"2.17.255.1", "DE", "Germany"},
+		{"::ffff:2.16.0.1", "DE", "Germany"},
+		{"2a02:908:1:2::3", "DE", "Cologne, North Rhine-Westphalia, Germany"},
+		{"1.0.0.1", "AU", "AU"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			location, err := provider.Lookup(context.Background(), tt.ip)
+			require.NoError(t, err)
+			require.NotNil(t, location)
+			assert.Equal(t, tt.country, location.CountryCode)
+			assert.Equal(t, tt.want, location.String())
+		})
+	}
+
+	for _, ip := range []string{"10.0.0.1", "81.2.70.1", "0.0.0.1", "::1"} {
+		t.Run("unknown "+ip, func(t *testing.T) {
+			location, err := provider.Lookup(context.Background(), ip)
+			require.NoError(t, err)
+			assert.Nil(t, location)
+		})
+	}
+
+	_, err = provider.Lookup(context.Background(), "not-an-ip")
+	assert.Error(t, err)
+}
+
+func TestParseCSV_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"bad network":     "81.2.69.0/33,GB\n",
+		"missing country": "81.2.69.0/24\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(data))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,731 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/database/policies"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Admin listing page sizes and ingest statistics windows
+const (
+	defaultAdminUserListLimit = 50
+	maxAdminUserListLimit     = 200
+	defaultIngestStatsWindow  = 24 * time.Hour
+	maxIngestStatsWindow      = 30 * 24 * time.Hour
+	defaultOrphanReportLimit  = 100
+	maxOrphanReportLimit      = 1000
+	defaultIngestAuditLimit   = 100
+	maxIngestAuditLimit       = 1000
+	defaultLoginEventLimit    = 100
+	maxLoginEventLimit        = 1000
+)
+
+// StoragePolicyInspector reports the telemetry compression and retention policies
+type StoragePolicyInspector interface {
+	Status(ctx context.Context) (*policies.Status, error)
+}
+
+// AdminHandler handles admin-only requests
+// All routes must be protected by the RequireScope(models.ScopeAdmin) middleware.
+type AdminHandler struct {
+	userRepo         repository.UserRepository
+	deviceRepo       repository.DeviceRepository
+	telemetryRepo    repository.TelemetryRepository
+	refreshTokenRepo repository.RefreshTokenRepository    // Optional: revokes sessions of deactivated users
+	denylist         *auth.Denylist                       // Optional: revokes access tokens of deactivated users
+	policyInspector  StoragePolicyInspector               // Optional: required for storage policy inspection
+	usageRepo        repository.UsageRepository           // Optional: required for plan assignment
+	ingestAuditRepo  repository.IngestAuditRepository     // Optional: required for ingest audit queries
+	loginEventRepo   repository.LoginEventRepository      // Optional: required for login audit trail queries
+	purgeRepo        repository.TelemetryPurgeRepository  // Optional: required for telemetry purges
+	ownershipRepo    repository.DeviceOwnershipRepository // Optional: nil leaves reassignments out of the ownership history
+	minFirmware      *models.FirmwareVersion              // Optional: flags outdated versions in the firmware report
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(userRepo repository.UserRepository, deviceRepo repository.DeviceRepository, telemetryRepo repository.TelemetryRepository) *AdminHandler {
+	return &AdminHandler{
+		userRepo:      userRepo,
+		deviceRepo:    deviceRepo,
+		telemetryRepo: telemetryRepo,
+	}
+}
+
+// WithRefreshTokenRepo sets the refresh token repository used to sign out deactivated users
+func (h *AdminHandler) WithRefreshTokenRepo(refreshTokenRepo repository.RefreshTokenRepository) *AdminHandler {
+	h.refreshTokenRepo = refreshTokenRepo
+	return h
+}
+
+// WithDenylist sets the denylist used to revoke the access tokens of signed out users
+func (h *AdminHandler) WithDenylist(denylist *auth.Denylist) *AdminHandler {
+	h.denylist = denylist
+	return h
+}
+
+// WithOwnershipHistory sets the repository reassignments are recorded in
+func (h *AdminHandler) WithOwnershipHistory(ownershipRepo repository.DeviceOwnershipRepository) *AdminHandler {
+	h.ownershipRepo = ownershipRepo
+	return h
+}
+
+// WithUsageRepo sets the usage repository used to assign plans
+func (h *AdminHandler) WithUsageRepo(usageRepo repository.UsageRepository) *AdminHandler {
+	h.usageRepo = usageRepo
+	return h
+}
+
+// WithPolicyInspector sets the inspector used to report storage policies
+func (h *AdminHandler) WithPolicyInspector(policyInspector StoragePolicyInspector) *AdminHandler {
+	h.policyInspector = policyInspector
+	return h
+}
+
+// WithIngestAuditRepo sets the repository used to query the ingest audit log
+func (h *AdminHandler) WithIngestAuditRepo(ingestAuditRepo repository.IngestAuditRepository) *AdminHandler {
+	h.ingestAuditRepo = ingestAuditRepo
+	return h
+}
+
+// WithLoginEventRepo sets the repository used to query the login audit trail
+func (h *AdminHandler) WithLoginEventRepo(loginEventRepo repository.LoginEventRepository) *AdminHandler {
+	h.loginEventRepo = loginEventRepo
+	return h
+}
+
+// WithTelemetryPurgeRepo sets the repository used to purge telemetry by time range
+func (h *AdminHandler) WithTelemetryPurgeRepo(purgeRepo repository.TelemetryPurgeRepository) *AdminHandler {
+	h.purgeRepo = purgeRepo
+	return h
+}
+
+// WithMinFirmwareVersion flags firmware versions older than minVersion in the firmware report
+func (h *AdminHandler) WithMinFirmwareVersion(minVersion *models.FirmwareVersion) *AdminHandler {
+	h.minFirmware = minVersion
+	return h
+}
+
+// ReassignDeviceRequest represents the device reassignment request body
+type ReassignDeviceRequest struct {
+	UserID string  `json:"userId" binding:"required"`
+	Reason *string `json:"reason,omitempty" binding:"omitempty,max=500"` // Recorded in the device's ownership history
+}
+
+// SetUserPlanRequest represents the plan assignment request body
+type SetUserPlanRequest struct {
+	Plan models.Plan `json:"plan" binding:"required"`
+}
+
+// PurgeTelemetryRequest represents the telemetry purge request body
+type PurgeTelemetryRequest struct {
+	DeviceID string    `json:"deviceId,omitempty" binding:"omitempty,max=50"` // Empty purges every device
+	From     time.Time `json:"from" binding:"required"`
+	To       time.Time `json:"to" binding:"required"` // Exclusive
+	DryRun   bool      `json:"dryRun"`                // Report the affected chunks and records without deleting
+}
+
+// ListUsers lists and searches user accounts, newest first
+// GET /api/v1/admin/users?q=&role=&active=&limit=&cursor=&offset=
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	page, offset, err := parsePage(c, defaultAdminUserListLimit, maxAdminUserListLimit)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	filter := repository.UserFilter{
+		Search: strings.TrimSpace(c.Query("q")),
+		Limit:  page.Fetch(),
+		Offset: offset,
+		After:  page.After,
+	}
+
+	if role := c.Query("role"); role != "" {
+		filter.Role = models.Role(role)
+		if !filter.Role.IsValid() {
+			problem.Abort(c, problem.BadRequest("invalid_request", "role must be one of: user, admin"))
+			return
+		}
+	}
+
+	if active := c.Query("active"); active != "" {
+		isActive, err := strconv.ParseBool(active)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_request", "active must be true or false"))
+			return
+		}
+		filter.IsActive = &isActive
+	}
+
+	users, err := h.userRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve users"))
+		return
+	}
+	users, nextCursor := pagination.Next(page, users, userCursor)
+
+	response := make([]*models.UserResponse, len(users))
+	for i, user := range users {
+		response[i] = user.ToResponse()
+	}
+
+	meta := api.Meta{
+		"total":  len(response),
+		"limit":  page.Limit,
+		"offset": offset,
+	}
+	if nextCursor != "" {
+		meta["nextCursor"] = nextCursor
+	}
+	api.List(c, http.StatusOK, "users", response, meta)
+}
+
+// userCursor returns the listing position of a user
+func userCursor(user *models.User) pagination.Cursor {
+	return pagination.Cursor{Time: user.CreatedAt, ID: user.ID.String()}
+}
+
+// DeactivateUser disables a user account and revokes its refresh and access tokens
+// PATCH /api/v1/admin/users/:id/deactivate
+func (h *AdminHandler) DeactivateUser(c *gin.Context) {
+	adminID := middleware.MustGetUserID(c)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_user_id", "Invalid user ID format"))
+		return
+	}
+
+	if userID == adminID {
+		problem.Abort(c, problem.BadRequest("invalid_request", "You cannot deactivate your own account"))
+		return
+	}
+
+	if err := h.userRepo.SetActive(c.Request.Context(), userID, false); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			problem.Abort(c, problem.NotFound("user_not_found", "User not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to deactivate user"))
+		return
+	}
+
+	// Sign the user out everywhere
+	if h.refreshTokenRepo != nil {
+		if err := h.refreshTokenRepo.RevokeAllForUser(c.Request.Context(), userID); err != nil {
+			slog.Error("Error revoking refresh tokens of deactivated user", "error", err, "user_id", userID)
+			// Non-critical, refreshing is refused for inactive users
+		}
+	}
+	// Access tokens stay valid until they expire unless they are denylisted; the account is already
+	// deactivated, so the request can simply be retried
+	if err := h.denylist.RevokeUser(c.Request.Context(), userID); err != nil {
+		slog.Error("Error revoking access tokens of deactivated user", "error", err, "user_id", userID)
+		problem.Abort(c, problem.Internal("Failed to revoke access tokens"))
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve user"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, user.ToResponse())
+}
+
+// RevokeUserTokens signs a user out everywhere immediately, for example when the account is compromised
+// Refresh tokens are revoked and every access token issued so far is rejected, without disabling
+// the account, so the user can log in again with their password.
+// POST /api/v1/admin/users/:id/revoke-tokens
+func (h *AdminHandler) RevokeUserTokens(c *gin.Context) {
+	if h.refreshTokenRepo == nil || h.denylist == nil {
+		problem.Abort(c, problem.ServiceUnavailable("revocation_unavailable", "Token revocation is not configured"))
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_user_id", "Invalid user ID format"))
+		return
+	}
+
+	if _, err := h.userRepo.GetByID(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			problem.Abort(c, problem.NotFound("user_not_found", "User not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve user"))
+		return
+	}
+
+	if err := h.refreshTokenRepo.RevokeAllForUser(c.Request.Context(), userID); err != nil {
+		problem.Abort(c, problem.Internal("Failed to revoke refresh tokens"))
+		return
+	}
+
+	if err := h.denylist.RevokeUser(c.Request.Context(), userID); err != nil {
+		problem.Abort(c, problem.Internal("Failed to revoke access tokens"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "All tokens revoked",
+	})
+}
+
+// ReassignDevice transfers a device to another user
+// The device's API keys are revoked so the previous owner can no longer upload.
+// PUT /api/v1/admin/devices/:id/owner
+func (h *AdminHandler) ReassignDevice(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return
+	}
+
+	var req ReassignDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_user_id", "Invalid user ID format"))
+		return
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			problem.Abort(c, problem.NotFound("user_not_found", "User not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve user"))
+		return
+	}
+
+	if !user.IsActive {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Cannot assign a device to a deactivated account"))
+		return
+	}
+
+	if err := h.deviceRepo.Reassign(c.Request.Context(), device.ID, user.ID); err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to reassign device"))
+		return
+	}
+
+	adminID := middleware.MustGetUserID(c)
+	previousOwner := device.UserID
+	recordOwnershipChange(c.Request.Context(), h.ownershipRepo, &models.DeviceOwnershipChange{
+		DeviceID:   device.ID,
+		Action:     models.OwnershipActionReassign,
+		FromUserID: &previousOwner,
+		ToUserID:   &user.ID,
+		ActorID:    &adminID,
+		Reason:     req.Reason,
+	})
+
+	now := time.Now().UTC()
+	device.UserID = user.ID
+	device.ClaimedAt = now
+	device.UpdatedAt = now
+
+	api.Respond(c, http.StatusOK, device.ToResponse())
+}
+
+// SetUserPlan assigns a usage plan to a user
+// PUT /api/v1/admin/users/:id/plan
+func (h *AdminHandler) SetUserPlan(c *gin.Context) {
+	if h.usageRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("usage_unavailable", "Usage quotas are not enabled"))
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_user_id", "Invalid user ID format"))
+		return
+	}
+
+	var req SetUserPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+	if !req.Plan.IsValid() {
+		problem.Abort(c, problem.BadRequest("invalid_request", "plan must be one of: free, pro"))
+		return
+	}
+
+	if _, err := h.userRepo.GetByID(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			problem.Abort(c, problem.NotFound("user_not_found", "User not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve user"))
+		return
+	}
+
+	if err := h.usageRepo.SetPlan(c.Request.Context(), userID, req.Plan); err != nil {
+		problem.Abort(c, problem.Internal("Failed to set plan"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"userId": userID,
+		"plan":   req.Plan,
+	})
+}
+
+// GetIngestStats reports telemetry ingestion statistics over a recent window
+// GET /api/v1/admin/stats/ingest?window=24h
+func (h *AdminHandler) GetIngestStats(c *gin.Context) {
+	window := defaultIngestStatsWindow
+	if raw := c.Query("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 || parsed > maxIngestStatsWindow {
+			problem.Abort(c, problem.BadRequest("invalid_request", "window must be a positive duration of at most "+maxIngestStatsWindow.String()))
+			return
+		}
+		window = parsed
+	}
+
+	stats, err := h.telemetryRepo.IngestStats(c.Request.Context(), time.Now().UTC().Add(-window))
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to compute ingest statistics"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, stats)
+}
+
+// GetFirmwareDistribution reports how many active devices run each firmware version
+// Devices that never sent X-Firmware-Version are counted under a null version.
+// GET /api/v1/admin/devices/firmware
+func (h *AdminHandler) GetFirmwareDistribution(c *gin.Context) {
+	counts, err := h.deviceRepo.FirmwareDistribution(c.Request.Context())
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to compute firmware distribution"))
+		return
+	}
+
+	var devices, belowMinimum int64
+	for _, count := range counts {
+		devices += count.Devices
+		if h.minFirmware == nil || count.Version == nil {
+			continue
+		}
+		if version, err := models.ParseFirmwareVersion(*count.Version); err == nil && version.Before(*h.minFirmware) {
+			count.BelowMinimum = true
+			belowMinimum += count.Devices
+		}
+	}
+
+	response := gin.H{
+		"versions":     counts,
+		"devices":      devices,
+		"belowMinimum": belowMinimum,
+	}
+	if h.minFirmware != nil {
+		response["minVersion"] = h.minFirmware.String()
+	}
+	api.Respond(c, http.StatusOK, response)
+}
+
+// GetOrphanedTelemetry reports telemetry stored without an owner, grouped by device
+// Devices that have since been claimed include their current owner so the data can be reassigned.
+// GET /api/v1/admin/telemetry/orphans?limit=100
+func (h *AdminHandler) GetOrphanedTelemetry(c *gin.Context) {
+	limit, err := parseIntQuery(c, "limit", defaultOrphanReportLimit)
+	if err != nil || limit <= 0 || limit > maxOrphanReportLimit {
+		problem.Abort(c, problem.BadRequest("invalid_request", "limit must be between 1 and "+strconv.Itoa(maxOrphanReportLimit)))
+		return
+	}
+
+	orphans, err := h.telemetryRepo.OrphanedTelemetry(c.Request.Context(), limit)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to compute orphaned telemetry report"))
+		return
+	}
+
+	var dataPoints int64
+	for _, orphan := range orphans {
+		dataPoints += orphan.DataPoints
+	}
+
+	api.List(c, http.StatusOK, "devices", orphans, api.Meta{
+		"count":      len(orphans),
+		"dataPoints": dataPoints,
+	})
+}
+
+// GetStoragePolicies reports the configured and active telemetry compression and retention policies
+// GET /api/v1/admin/storage/policies
+func (h *AdminHandler) GetStoragePolicies(c *gin.Context) {
+	if h.policyInspector == nil {
+		problem.Abort(c, problem.ServiceUnavailable("policies_unavailable", "Storage policy inspection is not configured"))
+		return
+	}
+
+	status, err := h.policyInspector.Status(c.Request.Context())
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve storage policies"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, status)
+}
+
+// ListIngestAudit queries the ingest audit log, newest first
+// GET /api/v1/admin/ingest/audit?userId=&deviceId=&ip=&result=&from=&to=&limit=&offset=
+func (h *AdminHandler) ListIngestAudit(c *gin.Context) {
+	if h.ingestAuditRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("audit_unavailable", "The ingest audit log is not enabled"))
+		return
+	}
+
+	filter, err := parseIngestAuditFilter(c)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	entries, err := h.ingestAuditRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve ingest audit log"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "entries", entries, api.Meta{
+		"total":  len(entries),
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// parseIngestAuditFilter builds an ingest audit filter from query parameters
+func parseIngestAuditFilter(c *gin.Context) (repository.IngestAuditFilter, error) {
+	filter := repository.IngestAuditFilter{
+		DeviceID: strings.TrimSpace(c.Query("deviceId")),
+		SourceIP: strings.TrimSpace(c.Query("ip")),
+		Result:   models.IngestResult(c.Query("result")),
+	}
+
+	if userID := c.Query("userId"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return filter, errors.New("userId must be a valid UUID")
+		}
+		filter.UserID = &id
+	}
+
+	if filter.Result != "" && !filter.Result.IsValid() {
+		return filter, errors.New("result must be one of: accepted, rejected, failed")
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, errors.New("from must be an RFC3339 timestamp")
+		}
+		filter.From = &t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, errors.New("to must be an RFC3339 timestamp")
+		}
+		filter.To = &t
+	}
+
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return filter, errors.New("to must not be before from")
+	}
+
+	limit, err := parseIntQuery(c, "limit", defaultIngestAuditLimit)
+	if err != nil || limit <= 0 || limit > maxIngestAuditLimit {
+		return filter, errors.New("limit must be between 1 and " + strconv.Itoa(maxIngestAuditLimit))
+	}
+	filter.Limit = limit
+
+	offset, err := parseIntQuery(c, "offset", 0)
+	if err != nil || offset < 0 {
+		return filter, errors.New("offset must be a non-negative integer")
+	}
+	filter.Offset = offset
+
+	return filter, nil
+}
+
+// ListLoginEvents queries the login audit trail, newest first
+// GET /api/v1/admin/logins?userId=&email=&ip=&method=&success=&from=&to=&limit=&cursor=
+func (h *AdminHandler) ListLoginEvents(c *gin.Context) {
+	if h.loginEventRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("login_activity_unavailable", "The login audit trail is not enabled"))
+		return
+	}
+
+	filter, page, err := parseLoginEventFilter(c)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	events, err := h.loginEventRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve login events"))
+		return
+	}
+	events, nextCursor := pagination.Next(page, events, loginEventCursor)
+
+	meta := api.Meta{
+		"total": len(events),
+		"limit": page.Limit,
+	}
+	if nextCursor != "" {
+		meta["nextCursor"] = nextCursor
+	}
+	api.List(c, http.StatusOK, "events", events, meta)
+}
+
+// parseLoginEventFilter builds a login audit trail filter from query parameters
+func parseLoginEventFilter(c *gin.Context) (repository.LoginEventFilter, pagination.Page, error) {
+	filter := repository.LoginEventFilter{
+		Email:     strings.ToLower(strings.TrimSpace(c.Query("email"))),
+		IPAddress: strings.TrimSpace(c.Query("ip")),
+		Method:    models.LoginMethod(c.Query("method")),
+	}
+
+	if userID := c.Query("userId"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return filter, pagination.Page{}, errors.New("userId must be a valid UUID")
+		}
+		filter.UserID = &id
+	}
+
+	if filter.Method != "" && !filter.Method.IsValid() {
+		return filter, pagination.Page{}, errors.New("method must be one of: password, magic_link, oidc")
+	}
+
+	if success := c.Query("success"); success != "" {
+		value, err := strconv.ParseBool(success)
+		if err != nil {
+			return filter, pagination.Page{}, errors.New("success must be true or false")
+		}
+		filter.Success = &value
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, pagination.Page{}, errors.New("from must be an RFC3339 timestamp")
+		}
+		filter.From = &t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, pagination.Page{}, errors.New("to must be an RFC3339 timestamp")
+		}
+		filter.To = &t
+	}
+
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return filter, pagination.Page{}, errors.New("to must not be before from")
+	}
+
+	page, err := pagination.Parse(c.Request.URL.Query(), defaultLoginEventLimit, maxLoginEventLimit, pagination.IntegerID)
+	if err != nil {
+		return filter, page, err
+	}
+	filter.Limit, filter.After = page.Fetch(), page.After
+
+	return filter, page, nil
+}
+
+// PurgeTelemetry permanently deletes telemetry recorded in a time range, optionally of one device
+// POST /api/v1/admin/telemetry/purge
+func (h *AdminHandler) PurgeTelemetry(c *gin.Context) {
+	if h.purgeRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("purge_unavailable", "Telemetry purges are not enabled"))
+		return
+	}
+
+	var req PurgeTelemetryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+	if !req.From.Before(req.To) {
+		problem.Abort(c, problem.BadRequest("invalid_request", "from must be before to"))
+		return
+	}
+
+	filter := repository.TelemetryPurgeFilter{
+		DeviceID: strings.TrimSpace(req.DeviceID),
+		From:     req.From.UTC(),
+		To:       req.To.UTC(),
+	}
+
+	if req.DryRun {
+		plan, err := h.purgeRepo.Plan(c.Request.Context(), filter)
+		if err != nil {
+			problem.Abort(c, problem.Internal("Failed to plan telemetry purge"))
+			return
+		}
+		api.Respond(c, http.StatusOK, plan)
+		return
+	}
+
+	purge, err := h.purgeRepo.Purge(c.Request.Context(), filter)
+	if err != nil {
+		slog.Error("Telemetry purge failed", "deviceId", filter.DeviceID, "from", filter.From, "to", filter.To, "error", err)
+		problem.Abort(c, problem.Internal("Failed to purge telemetry"))
+		return
+	}
+
+	slog.Info("Purged telemetry",
+		"adminId", middleware.MustGetUserID(c),
+		"deviceId", filter.DeviceID,
+		"from", filter.From,
+		"to", filter.To,
+		"chunksDropped", purge.ChunksDropped,
+		"rows", purge.Rows,
+	)
+
+	api.Respond(c, http.StatusOK, purge)
+}
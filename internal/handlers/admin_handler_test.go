@@ -0,0 +1,939 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/cache"
+	"github.com/sebasr/avt-service/internal/database/policies"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type adminTestRepos struct {
+	users         *repository.MockUserRepository
+	devices       *repository.MockDeviceRepository
+	telemetry     *repository.MockRepository
+	refreshTokens *repository.MockRefreshTokenRepository
+}
+
+func setupAdminTest() (*AdminHandler, adminTestRepos) {
+	repos := adminTestRepos{
+		users:         repository.NewMockUserRepository(),
+		devices:       repository.NewMockDeviceRepository(),
+		telemetry:     repository.NewMockRepository(),
+		refreshTokens: repository.NewMockRefreshTokenRepository(),
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	handler := NewAdminHandler(repos.users, repos.devices, repos.telemetry).
+		WithRefreshTokenRepo(repos.refreshTokens)
+	return handler, repos
+}
+
+func TestAdminHandler_ListUsers(t *testing.T) {
+	handler, repos := setupAdminTest()
+
+	var captured repository.UserFilter
+	repos.users.ListFunc = func(_ context.Context, filter repository.UserFilter) ([]*models.User, error) {
+		captured = filter
+		return []*models.User{
+			{ID: uuid.New(), Email: "driver@example.com", IsActive: true, Role: models.RoleUser},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/users?q=driver&role=user&active=true&limit=10&offset=5", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.ListUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "driver", captured.Search)
+	assert.Equal(t, models.RoleUser, captured.Role)
+	require.NotNil(t, captured.IsActive)
+	assert.True(t, *captured.IsActive)
+	assert.Equal(t, 11, captured.Limit, "one extra user detects the next page")
+	assert.Equal(t, 5, captured.Offset)
+
+	var response struct {
+		Users []models.UserResponse `json:"users"`
+		Total int                   `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, "driver@example.com", response.Users[0].Email)
+	assert.Equal(t, models.RoleUser, response.Users[0].Role)
+}
+
+func TestAdminHandler_ListUsers_Cursor(t *testing.T) {
+	handler, repos := setupAdminTest()
+
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	users := []*models.User{
+		{ID: uuid.New(), Email: "c@example.com", CreatedAt: base},
+		{ID: uuid.New(), Email: "b@example.com", CreatedAt: base.Add(-time.Hour)},
+		{ID: uuid.New(), Email: "a@example.com", CreatedAt: base.Add(-2 * time.Hour)},
+	}
+	var captured repository.UserFilter
+	repos.users.ListFunc = func(_ context.Context, filter repository.UserFilter) ([]*models.User, error) {
+		captured = filter
+		listed := users
+		if filter.After != nil {
+			listed = users[2:]
+		}
+		return listed[:min(filter.Limit, len(listed))], nil
+	}
+
+	list := func(query string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/users?"+query, nil)
+		handler.ListUsers(c)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	response := list("limit=2")
+	assert.Len(t, response["users"], 2)
+	nextCursor, ok := response["nextCursor"].(string)
+	require.True(t, ok)
+
+	response = list("limit=2&cursor=" + nextCursor)
+	require.NotNil(t, captured.After)
+	assert.Equal(t, users[1].ID.String(), captured.After.ID)
+	assert.True(t, captured.After.Time.Equal(users[1].CreatedAt))
+	assert.NotContains(t, response, "nextCursor", "the last page has no next cursor")
+}
+
+func TestAdminHandler_ListUsers_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown role", "role=superuser"},
+		{"invalid active", "active=maybe"},
+		{"limit too large", "limit=1000"},
+		{"negative offset", "offset=-1"},
+		{"invalid cursor", "cursor=garbage"},
+		{"cursor with offset", "offset=5&cursor=" + pagination.Cursor{Time: time.Now(), ID: uuid.New().String()}.Encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupAdminTest()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/users?"+tt.query, nil)
+
+			handler.ListUsers(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestAdminHandler_DeactivateUser(t *testing.T) {
+	handler, repos := setupAdminTest()
+	userID := uuid.New()
+
+	active := true
+	repos.users.SetActiveFunc = func(_ context.Context, id uuid.UUID, isActive bool) error {
+		assert.Equal(t, userID, id)
+		active = isActive
+		return nil
+	}
+	repos.users.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+		return &models.User{ID: id, Email: "driver@example.com", IsActive: active, Role: models.RoleUser}, nil
+	}
+
+	var revokedFor uuid.UUID
+	repos.refreshTokens.RevokeAllForUserFunc = func(_ context.Context, id uuid.UUID) error {
+		revokedFor = id
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/admin/users/"+userID.String()+"/deactivate", nil)
+	c.Params = gin.Params{{Key: "id", Value: userID.String()}}
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.DeactivateUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, active)
+	assert.Equal(t, userID, revokedFor)
+
+	var response models.UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.IsActive)
+}
+
+// failingCache rejects every write
+type failingCache struct {
+	cache.Cache
+}
+
+func (failingCache) Set(context.Context, string, interface{}, time.Duration) error {
+	return errors.New("cache unavailable")
+}
+
+func TestAdminHandler_DeactivateUser_DenylistFailure(t *testing.T) {
+	handler, repos := setupAdminTest()
+	handler.WithDenylist(auth.NewDenylist(failingCache{cache.NewMemoryCache()}, time.Hour))
+	userID := uuid.New()
+
+	repos.users.SetActiveFunc = func(_ context.Context, _ uuid.UUID, _ bool) error {
+		return nil
+	}
+	repos.refreshTokens.RevokeAllForUserFunc = func(_ context.Context, _ uuid.UUID) error {
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/admin/users/"+userID.String()+"/deactivate", nil)
+	c.Params = gin.Params{{Key: "id", Value: userID.String()}}
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.DeactivateUser(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAdminHandler_DeactivateUser_Self(t *testing.T) {
+	handler, repos := setupAdminTest()
+	adminID := uuid.New()
+
+	repos.users.SetActiveFunc = func(_ context.Context, _ uuid.UUID, _ bool) error {
+		t.Fatal("SetActive should not be called")
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/admin/users/"+adminID.String()+"/deactivate", nil)
+	c.Params = gin.Params{{Key: "id", Value: adminID.String()}}
+	c.Set(string(middleware.UserIDKey), adminID)
+
+	handler.DeactivateUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminHandler_DeactivateUser_NotFound(t *testing.T) {
+	handler, repos := setupAdminTest()
+	userID := uuid.New()
+
+	repos.users.SetActiveFunc = func(_ context.Context, _ uuid.UUID, _ bool) error {
+		return repository.ErrUserNotFound
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/admin/users/"+userID.String()+"/deactivate", nil)
+	c.Params = gin.Params{{Key: "id", Value: userID.String()}}
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.DeactivateUser(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminHandler_RevokeUserTokens(t *testing.T) {
+	userID := uuid.New()
+	serve := func(handler *AdminHandler) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+userID.String()+"/revoke-tokens", nil)
+		c.Params = gin.Params{{Key: "id", Value: userID.String()}}
+		c.Set(string(middleware.UserIDKey), uuid.New())
+		handler.RevokeUserTokens(c)
+		return w
+	}
+
+	t.Run("revokes refresh and access tokens", func(t *testing.T) {
+		handler, repos := setupAdminTest()
+		denylist := auth.NewDenylist(cache.NewMemoryCache(), time.Hour)
+		handler.WithDenylist(denylist)
+
+		repos.users.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+			return &models.User{ID: id, IsActive: true}, nil
+		}
+		var revokedFor uuid.UUID
+		repos.refreshTokens.RevokeAllForUserFunc = func(_ context.Context, id uuid.UUID) error {
+			revokedFor = id
+			return nil
+		}
+
+		w := serve(handler)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, userID, revokedFor)
+
+		issued := &auth.Claims{UserID: userID.String()}
+		issued.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		revoked, err := denylist.IsRevoked(context.Background(), issued)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		handler, repos := setupAdminTest()
+		handler.WithDenylist(auth.NewDenylist(cache.NewMemoryCache(), time.Hour))
+		repos.users.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.User, error) {
+			return nil, repository.ErrUserNotFound
+		}
+
+		w := serve(handler)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("unavailable without a denylist", func(t *testing.T) {
+		handler, _ := setupAdminTest()
+
+		w := serve(handler)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestAdminHandler_ReassignDevice(t *testing.T) {
+	previousOwner := uuid.New()
+	newOwner := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: previousOwner, IsActive: true}
+
+	tests := []struct {
+		name           string
+		body           string
+		targetUser     *models.User
+		expectedStatus int
+		expectReassign bool
+	}{
+		{
+			name:           "success",
+			body:           `{"userId":"` + newOwner.String() + `"}`,
+			targetUser:     &models.User{ID: newOwner, IsActive: true},
+			expectedStatus: http.StatusOK,
+			expectReassign: true,
+		},
+		{
+			name:           "target user not found",
+			body:           `{"userId":"` + newOwner.String() + `"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "target user deactivated",
+			body:           `{"userId":"` + newOwner.String() + `"}`,
+			targetUser:     &models.User{ID: newOwner, IsActive: false},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid user id",
+			body:           `{"userId":"not-a-uuid"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing user id",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, repos := setupAdminTest()
+
+			repos.devices.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Device, error) {
+				if id == device.ID {
+					copied := *device
+					return &copied, nil
+				}
+				return nil, repository.ErrDeviceNotFound
+			}
+			repos.users.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.User, error) {
+				if tt.targetUser == nil {
+					return nil, repository.ErrUserNotFound
+				}
+				return tt.targetUser, nil
+			}
+
+			reassigned := false
+			repos.devices.ReassignFunc = func(_ context.Context, id uuid.UUID, userID uuid.UUID) error {
+				assert.Equal(t, device.ID, id)
+				assert.Equal(t, newOwner, userID)
+				reassigned = true
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/admin/devices/"+device.ID.String()+"/owner", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: device.ID.String()}}
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.ReassignDevice(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectReassign, reassigned)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response models.DeviceResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, newOwner, response.UserID)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ReassignDevice_RecordsHistory(t *testing.T) {
+	handler, repos := setupAdminTest()
+	ownershipRepo := repository.NewMockDeviceOwnershipRepository()
+	handler.WithOwnershipHistory(ownershipRepo)
+
+	adminID := uuid.New()
+	previousOwner := uuid.New()
+	newOwner := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: previousOwner, IsActive: true}
+
+	repos.devices.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+		copied := *device
+		return &copied, nil
+	}
+	repos.users.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.User, error) {
+		return &models.User{ID: newOwner, IsActive: true}, nil
+	}
+
+	var recorded *models.DeviceOwnershipChange
+	ownershipRepo.RecordFunc = func(_ context.Context, change *models.DeviceOwnershipChange) error {
+		recorded = change
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := `{"userId":"` + newOwner.String() + `","reason":"Support ticket 4521"}`
+	c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/admin/devices/"+device.ID.String()+"/owner", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: device.ID.String()}}
+	c.Set(string(middleware.UserIDKey), adminID)
+
+	handler.ReassignDevice(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, recorded)
+	assert.Equal(t, device.ID, recorded.DeviceID)
+	assert.Equal(t, models.OwnershipActionReassign, recorded.Action)
+	assert.Equal(t, previousOwner, *recorded.FromUserID)
+	assert.Equal(t, newOwner, *recorded.ToUserID)
+	assert.Equal(t, adminID, *recorded.ActorID)
+	require.NotNil(t, recorded.Reason)
+	assert.Equal(t, "Support ticket 4521", *recorded.Reason)
+}
+
+func TestAdminHandler_GetIngestStats(t *testing.T) {
+	handler, repos := setupAdminTest()
+
+	var capturedSince time.Time
+	repos.telemetry.IngestStatsFunc = func(_ context.Context, since time.Time) (*models.IngestStats, error) {
+		capturedSince = since
+		return &models.IngestStats{Since: since, DataPoints: 1200, ActiveDevices: 3, Batches: 12, BatchRecords: 1200}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/stats/ingest?window=1h", nil)
+
+	handler.GetIngestStats(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), capturedSince, 5*time.Second)
+
+	var response models.IngestStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(1200), response.DataPoints)
+	assert.Equal(t, int64(3), response.ActiveDevices)
+}
+
+func TestAdminHandler_GetIngestStats_InvalidWindow(t *testing.T) {
+	for _, window := range []string{"yesterday", "-1h", "1000h"} {
+		t.Run(window, func(t *testing.T) {
+			handler, _ := setupAdminTest()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/stats/ingest?window="+window, nil)
+
+			handler.GetIngestStats(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestAdminHandler_GetFirmwareDistribution(t *testing.T) {
+	handler, repos := setupAdminTest()
+	minVersion, err := models.ParseFirmwareVersion("2.0")
+	require.NoError(t, err)
+	handler = handler.WithMinFirmwareVersion(&minVersion)
+
+	current, outdated := "2.1.0", "1.9.2"
+	repos.devices.FirmwareDistributionFunc = func(_ context.Context) ([]*models.FirmwareCount, error) {
+		return []*models.FirmwareCount{
+			{Version: &current, Devices: 40, Online: 12},
+			{Version: &outdated, Devices: 7, Online: 2},
+			{Version: nil, Devices: 3},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/devices/firmware", nil)
+
+	handler.GetFirmwareDistribution(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Versions     []models.FirmwareCount `json:"versions"`
+		Devices      int64                  `json:"devices"`
+		BelowMinimum int64                  `json:"belowMinimum"`
+		MinVersion   string                 `json:"minVersion"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(50), response.Devices)
+	assert.Equal(t, int64(7), response.BelowMinimum)
+	assert.Equal(t, "2.0.0", response.MinVersion)
+	require.Len(t, response.Versions, 3)
+	assert.False(t, response.Versions[0].BelowMinimum)
+	assert.True(t, response.Versions[1].BelowMinimum)
+	assert.False(t, response.Versions[2].BelowMinimum, "devices without a reported version are not flagged")
+}
+
+func TestAdminHandler_GetOrphanedTelemetry(t *testing.T) {
+	handler, repos := setupAdminTest()
+
+	owner := uuid.New()
+	var capturedLimit int
+	repos.telemetry.OrphanedTelemetryFunc = func(_ context.Context, limit int) ([]*models.OrphanedTelemetry, error) {
+		capturedLimit = limit
+		return []*models.OrphanedTelemetry{
+			{DeviceID: "racebox-1", DataPoints: 900, ClaimedBy: &owner},
+			{DeviceID: "", DataPoints: 100},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/telemetry/orphans?limit=10", nil)
+
+	handler.GetOrphanedTelemetry(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 10, capturedLimit)
+
+	var response struct {
+		Devices    []models.OrphanedTelemetry `json:"devices"`
+		Count      int                        `json:"count"`
+		DataPoints int64                      `json:"dataPoints"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, int64(1000), response.DataPoints)
+	require.NotNil(t, response.Devices[0].ClaimedBy)
+	assert.Equal(t, owner, *response.Devices[0].ClaimedBy)
+	assert.Nil(t, response.Devices[1].ClaimedBy)
+}
+
+func TestAdminHandler_GetOrphanedTelemetry_InvalidLimit(t *testing.T) {
+	for _, limit := range []string{"0", "abc", "5000"} {
+		t.Run(limit, func(t *testing.T) {
+			handler, _ := setupAdminTest()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/telemetry/orphans?limit="+limit, nil)
+
+			handler.GetOrphanedTelemetry(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestAdminHandler_SetUserPlan(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		param          string
+		body           string
+		expectedStatus int
+	}{
+		{"success", userID.String(), `{"plan":"pro"}`, http.StatusOK},
+		{"unknown plan", userID.String(), `{"plan":"enterprise"}`, http.StatusBadRequest},
+		{"invalid id", "not-a-uuid", `{"plan":"pro"}`, http.StatusBadRequest},
+		{"user not found", uuid.New().String(), `{"plan":"pro"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, repos := setupAdminTest()
+			usageRepo := repository.NewMockUsageRepository()
+			handler.WithUsageRepo(usageRepo)
+
+			repos.users.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+				if id != userID {
+					return nil, repository.ErrUserNotFound
+				}
+				return &models.User{ID: id, IsActive: true}, nil
+			}
+			var assigned models.Plan
+			usageRepo.SetPlanFunc = func(_ context.Context, _ uuid.UUID, plan models.Plan) error {
+				assigned = plan
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/admin/users/"+tt.param+"/plan", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: tt.param}}
+
+			handler.SetUserPlan(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, models.PlanPro, assigned)
+			} else {
+				assert.Empty(t, assigned)
+			}
+		})
+	}
+}
+
+type stubPolicyInspector struct {
+	status *policies.Status
+	err    error
+}
+
+func (s *stubPolicyInspector) Status(_ context.Context) (*policies.Status, error) {
+	return s.status, s.err
+}
+
+func TestAdminHandler_GetStoragePolicies(t *testing.T) {
+	status := &policies.Status{
+		Hypertable:    policies.TelemetryHypertable,
+		CompressAfter: "168h0m0s",
+		RetainFor:     "8760h0m0s",
+		Policies: []policies.Policy{
+			{JobID: 1000, Kind: policies.KindCompression, After: "7 days", ScheduleInterval: "12:00:00", Scheduled: true},
+			{JobID: 1004, Kind: policies.KindRetention, After: "365 days", ScheduleInterval: "1 day", Scheduled: true},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		inspector      StoragePolicyInspector
+		expectedStatus int
+	}{
+		{"success", &stubPolicyInspector{status: status}, http.StatusOK},
+		{"inspector error", &stubPolicyInspector{err: errors.New("database unavailable")}, http.StatusInternalServerError},
+		{"not configured", nil, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupAdminTest()
+			if tt.inspector != nil {
+				handler = handler.WithPolicyInspector(tt.inspector)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/storage/policies", nil)
+
+			handler.GetStoragePolicies(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response policies.Status
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "8760h0m0s", response.RetainFor)
+				require.Len(t, response.Policies, 2)
+				assert.Equal(t, policies.KindRetention, response.Policies[1].Kind)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ListIngestAudit(t *testing.T) {
+	handler, _ := setupAdminTest()
+	auditRepo := repository.NewMockIngestAuditRepository()
+	handler = handler.WithIngestAuditRepo(auditRepo)
+
+	userID := uuid.New()
+	var captured repository.IngestAuditFilter
+	auditRepo.ListFunc = func(_ context.Context, filter repository.IngestAuditFilter) ([]*models.IngestAuditEntry, error) {
+		captured = filter
+		return []*models.IngestAuditEntry{
+			{ID: 2, Source: models.IngestSourceBatch, UserID: &userID, DeviceID: "racebox-1", Records: 50, Status: 429, Result: models.IngestResultRejected},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet,
+		"/api/v1/admin/ingest/audit?userId="+userID.String()+"&deviceId=racebox-1&ip=203.0.113.7&result=rejected&from=2024-01-10T00:00:00Z&limit=20&offset=40", nil)
+
+	handler.ListIngestAudit(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, captured.UserID)
+	assert.Equal(t, userID, *captured.UserID)
+	assert.Equal(t, "racebox-1", captured.DeviceID)
+	assert.Equal(t, "203.0.113.7", captured.SourceIP)
+	assert.Equal(t, models.IngestResultRejected, captured.Result)
+	require.NotNil(t, captured.From)
+	assert.Nil(t, captured.To)
+	assert.Equal(t, 20, captured.Limit)
+	assert.Equal(t, 40, captured.Offset)
+
+	var response struct {
+		Entries []models.IngestAuditEntry `json:"entries"`
+		Total   int                       `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, 429, response.Entries[0].Status)
+}
+
+func TestAdminHandler_ListIngestAudit_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"userId=abc", "result=ok", "from=yesterday", "from=2024-01-10T00:00:00Z&to=2024-01-09T00:00:00Z", "limit=0", "limit=5000", "offset=-1"} {
+		t.Run(query, func(t *testing.T) {
+			handler, _ := setupAdminTest()
+			handler = handler.WithIngestAuditRepo(repository.NewMockIngestAuditRepository())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/ingest/audit?"+query, nil)
+
+			handler.ListIngestAudit(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestAdminHandler_ListIngestAudit_NotConfigured(t *testing.T) {
+	handler, _ := setupAdminTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/ingest/audit", nil)
+
+	handler.ListIngestAudit(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestAdminHandler_ListLoginEvents(t *testing.T) {
+	handler, _ := setupAdminTest()
+	eventRepo := repository.NewMockLoginEventRepository()
+	handler = handler.WithLoginEventRepo(eventRepo)
+
+	var captured repository.LoginEventFilter
+	eventRepo.ListFunc = func(_ context.Context, filter repository.LoginEventFilter) ([]*models.LoginEvent, error) {
+		captured = filter
+		return []*models.LoginEvent{
+			{ID: 9, Email: "victim@example.com", Method: models.LoginMethodPassword, FailureReason: models.LoginFailureInvalidCredentials, IPAddress: "203.0.113.7"},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet,
+		"/api/v1/admin/logins?email=Victim@Example.com&ip=203.0.113.7&method=password&success=false&from=2024-01-10T00:00:00Z&limit=20", nil)
+
+	handler.ListLoginEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, captured.UserID)
+	assert.Equal(t, "victim@example.com", captured.Email)
+	assert.Equal(t, "203.0.113.7", captured.IPAddress)
+	assert.Equal(t, models.LoginMethodPassword, captured.Method)
+	require.NotNil(t, captured.Success)
+	assert.False(t, *captured.Success)
+	require.NotNil(t, captured.From)
+	assert.Nil(t, captured.To)
+	assert.Equal(t, 21, captured.Limit)
+
+	var response struct {
+		Events []models.LoginEvent `json:"events"`
+		Total  int                 `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, models.LoginFailureInvalidCredentials, response.Events[0].FailureReason)
+}
+
+func TestAdminHandler_ListLoginEvents_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"userId=abc", "method=sso", "success=maybe", "to=tomorrow", "from=2024-01-10T00:00:00Z&to=2024-01-09T00:00:00Z", "limit=5000", "cursor=garbage"} {
+		t.Run(query, func(t *testing.T) {
+			handler, _ := setupAdminTest()
+			handler = handler.WithLoginEventRepo(repository.NewMockLoginEventRepository())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/logins?"+query, nil)
+
+			handler.ListLoginEvents(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestAdminHandler_ListLoginEvents_NotConfigured(t *testing.T) {
+	handler, _ := setupAdminTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/logins", nil)
+
+	handler.ListLoginEvents(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestAdminHandler_PurgeTelemetry(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		body          string
+		expectPlanned bool
+		expectPurged  bool
+	}{
+		{
+			name:          "dry run",
+			body:          `{"deviceId":"RACEBOX-001","from":"2024-01-01T00:00:00Z","to":"2024-02-01T00:00:00Z","dryRun":true}`,
+			expectPlanned: true,
+		},
+		{
+			name:         "purge",
+			body:         `{"from":"2024-01-01T01:00:00+01:00","to":"2024-02-01T00:00:00Z"}`,
+			expectPurged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupAdminTest()
+			purgeRepo := repository.NewMockTelemetryPurgeRepository()
+			handler = handler.WithTelemetryPurgeRepo(purgeRepo)
+
+			var planned, purged *repository.TelemetryPurgeFilter
+			purgeRepo.PlanFunc = func(_ context.Context, filter repository.TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+				planned = &filter
+				return &models.TelemetryPurge{DeviceID: filter.DeviceID, DryRun: true, ChunksTrimmed: 2, Rows: 1200}, nil
+			}
+			purgeRepo.PurgeFunc = func(_ context.Context, filter repository.TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+				purged = &filter
+				return &models.TelemetryPurge{ChunksDropped: 4, Rows: 90000, RowsEstimated: true}, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/telemetry/purge", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.PurgeTelemetry(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectPlanned, planned != nil)
+			assert.Equal(t, tt.expectPurged, purged != nil)
+
+			var response models.TelemetryPurge
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectPlanned {
+				assert.Equal(t, "RACEBOX-001", planned.DeviceID)
+				assert.True(t, response.DryRun)
+				assert.Equal(t, int64(1200), response.Rows)
+			}
+			if tt.expectPurged {
+				assert.Empty(t, purged.DeviceID)
+				assert.True(t, from.Equal(purged.From))
+				assert.Equal(t, time.UTC, purged.From.Location())
+				assert.True(t, to.Equal(purged.To))
+				assert.Equal(t, 4, response.ChunksDropped)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_PurgeTelemetry_InvalidRequest(t *testing.T) {
+	for _, body := range []string{
+		`{"to":"2024-02-01T00:00:00Z"}`,
+		`{"from":"2024-02-01T00:00:00Z","to":"2024-01-01T00:00:00Z"}`,
+		`{"from":"2024-01-01T00:00:00Z","to":"2024-01-01T00:00:00Z"}`,
+		`{"from":"yesterday","to":"2024-01-01T00:00:00Z"}`,
+	} {
+		t.Run(body, func(t *testing.T) {
+			handler, _ := setupAdminTest()
+			purgeRepo := repository.NewMockTelemetryPurgeRepository()
+			purgeRepo.PurgeFunc = func(_ context.Context, _ repository.TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+				t.Error("invalid requests must not purge")
+				return nil, nil
+			}
+			handler = handler.WithTelemetryPurgeRepo(purgeRepo)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/telemetry/purge", bytes.NewBufferString(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.PurgeTelemetry(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestAdminHandler_PurgeTelemetry_NotConfigured(t *testing.T) {
+	handler, _ := setupAdminTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/telemetry/purge",
+		bytes.NewBufferString(`{"from":"2024-01-01T00:00:00Z","to":"2024-02-01T00:00:00Z"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.PurgeTelemetry(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Maximum page size for alert event queries
+const maxAlertEventLimit = 1000
+
+// AlertRuleHandler handles speed and g-force alert rule management requests
+type AlertRuleHandler struct {
+	alertRepo repository.AlertRuleRepository
+}
+
+// NewAlertRuleHandler creates a new alert rule handler
+func NewAlertRuleHandler(alertRepo repository.AlertRuleRepository) *AlertRuleHandler {
+	return &AlertRuleHandler{
+		alertRepo: alertRepo,
+	}
+}
+
+// CreateAlertRuleRequest represents the alert rule creation request body
+type CreateAlertRuleRequest struct {
+	Name            string             `json:"name" binding:"required,max=255"`
+	DeviceID        string             `json:"deviceId" binding:"required,max=50"`
+	Metric          models.AlertMetric `json:"metric" binding:"required"`
+	Threshold       float64            `json:"threshold"`
+	CooldownSeconds *int               `json:"cooldownSeconds,omitempty"` // Defaults to 5 minutes
+	WebhookURL      *string            `json:"webhookUrl,omitempty"`
+}
+
+// UpdateAlertRuleRequest represents the alert rule update request body
+// Omitted fields keep their current values; an empty webhookUrl removes the webhook.
+type UpdateAlertRuleRequest struct {
+	Name            *string             `json:"name,omitempty" binding:"omitempty,max=255"`
+	DeviceID        *string             `json:"deviceId,omitempty" binding:"omitempty,max=50"`
+	Metric          *models.AlertMetric `json:"metric,omitempty"`
+	Threshold       *float64            `json:"threshold,omitempty"`
+	CooldownSeconds *int                `json:"cooldownSeconds,omitempty"`
+	WebhookURL      *string             `json:"webhookUrl,omitempty"`
+	IsActive        *bool               `json:"isActive,omitempty"`
+}
+
+// CreateAlertRule defines a new speed or g-force alert rule for one of the user's devices
+// POST /api/v1/alert-rules
+func (h *AlertRuleHandler) CreateAlertRule(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	name, deviceID := strings.TrimSpace(req.Name), strings.TrimSpace(req.DeviceID)
+	if name == "" || deviceID == "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "name and deviceId are required"))
+		return
+	}
+
+	cooldown := models.DefaultAlertCooldownSeconds
+	if req.CooldownSeconds != nil {
+		cooldown = *req.CooldownSeconds
+	}
+
+	now := time.Now().UTC()
+	rule := &models.AlertRule{
+		ID:              uuid.New(),
+		UserID:          userID,
+		DeviceID:        deviceID,
+		Name:            name,
+		Metric:          req.Metric,
+		Threshold:       req.Threshold,
+		CooldownSeconds: cooldown,
+		WebhookURL:      req.WebhookURL,
+		IsActive:        true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := rule.Validate(); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.alertRepo.Create(c.Request.Context(), rule); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create alert rule"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, rule)
+}
+
+// ListAlertRules retrieves the authenticated user's alert rules
+// GET /api/v1/alert-rules
+func (h *AlertRuleHandler) ListAlertRules(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	rules, err := h.alertRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve alert rules"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "rules", rules, api.Meta{
+		"total": len(rules),
+	})
+}
+
+// GetAlertRule retrieves one of the authenticated user's alert rules
+// GET /api/v1/alert-rules/:id
+func (h *AlertRuleHandler) GetAlertRule(c *gin.Context) {
+	rule, ok := h.ownedRule(c, middleware.MustGetUserID(c))
+	if !ok {
+		return
+	}
+
+	api.Respond(c, http.StatusOK, rule)
+}
+
+// UpdateAlertRule changes an alert rule's settings or pauses it
+// PATCH /api/v1/alert-rules/:id
+func (h *AlertRuleHandler) UpdateAlertRule(c *gin.Context) {
+	rule, ok := h.ownedRule(c, middleware.MustGetUserID(c))
+	if !ok {
+		return
+	}
+
+	var req UpdateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.DeviceID != nil {
+		rule.DeviceID = strings.TrimSpace(*req.DeviceID)
+	}
+	if rule.Name == "" || rule.DeviceID == "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "name and deviceId must not be empty"))
+		return
+	}
+	if req.Metric != nil {
+		rule.Metric = *req.Metric
+	}
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	if req.CooldownSeconds != nil {
+		rule.CooldownSeconds = *req.CooldownSeconds
+	}
+	if req.WebhookURL != nil {
+		rule.WebhookURL = req.WebhookURL
+		if *req.WebhookURL == "" {
+			rule.WebhookURL = nil
+		}
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := rule.Validate(); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.alertRepo.Update(c.Request.Context(), rule); err != nil {
+		if errors.Is(err, repository.ErrAlertRuleNotFound) {
+			problem.Abort(c, problem.NotFound("alert_rule_not_found", "Alert rule not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to update alert rule"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, rule)
+}
+
+// DeleteAlertRule removes an alert rule together with its alert history
+// DELETE /api/v1/alert-rules/:id
+func (h *AlertRuleHandler) DeleteAlertRule(c *gin.Context) {
+	rule, ok := h.ownedRule(c, middleware.MustGetUserID(c))
+	if !ok {
+		return
+	}
+
+	if err := h.alertRepo.Delete(c.Request.Context(), rule.ID); err != nil {
+		problem.Abort(c, problem.Internal("Failed to delete alert rule"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Alert rule deleted successfully",
+	})
+}
+
+// ListAlertEvents retrieves the alerts raised by the authenticated user's rules
+// GET /api/v1/alert-rules/events?ruleId=&deviceId=&limit=
+func (h *AlertRuleHandler) ListAlertEvents(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	filter := repository.AlertEventFilter{
+		UserID:   userID,
+		DeviceID: c.Query("deviceId"),
+	}
+
+	if raw := c.Query("ruleId"); raw != "" {
+		ruleID, err := uuid.Parse(raw)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_alert_rule_id", "Invalid alert rule ID format"))
+			return
+		}
+		filter.RuleID = &ruleID
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxAlertEventLimit {
+			problem.Abort(c, problem.BadRequest("invalid_request", "limit must be between 1 and "+strconv.Itoa(maxAlertEventLimit)))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := h.alertRepo.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve alert events"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "events", events, api.Meta{
+		"count": len(events),
+	})
+}
+
+// ownedRule loads the rule named by the id path parameter and checks that userID owns it
+// It writes the error response and returns false when the rule cannot be used.
+func (h *AlertRuleHandler) ownedRule(c *gin.Context, userID uuid.UUID) (*models.AlertRule, bool) {
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_alert_rule_id", "Invalid alert rule ID format"))
+		return nil, false
+	}
+
+	rule, err := h.alertRepo.GetByID(c.Request.Context(), ruleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAlertRuleNotFound) {
+			problem.Abort(c, problem.NotFound("alert_rule_not_found", "Alert rule not found"))
+			return nil, false
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve alert rule"))
+		return nil, false
+	}
+
+	if !rule.IsOwnedBy(userID) {
+		problem.Abort(c, problem.Forbidden("forbidden", "You do not have access to this alert rule"))
+		return nil, false
+	}
+
+	return rule, true
+}
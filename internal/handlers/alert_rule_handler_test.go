@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAlertRuleTest() (*AlertRuleHandler, *repository.MockAlertRuleRepository) {
+	alertRepo := repository.NewMockAlertRuleRepository()
+	handler := NewAlertRuleHandler(alertRepo)
+
+	gin.SetMode(gin.TestMode)
+
+	return handler, alertRepo
+}
+
+func TestAlertRuleHandler_CreateAlertRule(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedCooldown int
+	}{
+		{
+			name:             "speed with default cooldown",
+			body:             `{"name":" Speeding ","deviceId":"AVT-001","metric":"speed","threshold":180}`,
+			expectedStatus:   http.StatusCreated,
+			expectedCooldown: models.DefaultAlertCooldownSeconds,
+		},
+		{
+			name:             "lateral g with webhook",
+			body:             `{"name":"Speeding","deviceId":"AVT-001","metric":"lateral_g","threshold":1.3,"cooldownSeconds":0,"webhookUrl":"https://example.com/hooks/avt"}`,
+			expectedStatus:   http.StatusCreated,
+			expectedCooldown: 0,
+		},
+		{
+			name:           "unknown metric",
+			body:           `{"name":"Speeding","deviceId":"AVT-001","metric":"rpm","threshold":7000}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing device",
+			body:           `{"name":"Speeding","metric":"speed","threshold":180}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "threshold out of range",
+			body:           `{"name":"Speeding","deviceId":"AVT-001","metric":"g_force","threshold":50}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid webhook URL",
+			body:           `{"name":"Speeding","deviceId":"AVT-001","metric":"speed","threshold":180,"webhookUrl":"ftp://example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, alertRepo := setupAlertRuleTest()
+			userID := uuid.New()
+
+			var created *models.AlertRule
+			alertRepo.CreateFunc = func(_ context.Context, rule *models.AlertRule) error {
+				created = rule
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/alert-rules", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.CreateAlertRule(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, created)
+				return
+			}
+
+			require.NotNil(t, created)
+			assert.Equal(t, userID, created.UserID)
+			assert.Equal(t, "Speeding", created.Name)
+			assert.Equal(t, "AVT-001", created.DeviceID)
+			assert.Equal(t, tt.expectedCooldown, created.CooldownSeconds)
+			assert.True(t, created.IsActive)
+
+			var response models.AlertRule
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, created.ID, response.ID)
+		})
+	}
+}
+
+func TestAlertRuleHandler_ListAlertRules(t *testing.T) {
+	handler, alertRepo := setupAlertRuleTest()
+	userID := uuid.New()
+
+	alertRepo.ListByUserIDFunc = func(_ context.Context, id uuid.UUID) ([]*models.AlertRule, error) {
+		assert.Equal(t, userID, id)
+		return []*models.AlertRule{{ID: uuid.New(), UserID: userID, Name: "Speeding", Metric: models.AlertMetricSpeed}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/alert-rules", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListAlertRules(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Rules []models.AlertRule `json:"rules"`
+		Total int                `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, "Speeding", response.Rules[0].Name)
+}
+
+func TestAlertRuleHandler_UpdateAlertRule(t *testing.T) {
+	ownerID := uuid.New()
+	ruleID := uuid.New()
+	webhook := "https://example.com/hooks/avt"
+
+	tests := []struct {
+		name           string
+		param          string
+		userID         uuid.UUID
+		body           string
+		expectedStatus int
+	}{
+		{"pause and clear webhook", ruleID.String(), ownerID, `{"isActive":false,"threshold":200,"webhookUrl":""}`, http.StatusOK},
+		{"invalid threshold", ruleID.String(), ownerID, `{"threshold":-1}`, http.StatusBadRequest},
+		{"blank name", ruleID.String(), ownerID, `{"name":" "}`, http.StatusBadRequest},
+		{"invalid id", "not-a-uuid", ownerID, `{}`, http.StatusBadRequest},
+		{"not found", uuid.New().String(), ownerID, `{}`, http.StatusNotFound},
+		{"other user's rule", ruleID.String(), uuid.New(), `{}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, alertRepo := setupAlertRuleTest()
+			alertRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.AlertRule, error) {
+				if id != ruleID {
+					return nil, repository.ErrAlertRuleNotFound
+				}
+				return &models.AlertRule{
+					ID: ruleID, UserID: ownerID, DeviceID: "AVT-001", Name: "Speeding",
+					Metric: models.AlertMetricSpeed, Threshold: 180, WebhookURL: &webhook, IsActive: true,
+				}, nil
+			}
+			var updated *models.AlertRule
+			alertRepo.UpdateFunc = func(_ context.Context, rule *models.AlertRule) error {
+				updated = rule
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/alert-rules/"+tt.param, bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: tt.param}}
+			c.Set(string(middleware.UserIDKey), tt.userID)
+
+			handler.UpdateAlertRule(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Nil(t, updated)
+				return
+			}
+
+			require.NotNil(t, updated)
+			assert.False(t, updated.IsActive)
+			assert.Equal(t, 200.0, updated.Threshold)
+			assert.Nil(t, updated.WebhookURL)
+			assert.Equal(t, "Speeding", updated.Name, "omitted fields are kept")
+		})
+	}
+}
+
+func TestAlertRuleHandler_DeleteAlertRule(t *testing.T) {
+	ownerID := uuid.New()
+	ruleID := uuid.New()
+
+	tests := []struct {
+		name           string
+		param          string
+		userID         uuid.UUID
+		expectedStatus int
+	}{
+		{"success", ruleID.String(), ownerID, http.StatusOK},
+		{"not found", uuid.New().String(), ownerID, http.StatusNotFound},
+		{"other user's rule", ruleID.String(), uuid.New(), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, alertRepo := setupAlertRuleTest()
+			alertRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.AlertRule, error) {
+				if id != ruleID {
+					return nil, repository.ErrAlertRuleNotFound
+				}
+				return &models.AlertRule{ID: ruleID, UserID: ownerID}, nil
+			}
+			deleted := false
+			alertRepo.DeleteFunc = func(_ context.Context, id uuid.UUID) error {
+				assert.Equal(t, ruleID, id)
+				deleted = true
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/alert-rules/"+tt.param, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.param}}
+			c.Set(string(middleware.UserIDKey), tt.userID)
+
+			handler.DeleteAlertRule(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, deleted)
+		})
+	}
+}
+
+func TestAlertRuleHandler_ListAlertEvents(t *testing.T) {
+	handler, alertRepo := setupAlertRuleTest()
+	userID := uuid.New()
+	ruleID := uuid.New()
+
+	alertRepo.ListEventsFunc = func(_ context.Context, filter repository.AlertEventFilter) ([]*models.AlertEvent, error) {
+		assert.Equal(t, userID, filter.UserID)
+		require.NotNil(t, filter.RuleID)
+		assert.Equal(t, ruleID, *filter.RuleID)
+		assert.Equal(t, "AVT-001", filter.DeviceID)
+		assert.Equal(t, 50, filter.Limit)
+		return []*models.AlertEvent{{ID: 1, RuleID: ruleID, Metric: models.AlertMetricSpeed, Value: 192}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/alert-rules/events?ruleId="+ruleID.String()+"&deviceId=AVT-001&limit=50", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListAlertEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Events []models.AlertEvent `json:"events"`
+		Count  int                 `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, 192.0, response.Events[0].Value)
+}
+
+func TestAlertRuleHandler_ListAlertEvents_InvalidParams(t *testing.T) {
+	for _, query := range []string{"ruleId=nope", "limit=0", "limit=5000"} {
+		t.Run(query, func(t *testing.T) {
+			handler, _ := setupAlertRuleTest()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/alert-rules/events?"+query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.ListAlertEvents(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -1,31 +1,130 @@
 package handlers
 
 import (
+	"context"
 	"errors"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
 	"github.com/sebasr/avt-service/internal/auth"
 	"github.com/sebasr/avt-service/internal/email"
 	"github.com/sebasr/avt-service/internal/middleware"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/notify"
+	"github.com/sebasr/avt-service/internal/problem"
 	"github.com/sebasr/avt-service/internal/repository"
 )
 
 // Default reset token TTL (12 hours)
 const defaultResetTokenTTL = 12 * time.Hour
 
+// Default magic link TTL (15 minutes)
+const defaultMagicLinkTTL = 15 * time.Minute
+
+// InstallationIDHeader carries the app-generated installation ID refresh tokens can be bound to
+const InstallationIDHeader = "X-Installation-ID"
+
+// LockoutPolicy configures failed login tracking and temporary account lockout
+type LockoutPolicy struct {
+	MaxAttempts      int           // Failed logins within Window that lock the account
+	MaxAttemptsPerIP int           // Failed logins within Window after which an IP is throttled
+	Window           time.Duration // Sliding window failed attempts are counted over
+	Duration         time.Duration // How long an account stays locked
+}
+
+// SessionPolicy configures how long refresh tokens keep a client signed in
+// Each refresh issues a token valid for the sliding lifetime from now, but never past MaxAge
+// after the client logged in.
+type SessionPolicy struct {
+	TTL           time.Duration // Sliding lifetime of refresh tokens
+	RememberMeTTL time.Duration // Sliding lifetime for logins with rememberMe; zero uses TTL
+	MaxAge        time.Duration // Absolute cap on a session from login; zero disables
+
+	// Binding binds refresh tokens to the client they were issued to: "off" (or empty), "optional"
+	// for clients that send an installation ID, or "required" for every client
+	Binding string
+}
+
+// expiresAt returns when a refresh token issued now for a session started at startedAt expires
+func (p SessionPolicy) expiresAt(now, startedAt time.Time, rememberMe bool) time.Time {
+	ttl := p.TTL
+	if rememberMe && p.RememberMeTTL > 0 {
+		ttl = p.RememberMeTTL
+	}
+	expiresAt := now.Add(ttl)
+	if p.MaxAge > 0 {
+		if limit := startedAt.Add(p.MaxAge); expiresAt.After(limit) {
+			expiresAt = limit
+		}
+	}
+	return expiresAt
+}
+
+// fingerprint returns the fingerprint a refresh token issued to the client is bound to, or "" to leave it unbound
+func (p SessionPolicy) fingerprint(c *gin.Context) string {
+	installationID := strings.TrimSpace(c.GetHeader(InstallationIDHeader))
+	switch p.Binding {
+	case "required":
+	case "optional":
+		if installationID == "" {
+			return ""
+		}
+	default:
+		return ""
+	}
+	return auth.ClientFingerprint(c.Request.UserAgent(), installationID)
+}
+
+// allowsRefresh reports whether the client may refresh the token
+// Tokens issued unbound, e.g. before binding was enabled, may be refreshed by any client and are
+// bound when they are rotated.
+func (p SessionPolicy) allowsRefresh(c *gin.Context, token *models.RefreshToken) bool {
+	if p.Binding == "" || p.Binding == "off" || token.ClientFingerprint == "" {
+		return true
+	}
+	installationID := strings.TrimSpace(c.GetHeader(InstallationIDHeader))
+	return token.ClientFingerprint == auth.ClientFingerprint(c.Request.UserAgent(), installationID)
+}
+
+// GeoIPProvider looks up the coarse location of a client address
+// Lookup returns nil without an error for addresses it does not cover.
+type GeoIPProvider interface {
+	Lookup(ctx context.Context, ip string) (*models.GeoLocation, error)
+}
+
+// Notifier stores a notification in its user's inbox and delivers it over the channels they chose
+type Notifier interface {
+	Notify(ctx context.Context, notification *models.Notification) error
+}
+
 // AuthHandler handles authentication-related requests
 type AuthHandler struct {
 	userRepo         repository.UserRepository
 	refreshTokenRepo repository.RefreshTokenRepository
+	loginAttempts    repository.LoginAttemptRepository // Optional: nil disables lockout and throttling
+	loginEvents      repository.LoginEventRepository   // Optional: nil disables the login audit trail
+	lockout          LockoutPolicy
+	sessions         SessionPolicy
 	jwtService       *auth.JWTService
+	denylist         *auth.Denylist // Optional: nil leaves access tokens valid until they expire
+	geoip            GeoIPProvider  // Optional: nil stores sessions without a location and disables login alerts
 	emailService     email.Service
+	notifier         Notifier             // Optional: nil emails login alerts directly
+	events           EventPublisher       // Optional: when set, logins are published and login alerts left to subscribers
+	passwordPolicy   *auth.PasswordPolicy // Optional: nil only enforces the 8-72 character length
+	oidcProvider     OIDCProvider         // Optional: nil disables single sign-on
+	identityRepo     repository.UserIdentityRepository
+	oidcRoles        map[string]models.Role // Optional: group to role mapping applied on each single sign-on
 	resetTokenTTL    time.Duration
+	magicLinkTTL     time.Duration
 }
 
 // NewAuthHandler creates a new auth handler
@@ -37,8 +136,10 @@ func NewAuthHandler(
 	return &AuthHandler{
 		userRepo:         userRepo,
 		refreshTokenRepo: refreshTokenRepo,
+		sessions:         SessionPolicy{TTL: jwtService.GetRefreshTokenTTL()},
 		jwtService:       jwtService,
 		resetTokenTTL:    defaultResetTokenTTL,
+		magicLinkTTL:     defaultMagicLinkTTL,
 	}
 }
 
@@ -48,12 +149,68 @@ func (h *AuthHandler) WithEmailService(emailService email.Service) *AuthHandler
 	return h
 }
 
+// WithNotifier sets the notifier used for login alerts instead of emailing them directly
+func (h *AuthHandler) WithNotifier(notifier Notifier) *AuthHandler {
+	h.notifier = notifier
+	return h
+}
+
+// WithEvents publishes a user.logged_in event for every login
+// Alerts about logins from a new country are then left to the event's subscribers.
+func (h *AuthHandler) WithEvents(publisher EventPublisher) *AuthHandler {
+	h.events = publisher
+	return h
+}
+
 // WithResetTokenTTL sets the reset token TTL
 func (h *AuthHandler) WithResetTokenTTL(ttl time.Duration) *AuthHandler {
 	h.resetTokenTTL = ttl
 	return h
 }
 
+// WithMagicLinkTTL sets how long emailed sign-in links stay valid
+func (h *AuthHandler) WithMagicLinkTTL(ttl time.Duration) *AuthHandler {
+	h.magicLinkTTL = ttl
+	return h
+}
+
+// WithPasswordPolicy sets the policy new passwords are checked against
+func (h *AuthHandler) WithPasswordPolicy(policy *auth.PasswordPolicy) *AuthHandler {
+	h.passwordPolicy = policy
+	return h
+}
+
+// WithLockout enables failed login tracking with temporary account lockout and per-IP throttling
+func (h *AuthHandler) WithLockout(repo repository.LoginAttemptRepository, policy LockoutPolicy) *AuthHandler {
+	h.loginAttempts = repo
+	h.lockout = policy
+	return h
+}
+
+// WithLoginEvents records every login attempt in the login audit trail
+func (h *AuthHandler) WithLoginEvents(repo repository.LoginEventRepository) *AuthHandler {
+	h.loginEvents = repo
+	return h
+}
+
+// WithDenylist revokes outstanding access tokens on logout and password reset
+func (h *AuthHandler) WithDenylist(denylist *auth.Denylist) *AuthHandler {
+	h.denylist = denylist
+	return h
+}
+
+// WithGeoIP records the location of each session and emails users about logins from new countries
+func (h *AuthHandler) WithGeoIP(provider GeoIPProvider) *AuthHandler {
+	h.geoip = provider
+	return h
+}
+
+// WithSessionPolicy sets the lifetime of refresh tokens, including remember-me logins and sliding expiration
+func (h *AuthHandler) WithSessionPolicy(policy SessionPolicy) *AuthHandler {
+	h.sessions = policy
+	return h
+}
+
 // RegisterRequest represents the registration request body
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -62,8 +219,9 @@ type RegisterRequest struct {
 
 // LoginRequest represents the login request body
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"`
+	RememberMe bool   `json:"rememberMe"` // Issue a longer-lived refresh token
 }
 
 // RefreshTokenRequest represents the token refresh request body
@@ -102,33 +260,29 @@ type UserInfo struct {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request body: " + err.Error(),
-		})
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
 		return
 	}
 
 	// Normalize email
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 
+	// Enforce password policy
+	if rejectWeakPassword(c, h.passwordPolicy, req.Password) {
+		return
+	}
+
 	// Check if user already exists
 	existingUser, err := h.userRepo.GetByEmail(c.Request.Context(), email)
 	if err == nil && existingUser != nil {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":   "user_exists",
-			"message": "A user with this email already exists",
-		})
+		problem.Abort(c, problem.Conflict("user_exists", "A user with this email already exists"))
 		return
 	}
 
 	// Hash password
 	passwordHash, err := auth.HashPassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to process registration",
-		})
+		problem.Abort(c, problem.Internal("Failed to process registration"))
 		return
 	}
 
@@ -140,63 +294,54 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 		IsActive:     true,
+		Role:         models.RoleUser,
 	}
 
 	if err := h.userRepo.Create(c.Request.Context(), user); err != nil {
 		if errors.Is(err, repository.ErrUserExists) {
-			c.JSON(http.StatusConflict, gin.H{
-				"error":   "user_exists",
-				"message": "A user with this email already exists",
-			})
+			problem.Abort(c, problem.Conflict("user_exists", "A user with this email already exists"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to create user",
-		})
+		problem.Abort(c, problem.Internal("Failed to create user"))
 		return
 	}
 
 	// Generate tokens
-	accessToken, err := h.jwtService.GenerateAccessToken(user.ID, user.Email)
+	accessToken, err := h.jwtService.GenerateAccessToken(user.ID, user.Email, string(user.Role), accessTokenScopes(user.Role)...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to generate access token",
-		})
+		problem.Abort(c, problem.Internal("Failed to generate access token"))
 		return
 	}
 
-	refreshTokenString, expiresAt, err := h.jwtService.GenerateRefreshToken(user.ID, user.Email)
+	now := time.Now()
+	expiresAt := h.sessions.expiresAt(now, now, false)
+	refreshTokenString, err := h.jwtService.GenerateRefreshTokenUntil(user.ID, user.Email, expiresAt)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to generate refresh token",
-		})
+		problem.Abort(c, problem.Internal("Failed to generate refresh token"))
 		return
 	}
 
 	// Store refresh token
 	refreshToken := &models.RefreshToken{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		TokenHash: auth.HashToken(refreshTokenString),
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now(),
-		UserAgent: c.Request.UserAgent(),
-		IPAddress: c.ClientIP(),
+		ID:                uuid.New(),
+		UserID:            user.ID,
+		TokenHash:         auth.HashToken(refreshTokenString),
+		ExpiresAt:         expiresAt,
+		CreatedAt:         now,
+		UserAgent:         c.Request.UserAgent(),
+		IPAddress:         c.ClientIP(),
+		ClientFingerprint: h.sessions.fingerprint(c),
+		Location:          h.locate(c),
+		SessionStartedAt:  now,
 	}
 
 	if err := h.refreshTokenRepo.Create(c.Request.Context(), refreshToken); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to create session",
-		})
+		problem.Abort(c, problem.Internal("Failed to create session"))
 		return
 	}
 
 	// Return tokens
-	c.JSON(http.StatusCreated, AuthResponse{
+	api.Respond(c, http.StatusCreated, AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshTokenString,
 		ExpiresAt:    expiresAt,
@@ -213,94 +358,146 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request body: " + err.Error(),
-		})
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
 		return
 	}
 
 	// Normalize email
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 
+	// Throttle addresses with too many recent failures
+	if h.rejectThrottledIP(c) {
+		h.recordLogin(c, nil, email, models.LoginMethodPassword, models.LoginFailureThrottled)
+		return
+	}
+
 	// Get user
 	user, err := h.userRepo.GetByEmail(c.Request.Context(), email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "invalid_credentials",
-				"message": "Invalid email or password",
-			})
+			h.recordLoginFailure(c, nil, email)
+			h.recordLogin(c, nil, email, models.LoginMethodPassword, models.LoginFailureUnknownAccount)
+			problem.Abort(c, problem.Unauthorized("invalid_credentials", "Invalid email or password"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to authenticate",
-		})
+		problem.Abort(c, problem.Internal("Failed to authenticate"))
 		return
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "account_disabled",
-			"message": "This account has been disabled",
-		})
+		h.recordLogin(c, user, email, models.LoginMethodPassword, models.LoginFailureAccountDisabled)
+		problem.Abort(c, problem.Forbidden("account_disabled", "This account has been disabled"))
 		return
 	}
 
+	// Reject locked accounts before checking the password
+	if h.loginAttempts != nil {
+		lockedUntil, err := h.loginAttempts.GetLockedUntil(c.Request.Context(), user.ID)
+		if err != nil {
+			slog.Error("Error checking account lock", "error", err)
+		} else if lockedUntil != nil {
+			h.recordLogin(c, user, email, models.LoginMethodPassword, models.LoginFailureAccountLocked)
+			respondAccountLocked(c, *lockedUntil)
+			return
+		}
+	}
+
 	// Verify password
 	if !auth.VerifyPassword(req.Password, user.PasswordHash) {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "invalid_credentials",
-			"message": "Invalid email or password",
-		})
+		h.recordLogin(c, user, email, models.LoginMethodPassword, models.LoginFailureInvalidCredentials)
+		if lockedUntil := h.recordLoginFailure(c, user, email); lockedUntil != nil {
+			respondAccountLocked(c, *lockedUntil)
+			return
+		}
+		problem.Abort(c, problem.Unauthorized("invalid_credentials", "Invalid email or password"))
 		return
 	}
 
+	// Clear failed attempts
+	if h.loginAttempts != nil {
+		if err := h.loginAttempts.Reset(c.Request.Context(), user.ID); err != nil {
+			slog.Error("Error clearing failed login attempts", "error", err)
+			// Non-critical, continue
+		}
+	}
+
+	h.issueSession(c, user, req.RememberMe, models.LoginMethodPassword)
+}
+
+// accessTokenScopes returns the scopes embedded in the access tokens of users with the given role
+func accessTokenScopes(role models.Role) []string {
+	roleScopes := models.ScopesForRole(role)
+	scopes := make([]string, len(roleScopes))
+	for i, scope := range roleScopes {
+		scopes[i] = string(scope)
+	}
+	return scopes
+}
+
+// issueSession signs the user in, storing a new session and responding with its tokens
+func (h *AuthHandler) issueSession(c *gin.Context, user *models.User, rememberMe bool, method models.LoginMethod) {
 	// Update last login (non-blocking)
 	_ = h.userRepo.UpdateLastLogin(c.Request.Context(), user.ID)
 
 	// Generate tokens
-	accessToken, err := h.jwtService.GenerateAccessToken(user.ID, user.Email)
+	accessToken, err := h.jwtService.GenerateAccessToken(user.ID, user.Email, string(user.Role), accessTokenScopes(user.Role)...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to generate access token",
-		})
+		problem.Abort(c, problem.Internal("Failed to generate access token"))
 		return
 	}
 
-	refreshTokenString, expiresAt, err := h.jwtService.GenerateRefreshToken(user.ID, user.Email)
+	now := time.Now()
+	expiresAt := h.sessions.expiresAt(now, now, rememberMe)
+	refreshTokenString, err := h.jwtService.GenerateRefreshTokenUntil(user.ID, user.Email, expiresAt)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to generate refresh token",
-		})
+		problem.Abort(c, problem.Internal("Failed to generate refresh token"))
 		return
 	}
 
 	// Store refresh token
 	refreshToken := &models.RefreshToken{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		TokenHash: auth.HashToken(refreshTokenString),
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now(),
-		UserAgent: c.Request.UserAgent(),
-		IPAddress: c.ClientIP(),
+		ID:                uuid.New(),
+		UserID:            user.ID,
+		TokenHash:         auth.HashToken(refreshTokenString),
+		ExpiresAt:         expiresAt,
+		CreatedAt:         now,
+		UserAgent:         c.Request.UserAgent(),
+		IPAddress:         c.ClientIP(),
+		ClientFingerprint: h.sessions.fingerprint(c),
+		Location:          h.locate(c),
+		RememberMe:        rememberMe,
+		SessionStartedAt:  now,
+	}
+
+	// Compare with the countries of earlier sessions before this one is stored
+	newCountry := h.isNewCountry(c, user.ID, refreshToken.Location)
+
+	login := &models.UserLoggedIn{
+		UserID:     user.ID,
+		IPAddress:  refreshToken.IPAddress,
+		UserAgent:  refreshToken.UserAgent,
+		Location:   refreshToken.Location,
+		NewCountry: newCountry,
+		LoggedInAt: now,
 	}
 
-	if err := h.refreshTokenRepo.Create(c.Request.Context(), refreshToken); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to create session",
-		})
+	// The user.logged_in event is stored with the session
+	ctx := withEvent[*models.RefreshToken](c.Request.Context(), h.events, models.EventUserLoggedIn, login)
+	if err := h.refreshTokenRepo.Create(ctx, refreshToken); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create session"))
 		return
 	}
+	h.recordLogin(c, user, user.Email, method, "")
+
+	if h.events != nil {
+		notifyEvents(h.events)
+	} else if newCountry {
+		h.sendLoginAlert(c, user, login)
+	}
 
 	// Return tokens
-	c.JSON(http.StatusOK, AuthResponse{
+	api.Respond(c, http.StatusOK, AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshTokenString,
 		ExpiresAt:    expiresAt,
@@ -312,25 +509,181 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
+// locate looks up the location of the client, or returns nil when it is unknown
+func (h *AuthHandler) locate(c *gin.Context) *models.GeoLocation {
+	if h.geoip == nil {
+		return nil
+	}
+
+	location, err := h.geoip.Lookup(c.Request.Context(), c.ClientIP())
+	if err != nil {
+		slog.Warn("Failed to look up client location", "error", err)
+		return nil
+	}
+	return location
+}
+
+// isNewCountry reports whether a login from location should be reported to the user
+// Only logins from a country none of the user's stored sessions came from are reported. An account
+// without located sessions has nothing to compare with, so its first located login is not.
+func (h *AuthHandler) isNewCountry(c *gin.Context, userID uuid.UUID, location *models.GeoLocation) bool {
+	if location == nil || (h.emailService == nil && h.notifier == nil && h.events == nil) {
+		return false
+	}
+
+	countries, err := h.refreshTokenRepo.ListCountries(c.Request.Context(), userID)
+	if err != nil {
+		slog.Error("Error listing login countries", "error", err)
+		return false
+	}
+	return len(countries) > 0 && !slices.Contains(countries, location.CountryCode)
+}
+
+// sendLoginAlert notifies the user about a login from a new country
+func (h *AuthHandler) sendLoginAlert(c *gin.Context, user *models.User, login *models.UserLoggedIn) {
+	if h.notifier != nil {
+		if err := h.notifier.Notify(c.Request.Context(), notify.NewLogin(login)); err != nil {
+			slog.Error("Error sending new login notification", "error", err)
+			// Non-critical, continue
+		}
+		return
+	}
+
+	alert := email.LoginAlert{
+		Location:   login.Location.String(),
+		IPAddress:  login.IPAddress,
+		UserAgent:  login.UserAgent,
+		LoggedInAt: login.LoggedInAt,
+	}
+	if err := h.emailService.SendNewLoginLocationEmail(c.Request.Context(), user.Email, alert); err != nil {
+		slog.Error("Error sending new login location email", "error", err)
+		// Non-critical, continue
+	}
+}
+
+// recordLogin appends a login attempt to the audit trail; an empty failureReason records a success
+// Errors are logged and do not affect the login.
+func (h *AuthHandler) recordLogin(c *gin.Context, user *models.User, email string, method models.LoginMethod, failureReason string) {
+	if h.loginEvents == nil {
+		return
+	}
+
+	event := &models.LoginEvent{
+		Email:         email,
+		Method:        method,
+		Success:       failureReason == "",
+		FailureReason: failureReason,
+		IPAddress:     c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		CreatedAt:     time.Now(),
+	}
+	if user != nil {
+		event.UserID = &user.ID
+	}
+
+	if err := h.loginEvents.Record(c.Request.Context(), event); err != nil {
+		slog.Error("Error recording login event", "error", err)
+	}
+}
+
+// rejectThrottledIP responds with 429 if the client's address has too many recent failed logins
+func (h *AuthHandler) rejectThrottledIP(c *gin.Context) bool {
+	if h.loginAttempts == nil {
+		return false
+	}
+
+	failures, err := h.loginAttempts.CountFailuresForIP(c.Request.Context(), c.ClientIP(), time.Now().Add(-h.lockout.Window))
+	if err != nil {
+		slog.Error("Error counting failed logins for IP", "error", err)
+		return false
+	}
+	if failures < h.lockout.MaxAttemptsPerIP {
+		return false
+	}
+
+	retryAfter := strconv.Itoa(int(h.lockout.Window.Seconds()))
+	c.Header("Retry-After", retryAfter)
+	problem.Abort(c, problem.TooManyRequests("too_many_failed_logins", "Too many failed login attempts from this address, retry after "+retryAfter+" seconds"))
+	return true
+}
+
+// recordLoginFailure stores a failed login and locks the account once it reaches the limit
+// It returns the lock expiry when this failure locked the account, nil otherwise.
+func (h *AuthHandler) recordLoginFailure(c *gin.Context, user *models.User, email string) *time.Time {
+	if h.loginAttempts == nil {
+		return nil
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now()
+	attempt := &models.LoginAttempt{
+		Email:       email,
+		IPAddress:   c.ClientIP(),
+		AttemptedAt: now,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+
+	if err := h.loginAttempts.RecordFailure(ctx, attempt); err != nil {
+		slog.Error("Error recording failed login", "error", err)
+		return nil
+	}
+	if user == nil {
+		return nil
+	}
+
+	failures, err := h.loginAttempts.CountFailuresForUser(ctx, user.ID, now.Add(-h.lockout.Window))
+	if err != nil {
+		slog.Error("Error counting failed logins", "error", err)
+		return nil
+	}
+	if failures < h.lockout.MaxAttempts {
+		return nil
+	}
+
+	lockedUntil := now.Add(h.lockout.Duration)
+	if err := h.loginAttempts.Lock(ctx, user.ID, lockedUntil); err != nil {
+		slog.Error("Error locking account", "error", err)
+		return nil
+	}
+
+	// Notify the owner so they can react if it wasn't them
+	if h.emailService != nil {
+		if err := h.emailService.SendAccountLockedEmail(ctx, user.Email, attempt.IPAddress, lockedUntil); err != nil {
+			slog.Error("Error sending account locked email", "error", err)
+			// Non-critical, continue
+		}
+	}
+
+	return &lockedUntil
+}
+
+// respondAccountLocked responds with 423 and when the account can be used again
+func respondAccountLocked(c *gin.Context, lockedUntil time.Time) {
+	retryAfter := int(math.Ceil(time.Until(lockedUntil).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	problem.Abort(c, problem.New(http.StatusLocked, "account_locked", "Account temporarily locked after too many failed login attempts").
+		With("lockedUntil", lockedUntil.UTC()).
+		With("retryAfter", retryAfter))
+}
+
 // RefreshToken handles token refresh
 // POST /api/v1/auth/refresh
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request body: " + err.Error(),
-		})
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
 		return
 	}
 
 	// Validate the refresh token
-	claims, err := h.jwtService.ValidateToken(req.RefreshToken)
+	claims, err := h.jwtService.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "invalid_token",
-			"message": "Invalid or expired refresh token",
-		})
+		problem.Abort(c, problem.Unauthorized("invalid_token", "Invalid or expired refresh token"))
 		return
 	}
 
@@ -339,63 +692,56 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	storedToken, err := h.refreshTokenRepo.GetByHash(c.Request.Context(), tokenHash)
 	if err != nil {
 		if errors.Is(err, repository.ErrRefreshTokenNotFound) || errors.Is(err, repository.ErrRefreshTokenRevoked) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "invalid_token",
-				"message": "Invalid or revoked refresh token",
-			})
+			problem.Abort(c, problem.Unauthorized("invalid_token", "Invalid or revoked refresh token"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to validate token",
-		})
+		problem.Abort(c, problem.Internal("Failed to validate token"))
+		return
+	}
+
+	// A token bound to another client was most likely copied off the device it was issued to
+	if !h.sessions.allowsRefresh(c, storedToken) {
+		slog.Warn("Refresh token used by a different client", "user_id", storedToken.UserID, "token_id", storedToken.ID, "ip", c.ClientIP())
+		problem.Abort(c, problem.Unauthorized("client_mismatch", "Refresh token was issued to a different client"))
 		return
 	}
 
 	// Parse user ID from claims
 	userID, err := uuid.Parse(claims.UserID)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "invalid_token",
-			"message": "Invalid user ID in token",
-		})
+		problem.Abort(c, problem.Unauthorized("invalid_token", "Invalid user ID in token"))
 		return
 	}
 
 	// Get user to ensure they still exist and are active
 	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "invalid_token",
-			"message": "User not found",
-		})
+		problem.Abort(c, problem.Unauthorized("invalid_token", "User not found"))
 		return
 	}
 
 	if !user.IsActive {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "account_disabled",
-			"message": "This account has been disabled",
-		})
+		problem.Abort(c, problem.Forbidden("account_disabled", "This account has been disabled"))
 		return
 	}
 
 	// Generate new tokens
-	newAccessToken, err := h.jwtService.GenerateAccessToken(user.ID, user.Email)
+	newAccessToken, err := h.jwtService.GenerateAccessToken(user.ID, user.Email, string(user.Role), accessTokenScopes(user.Role)...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to generate access token",
-		})
+		problem.Abort(c, problem.Internal("Failed to generate access token"))
 		return
 	}
 
-	newRefreshTokenString, expiresAt, err := h.jwtService.GenerateRefreshToken(user.ID, user.Email)
+	// Sliding expiration: the new token extends the session, up to its cap counted from login
+	now := time.Now()
+	sessionStartedAt := storedToken.SessionStartedAt
+	if sessionStartedAt.IsZero() {
+		sessionStartedAt = storedToken.CreatedAt
+	}
+	expiresAt := h.sessions.expiresAt(now, sessionStartedAt, storedToken.RememberMe)
+	newRefreshTokenString, err := h.jwtService.GenerateRefreshTokenUntil(user.ID, user.Email, expiresAt)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to generate refresh token",
-		})
+		problem.Abort(c, problem.Internal("Failed to generate refresh token"))
 		return
 	}
 
@@ -404,26 +750,27 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 	// Store new refresh token
 	newRefreshToken := &models.RefreshToken{
-		ID:         uuid.New(),
-		UserID:     user.ID,
-		TokenHash:  auth.HashToken(newRefreshTokenString),
-		ExpiresAt:  expiresAt,
-		CreatedAt:  time.Now(),
-		ReplacedBy: &storedToken.ID,
-		UserAgent:  c.Request.UserAgent(),
-		IPAddress:  c.ClientIP(),
+		ID:                uuid.New(),
+		UserID:            user.ID,
+		TokenHash:         auth.HashToken(newRefreshTokenString),
+		ExpiresAt:         expiresAt,
+		CreatedAt:         now,
+		ReplacedBy:        &storedToken.ID,
+		UserAgent:         c.Request.UserAgent(),
+		IPAddress:         c.ClientIP(),
+		ClientFingerprint: h.sessions.fingerprint(c),
+		Location:          h.locate(c),
+		RememberMe:        storedToken.RememberMe,
+		SessionStartedAt:  sessionStartedAt,
 	}
 
 	if err := h.refreshTokenRepo.Create(c.Request.Context(), newRefreshToken); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to create session",
-		})
+		problem.Abort(c, problem.Internal("Failed to create session"))
 		return
 	}
 
 	// Return new tokens
-	c.JSON(http.StatusOK, AuthResponse{
+	api.Respond(c, http.StatusOK, AuthResponse{
 		AccessToken:  newAccessToken,
 		RefreshToken: newRefreshTokenString,
 		ExpiresAt:    expiresAt,
@@ -441,23 +788,28 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID, err := middleware.GetUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "unauthorized",
-			"message": "Not authenticated",
-		})
+		problem.Abort(c, problem.Unauthorized("unauthorized", "Not authenticated"))
 		return
 	}
 
 	// Revoke all refresh tokens for this user
 	if err := h.refreshTokenRepo.RevokeAllForUser(c.Request.Context(), userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to logout",
-		})
+		problem.Abort(c, problem.Internal("Failed to logout"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	// Revoke this access token and any other issued to the user, so none outlives the logout
+	if claims := middleware.GetTokenClaims(c); claims != nil {
+		if err := h.denylist.RevokeToken(c.Request.Context(), claims); err != nil {
+			slog.Error("Error revoking access token on logout", "error", err)
+		}
+	}
+	if err := h.denylist.RevokeUser(c.Request.Context(), userID); err != nil {
+		slog.Error("Error revoking access tokens on logout", "error", err)
+		// Non-critical, access tokens expire on their own
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
 		"message": "Successfully logged out",
 	})
 }
@@ -467,10 +819,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	var req ForgotPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request body: " + err.Error(),
-		})
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
 		return
 	}
 
@@ -480,14 +829,14 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	// Always return success to prevent email enumeration attacks
 	// We do the work asynchronously or just silently fail
 	defer func() {
-		c.JSON(http.StatusOK, gin.H{
+		api.Respond(c, http.StatusOK, gin.H{
 			"message": "If an account with that email exists, a password reset link has been sent",
 		})
 	}()
 
 	// Check if email service is configured
 	if h.emailService == nil {
-		log.Printf("Warning: Email service not configured, skipping password reset email for %s", emailAddr)
+		slog.Warn("Email service not configured, skipping password reset email", "email", emailAddr)
 		return
 	}
 
@@ -498,7 +847,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 			// User not found - return success anyway to prevent enumeration
 			return
 		}
-		log.Printf("Error looking up user for password reset: %v", err)
+		slog.Error("Error looking up user for password reset", "error", err)
 		return
 	}
 
@@ -510,7 +859,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	// Generate secure reset token
 	resetToken, err := auth.GenerateSecureToken()
 	if err != nil {
-		log.Printf("Error generating reset token: %v", err)
+		slog.Error("Error generating reset token", "error", err)
 		return
 	}
 
@@ -522,13 +871,13 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 
 	// Store the hashed token
 	if err := h.userRepo.SetResetToken(c.Request.Context(), user.ID, hashedToken, &expiresAt); err != nil {
-		log.Printf("Error storing reset token: %v", err)
+		slog.Error("Error storing reset token", "error", err)
 		return
 	}
 
 	// Send the password reset email (with plain token)
 	if err := h.emailService.SendPasswordResetEmail(c.Request.Context(), user.Email, resetToken); err != nil {
-		log.Printf("Error sending password reset email: %v", err)
+		slog.Error("Error sending password reset email", "error", err)
 		// Don't return error to user - token is saved, they could try again
 		return
 	}
@@ -539,10 +888,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	var req ResetPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request body: " + err.Error(),
-		})
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
 		return
 	}
 
@@ -553,77 +899,76 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	user, err := h.userRepo.GetByResetToken(c.Request.Context(), hashedToken)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "invalid_token",
-				"message": "Invalid or expired reset token",
-			})
+			problem.Abort(c, problem.BadRequest("invalid_token", "Invalid or expired reset token"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to process reset request",
-		})
+		problem.Abort(c, problem.Internal("Failed to process reset request"))
 		return
 	}
 
 	// Check if token is expired
 	if user.ResetTokenExpiresAt == nil || user.ResetTokenExpiresAt.Before(time.Now()) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "expired_token",
-			"message": "Reset token has expired",
-		})
+		problem.Abort(c, problem.BadRequest("expired_token", "Reset token has expired"))
 		return
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "account_disabled",
-			"message": "This account has been disabled",
-		})
+		problem.Abort(c, problem.Forbidden("account_disabled", "This account has been disabled"))
+		return
+	}
+
+	// Enforce password policy
+	if rejectWeakPassword(c, h.passwordPolicy, req.NewPassword) {
 		return
 	}
 
 	// Hash the new password
 	newPasswordHash, err := auth.HashPassword(req.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to process password",
-		})
+		problem.Abort(c, problem.Internal("Failed to process password"))
 		return
 	}
 
 	// Update the password
 	if err := h.userRepo.UpdatePassword(c.Request.Context(), user.ID, newPasswordHash); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to update password",
-		})
+		problem.Abort(c, problem.Internal("Failed to update password"))
 		return
 	}
 
 	// Clear the reset token
 	if err := h.userRepo.ClearResetToken(c.Request.Context(), user.ID); err != nil {
-		log.Printf("Error clearing reset token: %v", err)
+		slog.Error("Error clearing reset token", "error", err)
 		// Non-critical, continue
 	}
 
 	// Revoke all refresh tokens for security
 	if err := h.refreshTokenRepo.RevokeAllForUser(c.Request.Context(), user.ID); err != nil {
-		log.Printf("Error revoking refresh tokens after password reset: %v", err)
+		slog.Error("Error revoking refresh tokens after password reset", "error", err)
 		// Non-critical, continue
 	}
+	if err := h.denylist.RevokeUser(c.Request.Context(), user.ID); err != nil {
+		slog.Error("Error revoking access tokens after password reset", "error", err)
+		// Non-critical, continue
+	}
+
+	// Unlock the account; the reset proves control of the mailbox
+	if h.loginAttempts != nil {
+		if err := h.loginAttempts.Reset(c.Request.Context(), user.ID); err != nil {
+			slog.Error("Error clearing account lock after password reset", "error", err)
+			// Non-critical, continue
+		}
+	}
 
 	// Send password changed notification email
 	if h.emailService != nil {
 		if err := h.emailService.SendPasswordChangedEmail(c.Request.Context(), user.Email); err != nil {
-			log.Printf("Error sending password changed email: %v", err)
+			slog.Error("Error sending password changed email", "error", err)
 			// Non-critical, continue
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	api.Respond(c, http.StatusOK, gin.H{
 		"message": "Password has been reset successfully",
 	})
 }
@@ -4,15 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/cache"
+	"github.com/sebasr/avt-service/internal/config"
 	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/geoip"
 	"github.com/sebasr/avt-service/internal/middleware"
 	"github.com/sebasr/avt-service/internal/models"
 	"github.com/sebasr/avt-service/internal/repository"
@@ -157,6 +162,42 @@ func TestAuthHandler_Register_InvalidRequest(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Register_WeakPassword(t *testing.T) {
+	handler, userRepo, _, _ := setupAuthTest()
+	handler.WithPasswordPolicy(auth.NewPasswordPolicy(config.PasswordPolicyConfig{
+		MinLength:      8,
+		MinEntropyBits: 40,
+		BlockCommon:    true,
+	}))
+
+	created := false
+	userRepo.CreateFunc = func(_ context.Context, _ *models.User) error {
+		created = true
+		return nil
+	}
+
+	body, _ := json.Marshal(RegisterRequest{Email: "test@example.com", Password: "password"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Register(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, created)
+
+	var response struct {
+		Code       string                 `json:"code"`
+		Violations []auth.PolicyViolation `json:"violations"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "weak_password", response.Code)
+	require.Len(t, response.Violations, 2)
+	assert.Equal(t, auth.RuleEntropy, response.Violations[0].Rule)
+	assert.Equal(t, auth.RuleCommon, response.Violations[1].Rule)
+}
+
 func TestAuthHandler_Login_Success(t *testing.T) {
 	handler, userRepo, refreshTokenRepo, _ := setupAuthTest()
 
@@ -298,6 +339,214 @@ func TestAuthHandler_Login_InactiveUser(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "account_disabled")
 }
 
+var testLockoutPolicy = LockoutPolicy{
+	MaxAttempts:      3,
+	MaxAttemptsPerIP: 10,
+	Window:           15 * time.Minute,
+	Duration:         15 * time.Minute,
+}
+
+// setupLockoutTest returns an auth handler with lockout enabled and a single known user
+func setupLockoutTest(t *testing.T) (*AuthHandler, *repository.MockLoginAttemptRepository, *email.MockService, *models.User) {
+	t.Helper()
+	handler, userRepo, _, _ := setupAuthTest()
+
+	passwordHash, err := auth.HashPassword("correctpassword")
+	require.NoError(t, err)
+	user := &models.User{
+		ID:           uuid.New(),
+		Email:        "test@example.com",
+		PasswordHash: passwordHash,
+		IsActive:     true,
+	}
+	userRepo.GetByEmailFunc = func(_ context.Context, email string) (*models.User, error) {
+		if email == user.Email {
+			return user, nil
+		}
+		return nil, repository.ErrUserNotFound
+	}
+
+	attempts := repository.NewMockLoginAttemptRepository()
+	emailService := email.NewMockService()
+	handler.WithLockout(attempts, testLockoutPolicy).WithEmailService(emailService)
+
+	return handler, attempts, emailService, user
+}
+
+func performLogin(handler *AuthHandler, email, password string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(LoginRequest{Email: email, Password: password})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler.Login(c)
+	return w
+}
+
+func TestAuthHandler_Login_LocksAccountAfterRepeatedFailures(t *testing.T) {
+	handler, attempts, emailService, user := setupLockoutTest(t)
+
+	var recorded []*models.LoginAttempt
+	attempts.RecordFailureFunc = func(_ context.Context, attempt *models.LoginAttempt) error {
+		recorded = append(recorded, attempt)
+		return nil
+	}
+	attempts.CountFailuresForUserFunc = func(_ context.Context, _ uuid.UUID, _ time.Time) (int, error) {
+		return len(recorded), nil
+	}
+	var lockedUntil *time.Time
+	attempts.LockFunc = func(_ context.Context, userID uuid.UUID, until time.Time) error {
+		assert.Equal(t, user.ID, userID)
+		lockedUntil = &until
+		return nil
+	}
+
+	for i := 0; i < testLockoutPolicy.MaxAttempts-1; i++ {
+		w := performLogin(handler, user.Email, "wrongpassword")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+	assert.Nil(t, lockedUntil)
+
+	w := performLogin(handler, user.Email, "wrongpassword")
+	assert.Equal(t, http.StatusLocked, w.Code)
+	assert.Contains(t, w.Body.String(), "account_locked")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	require.NotNil(t, lockedUntil)
+	assert.WithinDuration(t, time.Now().Add(testLockoutPolicy.Duration), *lockedUntil, 5*time.Second)
+
+	require.Len(t, recorded, testLockoutPolicy.MaxAttempts)
+	require.NotNil(t, recorded[0].UserID)
+	assert.Equal(t, user.ID, *recorded[0].UserID)
+	assert.Equal(t, user.Email, recorded[0].Email)
+
+	locked := emailService.GetAccountLockedEmails()
+	require.Len(t, locked, 1)
+	assert.Equal(t, user.Email, locked[0].To)
+}
+
+func TestAuthHandler_Login_LockedAccount(t *testing.T) {
+	handler, attempts, _, _ := setupLockoutTest(t)
+
+	until := time.Now().Add(10 * time.Minute)
+	attempts.GetLockedUntilFunc = func(_ context.Context, _ uuid.UUID) (*time.Time, error) {
+		return &until, nil
+	}
+	attempts.ResetFunc = func(_ context.Context, _ uuid.UUID) error {
+		t.Error("locked account must not be reset")
+		return nil
+	}
+
+	// Even the correct password is rejected while locked
+	w := performLogin(handler, "test@example.com", "correctpassword")
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "account_locked", response["code"])
+	assert.InDelta(t, 600, response["retryAfter"], 2)
+	assert.Equal(t, w.Header().Get("Retry-After"), fmt.Sprint(response["retryAfter"]))
+}
+
+func TestAuthHandler_Login_ThrottlesIP(t *testing.T) {
+	handler, attempts, _, _ := setupLockoutTest(t)
+
+	attempts.CountFailuresForIPFunc = func(_ context.Context, _ string, _ time.Time) (int, error) {
+		return testLockoutPolicy.MaxAttemptsPerIP, nil
+	}
+
+	w := performLogin(handler, "test@example.com", "correctpassword")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "too_many_failed_logins")
+	assert.Equal(t, "900", w.Header().Get("Retry-After"))
+}
+
+func TestAuthHandler_Login_UnknownEmailRecordsFailure(t *testing.T) {
+	handler, attempts, _, _ := setupLockoutTest(t)
+
+	var recorded *models.LoginAttempt
+	attempts.RecordFailureFunc = func(_ context.Context, attempt *models.LoginAttempt) error {
+		recorded = attempt
+		return nil
+	}
+
+	w := performLogin(handler, "nobody@example.com", "whatever123")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	require.NotNil(t, recorded)
+	assert.Nil(t, recorded.UserID)
+	assert.Equal(t, "nobody@example.com", recorded.Email)
+}
+
+func TestAuthHandler_Login_SuccessResetsFailures(t *testing.T) {
+	handler, attempts, _, user := setupLockoutTest(t)
+
+	var resetFor uuid.UUID
+	attempts.ResetFunc = func(_ context.Context, userID uuid.UUID) error {
+		resetFor = userID
+		return nil
+	}
+
+	w := performLogin(handler, user.Email, "correctpassword")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, user.ID, resetFor)
+}
+
+func TestAuthHandler_Login_RecordsLoginEvents(t *testing.T) {
+	handler, attempts, _, user := setupLockoutTest(t)
+	loginEvents := repository.NewMockLoginEventRepository()
+	handler.WithLoginEvents(loginEvents)
+
+	var recorded []*models.LoginEvent
+	loginEvents.RecordFunc = func(_ context.Context, event *models.LoginEvent) error {
+		recorded = append(recorded, event)
+		return nil
+	}
+
+	login := func(email, password string) int {
+		body, _ := json.Marshal(LoginRequest{Email: email, Password: password})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("User-Agent", "AVT/2.1 (iOS 17.4)")
+		c.Request.RemoteAddr = "203.0.113.7:5000"
+		handler.Login(c)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, login(user.Email, "wrongpassword"))
+	assert.Equal(t, http.StatusUnauthorized, login("nobody@example.com", "whatever123"))
+	assert.Equal(t, http.StatusOK, login(user.Email, "correctpassword"))
+
+	attempts.CountFailuresForIPFunc = func(_ context.Context, _ string, _ time.Time) (int, error) {
+		return testLockoutPolicy.MaxAttemptsPerIP, nil
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login(user.Email, "correctpassword"))
+
+	require.Len(t, recorded, 4)
+	for _, event := range recorded {
+		assert.Equal(t, models.LoginMethodPassword, event.Method)
+		assert.Equal(t, "203.0.113.7", event.IPAddress)
+		assert.Equal(t, "AVT/2.1 (iOS 17.4)", event.UserAgent)
+	}
+
+	assert.False(t, recorded[0].Success)
+	assert.Equal(t, models.LoginFailureInvalidCredentials, recorded[0].FailureReason)
+	require.NotNil(t, recorded[0].UserID)
+	assert.Equal(t, user.ID, *recorded[0].UserID)
+
+	assert.Equal(t, models.LoginFailureUnknownAccount, recorded[1].FailureReason)
+	assert.Nil(t, recorded[1].UserID)
+	assert.Equal(t, "nobody@example.com", recorded[1].Email)
+
+	assert.True(t, recorded[2].Success)
+	assert.Empty(t, recorded[2].FailureReason)
+	require.NotNil(t, recorded[2].UserID)
+	assert.Equal(t, user.ID, *recorded[2].UserID)
+
+	assert.Equal(t, models.LoginFailureThrottled, recorded[3].FailureReason)
+}
+
 func TestAuthHandler_RefreshToken_Success(t *testing.T) {
 	handler, userRepo, refreshTokenRepo, jwtService := setupAuthTest()
 
@@ -384,6 +633,28 @@ func TestAuthHandler_RefreshToken_InvalidToken(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "invalid_token")
 }
 
+func TestAuthHandler_RefreshToken_AccessToken(t *testing.T) {
+	handler, _, _, jwtService := setupAuthTest()
+
+	accessToken, err := jwtService.GenerateAccessToken(uuid.New(), "test@example.com", "user")
+	require.NoError(t, err)
+
+	reqBody := RefreshTokenRequest{
+		RefreshToken: accessToken,
+	}
+
+	body, _ := json.Marshal(reqBody)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.RefreshToken(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_token")
+}
+
 func TestAuthHandler_RefreshToken_RevokedToken(t *testing.T) {
 	handler, _, refreshTokenRepo, jwtService := setupAuthTest()
 
@@ -414,6 +685,287 @@ func TestAuthHandler_RefreshToken_RevokedToken(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "invalid_token")
 }
 
+func TestAuthHandler_Login_RememberMe(t *testing.T) {
+	handler, userRepo, refreshTokenRepo, _ := setupAuthTest()
+	handler.WithSessionPolicy(SessionPolicy{TTL: 24 * time.Hour, RememberMeTTL: 90 * 24 * time.Hour})
+
+	passwordHash, _ := auth.HashPassword("password123")
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, IsActive: true}
+	userRepo.GetByEmailFunc = func(_ context.Context, _ string) (*models.User, error) {
+		return user, nil
+	}
+
+	var captured *models.RefreshToken
+	refreshTokenRepo.CreateFunc = func(_ context.Context, token *models.RefreshToken) error {
+		captured = token
+		return nil
+	}
+
+	body, _ := json.Marshal(LoginRequest{Email: "test@example.com", Password: "password123", RememberMe: true})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Login(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, captured)
+	assert.True(t, captured.RememberMe)
+	assert.WithinDuration(t, time.Now(), captured.SessionStartedAt, time.Minute)
+	assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), captured.ExpiresAt, time.Minute)
+
+	var response AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.WithinDuration(t, captured.ExpiresAt, response.ExpiresAt, time.Second)
+}
+
+func TestAuthHandler_Login_NewLocationAlert(t *testing.T) {
+	// httptest requests come from 192.0.2.1
+	provider, err := geoip.ParseCSV(strings.NewReader("192.0.2.0/24,FR,France,Ile-de-France,Paris\n"))
+	require.NoError(t, err)
+
+	login := func(t *testing.T, knownCountries []string, notifier Notifier) (*models.RefreshToken, *email.MockService) {
+		t.Helper()
+		handler, userRepo, refreshTokenRepo, _ := setupAuthTest()
+		emailService := email.NewMockService()
+		handler.WithGeoIP(provider).WithEmailService(emailService)
+		if notifier != nil {
+			handler.WithNotifier(notifier)
+		}
+
+		passwordHash, _ := auth.HashPassword("password123")
+		user := &models.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, IsActive: true}
+		userRepo.GetByEmailFunc = func(_ context.Context, _ string) (*models.User, error) {
+			return user, nil
+		}
+		refreshTokenRepo.ListCountriesFunc = func(_ context.Context, _ uuid.UUID) ([]string, error) {
+			return knownCountries, nil
+		}
+		var captured *models.RefreshToken
+		refreshTokenRepo.CreateFunc = func(_ context.Context, token *models.RefreshToken) error {
+			captured = token
+			return nil
+		}
+
+		body, _ := json.Marshal(LoginRequest{Email: "test@example.com", Password: "password123"})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.Login(c)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NotNil(t, captured)
+		return captured, emailService
+	}
+
+	t.Run("login from a new country is reported", func(t *testing.T) {
+		token, emailService := login(t, []string{"GB"}, nil)
+		require.NotNil(t, token.Location)
+		assert.Equal(t, "FR", token.Location.CountryCode)
+
+		emails := emailService.GetLoginAlertEmails()
+		require.Len(t, emails, 1)
+		assert.Equal(t, "test@example.com", emails[0].To)
+		assert.Equal(t, "Paris, Ile-de-France, France", emails[0].LoginAlert.Location)
+		assert.Equal(t, "192.0.2.1", emails[0].LoginAlert.IPAddress)
+	})
+
+	t.Run("login from a known country is not reported", func(t *testing.T) {
+		_, emailService := login(t, []string{"GB", "FR"}, nil)
+		assert.Empty(t, emailService.GetLoginAlertEmails())
+	})
+
+	t.Run("first located login is not reported", func(t *testing.T) {
+		token, emailService := login(t, nil, nil)
+		assert.NotNil(t, token.Location)
+		assert.Empty(t, emailService.GetLoginAlertEmails())
+	})
+
+	t.Run("login from a new country goes through the notifier", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		token, emailService := login(t, []string{"GB"}, notifier)
+
+		assert.Empty(t, emailService.GetLoginAlertEmails())
+		require.Len(t, notifier.notifications, 1)
+		assert.Equal(t, token.UserID, notifier.notifications[0].UserID)
+		assert.Equal(t, models.NotificationNewLogin, notifier.notifications[0].Type)
+		assert.Equal(t, "New sign-in from Paris, Ile-de-France, France", notifier.notifications[0].Title)
+	})
+}
+
+func TestAuthHandler_Login_PublishesEvent(t *testing.T) {
+	// httptest requests come from 192.0.2.1
+	provider, err := geoip.ParseCSV(strings.NewReader("192.0.2.0/24,FR,France,Ile-de-France,Paris\n"))
+	require.NoError(t, err)
+
+	handler, userRepo, refreshTokenRepo, _ := setupAuthTest()
+	emailService := email.NewMockService()
+	publisher := &recordingPublisher{}
+	handler.WithGeoIP(provider).WithEmailService(emailService).WithEvents(publisher)
+
+	passwordHash, _ := auth.HashPassword("password123")
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, IsActive: true}
+	userRepo.GetByEmailFunc = func(_ context.Context, _ string) (*models.User, error) {
+		return user, nil
+	}
+	refreshTokenRepo.ListCountriesFunc = func(_ context.Context, _ uuid.UUID) ([]string, error) {
+		return []string{"GB"}, nil
+	}
+	refreshTokenRepo.CreateFunc = func(ctx context.Context, token *models.RefreshToken) error {
+		storeEvents(t, publisher, ctx, []*models.RefreshToken{token})
+		return nil
+	}
+
+	body, _ := json.Marshal(LoginRequest{Email: "test@example.com", Password: "password123"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Login(c)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	logins := decodeEvents[models.UserLoggedIn](t, publisher, models.EventUserLoggedIn)
+	require.Len(t, logins, 1)
+	assert.Equal(t, user.ID, logins[0].UserID)
+	assert.True(t, logins[0].NewCountry)
+	assert.Equal(t, "FR", logins[0].Location.CountryCode)
+	assert.Empty(t, emailService.GetLoginAlertEmails(), "login alerts are left to the event's subscribers")
+}
+
+func TestAuthHandler_RefreshToken_SlidingExpiration(t *testing.T) {
+	userID := uuid.New()
+
+	refresh := func(t *testing.T, policy SessionPolicy, stored *models.RefreshToken) *models.RefreshToken {
+		t.Helper()
+		handler, userRepo, refreshTokenRepo, jwtService := setupAuthTest()
+		handler.WithSessionPolicy(policy)
+
+		refreshTokenString, expiresAt, _ := jwtService.GenerateRefreshToken(userID, "test@example.com")
+		stored.ExpiresAt = expiresAt
+		refreshTokenRepo.GetByHashFunc = func(_ context.Context, _ string) (*models.RefreshToken, error) {
+			return stored, nil
+		}
+		userRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.User, error) {
+			return &models.User{ID: userID, Email: "test@example.com", IsActive: true}, nil
+		}
+		var captured *models.RefreshToken
+		refreshTokenRepo.CreateFunc = func(_ context.Context, token *models.RefreshToken) error {
+			captured = token
+			return nil
+		}
+
+		body, _ := json.Marshal(RefreshTokenRequest{RefreshToken: refreshTokenString})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.RefreshToken(c)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NotNil(t, captured)
+		return captured
+	}
+
+	t.Run("each refresh extends the session", func(t *testing.T) {
+		startedAt := time.Now().Add(-10 * 24 * time.Hour)
+		stored := &models.RefreshToken{ID: uuid.New(), UserID: userID, CreatedAt: time.Now().Add(-time.Hour), SessionStartedAt: startedAt}
+
+		token := refresh(t, SessionPolicy{TTL: 24 * time.Hour, MaxAge: 30 * 24 * time.Hour}, stored)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), token.ExpiresAt, time.Minute)
+		assert.Equal(t, startedAt, token.SessionStartedAt)
+		assert.False(t, token.RememberMe)
+	})
+
+	t.Run("remember-me sessions keep their longer lifetime", func(t *testing.T) {
+		stored := &models.RefreshToken{ID: uuid.New(), UserID: userID, CreatedAt: time.Now(), SessionStartedAt: time.Now(), RememberMe: true}
+
+		token := refresh(t, SessionPolicy{TTL: 24 * time.Hour, RememberMeTTL: 90 * 24 * time.Hour}, stored)
+		assert.True(t, token.RememberMe)
+		assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), token.ExpiresAt, time.Minute)
+	})
+
+	t.Run("expiry is capped at the maximum session age", func(t *testing.T) {
+		startedAt := time.Now().Add(-(30*24*time.Hour - time.Hour))
+		stored := &models.RefreshToken{ID: uuid.New(), UserID: userID, CreatedAt: time.Now(), SessionStartedAt: startedAt}
+
+		token := refresh(t, SessionPolicy{TTL: 24 * time.Hour, MaxAge: 30 * 24 * time.Hour}, stored)
+		assert.Equal(t, startedAt.Add(30*24*time.Hour), token.ExpiresAt)
+	})
+}
+
+func TestAuthHandler_RefreshToken_ClientBinding(t *testing.T) {
+	const userAgent = "AVT/2.1 (iOS 17.4)"
+	userID := uuid.New()
+	bound := auth.ClientFingerprint(userAgent, "install-1")
+
+	tests := []struct {
+		name            string
+		binding         string
+		storedPrint     string
+		userAgent       string
+		installationID  string
+		expectedStatus  int
+		expectedRebound string
+	}{
+		{"same client", "optional", bound, userAgent, "install-1", http.StatusOK, bound},
+		{"other installation", "optional", bound, userAgent, "install-2", http.StatusUnauthorized, ""},
+		{"other user agent", "required", bound, "curl/8.5.0", "install-1", http.StatusUnauthorized, ""},
+		{"installation ID dropped", "optional", bound, userAgent, "", http.StatusUnauthorized, ""},
+		{"binding turned off", "off", bound, "curl/8.5.0", "", http.StatusOK, ""},
+		{"unbound token is bound on rotation", "optional", "", userAgent, "install-1", http.StatusOK, bound},
+		{"required binds clients without an installation ID", "required", "", userAgent, "", http.StatusOK, auth.ClientFingerprint(userAgent, "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, userRepo, refreshTokenRepo, jwtService := setupAuthTest()
+			handler.WithSessionPolicy(SessionPolicy{TTL: 24 * time.Hour, Binding: tt.binding})
+
+			refreshTokenString, expiresAt, _ := jwtService.GenerateRefreshToken(userID, "test@example.com")
+			refreshTokenRepo.GetByHashFunc = func(_ context.Context, _ string) (*models.RefreshToken, error) {
+				return &models.RefreshToken{
+					ID: uuid.New(), UserID: userID, ExpiresAt: expiresAt, CreatedAt: time.Now(),
+					ClientFingerprint: tt.storedPrint,
+				}, nil
+			}
+			userRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.User, error) {
+				return &models.User{ID: userID, Email: "test@example.com", IsActive: true}, nil
+			}
+			var captured *models.RefreshToken
+			refreshTokenRepo.CreateFunc = func(_ context.Context, token *models.RefreshToken) error {
+				captured = token
+				return nil
+			}
+
+			body, _ := json.Marshal(RefreshTokenRequest{RefreshToken: refreshTokenString})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Header.Set("User-Agent", tt.userAgent)
+			if tt.installationID != "" {
+				c.Request.Header.Set(InstallationIDHeader, tt.installationID)
+			}
+
+			handler.RefreshToken(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				assert.Contains(t, w.Body.String(), "client_mismatch")
+				assert.Nil(t, captured, "no token is issued to a mismatching client")
+				return
+			}
+			require.NotNil(t, captured)
+			assert.Equal(t, tt.expectedRebound, captured.ClientFingerprint)
+		})
+	}
+}
+
 func TestAuthHandler_Logout_Success(t *testing.T) {
 	handler, _, refreshTokenRepo, _ := setupAuthTest()
 
@@ -435,6 +987,31 @@ func TestAuthHandler_Logout_Success(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "Successfully logged out")
 }
 
+func TestAuthHandler_Logout_RevokesAccessTokens(t *testing.T) {
+	handler, _, _, jwtService := setupAuthTest()
+	denylist := auth.NewDenylist(cache.NewMemoryCache(), time.Hour)
+	handler.WithDenylist(denylist)
+
+	userID := uuid.New()
+	accessToken, err := jwtService.GenerateAccessToken(userID, "test@example.com", "user")
+	require.NoError(t, err)
+	claims, err := jwtService.ValidateToken(accessToken)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+	c.Set(string(middleware.TokenClaimsKey), claims)
+
+	handler.Logout(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	revoked, err := denylist.IsRevoked(context.Background(), claims)
+	require.NoError(t, err)
+	assert.True(t, revoked, "the token used to log out is revoked")
+}
+
 func TestAuthHandler_Logout_Unauthorized(t *testing.T) {
 	handler, _, _, _ := setupAuthTest()
 
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// MagicLinkRequest represents the magic link request body
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// MagicLoginRequest represents the magic link sign-in request body
+type MagicLoginRequest struct {
+	Token      string `json:"token" binding:"required"`
+	RememberMe bool   `json:"rememberMe"` // Issue a longer-lived refresh token
+}
+
+// RequestMagicLink emails a one-time sign-in link
+// POST /api/v1/auth/magic-link
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	emailAddr := strings.ToLower(strings.TrimSpace(req.Email))
+
+	// Always return success to prevent email enumeration attacks
+	defer func() {
+		api.Respond(c, http.StatusOK, gin.H{
+			"message": "If an account with that email exists, a sign-in link has been sent",
+		})
+	}()
+
+	if h.emailService == nil {
+		slog.Warn("Email service not configured, skipping magic link email", "email", emailAddr)
+		return
+	}
+
+	user, err := h.userRepo.GetByEmail(c.Request.Context(), emailAddr)
+	if err != nil {
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			slog.Error("Error looking up user for magic link", "error", err)
+		}
+		return
+	}
+
+	if !user.IsActive {
+		return
+	}
+
+	token, err := auth.GenerateSecureToken()
+	if err != nil {
+		slog.Error("Error generating magic link token", "error", err)
+		return
+	}
+
+	// Store the hash and send the plain token; a new link replaces any earlier one
+	expiresAt := time.Now().Add(h.magicLinkTTL)
+	if err := h.userRepo.SetMagicLinkToken(c.Request.Context(), user.ID, auth.HashToken(token), expiresAt); err != nil {
+		slog.Error("Error storing magic link token", "error", err)
+		return
+	}
+
+	if err := h.emailService.SendMagicLinkEmail(c.Request.Context(), user.Email, token, expiresAt); err != nil {
+		slog.Error("Error sending magic link email", "error", err)
+	}
+}
+
+// MagicLogin exchanges an emailed sign-in token for access and refresh tokens
+// POST /api/v1/auth/magic-login
+func (h *AuthHandler) MagicLogin(c *gin.Context) {
+	var req MagicLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Consuming the token clears it, so each link signs in at most once
+	user, err := h.userRepo.ConsumeMagicLinkToken(c.Request.Context(), auth.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			h.recordLogin(c, nil, "", models.LoginMethodMagicLink, models.LoginFailureInvalidToken)
+			problem.Abort(c, problem.Unauthorized("invalid_token", "Invalid or expired sign-in link"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to authenticate"))
+		return
+	}
+
+	if !user.IsActive {
+		h.recordLogin(c, user, user.Email, models.LoginMethodMagicLink, models.LoginFailureAccountDisabled)
+		problem.Abort(c, problem.Forbidden("account_disabled", "This account has been disabled"))
+		return
+	}
+
+	// The link proves control of the mailbox: unlock the account and verify the address
+	if h.loginAttempts != nil {
+		if err := h.loginAttempts.Reset(c.Request.Context(), user.ID); err != nil {
+			slog.Error("Error clearing failed login attempts", "error", err)
+			// Non-critical, continue
+		}
+	}
+	if !user.EmailVerified {
+		if err := h.userRepo.UpdateEmailVerification(c.Request.Context(), user.ID, true); err != nil {
+			slog.Error("Error verifying email after magic link login", "error", err)
+		} else {
+			user.EmailVerified = true
+		}
+	}
+
+	h.issueSession(c, user, req.RememberMe, models.LoginMethodMagicLink)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func performMagicLinkRequest(path string, body interface{}, serve gin.HandlerFunc) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	serve(c)
+	return w
+}
+
+func TestAuthHandler_RequestMagicLink(t *testing.T) {
+	handler, userRepo, _, _ := setupAuthTest()
+	emailService := email.NewMockService()
+	handler.WithEmailService(emailService).WithMagicLinkTTL(10 * time.Minute)
+
+	active := &models.User{ID: uuid.New(), Email: "driver@example.com", IsActive: true}
+	disabled := &models.User{ID: uuid.New(), Email: "disabled@example.com", IsActive: false}
+	userRepo.GetByEmailFunc = func(_ context.Context, emailAddr string) (*models.User, error) {
+		for _, user := range []*models.User{active, disabled} {
+			if user.Email == emailAddr {
+				return user, nil
+			}
+		}
+		return nil, repository.ErrUserNotFound
+	}
+
+	var storedHash string
+	var storedExpiry time.Time
+	userRepo.SetMagicLinkTokenFunc = func(_ context.Context, id uuid.UUID, token string, expiresAt time.Time) error {
+		assert.Equal(t, active.ID, id)
+		storedHash = token
+		storedExpiry = expiresAt
+		return nil
+	}
+
+	for _, addr := range []string{"Driver@Example.com", "disabled@example.com", "unknown@example.com"} {
+		w := performMagicLinkRequest("/api/v1/auth/magic-link", MagicLinkRequest{Email: addr}, handler.RequestMagicLink)
+		assert.Equal(t, http.StatusOK, w.Code, addr)
+		assert.Contains(t, w.Body.String(), "If an account with that email exists")
+	}
+
+	// Only the active account gets a link, and only the hash of its token is stored
+	emails := emailService.GetMagicLinkEmails()
+	require.Len(t, emails, 1)
+	assert.Equal(t, active.Email, emails[0].To)
+	assert.Equal(t, auth.HashToken(emails[0].Token), storedHash)
+	assert.Equal(t, storedExpiry, emails[0].ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), storedExpiry, 5*time.Second)
+}
+
+func TestAuthHandler_RequestMagicLink_InvalidRequest(t *testing.T) {
+	handler, _, _, _ := setupAuthTest()
+
+	w := performMagicLinkRequest("/api/v1/auth/magic-link", MagicLinkRequest{Email: "not-an-email"}, handler.RequestMagicLink)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuthHandler_MagicLogin(t *testing.T) {
+	const token = "emailed-token"
+
+	tests := []struct {
+		name           string
+		token          string
+		user           *models.User
+		expectedStatus int
+	}{
+		{
+			name:           "signs in and verifies the email",
+			token:          token,
+			user:           &models.User{ID: uuid.New(), Email: "driver@example.com", IsActive: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown or used token",
+			token:          "other-token",
+			user:           &models.User{ID: uuid.New(), Email: "driver@example.com", IsActive: true},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "disabled account",
+			token:          token,
+			user:           &models.User{ID: uuid.New(), Email: "driver@example.com", IsActive: false},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, userRepo, refreshTokenRepo, _ := setupAuthTest()
+			attempts := repository.NewMockLoginAttemptRepository()
+			handler.WithLockout(attempts, testLockoutPolicy)
+
+			userRepo.ConsumeMagicLinkTokenFunc = func(_ context.Context, hash string) (*models.User, error) {
+				if hash == auth.HashToken(token) {
+					return tt.user, nil
+				}
+				return nil, repository.ErrUserNotFound
+			}
+			var verified bool
+			userRepo.UpdateEmailVerificationFunc = func(_ context.Context, id uuid.UUID, value bool) error {
+				assert.Equal(t, tt.user.ID, id)
+				verified = value
+				return nil
+			}
+			var unlocked bool
+			attempts.ResetFunc = func(_ context.Context, id uuid.UUID) error {
+				assert.Equal(t, tt.user.ID, id)
+				unlocked = true
+				return nil
+			}
+			var session *models.RefreshToken
+			refreshTokenRepo.CreateFunc = func(_ context.Context, token *models.RefreshToken) error {
+				session = token
+				return nil
+			}
+
+			w := performMagicLinkRequest("/api/v1/auth/magic-login", MagicLoginRequest{Token: tt.token, RememberMe: true}, handler.MagicLogin)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Nil(t, session)
+				return
+			}
+
+			var response AuthResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.NotEmpty(t, response.AccessToken)
+			assert.NotEmpty(t, response.RefreshToken)
+			assert.True(t, response.User.EmailVerified)
+			assert.True(t, verified)
+			assert.True(t, unlocked)
+			require.NotNil(t, session)
+			assert.True(t, session.RememberMe)
+		})
+	}
+}
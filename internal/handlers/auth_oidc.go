@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/oidc"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// oidcStateAudience scopes state tokens to the single sign-on flow
+	oidcStateAudience = "oidc"
+
+	// oidcStateTTL is how long users have to sign in at the identity provider
+	oidcStateTTL = 10 * time.Minute
+)
+
+// OIDCProvider signs users in at an OpenID Connect identity provider
+type OIDCProvider interface {
+	Issuer() string
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	Exchange(ctx context.Context, code, nonce, verifier string) (*oidc.Identity, error)
+}
+
+// OIDCAuthorizeResponse is where to send the user to sign in at the identity provider
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+	State            string `json:"state"` // Keep until the callback and check it matches the state returned by the provider
+}
+
+// OIDCCallbackRequest represents the single sign-on callback request body
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// WithOIDC enables single sign-on through an OpenID Connect provider
+// Users are matched by the provider's subject, linked by verified email or created on first sign-in.
+// When roles is not empty, each sign-in sets the user's role from their groups.
+func (h *AuthHandler) WithOIDC(provider OIDCProvider, identities repository.UserIdentityRepository, roles map[string]models.Role) *AuthHandler {
+	h.oidcProvider = provider
+	h.identityRepo = identities
+	h.oidcRoles = roles
+	return h
+}
+
+// OIDCAuthorize starts a single sign-on
+// The nonce and PKCE verifier are derived from the state, so nothing is stored until the callback.
+// GET /api/v1/auth/oidc/authorize
+func (h *AuthHandler) OIDCAuthorize(c *gin.Context) {
+	if h.oidcProvider == nil {
+		problem.Abort(c, problem.ServiceUnavailable("sso_unavailable", "Single sign-on is not configured"))
+		return
+	}
+
+	state, stateToken, err := h.jwtService.GenerateStateToken(oidcStateAudience, oidcStateTTL)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to start single sign-on"))
+		return
+	}
+
+	authURL, err := h.oidcProvider.AuthCodeURL(c.Request.Context(), state,
+		stateToken.DeriveValue("oidc-nonce"), stateToken.DeriveValue("oidc-pkce"))
+	if err != nil {
+		slog.Error("Error discovering OpenID provider", "issuer", h.oidcProvider.Issuer(), "error", err)
+		problem.Abort(c, problem.New(http.StatusBadGateway, "sso_unavailable", "The identity provider could not be reached"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, OIDCAuthorizeResponse{AuthorizationURL: authURL, State: state})
+}
+
+// OIDCCallback exchanges the identity provider's authorization code for access and refresh tokens
+// POST /api/v1/auth/oidc/callback
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if h.oidcProvider == nil {
+		problem.Abort(c, problem.ServiceUnavailable("sso_unavailable", "Single sign-on is not configured"))
+		return
+	}
+
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	stateToken, err := h.jwtService.ValidateStateToken(req.State, oidcStateAudience)
+	if err != nil {
+		h.recordLogin(c, nil, "", models.LoginMethodOIDC, models.LoginFailureInvalidToken)
+		problem.Abort(c, problem.Unauthorized("invalid_state", "Invalid or expired sign-in state"))
+		return
+	}
+
+	identity, err := h.oidcProvider.Exchange(c.Request.Context(), req.Code,
+		stateToken.DeriveValue("oidc-nonce"), stateToken.DeriveValue("oidc-pkce"))
+	if err != nil {
+		switch {
+		case errors.Is(err, oidc.ErrInvalidGrant):
+			h.recordLogin(c, nil, "", models.LoginMethodOIDC, models.LoginFailureInvalidToken)
+			problem.Abort(c, problem.Unauthorized("invalid_code", "Invalid or expired authorization code"))
+		case errors.Is(err, oidc.ErrInvalidIDToken):
+			slog.Warn("Rejected ID token", "issuer", h.oidcProvider.Issuer(), "error", err)
+			h.recordLogin(c, nil, "", models.LoginMethodOIDC, models.LoginFailureInvalidToken)
+			problem.Abort(c, problem.Unauthorized("invalid_token", "The identity provider returned an invalid ID token"))
+		default:
+			slog.Error("Error exchanging authorization code", "issuer", h.oidcProvider.Issuer(), "error", err)
+			problem.Abort(c, problem.New(http.StatusBadGateway, "sso_unavailable", "The identity provider could not be reached"))
+		}
+		return
+	}
+
+	user, ok := h.provisionOIDCUser(c, identity)
+	if !ok {
+		return
+	}
+
+	if !user.IsActive {
+		h.recordLogin(c, user, identity.Email, models.LoginMethodOIDC, models.LoginFailureAccountDisabled)
+		problem.Abort(c, problem.Forbidden("account_disabled", "This account has been disabled"))
+		return
+	}
+
+	if len(h.oidcRoles) > 0 {
+		if role := mapGroupsToRole(identity.Groups, h.oidcRoles); role != user.Role {
+			slog.Info("Updating role from identity provider groups", "user_id", user.ID, "from", user.Role, "to", role)
+			user.Role = role
+			if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
+				problem.Abort(c, problem.Internal("Failed to update user role"))
+				return
+			}
+		}
+	}
+
+	h.issueSession(c, user, false, models.LoginMethodOIDC)
+}
+
+// provisionOIDCUser returns the user linked to the identity, linking an account with the same
+// verified email or creating one on first sign-in
+// It responds with an error and returns false when the user cannot be signed in.
+func (h *AuthHandler) provisionOIDCUser(c *gin.Context, identity *oidc.Identity) (*models.User, bool) {
+	ctx := c.Request.Context()
+
+	link, err := h.identityRepo.GetBySubject(ctx, identity.Issuer, identity.Subject)
+	switch {
+	case err == nil:
+		user, err := h.userRepo.GetByID(ctx, link.UserID)
+		if err != nil {
+			problem.Abort(c, problem.Internal("Failed to authenticate"))
+			return nil, false
+		}
+		if err := h.identityRepo.RecordLogin(ctx, link.ID, identity.Email); err != nil {
+			slog.Error("Error recording identity provider sign-in", "error", err)
+			// Non-critical, continue
+		}
+		return user, true
+	case !errors.Is(err, repository.ErrUserIdentityNotFound):
+		problem.Abort(c, problem.Internal("Failed to authenticate"))
+		return nil, false
+	}
+
+	if identity.Email == "" {
+		h.recordLogin(c, nil, "", models.LoginMethodOIDC, models.LoginFailureUnknownAccount)
+		problem.Abort(c, problem.Forbidden("email_required", "The identity provider did not share an email address"))
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		// Linking on an unverified address would let anyone who can set it at the provider take the account over
+		if !identity.EmailVerified {
+			h.recordLogin(c, user, identity.Email, models.LoginMethodOIDC, models.LoginFailureUnverifiedEmail)
+			problem.Abort(c, problem.Forbidden("email_not_verified",
+				"An account with this email already exists; verify the address at the identity provider to link it"))
+			return nil, false
+		}
+	case errors.Is(err, repository.ErrUserNotFound):
+		if user, err = h.createOIDCUser(ctx, identity); err != nil {
+			if errors.Is(err, repository.ErrUserExists) {
+				problem.Abort(c, problem.Conflict("user_exists", "A user with this email already exists"))
+				return nil, false
+			}
+			problem.Abort(c, problem.Internal("Failed to create user"))
+			return nil, false
+		}
+		slog.Info("Provisioned user from identity provider", "user_id", user.ID, "issuer", identity.Issuer)
+	default:
+		problem.Abort(c, problem.Internal("Failed to authenticate"))
+		return nil, false
+	}
+
+	now := time.Now()
+	err = h.identityRepo.Create(ctx, &models.UserIdentity{
+		UserID:      user.ID,
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		CreatedAt:   now,
+		LastLoginAt: &now,
+	})
+	if err != nil && !errors.Is(err, repository.ErrUserIdentityExists) {
+		problem.Abort(c, problem.Internal("Failed to link identity"))
+		return nil, false
+	}
+
+	return user, true
+}
+
+// createOIDCUser creates the account of a user signing in for the first time
+// The password is random, so the account can only be used through single sign-on, a magic link or
+// a password reset.
+func (h *AuthHandler) createOIDCUser(ctx context.Context, identity *oidc.Identity) (*models.User, error) {
+	password, err := auth.GenerateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	role := models.RoleUser
+	if len(h.oidcRoles) > 0 {
+		role = mapGroupsToRole(identity.Groups, h.oidcRoles)
+	}
+
+	now := time.Now()
+	user := &models.User{
+		ID:            uuid.New(),
+		Email:         identity.Email,
+		PasswordHash:  passwordHash,
+		EmailVerified: identity.EmailVerified,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		IsActive:      true,
+		Role:          role,
+	}
+	if err := h.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// mapGroupsToRole returns the admin role if any group maps to it and the user role otherwise
+func mapGroupsToRole(groups []string, roles map[string]models.Role) models.Role {
+	for _, group := range groups {
+		if roles[group] == models.RoleAdmin {
+			return models.RoleAdmin
+		}
+	}
+	return models.RoleUser
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/oidc"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOIDCProvider accepts the code "good-code" when it is exchanged with the nonce and
+// verifier of the last authorization URL
+type fakeOIDCProvider struct {
+	identity *oidc.Identity
+	err      error
+	nonce    string
+	verifier string
+}
+
+func (p *fakeOIDCProvider) Issuer() string {
+	return "https://idp.example.com"
+}
+
+func (p *fakeOIDCProvider) AuthCodeURL(_ context.Context, state, nonce, verifier string) (string, error) {
+	p.nonce, p.verifier = nonce, verifier
+	return "https://idp.example.com/authorize?state=" + url.QueryEscape(state), nil
+}
+
+func (p *fakeOIDCProvider) Exchange(_ context.Context, code, nonce, verifier string) (*oidc.Identity, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if code != "good-code" || nonce != p.nonce || verifier != p.verifier {
+		return nil, oidc.ErrInvalidGrant
+	}
+	return p.identity, nil
+}
+
+// authorizeOIDC starts a single sign-on and returns its state
+func authorizeOIDC(t *testing.T, handler *AuthHandler) string {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/authorize", nil)
+	handler.OIDCAuthorize(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response OIDCAuthorizeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.AuthorizationURL, url.QueryEscape(response.State))
+	return response.State
+}
+
+func TestAuthHandler_OIDCCallback_ProvisionsUser(t *testing.T) {
+	handler, userRepo, _, jwtService := setupAuthTest()
+	identities := repository.NewMockUserIdentityRepository()
+	events := repository.NewMockLoginEventRepository()
+	provider := &fakeOIDCProvider{identity: &oidc.Identity{
+		Issuer:        "https://idp.example.com",
+		Subject:       "00u1abcd",
+		Email:         "rider@example.com",
+		EmailVerified: true,
+		Groups:        []string{"everyone", "avt-admins"},
+	}}
+	handler.WithOIDC(provider, identities, map[string]models.Role{"avt-admins": models.RoleAdmin}).WithLoginEvents(events)
+
+	var created *models.User
+	userRepo.CreateFunc = func(_ context.Context, user *models.User) error {
+		created = user
+		return nil
+	}
+	var linked *models.UserIdentity
+	identities.CreateFunc = func(_ context.Context, identity *models.UserIdentity) error {
+		linked = identity
+		return nil
+	}
+	var recorded []*models.LoginEvent
+	events.RecordFunc = func(_ context.Context, event *models.LoginEvent) error {
+		recorded = append(recorded, event)
+		return nil
+	}
+
+	state := authorizeOIDC(t, handler)
+	w := performMagicLinkRequest("/api/v1/auth/oidc/callback", OIDCCallbackRequest{Code: "good-code", State: state}, handler.OIDCCallback)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.NotNil(t, created)
+	assert.Equal(t, "rider@example.com", created.Email)
+	assert.True(t, created.EmailVerified)
+	assert.Equal(t, models.RoleAdmin, created.Role)
+	assert.NotEmpty(t, created.PasswordHash)
+
+	require.NotNil(t, linked)
+	assert.Equal(t, created.ID, linked.UserID)
+	assert.Equal(t, "00u1abcd", linked.Subject)
+
+	var response AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	claims, err := jwtService.ValidateToken(response.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.RoleAdmin), claims.Role)
+
+	require.Len(t, recorded, 1)
+	assert.Equal(t, models.LoginMethodOIDC, recorded[0].Method)
+	assert.True(t, recorded[0].Success)
+}
+
+func TestAuthHandler_OIDCCallback_LinkedUser(t *testing.T) {
+	handler, userRepo, _, _ := setupAuthTest()
+	identities := repository.NewMockUserIdentityRepository()
+	provider := &fakeOIDCProvider{identity: &oidc.Identity{
+		Issuer:  "https://idp.example.com",
+		Subject: "00u1abcd",
+		Email:   "renamed@example.com",
+		Groups:  []string{"everyone"},
+	}}
+	handler.WithOIDC(provider, identities, map[string]models.Role{"avt-admins": models.RoleAdmin})
+
+	user := &models.User{ID: uuid.New(), Email: "rider@example.com", IsActive: true, Role: models.RoleAdmin}
+	link := &models.UserIdentity{ID: uuid.New(), UserID: user.ID, Issuer: "https://idp.example.com", Subject: "00u1abcd"}
+	identities.GetBySubjectFunc = func(_ context.Context, issuer, subject string) (*models.UserIdentity, error) {
+		if issuer == link.Issuer && subject == link.Subject {
+			return link, nil
+		}
+		return nil, repository.ErrUserIdentityNotFound
+	}
+	var loginEmail string
+	identities.RecordLoginFunc = func(_ context.Context, id uuid.UUID, email string) error {
+		assert.Equal(t, link.ID, id)
+		loginEmail = email
+		return nil
+	}
+	userRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+		assert.Equal(t, user.ID, id)
+		return user, nil
+	}
+	userRepo.GetByEmailFunc = func(_ context.Context, _ string) (*models.User, error) {
+		t.Fatal("linked users are not looked up by email")
+		return nil, nil
+	}
+	var updated *models.User
+	userRepo.UpdateFunc = func(_ context.Context, user *models.User) error {
+		updated = user
+		return nil
+	}
+
+	state := authorizeOIDC(t, handler)
+	w := performMagicLinkRequest("/api/v1/auth/oidc/callback", OIDCCallbackRequest{Code: "good-code", State: state}, handler.OIDCCallback)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "renamed@example.com", loginEmail)
+	require.NotNil(t, updated, "the role follows the user's groups")
+	assert.Equal(t, models.RoleUser, updated.Role)
+}
+
+func TestAuthHandler_OIDCCallback_UnverifiedEmail(t *testing.T) {
+	handler, userRepo, refreshTokenRepo, _ := setupAuthTest()
+	identities := repository.NewMockUserIdentityRepository()
+	provider := &fakeOIDCProvider{identity: &oidc.Identity{
+		Issuer:  "https://idp.example.com",
+		Subject: "00u1abcd",
+		Email:   "rider@example.com",
+	}}
+	handler.WithOIDC(provider, identities, nil)
+
+	userRepo.GetByEmailFunc = func(_ context.Context, _ string) (*models.User, error) {
+		return &models.User{ID: uuid.New(), Email: "rider@example.com", IsActive: true}, nil
+	}
+	identities.CreateFunc = func(_ context.Context, _ *models.UserIdentity) error {
+		t.Fatal("accounts are not linked on unverified addresses")
+		return nil
+	}
+	refreshTokenRepo.CreateFunc = func(_ context.Context, _ *models.RefreshToken) error {
+		t.Fatal("no session is issued")
+		return nil
+	}
+
+	state := authorizeOIDC(t, handler)
+	w := performMagicLinkRequest("/api/v1/auth/oidc/callback", OIDCCallbackRequest{Code: "good-code", State: state}, handler.OIDCCallback)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "email_not_verified")
+}
+
+func TestAuthHandler_OIDCCallback_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		providerErr    error
+		code           string
+		state          string
+		expectedStatus int
+	}{
+		{"tampered state", nil, "good-code", "not-a-state", http.StatusUnauthorized},
+		{"rejected code", nil, "expired-code", "", http.StatusUnauthorized},
+		{"invalid ID token", oidc.ErrInvalidIDToken, "good-code", "", http.StatusUnauthorized},
+		{"provider unreachable", errors.New("connection refused"), "good-code", "", http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, _, _ := setupAuthTest()
+			provider := &fakeOIDCProvider{err: tt.providerErr}
+			handler.WithOIDC(provider, repository.NewMockUserIdentityRepository(), nil)
+
+			state := authorizeOIDC(t, handler)
+			if tt.state != "" {
+				state = tt.state
+			}
+			w := performMagicLinkRequest("/api/v1/auth/oidc/callback", OIDCCallbackRequest{Code: tt.code, State: state}, handler.OIDCCallback)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestAuthHandler_OIDC_NotConfigured(t *testing.T) {
+	handler, _, _, _ := setupAuthTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/authorize", nil)
+	handler.OIDCAuthorize(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = performMagicLinkRequest("/api/v1/auth/oidc/callback", OIDCCallbackRequest{Code: "good-code", State: "state"}, handler.OIDCCallback)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Page sizes for the dev console
+const (
+	defaultDevConsoleLimit = 50
+	maxDevConsoleLimit     = 500
+
+	// maxDevConsoleUsers caps the accounts whose devices are listed
+	maxDevConsoleUsers = 100
+)
+
+// SentEmails lists the emails recorded by a development email service
+type SentEmails interface {
+	Sent() []email.SentEmail
+}
+
+// DevConsoleHandler serves the data behind the development console
+// Its routes are only registered in development mode and are not authenticated, so they must
+// never be exposed in production.
+type DevConsoleHandler struct {
+	telemetryRepo repository.TelemetryRepository
+	userRepo      repository.UserRepository
+	deviceRepo    repository.DeviceRepository
+	emails        SentEmails // Optional: nil when emails are not recorded (e.g. Mailgun)
+}
+
+// devConsoleDevice is a device listed with its owner's email address
+type devConsoleDevice struct {
+	*models.DeviceResponse
+	OwnerEmail string `json:"ownerEmail"`
+}
+
+// NewDevConsoleHandler creates a new dev console handler
+func NewDevConsoleHandler(telemetryRepo repository.TelemetryRepository, userRepo repository.UserRepository, deviceRepo repository.DeviceRepository) *DevConsoleHandler {
+	return &DevConsoleHandler{
+		telemetryRepo: telemetryRepo,
+		userRepo:      userRepo,
+		deviceRepo:    deviceRepo,
+	}
+}
+
+// WithEmails shows the emails recorded by the console email service
+func (h *DevConsoleHandler) WithEmails(emails SentEmails) *DevConsoleHandler {
+	h.emails = emails
+	return h
+}
+
+// ListTelemetry lists the most recent telemetry of all users, optionally for one device
+// GET /dev/api/telemetry?deviceId=&limit=
+func (h *DevConsoleHandler) ListTelemetry(c *gin.Context) {
+	limit, ok := devConsoleLimit(c)
+	if !ok {
+		return
+	}
+
+	var (
+		records []*models.TelemetryData
+		err     error
+	)
+	if deviceID := strings.TrimSpace(c.Query("deviceId")); deviceID != "" {
+		records, err = h.telemetryRepo.GetByDevice(c.Request.Context(), deviceID, limit)
+	} else {
+		records, err = h.telemetryRepo.GetRecent(c.Request.Context(), limit)
+	}
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve telemetry: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"telemetry": records,
+		"count":     len(records),
+	})
+}
+
+// ListUsers lists registered accounts, newest first
+// GET /dev/api/users?q=&limit=
+func (h *DevConsoleHandler) ListUsers(c *gin.Context) {
+	limit, ok := devConsoleLimit(c)
+	if !ok {
+		return
+	}
+
+	users, err := h.userRepo.List(c.Request.Context(), repository.UserFilter{
+		Search: strings.TrimSpace(c.Query("q")),
+		Limit:  limit,
+	})
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve users: "+err.Error()))
+		return
+	}
+
+	response := make([]*models.UserResponse, len(users))
+	for i, user := range users {
+		response[i] = user.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": response,
+		"count": len(response),
+	})
+}
+
+// ListDevices lists the devices claimed by the most recent accounts
+// Devices are looked up per account, which is fine for development databases.
+// GET /dev/api/devices
+func (h *DevConsoleHandler) ListDevices(c *gin.Context) {
+	users, err := h.userRepo.List(c.Request.Context(), repository.UserFilter{Limit: maxDevConsoleUsers})
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve users: "+err.Error()))
+		return
+	}
+
+	devices := make([]devConsoleDevice, 0)
+	for _, user := range users {
+		owned, err := h.deviceRepo.ListByUserID(c.Request.Context(), user.ID)
+		if err != nil {
+			problem.Abort(c, problem.Internal("Failed to retrieve devices: "+err.Error()))
+			return
+		}
+		for _, device := range owned {
+			devices = append(devices, devConsoleDevice{DeviceResponse: device.ToResponse(), OwnerEmail: user.Email})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"devices": devices,
+		"count":   len(devices),
+	})
+}
+
+// ListEmails lists the emails recorded by the console email service, newest first
+// GET /dev/api/emails
+func (h *DevConsoleHandler) ListEmails(c *gin.Context) {
+	if h.emails == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"available": false,
+			"emails":    []email.SentEmail{},
+			"count":     0,
+		})
+		return
+	}
+
+	sent := h.emails.Sent()
+	c.JSON(http.StatusOK, gin.H{
+		"available": true,
+		"emails":    sent,
+		"count":     len(sent),
+	})
+}
+
+// devConsoleLimit parses the limit query parameter, responding 400 when it is out of range
+func devConsoleLimit(c *gin.Context) (int, bool) {
+	limit, err := parseIntQuery(c, "limit", defaultDevConsoleLimit)
+	if err != nil || limit <= 0 || limit > maxDevConsoleLimit {
+		problem.Abort(c, problem.BadRequest("invalid_request", "limit must be between 1 and "+strconv.Itoa(maxDevConsoleLimit)))
+		return 0, false
+	}
+	return limit, true
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// staticEmails returns a fixed list of sent emails
+type staticEmails []email.SentEmail
+
+func (e staticEmails) Sent() []email.SentEmail {
+	return e
+}
+
+func setupDevConsoleRouter(handler *DevConsoleHandler) *gin.Engine {
+	router := gin.New()
+	router.GET("/dev/api/telemetry", handler.ListTelemetry)
+	router.GET("/dev/api/users", handler.ListUsers)
+	router.GET("/dev/api/devices", handler.ListDevices)
+	router.GET("/dev/api/emails", handler.ListEmails)
+	return router
+}
+
+func getDevConsole(t *testing.T, router *gin.Engine, target string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+	return w, response
+}
+
+func TestDevConsoleHandler_ListTelemetry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	telemetryRepo := repository.NewMockRepository()
+	var recentLimit int
+	telemetryRepo.GetRecentFunc = func(_ context.Context, limit int) ([]*models.TelemetryData, error) {
+		recentLimit = limit
+		return []*models.TelemetryData{{DeviceID: "RACEBOX-001", Timestamp: time.Now()}}, nil
+	}
+	var deviceID string
+	telemetryRepo.GetByDeviceFunc = func(_ context.Context, id string, _ int) ([]*models.TelemetryData, error) {
+		deviceID = id
+		return []*models.TelemetryData{}, nil
+	}
+	router := setupDevConsoleRouter(NewDevConsoleHandler(telemetryRepo, repository.NewMockUserRepository(), repository.NewMockDeviceRepository()))
+
+	w, response := getDevConsole(t, router, "/dev/api/telemetry")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(1), response["count"])
+	assert.Equal(t, defaultDevConsoleLimit, recentLimit)
+
+	w, response = getDevConsole(t, router, "/dev/api/telemetry?deviceId=RACEBOX-002&limit=10")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "RACEBOX-002", deviceID)
+	assert.Equal(t, float64(0), response["count"])
+
+	w, _ = getDevConsole(t, router, "/dev/api/telemetry?limit=1000")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDevConsoleHandler_ListUsersAndDevices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &models.User{ID: uuid.New(), Email: "driver@example.com", PasswordHash: "secret-hash", IsActive: true, Role: models.RoleUser}
+	userRepo := repository.NewMockUserRepository()
+	var filter repository.UserFilter
+	userRepo.ListFunc = func(_ context.Context, f repository.UserFilter) ([]*models.User, error) {
+		filter = f
+		return []*models.User{user}, nil
+	}
+	deviceRepo := repository.NewMockDeviceRepository()
+	deviceRepo.ListByUserIDFunc = func(_ context.Context, userID uuid.UUID) ([]*models.Device, error) {
+		return []*models.Device{{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: userID, IsActive: true}}, nil
+	}
+	router := setupDevConsoleRouter(NewDevConsoleHandler(repository.NewMockRepository(), userRepo, deviceRepo))
+
+	w, response := getDevConsole(t, router, "/dev/api/users?q=driver")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "driver", filter.Search)
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+	users := response["users"].([]interface{})
+	require.Len(t, users, 1)
+	assert.Equal(t, "driver@example.com", users[0].(map[string]interface{})["email"])
+
+	w, response = getDevConsole(t, router, "/dev/api/devices")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, maxDevConsoleUsers, filter.Limit)
+	devices := response["devices"].([]interface{})
+	require.Len(t, devices, 1)
+	device := devices[0].(map[string]interface{})
+	assert.Equal(t, "RACEBOX-001", device["deviceId"])
+	assert.Equal(t, "driver@example.com", device["ownerEmail"])
+	assert.Equal(t, false, device["isOnline"])
+}
+
+func TestDevConsoleHandler_ListEmails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("recorded emails", func(t *testing.T) {
+		handler := NewDevConsoleHandler(repository.NewMockRepository(), repository.NewMockUserRepository(), repository.NewMockDeviceRepository()).
+			WithEmails(staticEmails{{Kind: "password_reset", To: "driver@example.com", Subject: "Password Reset Request"}})
+
+		w, response := getDevConsole(t, setupDevConsoleRouter(handler), "/dev/api/emails")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, true, response["available"])
+		assert.Equal(t, float64(1), response["count"])
+	})
+
+	t.Run("not recorded", func(t *testing.T) {
+		handler := NewDevConsoleHandler(repository.NewMockRepository(), repository.NewMockUserRepository(), repository.NewMockDeviceRepository())
+
+		w, response := getDevConsole(t, setupDevConsoleRouter(handler), "/dev/api/emails")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, false, response["available"])
+		assert.Empty(t, response["emails"])
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// ClaimBackfillQueue schedules a requested backfill of a claimed device's anonymous history
+type ClaimBackfillQueue interface {
+	Enqueue(id uuid.UUID)
+}
+
+// DeviceBackfillResponse is a device backfill with its progress
+type DeviceBackfillResponse struct {
+	*models.DeviceBackfill
+	Progress float64 `json:"progress"` // Percentage of totalRecords assigned so far
+}
+
+// WithBackfills enables backfills of the anonymous history of claimed devices
+func (h *DeviceHandler) WithBackfills(backfillRepo repository.DeviceBackfillRepository, queue ClaimBackfillQueue) *DeviceHandler {
+	h.backfillRepo = backfillRepo
+	h.backfillQueue = queue
+	return h
+}
+
+// BackfillDevice requests that the device's telemetry, sessions and upload batches uploaded before it had
+// an owner be assigned to its owner in the background
+// Requests while a backfill is running return that backfill instead of starting another.
+// POST /api/v1/devices/:id/backfill
+func (h *DeviceHandler) BackfillDevice(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	device, ok := h.managedDevice(c, userID)
+	if !ok {
+		return
+	}
+
+	status := http.StatusAccepted
+	backfill := &models.DeviceBackfill{DeviceID: device.ID, RequestedBy: &userID}
+	if err := h.backfillRepo.Create(c.Request.Context(), backfill); err != nil {
+		if !errors.Is(err, repository.ErrDeviceBackfillInProgress) {
+			slog.Error("Error creating device backfill", "device_id", device.ID, "error", err)
+			problem.Abort(c, problem.Internal("Failed to request backfill"))
+			return
+		}
+
+		if backfill, err = h.backfillRepo.GetLatest(c.Request.Context(), device.ID); err != nil {
+			problem.Abort(c, problem.Internal("Failed to retrieve backfill"))
+			return
+		}
+		status = http.StatusOK
+	} else if h.backfillQueue != nil {
+		h.backfillQueue.Enqueue(backfill.ID)
+	}
+
+	api.Respond(c, status, newDeviceBackfillResponse(backfill))
+}
+
+// GetDeviceBackfill retrieves the progress of the device's most recent backfill
+// GET /api/v1/devices/:id/backfill
+func (h *DeviceHandler) GetDeviceBackfill(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	device, ok := h.managedDevice(c, userID)
+	if !ok {
+		return
+	}
+
+	backfill, err := h.backfillRepo.GetLatest(c.Request.Context(), device.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceBackfillNotFound) {
+			problem.Abort(c, problem.NotFound("backfill_not_found", "No backfill has been requested for this device"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve backfill"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, newDeviceBackfillResponse(backfill))
+}
+
+// managedDevice loads the device named in the path if the user owns it or manages its organization
+func (h *DeviceHandler) managedDevice(c *gin.Context, userID uuid.UUID) (*models.Device, bool) {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return nil, false
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return nil, false
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return nil, false
+	}
+
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessManage, "device") {
+		return nil, false
+	}
+	return device, true
+}
+
+// newDeviceBackfillResponse computes the backfill's progress
+func newDeviceBackfillResponse(backfill *models.DeviceBackfill) DeviceBackfillResponse {
+	var progress float64
+	switch {
+	case backfill.Status == models.BackfillCompleted:
+		progress = 100
+	case backfill.TotalRecords > 0:
+		progress = math.Round(float64(backfill.AssignedRecords)/float64(backfill.TotalRecords)*1000) / 10
+	}
+	return DeviceBackfillResponse{DeviceBackfill: backfill, Progress: progress}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backfillQueueFunc adapts a function to ClaimBackfillQueue
+type backfillQueueFunc func(id uuid.UUID)
+
+func (f backfillQueueFunc) Enqueue(id uuid.UUID) {
+	f(id)
+}
+
+// serveBackfill runs a backfill handler for the device as the given user
+func serveBackfill(handle gin.HandlerFunc, method string, userID uuid.UUID, deviceID string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/api/v1/devices/"+deviceID+"/backfill", nil)
+	c.Params = gin.Params{{Key: "id", Value: deviceID}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handle(c)
+	return w
+}
+
+func TestDeviceHandler_BackfillDevice(t *testing.T) {
+	ownerID := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: ownerID, IsActive: true}
+
+	handler, deviceRepo := setupDeviceTest()
+	deviceRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Device, error) {
+		if id == device.ID {
+			return device, nil
+		}
+		return nil, repository.ErrDeviceNotFound
+	}
+
+	var created *models.DeviceBackfill
+	backfillRepo := repository.NewMockDeviceBackfillRepository()
+	backfillRepo.CreateFunc = func(_ context.Context, backfill *models.DeviceBackfill) error {
+		if created != nil {
+			return repository.ErrDeviceBackfillInProgress
+		}
+		backfill.ID = uuid.New()
+		backfill.Status = models.BackfillPending
+		created = backfill
+		return nil
+	}
+	backfillRepo.GetLatestFunc = func(_ context.Context, id uuid.UUID) (*models.DeviceBackfill, error) {
+		assert.Equal(t, device.ID, id)
+		return &models.DeviceBackfill{ID: created.ID, DeviceID: device.ID, Status: models.BackfillProcessing, TotalRecords: 8, AssignedRecords: 2}, nil
+	}
+
+	var queued []uuid.UUID
+	handler.WithBackfills(backfillRepo, backfillQueueFunc(func(id uuid.UUID) {
+		queued = append(queued, id)
+	}))
+
+	w := serveBackfill(handler.BackfillDevice, http.MethodPost, ownerID, device.ID.String())
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	require.NotNil(t, created)
+	assert.Equal(t, device.ID, created.DeviceID)
+	assert.Equal(t, ownerID, *created.RequestedBy)
+	assert.Equal(t, []uuid.UUID{created.ID}, queued)
+
+	// Repeating the request returns the running backfill
+	w = serveBackfill(handler.BackfillDevice, http.MethodPost, ownerID, device.ID.String())
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Len(t, queued, 1)
+
+	var response DeviceBackfillResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, created.ID, response.ID)
+	assert.Equal(t, models.BackfillProcessing, response.Status)
+	assert.Equal(t, 25.0, response.Progress)
+
+	// Only the owner or the managers of its organization may request one
+	w = serveBackfill(handler.BackfillDevice, http.MethodPost, uuid.New(), device.ID.String())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serveBackfill(handler.BackfillDevice, http.MethodPost, ownerID, uuid.New().String())
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveBackfill(handler.BackfillDevice, http.MethodPost, ownerID, "not-a-uuid")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeviceHandler_GetDeviceBackfill(t *testing.T) {
+	ownerID := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: ownerID, IsActive: true}
+
+	handler, deviceRepo := setupDeviceTest()
+	deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+		return device, nil
+	}
+
+	backfillRepo := repository.NewMockDeviceBackfillRepository()
+	handler.WithBackfills(backfillRepo, nil)
+
+	w := serveBackfill(handler.GetDeviceBackfill, http.MethodGet, ownerID, device.ID.String())
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	backfillRepo.GetLatestFunc = func(_ context.Context, _ uuid.UUID) (*models.DeviceBackfill, error) {
+		return &models.DeviceBackfill{ID: uuid.New(), DeviceID: device.ID, Status: models.BackfillCompleted, TotalRecords: 0}, nil
+	}
+
+	w = serveBackfill(handler.GetDeviceBackfill, http.MethodGet, ownerID, device.ID.String())
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response DeviceBackfillResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.BackfillCompleted, response.Status)
+	assert.Equal(t, 100.0, response.Progress)
+
+	w = serveBackfill(handler.GetDeviceBackfill, http.MethodGet, uuid.New(), device.ID.String())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// maxBulkDevices is the maximum number of devices registered by one bulk request
+const maxBulkDevices = 500
+
+// Per-row statuses of a bulk device registration
+const (
+	bulkDeviceClaimed  = "claimed"  // Newly claimed by the caller
+	bulkDeviceOwned    = "owned"    // Already owned by the caller, left unchanged
+	bulkDeviceConflict = "conflict" // Owned by another user, left unchanged
+	bulkDeviceInvalid  = "invalid"  // Failed validation; nothing was registered
+)
+
+// BulkDeviceRow is a device to register in bulk
+type BulkDeviceRow struct {
+	DeviceID   string  `json:"deviceId"`
+	DeviceName *string `json:"deviceName,omitempty"`
+}
+
+// bulkDeviceResult reports the outcome of one row of a bulk device registration
+type bulkDeviceResult struct {
+	Index    int             `json:"index"` // 0-based position among the request's devices
+	DeviceID string          `json:"deviceId"`
+	Status   string          `json:"status"`
+	Device   *DeviceResponse `json:"device,omitempty"` // The stored device; omitted for conflicts and invalid rows
+	Error    string          `json:"error,omitempty"`
+}
+
+// WithQuotas enforces the caller's plan device limit on bulk registration
+func (h *DeviceHandler) WithQuotas(quotas *Quotas) *DeviceHandler {
+	h.quotas = quotas
+	return h
+}
+
+// WithEvents publishes a device.claimed event for every device claimed by bulk registration
+func (h *DeviceHandler) WithEvents(publisher EventPublisher) *DeviceHandler {
+	h.events = publisher
+	return h
+}
+
+// RegisterDevices claims many devices at once for fleet onboarding
+// The body is a JSON array of {deviceId, deviceName} objects, or text/csv with one device per line:
+// its serial, then an optional name, after an optional header row starting with deviceId.
+// Devices are owned by the caller and shared with orgId when given, which requires the caller to
+// manage that organization; without orgId the caller must be an administrator. Every row is
+// validated before anything is claimed and all claims are made in one transaction. Devices that
+// already exist are left unchanged and reported per row, and the response is 207 Multi-Status when
+// any of them belongs to another user.
+// POST /api/v1/devices/bulk?orgId=
+func (h *DeviceHandler) RegisterDevices(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var orgID *uuid.UUID
+	if raw := c.Query("orgId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_request", "orgId must be a valid UUID"))
+			return
+		}
+		if !h.orgs.authorize(c, userID, nil, &id, accessManage, "organization") {
+			return
+		}
+		orgID = &id
+	} else if !middleware.HasScope(c, models.ScopeAdmin) {
+		problem.Abort(c, problem.Forbidden("forbidden", "Bulk registration requires orgId of an organization you manage"))
+		return
+	}
+
+	rows, err := bindBulkDevices(c)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+	if len(rows) == 0 {
+		problem.Abort(c, problem.BadRequest("empty_batch", "No devices to register"))
+		return
+	}
+	if len(rows) > maxBulkDevices {
+		problem.Abort(c, problem.BadRequest("batch_too_large", fmt.Sprintf("Too many devices (max %d)", maxBulkDevices)))
+		return
+	}
+	if invalid := validateBulkDevices(rows); len(invalid) > 0 {
+		problem.Abort(c, problem.BadRequest("invalid_devices", "Some devices are invalid; none were registered").
+			With("results", invalid))
+		return
+	}
+
+	// Rows count against the limit even if the caller already owns their devices
+	if h.quotas != nil {
+		if remaining := h.quotas.remainingDevices(c.Request.Context(), userID); remaining >= 0 && int64(len(rows)) > remaining {
+			problem.Abort(c, problem.New(http.StatusPaymentRequired, "device_limit_reached", "Device limit reached for your plan").
+				With("remaining", remaining))
+			return
+		}
+	}
+
+	now := time.Now()
+	devices := make([]*models.Device, len(rows))
+	for i, row := range rows {
+		devices[i] = &models.Device{
+			ID:         uuid.New(),
+			DeviceID:   row.DeviceID,
+			UserID:     userID,
+			OrgID:      orgID,
+			DeviceName: row.DeviceName,
+			ClaimedAt:  now,
+			IsActive:   true,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+
+	ctx := withDeviceClaimedEvents(c.Request.Context(), h.events, models.DeviceClaimBulk)
+	stored, err := h.deviceRepo.ClaimAll(ctx, devices)
+	if err != nil {
+		slog.Error("Error registering devices in bulk", "user_id", userID, "error", err)
+		problem.Abort(c, problem.Internal("Failed to register devices"))
+		return
+	}
+
+	results := make([]bulkDeviceResult, len(stored))
+	counts := make(map[string]int)
+	for i, device := range stored {
+		results[i] = bulkDeviceResult{Index: i, DeviceID: device.DeviceID}
+		switch {
+		case device.ID == devices[i].ID:
+			results[i].Status, results[i].Device = bulkDeviceClaimed, bulkDeviceResponse(device)
+			recordOwnershipChange(c.Request.Context(), h.ownershipRepo, &models.DeviceOwnershipChange{
+				DeviceID: device.ID,
+				Action:   models.OwnershipActionClaim,
+				ToUserID: &userID,
+				ActorID:  &userID,
+			})
+		case device.UserID == userID:
+			results[i].Status, results[i].Device = bulkDeviceOwned, bulkDeviceResponse(device)
+		default:
+			results[i].Status, results[i].Error = bulkDeviceConflict, "device is claimed by another user"
+		}
+		counts[results[i].Status]++
+	}
+
+	if counts[bulkDeviceClaimed] > 0 {
+		notifyEvents(h.events)
+	}
+
+	slog.Info("Devices registered in bulk", "user_id", userID, "org_id", orgID,
+		"claimed", counts[bulkDeviceClaimed], "owned", counts[bulkDeviceOwned], "conflicts", counts[bulkDeviceConflict])
+
+	status := http.StatusCreated
+	if counts[bulkDeviceConflict] > 0 {
+		status = http.StatusMultiStatus
+	}
+	api.Respond(c, status, gin.H{
+		"claimed":   counts[bulkDeviceClaimed],
+		"owned":     counts[bulkDeviceOwned],
+		"conflicts": counts[bulkDeviceConflict],
+		"results":   results,
+	})
+}
+
+// bindBulkDevices reads the devices of a bulk registration from a JSON or CSV body
+func bindBulkDevices(c *gin.Context) ([]BulkDeviceRow, error) {
+	if c.ContentType() == "text/csv" {
+		return parseBulkDevicesCSV(c.Request.Body)
+	}
+
+	var rows []BulkDeviceRow
+	if err := json.NewDecoder(c.Request.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	return rows, nil
+}
+
+// parseBulkDevicesCSV reads one device per line: its serial, then an optional name
+// A first line whose serial is "deviceId" is a header and skipped. Fields are trimmed.
+func parseBulkDevicesCSV(r io.Reader) ([]BulkDeviceRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows := make([]BulkDeviceRow, 0)
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(record) > 2 {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("invalid CSV: line %d has more than 2 fields", line)
+		}
+
+		row := BulkDeviceRow{DeviceID: strings.TrimSpace(record[0])}
+		if first && strings.EqualFold(row.DeviceID, "deviceId") {
+			continue
+		}
+		if len(record) == 2 {
+			if name := strings.TrimSpace(record[1]); name != "" {
+				row.DeviceName = &name
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// validateBulkDevices returns the results of the invalid rows, or nil if every row is valid
+func validateBulkDevices(rows []BulkDeviceRow) []bulkDeviceResult {
+	var invalid []bulkDeviceResult
+	seen := make(map[string]int, len(rows))
+	for i, row := range rows {
+		var reason string
+		first, duplicate := seen[row.DeviceID]
+		switch {
+		case row.DeviceID == "":
+			reason = "deviceId is required"
+		case len(row.DeviceID) > 50:
+			reason = "deviceId must be at most 50 characters"
+		case row.DeviceName != nil && len(*row.DeviceName) > 255:
+			reason = "deviceName must be at most 255 characters"
+		case duplicate:
+			reason = fmt.Sprintf("deviceId duplicates index %d", first)
+		}
+		if !duplicate {
+			seen[row.DeviceID] = i
+		}
+		if reason != "" {
+			invalid = append(invalid, bulkDeviceResult{Index: i, DeviceID: row.DeviceID, Status: bulkDeviceInvalid, Error: reason})
+		}
+	}
+	return invalid
+}
+
+// bulkDeviceResponse converts a device stored by a bulk registration to its response
+func bulkDeviceResponse(device *models.Device) *DeviceResponse {
+	return &DeviceResponse{
+		ID:          device.ID.String(),
+		DeviceID:    device.DeviceID,
+		DeviceName:  device.DeviceName,
+		DeviceModel: device.DeviceModel,
+		ClaimedAt:   device.ClaimedAt.Format("2006-01-02T15:04:05Z07:00"),
+		IsActive:    device.IsActive,
+		OrgID:       device.OrgID,
+		CreatedAt:   device.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   device.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		RequireSignedUploads: device.RequireSignedUploads,
+	}
+}
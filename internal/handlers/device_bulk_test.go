@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bulkRegisterResponse is the body of a successful bulk device registration
+type bulkRegisterResponse struct {
+	Claimed   int                `json:"claimed"`
+	Owned     int                `json:"owned"`
+	Conflicts int                `json:"conflicts"`
+	Results   []bulkDeviceResult `json:"results"`
+}
+
+func performBulkRegister(handler *DeviceHandler, userID uuid.UUID, scopes []models.Scope, query, contentType, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/devices/bulk"+query, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	c.Set(string(middleware.UserIDKey), userID)
+	c.Set(string(middleware.ScopesKey), scopes)
+	handler.RegisterDevices(c)
+	return w
+}
+
+func TestDeviceHandler_RegisterDevices(t *testing.T) {
+	userID := uuid.New()
+	otherID := uuid.New()
+	admin := models.ScopesForRole(models.RoleAdmin)
+
+	publisher := &recordingPublisher{}
+	deviceRepo := repository.NewMockDeviceRepository()
+	var claimed []*models.Device
+	deviceRepo.ClaimAllFunc = func(ctx context.Context, devices []*models.Device) ([]*models.Device, error) {
+		claimed = devices
+		stored := make([]*models.Device, len(devices))
+		var created []*models.Device
+		for i, device := range devices {
+			switch device.DeviceID {
+			case "AVT-OWNED":
+				stored[i] = &models.Device{ID: uuid.New(), DeviceID: device.DeviceID, UserID: userID}
+			case "AVT-TAKEN":
+				stored[i] = &models.Device{ID: uuid.New(), DeviceID: device.DeviceID, UserID: otherID}
+			default:
+				stored[i] = device
+				created = append(created, device)
+			}
+		}
+		storeEvents(t, publisher, ctx, created)
+		return stored, nil
+	}
+	ownershipRepo := repository.NewMockDeviceOwnershipRepository()
+	var history []*models.DeviceOwnershipChange
+	ownershipRepo.RecordFunc = func(_ context.Context, change *models.DeviceOwnershipChange) error {
+		history = append(history, change)
+		return nil
+	}
+	handler := NewDeviceHandler(deviceRepo).WithOwnershipHistory(ownershipRepo).WithEvents(publisher)
+
+	t.Run("json", func(t *testing.T) {
+		claimed, history = nil, nil
+		w := performBulkRegister(handler, userID, admin, "", "application/json",
+			`[{"deviceId":"AVT-001","deviceName":"Kart 1"},{"deviceId":"AVT-OWNED"}]`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response bulkRegisterResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Claimed)
+		assert.Equal(t, 1, response.Owned)
+		require.Len(t, response.Results, 2)
+		assert.Equal(t, bulkDeviceClaimed, response.Results[0].Status)
+		assert.Equal(t, "Kart 1", *response.Results[0].Device.DeviceName)
+		assert.Equal(t, bulkDeviceOwned, response.Results[1].Status)
+
+		require.Len(t, claimed, 2)
+		assert.Equal(t, userID, claimed[0].UserID)
+		assert.Nil(t, claimed[0].OrgID)
+		require.Len(t, history, 1, "only new devices enter the ownership history")
+		assert.Equal(t, models.OwnershipActionClaim, history[0].Action)
+		claims := decodeEvents[models.DeviceClaimed](t, publisher, models.EventDeviceClaimed)
+		require.Len(t, claims, 1, "only new devices are published")
+		assert.Equal(t, "AVT-001", claims[0].DeviceID)
+		assert.Equal(t, models.DeviceClaimBulk, claims[0].Source)
+		assert.Equal(t, 1, publisher.notified)
+	})
+
+	t.Run("csv with conflicts", func(t *testing.T) {
+		w := performBulkRegister(handler, userID, admin, "", "text/csv",
+			"deviceId,name\nAVT-001, Kart 1\nAVT-TAKEN\n\nAVT-002,\n")
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		var response bulkRegisterResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Claimed)
+		assert.Equal(t, 1, response.Conflicts)
+		require.Len(t, response.Results, 3)
+		assert.Equal(t, bulkDeviceConflict, response.Results[1].Status)
+		assert.Nil(t, response.Results[1].Device, "other users' devices are not disclosed")
+		assert.Nil(t, response.Results[2].Device.DeviceName)
+	})
+
+	t.Run("invalid rows reject the request", func(t *testing.T) {
+		claimed = nil
+		w := performBulkRegister(handler, userID, admin, "", "application/json",
+			`[{"deviceId":"AVT-001"},{"deviceId":""},{"deviceId":"AVT-001"},{"deviceId":"`+strings.Repeat("A", 51)+`"}]`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Nil(t, claimed)
+		var response struct {
+			Results []bulkDeviceResult `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Results, 3)
+		assert.Equal(t, []int{1, 2, 3}, []int{response.Results[0].Index, response.Results[1].Index, response.Results[2].Index})
+		assert.Equal(t, "deviceId duplicates index 0", response.Results[1].Error)
+	})
+
+	t.Run("request errors", func(t *testing.T) {
+		for name, body := range map[string]string{
+			"empty":     `[]`,
+			"not array": `{"deviceId":"AVT-001"}`,
+			"too many":  `[` + strings.Repeat(`{"deviceId":"AVT"},`, maxBulkDevices) + `{"deviceId":"AVT"}]`,
+		} {
+			w := performBulkRegister(handler, userID, admin, "", "application/json", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		}
+		w := performBulkRegister(handler, userID, admin, "", "text/csv", "AVT-001,Kart 1,extra\n")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("users need an organization", func(t *testing.T) {
+		w := performBulkRegister(handler, userID, models.ScopesForRole(models.RoleUser), "", "application/json", `[{"deviceId":"AVT-001"}]`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestDeviceHandler_RegisterDevices_Organization(t *testing.T) {
+	orgID := uuid.New()
+	user := models.ScopesForRole(models.RoleUser)
+
+	tests := []struct {
+		name           string
+		role           models.OrgRole // Empty for non-members
+		expectedStatus int
+	}{
+		{name: "owner", role: models.OrgRoleOwner, expectedStatus: http.StatusCreated},
+		{name: "admin", role: models.OrgRoleAdmin, expectedStatus: http.StatusCreated},
+		{name: "member", role: models.OrgRoleMember, expectedStatus: http.StatusForbidden},
+		{name: "non-member", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceRepo := repository.NewMockDeviceRepository()
+			var claimed []*models.Device
+			deviceRepo.ClaimAllFunc = func(_ context.Context, devices []*models.Device) ([]*models.Device, error) {
+				claimed = devices
+				return devices, nil
+			}
+			orgRepo := repository.NewMockOrganizationRepository()
+			orgRepo.GetMemberFunc = func(_ context.Context, id, memberID uuid.UUID) (*models.OrganizationMember, error) {
+				if tt.role == "" {
+					return nil, repository.ErrOrgMemberNotFound
+				}
+				return &models.OrganizationMember{OrgID: id, UserID: memberID, Role: tt.role}, nil
+			}
+			handler := NewDeviceHandler(deviceRepo).WithOrganizations(orgRepo)
+
+			w := performBulkRegister(handler, uuid.New(), user, "?orgId="+orgID.String(), "application/json", `[{"deviceId":"AVT-001"}]`)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, claimed)
+				return
+			}
+			require.Len(t, claimed, 1)
+			assert.Equal(t, &orgID, claimed[0].OrgID)
+		})
+	}
+}
+
+func TestDeviceHandler_RegisterDevices_DeviceLimit(t *testing.T) {
+	policy := QuotaPolicy{Plans: map[models.Plan]models.PlanLimits{
+		models.PlanFree: {MaxDevices: 3},
+	}}
+	deviceRepo := repository.NewMockDeviceRepository()
+	deviceRepo.ListFunc = func(_ context.Context, _ repository.DeviceFilter) ([]*models.Device, int, error) {
+		return []*models.Device{}, 1, nil
+	}
+	handler := NewDeviceHandler(deviceRepo).WithQuotas(NewQuotas(repository.NewMockUsageRepository(), deviceRepo, policy))
+	admin := models.ScopesForRole(models.RoleAdmin)
+
+	w := performBulkRegister(handler, uuid.New(), admin, "", "text/csv", "AVT-001\nAVT-002\n")
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = performBulkRegister(handler, uuid.New(), admin, "", "text/csv", "AVT-001\nAVT-002\nAVT-003\n")
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+	assert.Contains(t, w.Body.String(), `"remaining":2`)
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// defaultDeviceCommandTTL is how long a command waits for its device when no TTL is given
+	defaultDeviceCommandTTL = time.Hour
+
+	// defaultDeviceCommandLimit and maxDeviceCommandLimit bound command listings and polls
+	defaultDeviceCommandLimit = 50
+	maxDeviceCommandLimit     = 100
+)
+
+// CreateDeviceCommandRequest represents the request body queueing a command for a device
+type CreateDeviceCommandRequest struct {
+	Type       models.DeviceCommandType `json:"type" binding:"required"`
+	Params     map[string]interface{}   `json:"params,omitempty"`
+	TTLSeconds int                      `json:"ttlSeconds,omitempty" binding:"omitempty,min=10,max=604800"` // Defaults to one hour
+}
+
+// AcknowledgeDeviceCommandRequest represents the outcome a device reports for a command
+type AcknowledgeDeviceCommandRequest struct {
+	Status  models.DeviceCommandStatus `json:"status" binding:"required,oneof=succeeded failed"`
+	Message string                     `json:"message,omitempty" binding:"max=1000"`
+}
+
+// WithCommandRepo enables the device command queue
+func (h *DeviceHandler) WithCommandRepo(commandRepo repository.DeviceCommandRepository) *DeviceHandler {
+	h.commandRepo = commandRepo
+	return h
+}
+
+// CreateDeviceCommand queues a remote action for the device
+// The device receives it on its next poll of ListDeviceCommands.
+// POST /api/v1/devices/:id/commands
+func (h *DeviceHandler) CreateDeviceCommand(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	device, ok := h.managedDevice(c, userID)
+	if !ok {
+		return
+	}
+
+	var req CreateDeviceCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+	if !req.Type.IsValid() {
+		problem.Abort(c, problem.BadRequest("invalid_command_type", "type must be one of: start_session, stop_session, flush_buffer, reboot"))
+		return
+	}
+
+	ttl := defaultDeviceCommandTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	now := time.Now()
+	command := &models.DeviceCommand{
+		ID:        uuid.New(),
+		DeviceID:  device.ID,
+		Type:      req.Type,
+		Params:    req.Params,
+		Status:    models.DeviceCommandPending,
+		CreatedBy: &userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := h.commandRepo.Create(c.Request.Context(), command); err != nil {
+		problem.Abort(c, problem.Internal("Failed to queue device command"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, command)
+}
+
+// ListDeviceCommands returns the device's commands
+// Devices poll it with their API key, which must belong to the device: the response holds the
+// commands not yet acknowledged, oldest first, which are returned again on every poll until the
+// device acknowledges them. Users who can view the device get its command history, most recent
+// first, optionally filtered by status.
+// GET /api/v1/devices/:id/commands?status=&limit=
+func (h *DeviceHandler) ListDeviceCommands(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		problem.Abort(c, problem.Unauthorized("unauthorized", "Authentication required: provide a bearer token or "+middleware.DeviceKeyHeader))
+		return
+	}
+
+	device, ok := h.commandDevice(c, userID)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeviceCommandLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxDeviceCommandLimit {
+			problem.Abort(c, problem.BadRequest("invalid_request", "limit must be between 1 and "+strconv.Itoa(maxDeviceCommandLimit)))
+			return
+		}
+	}
+
+	var commands []*models.DeviceCommand
+	if _, polling := middleware.GetDeviceID(c); polling {
+		commands, err = h.commandRepo.Poll(c.Request.Context(), device.ID, limit)
+	} else {
+		status := models.DeviceCommandStatus(c.Query("status"))
+		commands, err = h.commandRepo.List(c.Request.Context(), device.ID, repository.DeviceCommandFilter{Status: status, Limit: limit})
+	}
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve device commands"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "commands", commands, api.Meta{
+		"count": len(commands),
+	})
+}
+
+// GetDeviceCommand returns one of the device's commands, to follow its progress
+// GET /api/v1/devices/:id/commands/:commandId
+func (h *DeviceHandler) GetDeviceCommand(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	device, ok := h.commandDevice(c, userID)
+	if !ok {
+		return
+	}
+
+	commandID, ok := parseDeviceCommandID(c)
+	if !ok {
+		return
+	}
+
+	command, err := h.commandRepo.Get(c.Request.Context(), device.ID, commandID)
+	if err != nil {
+		respondDeviceCommandError(c, err, "Failed to retrieve device command")
+		return
+	}
+
+	api.Respond(c, http.StatusOK, command)
+}
+
+// CancelDeviceCommand withdraws a command the device has not acknowledged
+// A device that already polled the command may still carry it out.
+// DELETE /api/v1/devices/:id/commands/:commandId
+func (h *DeviceHandler) CancelDeviceCommand(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	device, ok := h.managedDevice(c, userID)
+	if !ok {
+		return
+	}
+
+	commandID, ok := parseDeviceCommandID(c)
+	if !ok {
+		return
+	}
+
+	command, err := h.commandRepo.Cancel(c.Request.Context(), device.ID, commandID)
+	if err != nil {
+		respondDeviceCommandError(c, err, "Failed to cancel device command")
+		return
+	}
+
+	api.Respond(c, http.StatusOK, command)
+}
+
+// AcknowledgeDeviceCommand records whether the device carried out a command
+// Only the device's API key can acknowledge its commands.
+// POST /api/v1/devices/:id/commands/:commandId/ack
+func (h *DeviceHandler) AcknowledgeDeviceCommand(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if _, ok := middleware.GetDeviceID(c); err != nil || !ok {
+		problem.Abort(c, problem.Unauthorized("unauthorized", "Authentication required: provide "+middleware.DeviceKeyHeader))
+		return
+	}
+
+	device, ok := h.commandDevice(c, userID)
+	if !ok {
+		return
+	}
+
+	commandID, ok := parseDeviceCommandID(c)
+	if !ok {
+		return
+	}
+
+	var req AcknowledgeDeviceCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	command, err := h.commandRepo.Acknowledge(c.Request.Context(), device.ID, commandID, req.Status, req.Message)
+	if err != nil {
+		respondDeviceCommandError(c, err, "Failed to acknowledge device command")
+		return
+	}
+
+	api.Respond(c, http.StatusOK, command)
+}
+
+// commandDevice loads the device named by the id path parameter for a command endpoint
+// Device keys must be bound to the device and users must be able to view it. It writes the
+// error response and returns false when the device cannot be used.
+func (h *DeviceHandler) commandDevice(c *gin.Context, userID uuid.UUID) (*models.Device, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return nil, false
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return nil, false
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return nil, false
+	}
+
+	// A device key acts for the device's owner, so it must also be bound to this device
+	if keyDeviceID, ok := middleware.GetDeviceID(c); ok && keyDeviceID != device.DeviceID {
+		problem.Abort(c, problem.Forbidden("forbidden", "Device key does not belong to this device"))
+		return nil, false
+	}
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessUse, "device") {
+		return nil, false
+	}
+	return device, true
+}
+
+// parseDeviceCommandID parses the commandId path parameter, writing the error response when it is invalid
+func parseDeviceCommandID(c *gin.Context) (uuid.UUID, bool) {
+	commandID, err := uuid.Parse(c.Param("commandId"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_command_id", "Invalid command ID format"))
+		return uuid.Nil, false
+	}
+	return commandID, true
+}
+
+// respondDeviceCommandError writes the response for a failed command lookup or update
+func respondDeviceCommandError(c *gin.Context, err error, detail string) {
+	switch {
+	case errors.Is(err, repository.ErrDeviceCommandNotFound):
+		problem.Abort(c, problem.NotFound("command_not_found", "Command not found"))
+	case errors.Is(err, repository.ErrDeviceCommandFinished):
+		problem.Abort(c, problem.Conflict("command_finished", "The command was already acknowledged, cancelled or expired"))
+	default:
+		problem.Abort(c, problem.Internal(detail))
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDeviceCommandContext builds a request to a device command endpoint
+// userID and keyDeviceID are only set on the context when not empty.
+func newDeviceCommandContext(method, target, body string, params gin.Params, userID *uuid.UUID, keyDeviceID string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	if userID != nil {
+		c.Set(string(middleware.UserIDKey), *userID)
+	}
+	if keyDeviceID != "" {
+		c.Set(string(middleware.DeviceIDKey), keyDeviceID)
+	}
+	return c, w
+}
+
+func TestDeviceHandler_CreateDeviceCommand(t *testing.T) {
+	ownerID := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "AVT-001", UserID: ownerID, IsActive: true}
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		body           string
+		expectedTTL    time.Duration
+		expectedStatus int
+	}{
+		{name: "default expiry", userID: ownerID, body: `{"type":"flush_buffer"}`, expectedTTL: time.Hour, expectedStatus: http.StatusCreated},
+		{name: "custom expiry", userID: ownerID, body: `{"type":"start_session","params":{"name":"Track day"},"ttlSeconds":300}`, expectedTTL: 5 * time.Minute, expectedStatus: http.StatusCreated},
+		{name: "unknown type", userID: ownerID, body: `{"type":"self_destruct"}`, expectedStatus: http.StatusBadRequest},
+		{name: "expiry too short", userID: ownerID, body: `{"type":"reboot","ttlSeconds":1}`, expectedStatus: http.StatusBadRequest},
+		{name: "another user's device", userID: uuid.New(), body: `{"type":"reboot"}`, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo := setupDeviceTest()
+			commandRepo := repository.NewMockDeviceCommandRepository()
+			handler.WithCommandRepo(commandRepo)
+
+			deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+				return device, nil
+			}
+			var stored *models.DeviceCommand
+			commandRepo.CreateFunc = func(_ context.Context, command *models.DeviceCommand) error {
+				stored = command
+				return nil
+			}
+
+			c, w := newDeviceCommandContext(http.MethodPost, "/api/v1/devices/"+device.ID.String()+"/commands", tt.body,
+				gin.Params{{Key: "id", Value: device.ID.String()}}, &tt.userID, "")
+			handler.CreateDeviceCommand(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, stored)
+				return
+			}
+
+			require.NotNil(t, stored)
+			assert.Equal(t, device.ID, stored.DeviceID)
+			assert.Equal(t, models.DeviceCommandPending, stored.Status)
+			assert.Equal(t, ownerID, *stored.CreatedBy)
+			assert.Equal(t, tt.expectedTTL, stored.ExpiresAt.Sub(stored.CreatedAt))
+		})
+	}
+}
+
+func TestDeviceHandler_ListDeviceCommands(t *testing.T) {
+	ownerID, otherID := uuid.New(), uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "AVT-001", UserID: ownerID, IsActive: true}
+
+	tests := []struct {
+		name           string
+		userID         *uuid.UUID
+		keyDeviceID    string
+		query          string
+		expectPoll     bool
+		expectedStatus int
+	}{
+		{name: "device polls", userID: &ownerID, keyDeviceID: "AVT-001", expectPoll: true, expectedStatus: http.StatusOK},
+		{name: "owner lists history", userID: &ownerID, query: "?status=failed&limit=10", expectedStatus: http.StatusOK},
+		{name: "key of another device", userID: &ownerID, keyDeviceID: "AVT-002", expectedStatus: http.StatusForbidden},
+		{name: "another user", userID: &otherID, expectedStatus: http.StatusForbidden},
+		{name: "limit too high", userID: &ownerID, query: "?limit=1000", expectedStatus: http.StatusBadRequest},
+		{name: "anonymous", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo := setupDeviceTest()
+			commandRepo := repository.NewMockDeviceCommandRepository()
+			handler.WithCommandRepo(commandRepo)
+
+			deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+				return device, nil
+			}
+			command := &models.DeviceCommand{ID: uuid.New(), DeviceID: device.ID, Type: models.DeviceCommandReboot}
+			var polled, listed bool
+			commandRepo.PollFunc = func(_ context.Context, deviceID uuid.UUID, limit int) ([]*models.DeviceCommand, error) {
+				assert.Equal(t, device.ID, deviceID)
+				assert.Equal(t, defaultDeviceCommandLimit, limit)
+				polled = true
+				command.Status = models.DeviceCommandDelivered
+				return []*models.DeviceCommand{command}, nil
+			}
+			commandRepo.ListFunc = func(_ context.Context, _ uuid.UUID, filter repository.DeviceCommandFilter) ([]*models.DeviceCommand, error) {
+				assert.Equal(t, models.DeviceCommandFailed, filter.Status)
+				assert.Equal(t, 10, filter.Limit)
+				listed = true
+				command.Status = models.DeviceCommandFailed
+				return []*models.DeviceCommand{command}, nil
+			}
+
+			c, w := newDeviceCommandContext(http.MethodGet, "/api/v1/devices/"+device.ID.String()+"/commands"+tt.query, "",
+				gin.Params{{Key: "id", Value: device.ID.String()}}, tt.userID, tt.keyDeviceID)
+			handler.ListDeviceCommands(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				assert.False(t, polled || listed)
+				return
+			}
+			assert.Equal(t, tt.expectPoll, polled)
+			assert.Equal(t, !tt.expectPoll, listed)
+
+			var response struct {
+				Commands []models.DeviceCommand `json:"commands"`
+				Count    int                    `json:"count"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Len(t, response.Commands, 1)
+			assert.Equal(t, command.ID, response.Commands[0].ID)
+		})
+	}
+}
+
+func TestDeviceHandler_AcknowledgeDeviceCommand(t *testing.T) {
+	ownerID := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "AVT-001", UserID: ownerID, IsActive: true}
+	commandID := uuid.New()
+
+	tests := []struct {
+		name           string
+		keyDeviceID    string
+		body           string
+		repoErr        error
+		expectedStatus int
+	}{
+		{name: "succeeded", keyDeviceID: "AVT-001", body: `{"status":"succeeded"}`, expectedStatus: http.StatusOK},
+		{name: "failed with message", keyDeviceID: "AVT-001", body: `{"status":"failed","message":"no GPS fix"}`, expectedStatus: http.StatusOK},
+		{name: "invalid status", keyDeviceID: "AVT-001", body: `{"status":"expired"}`, expectedStatus: http.StatusBadRequest},
+		{name: "already finished", keyDeviceID: "AVT-001", body: `{"status":"succeeded"}`, repoErr: repository.ErrDeviceCommandFinished, expectedStatus: http.StatusConflict},
+		{name: "unknown command", keyDeviceID: "AVT-001", body: `{"status":"succeeded"}`, repoErr: repository.ErrDeviceCommandNotFound, expectedStatus: http.StatusNotFound},
+		{name: "key of another device", keyDeviceID: "AVT-002", body: `{"status":"succeeded"}`, expectedStatus: http.StatusForbidden},
+		{name: "bearer token", body: `{"status":"succeeded"}`, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo := setupDeviceTest()
+			commandRepo := repository.NewMockDeviceCommandRepository()
+			handler.WithCommandRepo(commandRepo)
+
+			deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+				return device, nil
+			}
+			var acknowledged models.DeviceCommandStatus
+			commandRepo.AcknowledgeFunc = func(_ context.Context, deviceID, id uuid.UUID, status models.DeviceCommandStatus, message string) (*models.DeviceCommand, error) {
+				if tt.repoErr != nil {
+					return nil, tt.repoErr
+				}
+				assert.Equal(t, device.ID, deviceID)
+				assert.Equal(t, commandID, id)
+				acknowledged = status
+				return &models.DeviceCommand{ID: id, DeviceID: deviceID, Status: status, Message: message}, nil
+			}
+
+			c, w := newDeviceCommandContext(http.MethodPost, "/api/v1/devices/"+device.ID.String()+"/commands/"+commandID.String()+"/ack", tt.body,
+				gin.Params{{Key: "id", Value: device.ID.String()}, {Key: "commandId", Value: commandID.String()}}, &ownerID, tt.keyDeviceID)
+			handler.AcknowledgeDeviceCommand(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				assert.NotEmpty(t, acknowledged)
+			}
+		})
+	}
+}
+
+func TestDeviceHandler_CancelDeviceCommand(t *testing.T) {
+	ownerID := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "AVT-001", UserID: ownerID, IsActive: true}
+	commandID := uuid.New()
+
+	handler, deviceRepo := setupDeviceTest()
+	commandRepo := repository.NewMockDeviceCommandRepository()
+	handler.WithCommandRepo(commandRepo)
+	deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+		return device, nil
+	}
+	commandRepo.CancelFunc = func(_ context.Context, deviceID, id uuid.UUID) (*models.DeviceCommand, error) {
+		return &models.DeviceCommand{ID: id, DeviceID: deviceID, Status: models.DeviceCommandCancelled}, nil
+	}
+
+	params := gin.Params{{Key: "id", Value: device.ID.String()}, {Key: "commandId", Value: commandID.String()}}
+	c, w := newDeviceCommandContext(http.MethodDelete, "/api/v1/devices/"+device.ID.String()+"/commands/"+commandID.String(), "", params, &ownerID, "")
+	handler.CancelDeviceCommand(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"cancelled"`)
+
+	otherID := uuid.New()
+	c, w = newDeviceCommandContext(http.MethodDelete, "/api/v1/devices/"+device.ID.String()+"/commands/"+commandID.String(), "", params, &otherID, "")
+	handler.CancelDeviceCommand(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// UpdateDeviceConfigRequest represents the request body setting a device's desired configuration
+// Both settings are required; the configuration is replaced as a whole.
+type UpdateDeviceConfigRequest struct {
+	SampleRateHz          int `json:"sampleRateHz" binding:"required,min=1,max=25"`
+	UploadIntervalSeconds int `json:"uploadIntervalSeconds" binding:"required,min=1,max=86400"`
+}
+
+// WithConfigRepo enables the device configuration endpoints
+func (h *DeviceHandler) WithConfigRepo(configRepo repository.DeviceConfigRepository) *DeviceHandler {
+	h.configRepo = configRepo
+	return h
+}
+
+// UpdateDeviceConfig sets the configuration the device should apply
+// The device picks it up on its next poll of GetDeviceConfig.
+// PUT /api/v1/devices/:id/config
+func (h *DeviceHandler) UpdateDeviceConfig(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	device, ok := h.managedDevice(c, userID)
+	if !ok {
+		return
+	}
+
+	var req UpdateDeviceConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	config := &models.DeviceConfig{
+		DeviceID:              device.ID,
+		SampleRateHz:          req.SampleRateHz,
+		UploadIntervalSeconds: req.UploadIntervalSeconds,
+		UpdatedBy:             &userID,
+	}
+	if err := h.configRepo.Put(c.Request.Context(), config); err != nil {
+		problem.Abort(c, problem.Internal("Failed to update device config"))
+		return
+	}
+
+	c.Header("ETag", config.ETag())
+	api.Respond(c, http.StatusOK, config)
+}
+
+// GetDeviceConfig returns the configuration the device should apply, with its version as the ETag
+// Devices poll it with their API key, which must belong to the device; users can read the
+// configuration of devices they can view. A request whose If-None-Match matches the current
+// version gets 304 Not Modified.
+// GET /api/v1/devices/:id/config
+func (h *DeviceHandler) GetDeviceConfig(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		problem.Abort(c, problem.Unauthorized("unauthorized", "Authentication required: provide a bearer token or "+middleware.DeviceKeyHeader))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return
+	}
+
+	// A device key acts for the device's owner, so it must also be bound to this device
+	if keyDeviceID, ok := middleware.GetDeviceID(c); ok && keyDeviceID != device.DeviceID {
+		problem.Abort(c, problem.Forbidden("forbidden", "Device key does not belong to this device"))
+		return
+	}
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessUse, "device") {
+		return
+	}
+
+	config, err := h.configRepo.Get(c.Request.Context(), device.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceConfigNotFound) {
+			problem.Abort(c, problem.NotFound("config_not_found", "No configuration has been set for this device"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device config"))
+		return
+	}
+
+	etag := config.ETag()
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
+	api.Respond(c, http.StatusOK, config)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, or is "*"
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceHandler_UpdateDeviceConfig(t *testing.T) {
+	ownerID := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "AVT-001", UserID: ownerID, IsActive: true}
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		body           string
+		expectedStatus int
+	}{
+		{name: "sets the config", userID: ownerID, body: `{"sampleRateHz":10,"uploadIntervalSeconds":30}`, expectedStatus: http.StatusOK},
+		{name: "sample rate too high", userID: ownerID, body: `{"sampleRateHz":50,"uploadIntervalSeconds":30}`, expectedStatus: http.StatusBadRequest},
+		{name: "missing upload interval", userID: ownerID, body: `{"sampleRateHz":10}`, expectedStatus: http.StatusBadRequest},
+		{name: "another user's device", userID: uuid.New(), body: `{"sampleRateHz":10,"uploadIntervalSeconds":30}`, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo := setupDeviceTest()
+			configRepo := repository.NewMockDeviceConfigRepository()
+			handler.WithConfigRepo(configRepo)
+
+			deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+				return device, nil
+			}
+			var stored *models.DeviceConfig
+			configRepo.PutFunc = func(_ context.Context, config *models.DeviceConfig) error {
+				config.Version = 3
+				stored = config
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/devices/"+device.ID.String()+"/config", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: device.ID.String()}}
+			c.Set(string(middleware.UserIDKey), tt.userID)
+
+			handler.UpdateDeviceConfig(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Nil(t, stored)
+				return
+			}
+
+			require.NotNil(t, stored)
+			assert.Equal(t, device.ID, stored.DeviceID)
+			assert.Equal(t, 10, stored.SampleRateHz)
+			assert.Equal(t, 30, stored.UploadIntervalSeconds)
+			assert.Equal(t, ownerID, *stored.UpdatedBy)
+			assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestDeviceHandler_GetDeviceConfig(t *testing.T) {
+	ownerID, otherID := uuid.New(), uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "AVT-001", UserID: ownerID, IsActive: true}
+	config := &models.DeviceConfig{DeviceID: device.ID, SampleRateHz: 25, UploadIntervalSeconds: 5, Version: 2}
+
+	tests := []struct {
+		name           string
+		userID         *uuid.UUID
+		keyDeviceID    string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{name: "device key", userID: &ownerID, keyDeviceID: "AVT-001", expectedStatus: http.StatusOK},
+		{name: "owner", userID: &ownerID, expectedStatus: http.StatusOK},
+		{name: "unchanged", userID: &ownerID, keyDeviceID: "AVT-001", ifNoneMatch: `"1", "2"`, expectedStatus: http.StatusNotModified},
+		{name: "changed", userID: &ownerID, keyDeviceID: "AVT-001", ifNoneMatch: `"1"`, expectedStatus: http.StatusOK},
+		{name: "key of another device", userID: &ownerID, keyDeviceID: "AVT-002", expectedStatus: http.StatusForbidden},
+		{name: "another user", userID: &otherID, expectedStatus: http.StatusForbidden},
+		{name: "anonymous", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo := setupDeviceTest()
+			configRepo := repository.NewMockDeviceConfigRepository()
+			handler.WithConfigRepo(configRepo)
+
+			deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+				return device, nil
+			}
+			configRepo.GetFunc = func(_ context.Context, _ uuid.UUID) (*models.DeviceConfig, error) {
+				return config, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/"+device.ID.String()+"/config", nil)
+			if tt.ifNoneMatch != "" {
+				c.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			c.Params = gin.Params{{Key: "id", Value: device.ID.String()}}
+			if tt.userID != nil {
+				c.Set(string(middleware.UserIDKey), *tt.userID)
+			}
+			if tt.keyDeviceID != "" {
+				c.Set(string(middleware.DeviceIDKey), tt.keyDeviceID)
+			}
+
+			handler.GetDeviceConfig(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+				assert.Empty(t, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+			var response models.DeviceConfig
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, 25, response.SampleRateHz)
+			assert.Equal(t, 5, response.UploadIntervalSeconds)
+		})
+	}
+}
+
+func TestDeviceHandler_GetDeviceConfig_NotSet(t *testing.T) {
+	ownerID := uuid.New()
+	handler, deviceRepo := setupDeviceTest()
+	handler.WithConfigRepo(repository.NewMockDeviceConfigRepository())
+	deviceRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Device, error) {
+		return &models.Device{ID: id, DeviceID: "AVT-001", UserID: ownerID, IsActive: true}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	id := uuid.New().String()
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/"+id+"/config", nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set(string(middleware.UserIDKey), ownerID)
+
+	handler.GetDeviceConfig(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "config_not_found")
+}
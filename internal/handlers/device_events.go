@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// deviceEventsHeartbeat is how often an idle device event stream sends a comment to keep proxies from closing it
+const deviceEventsHeartbeat = 30 * time.Second
+
+// DevicePresence tracks devices coming online and going offline
+type DevicePresence interface {
+	// Seen records that a device was just seen
+	Seen(device *models.Device)
+
+	// Subscribe registers a subscriber for presence events; the returned function unsubscribes
+	Subscribe() (<-chan *models.DevicePresenceEvent, func())
+}
+
+// WithPresence enables the device online/offline event stream
+func (h *DeviceHandler) WithPresence(presence DevicePresence) *DeviceHandler {
+	h.presence = presence
+	return h
+}
+
+// StreamDeviceEvents streams online and offline transitions of the user's devices as Server-Sent Events
+// Each event is named after its type ("online" or "offline") and carries the device presence
+// event as JSON. Devices shared with the user's organizations are included; membership changes
+// apply when the client reconnects. Idle streams receive a comment every 30 seconds.
+// GET /api/v1/devices/events
+func (h *DeviceHandler) StreamDeviceEvents(c *gin.Context) {
+	if h.presence == nil {
+		problem.Abort(c, problem.ServiceUnavailable("events_unavailable", "Device events are not configured"))
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+	ctx := c.Request.Context()
+
+	orgIDs, err := h.orgs.orgIDs(ctx, userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve organizations"))
+		return
+	}
+	orgs := make(map[uuid.UUID]bool, len(orgIDs))
+	for _, id := range orgIDs {
+		orgs[id] = true
+	}
+
+	events, unsubscribe := h.presence.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Stop reverse proxies from buffering events
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(deviceEventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.UserID != userID && (event.OrgID == nil || !orgs[*event.OrgID]) {
+				continue
+			}
+			if err := writeEvent(c, "", event.Type, event); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// fakePresence hands out a prepared event channel to the first subscriber
+type fakePresence struct {
+	events       chan *models.DevicePresenceEvent
+	unsubscribed bool
+}
+
+func (p *fakePresence) Seen(_ *models.Device) {}
+
+func (p *fakePresence) Subscribe() (<-chan *models.DevicePresenceEvent, func()) {
+	return p.events, func() { p.unsubscribed = true }
+}
+
+func TestDeviceHandler_StreamDeviceEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID, orgID := uuid.New(), uuid.New()
+	seen := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	stream := func(handler *DeviceHandler) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/events", nil)
+		c.Set(string(middleware.UserIDKey), userID)
+
+		handler.StreamDeviceEvents(c)
+		return w
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		handler, _ := setupDeviceTest()
+
+		w := stream(handler)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "events_unavailable")
+	})
+
+	t.Run("streams events for own and shared devices", func(t *testing.T) {
+		orgRepo := repository.NewMockOrganizationRepository()
+		orgRepo.ListByUserIDFunc = func(_ context.Context, _ uuid.UUID) ([]*models.OrganizationMembership, error) {
+			return []*models.OrganizationMembership{{Organization: models.Organization{ID: orgID}, Role: models.OrgRoleMember}}, nil
+		}
+
+		// Events are queued before the stream starts; closing the channel ends it
+		presence := &fakePresence{events: make(chan *models.DevicePresenceEvent, 3)}
+		presence.events <- &models.DevicePresenceEvent{Type: models.DeviceEventOnline, DeviceID: "OWN-001", UserID: userID, LastSeenAt: seen}
+		presence.events <- &models.DevicePresenceEvent{Type: models.DeviceEventOnline, DeviceID: "OTHER-001", UserID: uuid.New(), LastSeenAt: seen}
+		presence.events <- &models.DevicePresenceEvent{Type: models.DeviceEventOffline, DeviceID: "SHARED-001", UserID: uuid.New(), OrgID: &orgID, LastSeenAt: seen}
+		close(presence.events)
+
+		handler, _ := setupDeviceTest()
+		handler = handler.WithOrganizations(orgRepo).WithPresence(presence)
+
+		w := stream(handler)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+		body := w.Body.String()
+		assert.Contains(t, body, "event: online\ndata: {\"type\":\"online\"")
+		assert.Contains(t, body, `"deviceId":"OWN-001"`)
+		assert.Contains(t, body, "event: offline\n")
+		assert.Contains(t, body, `"deviceId":"SHARED-001"`)
+		assert.NotContains(t, body, "OTHER-001")
+		assert.True(t, presence.unsubscribed)
+	})
+}
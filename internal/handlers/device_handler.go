@@ -1,17 +1,38 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
 	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/sebasr/avt-service/internal/problem"
 	"github.com/sebasr/avt-service/internal/repository"
 )
 
 // DeviceHandler handles device-related requests
 type DeviceHandler struct {
-	deviceRepo repository.DeviceRepository
+	deviceRepo    repository.DeviceRepository
+	healthRepo    repository.DeviceHealthRepository      // Optional: nil disables the health endpoint
+	ownershipRepo repository.DeviceOwnershipRepository   // Optional: nil disables the ownership history endpoint
+	backfillRepo  repository.DeviceBackfillRepository    // Optional: nil disables the backfill endpoints
+	backfillQueue ClaimBackfillQueue                     // Optional: nil leaves requested backfills to the periodic sweep
+	deletionRepo  repository.TelemetryDeletionRepository // Optional: nil disables telemetry range deletion
+	configRepo    repository.DeviceConfigRepository      // Optional: nil disables the device configuration endpoints
+	commandRepo   repository.DeviceCommandRepository     // Optional: nil disables the device command queue
+	undoWindow    time.Duration                          // How long deleted telemetry can be restored
+	orgs          *orgAccess                             // Optional: nil limits access to personal owners
+	presence      DevicePresence                         // Optional: nil disables the device event stream
+	quotas        *Quotas                                // Optional: nil disables the device limit on bulk registration
+	events        EventPublisher                         // Optional: nil publishes no device claim events
 }
 
 // NewDeviceHandler creates a new device handler
@@ -21,11 +42,27 @@ func NewDeviceHandler(deviceRepo repository.DeviceRepository) *DeviceHandler {
 	}
 }
 
+// WithOrganizations shares devices with the members of their organization
+// Members can view shared devices and owners and admins can also update and deactivate them.
+func (h *DeviceHandler) WithOrganizations(orgRepo repository.OrganizationRepository) *DeviceHandler {
+	h.orgs = newOrgAccess(orgRepo)
+	return h
+}
+
+// WithHealthRepo enables device health reporting
+func (h *DeviceHandler) WithHealthRepo(healthRepo repository.DeviceHealthRepository) *DeviceHandler {
+	h.healthRepo = healthRepo
+	return h
+}
+
 // UpdateDeviceRequest represents the device update request body
 type UpdateDeviceRequest struct {
 	DeviceName  *string                `json:"deviceName,omitempty"`
 	DeviceModel *string                `json:"deviceModel,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	// RequireSignedUploads rejects uploads with the device's API keys unless they carry a valid X-Signature
+	RequireSignedUploads *bool `json:"requireSignedUploads,omitempty"`
 }
 
 // DeviceResponse represents a device in API responses
@@ -37,22 +74,51 @@ type DeviceResponse struct {
 	ClaimedAt   string                 `json:"claimedAt"`
 	LastSeenAt  *string                `json:"lastSeenAt,omitempty"`
 	IsActive    bool                   `json:"isActive"`
+	OrgID       *uuid.UUID             `json:"orgId,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Tags        []string               `json:"tags"`
 	CreatedAt   string                 `json:"createdAt"`
 	UpdatedAt   string                 `json:"updatedAt"`
+
+	RequireSignedUploads bool `json:"requireSignedUploads"`
 }
 
-// ListDevices retrieves all devices for the authenticated user
-// GET /api/v1/devices
+// Default and maximum page sizes for device listings
+const (
+	defaultDeviceListLimit = 50
+	maxDeviceListLimit     = 200
+)
+
+// ListDevices retrieves a page of the authenticated user's devices
+// Cursors are only issued and accepted when sorting by claimedAt.
+// GET /api/v1/devices?active=&online=&tag=&sort=claimedAt|lastSeenAt|name|deviceId&order=asc|desc&limit=&cursor=&offset=
 func (h *DeviceHandler) ListDevices(c *gin.Context) {
 	userID := middleware.MustGetUserID(c)
 
-	devices, err := h.deviceRepo.ListByUserID(c.Request.Context(), userID)
+	filter, page, err := parseDeviceFilter(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to retrieve devices",
-		})
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+	filter.UserID = userID
+	if filter.OrgIDs, err = h.orgs.orgIDs(c.Request.Context(), userID); err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve organizations"))
+		return
+	}
+
+	devices, total, err := h.deviceRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve devices"))
+		return
+	}
+	devices, nextCursor := pagination.Next(page, devices, deviceCursor)
+
+	ids := make([]uuid.UUID, len(devices))
+	for i, device := range devices {
+		ids[i] = device.ID
+	}
+	tags, ok := h.deviceTags(c, ids...)
+	if !ok {
 		return
 	}
 
@@ -66,23 +132,98 @@ func (h *DeviceHandler) ListDevices(c *gin.Context) {
 		}
 
 		response[i] = DeviceResponse{
-			ID:          device.ID.String(),
-			DeviceID:    device.DeviceID,
-			DeviceName:  device.DeviceName,
-			DeviceModel: device.DeviceModel,
-			ClaimedAt:   device.ClaimedAt.Format("2006-01-02T15:04:05Z07:00"),
-			LastSeenAt:  lastSeenAt,
-			IsActive:    device.IsActive,
-			Metadata:    device.Metadata,
-			CreatedAt:   device.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:   device.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:                   device.ID.String(),
+			DeviceID:             device.DeviceID,
+			DeviceName:           device.DeviceName,
+			DeviceModel:          device.DeviceModel,
+			ClaimedAt:            device.ClaimedAt.Format("2006-01-02T15:04:05Z07:00"),
+			LastSeenAt:           lastSeenAt,
+			IsActive:             device.IsActive,
+			OrgID:                device.OrgID,
+			Metadata:             device.Metadata,
+			Tags:                 tagsOf(tags, device.ID),
+			RequireSignedUploads: device.RequireSignedUploads,
+			CreatedAt:            device.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			UpdatedAt:            device.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"devices": response,
-		"total":   len(response),
-	})
+	meta := api.Meta{
+		"total":  total,
+		"limit":  page.Limit,
+		"offset": filter.Offset,
+	}
+	if nextCursor != "" && filter.Sort == repository.DeviceSortClaimedAt {
+		meta["nextCursor"] = nextCursor
+	}
+	api.List(c, http.StatusOK, "devices", response, meta)
+}
+
+// deviceCursor returns the listing position of a device sorted by claimedAt
+func deviceCursor(device *models.Device) pagination.Cursor {
+	return pagination.Cursor{Time: device.ClaimedAt, ID: device.ID.String()}
+}
+
+// parseDeviceFilter reads the device listing query parameters
+// Timestamps sort newest first by default; names and device IDs sort alphabetically.
+// The filter fetches one device more than the page, to detect whether another page follows.
+func parseDeviceFilter(c *gin.Context) (repository.DeviceFilter, pagination.Page, error) {
+	var filter repository.DeviceFilter
+
+	page, offset, err := parsePage(c, defaultDeviceListLimit, maxDeviceListLimit)
+	if err != nil {
+		return filter, page, err
+	}
+	filter.Limit, filter.Offset, filter.After = page.Fetch(), offset, page.After
+
+	if filter.IsActive, err = parseBoolQuery(c, "active"); err != nil {
+		return filter, page, err
+	}
+	if filter.Online, err = parseBoolQuery(c, "online"); err != nil {
+		return filter, page, err
+	}
+	if raw := c.Query("tag"); raw != "" {
+		if filter.Tag, err = models.ParseDeviceTag(raw); err != nil {
+			return filter, page, errors.New("tag is not a valid device tag")
+		}
+	}
+
+	filter.Sort = repository.DeviceSortClaimedAt
+	if raw := c.Query("sort"); raw != "" {
+		filter.Sort = repository.DeviceSort(raw)
+		if !filter.Sort.IsValid() {
+			return filter, page, errors.New("sort must be one of: claimedAt, lastSeenAt, name, deviceId")
+		}
+	}
+	if page.After != nil && filter.Sort != repository.DeviceSortClaimedAt {
+		return filter, page, errors.New("cursor is only supported when sorting by claimedAt")
+	}
+	filter.Ascending = filter.Sort == repository.DeviceSortName || filter.Sort == repository.DeviceSortDeviceID
+
+	switch c.Query("order") {
+	case "":
+	case "asc":
+		filter.Ascending = true
+	case "desc":
+		filter.Ascending = false
+	default:
+		return filter, page, errors.New("order must be asc or desc")
+	}
+
+	return filter, page, nil
+}
+
+// parseBoolQuery reads an optional boolean query parameter; nil means it was not given
+func parseBoolQuery(c *gin.Context, key string) (*bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false", key)
+	}
+	return &value, nil
 }
 
 // GetDevice retrieves a specific device by ID
@@ -93,35 +234,27 @@ func (h *DeviceHandler) GetDevice(c *gin.Context) {
 	deviceIDParam := c.Param("id")
 	deviceID, err := uuid.Parse(deviceIDParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_device_id",
-			"message": "Invalid device ID format",
-		})
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
 		return
 	}
 
 	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
 	if err != nil {
 		if err == repository.ErrDeviceNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "device_not_found",
-				"message": "Device not found",
-			})
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to retrieve device",
-		})
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return
+	}
+
+	// Verify the user owns the device or shares it through an organization
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessUse, "device") {
 		return
 	}
 
-	// Verify device belongs to user
-	if device.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "forbidden",
-			"message": "You do not have access to this device",
-		})
+	tags, ok := h.deviceTags(c, device.ID)
+	if !ok {
 		return
 	}
 
@@ -131,17 +264,20 @@ func (h *DeviceHandler) GetDevice(c *gin.Context) {
 		lastSeenAt = &seenStr
 	}
 
-	c.JSON(http.StatusOK, DeviceResponse{
-		ID:          device.ID.String(),
-		DeviceID:    device.DeviceID,
-		DeviceName:  device.DeviceName,
-		DeviceModel: device.DeviceModel,
-		ClaimedAt:   device.ClaimedAt.Format("2006-01-02T15:04:05Z07:00"),
-		LastSeenAt:  lastSeenAt,
-		IsActive:    device.IsActive,
-		Metadata:    device.Metadata,
-		CreatedAt:   device.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   device.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	api.Respond(c, http.StatusOK, DeviceResponse{
+		ID:                   device.ID.String(),
+		DeviceID:             device.DeviceID,
+		DeviceName:           device.DeviceName,
+		DeviceModel:          device.DeviceModel,
+		ClaimedAt:            device.ClaimedAt.Format("2006-01-02T15:04:05Z07:00"),
+		LastSeenAt:           lastSeenAt,
+		IsActive:             device.IsActive,
+		OrgID:                device.OrgID,
+		Metadata:             device.Metadata,
+		Tags:                 tagsOf(tags, device.ID),
+		RequireSignedUploads: device.RequireSignedUploads,
+		CreatedAt:            device.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:            device.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
@@ -153,19 +289,13 @@ func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
 	deviceIDParam := c.Param("id")
 	deviceID, err := uuid.Parse(deviceIDParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_device_id",
-			"message": "Invalid device ID format",
-		})
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
 		return
 	}
 
 	var req UpdateDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request body: " + err.Error(),
-		})
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
 		return
 	}
 
@@ -173,25 +303,15 @@ func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
 	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
 	if err != nil {
 		if err == repository.ErrDeviceNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "device_not_found",
-				"message": "Device not found",
-			})
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to retrieve device",
-		})
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
 		return
 	}
 
-	// Verify device belongs to user
-	if device.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "forbidden",
-			"message": "You do not have access to this device",
-		})
+	// Verify the user owns the device or manages its organization
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessManage, "device") {
 		return
 	}
 
@@ -205,13 +325,18 @@ func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
 	if req.Metadata != nil {
 		device.Metadata = req.Metadata
 	}
+	if req.RequireSignedUploads != nil {
+		device.RequireSignedUploads = *req.RequireSignedUploads
+	}
 
 	// Save updates
 	if err := h.deviceRepo.Update(c.Request.Context(), device); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to update device",
-		})
+		problem.Abort(c, problem.Internal("Failed to update device"))
+		return
+	}
+
+	tags, ok := h.deviceTags(c, device.ID)
+	if !ok {
 		return
 	}
 
@@ -221,17 +346,20 @@ func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
 		lastSeenAt = &seenStr
 	}
 
-	c.JSON(http.StatusOK, DeviceResponse{
-		ID:          device.ID.String(),
-		DeviceID:    device.DeviceID,
-		DeviceName:  device.DeviceName,
-		DeviceModel: device.DeviceModel,
-		ClaimedAt:   device.ClaimedAt.Format("2006-01-02T15:04:05Z07:00"),
-		LastSeenAt:  lastSeenAt,
-		IsActive:    device.IsActive,
-		Metadata:    device.Metadata,
-		CreatedAt:   device.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   device.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	api.Respond(c, http.StatusOK, DeviceResponse{
+		ID:                   device.ID.String(),
+		DeviceID:             device.DeviceID,
+		DeviceName:           device.DeviceName,
+		DeviceModel:          device.DeviceModel,
+		ClaimedAt:            device.ClaimedAt.Format("2006-01-02T15:04:05Z07:00"),
+		LastSeenAt:           lastSeenAt,
+		IsActive:             device.IsActive,
+		OrgID:                device.OrgID,
+		Metadata:             device.Metadata,
+		Tags:                 tagsOf(tags, device.ID),
+		RequireSignedUploads: device.RequireSignedUploads,
+		CreatedAt:            device.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:            device.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
@@ -243,10 +371,7 @@ func (h *DeviceHandler) DeactivateDevice(c *gin.Context) {
 	deviceIDParam := c.Param("id")
 	deviceID, err := uuid.Parse(deviceIDParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_device_id",
-			"message": "Invalid device ID format",
-		})
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
 		return
 	}
 
@@ -254,39 +379,209 @@ func (h *DeviceHandler) DeactivateDevice(c *gin.Context) {
 	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
 	if err != nil {
 		if err == repository.ErrDeviceNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "device_not_found",
-				"message": "Device not found",
-			})
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to retrieve device",
-		})
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
 		return
 	}
 
-	// Verify device belongs to user
-	if device.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "forbidden",
-			"message": "You do not have access to this device",
-		})
+	// Verify the user owns the device or manages its organization
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessManage, "device") {
 		return
 	}
 
 	// Deactivate device
 	device.IsActive = false
 	if err := h.deviceRepo.Update(c.Request.Context(), device); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to deactivate device",
-		})
+		problem.Abort(c, problem.Internal("Failed to deactivate device"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	api.Respond(c, http.StatusOK, gin.H{
 		"message": "Device deactivated successfully",
 	})
 }
+
+// Default and maximum time windows for device health reports, in hours
+const (
+	defaultHealthHours = 24
+	maxHealthHours     = 7 * 24
+)
+
+// healthEventLimit is the number of recent health events included in device health reports
+const healthEventLimit = 20
+
+// DeviceHealthSnapshotResponse represents a device health snapshot in API responses
+type DeviceHealthSnapshotResponse struct {
+	BucketStart           time.Time `json:"bucketStart"`
+	Samples               int       `json:"samples"`
+	FixRatio              float64   `json:"fixRatio"`
+	AvgSatellites         float64   `json:"avgSatellites"`
+	AvgHorizontalAccuracy float64   `json:"avgHorizontalAccuracy"`
+	BatteryMin            float64   `json:"batteryMin"`
+	BatteryMax            float64   `json:"batteryMax"`
+	BatteryLast           float64   `json:"batteryLast"`
+	IsCharging            bool      `json:"isCharging"`
+	AvgClockSkewMs        float64   `json:"avgClockSkewMs"`
+	MaxClockSkewMs        int64     `json:"maxClockSkewMs"` // Largest skew in either direction
+	SkewedSamples         int       `json:"skewedSamples"`  // Samples flagged or corrected for clock skew
+}
+
+// ClockSkewResponse summarizes a device's clock skew over a health report window
+type ClockSkewResponse struct {
+	Samples        int     `json:"samples"` // Samples with a clock skew estimate
+	AvgClockSkewMs float64 `json:"avgClockSkewMs"`
+	MaxClockSkewMs int64   `json:"maxClockSkewMs"`
+	SkewedSamples  int     `json:"skewedSamples"`
+}
+
+// DeviceHealthResponse represents a device's health report
+type DeviceHealthResponse struct {
+	DeviceID            string                         `json:"deviceId"`
+	Hours               int                            `json:"hours"`
+	State               *models.DeviceHealthState      `json:"state"`                         // Null until the device has reported telemetry
+	BatteryTrendPerHour *float64                       `json:"batteryTrendPerHour,omitempty"` // Least-squares slope while discharging
+	ClockSkew           *ClockSkewResponse             `json:"clockSkew,omitempty"`           // Null when no sample had a skew estimate
+	Snapshots           []DeviceHealthSnapshotResponse `json:"snapshots"`
+	Events              []*models.DeviceHealthEvent    `json:"events"`
+}
+
+// GetDeviceHealth reports the battery and GPS fix health of a device
+// GET /api/v1/devices/:id/health?hours=
+func (h *DeviceHandler) GetDeviceHealth(c *gin.Context) {
+	if h.healthRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("device_health_unavailable", "Device health monitoring is not enabled"))
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return
+	}
+
+	hours := defaultHealthHours
+	if raw := c.Query("hours"); raw != "" {
+		hours, err = strconv.Atoi(raw)
+		if err != nil || hours < 1 || hours > maxHealthHours {
+			problem.Abort(c, problem.BadRequest("invalid_request", fmt.Sprintf("hours must be an integer between 1 and %d", maxHealthHours)))
+			return
+		}
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
+	if err != nil {
+		if err == repository.ErrDeviceNotFound {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return
+	}
+
+	// Verify the user owns the device or shares it through an organization
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessUse, "device") {
+		return
+	}
+
+	ctx := c.Request.Context()
+	state, err := h.healthRepo.GetState(ctx, device.DeviceID)
+	if err != nil && !errors.Is(err, repository.ErrDeviceHealthNotFound) {
+		problem.Abort(c, problem.Internal("Failed to retrieve device health"))
+		return
+	}
+
+	snapshots, err := h.healthRepo.ListSnapshots(ctx, device.DeviceID, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve device health snapshots"))
+		return
+	}
+
+	events, err := h.healthRepo.ListEvents(ctx, device.DeviceID, healthEventLimit)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve device health events"))
+		return
+	}
+
+	response := DeviceHealthResponse{
+		DeviceID:            device.DeviceID,
+		Hours:               hours,
+		State:               state,
+		BatteryTrendPerHour: batteryTrend(snapshots),
+		ClockSkew:           clockSkewSummary(snapshots),
+		Snapshots:           make([]DeviceHealthSnapshotResponse, len(snapshots)),
+		Events:              events,
+	}
+	for i, snapshot := range snapshots {
+		response.Snapshots[i] = DeviceHealthSnapshotResponse{
+			BucketStart:           snapshot.BucketStart,
+			Samples:               snapshot.Samples,
+			FixRatio:              snapshot.FixRatio(),
+			AvgSatellites:         snapshot.AvgSatellites(),
+			AvgHorizontalAccuracy: snapshot.AvgHorizontalAccuracy(),
+			BatteryMin:            snapshot.BatteryMin,
+			BatteryMax:            snapshot.BatteryMax,
+			BatteryLast:           snapshot.BatteryLast,
+			IsCharging:            snapshot.IsCharging,
+			AvgClockSkewMs:        snapshot.AvgClockSkewMs(),
+			MaxClockSkewMs:        snapshot.SkewAbsMaxMs,
+			SkewedSamples:         snapshot.SkewedSamples,
+		}
+	}
+
+	api.Respond(c, http.StatusOK, response)
+}
+
+// clockSkewSummary merges the clock skew statistics of snapshots, or returns nil when none has an estimate
+func clockSkewSummary(snapshots []*models.DeviceHealthSnapshot) *ClockSkewResponse {
+	var merged models.DeviceHealthSnapshot
+	for _, snapshot := range snapshots {
+		merged.SkewSamples += snapshot.SkewSamples
+		merged.SkewSumMs += snapshot.SkewSumMs
+		merged.SkewAbsMaxMs = max(merged.SkewAbsMaxMs, snapshot.SkewAbsMaxMs)
+		merged.SkewedSamples += snapshot.SkewedSamples
+	}
+	if merged.SkewSamples == 0 {
+		return nil
+	}
+
+	return &ClockSkewResponse{
+		Samples:        merged.SkewSamples,
+		AvgClockSkewMs: merged.AvgClockSkewMs(),
+		MaxClockSkewMs: merged.SkewAbsMaxMs,
+		SkewedSamples:  merged.SkewedSamples,
+	}
+}
+
+// batteryTrend fits a least-squares line through the last battery reading of each snapshot
+// taken while discharging and returns its slope per hour, or nil with fewer than two such snapshots
+func batteryTrend(snapshots []*models.DeviceHealthSnapshot) *float64 {
+	var n, sumX, sumY, sumXY, sumXX float64
+	var origin time.Time
+	for _, snapshot := range snapshots {
+		if snapshot.IsCharging {
+			continue
+		}
+		if origin.IsZero() {
+			origin = snapshot.LastRecordedAt
+		}
+		x := snapshot.LastRecordedAt.Sub(origin).Hours()
+		y := snapshot.BatteryLast
+		n++
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if n < 2 || math.Abs(denominator) < 1e-9 {
+		return nil
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+	return &slope
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/sebasr/avt-service/internal/middleware"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
 	"github.com/sebasr/avt-service/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -60,11 +61,11 @@ func TestDeviceHandler_ListDevices_Success(t *testing.T) {
 		},
 	}
 
-	deviceRepo.ListByUserIDFunc = func(_ context.Context, id uuid.UUID) ([]*models.Device, error) {
-		if id == userID {
-			return devices, nil
+	deviceRepo.ListFunc = func(_ context.Context, filter repository.DeviceFilter) ([]*models.Device, int, error) {
+		if filter.UserID == userID {
+			return devices, len(devices), nil
 		}
-		return []*models.Device{}, nil
+		return []*models.Device{}, 0, nil
 	}
 
 	w := httptest.NewRecorder()
@@ -90,8 +91,8 @@ func TestDeviceHandler_ListDevices_Empty(t *testing.T) {
 
 	userID := uuid.New()
 
-	deviceRepo.ListByUserIDFunc = func(_ context.Context, _ uuid.UUID) ([]*models.Device, error) {
-		return []*models.Device{}, nil
+	deviceRepo.ListFunc = func(_ context.Context, _ repository.DeviceFilter) ([]*models.Device, int, error) {
+		return []*models.Device{}, 0, nil
 	}
 
 	w := httptest.NewRecorder()
@@ -110,6 +111,161 @@ func TestDeviceHandler_ListDevices_Empty(t *testing.T) {
 	assert.Equal(t, float64(0), response["total"])
 }
 
+func TestDeviceHandler_ListDevices_FiltersAndPagination(t *testing.T) {
+	cursorDeviceID := uuid.New()
+	tests := []struct {
+		name  string
+		query string
+		check func(t *testing.T, filter repository.DeviceFilter)
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			check: func(t *testing.T, filter repository.DeviceFilter) {
+				assert.Equal(t, defaultDeviceListLimit+1, filter.Limit, "one extra device detects the next page")
+				assert.Equal(t, 0, filter.Offset)
+				assert.Equal(t, repository.DeviceSortClaimedAt, filter.Sort)
+				assert.False(t, filter.Ascending, "timestamps sort newest first")
+				assert.Nil(t, filter.IsActive)
+				assert.Nil(t, filter.Online)
+			},
+		},
+		{
+			name:  "filters and page",
+			query: "?active=true&online=false&limit=25&offset=50",
+			check: func(t *testing.T, filter repository.DeviceFilter) {
+				assert.Equal(t, 26, filter.Limit)
+				assert.Equal(t, 50, filter.Offset)
+				require.NotNil(t, filter.IsActive)
+				assert.True(t, *filter.IsActive)
+				require.NotNil(t, filter.Online)
+				assert.False(t, *filter.Online)
+			},
+		},
+		{
+			name:  "cursor",
+			query: "?cursor=" + pagination.Cursor{Time: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), ID: cursorDeviceID.String()}.Encode(),
+			check: func(t *testing.T, filter repository.DeviceFilter) {
+				require.NotNil(t, filter.After)
+				assert.Equal(t, cursorDeviceID.String(), filter.After.ID)
+				assert.Equal(t, 0, filter.Offset)
+			},
+		},
+		{
+			name:  "tag",
+			query: "?tag=rental-fleet-A",
+			check: func(t *testing.T, filter repository.DeviceFilter) {
+				assert.Equal(t, "rental-fleet-A", filter.Tag)
+			},
+		},
+		{
+			name:  "name sorts alphabetically by default",
+			query: "?sort=name",
+			check: func(t *testing.T, filter repository.DeviceFilter) {
+				assert.Equal(t, repository.DeviceSortName, filter.Sort)
+				assert.True(t, filter.Ascending)
+			},
+		},
+		{
+			name:  "explicit order",
+			query: "?sort=lastSeenAt&order=asc",
+			check: func(t *testing.T, filter repository.DeviceFilter) {
+				assert.Equal(t, repository.DeviceSortLastSeenAt, filter.Sort)
+				assert.True(t, filter.Ascending)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo := setupDeviceTest()
+			userID := uuid.New()
+
+			deviceRepo.ListFunc = func(_ context.Context, filter repository.DeviceFilter) ([]*models.Device, int, error) {
+				assert.Equal(t, userID, filter.UserID)
+				tt.check(t, filter)
+				return []*models.Device{}, 120, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.ListDevices(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, float64(120), response["total"], "total counts all matching devices, not just the page")
+		})
+	}
+}
+
+func TestDeviceHandler_ListDevices_NextCursor(t *testing.T) {
+	handler, deviceRepo := setupDeviceTest()
+	userID := uuid.New()
+
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	devices := make([]*models.Device, 3)
+	for i := range devices {
+		devices[i] = &models.Device{ID: uuid.New(), UserID: userID, ClaimedAt: base.Add(-time.Duration(i) * time.Hour)}
+	}
+	deviceRepo.ListFunc = func(_ context.Context, filter repository.DeviceFilter) ([]*models.Device, int, error) {
+		return devices[:min(filter.Limit, len(devices))], len(devices), nil
+	}
+
+	for _, tt := range []struct {
+		query      string
+		nextCursor bool
+	}{
+		{query: "limit=2", nextCursor: true},
+		{query: "limit=3"},
+		{query: "limit=2&sort=name"},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices?"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.ListDevices(c)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if !tt.nextCursor {
+				assert.NotContains(t, response, "nextCursor")
+				return
+			}
+			assert.Len(t, response["devices"], 2)
+			cursor, err := pagination.Decode(response["nextCursor"].(string))
+			require.NoError(t, err)
+			assert.Equal(t, devices[1].ID.String(), cursor.ID)
+		})
+	}
+}
+
+func TestDeviceHandler_ListDevices_InvalidParams(t *testing.T) {
+	cursor := pagination.Cursor{Time: time.Now(), ID: uuid.New().String()}.Encode()
+	for _, query := range []string{"limit=0", "limit=500", "offset=-1", "active=maybe", "online=1x", "sort=color", "order=up", "tag=a/b",
+		"cursor=garbage", "cursor=" + cursor + "&offset=10", "cursor=" + cursor + "&sort=name"} {
+		t.Run(query, func(t *testing.T) {
+			handler, _ := setupDeviceTest()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices?"+query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.ListDevices(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
 func TestDeviceHandler_GetDevice_Success(t *testing.T) {
 	handler, deviceRepo := setupDeviceTest()
 
@@ -260,10 +416,12 @@ func TestDeviceHandler_UpdateDevice_Success(t *testing.T) {
 
 	newName := "Updated Name"
 	newModel := "Mini S Pro"
+	requireSigned := true
 	reqBody := UpdateDeviceRequest{
-		DeviceName:  &newName,
-		DeviceModel: &newModel,
-		Metadata:    map[string]interface{}{"version": "2.0"},
+		DeviceName:           &newName,
+		DeviceModel:          &newModel,
+		Metadata:             map[string]interface{}{"version": "2.0"},
+		RequireSignedUploads: &requireSigned,
 	}
 
 	body, _ := json.Marshal(reqBody)
@@ -282,6 +440,8 @@ func TestDeviceHandler_UpdateDevice_Success(t *testing.T) {
 	assert.Equal(t, newName, *updatedDevice.DeviceName)
 	assert.Equal(t, newModel, *updatedDevice.DeviceModel)
 	assert.Equal(t, "2.0", updatedDevice.Metadata["version"])
+	assert.True(t, updatedDevice.RequireSignedUploads)
+	assert.Contains(t, w.Body.String(), `"requireSignedUploads":true`)
 }
 
 func TestDeviceHandler_UpdateDevice_NotFound(t *testing.T) {
@@ -457,3 +617,107 @@ func TestDeviceHandler_DeactivateDevice_Forbidden(t *testing.T) {
 	assert.Equal(t, http.StatusForbidden, w.Code)
 	assert.Contains(t, w.Body.String(), "forbidden")
 }
+
+func TestDeviceHandler_GetDeviceHealth_Success(t *testing.T) {
+	handler, deviceRepo := setupDeviceTest()
+	healthRepo := repository.NewMockDeviceHealthRepository()
+	handler.WithHealthRepo(healthRepo)
+
+	userID := uuid.New()
+	deviceID := uuid.New()
+	deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+		return &models.Device{ID: deviceID, DeviceID: "RACEBOX-001", UserID: userID, IsActive: true}, nil
+	}
+
+	now := time.Now().UTC()
+	healthRepo.GetStateFunc = func(_ context.Context, id string) (*models.DeviceHealthState, error) {
+		assert.Equal(t, "RACEBOX-001", id)
+		return &models.DeviceHealthState{DeviceID: id, Battery: 60, LastRecordedAt: now}, nil
+	}
+	healthRepo.ListSnapshotsFunc = func(_ context.Context, _ string, since time.Time) ([]*models.DeviceHealthSnapshot, error) {
+		assert.WithinDuration(t, now.Add(-6*time.Hour), since, time.Minute)
+		// Draining 10% per hour, with a charging snapshot that must not skew the trend
+		return []*models.DeviceHealthSnapshot{
+			{BucketStart: now.Add(-3 * time.Hour), Samples: 4, FixSamples: 3, SatellitesSum: 40, BatteryLast: 90, LastRecordedAt: now.Add(-3 * time.Hour)},
+			{BucketStart: now.Add(-2 * time.Hour), Samples: 4, FixSamples: 4, BatteryLast: 80, LastRecordedAt: now.Add(-2 * time.Hour),
+				SkewSamples: 4, SkewSumMs: 12000, SkewAbsMaxMs: 4000, SkewedSamples: 3},
+			{BucketStart: now.Add(-90 * time.Minute), Samples: 1, BatteryLast: 100, IsCharging: true, LastRecordedAt: now.Add(-90 * time.Minute)},
+			{BucketStart: now.Add(-time.Hour), Samples: 4, FixSamples: 4, BatteryLast: 70, LastRecordedAt: now.Add(-time.Hour),
+				SkewSamples: 4, SkewSumMs: -2000, SkewAbsMaxMs: 600},
+		}, nil
+	}
+	healthRepo.ListEventsFunc = func(_ context.Context, _ string, _ int) ([]*models.DeviceHealthEvent, error) {
+		return []*models.DeviceHealthEvent{{DeviceID: "RACEBOX-001", Type: models.DeviceHealthNoFix}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/"+deviceID.String()+"/health?hours=6", nil)
+	c.Params = gin.Params{{Key: "id", Value: deviceID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.GetDeviceHealth(c)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response DeviceHealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 6, response.Hours)
+	require.NotNil(t, response.State)
+	assert.Equal(t, 60.0, response.State.Battery)
+	require.NotNil(t, response.BatteryTrendPerHour)
+	assert.InDelta(t, -10.0, *response.BatteryTrendPerHour, 0.01)
+	require.Len(t, response.Snapshots, 4)
+	assert.InDelta(t, 0.75, response.Snapshots[0].FixRatio, 0.001)
+	assert.InDelta(t, 10.0, response.Snapshots[0].AvgSatellites, 0.001)
+	assert.InDelta(t, 3000.0, response.Snapshots[1].AvgClockSkewMs, 0.001)
+	assert.Equal(t, 3, response.Snapshots[1].SkewedSamples)
+	require.NotNil(t, response.ClockSkew)
+	assert.Equal(t, 8, response.ClockSkew.Samples)
+	assert.InDelta(t, 1250.0, response.ClockSkew.AvgClockSkewMs, 0.001)
+	assert.Equal(t, int64(4000), response.ClockSkew.MaxClockSkewMs)
+	assert.Equal(t, 3, response.ClockSkew.SkewedSamples)
+	require.Len(t, response.Events, 1)
+	assert.Equal(t, models.DeviceHealthNoFix, response.Events[0].Type)
+}
+
+func TestDeviceHandler_GetDeviceHealth_Errors(t *testing.T) {
+	userID := uuid.New()
+	deviceID := uuid.New()
+
+	tests := []struct {
+		name       string
+		healthRepo bool
+		owner      uuid.UUID
+		query      string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "not configured", owner: userID, wantStatus: http.StatusServiceUnavailable, wantError: "device_health_unavailable"},
+		{name: "invalid hours", healthRepo: true, owner: userID, query: "?hours=1000", wantStatus: http.StatusBadRequest, wantError: "invalid_request"},
+		{name: "other owner", healthRepo: true, owner: uuid.New(), wantStatus: http.StatusForbidden, wantError: "forbidden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo := setupDeviceTest()
+			if tt.healthRepo {
+				handler.WithHealthRepo(repository.NewMockDeviceHealthRepository())
+			}
+			deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+				return &models.Device{ID: deviceID, DeviceID: "RACEBOX-001", UserID: tt.owner}, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/"+deviceID.String()+"/health"+tt.query, nil)
+			c.Params = gin.Params{{Key: "id", Value: deviceID.String()}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.GetDeviceHealth(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantError)
+		})
+	}
+}
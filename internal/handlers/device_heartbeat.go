@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// HeartbeatRequest represents the status a device reports between uploads
+// The firmware version may also be sent in X-Firmware-Version; the body takes precedence.
+type HeartbeatRequest struct {
+	Battery         *float64 `json:"battery" binding:"required,min=0,max=100"`
+	IsCharging      bool     `json:"isCharging"`
+	SignalDbm       *int     `json:"signalDbm,omitempty" binding:"omitempty,min=-150,max=0"`
+	FirmwareVersion string   `json:"firmwareVersion,omitempty"`
+}
+
+// HandleHeartbeat records a device's status without telemetry
+// It refreshes the device's last-seen time and health state, so devices without a GPS fix, which
+// have no telemetry to upload, still appear online. Only the device's own API key may send it.
+// POST /api/v1/devices/:id/heartbeat, where :id is the hardware device ID
+func (h *TelemetryHandler) HandleHeartbeat(c *gin.Context) {
+	keyDeviceID, ok := middleware.GetDeviceID(c)
+	if !ok {
+		problem.Abort(c, problem.Unauthorized("unauthorized", "Authentication required: provide "+middleware.DeviceKeyHeader))
+		return
+	}
+	if keyDeviceID != c.Param("id") {
+		problem.Abort(c, problem.Forbidden("forbidden", "Device key does not belong to this device"))
+		return
+	}
+
+	var req HeartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	version, hasVersion := middleware.GetFirmwareVersion(c)
+	if reported := strings.TrimSpace(req.FirmwareVersion); reported != "" {
+		parsed, err := models.ParseFirmwareVersion(reported)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_firmware_version", "firmwareVersion must look like MAJOR[.MINOR[.PATCH]][-PRERELEASE]"))
+			return
+		}
+		version, hasVersion = parsed, true
+	}
+
+	ctx := c.Request.Context()
+	device, err := h.deviceRepo.GetByDeviceID(ctx, keyDeviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return
+	}
+
+	if err := h.deviceRepo.UpdateLastSeen(ctx, device.DeviceID); err != nil {
+		slog.Error("Failed to update device last_seen", "device_id", device.DeviceID, "error", err)
+		problem.Abort(c, problem.Internal("Failed to record heartbeat"))
+		return
+	}
+	h.markSeen(device)
+
+	heartbeat := &models.DeviceHeartbeat{
+		DeviceID:   device.DeviceID,
+		UserID:     device.UserID,
+		Battery:    *req.Battery,
+		IsCharging: req.IsCharging,
+		SignalDbm:  req.SignalDbm,
+		ReceivedAt: time.Now().UTC(),
+	}
+	if hasVersion {
+		h.storeFirmware(c, device, version)
+		heartbeat.FirmwareVersion = version.String()
+	}
+	if h.health != nil {
+		h.health.EnqueueHeartbeat(heartbeat)
+	}
+
+	api.Respond(c, http.StatusAccepted, heartbeat)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHealthMonitor captures the heartbeats handed to the health monitor
+type recordingHealthMonitor struct {
+	heartbeats []*models.DeviceHeartbeat
+}
+
+func (m *recordingHealthMonitor) Enqueue(_ []*models.TelemetryData) {}
+
+func (m *recordingHealthMonitor) EnqueueHeartbeat(heartbeat *models.DeviceHeartbeat) {
+	m.heartbeats = append(m.heartbeats, heartbeat)
+}
+
+func TestTelemetryHandler_HandleHeartbeat(t *testing.T) {
+	ownerID := uuid.New()
+	oldFirmware := "1.0.0"
+	device := &models.Device{ID: uuid.New(), DeviceID: "AVT-001", UserID: ownerID, IsActive: true, FirmwareVersion: &oldFirmware}
+
+	tests := []struct {
+		name             string
+		keyDeviceID      string
+		body             string
+		expectedStatus   int
+		expectedFirmware string
+	}{
+		{name: "records the heartbeat", keyDeviceID: "AVT-001", body: `{"battery":15,"signalDbm":-95,"firmwareVersion":"1.2.0"}`, expectedStatus: http.StatusAccepted, expectedFirmware: "1.2.0"},
+		{name: "without optional fields", keyDeviceID: "AVT-001", body: `{"battery":80,"isCharging":true}`, expectedStatus: http.StatusAccepted},
+		{name: "missing battery", keyDeviceID: "AVT-001", body: `{"signalDbm":-95}`, expectedStatus: http.StatusBadRequest},
+		{name: "signal out of range", keyDeviceID: "AVT-001", body: `{"battery":15,"signalDbm":20}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid firmware version", keyDeviceID: "AVT-001", body: `{"battery":15,"firmwareVersion":"latest"}`, expectedStatus: http.StatusBadRequest},
+		{name: "key of another device", keyDeviceID: "AVT-002", body: `{"battery":15}`, expectedStatus: http.StatusForbidden},
+		{name: "no device key", body: `{"battery":15}`, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceRepo := repository.NewMockDeviceRepository()
+			monitor := &recordingHealthMonitor{}
+			handler := NewTelemetryHandler(repository.NewMockRepository(), deviceRepo).WithHealthMonitor(monitor)
+
+			deviceRepo.GetByDeviceIDFunc = func(_ context.Context, _ string) (*models.Device, error) {
+				return device, nil
+			}
+			var lastSeen string
+			deviceRepo.UpdateLastSeenFunc = func(_ context.Context, deviceID string) error {
+				lastSeen = deviceID
+				return nil
+			}
+			var firmware string
+			deviceRepo.UpdateFirmwareFunc = func(_ context.Context, _ uuid.UUID, version string) error {
+				firmware = version
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/devices/AVT-001/heartbeat", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: "AVT-001"}}
+			if tt.keyDeviceID != "" {
+				c.Set(string(middleware.UserIDKey), ownerID)
+				c.Set(string(middleware.DeviceIDKey), tt.keyDeviceID)
+			}
+
+			handler.HandleHeartbeat(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusAccepted {
+				assert.Empty(t, lastSeen)
+				assert.Empty(t, monitor.heartbeats)
+				return
+			}
+
+			assert.Equal(t, "AVT-001", lastSeen)
+			assert.Equal(t, tt.expectedFirmware, firmware)
+			require.Len(t, monitor.heartbeats, 1)
+			heartbeat := monitor.heartbeats[0]
+			assert.Equal(t, "AVT-001", heartbeat.DeviceID)
+			assert.Equal(t, ownerID, heartbeat.UserID)
+			assert.False(t, heartbeat.ReceivedAt.IsZero())
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// WithOwnershipHistory enables the device ownership history endpoint
+func (h *DeviceHandler) WithOwnershipHistory(ownershipRepo repository.DeviceOwnershipRepository) *DeviceHandler {
+	h.ownershipRepo = ownershipRepo
+	return h
+}
+
+// GetDeviceHistory lists a device's ownership changes, oldest first
+// Only the owner, owners and admins of the device's organization, and admins may read it,
+// since it names previous owners.
+// GET /api/v1/devices/:id/history
+func (h *DeviceHandler) GetDeviceHistory(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return
+	}
+
+	if middleware.GetUserRole(c) != models.RoleAdmin &&
+		!h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessManage, "device") {
+		return
+	}
+
+	history, err := h.ownershipRepo.ListByDevice(c.Request.Context(), device.ID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve device history"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "history", history, api.Meta{
+		"total": len(history),
+	})
+}
+
+// recordOwnershipChange appends a change to the device's ownership history
+// The device has already changed owner, so a failure is logged rather than returned.
+func recordOwnershipChange(ctx context.Context, ownershipRepo repository.DeviceOwnershipRepository, change *models.DeviceOwnershipChange) {
+	if ownershipRepo == nil {
+		return
+	}
+	if err := ownershipRepo.Record(ctx, change); err != nil {
+		slog.Error("Failed to record device ownership change", "device_id", change.DeviceID, "action", change.Action, "error", err)
+	}
+}
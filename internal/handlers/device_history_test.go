@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceHandler_GetDeviceHistory(t *testing.T) {
+	ownerID := uuid.New()
+	previousOwnerID := uuid.New()
+	adminID := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: ownerID, IsActive: true}
+
+	reason := "Sold with the car"
+	history := []*models.DeviceOwnershipChange{
+		{ID: uuid.New(), DeviceID: device.ID, Action: models.OwnershipActionClaim, ToUserID: &previousOwnerID, ActorID: &previousOwnerID, CreatedAt: time.Now().Add(-time.Hour)},
+		{ID: uuid.New(), DeviceID: device.ID, Action: models.OwnershipActionReassign, FromUserID: &previousOwnerID, ToUserID: &ownerID, ActorID: &adminID, Reason: &reason, CreatedAt: time.Now()},
+	}
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		role           models.Role
+		deviceID       string
+		expectedStatus int
+	}{
+		{name: "owner", userID: ownerID, role: models.RoleUser, deviceID: device.ID.String(), expectedStatus: http.StatusOK},
+		{name: "admin", userID: adminID, role: models.RoleAdmin, deviceID: device.ID.String(), expectedStatus: http.StatusOK},
+		{name: "previous owner", userID: previousOwnerID, role: models.RoleUser, deviceID: device.ID.String(), expectedStatus: http.StatusForbidden},
+		{name: "device not found", userID: ownerID, role: models.RoleUser, deviceID: uuid.New().String(), expectedStatus: http.StatusNotFound},
+		{name: "invalid device id", userID: ownerID, role: models.RoleUser, deviceID: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo := setupDeviceTest()
+			ownershipRepo := repository.NewMockDeviceOwnershipRepository()
+			handler.WithOwnershipHistory(ownershipRepo)
+
+			deviceRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Device, error) {
+				if id == device.ID {
+					return device, nil
+				}
+				return nil, repository.ErrDeviceNotFound
+			}
+			ownershipRepo.ListByDeviceFunc = func(_ context.Context, id uuid.UUID) ([]*models.DeviceOwnershipChange, error) {
+				assert.Equal(t, device.ID, id)
+				return history, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/"+tt.deviceID+"/history", nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.deviceID}}
+			c.Set(string(middleware.UserIDKey), tt.userID)
+			c.Set(string(middleware.UserRoleKey), tt.role)
+
+			handler.GetDeviceHistory(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				History []models.DeviceOwnershipChange `json:"history"`
+				Total   int                            `json:"total"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, 2, response.Total)
+			require.Len(t, response.History, 2)
+			assert.Equal(t, models.OwnershipActionReassign, response.History[1].Action)
+			require.NotNil(t, response.History[1].Reason)
+			assert.Equal(t, reason, *response.History[1].Reason)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// DeviceKeyHandler handles device API key management requests
+type DeviceKeyHandler struct {
+	keyRepo    repository.DeviceAPIKeyRepository
+	deviceRepo repository.DeviceRepository
+	orgs       *orgAccess      // Optional: nil limits key management to personal owners
+	secrets    *auth.SecretBox // Optional: nil mints keys without signing secrets
+}
+
+// NewDeviceKeyHandler creates a new device key handler
+func NewDeviceKeyHandler(keyRepo repository.DeviceAPIKeyRepository, deviceRepo repository.DeviceRepository) *DeviceKeyHandler {
+	return &DeviceKeyHandler{
+		keyRepo:    keyRepo,
+		deviceRepo: deviceRepo,
+	}
+}
+
+// WithOrganizations lets owners and admins of a device's organization manage its keys
+func (h *DeviceKeyHandler) WithOrganizations(orgRepo repository.OrganizationRepository) *DeviceKeyHandler {
+	h.orgs = newOrgAccess(orgRepo)
+	return h
+}
+
+// WithSigningSecrets mints a signing secret with each key, stored sealed in secrets
+func (h *DeviceKeyHandler) WithSigningSecrets(secrets *auth.SecretBox) *DeviceKeyHandler {
+	h.secrets = secrets
+	return h
+}
+
+// CreateDeviceKeyRequest represents the device key creation request body
+type CreateDeviceKeyRequest struct {
+	Name *string `json:"name,omitempty" binding:"omitempty,max=255"`
+}
+
+// CreateDeviceKeyResponse includes the plain key and signing secret, which are only returned once
+type CreateDeviceKeyResponse struct {
+	*models.DeviceAPIKeyResponse
+	Key           string `json:"key"`
+	SigningSecret string `json:"signingSecret,omitempty"`
+}
+
+// CreateKey mints a new API key for a device
+// POST /api/v1/devices/:id/keys
+func (h *DeviceKeyHandler) CreateKey(c *gin.Context) {
+	device, ok := h.getOwnedDevice(c)
+	if !ok {
+		return
+	}
+
+	var req CreateDeviceKeyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+			return
+		}
+	}
+
+	plainKey, prefix, err := auth.GenerateDeviceAPIKey()
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to generate device key"))
+		return
+	}
+
+	key := &models.DeviceAPIKey{
+		ID:        uuid.New(),
+		DeviceID:  device.ID,
+		UserID:    device.UserID,
+		Name:      req.Name,
+		KeyPrefix: prefix,
+		KeyHash:   auth.HashToken(plainKey),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	// Uploads are signed with a separate secret, so the key sent with every request cannot forge them
+	var signingSecret string
+	if h.secrets != nil {
+		if signingSecret, err = auth.GenerateDeviceSigningSecret(); err != nil {
+			problem.Abort(c, problem.Internal("Failed to generate device key"))
+			return
+		}
+		if key.SigningSecret, err = h.secrets.Seal(signingSecret, key.ID[:]); err != nil {
+			problem.Abort(c, problem.Internal("Failed to generate device key"))
+			return
+		}
+	}
+
+	if err := h.keyRepo.Create(c.Request.Context(), key); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create device key"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, CreateDeviceKeyResponse{
+		DeviceAPIKeyResponse: key.ToResponse(),
+		Key:                  plainKey,
+		SigningSecret:        signingSecret,
+	})
+}
+
+// ListKeys retrieves all API keys minted for a device
+// GET /api/v1/devices/:id/keys
+func (h *DeviceKeyHandler) ListKeys(c *gin.Context) {
+	device, ok := h.getOwnedDevice(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.keyRepo.ListByDeviceID(c.Request.Context(), device.ID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve device keys"))
+		return
+	}
+
+	response := make([]*models.DeviceAPIKeyResponse, len(keys))
+	for i, key := range keys {
+		response[i] = key.ToResponse()
+	}
+
+	api.List(c, http.StatusOK, "keys", response, api.Meta{
+		"total": len(response),
+	})
+}
+
+// RevokeKey revokes a device API key
+// DELETE /api/v1/devices/:id/keys/:keyId
+func (h *DeviceKeyHandler) RevokeKey(c *gin.Context) {
+	device, ok := h.getOwnedDevice(c)
+	if !ok {
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("keyId"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_key_id", "Invalid key ID format"))
+		return
+	}
+
+	key, err := h.keyRepo.GetByID(c.Request.Context(), keyID)
+	if err != nil || key.DeviceID != device.ID {
+		if err != nil && !errors.Is(err, repository.ErrDeviceAPIKeyNotFound) {
+			problem.Abort(c, problem.Internal("Failed to retrieve device key"))
+			return
+		}
+		problem.Abort(c, problem.NotFound("key_not_found", "Device key not found"))
+		return
+	}
+
+	if err := h.keyRepo.Revoke(c.Request.Context(), key.ID); err != nil {
+		if errors.Is(err, repository.ErrDeviceAPIKeyRevoked) {
+			problem.Abort(c, problem.Conflict("key_already_revoked", "Device key has already been revoked"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to revoke device key"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Device key revoked successfully",
+	})
+}
+
+// getOwnedDevice loads the device referenced by the :id path parameter and
+// verifies the authenticated user may manage it. It writes the error response
+// and returns false when the device cannot be used.
+func (h *DeviceKeyHandler) getOwnedDevice(c *gin.Context) (*models.Device, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return nil, false
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return nil, false
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return nil, false
+	}
+
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessManage, "device") {
+		return nil, false
+	}
+
+	return device, true
+}
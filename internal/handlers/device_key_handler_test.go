@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDeviceKeyTest(owner uuid.UUID) (*DeviceKeyHandler, *repository.MockDeviceAPIKeyRepository, *models.Device) {
+	keyRepo := repository.NewMockDeviceAPIKeyRepository()
+	deviceRepo := repository.NewMockDeviceRepository()
+
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: owner, IsActive: true}
+	deviceRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Device, error) {
+		if id == device.ID {
+			return device, nil
+		}
+		return nil, repository.ErrDeviceNotFound
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	return NewDeviceKeyHandler(keyRepo, deviceRepo), keyRepo, device
+}
+
+func TestDeviceKeyHandler_CreateKey_Success(t *testing.T) {
+	userID := uuid.New()
+	handler, keyRepo, device := setupDeviceKeyTest(userID)
+
+	var stored *models.DeviceAPIKey
+	keyRepo.CreateFunc = func(_ context.Context, key *models.DeviceAPIKey) error {
+		stored = key
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/devices/"+device.ID.String()+"/keys", bytes.NewBufferString(`{"name":"Garage logger"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: device.ID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.CreateKey(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, stored)
+	assert.Equal(t, device.ID, stored.DeviceID)
+	assert.Equal(t, "Garage logger", *stored.Name)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	plainKey, ok := response["key"].(string)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(plainKey, auth.DeviceAPIKeyPrefix))
+	assert.Equal(t, auth.HashToken(plainKey), stored.KeyHash, "only the hash should be stored")
+	assert.Equal(t, stored.KeyPrefix, response["keyPrefix"])
+	assert.NotContains(t, w.Body.String(), stored.KeyHash)
+	assert.NotContains(t, response, "signingSecret", "keys are minted without signing secrets unless configured")
+}
+
+func TestDeviceKeyHandler_CreateKey_SigningSecret(t *testing.T) {
+	userID := uuid.New()
+	handler, keyRepo, device := setupDeviceKeyTest(userID)
+	secrets, err := auth.NewSecretBox(base64.StdEncoding.EncodeToString(make([]byte, auth.SecretBoxKeyLength)))
+	require.NoError(t, err)
+	handler.WithSigningSecrets(secrets)
+
+	var stored *models.DeviceAPIKey
+	keyRepo.CreateFunc = func(_ context.Context, key *models.DeviceAPIKey) error {
+		stored = key
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/devices/"+device.ID.String()+"/keys", nil)
+	c.Params = gin.Params{{Key: "id", Value: device.ID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.CreateKey(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, stored)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	signingSecret, ok := response["signingSecret"].(string)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(signingSecret, auth.DeviceSigningSecretPrefix))
+	assert.NotEqual(t, signingSecret, response["key"])
+	assert.NotContains(t, stored.SigningSecret, signingSecret, "only the sealed secret should be stored")
+	assert.NotContains(t, w.Body.String(), stored.SigningSecret)
+
+	opened, err := secrets.Open(stored.SigningSecret, stored.ID[:])
+	require.NoError(t, err)
+	assert.Equal(t, signingSecret, opened)
+}
+
+func TestDeviceKeyHandler_CreateKey_NotOwner(t *testing.T) {
+	handler, _, device := setupDeviceKeyTest(uuid.New())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/devices/"+device.ID.String()+"/keys", nil)
+	c.Params = gin.Params{{Key: "id", Value: device.ID.String()}}
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.CreateKey(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestDeviceKeyHandler_ListKeys(t *testing.T) {
+	userID := uuid.New()
+	handler, keyRepo, device := setupDeviceKeyTest(userID)
+
+	revokedAt := time.Now()
+	keyRepo.ListByDeviceIDFunc = func(_ context.Context, deviceID uuid.UUID) ([]*models.DeviceAPIKey, error) {
+		return []*models.DeviceAPIKey{
+			{ID: uuid.New(), DeviceID: deviceID, KeyPrefix: "avtdk_abc123", KeyHash: "secret-hash", CreatedAt: time.Now()},
+			{ID: uuid.New(), DeviceID: deviceID, KeyPrefix: "avtdk_def456", KeyHash: "secret-hash", CreatedAt: time.Now(), RevokedAt: &revokedAt},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/"+device.ID.String()+"/keys", nil)
+	c.Params = gin.Params{{Key: "id", Value: device.ID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListKeys(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(2), response["total"])
+}
+
+func TestDeviceKeyHandler_RevokeKey(t *testing.T) {
+	userID := uuid.New()
+	handler, keyRepo, device := setupDeviceKeyTest(userID)
+
+	ownKeyID := uuid.New()
+	otherDeviceKeyID := uuid.New()
+	revokedKeyID := uuid.New()
+	keyRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.DeviceAPIKey, error) {
+		switch id {
+		case ownKeyID, revokedKeyID:
+			return &models.DeviceAPIKey{ID: id, DeviceID: device.ID}, nil
+		case otherDeviceKeyID:
+			return &models.DeviceAPIKey{ID: id, DeviceID: uuid.New()}, nil
+		}
+		return nil, repository.ErrDeviceAPIKeyNotFound
+	}
+	keyRepo.RevokeFunc = func(_ context.Context, id uuid.UUID) error {
+		if id == revokedKeyID {
+			return repository.ErrDeviceAPIKeyRevoked
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name           string
+		keyID          string
+		expectedStatus int
+	}{
+		{name: "own key", keyID: ownKeyID.String(), expectedStatus: http.StatusOK},
+		{name: "already revoked", keyID: revokedKeyID.String(), expectedStatus: http.StatusConflict},
+		{name: "key of another device", keyID: otherDeviceKeyID.String(), expectedStatus: http.StatusNotFound},
+		{name: "unknown key", keyID: uuid.New().String(), expectedStatus: http.StatusNotFound},
+		{name: "invalid key id", keyID: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/devices/"+device.ID.String()+"/keys/"+tt.keyID, nil)
+			c.Params = gin.Params{{Key: "id", Value: device.ID.String()}, {Key: "keyId", Value: tt.keyID}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.RevokeKey(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// DeviceBackfiller schedules background assignment of an adopted device's anonymous history
+type DeviceBackfiller interface {
+	Enqueue(deviceID string)
+}
+
+// DeviceRegistrationHandler handles anonymous device pre-registration and adoption requests
+type DeviceRegistrationHandler struct {
+	registrationRepo repository.DeviceRegistrationRepository
+	ownershipRepo    repository.DeviceOwnershipRepository // Optional: nil leaves adoptions out of the ownership history
+	backfiller       DeviceBackfiller                     // Optional: nil leaves backfills to the periodic sweep
+	quotas           *Quotas                              // Optional: nil disables the device limit on adoption
+	events           EventPublisher                       // Optional: nil publishes no device claim events
+}
+
+// NewDeviceRegistrationHandler creates a new device registration handler
+func NewDeviceRegistrationHandler(registrationRepo repository.DeviceRegistrationRepository) *DeviceRegistrationHandler {
+	return &DeviceRegistrationHandler{
+		registrationRepo: registrationRepo,
+	}
+}
+
+// WithBackfiller sets the backfiller notified when a device is adopted
+func (h *DeviceRegistrationHandler) WithBackfiller(backfiller DeviceBackfiller) *DeviceRegistrationHandler {
+	h.backfiller = backfiller
+	return h
+}
+
+// WithOwnershipHistory records adoptions in the device ownership history
+func (h *DeviceRegistrationHandler) WithOwnershipHistory(ownershipRepo repository.DeviceOwnershipRepository) *DeviceRegistrationHandler {
+	h.ownershipRepo = ownershipRepo
+	return h
+}
+
+// WithEvents publishes a device.claimed event for every adopted device
+func (h *DeviceRegistrationHandler) WithEvents(publisher EventPublisher) *DeviceRegistrationHandler {
+	h.events = publisher
+	return h
+}
+
+// WithQuotas enforces the adopter's plan device limit
+func (h *DeviceRegistrationHandler) WithQuotas(quotas *Quotas) *DeviceRegistrationHandler {
+	h.quotas = quotas
+	return h
+}
+
+// RegisterDeviceRequest represents the device pre-registration request body
+type RegisterDeviceRequest struct {
+	DeviceID string `json:"deviceId" binding:"required,max=50"`
+}
+
+// RegisterDeviceResponse includes the plain claim code, which is only returned once
+type RegisterDeviceResponse struct {
+	DeviceID  string    `json:"deviceId"`
+	ClaimCode string    `json:"claimCode"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AdoptDeviceRequest represents the device adoption request body
+type AdoptDeviceRequest struct {
+	ClaimCode  string  `json:"claimCode" binding:"required"`
+	DeviceName *string `json:"deviceName,omitempty" binding:"omitempty,max=255"`
+}
+
+// AdoptDeviceResponse represents an adopted device; its history is backfilled in the background
+type AdoptDeviceResponse struct {
+	DeviceResponse
+	Backfill string `json:"backfill"`
+}
+
+// RegisterDevice pre-registers a device that uploads before it has an owner
+// The device keeps uploading anonymously and shows the returned claim code to whoever will adopt it.
+// POST /api/v1/devices/register
+func (h *DeviceRegistrationHandler) RegisterDevice(c *gin.Context) {
+	var req RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	claimCode, err := auth.GenerateDeviceClaimCode()
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to generate claim code"))
+		return
+	}
+
+	registration := &models.DeviceRegistration{
+		DeviceID:      req.DeviceID,
+		ClaimCodeHash: auth.HashToken(auth.NormalizeDeviceClaimCode(claimCode)),
+	}
+	if err := h.registrationRepo.Create(c.Request.Context(), registration); err != nil {
+		if errors.Is(err, repository.ErrDeviceRegistrationExists) {
+			problem.Abort(c, problem.Conflict("device_already_registered", "Device is already registered"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to register device"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, RegisterDeviceResponse{
+		DeviceID:  registration.DeviceID,
+		ClaimCode: claimCode,
+		CreatedAt: registration.CreatedAt,
+	})
+}
+
+// AdoptDevice binds a pre-registered device to the authenticated user, who proves possession with its claim code
+// The device's anonymous telemetry, sessions and upload batches are assigned to the user in the background.
+// POST /api/v1/devices/:id/adopt (where :id is the hardware device ID)
+func (h *DeviceRegistrationHandler) AdoptDevice(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	deviceID := c.Param("id")
+
+	var req AdoptDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	registration, err := h.registrationRepo.GetByDeviceID(c.Request.Context(), deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceRegistrationNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_registered", "Device is not registered"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device registration"))
+		return
+	}
+
+	codeHash := auth.HashToken(auth.NormalizeDeviceClaimCode(req.ClaimCode))
+	if subtle.ConstantTimeCompare([]byte(codeHash), []byte(registration.ClaimCodeHash)) != 1 {
+		problem.Abort(c, problem.Forbidden("invalid_claim_code", "Claim code does not match this device"))
+		return
+	}
+
+	if registration.IsAdopted() {
+		rejectAdopted(c)
+		return
+	}
+
+	if h.quotas != nil {
+		if err := h.quotas.checkDeviceLimit(c.Request.Context(), userID); err != nil {
+			problem.Abort(c, problem.New(http.StatusPaymentRequired, "device_limit_reached", "Device limit reached for your plan"))
+			return
+		}
+	}
+
+	now := time.Now()
+	device := &models.Device{
+		ID:         uuid.New(),
+		DeviceID:   deviceID,
+		UserID:     userID,
+		DeviceName: req.DeviceName,
+		ClaimedAt:  now,
+		IsActive:   true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	ctx := withDeviceClaimedEvents(c.Request.Context(), h.events, models.DeviceClaimAdoption)
+	if err := h.registrationRepo.Adopt(ctx, device); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDeviceRegistrationAdopted):
+			rejectAdopted(c)
+		case errors.Is(err, repository.ErrDeviceExists):
+			problem.Abort(c, problem.Conflict("device_already_claimed", "Device has already been claimed"))
+		default:
+			slog.Error("Error adopting device", "device_id", deviceID, "error", err)
+			problem.Abort(c, problem.Internal("Failed to adopt device"))
+		}
+		return
+	}
+
+	slog.Info("Device adopted", "device_id", deviceID, "user_id", userID)
+	recordOwnershipChange(c.Request.Context(), h.ownershipRepo, &models.DeviceOwnershipChange{
+		DeviceID: device.ID,
+		Action:   models.OwnershipActionAdopt,
+		ToUserID: &userID,
+		ActorID:  &userID,
+	})
+	notifyEvents(h.events)
+	if h.backfiller != nil {
+		h.backfiller.Enqueue(deviceID)
+	}
+
+	api.Respond(c, http.StatusAccepted, AdoptDeviceResponse{
+		DeviceResponse: DeviceResponse{
+			ID:         device.ID.String(),
+			DeviceID:   device.DeviceID,
+			DeviceName: device.DeviceName,
+			ClaimedAt:  device.ClaimedAt.Format("2006-01-02T15:04:05Z07:00"),
+			IsActive:   device.IsActive,
+			CreatedAt:  device.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			UpdatedAt:  device.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		},
+		Backfill: "pending",
+	})
+}
+
+// rejectAdopted responds that the device already has an adopter
+func rejectAdopted(c *gin.Context) {
+	problem.Abort(c, problem.Conflict("device_already_adopted", "Device has already been adopted"))
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingBackfiller records the devices enqueued for backfill
+type recordingBackfiller struct {
+	deviceIDs []string
+}
+
+func (b *recordingBackfiller) Enqueue(deviceID string) {
+	b.deviceIDs = append(b.deviceIDs, deviceID)
+}
+
+func adoptRequest(userID uuid.UUID, deviceID, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/devices/"+deviceID+"/adopt", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: deviceID}}
+	c.Set(string(middleware.UserIDKey), userID)
+	return c, w
+}
+
+func TestDeviceRegistrationHandler_RegisterDevice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := repository.NewMockDeviceRegistrationRepository()
+
+	var stored *models.DeviceRegistration
+	repo.CreateFunc = func(_ context.Context, registration *models.DeviceRegistration) error {
+		stored = registration
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/devices/register", bytes.NewBufferString(`{"deviceId":"ANON-001"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	NewDeviceRegistrationHandler(repo).RegisterDevice(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, stored)
+
+	var response RegisterDeviceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "ANON-001", response.DeviceID)
+	assert.Equal(t, auth.HashToken(auth.NormalizeDeviceClaimCode(response.ClaimCode)), stored.ClaimCodeHash, "only the hash should be stored")
+	assert.NotContains(t, w.Body.String(), stored.ClaimCodeHash)
+}
+
+func TestDeviceRegistrationHandler_RegisterDevice_AlreadyRegistered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := repository.NewMockDeviceRegistrationRepository()
+	repo.CreateFunc = func(_ context.Context, _ *models.DeviceRegistration) error {
+		return repository.ErrDeviceRegistrationExists
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/devices/register", bytes.NewBufferString(`{"deviceId":"ANON-001"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	NewDeviceRegistrationHandler(repo).RegisterDevice(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestDeviceRegistrationHandler_AdoptDevice(t *testing.T) {
+	userID := uuid.New()
+	repo := repository.NewMockDeviceRegistrationRepository()
+	repo.GetByDeviceIDFunc = func(_ context.Context, deviceID string) (*models.DeviceRegistration, error) {
+		return &models.DeviceRegistration{
+			DeviceID:      deviceID,
+			ClaimCodeHash: auth.HashToken(auth.NormalizeDeviceClaimCode("ABCD-2345-WXYZ-6789")),
+		}, nil
+	}
+
+	var adopted *models.Device
+	repo.AdoptFunc = func(_ context.Context, device *models.Device) error {
+		adopted = device
+		return nil
+	}
+	backfiller := &recordingBackfiller{}
+	handler := NewDeviceRegistrationHandler(repo).WithBackfiller(backfiller)
+
+	t.Run("wrong claim code", func(t *testing.T) {
+		c, w := adoptRequest(userID, "ANON-001", `{"claimCode":"AAAA-AAAA-AAAA-AAAA"}`)
+		handler.AdoptDevice(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Nil(t, adopted)
+		assert.Empty(t, backfiller.deviceIDs)
+	})
+
+	t.Run("claim code as typed", func(t *testing.T) {
+		c, w := adoptRequest(userID, "ANON-001", `{"claimCode":"abcd2345 wxyz6789","deviceName":"Track car"}`)
+		handler.AdoptDevice(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		require.NotNil(t, adopted)
+		assert.Equal(t, "ANON-001", adopted.DeviceID)
+		assert.Equal(t, userID, adopted.UserID)
+		assert.Equal(t, "Track car", *adopted.DeviceName)
+		assert.Equal(t, []string{"ANON-001"}, backfiller.deviceIDs)
+
+		var response AdoptDeviceResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "pending", response.Backfill)
+	})
+}
+
+func TestDeviceRegistrationHandler_AdoptDevice_Conflicts(t *testing.T) {
+	code := "ABCD-2345-WXYZ-6789"
+	registration := &models.DeviceRegistration{
+		DeviceID:      "ANON-001",
+		ClaimCodeHash: auth.HashToken(auth.NormalizeDeviceClaimCode(code)),
+	}
+
+	tests := []struct {
+		name     string
+		getErr   error
+		adoptErr error
+		expected int
+	}{
+		{name: "not registered", getErr: repository.ErrDeviceRegistrationNotFound, expected: http.StatusNotFound},
+		{name: "already adopted", adoptErr: repository.ErrDeviceRegistrationAdopted, expected: http.StatusConflict},
+		{name: "claimed through upload", adoptErr: repository.ErrDeviceExists, expected: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMockDeviceRegistrationRepository()
+			repo.GetByDeviceIDFunc = func(_ context.Context, _ string) (*models.DeviceRegistration, error) {
+				if tt.getErr != nil {
+					return nil, tt.getErr
+				}
+				return registration, nil
+			}
+			repo.AdoptFunc = func(_ context.Context, _ *models.Device) error {
+				return tt.adoptErr
+			}
+
+			c, w := adoptRequest(uuid.New(), "ANON-001", `{"claimCode":"`+code+`"}`)
+			NewDeviceRegistrationHandler(repo).AdoptDevice(c)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// SetDeviceTagsRequest represents the request body replacing a device's tags
+type SetDeviceTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// AddDeviceTagRequest represents the request body adding a tag to a device
+type AddDeviceTagRequest struct {
+	Tag string `json:"tag" binding:"required"`
+}
+
+// ListTags summarizes the tags of the devices the authenticated user can list
+// GET /api/v1/devices/tags
+func (h *DeviceHandler) ListTags(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	orgIDs, err := h.orgs.orgIDs(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve organizations"))
+		return
+	}
+
+	counts, err := h.deviceRepo.TagCounts(c.Request.Context(), repository.DeviceFilter{UserID: userID, OrgIDs: orgIDs})
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve device tags"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"tags": counts,
+	})
+}
+
+// SetDeviceTags replaces a device's tags
+// PUT /api/v1/devices/:id/tags
+func (h *DeviceHandler) SetDeviceTags(c *gin.Context) {
+	var req SetDeviceTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	tags := make([]string, 0, len(req.Tags))
+	for _, raw := range req.Tags {
+		tag, err := models.ParseDeviceTag(raw)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_tag", fmt.Sprintf("Invalid tag %q: tags are 1 to %d letters, digits, spaces, '-', '_', '.' or ':'", raw, models.MaxDeviceTagLength)))
+			return
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > models.MaxTagsPerDevice {
+		problem.Abort(c, problem.BadRequest("too_many_tags", fmt.Sprintf("A device can carry at most %d tags", models.MaxTagsPerDevice)))
+		return
+	}
+
+	device, ok := h.manageableDevice(c)
+	if !ok {
+		return
+	}
+
+	slices.Sort(tags)
+	if err := h.deviceRepo.SetTags(c.Request.Context(), device.ID, tags); err != nil {
+		h.respondTagChangeError(c, err)
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"tags": tags,
+	})
+}
+
+// AddDeviceTag adds a tag to a device
+// POST /api/v1/devices/:id/tags
+func (h *DeviceHandler) AddDeviceTag(c *gin.Context) {
+	var req AddDeviceTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	tag, err := models.ParseDeviceTag(req.Tag)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_tag", fmt.Sprintf("Tags are 1 to %d letters, digits, spaces, '-', '_', '.' or ':'", models.MaxDeviceTagLength)))
+		return
+	}
+
+	device, ok := h.manageableDevice(c)
+	if !ok {
+		return
+	}
+
+	current, ok := h.deviceTags(c, device.ID)
+	if !ok {
+		return
+	}
+	tags := tagsOf(current, device.ID)
+	if slices.Contains(tags, tag) {
+		api.Respond(c, http.StatusOK, gin.H{
+			"tags": tags,
+		})
+		return
+	}
+	if len(tags) >= models.MaxTagsPerDevice {
+		problem.Abort(c, problem.BadRequest("too_many_tags", fmt.Sprintf("A device can carry at most %d tags", models.MaxTagsPerDevice)))
+		return
+	}
+
+	if err := h.deviceRepo.AddTag(c.Request.Context(), device.ID, tag); err != nil {
+		h.respondTagChangeError(c, err)
+		return
+	}
+
+	tags = append(tags, tag)
+	slices.Sort(tags)
+	api.Respond(c, http.StatusOK, gin.H{
+		"tags": tags,
+	})
+}
+
+// RemoveDeviceTag removes a tag from a device
+// DELETE /api/v1/devices/:id/tags/:tag
+func (h *DeviceHandler) RemoveDeviceTag(c *gin.Context) {
+	device, ok := h.manageableDevice(c)
+	if !ok {
+		return
+	}
+
+	if err := h.deviceRepo.RemoveTag(c.Request.Context(), device.ID, c.Param("tag")); err != nil {
+		if errors.Is(err, repository.ErrDeviceTagNotFound) {
+			problem.Abort(c, problem.NotFound("tag_not_found", "The device does not carry this tag"))
+			return
+		}
+		h.respondTagChangeError(c, err)
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Tag removed successfully",
+	})
+}
+
+// manageableDevice loads the device named by the :id parameter, responding with an error unless
+// the user owns it or manages its organization
+func (h *DeviceHandler) manageableDevice(c *gin.Context) (*models.Device, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return nil, false
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return nil, false
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return nil, false
+	}
+
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessManage, "device") {
+		return nil, false
+	}
+
+	return device, true
+}
+
+// respondTagChangeError responds to a failed tag change
+func (h *DeviceHandler) respondTagChangeError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrDeviceNotFound) {
+		problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+		return
+	}
+	problem.Abort(c, problem.Internal("Failed to update device tags"))
+}
+
+// deviceTags retrieves the tags of the given devices, responding with an error when that fails
+func (h *DeviceHandler) deviceTags(c *gin.Context, ids ...uuid.UUID) (map[uuid.UUID][]string, bool) {
+	tags, err := h.deviceRepo.TagsByDevice(c.Request.Context(), ids)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve device tags"))
+		return nil, false
+	}
+	return tags, true
+}
+
+// tagsOf returns a device's tags from a TagsByDevice result, never nil
+func tagsOf(tags map[uuid.UUID][]string, id uuid.UUID) []string {
+	if deviceTags, ok := tags[id]; ok {
+		return deviceTags
+	}
+	return []string{}
+}
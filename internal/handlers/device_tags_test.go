@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDeviceTagTest returns a device handler whose mock repository holds one device owned by userID
+func setupDeviceTagTest(userID uuid.UUID) (*DeviceHandler, *repository.MockDeviceRepository, *models.Device) {
+	handler, deviceRepo := setupDeviceTest()
+
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "RACEBOX-001",
+		UserID:    userID,
+		ClaimedAt: time.Now().Add(-24 * time.Hour),
+		IsActive:  true,
+		CreatedAt: time.Now().Add(-24 * time.Hour),
+		UpdatedAt: time.Now(),
+	}
+	deviceRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Device, error) {
+		if id == device.ID {
+			return device, nil
+		}
+		return nil, repository.ErrDeviceNotFound
+	}
+
+	return handler, deviceRepo, device
+}
+
+// deviceTagRequest builds a test context for a tag request on the device
+func deviceTagRequest(method string, device *models.Device, userID uuid.UUID, body interface{}) (*gin.Context, *httptest.ResponseRecorder) {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/api/v1/devices/"+device.ID.String()+"/tags", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: device.ID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+	return c, w
+}
+
+func TestDeviceHandler_SetDeviceTags(t *testing.T) {
+	userID := uuid.New()
+	handler, deviceRepo, device := setupDeviceTagTest(userID)
+
+	var stored []string
+	deviceRepo.SetTagsFunc = func(_ context.Context, id uuid.UUID, tags []string) error {
+		assert.Equal(t, device.ID, id)
+		stored = tags
+		return nil
+	}
+
+	c, w := deviceTagRequest(http.MethodPut, device, userID, SetDeviceTagsRequest{Tags: []string{" rental-fleet-A ", "kart", "kart"}})
+	handler.SetDeviceTags(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"kart", "rental-fleet-A"}, stored, "tags are trimmed and deduplicated")
+
+	var response struct {
+		Tags []string `json:"tags"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"kart", "rental-fleet-A"}, response.Tags)
+}
+
+func TestDeviceHandler_SetDeviceTags_Invalid(t *testing.T) {
+	userID := uuid.New()
+	tooMany := make([]string, models.MaxTagsPerDevice+1)
+	for i := range tooMany {
+		tooMany[i] = "tag-" + strings.Repeat("x", i+1)
+	}
+
+	tests := []struct {
+		name string
+		tags []string
+		code string
+	}{
+		{"invalid characters", []string{"kart", "a/b"}, "invalid_tag"},
+		{"empty tag", []string{""}, "invalid_tag"},
+		{"too many", tooMany, "too_many_tags"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo, device := setupDeviceTagTest(userID)
+			deviceRepo.SetTagsFunc = func(_ context.Context, _ uuid.UUID, _ []string) error {
+				t.Fatal("tags must not be stored")
+				return nil
+			}
+
+			c, w := deviceTagRequest(http.MethodPut, device, userID, SetDeviceTagsRequest{Tags: tt.tags})
+			handler.SetDeviceTags(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.code)
+		})
+	}
+}
+
+func TestDeviceHandler_SetDeviceTags_Forbidden(t *testing.T) {
+	handler, deviceRepo, device := setupDeviceTagTest(uuid.New())
+	deviceRepo.SetTagsFunc = func(_ context.Context, _ uuid.UUID, _ []string) error {
+		t.Fatal("tags must not be stored")
+		return nil
+	}
+
+	c, w := deviceTagRequest(http.MethodPut, device, uuid.New(), SetDeviceTagsRequest{Tags: []string{"kart"}})
+	handler.SetDeviceTags(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestDeviceHandler_AddDeviceTag(t *testing.T) {
+	userID := uuid.New()
+	handler, deviceRepo, device := setupDeviceTagTest(userID)
+
+	deviceRepo.TagsByDeviceFunc = func(_ context.Context, _ []uuid.UUID) (map[uuid.UUID][]string, error) {
+		return map[uuid.UUID][]string{device.ID: {"rental-fleet-A"}}, nil
+	}
+	var added string
+	deviceRepo.AddTagFunc = func(_ context.Context, _ uuid.UUID, tag string) error {
+		added = tag
+		return nil
+	}
+
+	c, w := deviceTagRequest(http.MethodPost, device, userID, AddDeviceTagRequest{Tag: "kart"})
+	handler.AddDeviceTag(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "kart", added)
+	assert.JSONEq(t, `{"tags": ["kart", "rental-fleet-A"]}`, w.Body.String())
+}
+
+func TestDeviceHandler_AddDeviceTag_Limit(t *testing.T) {
+	userID := uuid.New()
+	handler, deviceRepo, device := setupDeviceTagTest(userID)
+
+	full := make([]string, models.MaxTagsPerDevice)
+	for i := range full {
+		full[i] = "tag-" + strings.Repeat("x", i+1)
+	}
+	deviceRepo.TagsByDeviceFunc = func(_ context.Context, _ []uuid.UUID) (map[uuid.UUID][]string, error) {
+		return map[uuid.UUID][]string{device.ID: full}, nil
+	}
+	deviceRepo.AddTagFunc = func(_ context.Context, _ uuid.UUID, _ string) error {
+		t.Fatal("tag must not be stored")
+		return nil
+	}
+
+	// A tag the device already carries is accepted even when the limit is reached
+	c, w := deviceTagRequest(http.MethodPost, device, userID, AddDeviceTagRequest{Tag: full[0]})
+	handler.AddDeviceTag(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	c, w = deviceTagRequest(http.MethodPost, device, userID, AddDeviceTagRequest{Tag: "kart"})
+	handler.AddDeviceTag(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "too_many_tags")
+}
+
+func TestDeviceHandler_RemoveDeviceTag(t *testing.T) {
+	userID := uuid.New()
+	handler, deviceRepo, device := setupDeviceTagTest(userID)
+
+	deviceRepo.RemoveTagFunc = func(_ context.Context, _ uuid.UUID, tag string) error {
+		if tag == "kart" {
+			return nil
+		}
+		return repository.ErrDeviceTagNotFound
+	}
+
+	c, w := deviceTagRequest(http.MethodDelete, device, userID, nil)
+	c.Params = append(c.Params, gin.Param{Key: "tag", Value: "kart"})
+	handler.RemoveDeviceTag(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	c, w = deviceTagRequest(http.MethodDelete, device, userID, nil)
+	c.Params = append(c.Params, gin.Param{Key: "tag", Value: "boat"})
+	handler.RemoveDeviceTag(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "tag_not_found")
+}
+
+func TestDeviceHandler_ListTags(t *testing.T) {
+	handler, deviceRepo := setupDeviceTest()
+	userID := uuid.New()
+
+	deviceRepo.TagCountsFunc = func(_ context.Context, filter repository.DeviceFilter) ([]*models.DeviceTagCount, error) {
+		assert.Equal(t, userID, filter.UserID)
+		return []*models.DeviceTagCount{{Tag: "kart", Devices: 12, Active: 11, Online: 4}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/tags", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListTags(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"tags": [{"tag": "kart", "devices": 12, "active": 11, "online": 4}]}`, w.Body.String())
+}
+
+func TestDeviceHandler_GetDevice_IncludesTags(t *testing.T) {
+	userID := uuid.New()
+	handler, deviceRepo, device := setupDeviceTagTest(userID)
+
+	deviceRepo.TagsByDeviceFunc = func(_ context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+		assert.Equal(t, []uuid.UUID{device.ID}, ids)
+		return map[uuid.UUID][]string{device.ID: {"kart"}}, nil
+	}
+
+	c, w := deviceTagRequest(http.MethodGet, device, userID, nil)
+	handler.GetDevice(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response DeviceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"kart"}, response.Tags)
+}
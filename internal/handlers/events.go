@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// EventPublisher delivers domain events to their subscribers
+// Changes store their events in the outbox in their own transaction (see withEvents); Notify then
+// wakes the dispatcher.
+type EventPublisher interface {
+	Notify()
+}
+
+// withEvents returns a context under which the change a repository stores also stores the events
+// build returns for the records it stored, in the same transaction
+// The context is returned unchanged when there is no publisher. Derive it for a single change.
+func withEvents[T any](ctx context.Context, publisher EventPublisher, build func(stored []T) ([]*models.Event, error)) context.Context {
+	if publisher == nil {
+		return ctx
+	}
+	return repository.WithEvents(ctx, build)
+}
+
+// withEvent returns a context under which the change a repository stores also stores an event with
+// the given payload, when it stores a T record
+func withEvent[T any](ctx context.Context, publisher EventPublisher, eventType models.EventType, payload interface{}) context.Context {
+	return withEvents(ctx, publisher, func(_ []T) ([]*models.Event, error) {
+		event, err := models.NewEvent(eventType, payload)
+		if err != nil {
+			return nil, err
+		}
+		return []*models.Event{event}, nil
+	})
+}
+
+// notifyEvents wakes the publisher to deliver the events of a change that was just stored
+func notifyEvents(publisher EventPublisher) {
+	if publisher != nil {
+		publisher.Notify()
+	}
+}
+
+// withDeviceClaimedEvents returns a context under which claiming devices stores a device.claimed
+// event for each device the claim newly stored, recording where the claim came from
+func withDeviceClaimedEvents(ctx context.Context, publisher EventPublisher, source string) context.Context {
+	return withEvents(ctx, publisher, func(claimed []*models.Device) ([]*models.Event, error) {
+		events := make([]*models.Event, 0, len(claimed))
+		for _, device := range claimed {
+			event, err := models.NewEvent(models.EventDeviceClaimed, models.DeviceClaimed{
+				ID:       device.ID,
+				DeviceID: device.DeviceID,
+				UserID:   device.UserID,
+				OrgID:    device.OrgID,
+				Source:   source,
+			})
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+		return events, nil
+	})
+}
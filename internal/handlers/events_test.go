@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher captures the events stored by mocked changes
+type recordingPublisher struct {
+	events   []*models.Event
+	notified int
+}
+
+func (p *recordingPublisher) Notify() {
+	p.notified++
+}
+
+// storeEvents records the events a change made under ctx stores with the records it stored, as the
+// repositories do in the change's transaction
+func storeEvents[T any](t *testing.T, p *recordingPublisher, ctx context.Context, stored []T) {
+	t.Helper()
+	events, err := repository.BuildEvents(ctx, stored)
+	require.NoError(t, err)
+	p.events = append(p.events, events...)
+}
+
+// decodeEvents returns the payloads of the recorded events of eventType
+func decodeEvents[T any](t *testing.T, p *recordingPublisher, eventType models.EventType) []T {
+	t.Helper()
+	var payloads []T
+	for _, event := range p.events {
+		if event.Type != eventType {
+			continue
+		}
+		var payload T
+		require.NoError(t, event.Decode(&payload))
+		payloads = append(payloads, payload)
+	}
+	return payloads
+}
+
+func TestTelemetryHandler_BatchPostStoresBatchSaved(t *testing.T) {
+	publisher := &recordingPublisher{}
+	repo := repository.NewMockRepository()
+	repo.SaveBatchFunc = func(ctx context.Context, data []*models.TelemetryData) error {
+		// The second record is already stored
+		data[1].Duplicate = true
+		storeEvents(t, publisher, ctx, data[:1])
+		return nil
+	}
+	handler := NewTelemetryHandler(repo, repository.NewMockDeviceRepository()).WithEvents(publisher)
+
+	router := gin.New()
+	router.POST("/api/telemetry/batch", handler.HandleBatchPost)
+
+	base := time.Now().UTC().Truncate(time.Second)
+	body, _ := json.Marshal([]models.TelemetryData{
+		{DeviceID: "AVT-001", Timestamp: base},
+		{DeviceID: "AVT-001", Timestamp: base.Add(time.Second)},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/telemetry/batch", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	batches := decodeEvents[models.TelemetryBatchSaved](t, publisher, models.EventTelemetryBatchSaved)
+	require.Len(t, batches, 1, "the event is stored by the save")
+	assert.Equal(t, 1, batches[0].Records)
+	assert.Equal(t, 1, publisher.notified)
+}
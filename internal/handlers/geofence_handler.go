@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Maximum page size for geofence event queries
+const maxGeofenceEventLimit = 1000
+
+// GeofenceHandler handles geofence management requests
+type GeofenceHandler struct {
+	geofenceRepo repository.GeofenceRepository
+}
+
+// NewGeofenceHandler creates a new geofence handler
+func NewGeofenceHandler(geofenceRepo repository.GeofenceRepository) *GeofenceHandler {
+	return &GeofenceHandler{
+		geofenceRepo: geofenceRepo,
+	}
+}
+
+// CreateGeofenceRequest represents the geofence creation request body
+type CreateGeofenceRequest struct {
+	Name         string              `json:"name" binding:"required,max=255"`
+	Kind         models.GeofenceKind `json:"kind" binding:"required"`
+	Center       *geo.Point          `json:"center,omitempty"`
+	RadiusMeters *float64            `json:"radiusMeters,omitempty"`
+	Polygon      []geo.Point         `json:"polygon,omitempty"`
+	NotifyEmail  bool                `json:"notifyEmail"`
+	WebhookURL   *string             `json:"webhookUrl,omitempty"`
+}
+
+// CreateGeofence defines a new circular or polygon geofence
+// POST /api/v1/geofences
+func (h *GeofenceHandler) CreateGeofence(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateGeofenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "name is required"))
+		return
+	}
+
+	now := time.Now().UTC()
+	geofence := &models.Geofence{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Name:         name,
+		Kind:         req.Kind,
+		Center:       req.Center,
+		RadiusMeters: req.RadiusMeters,
+		Polygon:      req.Polygon,
+		NotifyEmail:  req.NotifyEmail,
+		WebhookURL:   req.WebhookURL,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := geofence.Validate(); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.geofenceRepo.Create(c.Request.Context(), geofence); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create geofence"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, geofence)
+}
+
+// ListGeofences retrieves the authenticated user's geofences
+// GET /api/v1/geofences
+func (h *GeofenceHandler) ListGeofences(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	geofences, err := h.geofenceRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve geofences"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "geofences", geofences, api.Meta{
+		"total": len(geofences),
+	})
+}
+
+// DeleteGeofence removes a geofence together with its event history
+// DELETE /api/v1/geofences/:id
+func (h *GeofenceHandler) DeleteGeofence(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	geofenceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_geofence_id", "Invalid geofence ID format"))
+		return
+	}
+
+	geofence, err := h.geofenceRepo.GetByID(c.Request.Context(), geofenceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrGeofenceNotFound) {
+			problem.Abort(c, problem.NotFound("geofence_not_found", "Geofence not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve geofence"))
+		return
+	}
+
+	// Verify geofence belongs to user
+	if !geofence.IsOwnedBy(userID) {
+		problem.Abort(c, problem.Forbidden("forbidden", "You do not have access to this geofence"))
+		return
+	}
+
+	if err := h.geofenceRepo.Delete(c.Request.Context(), geofenceID); err != nil {
+		problem.Abort(c, problem.Internal("Failed to delete geofence"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Geofence deleted successfully",
+	})
+}
+
+// ListGeofenceEvents retrieves enter/exit events for the authenticated user's geofences
+// GET /api/v1/geofences/events?geofenceId=&deviceId=&limit=
+func (h *GeofenceHandler) ListGeofenceEvents(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	filter := repository.GeofenceEventFilter{
+		UserID:   userID,
+		DeviceID: c.Query("deviceId"),
+	}
+
+	if raw := c.Query("geofenceId"); raw != "" {
+		geofenceID, err := uuid.Parse(raw)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_geofence_id", "Invalid geofence ID format"))
+			return
+		}
+		filter.GeofenceID = &geofenceID
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxGeofenceEventLimit {
+			problem.Abort(c, problem.BadRequest("invalid_request", "limit must be between 1 and "+strconv.Itoa(maxGeofenceEventLimit)))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := h.geofenceRepo.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve geofence events"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "events", events, api.Meta{
+		"count": len(events),
+	})
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGeofenceTest() (*GeofenceHandler, *repository.MockGeofenceRepository) {
+	geofenceRepo := repository.NewMockGeofenceRepository()
+	handler := NewGeofenceHandler(geofenceRepo)
+
+	gin.SetMode(gin.TestMode)
+
+	return handler, geofenceRepo
+}
+
+func TestGeofenceHandler_CreateGeofence(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "circle",
+			body:           `{"name":" Paddock ","kind":"circle","center":{"latitude":42.6977,"longitude":23.3219},"radiusMeters":150,"notifyEmail":true}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "polygon with webhook",
+			body:           `{"name":"Paddock","kind":"polygon","polygon":[{"latitude":42.69,"longitude":23.32},{"latitude":42.70,"longitude":23.32},{"latitude":42.70,"longitude":23.33}],"webhookUrl":"https://example.com/hooks/avt"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "unknown kind",
+			body:           `{"name":"Paddock","kind":"square"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "circle without radius",
+			body:           `{"name":"Paddock","kind":"circle","center":{"latitude":42.6977,"longitude":23.3219}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "polygon with too few vertices",
+			body:           `{"name":"Paddock","kind":"polygon","polygon":[{"latitude":42.69,"longitude":23.32},{"latitude":42.70,"longitude":23.32}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid webhook URL",
+			body:           `{"name":"Paddock","kind":"circle","center":{"latitude":42.6977,"longitude":23.3219},"radiusMeters":150,"webhookUrl":"ftp://example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "blank name",
+			body:           `{"name":"  ","kind":"circle","center":{"latitude":42.6977,"longitude":23.3219},"radiusMeters":150}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, geofenceRepo := setupGeofenceTest()
+			userID := uuid.New()
+
+			var created *models.Geofence
+			geofenceRepo.CreateFunc = func(_ context.Context, geofence *models.Geofence) error {
+				created = geofence
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/geofences", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.CreateGeofence(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, created)
+				return
+			}
+
+			require.NotNil(t, created)
+			assert.Equal(t, userID, created.UserID)
+			assert.Equal(t, "Paddock", created.Name)
+			assert.True(t, created.IsActive)
+
+			var response models.Geofence
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, created.ID, response.ID)
+		})
+	}
+}
+
+func TestGeofenceHandler_ListGeofences(t *testing.T) {
+	handler, geofenceRepo := setupGeofenceTest()
+	userID := uuid.New()
+
+	geofenceRepo.ListByUserIDFunc = func(_ context.Context, id uuid.UUID) ([]*models.Geofence, error) {
+		assert.Equal(t, userID, id)
+		return []*models.Geofence{{ID: uuid.New(), UserID: userID, Name: "Paddock", Kind: models.GeofenceCircle}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/geofences", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListGeofences(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Geofences []models.Geofence `json:"geofences"`
+		Total     int               `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, "Paddock", response.Geofences[0].Name)
+}
+
+func TestGeofenceHandler_DeleteGeofence(t *testing.T) {
+	ownerID := uuid.New()
+	geofenceID := uuid.New()
+
+	tests := []struct {
+		name           string
+		param          string
+		userID         uuid.UUID
+		expectedStatus int
+	}{
+		{"success", geofenceID.String(), ownerID, http.StatusOK},
+		{"invalid id", "not-a-uuid", ownerID, http.StatusBadRequest},
+		{"not found", uuid.New().String(), ownerID, http.StatusNotFound},
+		{"other user's geofence", geofenceID.String(), uuid.New(), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, geofenceRepo := setupGeofenceTest()
+			geofenceRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Geofence, error) {
+				if id != geofenceID {
+					return nil, repository.ErrGeofenceNotFound
+				}
+				return &models.Geofence{ID: geofenceID, UserID: ownerID}, nil
+			}
+			deleted := false
+			geofenceRepo.DeleteFunc = func(_ context.Context, id uuid.UUID) error {
+				assert.Equal(t, geofenceID, id)
+				deleted = true
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/geofences/"+tt.param, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.param}}
+			c.Set(string(middleware.UserIDKey), tt.userID)
+
+			handler.DeleteGeofence(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, deleted)
+		})
+	}
+}
+
+func TestGeofenceHandler_ListGeofenceEvents(t *testing.T) {
+	handler, geofenceRepo := setupGeofenceTest()
+	userID := uuid.New()
+	geofenceID := uuid.New()
+
+	geofenceRepo.ListEventsFunc = func(_ context.Context, filter repository.GeofenceEventFilter) ([]*models.GeofenceEvent, error) {
+		assert.Equal(t, userID, filter.UserID)
+		require.NotNil(t, filter.GeofenceID)
+		assert.Equal(t, geofenceID, *filter.GeofenceID)
+		assert.Equal(t, "device-1", filter.DeviceID)
+		assert.Equal(t, 50, filter.Limit)
+		return []*models.GeofenceEvent{{ID: 1, GeofenceID: geofenceID, Type: models.GeofenceEnter}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/geofences/events?geofenceId="+geofenceID.String()+"&deviceId=device-1&limit=50", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListGeofenceEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Events []models.GeofenceEvent `json:"events"`
+		Count  int                    `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, models.GeofenceEnter, response.Events[0].Type)
+}
+
+func TestGeofenceHandler_ListGeofenceEvents_InvalidParams(t *testing.T) {
+	for _, query := range []string{"geofenceId=nope", "limit=0", "limit=5000", "limit=abc"} {
+		t.Run(query, func(t *testing.T) {
+			handler, _ := setupGeofenceTest()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/geofences/events?"+query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.ListGeofenceEvents(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
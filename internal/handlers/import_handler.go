@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/importer"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// maxImportFileSize caps the size of an uploaded recording
+	maxImportFileSize = 64 << 20
+
+	// maxImportRows caps the number of telemetry points in a single import
+	maxImportRows = 1000000
+
+	// importDeviceID is the session device used when the upload does not name one
+	importDeviceID = "RACEBOX-IMPORT"
+)
+
+// TelemetryImporter writes imported recordings in the background
+type TelemetryImporter interface {
+	Submit(job *models.ImportJob, records []*models.TelemetryData) error
+}
+
+// ImportHandler handles historical recording imports
+type ImportHandler struct {
+	jobRepo     repository.ImportJobRepository
+	sessionRepo repository.SessionRepository
+	deviceRepo  repository.DeviceRepository
+	importer    TelemetryImporter // Optional: nil disables imports
+	quotas      *Quotas           // Optional: nil disables usage quotas
+	orgs        *orgAccess        // Optional: nil limits imports to personally owned devices
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(jobRepo repository.ImportJobRepository, sessionRepo repository.SessionRepository, deviceRepo repository.DeviceRepository) *ImportHandler {
+	return &ImportHandler{
+		jobRepo:     jobRepo,
+		sessionRepo: sessionRepo,
+		deviceRepo:  deviceRepo,
+	}
+}
+
+// WithImporter sets the background importer that stores uploaded recordings
+func (h *ImportHandler) WithImporter(telemetryImporter TelemetryImporter) *ImportHandler {
+	h.importer = telemetryImporter
+	return h
+}
+
+// WithQuotas enables per-user plan limits on imported telemetry
+func (h *ImportHandler) WithQuotas(quotas *Quotas) *ImportHandler {
+	h.quotas = quotas
+	return h
+}
+
+// WithOrganizations lets organization members import sessions for shared devices
+// Imported sessions are shared with the device's organization.
+func (h *ImportHandler) WithOrganizations(orgRepo repository.OrganizationRepository) *ImportHandler {
+	h.orgs = newOrgAccess(orgRepo)
+	return h
+}
+
+// ImportRaceBoxCSV accepts a RaceBox app CSV export and imports it into a new session
+// The file is parsed and validated before responding; the telemetry is written in the
+// background and its progress can be followed through the returned job.
+// POST /api/v1/import/racebox-csv (multipart: file, optional deviceId and name)
+func (h *ImportHandler) ImportRaceBoxCSV(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if h.importer == nil {
+		problem.Abort(c, problem.ServiceUnavailable("import_unavailable", "Imports are not configured"))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			problem.Abort(c, problem.New(http.StatusRequestEntityTooLarge, "file_too_large", fmt.Sprintf("Import files must be at most %d MB", maxImportFileSize>>20)))
+			return
+		}
+		problem.Abort(c, problem.BadRequest("invalid_request", "A CSV file is required in the 'file' form field"))
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	deviceID := strings.TrimSpace(c.PostForm("deviceId"))
+	if deviceID == "" {
+		deviceID = importDeviceID
+	}
+	if len(deviceID) > 50 {
+		problem.Abort(c, problem.BadRequest("invalid_request", "deviceId must be at most 50 characters"))
+		return
+	}
+
+	filename := filepath.Base(header.Filename)
+	name := strings.TrimSpace(c.PostForm("name"))
+	if name == "" {
+		name = strings.TrimSuffix(filename, filepath.Ext(filename))
+	}
+	if len(name) > 255 || len(filename) > 255 {
+		problem.Abort(c, problem.BadRequest("invalid_request", "name and filename must be at most 255 characters"))
+		return
+	}
+
+	// Devices claimed by another user cannot receive imported sessions, unless shared
+	// through an organization
+	var orgID *uuid.UUID
+	if h.deviceRepo != nil {
+		device, err := h.deviceRepo.GetByDeviceID(c.Request.Context(), deviceID)
+		if err != nil && !errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.Internal("Failed to verify device"))
+			return
+		}
+		if device != nil {
+			if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessUse, "device") {
+				return
+			}
+			orgID = device.OrgID
+		}
+	}
+
+	records, err := importer.ParseRaceBoxCSV(file, maxImportRows)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_file", "Invalid RaceBox CSV: "+err.Error()))
+		return
+	}
+	if len(records) == 0 {
+		problem.Abort(c, problem.BadRequest("invalid_file", "Invalid RaceBox CSV: file contains no data rows"))
+		return
+	}
+
+	if h.quotas != nil {
+		if usage, ok := h.quotas.allowTelemetry(c.Request.Context(), userID, len(records)); !ok {
+			rejectTelemetryQuota(c, usage, h.quotas.now())
+			return
+		}
+	}
+
+	startedAt, endedAt := records[0].Timestamp, records[0].Timestamp
+	for _, record := range records {
+		if record.Timestamp.Before(startedAt) {
+			startedAt = record.Timestamp
+		}
+		if record.Timestamp.After(endedAt) {
+			endedAt = record.Timestamp
+		}
+	}
+
+	now := time.Now().UTC()
+	session := &models.Session{
+		ID:        uuid.New(),
+		DeviceID:  deviceID,
+		UserID:    &userID,
+		StartedAt: startedAt,
+		EndedAt:   &endedAt,
+		Name:      &name,
+		OrgID:     orgID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.sessionRepo.Create(c.Request.Context(), session); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create session"))
+		return
+	}
+
+	sessionID := session.ID.String()
+	for _, record := range records {
+		record.DeviceID = deviceID
+		record.SessionID = &sessionID
+		record.UserID = &userID
+	}
+
+	job := &models.ImportJob{
+		ID:        uuid.New(),
+		UserID:    userID,
+		SessionID: session.ID,
+		DeviceID:  deviceID,
+		Filename:  filename,
+		Status:    models.ImportPending,
+		TotalRows: len(records),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.jobRepo.Create(c.Request.Context(), job); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create import job"))
+		return
+	}
+
+	if err := h.importer.Submit(job, records); err != nil {
+		message := "Import queue is full"
+		if err := h.jobRepo.UpdateProgress(c.Request.Context(), job.ID, models.ImportFailed, 0, &message); err != nil {
+			slog.Error("Error failing import job", "job_id", job.ID, "error", err)
+		}
+		c.Header("Retry-After", "60")
+		problem.Abort(c, problem.ServiceUnavailable("import_busy", "Too many imports are in progress; try again later"))
+		return
+	}
+
+	if h.quotas != nil {
+		h.quotas.recordTelemetry(c.Request.Context(), userID, len(records))
+	}
+
+	c.Header("Location", "/api/v1/import/jobs/"+job.ID.String())
+	api.Respond(c, http.StatusAccepted, job)
+}
+
+// GetImportJob retrieves the status of one of the authenticated user's imports
+// GET /api/v1/import/jobs/:id
+func (h *ImportHandler) GetImportJob(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_job_id", "Invalid import job ID format"))
+		return
+	}
+
+	job, err := h.jobRepo.GetByID(c.Request.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrImportJobNotFound) {
+			problem.Abort(c, problem.NotFound("job_not_found", "Import job not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve import job"))
+		return
+	}
+
+	if !job.IsOwnedBy(userID) {
+		problem.Abort(c, problem.Forbidden("forbidden", "You do not have access to this import job"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, job)
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const importCSV = `Record,Time,Latitude,Longitude,Speed (km/h)
+1,2024-05-14T10:21:33.400Z,41.0717,23.5112,96.5
+2,2024-05-14T10:21:33.440Z,41.0716,23.5113,97.1
+`
+
+// mockImporter records submitted imports
+type mockImporter struct {
+	err     error
+	job     *models.ImportJob
+	records []*models.TelemetryData
+}
+
+func (m *mockImporter) Submit(job *models.ImportJob, records []*models.TelemetryData) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.job = job
+	m.records = records
+	return nil
+}
+
+func setupImportTest() (*ImportHandler, *repository.MockImportJobRepository, *repository.MockSessionRepository, *repository.MockDeviceRepository) {
+	jobRepo := repository.NewMockImportJobRepository()
+	sessionRepo := repository.NewMockSessionRepository()
+	deviceRepo := repository.NewMockDeviceRepository()
+	handler := NewImportHandler(jobRepo, sessionRepo, deviceRepo)
+
+	gin.SetMode(gin.TestMode)
+
+	return handler, jobRepo, sessionRepo, deviceRepo
+}
+
+// newImportRequest builds a multipart upload with the given file contents and form fields
+func newImportRequest(t *testing.T, contents string, fields map[string]string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if contents != "" {
+		part, err := writer.CreateFormFile("file", "serres-practice.csv")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	for key, value := range fields {
+		require.NoError(t, writer.WriteField(key, value))
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/import/racebox-csv", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestImportHandler_ImportRaceBoxCSV_Success(t *testing.T) {
+	handler, jobRepo, sessionRepo, _ := setupImportTest()
+	submitted := &mockImporter{}
+	handler = handler.WithImporter(submitted)
+	userID := uuid.New()
+
+	var session *models.Session
+	sessionRepo.CreateFunc = func(_ context.Context, s *models.Session) error {
+		session = s
+		return nil
+	}
+	var created *models.ImportJob
+	jobRepo.CreateFunc = func(_ context.Context, job *models.ImportJob) error {
+		created = job
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newImportRequest(t, importCSV, map[string]string{"deviceId": "RACEBOX-001"})
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ImportRaceBoxCSV(c)
+
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	require.NotNil(t, session)
+	assert.Equal(t, "RACEBOX-001", session.DeviceID)
+	assert.Equal(t, userID, *session.UserID)
+	assert.Equal(t, "serres-practice", *session.Name)
+	assert.Equal(t, time.Date(2024, 5, 14, 10, 21, 33, 400000000, time.UTC), session.StartedAt)
+	require.NotNil(t, session.EndedAt)
+	assert.Equal(t, time.Date(2024, 5, 14, 10, 21, 33, 440000000, time.UTC), *session.EndedAt)
+
+	require.NotNil(t, created)
+	assert.Equal(t, models.ImportPending, created.Status)
+	assert.Equal(t, 2, created.TotalRows)
+	assert.Equal(t, session.ID, created.SessionID)
+	assert.Equal(t, "serres-practice.csv", created.Filename)
+	assert.Equal(t, "/api/v1/import/jobs/"+created.ID.String(), w.Header().Get("Location"))
+
+	require.Len(t, submitted.records, 2)
+	for _, record := range submitted.records {
+		assert.Equal(t, "RACEBOX-001", record.DeviceID)
+		assert.Equal(t, session.ID.String(), *record.SessionID)
+		assert.Equal(t, userID, *record.UserID)
+	}
+
+	var response models.ImportJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, created.ID, response.ID)
+}
+
+func TestImportHandler_ImportRaceBoxCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		contents       string
+		fields         map[string]string
+		importerErr    error
+		unconfigured   bool
+		deviceOwned    bool // Registered to another user
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "importer not configured", contents: importCSV, unconfigured: true, expectedStatus: http.StatusServiceUnavailable, expectedError: "import_unavailable"},
+		{name: "missing file", expectedStatus: http.StatusBadRequest, expectedError: "invalid_request"},
+		{name: "invalid csv", contents: "just,some,text\n", expectedStatus: http.StatusBadRequest, expectedError: "invalid_file"},
+		{name: "no data rows", contents: "Time,Latitude,Longitude\n", expectedStatus: http.StatusBadRequest, expectedError: "invalid_file"},
+		{name: "device owned by someone else", contents: importCSV, fields: map[string]string{"deviceId": "RACEBOX-001"}, deviceOwned: true, expectedStatus: http.StatusForbidden, expectedError: "forbidden"},
+		{name: "queue full", contents: importCSV, importerErr: errors.New("import queue is full"), expectedStatus: http.StatusServiceUnavailable, expectedError: "import_busy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, jobRepo, _, deviceRepo := setupImportTest()
+			if !tt.unconfigured {
+				handler = handler.WithImporter(&mockImporter{err: tt.importerErr})
+			}
+			if tt.deviceOwned {
+				deviceRepo.GetByDeviceIDFunc = func(_ context.Context, deviceID string) (*models.Device, error) {
+					return &models.Device{DeviceID: deviceID, UserID: uuid.New()}, nil
+				}
+			}
+			var failed *string
+			jobRepo.UpdateProgressFunc = func(_ context.Context, _ uuid.UUID, status models.ImportStatus, _ int, errMsg *string) error {
+				assert.Equal(t, models.ImportFailed, status)
+				failed = errMsg
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = newImportRequest(t, tt.contents, tt.fields)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.ImportRaceBoxCSV(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedError, response["code"])
+
+			if tt.importerErr != nil {
+				require.NotNil(t, failed, "job should be failed when the queue is full")
+				assert.Equal(t, "60", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestImportHandler_GetImportJob(t *testing.T) {
+	userID := uuid.New()
+	jobID := uuid.New()
+
+	tests := []struct {
+		name           string
+		id             string
+		owner          uuid.UUID
+		repoErr        error
+		expectedStatus int
+	}{
+		{name: "success", id: jobID.String(), owner: userID, expectedStatus: http.StatusOK},
+		{name: "invalid id", id: "not-a-uuid", owner: userID, expectedStatus: http.StatusBadRequest},
+		{name: "not found", id: jobID.String(), repoErr: repository.ErrImportJobNotFound, expectedStatus: http.StatusNotFound},
+		{name: "other user's job", id: jobID.String(), owner: uuid.New(), expectedStatus: http.StatusForbidden},
+		{name: "repository error", id: jobID.String(), repoErr: errors.New("database unavailable"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, jobRepo, _, _ := setupImportTest()
+			jobRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.ImportJob, error) {
+				if tt.repoErr != nil {
+					return nil, tt.repoErr
+				}
+				return &models.ImportJob{ID: id, UserID: tt.owner, Status: models.ImportProcessing, TotalRows: 3000, ImportedRows: 1000}, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/import/jobs/"+tt.id, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.GetImportJob(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.ImportJob
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, models.ImportProcessing, response.Status)
+				assert.Equal(t, 1000, response.ImportedRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Page sizes for notification inbox queries
+const (
+	defaultNotificationListLimit = 50
+	maxNotificationListLimit     = 200
+)
+
+// NotificationHandler handles the notification inbox and notification preferences
+type NotificationHandler struct {
+	notificationRepo repository.NotificationRepository
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationRepo repository.NotificationRepository) *NotificationHandler {
+	return &NotificationHandler{
+		notificationRepo: notificationRepo,
+	}
+}
+
+// NotificationPreferencesRequest represents the request body updating notification preferences
+// Omitted fields and types keep their current settings.
+type NotificationPreferencesRequest struct {
+	Enabled     *bool                           `json:"enabled,omitempty"`
+	Preferences []models.NotificationPreference `json:"preferences,omitempty"`
+}
+
+// NotificationPreferencesResponse represents a user's notification settings
+type NotificationPreferencesResponse struct {
+	Enabled     bool                            `json:"enabled"`     // The profile's notifications_enabled flag; false stops all delivery but the inbox
+	Preferences []models.NotificationPreference `json:"preferences"` // One per notification type
+}
+
+// ListNotifications lists the authenticated user's notifications, most recent first
+// GET /api/v1/notifications?unread=&limit=&offset=
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	limit, err := parseIntQuery(c, "limit", defaultNotificationListLimit)
+	if err != nil || limit <= 0 || limit > maxNotificationListLimit {
+		problem.Abort(c, problem.BadRequest("invalid_request", "limit must be between 1 and "+strconv.Itoa(maxNotificationListLimit)))
+		return
+	}
+
+	offset, err := parseIntQuery(c, "offset", 0)
+	if err != nil || offset < 0 {
+		problem.Abort(c, problem.BadRequest("invalid_request", "offset must be a non-negative integer"))
+		return
+	}
+
+	filter := repository.NotificationFilter{Limit: limit, Offset: offset}
+	if unread := c.Query("unread"); unread != "" {
+		filter.UnreadOnly, err = strconv.ParseBool(unread)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_request", "unread must be true or false"))
+			return
+		}
+	}
+
+	notifications, err := h.notificationRepo.List(c.Request.Context(), userID, filter)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve notifications"))
+		return
+	}
+
+	unreadCount, err := h.notificationRepo.CountUnread(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to count unread notifications"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "notifications", notifications, api.Meta{
+		"count":       len(notifications),
+		"unreadCount": unreadCount,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// MarkNotificationRead marks one of the authenticated user's notifications as read
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_notification_id", "Invalid notification ID format"))
+		return
+	}
+
+	if err := h.notificationRepo.MarkRead(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			problem.Abort(c, problem.NotFound("notification_not_found", "Notification not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to mark notification as read"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Notification marked as read",
+	})
+}
+
+// MarkAllNotificationsRead marks all of the authenticated user's notifications as read
+// POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	marked, err := h.notificationRepo.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to mark notifications as read"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"marked": marked,
+	})
+}
+
+// GetPreferences returns the authenticated user's notification settings
+// GET /api/v1/notifications/preferences
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	response, ok := h.preferences(c, userID)
+	if !ok {
+		return
+	}
+
+	api.Respond(c, http.StatusOK, response)
+}
+
+// UpdatePreferences updates the authenticated user's notification settings
+// PUT /api/v1/notifications/preferences
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	for _, preference := range req.Preferences {
+		if !preference.Type.IsValid() {
+			problem.Abort(c, problem.BadRequest("invalid_notification_type", "Unknown notification type: "+string(preference.Type)))
+			return
+		}
+	}
+
+	if req.Enabled != nil {
+		if err := h.notificationRepo.SetNotificationsEnabled(c.Request.Context(), userID, *req.Enabled); err != nil {
+			problem.Abort(c, problem.Internal("Failed to update notification settings"))
+			return
+		}
+	}
+
+	if len(req.Preferences) > 0 {
+		if err := h.notificationRepo.SetPreferences(c.Request.Context(), userID, req.Preferences); err != nil {
+			problem.Abort(c, problem.Internal("Failed to update notification preferences"))
+			return
+		}
+	}
+
+	response, ok := h.preferences(c, userID)
+	if !ok {
+		return
+	}
+
+	api.Respond(c, http.StatusOK, response)
+}
+
+// preferences loads the user's notification settings, responding with an error when that fails
+func (h *NotificationHandler) preferences(c *gin.Context, userID uuid.UUID) (*NotificationPreferencesResponse, bool) {
+	enabled, err := h.notificationRepo.NotificationsEnabled(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve notification settings"))
+		return nil, false
+	}
+
+	stored, err := h.notificationRepo.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve notification preferences"))
+		return nil, false
+	}
+
+	return &NotificationPreferencesResponse{
+		Enabled:     enabled,
+		Preferences: models.WithDefaultPreferences(stored),
+	}, true
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier captures the notifications sent through it
+type recordingNotifier struct {
+	notifications []*models.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification *models.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func setupNotificationTest() (*NotificationHandler, *repository.MockNotificationRepository) {
+	notificationRepo := repository.NewMockNotificationRepository()
+	handler := NewNotificationHandler(notificationRepo)
+
+	gin.SetMode(gin.TestMode)
+
+	return handler, notificationRepo
+}
+
+func TestNotificationHandler_ListNotifications(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedFilter repository.NotificationFilter
+	}{
+		{
+			name:           "defaults",
+			expectedStatus: http.StatusOK,
+			expectedFilter: repository.NotificationFilter{Limit: defaultNotificationListLimit},
+		},
+		{
+			name:           "unread page",
+			query:          "?unread=true&limit=10&offset=20",
+			expectedStatus: http.StatusOK,
+			expectedFilter: repository.NotificationFilter{UnreadOnly: true, Limit: 10, Offset: 20},
+		},
+		{
+			name:           "invalid unread",
+			query:          "?unread=maybe",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "limit too large",
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, notificationRepo := setupNotificationTest()
+			userID := uuid.New()
+			readAt := time.Now()
+
+			var filter *repository.NotificationFilter
+			notificationRepo.ListFunc = func(_ context.Context, id uuid.UUID, f repository.NotificationFilter) ([]*models.Notification, error) {
+				assert.Equal(t, userID, id)
+				filter = &f
+				return []*models.Notification{
+					{ID: uuid.New(), UserID: userID, Type: models.NotificationLowBattery, Title: "kart-7 battery is low"},
+					{ID: uuid.New(), UserID: userID, Type: models.NotificationNewLogin, Title: "New sign-in", ReadAt: &readAt},
+				}, nil
+			}
+			notificationRepo.CountUnreadFunc = func(_ context.Context, _ uuid.UUID) (int, error) {
+				return 1, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/notifications"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.ListNotifications(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Nil(t, filter)
+				return
+			}
+
+			require.NotNil(t, filter)
+			assert.Equal(t, tt.expectedFilter, *filter)
+
+			var response struct {
+				Notifications []models.Notification `json:"notifications"`
+				UnreadCount   int                   `json:"unreadCount"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Len(t, response.Notifications, 2)
+			assert.Equal(t, 1, response.UnreadCount)
+			assert.NotContains(t, w.Body.String(), userID.String())
+		})
+	}
+}
+
+func TestNotificationHandler_MarkNotificationRead(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		found          bool
+		expectedStatus int
+	}{
+		{name: "marked", id: uuid.NewString(), found: true, expectedStatus: http.StatusOK},
+		{name: "not found", id: uuid.NewString(), expectedStatus: http.StatusNotFound},
+		{name: "invalid id", id: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, notificationRepo := setupNotificationTest()
+			userID := uuid.New()
+
+			notificationRepo.MarkReadFunc = func(_ context.Context, owner, id uuid.UUID) error {
+				assert.Equal(t, userID, owner)
+				assert.Equal(t, tt.id, id.String())
+				if !tt.found {
+					return repository.ErrNotificationNotFound
+				}
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/notifications/"+tt.id+"/read", nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.MarkNotificationRead(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestNotificationHandler_MarkAllNotificationsRead(t *testing.T) {
+	handler, notificationRepo := setupNotificationTest()
+	userID := uuid.New()
+
+	notificationRepo.MarkAllReadFunc = func(_ context.Context, id uuid.UUID) (int64, error) {
+		assert.Equal(t, userID, id)
+		return 3, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/notifications/read-all", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.MarkAllNotificationsRead(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"marked":3}`, w.Body.String())
+}
+
+func TestNotificationHandler_UpdatePreferences(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectEnabled  *bool
+		expectStored   int
+	}{
+		{
+			name:           "disable notifications",
+			body:           `{"enabled":false}`,
+			expectedStatus: http.StatusOK,
+			expectEnabled:  new(bool),
+		},
+		{
+			name:           "set a preference",
+			body:           `{"preferences":[{"type":"session_summary","email":true,"push":false}]}`,
+			expectedStatus: http.StatusOK,
+			expectStored:   1,
+		},
+		{
+			name:           "unknown type",
+			body:           `{"enabled":true,"preferences":[{"type":"geofence","email":true}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, notificationRepo := setupNotificationTest()
+			userID := uuid.New()
+
+			enabled := true
+			var enabledSet *bool
+			stored := make([]models.NotificationPreference, 0)
+			notificationRepo.SetNotificationsEnabledFunc = func(_ context.Context, _ uuid.UUID, value bool) error {
+				enabled = value
+				enabledSet = &value
+				return nil
+			}
+			notificationRepo.NotificationsEnabledFunc = func(_ context.Context, _ uuid.UUID) (bool, error) {
+				return enabled, nil
+			}
+			notificationRepo.SetPreferencesFunc = func(_ context.Context, id uuid.UUID, preferences []models.NotificationPreference) error {
+				assert.Equal(t, userID, id)
+				stored = append(stored, preferences...)
+				return nil
+			}
+			notificationRepo.GetPreferencesFunc = func(_ context.Context, _ uuid.UUID) ([]models.NotificationPreference, error) {
+				return stored, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/notifications/preferences", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.UpdatePreferences(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectEnabled, enabledSet)
+			assert.Len(t, stored, tt.expectStored)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response NotificationPreferencesResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, enabled, response.Enabled)
+			assert.Equal(t, models.WithDefaultPreferences(stored), response.Preferences)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// accessLevel is the kind of access requested to a device or session
+type accessLevel int
+
+const (
+	// accessUse lets organization members view shared resources and record with shared devices
+	accessUse accessLevel = iota
+	// accessManage lets organization owners and admins change shared resources
+	accessManage
+)
+
+// orgAccess decides whether users may access resources shared with their organizations
+// The personal owner always has full access. A nil *orgAccess grants nothing beyond that,
+// so handlers keep single-owner behavior when organizations are not configured.
+type orgAccess struct {
+	orgRepo repository.OrganizationRepository
+}
+
+// newOrgAccess creates an access checker backed by the organization repository
+func newOrgAccess(orgRepo repository.OrganizationRepository) *orgAccess {
+	if orgRepo == nil {
+		return nil
+	}
+	return &orgAccess{orgRepo: orgRepo}
+}
+
+// allows checks if userID may access a resource owned by ownerID and shared with orgID
+// Either may be nil: sessions can lose their owner and most resources are not shared.
+func (a *orgAccess) allows(ctx context.Context, userID uuid.UUID, ownerID, orgID *uuid.UUID, level accessLevel) (bool, error) {
+	if ownerID != nil && *ownerID == userID {
+		return true, nil
+	}
+	if a == nil || orgID == nil {
+		return false, nil
+	}
+
+	member, err := a.orgRepo.GetMember(ctx, *orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrgMemberNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return level == accessUse || member.Role.CanManage(), nil
+}
+
+// authorize checks access like allows and writes the error response when access is denied
+// It returns false when the request must stop.
+func (a *orgAccess) authorize(c *gin.Context, userID uuid.UUID, ownerID, orgID *uuid.UUID, level accessLevel, resource string) bool {
+	allowed, err := a.allows(c.Request.Context(), userID, ownerID, orgID, level)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to verify "+resource+" access"))
+		return false
+	}
+	if !allowed {
+		problem.Abort(c, problem.Forbidden("forbidden", "You do not have access to this "+resource))
+		return false
+	}
+	return true
+}
+
+// orgIDs returns the organizations a user belongs to, or nil when organizations are not configured
+func (a *orgAccess) orgIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	memberships, err := a.orgRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(memberships))
+	for i, membership := range memberships {
+		ids[i] = membership.ID
+	}
+	return ids, nil
+}
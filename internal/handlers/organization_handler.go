@@ -0,0 +1,456 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// invitationTTL is how long an emailed organization invitation can be accepted
+const invitationTTL = 7 * 24 * time.Hour
+
+// OrganizationHandler handles organization, membership and invitation requests
+type OrganizationHandler struct {
+	orgRepo      repository.OrganizationRepository
+	deviceRepo   repository.DeviceRepository
+	access       *orgAccess
+	emailService email.Service // Optional: nil disables invitations
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(orgRepo repository.OrganizationRepository, deviceRepo repository.DeviceRepository) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgRepo:    orgRepo,
+		deviceRepo: deviceRepo,
+		access:     newOrgAccess(orgRepo),
+	}
+}
+
+// WithEmailService sets the email service used to send invitations
+func (h *OrganizationHandler) WithEmailService(emailService email.Service) *OrganizationHandler {
+	h.emailService = emailService
+	return h
+}
+
+// CreateOrganizationRequest represents the organization creation request body
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+// UpdateMemberRoleRequest represents the member role change request body
+type UpdateMemberRoleRequest struct {
+	Role models.OrgRole `json:"role" binding:"required"`
+}
+
+// CreateInvitationRequest represents the invitation request body
+type CreateInvitationRequest struct {
+	Email string         `json:"email" binding:"required,email,max=255"`
+	Role  models.OrgRole `json:"role,omitempty"` // Defaults to member
+}
+
+// AcceptInvitationRequest represents the invitation acceptance request body
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// SetDeviceOrganizationRequest represents the device sharing request body
+// A null orgId stops sharing the device.
+type SetDeviceOrganizationRequest struct {
+	OrgID *uuid.UUID `json:"orgId"`
+}
+
+// CreateOrganization creates an organization owned by the authenticated user
+// POST /api/v1/orgs
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "name is required"))
+		return
+	}
+
+	now := time.Now().UTC()
+	org := &models.Organization{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedBy: &userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.orgRepo.Create(c.Request.Context(), org, userID); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create organization"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, models.OrganizationMembership{Organization: *org, Role: models.OrgRoleOwner})
+}
+
+// ListOrganizations retrieves the organizations the authenticated user belongs to
+// GET /api/v1/orgs
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	memberships, err := h.orgRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve organizations"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "organizations", memberships, api.Meta{
+		"total": len(memberships),
+	})
+}
+
+// GetOrganization retrieves an organization with the authenticated user's role
+// GET /api/v1/orgs/:id
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	orgID, member, ok := h.getMembership(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.orgRepo.GetByID(c.Request.Context(), orgID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrganizationNotFound) {
+			problem.Abort(c, problem.NotFound("organization_not_found", "Organization not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve organization"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, models.OrganizationMembership{Organization: *org, Role: member.Role})
+}
+
+// ListMembers retrieves an organization's members
+// GET /api/v1/orgs/:id/members
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, _, ok := h.getMembership(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.orgRepo.ListMembers(c.Request.Context(), orgID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve organization members"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "members", members, api.Meta{
+		"total": len(members),
+	})
+}
+
+// UpdateMemberRole changes a member's role
+// Owners and admins can change roles; only owners can grant or revoke the owner role.
+// PUT /api/v1/orgs/:id/members/:userId
+func (h *OrganizationHandler) UpdateMemberRole(c *gin.Context) {
+	orgID, caller, ok := h.getMembership(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+	if !req.Role.IsValid() {
+		problem.Abort(c, problem.BadRequest("invalid_request", "role must be one of: owner, admin, member"))
+		return
+	}
+
+	target, ok := h.getTargetMember(c, orgID)
+	if !ok {
+		return
+	}
+
+	ownerChange := target.Role == models.OrgRoleOwner || req.Role == models.OrgRoleOwner
+	if !caller.Role.CanManage() || (ownerChange && caller.Role != models.OrgRoleOwner) {
+		problem.Abort(c, problem.Forbidden("forbidden", "You cannot change this member's role"))
+		return
+	}
+
+	if err := h.orgRepo.UpdateMemberRole(c.Request.Context(), orgID, target.UserID, req.Role); err != nil {
+		h.respondMemberChangeError(c, err, "Failed to update member role")
+		return
+	}
+
+	target.Role = req.Role
+	target.UpdatedAt = time.Now().UTC()
+	api.Respond(c, http.StatusOK, target)
+}
+
+// RemoveMember removes a member from an organization
+// Members can always leave; owners and admins can remove others, and only owners can remove owners.
+// DELETE /api/v1/orgs/:id/members/:userId
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, caller, ok := h.getMembership(c)
+	if !ok {
+		return
+	}
+
+	target, ok := h.getTargetMember(c, orgID)
+	if !ok {
+		return
+	}
+
+	leaving := target.UserID == caller.UserID
+	if !leaving && (!caller.Role.CanManage() || (target.Role == models.OrgRoleOwner && caller.Role != models.OrgRoleOwner)) {
+		problem.Abort(c, problem.Forbidden("forbidden", "You cannot remove this member"))
+		return
+	}
+
+	if err := h.orgRepo.RemoveMember(c.Request.Context(), orgID, target.UserID); err != nil {
+		h.respondMemberChangeError(c, err, "Failed to remove member")
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Member removed successfully",
+	})
+}
+
+// CreateInvitation emails an invitation to join the organization
+// The invitee accepts it by signing in with the invited address.
+// POST /api/v1/orgs/:id/invitations
+func (h *OrganizationHandler) CreateInvitation(c *gin.Context) {
+	orgID, caller, ok := h.getMembership(c)
+	if !ok {
+		return
+	}
+
+	if h.emailService == nil {
+		problem.Abort(c, problem.ServiceUnavailable("invitations_unavailable", "Invitations require an email service"))
+		return
+	}
+
+	if !caller.Role.CanManage() {
+		problem.Abort(c, problem.Forbidden("forbidden", "Only organization owners and admins can invite members"))
+		return
+	}
+
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.OrgRoleMember
+	}
+	if req.Role != models.OrgRoleAdmin && req.Role != models.OrgRoleMember {
+		problem.Abort(c, problem.BadRequest("invalid_request", "role must be admin or member"))
+		return
+	}
+
+	org, err := h.orgRepo.GetByID(c.Request.Context(), orgID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve organization"))
+		return
+	}
+
+	token, err := auth.GenerateSecureToken()
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to create invitation"))
+		return
+	}
+
+	now := time.Now().UTC()
+	invitation := &models.OrganizationInvitation{
+		ID:        uuid.New(),
+		OrgID:     orgID,
+		Email:     strings.ToLower(strings.TrimSpace(req.Email)),
+		Role:      req.Role,
+		TokenHash: auth.HashToken(token),
+		InvitedBy: &caller.UserID,
+		ExpiresAt: now.Add(invitationTTL),
+		CreatedAt: now,
+	}
+	if err := h.orgRepo.CreateInvitation(c.Request.Context(), invitation); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create invitation"))
+		return
+	}
+
+	if err := h.emailService.SendOrganizationInvitationEmail(c.Request.Context(), invitation.Email, token, email.OrganizationInvitation{
+		OrganizationName: org.Name,
+		InviterEmail:     middleware.MustGetUserEmail(c),
+		Role:             string(invitation.Role),
+		ExpiresAt:        invitation.ExpiresAt,
+	}); err != nil {
+		slog.Error("Error sending organization invitation email", "error", err)
+		problem.Abort(c, problem.New(http.StatusBadGateway, "email_failed", "Failed to send invitation email"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, invitation)
+}
+
+// AcceptInvitation adds the authenticated user to the organization they were invited to
+// POST /api/v1/invitations/accept
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	invitation, err := h.orgRepo.GetInvitationByTokenHash(c.Request.Context(), auth.HashToken(req.Token))
+	if err != nil && !errors.Is(err, repository.ErrInvitationNotFound) {
+		problem.Abort(c, problem.Internal("Failed to retrieve invitation"))
+		return
+	}
+	if invitation == nil || !invitation.IsPending() {
+		problem.Abort(c, problem.NotFound("invitation_not_found", "Invitation not found or expired"))
+		return
+	}
+
+	// The token alone is not enough: it must be used by the invited account
+	if !strings.EqualFold(invitation.Email, middleware.MustGetUserEmail(c)) {
+		problem.Abort(c, problem.Forbidden("invitation_email_mismatch", "This invitation was sent to a different email address"))
+		return
+	}
+
+	member, err := h.orgRepo.AcceptInvitation(c.Request.Context(), invitation.ID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvitationNotFound) {
+			problem.Abort(c, problem.NotFound("invitation_not_found", "Invitation not found or expired"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to accept invitation"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, member)
+}
+
+// SetDeviceOrganization shares a device with an organization or stops sharing it
+// The caller must be able to manage the device and, when sharing, belong to the organization.
+// PUT /api/v1/devices/:id/organization
+func (h *OrganizationHandler) SetDeviceOrganization(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return
+	}
+
+	var req SetDeviceOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return
+	}
+
+	if !h.access.authorize(c, userID, &device.UserID, device.OrgID, accessManage, "device") {
+		return
+	}
+
+	if req.OrgID != nil {
+		if _, err := h.orgRepo.GetMember(c.Request.Context(), *req.OrgID, userID); err != nil {
+			if errors.Is(err, repository.ErrOrgMemberNotFound) {
+				problem.Abort(c, problem.Forbidden("forbidden", "You are not a member of this organization"))
+				return
+			}
+			problem.Abort(c, problem.Internal("Failed to verify organization membership"))
+			return
+		}
+	}
+
+	if err := h.deviceRepo.SetOrganization(c.Request.Context(), device.ID, req.OrgID); err != nil {
+		problem.Abort(c, problem.Internal("Failed to update device organization"))
+		return
+	}
+
+	device.OrgID = req.OrgID
+	api.Respond(c, http.StatusOK, device.ToResponse())
+}
+
+// getMembership parses the :id organization path parameter and loads the
+// authenticated user's membership. It writes the error response and returns
+// false when the user does not belong to the organization.
+func (h *OrganizationHandler) getMembership(c *gin.Context) (uuid.UUID, *models.OrganizationMember, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_organization_id", "Invalid organization ID format"))
+		return uuid.Nil, nil, false
+	}
+
+	member, err := h.orgRepo.GetMember(c.Request.Context(), orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrgMemberNotFound) {
+			// Non-members cannot tell organizations apart from missing ones
+			problem.Abort(c, problem.NotFound("organization_not_found", "Organization not found"))
+			return uuid.Nil, nil, false
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve organization membership"))
+		return uuid.Nil, nil, false
+	}
+
+	return orgID, member, true
+}
+
+// getTargetMember loads the member referenced by the :userId path parameter
+func (h *OrganizationHandler) getTargetMember(c *gin.Context, orgID uuid.UUID) (*models.OrganizationMember, bool) {
+	targetID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_user_id", "Invalid user ID format"))
+		return nil, false
+	}
+
+	member, err := h.orgRepo.GetMember(c.Request.Context(), orgID, targetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrgMemberNotFound) {
+			problem.Abort(c, problem.NotFound("member_not_found", "Member not found"))
+			return nil, false
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve member"))
+		return nil, false
+	}
+
+	return member, true
+}
+
+// respondMemberChangeError writes the response for a failed role change or removal
+func (h *OrganizationHandler) respondMemberChangeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrLastOrgOwner):
+		problem.Abort(c, problem.Conflict("last_owner", "An organization must keep at least one owner"))
+	case errors.Is(err, repository.ErrOrgMemberNotFound):
+		problem.Abort(c, problem.NotFound("member_not_found", "Member not found"))
+	default:
+		problem.Abort(c, problem.Internal(message))
+	}
+}
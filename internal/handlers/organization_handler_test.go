@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orgMembers returns a GetMember stub backed by a fixed set of roles
+func orgMembers(orgID uuid.UUID, roles map[uuid.UUID]models.OrgRole) func(context.Context, uuid.UUID, uuid.UUID) (*models.OrganizationMember, error) {
+	return func(_ context.Context, id, userID uuid.UUID) (*models.OrganizationMember, error) {
+		role, ok := roles[userID]
+		if id != orgID || !ok {
+			return nil, repository.ErrOrgMemberNotFound
+		}
+		return &models.OrganizationMember{OrgID: id, UserID: userID, Role: role}, nil
+	}
+}
+
+// serveOrgRequest sends a request through a router with the given user authenticated
+func serveOrgRequest(t *testing.T, handler *OrganizationHandler, userID uuid.UUID, userEmail, method, route, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(middleware.UserIDKey), userID)
+		c.Set(string(middleware.UserEmailKey), userEmail)
+		c.Next()
+	})
+	router.Handle(method, route, func(c *gin.Context) {
+		switch route {
+		case "/api/v1/orgs":
+			handler.CreateOrganization(c)
+		case "/api/v1/orgs/:id/members/:userId":
+			if method == http.MethodPut {
+				handler.UpdateMemberRole(c)
+			} else {
+				handler.RemoveMember(c)
+			}
+		case "/api/v1/orgs/:id/invitations":
+			handler.CreateInvitation(c)
+		case "/api/v1/invitations/accept":
+			handler.AcceptInvitation(c)
+		case "/api/v1/devices/:id/organization":
+			handler.SetDeviceOrganization(c)
+		default:
+			t.Fatalf("unexpected route %s", route)
+		}
+	})
+
+	var reader *bytes.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestOrganizationHandler_CreateOrganization(t *testing.T) {
+	orgRepo := repository.NewMockOrganizationRepository()
+	var ownerID uuid.UUID
+	orgRepo.CreateFunc = func(_ context.Context, _ *models.Organization, owner uuid.UUID) error {
+		ownerID = owner
+		return nil
+	}
+	handler := NewOrganizationHandler(orgRepo, repository.NewMockDeviceRepository())
+	userID := uuid.New()
+
+	w := serveOrgRequest(t, handler, userID, "owner@example.com", http.MethodPost, "/api/v1/orgs", "/api/v1/orgs",
+		map[string]string{"name": "  Team Racing  "})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, userID, ownerID)
+
+	var response models.OrganizationMembership
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Team Racing", response.Name)
+	assert.Equal(t, models.OrgRoleOwner, response.Role)
+}
+
+func TestOrganizationHandler_UpdateMemberRole(t *testing.T) {
+	orgID := uuid.New()
+	owner, admin, member := uuid.New(), uuid.New(), uuid.New()
+	roles := map[uuid.UUID]models.OrgRole{owner: models.OrgRoleOwner, admin: models.OrgRoleAdmin, member: models.OrgRoleMember}
+
+	tests := []struct {
+		name       string
+		caller     uuid.UUID
+		target     uuid.UUID
+		role       models.OrgRole
+		repoErr    error
+		wantStatus int
+	}{
+		{name: "admin promotes member to admin", caller: admin, target: member, role: models.OrgRoleAdmin, wantStatus: http.StatusOK},
+		{name: "admin cannot grant owner", caller: admin, target: member, role: models.OrgRoleOwner, wantStatus: http.StatusForbidden},
+		{name: "admin cannot demote owner", caller: admin, target: owner, role: models.OrgRoleMember, wantStatus: http.StatusForbidden},
+		{name: "member cannot change roles", caller: member, target: admin, role: models.OrgRoleMember, wantStatus: http.StatusForbidden},
+		{name: "owner grants owner", caller: owner, target: admin, role: models.OrgRoleOwner, wantStatus: http.StatusOK},
+		{name: "last owner is kept", caller: owner, target: owner, role: models.OrgRoleAdmin, repoErr: repository.ErrLastOrgOwner, wantStatus: http.StatusConflict},
+		{name: "invalid role", caller: owner, target: member, role: "superuser", wantStatus: http.StatusBadRequest},
+		{name: "non-member target", caller: owner, target: uuid.New(), role: models.OrgRoleAdmin, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgRepo := repository.NewMockOrganizationRepository()
+			orgRepo.GetMemberFunc = orgMembers(orgID, roles)
+			updated := false
+			orgRepo.UpdateMemberRoleFunc = func(_ context.Context, _, _ uuid.UUID, _ models.OrgRole) error {
+				updated = tt.repoErr == nil
+				return tt.repoErr
+			}
+			handler := NewOrganizationHandler(orgRepo, repository.NewMockDeviceRepository())
+
+			path := "/api/v1/orgs/" + orgID.String() + "/members/" + tt.target.String()
+			w := serveOrgRequest(t, handler, tt.caller, "caller@example.com", http.MethodPut, "/api/v1/orgs/:id/members/:userId", path,
+				map[string]string{"role": string(tt.role)})
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Equal(t, tt.wantStatus == http.StatusOK, updated)
+		})
+	}
+}
+
+func TestOrganizationHandler_RemoveMember(t *testing.T) {
+	orgID := uuid.New()
+	owner, admin, member := uuid.New(), uuid.New(), uuid.New()
+	roles := map[uuid.UUID]models.OrgRole{owner: models.OrgRoleOwner, admin: models.OrgRoleAdmin, member: models.OrgRoleMember}
+
+	tests := []struct {
+		name       string
+		caller     uuid.UUID
+		target     uuid.UUID
+		wantStatus int
+	}{
+		{name: "member leaves", caller: member, target: member, wantStatus: http.StatusOK},
+		{name: "member cannot remove others", caller: member, target: admin, wantStatus: http.StatusForbidden},
+		{name: "admin removes member", caller: admin, target: member, wantStatus: http.StatusOK},
+		{name: "admin cannot remove owner", caller: admin, target: owner, wantStatus: http.StatusForbidden},
+		{name: "non-member caller", caller: uuid.New(), target: member, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgRepo := repository.NewMockOrganizationRepository()
+			orgRepo.GetMemberFunc = orgMembers(orgID, roles)
+			handler := NewOrganizationHandler(orgRepo, repository.NewMockDeviceRepository())
+
+			path := "/api/v1/orgs/" + orgID.String() + "/members/" + tt.target.String()
+			w := serveOrgRequest(t, handler, tt.caller, "caller@example.com", http.MethodDelete, "/api/v1/orgs/:id/members/:userId", path, nil)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestOrganizationHandler_CreateInvitation(t *testing.T) {
+	orgID := uuid.New()
+	admin, member := uuid.New(), uuid.New()
+	roles := map[uuid.UUID]models.OrgRole{admin: models.OrgRoleAdmin, member: models.OrgRoleMember}
+
+	setup := func() (*OrganizationHandler, *repository.MockOrganizationRepository, *email.MockService) {
+		orgRepo := repository.NewMockOrganizationRepository()
+		orgRepo.GetMemberFunc = orgMembers(orgID, roles)
+		orgRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Organization, error) {
+			return &models.Organization{ID: id, Name: "Team Racing"}, nil
+		}
+		emailService := email.NewMockService()
+		return NewOrganizationHandler(orgRepo, repository.NewMockDeviceRepository()).WithEmailService(emailService), orgRepo, emailService
+	}
+	path := "/api/v1/orgs/" + orgID.String() + "/invitations"
+
+	t.Run("admin invites by email", func(t *testing.T) {
+		handler, orgRepo, emailService := setup()
+		var stored *models.OrganizationInvitation
+		orgRepo.CreateInvitationFunc = func(_ context.Context, invitation *models.OrganizationInvitation) error {
+			stored = invitation
+			return nil
+		}
+
+		w := serveOrgRequest(t, handler, admin, "admin@example.com", http.MethodPost, "/api/v1/orgs/:id/invitations", path,
+			map[string]string{"email": "New@Example.com"})
+
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.NotNil(t, stored)
+		assert.Equal(t, "new@example.com", stored.Email)
+		assert.Equal(t, models.OrgRoleMember, stored.Role)
+		assert.WithinDuration(t, time.Now().Add(invitationTTL), stored.ExpiresAt, time.Minute)
+		assert.NotContains(t, w.Body.String(), stored.TokenHash)
+
+		emails := emailService.GetInvitationEmails()
+		require.Len(t, emails, 1)
+		assert.Equal(t, "new@example.com", emails[0].To)
+		assert.Equal(t, stored.TokenHash, auth.HashToken(emails[0].Token))
+		assert.Equal(t, "Team Racing", emails[0].Invitation.OrganizationName)
+		assert.Equal(t, "admin@example.com", emails[0].Invitation.InviterEmail)
+	})
+
+	t.Run("members cannot invite", func(t *testing.T) {
+		handler, _, emailService := setup()
+		w := serveOrgRequest(t, handler, member, "member@example.com", http.MethodPost, "/api/v1/orgs/:id/invitations", path,
+			map[string]string{"email": "new@example.com"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, emailService.GetInvitationEmails())
+	})
+
+	t.Run("owner role cannot be invited", func(t *testing.T) {
+		handler, _, _ := setup()
+		w := serveOrgRequest(t, handler, admin, "admin@example.com", http.MethodPost, "/api/v1/orgs/:id/invitations", path,
+			map[string]string{"email": "new@example.com", "role": "owner"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unavailable without email service", func(t *testing.T) {
+		orgRepo := repository.NewMockOrganizationRepository()
+		orgRepo.GetMemberFunc = orgMembers(orgID, roles)
+		handler := NewOrganizationHandler(orgRepo, repository.NewMockDeviceRepository())
+
+		w := serveOrgRequest(t, handler, admin, "admin@example.com", http.MethodPost, "/api/v1/orgs/:id/invitations", path,
+			map[string]string{"email": "new@example.com"})
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestOrganizationHandler_AcceptInvitation(t *testing.T) {
+	invitation := &models.OrganizationInvitation{
+		ID:        uuid.New(),
+		OrgID:     uuid.New(),
+		Email:     "invitee@example.com",
+		Role:      models.OrgRoleAdmin,
+		TokenHash: auth.HashToken("invite-token"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	setup := func() (*OrganizationHandler, *bool) {
+		orgRepo := repository.NewMockOrganizationRepository()
+		orgRepo.GetInvitationByTokenHashFunc = func(_ context.Context, tokenHash string) (*models.OrganizationInvitation, error) {
+			if tokenHash != invitation.TokenHash {
+				return nil, repository.ErrInvitationNotFound
+			}
+			return invitation, nil
+		}
+		accepted := false
+		orgRepo.AcceptInvitationFunc = func(_ context.Context, id, userID uuid.UUID) (*models.OrganizationMember, error) {
+			accepted = true
+			return &models.OrganizationMember{OrgID: invitation.OrgID, UserID: userID, Role: invitation.Role}, nil
+		}
+		return NewOrganizationHandler(orgRepo, repository.NewMockDeviceRepository()), &accepted
+	}
+
+	t.Run("invited user joins", func(t *testing.T) {
+		handler, accepted := setup()
+		w := serveOrgRequest(t, handler, uuid.New(), "Invitee@Example.com", http.MethodPost, "/api/v1/invitations/accept", "/api/v1/invitations/accept",
+			map[string]string{"token": "invite-token"})
+
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.True(t, *accepted)
+	})
+
+	t.Run("other accounts cannot use the token", func(t *testing.T) {
+		handler, accepted := setup()
+		w := serveOrgRequest(t, handler, uuid.New(), "someone@example.com", http.MethodPost, "/api/v1/invitations/accept", "/api/v1/invitations/accept",
+			map[string]string{"token": "invite-token"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.False(t, *accepted)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		handler, _ := setup()
+		w := serveOrgRequest(t, handler, uuid.New(), "invitee@example.com", http.MethodPost, "/api/v1/invitations/accept", "/api/v1/invitations/accept",
+			map[string]string{"token": "wrong"})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestOrganizationHandler_SetDeviceOrganization(t *testing.T) {
+	orgID := uuid.New()
+	owner, teammate := uuid.New(), uuid.New()
+
+	setup := func(deviceOrg *uuid.UUID, roles map[uuid.UUID]models.OrgRole) (*OrganizationHandler, *models.Device, **uuid.UUID) {
+		device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: owner, OrgID: deviceOrg, IsActive: true}
+		deviceRepo := repository.NewMockDeviceRepository()
+		deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+			return device, nil
+		}
+		var set *uuid.UUID
+		deviceRepo.SetOrganizationFunc = func(_ context.Context, _ uuid.UUID, id *uuid.UUID) error {
+			set = id
+			return nil
+		}
+		orgRepo := repository.NewMockOrganizationRepository()
+		orgRepo.GetMemberFunc = orgMembers(orgID, roles)
+		return NewOrganizationHandler(orgRepo, deviceRepo), device, &set
+	}
+
+	t.Run("owner shares device with their organization", func(t *testing.T) {
+		handler, device, set := setup(nil, map[uuid.UUID]models.OrgRole{owner: models.OrgRoleMember})
+		w := serveOrgRequest(t, handler, owner, "owner@example.com", http.MethodPut, "/api/v1/devices/:id/organization",
+			"/api/v1/devices/"+device.ID.String()+"/organization", map[string]interface{}{"orgId": orgID})
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NotNil(t, *set)
+		assert.Equal(t, orgID, **set)
+	})
+
+	t.Run("owner cannot share with an organization they are not in", func(t *testing.T) {
+		handler, device, _ := setup(nil, map[uuid.UUID]models.OrgRole{})
+		w := serveOrgRequest(t, handler, owner, "owner@example.com", http.MethodPut, "/api/v1/devices/:id/organization",
+			"/api/v1/devices/"+device.ID.String()+"/organization", map[string]interface{}{"orgId": orgID})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("org admin stops sharing", func(t *testing.T) {
+		handler, device, set := setup(&orgID, map[uuid.UUID]models.OrgRole{teammate: models.OrgRoleAdmin})
+		w := serveOrgRequest(t, handler, teammate, "teammate@example.com", http.MethodPut, "/api/v1/devices/:id/organization",
+			"/api/v1/devices/"+device.ID.String()+"/organization", map[string]interface{}{"orgId": nil})
+
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Nil(t, *set)
+	})
+
+	t.Run("org member cannot change sharing", func(t *testing.T) {
+		handler, device, _ := setup(&orgID, map[uuid.UUID]models.OrgRole{teammate: models.OrgRoleMember})
+		w := serveOrgRequest(t, handler, teammate, "teammate@example.com", http.MethodPut, "/api/v1/devices/:id/organization",
+			"/api/v1/devices/"+device.ID.String()+"/organization", map[string]interface{}{"orgId": nil})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestOrgAccess_SharedDevices(t *testing.T) {
+	orgID := uuid.New()
+	owner, member, admin, outsider := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	orgRepo := repository.NewMockOrganizationRepository()
+	orgRepo.GetMemberFunc = orgMembers(orgID, map[uuid.UUID]models.OrgRole{member: models.OrgRoleMember, admin: models.OrgRoleAdmin})
+	access := newOrgAccess(orgRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		access *orgAccess
+		user   uuid.UUID
+		orgID  *uuid.UUID
+		level  accessLevel
+		want   bool
+	}{
+		{name: "owner manages personal device", access: access, user: owner, level: accessManage, want: true},
+		{name: "member uses shared device", access: access, user: member, orgID: &orgID, level: accessUse, want: true},
+		{name: "member cannot manage shared device", access: access, user: member, orgID: &orgID, level: accessManage, want: false},
+		{name: "admin manages shared device", access: access, user: admin, orgID: &orgID, level: accessManage, want: true},
+		{name: "outsider is denied", access: access, user: outsider, orgID: &orgID, level: accessUse, want: false},
+		{name: "unshared device is personal", access: access, user: member, level: accessUse, want: false},
+		{name: "disabled organizations", access: nil, user: member, orgID: &orgID, level: accessUse, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.access.allows(ctx, tt.user, &owner, tt.orgID, tt.level)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, allowed)
+		})
+	}
+}
+
+func TestSessionHandler_OrganizationSharing(t *testing.T) {
+	orgID := uuid.New()
+	owner, member := uuid.New(), uuid.New()
+	orgRepo := repository.NewMockOrganizationRepository()
+	orgRepo.GetMemberFunc = orgMembers(orgID, map[uuid.UUID]models.OrgRole{member: models.OrgRoleMember})
+	orgRepo.ListByUserIDFunc = func(_ context.Context, _ uuid.UUID) ([]*models.OrganizationMembership, error) {
+		return []*models.OrganizationMembership{{Organization: models.Organization{ID: orgID}, Role: models.OrgRoleMember}}, nil
+	}
+
+	handler, sessionRepo, deviceRepo := setupSessionTest()
+	handler = handler.WithOrganizations(orgRepo)
+	deviceRepo.GetByDeviceIDFunc = func(_ context.Context, id string) (*models.Device, error) {
+		return &models.Device{ID: uuid.New(), DeviceID: id, UserID: owner, OrgID: &orgID, IsActive: true}, nil
+	}
+
+	t.Run("members start sessions on shared devices", func(t *testing.T) {
+		var created *models.Session
+		sessionRepo.CreateFunc = func(_ context.Context, session *models.Session) error {
+			created = session
+			return nil
+		}
+
+		body, _ := json.Marshal(map[string]string{"deviceId": "RACEBOX-001"})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/sessions", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set(string(middleware.UserIDKey), member)
+
+		handler.CreateSession(c)
+
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, member, *created.UserID)
+		require.NotNil(t, created.OrgID)
+		assert.Equal(t, orgID, *created.OrgID)
+	})
+
+	t.Run("listing includes organization sessions", func(t *testing.T) {
+		var gotOrgIDs []uuid.UUID
+		sessionRepo.ListAccessibleFunc = func(_ context.Context, filter repository.SessionFilter) ([]*models.Session, int, error) {
+			gotOrgIDs = filter.OrgIDs
+			return []*models.Session{}, 0, nil
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+		c.Set(string(middleware.UserIDKey), member)
+
+		handler.ListSessions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []uuid.UUID{orgID}, gotOrgIDs)
+	})
+
+	t.Run("members view but cannot end shared sessions", func(t *testing.T) {
+		sessionID := uuid.New()
+		sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			return &models.Session{ID: id, DeviceID: "RACEBOX-001", UserID: &owner, OrgID: &orgID, StartedAt: time.Now().Add(-time.Hour)}, nil
+		}
+
+		for _, tc := range []struct {
+			method string
+			serve  func(*gin.Context)
+			want   int
+		}{
+			{http.MethodGet, handler.GetSession, http.StatusOK},
+			{http.MethodPatch, handler.EndSession, http.StatusForbidden},
+		} {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(tc.method, "/api/v1/sessions/"+sessionID.String(), nil)
+			c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+			c.Set(string(middleware.UserIDKey), member)
+
+			tc.serve(c)
+
+			assert.Equal(t, tc.want, w.Code, tc.method)
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// rejectWeakPassword responds with 400 and the failed rules if the password does not satisfy the policy
+// It returns true when the request was rejected. A nil policy accepts every password.
+func rejectWeakPassword(c *gin.Context, policy *auth.PasswordPolicy, password string) bool {
+	if policy == nil {
+		return false
+	}
+
+	violations := policy.Check(c.Request.Context(), password)
+	if len(violations) == 0 {
+		return false
+	}
+
+	problem.Abort(c, problem.BadRequest("weak_password", "Password does not meet the password policy").With("violations", violations))
+	return true
+}
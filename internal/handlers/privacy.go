@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/privacy"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// privacyDefaults picks how much of each end of a session's track to hide in exports and shared views
+// The owner's profile sets the default. The privacyTrim query parameter replaces it for the owner,
+// while other viewers can only hide more than the owner chose. A nil *privacyDefaults only honours
+// the query parameter.
+type privacyDefaults struct {
+	userRepo repository.UserRepository
+}
+
+// newPrivacyDefaults creates a privacy trim resolver backed by user profiles
+func newPrivacyDefaults(userRepo repository.UserRepository) *privacyDefaults {
+	if userRepo == nil {
+		return nil
+	}
+	return &privacyDefaults{userRepo: userRepo}
+}
+
+// resolve returns the distance in meters to hide at each end of the session's track
+// It writes the error response and returns false when the privacyTrim query parameter is invalid
+// or the owner's default cannot be loaded.
+func (p *privacyDefaults) resolve(c *gin.Context, session *models.Session) (float64, bool) {
+	requested := -1
+	if raw := c.Query("privacyTrim"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 || parsed > privacy.MaxTrimMeters {
+			problem.Abort(c, problem.BadRequest("invalid_request", fmt.Sprintf("privacyTrim must be a number of meters between 0 and %d", privacy.MaxTrimMeters)))
+			return 0, false
+		}
+		requested = parsed
+	}
+
+	if p == nil || session.UserID == nil {
+		return float64(max(requested, 0)), true
+	}
+
+	// Unlike units, the default protects the owner, so a failed lookup fails the request
+	owner := *session.UserID
+	trim, err := p.userRepo.GetPrivacyTrimMeters(c.Request.Context(), owner)
+	if err != nil {
+		slog.Error("Error retrieving privacy trim", "user_id", owner, "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve privacy settings"))
+		return 0, false
+	}
+
+	if requested >= 0 && (middleware.MustGetUserID(c) == owner || requested > trim) {
+		trim = requested
+	}
+	return float64(trim), true
+}
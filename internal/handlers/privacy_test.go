@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrivacyDefaults_Resolve(t *testing.T) {
+	ownerID, viewerID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		query          string
+		lookupErr      error
+		unconfigured   bool
+		expectedStatus int
+		expectedTrim   float64
+	}{
+		{name: "owner default", userID: ownerID, expectedStatus: http.StatusOK, expectedTrim: 300},
+		{name: "owner turns it off", userID: ownerID, query: "?privacyTrim=0", expectedStatus: http.StatusOK, expectedTrim: 0},
+		{name: "viewer cannot hide less", userID: viewerID, query: "?privacyTrim=0", expectedStatus: http.StatusOK, expectedTrim: 300},
+		{name: "viewer can hide more", userID: viewerID, query: "?privacyTrim=500", expectedStatus: http.StatusOK, expectedTrim: 500},
+		{name: "without profiles", userID: viewerID, query: "?privacyTrim=100", unconfigured: true, expectedStatus: http.StatusOK, expectedTrim: 100},
+		{name: "too large", userID: ownerID, query: "?privacyTrim=6000", expectedStatus: http.StatusBadRequest},
+		{name: "not a number", userID: ownerID, query: "?privacyTrim=far", expectedStatus: http.StatusBadRequest},
+		{name: "lookup fails", userID: viewerID, lookupErr: errors.New("db down"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := repository.NewMockUserRepository()
+			userRepo.GetPrivacyTrimMetersFunc = func(_ context.Context, id uuid.UUID) (int, error) {
+				assert.Equal(t, ownerID, id)
+				return 300, tt.lookupErr
+			}
+			defaults := newPrivacyDefaults(userRepo)
+			if tt.unconfigured {
+				defaults = nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/1/export"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), tt.userID)
+
+			trim, ok := defaults.resolve(c, &models.Session{ID: uuid.New(), UserID: &ownerID})
+
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, ok)
+			if !ok {
+				assert.Equal(t, tt.expectedStatus, w.Code)
+				return
+			}
+			assert.Equal(t, tt.expectedTrim, trim)
+		})
+	}
+}
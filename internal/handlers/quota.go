@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// errDeviceLimitReached is returned when claiming a device would exceed the owner's plan
+var errDeviceLimitReached = errors.New("device limit reached")
+
+// QuotaPolicy maps each plan to its usage limits; plans without an entry are unlimited
+type QuotaPolicy struct {
+	Plans map[models.Plan]models.PlanLimits
+}
+
+// Quotas enforces per-user plan limits on telemetry ingest and device claiming
+// Lookups fail open: if usage cannot be read the request is allowed and the error logged.
+type Quotas struct {
+	usageRepo  repository.UsageRepository
+	deviceRepo repository.DeviceRepository
+	policy     QuotaPolicy
+	now        func() time.Time
+}
+
+// NewQuotas creates a quota enforcer
+func NewQuotas(usageRepo repository.UsageRepository, deviceRepo repository.DeviceRepository, policy QuotaPolicy) *Quotas {
+	return &Quotas{
+		usageRepo:  usageRepo,
+		deviceRepo: deviceRepo,
+		policy:     policy,
+		now:        time.Now,
+	}
+}
+
+// Usage reports the user's plan and consumption for the current period
+func (q *Quotas) Usage(ctx context.Context, userID uuid.UUID) (*models.Usage, error) {
+	plan, err := q.usageRepo.GetPlan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	limits := q.policy.Plans[plan]
+	start, end := models.UsagePeriod(q.now())
+
+	points, err := q.usageRepo.GetTelemetryPoints(ctx, userID, start)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := q.countDevices(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Usage{
+		Plan:            plan,
+		PeriodStart:     start,
+		PeriodEnd:       end,
+		TelemetryPoints: models.UsageCounter{Used: points, Limit: limitPtr(limits.TelemetryPointsPerMonth)},
+		Devices:         models.UsageCounter{Used: devices, Limit: limitPtr(limits.MaxDevices)},
+	}, nil
+}
+
+// allowTelemetry reports whether the user may store n more telemetry points this period
+// When not allowed, the returned usage describes the exhausted quota.
+func (q *Quotas) allowTelemetry(ctx context.Context, userID uuid.UUID, n int) (*models.Usage, bool) {
+	plan, err := q.usageRepo.GetPlan(ctx, userID)
+	if err != nil {
+		slog.Error("Error reading plan", "user_id", userID, "error", err)
+		return nil, true
+	}
+	limit := q.policy.Plans[plan].TelemetryPointsPerMonth
+	if limit == 0 {
+		return nil, true
+	}
+
+	start, end := models.UsagePeriod(q.now())
+	used, err := q.usageRepo.GetTelemetryPoints(ctx, userID, start)
+	if err != nil {
+		slog.Error("Error reading usage", "user_id", userID, "error", err)
+		return nil, true
+	}
+	if used+int64(n) <= limit {
+		return nil, true
+	}
+
+	return &models.Usage{
+		Plan:            plan,
+		PeriodStart:     start,
+		PeriodEnd:       end,
+		TelemetryPoints: models.UsageCounter{Used: used, Limit: &limit},
+	}, false
+}
+
+// recordTelemetry adds n stored telemetry points to the user's usage for this period
+func (q *Quotas) recordTelemetry(ctx context.Context, userID uuid.UUID, n int) {
+	if n == 0 {
+		return
+	}
+	start, _ := models.UsagePeriod(q.now())
+	if err := q.usageRepo.AddTelemetryPoints(ctx, userID, start, int64(n)); err != nil {
+		slog.Error("Error recording usage", "user_id", userID, "error", err)
+	}
+}
+
+// checkDeviceLimit returns errDeviceLimitReached if the user cannot claim another device
+func (q *Quotas) checkDeviceLimit(ctx context.Context, userID uuid.UUID) error {
+	plan, err := q.usageRepo.GetPlan(ctx, userID)
+	if err != nil {
+		slog.Error("Error reading plan", "user_id", userID, "error", err)
+		return nil
+	}
+	limit := q.policy.Plans[plan].MaxDevices
+	if limit == 0 {
+		return nil
+	}
+
+	devices, err := q.countDevices(ctx, userID)
+	if err != nil {
+		slog.Error("Error counting devices", "user_id", userID, "error", err)
+		return nil
+	}
+	if devices >= limit {
+		return fmt.Errorf("%w: %s plan allows %d devices", errDeviceLimitReached, plan, limit)
+	}
+
+	return nil
+}
+
+// remainingDevices returns how many more devices the user can claim, or -1 if their plan is unlimited
+// Like checkDeviceLimit, it fails open when the plan or device count cannot be read.
+func (q *Quotas) remainingDevices(ctx context.Context, userID uuid.UUID) int64 {
+	plan, err := q.usageRepo.GetPlan(ctx, userID)
+	if err != nil {
+		slog.Error("Error reading plan", "user_id", userID, "error", err)
+		return -1
+	}
+	limit := q.policy.Plans[plan].MaxDevices
+	if limit == 0 {
+		return -1
+	}
+
+	devices, err := q.countDevices(ctx, userID)
+	if err != nil {
+		slog.Error("Error counting devices", "user_id", userID, "error", err)
+		return -1
+	}
+	return max(limit-devices, 0)
+}
+
+// countDevices returns the number of active devices the user owns
+func (q *Quotas) countDevices(ctx context.Context, userID uuid.UUID) (int64, error) {
+	active := true
+	_, total, err := q.deviceRepo.List(ctx, repository.DeviceFilter{UserID: userID, IsActive: &active, Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	return int64(total), nil
+}
+
+// limitPtr converts a zero (unlimited) limit to nil
+func limitPtr(limit int64) *int64 {
+	if limit == 0 {
+		return nil
+	}
+	return &limit
+}
+
+// setQuotaRetryAfter points Retry-After at the start of the next usage period
+func setQuotaRetryAfter(c *gin.Context, usage *models.Usage, now time.Time) {
+	retryAfter := int(math.Ceil(usage.PeriodEnd.Sub(now).Seconds()))
+	c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+}
+
+// rejectTelemetryQuota responds 429 when an upload would exceed the monthly telemetry quota
+func rejectTelemetryQuota(c *gin.Context, usage *models.Usage, now time.Time) {
+	setQuotaRetryAfter(c, usage, now)
+	problem.Abort(c, telemetryQuotaExceeded(usage))
+}
+
+// telemetryQuotaExceeded describes an exceeded monthly telemetry quota
+func telemetryQuotaExceeded(usage *models.Usage) *problem.Error {
+	return problem.TooManyRequests("quota_exceeded", "Monthly telemetry quota exceeded").
+		With("plan", usage.Plan).
+		With("used", usage.TelemetryPoints.Used).
+		With("limit", usage.TelemetryPoints.Limit).
+		With("resetsAt", usage.PeriodEnd)
+}
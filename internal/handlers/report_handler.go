@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/reports"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// maxReportDays caps the number of days a report covers
+	maxReportDays = 366
+
+	// defaultReportRetention is how long reports are kept when no retention is configured
+	defaultReportRetention = 7 * 24 * time.Hour
+
+	// defaultReportURLTTL is how long download URLs are valid when no lifetime is configured
+	defaultReportURLTTL = 15 * time.Minute
+)
+
+// ReportQueue generates requested reports in the background
+type ReportQueue interface {
+	Submit(report *models.Report) error
+}
+
+// CreateReportRequest represents the request body for a fleet report
+// From and To are inclusive UTC dates (YYYY-MM-DD); the format defaults to csv.
+type CreateReportRequest struct {
+	From   string              `json:"from" binding:"required"`
+	To     string              `json:"to" binding:"required"`
+	Format models.ReportFormat `json:"format,omitempty"`
+}
+
+// ReportHandler handles fleet report requests and downloads
+type ReportHandler struct {
+	reportRepo repository.ReportRepository
+	queue      ReportQueue
+	signer     *reports.Signer
+	orgRepo    repository.OrganizationRepository // Optional: nil disables organization reports
+	retention  time.Duration
+	urlTTL     time.Duration
+	now        func() time.Time
+}
+
+// NewReportHandler creates a new report handler
+// Reports are kept for retention and each download URL is valid for urlTTL; zero uses the defaults.
+func NewReportHandler(reportRepo repository.ReportRepository, queue ReportQueue, signer *reports.Signer, retention, urlTTL time.Duration) *ReportHandler {
+	if retention <= 0 {
+		retention = defaultReportRetention
+	}
+	if urlTTL <= 0 {
+		urlTTL = defaultReportURLTTL
+	}
+
+	return &ReportHandler{
+		reportRepo: reportRepo,
+		queue:      queue,
+		signer:     signer,
+		retention:  retention,
+		urlTTL:     urlTTL,
+		now:        time.Now,
+	}
+}
+
+// WithOrganizations lets organization owners request reports on their organization's devices
+func (h *ReportHandler) WithOrganizations(orgRepo repository.OrganizationRepository) *ReportHandler {
+	h.orgRepo = orgRepo
+	return h
+}
+
+// CreateFleetReport requests a report covering every device
+// POST /api/v1/admin/reports
+func (h *ReportHandler) CreateFleetReport(c *gin.Context) {
+	h.createReport(c, nil)
+}
+
+// CreateOrganizationReport requests a report on the sessions shared with an organization and
+// recorded by its devices
+// Only the organization's owners can request one; non-members get 404.
+// POST /api/v1/orgs/:id/reports
+func (h *ReportHandler) CreateOrganizationReport(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_organization_id", "Invalid organization ID format"))
+		return
+	}
+
+	member, err := h.orgRepo.GetMember(c.Request.Context(), orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrgMemberNotFound) {
+			problem.Abort(c, problem.NotFound("organization_not_found", "Organization not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve organization membership"))
+		return
+	}
+	if member.Role != models.OrgRoleOwner {
+		problem.Abort(c, problem.Forbidden("forbidden", "Only organization owners can request reports"))
+		return
+	}
+
+	h.createReport(c, &orgID)
+}
+
+// createReport validates the request and queues a report scoped to orgID, or to every device if nil
+func (h *ReportHandler) createReport(c *gin.Context, orgID *uuid.UUID) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	from, errFrom := time.Parse(time.DateOnly, req.From)
+	to, errTo := time.Parse(time.DateOnly, req.To)
+	if errFrom != nil || errTo != nil {
+		problem.Abort(c, problem.BadRequest("invalid_date", "from and to must be dates in YYYY-MM-DD format"))
+		return
+	}
+	if to.Before(from) {
+		problem.Abort(c, problem.BadRequest("invalid_time_range", "to must not be before from"))
+		return
+	}
+	if to.Sub(from) >= maxReportDays*24*time.Hour {
+		problem.Abort(c, problem.BadRequest("invalid_time_range", fmt.Sprintf("Reports cover at most %d days", maxReportDays)))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = models.ReportCSV
+	}
+	if !format.IsValid() {
+		problem.Abort(c, problem.BadRequest("invalid_format", "format must be csv or xlsx"))
+		return
+	}
+
+	report := &models.Report{
+		RequestedBy: userID,
+		OrgID:       orgID,
+		Format:      format,
+		Status:      models.ReportPending,
+		From:        from,
+		To:          to,
+		ExpiresAt:   h.now().UTC().Add(h.retention),
+	}
+	if err := h.reportRepo.Create(c.Request.Context(), report); err != nil {
+		slog.Error("Error creating report", "user_id", userID, "error", err)
+		problem.Abort(c, problem.Internal("Failed to create report"))
+		return
+	}
+
+	if err := h.queue.Submit(report); err != nil {
+		message := "Too many reports are in progress"
+		if err := h.reportRepo.UpdateStatus(c.Request.Context(), report.ID, models.ReportFailed, &message); err != nil {
+			slog.Error("Error failing report", "report_id", report.ID, "error", err)
+		}
+		c.Header("Retry-After", "60")
+		problem.Abort(c, problem.ServiceUnavailable("report_busy", "Too many reports are in progress; try again later"))
+		return
+	}
+
+	c.Header("Location", api.VersionOf(c.Request.URL.Path).Path()+"/reports/"+report.ID.String())
+	api.Respond(c, http.StatusAccepted, report)
+}
+
+// GetReport retrieves the status of a report the authenticated user requested
+// Completed reports include a signed download URL, valid for the configured lifetime.
+// GET /api/v1/reports/:id
+func (h *ReportHandler) GetReport(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_report_id", "Invalid report ID format"))
+		return
+	}
+
+	report, err := h.reportRepo.GetByID(c.Request.Context(), id)
+	if err != nil && !errors.Is(err, repository.ErrReportNotFound) {
+		problem.Abort(c, problem.Internal("Failed to retrieve report"))
+		return
+	}
+	// Other users get 404 so report IDs are not disclosed
+	if report == nil || report.RequestedBy != userID {
+		problem.Abort(c, problem.NotFound("report_not_found", "Report not found"))
+		return
+	}
+
+	if report.Status == models.ReportCompleted {
+		// Links never outlive the report
+		expires := h.now().Add(h.urlTTL)
+		if report.ExpiresAt.Before(expires) {
+			expires = report.ExpiresAt
+		}
+		report.DownloadURL = h.signer.URL(api.VersionOf(c.Request.URL.Path).Path(), report.ID, expires)
+	}
+
+	api.Respond(c, http.StatusOK, report)
+}
+
+// DownloadReport serves a completed report's file to holders of a valid signed URL
+// No credentials are needed, so links can be handed to browsers and spreadsheet tools.
+// GET /api/v1/reports/:id/download?expires=&signature=
+func (h *ReportHandler) DownloadReport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_report_id", "Invalid report ID format"))
+		return
+	}
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil || !h.signer.Verify(id, expires, c.Query("signature")) {
+		problem.Abort(c, problem.Forbidden("invalid_signature", "Invalid download signature"))
+		return
+	}
+	if h.now().Unix() > expires {
+		problem.Abort(c, problem.Forbidden("download_link_expired", "This download link has expired; request a new one from the report"))
+		return
+	}
+
+	report, err := h.reportRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReportNotFound) {
+			problem.Abort(c, problem.NotFound("report_not_found", "Report not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve report"))
+		return
+	}
+
+	content, err := h.reportRepo.GetContent(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrReportNotFound):
+			problem.Abort(c, problem.NotFound("report_not_found", "Report not found"))
+		case errors.Is(err, repository.ErrReportNotReady):
+			problem.Abort(c, problem.Conflict("report_not_ready", "The report has not been generated"))
+		default:
+			problem.Abort(c, problem.Internal("Failed to retrieve report"))
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+report.Filename()+`"`)
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, report.Format.ContentType(), content)
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/reports"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingReportQueue records the reports submitted for generation
+type recordingReportQueue struct {
+	submitted []*models.Report
+	err       error
+}
+
+func (q *recordingReportQueue) Submit(report *models.Report) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.submitted = append(q.submitted, report)
+	return nil
+}
+
+func performReportRequest(method, path, id string, userID uuid.UUID, body string, serve gin.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, path, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set(string(middleware.UserIDKey), userID)
+	serve(c)
+	return w
+}
+
+func setupReportTest() (*ReportHandler, *repository.MockReportRepository, *recordingReportQueue) {
+	reportRepo := repository.NewMockReportRepository()
+	queue := &recordingReportQueue{}
+	return NewReportHandler(reportRepo, queue, reports.NewSigner(auth.NewJWTService("test-secret", time.Hour, time.Hour)), time.Hour, 10*time.Minute), reportRepo, queue
+}
+
+func TestReportHandler_CreateFleetReport(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		queueErr       error
+		expectedStatus int
+		expectedFormat models.ReportFormat
+	}{
+		{name: "csv by default", body: `{"from":"2024-05-01","to":"2024-05-31"}`, expectedStatus: http.StatusAccepted, expectedFormat: models.ReportCSV},
+		{name: "xlsx", body: `{"from":"2024-05-01","to":"2024-05-01","format":"xlsx"}`, expectedStatus: http.StatusAccepted, expectedFormat: models.ReportXLSX},
+		{name: "unknown format", body: `{"from":"2024-05-01","to":"2024-05-31","format":"pdf"}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid date", body: `{"from":"2024-05-01T00:00:00Z","to":"2024-05-31"}`, expectedStatus: http.StatusBadRequest},
+		{name: "to before from", body: `{"from":"2024-05-31","to":"2024-05-01"}`, expectedStatus: http.StatusBadRequest},
+		{name: "range too long", body: `{"from":"2023-01-01","to":"2024-05-01"}`, expectedStatus: http.StatusBadRequest},
+		{name: "queue full", body: `{"from":"2024-05-01","to":"2024-05-31"}`, queueErr: reports.ErrQueueFull, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, reportRepo, queue := setupReportTest()
+			queue.err = tt.queueErr
+			adminID := uuid.New()
+
+			var created *models.Report
+			reportRepo.CreateFunc = func(_ context.Context, report *models.Report) error {
+				report.ID = uuid.New()
+				created = report
+				return nil
+			}
+			var failed bool
+			reportRepo.UpdateStatusFunc = func(_ context.Context, _ uuid.UUID, status models.ReportStatus, _ *string) error {
+				failed = status == models.ReportFailed
+				return nil
+			}
+
+			w := performReportRequest(http.MethodPost, "/api/v1/admin/reports", "", adminID, tt.body, handler.CreateFleetReport)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.queueErr != nil {
+				assert.True(t, failed, "unqueued reports are failed")
+				assert.Equal(t, "60", w.Header().Get("Retry-After"))
+				return
+			}
+			if tt.expectedStatus != http.StatusAccepted {
+				assert.Nil(t, created)
+				return
+			}
+
+			require.NotNil(t, created)
+			assert.Equal(t, adminID, created.RequestedBy)
+			assert.Nil(t, created.OrgID)
+			assert.Equal(t, tt.expectedFormat, created.Format)
+			assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), created.From)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), created.ExpiresAt, 5*time.Second)
+			assert.Equal(t, []*models.Report{created}, queue.submitted)
+			assert.Equal(t, "/api/v1/reports/"+created.ID.String(), w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestReportHandler_CreateOrganizationReport(t *testing.T) {
+	orgID := uuid.New()
+
+	tests := []struct {
+		name           string
+		role           models.OrgRole // Empty for non-members
+		expectedStatus int
+	}{
+		{name: "owner", role: models.OrgRoleOwner, expectedStatus: http.StatusAccepted},
+		{name: "admin", role: models.OrgRoleAdmin, expectedStatus: http.StatusForbidden},
+		{name: "member", role: models.OrgRoleMember, expectedStatus: http.StatusForbidden},
+		{name: "non-member", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, queue := setupReportTest()
+			orgRepo := repository.NewMockOrganizationRepository()
+			handler.WithOrganizations(orgRepo)
+			userID := uuid.New()
+
+			orgRepo.GetMemberFunc = func(_ context.Context, id, memberID uuid.UUID) (*models.OrganizationMember, error) {
+				if tt.role == "" {
+					return nil, repository.ErrOrgMemberNotFound
+				}
+				return &models.OrganizationMember{OrgID: id, UserID: memberID, Role: tt.role}, nil
+			}
+
+			w := performReportRequest(http.MethodPost, "/api/v1/orgs/"+orgID.String()+"/reports", orgID.String(), userID,
+				`{"from":"2024-05-01","to":"2024-05-31"}`, handler.CreateOrganizationReport)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusAccepted {
+				assert.Empty(t, queue.submitted)
+				return
+			}
+			require.Len(t, queue.submitted, 1)
+			assert.Equal(t, &orgID, queue.submitted[0].OrgID)
+		})
+	}
+}
+
+func TestReportHandler_GetReport(t *testing.T) {
+	requesterID := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		status         models.ReportStatus
+		expiresAt      time.Time
+		expectedStatus int
+		expectedExpiry time.Time
+	}{
+		{name: "pending", userID: requesterID, status: models.ReportPending, expiresAt: now.Add(time.Hour), expectedStatus: http.StatusOK},
+		{name: "completed", userID: requesterID, status: models.ReportCompleted, expiresAt: now.Add(time.Hour), expectedStatus: http.StatusOK, expectedExpiry: now.Add(10 * time.Minute)},
+		{name: "link capped at expiry", userID: requesterID, status: models.ReportCompleted, expiresAt: now.Add(time.Minute), expectedStatus: http.StatusOK, expectedExpiry: now.Add(time.Minute)},
+		{name: "another user", userID: uuid.New(), status: models.ReportCompleted, expiresAt: now.Add(time.Hour), expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, reportRepo, _ := setupReportTest()
+			id := uuid.New()
+			reportRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Report, error) {
+				return &models.Report{ID: id, RequestedBy: requesterID, Format: models.ReportCSV, Status: tt.status, ExpiresAt: tt.expiresAt}, nil
+			}
+
+			w := performReportRequest(http.MethodGet, "/api/v1/reports/"+id.String(), id.String(), tt.userID, "", handler.GetReport)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.Report
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedExpiry.IsZero() {
+				assert.Empty(t, response.DownloadURL)
+				return
+			}
+			link, err := url.Parse(response.DownloadURL)
+			require.NoError(t, err)
+			expires, err := strconv.ParseInt(link.Query().Get("expires"), 10, 64)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expectedExpiry.Unix(), expires, 5)
+		})
+	}
+}
+
+func TestReportHandler_DownloadReport(t *testing.T) {
+	signer := reports.NewSigner(auth.NewJWTService("test-secret", time.Hour, time.Hour))
+	id := uuid.New()
+	valid, err := url.Parse(signer.URL("/api/v1", id, time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	expired, err := url.Parse(signer.URL("/api/v1", id, time.Now().Add(-time.Minute)))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		query          string
+		contentErr     error
+		expectedStatus int
+	}{
+		{name: "valid link", query: valid.RawQuery, expectedStatus: http.StatusOK},
+		{name: "expired link", query: expired.RawQuery, expectedStatus: http.StatusForbidden},
+		{name: "tampered expiry", query: "expires=99999999999&signature=" + valid.Query().Get("signature"), expectedStatus: http.StatusForbidden},
+		{name: "missing signature", query: "", expectedStatus: http.StatusForbidden},
+		{name: "not generated", query: valid.RawQuery, contentErr: repository.ErrReportNotReady, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, reportRepo, _ := setupReportTest()
+			reportRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Report, error) {
+				return &models.Report{
+					ID: id, Format: models.ReportCSV, Status: models.ReportCompleted,
+					From: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+				}, nil
+			}
+			reportRepo.GetContentFunc = func(_ context.Context, _ uuid.UUID) ([]byte, error) {
+				if tt.contentErr != nil {
+					return nil, tt.contentErr
+				}
+				return []byte("device_id\nAVT-001\n"), nil
+			}
+
+			// Downloads need no credentials
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/reports/"+id.String()+"/download?"+tt.query, nil)
+			c.Params = gin.Params{{Key: "id", Value: id.String()}}
+
+			handler.DownloadReport(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "device_id\nAVT-001\n", w.Body.String())
+			assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename="avt-report-2024-05-01-2024-05-31.csv"`, w.Header().Get("Content-Disposition"))
+		})
+	}
+}
+
+func TestReportHandler_GetReport_LinkFollowsAPIVersion(t *testing.T) {
+	handler, reportRepo, _ := setupReportTest()
+	userID := uuid.New()
+	id := uuid.New()
+	reportRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Report, error) {
+		return &models.Report{ID: id, RequestedBy: userID, Format: models.ReportCSV, Status: models.ReportCompleted, ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+
+	w := performReportRequest(http.MethodGet, "/api/v2/reports/"+id.String(), id.String(), userID, "", handler.GetReport)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	link, err := url.Parse(response.DownloadURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v2/reports/"+id.String()+"/download", link.Path)
+}
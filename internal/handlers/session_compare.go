@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/compare"
+	"github.com/sebasr/avt-service/internal/laps"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// Session comparison alignment modes
+const (
+	alignDistance = "distance" // From the start of each session
+	alignLap      = "lap"      // From the start/finish line crossing of one lap of each session
+)
+
+// Default and maximum distance between compared points, in meters
+const (
+	defaultCompareStep = 10.0
+	maxCompareStep     = 1000.0
+)
+
+// ComparedSession describes the part of a session used in a comparison
+type ComparedSession struct {
+	SessionID uuid.UUID   `json:"sessionId"`
+	Lap       *models.Lap `json:"lap,omitempty"` // Only when aligned by lap
+	Distance  float64     `json:"distance"`      // Meters covered by the compared part
+	Duration  float64     `json:"duration"`      // Seconds covered by the compared part
+}
+
+// SessionComparison is the delta-time and speed-difference series of two sessions
+type SessionComparison struct {
+	Align  string          `json:"align"`
+	Step   float64         `json:"step"` // Meters between points
+	Track  *models.Track   `json:"track,omitempty"`
+	A      ComparedSession `json:"a"`
+	B      ComparedSession `json:"b"`
+	Points []compare.Point `json:"points"`
+	Count  int             `json:"count"`
+}
+
+// CompareSessions aligns two sessions by distance travelled and returns delta-time and speed-difference series
+// With align=distance both sessions are measured from their first located point. With align=lap one lap of
+// each is compared, lapA and lapB (default: each session's fastest lap) detected on trackId or, without it,
+// on the user's track that produces the most laps in session a.
+// GET /api/v1/sessions/compare?a=&b=&align=distance|lap&step=&lapA=&lapB=&trackId=
+func (h *SessionHandler) CompareSessions(c *gin.Context) {
+	align := c.DefaultQuery("align", alignDistance)
+	if align != alignDistance && align != alignLap {
+		problem.Abort(c, problem.BadRequest("invalid_request", "align must be distance or lap"))
+		return
+	}
+
+	step := defaultCompareStep
+	if raw := c.Query("step"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed < 1 || parsed > maxCompareStep {
+			problem.Abort(c, problem.BadRequest("invalid_request", fmt.Sprintf("step must be a number of meters between 1 and %.0f", maxCompareStep)))
+			return
+		}
+		step = parsed
+	}
+
+	lapA, errA := parseIntQuery(c, "lapA", 0)
+	lapB, errB := parseIntQuery(c, "lapB", 0)
+	if errA != nil || errB != nil || lapA < 0 || lapB < 0 {
+		problem.Abort(c, problem.BadRequest("invalid_request", "lapA and lapB must be lap numbers"))
+		return
+	}
+
+	var trackID uuid.UUID
+	if raw := c.Query("trackId"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_track_id", "Invalid track ID format"))
+			return
+		}
+		trackID = parsed
+	}
+
+	if c.Query("a") == "" || c.Query("b") == "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Query parameters a and b are required"))
+		return
+	}
+
+	sessionA, ok := h.loadSession(c, c.Query("a"), accessUse)
+	if !ok {
+		return
+	}
+	sessionB, ok := h.loadSession(c, c.Query("b"), accessUse)
+	if !ok {
+		return
+	}
+
+	if h.telemetryRepo == nil || (align == alignLap && h.trackRepo == nil) {
+		problem.Abort(c, problem.ServiceUnavailable("compare_unavailable", "Session comparison is not configured"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	comparison := SessionComparison{
+		Align: align,
+		A:     ComparedSession{SessionID: sessionA.ID},
+		B:     ComparedSession{SessionID: sessionB.ID},
+	}
+
+	var windowA, windowB [2]time.Time
+	if align == alignLap {
+		tracks, ok := h.lapTracks(c, trackID)
+		if !ok {
+			return
+		}
+
+		track, lapsA, err := h.detectLaps(ctx, sessionA, tracks)
+		if err != nil {
+			problem.Abort(c, problem.Internal("Failed to detect laps"))
+			return
+		}
+		if track == nil {
+			problem.Abort(c, problem.New(http.StatusUnprocessableEntity, "no_laps", "No laps detected in session a"))
+			return
+		}
+		_, lapsB, err := h.detectLaps(ctx, sessionB, []*models.Track{track})
+		if err != nil {
+			problem.Abort(c, problem.Internal("Failed to detect laps"))
+			return
+		}
+
+		comparison.Track = track
+		if comparison.A.Lap = pickLap(lapsA, lapA); comparison.A.Lap == nil {
+			rejectMissingLap(c, "a")
+			return
+		}
+		if comparison.B.Lap = pickLap(lapsB, lapB); comparison.B.Lap == nil {
+			rejectMissingLap(c, "b")
+			return
+		}
+		windowA = [2]time.Time{comparison.A.Lap.StartedAt, comparison.A.Lap.EndedAt}
+		windowB = [2]time.Time{comparison.B.Lap.StartedAt, comparison.B.Lap.EndedAt}
+	}
+
+	traceA, err := h.sessionTrace(ctx, sessionA, windowA)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to read session telemetry"))
+		return
+	}
+	traceB, err := h.sessionTrace(ctx, sessionB, windowB)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to read session telemetry"))
+		return
+	}
+
+	comparison.Points, comparison.Step = compare.Align(traceA, traceB, step)
+	comparison.Count = len(comparison.Points)
+	comparison.A.Distance, comparison.A.Duration = traceA.Distance(), traceA.Duration()
+	comparison.B.Distance, comparison.B.Duration = traceB.Distance(), traceB.Duration()
+
+	api.Respond(c, http.StatusOK, comparison)
+}
+
+// detectLaps runs lap detection over a session's telemetry
+func (h *SessionHandler) detectLaps(ctx context.Context, session *models.Session, tracks []*models.Track) (*models.Track, []*models.Lap, error) {
+	it, err := h.telemetryRepo.IterateBySession(ctx, session.ID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := it.Close(); err != nil {
+			slog.Error("Error closing telemetry iterator", "session_id", session.ID, "error", err)
+		}
+	}()
+
+	return laps.Detect(tracks, it)
+}
+
+// sessionTrace builds the distance trace of a session, limited to window when it is set
+func (h *SessionHandler) sessionTrace(ctx context.Context, session *models.Session, window [2]time.Time) (*compare.Trace, error) {
+	it, err := h.telemetryRepo.IterateBySession(ctx, session.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := it.Close(); err != nil {
+			slog.Error("Error closing telemetry iterator", "session_id", session.ID, "error", err)
+		}
+	}()
+
+	return compare.BuildTrace(it, window[0], window[1])
+}
+
+// pickLap returns the lap with the given number, or the fastest lap for number 0
+func pickLap(sessionLaps []*models.Lap, number int) *models.Lap {
+	for _, lap := range sessionLaps {
+		if (number == 0 && lap.IsBest) || lap.Number == number {
+			return lap
+		}
+	}
+	return nil
+}
+
+// rejectMissingLap responds that the requested lap of a compared session was not found
+func rejectMissingLap(c *gin.Context, side string) {
+	problem.Abort(c, problem.New(http.StatusUnprocessableEntity, "lap_not_found", "Requested lap not found in session "+side))
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+func TestSessionHandler_CompareSessions(t *testing.T) {
+	userID := uuid.New()
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	fastID, slowID := uuid.New(), uuid.New()
+	track := &models.Track{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      "Test Circuit",
+		LineStart: geo.Point{Latitude: 42.0, Longitude: 23.0},
+		LineEnd:   geo.Point{Latitude: 42.0002, Longitude: 23.0},
+	}
+
+	// Both sessions drive the same loop across the line; the slow one takes twice as long
+	drive := func(scale time.Duration) []*models.TelemetryData {
+		path := []struct {
+			offset   time.Duration
+			lat, lon float64
+		}{
+			{0, 42.0001, 22.9999},
+			{time.Second, 42.0001, 23.0001},
+			{15 * time.Second, 42.001, 23.0001},
+			{30 * time.Second, 42.001, 22.9999},
+			{45 * time.Second, 42.0001, 22.9999},
+			{46 * time.Second, 42.0001, 23.0001},
+		}
+		telemetry := make([]*models.TelemetryData, len(path))
+		for i, p := range path {
+			telemetry[i] = &models.TelemetryData{
+				Timestamp: start.Add(p.offset * scale),
+				GPS:       models.GpsData{Latitude: p.lat, Longitude: p.lon, Speed: 100 / float64(scale), IsFixValid: true},
+			}
+		}
+		return telemetry
+	}
+
+	compareSessions := func(query string, configured bool) *httptest.ResponseRecorder {
+		handler, sessionRepo, _ := setupSessionTest()
+		if configured {
+			telemetryRepo := repository.NewMockRepository()
+			telemetryRepo.IterateBySessionFunc = func(_ context.Context, id string) (repository.TelemetryIterator, error) {
+				if id == slowID.String() {
+					return repository.NewSliceTelemetryIterator(drive(2)), nil
+				}
+				return repository.NewSliceTelemetryIterator(drive(1)), nil
+			}
+			trackRepo := repository.NewMockTrackRepository()
+			trackRepo.ListByUserIDFunc = func(_ context.Context, _ uuid.UUID) ([]*models.Track, error) {
+				return []*models.Track{track}, nil
+			}
+			handler = handler.WithTelemetryRepo(telemetryRepo).WithTrackRepo(trackRepo)
+		}
+
+		sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			if id != fastID && id != slowID {
+				return nil, repository.ErrSessionNotFound
+			}
+			return &models.Session{ID: id, UserID: &userID, StartedAt: start}, nil
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/compare"+query, nil)
+		c.Set(string(middleware.UserIDKey), userID)
+
+		handler.CompareSessions(c)
+		return w
+	}
+
+	pair := "?a=" + fastID.String() + "&b=" + slowID.String()
+
+	t.Run("aligned by distance", func(t *testing.T) {
+		w := compareSessions(pair+"&step=50", true)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var comparison SessionComparison
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &comparison))
+		assert.Equal(t, alignDistance, comparison.Align)
+		assert.Equal(t, 50.0, comparison.Step)
+		assert.Equal(t, fastID, comparison.A.SessionID)
+		assert.InDelta(t, comparison.A.Distance, comparison.B.Distance, 0.001)
+		assert.InDelta(t, 46, comparison.A.Duration, 0.001)
+		assert.InDelta(t, 92, comparison.B.Duration, 0.001)
+		require.Equal(t, len(comparison.Points), comparison.Count)
+		require.NotEmpty(t, comparison.Points)
+
+		last := comparison.Points[len(comparison.Points)-1]
+		assert.InDelta(t, last.TimeA, last.DeltaTime, 0.001, "the slow session should lose as much time as the fast one took")
+		assert.InDelta(t, -50, last.SpeedDiff, 0.001)
+	})
+
+	t.Run("aligned by best lap", func(t *testing.T) {
+		w := compareSessions(pair+"&align=lap", true)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var comparison SessionComparison
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &comparison))
+		require.NotNil(t, comparison.Track)
+		assert.Equal(t, track.ID, comparison.Track.ID)
+		require.NotNil(t, comparison.A.Lap)
+		require.NotNil(t, comparison.B.Lap)
+		assert.InDelta(t, 45, comparison.A.Lap.DurationSeconds, 0.01)
+		assert.InDelta(t, 90, comparison.B.Lap.DurationSeconds, 0.01)
+		assert.NotEmpty(t, comparison.Points)
+	})
+
+	t.Run("unknown lap", func(t *testing.T) {
+		w := compareSessions(pair+"&align=lap&lapB=2", true)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("missing session", func(t *testing.T) {
+		w := compareSessions("?a="+fastID.String(), true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		w := compareSessions("?a="+fastID.String()+"&b="+uuid.New().String(), true)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid align", func(t *testing.T) {
+		w := compareSessions(pair+"&align=time", true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		w := compareSessions(pair, false)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// sessionGroupByDay groups session listings by local start date
+const sessionGroupByDay = "day"
+
+// SessionDayGroup is the sessions of a listing page that started on one local date
+// A date can continue on the next page, so clients merge groups with the same date across pages.
+type SessionDayGroup struct {
+	Date     string                    `json:"date"`    // YYYY-MM-DD in the listing's time zone
+	DaysAgo  int                       `json:"daysAgo"` // 0 for today and 1 for yesterday, in the listing's time zone
+	Sessions []*models.SessionResponse `json:"sessions"`
+}
+
+// groupSessionsByDay groups sessions sorted by start time by their start date in location
+func groupSessionsByDay(sessions []*models.SessionResponse, location *time.Location, now time.Time) []SessionDayGroup {
+	groups := make([]SessionDayGroup, 0)
+	today := civilDate(now.In(location))
+	for _, session := range sessions {
+		started := session.StartedAt.In(location)
+		date := started.Format(time.DateOnly)
+		if len(groups) == 0 || groups[len(groups)-1].Date != date {
+			groups = append(groups, SessionDayGroup{
+				Date:    date,
+				DaysAgo: int(today.Sub(civilDate(started)).Hours() / 24),
+			})
+		}
+		last := &groups[len(groups)-1]
+		last.Sessions = append(last.Sessions, session)
+	}
+	return groups
+}
+
+// civilDate returns the calendar date of t as midnight UTC, so that dates differ by whole days
+// even across daylight saving changes
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseSessionRange reads the from and to query parameters of a session listing
+// Dates are midnight in location and to covers its whole day; timestamps are used as given,
+// with to exclusive.
+func parseSessionRange(c *gin.Context, location *time.Location) (from, before *time.Time, err error) {
+	if raw := c.Query("from"); raw != "" {
+		if from, err = parseSessionBound(raw, location, false); err != nil {
+			return nil, nil, errors.New("from must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if before, err = parseSessionBound(raw, location, true); err != nil {
+			return nil, nil, errors.New("to must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		}
+	}
+	if from != nil && before != nil && !before.After(*from) {
+		return nil, nil, errors.New("to must be after from")
+	}
+	return from, before, nil
+}
+
+// parseSessionBound parses a date at midnight in location, or the following midnight if endOfDay,
+// or an RFC 3339 timestamp
+func parseSessionBound(raw string, location *time.Location, endOfDay bool) (*time.Time, error) {
+	if date, err := time.ParseInLocation(time.DateOnly, raw, location); err == nil {
+		if endOfDay {
+			date = date.AddDate(0, 0, 1)
+		}
+		return &date, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
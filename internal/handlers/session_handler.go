@@ -0,0 +1,745 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/export"
+	"github.com/sebasr/avt-service/internal/laps"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/sebasr/avt-service/internal/privacy"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Default and maximum page sizes for session listing
+const (
+	defaultSessionListLimit = 50
+	maxSessionListLimit     = 200
+)
+
+// Default and maximum simplification tolerance for session tracks, in meters
+const (
+	defaultTrackTolerance = 5.0
+	maxTrackTolerance     = 1000.0
+)
+
+// SessionSummarizer schedules background computation of session aggregates
+type SessionSummarizer interface {
+	Enqueue(sessionID uuid.UUID)
+}
+
+// SessionPostProcessor schedules background smoothing of session telemetry
+type SessionPostProcessor interface {
+	Enqueue(sessionID uuid.UUID)
+}
+
+// SessionHandler handles recording session requests
+type SessionHandler struct {
+	sessionRepo   repository.SessionRepository
+	deviceRepo    repository.DeviceRepository
+	summarizer    SessionSummarizer                      // Optional: nil disables summarizing on session end
+	postProcessor SessionPostProcessor                   // Optional: nil disables smoothing on session end
+	telemetryRepo repository.TelemetryRepository         // Optional: required for telemetry export, tracks and laps
+	trackRepo     repository.TrackRepository             // Optional: required for lap detection
+	deletionRepo  repository.TelemetryDeletionRepository // Optional: nil disables session trims
+	undoWindow    time.Duration                          // How long trimmed telemetry can be restored
+	orgs          *orgAccess                             // Optional: nil limits access to personal owners
+	units         *unitPreferences                       // Optional: nil ignores profile units preferences
+	privacy       *privacyDefaults                       // Optional: nil ignores profile privacy trims
+	timezones     *timezonePreferences                   // Optional: nil interprets listing dates in UTC
+	events        EventPublisher                         // Optional: when set, ended sessions are published instead of enqueued
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(sessionRepo repository.SessionRepository, deviceRepo repository.DeviceRepository) *SessionHandler {
+	return &SessionHandler{
+		sessionRepo: sessionRepo,
+		deviceRepo:  deviceRepo,
+	}
+}
+
+// WithOrganizations shares sessions and devices with the members of their organization
+// Members can start sessions on shared devices and view shared sessions; only the
+// session's owner and the organization's owners and admins can end them.
+func (h *SessionHandler) WithOrganizations(orgRepo repository.OrganizationRepository) *SessionHandler {
+	h.orgs = newOrgAccess(orgRepo)
+	return h
+}
+
+// WithUnitPreferences converts summaries and stats to the units preference in each user's profile
+func (h *SessionHandler) WithUnitPreferences(userRepo repository.UserRepository) *SessionHandler {
+	h.units = newUnitPreferences(userRepo)
+	return h
+}
+
+// WithTimezones interprets session listing dates in the time zone of each user's profile
+func (h *SessionHandler) WithTimezones(userRepo repository.UserRepository) *SessionHandler {
+	h.timezones = newTimezonePreferences(userRepo)
+	return h
+}
+
+// WithPrivacyDefaults hides the start and end of tracks in exports and shared views as set in
+// each owner's profile
+func (h *SessionHandler) WithPrivacyDefaults(userRepo repository.UserRepository) *SessionHandler {
+	h.privacy = newPrivacyDefaults(userRepo)
+	return h
+}
+
+// WithSummarizer sets the summarizer notified when sessions end
+func (h *SessionHandler) WithSummarizer(summarizer SessionSummarizer) *SessionHandler {
+	h.summarizer = summarizer
+	return h
+}
+
+// WithPostProcessor sets the post-processor notified when sessions end
+func (h *SessionHandler) WithPostProcessor(postProcessor SessionPostProcessor) *SessionHandler {
+	h.postProcessor = postProcessor
+	return h
+}
+
+// WithEvents publishes a session.ended event for every ended session
+// The summarizer and post-processor are then not called directly; they subscribe to the event.
+func (h *SessionHandler) WithEvents(publisher EventPublisher) *SessionHandler {
+	h.events = publisher
+	return h
+}
+
+// WithTelemetryRepo sets the telemetry repository used for exports
+func (h *SessionHandler) WithTelemetryRepo(telemetryRepo repository.TelemetryRepository) *SessionHandler {
+	h.telemetryRepo = telemetryRepo
+	return h
+}
+
+// WithTrackRepo sets the track repository used for lap detection
+func (h *SessionHandler) WithTrackRepo(trackRepo repository.TrackRepository) *SessionHandler {
+	h.trackRepo = trackRepo
+	return h
+}
+
+// CreateSessionRequest represents the session creation request body
+type CreateSessionRequest struct {
+	DeviceID  string     `json:"deviceId" binding:"required,max=50"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	Name      *string    `json:"name,omitempty" binding:"omitempty,max=255"`
+	Location  *string    `json:"location,omitempty" binding:"omitempty,max=255"`
+	Notes     *string    `json:"notes,omitempty"`
+}
+
+// UpdateSessionRequest represents the session details update request body
+// Omitted fields are left unchanged; an empty string clears the field.
+type UpdateSessionRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,max=255"`
+	Location *string `json:"location,omitempty" binding:"omitempty,max=255"`
+	Notes    *string `json:"notes,omitempty" binding:"omitempty,max=10000"`
+}
+
+// EndSessionRequest represents the optional session end request body
+type EndSessionRequest struct {
+	EndedAt *time.Time `json:"endedAt,omitempty"`
+}
+
+// CreateSession starts a new recording session
+// POST /api/v1/sessions
+func (h *SessionHandler) CreateSession(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	deviceID := strings.TrimSpace(req.DeviceID)
+	if deviceID == "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "deviceId is required"))
+		return
+	}
+
+	// Devices claimed by another user cannot be used to start sessions, unless shared
+	// through an organization, in which case the session is shared with it too
+	var orgID *uuid.UUID
+	if h.deviceRepo != nil {
+		device, err := h.deviceRepo.GetByDeviceID(c.Request.Context(), deviceID)
+		if err != nil && !errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.Internal("Failed to verify device"))
+			return
+		}
+		if device != nil {
+			if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessUse, "device") {
+				return
+			}
+			orgID = device.OrgID
+		}
+	}
+
+	now := time.Now().UTC()
+	startedAt := now
+	if req.StartedAt != nil {
+		startedAt = req.StartedAt.UTC()
+	}
+
+	session := &models.Session{
+		ID:        uuid.New(),
+		DeviceID:  deviceID,
+		UserID:    &userID,
+		StartedAt: startedAt,
+		Name:      req.Name,
+		Location:  req.Location,
+		Notes:     req.Notes,
+		OrgID:     orgID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := h.sessionRepo.Create(c.Request.Context(), session); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create session"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, session.ToResponse())
+}
+
+// UpdateSession renames a session and sets its location and notes
+// The response carries the session's aggregates, recomputed first if they are stale.
+// PATCH /api/v1/sessions/:id
+func (h *SessionHandler) UpdateSession(c *gin.Context) {
+	session, ok := h.getSession(c, accessManage)
+	if !ok {
+		return
+	}
+
+	var req UpdateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	if req.Name == nil && req.Location == nil && req.Notes == nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "At least one of name, location or notes is required"))
+		return
+	}
+
+	if req.Name != nil {
+		session.Name = optionalText(*req.Name)
+	}
+	if req.Location != nil {
+		session.Location = optionalText(*req.Location)
+	}
+	if req.Notes != nil {
+		session.Notes = optionalText(*req.Notes)
+	}
+
+	ctx := c.Request.Context()
+	if err := h.sessionRepo.UpdateDetails(ctx, session); err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			problem.Abort(c, problem.NotFound("session_not_found", "Session not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to update session"))
+		return
+	}
+
+	// The details are saved either way, so a failed recompute still returns the cached aggregates
+	if session.IsSummaryStale() {
+		updated, err := h.sessionRepo.UpdateSummary(ctx, session.ID)
+		if err != nil {
+			slog.Warn("Error refreshing session summary", "session_id", session.ID, "error", err)
+		} else {
+			session = updated
+		}
+	}
+
+	api.Respond(c, http.StatusOK, session.ToResponse())
+}
+
+// optionalText trims a text field, returning nil for an empty value so it is cleared
+func optionalText(value string) *string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// EndSession marks a recording session as ended
+// PATCH /api/v1/sessions/:id/end
+func (h *SessionHandler) EndSession(c *gin.Context) {
+	session, ok := h.getSession(c, accessManage)
+	if !ok {
+		return
+	}
+
+	var req EndSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+			return
+		}
+	}
+
+	endedAt := time.Now().UTC()
+	if req.EndedAt != nil {
+		endedAt = req.EndedAt.UTC()
+	}
+
+	if endedAt.Before(session.StartedAt) {
+		problem.Abort(c, problem.BadRequest("invalid_request", "endedAt must not be before startedAt"))
+		return
+	}
+
+	// The session.ended event is stored with the end, so it is delivered exactly when the end commits
+	ctx := withEvent[uuid.UUID](c.Request.Context(), h.events, models.EventSessionEnded, models.SessionEnded{
+		SessionID: session.ID,
+		DeviceID:  session.DeviceID,
+		UserID:    session.UserID,
+		StartedAt: session.StartedAt,
+		EndedAt:   endedAt,
+	})
+	if err := h.sessionRepo.End(ctx, session.ID, endedAt); err != nil {
+		if errors.Is(err, repository.ErrSessionAlreadyEnded) {
+			problem.Abort(c, problem.Conflict("session_already_ended", "Session has already ended"))
+			return
+		}
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			problem.Abort(c, problem.NotFound("session_not_found", "Session not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to end session"))
+		return
+	}
+
+	session.EndedAt = &endedAt
+	session.UpdatedAt = time.Now().UTC()
+
+	// Compute final aggregates and smoothed telemetry in the background
+	if h.events != nil {
+		notifyEvents(h.events)
+	} else {
+		if h.summarizer != nil {
+			h.summarizer.Enqueue(session.ID)
+		}
+		if h.postProcessor != nil {
+			h.postProcessor.Enqueue(session.ID)
+		}
+	}
+
+	api.Respond(c, http.StatusOK, session.ToResponse())
+}
+
+// ListSessions retrieves the authenticated user's sessions, most recent first
+// from and to restrict the sessions' start to a range of dates (YYYY-MM-DD, to inclusive) in the
+// user's time zone, or of RFC 3339 timestamps (to exclusive). groupBy=day returns the page's sessions
+// grouped by their local start date instead of a flat list.
+// GET /api/v1/sessions?from=&to=&groupBy=day&timezone=&limit=&cursor=&offset=
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	page, offset, err := parsePage(c, defaultSessionListLimit, maxSessionListLimit)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+	groupBy := c.Query("groupBy")
+	if groupBy != "" && groupBy != sessionGroupByDay {
+		problem.Abort(c, problem.BadRequest("invalid_request", "groupBy must be day"))
+		return
+	}
+	location, ok := h.timezones.resolve(c)
+	if !ok {
+		return
+	}
+	filter := repository.SessionFilter{UserID: userID, Limit: page.Fetch(), Offset: offset, After: page.After}
+	if filter.StartedFrom, filter.StartedBefore, err = parseSessionRange(c, location); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	if filter.OrgIDs, err = h.orgs.orgIDs(c.Request.Context(), userID); err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve organizations"))
+		return
+	}
+
+	sessions, total, err := h.sessionRepo.ListAccessible(c.Request.Context(), filter)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve sessions"))
+		return
+	}
+	sessions, nextCursor := pagination.Next(page, sessions, sessionCursor)
+
+	response := make([]*models.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = session.ToResponse()
+	}
+
+	meta := api.Meta{
+		"total":  total,
+		"limit":  page.Limit,
+		"offset": offset,
+	}
+	if nextCursor != "" {
+		meta["nextCursor"] = nextCursor
+	}
+	if groupBy == sessionGroupByDay {
+		meta["timezone"] = location.String()
+		api.List(c, http.StatusOK, "days", groupSessionsByDay(response, location, time.Now()), meta)
+		return
+	}
+	api.List(c, http.StatusOK, "sessions", response, meta)
+}
+
+// sessionCursor returns the listing position of a session
+func sessionCursor(session *models.Session) pagination.Cursor {
+	return pagination.Cursor{Time: session.StartedAt, ID: session.ID.String()}
+}
+
+// GetSession retrieves a specific session by ID
+// GET /api/v1/sessions/:id
+func (h *SessionHandler) GetSession(c *gin.Context) {
+	session, ok := h.getSession(c, accessUse)
+	if !ok {
+		return
+	}
+
+	api.Respond(c, http.StatusOK, session.ToResponse())
+}
+
+// GetSessionSummary retrieves aggregated statistics for a session
+// Summaries of active or not-yet-aggregated sessions are computed on demand.
+// GET /api/v1/sessions/:id/summary?units=
+func (h *SessionHandler) GetSessionSummary(c *gin.Context) {
+	session, ok := h.getSession(c, accessUse)
+	if !ok {
+		return
+	}
+
+	system, ok := h.units.resolve(c)
+	if !ok {
+		return
+	}
+
+	if session.IsSummaryStale() {
+		updated, err := h.sessionRepo.UpdateSummary(c.Request.Context(), session.ID)
+		if err != nil {
+			problem.Abort(c, problem.Internal("Failed to compute session summary"))
+			return
+		}
+		session = updated
+	}
+
+	api.Respond(c, http.StatusOK, convertSummary(system, session.Summary()))
+}
+
+// GetSessionStats retrieves detailed telemetry statistics for a session
+// Speed percentiles, g-force, altitude gain and time in speed bands are computed by the database
+// and cached on the session; stats of active sessions are recomputed on every request.
+// GET /api/v1/sessions/:id/stats?units=
+func (h *SessionHandler) GetSessionStats(c *gin.Context) {
+	session, ok := h.getSession(c, accessUse)
+	if !ok {
+		return
+	}
+
+	system, ok := h.units.resolve(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	stats, err := h.sessionRepo.GetStats(ctx, session.ID)
+	if err != nil && !errors.Is(err, repository.ErrSessionStatsNotFound) {
+		problem.Abort(c, problem.Internal("Failed to retrieve session stats"))
+		return
+	}
+
+	if stats == nil || stats.IsStaleFor(session) {
+		stats, err = h.sessionRepo.UpdateStats(ctx, session.ID)
+		if err != nil {
+			problem.Abort(c, problem.Internal("Failed to compute session stats"))
+			return
+		}
+	}
+
+	api.Respond(c, http.StatusOK, convertStats(system, stats))
+}
+
+// ExportSession streams a session's telemetry as a GPX track or CSV file, annotated with its markers
+// The start and end of the track are hidden as the owner's privacy trim or privacyTrim asks.
+// GET /api/v1/sessions/:id/export?format=gpx|csv&privacyTrim=
+func (h *SessionHandler) ExportSession(c *gin.Context) {
+	format, err := export.ParseFormat(c.DefaultQuery("format", string(export.FormatGPX)))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	session, ok := h.getSession(c, accessUse)
+	if !ok {
+		return
+	}
+
+	if h.telemetryRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("export_unavailable", "Telemetry export is not configured"))
+		return
+	}
+
+	trim, ok := h.privacy.resolve(c, session)
+	if !ok {
+		return
+	}
+
+	markers, err := h.sessionRepo.ListMarkers(c.Request.Context(), session.ID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve session markers"))
+		return
+	}
+
+	it, err := h.telemetryRepo.IterateBySession(c.Request.Context(), session.ID.String())
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to export session telemetry"))
+		return
+	}
+	defer func() {
+		if err := it.Close(); err != nil {
+			slog.Error("Error closing telemetry iterator", "session_id", session.ID, "error", err)
+		}
+	}()
+
+	filename := fmt.Sprintf("session-%s.%s", session.ID, format)
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure mid-stream can only be logged
+	if err := export.Write(c.Writer, format, session, markers, privacy.Trim(it, trim)); err != nil {
+		slog.Error("Error streaming session export", "session_id", session.ID, "error", err)
+		_ = c.Error(err)
+	}
+}
+
+// GetSessionTrack returns the session's path as a GeoJSON Feature with a simplified LineString
+// Douglas-Peucker simplification drops vertices closer than tolerance meters to the simplified line;
+// tolerance=0 keeps every located point. The geometry is null for sessions with fewer than two points,
+// or when the privacy trim hides the whole track.
+// GET /api/v1/sessions/:id/track.geojson?tolerance=&privacyTrim=
+func (h *SessionHandler) GetSessionTrack(c *gin.Context) {
+	tolerance := defaultTrackTolerance
+	if raw := c.Query("tolerance"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed < 0 || parsed > maxTrackTolerance {
+			problem.Abort(c, problem.BadRequest("invalid_request", fmt.Sprintf("tolerance must be a number of meters between 0 and %.0f", maxTrackTolerance)))
+			return
+		}
+		tolerance = parsed
+	}
+
+	session, ok := h.getSession(c, accessUse)
+	if !ok {
+		return
+	}
+
+	if h.telemetryRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("track_unavailable", "Session tracks are not configured"))
+		return
+	}
+
+	trim, ok := h.privacy.resolve(c, session)
+	if !ok {
+		return
+	}
+
+	track, err := h.telemetryRepo.SessionTrack(c.Request.Context(), session.ID.String(), tolerance)
+	if err != nil {
+		slog.Error("Error building session track", "session_id", session.ID, "error", err)
+		problem.Abort(c, problem.Internal("Failed to build session track"))
+		return
+	}
+
+	if track.Geometry != nil && trim > 0 {
+		track.Geometry, track.SimplifiedPoints, err = privacy.TrimLine(track.Geometry, trim)
+		if err != nil {
+			slog.Error("Error trimming session track", "session_id", session.ID, "error", err)
+			problem.Abort(c, problem.Internal("Failed to build session track"))
+			return
+		}
+	}
+
+	var geometry interface{}
+	if track.Geometry != nil {
+		geometry = track.Geometry
+	}
+
+	// GeoJSON is a standard document, so it is not wrapped in the API version's envelope
+	c.Header("Content-Type", "application/geo+json")
+	c.JSON(http.StatusOK, gin.H{
+		"type":     "Feature",
+		"geometry": geometry,
+		"properties": gin.H{
+			"sessionId":         session.ID,
+			"deviceId":          session.DeviceID,
+			"name":              session.Name,
+			"points":            track.Points,
+			"simplifiedPoints":  track.SimplifiedPoints,
+			"toleranceMeters":   tolerance,
+			"privacyTrimMeters": trim,
+		},
+	})
+}
+
+// GetSessionLaps detects laps in a session's telemetry using the start/finish line of one of the user's tracks
+// Without trackId, every track of the user is tried and the one producing the most laps is used.
+// GET /api/v1/sessions/:id/laps?trackId=
+func (h *SessionHandler) GetSessionLaps(c *gin.Context) {
+	var trackID uuid.UUID
+	if raw := c.Query("trackId"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_track_id", "Invalid track ID format"))
+			return
+		}
+		trackID = parsed
+	}
+
+	session, ok := h.getSession(c, accessUse)
+	if !ok {
+		return
+	}
+
+	if h.telemetryRepo == nil || h.trackRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("laps_unavailable", "Lap detection is not configured"))
+		return
+	}
+
+	tracks, ok := h.lapTracks(c, trackID)
+	if !ok {
+		return
+	}
+
+	it, err := h.telemetryRepo.IterateBySession(c.Request.Context(), session.ID.String())
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to read session telemetry"))
+		return
+	}
+	defer func() {
+		if err := it.Close(); err != nil {
+			slog.Error("Error closing telemetry iterator", "session_id", session.ID, "error", err)
+		}
+	}()
+
+	track, sessionLaps, err := laps.Detect(tracks, it)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to detect laps"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "laps", sessionLaps, api.Meta{
+		"sessionId": session.ID,
+		"track":     track,
+		"count":     len(sessionLaps),
+	})
+}
+
+// lapTracks returns the track with trackID, or every track of the authenticated user when trackID is nil
+// It writes the error response and returns false when the tracks cannot be used.
+func (h *SessionHandler) lapTracks(c *gin.Context, trackID uuid.UUID) ([]*models.Track, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	var tracks []*models.Track
+	if trackID != uuid.Nil {
+		track, err := h.trackRepo.GetByID(c.Request.Context(), trackID)
+		if err != nil {
+			if errors.Is(err, repository.ErrTrackNotFound) {
+				problem.Abort(c, problem.NotFound("track_not_found", "Track not found"))
+				return nil, false
+			}
+			problem.Abort(c, problem.Internal("Failed to retrieve track"))
+			return nil, false
+		}
+		if !track.IsOwnedBy(userID) {
+			problem.Abort(c, problem.Forbidden("forbidden", "You do not have access to this track"))
+			return nil, false
+		}
+		tracks = []*models.Track{track}
+	} else {
+		userTracks, err := h.trackRepo.ListByUserID(c.Request.Context(), userID)
+		if err != nil {
+			problem.Abort(c, problem.Internal("Failed to retrieve tracks"))
+			return nil, false
+		}
+		tracks = userTracks
+	}
+
+	return tracks, true
+}
+
+// getSession loads the session referenced by the :id path parameter and
+// verifies the authenticated user has the requested access. It writes the error
+// response and returns false when the session cannot be used.
+func (h *SessionHandler) getSession(c *gin.Context, level accessLevel) (*models.Session, bool) {
+	return h.loadSession(c, c.Param("id"), level)
+}
+
+// loadSession is getSession for a session ID taken from anywhere in the request
+func (h *SessionHandler) loadSession(c *gin.Context, rawID string, level accessLevel) (*models.Session, bool) {
+	userID := middleware.MustGetUserID(c)
+
+	sessionID, err := uuid.Parse(rawID)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_session_id", "Invalid session ID format"))
+		return nil, false
+	}
+
+	session, err := h.sessionRepo.GetByID(c.Request.Context(), sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			problem.Abort(c, problem.NotFound("session_not_found", "Session not found"))
+			return nil, false
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve session"))
+		return nil, false
+	}
+
+	if !h.orgs.authorize(c, userID, session.UserID, session.OrgID, level, "session") {
+		return nil, false
+	}
+
+	return session, true
+}
+
+// parseIntQuery parses an integer query parameter, returning the default when absent
+func parseIntQuery(c *gin.Context, key string, defaultValue int) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// parsePage parses the limit, cursor and offset query parameters of a listing keyed by UUIDs
+// Offsets predate cursors and are still accepted, but not together with a cursor.
+func parsePage(c *gin.Context, defaultLimit, maxLimit int) (pagination.Page, int, error) {
+	page, err := pagination.Parse(c.Request.URL.Query(), defaultLimit, maxLimit, pagination.UUID)
+	if err != nil {
+		return page, 0, err
+	}
+
+	offset, err := parseIntQuery(c, "offset", 0)
+	if err != nil || offset < 0 {
+		return page, 0, errors.New("offset must be a non-negative integer")
+	}
+	if offset > 0 && page.After != nil {
+		return page, 0, errors.New("cursor cannot be combined with offset")
+	}
+
+	return page, offset, nil
+}
@@ -0,0 +1,1065 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSessionTest() (*SessionHandler, *repository.MockSessionRepository, *repository.MockDeviceRepository) {
+	sessionRepo := repository.NewMockSessionRepository()
+	deviceRepo := repository.NewMockDeviceRepository()
+	handler := NewSessionHandler(sessionRepo, deviceRepo)
+
+	gin.SetMode(gin.TestMode)
+
+	return handler, sessionRepo, deviceRepo
+}
+
+func TestSessionHandler_CreateSession_Success(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	var created *models.Session
+	sessionRepo.CreateFunc = func(_ context.Context, session *models.Session) error {
+		created = session
+		return nil
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"deviceId": "RACEBOX-001",
+		"name":     "Morning practice",
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/sessions", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.CreateSession(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, created)
+	assert.Equal(t, "RACEBOX-001", created.DeviceID)
+	assert.Equal(t, userID, *created.UserID)
+	assert.Nil(t, created.EndedAt)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Morning practice", response["name"])
+	assert.Equal(t, true, response["isActive"])
+}
+
+func TestSessionHandler_CreateSession_MissingDeviceID(t *testing.T) {
+	handler, _, _ := setupSessionTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/sessions", bytes.NewBufferString(`{"name":"x"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.CreateSession(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSessionHandler_CreateSession_DeviceOwnedByOtherUser(t *testing.T) {
+	handler, _, deviceRepo := setupSessionTest()
+
+	deviceRepo.GetByDeviceIDFunc = func(_ context.Context, deviceID string) (*models.Device, error) {
+		return &models.Device{ID: uuid.New(), DeviceID: deviceID, UserID: uuid.New()}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/sessions", bytes.NewBufferString(`{"deviceId":"RACEBOX-001"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.CreateSession(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestSessionHandler_EndSession_Success(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, DeviceID: "RACEBOX-001", UserID: &userID, StartedAt: time.Now().Add(-time.Hour)}, nil
+	}
+
+	var endedID uuid.UUID
+	sessionRepo.EndFunc = func(_ context.Context, id uuid.UUID, _ time.Time) error {
+		endedID = id
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String()+"/end", nil)
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.EndSession(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, sessionID, endedID)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, false, response["isActive"])
+	assert.NotNil(t, response["endedAt"])
+}
+
+func TestSessionHandler_EndSession_AlreadyEnded(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now().Add(-time.Hour)}, nil
+	}
+	sessionRepo.EndFunc = func(_ context.Context, _ uuid.UUID, _ time.Time) error {
+		return repository.ErrSessionAlreadyEnded
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String()+"/end", nil)
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.EndSession(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestSessionHandler_EndSession_BeforeStart(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now()}, nil
+	}
+
+	body := `{"endedAt":"2020-01-01T00:00:00Z"}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String()+"/end", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.EndSession(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSessionHandler_UpdateSession_Success(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	oldName := "Session 1"
+	notes := "Wet track"
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &userID, Name: &oldName, Notes: &notes, StartedAt: time.Now().Add(-time.Hour)}, nil
+	}
+
+	var updated *models.Session
+	sessionRepo.UpdateDetailsFunc = func(_ context.Context, session *models.Session) error {
+		updated = session
+		return nil
+	}
+	sessionRepo.UpdateSummaryFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		refreshed := *updated
+		maxSpeed := 42.5
+		refreshed.MaxSpeed = &maxSpeed
+		return &refreshed, nil
+	}
+
+	body := `{"name":"  Qualifying  ","location":"Silverstone","notes":""}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String(), bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.UpdateSession(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, updated)
+	assert.Equal(t, "Qualifying", *updated.Name)
+	assert.Equal(t, "Silverstone", *updated.Location)
+	assert.Nil(t, updated.Notes)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Qualifying", response["name"])
+	assert.Equal(t, "Silverstone", response["location"])
+	assert.Equal(t, 42.5, response["maxSpeed"])
+}
+
+func TestSessionHandler_UpdateSession_PartialUpdate(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	name := "Session 1"
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &userID, Name: &name, StartedAt: time.Now().Add(-time.Hour)}, nil
+	}
+
+	var updated *models.Session
+	sessionRepo.UpdateDetailsFunc = func(_ context.Context, session *models.Session) error {
+		updated = session
+		return nil
+	}
+	sessionRepo.UpdateSummaryFunc = func(_ context.Context, _ uuid.UUID) (*models.Session, error) {
+		return nil, errors.New("database error")
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String(), bytes.NewBufferString(`{"notes":"Soft tyres"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.UpdateSession(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, updated)
+	assert.Equal(t, "Session 1", *updated.Name)
+	assert.Equal(t, "Soft tyres", *updated.Notes)
+}
+
+func TestSessionHandler_UpdateSession_Forbidden(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	ownerID := uuid.New()
+	sessionID := uuid.New()
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &ownerID, StartedAt: time.Now()}, nil
+	}
+	sessionRepo.UpdateDetailsFunc = func(_ context.Context, _ *models.Session) error {
+		t.Fatal("UpdateDetails should not be called")
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String(), bytes.NewBufferString(`{"name":"Mine"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.UpdateSession(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestSessionHandler_UpdateSession_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "empty body", body: `{}`},
+		{name: "name too long", body: `{"name":"` + strings.Repeat("a", 256) + `"}`},
+		{name: "invalid json", body: `{"name":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, sessionRepo, _ := setupSessionTest()
+
+			userID := uuid.New()
+			sessionID := uuid.New()
+			sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+				return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now()}, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String(), bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.UpdateSession(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestSessionHandler_UpdateSession_NotFound(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now()}, nil
+	}
+	sessionRepo.UpdateDetailsFunc = func(_ context.Context, _ *models.Session) error {
+		return repository.ErrSessionNotFound
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String(), bytes.NewBufferString(`{"name":"Race"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.UpdateSession(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSessionHandler_ListSessions(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	var gotLimit, gotOffset int
+	sessionRepo.ListAccessibleFunc = func(_ context.Context, filter repository.SessionFilter) ([]*models.Session, int, error) {
+		assert.Empty(t, filter.OrgIDs)
+		gotLimit, gotOffset = filter.Limit, filter.Offset
+		return []*models.Session{
+			{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: &filter.UserID, StartedAt: time.Now()},
+		}, 21, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions?limit=10&offset=20", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListSessions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 11, gotLimit, "one extra session detects the next page")
+	assert.Equal(t, 20, gotOffset)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(21), response["total"], "total counts every matching session, not the page")
+	assert.NotContains(t, response, "nextCursor")
+}
+
+func TestSessionHandler_ListSessions_Cursor(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sessions := make([]*models.Session, 3)
+	for i := range sessions {
+		sessions[i] = &models.Session{ID: uuid.New(), UserID: &userID, StartedAt: base.Add(-time.Duration(i) * time.Hour)}
+	}
+	var gotAfter *pagination.Cursor
+	sessionRepo.ListAccessibleFunc = func(_ context.Context, filter repository.SessionFilter) ([]*models.Session, int, error) {
+		gotAfter = filter.After
+		return sessions[:min(filter.Limit, len(sessions))], len(sessions), nil
+	}
+
+	list := func(query string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions?"+query, nil)
+		c.Set(string(middleware.UserIDKey), userID)
+		handler.ListSessions(c)
+
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	status, response := list("limit=2")
+	require.Equal(t, http.StatusOK, status)
+	assert.Len(t, response["sessions"], 2)
+	nextCursor, ok := response["nextCursor"].(string)
+	require.True(t, ok, "a full page links to the next one")
+
+	status, _ = list("limit=2&cursor=" + nextCursor)
+	require.Equal(t, http.StatusOK, status)
+	require.NotNil(t, gotAfter)
+	assert.Equal(t, sessions[1].ID.String(), gotAfter.ID)
+	assert.True(t, gotAfter.Time.Equal(sessions[1].StartedAt))
+
+	status, _ = list("cursor=" + nextCursor + "&offset=2")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// Telemetry cursors carry integer IDs and are rejected
+	status, _ = list("cursor=" + pagination.Cursor{Time: base, ID: "42"}.Encode())
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestSessionHandler_ListSessions_Range(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+	userRepo := repository.NewMockUserRepository()
+	userRepo.GetTimezoneFunc = func(_ context.Context, _ uuid.UUID) (string, error) {
+		return "America/New_York", nil
+	}
+	handler.WithTimezones(userRepo)
+
+	var got repository.SessionFilter
+	sessionRepo.ListAccessibleFunc = func(_ context.Context, filter repository.SessionFilter) ([]*models.Session, int, error) {
+		got = filter
+		return []*models.Session{}, 0, nil
+	}
+
+	list := func(query string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions?"+query, nil)
+		c.Set(string(middleware.UserIDKey), uuid.New())
+		handler.ListSessions(c)
+		return w.Code
+	}
+
+	// Dates are local to the profile's time zone and to covers its whole day
+	require.Equal(t, http.StatusOK, list("from=2024-03-09&to=2024-03-10"))
+	require.NotNil(t, got.StartedFrom)
+	require.NotNil(t, got.StartedBefore)
+	assert.True(t, got.StartedFrom.Equal(time.Date(2024, 3, 9, 5, 0, 0, 0, time.UTC)))
+	assert.True(t, got.StartedBefore.Equal(time.Date(2024, 3, 11, 4, 0, 0, 0, time.UTC)), "the range spans the DST change")
+
+	require.Equal(t, http.StatusOK, list("from=2024-03-09&timezone=Europe/Madrid"))
+	assert.True(t, got.StartedFrom.Equal(time.Date(2024, 3, 8, 23, 0, 0, 0, time.UTC)))
+	assert.Nil(t, got.StartedBefore)
+
+	// Timestamps are used as given
+	require.Equal(t, http.StatusOK, list("to=2024-03-09T12:00:00Z"))
+	assert.Nil(t, got.StartedFrom)
+	assert.True(t, got.StartedBefore.Equal(time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)))
+
+	assert.Equal(t, http.StatusBadRequest, list("from=03/09/2024"))
+	assert.Equal(t, http.StatusBadRequest, list("from=2024-03-10&to=2024-03-09"))
+	assert.Equal(t, http.StatusBadRequest, list("groupBy=week"))
+	assert.Equal(t, http.StatusBadRequest, list("timezone=PST"))
+}
+
+func TestSessionHandler_ListSessions_GroupByDay(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+	now := time.Now().In(madrid)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, madrid)
+	yesterday := today.AddDate(0, 0, -1)
+	sessions := []*models.Session{
+		{ID: uuid.New(), StartedAt: today},
+		{ID: uuid.New(), StartedAt: yesterday.Add(2 * time.Hour)},
+		{ID: uuid.New(), StartedAt: yesterday},
+		{ID: uuid.New(), StartedAt: today.AddDate(0, 0, -7)},
+	}
+	sessionRepo.ListAccessibleFunc = func(_ context.Context, _ repository.SessionFilter) ([]*models.Session, int, error) {
+		return sessions, len(sessions), nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions?groupBy=day&timezone=Europe/Madrid", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+	handler.ListSessions(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Days     []SessionDayGroup `json:"days"`
+		Total    int               `json:"total"`
+		Timezone string            `json:"timezone"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 4, response.Total)
+	assert.Equal(t, "Europe/Madrid", response.Timezone)
+	require.Len(t, response.Days, 3)
+	assert.Equal(t, today.Format(time.DateOnly), response.Days[0].Date)
+	assert.Equal(t, []int{0, 1, 7}, []int{response.Days[0].DaysAgo, response.Days[1].DaysAgo, response.Days[2].DaysAgo})
+	assert.Len(t, response.Days[1].Sessions, 2)
+}
+
+func TestGroupSessionsByDay_LocalDates(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 2, 1, 0, 0, 0, time.UTC) // 10:00 on May 2 in Tokyo
+
+	// 20:00 UTC on May 1 is already May 2 in Tokyo
+	groups := groupSessionsByDay([]*models.SessionResponse{
+		{StartedAt: time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)},
+		{StartedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+	}, tokyo, now)
+
+	require.Len(t, groups, 2)
+	assert.Equal(t, "2024-05-02", groups[0].Date)
+	assert.Equal(t, 0, groups[0].DaysAgo)
+	assert.Equal(t, "2024-05-01", groups[1].Date)
+	assert.Equal(t, 1, groups[1].DaysAgo)
+
+	assert.Empty(t, groupSessionsByDay(nil, tokyo, now))
+}
+
+func TestSessionHandler_ListSessions_InvalidLimit(t *testing.T) {
+	handler, _, _ := setupSessionTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions?limit=5000", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.ListSessions(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSessionHandler_GetSession(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	ownedID := uuid.New()
+	foreignID := uuid.New()
+
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		switch id {
+		case ownedID:
+			return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now()}, nil
+		case foreignID:
+			return &models.Session{ID: id, UserID: &otherUserID, StartedAt: time.Now()}, nil
+		}
+		return nil, repository.ErrSessionNotFound
+	}
+
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+	}{
+		{name: "owned session", id: ownedID.String(), expectedStatus: http.StatusOK},
+		{name: "other user's session", id: foreignID.String(), expectedStatus: http.StatusForbidden},
+		{name: "unknown session", id: uuid.New().String(), expectedStatus: http.StatusNotFound},
+		{name: "invalid id", id: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+tt.id, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.GetSession(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+type recordingSummarizer struct {
+	enqueued []uuid.UUID
+}
+
+func (r *recordingSummarizer) Enqueue(sessionID uuid.UUID) {
+	r.enqueued = append(r.enqueued, sessionID)
+}
+
+func TestSessionHandler_EndSession_EnqueuesSummary(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+	summarizer := &recordingSummarizer{}
+	postProcessor := &recordingSummarizer{}
+	handler = handler.WithSummarizer(summarizer).WithPostProcessor(postProcessor)
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now().Add(-time.Hour)}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String()+"/end", nil)
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.EndSession(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []uuid.UUID{sessionID}, summarizer.enqueued)
+	assert.Equal(t, []uuid.UUID{sessionID}, postProcessor.enqueued)
+}
+
+func TestSessionHandler_EndSession_PublishesEvent(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+	summarizer := &recordingSummarizer{}
+	publisher := &recordingPublisher{}
+	handler = handler.WithSummarizer(summarizer).WithEvents(publisher)
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, DeviceID: "AVT-001", UserID: &userID, StartedAt: time.Now().Add(-time.Hour)}, nil
+	}
+	sessionRepo.EndFunc = func(ctx context.Context, id uuid.UUID, _ time.Time) error {
+		storeEvents(t, publisher, ctx, []uuid.UUID{id})
+		return nil
+	}
+
+	endSession := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/sessions/"+sessionID.String()+"/end", nil)
+		c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+		c.Set(string(middleware.UserIDKey), userID)
+		handler.EndSession(c)
+		return w
+	}
+
+	w := endSession()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, summarizer.enqueued, "the summarizer subscribes to the event instead")
+	ended := decodeEvents[models.SessionEnded](t, publisher, models.EventSessionEnded)
+	require.Len(t, ended, 1)
+	assert.Equal(t, sessionID, ended[0].SessionID)
+	assert.Equal(t, "AVT-001", ended[0].DeviceID)
+	assert.Equal(t, &userID, ended[0].UserID)
+	assert.Equal(t, 1, publisher.notified)
+
+	// An end that is not stored stores no event either
+	sessionRepo.EndFunc = func(_ context.Context, _ uuid.UUID, _ time.Time) error {
+		return repository.ErrSessionAlreadyEnded
+	}
+	w = endSession()
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Len(t, publisher.events, 1)
+	assert.Equal(t, 1, publisher.notified)
+}
+
+func TestSessionHandler_GetSessionSummary(t *testing.T) {
+	userID := uuid.New()
+	endedAt := time.Now().Add(-time.Hour)
+	computedAt := time.Now()
+	distance := 12500.0
+	maxSpeed := 180.5
+
+	t.Run("uses cached summary when fresh", func(t *testing.T) {
+		handler, sessionRepo, _ := setupSessionTest()
+
+		sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			return &models.Session{
+				ID: id, UserID: &userID, StartedAt: endedAt.Add(-30 * time.Minute), EndedAt: &endedAt,
+				TotalDistance: &distance, MaxSpeed: &maxSpeed, DataPointsCount: 1800, SummaryComputedAt: &computedAt,
+			}, nil
+		}
+		sessionRepo.UpdateSummaryFunc = func(_ context.Context, _ uuid.UUID) (*models.Session, error) {
+			t.Fatal("fresh summary should not be recomputed")
+			return nil, nil
+		}
+
+		sessionID := uuid.New().String()
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID+"/summary", nil)
+		c.Params = gin.Params{{Key: "id", Value: sessionID}}
+		c.Set(string(middleware.UserIDKey), userID)
+
+		handler.GetSessionSummary(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, distance, response["totalDistance"])
+		assert.Equal(t, maxSpeed, response["maxSpeed"])
+		assert.Equal(t, float64(1800), response["dataPointsCount"])
+		assert.Equal(t, float64(1800), response["durationSeconds"])
+	})
+
+	t.Run("computes summary for active session", func(t *testing.T) {
+		handler, sessionRepo, _ := setupSessionTest()
+
+		sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now().Add(-time.Minute)}, nil
+		}
+		recomputed := false
+		sessionRepo.UpdateSummaryFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			recomputed = true
+			return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now().Add(-time.Minute), DataPointsCount: 60, SummaryComputedAt: &computedAt}, nil
+		}
+
+		sessionID := uuid.New().String()
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID+"/summary", nil)
+		c.Params = gin.Params{{Key: "id", Value: sessionID}}
+		c.Set(string(middleware.UserIDKey), userID)
+
+		handler.GetSessionSummary(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, recomputed)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, true, response["isActive"])
+		assert.Equal(t, float64(60), response["dataPointsCount"])
+	})
+}
+
+func TestSessionHandler_GetSessionStats(t *testing.T) {
+	userID := uuid.New()
+	endedAt := time.Now().Add(-time.Hour)
+	p50 := 92.5
+
+	request := func(handler *SessionHandler) *httptest.ResponseRecorder {
+		sessionID := uuid.New().String()
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID+"/stats", nil)
+		c.Params = gin.Params{{Key: "id", Value: sessionID}}
+		c.Set(string(middleware.UserIDKey), userID)
+		handler.GetSessionStats(c)
+		return w
+	}
+
+	t.Run("uses cached stats when fresh", func(t *testing.T) {
+		handler, sessionRepo, _ := setupSessionTest()
+
+		sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			return &models.Session{ID: id, UserID: &userID, StartedAt: endedAt.Add(-30 * time.Minute), EndedAt: &endedAt}, nil
+		}
+		sessionRepo.GetStatsFunc = func(_ context.Context, id uuid.UUID) (*models.SessionStats, error) {
+			return &models.SessionStats{SessionID: id, Samples: 1800, Speed: models.SpeedPercentiles{P50: &p50}, ComputedAt: time.Now()}, nil
+		}
+		sessionRepo.UpdateStatsFunc = func(_ context.Context, _ uuid.UUID) (*models.SessionStats, error) {
+			t.Fatal("fresh stats should not be recomputed")
+			return nil, nil
+		}
+
+		w := request(handler)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(1800), response["samples"])
+		assert.Equal(t, p50, response["speed"].(map[string]interface{})["p50"])
+	})
+
+	t.Run("computes missing and stale stats", func(t *testing.T) {
+		handler, sessionRepo, _ := setupSessionTest()
+
+		sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			return &models.Session{ID: id, UserID: &userID, StartedAt: endedAt.Add(-30 * time.Minute), EndedAt: &endedAt}, nil
+		}
+		recomputed := 0
+		sessionRepo.UpdateStatsFunc = func(_ context.Context, id uuid.UUID) (*models.SessionStats, error) {
+			recomputed++
+			return &models.SessionStats{SessionID: id, Samples: 10, ComputedAt: time.Now()}, nil
+		}
+
+		assert.Equal(t, http.StatusOK, request(handler).Code)
+
+		sessionRepo.GetStatsFunc = func(_ context.Context, id uuid.UUID) (*models.SessionStats, error) {
+			return &models.SessionStats{SessionID: id, ComputedAt: endedAt.Add(-time.Minute)}, nil
+		}
+		assert.Equal(t, http.StatusOK, request(handler).Code)
+		assert.Equal(t, 2, recomputed)
+	})
+
+	t.Run("lookup failure", func(t *testing.T) {
+		handler, sessionRepo, _ := setupSessionTest()
+
+		sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			return &models.Session{ID: id, UserID: &userID, StartedAt: endedAt}, nil
+		}
+		sessionRepo.GetStatsFunc = func(_ context.Context, _ uuid.UUID) (*models.SessionStats, error) {
+			return nil, errors.New("database unavailable")
+		}
+
+		w := request(handler)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestSessionHandler_ExportSession(t *testing.T) {
+	userID := uuid.New()
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		format          string
+		expectedStatus  int
+		expectedType    string
+		expectedContent string
+	}{
+		{name: "gpx", format: "gpx", expectedStatus: http.StatusOK, expectedType: "application/gpx+xml", expectedContent: "<trkpt lat=\"42.0000000\" lon=\"23.0000000\">"},
+		{name: "csv", format: "csv", expectedStatus: http.StatusOK, expectedType: "text/csv; charset=utf-8", expectedContent: "2024-01-10T08:00:00Z,42.0000000,23.0000000"},
+		{name: "unsupported format", format: "kml", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, sessionRepo, _ := setupSessionTest()
+			telemetryRepo := repository.NewMockRepository()
+			handler = handler.WithTelemetryRepo(telemetryRepo)
+
+			sessionID := uuid.New()
+			sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+				return &models.Session{ID: id, UserID: &userID, StartedAt: start}, nil
+			}
+
+			label := "Lights out"
+			sessionRepo.ListMarkersFunc = func(_ context.Context, id uuid.UUID) ([]*models.SessionMarker, error) {
+				return []*models.SessionMarker{{ID: uuid.New(), SessionID: id, Type: models.SessionMarkerSector, Label: &label, MarkedAt: start}}, nil
+			}
+
+			var iterator *repository.SliceTelemetryIterator
+			telemetryRepo.IterateBySessionFunc = func(_ context.Context, id string) (repository.TelemetryIterator, error) {
+				assert.Equal(t, sessionID.String(), id)
+				iterator = repository.NewSliceTelemetryIterator([]*models.TelemetryData{
+					{Timestamp: start, GPS: models.GpsData{Latitude: 42.0, Longitude: 23.0, IsFixValid: true}},
+				})
+				return iterator, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID.String()+"/export?format="+tt.format, nil)
+			c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.ExportSession(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tt.expectedType, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Header().Get("Content-Disposition"), "session-"+sessionID.String()+"."+tt.format)
+			assert.Contains(t, w.Body.String(), tt.expectedContent)
+			assert.Contains(t, w.Body.String(), label)
+			require.NotNil(t, iterator)
+			assert.True(t, iterator.Closed, "iterator should be closed")
+		})
+	}
+}
+
+func TestSessionHandler_GetSessionTrack(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name              string
+		query             string
+		geometry          json.RawMessage
+		expectedStatus    int
+		expectedTolerance float64
+	}{
+		{name: "default tolerance", geometry: json.RawMessage(`{"type":"LineString","coordinates":[[23,42],[23.001,42.001]]}`), expectedStatus: http.StatusOK, expectedTolerance: defaultTrackTolerance},
+		{name: "custom tolerance", query: "?tolerance=25", geometry: json.RawMessage(`{"type":"LineString","coordinates":[[23,42],[23.001,42.001]]}`), expectedStatus: http.StatusOK, expectedTolerance: 25},
+		{name: "no located points", expectedStatus: http.StatusOK, expectedTolerance: defaultTrackTolerance},
+		{name: "negative tolerance", query: "?tolerance=-1", expectedStatus: http.StatusBadRequest},
+		{name: "tolerance too large", query: "?tolerance=5000", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, sessionRepo, _ := setupSessionTest()
+			telemetryRepo := repository.NewMockRepository()
+			handler = handler.WithTelemetryRepo(telemetryRepo)
+
+			sessionID := uuid.New()
+			sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+				return &models.Session{ID: id, UserID: &userID, DeviceID: "RACEBOX-001", StartedAt: time.Now()}, nil
+			}
+
+			var capturedTolerance float64
+			telemetryRepo.SessionTrackFunc = func(_ context.Context, id string, tolerance float64) (*models.TrackGeometry, error) {
+				assert.Equal(t, sessionID.String(), id)
+				capturedTolerance = tolerance
+				return &models.TrackGeometry{Geometry: tt.geometry, Points: 120, SimplifiedPoints: 2}, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID.String()+"/track.geojson"+tt.query, nil)
+			c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.GetSessionTrack(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "application/geo+json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedTolerance, capturedTolerance)
+
+			var feature struct {
+				Type       string                 `json:"type"`
+				Geometry   json.RawMessage        `json:"geometry"`
+				Properties map[string]interface{} `json:"properties"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &feature))
+			assert.Equal(t, "Feature", feature.Type)
+			assert.Equal(t, "RACEBOX-001", feature.Properties["deviceId"])
+			assert.Equal(t, float64(120), feature.Properties["points"])
+			if tt.geometry == nil {
+				assert.Equal(t, "null", string(feature.Geometry))
+			} else {
+				assert.JSONEq(t, string(tt.geometry), string(feature.Geometry))
+			}
+		})
+	}
+}
+
+func TestSessionHandler_GetSessionLaps(t *testing.T) {
+	userID := uuid.New()
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	track := &models.Track{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      "Test Circuit",
+		LineStart: geo.Point{Latitude: 42.0, Longitude: 23.0},
+		LineEnd:   geo.Point{Latitude: 42.0002, Longitude: 23.0},
+	}
+
+	// Two eastbound crossings of the line 45 seconds apart
+	path := []struct {
+		offset   time.Duration
+		lat, lon float64
+	}{
+		{0, 42.0001, 22.9999},
+		{time.Second, 42.0001, 23.0001},
+		{15 * time.Second, 42.001, 23.0001},
+		{30 * time.Second, 42.001, 22.9999},
+		{45 * time.Second, 42.0001, 22.9999},
+		{46 * time.Second, 42.0001, 23.0001},
+	}
+	telemetry := make([]*models.TelemetryData, len(path))
+	for i, p := range path {
+		telemetry[i] = &models.TelemetryData{
+			Timestamp: start.Add(p.offset),
+			GPS:       models.GpsData{Latitude: p.lat, Longitude: p.lon, Speed: 50, IsFixValid: true},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		trackOwner     uuid.UUID
+		expectedStatus int
+		expectedLaps   int
+	}{
+		{name: "all user tracks", expectedStatus: http.StatusOK, expectedLaps: 1},
+		{name: "explicit track", query: "?trackId=" + track.ID.String(), expectedStatus: http.StatusOK, expectedLaps: 1},
+		{name: "track of another user", query: "?trackId=" + track.ID.String(), trackOwner: uuid.New(), expectedStatus: http.StatusForbidden},
+		{name: "unknown track", query: "?trackId=" + uuid.New().String(), expectedStatus: http.StatusNotFound},
+		{name: "invalid track id", query: "?trackId=spa", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, sessionRepo, _ := setupSessionTest()
+			telemetryRepo := repository.NewMockRepository()
+			trackRepo := repository.NewMockTrackRepository()
+			handler = handler.WithTelemetryRepo(telemetryRepo).WithTrackRepo(trackRepo)
+
+			owned := *track
+			if tt.trackOwner != uuid.Nil {
+				owned.UserID = tt.trackOwner
+			}
+			trackRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Track, error) {
+				if id == owned.ID {
+					return &owned, nil
+				}
+				return nil, repository.ErrTrackNotFound
+			}
+			trackRepo.ListByUserIDFunc = func(_ context.Context, id uuid.UUID) ([]*models.Track, error) {
+				assert.Equal(t, userID, id)
+				return []*models.Track{&owned}, nil
+			}
+
+			sessionID := uuid.New()
+			sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+				return &models.Session{ID: id, UserID: &userID, StartedAt: start}, nil
+			}
+
+			var iterator *repository.SliceTelemetryIterator
+			telemetryRepo.IterateBySessionFunc = func(_ context.Context, _ string) (repository.TelemetryIterator, error) {
+				iterator = repository.NewSliceTelemetryIterator(telemetry)
+				return iterator, nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID.String()+"/laps"+tt.query, nil)
+			c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.GetSessionLaps(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Track *models.Track `json:"track"`
+				Laps  []models.Lap  `json:"laps"`
+				Count int           `json:"count"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.NotNil(t, response.Track)
+			assert.Equal(t, track.ID, response.Track.ID)
+			require.Len(t, response.Laps, tt.expectedLaps)
+			assert.InDelta(t, 45, response.Laps[0].DurationSeconds, 0.01)
+			assert.True(t, response.Laps[0].IsBest)
+			require.NotNil(t, iterator)
+			assert.True(t, iterator.Closed, "iterator should be closed")
+		})
+	}
+}
+
+func TestSessionHandler_GetSessionLaps_NotConfigured(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+	userID := uuid.New()
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &userID}, nil
+	}
+
+	sessionID := uuid.New()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID.String()+"/laps", nil)
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.GetSessionLaps(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
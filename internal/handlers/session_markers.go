@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// CreateSessionMarkerRequest represents the request body adding a marker to a session
+type CreateSessionMarkerRequest struct {
+	Type      models.SessionMarkerType `json:"type" binding:"required"`
+	Timestamp time.Time                `json:"timestamp" binding:"required"`
+	Label     *string                  `json:"label,omitempty" binding:"omitempty,max=255"`
+}
+
+// CreateSessionMarker marks a pit stop, incident or sector split at a time within a session
+// POST /api/v1/sessions/:id/markers
+func (h *SessionHandler) CreateSessionMarker(c *gin.Context) {
+	session, ok := h.getSession(c, accessManage)
+	if !ok {
+		return
+	}
+
+	var req CreateSessionMarkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	if !req.Type.IsValid() {
+		problem.Abort(c, problem.BadRequest("invalid_marker_type", "type must be pit_stop, incident or sector"))
+		return
+	}
+
+	markedAt := req.Timestamp.UTC()
+	if markedAt.Before(session.StartedAt) || (session.EndedAt != nil && markedAt.After(*session.EndedAt)) {
+		problem.Abort(c, problem.BadRequest("timestamp_out_of_range", "timestamp must be within the session"))
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+	marker := &models.SessionMarker{
+		ID:        uuid.New(),
+		SessionID: session.ID,
+		UserID:    &userID,
+		Type:      req.Type,
+		MarkedAt:  markedAt,
+		CreatedAt: time.Now().UTC(),
+	}
+	if req.Label != nil {
+		marker.Label = optionalText(*req.Label)
+	}
+
+	if err := h.sessionRepo.AddMarker(c.Request.Context(), marker); err != nil {
+		problem.Abort(c, problem.Internal("Failed to add session marker"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, marker)
+}
+
+// ListSessionMarkers lists a session's markers in time order
+// GET /api/v1/sessions/:id/markers
+func (h *SessionHandler) ListSessionMarkers(c *gin.Context) {
+	session, ok := h.getSession(c, accessUse)
+	if !ok {
+		return
+	}
+
+	markers, err := h.sessionRepo.ListMarkers(c.Request.Context(), session.ID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve session markers"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "markers", markers, api.Meta{
+		"total": len(markers),
+	})
+}
+
+// DeleteSessionMarker removes a marker from a session
+// DELETE /api/v1/sessions/:id/markers/:markerId
+func (h *SessionHandler) DeleteSessionMarker(c *gin.Context) {
+	markerID, err := uuid.Parse(c.Param("markerId"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_marker_id", "Invalid marker ID format"))
+		return
+	}
+
+	session, ok := h.getSession(c, accessManage)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionRepo.DeleteMarker(c.Request.Context(), session.ID, markerID); err != nil {
+		if errors.Is(err, repository.ErrSessionMarkerNotFound) {
+			problem.Abort(c, problem.NotFound("marker_not_found", "Session marker not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to delete session marker"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Marker deleted successfully",
+	})
+}
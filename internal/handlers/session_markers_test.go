@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionHandler_CreateSessionMarker(t *testing.T) {
+	ownerID := uuid.New()
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		body           string
+		expectedStatus int
+	}{
+		{name: "pit stop", userID: ownerID, body: `{"type":"pit_stop","timestamp":"2024-01-10T08:30:00Z","label":" Tyre change "}`, expectedStatus: http.StatusCreated},
+		{name: "unknown type", userID: ownerID, body: `{"type":"lap","timestamp":"2024-01-10T08:30:00Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "missing timestamp", userID: ownerID, body: `{"type":"incident"}`, expectedStatus: http.StatusBadRequest},
+		{name: "before the session", userID: ownerID, body: `{"type":"incident","timestamp":"2024-01-10T07:59:59Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "after the session", userID: ownerID, body: `{"type":"incident","timestamp":"2024-01-10T09:00:01Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "another user's session", userID: uuid.New(), body: `{"type":"sector","timestamp":"2024-01-10T08:30:00Z"}`, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, sessionRepo, _ := setupSessionTest()
+
+			sessionID := uuid.New()
+			sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+				return &models.Session{ID: id, UserID: &ownerID, StartedAt: start, EndedAt: &end}, nil
+			}
+			var stored *models.SessionMarker
+			sessionRepo.AddMarkerFunc = func(_ context.Context, marker *models.SessionMarker) error {
+				stored = marker
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/sessions/"+sessionID.String()+"/markers", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+			c.Set(string(middleware.UserIDKey), tt.userID)
+
+			handler.CreateSessionMarker(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, stored)
+				return
+			}
+
+			require.NotNil(t, stored)
+			assert.Equal(t, sessionID, stored.SessionID)
+			assert.Equal(t, ownerID, *stored.UserID)
+			assert.Equal(t, models.SessionMarkerPitStop, stored.Type)
+			assert.Equal(t, "Tyre change", *stored.Label)
+			assert.Equal(t, start.Add(30*time.Minute), stored.MarkedAt)
+
+			var response models.SessionMarker
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, stored.ID, response.ID)
+		})
+	}
+}
+
+func TestSessionHandler_ListSessionMarkers(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now()}, nil
+	}
+	sessionRepo.ListMarkersFunc = func(_ context.Context, id uuid.UUID) ([]*models.SessionMarker, error) {
+		assert.Equal(t, sessionID, id)
+		return []*models.SessionMarker{
+			{ID: uuid.New(), SessionID: id, Type: models.SessionMarkerSector, MarkedAt: time.Now()},
+			{ID: uuid.New(), SessionID: id, Type: models.SessionMarkerIncident, MarkedAt: time.Now()},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID.String()+"/markers", nil)
+	c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListSessionMarkers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Markers []models.SessionMarker `json:"markers"`
+		Total   int                    `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Total)
+	require.Len(t, response.Markers, 2)
+	assert.Equal(t, models.SessionMarkerSector, response.Markers[0].Type)
+}
+
+func TestSessionHandler_DeleteSessionMarker(t *testing.T) {
+	userID := uuid.New()
+	markerID := uuid.New()
+
+	tests := []struct {
+		name           string
+		markerID       string
+		expectedStatus int
+	}{
+		{name: "deleted", markerID: markerID.String(), expectedStatus: http.StatusOK},
+		{name: "unknown marker", markerID: uuid.New().String(), expectedStatus: http.StatusNotFound},
+		{name: "invalid marker ID", markerID: "nope", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, sessionRepo, _ := setupSessionTest()
+
+			sessionID := uuid.New()
+			sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+				return &models.Session{ID: id, UserID: &userID, StartedAt: time.Now()}, nil
+			}
+			sessionRepo.DeleteMarkerFunc = func(_ context.Context, session, marker uuid.UUID) error {
+				assert.Equal(t, sessionID, session)
+				if marker == markerID {
+					return nil
+				}
+				return repository.ErrSessionMarkerNotFound
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/sessions/"+sessionID.String()+"/markers/"+tt.markerID, nil)
+			c.Params = gin.Params{{Key: "id", Value: sessionID.String()}, {Key: "markerId", Value: tt.markerID}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.DeleteSessionMarker(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/privacy"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Replay speed cap and the number of records read per query
+// Each page is read before it plays, so a replay only holds a database connection while reading one.
+const (
+	maxReplaySpeed = 100.0
+	replayPageSize = 500
+)
+
+// replayStart is the data of the first event of a session replay
+type replayStart struct {
+	Session *models.Session `json:"session"`
+	Speed   float64         `json:"speed"`
+}
+
+// replayEnd is the data of the final event of a session replay
+type replayEnd struct {
+	Count int `json:"count"` // Telemetry events sent by this connection
+}
+
+// ReplaySession streams a session's telemetry as Server-Sent Events, paced by the recorded timestamps
+// Events are "start" with the session, one "telemetry" event per record and "end" once all records
+// are sent, after which clients should close the connection. Telemetry events carry the record's
+// zero-based index as their id, so a reconnecting EventSource resumes after Last-Event-ID. The start
+// and end of the track are hidden as the owner's privacy trim or privacyTrim asks.
+// GET /api/v1/sessions/:id/replay?speed=&privacyTrim=
+func (h *SessionHandler) ReplaySession(c *gin.Context) {
+	speed := 1.0
+	if raw := c.Query("speed"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 || parsed > maxReplaySpeed {
+			problem.Abort(c, problem.BadRequest("invalid_request", fmt.Sprintf("speed must be greater than 0 and at most %g", maxReplaySpeed)))
+			return
+		}
+		speed = parsed
+	}
+
+	resumeAfter := -1
+	if raw := c.GetHeader("Last-Event-ID"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			problem.Abort(c, problem.BadRequest("invalid_request", "Last-Event-ID must be a telemetry event id"))
+			return
+		}
+		resumeAfter = parsed
+	}
+
+	session, ok := h.getSession(c, accessUse)
+	if !ok {
+		return
+	}
+
+	if h.telemetryRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("replay_unavailable", "Session replay is not configured"))
+		return
+	}
+
+	trim, ok := h.privacy.resolve(c, session)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	it, err := repository.IterateSessionPages(ctx, h.telemetryRepo, session.ID.String(), replayPageSize)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to replay session telemetry"))
+		return
+	}
+	defer func() {
+		if err := it.Close(); err != nil {
+			slog.Error("Error closing telemetry iterator", "session_id", session.ID, "error", err)
+		}
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Stop reverse proxies from buffering events
+	c.Status(http.StatusOK)
+
+	if err := writeEvent(c, "", "start", replayStart{Session: session, Speed: speed}); err != nil {
+		return
+	}
+
+	// Records are paced relative to the first one sent, so a resumed replay starts immediately
+	var (
+		first   time.Time
+		started time.Time
+		sent    int
+	)
+	trimmed := privacy.Trim(it, trim)
+	for index := 0; trimmed.Next(); index++ {
+		if index <= resumeAfter {
+			continue
+		}
+
+		record := trimmed.Telemetry()
+		if started.IsZero() {
+			first, started = record.Timestamp, time.Now()
+		} else {
+			due := started.Add(time.Duration(float64(record.Timestamp.Sub(first)) / speed))
+			if wait := time.Until(due); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+		}
+
+		if err := writeEvent(c, strconv.Itoa(index), "telemetry", record); err != nil {
+			return
+		}
+		sent++
+	}
+
+	// Headers are already sent, so a failure mid-stream can only be logged
+	if err := trimmed.Err(); err != nil {
+		slog.Error("Error replaying session", "session_id", session.ID, "error", err)
+		_ = c.Error(err)
+		return
+	}
+
+	_ = writeEvent(c, "", "end", replayEnd{Count: sent})
+}
+
+// writeEvent writes and flushes a single Server-Sent Event with a JSON data field
+func writeEvent(c *gin.Context, id, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event, err)
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(c.Writer, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
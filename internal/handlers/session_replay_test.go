@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+func TestSessionHandler_ReplaySession(t *testing.T) {
+	userID := uuid.New()
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	replay := func(query, lastEventID string) *httptest.ResponseRecorder {
+		handler, sessionRepo, _ := setupSessionTest()
+		telemetryRepo := repository.NewMockRepository()
+		handler = handler.WithTelemetryRepo(telemetryRepo)
+
+		sessionID := uuid.New()
+		sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			return &models.Session{ID: id, UserID: &userID, StartedAt: start}, nil
+		}
+		telemetryRepo.IterateSessionPageFunc = func(_ context.Context, _ string, after *pagination.Cursor, _ int) (repository.TelemetryIterator, error) {
+			if after != nil {
+				return repository.NewSliceTelemetryIterator(nil), nil
+			}
+			return repository.NewSliceTelemetryIterator([]*models.TelemetryData{
+				{Timestamp: start, GPS: models.GpsData{Latitude: 42.0, Longitude: 23.0}},
+				{Timestamp: start.Add(100 * time.Millisecond), GPS: models.GpsData{Latitude: 42.1, Longitude: 23.0}},
+				{Timestamp: start.Add(200 * time.Millisecond), GPS: models.GpsData{Latitude: 42.2, Longitude: 23.0}},
+			}), nil
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID.String()+"/replay"+query, nil)
+		if lastEventID != "" {
+			c.Request.Header.Set("Last-Event-ID", lastEventID)
+		}
+		c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+		c.Set(string(middleware.UserIDKey), userID)
+
+		handler.ReplaySession(c)
+		return w
+	}
+
+	t.Run("streams records paced by speed", func(t *testing.T) {
+		began := time.Now()
+		w := replay("?speed=10", "")
+		elapsed := time.Since(began)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.GreaterOrEqual(t, elapsed, 20*time.Millisecond)
+
+		body := w.Body.String()
+		assert.True(t, strings.HasPrefix(body, "event: start\n"))
+		assert.Contains(t, body, `"speed":10`)
+		assert.Contains(t, body, "id: 0\nevent: telemetry\n")
+		assert.Contains(t, body, "id: 2\nevent: telemetry\n")
+		assert.True(t, strings.HasSuffix(body, "event: end\ndata: {\"count\":3}\n\n"))
+	})
+
+	t.Run("resumes after last event id", func(t *testing.T) {
+		w := replay("?speed=100", "0")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.NotContains(t, body, "id: 0\n")
+		assert.Contains(t, body, "id: 1\nevent: telemetry\n")
+		assert.Contains(t, body, `"count":2`)
+	})
+
+	t.Run("closes each page before waiting", func(t *testing.T) {
+		handler, sessionRepo, _ := setupSessionTest()
+		telemetryRepo := repository.NewMockRepository()
+		handler = handler.WithTelemetryRepo(telemetryRepo)
+
+		sessionID := uuid.New()
+		sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+			return &models.Session{ID: id, UserID: &userID, StartedAt: start}, nil
+		}
+		var pages []*repository.SliceTelemetryIterator
+		telemetryRepo.IterateSessionPageFunc = func(_ context.Context, _ string, _ *pagination.Cursor, _ int) (repository.TelemetryIterator, error) {
+			// The second record is due an hour after the first, so the replay waits in between
+			page := repository.NewSliceTelemetryIterator([]*models.TelemetryData{
+				{ID: 1, Timestamp: start},
+				{ID: 2, Timestamp: start.Add(time.Hour)},
+			})
+			pages = append(pages, page)
+			return page, nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushed: make(chan struct{}, 8)}
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID.String()+"/replay", nil).WithContext(ctx)
+		c.Params = gin.Params{{Key: "id", Value: sessionID.String()}}
+		c.Set(string(middleware.UserIDKey), userID)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.ReplaySession(c)
+		}()
+
+		// The start event and the first record are flushed before the replay waits for the second
+		for range 2 {
+			select {
+			case <-w.flushed:
+			case <-time.After(5 * time.Second):
+				t.Fatal("replay did not send the first record")
+			}
+		}
+		require.Len(t, pages, 1)
+		assert.True(t, pages[0].Closed, "page must be closed while the replay waits")
+
+		cancel()
+		<-done
+	})
+
+	t.Run("rejects invalid speed", func(t *testing.T) {
+		for _, query := range []string{"?speed=0", "?speed=fast", "?speed=500"} {
+			w := replay(query, "")
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
+
+// flushRecorder is a ResponseRecorder that signals every flush
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed chan struct{}
+}
+
+// Flush records the flush and signals it
+func (r *flushRecorder) Flush() {
+	r.ResponseRecorder.Flush()
+	r.flushed <- struct{}{}
+}
@@ -2,23 +2,82 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/ingest"
 	"github.com/sebasr/avt-service/internal/middleware"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/sebasr/avt-service/internal/problem"
 	"github.com/sebasr/avt-service/internal/repository"
 )
 
+// GeofenceEvaluator schedules background geofence evaluation of saved telemetry
+type GeofenceEvaluator interface {
+	Enqueue(records []*models.TelemetryData)
+}
+
+// HealthMonitor schedules background device health tracking of saved telemetry and heartbeats
+type HealthMonitor interface {
+	Enqueue(records []*models.TelemetryData)
+	EnqueueHeartbeat(heartbeat *models.DeviceHeartbeat)
+}
+
+// AlertEvaluator schedules background alert rule evaluation of saved telemetry
+type AlertEvaluator interface {
+	Enqueue(records []*models.TelemetryData)
+}
+
+// TelemetryWriter accepts telemetry for a deferred, batched write
+type TelemetryWriter interface {
+	Enqueue(records []*models.TelemetryData) error
+}
+
+// errDeviceClaimedByOther is returned when an upload targets a device owned by a different user
+var errDeviceClaimedByOther = errors.New("device is claimed by another user")
+
+// defaultMaxBatchRecords caps batch uploads when no limit is configured
+const defaultMaxBatchRecords = 1000
+
+// bufferRetryAfter is the Retry-After hint, in seconds, when the write-behind buffer is full and
+// no pressure monitor estimates how long it takes to drain
+const bufferRetryAfter = "1"
+
 // TelemetryHandler handles telemetry-related HTTP requests
 type TelemetryHandler struct {
-	repo       repository.TelemetryRepository
-	deviceRepo repository.DeviceRepository
+	repo           repository.TelemetryRepository
+	deviceRepo     repository.DeviceRepository
+	geofences      GeofenceEvaluator                    // Optional: nil disables geofence evaluation on ingest
+	health         HealthMonitor                        // Optional: nil disables device health tracking on ingest
+	alerts         AlertEvaluator                       // Optional: nil disables alert rule evaluation on ingest
+	presence       DevicePresence                       // Optional: nil disables device online/offline tracking on ingest
+	writer         TelemetryWriter                      // Optional: when set, uploads are queued instead of written synchronously
+	pressure       IngestPressure                       // Optional: nil only sheds load when the write-behind buffer is full
+	uploads        repository.UploadRepository          // Optional: nil disables resumable uploads
+	ownershipRepo  repository.DeviceOwnershipRepository // Optional: nil leaves device claims out of the ownership history
+	uploadNotifier UploadNotifier                       // Optional: nil leaves finalized uploads to the processor's polling
+	archive        TelemetryArchive                     // Optional: nil disables archived telemetry queries
+	quotas         *Quotas                              // Optional: nil disables plan limits
+	orgs           *orgAccess                           // Optional: nil limits uploads to personally owned devices
+	units          *unitPreferences                     // Optional: nil ignores profile units preferences
+	clock          *ingest.ClockPolicy                  // Optional: nil leaves clock checks to the repository
+	events         EventPublisher                       // Optional: nil publishes no ingest or device claim events
+	eventTelemetry bool                                 // Include the stored records in telemetry.batch_saved events
+	strict         bool                                 // Reject anonymous uploads
+	lenient        bool                                 // Validate only timestamps and coordinates
+	maxBatch       int                                  // Maximum records per batch upload
 }
 
 // NewTelemetryHandler creates a new telemetry handler with the given repository
@@ -26,57 +85,268 @@ func NewTelemetryHandler(repo repository.TelemetryRepository, deviceRepo reposit
 	return &TelemetryHandler{
 		repo:       repo,
 		deviceRepo: deviceRepo,
+		maxBatch:   defaultMaxBatchRecords,
+	}
+}
+
+// WithWriter sets a write-behind writer for uploads
+// Uploads are then acknowledged with 202 Accepted once queued, without record IDs.
+func (h *TelemetryHandler) WithWriter(writer TelemetryWriter) *TelemetryHandler {
+	h.writer = writer
+	return h
+}
+
+// WithEvents publishes telemetry.batch_saved events for stored uploads and device.claimed events
+// for devices claimed by their first upload
+// Queued uploads are published by the writer once they are written.
+func (h *TelemetryHandler) WithEvents(publisher EventPublisher) *TelemetryHandler {
+	h.events = publisher
+	return h
+}
+
+// WithEventTelemetry includes the stored records in telemetry.batch_saved events, for subscribers
+// that forward the telemetry itself
+func (h *TelemetryHandler) WithEventTelemetry() *TelemetryHandler {
+	h.eventTelemetry = true
+	return h
+}
+
+// WithOwnershipHistory records devices claimed by their first upload in the ownership history
+func (h *TelemetryHandler) WithOwnershipHistory(ownershipRepo repository.DeviceOwnershipRepository) *TelemetryHandler {
+	h.ownershipRepo = ownershipRepo
+	return h
+}
+
+// WithStrictOwnership requires uploads to authenticate with a JWT or device API key
+// Anonymous uploads, which are stored without an owner, are then rejected with 401.
+func (h *TelemetryHandler) WithStrictOwnership(strict bool) *TelemetryHandler {
+	h.strict = strict
+	return h
+}
+
+// WithMaxBatchRecords sets the maximum number of records accepted in one batch upload
+func (h *TelemetryHandler) WithMaxBatchRecords(n int) *TelemetryHandler {
+	h.maxBatch = n
+	return h
+}
+
+// WithLenientValidation accepts records whose only problems are out-of-range sensor readings
+// Lenient validation still requires a timestamp and valid coordinates.
+func (h *TelemetryHandler) WithLenientValidation(lenient bool) *TelemetryHandler {
+	h.lenient = lenient
+	return h
+}
+
+// validate checks an uploaded record at the configured strictness
+func (h *TelemetryHandler) validate(telemetry *models.TelemetryData) error {
+	if h.lenient {
+		return telemetry.ValidateBasic()
+	}
+	return telemetry.Validate()
+}
+
+// validationFailed describes a record that failed validation, listing its invalid fields
+func validationFailed(detail string, err error) *problem.Error {
+	return problem.BadRequest("validation_failed", detail+": "+err.Error()).With("fields", invalidFields(err))
+}
+
+// invalidFields returns the invalid fields reported by a validation error, or nil for other errors
+func invalidFields(err error) []models.FieldError {
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields
+	}
+	return nil
+}
+
+// WithQuotas enforces plan limits on authenticated uploads and device claiming
+// Uploads over the monthly telemetry quota are rejected with 429 and new devices over the limit with 402.
+func (h *TelemetryHandler) WithQuotas(quotas *Quotas) *TelemetryHandler {
+	h.quotas = quotas
+	return h
+}
+
+// WithOrganizations lets organization members upload telemetry for shared devices
+func (h *TelemetryHandler) WithOrganizations(orgRepo repository.OrganizationRepository) *TelemetryHandler {
+	h.orgs = newOrgAccess(orgRepo)
+	return h
+}
+
+// WithUnitPreferences converts query results to the units preference in each user's profile
+func (h *TelemetryHandler) WithUnitPreferences(userRepo repository.UserRepository) *TelemetryHandler {
+	h.units = newUnitPreferences(userRepo)
+	return h
+}
+
+// WithGeofenceEvaluator sets the evaluator that checks ingested telemetry against geofences
+func (h *TelemetryHandler) WithGeofenceEvaluator(evaluator GeofenceEvaluator) *TelemetryHandler {
+	h.geofences = evaluator
+	return h
+}
+
+// WithHealthMonitor sets the monitor that tracks device battery and GPS fix health from ingested telemetry
+func (h *TelemetryHandler) WithHealthMonitor(monitor HealthMonitor) *TelemetryHandler {
+	h.health = monitor
+	return h
+}
+
+// WithAlertEvaluator sets the evaluator that checks ingested telemetry against speed and g-force alert rules
+func (h *TelemetryHandler) WithAlertEvaluator(evaluator AlertEvaluator) *TelemetryHandler {
+	h.alerts = evaluator
+	return h
+}
+
+// WithPresence sets the tracker notified when uploads refresh a device's last-seen time
+func (h *TelemetryHandler) WithPresence(presence DevicePresence) *TelemetryHandler {
+	h.presence = presence
+	return h
+}
+
+// WithClockPolicy checks device clocks against GPS and receive time as uploads arrive
+// Checking on receipt rather than on write keeps the write-behind buffer's delay out of the receive
+// time and hands corrected timestamps to the geofence evaluator and health monitor.
+func (h *TelemetryHandler) WithClockPolicy(policy *ingest.ClockPolicy) *TelemetryHandler {
+	h.clock = policy
+	return h
+}
+
+// checkClocks applies the clock policy, if any, to records that were just received
+func (h *TelemetryHandler) checkClocks(records []*models.TelemetryData) {
+	if h.clock != nil {
+		h.clock.Apply(records, time.Now())
 	}
 }
 
+// enqueueIngested hands accepted telemetry to the configured background consumers
+func (h *TelemetryHandler) enqueueIngested(records []*models.TelemetryData) {
+	if h.geofences != nil {
+		h.geofences.Enqueue(records)
+	}
+	if h.health != nil {
+		h.health.Enqueue(records)
+	}
+	if h.alerts != nil {
+		h.alerts.Enqueue(records)
+	}
+}
+
+// withBatchSaved returns a context under which saving telemetry stores its telemetry.batch_saved
+// events in the same transaction
+func (h *TelemetryHandler) withBatchSaved(ctx context.Context) context.Context {
+	return withEvents(ctx, h.events, func(stored []*models.TelemetryData) ([]*models.Event, error) {
+		return models.NewTelemetryBatchSavedEvents(stored, h.eventTelemetry)
+	})
+}
+
 // HandlePost handles incoming telemetry data from RaceBox devices
 func (h *TelemetryHandler) HandlePost(c *gin.Context) {
-	var telemetry models.TelemetryData
+	if h.shedLoad(c) {
+		return
+	}
+
+	var raw json.RawMessage
 
 	// Parse JSON body
-	if err := c.ShouldBindJSON(&telemetry); err != nil {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON payload",
-		})
+	if err := c.ShouldBindJSON(&raw); err != nil {
+		if limit, ok := middleware.BodyTooLarge(err); ok {
+			middleware.RespondBodyTooLarge(c, limit)
+			return
+		}
+		problem.Abort(c, problem.BadRequest("invalid_json", "Invalid JSON payload"))
 		return
 	}
 
+	// Decode with the record's schema version, keeping channels it does not define as extras
+	decoded, err := decodeTelemetry(raw)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_json", "Invalid JSON payload: "+err.Error()))
+		return
+	}
+	telemetry := *decoded
+	middleware.SetIngestDetails(c, telemetry.DeviceID, 1)
+
 	// Validate telemetry data
-	if err := telemetry.Validate(); err != nil {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"error":   "Validation failed",
-			"details": err.Error(),
-		})
+	if err := h.validate(&telemetry); err != nil {
+		problem.Abort(c, validationFailed("Validation failed", err))
+		return
+	}
+
+	// Device key uploads may only write telemetry for the key's device
+	if !applyDeviceKeyScope(c, &telemetry) {
+		problem.Abort(c, problem.Forbidden("device_key_mismatch", "Device key does not match deviceId"))
 		return
 	}
 
 	// Extract user ID from context (if authenticated)
 	userID, err := middleware.GetUserID(c)
-	if err == nil && h.deviceRepo != nil {
+	authenticated := err == nil
+	if !authenticated && h.strict {
+		rejectAnonymousUpload(c)
+		return
+	}
+	if authenticated && !h.allowTelemetry(c, userID, 1) {
+		return
+	}
+	if authenticated && h.deviceRepo != nil {
 		// User is authenticated and device repo is available - handle device claiming and association
 		if err := h.handleDeviceClaiming(c, &telemetry, userID); err != nil {
-			log.Printf("Error handling device claiming: %v", err)
-			c.PureJSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to process device claiming",
-			})
+			rejectClaimingError(c, err)
 			return
 		}
 	}
 
-	// Save to database
-	if err := h.repo.Save(c.Request.Context(), &telemetry); err != nil {
-		log.Printf("Error saving telemetry to database: %v", err)
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to save telemetry data",
+	h.checkClocks([]*models.TelemetryData{&telemetry})
+
+	// Queue for a batched write when buffering is enabled
+	if h.writer != nil {
+		if err := h.writer.Enqueue([]*models.TelemetryData{&telemetry}); err != nil {
+			h.rejectBufferedUpload(c, err)
+			return
+		}
+		h.enqueueIngested([]*models.TelemetryData{&telemetry})
+		if authenticated {
+			h.recordUsage(c, userID, 1)
+		}
+		api.Respond(c, http.StatusAccepted, gin.H{
+			"message":   "Telemetry data accepted",
+			"timestamp": telemetry.Timestamp,
 		})
 		return
 	}
 
-	// Log the telemetry data to console
-	logTelemetry(telemetry)
+	// Save to database
+	if err := h.repo.Save(h.withBatchSaved(c.Request.Context()), &telemetry); err != nil {
+		slog.Error("Error saving telemetry to database", "error", err)
+		problem.Abort(c, problem.Internal("Failed to save telemetry data"))
+		return
+	}
+
+	// Retried uploads of an already stored record are acknowledged without storing it again
+	// Replays of a clientId also report the stored record's ID.
+	if telemetry.Duplicate {
+		response := gin.H{
+			"message":   "Duplicate telemetry data skipped",
+			"timestamp": telemetry.Timestamp,
+			"duplicate": true,
+		}
+		if telemetry.ID != 0 {
+			response["id"] = telemetry.ID
+		}
+		api.Respond(c, http.StatusOK, response)
+		return
+	}
+
+	h.enqueueIngested([]*models.TelemetryData{&telemetry})
+	notifyEvents(h.events)
+	if authenticated {
+		h.recordUsage(c, userID, 1)
+	}
+
+	// Log the telemetry data at debug level
+	logTelemetry(c.Request.Context(), telemetry)
 
 	// Return success response
-	c.PureJSON(http.StatusCreated, gin.H{
+	api.Respond(c, http.StatusCreated, gin.H{
 		"message":   "Telemetry data received successfully",
 		"timestamp": telemetry.Timestamp,
 		"id":        telemetry.ID,
@@ -84,54 +354,88 @@ func (h *TelemetryHandler) HandlePost(c *gin.Context) {
 }
 
 // HandleBatchPost handles incoming batch telemetry data from RaceBox devices
+// A batch is stored all or nothing unless partial acceptance is requested with ?partial=true
+// or the X-Partial-Batch header, see handlePartialBatch.
 func (h *TelemetryHandler) HandleBatchPost(c *gin.Context) {
-	var telemetryBatch []models.TelemetryData
+	if h.shedLoad(c) {
+		return
+	}
 
-	// Parse JSON body
-	if err := c.ShouldBindJSON(&telemetryBatch); err != nil {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid JSON payload",
-			"details": err.Error(),
-		})
+	// Parse JSON body, bounding the bytes read by the records it may hold
+	body := c.Request.Body
+	if body == nil {
+		body = http.NoBody
+	}
+	body = http.MaxBytesReader(c.Writer, body, int64(h.maxBatch)*maxBatchRecordSize)
+	raws, err := readTelemetryBatch(body, h.maxBatch)
+	if err != nil {
+		if limit, ok := middleware.BodyTooLarge(err); ok {
+			middleware.RespondBodyTooLarge(c, limit)
+			return
+		}
+		switch {
+		case errors.Is(err, errBatchTooLarge):
+			problem.Abort(c, problem.BadRequest("batch_too_large", fmt.Sprintf("Batch too large (max %d records)", h.maxBatch)))
+		case errors.Is(err, errBatchRecordTooLarge):
+			problem.Abort(c, problem.BadRequest("record_too_large", err.Error()))
+		default:
+			problem.Abort(c, problem.BadRequest("invalid_json", "Invalid JSON payload: "+err.Error()))
+		}
 		return
 	}
 
+	if partialBatchRequested(c) {
+		h.handlePartialBatch(c, raws)
+		return
+	}
+
+	telemetryBatch, err := decodeTelemetryBatch(raws)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_json", "Invalid JSON payload: "+err.Error()))
+		return
+	}
+
+	if len(telemetryBatch) > 0 {
+		middleware.SetIngestDetails(c, telemetryBatch[0].DeviceID, len(telemetryBatch))
+	}
+
 	// Validate batch size
 	if len(telemetryBatch) == 0 {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"error": "Empty batch",
-		})
+		problem.Abort(c, problem.BadRequest("empty_batch", "Empty batch"))
 		return
 	}
 
-	if len(telemetryBatch) > 1000 {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"error": "Batch too large (max 1000 records)",
-		})
+	// Validate each telemetry record
+	if i, err := forEachRecord(len(telemetryBatch), func(i int) error {
+		return h.validate(telemetryBatch[i])
+	}); err != nil {
+		problem.Abort(c, validationFailed(fmt.Sprintf("Validation failed for record %d", i), err).With("record", i))
 		return
 	}
 
-	// Validate each telemetry record
-	for i, telemetry := range telemetryBatch {
-		if err := telemetry.Validate(); err != nil {
-			c.PureJSON(http.StatusBadRequest, gin.H{
-				"error":   fmt.Sprintf("Validation failed for record %d", i),
-				"details": err.Error(),
-			})
+	// Device key uploads may only write telemetry for the key's device
+	for i := range telemetryBatch {
+		if !applyDeviceKeyScope(c, telemetryBatch[i]) {
+			problem.Abort(c, problem.Forbidden("device_key_mismatch", fmt.Sprintf("Device key does not match deviceId for record %d", i)).With("record", i))
 			return
 		}
 	}
 
 	// Extract user ID from context (if authenticated)
 	userID, err := middleware.GetUserID(c)
-	if err == nil && h.deviceRepo != nil {
+	authenticated := err == nil
+	if !authenticated && h.strict {
+		rejectAnonymousUpload(c)
+		return
+	}
+	if authenticated && !h.allowTelemetry(c, userID, len(telemetryBatch)) {
+		return
+	}
+	if authenticated && h.deviceRepo != nil {
 		// User is authenticated and device repo is available - handle device claiming for first record
 		if len(telemetryBatch) > 0 {
-			if err := h.handleDeviceClaiming(c, &telemetryBatch[0], userID); err != nil {
-				log.Printf("Error handling device claiming: %v", err)
-				c.PureJSON(http.StatusInternalServerError, gin.H{
-					"error": "Failed to process device claiming",
-				})
+			if err := h.handleDeviceClaiming(c, telemetryBatch[0], userID); err != nil {
+				rejectClaimingError(c, err)
 				return
 			}
 
@@ -142,41 +446,319 @@ func (h *TelemetryHandler) HandleBatchPost(c *gin.Context) {
 		}
 	}
 
-	// Convert to pointers for SaveBatch
-	telemetryPointers := make([]*models.TelemetryData, len(telemetryBatch))
-	for i := range telemetryBatch {
-		telemetryPointers[i] = &telemetryBatch[i]
+	h.checkClocks(telemetryBatch)
+
+	// Queue for a batched write when buffering is enabled
+	if h.writer != nil {
+		if err := h.writer.Enqueue(telemetryBatch); err != nil {
+			h.rejectBufferedUpload(c, err)
+			return
+		}
+		h.enqueueIngested(telemetryBatch)
+		if authenticated {
+			h.recordUsage(c, userID, len(telemetryBatch))
+		}
+		api.Respond(c, http.StatusAccepted, gin.H{
+			"message": fmt.Sprintf("Batch telemetry data accepted (%d records)", len(telemetryBatch)),
+			"count":   len(telemetryBatch),
+		})
+		return
 	}
 
 	// Save batch to database
-	if err := h.repo.SaveBatch(c.Request.Context(), telemetryPointers); err != nil {
-		log.Printf("Error saving telemetry batch to database: %v", err)
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to save telemetry batch",
-		})
+	if err := h.repo.SaveBatch(h.withBatchSaved(c.Request.Context()), telemetryBatch); err != nil {
+		slog.Error("Error saving telemetry batch to database", "error", err)
+		problem.Abort(c, problem.Internal("Failed to save telemetry batch"))
 		return
 	}
 
-	// Collect IDs of saved records
-	savedIDs := make([]int64, len(telemetryBatch))
+	h.enqueueIngested(telemetryBatch)
+	notifyEvents(h.events)
+
+	// Collect IDs of saved records; duplicates skipped by the repository have none
+	savedIDs := make([]int64, 0, len(telemetryBatch))
 	for i, telemetry := range telemetryBatch {
-		savedIDs[i] = telemetry.ID
+		if !telemetry.Duplicate {
+			savedIDs = append(savedIDs, telemetry.ID)
+		}
 		// Log first and last records only to avoid spam
 		if i == 0 || i == len(telemetryBatch)-1 {
-			logTelemetry(telemetry)
+			logTelemetry(c.Request.Context(), *telemetry)
 		}
 	}
+	skipped := len(telemetryBatch) - len(savedIDs)
+	if authenticated {
+		h.recordUsage(c, userID, len(savedIDs))
+	}
 
-	log.Printf("Batch telemetry: Saved %d records", len(telemetryBatch))
+	slog.Info("Batch telemetry saved", "inserted", len(savedIDs), "skipped", skipped)
 
 	// Return success response with IDs
-	c.PureJSON(http.StatusCreated, gin.H{
-		"message": fmt.Sprintf("Batch telemetry data received successfully (%d records)", len(telemetryBatch)),
-		"count":   len(telemetryBatch),
-		"ids":     savedIDs,
+	api.Respond(c, http.StatusCreated, gin.H{
+		"message":  fmt.Sprintf("Batch telemetry data received successfully (%d records)", len(telemetryBatch)),
+		"count":    len(telemetryBatch),
+		"inserted": len(savedIDs),
+		"skipped":  skipped,
+		"ids":      savedIDs,
 	})
 }
 
+// rejectAnonymousUpload responds 401 to uploads without credentials in strict ownership mode
+func rejectAnonymousUpload(c *gin.Context) {
+	problem.Abort(c, problem.Unauthorized("unauthorized", "Authentication required: provide a bearer token or "+middleware.DeviceKeyHeader))
+}
+
+// rejectClaimingError responds 403 for devices owned by someone else, 402 over the plan's
+// device limit and 500 otherwise
+func rejectClaimingError(c *gin.Context, err error) {
+	if errors.Is(err, errDeviceClaimedByOther) {
+		problem.Abort(c, problem.Forbidden("device_claimed", "Device is claimed by another user"))
+		return
+	}
+	if errors.Is(err, errDeviceLimitReached) {
+		problem.Abort(c, problem.New(http.StatusPaymentRequired, "device_limit_reached", "Device limit reached for your plan"))
+		return
+	}
+	slog.Error("Error handling device claiming", "error", err)
+	problem.Abort(c, problem.Internal("Failed to process device claiming"))
+}
+
+// allowTelemetry checks n more points against the user's quota, responding 429 when exceeded
+func (h *TelemetryHandler) allowTelemetry(c *gin.Context, userID uuid.UUID, n int) bool {
+	if h.quotas == nil {
+		return true
+	}
+	usage, ok := h.quotas.allowTelemetry(c.Request.Context(), userID, n)
+	if !ok {
+		rejectTelemetryQuota(c, usage, h.quotas.now())
+	}
+	return ok
+}
+
+// recordUsage counts stored telemetry points against the user's quota
+func (h *TelemetryHandler) recordUsage(c *gin.Context, userID uuid.UUID, n int) {
+	if h.quotas != nil {
+		h.quotas.recordTelemetry(c.Request.Context(), userID, n)
+	}
+}
+
+// rejectBufferedUpload responds 503 when the write-behind buffer cannot take an upload
+func (h *TelemetryHandler) rejectBufferedUpload(c *gin.Context, err error) {
+	slog.Warn("Rejecting telemetry upload", "error", err)
+	c.Header("Retry-After", h.retryAfter())
+	problem.Abort(c, problem.ServiceUnavailable("ingest_busy", "Telemetry ingest is busy, retry later"))
+}
+
+// Default and maximum page sizes for telemetry queries
+const (
+	defaultTelemetryQueryLimit     = 100
+	maxTelemetryQueryLimit         = 1000
+	defaultTelemetryAggregateLimit = 1000
+	maxTelemetryAggregateLimit     = 10000
+)
+
+// HandleQuery retrieves the authenticated user's telemetry with filtering and cursor pagination
+// With points, the whole range is downsampled to that many representative records instead of paged.
+// GET /api/v1/telemetry?deviceId=&sessionId=&tag=&from=&to=&limit=&cursor=&points=&quality=&processed=&units=
+func (h *TelemetryHandler) HandleQuery(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	filter, err := parseTelemetryFilter(c, defaultTelemetryQueryLimit, maxTelemetryQueryLimit)
+	var points int
+	if err == nil {
+		points, err = parseDownsamplePoints(c)
+	}
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+	filter.UserID = userID
+
+	system, ok := h.units.resolve(c)
+	if !ok {
+		return
+	}
+
+	// Fetch one extra row to detect whether another page exists
+	pageSize := filter.Limit
+	if points > 0 {
+		pageSize = maxDownsampleSourceRows
+	}
+	filter.Limit = pageSize + 1
+
+	results, err := h.repo.Query(c.Request.Context(), filter)
+	if err != nil {
+		slog.Error("Error querying telemetry", "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve telemetry"))
+		return
+	}
+
+	meta := api.Meta{}
+	truncated := len(results) > pageSize
+	if truncated {
+		results = results[:pageSize]
+	}
+	switch {
+	case points > 0:
+		// Ranges over the cap keep their newest rows
+		meta["sourceCount"] = len(results)
+		meta["truncated"] = truncated
+		results = downsampleTelemetry(results, points)
+	case truncated:
+		last := results[len(results)-1]
+		meta["nextCursor"] = telemetryCursor(last).Encode()
+	}
+
+	if results == nil {
+		results = []*models.TelemetryData{}
+	}
+
+	convertTelemetry(system, results)
+	meta["count"] = len(results)
+	meta["units"] = system.Labels()
+
+	api.List(c, http.StatusOK, "telemetry", results, meta)
+}
+
+// HandleAggregate retrieves the authenticated user's downsampled telemetry for charts
+// GET /api/v1/telemetry/aggregate?bucket=1s|1m|10m&deviceId=&sessionId=&tag=&from=&to=&limit=&quality=&processed=&units=
+func (h *TelemetryHandler) HandleAggregate(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	bucket := repository.AggregateBucket(c.DefaultQuery("bucket", string(repository.Bucket1m)))
+	if !bucket.IsValid() {
+		problem.Abort(c, problem.BadRequest("invalid_request", "bucket must be one of: 1s, 1m, 10m"))
+		return
+	}
+
+	filter, err := parseTelemetryFilter(c, defaultTelemetryAggregateLimit, maxTelemetryAggregateLimit)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+	filter.UserID = userID
+
+	system, ok := h.units.resolve(c)
+	if !ok {
+		return
+	}
+
+	// Fetch one extra bucket to detect truncation
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	buckets, err := h.repo.Aggregate(c.Request.Context(), filter, bucket)
+	if err != nil {
+		slog.Error("Error aggregating telemetry", "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve telemetry aggregates"))
+		return
+	}
+
+	truncated := len(buckets) > limit
+	if truncated {
+		buckets = buckets[:limit]
+	}
+	if buckets == nil {
+		buckets = []*models.TelemetryBucket{}
+	}
+	convertBuckets(system, buckets)
+
+	api.List(c, http.StatusOK, "buckets", buckets, api.Meta{
+		"bucket":    bucket,
+		"count":     len(buckets),
+		"truncated": truncated,
+		"units":     system.Labels(),
+	})
+}
+
+// parseTelemetryFilter builds a telemetry filter from query parameters
+func parseTelemetryFilter(c *gin.Context, defaultLimit, maxLimit int) (repository.TelemetryFilter, error) {
+	filter := repository.TelemetryFilter{
+		DeviceID: strings.TrimSpace(c.Query("deviceId")),
+		Limit:    defaultLimit,
+	}
+
+	if sessionID := c.Query("sessionId"); sessionID != "" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			return filter, errors.New("sessionId must be a valid UUID")
+		}
+		filter.SessionID = sessionID
+	}
+
+	if tag := c.Query("tag"); tag != "" {
+		var err error
+		if filter.Tag, err = models.ParseDeviceTag(tag); err != nil {
+			return filter, errors.New("tag is not a valid device tag")
+		}
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, errors.New("from must be an RFC3339 timestamp")
+		}
+		filter.From = &t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, errors.New("to must be an RFC3339 timestamp")
+		}
+		filter.To = &t
+	}
+
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return filter, errors.New("to must not be before from")
+	}
+
+	page, err := pagination.Parse(c.Request.URL.Query(), defaultLimit, maxLimit, pagination.IntegerID)
+	if err != nil {
+		return filter, err
+	}
+	filter.Limit, filter.After = page.Limit, page.After
+
+	switch c.Query("quality") {
+	case "", "all":
+	case "clean":
+		filter.CleanOnly = true
+	default:
+		return filter, errors.New("quality must be one of: all, clean")
+	}
+
+	switch c.Query("processed") {
+	case "", "false":
+	case "true":
+		filter.Processed = true
+	default:
+		return filter, errors.New("processed must be true or false")
+	}
+
+	return filter, nil
+}
+
+// telemetryCursor returns the listing position of a telemetry record
+func telemetryCursor(record *models.TelemetryData) pagination.Cursor {
+	return pagination.Cursor{Time: record.Timestamp, ID: strconv.FormatInt(record.ID, 10)}
+}
+
+// applyDeviceKeyScope binds telemetry to the device authenticated by X-Device-Key.
+// Records without a deviceId inherit the key's device; it returns false if the
+// record names a different device.
+func applyDeviceKeyScope(c *gin.Context, telemetry *models.TelemetryData) bool {
+	deviceID, ok := middleware.GetDeviceID(c)
+	if !ok {
+		return true
+	}
+
+	if telemetry.DeviceID == "" {
+		telemetry.DeviceID = deviceID
+		return true
+	}
+
+	return telemetry.DeviceID == deviceID
+}
+
 // handleDeviceClaiming handles device claiming and association with user
 func (h *TelemetryHandler) handleDeviceClaiming(c *gin.Context, telemetry *models.TelemetryData, userID uuid.UUID) error {
 	// Skip if no device ID in telemetry
@@ -190,10 +772,17 @@ func (h *TelemetryHandler) handleDeviceClaiming(c *gin.Context, telemetry *model
 
 	// Check if device exists
 	device, err := h.deviceRepo.GetByDeviceID(c.Request.Context(), deviceID)
-	if err != nil {
-		// Device doesn't exist - create and claim it
+	switch {
+	case errors.Is(err, repository.ErrDeviceNotFound):
+		// Device doesn't exist - claim it, if the user's plan allows another device
+		if h.quotas != nil {
+			if err := h.quotas.checkDeviceLimit(c.Request.Context(), userID); err != nil {
+				return err
+			}
+		}
+
 		now := time.Now()
-		device = &models.Device{
+		claim := &models.Device{
 			ID:         uuid.New(),
 			DeviceID:   deviceID,
 			UserID:     userID,
@@ -203,63 +792,155 @@ func (h *TelemetryHandler) handleDeviceClaiming(c *gin.Context, telemetry *model
 			CreatedAt:  now,
 			UpdatedAt:  now,
 		}
-
-		if err := h.deviceRepo.Create(c.Request.Context(), device); err != nil {
-			return fmt.Errorf("failed to create device: %w", err)
+		if version, ok := middleware.GetFirmwareVersion(c); ok {
+			reported := version.String()
+			claim.FirmwareVersion = &reported
+			claim.FirmwareUpdatedAt = &now
 		}
 
-		log.Printf("Device %s claimed by user %s", deviceID, userID)
-	} else {
-		// Device exists - verify ownership
-		if device.UserID != userID {
-			return fmt.Errorf("device %s is already claimed by another user", deviceID)
+		ctx := withDeviceClaimedEvents(c.Request.Context(), h.events, models.DeviceClaimUpload)
+		device, err = h.deviceRepo.Claim(ctx, claim)
+		if err != nil && !errors.Is(err, repository.ErrDeviceClaimed) {
+			return fmt.Errorf("failed to claim device: %w", err)
 		}
 
-		// Update last seen timestamp
-		if err := h.deviceRepo.UpdateLastSeen(c.Request.Context(), deviceID); err != nil {
-			log.Printf("Warning: failed to update last_seen for device %s: %v", deviceID, err)
+		if device.ID == claim.ID {
+			slog.Info("Device claimed", "device_id", deviceID, "user_id", userID)
+			recordOwnershipChange(c.Request.Context(), h.ownershipRepo, &models.DeviceOwnershipChange{
+				DeviceID: device.ID,
+				Action:   models.OwnershipActionClaim,
+				ToUserID: &userID,
+				ActorID:  &userID,
+			})
+			notifyEvents(h.events)
+			h.markSeen(device)
+			telemetry.UserID = &userID
+			return nil
 		}
+
+		// Another upload claimed the device first; it is used like any existing device
+	case err != nil:
+		return fmt.Errorf("failed to look up device: %w", err)
+	}
+
+	// Device exists - verify ownership or organization membership
+	allowed, err := h.orgs.allows(c.Request.Context(), userID, &device.UserID, device.OrgID, accessUse)
+	if err != nil {
+		return fmt.Errorf("failed to verify device access: %w", err)
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", errDeviceClaimedByOther, deviceID)
 	}
 
+	// Update last seen timestamp
+	if err := h.deviceRepo.UpdateLastSeen(c.Request.Context(), deviceID); err != nil {
+		slog.Warn("Failed to update device last_seen", "device_id", deviceID, "error", err)
+	} else {
+		h.markSeen(device)
+	}
+	h.recordFirmware(c, device)
+
 	// Set user_id on telemetry data
 	telemetry.UserID = &userID
 
 	return nil
 }
 
-// logTelemetry logs telemetry data in a structured format
-func logTelemetry(data models.TelemetryData) {
-	log.Printf("=== Telemetry Data Received ===")
-	log.Printf("Timestamp: %s", data.Timestamp.Format("2006-01-02 15:04:05.000"))
-	log.Printf("iTOW: %d ms", data.ITOW)
-	log.Printf("Battery: %.1f%% (charging: %v)", data.Battery, data.IsCharging)
-
-	// Log GPS data
-	log.Printf("GPS:")
-	log.Printf("  Position: %.7f°, %.7f°", data.GPS.Latitude, data.GPS.Longitude)
-	log.Printf("  Altitude: %.1f m (MSL: %.1f m)", data.GPS.WgsAltitude, data.GPS.MslAltitude)
-	log.Printf("  Speed: %.1f km/h, Heading: %.1f°", data.GPS.Speed, data.GPS.Heading)
-	log.Printf("  Satellites: %d, Fix: %d (%s)",
-		data.GPS.NumSatellites,
-		data.GPS.FixStatus,
-		fixStatusString(data.GPS.FixStatus, data.GPS.IsFixValid))
-	log.Printf("  Accuracy: H=%.2fm, V=%.2fm, Speed=%.2fkm/h",
-		data.GPS.HorizontalAccuracy,
-		data.GPS.VerticalAccuracy,
-		data.GPS.SpeedAccuracy)
-	log.Printf("  PDOP: %.2f", data.GPS.PDOP)
-
-	// Log Motion data
-	log.Printf("Motion:")
-	log.Printf("  G-Forces: X=%.3fg, Y=%.3fg, Z=%.3fg",
-		data.Motion.GForceX,
-		data.Motion.GForceY,
-		data.Motion.GForceZ)
-	log.Printf("  Rotation: X=%.2f°/s, Y=%.2f°/s, Z=%.2f°/s",
-		data.Motion.RotationX,
-		data.Motion.RotationY,
-		data.Motion.RotationZ)
-	log.Printf("==============================")
+// recordFirmware stores the firmware version reported with the upload when the device's changed
+func (h *TelemetryHandler) recordFirmware(c *gin.Context, device *models.Device) {
+	version, ok := middleware.GetFirmwareVersion(c)
+	if !ok {
+		return
+	}
+	h.storeFirmware(c, device, version)
+}
+
+// storeFirmware stores a reported firmware version when it differs from the device's
+func (h *TelemetryHandler) storeFirmware(c *gin.Context, device *models.Device, version models.FirmwareVersion) {
+	reported := version.String()
+	if device.FirmwareVersion != nil && *device.FirmwareVersion == reported {
+		return
+	}
+	if err := h.deviceRepo.UpdateFirmware(c.Request.Context(), device.ID, reported); err != nil {
+		slog.Warn("Failed to update device firmware version", "device_id", device.DeviceID, "error", err)
+		return
+	}
+	slog.Info("Device firmware version changed", "device_id", device.DeviceID, "firmware_version", reported)
+}
+
+// claimRecord associates a record of a multi-record upload with the uploader, claiming its
+// device on first sight. The outcome is cached per device in claims, so each device is looked up
+// once per upload, and failures are returned as messages fit to report back for the record.
+func (h *TelemetryHandler) claimRecord(c *gin.Context, record *models.TelemetryData, userID uuid.UUID, claims map[string]error) error {
+	if record.DeviceID == "" {
+		record.UserID = &userID
+		return nil
+	}
+
+	err, seen := claims[record.DeviceID]
+	if !seen {
+		err = h.handleDeviceClaiming(c, record, userID)
+		switch {
+		case errors.Is(err, errDeviceClaimedByOther):
+			err = errors.New("device is claimed by another user")
+		case errors.Is(err, errDeviceLimitReached):
+			err = errors.New("device limit reached for your plan")
+		case err != nil:
+			slog.Error("Error handling device claiming", "error", err)
+			err = errors.New("failed to process device claiming")
+		}
+		claims[record.DeviceID] = err
+	}
+	if err != nil {
+		return err
+	}
+
+	record.UserID = &userID
+	return nil
+}
+
+// markSeen reports a device whose last-seen time was refreshed to the presence tracker
+func (h *TelemetryHandler) markSeen(device *models.Device) {
+	if h.presence != nil {
+		h.presence.Seen(device)
+	}
+}
+
+// logTelemetry logs a received telemetry record as a single debug record
+// Building the attributes is skipped unless debug logging is enabled.
+func logTelemetry(ctx context.Context, data models.TelemetryData) {
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	slog.DebugContext(ctx, "Telemetry data received",
+		"timestamp", data.Timestamp,
+		"device_id", data.DeviceID,
+		"itow", data.ITOW,
+		"battery", data.Battery,
+		"charging", data.IsCharging,
+		slog.Group("gps",
+			"latitude", data.GPS.Latitude,
+			"longitude", data.GPS.Longitude,
+			"wgs_altitude", data.GPS.WgsAltitude,
+			"msl_altitude", data.GPS.MslAltitude,
+			"speed", data.GPS.Speed,
+			"heading", data.GPS.Heading,
+			"satellites", data.GPS.NumSatellites,
+			"fix", fixStatusString(data.GPS.FixStatus, data.GPS.IsFixValid),
+			"horizontal_accuracy", data.GPS.HorizontalAccuracy,
+			"vertical_accuracy", data.GPS.VerticalAccuracy,
+			"speed_accuracy", data.GPS.SpeedAccuracy,
+			"pdop", data.GPS.PDOP,
+		),
+		slog.Group("motion",
+			"g_force_x", data.Motion.GForceX,
+			"g_force_y", data.Motion.GForceY,
+			"g_force_z", data.Motion.GForceZ,
+			"rotation_x", data.Motion.RotationX,
+			"rotation_y", data.Motion.RotationY,
+			"rotation_z", data.Motion.RotationZ,
+		),
+	)
 }
 
 // fixStatusString converts fix status code to human-readable string
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// TelemetryArchive reads telemetry that has been moved to object storage
+type TelemetryArchive interface {
+	Query(ctx context.Context, filter repository.TelemetryFilter) ([]*models.TelemetryData, error)
+}
+
+// Archived telemetry query limits
+// Every archive overlapping the range is downloaded, so ranges are capped.
+const (
+	defaultArchiveQueryLimit = 1000
+	maxArchiveQueryLimit     = 10000
+	maxArchiveQueryRange     = 31 * 24 * time.Hour
+)
+
+// WithArchive enables queries of archived telemetry
+func (h *TelemetryHandler) WithArchive(archive TelemetryArchive) *TelemetryHandler {
+	h.archive = archive
+	return h
+}
+
+// HandleArchiveQuery retrieves the authenticated user's archived telemetry, oldest first
+// With points, the range is downsampled to that many representative records instead of truncated at limit.
+// GET /api/v1/telemetry/archive?from=&to=&deviceId=&sessionId=&limit=&points=&quality=&units=
+func (h *TelemetryHandler) HandleArchiveQuery(c *gin.Context) {
+	if h.archive == nil {
+		problem.Abort(c, problem.ServiceUnavailable("archive_unavailable", "Telemetry archival is not configured"))
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+
+	filter, err := parseTelemetryFilter(c, defaultArchiveQueryLimit, maxArchiveQueryLimit)
+	if err == nil {
+		err = validateArchiveFilter(filter)
+	}
+	var points int
+	if err == nil {
+		points, err = parseDownsamplePoints(c)
+	}
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+	filter.UserID = userID
+
+	system, ok := h.units.resolve(c)
+	if !ok {
+		return
+	}
+
+	// Fetch one extra record to detect truncation
+	limit := filter.Limit
+	if points > 0 {
+		limit = maxDownsampleSourceRows
+	}
+	filter.Limit = limit + 1
+
+	results, err := h.archive.Query(c.Request.Context(), filter)
+	if err != nil {
+		slog.Error("Error querying archived telemetry", "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve archived telemetry"))
+		return
+	}
+
+	truncated := len(results) > limit
+	if truncated {
+		results = results[:limit]
+	}
+	meta := api.Meta{"truncated": truncated}
+	if points > 0 {
+		meta["sourceCount"] = len(results)
+		results = downsampleTelemetry(results, points)
+	}
+	if results == nil {
+		results = []*models.TelemetryData{}
+	}
+	convertTelemetry(system, results)
+	meta["count"] = len(results)
+	meta["units"] = system.Labels()
+
+	api.List(c, http.StatusOK, "telemetry", results, meta)
+}
+
+// validateArchiveFilter checks the parameters archived telemetry queries do not support
+func validateArchiveFilter(filter repository.TelemetryFilter) error {
+	switch {
+	case filter.From == nil || filter.To == nil:
+		return errors.New("from and to are required")
+	case filter.To.Sub(*filter.From) > maxArchiveQueryRange:
+		return fmt.Errorf("the range between from and to must not exceed %d days", int(maxArchiveQueryRange/(24*time.Hour)))
+	case filter.After != nil:
+		return errors.New("cursor is not supported for archived telemetry; narrow from and to instead")
+	case filter.Processed:
+		return errors.New("processed telemetry is not archived")
+	case filter.Tag != "":
+		return errors.New("tag is not supported for archived telemetry; filter by deviceId instead")
+	}
+	return nil
+}
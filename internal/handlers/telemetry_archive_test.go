@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// archiveFunc adapts a function to TelemetryArchive
+type archiveFunc func(ctx context.Context, filter repository.TelemetryFilter) ([]*models.TelemetryData, error)
+
+func (f archiveFunc) Query(ctx context.Context, filter repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+	return f(ctx, filter)
+}
+
+func TestTelemetryHandler_ArchiveQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	base := time.Date(2025, 11, 2, 9, 0, 0, 0, time.UTC)
+
+	var captured repository.TelemetryFilter
+	handler := NewTelemetryHandler(repository.NewMockRepository(), nil).
+		WithArchive(archiveFunc(func(_ context.Context, filter repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+			captured = filter
+			return []*models.TelemetryData{
+				{ID: 1, Timestamp: base, DeviceID: "RACEBOX-001"},
+				{ID: 2, Timestamp: base.Add(time.Second), DeviceID: "RACEBOX-001"},
+				{ID: 3, Timestamp: base.Add(2 * time.Second), DeviceID: "RACEBOX-001"},
+			}, nil
+		}))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet,
+		"/api/v1/telemetry/archive?from=2025-11-01T00:00:00Z&to=2025-11-03T00:00:00Z&deviceId=RACEBOX-001&limit=2&quality=clean", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.HandleArchiveQuery(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured.UserID != userID || captured.DeviceID != "RACEBOX-001" || !captured.CleanOnly {
+		t.Errorf("Unexpected filter: %+v", captured)
+	}
+	if captured.Limit != 3 {
+		t.Errorf("Expected archive limit 3 (limit + 1), got %d", captured.Limit)
+	}
+
+	var response struct {
+		Telemetry []models.TelemetryData `json:"telemetry"`
+		Count     int                    `json:"count"`
+		Truncated bool                   `json:"truncated"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Count != 2 || len(response.Telemetry) != 2 || !response.Truncated {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestTelemetryHandler_ArchiveQuery_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewTelemetryHandler(repository.NewMockRepository(), nil).
+		WithArchive(archiveFunc(func(_ context.Context, _ repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+			t.Fatal("invalid queries should not reach the archive")
+			return nil, nil
+		}))
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "missing range", query: "deviceId=RACEBOX-001"},
+		{name: "missing to", query: "from=2025-11-01T00:00:00Z"},
+		{name: "range too long", query: "from=2025-10-01T00:00:00Z&to=2025-11-03T00:00:00Z"},
+		{name: "processed", query: "from=2025-11-01T00:00:00Z&to=2025-11-03T00:00:00Z&processed=true"},
+		{name: "limit too large", query: "from=2025-11-01T00:00:00Z&to=2025-11-03T00:00:00Z&limit=20000"},
+		{name: "points with limit", query: "from=2025-11-01T00:00:00Z&to=2025-11-03T00:00:00Z&points=100&limit=50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry/archive?"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.HandleArchiveQuery(c)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestTelemetryHandler_ArchiveQuery_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	query := "/api/v1/telemetry/archive?from=2025-11-01T00:00:00Z&to=2025-11-03T00:00:00Z"
+	tests := []struct {
+		name    string
+		handler *TelemetryHandler
+		status  int
+	}{
+		{
+			name:    "not configured",
+			handler: NewTelemetryHandler(repository.NewMockRepository(), nil),
+			status:  http.StatusServiceUnavailable,
+		},
+		{
+			name: "archive failure",
+			handler: NewTelemetryHandler(repository.NewMockRepository(), nil).
+				WithArchive(archiveFunc(func(_ context.Context, _ repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+					return nil, errors.New("bucket unreachable")
+				})),
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			tt.handler.HandleArchiveQuery(c)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
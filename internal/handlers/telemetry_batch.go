@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// maxBatchRecordSize caps the encoded size of one record of a batch upload
+	// Together with the record limit it bounds the bytes read for a batch, even without a body limit.
+	maxBatchRecordSize = 64 << 10
+
+	// maxBatchWorkers caps the goroutines decoding and validating one batch upload
+	// Concurrent uploads share the CPUs, so a single batch never takes all of them on large hosts.
+	maxBatchWorkers = 8
+
+	// minRecordsPerWorker keeps small batches on the request goroutine, where starting workers
+	// costs more than it saves
+	minRecordsPerWorker = 64
+)
+
+// batchWorkers returns how many workers process a batch of n records
+func batchWorkers(n int) int {
+	return max(1, min(runtime.GOMAXPROCS(0), maxBatchWorkers, n/minRecordsPerWorker))
+}
+
+// forEachRecord calls fn for the indexes of a batch of n records, spread over a bounded pool of workers
+// Each worker takes a contiguous range of records and stops at its first error. The error returned
+// is the one of the lowest failing index, as with a sequential loop; records after it may or may
+// not have been processed.
+func forEachRecord(n int, fn func(i int) error) (int, error) {
+	workers := batchWorkers(n)
+	if workers == 1 {
+		for i := 0; i < n; i++ {
+			if err := fn(i); err != nil {
+				return i, err
+			}
+		}
+		return -1, nil
+	}
+
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Int64 // Lowest failing index so far
+		errs   = make([]error, workers)
+		starts = make([]int, workers+1)
+	)
+	failed.Store(int64(n))
+	for w := range starts {
+		starts[w] = w * n / workers
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := starts[w]; i < starts[w+1]; i++ {
+				// Records after a known failure cannot change the result
+				if int64(i) > failed.Load() {
+					return
+				}
+				if err := fn(i); err != nil {
+					errs[w] = err
+					for {
+						current := failed.Load()
+						if int64(i) >= current || failed.CompareAndSwap(current, int64(i)) {
+							break
+						}
+					}
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if i := int(failed.Load()); i < n {
+		for w := range errs {
+			if i < starts[w+1] {
+				return i, errs[w]
+			}
+		}
+	}
+	return -1, nil
+}
+
+var (
+	// errBatchTooLarge reports a batch upload with more records than allowed
+	errBatchTooLarge = errors.New("batch too large")
+
+	// errBatchRecordTooLarge reports a batch record over maxBatchRecordSize
+	errBatchRecordTooLarge = errors.New("batch record too large")
+)
+
+// readTelemetryBatch reads the records of a batch upload, a JSON array, as raw JSON one at a time
+// Reading stops at the first record past maxRecords or over maxBatchRecordSize, so oversized
+// batches are rejected without reading or buffering the rest of the body. A null body is an
+// empty batch.
+func readTelemetryBatch(body io.Reader, maxRecords int) ([]json.RawMessage, error) {
+	decoder := json.NewDecoder(body)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("batch must be a JSON array of records")
+	}
+
+	raws := make([]json.RawMessage, 0, min(maxRecords, defaultMaxBatchRecords))
+	for decoder.More() {
+		if len(raws) == maxRecords {
+			return nil, errBatchTooLarge
+		}
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if len(raw) > maxBatchRecordSize {
+			return nil, fmt.Errorf("%w: record %d exceeds %d bytes", errBatchRecordTooLarge, len(raws), maxBatchRecordSize)
+		}
+		raws = append(raws, raw)
+	}
+
+	// Consume the closing bracket so a truncated body is reported
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return raws, nil
+}
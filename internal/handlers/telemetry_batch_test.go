@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		failing map[int]bool
+		wantIdx int
+	}{
+		{name: "small batch", n: 10, wantIdx: -1},
+		{name: "small batch with failures", n: 10, failing: map[int]bool{7: true, 3: true}, wantIdx: 3},
+		{name: "full batch", n: 1000, wantIdx: -1},
+		{name: "full batch with failures", n: 1000, failing: map[int]bool{999: true, 420: true, 421: true}, wantIdx: 420},
+		{name: "full batch failing first", n: 1000, failing: map[int]bool{0: true, 500: true}, wantIdx: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int64
+			i, err := forEachRecord(tt.n, func(i int) error {
+				calls.Add(1)
+				if tt.failing[i] {
+					return fmt.Errorf("record %d is invalid", i)
+				}
+				return nil
+			})
+
+			assert.Equal(t, tt.wantIdx, i)
+			if tt.wantIdx < 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(tt.n), calls.Load(), "every record is processed")
+				return
+			}
+			assert.EqualError(t, err, fmt.Sprintf("record %d is invalid", tt.wantIdx))
+		})
+	}
+}
+
+func TestBatchWorkers(t *testing.T) {
+	assert.Equal(t, 1, batchWorkers(0))
+	assert.Equal(t, 1, batchWorkers(minRecordsPerWorker-1))
+	assert.LessOrEqual(t, batchWorkers(1000), min(maxBatchWorkers, runtime.GOMAXPROCS(0)))
+}
+
+// countingReader counts the bytes read from a reader
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += n
+	return n, err
+}
+
+func TestReadTelemetryBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantCount int
+		wantErr   error
+	}{
+		{name: "records", body: ` [{"deviceId":"RACEBOX-001"}, {"deviceId":"RACEBOX-001"}] `, wantCount: 2},
+		{name: "empty array", body: `[]`},
+		{name: "null", body: `null`},
+		{name: "too many records", body: `[{},{},{},{}]`, wantErr: errBatchTooLarge},
+		{name: "record too large", body: `[{"extra":"` + strings.Repeat("x", maxBatchRecordSize) + `"}]`, wantErr: errBatchRecordTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raws, err := readTelemetryBatch(strings.NewReader(tt.body), 3)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, raws, tt.wantCount)
+		})
+	}
+
+	for _, body := range []string{``, `{"deviceId":"RACEBOX-001"}`, `[{"deviceId":"RACEBOX-001"}`, `[{"deviceId":}]`} {
+		_, err := readTelemetryBatch(strings.NewReader(body), 3)
+		assert.Error(t, err, body)
+	}
+}
+
+func TestReadTelemetryBatch_StopsAtRecordLimit(t *testing.T) {
+	body := fullBatch(t, 1000)
+	reader := &countingReader{r: bytes.NewReader(body)}
+
+	_, err := readTelemetryBatch(reader, 10)
+
+	assert.ErrorIs(t, err, errBatchTooLarge)
+	assert.Less(t, reader.read, len(body)/10, "the rest of the body is not read")
+}
+
+func TestTelemetryHandler_BatchPostBoundsBodyByRecordLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Without a body limit, two records may only take twice the record size
+	router := gin.New()
+	router.POST("/api/telemetry/batch", NewTelemetryHandler(repository.NewMockRepository(), nil).WithMaxBatchRecords(2).HandleBatchPost)
+
+	body := `[` + strings.Repeat(" ", 2*maxBatchRecordSize) + `{}]`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/telemetry/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+}
+
+// fullBatch encodes a batch of n valid records
+func fullBatch(tb testing.TB, n int) []byte {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	records := make([]models.TelemetryData, n)
+	for i := range records {
+		records[i] = models.TelemetryData{
+			ITOW:      int64(118286240 + i*40),
+			Timestamp: start.Add(time.Duration(i) * 40 * time.Millisecond),
+			DeviceID:  "RACEBOX-001",
+			GPS: models.GpsData{
+				Latitude: 42.6719035 + float64(i)*1e-5, Longitude: 23.2887238, WgsAltitude: 625.761, MslAltitude: 590.095,
+				Speed: 125.5, Heading: 270.5, NumSatellites: 11, FixStatus: 3, PDOP: 3.0, IsFixValid: true,
+			},
+			Motion:  models.MotionData{GForceX: -0.003, GForceY: 0.113, GForceZ: 0.974, RotationX: 2.09},
+			Battery: 89.0,
+		}
+	}
+	body, err := json.Marshal(records)
+	require.NoError(tb, err)
+	return body
+}
+
+func TestTelemetryHandler_BatchPostReportsFirstInvalidRecord(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var records []map[string]interface{}
+	require.NoError(t, json.Unmarshal(fullBatch(t, 1000), &records))
+	records[250]["battery"] = -1
+	records[900]["battery"] = -1
+	body, _ := json.Marshal(records)
+
+	mockRepo := repository.NewMockRepository()
+	mockRepo.SaveBatchFunc = func(_ context.Context, _ []*models.TelemetryData) error {
+		t.Fatal("invalid batches are not saved")
+		return nil
+	}
+	router := gin.New()
+	router.POST("/api/telemetry/batch", NewTelemetryHandler(mockRepo, nil).HandleBatchPost)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/telemetry/batch", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(250), response["record"])
+}
+
+func BenchmarkDecodeTelemetryBatch(b *testing.B) {
+	var raws []json.RawMessage
+	require.NoError(b, json.Unmarshal(fullBatch(b, 1000), &raws))
+
+	procs := []int{1}
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		procs = append(procs, n)
+	}
+	for _, n := range procs {
+		b.Run(fmt.Sprintf("procs=%d", n), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(n))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := decodeTelemetryBatch(raws); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkTelemetryHandler_BatchPost(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+
+	// Every request logs the saved batch
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	body := fullBatch(b, 1000)
+	router := gin.New()
+	router.POST("/api/telemetry/batch", NewTelemetryHandler(repository.NewMockRepository(), nil).HandleBatchPost)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/telemetry/batch", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusCreated {
+				b.Fatal(errors.New(w.Body.String()))
+			}
+		}
+	})
+}
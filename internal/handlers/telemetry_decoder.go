@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// maxTelemetryExtras caps the number of unrecognized channels stored with one record
+const maxTelemetryExtras = 32
+
+// telemetryDecoder parses one uploaded record of a specific schema version
+type telemetryDecoder func(raw json.RawMessage) (*models.TelemetryData, error)
+
+// telemetryDecoders maps each known schema version to its decoder
+// Adding a version (e.g., one that defines wheel speed or OBD channels) means registering its decoder here.
+var telemetryDecoders = map[int]telemetryDecoder{
+	models.TelemetrySchemaV1: decodeTelemetryV1,
+	models.TelemetrySchemaV2: decodeTelemetryV2,
+}
+
+// telemetryV1Fields lists the top-level fields defined by schema version 1
+var telemetryV1Fields = map[string]bool{
+	"id": true, "clientId": true, "schemaVersion": true, "timestamp": true,
+	"deviceId": true, "sessionId": true, "userId": true,
+	"iTOW": true, "gps": true, "motion": true,
+	"battery": true, "isCharging": true, "timeAccuracy": true, "validityFlags": true,
+	"extras": true,
+}
+
+// telemetryV2Fields adds the vehicle block to the version 1 fields
+var telemetryV2Fields = withFields(telemetryV1Fields, "vehicle")
+
+// withFields returns a copy of fields with more field names added
+func withFields(fields map[string]bool, names ...string) map[string]bool {
+	merged := make(map[string]bool, len(fields)+len(names))
+	for name := range fields {
+		merged[name] = true
+	}
+	for _, name := range names {
+		merged[name] = true
+	}
+	return merged
+}
+
+// decodeTelemetry parses an uploaded record with the decoder for its schemaVersion
+// Records without a version are version 1. Records from versions newer than the service knows are
+// decoded with the latest decoder so their known fields are kept and new channels land in extras.
+func decodeTelemetry(raw json.RawMessage) (*models.TelemetryData, error) {
+	var header struct {
+		SchemaVersion *int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, err
+	}
+
+	version := models.TelemetrySchemaV1
+	if header.SchemaVersion != nil {
+		version = *header.SchemaVersion
+	}
+	if version < models.TelemetrySchemaV1 {
+		return nil, fmt.Errorf("unsupported schemaVersion %d", version)
+	}
+
+	decode, ok := telemetryDecoders[version]
+	if !ok {
+		decode = telemetryDecoders[models.LatestTelemetrySchemaVersion]
+	}
+
+	telemetry, err := decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	telemetry.SchemaVersion = version
+
+	return telemetry, nil
+}
+
+// DecodeTelemetry parses a single uploaded record like the ingest endpoints do
+// It lets background ingest of resumable uploads share the schema version handling.
+func DecodeTelemetry(raw []byte) (*models.TelemetryData, error) {
+	return decodeTelemetry(raw)
+}
+
+// decodeTelemetryV1 parses a version 1 record, keeping fields it does not define as extras
+// A vehicle block sent with a version 1 record is one of those fields.
+func decodeTelemetryV1(raw json.RawMessage) (*models.TelemetryData, error) {
+	telemetry, err := decodeTelemetryFields(raw, telemetryV1Fields)
+	if err != nil {
+		return nil, err
+	}
+	telemetry.Vehicle = nil
+	return telemetry, nil
+}
+
+// decodeTelemetryV2 parses a version 2 record, which may carry a vehicle block
+func decodeTelemetryV2(raw json.RawMessage) (*models.TelemetryData, error) {
+	return decodeTelemetryFields(raw, telemetryV2Fields)
+}
+
+// decodeTelemetryFields parses a record whose schema defines the known top-level fields
+// Other fields are kept as extras.
+func decodeTelemetryFields(raw json.RawMessage, known map[string]bool) (*models.TelemetryData, error) {
+	var telemetry models.TelemetryData
+	if err := json.Unmarshal(raw, &telemetry); err != nil {
+		return nil, err
+	}
+	telemetry.ClearReceipt()
+
+	extras, err := unknownFields(raw, known)
+	if err != nil {
+		return nil, err
+	}
+	if len(extras) > 0 && telemetry.Extras == nil {
+		telemetry.Extras = make(map[string]interface{}, len(extras))
+	}
+	for key, value := range extras {
+		telemetry.Extras[key] = value
+	}
+	if len(telemetry.Extras) > maxTelemetryExtras {
+		return nil, fmt.Errorf("too many extra channels: %d (max %d)", len(telemetry.Extras), maxTelemetryExtras)
+	}
+
+	return &telemetry, nil
+}
+
+// unknownFields returns the top-level fields of a JSON object that are not in known
+func unknownFields(raw json.RawMessage, known map[string]bool) (map[string]interface{}, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	var unknown map[string]interface{}
+	for key, value := range fields {
+		if known[key] {
+			continue
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(value, &decoded); err != nil {
+			return nil, err
+		}
+		if unknown == nil {
+			unknown = make(map[string]interface{})
+		}
+		unknown[key] = decoded
+	}
+
+	return unknown, nil
+}
+
+// decodeTelemetryBatch parses each record of a batch upload with decodeTelemetry, in parallel for large batches
+func decodeTelemetryBatch(raws []json.RawMessage) ([]*models.TelemetryData, error) {
+	batch := make([]*models.TelemetryData, len(raws))
+	if i, err := forEachRecord(len(raws), func(i int) (err error) {
+		batch[i], err = decodeTelemetry(raws[i])
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("record %d: %w", i, err)
+	}
+	return batch, nil
+}
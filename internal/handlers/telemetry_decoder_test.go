@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+func TestDecodeTelemetry(t *testing.T) {
+	t.Run("unversioned records are version 1", func(t *testing.T) {
+		telemetry, err := decodeTelemetry(json.RawMessage(`{"timestamp":"2024-01-10T08:51:08Z","battery":80}`))
+		require.NoError(t, err)
+		assert.Equal(t, models.TelemetrySchemaV1, telemetry.SchemaVersion)
+		assert.Equal(t, 80.0, telemetry.Battery)
+		assert.Nil(t, telemetry.Extras)
+	})
+
+	t.Run("clientId is a record field, not an extra", func(t *testing.T) {
+		telemetry, err := decodeTelemetry(json.RawMessage(
+			`{"clientId":"0b6f0c62-3f0e-4f4a-9a57-2d1f2b8d6c11","timestamp":"2024-01-10T08:51:08Z"}`))
+		require.NoError(t, err)
+		require.NotNil(t, telemetry.ClientID)
+		assert.Equal(t, "0b6f0c62-3f0e-4f4a-9a57-2d1f2b8d6c11", telemetry.ClientID.String())
+		assert.Nil(t, telemetry.Extras)
+	})
+
+	t.Run("invalid clientId is rejected", func(t *testing.T) {
+		_, err := decodeTelemetry(json.RawMessage(`{"clientId":"not-a-uuid","timestamp":"2024-01-10T08:51:08Z"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown channels are kept as extras", func(t *testing.T) {
+		telemetry, err := decodeTelemetry(json.RawMessage(
+			`{"schemaVersion":1,"timestamp":"2024-01-10T08:51:08Z","wheelSpeed":[101.2,101.5],"extras":{"rpm":6500}}`))
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{101.2, 101.5}, telemetry.Extras["wheelSpeed"])
+		assert.Equal(t, 6500.0, telemetry.Extras["rpm"])
+	})
+
+	t.Run("newer versions decode with the latest decoder", func(t *testing.T) {
+		telemetry, err := decodeTelemetry(json.RawMessage(
+			`{"schemaVersion":7,"timestamp":"2024-01-10T08:51:08Z","gps":{"speed":120},"coolantTemp":92}`))
+		require.NoError(t, err)
+		assert.Equal(t, 7, telemetry.SchemaVersion)
+		assert.Equal(t, 120.0, telemetry.GPS.Speed)
+		assert.Equal(t, 92.0, telemetry.Extras["coolantTemp"])
+	})
+
+	t.Run("version 2 carries a vehicle block", func(t *testing.T) {
+		telemetry, err := decodeTelemetry(json.RawMessage(
+			`{"schemaVersion":2,"timestamp":"2024-01-10T08:51:08Z","vehicle":{"rpm":7200,"gear":4}}`))
+		require.NoError(t, err)
+		require.NotNil(t, telemetry.Vehicle)
+		assert.Equal(t, 7200.0, *telemetry.Vehicle.RPM)
+		assert.Equal(t, 4, *telemetry.Vehicle.Gear)
+		assert.Nil(t, telemetry.Vehicle.Throttle)
+		assert.Nil(t, telemetry.Extras)
+	})
+
+	t.Run("version 1 keeps a vehicle block as extras", func(t *testing.T) {
+		telemetry, err := decodeTelemetry(json.RawMessage(
+			`{"timestamp":"2024-01-10T08:51:08Z","vehicle":{"rpm":7200}}`))
+		require.NoError(t, err)
+		assert.Nil(t, telemetry.Vehicle)
+		assert.Equal(t, map[string]interface{}{"rpm": 7200.0}, telemetry.Extras["vehicle"])
+	})
+
+	t.Run("receive time and clock skew are not taken from uploads", func(t *testing.T) {
+		telemetry, err := decodeTelemetry(json.RawMessage(
+			`{"timestamp":"2024-01-10T08:51:08Z","receivedAt":"2024-01-10T08:51:08Z","clockSkewMs":0,"deviceTimestamp":"2024-01-10T08:51:08Z"}`))
+		require.NoError(t, err)
+		assert.Nil(t, telemetry.ReceivedAt)
+		assert.Nil(t, telemetry.ClockSkewMs)
+		assert.Nil(t, telemetry.DeviceTimestamp)
+	})
+
+	t.Run("invalid versions are rejected", func(t *testing.T) {
+		_, err := decodeTelemetry(json.RawMessage(`{"schemaVersion":0}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("too many extras are rejected", func(t *testing.T) {
+		fields := make([]string, 0, maxTelemetryExtras+1)
+		for i := 0; i <= maxTelemetryExtras; i++ {
+			fields = append(fields, fmt.Sprintf(`"channel%d":%d`, i, i))
+		}
+		_, err := decodeTelemetry(json.RawMessage(`{` + strings.Join(fields, ",") + `}`))
+		assert.Error(t, err)
+	})
+}
+
+func TestTelemetryHandler_HandleBatchPost_StoresExtras(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var saved []*models.TelemetryData
+	repo := repository.NewMockRepository()
+	repo.SaveBatchFunc = func(_ context.Context, records []*models.TelemetryData) error {
+		saved = records
+		return nil
+	}
+
+	router := gin.New()
+	router.POST("/api/v1/telemetry/batch", NewTelemetryHandler(repo, nil).HandleBatchPost)
+
+	body := `[
+		{"timestamp":"2024-01-10T08:51:08Z","gps":{"latitude":42.6,"longitude":23.2}},
+		{"schemaVersion":2,"timestamp":"2024-01-10T08:51:09Z","gps":{"latitude":42.6,"longitude":23.2},"wheelSpeed":101.5}
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/telemetry/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.Len(t, saved, 2)
+	assert.Equal(t, models.TelemetrySchemaV1, saved[0].SchemaVersion)
+	assert.Nil(t, saved[0].Extras)
+	assert.Equal(t, 2, saved[1].SchemaVersion)
+	assert.Equal(t, 101.5, saved[1].Extras["wheelSpeed"])
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Page sizes for telemetry deletion listings
+const (
+	defaultTelemetryDeletionListLimit = 50
+	maxTelemetryDeletionListLimit     = 200
+)
+
+// TrimSessionRequest represents the request body trimming a session
+// Records before start and after end are deleted; at least one must be set.
+type TrimSessionRequest struct {
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// DeleteTelemetryRangeRequest represents the request body deleting a time range of a device's telemetry
+type DeleteTelemetryRangeRequest struct {
+	Start time.Time `json:"start" binding:"required"`
+	End   time.Time `json:"end" binding:"required"`
+}
+
+// WithTelemetryDeletions enables session trims, restorable for undoWindow
+func (h *SessionHandler) WithTelemetryDeletions(deletionRepo repository.TelemetryDeletionRepository, undoWindow time.Duration) *SessionHandler {
+	h.deletionRepo = deletionRepo
+	h.undoWindow = undoWindow
+	return h
+}
+
+// WithTelemetryDeletions enables deleting time ranges of device telemetry, restorable for undoWindow
+func (h *DeviceHandler) WithTelemetryDeletions(deletionRepo repository.TelemetryDeletionRepository, undoWindow time.Duration) *DeviceHandler {
+	h.deletionRepo = deletionRepo
+	h.undoWindow = undoWindow
+	return h
+}
+
+// TrimSession deletes the session's records before start and after end
+// The records are hidden at once and purged when the undo window closes.
+// POST /api/v1/sessions/:id/trim
+func (h *SessionHandler) TrimSession(c *gin.Context) {
+	session, ok := h.getSession(c, accessManage)
+	if !ok {
+		return
+	}
+
+	var req TrimSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+	if req.Start == nil && req.End == nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "start or end is required"))
+		return
+	}
+	if req.Start != nil && req.End != nil && !req.End.After(*req.Start) {
+		problem.Abort(c, problem.BadRequest("invalid_time_range", "end must be after start"))
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+	owner := userID
+	if session.UserID != nil {
+		owner = *session.UserID
+	}
+
+	deletion := &models.TelemetryDeletion{
+		UserID:      owner,
+		RequestedBy: &userID,
+		DeviceID:    session.DeviceID,
+		SessionID:   &session.ID,
+		Kind:        models.TelemetryDeletionTrim,
+		StartsAt:    utcTime(req.Start),
+		EndsAt:      utcTime(req.End),
+		UndoUntil:   time.Now().UTC().Add(h.undoWindow),
+	}
+	if !createTelemetryDeletion(c, h.deletionRepo, deletion) {
+		return
+	}
+
+	// Ended sessions are not summarized again on their own; active ones are when they end
+	if h.summarizer != nil && session.EndedAt != nil {
+		h.summarizer.Enqueue(session.ID)
+	}
+
+	api.Respond(c, http.StatusCreated, deletion)
+}
+
+// DeleteTelemetryRange deletes the device's records from start to end, inclusive
+// Only records owned by the device's current owner are deleted. They are hidden at once and
+// purged when the undo window closes.
+// POST /api/v1/devices/:id/telemetry/deletions
+func (h *DeviceHandler) DeleteTelemetryRange(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	device, ok := h.managedDevice(c, userID)
+	if !ok {
+		return
+	}
+
+	var req DeleteTelemetryRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+	if req.End.Before(req.Start) {
+		problem.Abort(c, problem.BadRequest("invalid_time_range", "end must not be before start"))
+		return
+	}
+
+	deletion := &models.TelemetryDeletion{
+		UserID:      device.UserID,
+		RequestedBy: &userID,
+		DeviceID:    device.DeviceID,
+		Kind:        models.TelemetryDeletionRange,
+		StartsAt:    utcTime(&req.Start),
+		EndsAt:      utcTime(&req.End),
+		UndoUntil:   time.Now().UTC().Add(h.undoWindow),
+	}
+	if !createTelemetryDeletion(c, h.deletionRepo, deletion) {
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, deletion)
+}
+
+// createTelemetryDeletion stores a correction, responding with the error if it fails
+func createTelemetryDeletion(c *gin.Context, deletionRepo repository.TelemetryDeletionRepository, deletion *models.TelemetryDeletion) bool {
+	if err := deletionRepo.Create(c.Request.Context(), deletion); err != nil {
+		if errors.Is(err, repository.ErrNoTelemetryToDelete) {
+			problem.Abort(c, problem.NotFound("no_telemetry", "No telemetry matches the requested range"))
+			return false
+		}
+		slog.Error("Error deleting telemetry", "kind", deletion.Kind, "device_id", deletion.DeviceID, "error", err)
+		problem.Abort(c, problem.Internal("Failed to delete telemetry"))
+		return false
+	}
+	return true
+}
+
+// utcTime converts an optional time to UTC
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// TelemetryDeletionHandler lists and undoes telemetry corrections
+type TelemetryDeletionHandler struct {
+	deletionRepo repository.TelemetryDeletionRepository
+}
+
+// NewTelemetryDeletionHandler creates a new telemetry deletion handler
+func NewTelemetryDeletionHandler(deletionRepo repository.TelemetryDeletionRepository) *TelemetryDeletionHandler {
+	return &TelemetryDeletionHandler{
+		deletionRepo: deletionRepo,
+	}
+}
+
+// ListTelemetryDeletions lists the corrections of the user's telemetry and those the user requested,
+// most recent first
+// GET /api/v1/telemetry/deletions?limit=&offset=
+func (h *TelemetryDeletionHandler) ListTelemetryDeletions(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	limit, err := parseIntQuery(c, "limit", defaultTelemetryDeletionListLimit)
+	if err != nil || limit <= 0 || limit > maxTelemetryDeletionListLimit {
+		problem.Abort(c, problem.BadRequest("invalid_request", "limit must be between 1 and "+strconv.Itoa(maxTelemetryDeletionListLimit)))
+		return
+	}
+
+	offset, err := parseIntQuery(c, "offset", 0)
+	if err != nil || offset < 0 {
+		problem.Abort(c, problem.BadRequest("invalid_request", "offset must be a non-negative integer"))
+		return
+	}
+
+	deletions, err := h.deletionRepo.ListByUser(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve telemetry deletions"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "deletions", deletions, api.Meta{
+		"count":  len(deletions),
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// UndoTelemetryDeletion restores the records of a correction whose undo window is still open
+// Only the owner of the records and the user who requested the correction can undo it.
+// POST /api/v1/telemetry/deletions/:id/undo
+func (h *TelemetryDeletionHandler) UndoTelemetryDeletion(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_deletion_id", "Invalid deletion ID format"))
+		return
+	}
+
+	deletion, err := h.deletionRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTelemetryDeletionNotFound) {
+			problem.Abort(c, problem.NotFound("deletion_not_found", "Telemetry deletion not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve telemetry deletion"))
+		return
+	}
+
+	// Other users get 404 so correction IDs are not disclosed
+	if deletion.UserID != userID && (deletion.RequestedBy == nil || *deletion.RequestedBy != userID) {
+		problem.Abort(c, problem.NotFound("deletion_not_found", "Telemetry deletion not found"))
+		return
+	}
+
+	restored, err := h.deletionRepo.Undo(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTelemetryDeletionExpired) {
+			problem.Abort(c, problem.Conflict("deletion_not_undoable", "This deletion was already undone or its undo window has closed").
+				With("undoUntil", deletion.UndoUntil))
+			return
+		}
+		slog.Error("Error undoing telemetry deletion", "deletion_id", id, "error", err)
+		problem.Abort(c, problem.Internal("Failed to undo telemetry deletion"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, restored)
+}
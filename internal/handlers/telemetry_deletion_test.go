@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func performTelemetryDeletionRequest(method, path, id string, userID uuid.UUID, body string, serve gin.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set(string(middleware.UserIDKey), userID)
+	serve(c)
+	return w
+}
+
+func TestSessionHandler_TrimSession(t *testing.T) {
+	ownerID := uuid.New()
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		body           string
+		noTelemetry    bool
+		expectedStatus int
+	}{
+		{name: "trims both ends", userID: ownerID, body: `{"start":"2024-01-10T08:05:00Z","end":"2024-01-10T08:55:00Z"}`, expectedStatus: http.StatusCreated},
+		{name: "trims the start only", userID: ownerID, body: `{"start":"2024-01-10T08:05:00Z"}`, expectedStatus: http.StatusCreated},
+		{name: "no bounds", userID: ownerID, body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "end before start", userID: ownerID, body: `{"start":"2024-01-10T08:55:00Z","end":"2024-01-10T08:05:00Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "nothing to trim", userID: ownerID, body: `{"start":"2024-01-10T08:00:00Z"}`, noTelemetry: true, expectedStatus: http.StatusNotFound},
+		{name: "another user's session", userID: uuid.New(), body: `{"start":"2024-01-10T08:05:00Z"}`, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, sessionRepo, _ := setupSessionTest()
+			deletionRepo := repository.NewMockTelemetryDeletionRepository()
+			summarizer := &recordingSummarizer{}
+			handler.WithTelemetryDeletions(deletionRepo, time.Hour).WithSummarizer(summarizer)
+
+			sessionID := uuid.New()
+			sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+				return &models.Session{ID: id, DeviceID: "AVT-001", UserID: &ownerID, StartedAt: start, EndedAt: &end}, nil
+			}
+			var stored *models.TelemetryDeletion
+			deletionRepo.CreateFunc = func(_ context.Context, deletion *models.TelemetryDeletion) error {
+				if tt.noTelemetry {
+					return repository.ErrNoTelemetryToDelete
+				}
+				deletion.Points = 120
+				stored = deletion
+				return nil
+			}
+
+			w := performTelemetryDeletionRequest(http.MethodPost, "/api/v1/sessions/"+sessionID.String()+"/trim",
+				sessionID.String(), tt.userID, tt.body, handler.TrimSession)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, stored)
+				assert.Empty(t, summarizer.enqueued)
+				return
+			}
+
+			require.NotNil(t, stored)
+			assert.Equal(t, models.TelemetryDeletionTrim, stored.Kind)
+			assert.Equal(t, sessionID, *stored.SessionID)
+			assert.Equal(t, ownerID, stored.UserID)
+			assert.Equal(t, "AVT-001", stored.DeviceID)
+			assert.Equal(t, start.Add(5*time.Minute), *stored.StartsAt)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), stored.UndoUntil, 5*time.Second)
+			assert.Equal(t, []uuid.UUID{sessionID}, summarizer.enqueued)
+
+			var response models.TelemetryDeletion
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, int64(120), response.Points)
+		})
+	}
+}
+
+func TestDeviceHandler_DeleteTelemetryRange(t *testing.T) {
+	ownerID := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "AVT-001", UserID: ownerID, IsActive: true}
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		body           string
+		expectedStatus int
+	}{
+		{name: "deletes the range", userID: ownerID, body: `{"start":"2024-01-10T08:00:00Z","end":"2024-01-10T08:10:00Z"}`, expectedStatus: http.StatusCreated},
+		{name: "missing end", userID: ownerID, body: `{"start":"2024-01-10T08:00:00Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "end before start", userID: ownerID, body: `{"start":"2024-01-10T08:10:00Z","end":"2024-01-10T08:00:00Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "another user's device", userID: uuid.New(), body: `{"start":"2024-01-10T08:00:00Z","end":"2024-01-10T08:10:00Z"}`, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, deviceRepo := setupDeviceTest()
+			deletionRepo := repository.NewMockTelemetryDeletionRepository()
+			handler.WithTelemetryDeletions(deletionRepo, time.Hour)
+
+			deviceRepo.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
+				return device, nil
+			}
+			var stored *models.TelemetryDeletion
+			deletionRepo.CreateFunc = func(_ context.Context, deletion *models.TelemetryDeletion) error {
+				stored = deletion
+				return nil
+			}
+
+			w := performTelemetryDeletionRequest(http.MethodPost, "/api/v1/devices/"+device.ID.String()+"/telemetry/deletions",
+				device.ID.String(), tt.userID, tt.body, handler.DeleteTelemetryRange)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, stored)
+				return
+			}
+
+			require.NotNil(t, stored)
+			assert.Equal(t, models.TelemetryDeletionRange, stored.Kind)
+			assert.Equal(t, ownerID, stored.UserID)
+			assert.Equal(t, tt.userID, *stored.RequestedBy)
+			assert.Equal(t, "AVT-001", stored.DeviceID)
+			assert.Nil(t, stored.SessionID)
+		})
+	}
+}
+
+func TestTelemetryDeletionHandler_UndoTelemetryDeletion(t *testing.T) {
+	ownerID, requesterID := uuid.New(), uuid.New()
+	deletion := &models.TelemetryDeletion{
+		ID: uuid.New(), UserID: ownerID, RequestedBy: &requesterID, DeviceID: "AVT-001",
+		Kind: models.TelemetryDeletionRange, UndoUntil: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		id             string
+		undoErr        error
+		expectedStatus int
+	}{
+		{name: "owner undoes", userID: ownerID, id: deletion.ID.String(), expectedStatus: http.StatusOK},
+		{name: "requester undoes", userID: requesterID, id: deletion.ID.String(), expectedStatus: http.StatusOK},
+		{name: "window closed", userID: ownerID, id: deletion.ID.String(), undoErr: repository.ErrTelemetryDeletionExpired, expectedStatus: http.StatusConflict},
+		{name: "another user", userID: uuid.New(), id: deletion.ID.String(), expectedStatus: http.StatusNotFound},
+		{name: "unknown deletion", userID: ownerID, id: uuid.New().String(), expectedStatus: http.StatusNotFound},
+		{name: "invalid ID", userID: ownerID, id: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deletionRepo := repository.NewMockTelemetryDeletionRepository()
+			handler := NewTelemetryDeletionHandler(deletionRepo)
+
+			deletionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.TelemetryDeletion, error) {
+				if id == deletion.ID {
+					return deletion, nil
+				}
+				return nil, repository.ErrTelemetryDeletionNotFound
+			}
+			var undone bool
+			deletionRepo.UndoFunc = func(_ context.Context, _ uuid.UUID) (*models.TelemetryDeletion, error) {
+				if tt.undoErr != nil {
+					return nil, tt.undoErr
+				}
+				undone = true
+				now := time.Now()
+				restored := *deletion
+				restored.UndoneAt = &now
+				return &restored, nil
+			}
+
+			w := performTelemetryDeletionRequest(http.MethodPost, "/api/v1/telemetry/deletions/"+tt.id+"/undo",
+				tt.id, tt.userID, "", handler.UndoTelemetryDeletion)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, undone)
+		})
+	}
+}
+
+func TestTelemetryDeletionHandler_ListTelemetryDeletions(t *testing.T) {
+	userID := uuid.New()
+	deletionRepo := repository.NewMockTelemetryDeletionRepository()
+	handler := NewTelemetryDeletionHandler(deletionRepo)
+
+	deletionRepo.ListByUserFunc = func(_ context.Context, id uuid.UUID, limit, offset int) ([]*models.TelemetryDeletion, error) {
+		assert.Equal(t, userID, id)
+		assert.Equal(t, 10, limit)
+		assert.Equal(t, 0, offset)
+		return []*models.TelemetryDeletion{{ID: uuid.New(), UserID: userID, Kind: models.TelemetryDeletionTrim}}, nil
+	}
+
+	w := performTelemetryDeletionRequest(http.MethodGet, "/api/v1/telemetry/deletions?limit=10", "", userID, "", handler.ListTelemetryDeletions)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Deletions []models.TelemetryDeletion `json:"deletions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Deletions, 1)
+}
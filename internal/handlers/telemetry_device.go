@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// HandleDeviceTelemetry lists the telemetry of one device, newest first, with cursor pagination
+// The device is addressed by its registration ID and must be owned by the user or shared with them
+// through an organization. Records are those stored under the device's current owner, so history
+// recorded by a previous owner is not listed.
+// GET /api/v1/devices/:id/telemetry?sessionId=&from=&to=&limit=&cursor=&quality=&processed=&units=
+func (h *TelemetryHandler) HandleDeviceTelemetry(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_device_id", "Invalid device ID format"))
+		return
+	}
+
+	// The path names the device; a second device or tag filter would only be confusing
+	if c.Query("deviceId") != "" || c.Query("tag") != "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "deviceId and tag are not supported for device telemetry"))
+		return
+	}
+	filter, err := parseTelemetryFilter(c, defaultTelemetryQueryLimit, maxTelemetryQueryLimit)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	system, ok := h.units.resolve(c)
+	if !ok {
+		return
+	}
+
+	device, err := h.deviceRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			problem.Abort(c, problem.NotFound("device_not_found", "Device not found"))
+			return
+		}
+		slog.Error("Error retrieving device", "id", id, "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve device"))
+		return
+	}
+	if !h.orgs.authorize(c, userID, &device.UserID, device.OrgID, accessUse, "device") {
+		return
+	}
+	filter.UserID, filter.DeviceID = device.UserID, device.DeviceID
+
+	// Fetch one extra row to detect whether another page exists
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1
+
+	results, err := h.repo.Query(c.Request.Context(), filter)
+	if err != nil {
+		slog.Error("Error querying device telemetry", "device_id", device.DeviceID, "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve telemetry"))
+		return
+	}
+
+	meta := api.Meta{}
+	if len(results) > pageSize {
+		results = results[:pageSize]
+		meta["nextCursor"] = telemetryCursor(results[len(results)-1]).Encode()
+	}
+	if results == nil {
+		results = []*models.TelemetryData{}
+	}
+
+	convertTelemetry(system, results)
+	meta["deviceId"] = device.DeviceID
+	meta["count"] = len(results)
+	meta["units"] = system.Labels()
+
+	api.List(c, http.StatusOK, "telemetry", results, meta)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// performDeviceTelemetryRequest calls HandleDeviceTelemetry as the given user for a device
+func performDeviceTelemetryRequest(handler *TelemetryHandler, userID uuid.UUID, id string, query url.Values) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/"+id+"/telemetry?"+query.Encode(), nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set(string(middleware.UserIDKey), userID)
+	handler.HandleDeviceTelemetry(c)
+	return w
+}
+
+func TestTelemetryHandler_DeviceTelemetry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ownerID := uuid.New()
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: ownerID, IsActive: true}
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	deviceRepo := repository.NewMockDeviceRepository()
+	deviceRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Device, error) {
+		if id != device.ID {
+			return nil, repository.ErrDeviceNotFound
+		}
+		return device, nil
+	}
+	var capturedFilter *repository.TelemetryFilter
+	mockRepo := repository.NewMockRepository()
+	mockRepo.QueryFunc = func(_ context.Context, filter repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+		capturedFilter = &filter
+		return []*models.TelemetryData{
+			{ID: 3, DeviceID: device.DeviceID, Timestamp: start.Add(2 * time.Second)},
+			{ID: 2, DeviceID: device.DeviceID, Timestamp: start.Add(time.Second)},
+			{ID: 1, DeviceID: device.DeviceID, Timestamp: start},
+		}, nil
+	}
+	handler := NewTelemetryHandler(mockRepo, deviceRepo)
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		id             string
+		query          url.Values
+		expectedStatus int
+	}{
+		{name: "owner", userID: ownerID, id: device.ID.String(), query: url.Values{"from": {start.Format(time.RFC3339)}, "limit": {"2"}}, expectedStatus: http.StatusOK},
+		{name: "another user", userID: uuid.New(), id: device.ID.String(), expectedStatus: http.StatusForbidden},
+		{name: "unknown device", userID: ownerID, id: uuid.New().String(), expectedStatus: http.StatusNotFound},
+		{name: "invalid device ID", userID: ownerID, id: "RACEBOX-001", expectedStatus: http.StatusBadRequest},
+		{name: "deviceId filter", userID: ownerID, id: device.ID.String(), query: url.Values{"deviceId": {"RACEBOX-002"}}, expectedStatus: http.StatusBadRequest},
+		{name: "invalid range", userID: ownerID, id: device.ID.String(), query: url.Values{"from": {"yesterday"}}, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capturedFilter = nil
+			w := performDeviceTelemetryRequest(handler, tt.userID, tt.id, tt.query)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				assert.Nil(t, capturedFilter, "telemetry is not queried")
+				return
+			}
+
+			require.NotNil(t, capturedFilter)
+			assert.Equal(t, ownerID, capturedFilter.UserID)
+			assert.Equal(t, device.DeviceID, capturedFilter.DeviceID)
+			assert.Equal(t, 3, capturedFilter.Limit)
+			require.NotNil(t, capturedFilter.From)
+			assert.True(t, start.Equal(*capturedFilter.From))
+
+			var response struct {
+				Telemetry  []models.TelemetryData `json:"telemetry"`
+				DeviceID   string                 `json:"deviceId"`
+				Count      int                    `json:"count"`
+				NextCursor string                 `json:"nextCursor"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, device.DeviceID, response.DeviceID)
+			assert.Equal(t, 2, response.Count)
+			assert.NotEmpty(t, response.NextCursor)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/downsample"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// Downsampling limits for ?points= queries
+// The whole range is read before it is reduced, so the rows read are capped.
+const (
+	minTelemetryPoints      = 3
+	maxTelemetryPoints      = 10000
+	maxDownsampleSourceRows = 250000
+)
+
+// parseDownsamplePoints reads the optional points parameter; zero means no downsampling
+// Downsampling covers the whole range, so it cannot be combined with paging.
+func parseDownsamplePoints(c *gin.Context) (int, error) {
+	value := c.Query("points")
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < minTelemetryPoints || n > maxTelemetryPoints {
+		return 0, fmt.Errorf("points must be between %d and %d", minTelemetryPoints, maxTelemetryPoints)
+	}
+	if c.Query("limit") != "" || c.Query("cursor") != "" {
+		return 0, errors.New("points cannot be combined with limit or cursor")
+	}
+	return n, nil
+}
+
+// downsampleTelemetry reduces time-ordered records to the given number of points, keeping their order
+// Points are picked by speed, the series charts plot against time.
+func downsampleTelemetry(records []*models.TelemetryData, points int) []*models.TelemetryData {
+	return downsample.LTTB(records, points,
+		func(r *models.TelemetryData) float64 { return float64(r.Timestamp.UnixNano()) },
+		func(r *models.TelemetryData) float64 { return r.GPS.Speed },
+	)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// Heatmap cell limits
+// A full-screen map at any zoom covers a few thousand cells.
+const (
+	defaultHeatmapCellLimit = 5000
+	maxHeatmapCellLimit     = 20000
+)
+
+// HandleHeatmap grids the authenticated user's telemetry within a bounding box for map heatmaps
+// GET /api/v1/telemetry/heatmap?bbox=&zoom=&deviceId=&sessionId=&tag=&from=&to=&limit=&quality=&processed=&units=
+func (h *TelemetryHandler) HandleHeatmap(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	bbox, err := models.ParseBoundingBox(c.Query("bbox"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	zoom, err := strconv.Atoi(c.Query("zoom"))
+	if err != nil || zoom < models.MinHeatmapZoom || zoom > models.MaxHeatmapZoom {
+		problem.Abort(c, problem.BadRequest("invalid_request", fmt.Sprintf("zoom must be an integer between %d and %d", models.MinHeatmapZoom, models.MaxHeatmapZoom)))
+		return
+	}
+
+	filter, err := parseTelemetryFilter(c, defaultHeatmapCellLimit, maxHeatmapCellLimit)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+	filter.UserID = userID
+	filter.After = nil
+
+	system, ok := h.units.resolve(c)
+	if !ok {
+		return
+	}
+
+	// Fetch one extra cell to detect truncation
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	cells, err := h.repo.Heatmap(c.Request.Context(), filter, bbox, zoom)
+	if err != nil {
+		slog.Error("Error computing telemetry heatmap", "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve telemetry heatmap"))
+		return
+	}
+
+	// Cells come densest first, so truncation drops the sparsest ones
+	truncated := len(cells) > limit
+	if truncated {
+		cells = cells[:limit]
+	}
+	if cells == nil {
+		cells = []*models.HeatmapCell{}
+	}
+	convertHeatmapCells(system, cells)
+
+	api.List(c, http.StatusOK, "cells", cells, api.Meta{
+		"bbox":      bbox,
+		"zoom":      zoom,
+		"cellSize":  models.HeatmapCellSize(zoom),
+		"count":     len(cells),
+		"truncated": truncated,
+		"units":     system.Labels(),
+	})
+}
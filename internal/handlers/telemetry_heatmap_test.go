@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryHandler_Heatmap(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+
+	var capturedFilter repository.TelemetryFilter
+	var capturedBox models.BoundingBox
+	var capturedZoom int
+	mockRepo := repository.NewMockRepository()
+	mockRepo.HeatmapFunc = func(_ context.Context, filter repository.TelemetryFilter, bbox models.BoundingBox, zoom int) ([]*models.HeatmapCell, error) {
+		capturedFilter, capturedBox, capturedZoom = filter, bbox, zoom
+		return []*models.HeatmapCell{
+			{Latitude: 50.4371, Longitude: 5.9712, Points: 420, AvgSpeed: 120, MaxSpeed: 180},
+			{Latitude: 50.4380, Longitude: 5.9725, Points: 300, AvgSpeed: 96, MaxSpeed: 150},
+			{Latitude: 50.4390, Longitude: 5.9730, Points: 12, AvgSpeed: 40, MaxSpeed: 60},
+		}, nil
+	}
+
+	handler := NewTelemetryHandler(mockRepo, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry/heatmap?bbox=5.955,50.427,5.985,50.446&zoom=15&deviceId=device-001&limit=2&units=imperial", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.HandleHeatmap(c)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, userID, capturedFilter.UserID)
+	assert.Equal(t, "device-001", capturedFilter.DeviceID)
+	assert.Equal(t, 3, capturedFilter.Limit)
+	assert.Equal(t, models.BoundingBox{West: 5.955, South: 50.427, East: 5.985, North: 50.446}, capturedBox)
+	assert.Equal(t, 15, capturedZoom)
+
+	var response struct {
+		Zoom      int                  `json:"zoom"`
+		CellSize  float64              `json:"cellSize"`
+		Cells     []models.HeatmapCell `json:"cells"`
+		Count     int                  `json:"count"`
+		Truncated bool                 `json:"truncated"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.True(t, response.Truncated)
+	assert.InDelta(t, models.HeatmapCellSize(15), response.CellSize, 1e-9)
+	require.Len(t, response.Cells, 2)
+	assert.Equal(t, int64(420), response.Cells[0].Points)
+	assert.InDelta(t, 74.56, response.Cells[0].AvgSpeed, 0.01) // mph
+}
+
+func TestTelemetryHandler_Heatmap_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewTelemetryHandler(repository.NewMockRepository(), nil)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "missing bbox", query: "zoom=10"},
+		{name: "malformed bbox", query: "bbox=1,2,3&zoom=10"},
+		{name: "missing zoom", query: "bbox=5.955,50.427,5.985,50.446"},
+		{name: "zoom too large", query: "bbox=5.955,50.427,5.985,50.446&zoom=23"},
+		{name: "limit too large", query: "bbox=5.955,50.427,5.985,50.446&zoom=10&limit=50000"},
+		{name: "unknown units", query: "bbox=5.955,50.427,5.985,50.446&zoom=10&units=nautical"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry/heatmap?"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.HandleHeatmap(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
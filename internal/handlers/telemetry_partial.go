@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// PartialBatchHeader opts a batch upload into partial acceptance, like the partial query parameter
+const PartialBatchHeader = "X-Partial-Batch"
+
+// Per-record statuses of a partial batch upload
+const (
+	batchRecordCreated   = "created"   // Stored
+	batchRecordAccepted  = "accepted"  // Queued for a deferred write
+	batchRecordDuplicate = "duplicate" // Already stored, skipped by deduplication
+	batchRecordRejected  = "rejected"  // Failed to decode, validate or claim its device
+)
+
+// batchRecordResult reports the outcome of one record of a partial batch upload
+type batchRecordResult struct {
+	Index  int    `json:"index"` // 0-based position in the request array
+	Status string `json:"status"`
+	ID     int64  `json:"id,omitempty"` // Stored record ID; omitted for queued and rejected records
+	Error  string `json:"error,omitempty"`
+
+	// Invalid fields of a record that failed validation
+	Fields []models.FieldError `json:"fields,omitempty"`
+}
+
+// partialBatchRequested reports whether a batch upload opted into partial acceptance
+func partialBatchRequested(c *gin.Context) bool {
+	value := c.Query("partial")
+	if value == "" {
+		value = c.GetHeader(PartialBatchHeader)
+	}
+	partial, err := strconv.ParseBool(value)
+	return err == nil && partial
+}
+
+// handlePartialBatch stores the valid records of a batch and reports the outcome of each record
+// Records that fail to decode, validate or claim their device are rejected individually instead
+// of failing the batch, so clients can drop them and stop resending the rest. Problems with the
+// request as a whole, such as authentication, batch size or quota, still reject the entire batch.
+// The response is 207 Multi-Status when any record was rejected and 400 when all of them were.
+func (h *TelemetryHandler) handlePartialBatch(c *gin.Context, raws []json.RawMessage) {
+	results := make([]batchRecordResult, len(raws))
+	records := make([]*models.TelemetryData, len(raws))
+	decoded := make([]*models.TelemetryData, len(raws)) // Also holds records that failed validation
+	_, _ = forEachRecord(len(raws), func(i int) error {
+		results[i].Index = i
+		record, err := decodeTelemetry(raws[i])
+		if err != nil {
+			results[i].Status, results[i].Error = batchRecordRejected, "invalid JSON: "+err.Error()
+			return nil
+		}
+		decoded[i] = record
+		if err := h.validate(record); err != nil {
+			results[i].Status, results[i].Error = batchRecordRejected, err.Error()
+			results[i].Fields = invalidFields(err)
+			return nil
+		}
+		records[i] = record
+		return nil
+	})
+	deviceID := ""
+	for _, record := range decoded {
+		if record != nil {
+			deviceID = record.DeviceID
+			break
+		}
+	}
+	middleware.SetIngestDetails(c, deviceID, len(raws))
+
+	if len(raws) == 0 {
+		problem.Abort(c, problem.BadRequest("empty_batch", "Empty batch"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	authenticated := err == nil
+	if !authenticated && h.strict {
+		rejectAnonymousUpload(c)
+		return
+	}
+
+	claims := make(map[string]error)
+	valid := make([]*models.TelemetryData, 0, len(records))
+	validIndexes := make([]int, 0, len(records))
+	for i, record := range records {
+		if record == nil {
+			continue
+		}
+		if err := h.checkBatchRecord(c, record, userID, authenticated, claims); err != nil {
+			results[i].Status, results[i].Error = batchRecordRejected, err.Error()
+			results[i].Fields = invalidFields(err)
+			continue
+		}
+		valid = append(valid, record)
+		validIndexes = append(validIndexes, i)
+	}
+	rejected := len(raws) - len(valid)
+
+	if len(valid) == 0 {
+		problem.Abort(c, problem.BadRequest("no_valid_records", "No valid records in batch").
+			With("count", len(raws)).
+			With("rejected", rejected).
+			With("results", results))
+		return
+	}
+
+	if authenticated && !h.allowTelemetry(c, userID, len(valid)) {
+		return
+	}
+
+	h.checkClocks(valid)
+
+	// Queue for a batched write when buffering is enabled
+	if h.writer != nil {
+		if err := h.writer.Enqueue(valid); err != nil {
+			h.rejectBufferedUpload(c, err)
+			return
+		}
+		h.enqueueIngested(valid)
+		if authenticated {
+			h.recordUsage(c, userID, len(valid))
+		}
+		for _, i := range validIndexes {
+			results[i].Status = batchRecordAccepted
+		}
+		api.Respond(c, partialBatchStatus(http.StatusAccepted, rejected), gin.H{
+			"message":  fmt.Sprintf("Batch telemetry data accepted (%d of %d records)", len(valid), len(raws)),
+			"count":    len(raws),
+			"accepted": len(valid),
+			"rejected": rejected,
+			"results":  results,
+		})
+		return
+	}
+
+	if err := h.repo.SaveBatch(h.withBatchSaved(c.Request.Context()), valid); err != nil {
+		slog.Error("Error saving telemetry batch to database", "error", err)
+		problem.Abort(c, problem.Internal("Failed to save telemetry batch"))
+		return
+	}
+
+	h.enqueueIngested(valid)
+	notifyEvents(h.events)
+
+	savedIDs := make([]int64, 0, len(valid))
+	for j, record := range valid {
+		result := &results[validIndexes[j]]
+		if record.Duplicate {
+			result.Status, result.ID = batchRecordDuplicate, record.ID
+			continue
+		}
+		result.Status, result.ID = batchRecordCreated, record.ID
+		savedIDs = append(savedIDs, record.ID)
+	}
+	skipped := len(valid) - len(savedIDs)
+	if authenticated {
+		h.recordUsage(c, userID, len(savedIDs))
+	}
+
+	slog.Info("Partial batch telemetry saved", "inserted", len(savedIDs), "skipped", skipped, "rejected", rejected)
+
+	api.Respond(c, partialBatchStatus(http.StatusCreated, rejected), gin.H{
+		"message":  fmt.Sprintf("Batch telemetry data received (%d of %d records)", len(valid), len(raws)),
+		"count":    len(raws),
+		"inserted": len(savedIDs),
+		"skipped":  skipped,
+		"rejected": rejected,
+		"ids":      savedIDs,
+		"results":  results,
+	})
+}
+
+// checkBatchRecord checks the device key scope of a validated record of a partial batch and assigns it to the uploader
+func (h *TelemetryHandler) checkBatchRecord(c *gin.Context, record *models.TelemetryData, userID uuid.UUID, authenticated bool, claims map[string]error) error {
+	// Device key uploads may only write telemetry for the key's device
+	if !applyDeviceKeyScope(c, record) {
+		return errors.New("device key does not match deviceId")
+	}
+
+	if authenticated && h.deviceRepo != nil {
+		return h.claimRecord(c, record, userID, claims)
+	}
+	return nil
+}
+
+// partialBatchStatus returns 207 Multi-Status when some records were rejected, or status otherwise
+func partialBatchStatus(status, rejected int) int {
+	if rejected > 0 {
+		return http.StatusMultiStatus
+	}
+	return status
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// postBatch sends a JSON array to the batch endpoint and decodes the response
+func postBatch(t *testing.T, router *gin.Engine, target string, body string, header bool) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if header {
+		req.Header.Set(PartialBatchHeader, "true")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return w, response
+}
+
+// batchResults extracts the per-record results of a partial batch response
+func batchResults(t *testing.T, response map[string]interface{}) []map[string]interface{} {
+	t.Helper()
+	raw, ok := response["results"].([]interface{})
+	if !ok {
+		t.Fatalf("Expected results array, got %v", response["results"])
+	}
+	results := make([]map[string]interface{}, len(raw))
+	for i, r := range raw {
+		results[i] = r.(map[string]interface{})
+	}
+	return results
+}
+
+func TestTelemetryHandler_PartialBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now().UTC()
+
+	body := "[" + strings.Join([]string{
+		streamLine(t, now, "racebox-1"),
+		`{"timestamp":"` + now.Format(time.RFC3339) + `","gps":{"latitude":95,"longitude":23}}`,
+		`"not a record"`,
+		streamLine(t, now.Add(time.Second), "racebox-1"),
+	}, ",") + "]"
+
+	t.Run("valid records are saved and invalid ones reported", func(t *testing.T) {
+		repo := repository.NewMockRepository()
+		var saved []*models.TelemetryData
+		repo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+			for i, record := range data {
+				record.ID = int64(100 + i)
+			}
+			data[1].Duplicate = true
+			saved = data
+			return nil
+		}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/batch", NewTelemetryHandler(repo, nil).HandleBatchPost)
+
+		w, response := postBatch(t, router, "/api/v1/telemetry/batch?partial=true", body, false)
+		if w.Code != http.StatusMultiStatus {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusMultiStatus, w.Code, w.Body.String())
+		}
+		if len(saved) != 2 {
+			t.Fatalf("Expected 2 saved records, got %d", len(saved))
+		}
+		if response["inserted"] != float64(1) || response["skipped"] != float64(1) || response["rejected"] != float64(2) {
+			t.Errorf("Unexpected counts: %v", response)
+		}
+
+		results := batchResults(t, response)
+		if len(results) != 4 {
+			t.Fatalf("Expected 4 results, got %d", len(results))
+		}
+		expected := []string{batchRecordCreated, batchRecordRejected, batchRecordRejected, batchRecordDuplicate}
+		for i, status := range expected {
+			if results[i]["index"] != float64(i) || results[i]["status"] != status {
+				t.Errorf("Result %d: expected status %q, got %v", i, status, results[i])
+			}
+		}
+		if results[0]["id"] != float64(100) {
+			t.Errorf("Expected record 0 to report id 100, got %v", results[0]["id"])
+		}
+		if results[1]["error"] == nil || results[2]["error"] == nil {
+			t.Errorf("Expected rejected records to report an error, got %v and %v", results[1], results[2])
+		}
+	})
+
+	t.Run("header opts in and a fully valid batch is created", func(t *testing.T) {
+		router := gin.New()
+		router.POST("/api/v1/telemetry/batch", NewTelemetryHandler(repository.NewMockRepository(), nil).HandleBatchPost)
+
+		valid := "[" + streamLine(t, now, "racebox-1") + "," + streamLine(t, now.Add(time.Second), "racebox-1") + "]"
+		w, response := postBatch(t, router, "/api/v1/telemetry/batch", valid, true)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if response["rejected"] != float64(0) || len(batchResults(t, response)) != 2 {
+			t.Errorf("Unexpected response: %v", response)
+		}
+	})
+
+	t.Run("without opting in the batch is all or nothing", func(t *testing.T) {
+		repo := repository.NewMockRepository()
+		repo.SaveBatchFunc = func(_ context.Context, _ []*models.TelemetryData) error {
+			t.Fatal("SaveBatch should not be called")
+			return nil
+		}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/batch", NewTelemetryHandler(repo, nil).HandleBatchPost)
+
+		w, _ := postBatch(t, router, "/api/v1/telemetry/batch?partial=false", body, false)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("no valid records", func(t *testing.T) {
+		repo := repository.NewMockRepository()
+		repo.SaveBatchFunc = func(_ context.Context, _ []*models.TelemetryData) error {
+			t.Fatal("SaveBatch should not be called")
+			return nil
+		}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/batch", NewTelemetryHandler(repo, nil).HandleBatchPost)
+
+		w, response := postBatch(t, router, "/api/v1/telemetry/batch?partial=true", `["x", 1]`, false)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+		if response["rejected"] != float64(2) || len(batchResults(t, response)) != 2 {
+			t.Errorf("Unexpected response: %v", response)
+		}
+	})
+
+	t.Run("records for devices claimed by another user are rejected", func(t *testing.T) {
+		userID := uuid.New()
+		deviceRepo := repository.NewMockDeviceRepository()
+		lookups := 0
+		deviceRepo.GetByDeviceIDFunc = func(_ context.Context, deviceID string) (*models.Device, error) {
+			lookups++
+			if deviceID == "foreign" {
+				return &models.Device{ID: uuid.New(), DeviceID: deviceID, UserID: uuid.New()}, nil
+			}
+			return &models.Device{ID: uuid.New(), DeviceID: deviceID, UserID: userID}, nil
+		}
+		var saved []*models.TelemetryData
+		repo := repository.NewMockRepository()
+		repo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+			saved = data
+			return nil
+		}
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Next()
+		})
+		router.POST("/api/v1/telemetry/batch", NewTelemetryHandler(repo, deviceRepo).HandleBatchPost)
+
+		batch := "[" + strings.Join([]string{
+			streamLine(t, now, "mine"),
+			streamLine(t, now, "foreign"),
+			streamLine(t, now.Add(time.Second), "foreign"),
+			streamLine(t, now.Add(time.Second), "mine"),
+		}, ",") + "]"
+		w, response := postBatch(t, router, "/api/v1/telemetry/batch?partial=true", batch, false)
+		if w.Code != http.StatusMultiStatus {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusMultiStatus, w.Code, w.Body.String())
+		}
+		if len(saved) != 2 {
+			t.Fatalf("Expected 2 saved records, got %d", len(saved))
+		}
+		for _, record := range saved {
+			if record.DeviceID != "mine" || record.UserID == nil || *record.UserID != userID {
+				t.Errorf("Unexpected saved record: device %s, user %v", record.DeviceID, record.UserID)
+			}
+		}
+		if lookups != 2 {
+			t.Errorf("Expected each device to be looked up once, got %d lookups", lookups)
+		}
+		if results := batchResults(t, response); results[1]["error"] != "device is claimed by another user" {
+			t.Errorf("Unexpected rejection: %v", results[1])
+		}
+	})
+
+	t.Run("buffered writes report records as accepted", func(t *testing.T) {
+		writer := &queueingWriter{}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/batch", NewTelemetryHandler(repository.NewMockRepository(), nil).WithWriter(writer).HandleBatchPost)
+
+		w, response := postBatch(t, router, "/api/v1/telemetry/batch?partial=true", body, false)
+		if w.Code != http.StatusMultiStatus {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusMultiStatus, w.Code, w.Body.String())
+		}
+		if len(writer.records) != 2 || response["accepted"] != float64(2) {
+			t.Errorf("Expected 2 queued records, got %d: %v", len(writer.records), response)
+		}
+		if results := batchResults(t, response); results[0]["status"] != batchRecordAccepted {
+			t.Errorf("Expected record 0 to be accepted, got %v", results[0])
+		}
+	})
+
+	t.Run("database error fails the batch", func(t *testing.T) {
+		repo := repository.NewMockRepository()
+		repo.SaveBatchFunc = func(_ context.Context, _ []*models.TelemetryData) error {
+			return errors.New("database error")
+		}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/batch", NewTelemetryHandler(repo, nil).HandleBatchPost)
+
+		w, _ := postBatch(t, router, "/api/v1/telemetry/batch?partial=true", body, false)
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// maxFleetDevices is the number of devices a fleet map shows positions for
+const maxFleetDevices = 1000
+
+// DevicePositionResponse is a device's last known position on the fleet map
+type DevicePositionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	DeviceName *string   `json:"deviceName,omitempty"`
+	Online     bool      `json:"online"` // Seen within the last hour
+	*models.DevicePosition
+}
+
+// HandleDevicePositions reports the last known position of each of the authenticated user's devices
+// Devices shared through organizations are included; devices that never reported a position are not.
+// Fleet maps poll this every few seconds, so it costs one device listing and one indexed lookup per device.
+// GET /api/v1/devices/positions?tag=&units=
+func (h *TelemetryHandler) HandleDevicePositions(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	system, ok := h.units.resolve(c)
+	if !ok {
+		return
+	}
+
+	active := true
+	filter := repository.DeviceFilter{
+		UserID:   userID,
+		IsActive: &active,
+		Tag:      c.Query("tag"),
+		Sort:     repository.DeviceSortLastSeenAt,
+		Limit:    maxFleetDevices,
+	}
+	var err error
+	if filter.OrgIDs, err = h.orgs.orgIDs(c.Request.Context(), userID); err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve organizations"))
+		return
+	}
+
+	devices, total, err := h.deviceRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve devices"))
+		return
+	}
+
+	owners := make([]repository.DeviceOwner, len(devices))
+	byDeviceID := make(map[string]*models.Device, len(devices))
+	for i, device := range devices {
+		owners[i] = repository.DeviceOwner{DeviceID: device.DeviceID, UserID: device.UserID}
+		byDeviceID[device.DeviceID] = device
+	}
+
+	positions, err := h.repo.LatestPositions(c.Request.Context(), owners)
+	if err != nil {
+		slog.Error("Error querying device positions", "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve device positions"))
+		return
+	}
+
+	convertPositions(system, positions)
+
+	response := make([]DevicePositionResponse, 0, len(positions))
+	for _, position := range positions {
+		device, ok := byDeviceID[position.DeviceID]
+		if !ok {
+			continue
+		}
+		response = append(response, DevicePositionResponse{
+			ID:             device.ID,
+			DeviceName:     device.DeviceName,
+			Online:         device.IsOnline(),
+			DevicePosition: position,
+		})
+	}
+
+	api.List(c, http.StatusOK, "positions", response, api.Meta{
+		"count":     len(response),
+		"truncated": total > len(devices),
+		"units":     system.Labels(),
+	})
+}
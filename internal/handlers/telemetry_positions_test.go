@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryHandler_DevicePositions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	previousOwner := uuid.New()
+	recently := time.Now().Add(-time.Minute)
+	name := "Track car"
+	car := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: userID, DeviceName: &name, LastSeenAt: &recently, IsActive: true}
+	kart := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-002", UserID: previousOwner, IsActive: true}
+	idle := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-003", UserID: userID, IsActive: true}
+
+	var capturedFilter repository.DeviceFilter
+	deviceRepo := repository.NewMockDeviceRepository()
+	deviceRepo.ListFunc = func(_ context.Context, filter repository.DeviceFilter) ([]*models.Device, int, error) {
+		capturedFilter = filter
+		return []*models.Device{car, kart, idle}, 3, nil
+	}
+
+	var capturedOwners []repository.DeviceOwner
+	mockRepo := repository.NewMockRepository()
+	mockRepo.LatestPositionsFunc = func(_ context.Context, devices []repository.DeviceOwner) ([]*models.DevicePosition, error) {
+		capturedOwners = devices
+		return []*models.DevicePosition{
+			{DeviceID: "RACEBOX-001", RecordedAt: recently, Latitude: 50.4371, Longitude: 5.9712, Speed: 160.9344, Heading: 270},
+			{DeviceID: "RACEBOX-002", RecordedAt: recently.Add(-time.Hour), Latitude: 50.4380, Longitude: 5.9725},
+		}, nil
+	}
+
+	handler := NewTelemetryHandler(mockRepo, deviceRepo)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/positions?tag=kart&units=imperial", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.HandleDevicePositions(c)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, userID, capturedFilter.UserID)
+	assert.Equal(t, "kart", capturedFilter.Tag)
+	require.NotNil(t, capturedFilter.IsActive)
+	assert.True(t, *capturedFilter.IsActive)
+
+	// Positions are looked up for each device's current owner
+	assert.Equal(t, []repository.DeviceOwner{
+		{DeviceID: "RACEBOX-001", UserID: userID},
+		{DeviceID: "RACEBOX-002", UserID: previousOwner},
+		{DeviceID: "RACEBOX-003", UserID: userID},
+	}, capturedOwners)
+
+	var response struct {
+		Positions []struct {
+			ID         uuid.UUID `json:"id"`
+			DeviceID   string    `json:"deviceId"`
+			DeviceName *string   `json:"deviceName"`
+			Online     bool      `json:"online"`
+			Latitude   float64   `json:"latitude"`
+			Speed      float64   `json:"speed"`
+			Heading    float64   `json:"heading"`
+		} `json:"positions"`
+		Count     int               `json:"count"`
+		Truncated bool              `json:"truncated"`
+		Units     map[string]string `json:"units"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.False(t, response.Truncated)
+	assert.Equal(t, "mph", response.Units["speed"])
+	require.Len(t, response.Positions, 2)
+	assert.Equal(t, car.ID, response.Positions[0].ID)
+	assert.Equal(t, "RACEBOX-001", response.Positions[0].DeviceID)
+	require.NotNil(t, response.Positions[0].DeviceName)
+	assert.Equal(t, "Track car", *response.Positions[0].DeviceName)
+	assert.True(t, response.Positions[0].Online)
+	assert.InDelta(t, 100, response.Positions[0].Speed, 0.001)
+	assert.Equal(t, 270.0, response.Positions[0].Heading)
+	assert.False(t, response.Positions[1].Online)
+}
+
+func TestTelemetryHandler_DevicePositions_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deviceRepo := repository.NewMockDeviceRepository()
+	deviceRepo.ListFunc = func(_ context.Context, _ repository.DeviceFilter) ([]*models.Device, int, error) {
+		return []*models.Device{{ID: uuid.New(), DeviceID: "RACEBOX-001"}}, 1, nil
+	}
+	mockRepo := repository.NewMockRepository()
+	mockRepo.LatestPositionsFunc = func(_ context.Context, _ []repository.DeviceOwner) ([]*models.DevicePosition, error) {
+		return nil, assert.AnError
+	}
+	handler := NewTelemetryHandler(mockRepo, deviceRepo)
+
+	for path, expected := range map[string]int{
+		"/api/v1/devices/positions":               http.StatusInternalServerError,
+		"/api/v1/devices/positions?units=furlong": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, path, nil)
+		c.Set(string(middleware.UserIDKey), uuid.New())
+
+		handler.HandleDevicePositions(c)
+
+		assert.Equal(t, expected, w.Code, path)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/ingest"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// IngestPressure reports how close telemetry ingest is to shedding load
+type IngestPressure interface {
+	Pressure() ingest.Pressure
+}
+
+// WithPressure rejects uploads with 503 and a Retry-After hint while ingest is overloaded
+// The same hint is used when the write-behind buffer is full.
+func (h *TelemetryHandler) WithPressure(pressure IngestPressure) *TelemetryHandler {
+	h.pressure = pressure
+	return h
+}
+
+// HandleIngestStatus reports ingest pressure so devices can adapt their upload cadence
+// Devices should wait retryAfter seconds between uploads while the status is elevated, and
+// before retrying while it is overloaded.
+// GET /api/v1/ingest/status
+func (h *TelemetryHandler) HandleIngestStatus(c *gin.Context) {
+	pressure := ingest.Pressure{Level: ingest.PressureNormal}
+	if h.pressure != nil {
+		pressure = h.pressure.Pressure()
+	}
+
+	response := gin.H{
+		"status":           pressure.Level,
+		"acceptingUploads": pressure.Level != ingest.PressureOverloaded,
+		"retryAfter":       pressure.RetryAfterSeconds(),
+		"buffered":         h.writer != nil,
+	}
+	if pressure.BufferUtilization != nil {
+		response["bufferUtilization"] = *pressure.BufferUtilization
+	}
+	if pressure.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(pressure.RetryAfterSeconds()))
+	}
+	api.Respond(c, http.StatusOK, response)
+}
+
+// shedLoad responds 503 with a Retry-After hint when ingest is overloaded
+// It runs before the body is read so rejected uploads cost as little as possible.
+func (h *TelemetryHandler) shedLoad(c *gin.Context) bool {
+	if h.pressure == nil {
+		return false
+	}
+
+	pressure := h.pressure.Pressure()
+	if pressure.Level != ingest.PressureOverloaded {
+		return false
+	}
+
+	slog.Debug("Shedding telemetry upload", "retry_after", pressure.RetryAfter)
+	c.Header("Retry-After", strconv.Itoa(pressure.RetryAfterSeconds()))
+	problem.Abort(c, problem.ServiceUnavailable("ingest_busy", "Telemetry ingest is busy, retry later"))
+	return true
+}
+
+// retryAfter returns the Retry-After hint for an upload rejected because the buffer is full
+func (h *TelemetryHandler) retryAfter() string {
+	if h.pressure == nil {
+		return bufferRetryAfter
+	}
+	if seconds := h.pressure.Pressure().RetryAfterSeconds(); seconds > 0 {
+		return strconv.Itoa(seconds)
+	}
+	return bufferRetryAfter
+}
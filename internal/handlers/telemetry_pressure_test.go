@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/ingest"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// fixedPressure reports a fixed ingest pressure
+type fixedPressure struct {
+	pressure ingest.Pressure
+}
+
+func (p *fixedPressure) Pressure() ingest.Pressure {
+	return p.pressure
+}
+
+// fullWriter rejects every upload as if the buffer were full
+type fullWriter struct{}
+
+func (fullWriter) Enqueue(_ []*models.TelemetryData) error {
+	return ingest.ErrBufferFull
+}
+
+func TestTelemetryHandler_ShedsLoad(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pressure := &fixedPressure{pressure: ingest.Pressure{Level: ingest.PressureOverloaded, RetryAfter: 2500 * time.Millisecond}}
+	repo := repository.NewMockRepository()
+	saved := false
+	repo.SaveFunc = func(_ context.Context, _ *models.TelemetryData) error {
+		saved = true
+		return nil
+	}
+	handler := NewTelemetryHandler(repo, nil).WithPressure(pressure)
+
+	router := gin.New()
+	router.POST("/api/v1/telemetry", handler.HandlePost)
+	router.POST("/api/v1/telemetry/batch", handler.HandleBatchPost)
+	router.POST("/api/v1/telemetry/stream", handler.HandleStream)
+
+	for _, target := range []string{"/api/v1/telemetry", "/api/v1/telemetry/batch", "/api/v1/telemetry/stream"} {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(streamLine(t, time.Now().UTC(), "racebox-1")))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, target)
+		assert.Equal(t, "3", w.Header().Get("Retry-After"), target)
+	}
+	assert.False(t, saved)
+
+	// Elevated pressure still accepts uploads
+	pressure.pressure = ingest.Pressure{Level: ingest.PressureElevated, RetryAfter: time.Second}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/telemetry", strings.NewReader(streamLine(t, time.Now().UTC(), "racebox-1")))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.True(t, saved)
+}
+
+func TestTelemetryHandler_BufferFullRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		pressure   IngestPressure
+		retryAfter string
+	}{
+		{name: "fixed hint without a monitor", retryAfter: bufferRetryAfter},
+		{name: "estimated from the monitor", pressure: &fixedPressure{pressure: ingest.Pressure{Level: ingest.PressureElevated, RetryAfter: 7 * time.Second}}, retryAfter: "7"},
+		{name: "fixed hint at normal pressure", pressure: &fixedPressure{pressure: ingest.Pressure{Level: ingest.PressureNormal}}, retryAfter: bufferRetryAfter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewTelemetryHandler(repository.NewMockRepository(), nil).WithWriter(fullWriter{})
+			if tt.pressure != nil {
+				handler = handler.WithPressure(tt.pressure)
+			}
+			router := gin.New()
+			router.POST("/api/v1/telemetry", handler.HandlePost)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/telemetry", strings.NewReader(streamLine(t, time.Now().UTC(), "racebox-1")))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestTelemetryHandler_IngestStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	getStatus := func(t *testing.T, handler *TelemetryHandler) (*httptest.ResponseRecorder, map[string]interface{}) {
+		t.Helper()
+		router := gin.New()
+		router.GET("/api/v1/ingest/status", handler.HandleIngestStatus)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/ingest/status", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+
+	t.Run("normal without a monitor", func(t *testing.T) {
+		w, response := getStatus(t, NewTelemetryHandler(repository.NewMockRepository(), nil))
+
+		assert.Equal(t, "normal", response["status"])
+		assert.Equal(t, true, response["acceptingUploads"])
+		assert.Equal(t, float64(0), response["retryAfter"])
+		assert.Equal(t, false, response["buffered"])
+		assert.NotContains(t, response, "bufferUtilization")
+		assert.Empty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("overloaded buffer", func(t *testing.T) {
+		utilization := 0.95
+		pressure := &fixedPressure{pressure: ingest.Pressure{Level: ingest.PressureOverloaded, RetryAfter: 4 * time.Second, BufferUtilization: &utilization}}
+		handler := NewTelemetryHandler(repository.NewMockRepository(), nil).WithWriter(fullWriter{}).WithPressure(pressure)
+
+		w, response := getStatus(t, handler)
+		assert.Equal(t, "overloaded", response["status"])
+		assert.Equal(t, false, response["acceptingUploads"])
+		assert.Equal(t, float64(4), response["retryAfter"])
+		assert.Equal(t, true, response["buffered"])
+		assert.Equal(t, 0.95, response["bufferUtilization"])
+		assert.Equal(t, "4", w.Header().Get("Retry-After"))
+	})
+}
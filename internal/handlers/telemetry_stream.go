@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/ingest"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+const (
+	// streamChunkSize is the number of valid records written together while streaming
+	streamChunkSize = 1000
+
+	// maxStreamLineSize caps the length of a single NDJSON line
+	maxStreamLineSize = 1 << 20
+
+	// maxStreamErrors caps the rejected lines reported back; the count includes all of them
+	maxStreamErrors = 100
+
+	// streamBackpressureTimeout is how long a stream waits for room in a full write-behind buffer
+	streamBackpressureTimeout = 10 * time.Second
+
+	// streamBackpressurePoll is how often a waiting stream retries the write-behind buffer
+	streamBackpressurePoll = 50 * time.Millisecond
+)
+
+// streamLineError reports why a line of a telemetry stream was rejected
+type streamLineError struct {
+	Line   int                 `json:"line"` // 1-based line number in the request body
+	Error  string              `json:"error"`
+	Fields []models.FieldError `json:"fields,omitempty"` // Invalid fields of a line that failed validation
+}
+
+// streamSummary counts the outcome of a telemetry stream upload
+type streamSummary struct {
+	Lines    int               // Lines read, including blank ones
+	Accepted int               // Records stored or queued
+	Skipped  int               // Duplicates skipped by deduplication
+	Rejected int               // Lines that failed to parse or validate
+	Errors   []streamLineError // First rejected lines, up to maxStreamErrors
+}
+
+// telemetryStream holds the state of a single NDJSON upload
+type telemetryStream struct {
+	h             *TelemetryHandler
+	c             *gin.Context
+	userID        uuid.UUID
+	authenticated bool
+	claims        map[string]error // Claiming outcome per device, looked up once per stream
+	deviceID      string           // First device in the stream, reported to the audit log
+	summary       streamSummary
+}
+
+// HandleStream ingests newline-delimited JSON telemetry of any length
+// Each line holds one record. Valid records are written in chunks as the body is read,
+// through the write-behind buffer when configured, so uploads are not bound by the batch
+// size limit. Invalid lines are rejected individually and reported with their line number;
+// the rest of the stream is still processed. Failures that stop the stream report the
+// summary so far, since records before the failure have already been stored.
+// POST /api/v1/telemetry/stream
+func (h *TelemetryHandler) HandleStream(c *gin.Context) {
+	if h.shedLoad(c) {
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	authenticated := err == nil
+	if !authenticated && h.strict {
+		rejectAnonymousUpload(c)
+		return
+	}
+
+	s := &telemetryStream{
+		h:             h,
+		c:             c,
+		userID:        userID,
+		authenticated: authenticated,
+		claims:        make(map[string]error),
+		summary:       streamSummary{Errors: []streamLineError{}},
+	}
+
+	scanner := bufio.NewScanner(c.Request.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	chunk := make([]*models.TelemetryData, 0, streamChunkSize)
+	for scanner.Scan() {
+		s.summary.Lines++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		record, err := s.parse(line)
+		if err != nil {
+			s.reject(s.summary.Lines, err)
+			continue
+		}
+
+		chunk = append(chunk, record)
+		if len(chunk) == streamChunkSize {
+			if !s.flush(chunk) {
+				return
+			}
+			// The writer may still hold the flushed records, so start a new slice
+			chunk = make([]*models.TelemetryData, 0, streamChunkSize)
+		}
+	}
+
+	// Records read before a broken line are still valid
+	if len(chunk) > 0 && !s.flush(chunk) {
+		return
+	}
+
+	if err := scanner.Err(); err != nil {
+		failure := problem.BadRequest("invalid_stream", "Failed to read stream")
+		if errors.Is(err, bufio.ErrTooLong) {
+			failure = problem.BadRequest("line_too_long", fmt.Sprintf("Line %d exceeds %d bytes", s.summary.Lines+1, maxStreamLineSize))
+		}
+		s.fail(failure)
+		return
+	}
+
+	if s.summary.Accepted+s.summary.Skipped == 0 {
+		failure := problem.BadRequest("empty_stream", "Empty stream")
+		if s.summary.Rejected > 0 {
+			failure = problem.BadRequest("no_valid_records", "No valid records in stream")
+		}
+		s.fail(failure)
+		return
+	}
+
+	slog.Info("Stream telemetry finished", "accepted", s.summary.Accepted, "skipped", s.summary.Skipped, "rejected", s.summary.Rejected)
+
+	status := http.StatusCreated
+	if h.writer != nil {
+		status = http.StatusAccepted
+	}
+	s.respond(status, gin.H{
+		"message": fmt.Sprintf("Stream telemetry received (%d records)", s.summary.Accepted),
+	})
+}
+
+// parse decodes, validates and assigns ownership to a single line
+func (s *telemetryStream) parse(line []byte) (*models.TelemetryData, error) {
+	record, err := decodeTelemetry(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if s.deviceID == "" {
+		s.deviceID = record.DeviceID
+	}
+	if err := s.h.validate(record); err != nil {
+		return nil, err
+	}
+
+	// Device key uploads may only write telemetry for the key's device
+	if !applyDeviceKeyScope(s.c, record) {
+		return nil, errors.New("device key does not match deviceId")
+	}
+
+	if s.authenticated && s.h.deviceRepo != nil {
+		if err := s.claim(record); err != nil {
+			return nil, err
+		}
+	}
+
+	return record, nil
+}
+
+// claim associates the record with the uploader, claiming its device on first sight
+func (s *telemetryStream) claim(record *models.TelemetryData) error {
+	return s.h.claimRecord(s.c, record, s.userID, s.claims)
+}
+
+// reject records a rejected line
+func (s *telemetryStream) reject(line int, err error) {
+	s.summary.Rejected++
+	if len(s.summary.Errors) < maxStreamErrors {
+		s.summary.Errors = append(s.summary.Errors, streamLineError{Line: line, Error: err.Error(), Fields: invalidFields(err)})
+	}
+}
+
+// flush writes a chunk of valid records, responding and returning false if the stream must stop
+func (s *telemetryStream) flush(chunk []*models.TelemetryData) bool {
+	h := s.h
+	if s.authenticated && h.quotas != nil {
+		if usage, ok := h.quotas.allowTelemetry(s.c.Request.Context(), s.userID, len(chunk)); !ok {
+			setQuotaRetryAfter(s.c, usage, h.quotas.now())
+			s.fail(telemetryQuotaExceeded(usage))
+			return false
+		}
+	}
+
+	h.checkClocks(chunk)
+
+	stored := len(chunk)
+	if h.writer != nil {
+		if err := s.enqueue(chunk); err != nil {
+			slog.Warn("Rejecting telemetry stream", "error", err)
+			s.c.Header("Retry-After", h.retryAfter())
+			s.fail(problem.ServiceUnavailable("ingest_busy", "Telemetry ingest is busy, retry later"))
+			return false
+		}
+	} else {
+		if err := h.repo.SaveBatch(h.withBatchSaved(s.c.Request.Context()), chunk); err != nil {
+			slog.Error("Error saving telemetry stream chunk to database", "error", err)
+			s.fail(problem.Internal("Failed to save telemetry stream"))
+			return false
+		}
+		for _, record := range chunk {
+			if record.Duplicate {
+				stored--
+			}
+		}
+		notifyEvents(h.events)
+	}
+
+	s.summary.Accepted += stored
+	s.summary.Skipped += len(chunk) - stored
+
+	h.enqueueIngested(chunk)
+	if s.authenticated {
+		h.recordUsage(s.c, s.userID, stored)
+	}
+
+	return true
+}
+
+// enqueue hands a chunk to the write-behind buffer, waiting while it is full
+// Waiting stops reading the body, which slows the client down instead of failing the stream.
+func (s *telemetryStream) enqueue(chunk []*models.TelemetryData) error {
+	deadline := time.Now().Add(streamBackpressureTimeout)
+	for {
+		err := s.h.writer.Enqueue(chunk)
+		if !errors.Is(err, ingest.ErrBufferFull) || time.Now().After(deadline) {
+			return err
+		}
+
+		select {
+		case <-s.c.Request.Context().Done():
+			return s.c.Request.Context().Err()
+		case <-time.After(streamBackpressurePoll):
+		}
+	}
+}
+
+// respond writes the summary merged with the given fields
+func (s *telemetryStream) respond(status int, fields gin.H) {
+	s.setIngestDetails()
+
+	fields["lines"] = s.summary.Lines
+	fields["accepted"] = s.summary.Accepted
+	fields["skipped"] = s.summary.Skipped
+	fields["rejected"] = s.summary.Rejected
+	fields["errors"] = s.summary.Errors
+	api.Respond(s.c, status, fields)
+}
+
+// fail responds with the error, carrying the summary of the lines read so far
+func (s *telemetryStream) fail(err *problem.Error) {
+	s.setIngestDetails()
+
+	problem.Abort(s.c, err.
+		With("lines", s.summary.Lines).
+		With("accepted", s.summary.Accepted).
+		With("skipped", s.summary.Skipped).
+		With("rejected", s.summary.Rejected).
+		With("errors", s.summary.Errors))
+}
+
+// setIngestDetails reports the stream's device and record count to the audit log
+func (s *telemetryStream) setIngestDetails() {
+	middleware.SetIngestDetails(s.c, s.deviceID, s.summary.Accepted+s.summary.Skipped+s.summary.Rejected)
+}
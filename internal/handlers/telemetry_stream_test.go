@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/ingest"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// streamLine renders a valid telemetry record as an NDJSON line
+func streamLine(t *testing.T, ts time.Time, deviceID string) string {
+	t.Helper()
+	line, err := json.Marshal(models.TelemetryData{
+		Timestamp: ts,
+		DeviceID:  deviceID,
+		GPS:       models.GpsData{Latitude: 42.0, Longitude: 23.0, Speed: 100},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal record: %v", err)
+	}
+	return string(line)
+}
+
+// postStream sends an NDJSON body to the stream endpoint and decodes the summary
+func postStream(t *testing.T, router *gin.Engine, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/telemetry/stream", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return w, response
+}
+
+func TestTelemetryHandler_Stream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now().UTC()
+
+	t.Run("valid lines are saved and invalid ones reported", func(t *testing.T) {
+		repo := repository.NewMockRepository()
+		saved := 0
+		repo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+			saved += len(data)
+			return nil
+		}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/stream", NewTelemetryHandler(repo, nil).HandleStream)
+
+		body := strings.Join([]string{
+			streamLine(t, now, "racebox-1"),
+			"not json",
+			"",
+			`{"timestamp":"` + now.Format(time.RFC3339) + `","gps":{"latitude":95,"longitude":23}}`,
+			streamLine(t, now.Add(time.Second), "racebox-1"),
+		}, "\n")
+
+		w, response := postStream(t, router, body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if saved != 2 {
+			t.Errorf("Expected 2 saved records, got %d", saved)
+		}
+		if response["accepted"] != float64(2) || response["rejected"] != float64(2) || response["lines"] != float64(5) {
+			t.Errorf("Unexpected summary: %v", response)
+		}
+
+		errs, _ := response["errors"].([]interface{})
+		if len(errs) != 2 {
+			t.Fatalf("Expected 2 line errors, got %v", response["errors"])
+		}
+		for i, wantLine := range []float64{2, 4} {
+			lineErr, _ := errs[i].(map[string]interface{})
+			if lineErr["line"] != wantLine {
+				t.Errorf("Expected error on line %v, got %v", wantLine, lineErr["line"])
+			}
+		}
+	})
+
+	t.Run("records are written in chunks beyond the batch limit", func(t *testing.T) {
+		repo := repository.NewMockRepository()
+		var chunks []int
+		repo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+			chunks = append(chunks, len(data))
+			return nil
+		}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/stream", NewTelemetryHandler(repo, nil).HandleStream)
+
+		lines := make([]string, 2500)
+		for i := range lines {
+			lines[i] = streamLine(t, now.Add(time.Duration(i)*time.Millisecond), "racebox-1")
+		}
+
+		w, response := postStream(t, router, strings.Join(lines, "\n")+"\n")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if fmt.Sprint(chunks) != "[1000 1000 500]" {
+			t.Errorf("Expected chunks [1000 1000 500], got %v", chunks)
+		}
+		if response["accepted"] != float64(2500) {
+			t.Errorf("Expected 2500 accepted, got %v", response["accepted"])
+		}
+	})
+
+	t.Run("duplicates are counted as skipped", func(t *testing.T) {
+		repo := repository.NewMockRepository()
+		repo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+			data[0].Duplicate = true
+			return nil
+		}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/stream", NewTelemetryHandler(repo, nil).HandleStream)
+
+		body := streamLine(t, now, "racebox-1") + "\n" + streamLine(t, now.Add(time.Second), "racebox-1")
+		w, response := postStream(t, router, body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if response["accepted"] != float64(1) || response["skipped"] != float64(1) {
+			t.Errorf("Expected 1 accepted and 1 skipped, got %v", response)
+		}
+	})
+
+	t.Run("buffered writes are accepted", func(t *testing.T) {
+		writer := &queueingWriter{}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/stream", NewTelemetryHandler(repository.NewMockRepository(), nil).WithWriter(writer).HandleStream)
+
+		w, _ := postStream(t, router, streamLine(t, now, "racebox-1"))
+		if w.Code != http.StatusAccepted {
+			t.Errorf("Expected status %d, got %d", http.StatusAccepted, w.Code)
+		}
+		if len(writer.records) != 1 {
+			t.Errorf("Expected 1 queued record, got %d", len(writer.records))
+		}
+	})
+
+	t.Run("full buffer stops the stream with 503", func(t *testing.T) {
+		writer := &queueingWriter{err: ingest.ErrWriterClosed}
+		router := gin.New()
+		router.POST("/api/v1/telemetry/stream", NewTelemetryHandler(repository.NewMockRepository(), nil).WithWriter(writer).HandleStream)
+
+		w, _ := postStream(t, router, streamLine(t, now, "racebox-1"))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Error("Expected Retry-After header on 503 response")
+		}
+	})
+
+	t.Run("empty and fully invalid streams return 400", func(t *testing.T) {
+		router := gin.New()
+		router.POST("/api/v1/telemetry/stream", NewTelemetryHandler(repository.NewMockRepository(), nil).HandleStream)
+
+		if w, response := postStream(t, router, "\n\n"); w.Code != http.StatusBadRequest || response["code"] != "empty_stream" {
+			t.Errorf("Expected empty stream error, got %d %v", w.Code, response["code"])
+		}
+		if w, response := postStream(t, router, "nope\n"); w.Code != http.StatusBadRequest || response["rejected"] != float64(1) {
+			t.Errorf("Expected invalid stream error, got %d %v", w.Code, response)
+		}
+	})
+
+	t.Run("lines for devices owned by others are rejected", func(t *testing.T) {
+		userID := uuid.New()
+		deviceRepo := repository.NewMockDeviceRepository()
+		lookups := 0
+		deviceRepo.GetByDeviceIDFunc = func(_ context.Context, id string) (*models.Device, error) {
+			lookups++
+			owner := userID
+			if id == "someone-elses" {
+				owner = uuid.New()
+			}
+			return &models.Device{ID: uuid.New(), DeviceID: id, UserID: owner, IsActive: true}, nil
+		}
+		repo := repository.NewMockRepository()
+		var saved []*models.TelemetryData
+		repo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+			saved = append(saved, data...)
+			return nil
+		}
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(string(middleware.UserIDKey), userID)
+			c.Next()
+		})
+		router.POST("/api/v1/telemetry/stream", NewTelemetryHandler(repo, deviceRepo).HandleStream)
+
+		body := strings.Join([]string{
+			streamLine(t, now, "racebox-1"),
+			streamLine(t, now, "someone-elses"),
+			streamLine(t, now.Add(time.Second), "racebox-1"),
+			streamLine(t, now.Add(time.Second), "someone-elses"),
+		}, "\n")
+
+		w, response := postStream(t, router, body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if response["accepted"] != float64(2) || response["rejected"] != float64(2) {
+			t.Errorf("Expected 2 accepted and 2 rejected, got %v", response)
+		}
+		if lookups != 2 {
+			t.Errorf("Expected one device lookup per device, got %d", lookups)
+		}
+		for _, record := range saved {
+			if record.UserID == nil || *record.UserID != userID {
+				t.Errorf("Expected saved records to be owned by the uploader")
+			}
+		}
+	})
+
+	t.Run("strict ownership rejects anonymous streams", func(t *testing.T) {
+		router := gin.New()
+		router.POST("/api/v1/telemetry/stream", NewTelemetryHandler(repository.NewMockRepository(), nil).WithStrictOwnership(true).HandleStream)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/telemetry/stream", strings.NewReader(streamLine(t, now, "racebox-1")))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+}
@@ -13,7 +13,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/sebasr/avt-service/internal/ingest"
+	"github.com/sebasr/avt-service/internal/middleware"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
 	"github.com/sebasr/avt-service/internal/repository"
 )
 
@@ -73,8 +76,8 @@ func TestTelemetryHandler(t *testing.T) {
 			payload:        "invalid json",
 			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
-				if err, ok := resp["error"].(string); !ok || err != "Invalid JSON payload" {
-					t.Errorf("Expected invalid JSON error, got %v", resp["error"])
+				if code, ok := resp["code"].(string); !ok || code != "invalid_json" {
+					t.Errorf("Expected invalid JSON error, got %v", resp["code"])
 				}
 			},
 		},
@@ -92,14 +95,17 @@ func TestTelemetryHandler(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
-				if err, ok := resp["error"].(string); !ok || err != "Validation failed" {
-					t.Errorf("Expected validation failed error, got %v", resp["error"])
+				if code, ok := resp["code"].(string); !ok || code != "validation_failed" {
+					t.Errorf("Expected validation failed error, got %v", resp["code"])
 				}
-				// Check that details mention timestamp
-				if details, ok := resp["details"].(string); ok {
-					if details != "timestamp is required" {
-						t.Errorf("Expected timestamp validation details, got %v", details)
-					}
+				// Check that the detail mentions timestamp
+				if detail, ok := resp["detail"].(string); !ok || detail != "Validation failed: timestamp is required" {
+					t.Errorf("Expected timestamp validation detail, got %v", resp["detail"])
+				}
+				// Check that the invalid field is reported
+				fields, ok := resp["fields"].([]interface{})
+				if !ok || len(fields) != 1 || fields[0].(map[string]interface{})["field"] != "timestamp" {
+					t.Errorf("Expected timestamp field error, got %v", resp["fields"])
 				}
 			},
 		},
@@ -242,8 +248,8 @@ func TestTelemetryHandlerDatabaseError(t *testing.T) {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
 
-	if err, ok := response["error"].(string); !ok || err != "Failed to save telemetry data" {
-		t.Errorf("Expected database error message, got %v", response["error"])
+	if detail, ok := response["detail"].(string); !ok || detail != "Failed to save telemetry data" {
+		t.Errorf("Expected database error message, got %v", response["detail"])
 	}
 }
 
@@ -365,8 +371,8 @@ func TestTelemetryHandler_BatchPost(t *testing.T) {
 			payload:        []models.TelemetryData{},
 			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
-				if err, ok := resp["error"].(string); !ok || err != "Empty batch" {
-					t.Errorf("Expected empty batch error, got %v", resp["error"])
+				if code, ok := resp["code"].(string); !ok || code != "empty_batch" {
+					t.Errorf("Expected empty batch error, got %v", resp["code"])
 				}
 			},
 		},
@@ -375,8 +381,8 @@ func TestTelemetryHandler_BatchPost(t *testing.T) {
 			payload:        "not valid json",
 			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
-				if err, ok := resp["error"].(string); !ok || err != "Invalid JSON payload" {
-					t.Errorf("Expected invalid JSON error, got %v", resp["error"])
+				if code, ok := resp["code"].(string); !ok || code != "invalid_json" {
+					t.Errorf("Expected invalid JSON error, got %v", resp["code"])
 				}
 			},
 		},
@@ -398,14 +404,12 @@ func TestTelemetryHandler_BatchPost(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
-				if err, ok := resp["error"].(string); !ok || err != "Validation failed for record 1" {
-					t.Errorf("Expected validation failed error, got %v", resp["error"])
+				if code, ok := resp["code"].(string); !ok || code != "validation_failed" || resp["record"] != float64(1) {
+					t.Errorf("Expected validation failed error for record 1, got %v", resp)
 				}
-				// Check that details mention timestamp
-				if details, ok := resp["details"].(string); ok {
-					if details != "timestamp is required" {
-						t.Errorf("Expected timestamp validation details, got %v", details)
-					}
+				// Check that the detail mentions timestamp
+				if detail, ok := resp["detail"].(string); !ok || detail != "Validation failed for record 1: timestamp is required" {
+					t.Errorf("Expected timestamp validation detail, got %v", resp["detail"])
 				}
 			},
 		},
@@ -503,8 +507,112 @@ func TestTelemetryHandler_BatchPostTooLarge(t *testing.T) {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
 
-	if err, ok := response["error"].(string); !ok || err != "Batch too large (max 1000 records)" {
-		t.Errorf("Expected batch too large error, got %v", response["error"])
+	if detail, ok := response["detail"].(string); !ok || detail != "Batch too large (max 1000 records)" {
+		t.Errorf("Expected batch too large error, got %v", response["detail"])
+	}
+}
+
+func TestTelemetryHandler_BatchPostConfiguredLimits(t *testing.T) {
+	now := time.Now().UTC()
+	batch := make([]models.TelemetryData, 3)
+	for i := range batch {
+		batch[i] = models.TelemetryData{
+			ITOW:      int64(118286240 + i),
+			Timestamp: now.Add(time.Duration(i) * time.Millisecond),
+			GPS:       models.GpsData{Latitude: 42.0, Longitude: 23.0},
+		}
+	}
+	body, _ := json.Marshal(batch)
+
+	send := func(handler *TelemetryHandler, maxBytes int64) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/api/telemetry/batch", middleware.NewBodyLimitMiddleware(maxBytes), handler.HandleBatchPost)
+
+		req, _ := http.NewRequest("POST", "/api/telemetry/batch", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		// Unknown length, so the limit is enforced while the body is decoded
+		req.ContentLength = -1
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("max batch records", func(t *testing.T) {
+		handler := NewTelemetryHandler(repository.NewMockRepository(), &repository.MockDeviceRepository{}).
+			WithMaxBatchRecords(2)
+		w := send(handler, 0)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if detail, ok := response["detail"].(string); !ok || detail != "Batch too large (max 2 records)" {
+			t.Errorf("Expected batch too large error, got %v", response["detail"])
+		}
+	})
+
+	t.Run("body over limit while decoding", func(t *testing.T) {
+		handler := NewTelemetryHandler(repository.NewMockRepository(), &repository.MockDeviceRepository{})
+		w := send(handler, 64)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+		}
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if response["code"] != "request_too_large" || response["limit"] != float64(64) {
+			t.Errorf("Expected request_too_large with limit 64, got %v", response)
+		}
+	})
+}
+
+func TestTelemetryHandler_LenientValidation(t *testing.T) {
+	// Battery is out of range and speed is flagged as impossible, coordinates are valid
+	telemetry := models.TelemetryData{
+		ITOW:      118286240,
+		Timestamp: time.Now().UTC(),
+		GPS:       models.GpsData{Latitude: 42.0, Longitude: 23.0, Speed: 900},
+		Battery:   250,
+	}
+	invalidCoordinates := telemetry
+	invalidCoordinates.GPS.Latitude = 95
+
+	tests := []struct {
+		name           string
+		lenient        bool
+		payload        models.TelemetryData
+		expectedStatus int
+	}{
+		{"strict rejects out-of-range readings", false, telemetry, http.StatusBadRequest},
+		{"lenient accepts out-of-range readings", true, telemetry, http.StatusCreated},
+		{"lenient still rejects invalid coordinates", true, invalidCoordinates, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewTelemetryHandler(repository.NewMockRepository(), &repository.MockDeviceRepository{}).
+				WithLenientValidation(tt.lenient)
+
+			router := gin.New()
+			router.POST("/api/telemetry", handler.HandlePost)
+
+			body, _ := json.Marshal(tt.payload)
+			req, _ := http.NewRequest("POST", "/api/telemetry", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
 	}
 }
 
@@ -549,8 +657,8 @@ func TestTelemetryHandler_BatchPostDatabaseError(t *testing.T) {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
 
-	if err, ok := response["error"].(string); !ok || err != "Failed to save telemetry batch" {
-		t.Errorf("Expected database error message, got %v", response["error"])
+	if detail, ok := response["detail"].(string); !ok || detail != "Failed to save telemetry batch" {
+		t.Errorf("Expected database error message, got %v", response["detail"])
 	}
 }
 
@@ -641,15 +749,15 @@ func TestTelemetryHandler_WithAuthentication(t *testing.T) {
 		mockRepo := repository.NewMockRepository()
 		mockDeviceRepo := &repository.MockDeviceRepository{}
 
-		// Mock device creation
+		// Mock device claiming
 		var createdDevice *models.Device
-		mockDeviceRepo.CreateFunc = func(_ context.Context, device *models.Device) error {
+		mockDeviceRepo.ClaimFunc = func(_ context.Context, device *models.Device) (*models.Device, error) {
 			createdDevice = device
-			return nil
+			return device, nil
 		}
 
 		mockDeviceRepo.GetByDeviceIDFunc = func(_ context.Context, _ string) (*models.Device, error) {
-			return nil, errors.New("device not found")
+			return nil, repository.ErrDeviceNotFound
 		}
 
 		handler := NewTelemetryHandler(mockRepo, mockDeviceRepo)
@@ -750,6 +858,86 @@ func TestTelemetryHandler_WithAuthentication(t *testing.T) {
 		}
 	})
 
+	// Two first uploads from a device race to claim it; the loser reads the winner's device
+	lostClaim := func(owner uuid.UUID, claimErr error) (*httptest.ResponseRecorder, bool) {
+		mockDeviceRepo := repository.NewMockDeviceRepository()
+		mockDeviceRepo.ClaimFunc = func(_ context.Context, device *models.Device) (*models.Device, error) {
+			return &models.Device{ID: uuid.New(), DeviceID: device.DeviceID, UserID: owner, IsActive: true}, claimErr
+		}
+		lastSeenUpdated := false
+		mockDeviceRepo.UpdateLastSeenFunc = func(_ context.Context, _ string) error {
+			lastSeenUpdated = true
+			return nil
+		}
+
+		handler := NewTelemetryHandler(repository.NewMockRepository(), mockDeviceRepo)
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Next()
+		})
+		router.POST("/api/telemetry", handler.HandlePost)
+
+		body, _ := json.Marshal(models.TelemetryData{Timestamp: time.Now().UTC(), DeviceID: deviceID})
+		req, _ := http.NewRequest("POST", "/api/telemetry", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w, lastSeenUpdated
+	}
+
+	t.Run("device claimed concurrently by the same user", func(t *testing.T) {
+		w, lastSeenUpdated := lostClaim(userID, nil)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if !lastSeenUpdated {
+			t.Error("Expected last_seen to be updated")
+		}
+	})
+
+	t.Run("device claimed concurrently by another user", func(t *testing.T) {
+		w, _ := lostClaim(uuid.New(), repository.ErrDeviceClaimed)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d. Body: %s", http.StatusForbidden, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("device lookup failure is not treated as a new device", func(t *testing.T) {
+		mockDeviceRepo := repository.NewMockDeviceRepository()
+		mockDeviceRepo.GetByDeviceIDFunc = func(_ context.Context, _ string) (*models.Device, error) {
+			return nil, errors.New("connection refused")
+		}
+		mockDeviceRepo.ClaimFunc = func(_ context.Context, device *models.Device) (*models.Device, error) {
+			t.Error("Device should not be claimed when the lookup fails")
+			return device, nil
+		}
+
+		handler := NewTelemetryHandler(repository.NewMockRepository(), mockDeviceRepo)
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Next()
+		})
+		router.POST("/api/telemetry", handler.HandlePost)
+
+		body, _ := json.Marshal(models.TelemetryData{Timestamp: time.Now().UTC(), DeviceID: deviceID})
+		req, _ := http.NewRequest("POST", "/api/telemetry", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+
 	t.Run("authenticated user without device ID", func(t *testing.T) {
 		mockRepo := repository.NewMockRepository()
 		mockDeviceRepo := &repository.MockDeviceRepository{}
@@ -827,13 +1015,13 @@ func TestTelemetryHandler_BatchWithAuthentication(t *testing.T) {
 		mockDeviceRepo := &repository.MockDeviceRepository{}
 
 		var createdDevice *models.Device
-		mockDeviceRepo.CreateFunc = func(_ context.Context, device *models.Device) error {
+		mockDeviceRepo.ClaimFunc = func(_ context.Context, device *models.Device) (*models.Device, error) {
 			createdDevice = device
-			return nil
+			return device, nil
 		}
 
 		mockDeviceRepo.GetByDeviceIDFunc = func(_ context.Context, _ string) (*models.Device, error) {
-			return nil, errors.New("device not found")
+			return nil, repository.ErrDeviceNotFound
 		}
 
 		handler := NewTelemetryHandler(mockRepo, mockDeviceRepo)
@@ -910,3 +1098,1058 @@ func TestTelemetryHandler_BatchWithAuthentication(t *testing.T) {
 		}
 	})
 }
+
+// recordingGeofenceEvaluator captures the telemetry enqueued for geofence evaluation
+type recordingGeofenceEvaluator struct {
+	records []*models.TelemetryData
+}
+
+func (e *recordingGeofenceEvaluator) Enqueue(records []*models.TelemetryData) {
+	e.records = append(e.records, records...)
+}
+
+func TestTelemetryHandler_EnqueuesGeofenceEvaluation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	now := time.Now().UTC()
+
+	t.Run("batch upload is enqueued after saving", func(t *testing.T) {
+		evaluator := &recordingGeofenceEvaluator{}
+		handler := NewTelemetryHandler(repository.NewMockRepository(), nil).WithGeofenceEvaluator(evaluator)
+
+		router := gin.New()
+		router.POST("/api/telemetry/batch", handler.HandleBatchPost)
+
+		batch := []models.TelemetryData{
+			{Timestamp: now, DeviceID: "device-1", UserID: &userID},
+			{Timestamp: now.Add(time.Second), DeviceID: "device-1", UserID: &userID},
+		}
+		body, _ := json.Marshal(batch)
+		req, _ := http.NewRequest("POST", "/api/telemetry/batch", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if len(evaluator.records) != 2 {
+			t.Errorf("Expected 2 records enqueued, got %d", len(evaluator.records))
+		}
+	})
+
+	t.Run("failed save is not enqueued", func(t *testing.T) {
+		mockRepo := repository.NewMockRepository()
+		mockRepo.SaveFunc = func(_ context.Context, _ *models.TelemetryData) error {
+			return errors.New("database error")
+		}
+		evaluator := &recordingGeofenceEvaluator{}
+		handler := NewTelemetryHandler(mockRepo, nil).WithGeofenceEvaluator(evaluator)
+
+		router := gin.New()
+		router.POST("/api/telemetry", handler.HandlePost)
+
+		body, _ := json.Marshal(models.TelemetryData{Timestamp: now, DeviceID: "device-1"})
+		req, _ := http.NewRequest("POST", "/api/telemetry", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+		if len(evaluator.records) != 0 {
+			t.Errorf("Expected no records enqueued, got %d", len(evaluator.records))
+		}
+	})
+}
+
+// queueingWriter is a TelemetryWriter that records queued uploads or rejects them
+type queueingWriter struct {
+	records []*models.TelemetryData
+	err     error
+}
+
+func (w *queueingWriter) Enqueue(records []*models.TelemetryData) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.records = append(w.records, records...)
+	return nil
+}
+
+func TestTelemetryHandler_BufferedWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now().UTC()
+
+	newRouter := func(writer *queueingWriter, repo *repository.MockRepository) *gin.Engine {
+		handler := NewTelemetryHandler(repo, nil).WithWriter(writer)
+		router := gin.New()
+		router.POST("/api/telemetry", handler.HandlePost)
+		router.POST("/api/telemetry/batch", handler.HandleBatchPost)
+		return router
+	}
+	post := func(router *gin.Engine, path string, payload interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("uploads are queued instead of saved", func(t *testing.T) {
+		repo := repository.NewMockRepository()
+		repo.SaveFunc = func(_ context.Context, _ *models.TelemetryData) error {
+			t.Error("Save should not be called when buffering")
+			return nil
+		}
+		repo.SaveBatchFunc = func(_ context.Context, _ []*models.TelemetryData) error {
+			t.Error("SaveBatch should not be called when buffering")
+			return nil
+		}
+		writer := &queueingWriter{}
+		router := newRouter(writer, repo)
+
+		w := post(router, "/api/telemetry", models.TelemetryData{Timestamp: now, DeviceID: "device-1"})
+		if w.Code != http.StatusAccepted {
+			t.Errorf("Expected status %d, got %d", http.StatusAccepted, w.Code)
+		}
+
+		w = post(router, "/api/telemetry/batch", []models.TelemetryData{
+			{Timestamp: now, DeviceID: "device-1"},
+			{Timestamp: now.Add(time.Second), DeviceID: "device-1"},
+		})
+		if w.Code != http.StatusAccepted {
+			t.Errorf("Expected status %d, got %d", http.StatusAccepted, w.Code)
+		}
+
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if response["count"] != float64(2) {
+			t.Errorf("Expected count 2, got %v", response["count"])
+		}
+		if _, ok := response["ids"]; ok {
+			t.Error("Buffered responses should not include record IDs")
+		}
+		if len(writer.records) != 3 {
+			t.Errorf("Expected 3 queued records, got %d", len(writer.records))
+		}
+	})
+
+	t.Run("full buffer returns 503 with Retry-After", func(t *testing.T) {
+		router := newRouter(&queueingWriter{err: errors.New("ingest buffer is full")}, repository.NewMockRepository())
+
+		w := post(router, "/api/telemetry/batch", []models.TelemetryData{{Timestamp: now, DeviceID: "device-1"}})
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Error("Expected Retry-After header on 503 response")
+		}
+	})
+
+	t.Run("clocks are checked on receipt, before queueing", func(t *testing.T) {
+		writer := &queueingWriter{}
+		evaluator := &recordingGeofenceEvaluator{}
+		handler := NewTelemetryHandler(repository.NewMockRepository(), nil).
+			WithWriter(writer).
+			WithGeofenceEvaluator(evaluator).
+			WithClockPolicy(ingest.NewClockPolicy(ingest.ClockSkewCorrect, 2*time.Second))
+		router := gin.New()
+		router.POST("/api/telemetry", handler.HandlePost)
+
+		// A device clock running ten minutes ahead
+		reported := time.Now().UTC().Add(10 * time.Minute)
+		w := post(router, "/api/telemetry", models.TelemetryData{Timestamp: reported, DeviceID: "device-1"})
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected status %d, got %d", http.StatusAccepted, w.Code)
+		}
+
+		if len(writer.records) != 1 || len(evaluator.records) != 1 {
+			t.Fatalf("Expected the record queued and enqueued once, got %d and %d", len(writer.records), len(evaluator.records))
+		}
+		record := evaluator.records[0]
+		if record.ReceivedAt == nil || record.DeviceTimestamp == nil || !record.DeviceTimestamp.Equal(reported) {
+			t.Errorf("Expected receive time and reported timestamp to be kept, got %v and %v", record.ReceivedAt, record.DeviceTimestamp)
+		}
+		if !record.Timestamp.Equal(*record.ReceivedAt) {
+			t.Errorf("Expected timestamp corrected to the receive time, got %v", record.Timestamp)
+		}
+	})
+}
+
+func TestTelemetryHandler_Duplicates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now().UTC()
+
+	// The repository marks records already stored for the same device, iTOW and timestamp
+	stored := map[int64]bool{1000: true}
+	nextID := int64(1)
+	markDuplicate := func(data *models.TelemetryData) {
+		if stored[data.ITOW] {
+			data.Duplicate = true
+			return
+		}
+		stored[data.ITOW] = true
+		data.ID = nextID
+		nextID++
+	}
+
+	mockRepo := repository.NewMockRepository()
+	mockRepo.SaveFunc = func(_ context.Context, data *models.TelemetryData) error {
+		markDuplicate(data)
+		return nil
+	}
+	mockRepo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+		for _, record := range data {
+			markDuplicate(record)
+		}
+		return nil
+	}
+
+	handler := NewTelemetryHandler(mockRepo, nil)
+	router := gin.New()
+	router.POST("/api/telemetry", handler.HandlePost)
+	router.POST("/api/telemetry/batch", handler.HandleBatchPost)
+
+	post := func(path string, payload interface{}) (int, map[string]interface{}) {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return w.Code, response
+	}
+
+	t.Run("batch reports inserted and skipped counts", func(t *testing.T) {
+		code, response := post("/api/telemetry/batch", []models.TelemetryData{
+			{Timestamp: now, DeviceID: "device-1", ITOW: 1000},
+			{Timestamp: now.Add(time.Second), DeviceID: "device-1", ITOW: 2000},
+			{Timestamp: now.Add(2 * time.Second), DeviceID: "device-1", ITOW: 3000},
+		})
+		if code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, code)
+		}
+		if response["count"] != float64(3) {
+			t.Errorf("Expected count 3, got %v", response["count"])
+		}
+		if response["inserted"] != float64(2) || response["skipped"] != float64(1) {
+			t.Errorf("Expected 2 inserted and 1 skipped, got %v and %v", response["inserted"], response["skipped"])
+		}
+		if ids, ok := response["ids"].([]interface{}); !ok || len(ids) != 2 {
+			t.Errorf("Expected IDs for the 2 inserted records, got %v", response["ids"])
+		}
+	})
+
+	t.Run("duplicate single record returns 200", func(t *testing.T) {
+		code, response := post("/api/telemetry", models.TelemetryData{Timestamp: now, DeviceID: "device-1", ITOW: 1000})
+		if code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+		if response["duplicate"] != true {
+			t.Errorf("Expected duplicate flag, got %v", response["duplicate"])
+		}
+		if _, ok := response["id"]; ok {
+			t.Error("Skipped records should not report an ID")
+		}
+	})
+}
+
+func TestTelemetryHandler_ClientIDReplay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now().UTC()
+
+	// The repository skips records repeating a stored clientId and reports the stored ID
+	stored := map[uuid.UUID]int64{}
+	mockRepo := repository.NewMockRepository()
+	mockRepo.SaveFunc = func(_ context.Context, data *models.TelemetryData) error {
+		if id, ok := stored[*data.ClientID]; ok {
+			data.ID = id
+			data.Duplicate = true
+			return nil
+		}
+		data.ID = int64(len(stored) + 1)
+		stored[*data.ClientID] = data.ID
+		return nil
+	}
+
+	handler := NewTelemetryHandler(mockRepo, nil)
+	router := gin.New()
+	router.POST("/api/telemetry", handler.HandlePost)
+
+	clientID := uuid.New()
+	post := func() (int, map[string]interface{}) {
+		body, _ := json.Marshal(models.TelemetryData{ClientID: &clientID, Timestamp: now, DeviceID: "device-1", ITOW: 1000})
+		req, _ := http.NewRequest("POST", "/api/telemetry", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return w.Code, response
+	}
+
+	code, first := post()
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, code)
+	}
+
+	code, replay := post()
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d for a replay, got %d", http.StatusOK, code)
+	}
+	if replay["duplicate"] != true {
+		t.Errorf("Expected duplicate flag, got %v", replay["duplicate"])
+	}
+	if replay["id"] != first["id"] {
+		t.Errorf("Expected the stored ID %v, got %v", first["id"], replay["id"])
+	}
+}
+
+func TestTelemetryHandler_WithDeviceKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	deviceID := "RACEBOX-KEY-001"
+
+	setupRouter := func(saved *[]*models.TelemetryData) *gin.Engine {
+		mockRepo := repository.NewMockRepository()
+		mockRepo.SaveFunc = func(_ context.Context, data *models.TelemetryData) error {
+			*saved = append(*saved, data)
+			return nil
+		}
+		mockRepo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+			*saved = append(*saved, data...)
+			return nil
+		}
+
+		mockDeviceRepo := repository.NewMockDeviceRepository()
+		mockDeviceRepo.GetByDeviceIDFunc = func(_ context.Context, id string) (*models.Device, error) {
+			return &models.Device{ID: uuid.New(), DeviceID: id, UserID: userID, IsActive: true}, nil
+		}
+
+		handler := NewTelemetryHandler(mockRepo, mockDeviceRepo)
+
+		router := gin.New()
+		// Simulate device key middleware setting device context
+		router.Use(func(c *gin.Context) {
+			c.Set(string(middleware.UserIDKey), userID)
+			c.Set(string(middleware.DeviceIDKey), deviceID)
+			c.Next()
+		})
+		router.POST("/api/telemetry", handler.HandlePost)
+		router.POST("/api/telemetry/batch", handler.HandleBatchPost)
+		return router
+	}
+
+	t.Run("record without deviceId inherits key device", func(t *testing.T) {
+		var saved []*models.TelemetryData
+		router := setupRouter(&saved)
+
+		body, _ := json.Marshal(models.TelemetryData{
+			Timestamp: time.Now().UTC(),
+			GPS:       models.GpsData{Latitude: 42.0, Longitude: 23.0},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/telemetry", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if len(saved) != 1 || saved[0].DeviceID != deviceID {
+			t.Fatalf("Expected telemetry saved for %s, got %+v", deviceID, saved)
+		}
+		if saved[0].UserID == nil || *saved[0].UserID != userID {
+			t.Errorf("Expected user ID %s, got %v", userID, saved[0].UserID)
+		}
+	})
+
+	t.Run("record for another device is rejected", func(t *testing.T) {
+		var saved []*models.TelemetryData
+		router := setupRouter(&saved)
+
+		body, _ := json.Marshal([]models.TelemetryData{
+			{Timestamp: time.Now().UTC(), DeviceID: deviceID, GPS: models.GpsData{Latitude: 42.0, Longitude: 23.0}},
+			{Timestamp: time.Now().UTC(), DeviceID: "OTHER-DEVICE", GPS: models.GpsData{Latitude: 42.0, Longitude: 23.0}},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/telemetry/batch", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+		if len(saved) != 0 {
+			t.Errorf("Expected nothing saved, got %d records", len(saved))
+		}
+	})
+}
+
+func TestTelemetryHandler_FirmwareVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	deviceID := "RACEBOX-FW-001"
+
+	post := func(mockDeviceRepo *repository.MockDeviceRepository, firmware string) *httptest.ResponseRecorder {
+		handler := NewTelemetryHandler(repository.NewMockRepository(), mockDeviceRepo)
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(string(middleware.UserIDKey), userID)
+			c.Next()
+		})
+		router.POST("/api/telemetry", middleware.NewFirmwareMiddleware(nil, false), handler.HandlePost)
+
+		body, _ := json.Marshal(models.TelemetryData{Timestamp: time.Now().UTC(), DeviceID: deviceID})
+		req := httptest.NewRequest(http.MethodPost, "/api/telemetry", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.FirmwareVersionHeader, firmware)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("new device is claimed with its firmware version", func(t *testing.T) {
+		var claimed *models.Device
+		mockDeviceRepo := repository.NewMockDeviceRepository()
+		mockDeviceRepo.ClaimFunc = func(_ context.Context, device *models.Device) (*models.Device, error) {
+			claimed = device
+			return device, nil
+		}
+
+		w := post(mockDeviceRepo, "v2.4")
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if claimed == nil || claimed.FirmwareVersion == nil || *claimed.FirmwareVersion != "2.4.0" {
+			t.Errorf("Expected device claimed with firmware 2.4.0, got %+v", claimed)
+		}
+	})
+
+	t.Run("changed firmware version is recorded", func(t *testing.T) {
+		existing := &models.Device{ID: uuid.New(), DeviceID: deviceID, UserID: userID, IsActive: true}
+		mockDeviceRepo := repository.NewMockDeviceRepository()
+		mockDeviceRepo.GetByDeviceIDFunc = func(_ context.Context, _ string) (*models.Device, error) {
+			return existing, nil
+		}
+		var recorded string
+		mockDeviceRepo.UpdateFirmwareFunc = func(_ context.Context, id uuid.UUID, version string) error {
+			if id != existing.ID {
+				t.Errorf("Expected firmware recorded for %s, got %s", existing.ID, id)
+			}
+			recorded = version
+			return nil
+		}
+
+		w := post(mockDeviceRepo, "2.5.0-rc.1")
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if recorded != "2.5.0-rc.1" {
+			t.Errorf("Expected firmware 2.5.0-rc.1 recorded, got %q", recorded)
+		}
+	})
+
+	t.Run("unchanged firmware version is not written again", func(t *testing.T) {
+		current := "2.5.0"
+		mockDeviceRepo := repository.NewMockDeviceRepository()
+		mockDeviceRepo.GetByDeviceIDFunc = func(_ context.Context, _ string) (*models.Device, error) {
+			return &models.Device{ID: uuid.New(), DeviceID: deviceID, UserID: userID, IsActive: true, FirmwareVersion: &current}, nil
+		}
+		mockDeviceRepo.UpdateFirmwareFunc = func(_ context.Context, _ uuid.UUID, _ string) error {
+			t.Error("Firmware version should not be updated")
+			return nil
+		}
+
+		w := post(mockDeviceRepo, "2.5")
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+	})
+}
+
+func TestTelemetryHandler_StrictOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ownerID := uuid.New()
+	deviceID := "RACEBOX-STRICT-001"
+
+	setupRouter := func(userID *uuid.UUID, saved *[]*models.TelemetryData) *gin.Engine {
+		mockRepo := repository.NewMockRepository()
+		mockRepo.SaveFunc = func(_ context.Context, data *models.TelemetryData) error {
+			*saved = append(*saved, data)
+			return nil
+		}
+		mockRepo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+			*saved = append(*saved, data...)
+			return nil
+		}
+
+		mockDeviceRepo := repository.NewMockDeviceRepository()
+		mockDeviceRepo.GetByDeviceIDFunc = func(_ context.Context, id string) (*models.Device, error) {
+			return &models.Device{ID: uuid.New(), DeviceID: id, UserID: ownerID, IsActive: true}, nil
+		}
+
+		handler := NewTelemetryHandler(mockRepo, mockDeviceRepo).WithStrictOwnership(true)
+
+		router := gin.New()
+		if userID != nil {
+			router.Use(func(c *gin.Context) {
+				c.Set(string(middleware.UserIDKey), *userID)
+				c.Next()
+			})
+		}
+		router.POST("/api/telemetry", handler.HandlePost)
+		router.POST("/api/telemetry/batch", handler.HandleBatchPost)
+		return router
+	}
+
+	record := models.TelemetryData{
+		Timestamp: time.Now().UTC(),
+		DeviceID:  deviceID,
+		GPS:       models.GpsData{Latitude: 42.0, Longitude: 23.0},
+	}
+
+	post := func(router *gin.Engine, path string, payload interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("anonymous uploads are rejected", func(t *testing.T) {
+		var saved []*models.TelemetryData
+		router := setupRouter(nil, &saved)
+
+		if w := post(router, "/api/telemetry", record); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+		if w := post(router, "/api/telemetry/batch", []models.TelemetryData{record}); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+		if len(saved) != 0 {
+			t.Errorf("Expected nothing saved, got %d records", len(saved))
+		}
+	})
+
+	t.Run("owner uploads are accepted", func(t *testing.T) {
+		var saved []*models.TelemetryData
+		router := setupRouter(&ownerID, &saved)
+
+		if w := post(router, "/api/telemetry", record); w.Code != http.StatusCreated {
+			t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if len(saved) != 1 || saved[0].UserID == nil || *saved[0].UserID != ownerID {
+			t.Errorf("Expected one record owned by %s, got %+v", ownerID, saved)
+		}
+	})
+
+	t.Run("uploads for another user's device are forbidden", func(t *testing.T) {
+		var saved []*models.TelemetryData
+		otherID := uuid.New()
+		router := setupRouter(&otherID, &saved)
+
+		if w := post(router, "/api/telemetry", record); w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+		if w := post(router, "/api/telemetry/batch", []models.TelemetryData{record}); w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+		if len(saved) != 0 {
+			t.Errorf("Expected nothing saved, got %d records", len(saved))
+		}
+	})
+}
+
+func TestTelemetryHandler_Query(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	base := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	var captured repository.TelemetryFilter
+	mockRepo := repository.NewMockRepository()
+	mockRepo.QueryFunc = func(_ context.Context, filter repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+		captured = filter
+		results := make([]*models.TelemetryData, 0, filter.Limit)
+		for i := 0; i < filter.Limit; i++ {
+			results = append(results, &models.TelemetryData{ID: int64(100 - i), Timestamp: base.Add(-time.Duration(i) * time.Second)})
+		}
+		return results, nil
+	}
+
+	handler := NewTelemetryHandler(mockRepo, nil)
+	router := gin.New()
+	router.GET("/api/v1/telemetry", func(c *gin.Context) {
+		c.Set(string(middleware.UserIDKey), userID)
+		c.Next()
+	}, handler.HandleQuery)
+
+	sessionID := uuid.New().String()
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v1/telemetry?deviceId=device-001&sessionId="+sessionID+"&from=2024-01-10T07:00:00Z&to=2024-01-10T09:00:00Z&limit=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if captured.UserID != userID {
+		t.Errorf("Expected query scoped to user %s, got %s", userID, captured.UserID)
+	}
+	if captured.DeviceID != "device-001" || captured.SessionID != sessionID {
+		t.Errorf("Unexpected filter: %+v", captured)
+	}
+	if captured.Limit != 3 {
+		t.Errorf("Expected repository limit 3 (page size + 1), got %d", captured.Limit)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response["count"] != float64(2) {
+		t.Errorf("Expected count 2, got %v", response["count"])
+	}
+
+	nextCursor, ok := response["nextCursor"].(string)
+	if !ok || nextCursor == "" {
+		t.Fatal("Expected nextCursor in response")
+	}
+
+	cursor, err := pagination.Decode(nextCursor)
+	if err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+	if cursor.ID != "99" || !cursor.Time.Equal(base.Add(-time.Second)) {
+		t.Errorf("Unexpected cursor: %+v", cursor)
+	}
+
+	// Following the cursor passes it to the repository
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry?cursor="+nextCursor, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if captured.After == nil || captured.After.ID != "99" {
+		t.Errorf("Expected cursor to be forwarded, got %+v", captured.After)
+	}
+	if captured.CleanOnly {
+		t.Error("Expected flagged points to be included by default")
+	}
+
+	// quality=clean leaves out flagged points
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry?quality=clean", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !captured.CleanOnly {
+		t.Error("Expected quality=clean to be forwarded")
+	}
+	if captured.Processed {
+		t.Error("Expected raw telemetry by default")
+	}
+
+	// processed=true returns smoothed telemetry
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry?processed=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !captured.Processed {
+		t.Error("Expected processed=true to be forwarded")
+	}
+}
+
+func TestTelemetryHandler_Query_LastPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := repository.NewMockRepository()
+	mockRepo.QueryFunc = func(_ context.Context, _ repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+		return nil, nil
+	}
+
+	handler := NewTelemetryHandler(mockRepo, nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.HandleQuery(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if _, ok := response["nextCursor"]; ok {
+		t.Error("Expected no nextCursor on last page")
+	}
+	if telemetry, ok := response["telemetry"].([]interface{}); !ok || len(telemetry) != 0 {
+		t.Errorf("Expected empty telemetry array, got %v", response["telemetry"])
+	}
+}
+
+func TestTelemetryHandler_Query_Downsampled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	base := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	var captured repository.TelemetryFilter
+	mockRepo := repository.NewMockRepository()
+	mockRepo.QueryFunc = func(_ context.Context, filter repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+		captured = filter
+		// Newest first, as the repository returns them
+		results := make([]*models.TelemetryData, 1000)
+		for i := range results {
+			results[i] = &models.TelemetryData{
+				ID:        int64(1000 - i),
+				Timestamp: base.Add(time.Duration(999-i) * 40 * time.Millisecond),
+				GPS:       models.GpsData{Speed: 100},
+			}
+		}
+		results[500].GPS.Speed = 180
+		return results, nil
+	}
+
+	handler := NewTelemetryHandler(mockRepo, nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry?sessionId="+uuid.New().String()+"&points=50", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.HandleQuery(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured.Limit != maxDownsampleSourceRows+1 {
+		t.Errorf("Expected the whole range to be read, got limit %d", captured.Limit)
+	}
+
+	var response struct {
+		Telemetry   []models.TelemetryData `json:"telemetry"`
+		Count       int                    `json:"count"`
+		SourceCount int                    `json:"sourceCount"`
+		Truncated   bool                   `json:"truncated"`
+		NextCursor  *string                `json:"nextCursor"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Count != 50 || len(response.Telemetry) != 50 || response.SourceCount != 1000 || response.Truncated {
+		t.Errorf("Unexpected response meta: count=%d sourceCount=%d truncated=%v", response.Count, response.SourceCount, response.Truncated)
+	}
+	if response.NextCursor != nil {
+		t.Error("Expected no nextCursor for downsampled queries")
+	}
+	if response.Telemetry[0].ID != 1000 || response.Telemetry[49].ID != 1 {
+		t.Errorf("Expected newest and oldest records to be kept in order, got %d and %d", response.Telemetry[0].ID, response.Telemetry[49].ID)
+	}
+
+	var peak bool
+	for _, record := range response.Telemetry {
+		peak = peak || record.GPS.Speed == 180
+	}
+	if !peak {
+		t.Error("Expected the speed peak to survive downsampling")
+	}
+}
+
+func TestTelemetryHandler_Query_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewTelemetryHandler(repository.NewMockRepository(), nil)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid session id", query: "sessionId=abc"},
+		{name: "invalid from", query: "from=yesterday"},
+		{name: "invalid to", query: "to=2024-13-01"},
+		{name: "to before from", query: "from=2024-01-10T09:00:00Z&to=2024-01-10T08:00:00Z"},
+		{name: "limit too large", query: "limit=5000"},
+		{name: "limit zero", query: "limit=0"},
+		{name: "invalid cursor", query: "cursor=not-a-cursor"},
+		{name: "unknown quality", query: "quality=noisy"},
+		{name: "invalid processed", query: "processed=yes"},
+		{name: "points too small", query: "points=2"},
+		{name: "points too large", query: "points=20000"},
+		{name: "points with limit", query: "points=100&limit=50"},
+		{name: "points with cursor", query: "points=100&cursor=" + pagination.Cursor{ID: "1"}.Encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry?"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.HandleQuery(c)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestTelemetryHandler_Aggregate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	base := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	var capturedFilter repository.TelemetryFilter
+	var capturedBucket repository.AggregateBucket
+	mockRepo := repository.NewMockRepository()
+	mockRepo.AggregateFunc = func(_ context.Context, filter repository.TelemetryFilter, bucket repository.AggregateBucket) ([]*models.TelemetryBucket, error) {
+		capturedFilter = filter
+		capturedBucket = bucket
+		return []*models.TelemetryBucket{
+			{Bucket: base, Samples: 1500, AvgSpeed: 98.2, MaxSpeed: 141.0},
+			{Bucket: base.Add(time.Minute), Samples: 1500, AvgSpeed: 102.7, MaxSpeed: 150.3},
+			{Bucket: base.Add(2 * time.Minute), Samples: 1500, AvgSpeed: 87.1, MaxSpeed: 120.9},
+		}, nil
+	}
+
+	handler := NewTelemetryHandler(mockRepo, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry/aggregate?bucket=1m&deviceId=device-001&limit=2&quality=clean&processed=true", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.HandleAggregate(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if capturedBucket != repository.Bucket1m {
+		t.Errorf("Expected bucket 1m, got %s", capturedBucket)
+	}
+	if capturedFilter.UserID != userID || capturedFilter.DeviceID != "device-001" ||
+		!capturedFilter.CleanOnly || !capturedFilter.Processed {
+		t.Errorf("Unexpected filter: %+v", capturedFilter)
+	}
+	if capturedFilter.Limit != 3 {
+		t.Errorf("Expected repository limit 3 (limit + 1), got %d", capturedFilter.Limit)
+	}
+
+	var response struct {
+		Bucket    string                   `json:"bucket"`
+		Buckets   []models.TelemetryBucket `json:"buckets"`
+		Count     int                      `json:"count"`
+		Truncated bool                     `json:"truncated"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Bucket != "1m" || response.Count != 2 || len(response.Buckets) != 2 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if !response.Truncated {
+		t.Error("Expected truncated response")
+	}
+	if response.Buckets[1].MaxSpeed != 150.3 {
+		t.Errorf("Expected second bucket max speed 150.3, got %v", response.Buckets[1].MaxSpeed)
+	}
+}
+
+func TestTelemetryHandler_Aggregate_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewTelemetryHandler(repository.NewMockRepository(), nil)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "unsupported bucket", query: "bucket=5m"},
+		{name: "invalid from", query: "bucket=1s&from=yesterday"},
+		{name: "limit too large", query: "bucket=1s&limit=20000"},
+		{name: "unknown units", query: "bucket=1s&units=nautical"},
+		{name: "unknown quality", query: "bucket=1s&quality=noisy"},
+		{name: "invalid processed", query: "bucket=1s&processed=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry/aggregate?"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.HandleAggregate(c)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestTelemetryHandler_Quotas(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	policy := QuotaPolicy{Plans: map[models.Plan]models.PlanLimits{
+		models.PlanFree: {TelemetryPointsPerMonth: 10, MaxDevices: 1},
+	}}
+
+	setupRouter := func(usageRepo *repository.MockUsageRepository, deviceRepo *repository.MockDeviceRepository) *gin.Engine {
+		handler := NewTelemetryHandler(repository.NewMockRepository(), deviceRepo).
+			WithQuotas(NewQuotas(usageRepo, deviceRepo, policy))
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(string(middleware.UserIDKey), userID)
+			c.Next()
+		})
+		router.POST("/api/telemetry", handler.HandlePost)
+		router.POST("/api/telemetry/batch", handler.HandleBatchPost)
+		return router
+	}
+
+	post := func(router *gin.Engine, path string, payload interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	now := time.Now().UTC()
+	record := func(deviceID string) models.TelemetryData {
+		return models.TelemetryData{Timestamp: now, DeviceID: deviceID, GPS: models.GpsData{Latitude: 42.0, Longitude: 23.0}}
+	}
+	ownDevice := func(_ context.Context, id string) (*models.Device, error) {
+		return &models.Device{ID: uuid.New(), DeviceID: id, UserID: userID, IsActive: true}, nil
+	}
+
+	t.Run("stored points are counted", func(t *testing.T) {
+		usageRepo := repository.NewMockUsageRepository()
+		var added int64
+		usageRepo.AddTelemetryPointsFunc = func(_ context.Context, id uuid.UUID, periodStart time.Time, points int64) error {
+			if id != userID || periodStart.Day() != 1 {
+				t.Errorf("Unexpected usage counter %s %v", id, periodStart)
+			}
+			added += points
+			return nil
+		}
+		deviceRepo := repository.NewMockDeviceRepository()
+		deviceRepo.GetByDeviceIDFunc = ownDevice
+		router := setupRouter(usageRepo, deviceRepo)
+
+		if w := post(router, "/api/telemetry/batch", []models.TelemetryData{record("racebox-1"), record("racebox-1")}); w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if added != 2 {
+			t.Errorf("Expected 2 points counted, got %d", added)
+		}
+	})
+
+	t.Run("uploads over the monthly quota return 429", func(t *testing.T) {
+		usageRepo := repository.NewMockUsageRepository()
+		usageRepo.GetTelemetryPointsFunc = func(_ context.Context, _ uuid.UUID, _ time.Time) (int64, error) {
+			return 9, nil
+		}
+		deviceRepo := repository.NewMockDeviceRepository()
+		deviceRepo.GetByDeviceIDFunc = ownDevice
+		router := setupRouter(usageRepo, deviceRepo)
+
+		if w := post(router, "/api/telemetry", record("racebox-1")); w.Code != http.StatusCreated {
+			t.Errorf("Expected the last point within quota to be accepted, got %d", w.Code)
+		}
+
+		w := post(router, "/api/telemetry/batch", []models.TelemetryData{record("racebox-1"), record("racebox-1")})
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Error("Expected Retry-After header on 429 response")
+		}
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if response["limit"] != float64(10) || response["used"] != float64(9) {
+			t.Errorf("Expected used 9 of 10, got %v of %v", response["used"], response["limit"])
+		}
+	})
+
+	t.Run("claiming a device over the limit returns 402", func(t *testing.T) {
+		deviceRepo := repository.NewMockDeviceRepository()
+		deviceRepo.ListFunc = func(_ context.Context, filter repository.DeviceFilter) ([]*models.Device, int, error) {
+			if filter.UserID != userID || filter.IsActive == nil || !*filter.IsActive {
+				t.Errorf("Expected active devices of %s to be counted, got %+v", userID, filter)
+			}
+			return []*models.Device{}, 1, nil
+		}
+		deviceRepo.ClaimFunc = func(_ context.Context, device *models.Device) (*models.Device, error) {
+			t.Error("Device should not be claimed over the limit")
+			return device, nil
+		}
+		router := setupRouter(repository.NewMockUsageRepository(), deviceRepo)
+
+		if w := post(router, "/api/telemetry", record("racebox-2")); w.Code != http.StatusPaymentRequired {
+			t.Errorf("Expected status %d, got %d", http.StatusPaymentRequired, w.Code)
+		}
+	})
+
+	t.Run("unlimited plans are not checked", func(t *testing.T) {
+		usageRepo := repository.NewMockUsageRepository()
+		usageRepo.GetPlanFunc = func(_ context.Context, _ uuid.UUID) (models.Plan, error) {
+			return models.PlanPro, nil
+		}
+		usageRepo.GetTelemetryPointsFunc = func(_ context.Context, _ uuid.UUID, _ time.Time) (int64, error) {
+			return 1 << 40, nil
+		}
+		deviceRepo := repository.NewMockDeviceRepository()
+		deviceRepo.ListFunc = func(_ context.Context, _ repository.DeviceFilter) ([]*models.Device, int, error) {
+			return []*models.Device{}, 100, nil
+		}
+		router := setupRouter(usageRepo, deviceRepo)
+
+		if w := post(router, "/api/telemetry", record("racebox-3")); w.Code != http.StatusCreated {
+			t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+	})
+}
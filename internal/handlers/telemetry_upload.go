@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// maxUploadSize caps the declared size of a resumable upload
+const maxUploadSize = 512 << 20
+
+// UploadNotifier wakes the background ingest of finalized uploads
+type UploadNotifier interface {
+	Notify()
+}
+
+// CreateUploadRequest represents the resumable upload creation request body
+type CreateUploadRequest struct {
+	DeviceID string `json:"deviceId" binding:"omitempty,max=50"`
+	Size     int64  `json:"size" binding:"required,gt=0"`
+}
+
+// WithUploads enables resumable uploads, stored in the repository and ingested in the background
+func (h *TelemetryHandler) WithUploads(uploads repository.UploadRepository, notifier UploadNotifier) *TelemetryHandler {
+	h.uploads = uploads
+	h.uploadNotifier = notifier
+	return h
+}
+
+// CreateUpload opens a resumable upload of NDJSON telemetry for a single device
+// The device is claimed as for other uploads. Records without a deviceId are stored for the
+// upload's device and records for other devices are rejected when the upload is ingested.
+// POST /api/v1/uploads
+func (h *TelemetryHandler) CreateUpload(c *gin.Context) {
+	if !h.uploadsEnabled(c) {
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		rejectAnonymousUpload(c)
+		return
+	}
+
+	var req CreateUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	if req.Size > maxUploadSize {
+		problem.Abort(c, problem.New(http.StatusRequestEntityTooLarge, "upload_too_large", fmt.Sprintf("Uploads must be at most %d MB", maxUploadSize>>20)))
+		return
+	}
+
+	// Device key uploads may only write telemetry for the key's device
+	device := &models.TelemetryData{DeviceID: req.DeviceID}
+	if !applyDeviceKeyScope(c, device) {
+		problem.Abort(c, problem.Forbidden("forbidden", "Device key does not match deviceId"))
+		return
+	}
+	if device.DeviceID == "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "deviceId is required"))
+		return
+	}
+
+	if !h.allowTelemetry(c, userID, 1) {
+		return
+	}
+
+	if h.deviceRepo != nil {
+		if err := h.handleDeviceClaiming(c, device, userID); err != nil {
+			rejectUploadClaimingError(c, err)
+			return
+		}
+	}
+
+	upload := &models.Upload{
+		ID:       uuid.New(),
+		UserID:   userID,
+		DeviceID: device.DeviceID,
+		Size:     req.Size,
+	}
+	if err := h.uploads.Create(c.Request.Context(), upload); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create upload"))
+		return
+	}
+
+	c.Header("Location", "/api/v1/uploads/"+upload.ID.String())
+	api.Respond(c, http.StatusCreated, upload)
+}
+
+// UploadChunk appends the request body to an open upload
+// The offset query parameter must equal the bytes received so far; otherwise the upload is left
+// unchanged and 409 reports receivedBytes, so a client that lost a response resumes from there.
+// PUT /api/v1/uploads/:id?offset=
+func (h *TelemetryHandler) UploadChunk(c *gin.Context) {
+	upload, ok := h.getUpload(c)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.Query("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		problem.Abort(c, problem.BadRequest("invalid_request", "offset must be a non-negative byte offset"))
+		return
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		if limit, ok := middleware.BodyTooLarge(err); ok {
+			middleware.RespondBodyTooLarge(c, limit)
+			return
+		}
+		problem.Abort(c, problem.BadRequest("invalid_request", "Failed to read chunk"))
+		return
+	}
+	if len(data) == 0 {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Chunk is empty"))
+		return
+	}
+
+	received, err := h.uploads.AppendChunk(c.Request.Context(), upload.ID, offset, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrUploadOffsetMismatch):
+			problem.Abort(c, problem.Conflict("offset_mismatch", fmt.Sprintf("Chunk must start at offset %d", received)).
+				With("receivedBytes", received))
+		case errors.Is(err, repository.ErrUploadSizeExceeded):
+			problem.Abort(c, problem.BadRequest("size_exceeded", fmt.Sprintf("Chunk extends past the declared size of %d bytes", upload.Size)))
+		case errors.Is(err, repository.ErrUploadNotOpen):
+			rejectUploadNotOpen(c)
+		case errors.Is(err, repository.ErrUploadNotFound):
+			rejectUploadNotFound(c)
+		default:
+			slog.Error("Error storing upload chunk", "upload_id", upload.ID, "error", err)
+			problem.Abort(c, problem.Internal("Failed to store chunk"))
+		}
+		return
+	}
+
+	upload.ReceivedBytes = received
+	api.Respond(c, http.StatusOK, upload)
+}
+
+// CompleteUpload finalizes a fully received upload and schedules it for ingestion
+// Progress and rejected lines are reported on the upload as it is ingested.
+// POST /api/v1/uploads/:id/complete
+func (h *TelemetryHandler) CompleteUpload(c *gin.Context) {
+	upload, ok := h.getUpload(c)
+	if !ok {
+		return
+	}
+
+	if !h.allowTelemetry(c, upload.UserID, 1) {
+		return
+	}
+
+	finalized, err := h.uploads.Finalize(c.Request.Context(), upload.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrUploadIncomplete):
+			problem.Abort(c, problem.Conflict("upload_incomplete", fmt.Sprintf("Upload has %d of %d bytes", finalized.ReceivedBytes, finalized.Size)).
+				With("receivedBytes", finalized.ReceivedBytes))
+		case errors.Is(err, repository.ErrUploadNotOpen):
+			rejectUploadNotOpen(c)
+		case errors.Is(err, repository.ErrUploadNotFound):
+			rejectUploadNotFound(c)
+		default:
+			problem.Abort(c, problem.Internal("Failed to complete upload"))
+		}
+		return
+	}
+
+	if h.uploadNotifier != nil {
+		h.uploadNotifier.Notify()
+	}
+
+	c.Header("Location", "/api/v1/uploads/"+finalized.ID.String())
+	api.Respond(c, http.StatusAccepted, finalized)
+}
+
+// GetUpload reports the received bytes of an open upload or the ingest progress of a finalized one
+// GET /api/v1/uploads/:id
+func (h *TelemetryHandler) GetUpload(c *gin.Context) {
+	upload, ok := h.getUpload(c)
+	if !ok {
+		return
+	}
+
+	api.Respond(c, http.StatusOK, upload)
+}
+
+// uploadsEnabled responds 503 when resumable uploads are not configured
+func (h *TelemetryHandler) uploadsEnabled(c *gin.Context) bool {
+	if h.uploads == nil {
+		problem.Abort(c, problem.ServiceUnavailable("uploads_unavailable", "Resumable uploads are not configured"))
+		return false
+	}
+	return true
+}
+
+// getUpload loads the upload named in the path, responding with an error unless the caller owns it
+func (h *TelemetryHandler) getUpload(c *gin.Context) (*models.Upload, bool) {
+	if !h.uploadsEnabled(c) {
+		return nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		rejectAnonymousUpload(c)
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_upload_id", "Invalid upload ID format"))
+		return nil, false
+	}
+
+	upload, err := h.uploads.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUploadNotFound) {
+			rejectUploadNotFound(c)
+			return nil, false
+		}
+		problem.Abort(c, problem.Internal("Failed to retrieve upload"))
+		return nil, false
+	}
+
+	if !upload.IsOwnedBy(userID) {
+		problem.Abort(c, problem.Forbidden("forbidden", "You do not have access to this upload"))
+		return nil, false
+	}
+
+	return upload, true
+}
+
+// rejectUploadClaimingError responds 403 for devices owned by someone else, 402 over the plan's
+// device limit and 500 otherwise
+func rejectUploadClaimingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errDeviceClaimedByOther):
+		problem.Abort(c, problem.Forbidden("forbidden", "Device is claimed by another user"))
+	case errors.Is(err, errDeviceLimitReached):
+		problem.Abort(c, problem.New(http.StatusPaymentRequired, "device_limit_reached", "Device limit reached for your plan"))
+	default:
+		slog.Error("Error handling device claiming", "error", err)
+		problem.Abort(c, problem.Internal("Failed to process device claiming"))
+	}
+}
+
+// rejectUploadNotFound responds 404 for an unknown or abandoned upload
+func rejectUploadNotFound(c *gin.Context) {
+	problem.Abort(c, problem.NotFound("upload_not_found", "Upload not found"))
+}
+
+// rejectUploadNotOpen responds 409 for chunks or completion sent to an already finalized upload
+func rejectUploadNotOpen(c *gin.Context) {
+	problem.Abort(c, problem.Conflict("upload_not_open", "Upload has already been completed"))
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// countingNotifier counts wake-ups of the upload processor
+type countingNotifier struct {
+	notified int
+}
+
+func (n *countingNotifier) Notify() {
+	n.notified++
+}
+
+// setupUploadRouter registers the upload routes, authenticating requests as userID and,
+// when deviceKey is set, as a device key for that device
+func setupUploadRouter(uploads repository.UploadRepository, notifier UploadNotifier, userID *uuid.UUID, deviceKey string) *gin.Engine {
+	deviceRepo := repository.NewMockDeviceRepository()
+	deviceRepo.GetByDeviceIDFunc = func(_ context.Context, id string) (*models.Device, error) {
+		return &models.Device{ID: uuid.New(), DeviceID: id, UserID: *userID, IsActive: true}, nil
+	}
+
+	handler := NewTelemetryHandler(repository.NewMockRepository(), deviceRepo)
+	if uploads != nil {
+		handler = handler.WithUploads(uploads, notifier)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID != nil {
+			c.Set(string(middleware.UserIDKey), *userID)
+		}
+		if deviceKey != "" {
+			c.Set(string(middleware.DeviceIDKey), deviceKey)
+		}
+		c.Next()
+	})
+	router.POST("/api/v1/uploads", handler.CreateUpload)
+	router.GET("/api/v1/uploads/:id", handler.GetUpload)
+	router.PUT("/api/v1/uploads/:id", handler.UploadChunk)
+	router.POST("/api/v1/uploads/:id/complete", handler.CompleteUpload)
+	return router
+}
+
+// serveUpload sends a request to the upload routes and decodes the response
+func serveUpload(t *testing.T, router *gin.Engine, method, target, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+	return w, response
+}
+
+func TestTelemetryHandler_CreateUpload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	t.Run("opens an upload for the device", func(t *testing.T) {
+		uploads := repository.NewMockUploadRepository()
+		var created *models.Upload
+		uploads.CreateFunc = func(_ context.Context, upload *models.Upload) error {
+			created = upload
+			return nil
+		}
+		router := setupUploadRouter(uploads, nil, &userID, "")
+
+		w, response := serveUpload(t, router, http.MethodPost, "/api/v1/uploads", `{"deviceId":"RACEBOX-001","size":2048}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.NotNil(t, created)
+		assert.Equal(t, userID, created.UserID)
+		assert.Equal(t, "RACEBOX-001", created.DeviceID)
+		assert.Equal(t, int64(2048), created.Size)
+		assert.Equal(t, "/api/v1/uploads/"+created.ID.String(), w.Header().Get("Location"))
+		assert.Equal(t, created.ID.String(), response["id"])
+	})
+
+	t.Run("device key supplies the device", func(t *testing.T) {
+		uploads := repository.NewMockUploadRepository()
+		var created *models.Upload
+		uploads.CreateFunc = func(_ context.Context, upload *models.Upload) error {
+			created = upload
+			return nil
+		}
+		router := setupUploadRouter(uploads, nil, &userID, "RACEBOX-KEY")
+
+		w, _ := serveUpload(t, router, http.MethodPost, "/api/v1/uploads", `{"size":10}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, "RACEBOX-KEY", created.DeviceID)
+	})
+
+	t.Run("device key for another device is forbidden", func(t *testing.T) {
+		router := setupUploadRouter(repository.NewMockUploadRepository(), nil, &userID, "RACEBOX-KEY")
+
+		w, response := serveUpload(t, router, http.MethodPost, "/api/v1/uploads", `{"deviceId":"RACEBOX-001","size":10}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "forbidden", response["code"])
+	})
+
+	t.Run("deviceId is required", func(t *testing.T) {
+		router := setupUploadRouter(repository.NewMockUploadRepository(), nil, &userID, "")
+
+		w, _ := serveUpload(t, router, http.MethodPost, "/api/v1/uploads", `{"size":10}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("size is capped", func(t *testing.T) {
+		router := setupUploadRouter(repository.NewMockUploadRepository(), nil, &userID, "")
+
+		w, response := serveUpload(t, router, http.MethodPost, "/api/v1/uploads", `{"deviceId":"RACEBOX-001","size":1099511627776}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, "upload_too_large", response["code"])
+	})
+
+	t.Run("anonymous uploads are rejected", func(t *testing.T) {
+		router := setupUploadRouter(repository.NewMockUploadRepository(), nil, nil, "")
+
+		w, _ := serveUpload(t, router, http.MethodPost, "/api/v1/uploads", `{"deviceId":"RACEBOX-001","size":10}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("unavailable without a repository", func(t *testing.T) {
+		router := setupUploadRouter(nil, nil, &userID, "")
+
+		w, response := serveUpload(t, router, http.MethodPost, "/api/v1/uploads", `{"deviceId":"RACEBOX-001","size":10}`)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "uploads_unavailable", response["code"])
+	})
+}
+
+func TestTelemetryHandler_UploadChunk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	upload := &models.Upload{ID: uuid.New(), UserID: userID, DeviceID: "RACEBOX-001", Size: 10, ReceivedBytes: 4, Status: models.UploadOpen}
+
+	setup := func() (*repository.MockUploadRepository, *gin.Engine) {
+		uploads := repository.NewMockUploadRepository()
+		uploads.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Upload, error) {
+			if id != upload.ID {
+				return nil, repository.ErrUploadNotFound
+			}
+			copied := *upload
+			return &copied, nil
+		}
+		return uploads, setupUploadRouter(uploads, nil, &userID, "")
+	}
+
+	t.Run("appends the chunk at its offset", func(t *testing.T) {
+		uploads, router := setup()
+		var gotOffset int64
+		var gotData string
+		uploads.AppendChunkFunc = func(_ context.Context, _ uuid.UUID, offset int64, data []byte) (int64, error) {
+			gotOffset, gotData = offset, string(data)
+			return offset + int64(len(data)), nil
+		}
+
+		w, response := serveUpload(t, router, http.MethodPut, "/api/v1/uploads/"+upload.ID.String()+"?offset=4", "{}\n{}")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, int64(4), gotOffset)
+		assert.Equal(t, "{}\n{}", gotData)
+		assert.Equal(t, float64(9), response["receivedBytes"])
+	})
+
+	t.Run("wrong offset reports the received bytes", func(t *testing.T) {
+		uploads, router := setup()
+		uploads.AppendChunkFunc = func(_ context.Context, _ uuid.UUID, _ int64, _ []byte) (int64, error) {
+			return 4, repository.ErrUploadOffsetMismatch
+		}
+
+		w, response := serveUpload(t, router, http.MethodPut, "/api/v1/uploads/"+upload.ID.String()+"?offset=0", "data")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "offset_mismatch", response["code"])
+		assert.Equal(t, float64(4), response["receivedBytes"])
+	})
+
+	t.Run("offset is required", func(t *testing.T) {
+		_, router := setup()
+
+		w, _ := serveUpload(t, router, http.MethodPut, "/api/v1/uploads/"+upload.ID.String(), "data")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown upload is not found", func(t *testing.T) {
+		_, router := setup()
+
+		w, response := serveUpload(t, router, http.MethodPut, "/api/v1/uploads/"+uuid.New().String()+"?offset=0", "data")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "upload_not_found", response["code"])
+	})
+
+	t.Run("another user's upload is forbidden", func(t *testing.T) {
+		uploads := repository.NewMockUploadRepository()
+		uploads.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Upload, error) {
+			return upload, nil
+		}
+		otherID := uuid.New()
+		router := setupUploadRouter(uploads, nil, &otherID, "")
+
+		w, _ := serveUpload(t, router, http.MethodGet, "/api/v1/uploads/"+upload.ID.String(), "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestTelemetryHandler_CompleteUpload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	upload := &models.Upload{ID: uuid.New(), UserID: userID, DeviceID: "RACEBOX-001", Size: 10, ReceivedBytes: 10, Status: models.UploadOpen}
+
+	setup := func(notifier UploadNotifier) (*repository.MockUploadRepository, *gin.Engine) {
+		uploads := repository.NewMockUploadRepository()
+		uploads.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Upload, error) {
+			copied := *upload
+			return &copied, nil
+		}
+		return uploads, setupUploadRouter(uploads, notifier, &userID, "")
+	}
+
+	t.Run("schedules the upload for ingestion", func(t *testing.T) {
+		notifier := &countingNotifier{}
+		uploads, router := setup(notifier)
+		uploads.FinalizeFunc = func(_ context.Context, _ uuid.UUID) (*models.Upload, error) {
+			finalized := *upload
+			finalized.Status = models.UploadPending
+			return &finalized, nil
+		}
+
+		w, response := serveUpload(t, router, http.MethodPost, "/api/v1/uploads/"+upload.ID.String()+"/complete", "")
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		assert.Equal(t, "pending", response["status"])
+		assert.Equal(t, 1, notifier.notified)
+	})
+
+	t.Run("incomplete upload reports the received bytes", func(t *testing.T) {
+		notifier := &countingNotifier{}
+		uploads, router := setup(notifier)
+		uploads.FinalizeFunc = func(_ context.Context, _ uuid.UUID) (*models.Upload, error) {
+			current := *upload
+			current.ReceivedBytes = 6
+			return &current, repository.ErrUploadIncomplete
+		}
+
+		w, response := serveUpload(t, router, http.MethodPost, "/api/v1/uploads/"+upload.ID.String()+"/complete", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "upload_incomplete", response["code"])
+		assert.Equal(t, float64(6), response["receivedBytes"])
+		assert.Zero(t, notifier.notified)
+	})
+
+	t.Run("completed upload cannot be completed again", func(t *testing.T) {
+		uploads, router := setup(nil)
+		uploads.FinalizeFunc = func(_ context.Context, _ uuid.UUID) (*models.Upload, error) {
+			return upload, repository.ErrUploadNotOpen
+		}
+
+		w, response := serveUpload(t, router, http.MethodPost, "/api/v1/uploads/"+upload.ID.String()+"/complete", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "upload_not_open", response["code"])
+	})
+}
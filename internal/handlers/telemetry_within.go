@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// HandleWithin retrieves the authenticated user's telemetry recorded inside a GeoJSON polygon
+// By default it lists the matching points with cursor pagination; with mode=stats it summarizes them.
+// GET /api/v1/telemetry/within?polygon=&mode=points|stats&deviceId=&sessionId=&tag=&from=&to=&limit=&cursor=&quality=&units=
+func (h *TelemetryHandler) HandleWithin(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if c.Query("polygon") == "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "polygon is required"))
+		return
+	}
+	area, err := models.ParseGeoPolygon(c.Query("polygon"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	mode := c.DefaultQuery("mode", "points")
+	if mode != "points" && mode != "stats" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "mode must be one of: points, stats"))
+		return
+	}
+
+	filter, err := parseTelemetryFilter(c, defaultTelemetryQueryLimit, maxTelemetryQueryLimit)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+	if filter.Processed {
+		problem.Abort(c, problem.BadRequest("invalid_request", "processed is not supported for area queries"))
+		return
+	}
+	filter.UserID = userID
+
+	system, ok := h.units.resolve(c)
+	if !ok {
+		return
+	}
+
+	if mode == "stats" {
+		stats, err := h.repo.WithinStats(c.Request.Context(), filter, area)
+		if err != nil {
+			slog.Error("Error summarizing telemetry in area", "error", err)
+			problem.Abort(c, problem.Internal("Failed to retrieve telemetry area stats"))
+			return
+		}
+
+		api.Respond(c, http.StatusOK, convertAreaStats(system, stats))
+		return
+	}
+
+	// Fetch one extra row to detect whether another page exists
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1
+
+	results, err := h.repo.Within(c.Request.Context(), filter, area)
+	if err != nil {
+		slog.Error("Error querying telemetry in area", "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve telemetry"))
+		return
+	}
+
+	meta := api.Meta{}
+	if len(results) > pageSize {
+		results = results[:pageSize]
+		meta["nextCursor"] = telemetryCursor(results[len(results)-1]).Encode()
+	}
+	if results == nil {
+		results = []*models.TelemetryData{}
+	}
+
+	convertTelemetry(system, results)
+	meta["count"] = len(results)
+	meta["units"] = system.Labels()
+
+	api.List(c, http.StatusOK, "telemetry", results, meta)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const withinTestPolygon = `{"type":"Polygon","coordinates":[[[5.96,50.43],[5.98,50.43],[5.98,50.45],[5.96,50.45],[5.96,50.43]]]}`
+
+// performWithinRequest calls HandleWithin as the given user with the query parameters
+func performWithinRequest(handler *TelemetryHandler, userID uuid.UUID, query url.Values) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry/within?"+query.Encode(), nil)
+	c.Set(string(middleware.UserIDKey), userID)
+	handler.HandleWithin(c)
+	return w
+}
+
+func TestTelemetryHandler_Within_Points(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	var capturedFilter repository.TelemetryFilter
+	var capturedArea models.GeoPolygon
+	mockRepo := repository.NewMockRepository()
+	mockRepo.WithinFunc = func(_ context.Context, filter repository.TelemetryFilter, area models.GeoPolygon) ([]*models.TelemetryData, error) {
+		capturedFilter, capturedArea = filter, area
+		return []*models.TelemetryData{
+			{ID: 3, Timestamp: start.Add(2 * time.Second), GPS: models.GpsData{Latitude: 50.44, Longitude: 5.97, Speed: 160.934}},
+			{ID: 2, Timestamp: start.Add(time.Second), GPS: models.GpsData{Latitude: 50.44, Longitude: 5.97, Speed: 100}},
+			{ID: 1, Timestamp: start, GPS: models.GpsData{Latitude: 50.44, Longitude: 5.97, Speed: 90}},
+		}, nil
+	}
+	handler := NewTelemetryHandler(mockRepo, nil)
+
+	w := performWithinRequest(handler, userID, url.Values{
+		"polygon": {withinTestPolygon},
+		"from":    {start.Format(time.RFC3339)},
+		"limit":   {"2"},
+		"units":   {"imperial"},
+	})
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, userID, capturedFilter.UserID)
+	assert.Equal(t, 3, capturedFilter.Limit)
+	require.NotNil(t, capturedFilter.From)
+	assert.True(t, start.Equal(*capturedFilter.From))
+	assert.Equal(t, models.BoundingBox{West: 5.96, South: 50.43, East: 5.98, North: 50.45}, capturedArea.Bounds())
+
+	var response struct {
+		Telemetry  []models.TelemetryData `json:"telemetry"`
+		Count      int                    `json:"count"`
+		NextCursor string                 `json:"nextCursor"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.NotEmpty(t, response.NextCursor)
+	require.Len(t, response.Telemetry, 2)
+	assert.InDelta(t, 100, response.Telemetry[0].GPS.Speed, 0.01) // mph
+}
+
+func TestTelemetryHandler_Within_Stats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	first := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	last := first.Add(time.Hour)
+
+	var capturedFilter repository.TelemetryFilter
+	mockRepo := repository.NewMockRepository()
+	mockRepo.WithinFunc = func(_ context.Context, _ repository.TelemetryFilter, _ models.GeoPolygon) ([]*models.TelemetryData, error) {
+		t.Fatal("stats do not list points")
+		return nil, nil
+	}
+	mockRepo.WithinStatsFunc = func(_ context.Context, filter repository.TelemetryFilter, _ models.GeoPolygon) (*models.AreaStats, error) {
+		capturedFilter = filter
+		return &models.AreaStats{Points: 420, Devices: 2, Sessions: 3, FirstRecordedAt: &first, LastRecordedAt: &last, AvgSpeed: 120, MaxSpeed: 180}, nil
+	}
+	handler := NewTelemetryHandler(mockRepo, nil)
+
+	w := performWithinRequest(handler, userID, url.Values{
+		"polygon":  {withinTestPolygon},
+		"mode":     {"stats"},
+		"deviceId": {"RACEBOX-001"},
+		"quality":  {"clean"},
+	})
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, userID, capturedFilter.UserID)
+	assert.Equal(t, "RACEBOX-001", capturedFilter.DeviceID)
+	assert.True(t, capturedFilter.CleanOnly)
+
+	var response struct {
+		models.AreaStats
+		Units struct {
+			Speed string `json:"speed"`
+		} `json:"units"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(420), response.Points)
+	assert.Equal(t, int64(3), response.Sessions)
+	assert.InDelta(t, 180, response.MaxSpeed, 1e-9)
+	assert.Equal(t, "km/h", response.Units.Speed)
+}
+
+func TestTelemetryHandler_Within_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewTelemetryHandler(repository.NewMockRepository(), nil)
+
+	tests := []struct {
+		name  string
+		query url.Values
+	}{
+		{name: "missing polygon", query: url.Values{}},
+		{name: "malformed polygon", query: url.Values{"polygon": {`{"type":"Polygon"`}}},
+		{name: "open ring", query: url.Values{"polygon": {`{"type":"Polygon","coordinates":[[[5.96,50.43],[5.98,50.43],[5.98,50.45],[5.96,50.45]]]}`}}},
+		{name: "unknown mode", query: url.Values{"polygon": {withinTestPolygon}, "mode": {"heatmap"}}},
+		{name: "processed", query: url.Values{"polygon": {withinTestPolygon}, "processed": {"true"}}},
+		{name: "limit too large", query: url.Values{"polygon": {withinTestPolygon}, "limit": {"5000"}}},
+		{name: "reversed range", query: url.Values{"polygon": {withinTestPolygon}, "from": {"2026-03-02T00:00:00Z"}, "to": {"2026-03-01T00:00:00Z"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performWithinRequest(handler, uuid.New(), tt.query)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// errInvalidTimezone is returned for names that are not IANA time zones, such as "Europe/Madrid"
+var errInvalidTimezone = errors.New("timezone must be an IANA time zone name such as Europe/Madrid")
+
+// timezonePreferences picks the time zone that calendar dates of a request are interpreted in
+// The timezone query parameter replaces the profile's time zone, which defaults to UTC. A nil
+// *timezonePreferences only honours the query parameter.
+type timezonePreferences struct {
+	userRepo repository.UserRepository
+}
+
+// newTimezonePreferences creates a time zone resolver backed by user profiles
+func newTimezonePreferences(userRepo repository.UserRepository) *timezonePreferences {
+	if userRepo == nil {
+		return nil
+	}
+	return &timezonePreferences{userRepo: userRepo}
+}
+
+// resolve returns the time zone for the request
+// It writes the error response and returns false when the timezone query parameter is invalid.
+func (p *timezonePreferences) resolve(c *gin.Context) (*time.Location, bool) {
+	if raw := c.Query("timezone"); raw != "" {
+		location, err := parseTimezone(raw)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+			return nil, false
+		}
+		return location, true
+	}
+
+	if p == nil {
+		return time.UTC, true
+	}
+
+	// Like units, a failed lookup or an unknown stored name falls back to UTC
+	userID := middleware.MustGetUserID(c)
+	name, err := p.userRepo.GetTimezone(c.Request.Context(), userID)
+	if err != nil {
+		slog.Error("Error retrieving timezone", "user_id", userID, "error", err)
+		return time.UTC, true
+	}
+	location, err := parseTimezone(name)
+	if err != nil {
+		return time.UTC, true
+	}
+	return location, true
+}
+
+// parseTimezone loads an IANA time zone by name
+// Unlike time.LoadLocation, it rejects "" and "Local", which depend on the server.
+func parseTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errInvalidTimezone
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errInvalidTimezone
+	}
+	return location, nil
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+func TestTimezonePreferences_Resolve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		query      string
+		preference string
+		lookupErr  error
+		configured bool
+		expected   string
+		valid      bool
+	}{
+		{name: "defaults to UTC", expected: "UTC", valid: true},
+		{name: "query parameter", query: "?timezone=Europe/Madrid", expected: "Europe/Madrid", valid: true},
+		{name: "profile preference", configured: true, preference: "America/New_York", expected: "America/New_York", valid: true},
+		{name: "query overrides profile", query: "?timezone=Asia/Tokyo", configured: true, preference: "America/New_York", expected: "Asia/Tokyo", valid: true},
+		{name: "no profile", configured: true, expected: "UTC", valid: true},
+		{name: "unknown preference", configured: true, preference: "Mars/Olympus", expected: "UTC", valid: true},
+		{name: "failed lookup", configured: true, lookupErr: errors.New("connection refused"), expected: "UTC", valid: true},
+		{name: "invalid query parameter", query: "?timezone=Mars/Olympus"},
+		{name: "server local time", query: "?timezone=Local"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var preferences *timezonePreferences
+			if tt.configured {
+				userRepo := repository.NewMockUserRepository()
+				userRepo.GetTimezoneFunc = func(_ context.Context, _ uuid.UUID) (string, error) {
+					return tt.preference, tt.lookupErr
+				}
+				preferences = newTimezonePreferences(userRepo)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			location, ok := preferences.resolve(c)
+			assert.Equal(t, tt.valid, ok)
+			if !tt.valid {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				return
+			}
+			assert.Equal(t, tt.expected, location.String())
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Default and maximum search radius for nearby circuits, in meters
+const (
+	defaultNearbyRadius = 50000.0
+	maxNearbyRadius     = 500000.0
+	nearbyCircuitLimit  = 20
+)
+
+// TrackHandler handles track management requests
+type TrackHandler struct {
+	trackRepo   repository.TrackRepository
+	circuitRepo repository.CircuitRepository // Optional: required for nearby circuit lookup
+}
+
+// NewTrackHandler creates a new track handler
+func NewTrackHandler(trackRepo repository.TrackRepository) *TrackHandler {
+	return &TrackHandler{
+		trackRepo: trackRepo,
+	}
+}
+
+// WithCircuitRepo sets the circuit catalog used for nearby lookups
+func (h *TrackHandler) WithCircuitRepo(circuitRepo repository.CircuitRepository) *TrackHandler {
+	h.circuitRepo = circuitRepo
+	return h
+}
+
+// CreateTrackRequest represents the track creation request body
+type CreateTrackRequest struct {
+	Name      string     `json:"name" binding:"required,max=255"`
+	LineStart *geo.Point `json:"lineStart" binding:"required"`
+	LineEnd   *geo.Point `json:"lineEnd" binding:"required"`
+}
+
+// CreateTrack defines a new track with its start/finish line
+// POST /api/v1/tracks
+func (h *TrackHandler) CreateTrack(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req CreateTrackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		problem.Abort(c, problem.BadRequest("invalid_request", "name is required"))
+		return
+	}
+
+	now := time.Now().UTC()
+	track := &models.Track{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		LineStart: *req.LineStart,
+		LineEnd:   *req.LineEnd,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := track.Validate(); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.trackRepo.Create(c.Request.Context(), track); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create track"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, track)
+}
+
+// ListTracks retrieves the authenticated user's tracks
+// GET /api/v1/tracks
+func (h *TrackHandler) ListTracks(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	tracks, err := h.trackRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve tracks"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "tracks", tracks, api.Meta{
+		"total": len(tracks),
+	})
+}
+
+// NearbyTracks lists known circuits near a coordinate, closest first
+// radius is in meters and defaults to 50 km.
+// GET /api/v1/tracks/nearby?lat=&lon=&radius=
+func (h *TrackHandler) NearbyTracks(c *gin.Context) {
+	lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	lon, lonErr := strconv.ParseFloat(c.Query("lon"), 64)
+	point := geo.Point{Latitude: lat, Longitude: lon}
+	if latErr != nil || lonErr != nil || !point.IsValid() {
+		problem.Abort(c, problem.BadRequest("invalid_request", "lat and lon must be valid WGS84 coordinates"))
+		return
+	}
+
+	radius := defaultNearbyRadius
+	if raw := c.Query("radius"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 || parsed > maxNearbyRadius {
+			problem.Abort(c, problem.BadRequest("invalid_request", fmt.Sprintf("radius must be a number of meters between 0 and %.0f", maxNearbyRadius)))
+			return
+		}
+		radius = parsed
+	}
+
+	if h.circuitRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("circuits_unavailable", "Circuit catalog is not configured"))
+		return
+	}
+
+	circuits, err := h.circuitRepo.Nearby(c.Request.Context(), point, radius, nearbyCircuitLimit)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve nearby tracks"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"tracks": circuits,
+		"total":  len(circuits),
+	})
+}
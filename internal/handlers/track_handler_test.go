@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTrackTest() (*TrackHandler, *repository.MockTrackRepository) {
+	trackRepo := repository.NewMockTrackRepository()
+	handler := NewTrackHandler(trackRepo)
+
+	gin.SetMode(gin.TestMode)
+
+	return handler, trackRepo
+}
+
+func TestTrackHandler_CreateTrack(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "success",
+			body:           `{"name":" Serres Circuit ","lineStart":{"latitude":41.0717,"longitude":23.5112},"lineEnd":{"latitude":41.0719,"longitude":23.5112}}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing line end",
+			body:           `{"name":"Serres","lineStart":{"latitude":41.0717,"longitude":23.5112}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "blank name",
+			body:           `{"name":"  ","lineStart":{"latitude":41.0717,"longitude":23.5112},"lineEnd":{"latitude":41.0719,"longitude":23.5112}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "line too long",
+			body:           `{"name":"Serres","lineStart":{"latitude":41.0717,"longitude":23.5112},"lineEnd":{"latitude":41.0817,"longitude":23.5112}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "coordinates out of range",
+			body:           `{"name":"Serres","lineStart":{"latitude":141.0717,"longitude":23.5112},"lineEnd":{"latitude":41.0719,"longitude":23.5112}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, trackRepo := setupTrackTest()
+			userID := uuid.New()
+
+			var created *models.Track
+			trackRepo.CreateFunc = func(_ context.Context, track *models.Track) error {
+				created = track
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/tracks", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.CreateTrack(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, created)
+				return
+			}
+
+			require.NotNil(t, created)
+			assert.Equal(t, userID, created.UserID)
+			assert.Equal(t, "Serres Circuit", created.Name)
+			assert.InDelta(t, 41.0719, created.LineEnd.Latitude, 1e-9)
+
+			var response models.Track
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, created.ID, response.ID)
+		})
+	}
+}
+
+func TestTrackHandler_ListTracks(t *testing.T) {
+	handler, trackRepo := setupTrackTest()
+	userID := uuid.New()
+
+	trackRepo.ListByUserIDFunc = func(_ context.Context, id uuid.UUID) ([]*models.Track, error) {
+		assert.Equal(t, userID, id)
+		return []*models.Track{{ID: uuid.New(), UserID: userID, Name: "Serres Circuit"}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/tracks", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListTracks(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Tracks []models.Track `json:"tracks"`
+		Total  int            `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, "Serres Circuit", response.Tracks[0].Name)
+}
+
+func TestTrackHandler_ListTracks_Error(t *testing.T) {
+	handler, trackRepo := setupTrackTest()
+	trackRepo.ListByUserIDFunc = func(_ context.Context, _ uuid.UUID) ([]*models.Track, error) {
+		return nil, errors.New("database unavailable")
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/tracks", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.ListTracks(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestTrackHandler_NearbyTracks(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		configured     bool
+		expectedStatus int
+		expectedRadius float64
+	}{
+		{name: "default radius", query: "lat=41.072&lon=23.512", configured: true, expectedStatus: http.StatusOK, expectedRadius: 50000},
+		{name: "custom radius", query: "lat=41.072&lon=23.512&radius=2000", configured: true, expectedStatus: http.StatusOK, expectedRadius: 2000},
+		{name: "missing lon", query: "lat=41.072", configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "latitude out of range", query: "lat=91&lon=23.512", configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "radius too large", query: "lat=41.072&lon=23.512&radius=600000", configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "zero radius", query: "lat=41.072&lon=23.512&radius=0", configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "catalog not configured", query: "lat=41.072&lon=23.512", configured: false, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupTrackTest()
+			if tt.configured {
+				circuitRepo := repository.NewMockCircuitRepository()
+				circuitRepo.NearbyFunc = func(_ context.Context, point geo.Point, radius float64, _ int) ([]*models.Circuit, error) {
+					assert.Equal(t, geo.Point{Latitude: 41.072, Longitude: 23.512}, point)
+					assert.Equal(t, tt.expectedRadius, radius)
+					return []*models.Circuit{{ID: uuid.New(), Name: "Serres Racing Circuit", Country: "GR"}}, nil
+				}
+				handler = handler.WithCircuitRepo(circuitRepo)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/tracks/nearby?"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.NearbyTracks(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Tracks []models.Circuit `json:"tracks"`
+					Total  int              `json:"total"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, 1, response.Total)
+				assert.Equal(t, "Serres Racing Circuit", response.Tracks[0].Name)
+			}
+		})
+	}
+}
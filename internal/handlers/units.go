@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/sebasr/avt-service/internal/units"
+)
+
+// unitPreferences picks the unit system of telemetry and stats responses
+// The units query parameter wins over the units preference in the user's profile. A nil
+// *unitPreferences only honours the query parameter, so responses default to metric.
+type unitPreferences struct {
+	userRepo repository.UserRepository
+}
+
+// newUnitPreferences creates a unit system resolver backed by user profiles
+func newUnitPreferences(userRepo repository.UserRepository) *unitPreferences {
+	if userRepo == nil {
+		return nil
+	}
+	return &unitPreferences{userRepo: userRepo}
+}
+
+// resolve returns the unit system for the request
+// It writes the error response and returns false when the units query parameter is invalid.
+func (p *unitPreferences) resolve(c *gin.Context) (units.System, bool) {
+	if raw := c.Query("units"); raw != "" {
+		system, err := units.Parse(raw)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+			return "", false
+		}
+		return system, true
+	}
+
+	if p == nil {
+		return units.Metric, true
+	}
+
+	// The preference only changes presentation, so a failed lookup falls back to metric
+	userID := middleware.MustGetUserID(c)
+	preference, err := p.userRepo.GetUnitsPreference(c.Request.Context(), userID)
+	if err != nil {
+		slog.Error("Error retrieving units preference", "user_id", userID, "error", err)
+		return units.Metric, true
+	}
+	system, err := units.Parse(preference)
+	if err != nil {
+		return units.Metric, true
+	}
+	return system, true
+}
+
+// convertTelemetry converts the speed and altitudes of telemetry records in place
+func convertTelemetry(system units.System, records []*models.TelemetryData) {
+	if system == units.Metric {
+		return
+	}
+	for _, record := range records {
+		record.GPS.Speed = system.Speed(record.GPS.Speed)
+		record.GPS.WgsAltitude = system.Altitude(record.GPS.WgsAltitude)
+		record.GPS.MslAltitude = system.Altitude(record.GPS.MslAltitude)
+		if derived := record.Derived; derived != nil {
+			derived.SpeedDelta = system.SpeedPtr(derived.SpeedDelta)
+			derived.VerticalSpeed = convertPtr(system.Altitude, derived.VerticalSpeed) // Per second
+			derived.DistanceIncrement = convertPtr(system.Distance, derived.DistanceIncrement)
+		}
+	}
+}
+
+// convertPtr applies a conversion to an optional value
+func convertPtr(convert func(float64) float64, value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	converted := convert(*value)
+	return &converted
+}
+
+// convertBuckets converts the speeds of telemetry aggregate buckets in place
+func convertBuckets(system units.System, buckets []*models.TelemetryBucket) {
+	if system == units.Metric {
+		return
+	}
+	for _, bucket := range buckets {
+		bucket.AvgSpeed = system.Speed(bucket.AvgSpeed)
+		bucket.MaxSpeed = system.Speed(bucket.MaxSpeed)
+	}
+}
+
+// convertHeatmapCells converts heatmap cell speeds
+func convertHeatmapCells(system units.System, cells []*models.HeatmapCell) {
+	if system == units.Metric {
+		return
+	}
+	for _, cell := range cells {
+		cell.AvgSpeed = system.Speed(cell.AvgSpeed)
+		cell.MaxSpeed = system.Speed(cell.MaxSpeed)
+	}
+}
+
+// convertPositions converts device position speeds
+func convertPositions(system units.System, positions []*models.DevicePosition) {
+	if system == units.Metric {
+		return
+	}
+	for _, position := range positions {
+		position.Speed = system.Speed(position.Speed)
+	}
+}
+
+// summaryResponse is a session summary annotated with its units
+type summaryResponse struct {
+	*models.SessionSummary
+	Units units.Labels `json:"units"`
+}
+
+// convertSummary converts a session summary's distance and speeds
+func convertSummary(system units.System, summary *models.SessionSummary) summaryResponse {
+	converted := *summary
+	converted.TotalDistance = system.Distance(summary.TotalDistance)
+	converted.MaxSpeed = system.SpeedPtr(summary.MaxSpeed)
+	converted.AvgSpeed = system.SpeedPtr(summary.AvgSpeed)
+	return summaryResponse{SessionSummary: &converted, Units: system.Labels()}
+}
+
+// statsResponse is a session's detailed stats annotated with their units
+type statsResponse struct {
+	*models.SessionStats
+	Units units.Labels `json:"units"`
+}
+
+// convertStats converts a session's speed percentiles, speed bands and altitude gain
+func convertStats(system units.System, stats *models.SessionStats) statsResponse {
+	converted := *stats
+	converted.Speed = models.SpeedPercentiles{
+		P50: system.SpeedPtr(stats.Speed.P50),
+		P75: system.SpeedPtr(stats.Speed.P75),
+		P90: system.SpeedPtr(stats.Speed.P90),
+		P95: system.SpeedPtr(stats.Speed.P95),
+		P99: system.SpeedPtr(stats.Speed.P99),
+	}
+	converted.AltitudeGain = system.Altitude(stats.AltitudeGain)
+	converted.TimeInSpeedBands = make([]models.SpeedBandTime, len(stats.TimeInSpeedBands))
+	for i, band := range stats.TimeInSpeedBands {
+		converted.TimeInSpeedBands[i] = models.SpeedBandTime{
+			MinSpeed: system.Speed(band.MinSpeed),
+			MaxSpeed: system.SpeedPtr(band.MaxSpeed),
+			Seconds:  band.Seconds,
+		}
+	}
+	return statsResponse{SessionStats: &converted, Units: system.Labels()}
+}
+
+// areaStatsResponse is the summary of the telemetry within an area annotated with its units
+type areaStatsResponse struct {
+	*models.AreaStats
+	Units units.Labels `json:"units"`
+}
+
+// convertAreaStats converts the speeds of an area summary
+func convertAreaStats(system units.System, stats *models.AreaStats) areaStatsResponse {
+	converted := *stats
+	converted.AvgSpeed = system.Speed(stats.AvgSpeed)
+	converted.MaxSpeed = system.Speed(stats.MaxSpeed)
+	return areaStatsResponse{AreaStats: &converted, Units: system.Labels()}
+}
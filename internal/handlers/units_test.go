@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/sebasr/avt-service/internal/units"
+)
+
+func TestUnitPreferences_Resolve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		query      string
+		preference string
+		lookupErr  error
+		configured bool
+		expected   units.System
+		valid      bool
+	}{
+		{name: "defaults to metric", expected: units.Metric, valid: true},
+		{name: "query parameter", query: "?units=imperial", expected: units.Imperial, valid: true},
+		{name: "profile preference", configured: true, preference: "imperial", expected: units.Imperial, valid: true},
+		{name: "query overrides profile", query: "?units=metric", configured: true, preference: "imperial", expected: units.Metric, valid: true},
+		{name: "unknown preference", configured: true, preference: "furlongs", expected: units.Metric, valid: true},
+		{name: "failed lookup", configured: true, lookupErr: errors.New("connection refused"), expected: units.Metric, valid: true},
+		{name: "invalid query parameter", query: "?units=nautical"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var preferences *unitPreferences
+			if tt.configured {
+				userRepo := repository.NewMockUserRepository()
+				userRepo.GetUnitsPreferenceFunc = func(_ context.Context, _ uuid.UUID) (string, error) {
+					return tt.preference, tt.lookupErr
+				}
+				preferences = newUnitPreferences(userRepo)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry"+tt.query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			system, ok := preferences.resolve(c)
+			assert.Equal(t, tt.valid, ok)
+			if !tt.valid {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				return
+			}
+			assert.Equal(t, tt.expected, system)
+		})
+	}
+}
+
+func TestTelemetryHandler_Query_Imperial(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := repository.NewMockRepository()
+	mockRepo.QueryFunc = func(_ context.Context, _ repository.TelemetryFilter) ([]*models.TelemetryData, error) {
+		return []*models.TelemetryData{
+			{Timestamp: time.Now(), GPS: models.GpsData{Speed: 160.9344, MslAltitude: 304.8}},
+		}, nil
+	}
+	userRepo := repository.NewMockUserRepository()
+	userRepo.GetUnitsPreferenceFunc = func(_ context.Context, _ uuid.UUID) (string, error) {
+		return "imperial", nil
+	}
+	handler := NewTelemetryHandler(mockRepo, nil).WithUnitPreferences(userRepo)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/telemetry", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.HandleQuery(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Telemetry []models.TelemetryData `json:"telemetry"`
+		Units     units.Labels           `json:"units"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Telemetry, 1)
+	assert.InDelta(t, 100, response.Telemetry[0].GPS.Speed, 0.001)
+	assert.InDelta(t, 1000, response.Telemetry[0].GPS.MslAltitude, 0.001)
+	assert.Equal(t, units.Imperial.Labels(), response.Units)
+}
+
+func TestSessionHandler_GetSessionStats_Imperial(t *testing.T) {
+	handler, sessionRepo, _ := setupSessionTest()
+	userID := uuid.New()
+	endedAt := time.Now().Add(-time.Hour)
+	p50, bandMax := 160.9344, 80.4672
+
+	sessionRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, UserID: &userID, StartedAt: endedAt.Add(-time.Hour), EndedAt: &endedAt}, nil
+	}
+	sessionRepo.GetStatsFunc = func(_ context.Context, id uuid.UUID) (*models.SessionStats, error) {
+		return &models.SessionStats{
+			SessionID:        id,
+			Speed:            models.SpeedPercentiles{P50: &p50},
+			AltitudeGain:     30.48,
+			TimeInSpeedBands: []models.SpeedBandTime{{MinSpeed: 0, MaxSpeed: &bandMax, Seconds: 60}},
+			ComputedAt:       time.Now(),
+		}, nil
+	}
+
+	sessionID := uuid.New().String()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID+"/stats?units=imperial", nil)
+	c.Params = gin.Params{{Key: "id", Value: sessionID}}
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.GetSessionStats(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		models.SessionStats
+		Units units.Labels `json:"units"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.InDelta(t, 100, *response.Speed.P50, 0.001)
+	assert.InDelta(t, 100, response.AltitudeGain, 0.001)
+	assert.InDelta(t, 50, *response.TimeInSpeedBands[0].MaxSpeed, 0.001)
+	assert.Equal(t, "mph", response.Units.Speed)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// CreatePersonalAccessTokenRequest represents the request body minting a personal access token
+// Tokens without expiresInDays never expire.
+type CreatePersonalAccessTokenRequest struct {
+	Name          string         `json:"name" binding:"required,max=255"`
+	Scopes        []models.Scope `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays *int           `json:"expiresInDays,omitempty" binding:"omitempty,min=1,max=365"`
+}
+
+// CreatePersonalAccessTokenResponse includes the plain token, which is only returned once
+type CreatePersonalAccessTokenResponse struct {
+	*models.PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// CreateAccessToken mints a personal access token for the authenticated user
+// POST /api/v1/users/me/tokens
+func (h *UserHandler) CreateAccessToken(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if !h.accessTokensEnabled(c) {
+		return
+	}
+
+	var req CreatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	scopes := make([]models.Scope, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !scope.IsGrantable() {
+			problem.Abort(c, problem.BadRequest("invalid_scope", "scopes must be telemetry:read, telemetry:write or devices:manage"))
+			return
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	plain, prefix, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to generate token"))
+		return
+	}
+
+	now := time.Now().UTC()
+	token := &models.PersonalAccessToken{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        req.Name,
+		TokenPrefix: prefix,
+		TokenHash:   auth.HashToken(plain),
+		Scopes:      scopes,
+		CreatedAt:   now,
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := now.AddDate(0, 0, *req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := h.accessTokenRepo.Create(c.Request.Context(), token); err != nil {
+		problem.Abort(c, problem.Internal("Failed to create token"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, CreatePersonalAccessTokenResponse{
+		PersonalAccessToken: token,
+		Token:               plain,
+	})
+}
+
+// ListAccessTokens lists the authenticated user's personal access tokens, including revoked ones
+// GET /api/v1/users/me/tokens
+func (h *UserHandler) ListAccessTokens(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if !h.accessTokensEnabled(c) {
+		return
+	}
+
+	tokens, err := h.accessTokenRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve tokens"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "tokens", tokens, api.Meta{
+		"total": len(tokens),
+	})
+}
+
+// RevokeAccessToken revokes one of the authenticated user's personal access tokens
+// DELETE /api/v1/users/me/tokens/:id
+func (h *UserHandler) RevokeAccessToken(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if !h.accessTokensEnabled(c) {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_token_id", "Invalid token ID format"))
+		return
+	}
+
+	if err := h.accessTokenRepo.Revoke(c.Request.Context(), userID, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrPersonalAccessTokenNotFound):
+			problem.Abort(c, problem.NotFound("token_not_found", "Token not found"))
+		case errors.Is(err, repository.ErrPersonalAccessTokenRevoked):
+			problem.Abort(c, problem.Conflict("token_already_revoked", "Token has already been revoked"))
+		default:
+			problem.Abort(c, problem.Internal("Failed to revoke token"))
+		}
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Token revoked successfully",
+	})
+}
+
+// accessTokensEnabled responds with an error when personal access tokens are not configured
+func (h *UserHandler) accessTokensEnabled(c *gin.Context) bool {
+	if h.accessTokenRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("tokens_unavailable", "Personal access tokens are not configured"))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserHandler_CreateAccessToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedScopes []models.Scope
+		expectExpiry   bool
+	}{
+		{
+			name:           "read-only token",
+			body:           `{"name":"Grafana","scopes":["telemetry:read"]}`,
+			expectedStatus: http.StatusCreated,
+			expectedScopes: []models.Scope{models.ScopeTelemetryRead},
+		},
+		{
+			name:           "expiring ingest token",
+			body:           `{"name":"Logger","scopes":["telemetry:write","telemetry:write"],"expiresInDays":30}`,
+			expectedStatus: http.StatusCreated,
+			expectedScopes: []models.Scope{models.ScopeTelemetryWrite},
+			expectExpiry:   true,
+		},
+		{
+			name:           "device management token",
+			body:           `{"name":"Fleet script","scopes":["devices:manage","telemetry:read"]}`,
+			expectedStatus: http.StatusCreated,
+			expectedScopes: []models.Scope{models.ScopeDevicesManage, models.ScopeTelemetryRead},
+		},
+		{name: "admin scope", body: `{"name":"Admin","scopes":["admin"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown scope", body: `{"name":"Delete","scopes":["telemetry:delete"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "no scopes", body: `{"name":"Empty","scopes":[]}`, expectedStatus: http.StatusBadRequest},
+		{name: "missing name", body: `{"scopes":["telemetry:read"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "expiry too long", body: `{"name":"Forever","scopes":["telemetry:read"],"expiresInDays":400}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupUserTest()
+			tokenRepo := repository.NewMockPersonalAccessTokenRepository()
+			handler.WithPersonalAccessTokens(tokenRepo)
+			userID := uuid.New()
+
+			var created *models.PersonalAccessToken
+			tokenRepo.CreateFunc = func(_ context.Context, token *models.PersonalAccessToken) error {
+				created = token
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/users/me/tokens", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.CreateAccessToken(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, created)
+				return
+			}
+
+			require.NotNil(t, created)
+			assert.Equal(t, userID, created.UserID)
+			assert.Equal(t, tt.expectedScopes, created.Scopes)
+			if tt.expectExpiry {
+				require.NotNil(t, created.ExpiresAt)
+				assert.WithinDuration(t, time.Now().AddDate(0, 0, 30), *created.ExpiresAt, time.Minute)
+			} else {
+				assert.Nil(t, created.ExpiresAt)
+			}
+
+			var response CreatePersonalAccessTokenResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.True(t, strings.HasPrefix(response.Token, auth.PersonalAccessTokenPrefix))
+			assert.Equal(t, auth.HashToken(response.Token), created.TokenHash, "only the hash is stored")
+			assert.NotContains(t, w.Body.String(), created.TokenHash)
+		})
+	}
+}
+
+func TestUserHandler_RevokeAccessToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		revokeErr      error
+		expectedStatus int
+	}{
+		{name: "revoked", id: uuid.NewString(), expectedStatus: http.StatusOK},
+		{name: "not found", id: uuid.NewString(), revokeErr: repository.ErrPersonalAccessTokenNotFound, expectedStatus: http.StatusNotFound},
+		{name: "already revoked", id: uuid.NewString(), revokeErr: repository.ErrPersonalAccessTokenRevoked, expectedStatus: http.StatusConflict},
+		{name: "invalid id", id: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupUserTest()
+			tokenRepo := repository.NewMockPersonalAccessTokenRepository()
+			handler.WithPersonalAccessTokens(tokenRepo)
+			userID := uuid.New()
+
+			tokenRepo.RevokeFunc = func(_ context.Context, owner, id uuid.UUID) error {
+				assert.Equal(t, userID, owner)
+				assert.Equal(t, tt.id, id.String())
+				return tt.revokeErr
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/tokens/"+tt.id, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.RevokeAccessToken(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUserHandler_ListAccessTokens_NotConfigured(t *testing.T) {
+	handler, _ := setupUserTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/tokens", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.ListAccessTokens(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -1,13 +1,17 @@
 package handlers
 
 import (
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
 	"github.com/sebasr/avt-service/internal/auth"
 	"github.com/sebasr/avt-service/internal/email"
 	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/problem"
 	"github.com/sebasr/avt-service/internal/repository"
 )
 
@@ -15,7 +19,13 @@ import (
 type UserHandler struct {
 	userRepo         repository.UserRepository
 	refreshTokenRepo repository.RefreshTokenRepository
+	denylist         *auth.Denylist // Optional: nil leaves access tokens valid until they expire
 	emailService     email.Service
+	passwordPolicy   *auth.PasswordPolicy                     // Optional: nil only enforces the 8-72 character length
+	quotas           *Quotas                                  // Optional: required for usage reporting
+	pushTokenRepo    repository.PushTokenRepository           // Optional: required for push token registration
+	accessTokenRepo  repository.PersonalAccessTokenRepository // Optional: required for personal access tokens
+	loginEventRepo   repository.LoginEventRepository          // Optional: required for login activity
 }
 
 // NewUserHandler creates a new user handler
@@ -31,16 +41,48 @@ func (h *UserHandler) WithRefreshTokenRepo(repo repository.RefreshTokenRepositor
 	return h
 }
 
+// WithDenylist revokes outstanding access tokens when the password changes
+func (h *UserHandler) WithDenylist(denylist *auth.Denylist) *UserHandler {
+	h.denylist = denylist
+	return h
+}
+
 // WithEmailService sets the email service for sending notifications
 func (h *UserHandler) WithEmailService(emailService email.Service) *UserHandler {
 	h.emailService = emailService
 	return h
 }
 
+// WithPasswordPolicy sets the policy new passwords are checked against
+func (h *UserHandler) WithPasswordPolicy(policy *auth.PasswordPolicy) *UserHandler {
+	h.passwordPolicy = policy
+	return h
+}
+
+// WithQuotas sets the quota enforcer used to report plan usage
+func (h *UserHandler) WithQuotas(quotas *Quotas) *UserHandler {
+	h.quotas = quotas
+	return h
+}
+
+// WithPushTokens sets the repository of the push tokens users' mobile apps register
+func (h *UserHandler) WithPushTokens(repo repository.PushTokenRepository) *UserHandler {
+	h.pushTokenRepo = repo
+	return h
+}
+
+// WithPersonalAccessTokens sets the repository of the personal access tokens users mint for scripts and integrations
+func (h *UserHandler) WithPersonalAccessTokens(repo repository.PersonalAccessTokenRepository) *UserHandler {
+	h.accessTokenRepo = repo
+	return h
+}
+
 // UpdateProfileRequest represents the profile update request body
 type UpdateProfileRequest struct {
-	DisplayName *string `json:"displayName,omitempty"`
-	AvatarURL   *string `json:"avatarUrl,omitempty"`
+	DisplayName       *string `json:"displayName,omitempty"`
+	AvatarURL         *string `json:"avatarUrl,omitempty"`
+	PrivacyTrimMeters *int    `json:"privacyTrimMeters,omitempty" binding:"omitempty,min=0,max=5000"` // Hidden at each end of shared and exported tracks
+	Timezone          *string `json:"timezone,omitempty"`                                             // IANA name, e.g. Europe/Madrid
 }
 
 // ChangePasswordRequest represents the password change request body
@@ -51,14 +93,16 @@ type ChangePasswordRequest struct {
 
 // UserProfileResponse represents the user profile response
 type UserProfileResponse struct {
-	ID            string  `json:"id"`
-	Email         string  `json:"email"`
-	EmailVerified bool    `json:"emailVerified"`
-	DisplayName   *string `json:"displayName,omitempty"`
-	AvatarURL     *string `json:"avatarUrl,omitempty"`
-	IsActive      bool    `json:"isActive"`
-	CreatedAt     string  `json:"createdAt"`
-	LastLoginAt   *string `json:"lastLoginAt,omitempty"`
+	ID                string  `json:"id"`
+	Email             string  `json:"email"`
+	EmailVerified     bool    `json:"emailVerified"`
+	DisplayName       *string `json:"displayName,omitempty"`
+	AvatarURL         *string `json:"avatarUrl,omitempty"`
+	IsActive          bool    `json:"isActive"`
+	CreatedAt         string  `json:"createdAt"`
+	LastLoginAt       *string `json:"lastLoginAt,omitempty"`
+	PrivacyTrimMeters int     `json:"privacyTrimMeters"` // Hidden at each end of shared and exported tracks
+	Timezone          string  `json:"timezone"`          // Interprets session listing dates; defaults to UTC
 }
 
 // GetProfile retrieves the authenticated user's profile
@@ -69,16 +113,20 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
 		if err == repository.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "user_not_found",
-				"message": "User not found",
-			})
+			problem.Abort(c, problem.NotFound("user_not_found", "User not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to retrieve profile",
-		})
+		problem.Abort(c, problem.Internal("Failed to retrieve profile"))
+		return
+	}
+
+	privacyTrim, err := h.userRepo.GetPrivacyTrimMeters(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve profile"))
+		return
+	}
+	timezone, ok := h.profileTimezone(c, userID)
+	if !ok {
 		return
 	}
 
@@ -88,13 +136,15 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		lastLoginAt = &loginStr
 	}
 
-	c.JSON(http.StatusOK, UserProfileResponse{
-		ID:            user.ID.String(),
-		Email:         user.Email,
-		EmailVerified: user.EmailVerified,
-		IsActive:      user.IsActive,
-		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		LastLoginAt:   lastLoginAt,
+	api.Respond(c, http.StatusOK, UserProfileResponse{
+		ID:                user.ID.String(),
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerified,
+		IsActive:          user.IsActive,
+		CreatedAt:         user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		LastLoginAt:       lastLoginAt,
+		PrivacyTrimMeters: privacyTrim,
+		Timezone:          timezone,
 	})
 }
 
@@ -105,10 +155,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	var req UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request body: " + err.Error(),
-		})
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
 		return
 	}
 
@@ -116,42 +163,73 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
 		if err == repository.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "user_not_found",
-				"message": "User not found",
-			})
+			problem.Abort(c, problem.NotFound("user_not_found", "User not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to retrieve profile",
-		})
+		problem.Abort(c, problem.Internal("Failed to retrieve profile"))
 		return
 	}
 
-	// Note: In the current implementation, we don't have a UserProfile table yet,
-	// so we're just validating the request structure. When Phase 4 user_profiles
-	// table is ready, we would update it here.
+	if req.PrivacyTrimMeters != nil {
+		if err := h.userRepo.SetPrivacyTrimMeters(c.Request.Context(), userID, *req.PrivacyTrimMeters); err != nil {
+			problem.Abort(c, problem.Internal("Failed to update profile"))
+			return
+		}
+	}
+	if req.Timezone != nil {
+		if _, err := parseTimezone(*req.Timezone); err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+			return
+		}
+		if err := h.userRepo.SetTimezone(c.Request.Context(), userID, *req.Timezone); err != nil {
+			problem.Abort(c, problem.Internal("Failed to update profile"))
+			return
+		}
+	}
+	privacyTrim, err := h.userRepo.GetPrivacyTrimMeters(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve profile"))
+		return
+	}
+	timezone, ok := h.profileTimezone(c, userID)
+	if !ok {
+		return
+	}
 
-	// For now, just return success with the current user data
+	// Note: display name and avatar are not stored yet, so they are only validated and echoed back
 	var lastLoginAt *string
 	if user.LastLoginAt != nil {
 		loginStr := user.LastLoginAt.Format("2006-01-02T15:04:05Z07:00")
 		lastLoginAt = &loginStr
 	}
 
-	c.JSON(http.StatusOK, UserProfileResponse{
-		ID:            user.ID.String(),
-		Email:         user.Email,
-		EmailVerified: user.EmailVerified,
-		DisplayName:   req.DisplayName,
-		AvatarURL:     req.AvatarURL,
-		IsActive:      user.IsActive,
-		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		LastLoginAt:   lastLoginAt,
+	api.Respond(c, http.StatusOK, UserProfileResponse{
+		ID:                user.ID.String(),
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerified,
+		DisplayName:       req.DisplayName,
+		AvatarURL:         req.AvatarURL,
+		IsActive:          user.IsActive,
+		CreatedAt:         user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		LastLoginAt:       lastLoginAt,
+		PrivacyTrimMeters: privacyTrim,
+		Timezone:          timezone,
 	})
 }
 
+// profileTimezone returns the time zone of the user's profile, or UTC if it has none
+func (h *UserHandler) profileTimezone(c *gin.Context, userID uuid.UUID) (string, bool) {
+	timezone, err := h.userRepo.GetTimezone(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve profile"))
+		return "", false
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return timezone, true
+}
+
 // ChangePassword changes the authenticated user's password
 // POST /api/v1/users/me/change-password
 func (h *UserHandler) ChangePassword(c *gin.Context) {
@@ -159,10 +237,7 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request body: " + err.Error(),
-		})
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
 		return
 	}
 
@@ -170,73 +245,136 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
 		if err == repository.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "user_not_found",
-				"message": "User not found",
-			})
+			problem.Abort(c, problem.NotFound("user_not_found", "User not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to retrieve user",
-		})
+		problem.Abort(c, problem.Internal("Failed to retrieve user"))
 		return
 	}
 
 	// Verify current password
 	if !auth.VerifyPassword(req.CurrentPassword, user.PasswordHash) {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "invalid_password",
-			"message": "Current password is incorrect",
-		})
+		problem.Abort(c, problem.Unauthorized("invalid_password", "Current password is incorrect"))
 		return
 	}
 
 	// Check if new password is the same as current
 	if auth.VerifyPassword(req.NewPassword, user.PasswordHash) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "same_password",
-			"message": "New password must be different from current password",
-		})
+		problem.Abort(c, problem.BadRequest("same_password", "New password must be different from current password"))
+		return
+	}
+
+	// Enforce password policy
+	if rejectWeakPassword(c, h.passwordPolicy, req.NewPassword) {
 		return
 	}
 
 	// Hash new password
 	newPasswordHash, err := auth.HashPassword(req.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to process password change",
-		})
+		problem.Abort(c, problem.Internal("Failed to process password change"))
 		return
 	}
 
 	// Update password
 	if err := h.userRepo.UpdatePassword(c.Request.Context(), userID, newPasswordHash); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to update password",
-		})
+		problem.Abort(c, problem.Internal("Failed to update password"))
 		return
 	}
 
 	// Revoke all refresh tokens for security (invalidate other sessions)
 	if h.refreshTokenRepo != nil {
 		if err := h.refreshTokenRepo.RevokeAllForUser(c.Request.Context(), userID); err != nil {
-			log.Printf("Error revoking refresh tokens after password change: %v", err)
+			slog.Error("Error revoking refresh tokens after password change", "error", err)
 			// Non-critical, continue
 		}
 	}
+	if err := h.denylist.RevokeUser(c.Request.Context(), userID); err != nil {
+		slog.Error("Error revoking access tokens after password change", "error", err)
+		// Non-critical, continue
+	}
 
 	// Send password changed notification email
 	if h.emailService != nil {
 		if err := h.emailService.SendPasswordChangedEmail(c.Request.Context(), user.Email); err != nil {
-			log.Printf("Error sending password changed email: %v", err)
+			slog.Error("Error sending password changed email", "error", err)
 			// Non-critical, continue
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	api.Respond(c, http.StatusOK, gin.H{
 		"message": "Password changed successfully",
 	})
 }
+
+// ListSessions retrieves the clients currently signed in to the authenticated user's account
+// GET /api/v1/users/me/sessions
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if h.refreshTokenRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("sessions_unavailable", "Session management is not configured"))
+		return
+	}
+
+	sessions, err := h.refreshTokenRepo.ListActiveSessions(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve sessions"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "sessions", sessions, api.Meta{
+		"total": len(sessions),
+	})
+}
+
+// GetUsage reports the authenticated user's plan, limits and usage for the current month
+// GET /api/v1/users/me/usage
+func (h *UserHandler) GetUsage(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if h.quotas == nil {
+		problem.Abort(c, problem.ServiceUnavailable("usage_unavailable", "Usage quotas are not enabled"))
+		return
+	}
+
+	usage, err := h.quotas.Usage(c.Request.Context(), userID)
+	if err != nil {
+		slog.Error("Error retrieving usage", "user_id", userID, "error", err)
+		problem.Abort(c, problem.Internal("Failed to retrieve usage"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, usage)
+}
+
+// RevokeSession signs out one client by revoking its refresh token
+// Its current access token stays valid until it expires.
+// DELETE /api/v1/users/me/sessions/:id
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if h.refreshTokenRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("sessions_unavailable", "Session management is not configured"))
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_session_id", "Invalid session ID format"))
+		return
+	}
+
+	if err := h.refreshTokenRepo.RevokeForUser(c.Request.Context(), sessionID, userID); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			problem.Abort(c, problem.NotFound("session_not_found", "Session not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to revoke session"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
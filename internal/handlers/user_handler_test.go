@@ -136,6 +136,87 @@ func TestUserHandler_UpdateProfile_Success(t *testing.T) {
 	assert.Equal(t, avatarURL, *response.AvatarURL)
 }
 
+func TestUserHandler_UpdateProfile_PrivacyTrim(t *testing.T) {
+	handler, userRepo := setupUserTest()
+
+	userID := uuid.New()
+	userRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+		return &models.User{ID: id, Email: "test@example.com", IsActive: true}, nil
+	}
+	stored := 0
+	userRepo.SetPrivacyTrimMetersFunc = func(_ context.Context, id uuid.UUID, meters int) error {
+		assert.Equal(t, userID, id)
+		stored = meters
+		return nil
+	}
+	userRepo.GetPrivacyTrimMetersFunc = func(_ context.Context, _ uuid.UUID) (int, error) {
+		return stored, nil
+	}
+
+	update := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/users/me", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set(string(middleware.UserIDKey), userID)
+		handler.UpdateProfile(c)
+		return w
+	}
+
+	w := update(`{"privacyTrimMeters":250}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response UserProfileResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 250, response.PrivacyTrimMeters)
+
+	w = update(`{"privacyTrimMeters":10000}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 250, stored)
+}
+
+func TestUserHandler_UpdateProfile_Timezone(t *testing.T) {
+	handler, userRepo := setupUserTest()
+
+	userID := uuid.New()
+	userRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+		return &models.User{ID: id, Email: "test@example.com", IsActive: true}, nil
+	}
+	stored := ""
+	userRepo.SetTimezoneFunc = func(_ context.Context, id uuid.UUID, timezone string) error {
+		assert.Equal(t, userID, id)
+		stored = timezone
+		return nil
+	}
+	userRepo.GetTimezoneFunc = func(_ context.Context, _ uuid.UUID) (string, error) {
+		return stored, nil
+	}
+
+	update := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/users/me", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set(string(middleware.UserIDKey), userID)
+		handler.UpdateProfile(c)
+		return w
+	}
+
+	w := update(`{}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response UserProfileResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "UTC", response.Timezone, "profiles without a time zone use UTC")
+
+	w = update(`{"timezone":"Europe/Madrid"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Europe/Madrid", response.Timezone)
+
+	w = update(`{"timezone":"CEST"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Europe/Madrid", stored)
+}
+
 func TestUserHandler_UpdateProfile_InvalidRequest(t *testing.T) {
 	handler, _ := setupUserTest()
 
@@ -346,3 +427,147 @@ func TestUserHandler_ChangePassword_UserNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	assert.Contains(t, w.Body.String(), "user_not_found")
 }
+
+func TestUserHandler_ListSessions(t *testing.T) {
+	handler, _ := setupUserTest()
+	refreshTokenRepo := repository.NewMockRefreshTokenRepository()
+	handler.WithRefreshTokenRepo(refreshTokenRepo)
+
+	userID := uuid.New()
+	signedIn := time.Now().Add(-72 * time.Hour)
+	refreshTokenRepo.ListActiveSessionsFunc = func(_ context.Context, id uuid.UUID) ([]*models.LoginSession, error) {
+		assert.Equal(t, userID, id)
+		return []*models.LoginSession{{
+			ID:         uuid.New(),
+			UserAgent:  "RaceBox/2.1 (iOS)",
+			IPAddress:  "203.0.113.7",
+			CreatedAt:  signedIn,
+			LastUsedAt: time.Now().Add(-time.Hour),
+			ExpiresAt:  time.Now().Add(29 * 24 * time.Hour),
+		}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/sessions", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.ListSessions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Sessions []models.LoginSession `json:"sessions"`
+		Total    int                   `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, "RaceBox/2.1 (iOS)", response.Sessions[0].UserAgent)
+	assert.Equal(t, "203.0.113.7", response.Sessions[0].IPAddress)
+	assert.WithinDuration(t, signedIn, response.Sessions[0].CreatedAt, time.Second)
+}
+
+func TestUserHandler_RevokeSession(t *testing.T) {
+	userID := uuid.New()
+	sessionID := uuid.New()
+
+	tests := []struct {
+		name           string
+		param          string
+		expectedStatus int
+	}{
+		{"success", sessionID.String(), http.StatusOK},
+		{"invalid id", "not-a-uuid", http.StatusBadRequest},
+		{"not found or not owned", uuid.New().String(), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupUserTest()
+			refreshTokenRepo := repository.NewMockRefreshTokenRepository()
+			handler.WithRefreshTokenRepo(refreshTokenRepo)
+
+			refreshTokenRepo.RevokeForUserFunc = func(_ context.Context, id uuid.UUID, ownerID uuid.UUID) error {
+				assert.Equal(t, userID, ownerID)
+				if id != sessionID {
+					return repository.ErrRefreshTokenNotFound
+				}
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/sessions/"+tt.param, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.param}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.RevokeSession(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUserHandler_Sessions_Unavailable(t *testing.T) {
+	handler, _ := setupUserTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/sessions", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.ListSessions(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestUserHandler_GetUsage(t *testing.T) {
+	handler, _ := setupUserTest()
+
+	userID := uuid.New()
+	usageRepo := repository.NewMockUsageRepository()
+	usageRepo.GetTelemetryPointsFunc = func(_ context.Context, id uuid.UUID, periodStart time.Time) (int64, error) {
+		assert.Equal(t, userID, id)
+		assert.Equal(t, 1, periodStart.Day())
+		return 1500, nil
+	}
+	deviceRepo := repository.NewMockDeviceRepository()
+	deviceRepo.ListFunc = func(_ context.Context, _ repository.DeviceFilter) ([]*models.Device, int, error) {
+		return []*models.Device{}, 2, nil
+	}
+	handler.WithQuotas(NewQuotas(usageRepo, deviceRepo, QuotaPolicy{Plans: map[models.Plan]models.PlanLimits{
+		models.PlanFree: {TelemetryPointsPerMonth: 100000},
+	}}))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/usage", nil)
+	c.Set(string(middleware.UserIDKey), userID)
+
+	handler.GetUsage(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var usage models.Usage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &usage))
+	assert.Equal(t, models.PlanFree, usage.Plan)
+	assert.Equal(t, int64(1500), usage.TelemetryPoints.Used)
+	require.NotNil(t, usage.TelemetryPoints.Limit)
+	assert.Equal(t, int64(100000), *usage.TelemetryPoints.Limit)
+	assert.Equal(t, int64(2), usage.Devices.Used)
+	assert.Nil(t, usage.Devices.Limit, "a zero limit is reported as unlimited")
+	assert.Equal(t, usage.PeriodStart.AddDate(0, 1, 0), usage.PeriodEnd)
+}
+
+func TestUserHandler_GetUsage_Unavailable(t *testing.T) {
+	handler, _ := setupUserTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/usage", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.GetUsage(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
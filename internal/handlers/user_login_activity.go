@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Login activity page sizes
+const (
+	defaultLoginActivityLimit = 50
+	maxLoginActivityLimit     = 200
+)
+
+// WithLoginEvents sets the login audit trail users' recent activity is read from
+func (h *UserHandler) WithLoginEvents(repo repository.LoginEventRepository) *UserHandler {
+	h.loginEventRepo = repo
+	return h
+}
+
+// GetActivity lists the authenticated user's recent login attempts, newest first
+// GET /api/v1/users/me/activity?limit=&cursor=
+func (h *UserHandler) GetActivity(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if h.loginEventRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("login_activity_unavailable", "The login audit trail is not enabled"))
+		return
+	}
+
+	page, err := pagination.Parse(c.Request.URL.Query(), defaultLoginActivityLimit, maxLoginActivityLimit, pagination.IntegerID)
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	events, err := h.loginEventRepo.List(c.Request.Context(), repository.LoginEventFilter{
+		UserID: &userID,
+		Limit:  page.Fetch(),
+		After:  page.After,
+	})
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve login activity"))
+		return
+	}
+	events, nextCursor := pagination.Next(page, events, loginEventCursor)
+
+	meta := api.Meta{
+		"count": len(events),
+		"limit": page.Limit,
+	}
+	if nextCursor != "" {
+		meta["nextCursor"] = nextCursor
+	}
+	api.List(c, http.StatusOK, "events", events, meta)
+}
+
+// loginEventCursor returns the listing position of a login event
+func loginEventCursor(event *models.LoginEvent) pagination.Cursor {
+	return pagination.Cursor{Time: event.CreatedAt, ID: strconv.FormatInt(event.ID, 10)}
+}
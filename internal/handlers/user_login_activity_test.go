@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserHandler_GetActivity(t *testing.T) {
+	handler, _ := setupUserTest()
+	eventRepo := repository.NewMockLoginEventRepository()
+	handler = handler.WithLoginEvents(eventRepo)
+
+	userID := uuid.New()
+	now := time.Now().UTC()
+	var filters []repository.LoginEventFilter
+	eventRepo.ListFunc = func(_ context.Context, filter repository.LoginEventFilter) ([]*models.LoginEvent, error) {
+		filters = append(filters, filter)
+		if filter.After != nil {
+			return []*models.LoginEvent{{ID: 1, UserID: &userID, Method: models.LoginMethodPassword, Success: true, CreatedAt: now.Add(-2 * time.Hour)}}, nil
+		}
+		return []*models.LoginEvent{
+			{ID: 3, UserID: &userID, Method: models.LoginMethodMagicLink, Success: true, IPAddress: "203.0.113.7", CreatedAt: now},
+			{ID: 2, UserID: &userID, Method: models.LoginMethodPassword, FailureReason: models.LoginFailureInvalidCredentials, CreatedAt: now.Add(-time.Hour)},
+			{ID: 1, UserID: &userID, Method: models.LoginMethodPassword, Success: true, CreatedAt: now.Add(-2 * time.Hour)},
+		}, nil
+	}
+
+	list := func(query string) map[string]any {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/activity?"+query, nil)
+		c.Set(string(middleware.UserIDKey), userID)
+
+		handler.GetActivity(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	response := list("limit=2")
+	require.NotNil(t, filters[0].UserID)
+	assert.Equal(t, userID, *filters[0].UserID, "only the caller's events are listed")
+	assert.Equal(t, 3, filters[0].Limit, "one extra row is fetched to detect the next page")
+	assert.Len(t, response["events"], 2)
+	assert.Equal(t, "invalid_credentials", response["events"].([]any)[1].(map[string]any)["failureReason"])
+
+	nextCursor, ok := response["nextCursor"].(string)
+	require.True(t, ok)
+
+	response = list("limit=2&cursor=" + nextCursor)
+	require.NotNil(t, filters[1].After)
+	assert.Equal(t, strconv.Itoa(2), filters[1].After.ID)
+	assert.Len(t, response["events"], 1)
+	assert.NotContains(t, response, "nextCursor", "the last page has no next cursor")
+}
+
+func TestUserHandler_GetActivity_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"limit=0", "limit=500", "cursor=garbage"} {
+		t.Run(query, func(t *testing.T) {
+			handler, _ := setupUserTest()
+			handler = handler.WithLoginEvents(repository.NewMockLoginEventRepository())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/activity?"+query, nil)
+			c.Set(string(middleware.UserIDKey), uuid.New())
+
+			handler.GetActivity(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestUserHandler_GetActivity_NotConfigured(t *testing.T) {
+	handler, _ := setupUserTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/activity", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.GetActivity(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/api"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// RegisterPushTokenRequest represents the request body registering a mobile app installation for push notifications
+type RegisterPushTokenRequest struct {
+	Token      string              `json:"token" binding:"required"`
+	Provider   models.PushProvider `json:"provider" binding:"required"`
+	DeviceName *string             `json:"deviceName,omitempty"`
+}
+
+// RegisterPushToken registers a device token for the authenticated user's push notifications
+// Apps should register on every launch; registering a known token refreshes it and moves it to the
+// signed-in user.
+// POST /api/v1/users/me/push-tokens
+func (h *UserHandler) RegisterPushToken(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if !h.pushTokensEnabled(c) {
+		return
+	}
+
+	var req RegisterPushTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_request", "Invalid request body: "+err.Error()))
+		return
+	}
+
+	if !req.Provider.IsValid() {
+		problem.Abort(c, problem.BadRequest("invalid_push_provider", "provider must be fcm or apns"))
+		return
+	}
+
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" || len(req.Token) > models.MaxPushTokenLength {
+		problem.Abort(c, problem.BadRequest("invalid_push_token", "token must be between 1 and "+strconv.Itoa(models.MaxPushTokenLength)+" characters"))
+		return
+	}
+
+	token := &models.PushToken{
+		UserID:     userID,
+		Provider:   req.Provider,
+		Token:      req.Token,
+		DeviceName: req.DeviceName,
+	}
+	if err := h.pushTokenRepo.Register(c.Request.Context(), token); err != nil {
+		problem.Abort(c, problem.Internal("Failed to register push token"))
+		return
+	}
+
+	api.Respond(c, http.StatusCreated, token)
+}
+
+// ListPushTokens lists the mobile app installations registered for the authenticated user's push notifications
+// GET /api/v1/users/me/push-tokens
+func (h *UserHandler) ListPushTokens(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if !h.pushTokensEnabled(c) {
+		return
+	}
+
+	tokens, err := h.pushTokenRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.Internal("Failed to retrieve push tokens"))
+		return
+	}
+
+	api.List(c, http.StatusOK, "pushTokens", tokens, api.Meta{
+		"total": len(tokens),
+	})
+}
+
+// DeletePushToken unregisters one of the authenticated user's push tokens, e.g. when the app signs out
+// DELETE /api/v1/users/me/push-tokens/:id
+func (h *UserHandler) DeletePushToken(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	if !h.pushTokensEnabled(c) {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, problem.BadRequest("invalid_push_token_id", "Invalid push token ID format"))
+		return
+	}
+
+	if err := h.pushTokenRepo.Delete(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, repository.ErrPushTokenNotFound) {
+			problem.Abort(c, problem.NotFound("push_token_not_found", "Push token not found"))
+			return
+		}
+		problem.Abort(c, problem.Internal("Failed to delete push token"))
+		return
+	}
+
+	api.Respond(c, http.StatusOK, gin.H{
+		"message": "Push token deleted successfully",
+	})
+}
+
+// pushTokensEnabled responds with an error when push token registration is not configured
+func (h *UserHandler) pushTokensEnabled(c *gin.Context) bool {
+	if h.pushTokenRepo == nil {
+		problem.Abort(c, problem.ServiceUnavailable("push_unavailable", "Push notifications are not configured"))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserHandler_RegisterPushToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "fcm token",
+			body:           `{"token":"fcm-token","provider":"fcm","deviceName":"Pixel 8"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "apns token",
+			body:           `{"token":"apns-token","provider":"apns"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "unknown provider",
+			body:           `{"token":"token","provider":"webpush"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "blank token",
+			body:           `{"token":"   ","provider":"fcm"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "token too long",
+			body:           `{"token":"` + strings.Repeat("a", models.MaxPushTokenLength+1) + `","provider":"fcm"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing provider",
+			body:           `{"token":"token"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupUserTest()
+			pushTokenRepo := repository.NewMockPushTokenRepository()
+			handler.WithPushTokens(pushTokenRepo)
+			userID := uuid.New()
+
+			var registered *models.PushToken
+			pushTokenRepo.RegisterFunc = func(_ context.Context, token *models.PushToken) error {
+				token.ID = uuid.New()
+				registered = token
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/users/me/push-tokens", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.RegisterPushToken(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, registered)
+				return
+			}
+
+			require.NotNil(t, registered)
+			assert.Equal(t, userID, registered.UserID)
+
+			var response models.PushToken
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, registered.ID, response.ID)
+			assert.Equal(t, registered.Token, response.Token)
+		})
+	}
+}
+
+func TestUserHandler_DeletePushToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		found          bool
+		expectedStatus int
+	}{
+		{name: "deleted", id: uuid.NewString(), found: true, expectedStatus: http.StatusOK},
+		{name: "not found", id: uuid.NewString(), expectedStatus: http.StatusNotFound},
+		{name: "invalid id", id: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupUserTest()
+			pushTokenRepo := repository.NewMockPushTokenRepository()
+			handler.WithPushTokens(pushTokenRepo)
+			userID := uuid.New()
+
+			pushTokenRepo.DeleteFunc = func(_ context.Context, owner, id uuid.UUID) error {
+				assert.Equal(t, userID, owner)
+				assert.Equal(t, tt.id, id.String())
+				if !tt.found {
+					return repository.ErrPushTokenNotFound
+				}
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/push-tokens/"+tt.id, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			c.Set(string(middleware.UserIDKey), userID)
+
+			handler.DeletePushToken(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUserHandler_ListPushTokens_NotConfigured(t *testing.T) {
+	handler, _ := setupUserTest()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/push-tokens", nil)
+	c.Set(string(middleware.UserIDKey), uuid.New())
+
+	handler.ListPushTokens(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// queueSize bounds the number of imports waiting to be written
+	queueSize = 16
+
+	// chunkSize is the number of records written per SaveBatch call
+	chunkSize = 1000
+
+	// interruptedMessage is recorded on jobs left unfinished by a restart
+	interruptedMessage = "Import was interrupted by a service restart; upload the file again"
+)
+
+// ErrQueueFull is returned when too many imports are already waiting
+var ErrQueueFull = errors.New("import queue is full")
+
+// task is an accepted import awaiting its telemetry write
+type task struct {
+	job     *models.ImportJob
+	records []*models.TelemetryData
+}
+
+// Importer writes imported recordings to the telemetry hypertable in the background
+// Records are written in chunks and the job's progress is updated after each one, so
+// clients can poll the job while large files are stored. Once every row is written the
+// session summary is computed. Parsed records are held in memory until they are written,
+// so jobs that were queued or running when the service stopped are failed by FailInterrupted.
+type Importer struct {
+	jobRepo       repository.ImportJobRepository
+	telemetryRepo repository.TelemetryRepository
+	sessionRepo   repository.SessionRepository
+	queue         chan task
+}
+
+// NewImporter creates a new background importer
+func NewImporter(jobRepo repository.ImportJobRepository, telemetryRepo repository.TelemetryRepository, sessionRepo repository.SessionRepository) *Importer {
+	return &Importer{
+		jobRepo:       jobRepo,
+		telemetryRepo: telemetryRepo,
+		sessionRepo:   sessionRepo,
+		queue:         make(chan task, queueSize),
+	}
+}
+
+// Submit schedules a job's records for writing without blocking
+func (i *Importer) Submit(job *models.ImportJob, records []*models.TelemetryData) error {
+	select {
+	case i.queue <- task{job: job, records: records}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// FailInterrupted marks jobs left queued or running by a previous process as failed
+// It must run before new imports are accepted, since their jobs start out pending too.
+func (i *Importer) FailInterrupted(ctx context.Context) error {
+	failed, err := i.jobRepo.FailIncomplete(ctx, interruptedMessage)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		slog.Info("Marked interrupted import jobs as failed", "count", failed)
+	}
+	return nil
+}
+
+// Run processes queued imports until the context is cancelled
+func (i *Importer) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-i.queue:
+			if err := i.Process(ctx, t.job, t.records); err != nil {
+				slog.Error("Error importing job", "job_id", t.job.ID, "error", err)
+			}
+		}
+	}
+}
+
+// Process writes a job's records and records the outcome on the job
+func (i *Importer) Process(ctx context.Context, job *models.ImportJob, records []*models.TelemetryData) error {
+	imported := 0
+	if err := i.jobRepo.UpdateProgress(ctx, job.ID, models.ImportProcessing, imported, nil); err != nil {
+		return fmt.Errorf("failed to start job: %w", err)
+	}
+
+	for start := 0; start < len(records); start += chunkSize {
+		chunk := records[start:min(start+chunkSize, len(records))]
+		if err := i.telemetryRepo.SaveBatch(ctx, chunk); err != nil {
+			i.fail(ctx, job, imported, "Failed to store telemetry")
+			return fmt.Errorf("failed to save telemetry: %w", err)
+		}
+		imported += len(chunk)
+
+		if imported < len(records) {
+			if err := i.jobRepo.UpdateProgress(ctx, job.ID, models.ImportProcessing, imported, nil); err != nil {
+				slog.Error("Error updating import job progress", "job_id", job.ID, "error", err)
+			}
+		}
+	}
+
+	if _, err := i.sessionRepo.UpdateSummary(ctx, job.SessionID); err != nil {
+		// The periodic sweep computes it later
+		slog.Error("Error summarizing imported session", "session_id", job.SessionID, "error", err)
+	}
+
+	if err := i.jobRepo.UpdateProgress(ctx, job.ID, models.ImportCompleted, imported, nil); err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+
+	return nil
+}
+
+// fail marks the job failed with a client-facing message
+// The update ignores cancellation so failures during shutdown are still recorded.
+func (i *Importer) fail(ctx context.Context, job *models.ImportJob, imported int, message string) {
+	if err := i.jobRepo.UpdateProgress(context.WithoutCancel(ctx), job.ID, models.ImportFailed, imported, &message); err != nil {
+		slog.Error("Error failing import job", "job_id", job.ID, "error", err)
+	}
+}
@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// progress is a recorded UpdateProgress call
+type progress struct {
+	status   models.ImportStatus
+	imported int
+	errMsg   *string
+}
+
+func setupImporter() (*Importer, *repository.MockImportJobRepository, *repository.MockRepository, *repository.MockSessionRepository, *[]progress) {
+	jobRepo := repository.NewMockImportJobRepository()
+	telemetryRepo := repository.NewMockRepository()
+	sessionRepo := repository.NewMockSessionRepository()
+
+	updates := &[]progress{}
+	jobRepo.UpdateProgressFunc = func(_ context.Context, _ uuid.UUID, status models.ImportStatus, imported int, errMsg *string) error {
+		*updates = append(*updates, progress{status: status, imported: imported, errMsg: errMsg})
+		return nil
+	}
+
+	return NewImporter(jobRepo, telemetryRepo, sessionRepo), jobRepo, telemetryRepo, sessionRepo, updates
+}
+
+func makeRecords(n int) []*models.TelemetryData {
+	records := make([]*models.TelemetryData, n)
+	start := time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC)
+	for i := range records {
+		records[i] = &models.TelemetryData{Timestamp: start.Add(time.Duration(i) * 40 * time.Millisecond)}
+	}
+	return records
+}
+
+func TestImporter_Process(t *testing.T) {
+	importer, _, telemetryRepo, sessionRepo, updates := setupImporter()
+	job := &models.ImportJob{ID: uuid.New(), SessionID: uuid.New(), TotalRows: 2500}
+
+	var batches []int
+	telemetryRepo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+		batches = append(batches, len(data))
+		return nil
+	}
+	summarized := false
+	sessionRepo.UpdateSummaryFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		assert.Equal(t, job.SessionID, id)
+		summarized = true
+		return &models.Session{ID: id}, nil
+	}
+
+	require.NoError(t, importer.Process(context.Background(), job, makeRecords(2500)))
+
+	assert.Equal(t, []int{1000, 1000, 500}, batches)
+	assert.True(t, summarized)
+	assert.Equal(t, []progress{
+		{status: models.ImportProcessing, imported: 0},
+		{status: models.ImportProcessing, imported: 1000},
+		{status: models.ImportProcessing, imported: 2000},
+		{status: models.ImportCompleted, imported: 2500},
+	}, *updates)
+}
+
+func TestImporter_Process_SaveError(t *testing.T) {
+	importer, _, telemetryRepo, _, updates := setupImporter()
+	job := &models.ImportJob{ID: uuid.New(), SessionID: uuid.New(), TotalRows: 1500}
+
+	calls := 0
+	telemetryRepo.SaveBatchFunc = func(_ context.Context, _ []*models.TelemetryData) error {
+		calls++
+		if calls == 2 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	}
+
+	err := importer.Process(context.Background(), job, makeRecords(1500))
+	require.Error(t, err)
+
+	last := (*updates)[len(*updates)-1]
+	assert.Equal(t, models.ImportFailed, last.status)
+	assert.Equal(t, 1000, last.imported)
+	require.NotNil(t, last.errMsg)
+	assert.Equal(t, "Failed to store telemetry", *last.errMsg)
+}
+
+func TestImporter_Submit_QueueFull(t *testing.T) {
+	importer, _, _, _, _ := setupImporter()
+
+	for range queueSize {
+		require.NoError(t, importer.Submit(&models.ImportJob{ID: uuid.New()}, nil))
+	}
+	assert.ErrorIs(t, importer.Submit(&models.ImportJob{ID: uuid.New()}, nil), ErrQueueFull)
+}
+
+func TestImporter_RunProcessesQueue(t *testing.T) {
+	importer, jobRepo, _, _, _ := setupImporter()
+	completed := make(chan uuid.UUID, 1)
+	jobRepo.UpdateProgressFunc = func(_ context.Context, id uuid.UUID, status models.ImportStatus, _ int, _ *string) error {
+		if status == models.ImportCompleted {
+			completed <- id
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go importer.Run(ctx)
+
+	job := &models.ImportJob{ID: uuid.New(), SessionID: uuid.New()}
+	require.NoError(t, importer.Submit(job, makeRecords(10)))
+
+	select {
+	case id := <-completed:
+		assert.Equal(t, job.ID, id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("import was not processed")
+	}
+}
+
+func TestImporter_FailInterrupted(t *testing.T) {
+	importer, jobRepo, _, _, _ := setupImporter()
+	jobRepo.FailIncompleteFunc = func(_ context.Context, errMsg string) (int64, error) {
+		assert.Equal(t, interruptedMessage, errMsg)
+		return 2, nil
+	}
+	require.NoError(t, importer.FailInterrupted(context.Background()))
+
+	jobRepo.FailIncompleteFunc = func(_ context.Context, _ string) (int64, error) {
+		return 0, errors.New("database unavailable")
+	}
+	assert.Error(t, importer.FailInterrupted(context.Background()))
+}
@@ -0,0 +1,285 @@
+// Package importer converts historical recordings into telemetry and stores them in the background.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+const (
+	// maxPreambleLines caps how many metadata lines may precede the header row
+	maxPreambleLines = 50
+)
+
+// ErrTooManyRows is returned when a file holds more data rows than the parser was allowed to read
+var ErrTooManyRows = errors.New("too many rows")
+
+// Column names recognized in RaceBox CSV exports, keyed by normalized header
+// Headers are normalized by lowercasing and dropping units in parentheses and any
+// spaces, dashes or underscores, so "Speed (km/h)", "GForce X" and "g_force_x" all match.
+// The service's own CSV export uses the same names and can be re-imported.
+const (
+	colTime       = "time"
+	colLatitude   = "latitude"
+	colLongitude  = "longitude"
+	colWgsAlt     = "wgsaltitude"
+	colMslAlt     = "mslaltitude"
+	colSpeed      = "speed"
+	colHeading    = "heading"
+	colSatellites = "satellites"
+	colFix        = "fixstatus"
+	colFixValid   = "isfixvalid"
+	colHAccuracy  = "horizontalaccuracy"
+	colVAccuracy  = "verticalaccuracy"
+	colGForceX    = "gforcex"
+	colGForceY    = "gforcey"
+	colGForceZ    = "gforcez"
+	colRotationX  = "rotationx"
+	colRotationY  = "rotationy"
+	colRotationZ  = "rotationz"
+	colBattery    = "battery"
+)
+
+// columnAliases maps alternative normalized headers to their canonical column
+var columnAliases = map[string]string{
+	"timestamp":     colTime,
+	"utctime":       colTime,
+	"datetime":      colTime,
+	"lat":           colLatitude,
+	"lon":           colLongitude,
+	"lng":           colLongitude,
+	"altitude":      colMslAlt,
+	"alt":           colMslAlt,
+	"course":        colHeading,
+	"sats":          colSatellites,
+	"numsatellites": colSatellites,
+	"gpsfix":        colFix,
+	"fix":           colFix,
+	"hacc":          colHAccuracy,
+	"vacc":          colVAccuracy,
+	"gx":            colGForceX,
+	"gy":            colGForceY,
+	"gz":            colGForceZ,
+	"gyrox":         colRotationX,
+	"gyroy":         colRotationY,
+	"gyroz":         colRotationZ,
+}
+
+// ParseRaceBoxCSV reads a RaceBox app CSV export into telemetry records in file order
+// Metadata lines before the header row are skipped. Only time, latitude and longitude are
+// required; missing columns are left at zero. Timestamps may be RFC 3339, "2006-01-02 15:04:05.000"
+// in UTC, or Unix seconds. Each record is validated and the first invalid row aborts the parse.
+// At most maxRows data rows are read (0 means unlimited); larger files return ErrTooManyRows.
+func ParseRaceBoxCSV(r io.Reader, maxRows int) ([]*models.TelemetryData, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*models.TelemetryData, 0)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if isBlank(row) {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		if maxRows > 0 && len(records) >= maxRows {
+			return nil, fmt.Errorf("%w: files may contain at most %d data rows", ErrTooManyRows, maxRows)
+		}
+
+		record, err := parseRow(columns, row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := record.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// readHeader skips metadata lines and returns the column index of each recognized header
+func readHeader(reader *csv.Reader) (map[string]int, error) {
+	for i := 0; i < maxPreambleLines; i++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		columns := make(map[string]int)
+		for idx, name := range row {
+			key := normalizeHeader(name)
+			if alias, ok := columnAliases[key]; ok {
+				key = alias
+			}
+			if _, seen := columns[key]; !seen {
+				columns[key] = idx
+			}
+		}
+
+		_, hasTime := columns[colTime]
+		_, hasLat := columns[colLatitude]
+		_, hasLon := columns[colLongitude]
+		if hasTime && hasLat && hasLon {
+			return columns, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no header row with time, latitude and longitude columns found")
+}
+
+// parseRow converts a data row into a telemetry record
+func parseRow(columns map[string]int, row []string) (*models.TelemetryData, error) {
+	field := func(name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+
+	var err error
+	number := func(name string) float64 {
+		raw := field(name)
+		if raw == "" || err != nil {
+			return 0
+		}
+		value, parseErr := strconv.ParseFloat(raw, 64)
+		if parseErr != nil {
+			err = fmt.Errorf("invalid %s %q", name, raw)
+		}
+		return value
+	}
+
+	timestamp, timeErr := parseTimestamp(field(colTime))
+	if timeErr != nil {
+		return nil, timeErr
+	}
+
+	record := &models.TelemetryData{
+		Timestamp: timestamp,
+		ITOW:      models.GPSTimeOfWeek(timestamp), // Imported files rarely include ITOW, but deduplication keys on it
+		GPS: models.GpsData{
+			Latitude:           number(colLatitude),
+			Longitude:          number(colLongitude),
+			WgsAltitude:        number(colWgsAlt),
+			MslAltitude:        number(colMslAlt),
+			Speed:              number(colSpeed),
+			Heading:            number(colHeading),
+			NumSatellites:      int(number(colSatellites)),
+			FixStatus:          int(number(colFix)),
+			HorizontalAccuracy: number(colHAccuracy),
+			VerticalAccuracy:   number(colVAccuracy),
+		},
+		Motion: models.MotionData{
+			GForceX:   number(colGForceX),
+			GForceY:   number(colGForceY),
+			GForceZ:   number(colGForceZ),
+			RotationX: number(colRotationX),
+			RotationY: number(colRotationY),
+			RotationZ: number(colRotationZ),
+		},
+		Battery: number(colBattery),
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Files without an explicit validity column are trusted when they report a fix, or
+	// when they carry no fix information at all but do have coordinates
+	switch raw := field(colFixValid); {
+	case raw != "":
+		valid, parseErr := strconv.ParseBool(raw)
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid %s %q", colFixValid, raw)
+		}
+		record.GPS.IsFixValid = valid
+	case field(colFix) != "":
+		record.GPS.IsFixValid = record.GPS.FixStatus >= 2
+	default:
+		record.GPS.IsFixValid = record.GPS.Latitude != 0 || record.GPS.Longitude != 0
+	}
+
+	// Altitude exports usually carry only one of the two references
+	if _, ok := columns[colWgsAlt]; !ok {
+		record.GPS.WgsAltitude = record.GPS.MslAltitude
+	}
+	if _, ok := columns[colMslAlt]; !ok {
+		record.GPS.MslAltitude = record.GPS.WgsAltitude
+	}
+
+	return record, nil
+}
+
+// parseTimestamp accepts RFC 3339, a space-separated UTC date-time or Unix seconds
+func parseTimestamp(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, fmt.Errorf("time is required")
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02 15:04:05.999999999", raw); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil && seconds > 0 {
+		whole, frac := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(math.Round(frac*1e9))).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q", raw)
+}
+
+// normalizeHeader lowercases a header and strips units and separators
+func normalizeHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	if idx := strings.IndexByte(name, '('); idx >= 0 {
+		name = name[:idx]
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		switch r {
+		case ' ', '-', '_', '.':
+			continue
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isBlank reports whether every field of a row is empty
+func isBlank(row []string) bool {
+	for _, field := range row {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const raceBoxCSV = `Format,RaceBox CSV
+Session,Serres Track Day
+Device,RaceBox Mini S
+
+Record,Time,Latitude,Longitude,Altitude (m),Speed (km/h),Heading,Satellites,GPS Fix,GForceX,GForceY,GForceZ,GyroX,GyroY,GyroZ
+1,2024-05-14T10:21:33.400Z,41.0717000,23.5112000,112.4,96.5,181.2,14,3,0.12,-0.45,0.98,1.5,-0.4,12.0
+2,2024-05-14T10:21:33.440Z,41.0716900,23.5112100,112.5,97.1,181.0,14,3,0.15,-0.47,0.99,1.6,-0.3,12.2
+
+3,2024-05-14T10:21:33.480Z,41.0716800,23.5112200,112.5,97.4,180.8,13,0,0.10,-0.42,1.01,1.4,-0.2,11.9
+`
+
+func TestParseRaceBoxCSV(t *testing.T) {
+	records, err := ParseRaceBoxCSV(strings.NewReader(raceBoxCSV), 0)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	first := records[0]
+	assert.Equal(t, time.Date(2024, 5, 14, 10, 21, 33, 400000000, time.UTC), first.Timestamp)
+	assert.Equal(t, 41.0717, first.GPS.Latitude)
+	assert.Equal(t, 23.5112, first.GPS.Longitude)
+	assert.Equal(t, 112.4, first.GPS.MslAltitude)
+	assert.Equal(t, 112.4, first.GPS.WgsAltitude)
+	assert.Equal(t, 96.5, first.GPS.Speed)
+	assert.Equal(t, 181.2, first.GPS.Heading)
+	assert.Equal(t, 14, first.GPS.NumSatellites)
+	assert.Equal(t, 3, first.GPS.FixStatus)
+	assert.True(t, first.GPS.IsFixValid)
+	assert.Equal(t, -0.45, first.Motion.GForceY)
+	assert.Equal(t, 12.0, first.Motion.RotationZ)
+	assert.Equal(t, int64(40), records[1].ITOW-first.ITOW)
+
+	// No fix on the last row
+	assert.False(t, records[2].GPS.IsFixValid)
+}
+
+func TestParseRaceBoxCSV_ServiceExport(t *testing.T) {
+	csv := "timestamp,latitude,longitude,wgs_altitude,msl_altitude,speed,heading,num_satellites,fix_status,is_fix_valid,g_force_x,battery\n" +
+		"2024-05-14T10:21:33Z,41.0717,23.5112,150.2,112.4,96.5,181.2,14,3,false,0.12,88.5\n"
+
+	records, err := ParseRaceBoxCSV(strings.NewReader(csv), 0)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, 150.2, records[0].GPS.WgsAltitude)
+	assert.Equal(t, 112.4, records[0].GPS.MslAltitude)
+	assert.False(t, records[0].GPS.IsFixValid)
+	assert.Equal(t, 88.5, records[0].Battery)
+}
+
+func TestParseRaceBoxCSV_TimestampFormats(t *testing.T) {
+	csv := "Time,Lat,Lon\n" +
+		"2024-05-14 10:21:33.250,41.0717,23.5112\n" +
+		"1715682093.5,41.0717,23.5112\n"
+
+	records, err := ParseRaceBoxCSV(strings.NewReader(csv), 0)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, time.Date(2024, 5, 14, 10, 21, 33, 250000000, time.UTC), records[0].Timestamp)
+	assert.Equal(t, time.Date(2024, 5, 14, 10, 21, 33, 500000000, time.UTC), records[1].Timestamp)
+	assert.True(t, records[0].GPS.IsFixValid)
+}
+
+func TestParseRaceBoxCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		message string
+	}{
+		{name: "no header", csv: "Format,RaceBox CSV\n1,2,3\n", message: "no header row"},
+		{name: "bad number", csv: "Time,Latitude,Longitude,Speed\n2024-05-14T10:21:33Z,41.07,23.51,fast\n", message: "line 2: invalid speed"},
+		{name: "bad time", csv: "Time,Latitude,Longitude\nyesterday,41.07,23.51\n", message: "line 2: invalid time"},
+		{name: "out of range", csv: "Time,Latitude,Longitude\n2024-05-14T10:21:33Z,91.07,23.51\n", message: "line 2: invalid latitude"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRaceBoxCSV(strings.NewReader(tt.csv), 0)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestParseRaceBoxCSV_MaxRows(t *testing.T) {
+	_, err := ParseRaceBoxCSV(strings.NewReader(raceBoxCSV), 2)
+	assert.True(t, errors.Is(err, ErrTooManyRows))
+
+	records, err := ParseRaceBoxCSV(strings.NewReader(raceBoxCSV), 3)
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+}
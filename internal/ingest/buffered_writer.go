@@ -0,0 +1,259 @@
+// Package ingest provides the stages telemetry passes through on its way to storage: quality checks
+// and a write-behind buffer that batches writes.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// maxFlushAttempts is how many times a batch is written before it is dropped
+	maxFlushAttempts = 3
+
+	// flushRetryDelay is the base backoff between flush attempts
+	flushRetryDelay = 100 * time.Millisecond
+
+	// flushTimeout caps a single SaveBatch call
+	flushTimeout = 30 * time.Second
+)
+
+var (
+	// ErrBufferFull is returned when accepting the records would exceed the buffer size
+	ErrBufferFull = errors.New("ingest buffer is full")
+	// ErrWriterClosed is returned when records are enqueued after shutdown has started
+	ErrWriterClosed = errors.New("ingest writer is closed")
+)
+
+// EventNotifier wakes the delivery of domain events a write stored in the outbox
+type EventNotifier interface {
+	Notify()
+}
+
+// BufferedWriter queues telemetry in memory and writes it through SaveBatch in the background
+// A batch is flushed once FlushSize records are waiting or FlushInterval has passed, whichever
+// comes first. Uploads are rejected rather than blocked when BufferSize records are already
+// waiting, so callers can shed load (e.g. respond 503). Remaining records are flushed on shutdown.
+type BufferedWriter struct {
+	repo           repository.TelemetryRepository
+	bufferSize     int64
+	flushSize      int
+	flushInterval  time.Duration
+	events         EventNotifier // Optional: stores telemetry.batch_saved events with each write
+	eventTelemetry bool          // Include the written records in telemetry.batch_saved events
+
+	queue   chan []*models.TelemetryData
+	pending atomic.Int64 // Records accepted but not yet written
+	flushed atomic.Int64 // Records taken off the buffer, whether written or dropped
+
+	mu     sync.RWMutex // Guards closed against concurrent sends to queue
+	closed bool
+}
+
+// NewBufferedWriter creates a new write-behind writer
+func NewBufferedWriter(repo repository.TelemetryRepository, cfg config.IngestConfig) *BufferedWriter {
+	return &BufferedWriter{
+		repo:          repo,
+		bufferSize:    int64(cfg.BufferSize),
+		flushSize:     cfg.FlushSize,
+		flushInterval: cfg.FlushInterval,
+		// Every queued slice holds at least one record, so the queue never blocks within bufferSize
+		queue: make(chan []*models.TelemetryData, cfg.BufferSize),
+	}
+}
+
+// WithEvents stores a telemetry.batch_saved event per device with each write, in its transaction,
+// and wakes notifier to deliver them once the write commits
+func (w *BufferedWriter) WithEvents(notifier EventNotifier) *BufferedWriter {
+	w.events = notifier
+	return w
+}
+
+// WithEventTelemetry includes the written records in telemetry.batch_saved events, for subscribers
+// that forward the telemetry itself
+func (w *BufferedWriter) WithEventTelemetry() *BufferedWriter {
+	w.eventTelemetry = true
+	return w
+}
+
+// Enqueue accepts records for a later batched write without blocking
+// Either all records are accepted or none are, so a rejected upload can be retried as a whole.
+func (w *BufferedWriter) Enqueue(records []*models.TelemetryData) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	n := int64(len(records))
+	if w.pending.Add(n) > w.bufferSize {
+		w.pending.Add(-n)
+		return ErrBufferFull
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		w.pending.Add(-n)
+		return ErrWriterClosed
+	}
+	w.queue <- records
+	return nil
+}
+
+// Pending returns the number of records accepted but not yet written
+func (w *BufferedWriter) Pending() int {
+	return int(w.pending.Load())
+}
+
+// Capacity returns the maximum number of records the buffer holds
+func (w *BufferedWriter) Capacity() int {
+	return int(w.bufferSize)
+}
+
+// Flushed returns the number of records taken off the buffer since it was created
+func (w *BufferedWriter) Flushed() int64 {
+	return w.flushed.Load()
+}
+
+// Run flushes queued records until the context is cancelled, then stops accepting new
+// records and flushes everything still buffered before returning
+func (w *BufferedWriter) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	// batch keeps the records of each Enqueue call apart, so a failed write only drops its own upload
+	var batch [][]*models.TelemetryData
+	size := 0
+	for {
+		select {
+		case <-ctx.Done():
+			w.drain(batch)
+			return
+		case records := <-w.queue:
+			batch = append(batch, records)
+			if size += len(records); size >= w.flushSize {
+				batch, size = w.flush(batch), 0
+			}
+		case <-ticker.C:
+			batch, size = w.flush(batch), 0
+		}
+	}
+}
+
+// drain closes the writer and flushes the in-progress batch plus anything left in the queue
+func (w *BufferedWriter) drain(batch [][]*models.TelemetryData) {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+
+	for {
+		select {
+		case records := <-w.queue:
+			batch = append(batch, records)
+		default:
+			remaining := 0
+			for _, records := range batch {
+				remaining += len(records)
+			}
+			if remaining > 0 {
+				w.flush(batch)
+				slog.Info("Ingest buffer: flushed records on shutdown", "count", remaining)
+			}
+			return
+		}
+	}
+}
+
+// flush writes the batch's uploads in chunks of at most flushSize records and returns the emptied batch
+// Uploads are kept whole within a chunk, except those larger than flushSize, which are split.
+func (w *BufferedWriter) flush(batch [][]*models.TelemetryData) [][]*models.TelemetryData {
+	var chunk [][]*models.TelemetryData
+	size := 0
+	for _, records := range batch {
+		for len(records) > 0 {
+			part := records[:min(len(records), w.flushSize)]
+			records = records[len(part):]
+			if size > 0 && size+len(part) > w.flushSize {
+				w.write(chunk, size)
+				chunk, size = chunk[:0], 0
+			}
+			chunk = append(chunk, part)
+			size += len(part)
+		}
+	}
+	if size > 0 {
+		w.write(chunk, size)
+	}
+
+	clear(batch)
+	return batch[:0]
+}
+
+// write saves a chunk of uploads in one SaveBatch call
+// SaveBatch writes all records or none, so when the chunk cannot be written each upload is written
+// on its own: a record the database rejects only drops the upload it came with.
+// Writes run on their own context so the final flush still completes during shutdown.
+func (w *BufferedWriter) write(chunk [][]*models.TelemetryData, size int) {
+	records := make([]*models.TelemetryData, 0, size)
+	for _, upload := range chunk {
+		records = append(records, upload...)
+	}
+	defer func() {
+		w.pending.Add(-int64(size))
+		w.flushed.Add(int64(size))
+	}()
+
+	err := w.save(records, maxFlushAttempts)
+	if err == nil {
+		return
+	}
+	if len(chunk) == 1 {
+		slog.Error("Ingest buffer: dropping records", "count", size, "attempts", maxFlushAttempts, "error", err)
+		return
+	}
+
+	slog.Warn("Ingest buffer: batch write failed, writing uploads one at a time", "uploads", len(chunk), "count", size, "error", err)
+	for _, upload := range chunk {
+		if err := w.save(upload, 1); err != nil {
+			slog.Error("Ingest buffer: dropping records", "count", len(upload), "attempts", maxFlushAttempts+1, "error", err)
+		}
+	}
+}
+
+// save writes records, making up to attempts attempts with a linear backoff between them
+// Each write stores the batch_saved events of the records it stored, so retries publish nothing twice.
+func (w *BufferedWriter) save(records []*models.TelemetryData, attempts int) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		err = w.repo.SaveBatch(w.withBatchSaved(ctx), records)
+		cancel()
+		if err == nil {
+			if w.events != nil {
+				w.events.Notify()
+			}
+			return nil
+		}
+		if attempt < attempts {
+			time.Sleep(time.Duration(attempt) * flushRetryDelay)
+		}
+	}
+	return err
+}
+
+// withBatchSaved returns a context under which a write stores its telemetry.batch_saved events
+func (w *BufferedWriter) withBatchSaved(ctx context.Context) context.Context {
+	if w.events == nil {
+		return ctx
+	}
+	return repository.WithEvents(ctx, func(stored []*models.TelemetryData) ([]*models.Event, error) {
+		return models.NewTelemetryBatchSavedEvents(stored, w.eventTelemetry)
+	})
+}
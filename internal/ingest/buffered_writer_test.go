@@ -0,0 +1,229 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingRepo captures SaveBatch calls made by the flusher
+type recordingRepo struct {
+	*repository.MockRepository
+	mu      sync.Mutex
+	batches [][]*models.TelemetryData
+	saved   chan int
+}
+
+func newRecordingRepo() *recordingRepo {
+	r := &recordingRepo{MockRepository: repository.NewMockRepository(), saved: make(chan int, 100)}
+	r.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+		r.mu.Lock()
+		r.batches = append(r.batches, append([]*models.TelemetryData(nil), data...))
+		r.mu.Unlock()
+		r.saved <- len(data)
+		return nil
+	}
+	return r
+}
+
+func records(n int) []*models.TelemetryData {
+	out := make([]*models.TelemetryData, n)
+	for i := range out {
+		out[i] = &models.TelemetryData{DeviceID: "device-1", Timestamp: time.Now()}
+	}
+	return out
+}
+
+func waitSaved(t *testing.T, repo *recordingRepo) int {
+	t.Helper()
+	select {
+	case n := <-repo.saved:
+		return n
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for flush")
+		return 0
+	}
+}
+
+func TestBufferedWriter_FlushesWhenBatchIsFull(t *testing.T) {
+	repo := newRecordingRepo()
+	writer := NewBufferedWriter(repo, config.IngestConfig{BufferSize: 100, FlushSize: 10, FlushInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go writer.Run(ctx)
+
+	require.NoError(t, writer.Enqueue(records(6)))
+	require.NoError(t, writer.Enqueue(records(6)))
+
+	assert.Equal(t, 6, waitSaved(t, repo), "writes are capped at the flush size and keep uploads whole")
+	assert.Equal(t, 6, waitSaved(t, repo))
+	assert.Eventually(t, func() bool { return writer.Pending() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestBufferedWriter_FlushesOnInterval(t *testing.T) {
+	repo := newRecordingRepo()
+	writer := NewBufferedWriter(repo, config.IngestConfig{BufferSize: 100, FlushSize: 50, FlushInterval: 20 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go writer.Run(ctx)
+
+	require.NoError(t, writer.Enqueue(records(3)))
+	assert.Equal(t, 3, waitSaved(t, repo))
+}
+
+func TestBufferedWriter_RejectsWhenFull(t *testing.T) {
+	writer := NewBufferedWriter(newRecordingRepo(), config.IngestConfig{BufferSize: 10, FlushSize: 5, FlushInterval: time.Hour})
+
+	require.NoError(t, writer.Enqueue(records(8)))
+	assert.ErrorIs(t, writer.Enqueue(records(3)), ErrBufferFull, "uploads are accepted whole or not at all")
+	require.NoError(t, writer.Enqueue(records(2)))
+	assert.Equal(t, 10, writer.Pending())
+}
+
+func TestBufferedWriter_FlushesOnShutdown(t *testing.T) {
+	repo := newRecordingRepo()
+	writer := NewBufferedWriter(repo, config.IngestConfig{BufferSize: 100, FlushSize: 4, FlushInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		writer.Run(ctx)
+		close(done)
+	}()
+
+	require.NoError(t, writer.Enqueue(records(3)))
+	require.NoError(t, writer.Enqueue(records(7)))
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after shutdown")
+	}
+
+	repo.mu.Lock()
+	total := 0
+	for _, batch := range repo.batches {
+		assert.LessOrEqual(t, len(batch), 4, "shutdown flush respects the flush size")
+		total += len(batch)
+	}
+	repo.mu.Unlock()
+	assert.Equal(t, 10, total)
+	assert.Equal(t, 0, writer.Pending())
+	assert.ErrorIs(t, writer.Enqueue(records(1)), ErrWriterClosed)
+}
+
+func TestBufferedWriter_RetriesFailedFlush(t *testing.T) {
+	repo := newRecordingRepo()
+	failures := 1
+	save := repo.SaveBatchFunc
+	repo.SaveBatchFunc = func(ctx context.Context, data []*models.TelemetryData) error {
+		if failures > 0 {
+			failures--
+			return errors.New("connection reset")
+		}
+		return save(ctx, data)
+	}
+	writer := NewBufferedWriter(repo, config.IngestConfig{BufferSize: 100, FlushSize: 2, FlushInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go writer.Run(ctx)
+
+	require.NoError(t, writer.Enqueue(records(2)))
+	assert.Equal(t, 2, waitSaved(t, repo))
+}
+
+func TestBufferedWriter_RetriesUploadsAlone(t *testing.T) {
+	repo := newRecordingRepo()
+	bad := &models.TelemetryData{DeviceID: "device-2", Timestamp: time.Now()}
+	save := repo.SaveBatchFunc
+	repo.SaveBatchFunc = func(ctx context.Context, data []*models.TelemetryData) error {
+		for _, record := range data {
+			if record == bad {
+				return errors.New("value out of range")
+			}
+		}
+		return save(ctx, data)
+	}
+	writer := NewBufferedWriter(repo, config.IngestConfig{BufferSize: 100, FlushSize: 10, FlushInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go writer.Run(ctx)
+
+	require.NoError(t, writer.Enqueue(records(3)))
+	require.NoError(t, writer.Enqueue(append(records(2), bad)))
+	require.NoError(t, writer.Enqueue(records(4)))
+
+	// The chunk holding the rejected record fails, so its uploads are written one at a time
+	assert.Equal(t, 3, waitSaved(t, repo))
+	assert.Equal(t, 4, waitSaved(t, repo))
+	assert.Eventually(t, func() bool { return writer.Pending() == 0 }, time.Second, 5*time.Millisecond)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Len(t, repo.batches, 2, "only the upload with the rejected record is dropped")
+}
+
+// countingNotifier counts the wake-ups of event delivery
+type countingNotifier struct {
+	notified atomic.Int32
+}
+
+func (n *countingNotifier) Notify() {
+	n.notified.Add(1)
+}
+
+func TestBufferedWriter_StoresBatchSavedWithWrite(t *testing.T) {
+	repo := newRecordingRepo()
+	var events []*models.Event
+	failures := 1
+	save := repo.SaveBatchFunc
+	repo.SaveBatchFunc = func(ctx context.Context, data []*models.TelemetryData) error {
+		if failures > 0 {
+			failures--
+			return errors.New("connection reset")
+		}
+		// The first record is already stored
+		data[0].Duplicate = true
+		stored, err := repository.BuildEvents(ctx, data[1:])
+		require.NoError(t, err)
+		repo.mu.Lock()
+		events = append(events, stored...)
+		repo.mu.Unlock()
+		return save(ctx, data)
+	}
+	notifier := &countingNotifier{}
+	writer := NewBufferedWriter(repo, config.IngestConfig{BufferSize: 100, FlushSize: 3, FlushInterval: time.Hour}).
+		WithEvents(notifier).
+		WithEventTelemetry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go writer.Run(ctx)
+
+	require.NoError(t, writer.Enqueue(records(3)))
+	assert.Equal(t, 3, waitSaved(t, repo))
+	assert.Eventually(t, func() bool { return notifier.notified.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	require.Len(t, events, 1, "only the write that stored the records publishes them")
+	var batch models.TelemetryBatchSaved
+	require.NoError(t, events[0].Decode(&batch))
+	assert.Equal(t, "device-1", batch.DeviceID)
+	assert.Equal(t, 2, batch.Records, "duplicates are not counted")
+	assert.Len(t, batch.Telemetry, 2)
+}
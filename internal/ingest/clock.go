@@ -0,0 +1,86 @@
+package ingest
+
+import (
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// Clock skew policies
+const (
+	// ClockSkewOff only records receive times and skew estimates
+	ClockSkewOff = "off"
+	// ClockSkewFlag also sets models.QualityClockSkew on records skewed beyond the threshold
+	ClockSkewFlag = "flag"
+	// ClockSkewCorrect shifts the timestamps of records skewed beyond the threshold back by the estimated
+	// skew, keeping the reported one in DeviceTimestamp
+	ClockSkewCorrect = "correct"
+)
+
+// ClockPolicy compares device timestamps with GPS and server time and handles skewed device clocks
+// The skew of a record with a valid fix is its timestamp minus the UTC time of its iTOW. Records
+// without one can only be checked against the receive time: a device whose newest record in an
+// upload is ahead of the server is skewed by that much, while timestamps in the past are expected
+// from uploads buffered offline.
+type ClockPolicy struct {
+	mode      string
+	threshold time.Duration
+}
+
+// NewClockPolicy creates a clock policy with the given ClockSkew* mode
+// Records skewed by more than threshold are flagged or corrected; an empty mode behaves like ClockSkewOff.
+func NewClockPolicy(mode string, threshold time.Duration) *ClockPolicy {
+	return &ClockPolicy{mode: mode, threshold: threshold}
+}
+
+// Apply stamps records received at receivedAt with their receive time and clock skew
+// Records already stamped, such as ones checked by the upload handler before reaching the
+// QualityChecker, are left as they are.
+func (p *ClockPolicy) Apply(records []*models.TelemetryData, receivedAt time.Time) {
+	receivedAt = receivedAt.UTC()
+
+	// Newest timestamp per device, for records the GPS cannot vouch for
+	newest := make(map[string]time.Time)
+	for _, record := range records {
+		if record.ReceivedAt == nil && !hasGPSTime(record) && record.Timestamp.After(newest[record.DeviceID]) {
+			newest[record.DeviceID] = record.Timestamp
+		}
+	}
+
+	for _, record := range records {
+		if record.ReceivedAt != nil {
+			continue
+		}
+		at := receivedAt
+		record.ReceivedAt = &at
+
+		var skew time.Duration
+		if hasGPSTime(record) {
+			skew = record.Timestamp.Sub(models.GPSTimeNear(record.ITOW, record.Timestamp))
+		} else if ahead := newest[record.DeviceID].Sub(receivedAt); ahead > 0 {
+			skew = ahead
+		} else {
+			continue
+		}
+
+		skewMs := skew.Milliseconds()
+		record.ClockSkewMs = &skewMs
+		if skew.Abs() <= p.threshold {
+			continue
+		}
+
+		switch p.mode {
+		case ClockSkewFlag:
+			record.QualityFlags |= models.QualityClockSkew
+		case ClockSkewCorrect:
+			reported := record.Timestamp
+			record.DeviceTimestamp = &reported
+			record.Timestamp = reported.Add(-skew)
+		}
+	}
+}
+
+// hasGPSTime reports whether a record's iTOW can be trusted as a time reference
+func hasGPSTime(record *models.TelemetryData) bool {
+	return record.ITOW > 0 && record.GPS.IsFixValid
+}
@@ -0,0 +1,115 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gpsFix returns a record with a valid fix whose iTOW is GPS time at gpsTime, timestamped by a device
+// clock that is skew ahead
+func gpsFix(deviceID string, gpsTime time.Time, skew time.Duration) *models.TelemetryData {
+	record := fix(deviceID, gpsTime.Add(skew), 0, 42.0)
+	record.ITOW = models.GPSTimeOfWeek(gpsTime)
+	return record
+}
+
+func TestClockPolicy_GPSSkew(t *testing.T) {
+	received := time.Date(2026, 5, 6, 12, 0, 0, 0, time.UTC)
+	at := received.Add(-time.Hour) // Uploaded an hour late
+
+	records := []*models.TelemetryData{
+		gpsFix("device-1", at, 500*time.Millisecond),
+		gpsFix("device-1", at.Add(time.Second), -10*time.Second),
+	}
+	NewClockPolicy(ClockSkewFlag, 2*time.Second).Apply(records, received)
+
+	for _, record := range records {
+		require.NotNil(t, record.ReceivedAt)
+		assert.True(t, received.Equal(*record.ReceivedAt))
+		require.NotNil(t, record.ClockSkewMs)
+		assert.Nil(t, record.DeviceTimestamp)
+	}
+	assert.Equal(t, int64(500), *records[0].ClockSkewMs)
+	assert.True(t, records[0].IsClean(), "skew within the threshold")
+	assert.Equal(t, int64(-10000), *records[1].ClockSkewMs)
+	assert.Equal(t, models.QualityClockSkew, records[1].QualityFlags)
+}
+
+func TestClockPolicy_Correct(t *testing.T) {
+	received := time.Date(2026, 5, 6, 12, 0, 0, 0, time.UTC)
+	at := received.Add(-time.Minute)
+
+	record := gpsFix("device-1", at, 30*time.Second)
+	reported := record.Timestamp
+	NewClockPolicy(ClockSkewCorrect, 2*time.Second).Apply([]*models.TelemetryData{record}, received)
+
+	assert.True(t, at.Equal(record.Timestamp))
+	require.NotNil(t, record.DeviceTimestamp)
+	assert.True(t, reported.Equal(*record.DeviceTimestamp))
+	assert.Equal(t, int64(30000), *record.ClockSkewMs)
+	assert.True(t, record.IsClean(), "corrected records are not flagged")
+}
+
+func TestClockPolicy_ReceiveTimeSkew(t *testing.T) {
+	received := time.Date(2026, 5, 6, 12, 0, 0, 0, time.UTC)
+
+	// Without a fix only a clock running ahead of the server can be told apart from buffered uploads
+	ahead := []*models.TelemetryData{
+		fix("device-1", received, 5, 42.0),
+		fix("device-1", received, 10, 42.0),
+	}
+	behind := fix("device-2", received, -3600, 42.0)
+	for _, record := range append(ahead, behind) {
+		record.GPS.IsFixValid = false
+	}
+	NewClockPolicy(ClockSkewCorrect, 2*time.Second).Apply(append(ahead, behind), received)
+
+	for _, record := range ahead {
+		assert.Equal(t, int64(10000), *record.ClockSkewMs)
+		require.NotNil(t, record.DeviceTimestamp)
+	}
+	assert.True(t, received.Add(-5*time.Second).Equal(ahead[0].Timestamp))
+	assert.True(t, received.Equal(ahead[1].Timestamp))
+
+	assert.NotNil(t, behind.ReceivedAt)
+	assert.Nil(t, behind.ClockSkewMs)
+	assert.Nil(t, behind.DeviceTimestamp)
+}
+
+func TestClockPolicy_Off(t *testing.T) {
+	received := time.Date(2026, 5, 6, 12, 0, 0, 0, time.UTC)
+	record := gpsFix("device-1", received, time.Minute)
+	reported := record.Timestamp
+
+	NewClockPolicy(ClockSkewOff, 2*time.Second).Apply([]*models.TelemetryData{record}, received)
+
+	assert.Equal(t, int64(60000), *record.ClockSkewMs)
+	assert.True(t, reported.Equal(record.Timestamp))
+	assert.True(t, record.IsClean())
+}
+
+func TestQualityChecker_ClockPolicy(t *testing.T) {
+	checker := NewQualityChecker(repository.NewMockRepository()).
+		WithClockPolicy(NewClockPolicy(ClockSkewFlag, 2*time.Second))
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	skewed := gpsFix("device-1", now, time.Minute)
+	require.NoError(t, checker.Save(ctx, skewed))
+	require.NotNil(t, skewed.ReceivedAt)
+	assert.Equal(t, models.QualityClockSkew, skewed.QualityFlags)
+
+	// Records already checked on receipt keep their receive time
+	earlier := now.Add(-time.Minute)
+	checked := gpsFix("device-2", now, time.Minute)
+	checked.ReceivedAt = &earlier
+	require.NoError(t, checker.Save(ctx, checked))
+	assert.True(t, earlier.Equal(*checked.ReceivedAt))
+	assert.True(t, checked.IsClean())
+}
@@ -0,0 +1,81 @@
+package ingest
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// ChannelDeriver computes the derived channels of telemetry before it is written to another TelemetryRepository
+// Deltas are taken from the device's previous record, whether it is stored or earlier in the same
+// upload, so every instance derives the same values. Uploads of one device saved concurrently do
+// not see each other's records. When the previous records cannot be read, the records are saved
+// without derived channels and left to the backfill.
+type ChannelDeriver struct {
+	repository.TelemetryRepository
+}
+
+// NewChannelDeriver wraps repo with derived channels on every save
+func NewChannelDeriver(repo repository.TelemetryRepository) *ChannelDeriver {
+	return &ChannelDeriver{TelemetryRepository: repo}
+}
+
+// Save implements TelemetryRepository.Save
+func (d *ChannelDeriver) Save(ctx context.Context, data *models.TelemetryData) error {
+	d.Derive(ctx, []*models.TelemetryData{data})
+	return d.TelemetryRepository.Save(ctx, data)
+}
+
+// SaveBatch implements TelemetryRepository.SaveBatch
+func (d *ChannelDeriver) SaveBatch(ctx context.Context, data []*models.TelemetryData) error {
+	d.Derive(ctx, data)
+	return d.TelemetryRepository.SaveBatch(ctx, data)
+}
+
+// Derive sets the derived channels of records
+// Each record is derived from the latest earlier record of its device, stored or in records.
+func (d *ChannelDeriver) Derive(ctx context.Context, records []*models.TelemetryData) {
+	stored, err := d.TelemetryRepository.PreviousRecords(ctx, records)
+	if err != nil {
+		slog.Warn("Error reading previous telemetry, leaving derived channels to the backfill", "error", err)
+		return
+	}
+
+	order := make([]int, len(records))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := records[order[a]], records[order[b]]
+		if ra.DeviceID != rb.DeviceID {
+			return ra.DeviceID < rb.DeviceID
+		}
+		return ra.Timestamp.Before(rb.Timestamp)
+	})
+
+	// earlier is the latest record of the device in records that is older than the current one
+	var earlier, last *models.TelemetryData
+	for _, i := range order {
+		record := records[i]
+		if record.DeviceID == "" {
+			record.DeriveChannels(nil)
+			continue
+		}
+
+		if last == nil || last.DeviceID != record.DeviceID {
+			earlier = nil
+		} else if last.Timestamp.Before(record.Timestamp) {
+			earlier = last
+		}
+		last = record
+
+		prev := stored[i]
+		if earlier != nil && (prev == nil || earlier.Timestamp.After(prev.Timestamp)) {
+			prev = earlier
+		}
+		record.DeriveChannels(prev)
+	}
+}
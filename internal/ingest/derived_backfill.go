@@ -0,0 +1,107 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// DefaultDerivedBackfillInterval is how often a batch of records stored without derived channels is backfilled
+	DefaultDerivedBackfillInterval = time.Minute
+
+	// derivedBackfillBatchSize caps the records backfilled per sweep
+	derivedBackfillBatchSize = 5000
+
+	// derivedBackfillWindow is the span of recorded_at scanned by one backfill query
+	// It keeps each query on a few TimescaleDB chunks.
+	derivedBackfillWindow = 24 * time.Hour
+)
+
+// DerivedBackfiller periodically derives the channels of telemetry stored while they were disabled
+// It walks back in time from startup, one window at a time, and stops once it passes the oldest
+// record. Progress is kept in memory, so a restart walks the history again, skipping records that
+// already have derived channels.
+type DerivedBackfiller struct {
+	repo     repository.DerivedChannelRepository
+	interval time.Duration
+	now      func() time.Time
+
+	cursor time.Time  // Records before this are still to be checked
+	oldest *time.Time // Recorded time of the oldest record, looked up on the first sweep
+}
+
+// NewDerivedBackfiller creates a new derived channel backfiller
+func NewDerivedBackfiller(repo repository.DerivedChannelRepository, interval time.Duration) *DerivedBackfiller {
+	if interval <= 0 {
+		interval = DefaultDerivedBackfillInterval
+	}
+
+	return &DerivedBackfiller{
+		repo:     repo,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run backfills derived channels periodically until the history is done or the context is cancelled
+func (b *DerivedBackfiller) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for !b.sweep(ctx) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep backfills up to derivedBackfillBatchSize records, newest first
+// It returns true once every window down to the oldest record has been checked.
+func (b *DerivedBackfiller) sweep(ctx context.Context) bool {
+	if b.cursor.IsZero() {
+		oldest, err := b.repo.OldestRecordedAt(ctx)
+		if err != nil {
+			slog.Error("Error finding oldest telemetry to backfill", "error", err)
+			return false
+		}
+		if oldest == nil {
+			return true
+		}
+		// Records stored from now on get derived channels on ingest, unless they are backdated
+		b.cursor, b.oldest = b.now(), oldest
+	}
+
+	var updated int64
+	for updated < derivedBackfillBatchSize {
+		if b.cursor.Before(*b.oldest) {
+			slog.Info("Derived channels: backfill complete")
+			return true
+		}
+
+		from := b.cursor.Add(-derivedBackfillWindow)
+		n, err := b.repo.Backfill(ctx, from, b.cursor, int(derivedBackfillBatchSize-updated))
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				slog.Error("Error backfilling derived channels", "from", from, "to", b.cursor, "error", err)
+			}
+			break
+		}
+		updated += n
+
+		// A full batch may leave records in the window, so it is checked again next sweep
+		if updated < derivedBackfillBatchSize {
+			b.cursor = from
+		}
+	}
+
+	if updated > 0 {
+		slog.Info("Derived channels: backfilled records", "count", updated, "before", b.cursor)
+	}
+	return false
+}
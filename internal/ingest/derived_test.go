@@ -0,0 +1,107 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelDeriver_SaveBatch(t *testing.T) {
+	start := time.Now().UTC()
+	repo := repository.NewMockRepository()
+
+	// device-1's previous record was stored by another upload, possibly on another instance
+	stored := fix("device-1", start, 0, 42.0)
+	repo.PreviousRecordsFunc = func(_ context.Context, records []*models.TelemetryData) ([]*models.TelemetryData, error) {
+		previous := make([]*models.TelemetryData, len(records))
+		for i, record := range records {
+			if record.DeviceID == "device-1" && record.Timestamp.After(stored.Timestamp) {
+				previous[i] = stored
+			}
+		}
+		return previous, nil
+	}
+
+	// Records are derived in time order, whatever order they arrive in
+	second := fix("device-1", start, 2, 42.001)
+	first := fix("device-1", start, 1, 42.0005)
+	other := fix("device-2", start, 1, 40.0)
+	deriver := NewChannelDeriver(repo)
+	require.NoError(t, deriver.SaveBatch(context.Background(), []*models.TelemetryData{second, other, first}))
+
+	require.NotNil(t, first.Derived.DistanceIncrement, "deltas are taken from the stored record")
+	assert.InDelta(t, 55.6, *first.Derived.DistanceIncrement, 0.1)
+	require.NotNil(t, second.Derived.DistanceIncrement, "deltas are taken from the earlier record of the batch")
+	assert.InDelta(t, 55.6, *second.Derived.DistanceIncrement, 0.1)
+
+	require.NotNil(t, other.Derived)
+	assert.NotNil(t, other.Derived.TotalG)
+	assert.Nil(t, other.Derived.DistanceIncrement)
+}
+
+func TestChannelDeriver_PreviousRecordsError(t *testing.T) {
+	repo := repository.NewMockRepository()
+	repo.PreviousRecordsFunc = func(_ context.Context, _ []*models.TelemetryData) ([]*models.TelemetryData, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	record := fix("device-1", time.Now().UTC(), 0, 42.0)
+	require.NoError(t, NewChannelDeriver(repo).Save(context.Background(), record))
+	assert.Nil(t, record.Derived, "records are left to the backfill")
+}
+
+func TestDerivedBackfiller_Sweep(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	oldest := now.Add(-60 * time.Hour)
+
+	type window struct{ from, to time.Time }
+	var windows []window
+	results := []int64{derivedBackfillBatchSize, 100, 0, 0}
+
+	repo := repository.NewMockDerivedChannelRepository()
+	repo.OldestRecordedAtFunc = func(_ context.Context) (*time.Time, error) {
+		return &oldest, nil
+	}
+	repo.BackfillFunc = func(_ context.Context, from, to time.Time, limit int) (int64, error) {
+		windows = append(windows, window{from, to})
+		n := results[0]
+		results = results[1:]
+		assert.LessOrEqual(t, n, int64(limit))
+		return n, nil
+	}
+
+	backfiller := NewDerivedBackfiller(repo, time.Hour)
+	backfiller.now = func() time.Time { return now }
+
+	// A full batch ends the sweep and leaves the window to be checked again
+	assert.False(t, backfiller.sweep(context.Background()))
+	assert.Equal(t, []window{{now.Add(-24 * time.Hour), now}}, windows)
+
+	// Windows are walked back until the oldest record
+	assert.True(t, backfiller.sweep(context.Background()))
+	assert.Equal(t, []window{
+		{now.Add(-24 * time.Hour), now},
+		{now.Add(-24 * time.Hour), now},
+		{now.Add(-48 * time.Hour), now.Add(-24 * time.Hour)},
+		{now.Add(-72 * time.Hour), now.Add(-48 * time.Hour)},
+	}, windows)
+}
+
+func TestDerivedBackfiller_EmptyHistory(t *testing.T) {
+	repo := repository.NewMockDerivedChannelRepository()
+	repo.BackfillFunc = func(_ context.Context, _, _ time.Time, _ int) (int64, error) {
+		t.Fatal("nothing to backfill")
+		return 0, nil
+	}
+
+	backfiller := NewDerivedBackfiller(repo, 0)
+
+	assert.Equal(t, DefaultDerivedBackfillInterval, backfiller.interval)
+	assert.True(t, backfiller.sweep(context.Background()))
+}
@@ -0,0 +1,199 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+const (
+	// sampleInterval is how often the buffer drain rate and connection pool waits are sampled
+	sampleInterval = time.Second
+
+	// elevatedRatio is the fraction of a shed limit at which clients are asked to slow down
+	elevatedRatio = 0.5
+
+	// minRetryAfter and maxRetryAfter bound the Retry-After hint given to clients
+	minRetryAfter = time.Second
+	maxRetryAfter = time.Minute
+
+	// poolRetryAfter is the Retry-After hint while the connection pool is saturated
+	poolRetryAfter = 5 * time.Second
+)
+
+// PressureLevel describes how close telemetry ingest is to shedding load
+type PressureLevel string
+
+const (
+	// PressureNormal means uploads are accepted at any cadence
+	PressureNormal PressureLevel = "normal"
+	// PressureElevated means uploads are accepted but clients should slow down
+	PressureElevated PressureLevel = "elevated"
+	// PressureOverloaded means uploads are rejected with 503 until the backlog drains
+	PressureOverloaded PressureLevel = "overloaded"
+)
+
+// Pressure is a snapshot of the ingest backlog
+type Pressure struct {
+	Level      PressureLevel
+	RetryAfter time.Duration // Suggested delay before the next upload; zero at normal pressure
+
+	// BufferUtilization is the fraction of the write-behind buffer in use, or nil when it is disabled
+	BufferUtilization *float64
+}
+
+// bufferQueue is the part of BufferedWriter the monitor samples
+type bufferQueue interface {
+	Pending() int
+	Capacity() int
+	Flushed() int64
+}
+
+// Monitor tracks the write-behind buffer and database connection pool to decide when
+// telemetry uploads should be slowed down or rejected
+// The buffer is read on every call; the drain rate and pool waits are sampled by Run.
+type Monitor struct {
+	shedThreshold float64       // Buffer fill ratio at which uploads are shed; 0 disables
+	maxPoolWait   time.Duration // Average connection wait at which uploads are shed; 0 disables
+
+	buffer bufferQueue        // Optional: nil when uploads are written synchronously
+	pool   func() sql.DBStats // Optional: nil ignores the connection pool
+
+	mu          sync.RWMutex
+	lastFlushed int64
+	lastPool    sql.DBStats
+	drainRate   float64       // Records flushed per second, smoothed across samples
+	poolWaits   int64         // Connection waits during the last sample
+	poolWait    time.Duration // Average connection wait during the last sample
+}
+
+// NewMonitor creates a new ingest pressure monitor
+func NewMonitor(cfg config.IngestConfig) *Monitor {
+	return &Monitor{
+		shedThreshold: cfg.ShedThreshold,
+		maxPoolWait:   cfg.MaxPoolWait,
+	}
+}
+
+// WithWriter tracks the fill level and drain rate of the write-behind buffer
+func (m *Monitor) WithWriter(writer *BufferedWriter) *Monitor {
+	m.buffer = writer
+	return m
+}
+
+// WithPool tracks waits for connections in the database pool
+func (m *Monitor) WithPool(db *sql.DB) *Monitor {
+	m.pool = db.Stats
+	return m
+}
+
+// Run samples the drain rate and connection pool until the context is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	m.sample(sampleInterval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.sample(sampleInterval)
+		}
+	}
+}
+
+// sample updates the drain rate and pool waits from the counters since the last sample
+func (m *Monitor) sample(elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.buffer != nil {
+		flushed := m.buffer.Flushed()
+		// An idle buffer says nothing about how fast it drains, so keep the last rate
+		if delta := flushed - m.lastFlushed; delta > 0 {
+			rate := float64(delta) / elapsed.Seconds()
+			if m.drainRate == 0 {
+				m.drainRate = rate
+			} else {
+				m.drainRate = (m.drainRate + rate) / 2
+			}
+		}
+		m.lastFlushed = flushed
+	}
+
+	if m.pool != nil {
+		stats := m.pool()
+		m.poolWaits = stats.WaitCount - m.lastPool.WaitCount
+		m.poolWait = 0
+		if m.poolWaits > 0 {
+			m.poolWait = (stats.WaitDuration - m.lastPool.WaitDuration) / time.Duration(m.poolWaits)
+		}
+		m.lastPool = stats
+	}
+}
+
+// Pressure reports the current ingest pressure
+// The buffer counts as overloaded past the shed threshold and elevated past half of it; the
+// pool counts as overloaded once the average connection wait reaches the limit and elevated
+// whenever requests had to wait. The higher of the two wins.
+func (m *Monitor) Pressure() Pressure {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p := Pressure{Level: PressureNormal}
+
+	if m.buffer != nil {
+		pending, capacity := m.buffer.Pending(), m.buffer.Capacity()
+		utilization := 0.0
+		if capacity > 0 {
+			utilization = float64(pending) / float64(capacity)
+		}
+		p.BufferUtilization = &utilization
+
+		if m.shedThreshold > 0 {
+			switch {
+			case utilization >= m.shedThreshold:
+				p.raise(PressureOverloaded, m.drainTime(pending))
+			case utilization >= m.shedThreshold*elevatedRatio:
+				p.raise(PressureElevated, m.drainTime(pending))
+			}
+		}
+	}
+
+	if m.pool != nil && m.poolWaits > 0 {
+		if m.maxPoolWait > 0 && m.poolWait >= m.maxPoolWait {
+			p.raise(PressureOverloaded, poolRetryAfter)
+		} else {
+			p.raise(PressureElevated, minRetryAfter)
+		}
+	}
+
+	return p
+}
+
+// drainTime estimates how long the buffer takes to write pending records
+func (m *Monitor) drainTime(pending int) time.Duration {
+	if m.drainRate == 0 {
+		return minRetryAfter
+	}
+	return time.Duration(float64(pending) / m.drainRate * float64(time.Second))
+}
+
+// raise moves the pressure up to level, keeping the longest suggested delay
+func (p *Pressure) raise(level PressureLevel, retryAfter time.Duration) {
+	if level == PressureOverloaded || p.Level == PressureNormal {
+		p.Level = level
+	}
+	retryAfter = min(max(retryAfter, minRetryAfter), maxRetryAfter)
+	p.RetryAfter = max(p.RetryAfter, retryAfter)
+}
+
+// RetryAfterSeconds returns the suggested delay rounded up to whole seconds, as used by Retry-After
+func (p Pressure) RetryAfterSeconds() int {
+	return int(math.Ceil(p.RetryAfter.Seconds()))
+}
@@ -0,0 +1,115 @@
+package ingest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueue is a buffer with settable counters
+type fakeQueue struct {
+	pending, capacity int
+	flushed           int64
+}
+
+func (q *fakeQueue) Pending() int   { return q.pending }
+func (q *fakeQueue) Capacity() int  { return q.capacity }
+func (q *fakeQueue) Flushed() int64 { return q.flushed }
+
+func TestMonitor_BufferPressure(t *testing.T) {
+	queue := &fakeQueue{capacity: 1000}
+	monitor := NewMonitor(config.IngestConfig{ShedThreshold: 0.8})
+	monitor.buffer = queue
+
+	p := monitor.Pressure()
+	assert.Equal(t, PressureNormal, p.Level)
+	assert.Zero(t, p.RetryAfter)
+	require.NotNil(t, p.BufferUtilization)
+	assert.Zero(t, *p.BufferUtilization)
+
+	// 200 records drained in a second
+	monitor.sample(time.Second)
+	queue.flushed = 200
+	monitor.sample(time.Second)
+
+	queue.pending = 500
+	p = monitor.Pressure()
+	assert.Equal(t, PressureElevated, p.Level)
+	assert.Equal(t, 2500*time.Millisecond, p.RetryAfter)
+	assert.Equal(t, 3, p.RetryAfterSeconds())
+
+	queue.pending = 800
+	p = monitor.Pressure()
+	assert.Equal(t, PressureOverloaded, p.Level)
+	assert.Equal(t, 4*time.Second, p.RetryAfter)
+	assert.InDelta(t, 0.8, *p.BufferUtilization, 0.001)
+
+	// An idle sample keeps the last drain rate
+	monitor.sample(time.Second)
+	assert.Equal(t, 4*time.Second, monitor.Pressure().RetryAfter)
+}
+
+func TestMonitor_RetryAfterIsBounded(t *testing.T) {
+	queue := &fakeQueue{capacity: 100000, pending: 99000}
+	monitor := NewMonitor(config.IngestConfig{ShedThreshold: 0.9})
+	monitor.buffer = queue
+
+	// Without a measured drain rate the minimum is suggested
+	assert.Equal(t, time.Second, monitor.Pressure().RetryAfter)
+
+	queue.flushed = 10
+	monitor.sample(time.Second)
+	assert.Equal(t, time.Minute, monitor.Pressure().RetryAfter)
+}
+
+func TestMonitor_ShedThresholdDisabled(t *testing.T) {
+	monitor := NewMonitor(config.IngestConfig{})
+	monitor.buffer = &fakeQueue{capacity: 100, pending: 100}
+
+	assert.Equal(t, PressureNormal, monitor.Pressure().Level)
+}
+
+func TestMonitor_PoolPressure(t *testing.T) {
+	stats := sql.DBStats{}
+	monitor := NewMonitor(config.IngestConfig{MaxPoolWait: 500 * time.Millisecond})
+	monitor.pool = func() sql.DBStats { return stats }
+	monitor.sample(time.Second)
+	assert.Equal(t, PressureNormal, monitor.Pressure().Level)
+
+	// Short waits for a connection ask clients to slow down
+	stats.WaitCount, stats.WaitDuration = 4, 400*time.Millisecond
+	monitor.sample(time.Second)
+	p := monitor.Pressure()
+	assert.Equal(t, PressureElevated, p.Level)
+	assert.Nil(t, p.BufferUtilization)
+
+	// Long waits shed uploads
+	stats.WaitCount, stats.WaitDuration = 6, 2400*time.Millisecond
+	monitor.sample(time.Second)
+	p = monitor.Pressure()
+	assert.Equal(t, PressureOverloaded, p.Level)
+	assert.Equal(t, poolRetryAfter, p.RetryAfter)
+
+	// Pressure clears once requests stop waiting
+	monitor.sample(time.Second)
+	assert.Equal(t, PressureNormal, monitor.Pressure().Level)
+}
+
+func TestMonitor_HighestPressureWins(t *testing.T) {
+	stats := sql.DBStats{}
+	monitor := NewMonitor(config.IngestConfig{ShedThreshold: 0.9, MaxPoolWait: time.Second})
+	monitor.buffer = &fakeQueue{capacity: 100, pending: 95}
+	monitor.pool = func() sql.DBStats { return stats }
+	monitor.sample(time.Second)
+
+	stats.WaitCount, stats.WaitDuration = 1, time.Millisecond
+	monitor.sample(time.Second)
+
+	p := monitor.Pressure()
+	assert.Equal(t, PressureOverloaded, p.Level)
+	assert.Equal(t, time.Second, p.RetryAfter)
+}
@@ -0,0 +1,109 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// jumpWindow is the longest gap between two points of a device that are checked for a position jump
+	// A longer gap starts over from the new point, so a device switched off and moved elsewhere is
+	// not flagged indefinitely.
+	jumpWindow = 5 * time.Minute
+
+	// minJumpDistance in meters keeps position noise between closely spaced points at 25 Hz from
+	// counting as a jump
+	minJumpDistance = 50.0
+)
+
+// trackedPosition is the last clean position of a device
+type trackedPosition struct {
+	at    time.Time
+	point geo.Point
+}
+
+// QualityChecker flags physically impossible telemetry before it is written to another TelemetryRepository
+// Points reporting more than models.MaxPlausibleSpeed or a negative PDOP, and points that would
+// take more than models.MaxPlausibleSpeed to reach from the device's previous position, are stored
+// with quality flags instead of being rejected. Previous positions are kept in memory, so jumps
+// across uploads handled by different instances, or across a restart, go unnoticed.
+type QualityChecker struct {
+	repository.TelemetryRepository
+	clock *ClockPolicy // Optional: nil leaves receive times and clock skew unset
+
+	mu   sync.Mutex
+	last map[string]trackedPosition // By device ID
+}
+
+// NewQualityChecker wraps repo with quality checks on every save
+func NewQualityChecker(repo repository.TelemetryRepository) *QualityChecker {
+	return &QualityChecker{
+		TelemetryRepository: repo,
+		last:                make(map[string]trackedPosition),
+	}
+}
+
+// WithClockPolicy checks the device clock of records that were not checked on receipt
+func (q *QualityChecker) WithClockPolicy(policy *ClockPolicy) *QualityChecker {
+	q.clock = policy
+	return q
+}
+
+// Save implements TelemetryRepository.Save
+func (q *QualityChecker) Save(ctx context.Context, data *models.TelemetryData) error {
+	q.Check([]*models.TelemetryData{data})
+	return q.TelemetryRepository.Save(ctx, data)
+}
+
+// SaveBatch implements TelemetryRepository.SaveBatch
+func (q *QualityChecker) SaveBatch(ctx context.Context, data []*models.TelemetryData) error {
+	q.Check(data)
+	return q.TelemetryRepository.SaveBatch(ctx, data)
+}
+
+// Check sets the quality flags of records, in the order they were recorded
+func (q *QualityChecker) Check(records []*models.TelemetryData) {
+	// Corrected timestamps are the ones jumps are measured over
+	if q.clock != nil {
+		q.clock.Apply(records, time.Now())
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, record := range records {
+		record.CheckQuality()
+		q.checkJump(record)
+	}
+}
+
+// checkJump flags a record whose position cannot be reached from the device's previous one
+// Jumps do not replace the previous position, so the points after a glitch are compared with the
+// last clean one.
+func (q *QualityChecker) checkJump(record *models.TelemetryData) {
+	if record.DeviceID == "" || !record.GPS.IsFixValid {
+		return
+	}
+
+	point := geo.Point{Latitude: record.GPS.Latitude, Longitude: record.GPS.Longitude}
+	prev, ok := q.last[record.DeviceID]
+	gap := record.Timestamp.Sub(prev.at)
+	if ok && gap.Abs() <= jumpWindow {
+		// Points arriving out of order are not checked and do not move the reference back
+		if gap <= 0 {
+			return
+		}
+		distance := geo.Distance(prev.point, point)
+		if distance > minJumpDistance && distance/gap.Seconds()*3.6 > models.MaxPlausibleSpeed {
+			record.QualityFlags |= models.QualityPositionJump
+			return
+		}
+	}
+
+	q.last[record.DeviceID] = trackedPosition{at: record.Timestamp, point: point}
+}
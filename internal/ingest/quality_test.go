@@ -0,0 +1,76 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fix returns a record with a valid fix at latitude, offset seconds after start
+func fix(deviceID string, start time.Time, offset int, latitude float64) *models.TelemetryData {
+	return &models.TelemetryData{
+		DeviceID:  deviceID,
+		Timestamp: start.Add(time.Duration(offset) * time.Second),
+		GPS:       models.GpsData{Latitude: latitude, Longitude: 23.0, IsFixValid: true},
+	}
+}
+
+func TestQualityChecker_SaveBatch(t *testing.T) {
+	repo := repository.NewMockRepository()
+	var saved []*models.TelemetryData
+	repo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+		saved = data
+		return nil
+	}
+	checker := NewQualityChecker(repo)
+
+	start := time.Now().UTC()
+	batch := []*models.TelemetryData{
+		fix("device-1", start, 0, 42.0),
+		fix("device-1", start, 1, 42.0005), // 55 m in a second
+		fix("device-1", start, 2, 42.1),    // 11 km in a second
+		fix("device-1", start, 3, 42.001),  // Back on track, 55 m from the last clean point
+	}
+	batch[1].GPS.Speed = 650
+	batch[1].GPS.PDOP = -1
+
+	require.NoError(t, checker.SaveBatch(context.Background(), batch))
+	require.Len(t, saved, 4, "flagged points are stored")
+	assert.True(t, saved[0].IsClean())
+	assert.Equal(t, models.QualityImpossibleSpeed|models.QualityNegativePDOP, saved[1].QualityFlags)
+	assert.Equal(t, models.QualityPositionJump, saved[2].QualityFlags)
+	assert.True(t, saved[3].IsClean())
+}
+
+func TestQualityChecker_AcrossUploads(t *testing.T) {
+	checker := NewQualityChecker(repository.NewMockRepository())
+	ctx := context.Background()
+	start := time.Now().UTC()
+
+	require.NoError(t, checker.Save(ctx, fix("device-1", start, 0, 42.0)))
+
+	jump := fix("device-1", start, 10, 43.0)
+	require.NoError(t, checker.Save(ctx, jump))
+	assert.Equal(t, models.QualityPositionJump, jump.QualityFlags)
+
+	// Each device is compared with its own previous position
+	other := fix("device-2", start, 10, 43.0)
+	require.NoError(t, checker.Save(ctx, other))
+	assert.True(t, other.IsClean())
+
+	// After a long gap the device may have been moved, so the new position is accepted
+	moved := fix("device-1", start, 3600, 43.0)
+	require.NoError(t, checker.Save(ctx, moved))
+	assert.True(t, moved.IsClean())
+
+	// Points without a fix are not compared
+	noFix := fix("device-1", start, 3601, 0)
+	noFix.GPS.IsFixValid = false
+	require.NoError(t, checker.Save(ctx, noFix))
+	assert.True(t, noFix.IsClean())
+}
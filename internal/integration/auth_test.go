@@ -137,7 +137,8 @@ func runMigrations(db *database.DB, _ string) error {
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			last_login_at TIMESTAMPTZ,
-			is_active BOOLEAN DEFAULT TRUE
+			is_active BOOLEAN DEFAULT TRUE,
+			role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'))
 		);
 		
 		CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
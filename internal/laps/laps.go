@@ -0,0 +1,207 @@
+// Package laps detects start/finish line crossings in session telemetry and computes lap times.
+package laps
+
+import (
+	"math"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// minLapDuration debounces GPS jitter around the line; crossings closer together than this are ignored
+const minLapDuration = 10 * time.Second
+
+// sample is a telemetry point projected into the track's local frame
+type sample struct {
+	point geo.Point
+	x, y  float64
+	at    time.Time
+}
+
+// lapAccumulator collects statistics for the lap in progress
+type lapAccumulator struct {
+	startedAt  time.Time
+	distance   float64
+	maxSpeed   float64
+	maxGForce  float64
+	dataPoints int64
+}
+
+// Detector finds crossings of a track's start/finish line in a chronological telemetry stream
+// Only crossings in the direction of the first one count, so driving back over the line
+// (e.g. leaving through the pit lane) does not start a new lap. Points without a valid GPS fix are skipped.
+type Detector struct {
+	track          *models.Track
+	projection     geo.Projection
+	ax, ay, bx, by float64 // Start/finish line endpoints in the local frame
+
+	prev      *sample
+	direction float64 // Sign of the counted crossings; zero until the first crossing
+	current   *lapAccumulator
+	laps      []*models.Lap
+}
+
+// NewDetector creates a lap detector for a track
+func NewDetector(track *models.Track) *Detector {
+	projection := geo.NewProjection(track.LineStart)
+	ax, ay := projection.Project(track.LineStart)
+	bx, by := projection.Project(track.LineEnd)
+
+	return &Detector{
+		track:      track,
+		projection: projection,
+		ax:         ax,
+		ay:         ay,
+		bx:         bx,
+		by:         by,
+	}
+}
+
+// Add feeds the next telemetry point to the detector
+func (d *Detector) Add(t *models.TelemetryData) {
+	if !t.GPS.IsFixValid {
+		return
+	}
+
+	point := geo.Point{Latitude: t.GPS.Latitude, Longitude: t.GPS.Longitude}
+	x, y := d.projection.Project(point)
+	cur := &sample{point: point, x: x, y: y, at: t.Timestamp}
+
+	prev := d.prev
+	d.prev = cur
+	if prev == nil || !cur.at.After(prev.at) {
+		d.record(t)
+		return
+	}
+
+	segment := geo.Distance(prev.point, cur.point)
+
+	if frac, direction, ok := d.crossing(prev, cur); ok && (d.direction == 0 || direction == d.direction) {
+		crossedAt := prev.at.Add(time.Duration(frac * float64(cur.at.Sub(prev.at))))
+		if d.current == nil || crossedAt.Sub(d.current.startedAt) >= minLapDuration {
+			if d.current != nil {
+				d.current.distance += frac * segment
+				d.finish(crossedAt)
+			}
+			d.direction = direction
+			d.current = &lapAccumulator{startedAt: crossedAt, distance: (1 - frac) * segment}
+			d.record(t)
+			return
+		}
+	}
+
+	if d.current != nil {
+		d.current.distance += segment
+	}
+	d.record(t)
+}
+
+// Laps returns the completed laps with the fastest one flagged
+// The out-lap before the first crossing and the unfinished lap after the last one are excluded.
+func (d *Detector) Laps() []*models.Lap {
+	var best *models.Lap
+	for _, lap := range d.laps {
+		lap.IsBest = false
+		if best == nil || lap.DurationSeconds < best.DurationSeconds {
+			best = lap
+		}
+	}
+	if best != nil {
+		best.IsBest = true
+	}
+
+	laps := make([]*models.Lap, len(d.laps))
+	copy(laps, d.laps)
+	return laps
+}
+
+// record adds a point's speed and g-force to the lap in progress
+func (d *Detector) record(t *models.TelemetryData) {
+	if d.current == nil {
+		return
+	}
+	d.current.dataPoints++
+	d.current.maxSpeed = math.Max(d.current.maxSpeed, t.GPS.Speed)
+	d.current.maxGForce = math.Max(d.current.maxGForce, math.Hypot(t.Motion.GForceX, t.Motion.GForceY))
+}
+
+// finish closes the lap in progress at the given crossing time
+func (d *Detector) finish(endedAt time.Time) {
+	lap := d.current
+	duration := endedAt.Sub(lap.startedAt).Seconds()
+
+	avgSpeed := 0.0
+	if duration > 0 {
+		avgSpeed = lap.distance / duration * 3.6
+	}
+
+	d.laps = append(d.laps, &models.Lap{
+		Number:          len(d.laps) + 1,
+		StartedAt:       lap.startedAt,
+		EndedAt:         endedAt,
+		DurationSeconds: duration,
+		Distance:        lap.distance,
+		MaxSpeed:        lap.maxSpeed,
+		AvgSpeed:        avgSpeed,
+		MaxGForce:       lap.maxGForce,
+		DataPoints:      lap.dataPoints,
+	})
+}
+
+// crossing checks whether the segment prev→cur intersects the start/finish line
+// It returns the fraction along the segment where the crossing happens and the crossing direction.
+// Segments that start exactly on the line are not counted, so a point on the line is only crossed once.
+func (d *Detector) crossing(prev, cur *sample) (float64, float64, bool) {
+	rx, ry := cur.x-prev.x, cur.y-prev.y
+	sx, sy := d.bx-d.ax, d.by-d.ay
+
+	denom := rx*sy - ry*sx
+	if denom == 0 {
+		return 0, 0, false // Parallel to the line
+	}
+
+	qx, qy := d.ax-prev.x, d.ay-prev.y
+	t := (qx*sy - qy*sx) / denom // Fraction along the travelled segment
+	u := (qx*ry - qy*rx) / denom // Fraction along the start/finish line
+
+	if t <= 0 || t > 1 || u < 0 || u > 1 {
+		return 0, 0, false
+	}
+
+	return t, math.Copysign(1, denom), true
+}
+
+// Detect streams a session's telemetry through a detector per track and returns the track
+// that produced the most laps, together with those laps. It returns a nil track when no
+// laps were found on any of them.
+func Detect(tracks []*models.Track, it repository.TelemetryIterator) (*models.Track, []*models.Lap, error) {
+	detectors := make([]*Detector, len(tracks))
+	for i, track := range tracks {
+		detectors[i] = NewDetector(track)
+	}
+
+	for it.Next() {
+		t := it.Telemetry()
+		for _, detector := range detectors {
+			detector.Add(t)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		bestTrack *models.Track
+		bestLaps  = []*models.Lap{}
+	)
+	for _, detector := range detectors {
+		if laps := detector.Laps(); len(laps) > len(bestLaps) {
+			bestTrack = detector.track
+			bestLaps = laps
+		}
+	}
+
+	return bestTrack, bestLaps, nil
+}
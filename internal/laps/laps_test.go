@@ -0,0 +1,184 @@
+package laps
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	circuitCenter = geo.Point{Latitude: 42.6977, Longitude: 23.3219}
+	circuitStart  = time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+)
+
+const (
+	circuitRadius  = 200.0 // meters
+	circuitLapTime = 60 * time.Second
+	sampleRate     = 10 // Hz
+)
+
+// offset returns the point dx meters east and dy meters north of the circuit center
+func offset(dx, dy float64) geo.Point {
+	return geo.Point{
+		Latitude:  circuitCenter.Latitude + dy/geo.EarthRadius*180/math.Pi,
+		Longitude: circuitCenter.Longitude + dx/(geo.EarthRadius*math.Cos(circuitCenter.Latitude*math.Pi/180))*180/math.Pi,
+	}
+}
+
+// circuitTrack has its start/finish line across the circle's eastern edge
+func circuitTrack() *models.Track {
+	return &models.Track{
+		ID:        uuid.New(),
+		Name:      "Test Circuit",
+		LineStart: offset(circuitRadius-20, 0),
+		LineEnd:   offset(circuitRadius+20, 0),
+	}
+}
+
+// driveCircuit simulates counter-clockwise laps starting at startAngle (radians, 0 = east)
+func driveCircuit(startAngle, laps float64) []*models.TelemetryData {
+	omega := 2 * math.Pi / circuitLapTime.Seconds()
+	speed := omega * circuitRadius * 3.6
+
+	n := int(laps * circuitLapTime.Seconds() * sampleRate)
+	points := make([]*models.TelemetryData, 0, n+1)
+	for i := 0; i <= n; i++ {
+		elapsed := float64(i) / sampleRate
+		angle := startAngle + omega*elapsed
+		p := offset(circuitRadius*math.Cos(angle), circuitRadius*math.Sin(angle))
+
+		points = append(points, &models.TelemetryData{
+			Timestamp: circuitStart.Add(time.Duration(elapsed * float64(time.Second))),
+			GPS: models.GpsData{
+				Latitude:   p.Latitude,
+				Longitude:  p.Longitude,
+				Speed:      speed,
+				IsFixValid: true,
+			},
+			Motion: models.MotionData{GForceY: 0.8},
+		})
+	}
+	return points
+}
+
+func TestDetector_CountsLaps(t *testing.T) {
+	detector := NewDetector(circuitTrack())
+	for _, point := range driveCircuit(-math.Pi/6, 3.5) {
+		detector.Add(point)
+	}
+
+	laps := detector.Laps()
+	require.Len(t, laps, 3, "out-lap and the unfinished last lap are excluded")
+
+	for i, lap := range laps {
+		assert.Equal(t, i+1, lap.Number)
+		assert.InDelta(t, circuitLapTime.Seconds(), lap.DurationSeconds, 0.01)
+		assert.InDelta(t, 2*math.Pi*circuitRadius, lap.Distance, 1)
+		assert.InDelta(t, 2*math.Pi*circuitRadius/circuitLapTime.Seconds()*3.6, lap.AvgSpeed, 0.1)
+		assert.InDelta(t, 0.8, lap.MaxGForce, 1e-9)
+		assert.InDelta(t, int64(circuitLapTime.Seconds()*sampleRate), lap.DataPoints, 1)
+	}
+
+	// The first crossing happens a twelfth of a lap after the start
+	assert.WithinDuration(t, circuitStart.Add(5*time.Second), laps[0].StartedAt, 10*time.Millisecond)
+	assert.True(t, laps[0].EndedAt.Equal(laps[1].StartedAt))
+}
+
+func TestDetector_FlagsBestLap(t *testing.T) {
+	points := driveCircuit(-math.Pi/6, 3.5)
+
+	// Compress time in the second lap to make it the fastest
+	secondLapStart := circuitStart.Add(65 * time.Second)
+	for _, point := range points {
+		if elapsed := point.Timestamp.Sub(secondLapStart); elapsed > 0 {
+			if elapsed > circuitLapTime {
+				point.Timestamp = point.Timestamp.Add(-5 * time.Second)
+			} else {
+				point.Timestamp = secondLapStart.Add(elapsed * 11 / 12)
+			}
+		}
+	}
+
+	detector := NewDetector(circuitTrack())
+	for _, point := range points {
+		detector.Add(point)
+	}
+
+	laps := detector.Laps()
+	require.Len(t, laps, 3)
+	assert.False(t, laps[0].IsBest)
+	assert.True(t, laps[1].IsBest)
+	assert.False(t, laps[2].IsBest)
+	assert.InDelta(t, 55, laps[1].DurationSeconds, 0.01)
+}
+
+func TestDetector_IgnoresReverseAndJitterCrossings(t *testing.T) {
+	detector := NewDetector(circuitTrack())
+
+	// Cross northbound, wobble back over the line within the debounce window,
+	// cross southbound (e.g. through the pits), then cross northbound again
+	path := []struct {
+		elapsed time.Duration
+		dy      float64
+	}{
+		{0, -5}, {1 * time.Second, 5}, {2 * time.Second, -3}, {3 * time.Second, 4},
+		{20 * time.Second, 5}, {25 * time.Second, -5},
+		{30 * time.Second, -5}, {40 * time.Second, 5},
+	}
+	for _, step := range path {
+		p := offset(circuitRadius, step.dy)
+		detector.Add(&models.TelemetryData{
+			Timestamp: circuitStart.Add(step.elapsed),
+			GPS:       models.GpsData{Latitude: p.Latitude, Longitude: p.Longitude, IsFixValid: true},
+		})
+	}
+
+	laps := detector.Laps()
+	require.Len(t, laps, 1)
+	assert.WithinDuration(t, circuitStart.Add(500*time.Millisecond), laps[0].StartedAt, time.Millisecond)
+	assert.WithinDuration(t, circuitStart.Add(35*time.Second), laps[0].EndedAt, time.Millisecond)
+}
+
+func TestDetector_SkipsInvalidFixes(t *testing.T) {
+	points := driveCircuit(-math.Pi/6, 2.5)
+	for _, point := range points {
+		point.GPS.IsFixValid = false
+	}
+
+	detector := NewDetector(circuitTrack())
+	for _, point := range points {
+		detector.Add(point)
+	}
+
+	assert.Empty(t, detector.Laps())
+}
+
+func TestDetect_PicksTrackWithMostLaps(t *testing.T) {
+	elsewhere := &models.Track{
+		ID:        uuid.New(),
+		Name:      "Elsewhere",
+		LineStart: geo.Point{Latitude: 50.4372, Longitude: 5.9714},
+		LineEnd:   geo.Point{Latitude: 50.4374, Longitude: 5.9714},
+	}
+	circuit := circuitTrack()
+
+	it := repository.NewSliceTelemetryIterator(driveCircuit(-math.Pi/6, 2.5))
+	track, laps, err := Detect([]*models.Track{elsewhere, circuit}, it)
+	require.NoError(t, err)
+	require.NotNil(t, track)
+	assert.Equal(t, circuit.ID, track.ID)
+	assert.Len(t, laps, 2)
+
+	it = repository.NewSliceTelemetryIterator(driveCircuit(-math.Pi/6, 2.5))
+	track, laps, err = Detect([]*models.Track{elsewhere}, it)
+	require.NoError(t, err)
+	assert.Nil(t, track)
+	assert.Empty(t, laps)
+}
@@ -0,0 +1,81 @@
+// Package logging configures the service's structured logger.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+// Log output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// level is the minimum level of the logger installed by Setup, changed by SetLevel
+var level = new(slog.LevelVar)
+
+// New creates a logger writing records at or above the configured level to w
+// An empty level or format logs text at info level.
+func New(cfg config.LoggingConfig, w io.Writer) (*slog.Logger, error) {
+	lvl, err := parseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	return newLogger(cfg.Format, w, lvl)
+}
+
+// newLogger creates a logger in the given format filtered by leveler
+func newLogger(format string, w io.Writer, leveler slog.Leveler) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: leveler}
+
+	switch format {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q (must be text or json)", format)
+	}
+}
+
+// parseLevel parses a level name; empty means info
+func parseLevel(name string) (slog.Level, error) {
+	lvl := slog.LevelInfo
+	if name != "" {
+		if err := lvl.UnmarshalText([]byte(name)); err != nil {
+			return lvl, fmt.Errorf("invalid log level %q: %w", name, err)
+		}
+	}
+	return lvl, nil
+}
+
+// Setup installs the configured logger, writing to stderr, as the default logger
+// Output from the standard library log package goes through it too, at info level.
+func Setup(cfg config.LoggingConfig) error {
+	lvl, err := parseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	logger, err := newLogger(cfg.Format, os.Stderr, level)
+	if err != nil {
+		return err
+	}
+	level.Set(lvl)
+	slog.SetDefault(logger)
+	return nil
+}
+
+// SetLevel changes the minimum level of the logger installed by Setup while it is in use
+func SetLevel(name string) error {
+	lvl, err := parseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.Set(lvl)
+	return nil
+}
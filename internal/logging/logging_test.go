@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("json format", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(config.LoggingConfig{Level: "info", Format: FormatJSON}, &buf)
+		require.NoError(t, err)
+
+		logger.Info("Device claimed", "device_id", "RACEBOX-001")
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "INFO", record["level"])
+		assert.Equal(t, "Device claimed", record["msg"])
+		assert.Equal(t, "RACEBOX-001", record["device_id"])
+	})
+
+	t.Run("text format", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(config.LoggingConfig{Level: "info", Format: FormatText}, &buf)
+		require.NoError(t, err)
+
+		logger.Info("Device claimed", "device_id", "RACEBOX-001")
+		assert.Contains(t, buf.String(), `msg="Device claimed" device_id=RACEBOX-001`)
+	})
+
+	t.Run("level filters records", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(config.LoggingConfig{Level: "warn", Format: FormatText}, &buf)
+		require.NoError(t, err)
+
+		logger.Info("dropped")
+		logger.Warn("kept")
+		assert.NotContains(t, buf.String(), "dropped")
+		assert.Contains(t, buf.String(), "kept")
+		assert.False(t, logger.Enabled(t.Context(), slog.LevelDebug))
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		_, err := New(config.LoggingConfig{Level: "verbose", Format: FormatText}, &bytes.Buffer{})
+		assert.Error(t, err)
+
+		_, err = New(config.LoggingConfig{Level: "info", Format: "xml"}, &bytes.Buffer{})
+		assert.Error(t, err)
+	})
+}
+
+func TestSetLevel(t *testing.T) {
+	original := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(original) })
+
+	require.NoError(t, Setup(config.LoggingConfig{Level: "info", Format: FormatText}))
+	assert.False(t, slog.Default().Enabled(t.Context(), slog.LevelDebug))
+
+	require.NoError(t, SetLevel("debug"))
+	assert.True(t, slog.Default().Enabled(t.Context(), slog.LevelDebug))
+
+	assert.Error(t, SetLevel("verbose"))
+	assert.True(t, slog.Default().Enabled(t.Context(), slog.LevelDebug), "invalid levels leave the level unchanged")
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog configures the access log
+type AccessLog struct {
+	SampleRate float64  // Fraction of successful requests logged, from 0 to 1; failed requests are always logged
+	SkipPaths  []string // Paths never logged, like health checks
+}
+
+// NewAccessLogMiddleware logs every handled request through the structured logger
+// Records carry the method, path, route, status, latency and bytes, with the user and device
+// when known, read once the request has been handled. Server errors are logged at error level,
+// client errors at warn and the rest at info.
+func NewAccessLogMiddleware(cfg AccessLog) gin.HandlerFunc {
+	return newAccessLogMiddleware(cfg, rand.Float64)
+}
+
+// newAccessLogMiddleware creates the access log middleware with the source of sampling decisions
+func newAccessLogMiddleware(cfg AccessLog, sample func() float64) gin.HandlerFunc {
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		ctx := c.Request.Context()
+		if !slog.Default().Enabled(ctx, level) {
+			return
+		}
+		if level == slog.LevelInfo && cfg.SampleRate < 1 && sample() >= cfg.SampleRate {
+			return
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()), // Empty for unmatched paths
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int64("bytes_in", max(c.Request.ContentLength, 0)),
+			slog.Int("bytes_out", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if requestID := c.GetString("RequestID"); requestID != "" {
+			attrs = append(attrs, slog.String("request_id", requestID))
+		}
+		if userID, err := GetUserID(c); err == nil {
+			attrs = append(attrs, slog.String("user_id", userID.String()))
+		}
+		if deviceID := c.GetString(string(IngestDeviceIDKey)); deviceID != "" {
+			attrs = append(attrs, slog.String("device_id", deviceID))
+		} else if deviceID, ok := GetDeviceID(c); ok {
+			attrs = append(attrs, slog.String("device_id", deviceID))
+		}
+
+		slog.LogAttrs(ctx, level, "HTTP request", attrs...)
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs installs a JSON logger writing to the returned buffer for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	original := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(original) })
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	return &buf
+}
+
+// logRecords decodes the JSON records written to buf
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("logs the request with its user and device", func(t *testing.T) {
+		buf := captureLogs(t)
+		userID := uuid.New()
+		router := gin.New()
+		router.Use(NewAccessLogMiddleware(AccessLog{SampleRate: 1}))
+		router.POST("/api/v1/telemetry/:kind", func(c *gin.Context) {
+			c.Set(string(UserIDKey), userID)
+			SetIngestDetails(c, "RACEBOX-001", 2)
+			c.String(http.StatusCreated, "created")
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/telemetry/batch", strings.NewReader(`[{},{}]`))
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		records := logRecords(t, buf)
+		require.Len(t, records, 1)
+		record := records[0]
+		assert.Equal(t, "INFO", record["level"])
+		assert.Equal(t, "HTTP request", record["msg"])
+		assert.Equal(t, "POST", record["method"])
+		assert.Equal(t, "/api/v1/telemetry/batch", record["path"])
+		assert.Equal(t, "/api/v1/telemetry/:kind", record["route"])
+		assert.Equal(t, float64(http.StatusCreated), record["status"])
+		assert.Equal(t, userID.String(), record["user_id"])
+		assert.Equal(t, "RACEBOX-001", record["device_id"])
+		assert.Equal(t, float64(7), record["bytes_in"])
+		assert.Equal(t, float64(len("created")), record["bytes_out"])
+		assert.Contains(t, record, "latency_ms")
+	})
+
+	t.Run("levels follow the status", func(t *testing.T) {
+		buf := captureLogs(t)
+		router := gin.New()
+		router.Use(NewAccessLogMiddleware(AccessLog{SampleRate: 1}))
+		router.GET("/missing", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+		router.GET("/broken", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/broken", nil))
+
+		records := logRecords(t, buf)
+		require.Len(t, records, 2)
+		assert.Equal(t, "WARN", records[0]["level"])
+		assert.Equal(t, "ERROR", records[1]["level"])
+		assert.NotContains(t, records[0], "user_id")
+		assert.NotContains(t, records[0], "device_id")
+	})
+
+	t.Run("samples successful requests only", func(t *testing.T) {
+		buf := captureLogs(t)
+		draws := []float64{0.1, 0.9, 0.9}
+		sample := func() float64 {
+			draw := draws[0]
+			draws = draws[1:]
+			return draw
+		}
+		router := gin.New()
+		router.Use(newAccessLogMiddleware(AccessLog{SampleRate: 0.5}, sample))
+		router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+		router.GET("/bad", func(c *gin.Context) { c.Status(http.StatusBadRequest) })
+
+		for _, path := range []string{"/ok", "/ok", "/bad"} {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		records := logRecords(t, buf)
+		require.Len(t, records, 2)
+		assert.Equal(t, float64(http.StatusOK), records[0]["status"])
+		assert.Equal(t, float64(http.StatusBadRequest), records[1]["status"])
+	})
+
+	t.Run("skips configured paths", func(t *testing.T) {
+		buf := captureLogs(t)
+		router := gin.New()
+		router.Use(NewAccessLogMiddleware(AccessLog{SampleRate: 1, SkipPaths: []string{"/api/v1/health"}}))
+		router.GET("/api/v1/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+
+		assert.Empty(t, buf.String())
+	})
+}
@@ -2,12 +2,17 @@ package middleware
 
 import (
 	"errors"
-	"net/http"
+	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
 )
 
 // ContextKey is a custom type for context keys to avoid collisions
@@ -19,65 +24,172 @@ const (
 
 	// UserEmailKey is the context key for the authenticated user's email
 	UserEmailKey ContextKey = "user_email"
+
+	// UserRoleKey is the context key for the authenticated user's role
+	UserRoleKey ContextKey = "user_role"
+
+	// TokenClaimsKey is the context key for the claims of the request's access token
+	TokenClaimsKey ContextKey = "token_claims"
+
+	// ScopesKey is the context key for the scopes of the request's credential
+	ScopesKey ContextKey = "scopes"
+
+	// PersonalAccessTokenKey is the context key for the personal access token used to authenticate
+	PersonalAccessTokenKey ContextKey = "personal_access_token"
 )
 
+// errTokenRevoked is returned for access tokens on the denylist
+var errTokenRevoked = errors.New("token has been revoked")
+
 // AuthMiddleware provides authentication middleware
 type AuthMiddleware struct {
 	jwtService *auth.JWTService
+	denylist   *auth.Denylist                           // Optional: nil accepts every token until it expires
+	tokenRepo  repository.PersonalAccessTokenRepository // Optional: nil disables personal access tokens
+	userRepo   repository.UserRepository                // Optional: required for personal access tokens
+	now        func() time.Time
 }
 
 // NewAuthMiddleware creates a new auth middleware
 func NewAuthMiddleware(jwtService *auth.JWTService) *AuthMiddleware {
 	return &AuthMiddleware{
 		jwtService: jwtService,
+		now:        time.Now,
 	}
 }
 
+// WithDenylist rejects access tokens that were revoked before they expired
+func (m *AuthMiddleware) WithDenylist(denylist *auth.Denylist) *AuthMiddleware {
+	m.denylist = denylist
+	return m
+}
+
+// WithPersonalAccessTokens accepts users' personal access tokens on routes that allow their scopes
+// Tokens act on behalf of their user, who must still be active.
+func (m *AuthMiddleware) WithPersonalAccessTokens(tokenRepo repository.PersonalAccessTokenRepository, userRepo repository.UserRepository) *AuthMiddleware {
+	m.tokenRepo = tokenRepo
+	m.userRepo = userRepo
+	return m
+}
+
 // Required returns a middleware that requires a valid JWT token
+// Personal access tokens are rejected; routes accept them with RequireScope.
 // Returns 401 Unauthorized if the token is missing or invalid
 func (m *AuthMiddleware) Required() gin.HandlerFunc {
+	return m.RequireScope()
+}
+
+// RequireScope returns a middleware that requires a valid token carrying one of the given scopes
+// Access tokens carry the scopes of their user's role and personal access tokens the scopes they
+// were minted with. Without scopes, only access tokens are accepted.
+// Returns 401 Unauthorized if the token is missing or invalid, 403 Forbidden if it lacks the scopes
+func (m *AuthMiddleware) RequireScope(scopes ...models.Scope) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, err := m.extractAndValidateToken(c)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": err.Error(),
-			})
-			c.Abort()
+		if !m.authenticate(c) {
+			return
+		}
+		if err := authorize(c, scopes); err != nil {
+			problem.Abort(c, err)
 			return
 		}
+		c.Next()
+	}
+}
 
-		// Parse user ID from string to UUID
-		userID, err := uuid.Parse(claims.UserID)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "invalid user ID in token",
-			})
-			c.Abort()
+// RequireRole returns a middleware that requires a valid JWT token carrying one of the given roles
+// Personal access tokens are never accepted.
+// Returns 401 Unauthorized if the token is missing or invalid, 403 Forbidden if the role is not allowed
+func (m *AuthMiddleware) RequireRole(roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.authenticate(c) {
 			return
 		}
+		if err := authorize(c, nil); err != nil {
+			problem.Abort(c, err)
+			return
+		}
+
+		role := GetUserRole(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
 
-		// Set user information in context
-		c.Set(string(UserIDKey), userID)
-		c.Set(string(UserEmailKey), claims.Email)
+		problem.Abort(c, problem.Forbidden("forbidden", "insufficient permissions"))
+	}
+}
 
-		c.Next()
+// authenticate validates the request's token and stores the user information in the context
+// It writes a 401 response and aborts the request when authentication fails.
+func (m *AuthMiddleware) authenticate(c *gin.Context) bool {
+	if token, ok := m.personalAccessToken(c); ok {
+		if err := m.authenticatePersonalAccessToken(c, token); err != nil {
+			problem.Abort(c, err)
+			return false
+		}
+		return true
+	}
+
+	claims, err := m.extractAndValidateToken(c)
+	if err != nil {
+		problem.Abort(c, problem.Unauthorized("unauthorized", err.Error()))
+		return false
+	}
+
+	// Parse user ID from string to UUID
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		problem.Abort(c, problem.Unauthorized("unauthorized", "invalid user ID in token"))
+		return false
+	}
+
+	// Set user information in context
+	setUserContext(c, userID, claims)
+	return true
+}
+
+// authorize checks the authenticated request carries one of scopes
+// Without scopes, requests authenticated with a personal access token are refused, so routes
+// stay closed to them unless they name the scopes they accept.
+func authorize(c *gin.Context, scopes []models.Scope) error {
+	if len(scopes) == 0 {
+		if GetPersonalAccessToken(c) != nil {
+			return problem.Forbidden("insufficient_scope", "personal access tokens cannot be used for this endpoint")
+		}
+		return nil
 	}
+
+	if !HasScope(c, scopes...) {
+		return problem.Forbidden("insufficient_scope", "token does not grant access to this endpoint").
+			With("requiredScopes", scopes)
+	}
+	return nil
 }
 
 // Optional returns a middleware that extracts user info if a valid token is present
-// Continues execution even if the token is missing or invalid
-func (m *AuthMiddleware) Optional() gin.HandlerFunc {
+// Continues execution even if the token is missing or invalid. A valid token lacking all of the
+// given scopes gets 403 Forbidden rather than being ignored, so clients learn why they were not
+// authenticated.
+func (m *AuthMiddleware) Optional(scopes ...models.Scope) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, err := m.extractAndValidateToken(c)
-		if err == nil && claims != nil {
+		authenticated := false
+		if token, ok := m.personalAccessToken(c); ok {
+			authenticated = m.authenticatePersonalAccessToken(c, token) == nil
+		} else if claims, err := m.extractAndValidateToken(c); err == nil && claims != nil {
 			// Parse user ID from string to UUID
-			userID, err := uuid.Parse(claims.UserID)
-			if err == nil {
+			if userID, err := uuid.Parse(claims.UserID); err == nil {
 				// Set user information in context if token is valid
-				c.Set(string(UserIDKey), userID)
-				c.Set(string(UserEmailKey), claims.Email)
+				setUserContext(c, userID, claims)
+				authenticated = true
+			}
+		}
+
+		if authenticated {
+			if err := authorize(c, scopes); err != nil {
+				problem.Abort(c, err)
+				return
 			}
 		}
 
@@ -86,31 +198,70 @@ func (m *AuthMiddleware) Optional() gin.HandlerFunc {
 	}
 }
 
-// extractAndValidateToken extracts the JWT token from the request and validates it
-func (m *AuthMiddleware) extractAndValidateToken(c *gin.Context) (*auth.Claims, error) {
-	// Extract token from Authorization header
+// setUserContext stores the authenticated user's information in the context
+// Tokens issued before roles were introduced carry no role and are treated as regular users, and
+// tokens issued before scopes were introduced carry the scopes of their role.
+func setUserContext(c *gin.Context, userID uuid.UUID, claims *auth.Claims) {
+	role := models.Role(claims.Role)
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	scopes := models.ScopesForRole(role)
+	if len(claims.Scopes) > 0 {
+		scopes = make([]models.Scope, len(claims.Scopes))
+		for i, scope := range claims.Scopes {
+			scopes[i] = models.Scope(scope)
+		}
+	}
+
+	c.Set(string(UserIDKey), userID)
+	c.Set(string(UserEmailKey), claims.Email)
+	c.Set(string(UserRoleKey), role)
+	c.Set(string(TokenClaimsKey), claims)
+	c.Set(string(ScopesKey), scopes)
+}
+
+// bearerToken extracts the bearer token from the request's Authorization header
+func bearerToken(c *gin.Context) (string, error) {
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		return nil, errors.New("missing authorization header")
+		return "", errors.New("missing authorization header")
 	}
 
 	// Check for Bearer token format
 	parts := strings.SplitN(authHeader, " ", 2)
 	if len(parts) != 2 || parts[0] != "Bearer" {
-		return nil, errors.New("invalid authorization header format")
+		return "", errors.New("invalid authorization header format")
 	}
 
-	tokenString := parts[1]
-	if tokenString == "" {
-		return nil, errors.New("missing token")
+	if parts[1] == "" {
+		return "", errors.New("missing token")
+	}
+	return parts[1], nil
+}
+
+// extractAndValidateToken extracts the JWT token from the request and validates it
+func (m *AuthMiddleware) extractAndValidateToken(c *gin.Context) (*auth.Claims, error) {
+	tokenString, err := bearerToken(c)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate token
-	claims, err := m.jwtService.ValidateToken(tokenString)
+	claims, err := m.jwtService.ValidateAccessToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
 
+	// Reject revoked tokens; if the denylist cannot be read, tokens are accepted until they expire
+	revoked, err := m.denylist.IsRevoked(c.Request.Context(), claims)
+	if err != nil {
+		slog.Warn("Failed to check token denylist", "error", err)
+	} else if revoked {
+		return nil, errTokenRevoked
+	}
+
 	return claims, nil
 }
 
@@ -144,6 +295,53 @@ func GetUserEmail(c *gin.Context) (string, error) {
 	return emailStr, nil
 }
 
+// GetUserRole retrieves the authenticated user's role from the context
+// Returns an empty role if the request is not authenticated with a user token.
+func GetUserRole(c *gin.Context) models.Role {
+	role, exists := c.Get(string(UserRoleKey))
+	if !exists {
+		return ""
+	}
+
+	r, _ := role.(models.Role)
+	return r
+}
+
+// GetTokenClaims retrieves the claims of the request's access token from the context
+// Returns nil if the request is not authenticated with a user token.
+func GetTokenClaims(c *gin.Context) *auth.Claims {
+	claims, exists := c.Get(string(TokenClaimsKey))
+	if !exists {
+		return nil
+	}
+
+	cl, _ := claims.(*auth.Claims)
+	return cl
+}
+
+// GetScopes retrieves the scopes of the credential the request was authenticated with
+// Returns nil if the request is not authenticated.
+func GetScopes(c *gin.Context) []models.Scope {
+	scopes, exists := c.Get(string(ScopesKey))
+	if !exists {
+		return nil
+	}
+
+	s, _ := scopes.([]models.Scope)
+	return s
+}
+
+// HasScope checks if the request's credential carries at least one of the given scopes
+func HasScope(c *gin.Context, scopes ...models.Scope) bool {
+	granted := GetScopes(c)
+	for _, scope := range scopes {
+		if slices.Contains(granted, scope) {
+			return true
+		}
+	}
+	return false
+}
+
 // MustGetUserID retrieves the user ID from context, panics if not found
 // Use this only in handlers protected by Required() middleware
 func MustGetUserID(c *gin.Context) uuid.UUID {
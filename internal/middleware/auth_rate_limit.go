@@ -25,7 +25,7 @@ func NewAuthRateLimitMiddleware() gin.HandlerFunc {
 	instance := limiter.New(store, rate)
 
 	// Create and return Gin middleware
-	middleware := mgin.NewMiddleware(instance)
+	middleware := mgin.NewMiddleware(instance, mgin.WithLimitReachedHandler(RateLimitReached))
 
 	return middleware
 }
@@ -39,7 +39,7 @@ func NewAuthRateLimitMiddlewareWithConfig(limit int64, period time.Duration) gin
 
 	store := memory.NewStore()
 	instance := limiter.New(store, rate)
-	middleware := mgin.NewMiddleware(instance)
+	middleware := mgin.NewMiddleware(instance, mgin.WithLimitReachedHandler(RateLimitReached))
 
 	return middleware
 }
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,6 +10,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/cache"
+	"github.com/sebasr/avt-service/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,7 +28,7 @@ func TestAuthMiddleware_Required_ValidToken(t *testing.T) {
 	// Create a valid token
 	userID := uuid.New()
 	email := "test@example.com"
-	token, err := jwtService.GenerateAccessToken(userID, email)
+	token, err := jwtService.GenerateAccessToken(userID, email, "user")
 	require.NoError(t, err)
 
 	// Setup Gin router
@@ -105,6 +108,69 @@ func TestAuthMiddleware_Required_InvalidToken(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestAuthMiddleware_Required_RevokedToken(t *testing.T) {
+	middleware, jwtService := setupTestMiddleware()
+	denylist := auth.NewDenylist(cache.NewMemoryCache(), time.Hour)
+	middleware.WithDenylist(denylist)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var claims *auth.Claims
+	router.GET("/protected", middleware.Required(), func(c *gin.Context) {
+		claims = GetTokenClaims(c)
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	token, err := jwtService.GenerateAccessToken(uuid.New(), "test@example.com", "user")
+	require.NoError(t, err)
+
+	w := serve(token)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, claims)
+	assert.NotEmpty(t, claims.ID)
+
+	require.NoError(t, denylist.RevokeToken(context.Background(), claims))
+
+	w = serve(token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "token has been revoked")
+}
+
+func TestAuthMiddleware_Required_RefreshToken(t *testing.T) {
+	middleware, jwtService := setupTestMiddleware()
+
+	token, _, err := jwtService.GenerateRefreshToken(uuid.New(), "test@example.com")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	handlerCalled := false
+	router.GET("/protected", middleware.Required(), func(c *gin.Context) {
+		handlerCalled = true
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	router.ServeHTTP(w, req)
+
+	assert.False(t, handlerCalled)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "wrong token type")
+}
+
 func TestAuthMiddleware_Required_ExpiredToken(t *testing.T) {
 	// Create JWT service with very short TTL
 	jwtService := auth.NewJWTService("test-secret", 1*time.Millisecond, 1*time.Hour)
@@ -112,7 +178,7 @@ func TestAuthMiddleware_Required_ExpiredToken(t *testing.T) {
 
 	userID := uuid.New()
 	email := "expired@example.com"
-	token, err := jwtService.GenerateAccessToken(userID, email)
+	token, err := jwtService.GenerateAccessToken(userID, email, "user")
 	require.NoError(t, err)
 
 	// Wait for token to expire
@@ -174,12 +240,135 @@ func TestAuthMiddleware_Required_MalformedAuthHeader(t *testing.T) {
 	}
 }
 
+func TestAuthMiddleware_RequireRole(t *testing.T) {
+	middleware, jwtService := setupTestMiddleware()
+
+	tests := []struct {
+		name           string
+		role           string
+		expectedStatus int
+	}{
+		{"admin allowed", "admin", http.StatusOK},
+		{"user forbidden", "user", http.StatusForbidden},
+		{"legacy token without role forbidden", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwtService.GenerateAccessToken(uuid.New(), "test@example.com", tt.role)
+			require.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+
+			var capturedRole models.Role
+			router.GET("/admin", middleware.RequireRole(models.RoleAdmin), func(c *gin.Context) {
+				capturedRole = GetUserRole(c)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, models.RoleAdmin, capturedRole)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_RequireRole_NoToken(t *testing.T) {
+	middleware, _ := setupTestMiddleware()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin", middleware.RequireRole(models.RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_RequireScope(t *testing.T) {
+	middleware, jwtService := setupTestMiddleware()
+
+	tests := []struct {
+		name           string
+		role           string
+		scopes         []string // Empty for tokens issued before scopes
+		routeScope     models.Scope
+		expectedStatus int
+	}{
+		{"scope granted", "user", []string{"telemetry:read"}, models.ScopeTelemetryRead, http.StatusOK},
+		{"scope missing", "user", []string{"telemetry:read"}, models.ScopeDevicesManage, http.StatusForbidden},
+		{"admin scope", "admin", []string{"admin"}, models.ScopeAdmin, http.StatusOK},
+		{"legacy user token", "user", nil, models.ScopeDevicesManage, http.StatusOK},
+		{"legacy user token on admin route", "user", nil, models.ScopeAdmin, http.StatusForbidden},
+		{"legacy admin token", "admin", nil, models.ScopeAdmin, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwtService.GenerateAccessToken(uuid.New(), "test@example.com", tt.role, tt.scopes...)
+			require.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+
+			var capturedScopes []models.Scope
+			router.GET("/scoped", middleware.RequireScope(tt.routeScope), func(c *gin.Context) {
+				capturedScopes = GetScopes(c)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/scoped", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, capturedScopes, tt.routeScope)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_Optional_MissingScope(t *testing.T) {
+	middleware, jwtService := setupTestMiddleware()
+	token, err := jwtService.GenerateAccessToken(uuid.New(), "test@example.com", "user", "telemetry:read")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/telemetry", middleware.Optional(models.ScopeTelemetryWrite), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/telemetry", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code, "a read-only token is refused rather than ignored")
+}
+
 func TestAuthMiddleware_Optional_ValidToken(t *testing.T) {
 	middleware, jwtService := setupTestMiddleware()
 
 	userID := uuid.New()
 	email := "optional@example.com"
-	token, err := jwtService.GenerateAccessToken(userID, email)
+	token, err := jwtService.GenerateAccessToken(userID, email, "user")
 	require.NoError(t, err)
 
 	gin.SetMode(gin.TestMode)
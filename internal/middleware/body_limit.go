@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// NewBodyLimitMiddleware rejects request bodies larger than maxBytes with 413 Request Entity Too Large
+// Requests declaring a larger Content-Length are rejected before the body is read. Otherwise the body
+// is wrapped so reads fail past the limit, which also bounds bodies inflated by request decompression;
+// handlers report those failures with RespondBodyTooLarge. A non-positive maxBytes disables the limit.
+func NewBodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			RespondBodyTooLarge(c, maxBytes)
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// RespondBodyTooLarge writes the structured 413 response for a body over limit bytes
+func RespondBodyTooLarge(c *gin.Context, limit int64) {
+	problem.Abort(c, problem.New(http.StatusRequestEntityTooLarge, "request_too_large", fmt.Sprintf("Request body exceeds the %d byte limit", limit)).
+		With("limit", limit))
+}
+
+// BodyTooLarge reports whether err came from reading past a body limit, returning the limit
+func BodyTooLarge(err error) (int64, bool) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return maxErr.Limit, true
+	}
+	return 0, false
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	send := func(maxBytes int64, req *http.Request) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/upload", NewBodyLimitMiddleware(maxBytes), func(c *gin.Context) {
+			body, err := io.ReadAll(c.Request.Body)
+			if limit, ok := BodyTooLarge(err); ok {
+				RespondBodyTooLarge(c, limit)
+				return
+			}
+			require.NoError(t, err)
+			c.String(http.StatusOK, "%d", len(body))
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assertTooLarge := func(t *testing.T, w *httptest.ResponseRecorder, limit float64) {
+		t.Helper()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "request_too_large", response["code"])
+		assert.Equal(t, limit, response["limit"])
+	}
+
+	t.Run("accepts body within limit", func(t *testing.T) {
+		w := send(10, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("0123456789")))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "10", w.Body.String())
+	})
+
+	t.Run("rejects declared content length over limit", func(t *testing.T) {
+		w := send(10, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("0123456789a")))
+
+		assertTooLarge(t, w, 10)
+	})
+
+	t.Run("rejects body read past limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", 64)))
+		req.ContentLength = -1
+
+		w := send(10, req)
+
+		assertTooLarge(t, w, 10)
+	})
+
+	t.Run("zero disables the limit", func(t *testing.T) {
+		w := send(0, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", 64))))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "64", w.Body.String())
+	})
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// DeviceKeyHeader is the request header carrying a device API key
+	DeviceKeyHeader = "X-Device-Key"
+
+	// DeviceKeyIDHeader is the request header identifying the device API key a signed request was signed for
+	// It lets signed uploads authenticate without sending the key itself.
+	DeviceKeyIDHeader = "X-Device-Key-Id"
+)
+
+const (
+	// DeviceIDKey is the context key for the hardware device ID bound to a device API key
+	DeviceIDKey ContextKey = "device_id"
+
+	// DeviceAPIKeyIDKey is the context key for the ID of the device API key used
+	DeviceAPIKeyIDKey ContextKey = "device_api_key_id"
+
+	// DeviceSignatureRequiredKey is the context key for whether the device requires signed uploads
+	DeviceSignatureRequiredKey ContextKey = "device_signature_required"
+)
+
+// DeviceKeyMiddleware authenticates unattended devices using per-device API keys
+type DeviceKeyMiddleware struct {
+	keyRepo    repository.DeviceAPIKeyRepository
+	deviceRepo repository.DeviceRepository
+}
+
+// NewDeviceKeyMiddleware creates a new device key middleware
+func NewDeviceKeyMiddleware(keyRepo repository.DeviceAPIKeyRepository, deviceRepo repository.DeviceRepository) *DeviceKeyMiddleware {
+	return &DeviceKeyMiddleware{
+		keyRepo:    keyRepo,
+		deviceRepo: deviceRepo,
+	}
+}
+
+// Authenticate returns a middleware that authenticates requests carrying an X-Device-Key header
+// Requests without the header pass through untouched so JWT authentication can still apply, and
+// signed requests identified by X-Device-Key-Id are authenticated when their signature is verified.
+// Returns 401 Unauthorized if the key is unknown, revoked, or bound to an inactive device.
+func (m *DeviceKeyMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(DeviceKeyHeader))
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		apiKey, err := m.keyRepo.GetByHash(c.Request.Context(), auth.HashToken(key))
+		if err != nil {
+			message := "invalid device key"
+			if errors.Is(err, repository.ErrDeviceAPIKeyRevoked) {
+				message = "device key has been revoked"
+			} else if !errors.Is(err, repository.ErrDeviceAPIKeyNotFound) {
+				slog.Error("Error looking up device key", "error", err)
+			}
+			problem.Abort(c, problem.Unauthorized("unauthorized", message))
+			return
+		}
+
+		if !m.authorize(c, apiKey) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authorize lets the request act for the device the key is bound to
+// It writes the error response and returns false when the device is not active.
+func (m *DeviceKeyMiddleware) authorize(c *gin.Context, apiKey *models.DeviceAPIKey) bool {
+	device, err := m.deviceRepo.GetByID(c.Request.Context(), apiKey.DeviceID)
+	if err != nil || !device.IsActive {
+		problem.Abort(c, problem.Unauthorized("unauthorized", "device is not active"))
+		return false
+	}
+
+	if err := m.keyRepo.UpdateLastUsed(c.Request.Context(), apiKey.ID); err != nil {
+		slog.Warn("Failed to update device key last_used", "device_key_id", apiKey.ID, "error", err)
+	}
+
+	// Act on behalf of the device owner, but only to upload telemetry
+	c.Set(string(UserIDKey), device.UserID)
+	c.Set(string(ScopesKey), []models.Scope{models.ScopeTelemetryWrite})
+	c.Set(string(DeviceIDKey), device.DeviceID)
+	c.Set(string(DeviceAPIKeyIDKey), apiKey.ID)
+	c.Set(string(DeviceSignatureRequiredKey), device.RequireSignedUploads)
+	return true
+}
+
+// GetDeviceID retrieves the hardware device ID bound to the request's device API key
+// Returns false if the request was not authenticated with a device key
+func GetDeviceID(c *gin.Context) (string, bool) {
+	deviceID, exists := c.Get(string(DeviceIDKey))
+	if !exists {
+		return "", false
+	}
+
+	id, ok := deviceID.(string)
+	return id, ok
+}
+
+// GetDeviceAPIKeyID retrieves the ID of the device API key used to authenticate the request
+func GetDeviceAPIKeyID(c *gin.Context) (uuid.UUID, bool) {
+	keyID, exists := c.Get(string(DeviceAPIKeyIDKey))
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	id, ok := keyID.(uuid.UUID)
+	return id, ok
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDeviceKeyTest(isActive bool) (*DeviceKeyMiddleware, string, *models.Device, *models.DeviceAPIKey) {
+	key, prefix, _ := auth.GenerateDeviceAPIKey()
+	hash := auth.HashToken(key)
+
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: uuid.New(), IsActive: isActive}
+	apiKey := &models.DeviceAPIKey{ID: uuid.New(), DeviceID: device.ID, UserID: device.UserID, KeyPrefix: prefix, KeyHash: hash}
+
+	keyRepo := repository.NewMockDeviceAPIKeyRepository()
+	keyRepo.GetByHashFunc = func(_ context.Context, h string) (*models.DeviceAPIKey, error) {
+		if h == hash {
+			return apiKey, nil
+		}
+		if h == auth.HashToken("revoked-key") {
+			return nil, repository.ErrDeviceAPIKeyRevoked
+		}
+		return nil, repository.ErrDeviceAPIKeyNotFound
+	}
+	keyRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.DeviceAPIKey, error) {
+		if id == apiKey.ID {
+			return apiKey, nil
+		}
+		return nil, repository.ErrDeviceAPIKeyNotFound
+	}
+
+	deviceRepo := repository.NewMockDeviceRepository()
+	deviceRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Device, error) {
+		if id == device.ID {
+			return device, nil
+		}
+		return nil, repository.ErrDeviceNotFound
+	}
+
+	return NewDeviceKeyMiddleware(keyRepo, deviceRepo), key, device, apiKey
+}
+
+func TestDeviceKeyMiddleware_ValidKey(t *testing.T) {
+	middleware, key, device, apiKey := setupDeviceKeyTest(true)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var capturedUserID uuid.UUID
+	var capturedDeviceID string
+	var capturedKeyID uuid.UUID
+	var capturedScopes []models.Scope
+	router.POST("/telemetry", middleware.Authenticate(), func(c *gin.Context) {
+		capturedUserID, _ = GetUserID(c)
+		capturedDeviceID, _ = GetDeviceID(c)
+		capturedKeyID, _ = GetDeviceAPIKeyID(c)
+		capturedScopes = GetScopes(c)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/telemetry", nil)
+	req.Header.Set(DeviceKeyHeader, key)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, device.UserID, capturedUserID)
+	assert.Equal(t, device.DeviceID, capturedDeviceID)
+	assert.Equal(t, apiKey.ID, capturedKeyID)
+	assert.Equal(t, []models.Scope{models.ScopeTelemetryWrite}, capturedScopes, "device keys only upload telemetry")
+}
+
+func TestDeviceKeyMiddleware_NoHeader(t *testing.T) {
+	middleware, _, _, _ := setupDeviceKeyTest(true)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	handlerCalled := false
+	router.POST("/telemetry", middleware.Authenticate(), func(c *gin.Context) {
+		handlerCalled = true
+		_, hasDevice := GetDeviceID(c)
+		assert.False(t, hasDevice)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/telemetry", nil))
+
+	assert.True(t, handlerCalled)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDeviceKeyMiddleware_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		isActive bool
+	}{
+		{name: "unknown key", key: "avtdk_unknown", isActive: true},
+		{name: "revoked key", key: "revoked-key", isActive: true},
+		{name: "inactive device", key: "", isActive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware, validKey, _, _ := setupDeviceKeyTest(tt.isActive)
+			key := tt.key
+			if key == "" {
+				key = validKey
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			handlerCalled := false
+			router.POST("/telemetry", middleware.Authenticate(), func(c *gin.Context) {
+				handlerCalled = true
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/telemetry", nil)
+			req.Header.Set(DeviceKeyHeader, key)
+			router.ServeHTTP(w, req)
+
+			assert.False(t, handlerCalled)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/cache"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// SignatureHeader is the request header carrying the hex HMAC-SHA256 signature of an upload
+	SignatureHeader = "X-Signature"
+
+	// SignatureTimestampHeader is the request header carrying the Unix time (seconds) the upload was signed at
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+
+	// SignatureNonceHeader is the request header carrying a value the device never reuses with the same key
+	SignatureNonceHeader = "X-Signature-Nonce"
+)
+
+// maxSignatureNonceLength bounds the nonces kept for replay protection
+const maxSignatureNonceLength = 128
+
+// defaultSignatureMaxAge is how far signature timestamps may be from the server clock by default
+const defaultSignatureMaxAge = 5 * time.Minute
+
+// signatureNoncePrefix namespaces used nonces in the nonce store
+const signatureNoncePrefix = "signature:nonce:"
+
+// DeviceSignatureMiddleware verifies HMAC-signed uploads from devices with an API key
+// The signature is the hex HMAC-SHA256, keyed with the signing secret minted with the device API key,
+// of "<timestamp>\n<nonce>\n<body>", where body is the request body after any Content-Encoding has
+// been removed. Signatures older or newer than maxAge are rejected, and each nonce is accepted once
+// per key for as long as its signature could still be valid. The signing secret is stored sealed
+// and never sent, so a captured request does not let its signatures be forged.
+type DeviceSignatureMiddleware struct {
+	keys    *DeviceKeyMiddleware
+	secrets *auth.SecretBox
+	nonces  cache.Cache
+	maxAge  time.Duration
+	now     func() time.Time
+}
+
+// NewDeviceSignatureMiddleware creates a signature middleware that remembers used nonces in nonces
+// Signing secrets are opened with secrets; a nil box rejects every signature. An in-memory nonce store
+// is not shared between instances, so a request could be replayed once against each instance within
+// maxAge. A non-positive maxAge accepts signatures up to 5 minutes away.
+func NewDeviceSignatureMiddleware(keys *DeviceKeyMiddleware, secrets *auth.SecretBox, nonces cache.Cache, maxAge time.Duration) *DeviceSignatureMiddleware {
+	if maxAge <= 0 {
+		maxAge = defaultSignatureMaxAge
+	}
+	return &DeviceSignatureMiddleware{
+		keys:    keys,
+		secrets: secrets,
+		nonces:  nonces,
+		maxAge:  maxAge,
+		now:     time.Now,
+	}
+}
+
+// Verify returns a middleware that checks X-Signature on uploads from devices
+// Signed uploads identify their key with X-Device-Key-Id and are authenticated as that key's device
+// once the signature is verified; uploads that also send X-Device-Key are checked against that key.
+// Unsigned uploads pass through unless the device requires signed uploads. Uploads authenticated with
+// a JWT, or anonymously, are not checked. Invalid, expired or replayed signatures are rejected with
+// 401 Unauthorized. The body is read in full, so the route must limit its size.
+func (m *DeviceSignatureMiddleware) Verify() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyID, authenticated := GetDeviceAPIKeyID(c)
+		signature := strings.TrimSpace(c.GetHeader(SignatureHeader))
+		if signature == "" {
+			if authenticated && signatureRequired(c) {
+				problem.Abort(c, problem.Unauthorized("signature_required", "This device must sign uploads with "+SignatureHeader))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !authenticated {
+			header := strings.TrimSpace(c.GetHeader(DeviceKeyIDHeader))
+			if header == "" || m.keys == nil {
+				c.Next()
+				return
+			}
+			id, err := uuid.Parse(header)
+			if err != nil {
+				problem.Abort(c, problem.Unauthorized("unauthorized", "invalid device key"))
+				return
+			}
+			keyID = id
+		}
+
+		timestamp := strings.TrimSpace(c.GetHeader(SignatureTimestampHeader))
+		nonce := strings.TrimSpace(c.GetHeader(SignatureNonceHeader))
+		if timestamp == "" || nonce == "" {
+			problem.Abort(c, problem.Unauthorized("invalid_signature", SignatureTimestampHeader+" and "+SignatureNonceHeader+" are required with "+SignatureHeader))
+			return
+		}
+		if len(nonce) > maxSignatureNonceLength {
+			problem.Abort(c, problem.Unauthorized("invalid_signature", SignatureNonceHeader+" must be at most "+strconv.Itoa(maxSignatureNonceLength)+" characters"))
+			return
+		}
+
+		signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			problem.Abort(c, problem.Unauthorized("invalid_signature", SignatureTimestampHeader+" must be a Unix time in seconds"))
+			return
+		}
+		if age := m.now().Sub(time.Unix(signedAt, 0)); age > m.maxAge || age < -m.maxAge {
+			problem.Abort(c, problem.Unauthorized("signature_expired", "Signature timestamp is outside the accepted window").
+				With("maxAge", m.maxAge.String()))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			if limit, ok := BodyTooLarge(err); ok {
+				RespondBodyTooLarge(c, limit)
+				return
+			}
+			problem.Abort(c, problem.BadRequest("invalid_request", "Failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		apiKey, ok := m.signingKey(c, keyID)
+		if !ok {
+			return
+		}
+		secret, ok := m.signingSecret(c, apiKey)
+		if !ok {
+			return
+		}
+		if !validSignature(secret, timestamp, nonce, body, signature) {
+			problem.Abort(c, problem.Unauthorized("invalid_signature", "Signature does not match the request"))
+			return
+		}
+
+		if !authenticated && !m.keys.authorize(c, apiKey) {
+			return
+		}
+
+		if m.replayed(c.Request.Context(), keyID.String(), nonce) {
+			problem.Abort(c, problem.Unauthorized("replayed_request", "Signature nonce has already been used"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RejectRequired returns a middleware that rejects uploads from devices that require signed uploads
+// It guards routes whose streamed bodies cannot be verified before they are processed.
+func (m *DeviceSignatureMiddleware) RejectRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signatureRequired(c) {
+			problem.Abort(c, problem.Unauthorized("signature_required", "This device must sign uploads, which this endpoint does not support"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// signingKey loads the device API key a signed request was signed for
+// It writes the error response and returns false when the key is unknown or revoked.
+func (m *DeviceSignatureMiddleware) signingKey(c *gin.Context, keyID uuid.UUID) (*models.DeviceAPIKey, bool) {
+	apiKey, err := m.keys.keyRepo.GetByID(c.Request.Context(), keyID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrDeviceAPIKeyNotFound) {
+			slog.Error("Error looking up device key", "error", err)
+		}
+		problem.Abort(c, problem.Unauthorized("unauthorized", "invalid device key"))
+		return nil, false
+	}
+	if apiKey.IsRevoked() {
+		problem.Abort(c, problem.Unauthorized("unauthorized", "device key has been revoked"))
+		return nil, false
+	}
+	return apiKey, true
+}
+
+// signingSecret opens the signing secret minted with apiKey
+// It writes the error response and returns false when the key has no secret the server can open.
+func (m *DeviceSignatureMiddleware) signingSecret(c *gin.Context, apiKey *models.DeviceAPIKey) (string, bool) {
+	if m.secrets == nil || apiKey.SigningSecret == "" {
+		problem.Abort(c, problem.Unauthorized("invalid_signature", "This device key has no signing secret; mint a new key to sign uploads"))
+		return "", false
+	}
+	secret, err := m.secrets.Open(apiKey.SigningSecret, apiKey.ID[:])
+	if err != nil {
+		slog.Error("Failed to open device signing secret", "device_key_id", apiKey.ID, "error", err)
+		problem.Abort(c, problem.Internal("Failed to verify signature"))
+		return "", false
+	}
+	return secret, true
+}
+
+// replayed records the nonce as used, reporting whether it had been used already
+// If the store cannot be written, the nonce is accepted; the timestamp still bounds replays.
+func (m *DeviceSignatureMiddleware) replayed(ctx context.Context, keyID, nonce string) bool {
+	// A nonce must be remembered while its timestamp is within maxAge of the clock on either side
+	added, err := m.nonces.Add(ctx, signatureNoncePrefix+keyID+":"+nonce, true, 2*m.maxAge)
+	if err != nil {
+		slog.Warn("Failed to record signature nonce", "error", err)
+		return false
+	}
+	return !added
+}
+
+// signatureRequired reports whether the request's device key belongs to a device requiring signed uploads
+func signatureRequired(c *gin.Context) bool {
+	required, _ := c.Get(string(DeviceSignatureRequiredKey))
+	value, _ := required.(bool)
+	return value
+}
+
+// validSignature compares signature with the HMAC of the signed request in constant time
+func validSignature(secret, timestamp, nonce string, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(uploadMAC(secret, timestamp, nonce, body), expected)
+}
+
+// uploadMAC computes the HMAC-SHA256 of a signed upload
+func uploadMAC(secret, timestamp, nonce string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + nonce + "\n"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,291 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/cache"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signingTestDevice holds the credentials minted for the device in signature tests
+type signingTestDevice struct {
+	key    string
+	keyID  uuid.UUID
+	secret string
+	device *models.Device
+	apiKey *models.DeviceAPIKey
+}
+
+func setupDeviceSignatureTest(t *testing.T, requireSigned bool) (*gin.Engine, *signingTestDevice) {
+	t.Helper()
+	keyAuth, key, device, apiKey := setupDeviceKeyTest(true)
+	device.RequireSignedUploads = requireSigned
+
+	secrets, err := auth.NewSecretBox(base64.StdEncoding.EncodeToString(make([]byte, auth.SecretBoxKeyLength)))
+	require.NoError(t, err)
+	secret, err := auth.GenerateDeviceSigningSecret()
+	require.NoError(t, err)
+	apiKey.SigningSecret, err = secrets.Seal(secret, apiKey.ID[:])
+	require.NoError(t, err)
+
+	signatures := NewDeviceSignatureMiddleware(keyAuth, secrets, cache.NewMemoryCache(), time.Minute)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/telemetry", keyAuth.Authenticate(), signatures.Verify(), func(c *gin.Context) {
+		if deviceID, ok := GetDeviceID(c); ok {
+			c.Header("X-Test-Device", deviceID)
+		}
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	router.POST("/telemetry/stream", keyAuth.Authenticate(), signatures.RejectRequired(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	return router, &signingTestDevice{key: key, keyID: apiKey.ID, secret: secret, device: device, apiKey: apiKey}
+}
+
+// signedRequest builds an upload signed with secret and identified by its key ID
+func signedRequest(keyID uuid.UUID, secret, timestamp, nonce string, body []byte) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/telemetry", bytes.NewReader(body))
+	req.Header.Set(DeviceKeyIDHeader, keyID.String())
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureNonceHeader, nonce)
+	req.Header.Set(SignatureHeader, hex.EncodeToString(uploadMAC(secret, timestamp, nonce, body)))
+	return req
+}
+
+func TestDeviceSignatureMiddleware_Verify(t *testing.T) {
+	body := []byte(`{"speed":42}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	tests := []struct {
+		name           string
+		requireSigned  bool
+		request        func(d *signingTestDevice) *http.Request
+		expectedStatus int
+		expectedCode   string
+		anonymous      bool
+	}{
+		{
+			name:          "valid signature identified by key ID",
+			requireSigned: true,
+			request: func(d *signingTestDevice) *http.Request {
+				return signedRequest(d.keyID, d.secret, now, "nonce-1", body)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:          "valid signature with the API key",
+			requireSigned: true,
+			request: func(d *signingTestDevice) *http.Request {
+				req := signedRequest(d.keyID, d.secret, now, "nonce-1", body)
+				req.Header.Del(DeviceKeyIDHeader)
+				req.Header.Set(DeviceKeyHeader, d.key)
+				return req
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "unsigned upload from a device not requiring signatures",
+			request: func(d *signingTestDevice) *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/telemetry", bytes.NewReader(body))
+				req.Header.Set(DeviceKeyHeader, d.key)
+				return req
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:          "unsigned upload from a device requiring signatures",
+			requireSigned: true,
+			request: func(d *signingTestDevice) *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/telemetry", bytes.NewReader(body))
+				req.Header.Set(DeviceKeyHeader, d.key)
+				return req
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "signature_required",
+		},
+		{
+			name:          "anonymous upload is not checked",
+			requireSigned: true,
+			request: func(_ *signingTestDevice) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/telemetry", bytes.NewReader(body))
+			},
+			expectedStatus: http.StatusOK,
+			anonymous:      true,
+		},
+		{
+			name: "key ID without a signature does not authenticate",
+			request: func(d *signingTestDevice) *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/telemetry", bytes.NewReader(body))
+				req.Header.Set(DeviceKeyIDHeader, d.keyID.String())
+				return req
+			},
+			expectedStatus: http.StatusOK,
+			anonymous:      true,
+		},
+		{
+			name: "tampered body",
+			request: func(d *signingTestDevice) *http.Request {
+				req := signedRequest(d.keyID, d.secret, now, "nonce-1", body)
+				req.Body = io.NopCloser(bytes.NewReader([]byte(`{"speed":420}`)))
+				return req
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "invalid_signature",
+		},
+		{
+			name: "signed with the API key",
+			request: func(d *signingTestDevice) *http.Request {
+				return signedRequest(d.keyID, d.key, now, "nonce-1", body)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "invalid_signature",
+		},
+		{
+			name: "unknown key ID",
+			request: func(d *signingTestDevice) *http.Request {
+				return signedRequest(uuid.New(), d.secret, now, "nonce-1", body)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "unauthorized",
+		},
+		{
+			name: "revoked key",
+			request: func(d *signingTestDevice) *http.Request {
+				revokedAt := time.Now()
+				d.apiKey.RevokedAt = &revokedAt
+				return signedRequest(d.keyID, d.secret, now, "nonce-1", body)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "device key has been revoked",
+		},
+		{
+			name: "key minted without a signing secret",
+			request: func(d *signingTestDevice) *http.Request {
+				d.apiKey.SigningSecret = ""
+				return signedRequest(d.keyID, d.secret, now, "nonce-1", body)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "invalid_signature",
+		},
+		{
+			name: "inactive device",
+			request: func(d *signingTestDevice) *http.Request {
+				d.device.IsActive = false
+				return signedRequest(d.keyID, d.secret, now, "nonce-1", body)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "device is not active",
+		},
+		{
+			name: "missing nonce",
+			request: func(d *signingTestDevice) *http.Request {
+				req := signedRequest(d.keyID, d.secret, now, "nonce-1", body)
+				req.Header.Del(SignatureNonceHeader)
+				return req
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "invalid_signature",
+		},
+		{
+			name: "expired timestamp",
+			request: func(d *signingTestDevice) *http.Request {
+				return signedRequest(d.keyID, d.secret, strconv.FormatInt(time.Now().Add(-2*time.Minute).Unix(), 10), "nonce-1", body)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "signature_expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, device := setupDeviceSignatureTest(t, tt.requireSigned)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.request(device))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedCode)
+				return
+			}
+			// The verified body is still available to the handler
+			assert.Equal(t, string(body), w.Body.String())
+			if tt.anonymous {
+				assert.Empty(t, w.Header().Get("X-Test-Device"))
+			} else {
+				assert.Equal(t, device.device.DeviceID, w.Header().Get("X-Test-Device"))
+			}
+		})
+	}
+}
+
+func TestDeviceSignatureMiddleware_NoSigningSecrets(t *testing.T) {
+	keyAuth, _, _, apiKey := setupDeviceKeyTest(true)
+	apiKey.SigningSecret = "sealed"
+	signatures := NewDeviceSignatureMiddleware(keyAuth, nil, cache.NewMemoryCache(), time.Minute)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/telemetry", keyAuth.Authenticate(), signatures.Verify(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest(apiKey.ID, "secret", strconv.FormatInt(time.Now().Unix(), 10), "nonce-1", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_signature")
+}
+
+func TestDeviceSignatureMiddleware_ReplayedNonce(t *testing.T) {
+	router, device := setupDeviceSignatureTest(t, false)
+	body := []byte(`{"speed":42}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest(device.keyID, device.secret, now, "nonce-1", body))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest(device.keyID, device.secret, now, "nonce-1", body))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "replayed_request")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest(device.keyID, device.secret, now, "nonce-2", body))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDeviceSignatureMiddleware_RejectRequired(t *testing.T) {
+	for _, requireSigned := range []bool{false, true} {
+		router, device := setupDeviceSignatureTest(t, requireSigned)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/telemetry/stream", nil)
+		req.Header.Set(DeviceKeyHeader, device.key)
+		router.ServeHTTP(w, req)
+
+		if requireSigned {
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), "signature_required")
+		} else {
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+	}
+}
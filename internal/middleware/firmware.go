@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+const (
+	// FirmwareVersionHeader is the request header carrying the uploading device's firmware version
+	FirmwareVersionHeader = "X-Firmware-Version"
+
+	// MinFirmwareVersionHeader is the response header naming the oldest supported firmware version
+	MinFirmwareVersionHeader = "X-Min-Firmware-Version"
+
+	// FirmwareVersionKey is the context key for the parsed firmware version of the request
+	FirmwareVersionKey ContextKey = "firmware_version"
+)
+
+// NewFirmwareMiddleware reads the firmware version reported by uploading devices and advises
+// devices older than minVersion to update
+// Outdated uploads are answered with a Warning header and X-Min-Firmware-Version, or rejected with
+// 426 Upgrade Required when require is set. Requests without the header pass through untouched, and
+// a nil minVersion only records the version. Malformed versions are rejected with 400.
+func NewFirmwareMiddleware(minVersion *models.FirmwareVersion, require bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := strings.TrimSpace(c.GetHeader(FirmwareVersionHeader))
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		version, err := models.ParseFirmwareVersion(header)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("invalid_firmware_version", FirmwareVersionHeader+" must look like MAJOR[.MINOR[.PATCH]][-PRERELEASE]"))
+			return
+		}
+		c.Set(string(FirmwareVersionKey), version)
+
+		if minVersion == nil || !version.Before(*minVersion) {
+			c.Next()
+			return
+		}
+
+		c.Header(MinFirmwareVersionHeader, minVersion.String())
+		if require {
+			problem.Abort(c, problem.New(http.StatusUpgradeRequired, "firmware_update_required", fmt.Sprintf("Firmware %s is no longer supported, update to %s or later", version, minVersion)).
+				With("minVersion", minVersion.String()))
+			return
+		}
+
+		c.Header("Warning", fmt.Sprintf(`299 - "Firmware %s is outdated, update to %s or later"`, version, minVersion))
+		c.Next()
+	}
+}
+
+// GetFirmwareVersion retrieves the firmware version reported with the request
+// Returns false if the request did not carry X-Firmware-Version
+func GetFirmwareVersion(c *gin.Context) (models.FirmwareVersion, bool) {
+	value, exists := c.Get(string(FirmwareVersionKey))
+	if !exists {
+		return models.FirmwareVersion{}, false
+	}
+
+	version, ok := value.(models.FirmwareVersion)
+	return version, ok
+}
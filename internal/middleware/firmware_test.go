@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+func setupFirmwareTest(t *testing.T, minimum string, requireMin bool) (*gin.Engine, *string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var minVersion *models.FirmwareVersion
+	if minimum != "" {
+		parsed, err := models.ParseFirmwareVersion(minimum)
+		require.NoError(t, err)
+		minVersion = &parsed
+	}
+
+	var reported string
+	router := gin.New()
+	router.POST("/telemetry", NewFirmwareMiddleware(minVersion, requireMin), func(c *gin.Context) {
+		if version, ok := GetFirmwareVersion(c); ok {
+			reported = version.String()
+		}
+		c.Status(http.StatusCreated)
+	})
+	return router, &reported
+}
+
+func postWithFirmware(router *gin.Engine, version string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/telemetry", nil)
+	if version != "" {
+		req.Header.Set(FirmwareVersionHeader, version)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFirmwareMiddleware_Warn(t *testing.T) {
+	router, reported := setupFirmwareTest(t, "2.0", false)
+
+	t.Run("current firmware", func(t *testing.T) {
+		w := postWithFirmware(router, "v2.1")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get("Warning"))
+		assert.Equal(t, "2.1.0", *reported)
+	})
+
+	t.Run("outdated firmware", func(t *testing.T) {
+		w := postWithFirmware(router, "1.9.4")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Header().Get("Warning"), "Firmware 1.9.4 is outdated")
+		assert.Equal(t, "2.0.0", w.Header().Get(MinFirmwareVersionHeader))
+		assert.Equal(t, "1.9.4", *reported)
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		*reported = ""
+		w := postWithFirmware(router, "")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get(MinFirmwareVersionHeader))
+		assert.Empty(t, *reported)
+	})
+
+	t.Run("malformed version", func(t *testing.T) {
+		w := postWithFirmware(router, "latest")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_firmware_version")
+	})
+}
+
+func TestFirmwareMiddleware_Require(t *testing.T) {
+	router, _ := setupFirmwareTest(t, "2.0.0", true)
+
+	w := postWithFirmware(router, "2.0.0-rc.1")
+	assert.Equal(t, http.StatusUpgradeRequired, w.Code)
+	assert.Contains(t, w.Body.String(), "firmware_update_required")
+	assert.Equal(t, "2.0.0", w.Header().Get(MinFirmwareVersionHeader))
+
+	w = postWithFirmware(router, "2.0.0")
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Devices that do not report a version cannot be judged and are accepted
+	w = postWithFirmware(router, "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestFirmwareMiddleware_NoMinimum(t *testing.T) {
+	router, reported := setupFirmwareTest(t, "", true)
+
+	w := postWithFirmware(router, "0.1")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get("Warning"))
+	assert.Equal(t, "0.1.0", *reported)
+}
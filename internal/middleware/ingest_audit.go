@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+const (
+	// IngestDeviceIDKey is the context key for the hardware device ID an upload targets
+	IngestDeviceIDKey ContextKey = "ingest_device_id"
+
+	// IngestRecordsKey is the context key for the number of records in an upload
+	IngestRecordsKey ContextKey = "ingest_records"
+)
+
+// IngestAuditRecorder stores ingest audit entries
+type IngestAuditRecorder interface {
+	Record(entry *models.IngestAuditEntry)
+}
+
+// NewIngestAuditMiddleware records every request to an ingest route in the audit log
+// It must run before the authentication and rate limiting middleware so rejected requests are
+// recorded too. The user and device key are read once the request has been handled; the device
+// and record count are those reported by the handler through SetIngestDetails.
+func NewIngestAuditMiddleware(recorder IngestAuditRecorder, source models.IngestSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		entry := &models.IngestAuditEntry{
+			CreatedAt: start.UTC(),
+			Source:    source,
+			SourceIP:  c.ClientIP(),
+			Status:    status,
+			Result:    models.IngestResultForStatus(status),
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if userID, err := GetUserID(c); err == nil {
+			entry.UserID = &userID
+		}
+		if keyID, ok := GetDeviceAPIKeyID(c); ok {
+			entry.APIKeyID = &keyID
+		}
+		if deviceID := c.GetString(string(IngestDeviceIDKey)); deviceID != "" {
+			entry.DeviceID = deviceID
+		} else if deviceID, ok := GetDeviceID(c); ok {
+			entry.DeviceID = deviceID
+		}
+		entry.Records = c.GetInt(string(IngestRecordsKey))
+
+		recorder.Record(entry)
+	}
+}
+
+// SetIngestDetails reports the device and number of records of an upload to the audit log
+func SetIngestDetails(c *gin.Context, deviceID string, records int) {
+	c.Set(string(IngestDeviceIDKey), deviceID)
+	c.Set(string(IngestRecordsKey), records)
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedEntries []*models.IngestAuditEntry
+
+func (r *recordedEntries) Record(entry *models.IngestAuditEntry) {
+	*r = append(*r, entry)
+}
+
+func TestIngestAuditMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("records an accepted upload with its details", func(t *testing.T) {
+		var entries recordedEntries
+		userID := uuid.New()
+		router := gin.New()
+		router.POST("/telemetry",
+			NewIngestAuditMiddleware(&entries, models.IngestSourceBatch),
+			func(c *gin.Context) {
+				c.Set(string(UserIDKey), userID)
+				c.Next()
+			},
+			func(c *gin.Context) {
+				SetIngestDetails(c, "RACEBOX-001", 25)
+				c.Status(http.StatusCreated)
+			},
+		)
+
+		req := httptest.NewRequest(http.MethodPost, "/telemetry", nil)
+		req.RemoteAddr = "203.0.113.7:5000"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Len(t, entries, 1)
+		entry := entries[0]
+		assert.Equal(t, models.IngestSourceBatch, entry.Source)
+		assert.Equal(t, &userID, entry.UserID)
+		assert.Equal(t, "RACEBOX-001", entry.DeviceID)
+		assert.Equal(t, 25, entry.Records)
+		assert.Equal(t, "203.0.113.7", entry.SourceIP)
+		assert.Equal(t, http.StatusCreated, entry.Status)
+		assert.Equal(t, models.IngestResultAccepted, entry.Result)
+		assert.False(t, entry.CreatedAt.IsZero())
+		assert.GreaterOrEqual(t, entry.LatencyMS, 0.0)
+	})
+
+	t.Run("records requests rejected by earlier middleware", func(t *testing.T) {
+		var entries recordedEntries
+		keyID := uuid.New()
+		router := gin.New()
+		router.POST("/telemetry",
+			NewIngestAuditMiddleware(&entries, models.IngestSourceSingle),
+			func(c *gin.Context) {
+				c.Set(string(DeviceIDKey), "RACEBOX-002")
+				c.Set(string(DeviceAPIKeyIDKey), keyID)
+				c.AbortWithStatus(http.StatusTooManyRequests)
+			},
+		)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/telemetry", nil))
+
+		require.Len(t, entries, 1)
+		entry := entries[0]
+		assert.Nil(t, entry.UserID)
+		assert.Equal(t, &keyID, entry.APIKeyID)
+		assert.Equal(t, "RACEBOX-002", entry.DeviceID)
+		assert.Zero(t, entry.Records)
+		assert.Equal(t, models.IngestResultRejected, entry.Result)
+	})
+
+	t.Run("records server errors as failed", func(t *testing.T) {
+		var entries recordedEntries
+		router := gin.New()
+		router.POST("/telemetry",
+			NewIngestAuditMiddleware(&entries, models.IngestSourceStream),
+			func(c *gin.Context) { c.Status(http.StatusInternalServerError) },
+		)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/telemetry", nil))
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, models.IngestResultFailed, entries[0].Result)
+	})
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// personalAccessToken returns the request's bearer token if it is a personal access token
+// Tokens are recognised by their prefix, and only when personal access tokens are enabled.
+func (m *AuthMiddleware) personalAccessToken(c *gin.Context) (string, bool) {
+	if m.tokenRepo == nil {
+		return "", false
+	}
+
+	token, err := bearerToken(c)
+	if err != nil || !strings.HasPrefix(token, auth.PersonalAccessTokenPrefix) {
+		return "", false
+	}
+	return token, true
+}
+
+// authenticatePersonalAccessToken validates a personal access token and stores its user and scopes in the context
+// Returns a 401 error if the token is unknown, revoked or expired, or its user is inactive.
+func (m *AuthMiddleware) authenticatePersonalAccessToken(c *gin.Context, plain string) error {
+	token, err := m.tokenRepo.GetByHash(c.Request.Context(), auth.HashToken(plain))
+	if err != nil {
+		message := "invalid token"
+		if errors.Is(err, repository.ErrPersonalAccessTokenRevoked) {
+			message = "token has been revoked"
+		} else if !errors.Is(err, repository.ErrPersonalAccessTokenNotFound) {
+			slog.Error("Error looking up personal access token", "error", err)
+		}
+		return problem.Unauthorized("unauthorized", message)
+	}
+	if token.IsExpired(m.now()) {
+		return problem.Unauthorized("unauthorized", "token has expired")
+	}
+
+	user, err := m.userRepo.GetByID(c.Request.Context(), token.UserID)
+	if err != nil || !user.IsActive {
+		return problem.Unauthorized("unauthorized", "user is not active")
+	}
+
+	if err := m.tokenRepo.UpdateLastUsed(c.Request.Context(), token.ID); err != nil {
+		slog.Warn("Failed to update personal access token last_used", "token_id", token.ID, "error", err)
+	}
+
+	role := user.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+	c.Set(string(UserIDKey), user.ID)
+	c.Set(string(UserEmailKey), user.Email)
+	c.Set(string(UserRoleKey), role)
+	c.Set(string(ScopesKey), token.Scopes)
+	c.Set(string(PersonalAccessTokenKey), token)
+	return nil
+}
+
+// GetPersonalAccessToken retrieves the personal access token used to authenticate the request
+// Returns nil if the request was not authenticated with a personal access token.
+func GetPersonalAccessToken(c *gin.Context) *models.PersonalAccessToken {
+	token, exists := c.Get(string(PersonalAccessTokenKey))
+	if !exists {
+		return nil
+	}
+
+	t, _ := token.(*models.PersonalAccessToken)
+	return t
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPersonalAccessTokenTest(scopes []models.Scope, expiresAt *time.Time, userActive bool) (*AuthMiddleware, string, *models.User, *repository.MockPersonalAccessTokenRepository) {
+	plain, prefix, _ := auth.GeneratePersonalAccessToken()
+	hash := auth.HashToken(plain)
+
+	user := &models.User{ID: uuid.New(), Email: "pat@example.com", IsActive: userActive, Role: models.RoleUser}
+	token := &models.PersonalAccessToken{ID: uuid.New(), UserID: user.ID, TokenPrefix: prefix, TokenHash: hash, Scopes: scopes, ExpiresAt: expiresAt}
+
+	tokenRepo := repository.NewMockPersonalAccessTokenRepository()
+	tokenRepo.GetByHashFunc = func(_ context.Context, h string) (*models.PersonalAccessToken, error) {
+		if h == hash {
+			return token, nil
+		}
+		if h == auth.HashToken(auth.PersonalAccessTokenPrefix+"revoked") {
+			return nil, repository.ErrPersonalAccessTokenRevoked
+		}
+		return nil, repository.ErrPersonalAccessTokenNotFound
+	}
+
+	userRepo := repository.NewMockUserRepository()
+	userRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+		if id == user.ID {
+			return user, nil
+		}
+		return nil, repository.ErrUserNotFound
+	}
+
+	jwtService := auth.NewJWTService("test-secret-key", time.Hour, 24*time.Hour)
+	middleware := NewAuthMiddleware(jwtService).WithPersonalAccessTokens(tokenRepo, userRepo)
+	return middleware, plain, user, tokenRepo
+}
+
+func TestAuthMiddleware_PersonalAccessToken_RequireScope(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	read := []models.Scope{models.ScopeTelemetryRead}
+
+	tests := []struct {
+		name           string
+		scopes         []models.Scope // Scopes the token carries
+		expiresAt      *time.Time
+		userActive     bool
+		routeScopes    []models.Scope // Scopes the route accepts
+		token          string         // Overrides the minted token
+		expectedStatus int
+	}{
+		{name: "scope granted", scopes: read, userActive: true, routeScopes: read, expectedStatus: http.StatusOK},
+		{name: "one of several scopes", scopes: []models.Scope{models.ScopeTelemetryWrite}, userActive: true, routeScopes: []models.Scope{models.ScopeTelemetryRead, models.ScopeTelemetryWrite}, expectedStatus: http.StatusOK},
+		{name: "not yet expired", scopes: read, expiresAt: &future, userActive: true, routeScopes: read, expectedStatus: http.StatusOK},
+		{name: "missing scope", scopes: []models.Scope{models.ScopeTelemetryWrite}, userActive: true, routeScopes: read, expectedStatus: http.StatusForbidden},
+		{name: "route without scopes", scopes: read, userActive: true, expectedStatus: http.StatusForbidden},
+		{name: "expired", scopes: read, expiresAt: &past, userActive: true, routeScopes: read, expectedStatus: http.StatusUnauthorized},
+		{name: "inactive user", scopes: read, userActive: false, routeScopes: read, expectedStatus: http.StatusUnauthorized},
+		{name: "revoked", scopes: read, userActive: true, routeScopes: read, token: auth.PersonalAccessTokenPrefix + "revoked", expectedStatus: http.StatusUnauthorized},
+		{name: "unknown", scopes: read, userActive: true, routeScopes: read, token: auth.PersonalAccessTokenPrefix + "unknown", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware, plain, user, tokenRepo := setupPersonalAccessTokenTest(tt.scopes, tt.expiresAt, tt.userActive)
+			if tt.token != "" {
+				plain = tt.token
+			}
+			var lastUsed bool
+			tokenRepo.UpdateLastUsedFunc = func(_ context.Context, _ uuid.UUID) error {
+				lastUsed = true
+				return nil
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+
+			var capturedUserID uuid.UUID
+			var capturedToken *models.PersonalAccessToken
+			router.GET("/telemetry", middleware.RequireScope(tt.routeScopes...), func(c *gin.Context) {
+				capturedUserID = MustGetUserID(c)
+				capturedToken = GetPersonalAccessToken(c)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/telemetry", nil)
+			req.Header.Set("Authorization", "Bearer "+plain)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedStatus != http.StatusUnauthorized, lastUsed, "authentication is recorded even when the scope is missing")
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, user.ID, capturedUserID)
+				assert.NotNil(t, capturedToken)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_PersonalAccessToken_RequireRole(t *testing.T) {
+	middleware, plain, user, _ := setupPersonalAccessTokenTest([]models.Scope{models.ScopeTelemetryRead}, nil, true)
+	user.Role = models.RoleAdmin
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin", middleware.RequireRole(models.RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("Authorization", "Bearer "+plain)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code, "admin routes never accept personal access tokens")
+}
+
+func TestAuthMiddleware_PersonalAccessToken_Optional(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string // Overrides the minted token
+		routeScopes    []models.Scope
+		expectedStatus int
+		expectUser     bool
+	}{
+		{name: "scope granted", routeScopes: []models.Scope{models.ScopeTelemetryWrite}, expectedStatus: http.StatusOK, expectUser: true},
+		{name: "missing scope", routeScopes: []models.Scope{models.ScopeTelemetryRead}, expectedStatus: http.StatusForbidden},
+		{name: "unknown token", token: auth.PersonalAccessTokenPrefix + "unknown", routeScopes: []models.Scope{models.ScopeTelemetryWrite}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware, plain, user, _ := setupPersonalAccessTokenTest([]models.Scope{models.ScopeTelemetryWrite}, nil, true)
+			if tt.token != "" {
+				plain = tt.token
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+
+			var capturedUserID uuid.UUID
+			router.POST("/telemetry", middleware.Optional(tt.routeScopes...), func(c *gin.Context) {
+				capturedUserID, _ = GetUserID(c)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/telemetry", nil)
+			req.Header.Set("Authorization", "Bearer "+plain)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectUser {
+				assert.Equal(t, user.ID, capturedUserID)
+			} else {
+				assert.Equal(t, uuid.Nil, capturedUserID)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_PersonalAccessToken_Disabled(t *testing.T) {
+	// Without a token repository, personal access tokens are rejected like any invalid JWT
+	middleware, _ := setupTestMiddleware()
+	plain, _, _ := auth.GeneratePersonalAccessToken()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/telemetry", middleware.RequireScope(models.ScopeTelemetryRead), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/telemetry", nil)
+	req.Header.Set("Authorization", "Bearer "+plain)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// NewProblemMiddleware makes sure every failed request ends in an application/problem+json response
+// Handlers normally respond with problem.Abort; this renders errors they only attached to the
+// context, turns panics into 500 problems, and logs the causes attached to server errors.
+// It replaces gin.Recovery and must run before any middleware that can fail a request.
+func NewProblemMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(recovered)
+				}
+				slog.Error("Panic while handling request",
+					"method", c.Request.Method,
+					"path", c.Request.URL.Path,
+					"requestId", c.GetString("RequestID"),
+					"panic", recovered,
+				)
+				if !c.Writer.Written() {
+					problem.Abort(c, problem.Internal("An unexpected error occurred").WithCause(fmt.Errorf("panic: %v", recovered)))
+				}
+			}
+		}()
+
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil {
+			return
+		}
+		if !c.Writer.Written() {
+			problem.Write(c, last.Err)
+		}
+		// Handlers log the failures they respond to; only causes they handed over are logged here
+		if err := problem.From(last.Err); err.Status >= http.StatusInternalServerError && err.Unwrap() != nil {
+			slog.Error("Request failed",
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"requestId", c.GetString("RequestID"),
+				"status", err.Status,
+				"code", err.Code,
+				"error", err.Unwrap(),
+			)
+		}
+	}
+}
+
+// NotFoundHandler responds to requests for unknown routes
+func NotFoundHandler(c *gin.Context) {
+	problem.Abort(c, problem.NotFound("route_not_found", "No route matches "+c.Request.Method+" "+c.Request.URL.Path))
+}
+
+// RateLimitReached responds to requests rejected by a ulule/limiter middleware
+func RateLimitReached(c *gin.Context) {
+	problem.Abort(c, problem.TooManyRequests("rate_limit_exceeded", "Too many requests, please try again later"))
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+func TestProblemMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewProblemMiddleware())
+	router.NoRoute(NotFoundHandler)
+	router.GET("/panic", func(_ *gin.Context) {
+		panic("boom")
+	})
+	router.GET("/attached", func(c *gin.Context) {
+		_ = c.Error(problem.Conflict("session_already_ended", "Session has already ended"))
+	})
+	router.GET("/untyped", func(c *gin.Context) {
+		_ = c.Error(errors.New("connection refused"))
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "panic", path: "/panic", expectedStatus: http.StatusInternalServerError, expectedCode: "internal_error"},
+		{name: "attached error", path: "/attached", expectedStatus: http.StatusConflict, expectedCode: "session_already_ended"},
+		{name: "untyped error", path: "/untyped", expectedStatus: http.StatusInternalServerError, expectedCode: "internal_error"},
+		{name: "unknown route", path: "/missing", expectedStatus: http.StatusNotFound, expectedCode: "route_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response["code"])
+			assert.Equal(t, tt.path, response["instance"])
+			assert.NotContains(t, w.Body.String(), "connection refused")
+		})
+	}
+}
+
+func TestRateLimitReached(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewAuthRateLimitMiddlewareWithConfig(1, time.Minute))
+	router.GET("/login", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, send().Code)
+	w := send()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"code":"rate_limit_exceeded"`)
+}
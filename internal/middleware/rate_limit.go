@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sebasr/avt-service/internal/problem"
+	"github.com/sebasr/avt-service/internal/ratelimit"
+)
+
+// RateLimitKeyFunc derives the bucket key identifying the caller of a request
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// KeyByIP identifies callers by client IP address
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUserOrIP identifies authenticated callers by user ID, falling back to client IP
+// Must run after the authentication middleware to see the user.
+func KeyByUserOrIP(c *gin.Context) string {
+	if userID, err := GetUserID(c); err == nil {
+		return "user:" + userID.String()
+	}
+	return KeyByIP(c)
+}
+
+// NewRateLimitMiddleware creates a token bucket rate limiting middleware
+// Buckets are namespaced by name so separate routes never share a budget.
+// Returns 429 Too Many Requests with a Retry-After header once the bucket is empty.
+// If the store fails, the request is allowed through rather than blocking traffic.
+func NewRateLimitMiddleware(store ratelimit.Store, name string, policy ratelimit.Policy, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	return NewReloadableRateLimitMiddleware(store, name, ratelimit.NewPolicyVar(policy), keyFunc)
+}
+
+// NewReloadableRateLimitMiddleware creates a token bucket rate limiting middleware whose policy
+// can be changed while serving; each request is limited by the policy current at the time
+func NewReloadableRateLimitMiddleware(store ratelimit.Store, name string, policy *ratelimit.PolicyVar, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := store.Take(c.Request.Context(), name+":"+keyFunc(c), policy.Policy())
+		if err != nil {
+			slog.Warn("Rate limiter unavailable, allowing request", "limiter", name, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			problem.Abort(c, problem.TooManyRequests("rate_limit_exceeded", "Too many requests, retry after "+strconv.Itoa(retryAfter)+" seconds"))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(_ context.Context, _ string, _ ratelimit.Policy) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func TestRateLimitMiddleware_LimitsAndSetsRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/login", NewRateLimitMiddleware(ratelimit.NewMemoryStore(), "login", ratelimit.PerMinute(6, 2), KeyByIP), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send("10.0.0.1").Code)
+	w := send("10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = send("10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "rate_limit_exceeded")
+
+	// A different IP has its own budget
+	assert.Equal(t, http.StatusOK, send("10.0.0.2").Code)
+}
+
+func TestRateLimitMiddleware_KeyByUserOrIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := ratelimit.NewMemoryStore()
+	userID := uuid.New()
+
+	router := gin.New()
+	router.POST("/telemetry", func(c *gin.Context) {
+		if c.GetHeader("X-Test-User") != "" {
+			c.Set(string(UserIDKey), userID)
+		}
+		c.Next()
+	}, NewRateLimitMiddleware(store, "ingest", ratelimit.PerMinute(1, 1), KeyByUserOrIP), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(ip string, authenticated bool) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/telemetry", nil)
+		req.RemoteAddr = ip + ":1234"
+		if authenticated {
+			req.Header.Set("X-Test-User", "1")
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// The same user is limited across IPs
+	assert.Equal(t, http.StatusOK, send("10.0.0.1", true))
+	assert.Equal(t, http.StatusTooManyRequests, send("10.0.0.2", true))
+
+	// Anonymous callers fall back to per-IP buckets
+	assert.Equal(t, http.StatusOK, send("10.0.0.1", false))
+	assert.Equal(t, http.StatusTooManyRequests, send("10.0.0.1", false))
+}
+
+func TestRateLimitMiddleware_FailsOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", NewRateLimitMiddleware(failingStore{}, "test", ratelimit.PerMinute(1, 1), KeyByIP), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReloadableRateLimitMiddleware_UsesCurrentPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy := ratelimit.NewPolicyVar(ratelimit.PerMinute(1, 1))
+	router := gin.New()
+	router.POST("/login", NewReloadableRateLimitMiddleware(ratelimit.NewMemoryStore(), "login", policy, KeyByIP), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send("10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("10.0.0.1").Code)
+
+	// The new policy applies from the next request
+	policy.Set(ratelimit.PerMinute(10, 5))
+	w := send("10.0.0.2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "5", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "4", w.Header().Get("X-RateLimit-Remaining"))
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	send := func(headers SecurityHeaders) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(NewSecurityHeadersMiddleware(headers))
+		router.GET("/health", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+		return w
+	}
+
+	t.Run("sets configured headers", func(t *testing.T) {
+		w := send(SecurityHeaders{
+			HSTSMaxAge:            365 * 24 * time.Hour,
+			HSTSIncludeSubdomains: true,
+			FrameOptions:          "DENY",
+			ReferrerPolicy:        "no-referrer",
+			ContentSecurityPolicy: "frame-ancestors 'none'",
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "max-age=31536000; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+		assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+		assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+		assert.Equal(t, "frame-ancestors 'none'", w.Header().Get("Content-Security-Policy"))
+	})
+
+	t.Run("omits empty headers", func(t *testing.T) {
+		w := send(SecurityHeaders{})
+
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+		for _, header := range []string{"Strict-Transport-Security", "X-Frame-Options", "Referrer-Policy", "Content-Security-Policy"} {
+			assert.Empty(t, w.Header().Get(header), header)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+// TraceIDHeader is the response header carrying the request's trace ID when exposed
+const TraceIDHeader = "X-Trace-ID"
+
+// NewTracingMiddleware starts a server span for every request
+// Incoming W3C trace context is continued, and the span is named after the matched route.
+// When exposeTraceID is set (development only), the trace ID is returned in the X-Trace-ID
+// header and used as the "traceId" of problem responses, so failures can be looked up directly.
+func NewTracingMiddleware(tracer trace.Tracer, exposeTraceID bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method + " " + route
+		if route == "" {
+			name = c.Request.Method
+		}
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("http.request.id", c.GetString("RequestID")),
+			),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		if traceID := span.SpanContext().TraceID(); exposeTraceID && traceID.IsValid() {
+			c.Header(TraceIDHeader, traceID.String())
+			c.Set(problem.TraceIDKey, traceID.String())
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
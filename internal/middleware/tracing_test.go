@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/sebasr/avt-service/internal/problem"
+)
+
+func setupTracingRouter(exposeTraceID bool) (*gin.Engine, *tracetest.SpanRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	router := gin.New()
+	router.Use(NewTracingMiddleware(provider.Tracer("test"), exposeTraceID))
+	router.GET("/devices/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		problem.Abort(c, problem.Internal("Failed"))
+	})
+	return router, recorder
+}
+
+func TestTracingMiddleware_RecordsServerSpans(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator()) })
+	router, recorder := setupTracingRouter(false)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/devices/racebox-1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(TraceIDHeader), "trace IDs are only exposed in dev mode")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /devices/:id", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String(), "incoming trace context should be continued")
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	status, _ := attrs.Value("http.response.status_code")
+	assert.Equal(t, int64(http.StatusOK), status.AsInt64())
+}
+
+func TestTracingMiddleware_ExposesTraceIDOnErrors(t *testing.T) {
+	router, recorder := setupTracingRouter(true)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+	traceID := spans[0].SpanContext().TraceID().String()
+	assert.Equal(t, traceID, w.Header().Get(TraceIDHeader))
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, traceID, response["traceId"])
+	assert.Equal(t, "internal_error", response["code"])
+
+	// Successful bodies are left untouched
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/devices/racebox-1", nil))
+	assert.JSONEq(t, `{"id":"racebox-1"}`, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get(TraceIDHeader))
+}
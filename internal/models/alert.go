@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertMetric identifies the telemetry value an alert rule watches
+type AlertMetric string
+
+// Supported alert metrics
+const (
+	AlertMetricSpeed         AlertMetric = "speed"          // GPS speed in km/h; records without a valid fix are skipped
+	AlertMetricLateralG      AlertMetric = "lateral_g"      // Absolute g-force on the Y axis
+	AlertMetricLongitudinalG AlertMetric = "longitudinal_g" // Absolute g-force on the X axis, braking or accelerating
+	AlertMetricGForce        AlertMetric = "g_force"        // Combined horizontal g-force, like a session's peak g-force
+)
+
+// Alert rule limits
+const (
+	maxAlertSpeed    = 1000.0 // km/h
+	maxAlertGForce   = 20.0   // g
+	maxAlertCooldown = 7 * 24 * 60 * 60
+)
+
+// DefaultAlertCooldownSeconds is the cooldown of rules created without one
+const DefaultAlertCooldownSeconds = 300
+
+// Value returns the metric's value in a telemetry record, or false if the record has none
+func (m AlertMetric) Value(record *TelemetryData) (float64, bool) {
+	switch m {
+	case AlertMetricSpeed:
+		return record.GPS.Speed, record.GPS.IsFixValid
+	case AlertMetricLateralG:
+		return math.Abs(record.Motion.GForceY), true
+	case AlertMetricLongitudinalG:
+		return math.Abs(record.Motion.GForceX), true
+	case AlertMetricGForce:
+		return math.Hypot(record.Motion.GForceX, record.Motion.GForceY), true
+	}
+	return 0, false
+}
+
+// AlertRule raises an alert when a device's telemetry exceeds a threshold
+// After firing, a rule stays quiet until its cooldown has passed, so a sustained breach alerts once.
+type AlertRule struct {
+	ID              uuid.UUID   `json:"id" db:"id"`
+	UserID          uuid.UUID   `json:"userId" db:"user_id"`
+	DeviceID        string      `json:"deviceId" db:"device_id"`
+	Name            string      `json:"name" db:"name"`
+	Metric          AlertMetric `json:"metric" db:"metric"`
+	Threshold       float64     `json:"threshold" db:"threshold"` // Fires when the metric exceeds it, in km/h or g
+	CooldownSeconds int         `json:"cooldownSeconds" db:"cooldown_seconds"`
+	WebhookURL      *string     `json:"webhookUrl,omitempty" db:"webhook_url"` // POST alerts to this URL
+	IsActive        bool        `json:"isActive" db:"is_active"`
+	LastTriggeredAt *time.Time  `json:"lastTriggeredAt,omitempty" db:"last_triggered_at"` // Timestamp of the telemetry that last fired the rule
+	CreatedAt       time.Time   `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time   `json:"updatedAt" db:"updated_at"`
+}
+
+// Validate checks the rule's metric, threshold, cooldown and webhook URL
+func (r *AlertRule) Validate() error {
+	switch r.Metric {
+	case AlertMetricSpeed:
+		if r.Threshold <= 0 || r.Threshold > maxAlertSpeed {
+			return fmt.Errorf("threshold must be between 0 and %.0f km/h", maxAlertSpeed)
+		}
+	case AlertMetricLateralG, AlertMetricLongitudinalG, AlertMetricGForce:
+		if r.Threshold <= 0 || r.Threshold > maxAlertGForce {
+			return fmt.Errorf("threshold must be between 0 and %.0f g", maxAlertGForce)
+		}
+	default:
+		return fmt.Errorf("metric must be one of: speed, lateral_g, longitudinal_g, g_force")
+	}
+
+	if r.CooldownSeconds < 0 || r.CooldownSeconds > maxAlertCooldown {
+		return fmt.Errorf("cooldownSeconds must be between 0 and %d", maxAlertCooldown)
+	}
+
+	if r.WebhookURL != nil {
+		u, err := url.Parse(*r.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhookUrl must be an absolute http or https URL")
+		}
+	}
+
+	return nil
+}
+
+// Cooldown returns how long the rule stays quiet after firing
+func (r *AlertRule) Cooldown() time.Duration {
+	return time.Duration(r.CooldownSeconds) * time.Second
+}
+
+// Ready reports whether the rule may fire for telemetry recorded at t
+// Telemetry recorded before the rule last fired, e.g. from a late upload, counts as within the cooldown.
+func (r *AlertRule) Ready(t time.Time) bool {
+	return r.LastTriggeredAt == nil || !t.Before(r.LastTriggeredAt.Add(r.Cooldown()))
+}
+
+// IsOwnedBy checks if the rule belongs to the given user
+func (r *AlertRule) IsOwnedBy(userID uuid.UUID) bool {
+	return r.UserID == userID
+}
+
+// AlertEvent records an alert rule firing on a telemetry record
+// The rule's metric and threshold are copied, so the history stays accurate after the rule is edited.
+type AlertEvent struct {
+	ID         int64       `json:"id" db:"id"`
+	RuleID     uuid.UUID   `json:"ruleId" db:"rule_id"`
+	UserID     uuid.UUID   `json:"userId" db:"user_id"`
+	DeviceID   string      `json:"deviceId" db:"device_id"`
+	SessionID  *string     `json:"sessionId,omitempty" db:"session_id"`
+	Metric     AlertMetric `json:"metric" db:"metric"`
+	Threshold  float64     `json:"threshold" db:"threshold"`
+	Value      float64     `json:"value" db:"value"`
+	RecordedAt time.Time   `json:"recordedAt" db:"recorded_at"` // Timestamp of the telemetry that fired the rule
+	Latitude   *float64    `json:"latitude,omitempty" db:"latitude"`
+	Longitude  *float64    `json:"longitude,omitempty" db:"longitude"`
+	CreatedAt  time.Time   `json:"createdAt" db:"created_at"`
+}
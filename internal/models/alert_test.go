@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertMetric_Value(t *testing.T) {
+	record := &TelemetryData{
+		GPS:    GpsData{Speed: 182, IsFixValid: true},
+		Motion: MotionData{GForceX: -0.6, GForceY: 0.8},
+	}
+
+	for metric, want := range map[AlertMetric]float64{
+		AlertMetricSpeed:         182,
+		AlertMetricLateralG:      0.8,
+		AlertMetricLongitudinalG: 0.6,
+		AlertMetricGForce:        1.0,
+	} {
+		value, ok := metric.Value(record)
+		assert.True(t, ok, metric)
+		assert.InDelta(t, want, value, 1e-9, metric)
+	}
+
+	record.GPS.IsFixValid = false
+	_, ok := AlertMetricSpeed.Value(record)
+	assert.False(t, ok, "speed needs a valid fix")
+}
+
+func TestAlertRule_Validate(t *testing.T) {
+	webhook := "ftp://example.com/hook"
+	tests := []struct {
+		name    string
+		rule    AlertRule
+		wantErr string
+	}{
+		{name: "speed", rule: AlertRule{Metric: AlertMetricSpeed, Threshold: 180, CooldownSeconds: 300}},
+		{name: "lateral g", rule: AlertRule{Metric: AlertMetricLateralG, Threshold: 1.3}},
+		{name: "unknown metric", rule: AlertRule{Metric: "rpm", Threshold: 7000}, wantErr: "metric must be one of"},
+		{name: "speed out of range", rule: AlertRule{Metric: AlertMetricSpeed, Threshold: 1500}, wantErr: "threshold must be between 0 and 1000 km/h"},
+		{name: "g-force out of range", rule: AlertRule{Metric: AlertMetricGForce, Threshold: 0}, wantErr: "threshold must be between 0 and 20 g"},
+		{name: "negative cooldown", rule: AlertRule{Metric: AlertMetricSpeed, Threshold: 180, CooldownSeconds: -1}, wantErr: "cooldownSeconds"},
+		{name: "webhook scheme", rule: AlertRule{Metric: AlertMetricSpeed, Threshold: 180, WebhookURL: &webhook}, wantErr: "webhookUrl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAlertRule_Ready(t *testing.T) {
+	rule := AlertRule{CooldownSeconds: 60}
+	now := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	assert.True(t, rule.Ready(now), "a rule that never fired is ready")
+
+	rule.LastTriggeredAt = &now
+	assert.False(t, rule.Ready(now.Add(59*time.Second)))
+	assert.True(t, rule.Ready(now.Add(time.Minute)))
+	assert.False(t, rule.Ready(now.Add(-time.Hour)), "telemetry from before the last alert stays quiet")
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchiveChunk is one device's telemetry for one UTC day, the unit moved to object storage
+type ArchiveChunk struct {
+	DeviceID string
+	Day      time.Time // Midnight UTC
+	Rows     int64
+}
+
+// End returns the exclusive end of the chunk's day
+func (c ArchiveChunk) End() time.Time {
+	return c.Day.AddDate(0, 0, 1)
+}
+
+// TelemetryArchive is the manifest of a Parquet file of archived telemetry
+type TelemetryArchive struct {
+	ID              uuid.UUID   `json:"id" db:"id"`
+	DeviceID        string      `json:"deviceId" db:"device_id"`
+	Day             time.Time   `json:"day" db:"day"`
+	ObjectKey       string      `json:"objectKey" db:"object_key"`
+	Regions         []string    `json:"regions" db:"regions"` // Regions of the buckets holding a copy
+	UserIDs         []uuid.UUID `json:"-" db:"user_ids"`      // Owners of the archived records
+	RowCount        int64       `json:"rowCount" db:"row_count"`
+	SizeBytes       int64       `json:"sizeBytes" db:"size_bytes"`
+	FirstRecordedAt time.Time   `json:"firstRecordedAt" db:"first_recorded_at"`
+	LastRecordedAt  time.Time   `json:"lastRecordedAt" db:"last_recorded_at"`
+	ArchivedAt      time.Time   `json:"archivedAt" db:"archived_at"`
+
+	// MaxID is the highest telemetry ID archived; records of the day stored later are left in place
+	MaxID int64 `json:"-" db:"-"`
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/geo"
+)
+
+// maxAreaVertices bounds the positions of an area polygon across all of its rings
+const maxAreaVertices = 1000
+
+// GeoPolygon is a GeoJSON Polygon: an outer ring followed by optional holes
+// Positions are [longitude, latitude] in degrees and every ring is closed.
+type GeoPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+// ParseGeoPolygon parses and validates a GeoJSON Polygon geometry
+// Altitudes are dropped. Polygons spanning 180 degrees of longitude or more are rejected, as their
+// edges are ambiguous; areas crossing the antimeridian must be requested as two polygons.
+func ParseGeoPolygon(value string) (GeoPolygon, error) {
+	var raw struct {
+		Type        string        `json:"type"`
+		Coordinates [][][]float64 `json:"coordinates"`
+	}
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return GeoPolygon{}, errors.New("polygon must be a GeoJSON Polygon geometry")
+	}
+	if raw.Type != "Polygon" {
+		return GeoPolygon{}, errors.New(`polygon type must be "Polygon"`)
+	}
+	if len(raw.Coordinates) == 0 {
+		return GeoPolygon{}, errors.New("polygon must have an outer ring")
+	}
+
+	polygon := GeoPolygon{Type: "Polygon", Coordinates: make([][][2]float64, len(raw.Coordinates))}
+	vertices := 0
+	for i, ring := range raw.Coordinates {
+		if len(ring) < 4 {
+			return GeoPolygon{}, fmt.Errorf("polygon ring %d must have at least 4 positions", i)
+		}
+		vertices += len(ring)
+		if vertices > maxAreaVertices {
+			return GeoPolygon{}, fmt.Errorf("polygon must have at most %d positions", maxAreaVertices)
+		}
+
+		polygon.Coordinates[i] = make([][2]float64, len(ring))
+		for j, position := range ring {
+			if len(position) < 2 {
+				return GeoPolygon{}, fmt.Errorf("polygon ring %d position %d must be [longitude, latitude]", i, j)
+			}
+			point := geo.Point{Longitude: position[0], Latitude: position[1]}
+			if math.IsNaN(point.Longitude) || math.IsNaN(point.Latitude) || !point.IsValid() {
+				return GeoPolygon{}, fmt.Errorf("polygon ring %d position %d is out of range", i, j)
+			}
+			polygon.Coordinates[i][j] = [2]float64{point.Longitude, point.Latitude}
+		}
+		if polygon.Coordinates[i][0] != polygon.Coordinates[i][len(ring)-1] {
+			return GeoPolygon{}, fmt.Errorf("polygon ring %d must end at its first position", i)
+		}
+	}
+
+	if bounds := polygon.Bounds(); bounds.East-bounds.West >= 180 {
+		return GeoPolygon{}, errors.New("polygon must span less than 180 degrees of longitude")
+	}
+	return polygon, nil
+}
+
+// Bounds returns the bounding box of the outer ring
+func (p GeoPolygon) Bounds() BoundingBox {
+	box := BoundingBox{West: 180, South: 90, East: -180, North: -90}
+	for _, position := range p.Coordinates[0] {
+		box.West = math.Min(box.West, position[0])
+		box.East = math.Max(box.East, position[0])
+		box.South = math.Min(box.South, position[1])
+		box.North = math.Max(box.North, position[1])
+	}
+	return box
+}
+
+// Contains reports whether a point lies inside the outer ring and outside every hole
+// Edges are straight lines in a local projection, as for polygon geofences.
+func (p GeoPolygon) Contains(latitude, longitude float64) bool {
+	point := geo.Point{Latitude: latitude, Longitude: longitude}
+	for i, ring := range p.Coordinates {
+		vertices := make([]geo.Point, len(ring)-1)
+		for j, position := range ring[:len(ring)-1] {
+			vertices[j] = geo.Point{Longitude: position[0], Latitude: position[1]}
+		}
+		if inside := geo.PolygonContains(vertices, point); inside != (i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// GeoJSON encodes the polygon as a GeoJSON geometry
+func (p GeoPolygon) GeoJSON() string {
+	encoded, _ := json.Marshal(p)
+	return string(encoded)
+}
+
+// AreaStats summarizes the telemetry recorded within an area
+type AreaStats struct {
+	Points          int64      `json:"points"`
+	Devices         int64      `json:"devices"`
+	Sessions        int64      `json:"sessions"`
+	FirstRecordedAt *time.Time `json:"firstRecordedAt,omitempty"` // Nil when no points match
+	LastRecordedAt  *time.Time `json:"lastRecordedAt,omitempty"`
+	AvgSpeed        float64    `json:"avgSpeed"` // km/h
+	MaxSpeed        float64    `json:"maxSpeed"` // km/h
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spaPaddock is a square around the Spa-Francorchamps paddock with a hole over the pit building
+const spaPaddock = `{"type":"Polygon","coordinates":[
+	[[5.96,50.43],[5.98,50.43],[5.98,50.45],[5.96,50.45],[5.96,50.43]],
+	[[5.965,50.435],[5.975,50.435],[5.975,50.445],[5.965,50.445],[5.965,50.435]]
+]}`
+
+func TestParseGeoPolygon(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "with hole", value: spaPaddock},
+		{name: "altitudes dropped", value: `{"type":"Polygon","coordinates":[[[5.96,50.43,400],[5.98,50.43,400],[5.97,50.45,400],[5.96,50.43,400]]]}`},
+		{name: "not json", value: "5.96,50.43", wantErr: true},
+		{name: "not a polygon", value: `{"type":"Point","coordinates":[5.96,50.43]}`, wantErr: true},
+		{name: "no rings", value: `{"type":"Polygon","coordinates":[]}`, wantErr: true},
+		{name: "too few positions", value: `{"type":"Polygon","coordinates":[[[5.96,50.43],[5.98,50.43],[5.96,50.43]]]}`, wantErr: true},
+		{name: "not closed", value: `{"type":"Polygon","coordinates":[[[5.96,50.43],[5.98,50.43],[5.97,50.45],[5.96,50.44]]]}`, wantErr: true},
+		{name: "out of range", value: `{"type":"Polygon","coordinates":[[[5.96,91],[5.98,50.43],[5.97,50.45],[5.96,91]]]}`, wantErr: true},
+		{name: "missing latitude", value: `{"type":"Polygon","coordinates":[[[5.96],[5.98,50.43],[5.97,50.45],[5.96]]]}`, wantErr: true},
+		{name: "half the world", value: `{"type":"Polygon","coordinates":[[[-90,0],[90,0],[90,10],[-90,0]]]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polygon, err := ParseGeoPolygon(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Polygon", polygon.Type)
+			assert.NotEmpty(t, polygon.Coordinates)
+		})
+	}
+}
+
+func TestGeoPolygon_Contains(t *testing.T) {
+	polygon, err := ParseGeoPolygon(spaPaddock)
+	require.NoError(t, err)
+
+	assert.Equal(t, BoundingBox{West: 5.96, South: 50.43, East: 5.98, North: 50.45}, polygon.Bounds())
+	assert.True(t, polygon.Contains(50.432, 5.962), "inside the outer ring")
+	assert.False(t, polygon.Contains(50.44, 5.97), "inside the hole")
+	assert.False(t, polygon.Contains(50.46, 5.97), "outside")
+
+	// The encoded geometry round-trips
+	decoded, err := ParseGeoPolygon(polygon.GeoJSON())
+	require.NoError(t, err)
+	assert.Equal(t, polygon, decoded)
+}
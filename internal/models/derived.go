@@ -0,0 +1,45 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/geo"
+)
+
+// MaxDerivedGap is the longest gap to a device's previous record that deltas are derived over
+// Records after a longer gap, like the first record of a device, only get TotalG.
+const MaxDerivedGap = 5 * time.Minute
+
+// DerivedChannels are computed from a record and the previous record of its device, so analytics
+// read them instead of recomputing them on every query
+type DerivedChannels struct {
+	TotalG            *float64 `json:"totalG,omitempty" db:"total_g"`                       // Magnitude of the g-force vector
+	SpeedDelta        *float64 `json:"speedDelta,omitempty" db:"speed_delta"`               // km/h gained since the previous record
+	VerticalSpeed     *float64 `json:"verticalSpeed,omitempty" db:"vertical_speed"`         // m/s of MSL altitude gained since the previous record
+	DistanceIncrement *float64 `json:"distanceIncrement,omitempty" db:"distance_increment"` // Meters travelled since the previous record
+}
+
+// DeriveChannels sets the record's derived channels
+// prev is the device's previous record, or nil when it is unknown. Deltas are left unset when prev
+// is not earlier than the record or more than MaxDerivedGap before it.
+func (t *TelemetryData) DeriveChannels(prev *TelemetryData) {
+	totalG := math.Sqrt(t.Motion.GForceX*t.Motion.GForceX + t.Motion.GForceY*t.Motion.GForceY + t.Motion.GForceZ*t.Motion.GForceZ)
+	t.Derived = &DerivedChannels{TotalG: &totalG}
+
+	if prev == nil {
+		return
+	}
+	gap := t.Timestamp.Sub(prev.Timestamp)
+	if gap <= 0 || gap > MaxDerivedGap {
+		return
+	}
+
+	speedDelta := t.GPS.Speed - prev.GPS.Speed
+	verticalSpeed := (t.GPS.MslAltitude - prev.GPS.MslAltitude) / gap.Seconds()
+	distance := geo.Distance(
+		geo.Point{Latitude: prev.GPS.Latitude, Longitude: prev.GPS.Longitude},
+		geo.Point{Latitude: t.GPS.Latitude, Longitude: t.GPS.Longitude},
+	)
+	t.Derived.SpeedDelta, t.Derived.VerticalSpeed, t.Derived.DistanceIncrement = &speedDelta, &verticalSpeed, &distance
+}
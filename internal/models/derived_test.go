@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryData_DeriveChannels(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	prev := &TelemetryData{
+		Timestamp: start,
+		GPS:       GpsData{Latitude: 50.0, Longitude: 5.0, Speed: 100, MslAltitude: 400},
+	}
+
+	tests := []struct {
+		name       string
+		prev       *TelemetryData
+		offset     time.Duration
+		wantDeltas bool
+	}{
+		{name: "next record", prev: prev, offset: 2 * time.Second, wantDeltas: true},
+		{name: "first record", prev: nil, offset: 2 * time.Second},
+		{name: "after a long gap", prev: prev, offset: MaxDerivedGap + time.Second},
+		{name: "out of order", prev: prev, offset: -time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &TelemetryData{
+				Timestamp: start.Add(tt.offset),
+				GPS:       GpsData{Latitude: 50.001, Longitude: 5.0, Speed: 110, MslAltitude: 404},
+				Motion:    MotionData{GForceX: 0.3, GForceY: 0.4, GForceZ: 1.2},
+			}
+			record.DeriveChannels(tt.prev)
+
+			require.NotNil(t, record.Derived)
+			require.NotNil(t, record.Derived.TotalG)
+			assert.InDelta(t, 1.3, *record.Derived.TotalG, 1e-9)
+			if !tt.wantDeltas {
+				assert.Nil(t, record.Derived.SpeedDelta)
+				assert.Nil(t, record.Derived.VerticalSpeed)
+				assert.Nil(t, record.Derived.DistanceIncrement)
+				return
+			}
+			assert.InDelta(t, 10, *record.Derived.SpeedDelta, 1e-9)
+			assert.InDelta(t, 2, *record.Derived.VerticalSpeed, 1e-9)
+			assert.InDelta(t, 111.2, *record.Derived.DistanceIncrement, 0.1)
+		})
+	}
+}
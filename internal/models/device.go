@@ -12,6 +12,7 @@ type Device struct {
 	ID          uuid.UUID              `json:"id" db:"id"`
 	DeviceID    string                 `json:"deviceId" db:"device_id"`                 // Hardware device ID
 	UserID      uuid.UUID              `json:"userId" db:"user_id"`                     // Owner of the device
+	OrgID       *uuid.UUID             `json:"orgId,omitempty" db:"org_id"`             // Organization the device is shared with
 	DeviceName  *string                `json:"deviceName,omitempty" db:"device_name"`   // User-friendly name
 	DeviceModel *string                `json:"deviceModel,omitempty" db:"device_model"` // e.g., "Mini S", "Micro"
 	ClaimedAt   time.Time              `json:"claimedAt" db:"claimed_at"`               // When the device was claimed
@@ -20,6 +21,13 @@ type Device struct {
 	Metadata    map[string]interface{} `json:"metadata,omitempty" db:"metadata"`        // Additional device info (JSONB)
 	CreatedAt   time.Time              `json:"createdAt" db:"created_at"`
 	UpdatedAt   time.Time              `json:"updatedAt" db:"updated_at"`
+
+	// FirmwareVersion is the last version reported in X-Firmware-Version, normalized by FirmwareVersion.String
+	FirmwareVersion   *string    `json:"firmwareVersion,omitempty" db:"firmware_version"`
+	FirmwareUpdatedAt *time.Time `json:"firmwareUpdatedAt,omitempty" db:"firmware_updated_at"` // When the device first reported it
+
+	// RequireSignedUploads rejects uploads authenticated with the device's API keys unless they carry a valid X-Signature
+	RequireSignedUploads bool `json:"requireSignedUploads" db:"require_signed_uploads"`
 }
 
 // MetadataJSON returns the metadata as a JSON string for database storage
@@ -46,14 +54,34 @@ func (d *Device) SetMetadataFromJSON(jsonStr string) error {
 	return json.Unmarshal([]byte(jsonStr), &d.Metadata)
 }
 
-// IsOnline checks if the device has been seen recently (within the last hour)
+// DeviceOnlineWindow is how recently a device must have been seen to count as online
+const DeviceOnlineWindow = time.Hour
+
+// IsOnline checks if the device has been seen recently (within DeviceOnlineWindow)
 func (d *Device) IsOnline() bool {
 	if d.LastSeenAt == nil {
 		return false
 	}
 
-	// Consider device online if it was seen within the last hour
-	return time.Since(*d.LastSeenAt) < time.Hour
+	return time.Since(*d.LastSeenAt) < DeviceOnlineWindow
+}
+
+// Device presence event types
+const (
+	DeviceEventOnline  = "online"
+	DeviceEventOffline = "offline"
+)
+
+// DevicePresenceEvent reports a device coming online or going offline
+// A device goes offline once it has not been seen for DeviceOnlineWindow.
+type DevicePresenceEvent struct {
+	Type       string     `json:"type"`     // online or offline
+	ID         uuid.UUID  `json:"id"`       // Device record ID
+	DeviceID   string     `json:"deviceId"` // Hardware device ID
+	UserID     uuid.UUID  `json:"userId"`
+	OrgID      *uuid.UUID `json:"orgId,omitempty"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	At         time.Time  `json:"at"` // When the transition was detected
 }
 
 // DeviceResponse represents a device for API responses
@@ -61,6 +89,7 @@ type DeviceResponse struct {
 	ID          uuid.UUID              `json:"id"`
 	DeviceID    string                 `json:"deviceId"`
 	UserID      uuid.UUID              `json:"userId"`
+	OrgID       *uuid.UUID             `json:"orgId,omitempty"`
 	DeviceName  *string                `json:"deviceName,omitempty"`
 	DeviceModel *string                `json:"deviceModel,omitempty"`
 	ClaimedAt   time.Time              `json:"claimedAt"`
@@ -70,6 +99,9 @@ type DeviceResponse struct {
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 	CreatedAt   time.Time              `json:"createdAt"`
 	UpdatedAt   time.Time              `json:"updatedAt"`
+
+	FirmwareVersion   *string    `json:"firmwareVersion,omitempty"`
+	FirmwareUpdatedAt *time.Time `json:"firmwareUpdatedAt,omitempty"`
 }
 
 // ToResponse converts a Device to a DeviceResponse
@@ -78,6 +110,7 @@ func (d *Device) ToResponse() *DeviceResponse {
 		ID:          d.ID,
 		DeviceID:    d.DeviceID,
 		UserID:      d.UserID,
+		OrgID:       d.OrgID,
 		DeviceName:  d.DeviceName,
 		DeviceModel: d.DeviceModel,
 		ClaimedAt:   d.ClaimedAt,
@@ -87,5 +120,8 @@ func (d *Device) ToResponse() *DeviceResponse {
 		Metadata:    d.Metadata,
 		CreatedAt:   d.CreatedAt,
 		UpdatedAt:   d.UpdatedAt,
+
+		FirmwareVersion:   d.FirmwareVersion,
+		FirmwareUpdatedAt: d.FirmwareUpdatedAt,
 	}
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceAPIKey represents a long-lived credential a device uses to upload telemetry
+type DeviceAPIKey struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	DeviceID      uuid.UUID  `json:"deviceId" db:"device_id"`                // Device UUID (devices.id)
+	UserID        uuid.UUID  `json:"userId" db:"user_id"`                    // Owner of the device at mint time
+	Name          *string    `json:"name,omitempty" db:"name"`               // User-friendly label
+	KeyPrefix     string     `json:"keyPrefix" db:"key_prefix"`              // Leading characters of the key for identification
+	KeyHash       string     `json:"-" db:"key_hash"`                        // Never expose in JSON - stored as SHA256 hash
+	SigningSecret string     `json:"-" db:"signing_secret"`                  // Never expose in JSON - sealed upload signing secret; empty for older keys
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`              // When the key was minted
+	LastUsedAt    *time.Time `json:"lastUsedAt,omitempty" db:"last_used_at"` // Last successful authentication
+	RevokedAt     *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`    // When the key was revoked
+}
+
+// IsRevoked checks if the key has been revoked
+func (k *DeviceAPIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// DeviceAPIKeyResponse represents a device API key for API responses
+type DeviceAPIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	DeviceID   uuid.UUID  `json:"deviceId"`
+	Name       *string    `json:"name,omitempty"`
+	KeyPrefix  string     `json:"keyPrefix"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	IsActive   bool       `json:"isActive"`
+}
+
+// ToResponse converts a DeviceAPIKey to a DeviceAPIKeyResponse (safe for API)
+func (k *DeviceAPIKey) ToResponse() *DeviceAPIKeyResponse {
+	return &DeviceAPIKeyResponse{
+		ID:         k.ID,
+		DeviceID:   k.DeviceID,
+		Name:       k.Name,
+		KeyPrefix:  k.KeyPrefix,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		IsActive:   !k.IsRevoked(),
+	}
+}
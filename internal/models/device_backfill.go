@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackfillStatus is the lifecycle state of a device backfill
+type BackfillStatus string
+
+const (
+	// BackfillPending backfills are queued and have not started
+	BackfillPending BackfillStatus = "pending"
+	// BackfillProcessing backfills are assigning records
+	BackfillProcessing BackfillStatus = "processing"
+	// BackfillCompleted backfills have assigned every anonymous record
+	BackfillCompleted BackfillStatus = "completed"
+	// BackfillFailed backfills stopped before assigning every record; see Error
+	BackfillFailed BackfillStatus = "failed"
+)
+
+// IsFinished checks if the backfill has reached a terminal state
+func (s BackfillStatus) IsFinished() bool {
+	return s == BackfillCompleted || s == BackfillFailed
+}
+
+// DeviceBackfill tracks the background assignment of a claimed device's anonymous telemetry,
+// sessions and upload batches to the device's owner
+type DeviceBackfill struct {
+	ID              uuid.UUID      `json:"id" db:"id"`
+	DeviceID        uuid.UUID      `json:"deviceId" db:"device_id"`
+	RequestedBy     *uuid.UUID     `json:"requestedBy,omitempty" db:"requested_by"` // Null once the account is deleted
+	Status          BackfillStatus `json:"status" db:"status"`
+	TotalRecords    int64          `json:"totalRecords" db:"total_records"` // Anonymous records found when the backfill started
+	AssignedRecords int64          `json:"assignedRecords" db:"assigned_records"`
+	Error           *string        `json:"error,omitempty" db:"error"`
+	CreatedAt       time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updatedAt" db:"updated_at"`
+	CompletedAt     *time.Time     `json:"completedAt,omitempty" db:"completed_at"`
+}
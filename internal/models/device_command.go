@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceCommandType is a remote action a device can be asked to perform
+type DeviceCommandType string
+
+const (
+	// DeviceCommandStartSession starts recording a session
+	DeviceCommandStartSession DeviceCommandType = "start_session"
+	// DeviceCommandStopSession stops recording the current session
+	DeviceCommandStopSession DeviceCommandType = "stop_session"
+	// DeviceCommandFlushBuffer uploads buffered telemetry without waiting for the upload interval
+	DeviceCommandFlushBuffer DeviceCommandType = "flush_buffer"
+	// DeviceCommandReboot restarts the device
+	DeviceCommandReboot DeviceCommandType = "reboot"
+)
+
+// IsValid checks if the command type is supported
+func (t DeviceCommandType) IsValid() bool {
+	switch t {
+	case DeviceCommandStartSession, DeviceCommandStopSession, DeviceCommandFlushBuffer, DeviceCommandReboot:
+		return true
+	}
+	return false
+}
+
+// DeviceCommandStatus is the lifecycle state of a device command
+type DeviceCommandStatus string
+
+const (
+	// DeviceCommandPending commands have not been polled by the device yet
+	DeviceCommandPending DeviceCommandStatus = "pending"
+	// DeviceCommandDelivered commands were polled but not acknowledged, and are returned again on the next poll
+	DeviceCommandDelivered DeviceCommandStatus = "delivered"
+	// DeviceCommandSucceeded commands were carried out by the device
+	DeviceCommandSucceeded DeviceCommandStatus = "succeeded"
+	// DeviceCommandFailed commands could not be carried out; see Message
+	DeviceCommandFailed DeviceCommandStatus = "failed"
+	// DeviceCommandExpired commands were not acknowledged before they expired
+	DeviceCommandExpired DeviceCommandStatus = "expired"
+	// DeviceCommandCancelled commands were withdrawn by a user before being acknowledged
+	DeviceCommandCancelled DeviceCommandStatus = "cancelled"
+)
+
+// IsFinished checks if the command has reached a terminal state
+func (s DeviceCommandStatus) IsFinished() bool {
+	return s != DeviceCommandPending && s != DeviceCommandDelivered
+}
+
+// DeviceCommand is a remote action queued for a device
+type DeviceCommand struct {
+	ID             uuid.UUID              `json:"id" db:"id"`
+	DeviceID       uuid.UUID              `json:"deviceId" db:"device_id"` // Device record ID
+	Type           DeviceCommandType      `json:"type" db:"type"`
+	Params         map[string]interface{} `json:"params,omitempty" db:"params"` // Type-specific arguments passed to the device as-is
+	Status         DeviceCommandStatus    `json:"status" db:"status"`
+	Message        string                 `json:"message,omitempty" db:"message"`
+	CreatedBy      *uuid.UUID             `json:"createdBy,omitempty" db:"created_by"` // Null once the account is deleted
+	CreatedAt      time.Time              `json:"createdAt" db:"created_at"`
+	ExpiresAt      time.Time              `json:"expiresAt" db:"expires_at"`
+	DeliveredAt    *time.Time             `json:"deliveredAt,omitempty" db:"delivered_at"`
+	AcknowledgedAt *time.Time             `json:"acknowledgedAt,omitempty" db:"acknowledged_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceConfig is the configuration a device should apply, set by its owner and polled by the device
+type DeviceConfig struct {
+	DeviceID              uuid.UUID  `json:"deviceId" db:"device_id"`                            // Device record ID
+	SampleRateHz          int        `json:"sampleRateHz" db:"sample_rate_hz"`                   // GNSS and IMU sampling rate
+	UploadIntervalSeconds int        `json:"uploadIntervalSeconds" db:"upload_interval_seconds"` // How often buffered telemetry is uploaded
+	Version               int64      `json:"version" db:"version"`                               // Incremented on every change
+	UpdatedBy             *uuid.UUID `json:"updatedBy,omitempty" db:"updated_by"`                // Null once the account is deleted
+	UpdatedAt             time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// ETag returns the strong entity tag identifying this version of the configuration
+func (c *DeviceConfig) ETag() string {
+	return `"` + strconv.FormatInt(c.Version, 10) + `"`
+}
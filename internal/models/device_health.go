@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceHealthEventType identifies the kind of device health alert
+type DeviceHealthEventType string
+
+// Supported device health event types
+const (
+	DeviceHealthLowBattery DeviceHealthEventType = "low_battery"
+	DeviceHealthNoFix      DeviceHealthEventType = "no_fix"
+)
+
+// DeviceHealthState is the latest known health of a device
+// The alerted flags keep an alert from firing again until the condition clears.
+type DeviceHealthState struct {
+	DeviceID          string     `json:"deviceId" db:"device_id"`
+	UserID            uuid.UUID  `json:"-" db:"user_id"`
+	Battery           float64    `json:"battery" db:"battery"`
+	IsCharging        bool       `json:"isCharging" db:"is_charging"`
+	LastRecordedAt    time.Time  `json:"lastRecordedAt" db:"last_recorded_at"`
+	LastFixAt         *time.Time `json:"lastFixAt,omitempty" db:"last_fix_at"`
+	NoFixSince        *time.Time `json:"noFixSince,omitempty" db:"no_fix_since"` // Start of the current run without a GPS fix
+	LowBatteryAlerted bool       `json:"lowBatteryAlerted" db:"low_battery_alerted"`
+	NoFixAlerted      bool       `json:"noFixAlerted" db:"no_fix_alerted"`
+	SignalDbm         *int       `json:"signalDbm,omitempty" db:"signal_dbm"` // Reported by heartbeats only
+	LastHeartbeatAt   *time.Time `json:"lastHeartbeatAt,omitempty" db:"last_heartbeat_at"`
+	UpdatedAt         time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// DeviceHealthSnapshot aggregates a device's health over a fixed time bucket
+// Sums are stored instead of averages so snapshots from separate uploads can be merged.
+type DeviceHealthSnapshot struct {
+	DeviceID              string    `json:"deviceId" db:"device_id"`
+	BucketStart           time.Time `json:"bucketStart" db:"bucket_start"`
+	Samples               int       `json:"samples" db:"samples"`
+	FixSamples            int       `json:"fixSamples" db:"fix_samples"`
+	BatteryMin            float64   `json:"batteryMin" db:"battery_min"`
+	BatteryMax            float64   `json:"batteryMax" db:"battery_max"`
+	BatteryLast           float64   `json:"batteryLast" db:"battery_last"`
+	IsCharging            bool      `json:"isCharging" db:"is_charging"`
+	SatellitesSum         int64     `json:"-" db:"satellites_sum"`
+	HorizontalAccuracySum float64   `json:"-" db:"horizontal_accuracy_sum"` // Fix samples only
+	SkewSamples           int       `json:"-" db:"skew_samples"`            // Samples with a clock skew estimate
+	SkewSumMs             int64     `json:"-" db:"skew_sum_ms"`
+	SkewAbsMaxMs          int64     `json:"-" db:"skew_abs_max_ms"`
+	SkewedSamples         int       `json:"skewedSamples" db:"skewed_samples"` // Samples flagged or corrected for clock skew
+	LastRecordedAt        time.Time `json:"lastRecordedAt" db:"last_recorded_at"`
+}
+
+// FixRatio returns the fraction of samples in the bucket that had a valid GPS fix
+func (s *DeviceHealthSnapshot) FixRatio() float64 {
+	if s.Samples == 0 {
+		return 0
+	}
+	return float64(s.FixSamples) / float64(s.Samples)
+}
+
+// AvgSatellites returns the mean number of satellites across all samples in the bucket
+func (s *DeviceHealthSnapshot) AvgSatellites() float64 {
+	if s.Samples == 0 {
+		return 0
+	}
+	return float64(s.SatellitesSum) / float64(s.Samples)
+}
+
+// AvgHorizontalAccuracy returns the mean horizontal accuracy of the samples with a fix, in meters
+func (s *DeviceHealthSnapshot) AvgHorizontalAccuracy() float64 {
+	if s.FixSamples == 0 {
+		return 0
+	}
+	return s.HorizontalAccuracySum / float64(s.FixSamples)
+}
+
+// AvgClockSkewMs returns the mean clock skew of the samples with an estimate, in milliseconds
+func (s *DeviceHealthSnapshot) AvgClockSkewMs() float64 {
+	if s.SkewSamples == 0 {
+		return 0
+	}
+	return float64(s.SkewSumMs) / float64(s.SkewSamples)
+}
+
+// DeviceHealthEvent records a device crossing a health alert threshold
+type DeviceHealthEvent struct {
+	ID         int64                 `json:"id" db:"id"`
+	DeviceID   string                `json:"deviceId" db:"device_id"`
+	UserID     uuid.UUID             `json:"userId" db:"user_id"`
+	Type       DeviceHealthEventType `json:"type" db:"event_type"`
+	RecordedAt time.Time             `json:"recordedAt" db:"recorded_at"` // Timestamp of the telemetry that triggered the event
+	Battery    float64               `json:"battery" db:"battery"`
+	NoFixSince *time.Time            `json:"noFixSince,omitempty" db:"no_fix_since"` // no_fix events only
+	CreatedAt  time.Time             `json:"createdAt" db:"created_at"`
+}
+
+// DeviceHeartbeat is a status report a device sends without telemetry, so devices without a GPS
+// fix still report their battery and appear online
+type DeviceHeartbeat struct {
+	DeviceID        string    `json:"deviceId"`
+	UserID          uuid.UUID `json:"-"`
+	Battery         float64   `json:"battery"`
+	IsCharging      bool      `json:"isCharging"`
+	SignalDbm       *int      `json:"signalDbm,omitempty"`
+	FirmwareVersion string    `json:"firmwareVersion,omitempty"`
+	ReceivedAt      time.Time `json:"receivedAt"` // Server time; heartbeats carry no device timestamp
+}
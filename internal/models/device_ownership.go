@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OwnershipAction identifies how a device changed owner
+type OwnershipAction string
+
+// Device ownership changes
+const (
+	OwnershipActionClaim    OwnershipAction = "claim"    // First upload from an unknown device
+	OwnershipActionAdopt    OwnershipAction = "adopt"    // Adoption of a pre-registered device with its claim code
+	OwnershipActionReassign OwnershipAction = "reassign" // Transfer by an admin
+)
+
+// DeviceOwnershipChange is an entry of a device's ownership history
+type DeviceOwnershipChange struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	DeviceID   uuid.UUID       `json:"deviceId" db:"device_id"`
+	Action     OwnershipAction `json:"action" db:"action"`
+	FromUserID *uuid.UUID      `json:"fromUserId,omitempty" db:"from_user_id"` // Null for the first owner
+	ToUserID   *uuid.UUID      `json:"toUserId,omitempty" db:"to_user_id"`     // Null once the account is deleted
+	ActorID    *uuid.UUID      `json:"actorId,omitempty" db:"actor_id"`        // The user who made the change
+	Reason     *string         `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time       `json:"createdAt" db:"created_at"`
+}
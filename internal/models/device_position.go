@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// DevicePosition is the last known position of a device, from its most recent located and unflagged point
+type DevicePosition struct {
+	DeviceID   string    `json:"deviceId"`
+	RecordedAt time.Time `json:"recordedAt"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Speed      float64   `json:"speed"`   // km/h, or the requested units
+	Heading    float64   `json:"heading"` // Degrees from North
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceRegistration is a device's anonymous pre-registration, held until a user adopts it
+type DeviceRegistration struct {
+	DeviceID          string     `json:"deviceId" db:"device_id"`
+	ClaimCodeHash     string     `json:"-" db:"claim_code_hash"`
+	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
+	AdoptedBy         *uuid.UUID `json:"adoptedBy,omitempty" db:"adopted_by"`
+	AdoptedAt         *time.Time `json:"adoptedAt,omitempty" db:"adopted_at"`
+	BackfilledAt      *time.Time `json:"backfilledAt,omitempty" db:"backfilled_at"` // Set once the device's anonymous history belongs to the adopter
+	BackfilledRecords int64      `json:"backfilledRecords" db:"backfilled_records"`
+}
+
+// IsAdopted reports whether a user has adopted the device
+func (r *DeviceRegistration) IsAdopted() bool {
+	return r.AdoptedAt != nil
+}
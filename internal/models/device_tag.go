@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Device tag limits
+const (
+	MaxDeviceTagLength = 64
+	MaxTagsPerDevice   = 20
+)
+
+// ErrInvalidDeviceTag is returned when a device tag is empty, too long or has unsupported characters
+var ErrInvalidDeviceTag = errors.New("invalid device tag")
+
+// ParseDeviceTag validates a device tag such as "kart" or "rental-fleet-A"
+// Surrounding whitespace is trimmed. Tags may hold letters, digits, spaces and "-", "_", "." or ":",
+// and are case-sensitive.
+func ParseDeviceTag(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || utf8.RuneCountInString(s) > MaxDeviceTagLength {
+		return "", ErrInvalidDeviceTag
+	}
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" -_.:", r) {
+			return "", ErrInvalidDeviceTag
+		}
+	}
+
+	return s, nil
+}
+
+// DeviceTagCount summarizes the devices carrying one tag
+type DeviceTagCount struct {
+	Tag     string `json:"tag"`
+	Devices int64  `json:"devices"` // Devices with the tag
+	Active  int64  `json:"active"`  // Of which active
+	Online  int64  `json:"online"`  // Of which seen within DeviceOnlineWindow
+}
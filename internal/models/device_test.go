@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -332,3 +333,26 @@ func TestDevice_ToResponse_Offline(t *testing.T) {
 
 	assert.False(t, response.IsOnline) // Should be offline
 }
+
+func TestParseDeviceTag(t *testing.T) {
+	for input, want := range map[string]string{
+		"kart":               "kart",
+		" rental-fleet-A ":   "rental-fleet-A",
+		"team 2:pit_lane.v1": "team 2:pit_lane.v1",
+		"équipe":             "équipe",
+	} {
+		t.Run(input, func(t *testing.T) {
+			tag, err := ParseDeviceTag(input)
+			require.NoError(t, err)
+			assert.Equal(t, want, tag)
+		})
+	}
+
+	tooLong := strings.Repeat("a", MaxDeviceTagLength+1)
+	for _, input := range []string{"", "   ", "a/b", "kart,rental", "#fleet", tooLong} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := ParseDeviceTag(input)
+			assert.ErrorIs(t, err, ErrInvalidDeviceTag)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType identifies the kind of change a domain event reports
+type EventType string
+
+const (
+	// EventTelemetryBatchSaved events report telemetry written by an HTTP upload
+	EventTelemetryBatchSaved EventType = "telemetry.batch_saved"
+	// EventDeviceClaimed events report a device gaining its first owner
+	EventDeviceClaimed EventType = "device.claimed"
+	// EventSessionEnded events report a recording session that was ended
+	EventSessionEnded EventType = "session.ended"
+	// EventUserLoggedIn events report a user signing in
+	EventUserLoggedIn EventType = "user.logged_in"
+)
+
+// EventTypes lists every domain event type
+var EventTypes = []EventType{
+	EventTelemetryBatchSaved,
+	EventDeviceClaimed,
+	EventSessionEnded,
+	EventUserLoggedIn,
+}
+
+// Event is a domain event kept in the outbox until its subscribers have handled it
+// Payload is the JSON encoding of the struct matching Type, e.g. SessionEnded.
+type Event struct {
+	ID        int64           `json:"id" db:"id"`
+	Type      EventType       `json:"type" db:"event_type"`
+	Payload   json.RawMessage `json:"data" db:"payload"`
+	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
+	Attempts  int             `json:"-" db:"attempts"` // Deliveries started, including the current one
+}
+
+// NewEvent creates an event of the given type with payload encoded as JSON
+func NewEvent(eventType EventType, payload interface{}) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	return &Event{Type: eventType, Payload: data, CreatedAt: time.Now()}, nil
+}
+
+// Decode unmarshals the event's payload into v
+func (e *Event) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s event: %w", e.Type, err)
+	}
+	return nil
+}
+
+// TelemetryBatchSaved is the payload of EventTelemetryBatchSaved
+// Records skipped as duplicates are not counted. Uploads queued for a deferred write are reported
+// once written, by the write that stored them.
+type TelemetryBatchSaved struct {
+	DeviceID        string     `json:"deviceId"`
+	UserID          *uuid.UUID `json:"userId,omitempty"` // Nil for anonymous uploads
+	Records         int        `json:"records"`
+	FirstRecordedAt time.Time  `json:"firstRecordedAt"`
+	LastRecordedAt  time.Time  `json:"lastRecordedAt"`
+
+	// Telemetry holds the stored records when the writer is configured to include them, e.g. for
+	// forwarding to a stream
+	Telemetry []*TelemetryData `json:"telemetry,omitempty"`
+}
+
+// NewTelemetryBatchSavedEvents returns an EventTelemetryBatchSaved event per device of the stored records
+// Records skipped as duplicates are left out, and there are no events when all of them were. The
+// records themselves are included when withTelemetry is set.
+func NewTelemetryBatchSavedEvents(records []*TelemetryData, withTelemetry bool) ([]*Event, error) {
+	batches := make(map[string]*TelemetryBatchSaved)
+	var order []string
+	for _, record := range records {
+		if record.Duplicate {
+			continue
+		}
+		batch, ok := batches[record.DeviceID]
+		if !ok {
+			batch = &TelemetryBatchSaved{
+				DeviceID:        record.DeviceID,
+				UserID:          record.UserID,
+				FirstRecordedAt: record.Timestamp,
+				LastRecordedAt:  record.Timestamp,
+			}
+			batches[record.DeviceID] = batch
+			order = append(order, record.DeviceID)
+		}
+		batch.Records++
+		if withTelemetry {
+			batch.Telemetry = append(batch.Telemetry, record)
+		}
+		if record.Timestamp.Before(batch.FirstRecordedAt) {
+			batch.FirstRecordedAt = record.Timestamp
+		}
+		if record.Timestamp.After(batch.LastRecordedAt) {
+			batch.LastRecordedAt = record.Timestamp
+		}
+	}
+
+	events := make([]*Event, 0, len(order))
+	for _, deviceID := range order {
+		event, err := NewEvent(EventTelemetryBatchSaved, batches[deviceID])
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// DeviceClaimed is the payload of EventDeviceClaimed
+type DeviceClaimed struct {
+	ID       uuid.UUID  `json:"id"`       // Device UUID
+	DeviceID string     `json:"deviceId"` // Hardware device ID
+	UserID   uuid.UUID  `json:"userId"`
+	OrgID    *uuid.UUID `json:"orgId,omitempty"`
+	Source   string     `json:"source"` // upload, bulk or adoption
+}
+
+// Sources of a device claim
+const (
+	DeviceClaimUpload   = "upload"   // First authenticated upload
+	DeviceClaimBulk     = "bulk"     // Bulk registration
+	DeviceClaimAdoption = "adoption" // Adoption of a pre-registered device with its claim code
+)
+
+// SessionEnded is the payload of EventSessionEnded
+type SessionEnded struct {
+	SessionID uuid.UUID  `json:"sessionId"`
+	DeviceID  string     `json:"deviceId"`
+	UserID    *uuid.UUID `json:"userId,omitempty"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   time.Time  `json:"endedAt"`
+}
+
+// UserLoggedIn is the payload of EventUserLoggedIn
+type UserLoggedIn struct {
+	UserID     uuid.UUID    `json:"userId"`
+	IPAddress  string       `json:"ipAddress"`
+	UserAgent  string       `json:"userAgent"`
+	Location   *GeoLocation `json:"location,omitempty"`
+	NewCountry bool         `json:"newCountry"` // The login came from a country none of the user's sessions came from
+	LoggedInAt time.Time    `json:"loggedInAt"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeBatchSaved(t *testing.T, events []*Event) []TelemetryBatchSaved {
+	t.Helper()
+	batches := make([]TelemetryBatchSaved, len(events))
+	for i, event := range events {
+		require.Equal(t, EventTelemetryBatchSaved, event.Type)
+		require.NoError(t, event.Decode(&batches[i]))
+	}
+	return batches
+}
+
+func TestNewTelemetryBatchSavedEvents(t *testing.T) {
+	userID := uuid.New()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	events, err := NewTelemetryBatchSavedEvents([]*TelemetryData{
+		{DeviceID: "AVT-001", UserID: &userID, Timestamp: base.Add(2 * time.Second)},
+		{DeviceID: "AVT-002", Timestamp: base},
+		{DeviceID: "AVT-001", UserID: &userID, Timestamp: base},
+		{DeviceID: "AVT-001", UserID: &userID, Timestamp: base.Add(time.Hour), Duplicate: true},
+		{DeviceID: "AVT-003", Timestamp: base, Duplicate: true},
+	}, false)
+	require.NoError(t, err)
+
+	batches := decodeBatchSaved(t, events)
+	require.Len(t, batches, 2, "one event per device with stored records")
+	assert.Equal(t, "AVT-001", batches[0].DeviceID)
+	assert.Equal(t, &userID, batches[0].UserID)
+	assert.Equal(t, 2, batches[0].Records)
+	assert.Equal(t, base, batches[0].FirstRecordedAt)
+	assert.Equal(t, base.Add(2*time.Second), batches[0].LastRecordedAt)
+	assert.Empty(t, batches[0].Telemetry, "records are left out unless requested")
+	assert.Equal(t, "AVT-002", batches[1].DeviceID)
+	assert.Nil(t, batches[1].UserID)
+}
+
+func TestNewTelemetryBatchSavedEvents_WithTelemetry(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	records := []*TelemetryData{
+		{DeviceID: "AVT-001", Timestamp: base},
+		{DeviceID: "AVT-001", Timestamp: base.Add(time.Second), Duplicate: true},
+	}
+
+	events, err := NewTelemetryBatchSavedEvents(records, true)
+	require.NoError(t, err)
+
+	batches := decodeBatchSaved(t, events)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0].Telemetry, 1, "duplicates are left out")
+	assert.Equal(t, base, batches[0].Telemetry[0].Timestamp)
+
+	events, err = NewTelemetryBatchSavedEvents(records[1:], true)
+	require.NoError(t, err)
+	assert.Empty(t, events, "nothing is published when every record was a duplicate")
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxFirmwareVersionLength bounds the reported firmware version string
+const maxFirmwareVersionLength = 64
+
+// ErrInvalidFirmwareVersion is returned when a firmware version cannot be parsed
+var ErrInvalidFirmwareVersion = errors.New("invalid firmware version")
+
+// FirmwareVersion is a device firmware version of the form MAJOR[.MINOR[.PATCH]][-PRERELEASE]
+// Missing minor and patch numbers count as zero, and a pre-release sorts before its release.
+type FirmwareVersion struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// ParseFirmwareVersion parses a firmware version such as "2.1.0", "v2.1" or "2.1.0-beta.1"
+// Build metadata after a "+" is ignored.
+func ParseFirmwareVersion(s string) (FirmwareVersion, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || len(s) > maxFirmwareVersionLength {
+		return FirmwareVersion{}, ErrInvalidFirmwareVersion
+	}
+
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	s, _, _ = strings.Cut(s, "+")
+
+	var v FirmwareVersion
+	core, prerelease, hasPrerelease := strings.Cut(s, "-")
+	if hasPrerelease {
+		if prerelease == "" {
+			return FirmwareVersion{}, ErrInvalidFirmwareVersion
+		}
+		v.Prerelease = prerelease
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return FirmwareVersion{}, ErrInvalidFirmwareVersion
+	}
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || strings.HasPrefix(part, "+") {
+			return FirmwareVersion{}, ErrInvalidFirmwareVersion
+		}
+		*numbers[i] = n
+	}
+
+	return v, nil
+}
+
+// String formats the version as MAJOR.MINOR.PATCH[-PRERELEASE]
+func (v FirmwareVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v is older than, the same as or newer than other
+func (v FirmwareVersion) Compare(other FirmwareVersion) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d != 0 {
+			if d < 0 {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+	return strings.Compare(v.Prerelease, other.Prerelease)
+}
+
+// Before reports whether v is older than other
+func (v FirmwareVersion) Before(other FirmwareVersion) bool {
+	return v.Compare(other) < 0
+}
+
+// FirmwareCount is the number of devices reporting one firmware version
+type FirmwareCount struct {
+	Version      *string `json:"version"`      // Nil for devices that never reported a version
+	Devices      int64   `json:"devices"`      // Active devices on this version
+	Online       int64   `json:"online"`       // Of which seen within DeviceOnlineWindow
+	BelowMinimum bool    `json:"belowMinimum"` // Older than the minimum supported version
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFirmwareVersion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"2.1.3", "2.1.3"},
+		{"v2.1", "2.1.0"},
+		{" 3 ", "3.0.0"},
+		{"2.1.0-beta.1", "2.1.0-beta.1"},
+		{"2.1.0+build.7", "2.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			version, err := ParseFirmwareVersion(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, version.String())
+		})
+	}
+
+	for _, input := range []string{"", "latest", "1.2.3.4", "1..2", "1.-2", "1.+2", "1.2-", "x1.2"} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := ParseFirmwareVersion(input)
+			assert.ErrorIs(t, err, ErrInvalidFirmwareVersion)
+		})
+	}
+}
+
+func TestFirmwareVersion_Compare(t *testing.T) {
+	parse := func(s string) FirmwareVersion {
+		version, err := ParseFirmwareVersion(s)
+		require.NoError(t, err)
+		return version
+	}
+
+	assert.Equal(t, 0, parse("2.1").Compare(parse("2.1.0")))
+	assert.Equal(t, -1, parse("2.1.9").Compare(parse("2.10.0")), "components compare numerically")
+	assert.Equal(t, 1, parse("3.0.0").Compare(parse("2.99.99")))
+	assert.True(t, parse("2.1.0-rc.1").Before(parse("2.1.0")), "pre-releases precede their release")
+	assert.True(t, parse("2.1.0-alpha").Before(parse("2.1.0-beta")))
+	assert.False(t, parse("2.1.0").Before(parse("2.1.0-beta")))
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/geo"
+)
+
+// GeofenceKind identifies the shape of a geofence
+type GeofenceKind string
+
+// Supported geofence shapes
+const (
+	GeofenceCircle  GeofenceKind = "circle"
+	GeofencePolygon GeofenceKind = "polygon"
+)
+
+// Geofence size limits
+const (
+	minGeofenceRadius   = 10.0     // meters
+	maxGeofenceRadius   = 100000.0 // meters
+	maxGeofenceVertices = 500
+)
+
+// Geofence represents a user-defined area that emits enter/exit events as devices move
+type Geofence struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	UserID       uuid.UUID    `json:"userId" db:"user_id"`
+	Name         string       `json:"name" db:"name"`
+	Kind         GeofenceKind `json:"kind" db:"kind"`
+	Center       *geo.Point   `json:"center,omitempty"`                      // Circle only
+	RadiusMeters *float64     `json:"radiusMeters,omitempty" db:"radius_m"`  // Circle only
+	Polygon      []geo.Point  `json:"polygon,omitempty" db:"polygon"`        // Polygon only (JSONB)
+	NotifyEmail  bool         `json:"notifyEmail" db:"notify_email"`         // Email the owner on events
+	WebhookURL   *string      `json:"webhookUrl,omitempty" db:"webhook_url"` // POST events to this URL
+	IsActive     bool         `json:"isActive" db:"is_active"`
+	CreatedAt    time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updatedAt" db:"updated_at"`
+}
+
+// Validate checks the geofence shape and notification settings
+func (g *Geofence) Validate() error {
+	switch g.Kind {
+	case GeofenceCircle:
+		if g.Center == nil || g.RadiusMeters == nil {
+			return fmt.Errorf("circle geofences require center and radiusMeters")
+		}
+		if !g.Center.IsValid() {
+			return fmt.Errorf("center coordinates are out of range")
+		}
+		if *g.RadiusMeters < minGeofenceRadius || *g.RadiusMeters > maxGeofenceRadius {
+			return fmt.Errorf("radiusMeters must be between %.0f and %.0f", minGeofenceRadius, maxGeofenceRadius)
+		}
+		if len(g.Polygon) > 0 {
+			return fmt.Errorf("circle geofences must not define polygon")
+		}
+	case GeofencePolygon:
+		if len(g.Polygon) < 3 || len(g.Polygon) > maxGeofenceVertices {
+			return fmt.Errorf("polygon must have between 3 and %d vertices", maxGeofenceVertices)
+		}
+		for i, vertex := range g.Polygon {
+			if !vertex.IsValid() {
+				return fmt.Errorf("polygon vertex %d is out of range", i)
+			}
+		}
+		if g.Center != nil || g.RadiusMeters != nil {
+			return fmt.Errorf("polygon geofences must not define center or radiusMeters")
+		}
+	default:
+		return fmt.Errorf("kind must be one of: circle, polygon")
+	}
+
+	if g.WebhookURL != nil {
+		u, err := url.Parse(*g.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhookUrl must be an absolute http or https URL")
+		}
+	}
+
+	return nil
+}
+
+// Contains reports whether a point lies inside the geofence
+func (g *Geofence) Contains(p geo.Point) bool {
+	switch g.Kind {
+	case GeofenceCircle:
+		return g.Center != nil && g.RadiusMeters != nil && geo.Distance(*g.Center, p) <= *g.RadiusMeters
+	case GeofencePolygon:
+		return geo.PolygonContains(g.Polygon, p)
+	default:
+		return false
+	}
+}
+
+// IsOwnedBy checks if the geofence belongs to the given user
+func (g *Geofence) IsOwnedBy(userID uuid.UUID) bool {
+	return g.UserID == userID
+}
+
+// GeofenceEventType identifies a geofence transition
+type GeofenceEventType string
+
+// Geofence transitions
+const (
+	GeofenceEnter GeofenceEventType = "enter"
+	GeofenceExit  GeofenceEventType = "exit"
+)
+
+// GeofenceEvent records a device entering or leaving a geofence
+type GeofenceEvent struct {
+	ID         int64             `json:"id" db:"id"`
+	GeofenceID uuid.UUID         `json:"geofenceId" db:"geofence_id"`
+	UserID     uuid.UUID         `json:"userId" db:"user_id"`
+	DeviceID   string            `json:"deviceId" db:"device_id"`
+	Type       GeofenceEventType `json:"type" db:"event_type"`
+	RecordedAt time.Time         `json:"recordedAt" db:"recorded_at"` // Timestamp of the telemetry that triggered the event
+	Latitude   float64           `json:"latitude" db:"latitude"`
+	Longitude  float64           `json:"longitude" db:"longitude"`
+	CreatedAt  time.Time         `json:"createdAt" db:"created_at"`
+}
+
+// GeofenceState is the last known position of a device relative to a geofence
+type GeofenceState struct {
+	GeofenceID uuid.UUID `db:"geofence_id"`
+	DeviceID   string    `db:"device_id"`
+	Inside     bool      `db:"inside"`
+	RecordedAt time.Time `db:"recorded_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const (
+	// gpsWeek is the length of a GPS week, over which ITOW wraps
+	gpsWeek = 7 * 24 * time.Hour
+
+	// gpsLeapSeconds is the current offset between GPS time and UTC
+	gpsLeapSeconds = 18 * time.Second
+)
+
+// gpsEpoch is the start of GPS time
+var gpsEpoch = time.Date(1980, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+// GPSTimeOfWeek derives the GPS time of week in milliseconds from a UTC timestamp
+func GPSTimeOfWeek(t time.Time) int64 {
+	sinceEpoch := t.Sub(gpsEpoch) + gpsLeapSeconds
+	return (sinceEpoch % gpsWeek).Milliseconds()
+}
+
+// GPSTimeNear returns the UTC time of a GPS time of week in the GPS week closest to near
+// ITOW does not carry the week number, so near must be within half a week of the real time.
+func GPSTimeNear(itow int64, near time.Time) time.Time {
+	sinceEpoch := near.Sub(gpsEpoch) + gpsLeapSeconds
+	weekStart := gpsEpoch.Add(sinceEpoch - sinceEpoch%gpsWeek - gpsLeapSeconds)
+
+	t := weekStart.Add(time.Duration(itow) * time.Millisecond)
+	switch diff := t.Sub(near); {
+	case diff > gpsWeek/2:
+		t = t.Add(-gpsWeek)
+	case diff < -gpsWeek/2:
+		t = t.Add(gpsWeek)
+	}
+	return t
+}
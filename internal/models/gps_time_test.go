@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGPSTimeOfWeek(t *testing.T) {
+	// Sunday midnight GPS time is the start of the week
+	weekStart := time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC).Add(-gpsLeapSeconds)
+	assert.Equal(t, int64(0), GPSTimeOfWeek(weekStart))
+	assert.Equal(t, int64(90061500), GPSTimeOfWeek(weekStart.Add(25*time.Hour+61*time.Second+500*time.Millisecond)))
+}
+
+func TestGPSTimeNear(t *testing.T) {
+	at := time.Date(2024, 5, 15, 12, 30, 0, 250_000_000, time.UTC)
+	itow := GPSTimeOfWeek(at)
+
+	assert.True(t, GPSTimeNear(itow, at).Equal(at))
+	assert.True(t, GPSTimeNear(itow, at.Add(-90*time.Second)).Equal(at))
+
+	// Times of week near a week boundary resolve to the closer week
+	weekStart := time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC).Add(-gpsLeapSeconds)
+	assert.True(t, GPSTimeNear(GPSTimeOfWeek(weekStart.Add(-time.Second)), weekStart.Add(time.Second)).Equal(weekStart.Add(-time.Second)))
+	assert.True(t, GPSTimeNear(GPSTimeOfWeek(weekStart.Add(time.Second)), weekStart.Add(-time.Second)).Equal(weekStart.Add(time.Second)))
+}
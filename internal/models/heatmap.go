@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Heatmap zoom levels follow web map tiles: at zoom z the world is 2^z tiles of 256 pixels wide
+const (
+	MinHeatmapZoom = 0
+	MaxHeatmapZoom = 22
+
+	// HeatmapCellPixels is the width of a heatmap cell in pixels at the requested zoom
+	HeatmapCellPixels = 16
+)
+
+// MaxMercatorLatitude is the latitude where Web Mercator maps end; bounding boxes are clamped to it
+const MaxMercatorLatitude = 85.05112878
+
+// mercatorWorldWidth is the circumference of the Web Mercator (EPSG:3857) world in meters
+const mercatorWorldWidth = 2 * math.Pi * 6378137
+
+// HeatmapCellSize returns the width of heatmap cells at a zoom level, in Web Mercator meters
+func HeatmapCellSize(zoom int) float64 {
+	return mercatorWorldWidth / math.Exp2(float64(zoom)) / 256 * HeatmapCellPixels
+}
+
+// BoundingBox is a longitude/latitude rectangle in degrees
+type BoundingBox struct {
+	West  float64 `json:"west"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	North float64 `json:"north"`
+}
+
+// ParseBoundingBox parses a "west,south,east,north" bounding box
+// Latitudes beyond the Web Mercator limit are clamped to it. Boxes crossing the antimeridian
+// are not supported and must be requested as two boxes.
+func ParseBoundingBox(value string) (BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, errors.New("bbox must be west,south,east,north")
+	}
+
+	var coords [4]float64
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return BoundingBox{}, errors.New("bbox must be west,south,east,north")
+		}
+		coords[i] = n
+	}
+
+	box := BoundingBox{West: coords[0], South: coords[1], East: coords[2], North: coords[3]}
+	if box.West < -180 || box.East > 180 || box.South < -90 || box.North > 90 {
+		return BoundingBox{}, errors.New("bbox is outside longitudes -180 to 180 and latitudes -90 to 90")
+	}
+	if box.West >= box.East || box.South >= box.North {
+		return BoundingBox{}, errors.New("bbox west must be less than east and south less than north")
+	}
+
+	box.South = math.Max(box.South, -MaxMercatorLatitude)
+	box.North = math.Min(box.North, MaxMercatorLatitude)
+	return box, nil
+}
+
+// Contains reports whether a point lies within the box, edges included
+func (b BoundingBox) Contains(latitude, longitude float64) bool {
+	return latitude >= b.South && latitude <= b.North && longitude >= b.West && longitude <= b.East
+}
+
+// HeatmapCell aggregates the telemetry points within one grid cell
+type HeatmapCell struct {
+	Latitude  float64 `json:"latitude"`  // Center of the cell
+	Longitude float64 `json:"longitude"` // Center of the cell
+	Points    int64   `json:"points"`
+	AvgSpeed  float64 `json:"avgSpeed"` // km/h
+	MaxSpeed  float64 `json:"maxSpeed"` // km/h
+}
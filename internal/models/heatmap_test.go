@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBoundingBox(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected BoundingBox
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			value:    "5.955,50.427, 5.985,50.446",
+			expected: BoundingBox{West: 5.955, South: 50.427, East: 5.985, North: 50.446},
+		},
+		{
+			name:     "clamped to mercator latitudes",
+			value:    "-180,-90,180,90",
+			expected: BoundingBox{West: -180, South: -MaxMercatorLatitude, East: 180, North: MaxMercatorLatitude},
+		},
+		{name: "too few values", value: "1,2,3", wantErr: true},
+		{name: "not a number", value: "a,2,3,4", wantErr: true},
+		{name: "out of range", value: "-181,0,10,10", wantErr: true},
+		{name: "crosses antimeridian", value: "170,0,-170,10", wantErr: true},
+		{name: "empty", value: "0,10,5,10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box, err := ParseBoundingBox(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, box)
+		})
+	}
+}
+
+func TestHeatmapCellSize(t *testing.T) {
+	// One cell spans the world width over 16 cells per tile
+	assert.InDelta(t, 40075016.686/16, HeatmapCellSize(0), 0.01)
+	assert.InDelta(t, HeatmapCellSize(14)/2, HeatmapCellSize(15), 1e-9)
+}
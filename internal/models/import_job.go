@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportStatus is the lifecycle state of an import job
+type ImportStatus string
+
+const (
+	// ImportPending jobs are queued and have not started
+	ImportPending ImportStatus = "pending"
+	// ImportProcessing jobs are writing telemetry
+	ImportProcessing ImportStatus = "processing"
+	// ImportCompleted jobs have stored every row
+	ImportCompleted ImportStatus = "completed"
+	// ImportFailed jobs stopped before storing every row; see Error
+	ImportFailed ImportStatus = "failed"
+)
+
+// IsFinished checks if the job has reached a terminal state
+func (s ImportStatus) IsFinished() bool {
+	return s == ImportCompleted || s == ImportFailed
+}
+
+// ImportJob tracks the background import of a historical recording into a new session
+type ImportJob struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	UserID       uuid.UUID    `json:"userId" db:"user_id"`
+	SessionID    uuid.UUID    `json:"sessionId" db:"session_id"` // Session created for the recording
+	DeviceID     string       `json:"deviceId" db:"device_id"`
+	Filename     string       `json:"filename" db:"filename"`
+	Status       ImportStatus `json:"status" db:"status"`
+	TotalRows    int          `json:"totalRows" db:"total_rows"`
+	ImportedRows int          `json:"importedRows" db:"imported_rows"`
+	Error        *string      `json:"error,omitempty" db:"error"`
+	CreatedAt    time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updatedAt" db:"updated_at"`
+	CompletedAt  *time.Time   `json:"completedAt,omitempty" db:"completed_at"`
+}
+
+// IsOwnedBy checks if the job belongs to the given user
+func (j *ImportJob) IsOwnedBy(userID uuid.UUID) bool {
+	return j.UserID == userID
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IngestSource identifies the path an ingest request arrived through
+type IngestSource string
+
+// Supported ingest sources
+const (
+	IngestSourceSingle IngestSource = "single"
+	IngestSourceBatch  IngestSource = "batch"
+	IngestSourceStream IngestSource = "stream"
+	IngestSourceMQTT   IngestSource = "mqtt"
+)
+
+// IngestResult summarizes the outcome of an ingest request
+type IngestResult string
+
+// Supported ingest results
+const (
+	IngestResultAccepted IngestResult = "accepted" // Stored or queued, including duplicates that were skipped
+	IngestResultRejected IngestResult = "rejected" // Refused because of the request (invalid payload, auth, quota, rate limit)
+	IngestResultFailed   IngestResult = "failed"   // Refused because of a server-side error
+)
+
+// IsValid checks if the result is a known ingest result
+func (r IngestResult) IsValid() bool {
+	switch r {
+	case IngestResultAccepted, IngestResultRejected, IngestResultFailed:
+		return true
+	}
+	return false
+}
+
+// IngestResultForStatus maps an HTTP response status to an ingest result
+func IngestResultForStatus(status int) IngestResult {
+	switch {
+	case status >= 500:
+		return IngestResultFailed
+	case status >= 400:
+		return IngestResultRejected
+	default:
+		return IngestResultAccepted
+	}
+}
+
+// IngestAuditEntry records a single telemetry upload request or MQTT message
+type IngestAuditEntry struct {
+	ID        int64        `json:"id" db:"id"`
+	CreatedAt time.Time    `json:"createdAt" db:"created_at"`
+	Source    IngestSource `json:"source" db:"source"`
+	UserID    *uuid.UUID   `json:"userId,omitempty" db:"user_id"`      // Nil for anonymous uploads
+	DeviceID  string       `json:"deviceId,omitempty" db:"device_id"`  // Empty when the payload could not be decoded
+	APIKeyID  *uuid.UUID   `json:"apiKeyId,omitempty" db:"api_key_id"` // Device API key used, if any
+	Records   int          `json:"records" db:"records"`               // Records in the request, valid or not
+	SourceIP  string       `json:"sourceIp,omitempty" db:"source_ip"`  // Empty for MQTT
+	Status    int          `json:"status,omitempty" db:"status"`       // HTTP status; zero for MQTT
+	Result    IngestResult `json:"result" db:"result"`
+	Error     string       `json:"error,omitempty" db:"error"` // Why an MQTT message was dropped
+	LatencyMS float64      `json:"latencyMs" db:"latency_ms"`  // Time spent handling the request
+}
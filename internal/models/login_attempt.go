@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt records a failed login
+type LoginAttempt struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      *uuid.UUID `json:"userId,omitempty" db:"user_id"` // Nil when the email does not belong to an account
+	Email       string     `json:"email" db:"email"`
+	IPAddress   string     `json:"ipAddress,omitempty" db:"ip_address"`
+	AttemptedAt time.Time  `json:"attemptedAt" db:"attempted_at"`
+}
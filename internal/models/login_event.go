@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginMethod is how a client tried to sign in
+type LoginMethod string
+
+// Supported login methods
+const (
+	LoginMethodPassword  LoginMethod = "password"
+	LoginMethodMagicLink LoginMethod = "magic_link"
+	LoginMethodOIDC      LoginMethod = "oidc" // Single sign-on through an OpenID Connect provider
+)
+
+// IsValid checks if the method is a known login method
+func (m LoginMethod) IsValid() bool {
+	switch m {
+	case LoginMethodPassword, LoginMethodMagicLink, LoginMethodOIDC:
+		return true
+	}
+	return false
+}
+
+// Reasons failed logins are recorded with
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials" // Wrong password
+	LoginFailureUnknownAccount     = "unknown_account"     // The email does not belong to an account
+	LoginFailureAccountDisabled    = "account_disabled"
+	LoginFailureAccountLocked      = "account_locked"
+	LoginFailureThrottled          = "throttled"        // The client address had too many recent failures
+	LoginFailureInvalidToken       = "invalid_token"    // Unknown, used or expired sign-in link
+	LoginFailureUnverifiedEmail    = "unverified_email" // The identity provider's address matches an account but is not verified
+)
+
+// LoginEvent records a login attempt in the login audit trail
+type LoginEvent struct {
+	ID            int64       `json:"id" db:"id"`
+	UserID        *uuid.UUID  `json:"userId,omitempty" db:"user_id"` // Nil when the attempt does not identify an account
+	Email         string      `json:"email,omitempty" db:"email"`
+	Method        LoginMethod `json:"method" db:"method"`
+	Success       bool        `json:"success" db:"success"`
+	FailureReason string      `json:"failureReason,omitempty" db:"failure_reason"`
+	IPAddress     string      `json:"ipAddress,omitempty" db:"ip_address"`
+	UserAgent     string      `json:"userAgent,omitempty" db:"user_agent"`
+	CreatedAt     time.Time   `json:"createdAt" db:"created_at"`
+}
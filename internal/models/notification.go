@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationType identifies the kind of event a notification reports
+type NotificationType string
+
+// Supported notification types
+const (
+	NotificationSessionSummary NotificationType = "session_summary"
+	NotificationLowBattery     NotificationType = "low_battery"
+	NotificationNewLogin       NotificationType = "new_login"
+	NotificationThresholdAlert NotificationType = "threshold_alert"
+)
+
+// NotificationTypes lists every supported notification type
+var NotificationTypes = []NotificationType{
+	NotificationSessionSummary,
+	NotificationLowBattery,
+	NotificationNewLogin,
+	NotificationThresholdAlert,
+}
+
+// IsValid checks if the notification type is a known type
+func (t NotificationType) IsValid() bool {
+	switch t {
+	case NotificationSessionSummary, NotificationLowBattery, NotificationNewLogin, NotificationThresholdAlert:
+		return true
+	}
+	return false
+}
+
+// NotificationChannel identifies how a notification is delivered outside the inbox
+type NotificationChannel string
+
+// Supported delivery channels
+const (
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelPush  NotificationChannel = "push" // Stored as a preference; FCM/APNs delivery is not implemented yet
+)
+
+// Notification is an entry in a user's notification inbox
+type Notification struct {
+	ID        uuid.UUID         `json:"id" db:"id"`
+	UserID    uuid.UUID         `json:"-" db:"user_id"`
+	Type      NotificationType  `json:"type" db:"type"`
+	Title     string            `json:"title" db:"title"`
+	Body      string            `json:"body" db:"body"`
+	Data      map[string]string `json:"data,omitempty" db:"data"` // e.g. sessionId or deviceId, for deep links
+	ReadAt    *time.Time        `json:"readAt,omitempty" db:"read_at"`
+	CreatedAt time.Time         `json:"createdAt" db:"created_at"`
+}
+
+// IsRead checks if the notification has been read
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// NotificationPreference selects the channels a notification type is delivered over
+// Notifications are always kept in the inbox; the preference only covers other channels.
+type NotificationPreference struct {
+	Type  NotificationType `json:"type" db:"type"`
+	Email bool             `json:"email" db:"email"`
+	Push  bool             `json:"push" db:"push"`
+}
+
+// Wants reports whether the preference enables the given channel
+func (p NotificationPreference) Wants(channel NotificationChannel) bool {
+	switch channel {
+	case NotificationChannelEmail:
+		return p.Email
+	case NotificationChannelPush:
+		return p.Push
+	}
+	return false
+}
+
+// DefaultNotificationPreference returns the preference of a type the user has not configured
+// Alerts are emailed by default, while session summaries only go to the inbox and push.
+func DefaultNotificationPreference(t NotificationType) NotificationPreference {
+	return NotificationPreference{
+		Type:  t,
+		Email: t != NotificationSessionSummary,
+		Push:  true,
+	}
+}
+
+// WithDefaultPreferences returns a preference for every notification type, in NotificationTypes order,
+// falling back to the defaults for types missing from stored
+func WithDefaultPreferences(stored []NotificationPreference) []NotificationPreference {
+	preferences := make([]NotificationPreference, 0, len(NotificationTypes))
+	for _, t := range NotificationTypes {
+		preference := DefaultNotificationPreference(t)
+		for _, p := range stored {
+			if p.Type == t {
+				preference = p
+				break
+			}
+		}
+		preferences = append(preferences, preference)
+	}
+	return preferences
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationType_IsValid(t *testing.T) {
+	for _, notificationType := range NotificationTypes {
+		assert.True(t, notificationType.IsValid())
+	}
+	assert.False(t, NotificationType("geofence").IsValid())
+}
+
+func TestWithDefaultPreferences(t *testing.T) {
+	preferences := WithDefaultPreferences([]NotificationPreference{
+		{Type: NotificationLowBattery, Email: false, Push: false},
+	})
+
+	assert.Equal(t, []NotificationPreference{
+		{Type: NotificationSessionSummary, Email: false, Push: true},
+		{Type: NotificationLowBattery, Email: false, Push: false},
+		{Type: NotificationNewLogin, Email: true, Push: true},
+		{Type: NotificationThresholdAlert, Email: true, Push: true},
+	}, preferences)
+	assert.True(t, preferences[2].Wants(NotificationChannelEmail))
+	assert.False(t, preferences[1].Wants(NotificationChannelPush))
+}
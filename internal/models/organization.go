@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrgRole is a member's role within an organization
+type OrgRole string
+
+const (
+	// OrgRoleOwner members manage the organization, including other owners
+	OrgRoleOwner OrgRole = "owner"
+	// OrgRoleAdmin members manage shared devices, members and invitations
+	OrgRoleAdmin OrgRole = "admin"
+	// OrgRoleMember members can use and view shared devices and sessions
+	OrgRoleMember OrgRole = "member"
+)
+
+// IsValid checks if the role is supported
+func (r OrgRole) IsValid() bool {
+	switch r {
+	case OrgRoleOwner, OrgRoleAdmin, OrgRoleMember:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanManage checks if the role may manage shared devices, members and invitations
+func (r OrgRole) CanManage() bool {
+	return r == OrgRoleOwner || r == OrgRoleAdmin
+}
+
+// Organization is a team that shares devices and sessions between its members
+type Organization struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// OrganizationMembership is an organization as seen by one of its members
+type OrganizationMembership struct {
+	Organization
+	Role OrgRole `json:"role"`
+}
+
+// OrganizationMember is a user's membership in an organization
+type OrganizationMember struct {
+	OrgID     uuid.UUID `json:"orgId" db:"org_id"`
+	UserID    uuid.UUID `json:"userId" db:"user_id"`
+	Email     string    `json:"email" db:"email"` // Read from users; ignored on write
+	Role      OrgRole   `json:"role" db:"role"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// OrganizationInvitation is a pending invitation to join an organization
+// The token is emailed to the invitee and only its hash is stored.
+type OrganizationInvitation struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	OrgID      uuid.UUID  `json:"orgId" db:"org_id"`
+	Email      string     `json:"email" db:"email"`
+	Role       OrgRole    `json:"role" db:"role"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	InvitedBy  *uuid.UUID `json:"invitedBy,omitempty" db:"invited_by"`
+	ExpiresAt  time.Time  `json:"expiresAt" db:"expires_at"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty" db:"accepted_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// IsPending checks if the invitation can still be accepted
+func (i *OrganizationInvitation) IsPending() bool {
+	return i.AcceptedAt == nil && time.Now().Before(i.ExpiresAt)
+}
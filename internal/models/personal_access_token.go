@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalAccessToken is a long-lived, scoped credential a user mints for scripts and integrations
+type PersonalAccessToken struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"-" db:"user_id"`
+	Name        string     `json:"name" db:"name"`                         // User-friendly label
+	TokenPrefix string     `json:"tokenPrefix" db:"token_prefix"`          // Leading characters of the token for identification
+	TokenHash   string     `json:"-" db:"token_hash"`                      // Never expose in JSON - stored as SHA256 hash
+	Scopes      []Scope    `json:"scopes" db:"scopes"`                     // What the token may be used for
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty" db:"expires_at"`    // Nil for tokens that never expire
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`              // When the token was minted
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty" db:"last_used_at"` // Last successful authentication
+	RevokedAt   *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`    // When the token was revoked
+}
+
+// IsRevoked checks if the token has been revoked
+func (t *PersonalAccessToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsExpired checks if the token has expired at the given time
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PushProvider identifies the service push notifications are sent through
+type PushProvider string
+
+// Supported push providers
+const (
+	PushProviderFCM  PushProvider = "fcm"  // Firebase Cloud Messaging (Android, and iOS apps using Firebase)
+	PushProviderAPNs PushProvider = "apns" // Apple Push Notification service
+)
+
+// IsValid checks if the push provider is a known provider
+func (p PushProvider) IsValid() bool {
+	return p == PushProviderFCM || p == PushProviderAPNs
+}
+
+// MaxPushTokenLength bounds registered device tokens; FCM tokens are around 160 characters and APNs tokens 64
+const MaxPushTokenLength = 4096
+
+// PushToken is a mobile app installation registered to receive a user's push notifications
+type PushToken struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	UserID     uuid.UUID    `json:"-" db:"user_id"`
+	Provider   PushProvider `json:"provider" db:"provider"`
+	Token      string       `json:"token" db:"token"`
+	DeviceName *string      `json:"deviceName,omitempty" db:"device_name"` // e.g. "Pixel 8"
+	CreatedAt  time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updatedAt" db:"updated_at"` // Last time the app registered the token
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,42 @@ type RefreshToken struct {
 	ReplacedBy *uuid.UUID `json:"replacedBy,omitempty" db:"replaced_by"` // ID of the token that replaced this one
 	UserAgent  string     `json:"userAgent,omitempty" db:"user_agent"`
 	IPAddress  string     `json:"ipAddress,omitempty" db:"ip_address"`
+
+	// ClientFingerprint binds the token to the client it was issued to; empty when it is not bound
+	ClientFingerprint string `json:"-" db:"client_fingerprint"`
+
+	// RememberMe and SessionStartedAt are carried over when the token is rotated: they select the
+	// sliding lifetime of the session and the login time its absolute cap is counted from
+	RememberMe       bool      `json:"rememberMe" db:"remember_me"`
+	SessionStartedAt time.Time `json:"sessionStartedAt" db:"session_started_at"`
+
+	// Location is looked up from IPAddress when the token is issued; nil when GeoIP is disabled or
+	// the address is unknown
+	Location *GeoLocation `json:"location,omitempty"`
+}
+
+// GeoLocation is the coarse location of an IP address
+type GeoLocation struct {
+	CountryCode string `json:"countryCode"` // ISO 3166-1 alpha-2, e.g. "DE"
+	Country     string `json:"country,omitempty"`
+	Region      string `json:"region,omitempty"`
+	City        string `json:"city,omitempty"`
+}
+
+// String formats the location as "City, Region, Country", skipping unknown parts
+func (l *GeoLocation) String() string {
+	country := l.Country
+	if country == "" {
+		country = l.CountryCode
+	}
+
+	parts := make([]string, 0, 3)
+	for _, part := range []string{l.City, l.Region, country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 
 // IsValid checks if the refresh token is still valid
@@ -46,15 +83,16 @@ func (rt *RefreshToken) IsRevoked() bool {
 
 // RefreshTokenResponse represents a refresh token for API responses
 type RefreshTokenResponse struct {
-	ID         uuid.UUID  `json:"id"`
-	UserID     uuid.UUID  `json:"userId"`
-	ExpiresAt  time.Time  `json:"expiresAt"`
-	CreatedAt  time.Time  `json:"createdAt"`
-	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
-	ReplacedBy *uuid.UUID `json:"replacedBy,omitempty"`
-	UserAgent  string     `json:"userAgent,omitempty"`
-	IPAddress  string     `json:"ipAddress,omitempty"`
-	IsValid    bool       `json:"isValid"`
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"userId"`
+	ExpiresAt  time.Time    `json:"expiresAt"`
+	CreatedAt  time.Time    `json:"createdAt"`
+	RevokedAt  *time.Time   `json:"revokedAt,omitempty"`
+	ReplacedBy *uuid.UUID   `json:"replacedBy,omitempty"`
+	UserAgent  string       `json:"userAgent,omitempty"`
+	IPAddress  string       `json:"ipAddress,omitempty"`
+	Location   *GeoLocation `json:"location,omitempty"`
+	IsValid    bool         `json:"isValid"`
 }
 
 // ToResponse converts a RefreshToken to a RefreshTokenResponse (safe for API)
@@ -68,6 +106,20 @@ func (rt *RefreshToken) ToResponse() *RefreshTokenResponse {
 		ReplacedBy: rt.ReplacedBy,
 		UserAgent:  rt.UserAgent,
 		IPAddress:  rt.IPAddress,
+		Location:   rt.Location,
 		IsValid:    rt.IsValid(),
 	}
 }
+
+// LoginSession is a signed-in client, represented by the active refresh token at the end of its rotation chain
+type LoginSession struct {
+	ID         uuid.UUID `json:"id"` // ID of the active refresh token; changes each time the client refreshes
+	UserAgent  string    `json:"userAgent,omitempty"`
+	IPAddress  string    `json:"ipAddress,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`  // When the client signed in
+	LastUsedAt time.Time `json:"lastUsedAt"` // When the client last refreshed its tokens
+	ExpiresAt  time.Time `json:"expiresAt"`
+	RememberMe bool      `json:"rememberMe"`
+
+	Location *GeoLocation `json:"location,omitempty"` // Where the client last refreshed from
+}
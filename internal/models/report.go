@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportFormat is the file format a report is rendered in
+type ReportFormat string
+
+const (
+	// ReportCSV reports are comma-separated values with a header row
+	ReportCSV ReportFormat = "csv"
+	// ReportXLSX reports are single-sheet Excel workbooks
+	ReportXLSX ReportFormat = "xlsx"
+)
+
+// IsValid checks if the format is supported
+func (f ReportFormat) IsValid() bool {
+	return f == ReportCSV || f == ReportXLSX
+}
+
+// ContentType returns the MIME type of files in the format
+func (f ReportFormat) ContentType() string {
+	if f == ReportXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ReportStatus is the lifecycle state of a report
+type ReportStatus string
+
+const (
+	// ReportPending reports are queued and have not started
+	ReportPending ReportStatus = "pending"
+	// ReportProcessing reports are being generated
+	ReportProcessing ReportStatus = "processing"
+	// ReportCompleted reports can be downloaded until they expire
+	ReportCompleted ReportStatus = "completed"
+	// ReportFailed reports could not be generated; see Error
+	ReportFailed ReportStatus = "failed"
+)
+
+// IsFinished checks if the report has reached a terminal state
+func (s ReportStatus) IsFinished() bool {
+	return s == ReportCompleted || s == ReportFailed
+}
+
+// Report is a fleet usage summary generated in the background
+// Reports without an organization cover every device and are only created by admins.
+// From and To are inclusive UTC dates.
+type Report struct {
+	ID          uuid.UUID    `json:"id" db:"id"`
+	RequestedBy uuid.UUID    `json:"requestedBy" db:"requested_by"`
+	OrgID       *uuid.UUID   `json:"orgId,omitempty" db:"org_id"`
+	Format      ReportFormat `json:"format" db:"format"`
+	Status      ReportStatus `json:"status" db:"status"`
+	From        time.Time    `json:"from" db:"range_start"`
+	To          time.Time    `json:"to" db:"range_end"`
+	Rows        int          `json:"rows" db:"row_count"`
+	Error       *string      `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time    `json:"createdAt" db:"created_at"`
+	CompletedAt *time.Time   `json:"completedAt,omitempty" db:"completed_at"`
+	ExpiresAt   time.Time    `json:"expiresAt" db:"expires_at"` // The report and its file are deleted after this
+	DownloadURL string       `json:"downloadUrl,omitempty" db:"-"`
+}
+
+// Filename returns the name the report is downloaded as
+func (r *Report) Filename() string {
+	return "avt-report-" + r.From.Format("2006-01-02") + "-" + r.To.Format("2006-01-02") + "." + string(r.Format)
+}
+
+// ReportRow summarizes one device's sessions started in one week
+// Weeks start on Monday, UTC.
+type ReportRow struct {
+	DeviceID       string    `json:"deviceId"`
+	WeekStart      time.Time `json:"weekStart"`
+	Sessions       int       `json:"sessions"`
+	DistanceMeters float64   `json:"distanceMeters"`
+	UsageHours     float64   `json:"usageHours"`
+	MaxSpeed       float64   `json:"maxSpeed"` // km/h
+}
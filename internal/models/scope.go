@@ -0,0 +1,38 @@
+package models
+
+// Scope is a permission carried by a credential
+// Access tokens carry the scopes of their user's role, personal access tokens the scopes they were
+// minted with, and device API keys only telemetry:write. Routes name the scopes they accept.
+type Scope string
+
+// Supported scopes
+const (
+	ScopeTelemetryRead  Scope = "telemetry:read"  // Query telemetry
+	ScopeTelemetryWrite Scope = "telemetry:write" // Upload telemetry
+	ScopeDevicesManage  Scope = "devices:manage"  // View, claim and configure devices
+	ScopeAdmin          Scope = "admin"           // Administer users and the fleet
+)
+
+// IsValid checks if the scope is a known scope
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeTelemetryRead, ScopeTelemetryWrite, ScopeDevicesManage, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// IsGrantable checks if the scope can be granted to a personal access token
+// Administration needs an interactive login.
+func (s Scope) IsGrantable() bool {
+	return s.IsValid() && s != ScopeAdmin
+}
+
+// ScopesForRole returns the scopes granted to users with the given role
+func ScopesForRole(role Role) []Scope {
+	scopes := []Scope{ScopeTelemetryRead, ScopeTelemetryWrite, ScopeDevicesManage}
+	if role == RoleAdmin {
+		scopes = append(scopes, ScopeAdmin)
+	}
+	return scopes
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScope(t *testing.T) {
+	assert.True(t, ScopeDevicesManage.IsValid())
+	assert.False(t, Scope("telemetry:delete").IsValid())
+
+	assert.True(t, ScopeTelemetryWrite.IsGrantable())
+	assert.False(t, ScopeAdmin.IsGrantable())
+	assert.False(t, Scope("unknown").IsGrantable())
+}
+
+func TestScopesForRole(t *testing.T) {
+	assert.Equal(t, []Scope{ScopeTelemetryRead, ScopeTelemetryWrite, ScopeDevicesManage}, ScopesForRole(RoleUser))
+	assert.Contains(t, ScopesForRole(RoleAdmin), ScopeAdmin)
+	assert.NotContains(t, ScopesForRole(""), ScopeAdmin)
+}
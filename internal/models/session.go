@@ -0,0 +1,216 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session represents a recording session grouping telemetry data
+type Session struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	DeviceID  string     `json:"deviceId" db:"device_id"`             // Hardware device ID
+	UserID    *uuid.UUID `json:"userId,omitempty" db:"user_id"`       // Owner of the session
+	OrgID     *uuid.UUID `json:"orgId,omitempty" db:"org_id"`         // Organization the session is shared with
+	StartedAt time.Time  `json:"startedAt" db:"started_at"`           // When recording started
+	EndedAt   *time.Time `json:"endedAt,omitempty" db:"ended_at"`     // When recording ended (nil while active)
+	Name      *string    `json:"name,omitempty" db:"name"`            // User-friendly name
+	Location  *string    `json:"location,omitempty" db:"location"`    // e.g., track or venue name
+	Notes     *string    `json:"notes,omitempty" db:"notes"`          // Free-form notes
+	CircuitID *uuid.UUID `json:"circuitId,omitempty" db:"circuit_id"` // Known circuit detected from telemetry
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
+
+	// Cached aggregates
+	TotalDistance     *float64   `json:"totalDistance,omitempty" db:"total_distance"` // Meters
+	MaxSpeed          *float64   `json:"maxSpeed,omitempty" db:"max_speed"`           // km/h
+	AvgSpeed          *float64   `json:"avgSpeed,omitempty" db:"avg_speed"`           // km/h
+	MaxGForce         *float64   `json:"maxGForce,omitempty" db:"max_g_force"`        // Peak horizontal g
+	DataPointsCount   int64      `json:"dataPointsCount" db:"data_points_count"`
+	SummaryComputedAt *time.Time `json:"summaryComputedAt,omitempty" db:"summary_computed_at"` // When aggregates were last computed
+}
+
+// IsActive checks if the session is still recording
+func (s *Session) IsActive() bool {
+	return s.EndedAt == nil
+}
+
+// Duration returns the session duration, measured up to now for active sessions
+func (s *Session) Duration() time.Duration {
+	if s.EndedAt == nil {
+		return time.Since(s.StartedAt)
+	}
+	return s.EndedAt.Sub(s.StartedAt)
+}
+
+// IsOwnedBy checks if the session belongs to the given user
+func (s *Session) IsOwnedBy(userID uuid.UUID) bool {
+	return s.UserID != nil && *s.UserID == userID
+}
+
+// IsSummaryStale checks if the cached aggregates need recomputing
+// Active sessions are always stale since telemetry is still arriving.
+func (s *Session) IsSummaryStale() bool {
+	if s.SummaryComputedAt == nil || s.EndedAt == nil {
+		return true
+	}
+	return s.SummaryComputedAt.Before(*s.EndedAt)
+}
+
+// SessionSummary represents the aggregated statistics of a session
+type SessionSummary struct {
+	SessionID       uuid.UUID  `json:"sessionId"`
+	IsActive        bool       `json:"isActive"`
+	DurationSeconds float64    `json:"durationSeconds"`
+	TotalDistance   float64    `json:"totalDistance"` // Meters
+	MaxSpeed        *float64   `json:"maxSpeed,omitempty"`
+	AvgSpeed        *float64   `json:"avgSpeed,omitempty"`
+	MaxGForce       *float64   `json:"maxGForce,omitempty"`
+	DataPointsCount int64      `json:"dataPointsCount"`
+	ComputedAt      *time.Time `json:"computedAt,omitempty"`
+}
+
+// Summary returns the session's aggregated statistics
+func (s *Session) Summary() *SessionSummary {
+	summary := &SessionSummary{
+		SessionID:       s.ID,
+		IsActive:        s.IsActive(),
+		DurationSeconds: s.Duration().Seconds(),
+		MaxSpeed:        s.MaxSpeed,
+		AvgSpeed:        s.AvgSpeed,
+		MaxGForce:       s.MaxGForce,
+		DataPointsCount: s.DataPointsCount,
+		ComputedAt:      s.SummaryComputedAt,
+	}
+	if s.TotalDistance != nil {
+		summary.TotalDistance = *s.TotalDistance
+	}
+	return summary
+}
+
+// SessionResponse represents a session for API responses
+type SessionResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	DeviceID        string     `json:"deviceId"`
+	OrgID           *uuid.UUID `json:"orgId,omitempty"`
+	StartedAt       time.Time  `json:"startedAt"`
+	EndedAt         *time.Time `json:"endedAt,omitempty"`
+	Name            *string    `json:"name,omitempty"`
+	Location        *string    `json:"location,omitempty"`
+	Notes           *string    `json:"notes,omitempty"`
+	IsActive        bool       `json:"isActive"`
+	DurationSeconds float64    `json:"durationSeconds"`
+	TotalDistance   *float64   `json:"totalDistance,omitempty"`
+	MaxSpeed        *float64   `json:"maxSpeed,omitempty"`
+	AvgSpeed        *float64   `json:"avgSpeed,omitempty"`
+	MaxGForce       *float64   `json:"maxGForce,omitempty"`
+	DataPointsCount int64      `json:"dataPointsCount"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// ToResponse converts a Session to a SessionResponse
+func (s *Session) ToResponse() *SessionResponse {
+	return &SessionResponse{
+		ID:              s.ID,
+		DeviceID:        s.DeviceID,
+		OrgID:           s.OrgID,
+		StartedAt:       s.StartedAt,
+		EndedAt:         s.EndedAt,
+		Name:            s.Name,
+		Location:        s.Location,
+		Notes:           s.Notes,
+		IsActive:        s.IsActive(),
+		DurationSeconds: s.Duration().Seconds(),
+		TotalDistance:   s.TotalDistance,
+		MaxSpeed:        s.MaxSpeed,
+		AvgSpeed:        s.AvgSpeed,
+		MaxGForce:       s.MaxGForce,
+		DataPointsCount: s.DataPointsCount,
+		CreatedAt:       s.CreatedAt,
+		UpdatedAt:       s.UpdatedAt,
+	}
+}
+
+// TrackGeometry is a session's simplified path
+type TrackGeometry struct {
+	Geometry         json.RawMessage // GeoJSON LineString; nil when the session has fewer than two located points
+	Points           int64           // Located telemetry points in the session
+	SimplifiedPoints int             // Vertices left after simplification
+}
+
+// SpeedBandThresholds are the boundaries, in km/h, of the speed bands reported in session stats
+// Band i covers speeds from SpeedBandThresholds[i-1] up to SpeedBandThresholds[i]; the first band
+// starts at zero and the last one is open-ended.
+var SpeedBandThresholds = []float64{50, 100, 150, 200}
+
+// SpeedPercentiles are speed percentiles in km/h
+type SpeedPercentiles struct {
+	P50 *float64 `json:"p50,omitempty"`
+	P75 *float64 `json:"p75,omitempty"`
+	P90 *float64 `json:"p90,omitempty"`
+	P95 *float64 `json:"p95,omitempty"`
+	P99 *float64 `json:"p99,omitempty"`
+}
+
+// SpeedBandTime is the time a session spent within a speed band
+type SpeedBandTime struct {
+	MinSpeed float64  `json:"minSpeed"`           // km/h, inclusive
+	MaxSpeed *float64 `json:"maxSpeed,omitempty"` // km/h, exclusive; nil for the open-ended top band
+	Seconds  float64  `json:"seconds"`
+}
+
+// SessionStats are detailed telemetry statistics of a session, cached on the session row
+// Lateral g is taken from the Y axis and longitudinal g from the X axis, both as absolute values.
+type SessionStats struct {
+	SessionID        uuid.UUID        `json:"sessionId"`
+	Samples          int64            `json:"samples"`
+	Speed            SpeedPercentiles `json:"speed"`
+	MaxLateralG      *float64         `json:"maxLateralG,omitempty"`
+	AvgLateralG      *float64         `json:"avgLateralG,omitempty"`
+	MaxLongitudinalG *float64         `json:"maxLongitudinalG,omitempty"`
+	AvgLongitudinalG *float64         `json:"avgLongitudinalG,omitempty"`
+	AltitudeGain     float64          `json:"altitudeGain"` // Meters climbed, summed over MSL altitude increases
+	TimeInSpeedBands []SpeedBandTime  `json:"timeInSpeedBands"`
+	ComputedAt       time.Time        `json:"computedAt"`
+}
+
+// IsStaleFor checks if the cached stats need recomputing for the given session
+// Stats of active sessions are always stale since telemetry is still arriving.
+func (s *SessionStats) IsStaleFor(session *Session) bool {
+	if session.EndedAt == nil {
+		return true
+	}
+	return s.ComputedAt.Before(*session.EndedAt)
+}
+
+// SessionMarkerType identifies what a session marker records
+type SessionMarkerType string
+
+// Supported session marker types
+const (
+	SessionMarkerPitStop  SessionMarkerType = "pit_stop"
+	SessionMarkerIncident SessionMarkerType = "incident"
+	SessionMarkerSector   SessionMarkerType = "sector" // Splits the session into segments at the marker
+)
+
+// IsValid checks if the marker type is a known type
+func (t SessionMarkerType) IsValid() bool {
+	switch t {
+	case SessionMarkerPitStop, SessionMarkerIncident, SessionMarkerSector:
+		return true
+	}
+	return false
+}
+
+// SessionMarker is a labelled point in time within a session
+type SessionMarker struct {
+	ID        uuid.UUID         `json:"id" db:"id"`
+	SessionID uuid.UUID         `json:"sessionId" db:"session_id"`
+	UserID    *uuid.UUID        `json:"userId,omitempty" db:"user_id"` // Who added the marker
+	Type      SessionMarkerType `json:"type" db:"type"`
+	Label     *string           `json:"label,omitempty" db:"label"`
+	MarkedAt  time.Time         `json:"timestamp" db:"marked_at"`
+	CreatedAt time.Time         `json:"createdAt" db:"created_at"`
+}
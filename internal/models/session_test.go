@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_IsActive(t *testing.T) {
+	ended := time.Now()
+
+	active := &Session{ID: uuid.New(), StartedAt: time.Now().Add(-time.Hour)}
+	assert.True(t, active.IsActive())
+
+	finished := &Session{ID: uuid.New(), StartedAt: time.Now().Add(-time.Hour), EndedAt: &ended}
+	assert.False(t, finished.IsActive())
+}
+
+func TestSession_Duration(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(45 * time.Minute)
+
+	session := &Session{StartedAt: start, EndedAt: &end}
+	assert.Equal(t, 45*time.Minute, session.Duration())
+
+	active := &Session{StartedAt: time.Now().Add(-10 * time.Minute)}
+	assert.GreaterOrEqual(t, active.Duration(), 10*time.Minute)
+}
+
+func TestSession_IsOwnedBy(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		session  *Session
+		expected bool
+	}{
+		{
+			name:     "owned by user",
+			session:  &Session{UserID: &userID},
+			expected: true,
+		},
+		{
+			name:     "owned by another user",
+			session:  &Session{UserID: func() *uuid.UUID { id := uuid.New(); return &id }()},
+			expected: false,
+		},
+		{
+			name:     "anonymous session",
+			session:  &Session{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.session.IsOwnedBy(userID))
+		})
+	}
+}
+
+func TestSession_ToResponse(t *testing.T) {
+	userID := uuid.New()
+	start := time.Now().Add(-2 * time.Hour)
+	end := start.Add(90 * time.Minute)
+	name := "Track Day"
+	maxSpeed := 182.5
+
+	session := &Session{
+		ID:              uuid.New(),
+		DeviceID:        "RACEBOX-001",
+		UserID:          &userID,
+		StartedAt:       start,
+		EndedAt:         &end,
+		Name:            &name,
+		MaxSpeed:        &maxSpeed,
+		DataPointsCount: 1200,
+		CreatedAt:       start,
+		UpdatedAt:       end,
+	}
+
+	response := session.ToResponse()
+
+	assert.Equal(t, session.ID, response.ID)
+	assert.Equal(t, "RACEBOX-001", response.DeviceID)
+	assert.Equal(t, &name, response.Name)
+	assert.False(t, response.IsActive)
+	assert.Equal(t, float64(90*60), response.DurationSeconds)
+	assert.Equal(t, &maxSpeed, response.MaxSpeed)
+	assert.Equal(t, int64(1200), response.DataPointsCount)
+}
+
+func TestSession_IsSummaryStale(t *testing.T) {
+	endedAt := time.Now().Add(-time.Hour)
+	before := endedAt.Add(-time.Minute)
+	after := endedAt.Add(time.Minute)
+
+	assert.True(t, (&Session{}).IsSummaryStale(), "never computed")
+	assert.True(t, (&Session{SummaryComputedAt: &after}).IsSummaryStale(), "active session")
+	assert.True(t, (&Session{EndedAt: &endedAt, SummaryComputedAt: &before}).IsSummaryStale(), "computed before end")
+	assert.False(t, (&Session{EndedAt: &endedAt, SummaryComputedAt: &after}).IsSummaryStale())
+}
+
+func TestSessionStats_IsStaleFor(t *testing.T) {
+	endedAt := time.Now().Add(-time.Hour)
+
+	assert.True(t, (&SessionStats{ComputedAt: time.Now()}).IsStaleFor(&Session{}), "active session")
+	assert.True(t, (&SessionStats{ComputedAt: endedAt.Add(-time.Minute)}).IsStaleFor(&Session{EndedAt: &endedAt}), "computed before end")
+	assert.False(t, (&SessionStats{ComputedAt: endedAt.Add(time.Minute)}).IsStaleFor(&Session{EndedAt: &endedAt}))
+}
@@ -2,20 +2,63 @@
 package models
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Telemetry upload schema versions
+// Version 1 is the original RaceBox record; records without a schemaVersion are version 1.
+// Version 2 adds the optional vehicle block read from OBD-II / CAN adapters.
+const (
+	TelemetrySchemaV1 = 1
+	TelemetrySchemaV2 = 2
+
+	// LatestTelemetrySchemaVersion is the newest version the service knows how to decode
+	LatestTelemetrySchemaVersion = TelemetrySchemaV2
+)
+
+// Telemetry quality flags
+// Physically impossible points are stored as reported with these bits set instead of being
+// rejected, so charts can leave them out.
+const (
+	// QualityImpossibleSpeed marks a reported speed above MaxPlausibleSpeed
+	QualityImpossibleSpeed = 1 << iota
+	// QualityPositionJump marks a point too far from the device's previous one to have been reached
+	QualityPositionJump
+	// QualityNegativePDOP marks a negative position dilution of precision
+	QualityNegativePDOP
+	// QualityClockSkew marks a timestamp from a device clock that is off by more than the ingest threshold
+	QualityClockSkew
+)
+
+// MaxPlausibleSpeed is the fastest speed in km/h a tracked vehicle is expected to reach
+const MaxPlausibleSpeed = 500.0
+
 // TelemetryData represents complete telemetry data from a RaceBox device
 type TelemetryData struct {
 	// Database ID
 	ID int64 `json:"id,omitempty" db:"id"`
 
+	// Client-generated record ID (UUID); uploads repeating a stored clientId and timestamp are replays
+	ClientID *uuid.UUID `json:"clientId,omitempty" db:"client_id"`
+
+	// Upload schema version the record was decoded from
+	SchemaVersion int `json:"schemaVersion,omitempty" db:"schema_version"`
+
 	// UTC timestamp
 	Timestamp time.Time `json:"timestamp" db:"recorded_at"`
 
+	// Server time the record was received, set on ingest
+	ReceivedAt *time.Time `json:"receivedAt,omitempty" db:"received_at"`
+
+	// Estimated offset of the device clock in milliseconds, positive when it runs ahead; set on ingest
+	// when it can be estimated
+	ClockSkewMs *int64 `json:"clockSkewMs,omitempty" db:"clock_skew_ms"`
+
+	// Timestamp as reported by the device, set when ingest corrected a skewed Timestamp
+	DeviceTimestamp *time.Time `json:"deviceTimestamp,omitempty" db:"device_recorded_at"`
+
 	// Device and session identifiers
 	DeviceID  string     `json:"deviceId,omitempty" db:"device_id"`
 	SessionID *string    `json:"sessionId,omitempty" db:"session_id"`
@@ -30,6 +73,9 @@ type TelemetryData struct {
 	// Motion data
 	Motion MotionData `json:"motion"`
 
+	// Vehicle data from a paired OBD-II / CAN reader (schema version 2 and later)
+	Vehicle *VehicleData `json:"vehicle,omitempty"`
+
 	// Battery level (0-100%) or input voltage for Micro (in volts)
 	Battery float64 `json:"battery" db:"battery"`
 
@@ -41,6 +87,18 @@ type TelemetryData struct {
 
 	// Validity flags
 	ValidityFlags int `json:"validityFlags" db:"validity_flags"`
+
+	// Quality flags set on ingest for physically impossible points (Quality* bits); zero for clean points
+	QualityFlags int `json:"qualityFlags,omitempty" db:"quality_flags"`
+
+	// Channels the record's schema version does not define (e.g., wheel speed or OBD readings), stored as reported
+	Extras map[string]interface{} `json:"extras,omitempty" db:"extras"`
+
+	// Channels computed on ingest when derived channels are enabled; nil for records stored without them
+	Derived *DerivedChannels `json:"derived,omitempty"`
+
+	// Set on save when deduplication or a repeated clientId skipped the record because it was already stored
+	Duplicate bool `json:"-" db:"-"`
 }
 
 // BatchUploadRequest represents a batch upload request with idempotency support
@@ -124,127 +182,204 @@ type MotionData struct {
 	RotationZ float64 `json:"rotationZ" db:"rotation_z"`
 }
 
-// Validate validates the telemetry data for correctness
-func (t *TelemetryData) Validate() error {
-	// Validate timestamp
-	if t.Timestamp.IsZero() {
-		return fmt.Errorf("timestamp is required")
-	}
+// VehicleData represents engine and driver inputs read over OBD-II or CAN
+// Every channel is optional since adapters and vehicles expose different PIDs.
+type VehicleData struct {
+	// Engine speed in revolutions per minute
+	RPM *float64 `json:"rpm,omitempty" db:"rpm"`
 
-	// Validate GPS data
-	if err := t.GPS.Validate(); err != nil {
-		return fmt.Errorf("GPS validation failed: %w", err)
-	}
+	// Throttle position in percent (0-100)
+	Throttle *float64 `json:"throttle,omitempty" db:"throttle"`
 
-	// Validate Motion data
-	if err := t.Motion.Validate(); err != nil {
-		return fmt.Errorf("motion validation failed: %w", err)
-	}
+	// Brake line pressure in bar
+	BrakePressure *float64 `json:"brakePressure,omitempty" db:"brake_pressure"`
 
-	// Validate battery level (0-100% for percentage, or 0-30V for voltage)
-	if t.Battery < 0 || t.Battery > 100 {
-		// Allow higher values for voltage readings (up to 30V)
-		if t.Battery > 30 {
-			return fmt.Errorf("invalid battery value: %.2f (must be 0-100%% or 0-30V)", t.Battery)
-		}
-	}
+	// Engine coolant temperature in degrees Celsius
+	CoolantTemp *float64 `json:"coolantTemp,omitempty" db:"coolant_temp"`
 
-	return nil
+	// Selected gear (0 is neutral, -1 is reverse)
+	Gear *int `json:"gear,omitempty" db:"gear"`
 }
 
-// Validate validates GPS data for correctness
-func (g *GpsData) Validate() error {
-	// Validate latitude range
-	if g.Latitude < -90 || g.Latitude > 90 {
-		return fmt.Errorf("invalid latitude: %.7f (must be between -90 and 90)", g.Latitude)
+// Validate checks every field of the record, reporting all invalid ones as a *ValidationError
+func (t *TelemetryData) Validate() error {
+	v := newFieldValidator()
+	v.check(!t.Timestamp.IsZero(), "timestamp", "timestamp is required")
+	t.GPS.validate(v.nested("gps"), false)
+	t.Motion.validate(v.nested("motion"))
+	if t.Vehicle != nil {
+		t.Vehicle.validate(v.nested("vehicle"))
 	}
 
-	// Validate longitude range
-	if g.Longitude < -180 || g.Longitude > 180 {
-		return fmt.Errorf("invalid longitude: %.7f (must be between -180 and 180)", g.Longitude)
-	}
+	// Battery is a percentage, or the input voltage for the Micro, which never exceeds 30V
+	v.check(t.Battery >= 0 && t.Battery <= 100, "battery", "invalid battery value: %.2f (must be 0-100%% or 0-30V)", t.Battery)
 
-	// Validate speed (reasonable maximum: 500 km/h for racing)
-	if g.Speed < 0 || g.Speed > 500 {
-		return fmt.Errorf("invalid speed: %.2f km/h (must be between 0 and 500)", g.Speed)
-	}
+	return v.err()
+}
 
-	// Validate heading range
-	if g.Heading < 0 || g.Heading > 360 {
-		return fmt.Errorf("invalid heading: %.2f degrees (must be between 0 and 360)", g.Heading)
-	}
+// ClearReceipt drops the receive time, clock skew and derived channels of an uploaded record
+// They are set on ingest; uploaded values would skip the clock checks.
+func (t *TelemetryData) ClearReceipt() {
+	t.ReceivedAt, t.ClockSkewMs, t.DeviceTimestamp = nil, nil, nil
+	t.Derived = nil
+}
 
-	// Validate altitude (reasonable range: -500m to 9000m)
-	if g.WgsAltitude < -500 || g.WgsAltitude > 9000 {
-		return fmt.Errorf("invalid WGS altitude: %.2f m (must be between -500 and 9000)", g.WgsAltitude)
+// CheckQuality flags readings that cannot be physically right
+// It only looks at the record itself; position jumps need the previous point and are flagged on ingest.
+func (t *TelemetryData) CheckQuality() {
+	if t.GPS.Speed > MaxPlausibleSpeed {
+		t.QualityFlags |= QualityImpossibleSpeed
 	}
-
-	if g.MslAltitude < -500 || g.MslAltitude > 9000 {
-		return fmt.Errorf("invalid MSL altitude: %.2f m (must be between -500 and 9000)", g.MslAltitude)
+	if t.GPS.PDOP < 0 {
+		t.QualityFlags |= QualityNegativePDOP
 	}
+}
 
-	// Validate number of satellites
-	if g.NumSatellites < 0 || g.NumSatellites > 50 {
-		return fmt.Errorf("invalid number of satellites: %d (must be between 0 and 50)", g.NumSatellites)
-	}
+// IsClean reports whether no quality flag is set
+func (t *TelemetryData) IsClean() bool {
+	return t.QualityFlags == 0
+}
 
-	// Validate fix status
-	if g.FixStatus < 0 || g.FixStatus > 3 {
-		return fmt.Errorf("invalid fix status: %d (must be 0, 2, or 3)", g.FixStatus)
-	}
+// ValidateBasic checks only what storage and queries depend on: a timestamp and in-range coordinates
+// It backs lenient ingest, where out-of-range readings from other fields are stored as reported.
+func (t *TelemetryData) ValidateBasic() error {
+	v := newFieldValidator()
+	v.check(!t.Timestamp.IsZero(), "timestamp", "timestamp is required")
+	t.GPS.validate(v.nested("gps"), true)
+	return v.err()
+}
 
-	// Validate accuracy values (must be non-negative)
-	if g.HorizontalAccuracy < 0 {
-		return fmt.Errorf("invalid horizontal accuracy: %.2f (must be non-negative)", g.HorizontalAccuracy)
-	}
+// Validate validates GPS data for correctness
+func (g *GpsData) Validate() error {
+	v := newFieldValidator()
+	g.validate(v, false)
+	return v.err()
+}
 
-	if g.VerticalAccuracy < 0 {
-		return fmt.Errorf("invalid vertical accuracy: %.2f (must be non-negative)", g.VerticalAccuracy)
+// validate checks the GPS fields, only the coordinates when coordinatesOnly is set
+func (g *GpsData) validate(v fieldValidator, coordinatesOnly bool) {
+	v.check(g.Latitude >= -90 && g.Latitude <= 90, "latitude", "invalid latitude: %.7f (must be between -90 and 90)", g.Latitude)
+	v.check(g.Longitude >= -180 && g.Longitude <= 180, "longitude", "invalid longitude: %.7f (must be between -180 and 180)", g.Longitude)
+	if coordinatesOnly {
+		return
 	}
 
-	if g.SpeedAccuracy < 0 {
-		return fmt.Errorf("invalid speed accuracy: %.2f (must be non-negative)", g.SpeedAccuracy)
-	}
+	// Speeds above MaxPlausibleSpeed are flagged by CheckQuality instead
+	v.check(g.Speed >= 0, "speed", "invalid speed: %.2f km/h (must not be negative)", g.Speed)
+	v.check(g.Heading >= 0 && g.Heading <= 360, "heading", "invalid heading: %.2f degrees (must be between 0 and 360)", g.Heading)
 
-	if g.HeadingAccuracy < 0 || g.HeadingAccuracy > 360 {
-		return fmt.Errorf("invalid heading accuracy: %.2f (must be between 0 and 360)", g.HeadingAccuracy)
-	}
+	// Reasonable altitude range: -500m to 9000m
+	v.check(g.WgsAltitude >= -500 && g.WgsAltitude <= 9000, "wgsAltitude", "invalid WGS altitude: %.2f m (must be between -500 and 9000)", g.WgsAltitude)
+	v.check(g.MslAltitude >= -500 && g.MslAltitude <= 9000, "mslAltitude", "invalid MSL altitude: %.2f m (must be between -500 and 9000)", g.MslAltitude)
 
-	// Validate PDOP (reasonable range: 0-50)
-	if g.PDOP < 0 || g.PDOP > 50 {
-		return fmt.Errorf("invalid PDOP: %.2f (must be between 0 and 50)", g.PDOP)
-	}
+	v.check(g.NumSatellites >= 0 && g.NumSatellites <= 50, "numSatellites", "invalid number of satellites: %d (must be between 0 and 50)", g.NumSatellites)
+	v.check(g.FixStatus >= 0 && g.FixStatus <= 3, "fixStatus", "invalid fix status: %d (must be 0, 2, or 3)", g.FixStatus)
 
-	return nil
+	// Accuracy values must be non-negative
+	v.check(g.HorizontalAccuracy >= 0, "horizontalAccuracy", "invalid horizontal accuracy: %.2f (must be non-negative)", g.HorizontalAccuracy)
+	v.check(g.VerticalAccuracy >= 0, "verticalAccuracy", "invalid vertical accuracy: %.2f (must be non-negative)", g.VerticalAccuracy)
+	v.check(g.SpeedAccuracy >= 0, "speedAccuracy", "invalid speed accuracy: %.2f (must be non-negative)", g.SpeedAccuracy)
+	v.check(g.HeadingAccuracy >= 0 && g.HeadingAccuracy <= 360, "headingAccuracy", "invalid heading accuracy: %.2f (must be between 0 and 360)", g.HeadingAccuracy)
+
+	// Reasonable PDOP maximum: 50; negative values are flagged by CheckQuality instead
+	v.check(g.PDOP <= 50, "pdop", "invalid PDOP: %.2f (must be at most 50)", g.PDOP)
 }
 
 // Validate validates motion sensor data for correctness
 func (m *MotionData) Validate() error {
-	// Validate G-forces (reasonable range: -10g to +10g for racing)
-	if m.GForceX < -10 || m.GForceX > 10 {
-		return fmt.Errorf("invalid G-force X: %.3f (must be between -10 and 10)", m.GForceX)
-	}
+	v := newFieldValidator()
+	m.validate(v)
+	return v.err()
+}
 
-	if m.GForceY < -10 || m.GForceY > 10 {
-		return fmt.Errorf("invalid G-force Y: %.3f (must be between -10 and 10)", m.GForceY)
-	}
+// validate checks the motion fields
+func (m *MotionData) validate(v fieldValidator) {
+	// Reasonable G-force range: -10g to +10g for racing
+	v.check(m.GForceX >= -10 && m.GForceX <= 10, "gForceX", "invalid G-force X: %.3f (must be between -10 and 10)", m.GForceX)
+	v.check(m.GForceY >= -10 && m.GForceY <= 10, "gForceY", "invalid G-force Y: %.3f (must be between -10 and 10)", m.GForceY)
+	v.check(m.GForceZ >= -10 && m.GForceZ <= 10, "gForceZ", "invalid G-force Z: %.3f (must be between -10 and 10)", m.GForceZ)
+
+	// Reasonable rotation rate range: -360 to +360 degrees/second
+	v.check(m.RotationX >= -360 && m.RotationX <= 360, "rotationX", "invalid rotation X: %.2f deg/s (must be between -360 and 360)", m.RotationX)
+	v.check(m.RotationY >= -360 && m.RotationY <= 360, "rotationY", "invalid rotation Y: %.2f deg/s (must be between -360 and 360)", m.RotationY)
+	v.check(m.RotationZ >= -360 && m.RotationZ <= 360, "rotationZ", "invalid rotation Z: %.2f deg/s (must be between -360 and 360)", m.RotationZ)
+}
 
-	if m.GForceZ < -10 || m.GForceZ > 10 {
-		return fmt.Errorf("invalid G-force Z: %.3f (must be between -10 and 10)", m.GForceZ)
-	}
+// Validate validates vehicle data for correctness
+func (d *VehicleData) Validate() error {
+	v := newFieldValidator()
+	d.validate(v)
+	return v.err()
+}
 
-	// Validate rotation rates (reasonable range: -360 to +360 degrees/second)
-	if m.RotationX < -360 || m.RotationX > 360 {
-		return fmt.Errorf("invalid rotation X: %.2f deg/s (must be between -360 and 360)", m.RotationX)
+// validate checks the vehicle channels that are present
+func (d *VehicleData) validate(v fieldValidator) {
+	// Reasonable engine speed maximum: 20000 rpm for racing engines
+	if d.RPM != nil {
+		v.check(*d.RPM >= 0 && *d.RPM <= 20000, "rpm", "invalid RPM: %.0f (must be between 0 and 20000)", *d.RPM)
 	}
-
-	if m.RotationY < -360 || m.RotationY > 360 {
-		return fmt.Errorf("invalid rotation Y: %.2f deg/s (must be between -360 and 360)", m.RotationY)
+	if d.Throttle != nil {
+		v.check(*d.Throttle >= 0 && *d.Throttle <= 100, "throttle", "invalid throttle: %.1f%% (must be between 0 and 100)", *d.Throttle)
 	}
-
-	if m.RotationZ < -360 || m.RotationZ > 360 {
-		return fmt.Errorf("invalid rotation Z: %.2f deg/s (must be between -360 and 360)", m.RotationZ)
+	// Reasonable brake pressure maximum: 250 bar
+	if d.BrakePressure != nil {
+		v.check(*d.BrakePressure >= 0 && *d.BrakePressure <= 250, "brakePressure", "invalid brake pressure: %.1f bar (must be between 0 and 250)", *d.BrakePressure)
+	}
+	// OBD-II reports coolant temperatures from -40 to 215 °C
+	if d.CoolantTemp != nil {
+		v.check(*d.CoolantTemp >= -40 && *d.CoolantTemp <= 215, "coolantTemp", "invalid coolant temperature: %.1f °C (must be between -40 and 215)", *d.CoolantTemp)
+	}
+	if d.Gear != nil {
+		v.check(*d.Gear >= -1 && *d.Gear <= 10, "gear", "invalid gear: %d (must be between -1 and 10)", *d.Gear)
 	}
+}
+
+// IngestStats summarizes telemetry ingestion over a time window
+type IngestStats struct {
+	Since          time.Time  `json:"since"`
+	DataPoints     int64      `json:"dataPoints"`    // Telemetry points recorded in the window
+	ActiveDevices  int64      `json:"activeDevices"` // Distinct devices that reported in the window
+	ActiveUsers    int64      `json:"activeUsers"`   // Distinct owners with telemetry in the window
+	Batches        int64      `json:"batches"`       // Batch uploads received in the window
+	BatchRecords   int64      `json:"batchRecords"`  // Records received through batch uploads
+	LastRecordedAt *time.Time `json:"lastRecordedAt,omitempty"`
+}
+
+// OrphanedTelemetry summarizes one device's telemetry stored without an owner
+type OrphanedTelemetry struct {
+	DeviceID        string     `json:"deviceId"` // Empty for records uploaded without a device ID
+	DataPoints      int64      `json:"dataPoints"`
+	FirstRecordedAt time.Time  `json:"firstRecordedAt"`
+	LastRecordedAt  time.Time  `json:"lastRecordedAt"`
+	ClaimedBy       *uuid.UUID `json:"claimedBy,omitempty"` // Current owner if the device has since been claimed
+}
+
+// ProcessedTelemetry is the smoothed counterpart of a telemetry record, produced once its session ends
+type ProcessedTelemetry struct {
+	TelemetryID int64     // ID of the raw record
+	RecordedAt  time.Time // Timestamp of the raw record
+	Latitude    float64   // Filtered latitude in degrees
+	Longitude   float64   // Filtered longitude in degrees
+	Speed       float64   // Filtered speed in km/h
+}
 
-	return nil
+// TelemetryBucket represents downsampled telemetry for one time bucket
+type TelemetryBucket struct {
+	Bucket     time.Time `json:"bucket"`     // Start of the bucket
+	Samples    int64     `json:"samples"`    // Raw data points in the bucket
+	AvgSpeed   float64   `json:"avgSpeed"`   // km/h
+	MaxSpeed   float64   `json:"maxSpeed"`   // km/h
+	AvgGForceX float64   `json:"avgGForceX"` // Longitudinal g
+	AvgGForceY float64   `json:"avgGForceY"` // Lateral g
+	AvgGForceZ float64   `json:"avgGForceZ"` // Vertical g
+	MaxGForce  float64   `json:"maxGForce"`  // Peak horizontal g
+	AvgBattery float64   `json:"avgBattery"`
+	MinBattery float64   `json:"minBattery"`
+
+	// Vehicle channels are omitted for buckets without vehicle data
+	AvgRPM           *float64 `json:"avgRpm,omitempty"`
+	MaxRPM           *float64 `json:"maxRpm,omitempty"`
+	AvgThrottle      *float64 `json:"avgThrottle,omitempty"`      // Percent
+	MaxBrakePressure *float64 `json:"maxBrakePressure,omitempty"` // bar
+	MaxCoolantTemp   *float64 `json:"maxCoolantTemp,omitempty"`   // °C
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TelemetryDeletionKind identifies how a telemetry correction selects the records it deletes
+type TelemetryDeletionKind string
+
+const (
+	// TelemetryDeletionTrim deletes a session's records before StartsAt and after EndsAt
+	TelemetryDeletionTrim TelemetryDeletionKind = "trim"
+	// TelemetryDeletionRange deletes a device's records from StartsAt to EndsAt
+	TelemetryDeletionRange TelemetryDeletionKind = "range"
+)
+
+// TelemetryDeletion is a correction that soft-deletes telemetry records
+// Deleted records are hidden from every query and purged once UndoUntil has passed, unless the
+// correction is undone first.
+type TelemetryDeletion struct {
+	ID              uuid.UUID             `json:"id" db:"id"`
+	UserID          uuid.UUID             `json:"-" db:"user_id"`                                   // Owner of the deleted records
+	RequestedBy     *uuid.UUID            `json:"requestedBy,omitempty" db:"requested_by"`          // Null once the account is deleted
+	DeviceID        string                `json:"deviceId" db:"device_id"`                          // Hardware device ID
+	SessionID       *uuid.UUID            `json:"sessionId,omitempty" db:"session_id"`              // Set for session trims
+	Kind            TelemetryDeletionKind `json:"kind" db:"kind"`                                   // trim or range
+	StartsAt        *time.Time            `json:"start,omitempty" db:"starts_at"`                   // Nil trims nothing from the start
+	EndsAt          *time.Time            `json:"end,omitempty" db:"ends_at"`                       // Nil trims nothing from the end
+	Points          int64                 `json:"points" db:"points"`                               // Records deleted
+	FirstRecordedAt *time.Time            `json:"firstRecordedAt,omitempty" db:"first_recorded_at"` // Earliest deleted record
+	LastRecordedAt  *time.Time            `json:"lastRecordedAt,omitempty" db:"last_recorded_at"`   // Latest deleted record
+	UndoUntil       time.Time             `json:"undoUntil" db:"undo_until"`
+	UndoneAt        *time.Time            `json:"undoneAt,omitempty" db:"undone_at"`
+	PurgedAt        *time.Time            `json:"purgedAt,omitempty" db:"purged_at"`
+	CreatedAt       time.Time             `json:"createdAt" db:"created_at"`
+}
+
+// CanUndo checks if the deleted records can still be restored at the given time
+func (d *TelemetryDeletion) CanUndo(now time.Time) bool {
+	return d.UndoneAt == nil && d.PurgedAt == nil && now.Before(d.UndoUntil)
+}
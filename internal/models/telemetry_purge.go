@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// TelemetryPurgeAction is how a purge removes the matching records of a chunk
+type TelemetryPurgeAction string
+
+const (
+	// TelemetryPurgeDropChunk drops a chunk lying entirely within the purged range
+	TelemetryPurgeDropChunk TelemetryPurgeAction = "drop_chunk"
+	// TelemetryPurgeDelete deletes the matching records of a chunk that also holds other telemetry
+	TelemetryPurgeDelete TelemetryPurgeAction = "delete"
+)
+
+// TelemetryChunkPurge describes what a purge does to one telemetry hypertable chunk
+type TelemetryChunkPurge struct {
+	Chunk      string               `json:"chunk"` // Schema-qualified chunk table
+	RangeStart time.Time            `json:"rangeStart"`
+	RangeEnd   time.Time            `json:"rangeEnd"`
+	Compressed bool                 `json:"compressed"`
+	Action     TelemetryPurgeAction `json:"action"`
+	Rows       int64                `json:"rows"`      // Records removed from the chunk
+	Estimated  bool                 `json:"estimated"` // Rows comes from planner statistics rather than a count
+}
+
+// TelemetryPurge reports the telemetry an administrator purged, or would purge in a dry run
+// Purged records are deleted permanently; unlike corrections they cannot be undone.
+type TelemetryPurge struct {
+	DeviceID      string                `json:"deviceId,omitempty"` // Empty purges every device
+	From          time.Time             `json:"from"`
+	To            time.Time             `json:"to"` // Exclusive
+	DryRun        bool                  `json:"dryRun"`
+	Chunks        []TelemetryChunkPurge `json:"chunks"`
+	ChunksDropped int                   `json:"chunksDropped"`
+	ChunksTrimmed int                   `json:"chunksTrimmed"` // Chunks records are deleted from
+	Rows          int64                 `json:"rows"`
+	RowsEstimated bool                  `json:"rowsEstimated"` // Set when Rows includes estimates for dropped chunks
+}
+
+// AddChunk appends a chunk to the purge and updates the totals
+func (p *TelemetryPurge) AddChunk(chunk TelemetryChunkPurge) {
+	p.Chunks = append(p.Chunks, chunk)
+	p.Rows += chunk.Rows
+	if chunk.Estimated {
+		p.RowsEstimated = true
+	}
+	switch chunk.Action {
+	case TelemetryPurgeDropChunk:
+		p.ChunksDropped++
+	case TelemetryPurgeDelete:
+		p.ChunksTrimmed++
+	}
+}
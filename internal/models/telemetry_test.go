@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVehicleData_Validate(t *testing.T) {
+	rpm, throttle, brake, coolant, gear := 7200.0, 85.0, 42.5, 96.0, 4
+	valid := VehicleData{RPM: &rpm, Throttle: &throttle, BrakePressure: &brake, CoolantTemp: &coolant, Gear: &gear}
+	assert.NoError(t, valid.Validate())
+
+	// Every channel is optional
+	assert.NoError(t, (&VehicleData{}).Validate())
+
+	overRev, overThrottle, reverse, tooCold, noGear := 25000.0, 101.0, -1, -50.0, 11
+	assert.Error(t, (&VehicleData{RPM: &overRev}).Validate())
+	assert.Error(t, (&VehicleData{Throttle: &overThrottle}).Validate())
+	assert.NoError(t, (&VehicleData{Gear: &reverse}).Validate())
+	assert.Error(t, (&VehicleData{CoolantTemp: &tooCold}).Validate())
+	assert.Error(t, (&VehicleData{Gear: &noGear}).Validate())
+}
+
+func TestTelemetryData_ValidateVehicle(t *testing.T) {
+	throttle := 120.0
+	telemetry := TelemetryData{
+		Timestamp: time.Now(),
+		Vehicle:   &VehicleData{Throttle: &throttle},
+	}
+	var validationErr *ValidationError
+	require.ErrorAs(t, telemetry.Validate(), &validationErr)
+	assert.Equal(t, []FieldError{{Field: "vehicle.throttle", Message: "invalid throttle: 120.0% (must be between 0 and 100)"}}, validationErr.Fields)
+
+	// Lenient validation stores vehicle readings as reported
+	assert.NoError(t, telemetry.ValidateBasic())
+}
+
+func TestTelemetryData_CheckQuality(t *testing.T) {
+	telemetry := TelemetryData{Timestamp: time.Now(), GPS: GpsData{Speed: 180, PDOP: 1.2}}
+	telemetry.CheckQuality()
+	assert.True(t, telemetry.IsClean())
+
+	// Impossible readings are flagged instead of failing validation
+	telemetry.GPS.Speed = 650
+	telemetry.GPS.PDOP = -1
+	assert.NoError(t, telemetry.Validate())
+	telemetry.CheckQuality()
+	assert.Equal(t, QualityImpossibleSpeed|QualityNegativePDOP, telemetry.QualityFlags)
+	assert.False(t, telemetry.IsClean())
+
+	telemetry.GPS.Speed = -5
+	assert.ErrorContains(t, telemetry.Validate(), "invalid speed")
+}
+
+func TestTelemetryData_ValidateReportsEveryField(t *testing.T) {
+	telemetry := TelemetryData{
+		GPS:     GpsData{Latitude: 91, Heading: 400, NumSatellites: -1, HorizontalAccuracy: -2},
+		Battery: -5,
+	}
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, telemetry.Validate(), &validationErr)
+	fields := make([]string, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		fields[i] = field.Field
+	}
+	assert.Equal(t, []string{"timestamp", "gps.latitude", "gps.heading", "gps.numSatellites", "gps.horizontalAccuracy", "battery"}, fields)
+	assert.Contains(t, validationErr.Error(), "timestamp is required; invalid latitude")
+
+	// Lenient validation only reports the timestamp and coordinates
+	require.ErrorAs(t, telemetry.ValidateBasic(), &validationErr)
+	assert.Len(t, validationErr.Fields, 2)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/geo"
+)
+
+// Start/finish line length bounds in meters
+const (
+	minStartFinishLineLength = 2.0
+	maxStartFinishLineLength = 500.0
+)
+
+// Track represents a user-defined circuit with a start/finish line used for lap timing
+type Track struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"userId" db:"user_id"` // Owner of the track
+	Name      string    `json:"name" db:"name"`
+	LineStart geo.Point `json:"lineStart"` // First endpoint of the start/finish line
+	LineEnd   geo.Point `json:"lineEnd"`   // Second endpoint of the start/finish line
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Validate checks the start/finish line is a plausible gate across the track
+func (t *Track) Validate() error {
+	if !t.LineStart.IsValid() || !t.LineEnd.IsValid() {
+		return fmt.Errorf("start/finish line coordinates are out of range")
+	}
+
+	length := geo.Distance(t.LineStart, t.LineEnd)
+	if length < minStartFinishLineLength || length > maxStartFinishLineLength {
+		return fmt.Errorf("start/finish line must be between %.0f and %.0f meters long (got %.1f)",
+			minStartFinishLineLength, maxStartFinishLineLength, length)
+	}
+
+	return nil
+}
+
+// IsOwnedBy checks if the track belongs to the given user
+func (t *Track) IsOwnedBy(userID uuid.UUID) bool {
+	return t.UserID == userID
+}
+
+// Circuit is a known venue from the seeded catalog, used to tag where sessions were recorded
+type Circuit struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	Name           string    `json:"name" db:"name"`
+	Country        string    `json:"country" db:"country"` // ISO 3166-1 alpha-2
+	Center         geo.Point `json:"center"`               // Centroid of the circuit boundary
+	DistanceMeters float64   `json:"distanceMeters"`       // From the queried point to the boundary; 0 when inside
+}
+
+// Lap represents one timed lap between two consecutive start/finish line crossings
+type Lap struct {
+	Number          int       `json:"number"` // 1-based lap number within the session
+	StartedAt       time.Time `json:"startedAt"`
+	EndedAt         time.Time `json:"endedAt"`
+	DurationSeconds float64   `json:"durationSeconds"` // Lap time
+	Distance        float64   `json:"distance"`        // Meters
+	MaxSpeed        float64   `json:"maxSpeed"`        // km/h
+	AvgSpeed        float64   `json:"avgSpeed"`        // km/h, derived from distance and lap time
+	MaxGForce       float64   `json:"maxGForce"`       // Peak horizontal g
+	DataPoints      int64     `json:"dataPoints"`
+	IsBest          bool      `json:"isBest"` // Fastest lap of the session
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadStatus is the lifecycle state of a resumable upload
+type UploadStatus string
+
+const (
+	// UploadOpen uploads are receiving chunks
+	UploadOpen UploadStatus = "open"
+	// UploadPending uploads have every byte and are waiting to be ingested
+	UploadPending UploadStatus = "pending"
+	// UploadProcessing uploads are being ingested
+	UploadProcessing UploadStatus = "processing"
+	// UploadCompleted uploads have been fully ingested; rejected lines are reported in Errors
+	UploadCompleted UploadStatus = "completed"
+	// UploadFailed uploads stopped before every line was ingested; see Error
+	UploadFailed UploadStatus = "failed"
+)
+
+// IsFinished checks if the upload has reached a terminal state
+func (s UploadStatus) IsFinished() bool {
+	return s == UploadCompleted || s == UploadFailed
+}
+
+// UploadLineError reports why a line of an upload was rejected
+type UploadLineError struct {
+	Line  int    `json:"line"` // 1-based line number in the assembled upload
+	Error string `json:"error"`
+}
+
+// Upload tracks a large NDJSON telemetry upload sent in chunks and ingested in the background
+type Upload struct {
+	ID             uuid.UUID         `json:"id" db:"id"`
+	UserID         uuid.UUID         `json:"userId" db:"user_id"`
+	DeviceID       string            `json:"deviceId" db:"device_id"` // Records without a deviceId are stored for this device
+	Size           int64             `json:"size" db:"size"`          // Declared total size in bytes
+	ReceivedBytes  int64             `json:"receivedBytes" db:"received_bytes"`
+	ProcessedBytes int64             `json:"processedBytes" db:"processed_bytes"`
+	Status         UploadStatus      `json:"status" db:"status"`
+	Lines          int               `json:"lines" db:"lines"`       // Lines ingested so far, including blank ones
+	Accepted       int               `json:"accepted" db:"accepted"` // Records stored
+	Skipped        int               `json:"skipped" db:"skipped"`   // Duplicates skipped by deduplication
+	Rejected       int               `json:"rejected" db:"rejected"` // Lines that failed to parse or validate
+	Errors         []UploadLineError `json:"errors" db:"line_errors"`
+	Error          *string           `json:"error,omitempty" db:"error"`
+	CreatedAt      time.Time         `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updatedAt" db:"updated_at"`
+	CompletedAt    *time.Time        `json:"completedAt,omitempty" db:"completed_at"`
+}
+
+// IsOwnedBy checks if the upload belongs to the given user
+func (u *Upload) IsOwnedBy(userID uuid.UUID) bool {
+	return u.UserID == userID
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Plan identifies a user's subscription plan
+type Plan string
+
+const (
+	// PlanFree is the default plan for new accounts
+	PlanFree Plan = "free"
+	// PlanPro raises the usage limits
+	PlanPro Plan = "pro"
+)
+
+// IsValid checks if the plan is a known plan
+func (p Plan) IsValid() bool {
+	return p == PlanFree || p == PlanPro
+}
+
+// PlanLimits holds a plan's usage limits; a zero limit means unlimited
+type PlanLimits struct {
+	TelemetryPointsPerMonth int64
+	MaxDevices              int64
+}
+
+// UsageCounter reports consumption of a single quota
+type UsageCounter struct {
+	Used  int64  `json:"used"`
+	Limit *int64 `json:"limit"` // nil when unlimited
+}
+
+// Usage reports a user's plan and current consumption
+type Usage struct {
+	Plan            Plan         `json:"plan"`
+	PeriodStart     time.Time    `json:"periodStart"`
+	PeriodEnd       time.Time    `json:"periodEnd"`
+	TelemetryPoints UsageCounter `json:"telemetryPoints"`
+	Devices         UsageCounter `json:"devices"`
+}
+
+// UsagePeriod returns the calendar month (UTC) containing t as a half-open [start, end) range
+func UsagePeriod(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
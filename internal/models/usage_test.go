@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsagePeriod(t *testing.T) {
+	start, end := UsagePeriod(time.Date(2024, 12, 31, 23, 30, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	// Periods are calendar months in UTC regardless of the input's location
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	start, _ = UsagePeriod(time.Date(2024, 3, 1, 1, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), start)
+}
+
+func TestPlan_IsValid(t *testing.T) {
+	assert.True(t, PlanFree.IsValid())
+	assert.True(t, PlanPro.IsValid())
+	assert.False(t, Plan("enterprise").IsValid())
+}
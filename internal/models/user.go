@@ -6,6 +6,21 @@ import (
 	"github.com/google/uuid"
 )
 
+// Role identifies a user's access level
+type Role string
+
+const (
+	// RoleUser is the default role for regular accounts
+	RoleUser Role = "user"
+	// RoleAdmin grants access to the admin API
+	RoleAdmin Role = "admin"
+)
+
+// IsValid checks if the role is a known role
+func (r Role) IsValid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
 // User represents a user account in the system
 type User struct {
 	ID                         uuid.UUID  `json:"id" db:"id"`
@@ -20,6 +35,12 @@ type User struct {
 	UpdatedAt                  time.Time  `json:"updatedAt" db:"updated_at"`
 	LastLoginAt                *time.Time `json:"lastLoginAt,omitempty" db:"last_login_at"`
 	IsActive                   bool       `json:"isActive" db:"is_active"`
+	Role                       Role       `json:"role" db:"role"`
+}
+
+// IsAdmin checks if the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
 }
 
 // UserProfile represents user profile information
@@ -43,6 +64,7 @@ type UserResponse struct {
 	UpdatedAt     time.Time  `json:"updatedAt"`
 	LastLoginAt   *time.Time `json:"lastLoginAt,omitempty"`
 	IsActive      bool       `json:"isActive"`
+	Role          Role       `json:"role"`
 }
 
 // ToResponse converts a User to a UserResponse (safe for API)
@@ -55,6 +77,7 @@ func (u *User) ToResponse() *UserResponse {
 		UpdatedAt:     u.UpdatedAt,
 		LastLoginAt:   u.LastLoginAt,
 		IsActive:      u.IsActive,
+		Role:          u.Role,
 	}
 }
 
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to their account at an OpenID Connect identity provider
+type UserIdentity struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	Issuer      string     `json:"issuer" db:"issuer"`   // Provider issuer URL
+	Subject     string     `json:"subject" db:"subject"` // Stable user ID at the provider
+	Email       string     `json:"email,omitempty" db:"email"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty" db:"last_login_at"`
+}
@@ -225,3 +225,16 @@ func TestUserWithProfile_NoProfile(t *testing.T) {
 	assert.Nil(t, userWithProfile.Profile)
 	assert.Equal(t, userID, userWithProfile.User.ID)
 }
+
+func TestUser_IsAdmin(t *testing.T) {
+	assert.True(t, (&User{Role: RoleAdmin}).IsAdmin())
+	assert.False(t, (&User{Role: RoleUser}).IsAdmin())
+	assert.False(t, (&User{}).IsAdmin())
+}
+
+func TestRole_IsValid(t *testing.T) {
+	assert.True(t, RoleUser.IsValid())
+	assert.True(t, RoleAdmin.IsValid())
+	assert.False(t, Role("superuser").IsValid())
+	assert.False(t, Role("").IsValid())
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes one invalid field of an uploaded record
+type FieldError struct {
+	Field   string `json:"field"`   // JSON path of the field, e.g. "gps.latitude"
+	Message string `json:"message"` // What is wrong with the value
+}
+
+// ValidationError lists every invalid field of a record
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error joins the field messages
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// fieldValidator collects field errors under a JSON path prefix
+type fieldValidator struct {
+	prefix string
+	fields *[]FieldError
+}
+
+// newFieldValidator creates a validator for a record's top-level fields
+func newFieldValidator() fieldValidator {
+	return fieldValidator{fields: &[]FieldError{}}
+}
+
+// nested returns a validator for the fields of the named object
+func (v fieldValidator) nested(name string) fieldValidator {
+	return fieldValidator{prefix: v.prefix + name + ".", fields: v.fields}
+}
+
+// check records an error for the field unless ok holds
+func (v fieldValidator) check(ok bool, field, format string, args ...interface{}) {
+	if !ok {
+		*v.fields = append(*v.fields, FieldError{Field: v.prefix + field, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// err returns the collected errors, or nil when every field is valid
+func (v fieldValidator) err() error {
+	if len(*v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: *v.fields}
+}
@@ -0,0 +1,341 @@
+// Package mqtt provides an MQTT subscriber that bridges device telemetry into the telemetry repository.
+package mqtt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// maxMessageRecords mirrors the HTTP batch limit for a single MQTT message
+const maxMessageRecords = 1000
+
+// disconnectQuiesceMs is how long the client waits for in-flight work when disconnecting
+const disconnectQuiesceMs = 250
+
+var (
+	// ErrInvalidTopic is returned when a topic does not match the subscription filter
+	ErrInvalidTopic = errors.New("topic does not match subscription filter")
+	// ErrInvalidPayload is returned when a message payload cannot be decoded
+	ErrInvalidPayload = errors.New("invalid telemetry payload")
+	// ErrDeviceMismatch is returned when a payload's deviceId differs from the topic's device
+	ErrDeviceMismatch = errors.New("payload deviceId does not match topic")
+)
+
+// GeofenceEvaluator schedules background geofence evaluation of saved telemetry
+type GeofenceEvaluator interface {
+	Enqueue(records []*models.TelemetryData)
+}
+
+// HealthMonitor schedules background device health tracking of saved telemetry
+type HealthMonitor interface {
+	Enqueue(records []*models.TelemetryData)
+}
+
+// AlertEvaluator schedules background alert rule evaluation of saved telemetry
+type AlertEvaluator interface {
+	Enqueue(records []*models.TelemetryData)
+}
+
+// DevicePresence tracks devices coming online and going offline
+type DevicePresence interface {
+	Seen(device *models.Device)
+}
+
+// IngestAuditRecorder stores ingest audit entries
+type IngestAuditRecorder interface {
+	Record(entry *models.IngestAuditEntry)
+}
+
+// Bridge subscribes to MQTT telemetry topics and writes received data through the telemetry repository
+// Messages carry either a single telemetry object or a JSON array of them, in the HTTP ingest format.
+// The device ID is taken from the topic level matched by the single-level wildcard (avt/{deviceId}/telemetry).
+type Bridge struct {
+	cfg        config.MQTTConfig
+	repo       repository.TelemetryRepository
+	deviceRepo repository.DeviceRepository // Optional: attributes telemetry to registered device owners
+	geofences  GeofenceEvaluator           // Optional: nil disables geofence evaluation
+	health     HealthMonitor               // Optional: nil disables device health tracking
+	alerts     AlertEvaluator              // Optional: nil disables alert rule evaluation
+	presence   DevicePresence              // Optional: nil disables device online/offline tracking
+	audit      IngestAuditRecorder         // Optional: nil disables the ingest audit log
+	lenient    bool                        // Validate only timestamps and coordinates
+}
+
+// NewBridge creates a new MQTT ingestion bridge
+func NewBridge(cfg config.MQTTConfig, repo repository.TelemetryRepository, deviceRepo repository.DeviceRepository) *Bridge {
+	return &Bridge{
+		cfg:        cfg,
+		repo:       repo,
+		deviceRepo: deviceRepo,
+	}
+}
+
+// WithGeofenceEvaluator sets the evaluator that checks received telemetry against geofences
+func (b *Bridge) WithGeofenceEvaluator(evaluator GeofenceEvaluator) *Bridge {
+	b.geofences = evaluator
+	return b
+}
+
+// WithHealthMonitor sets the monitor that tracks device health from received telemetry
+func (b *Bridge) WithHealthMonitor(monitor HealthMonitor) *Bridge {
+	b.health = monitor
+	return b
+}
+
+// WithAlertEvaluator sets the evaluator that checks received telemetry against alert rules
+func (b *Bridge) WithAlertEvaluator(evaluator AlertEvaluator) *Bridge {
+	b.alerts = evaluator
+	return b
+}
+
+// WithPresence sets the tracker notified when messages refresh a registered device's last-seen time
+func (b *Bridge) WithPresence(presence DevicePresence) *Bridge {
+	b.presence = presence
+	return b
+}
+
+// WithAuditLog sets the recorder every received message is logged to
+func (b *Bridge) WithAuditLog(audit IngestAuditRecorder) *Bridge {
+	b.audit = audit
+	return b
+}
+
+// WithLenientValidation accepts records whose only problems are out-of-range sensor readings
+func (b *Bridge) WithLenientValidation(lenient bool) *Bridge {
+	b.lenient = lenient
+	return b
+}
+
+// Run connects to the broker, subscribes to the telemetry topic and processes messages until ctx is cancelled
+// The client reconnects and resubscribes automatically if the connection drops.
+func (b *Bridge) Run(ctx context.Context) error {
+	opts := paho.NewClientOptions().
+		AddBroker(b.cfg.BrokerURL).
+		SetClientID(b.cfg.ClientID).
+		SetUsername(b.cfg.Username).
+		SetPassword(b.cfg.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true)
+
+	// Subscribe on every (re)connect since clean sessions drop subscriptions
+	opts.SetOnConnectHandler(func(client paho.Client) {
+		token := client.Subscribe(b.cfg.Topic, byte(b.cfg.QoS), func(_ paho.Client, msg paho.Message) {
+			if err := b.HandleMessage(ctx, msg.Topic(), msg.Payload()); err != nil {
+				slog.Warn("MQTT: dropped message", "topic", msg.Topic(), "error", err)
+			}
+		})
+		if token.Wait() && token.Error() != nil {
+			slog.Error("MQTT: failed to subscribe", "topic", b.cfg.Topic, "error", token.Error())
+			return
+		}
+		slog.Info("MQTT bridge subscribed", "topic", b.cfg.Topic, "broker_url", b.cfg.BrokerURL)
+	})
+	opts.SetConnectionLostHandler(func(_ paho.Client, err error) {
+		slog.Warn("MQTT: connection lost", "error", err)
+	})
+
+	client := paho.NewClient(opts)
+	token := client.Connect()
+
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return fmt.Errorf("failed to connect to MQTT broker: %w", err)
+		}
+	case <-ctx.Done():
+		client.Disconnect(disconnectQuiesceMs)
+		return nil
+	}
+
+	<-ctx.Done()
+	client.Disconnect(disconnectQuiesceMs)
+	slog.Info("MQTT bridge stopped")
+	return nil
+}
+
+// HandleMessage decodes, validates and stores the telemetry carried by a single MQTT message
+func (b *Bridge) HandleMessage(ctx context.Context, topic string, payload []byte) error {
+	if b.audit == nil {
+		return b.handleMessage(ctx, topic, payload, &models.IngestAuditEntry{})
+	}
+
+	start := time.Now()
+	entry := &models.IngestAuditEntry{
+		CreatedAt: start.UTC(),
+		Source:    models.IngestSourceMQTT,
+		Result:    models.IngestResultAccepted,
+	}
+	err := b.handleMessage(ctx, topic, payload, entry)
+	if err != nil {
+		entry.Error = err.Error()
+		if entry.Result == models.IngestResultAccepted {
+			entry.Result = models.IngestResultRejected
+		}
+	}
+	entry.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	b.audit.Record(entry)
+	return err
+}
+
+// handleMessage processes a message, filling in the audit entry's device, owner and record count
+// Storage failures mark the entry as failed; any other error means the message was rejected.
+func (b *Bridge) handleMessage(ctx context.Context, topic string, payload []byte, entry *models.IngestAuditEntry) error {
+	deviceID, err := deviceIDFromTopic(b.cfg.Topic, topic)
+	if err != nil {
+		return err
+	}
+	entry.DeviceID = deviceID
+
+	records, err := decodePayload(payload)
+	if err != nil {
+		return err
+	}
+	entry.Records = len(records)
+
+	for i, record := range records {
+		// The topic identifies the device; payloads may omit deviceId but must not contradict it
+		if record.DeviceID == "" {
+			record.DeviceID = deviceID
+		} else if record.DeviceID != deviceID {
+			return fmt.Errorf("%w: record %d has %q, topic has %q", ErrDeviceMismatch, i, record.DeviceID, deviceID)
+		}
+
+		validate := record.Validate
+		if b.lenient {
+			validate = record.ValidateBasic
+		}
+		if err := validate(); err != nil {
+			return fmt.Errorf("validation failed for record %d: %w", i, err)
+		}
+	}
+
+	b.attributeOwner(ctx, deviceID, records)
+	entry.UserID = records[0].UserID
+
+	if len(records) == 1 {
+		if err := b.repo.Save(ctx, records[0]); err != nil {
+			entry.Result = models.IngestResultFailed
+			return fmt.Errorf("failed to save telemetry: %w", err)
+		}
+	} else if err := b.repo.SaveBatch(ctx, records); err != nil {
+		entry.Result = models.IngestResultFailed
+		return fmt.Errorf("failed to save telemetry batch: %w", err)
+	}
+
+	if b.geofences != nil {
+		b.geofences.Enqueue(records)
+	}
+	if b.health != nil {
+		b.health.Enqueue(records)
+	}
+	if b.alerts != nil {
+		b.alerts.Enqueue(records)
+	}
+	return nil
+}
+
+// attributeOwner sets the owner of a registered device on the records and refreshes its last-seen time
+// Telemetry from unregistered devices is stored without an owner, as with anonymous HTTP uploads.
+func (b *Bridge) attributeOwner(ctx context.Context, deviceID string, records []*models.TelemetryData) {
+	if b.deviceRepo == nil {
+		return
+	}
+
+	device, err := b.deviceRepo.GetByDeviceID(ctx, deviceID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrDeviceNotFound) {
+			slog.Error("MQTT: failed to look up device", "device_id", deviceID, "error", err)
+		}
+		return
+	}
+
+	for _, record := range records {
+		record.UserID = &device.UserID
+	}
+
+	if err := b.deviceRepo.UpdateLastSeen(ctx, deviceID); err != nil {
+		slog.Warn("Failed to update device last_seen", "device_id", deviceID, "error", err)
+		return
+	}
+	if b.presence != nil {
+		b.presence.Seen(device)
+	}
+}
+
+// decodePayload parses a single telemetry object or an array of telemetry objects
+func decodePayload(payload []byte) ([]*models.TelemetryData, error) {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("%w: empty payload", ErrInvalidPayload)
+	}
+
+	if trimmed[0] != '[' {
+		var record models.TelemetryData
+		if err := json.Unmarshal(trimmed, &record); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+		record.ClearReceipt()
+		return []*models.TelemetryData{&record}, nil
+	}
+
+	var records []*models.TelemetryData
+	if err := json.Unmarshal(trimmed, &records); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: empty batch", ErrInvalidPayload)
+	}
+	if len(records) > maxMessageRecords {
+		return nil, fmt.Errorf("%w: batch too large (max %d records)", ErrInvalidPayload, maxMessageRecords)
+	}
+	for i, record := range records {
+		if record == nil {
+			return nil, fmt.Errorf("%w: record %d is null", ErrInvalidPayload, i)
+		}
+		record.ClearReceipt()
+	}
+
+	return records, nil
+}
+
+// deviceIDFromTopic extracts the topic level matched by the filter's single-level wildcard
+func deviceIDFromTopic(filter, topic string) (string, error) {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	if len(filterLevels) != len(topicLevels) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidTopic, topic)
+	}
+
+	deviceID := ""
+	for i, level := range filterLevels {
+		switch {
+		case level == "+":
+			if deviceID == "" {
+				deviceID = topicLevels[i]
+			}
+		case level != topicLevels[i]:
+			return "", fmt.Errorf("%w: %s", ErrInvalidTopic, topic)
+		}
+	}
+
+	if deviceID == "" {
+		return "", fmt.Errorf("%w: no device ID in %s", ErrInvalidTopic, topic)
+	}
+	if len(deviceID) > 50 {
+		return "", fmt.Errorf("%w: device ID too long in %s", ErrInvalidTopic, topic)
+	}
+
+	return deviceID, nil
+}
@@ -0,0 +1,212 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const samplePoint = `{"timestamp":"2024-01-10T08:00:00Z","gps":{"latitude":42.6977,"longitude":23.3219,"speed":120.5,"heading":90,"numSatellites":12,"fixStatus":3,"isFixValid":true},"motion":{"gForceX":0.1,"gForceY":0.2,"gForceZ":1.0},"battery":85}`
+
+func newTestBridge() (*Bridge, *repository.MockRepository, *repository.MockDeviceRepository) {
+	repo := repository.NewMockRepository()
+	deviceRepo := repository.NewMockDeviceRepository()
+	cfg := config.MQTTConfig{Topic: "avt/+/telemetry"}
+	return NewBridge(cfg, repo, deviceRepo), repo, deviceRepo
+}
+
+func TestBridge_HandleMessage_SinglePoint(t *testing.T) {
+	bridge, repo, _ := newTestBridge()
+
+	var saved *models.TelemetryData
+	repo.SaveFunc = func(_ context.Context, data *models.TelemetryData) error {
+		saved = data
+		return nil
+	}
+
+	err := bridge.HandleMessage(context.Background(), "avt/RACEBOX-001/telemetry", []byte(samplePoint))
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, "RACEBOX-001", saved.DeviceID, "device ID should come from the topic")
+	assert.Nil(t, saved.UserID, "unregistered devices are stored without an owner")
+	assert.InDelta(t, 120.5, saved.GPS.Speed, 0.001)
+}
+
+func TestBridge_HandleMessage_BatchAttributedToOwner(t *testing.T) {
+	bridge, repo, deviceRepo := newTestBridge()
+	ownerID := uuid.New()
+
+	deviceRepo.GetByDeviceIDFunc = func(_ context.Context, deviceID string) (*models.Device, error) {
+		return &models.Device{ID: uuid.New(), DeviceID: deviceID, UserID: ownerID}, nil
+	}
+	lastSeenUpdated := false
+	deviceRepo.UpdateLastSeenFunc = func(_ context.Context, deviceID string) error {
+		assert.Equal(t, "RACEBOX-001", deviceID)
+		lastSeenUpdated = true
+		return nil
+	}
+
+	var saved []*models.TelemetryData
+	repo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+		saved = data
+		return nil
+	}
+
+	payload := "[" + samplePoint + "," + samplePoint + "]"
+	err := bridge.HandleMessage(context.Background(), "avt/RACEBOX-001/telemetry", []byte(payload))
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+	for _, record := range saved {
+		require.NotNil(t, record.UserID)
+		assert.Equal(t, ownerID, *record.UserID)
+		assert.Equal(t, "RACEBOX-001", record.DeviceID)
+	}
+	assert.True(t, lastSeenUpdated)
+}
+
+type recordingEvaluator struct {
+	records []*models.TelemetryData
+}
+
+func (e *recordingEvaluator) Enqueue(records []*models.TelemetryData) {
+	e.records = append(e.records, records...)
+}
+
+func TestBridge_HandleMessage_EnqueuesGeofenceEvaluation(t *testing.T) {
+	bridge, _, _ := newTestBridge()
+	evaluator := &recordingEvaluator{}
+	bridge.WithGeofenceEvaluator(evaluator)
+
+	payload := "[" + samplePoint + "," + samplePoint + "]"
+	require.NoError(t, bridge.HandleMessage(context.Background(), "avt/RACEBOX-001/telemetry", []byte(payload)))
+	assert.Len(t, evaluator.records, 2)
+}
+
+func TestBridge_HandleMessage_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		topic   string
+		payload string
+		wantErr error
+	}{
+		{"topic outside filter", "other/RACEBOX-001/telemetry", samplePoint, ErrInvalidTopic},
+		{"extra topic level", "avt/RACEBOX-001/telemetry/raw", samplePoint, ErrInvalidTopic},
+		{"malformed json", "avt/RACEBOX-001/telemetry", `{"timestamp":`, ErrInvalidPayload},
+		{"empty batch", "avt/RACEBOX-001/telemetry", `[]`, ErrInvalidPayload},
+		{"null record", "avt/RACEBOX-001/telemetry", `[null]`, ErrInvalidPayload},
+		{"device mismatch", "avt/RACEBOX-001/telemetry", `{"deviceId":"RACEBOX-002","timestamp":"2024-01-10T08:00:00Z"}`, ErrDeviceMismatch},
+		{"validation failure", "avt/RACEBOX-001/telemetry", `{"timestamp":"2024-01-10T08:00:00Z","gps":{"latitude":120}}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bridge, repo, _ := newTestBridge()
+			repo.SaveFunc = func(_ context.Context, _ *models.TelemetryData) error {
+				t.Fatal("rejected messages must not be saved")
+				return nil
+			}
+
+			err := bridge.HandleMessage(context.Background(), tt.topic, []byte(tt.payload))
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBridge_HandleMessage_LenientValidation(t *testing.T) {
+	bridge, repo, _ := newTestBridge()
+	bridge.WithLenientValidation(true)
+
+	saved := false
+	repo.SaveFunc = func(_ context.Context, _ *models.TelemetryData) error {
+		saved = true
+		return nil
+	}
+
+	// Out-of-range sensor readings are stored as reported
+	err := bridge.HandleMessage(context.Background(), "avt/RACEBOX-001/telemetry", []byte(`{"timestamp":"2024-01-10T08:00:00Z","gps":{"latitude":42.7,"heading":400},"battery":-1}`))
+	require.NoError(t, err)
+	assert.True(t, saved)
+
+	// Coordinates are still checked
+	err = bridge.HandleMessage(context.Background(), "avt/RACEBOX-001/telemetry", []byte(`{"timestamp":"2024-01-10T08:00:00Z","gps":{"latitude":120}}`))
+	assert.Error(t, err)
+}
+
+func TestBridge_HandleMessage_SaveError(t *testing.T) {
+	bridge, repo, _ := newTestBridge()
+	repo.SaveFunc = func(_ context.Context, _ *models.TelemetryData) error {
+		return errors.New("database unavailable")
+	}
+
+	err := bridge.HandleMessage(context.Background(), "avt/RACEBOX-001/telemetry", []byte(samplePoint))
+	assert.ErrorContains(t, err, "database unavailable")
+}
+
+type recordingAuditLog struct {
+	entries []*models.IngestAuditEntry
+}
+
+func (l *recordingAuditLog) Record(entry *models.IngestAuditEntry) {
+	l.entries = append(l.entries, entry)
+}
+
+func TestBridge_HandleMessage_AuditLog(t *testing.T) {
+	bridge, repo, deviceRepo := newTestBridge()
+	auditLog := &recordingAuditLog{}
+	bridge.WithAuditLog(auditLog)
+	ownerID := uuid.New()
+	deviceRepo.GetByDeviceIDFunc = func(_ context.Context, deviceID string) (*models.Device, error) {
+		return &models.Device{ID: uuid.New(), DeviceID: deviceID, UserID: ownerID}, nil
+	}
+
+	payload := "[" + samplePoint + "," + samplePoint + "]"
+	require.NoError(t, bridge.HandleMessage(context.Background(), "avt/RACEBOX-001/telemetry", []byte(payload)))
+	require.Error(t, bridge.HandleMessage(context.Background(), "avt/RACEBOX-001/telemetry", []byte(`{"timestamp":`)))
+	repo.SaveFunc = func(_ context.Context, _ *models.TelemetryData) error {
+		return errors.New("database unavailable")
+	}
+	require.Error(t, bridge.HandleMessage(context.Background(), "avt/RACEBOX-002/telemetry", []byte(samplePoint)))
+
+	require.Len(t, auditLog.entries, 3)
+
+	accepted := auditLog.entries[0]
+	assert.Equal(t, models.IngestSourceMQTT, accepted.Source)
+	assert.Equal(t, models.IngestResultAccepted, accepted.Result)
+	assert.Equal(t, "RACEBOX-001", accepted.DeviceID)
+	assert.Equal(t, 2, accepted.Records)
+	require.NotNil(t, accepted.UserID)
+	assert.Equal(t, ownerID, *accepted.UserID)
+	assert.Empty(t, accepted.Error)
+
+	rejected := auditLog.entries[1]
+	assert.Equal(t, models.IngestResultRejected, rejected.Result)
+	assert.Zero(t, rejected.Records)
+	assert.Contains(t, rejected.Error, ErrInvalidPayload.Error())
+
+	failed := auditLog.entries[2]
+	assert.Equal(t, models.IngestResultFailed, failed.Result)
+	assert.Equal(t, "RACEBOX-002", failed.DeviceID)
+	assert.Contains(t, failed.Error, "database unavailable")
+}
+
+func TestDeviceIDFromTopic(t *testing.T) {
+	deviceID, err := deviceIDFromTopic("fleet/+/avt/telemetry", "fleet/RACEBOX-9/avt/telemetry")
+	require.NoError(t, err)
+	assert.Equal(t, "RACEBOX-9", deviceID)
+
+	_, err = deviceIDFromTopic("avt/telemetry", "avt/telemetry")
+	assert.ErrorIs(t, err, ErrInvalidTopic, "filters without a wildcard carry no device ID")
+
+	_, err = deviceIDFromTopic("avt/+/telemetry", "avt//telemetry")
+	assert.ErrorIs(t, err, ErrInvalidTopic)
+}
@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// Keys of notification data
+const (
+	DataSessionID   = "sessionId"
+	DataDeviceID    = "deviceId"
+	DataAlertRuleID = "alertRuleId"
+
+	// Added to push payloads only; the inbox has them as fields
+	DataNotificationID = "notificationId"
+	DataType           = "type"
+)
+
+// SessionSummary builds the notification sent when an ended session's summary has been computed
+// The session must have an owner.
+func SessionSummary(session *models.Session) *models.Notification {
+	name := "Your session"
+	if session.Name != nil && *session.Name != "" {
+		name = *session.Name
+	}
+
+	lines := []string{fmt.Sprintf("Duration: %s", session.Duration().Round(time.Second))}
+	if session.TotalDistance != nil {
+		lines = append(lines, fmt.Sprintf("Distance: %.1f km", *session.TotalDistance/1000))
+	}
+	if session.MaxSpeed != nil {
+		lines = append(lines, fmt.Sprintf("Top speed: %.0f km/h", *session.MaxSpeed))
+	}
+	if session.AvgSpeed != nil {
+		lines = append(lines, fmt.Sprintf("Average speed: %.0f km/h", *session.AvgSpeed))
+	}
+	if session.MaxGForce != nil {
+		lines = append(lines, fmt.Sprintf("Peak g-force: %.2f g", *session.MaxGForce))
+	}
+
+	return &models.Notification{
+		UserID: *session.UserID,
+		Type:   models.NotificationSessionSummary,
+		Title:  "Session summary ready",
+		Body:   fmt.Sprintf("%s on %s is ready to review.\n\n%s", name, session.DeviceID, strings.Join(lines, "\n")),
+		Data: map[string]string{
+			DataSessionID: session.ID.String(),
+			DataDeviceID:  session.DeviceID,
+		},
+	}
+}
+
+// LowBattery builds the notification sent when a device's battery drops below the alert threshold
+func LowBattery(event *models.DeviceHealthEvent) *models.Notification {
+	return &models.Notification{
+		UserID: event.UserID,
+		Type:   models.NotificationLowBattery,
+		Title:  fmt.Sprintf("%s battery is low", event.DeviceID),
+		Body: fmt.Sprintf("%s reported a battery level of %.0f at %s. Charge it before your next session.",
+			event.DeviceID, event.Battery, event.RecordedAt.UTC().Format(time.RFC1123)),
+		Data: map[string]string{
+			DataDeviceID: event.DeviceID,
+		},
+	}
+}
+
+// NewLogin builds the notification sent when an account is signed in to from a new country
+// The login must have a location.
+func NewLogin(login *models.UserLoggedIn) *models.Notification {
+	return &models.Notification{
+		UserID: login.UserID,
+		Type:   models.NotificationNewLogin,
+		Title:  "New sign-in from " + login.Location.String(),
+		Body: fmt.Sprintf("Your account was signed in to from %s, a country it was not used from before.\n\n"+
+			"Time: %s\nIP address: %s\nDevice: %s\n\n"+
+			"If this wasn't you, change your password and sign out your other sessions.",
+			login.Location.String(), login.LoggedInAt.UTC().Format(time.RFC1123), login.IPAddress, login.UserAgent),
+	}
+}
+
+// ThresholdAlert builds the notification sent when an alert rule fires
+func ThresholdAlert(rule *models.AlertRule, event *models.AlertEvent) *models.Notification {
+	value, threshold := fmt.Sprintf("%.2f g", event.Value), fmt.Sprintf("%.2f g", event.Threshold)
+	if event.Metric == models.AlertMetricSpeed {
+		value, threshold = fmt.Sprintf("%.0f km/h", event.Value), fmt.Sprintf("%.0f km/h", event.Threshold)
+	}
+
+	data := map[string]string{
+		DataDeviceID:    event.DeviceID,
+		DataAlertRuleID: rule.ID.String(),
+	}
+	if event.SessionID != nil {
+		data[DataSessionID] = *event.SessionID
+	}
+
+	return &models.Notification{
+		UserID: event.UserID,
+		Type:   models.NotificationThresholdAlert,
+		Title:  fmt.Sprintf("%s: %s", rule.Name, event.DeviceID),
+		Body: fmt.Sprintf("%s recorded %s %s at %s, above the alert threshold of %s.",
+			event.DeviceID, strings.ReplaceAll(string(event.Metric), "_", " "), value,
+			event.RecordedAt.UTC().Format(time.RFC1123), threshold),
+		Data: data,
+	}
+}
@@ -0,0 +1,170 @@
+// Package notify keeps user notifications in their inbox and delivers them over the channels they chose.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/push"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// Channel delivers notifications outside the inbox, e.g. by email
+type Channel interface {
+	// Name identifies the channel in notification preferences
+	Name() models.NotificationChannel
+
+	// Deliver sends the notification to the user
+	Deliver(ctx context.Context, user *models.User, notification *models.Notification) error
+}
+
+// Service stores notifications and delivers them according to the user's preferences
+// Every notification is kept in the inbox. It is also delivered over each registered channel the
+// user's preference for its type enables, unless the user turned notifications off in their profile.
+type Service struct {
+	repo     repository.NotificationRepository
+	userRepo repository.UserRepository
+	channels []Channel
+}
+
+// NewService creates a notification service without delivery channels
+func NewService(repo repository.NotificationRepository, userRepo repository.UserRepository) *Service {
+	return &Service{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// WithChannel registers a delivery channel
+func (s *Service) WithChannel(channel Channel) *Service {
+	s.channels = append(s.channels, channel)
+	return s
+}
+
+// Notify stores the notification in its user's inbox and delivers it
+// Only the inbox write can fail; delivery errors are logged, like other best-effort alerts.
+func (s *Service) Notify(ctx context.Context, notification *models.Notification) error {
+	if err := s.repo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to store notification: %w", err)
+	}
+
+	if len(s.channels) == 0 {
+		return nil
+	}
+
+	enabled, err := s.repo.NotificationsEnabled(ctx, notification.UserID)
+	if err != nil {
+		slog.Error("Error checking whether notifications are enabled", "user_id", notification.UserID, "error", err)
+		return nil
+	}
+	if !enabled {
+		return nil
+	}
+
+	preference, err := s.preference(ctx, notification)
+	if err != nil {
+		slog.Error("Error loading notification preferences", "user_id", notification.UserID, "error", err)
+		return nil
+	}
+
+	var user *models.User
+	for _, channel := range s.channels {
+		if !preference.Wants(channel.Name()) {
+			continue
+		}
+		if user == nil {
+			if user, err = s.userRepo.GetByID(ctx, notification.UserID); err != nil {
+				slog.Error("Error loading user for notification", "user_id", notification.UserID, "error", err)
+				return nil
+			}
+		}
+		if err := channel.Deliver(ctx, user, notification); err != nil {
+			slog.Error("Error delivering notification",
+				"channel", channel.Name(), "type", notification.Type, "user_id", notification.UserID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// preference returns the user's preference for the notification's type, or the default one
+func (s *Service) preference(ctx context.Context, notification *models.Notification) (models.NotificationPreference, error) {
+	stored, err := s.repo.GetPreferences(ctx, notification.UserID)
+	if err != nil {
+		return models.NotificationPreference{}, err
+	}
+	for _, preference := range stored {
+		if preference.Type == notification.Type {
+			return preference, nil
+		}
+	}
+	return models.DefaultNotificationPreference(notification.Type), nil
+}
+
+// EmailChannel delivers notifications by email
+type EmailChannel struct {
+	emailService email.Service
+}
+
+// NewEmailChannel creates an email delivery channel
+func NewEmailChannel(emailService email.Service) *EmailChannel {
+	return &EmailChannel{emailService: emailService}
+}
+
+// Name implements Channel.Name
+func (c *EmailChannel) Name() models.NotificationChannel {
+	return models.NotificationChannelEmail
+}
+
+// Deliver implements Channel.Deliver
+func (c *EmailChannel) Deliver(ctx context.Context, user *models.User, notification *models.Notification) error {
+	return c.emailService.SendNotificationEmail(ctx, user.Email, email.Notification{
+		Title: notification.Title,
+		Body:  notification.Body,
+		Link:  link(notification),
+	})
+}
+
+// PushChannel delivers notifications to the user's registered mobile app installations
+type PushChannel struct {
+	sender *push.Sender
+}
+
+// NewPushChannel creates a push delivery channel
+func NewPushChannel(sender *push.Sender) *PushChannel {
+	return &PushChannel{sender: sender}
+}
+
+// Name implements Channel.Name
+func (c *PushChannel) Name() models.NotificationChannel {
+	return models.NotificationChannelPush
+}
+
+// Deliver implements Channel.Deliver
+// The notification's data is passed to the app along with its ID and type so it can open the
+// related screen and mark the notification read.
+func (c *PushChannel) Deliver(ctx context.Context, user *models.User, notification *models.Notification) error {
+	data := make(map[string]string, len(notification.Data)+2)
+	for key, value := range notification.Data {
+		data[key] = value
+	}
+	data[DataNotificationID] = notification.ID.String()
+	data[DataType] = string(notification.Type)
+
+	return c.sender.Send(ctx, user.ID, push.Message{
+		Title: notification.Title,
+		Body:  notification.Body,
+		Data:  data,
+	})
+}
+
+// link returns the path within the app a notification refers to, or "" when there is none
+func link(notification *models.Notification) string {
+	if sessionID := notification.Data[DataSessionID]; sessionID != "" {
+		return "/sessions/" + sessionID
+	}
+	return ""
+}
@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/email"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/push"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testService is a notification service emailing one user through a mock email service
+type testService struct {
+	*Service
+	user   *models.User
+	repo   *repository.MockNotificationRepository
+	emails *email.MockService
+	stored []*models.Notification
+}
+
+func newTestService() *testService {
+	ts := &testService{
+		user:   &models.User{ID: uuid.New(), Email: "driver@example.com"},
+		repo:   repository.NewMockNotificationRepository(),
+		emails: email.NewMockService(),
+	}
+
+	userRepo := repository.NewMockUserRepository()
+	userRepo.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.User, error) {
+		if id != ts.user.ID {
+			return nil, repository.ErrUserNotFound
+		}
+		return ts.user, nil
+	}
+	ts.repo.CreateFunc = func(_ context.Context, notification *models.Notification) error {
+		ts.stored = append(ts.stored, notification)
+		return nil
+	}
+
+	ts.Service = NewService(ts.repo, userRepo).WithChannel(NewEmailChannel(ts.emails))
+	return ts
+}
+
+func sessionSummary(userID uuid.UUID) *models.Notification {
+	ended := time.Date(2026, 5, 6, 12, 30, 0, 0, time.UTC)
+	distance, maxSpeed := 12400.0, 98.2
+	return SessionSummary(&models.Session{
+		ID:            uuid.New(),
+		DeviceID:      "kart-7",
+		UserID:        &userID,
+		StartedAt:     ended.Add(-25 * time.Minute),
+		EndedAt:       &ended,
+		TotalDistance: &distance,
+		MaxSpeed:      &maxSpeed,
+	})
+}
+
+func TestService_NotifyDefaults(t *testing.T) {
+	ts := newTestService()
+	ctx := context.Background()
+
+	// Alerts are emailed by default; session summaries only go to the inbox
+	require.NoError(t, ts.Notify(ctx, sessionSummary(ts.user.ID)))
+	require.NoError(t, ts.Notify(ctx, LowBattery(&models.DeviceHealthEvent{
+		DeviceID:   "kart-7",
+		UserID:     ts.user.ID,
+		Type:       models.DeviceHealthLowBattery,
+		RecordedAt: time.Now(),
+		Battery:    12,
+	})))
+
+	assert.Len(t, ts.stored, 2)
+	emails := ts.emails.GetNotificationEmails()
+	require.Len(t, emails, 1)
+	assert.Equal(t, "driver@example.com", emails[0].To)
+	assert.Equal(t, "kart-7 battery is low", emails[0].Notification.Title)
+	assert.Empty(t, emails[0].Notification.Link)
+}
+
+func TestService_NotifyPreferences(t *testing.T) {
+	ts := newTestService()
+	ts.repo.GetPreferencesFunc = func(_ context.Context, _ uuid.UUID) ([]models.NotificationPreference, error) {
+		return []models.NotificationPreference{
+			{Type: models.NotificationSessionSummary, Email: true, Push: false},
+		}, nil
+	}
+
+	notification := sessionSummary(ts.user.ID)
+	require.NoError(t, ts.Notify(context.Background(), notification))
+
+	require.Len(t, ts.stored, 1)
+	emails := ts.emails.GetNotificationEmails()
+	require.Len(t, emails, 1)
+	assert.Equal(t, "/sessions/"+notification.Data[DataSessionID], emails[0].Notification.Link)
+	assert.Contains(t, emails[0].Notification.Body, "Duration: 25m0s")
+	assert.Contains(t, emails[0].Notification.Body, "Distance: 12.4 km")
+	assert.Contains(t, emails[0].Notification.Body, "Top speed: 98 km/h")
+}
+
+func TestService_NotifyDisabled(t *testing.T) {
+	ts := newTestService()
+	ts.repo.NotificationsEnabledFunc = func(_ context.Context, _ uuid.UUID) (bool, error) {
+		return false, nil
+	}
+
+	require.NoError(t, ts.Notify(context.Background(), &models.Notification{
+		UserID: ts.user.ID,
+		Type:   models.NotificationNewLogin,
+		Title:  "New sign-in from France",
+	}))
+
+	// Still kept in the inbox, but not emailed
+	assert.Len(t, ts.stored, 1)
+	assert.Empty(t, ts.emails.GetNotificationEmails())
+}
+
+func TestService_NotifyInboxError(t *testing.T) {
+	ts := newTestService()
+	ts.repo.CreateFunc = func(_ context.Context, _ *models.Notification) error {
+		return errors.New("database unavailable")
+	}
+
+	err := ts.Notify(context.Background(), &models.Notification{UserID: ts.user.ID, Type: models.NotificationNewLogin})
+	assert.Error(t, err)
+	assert.Empty(t, ts.emails.GetNotificationEmails())
+}
+
+// pushProvider records the messages sent to each token
+type pushProvider map[string]push.Message
+
+func (p pushProvider) Send(_ context.Context, token string, message push.Message) error {
+	p[token] = message
+	return nil
+}
+
+func TestService_NotifyPush(t *testing.T) {
+	ts := newTestService()
+	tokens := repository.NewMockPushTokenRepository()
+	tokens.ListByUserIDFunc = func(_ context.Context, id uuid.UUID) ([]*models.PushToken, error) {
+		assert.Equal(t, ts.user.ID, id)
+		return []*models.PushToken{{ID: uuid.New(), UserID: id, Provider: models.PushProviderFCM, Token: "android"}}, nil
+	}
+	provider := pushProvider{}
+	ts.WithChannel(NewPushChannel(push.NewSender(tokens).WithProvider(models.PushProviderFCM, provider)))
+
+	// Session summaries are pushed by default
+	notification := sessionSummary(ts.user.ID)
+	notification.ID = uuid.New()
+	require.NoError(t, ts.Notify(context.Background(), notification))
+
+	assert.Empty(t, ts.emails.GetNotificationEmails())
+	require.Contains(t, provider, "android")
+	message := provider["android"]
+	assert.Equal(t, notification.Title, message.Title)
+	assert.Equal(t, notification.Data[DataSessionID], message.Data[DataSessionID])
+	assert.Equal(t, notification.ID.String(), message.Data[DataNotificationID])
+	assert.Equal(t, string(models.NotificationSessionSummary), message.Data[DataType])
+}
@@ -0,0 +1,278 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// idpServer fakes an OpenID provider's discovery, JWKS and token endpoints
+type idpServer struct {
+	*httptest.Server
+	key        *rsa.PrivateKey
+	kid        string
+	idToken    func() string // ID token returned by the token endpoint
+	keyFetches int
+	discovered int
+}
+
+func newIDPServer(t *testing.T) *idpServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	s := &idpServer{key: key, kid: "key-1"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		s.discovered++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/authorize?tenant=acme",
+			"token_endpoint":         s.URL + "/token",
+			"jwks_uri":               s.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, _ *http.Request) {
+		s.keyFetches++
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": s.kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "avt-client", user)
+		assert.Equal(t, "s3cr%2Ft", pass, "credentials are form-encoded")
+		assert.Equal(t, "authorization_code", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "https://app.example.com/sso/callback", r.PostForm.Get("redirect_uri"))
+
+		if r.PostForm.Get("code") != "good-code" || r.PostForm.Get("code_verifier") != "verifier-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"code expired"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "id_token": s.idToken()})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// sign returns an RS256 ID token with standard claims overridden by extra
+func (s *idpServer) sign(t *testing.T, extra jwt.MapClaims) string {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   s.URL,
+		"aud":   "avt-client",
+		"sub":   "00u1abcd",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": "nonce-1",
+	}
+	for name, value := range extra {
+		claims[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newTestProvider(s *idpServer) *Provider {
+	return NewProvider(Config{
+		IssuerURL:    s.URL + "/",
+		ClientID:     "avt-client",
+		ClientSecret: "s3cr/t",
+		RedirectURL:  "https://app.example.com/sso/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	s := newIDPServer(t)
+	provider := newTestProvider(s)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "/authorize", parsed.Path)
+	assert.Equal(t, "acme", query.Get("tenant"), "endpoint query parameters are kept")
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "avt-client", query.Get("client_id"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "state-1", query.Get("state"))
+	assert.Equal(t, "nonce-1", query.Get("nonce"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, codeChallenge("verifier-1"), query.Get("code_challenge"))
+
+	_, err = provider.AuthCodeURL(context.Background(), "state-2", "nonce-2", "verifier-2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.discovered, "metadata is cached")
+}
+
+func TestProvider_Discover_IssuerMismatch(t *testing.T) {
+	s := newIDPServer(t)
+	provider := NewProvider(Config{IssuerURL: s.URL + "/other-tenant", ClientID: "avt-client"})
+
+	_, err := provider.Discover(context.Background())
+	assert.ErrorContains(t, err, "failed to discover")
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	s := newIDPServer(t)
+	provider := newTestProvider(s)
+	s.idToken = func() string {
+		return s.sign(t, jwt.MapClaims{
+			"email":          "Rider@Example.com",
+			"email_verified": true,
+			"name":           "Rider One",
+			"groups":         []string{"avt-admins", "everyone"},
+		})
+	}
+
+	identity, err := provider.Exchange(context.Background(), "good-code", "nonce-1", "verifier-1")
+	require.NoError(t, err)
+	assert.Equal(t, s.URL, identity.Issuer)
+	assert.Equal(t, "00u1abcd", identity.Subject)
+	assert.Equal(t, "rider@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "Rider One", identity.Name)
+	assert.Equal(t, []string{"avt-admins", "everyone"}, identity.Groups)
+
+	_, err = provider.Exchange(context.Background(), "expired-code", "nonce-1", "verifier-1")
+	assert.ErrorIs(t, err, ErrInvalidGrant)
+
+	_, err = provider.Exchange(context.Background(), "good-code", "nonce-1", "wrong-verifier")
+	assert.ErrorIs(t, err, ErrInvalidGrant)
+}
+
+func TestProvider_VerifyIDToken(t *testing.T) {
+	s := newIDPServer(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": "x", "aud": "avt-client", "sub": "a", "exp": time.Now().Add(time.Hour).Unix(), "nonce": "nonce-1",
+	})
+	forged.Header["kid"] = "key-1"
+	forgedToken, err := forged.SignedString(other)
+	require.NoError(t, err)
+
+	symmetric, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss": s.URL, "aud": "avt-client", "sub": "a", "exp": time.Now().Add(time.Hour).Unix(), "nonce": "nonce-1",
+	}).SignedString([]byte("avt-client"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+		valid bool
+	}{
+		{"valid", s.sign(t, nil), "nonce-1", true},
+		{"nonce mismatch", s.sign(t, nil), "nonce-2", false},
+		{"wrong audience", s.sign(t, jwt.MapClaims{"aud": "other-client"}), "nonce-1", false},
+		{"wrong issuer", s.sign(t, jwt.MapClaims{"iss": "https://evil.example.com"}), "nonce-1", false},
+		{"expired", s.sign(t, jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}), "nonce-1", false},
+		{"missing subject", s.sign(t, jwt.MapClaims{"sub": ""}), "nonce-1", false},
+		{"other authorized party", s.sign(t, jwt.MapClaims{"aud": []string{"avt-client", "other"}, "azp": "other"}), "nonce-1", false},
+		{"foreign signature", forgedToken, "nonce-1", false},
+		{"symmetric algorithm", symmetric, "nonce-1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestProvider(s)
+
+			identity, err := provider.VerifyIDToken(context.Background(), tt.token, tt.nonce)
+			if tt.valid {
+				require.NoError(t, err)
+				assert.Equal(t, "00u1abcd", identity.Subject)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestProvider_VerifyIDToken_KeyRotation(t *testing.T) {
+	s := newIDPServer(t)
+	provider := newTestProvider(s)
+
+	_, err := provider.VerifyIDToken(context.Background(), s.sign(t, nil), "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.keyFetches)
+
+	// A token signed with a newly published key triggers a refetch once the interval has passed
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	s.key, s.kid = rotated, "key-2"
+	provider.keysFetched = time.Now().Add(-2 * keyRefreshInterval)
+
+	_, err = provider.VerifyIDToken(context.Background(), s.sign(t, nil), "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.keyFetches)
+
+	// Unknown key IDs do not refetch more often than the interval
+	s.kid = "key-3"
+	_, err = provider.VerifyIDToken(context.Background(), s.sign(t, nil), "nonce-1")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+	assert.Equal(t, 2, s.keyFetches)
+}
+
+func TestProvider_VerifyIDToken_AzureUsername(t *testing.T) {
+	s := newIDPServer(t)
+	provider := newTestProvider(s)
+
+	identity, err := provider.VerifyIDToken(context.Background(), s.sign(t, jwt.MapClaims{
+		"preferred_username": "Rider@Contoso.com",
+		"groups":             "avt-admins",
+	}), "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "rider@contoso.com", identity.Email)
+	assert.False(t, identity.EmailVerified)
+	assert.Equal(t, []string{"avt-admins"}, identity.Groups)
+}
+
+func TestJSONWebKey_EC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk := jsonWebKey{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}
+	public, err := jwk.publicKey()
+	require.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(public))
+
+	jwk.Y = base64.RawURLEncoding.EncodeToString(big.NewInt(7).Bytes())
+	_, err = jwk.publicKey()
+	assert.Error(t, err, "points off the curve are rejected")
+}
@@ -0,0 +1,146 @@
+// Package pagination implements the cursor paging contract shared by list endpoints.
+// Listings are ordered by a timestamp, with ties broken by ID. A page is requested with
+// limit and cursor query parameters and its response carries nextCursor, the opaque position
+// of its last item, until the last page.
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a cursor was not produced by Cursor.Encode for the listing
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor identifies an item's position in a listing
+type Cursor struct {
+	Time time.Time
+	ID   string
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.Time.UnixNano(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode parses a cursor produced by Encode
+func Decode(value string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{Time: time.Unix(0, n).UTC(), ID: id}, nil
+}
+
+// IntegerID accepts the IDs of listings keyed by integers, like telemetry
+func IntegerID(id string) error {
+	_, err := strconv.ParseInt(id, 10, 64)
+	return err
+}
+
+// UUID accepts the IDs of listings keyed by UUIDs
+func UUID(id string) error {
+	_, err := uuid.Parse(id)
+	return err
+}
+
+// Page is a requested page: at most Limit items, starting after the After cursor if set
+type Page struct {
+	Limit int
+	After *Cursor
+}
+
+// Parse reads the limit and cursor query parameters
+// The limit defaults to defaultLimit and must not exceed maxLimit. validID checks the cursor's ID
+// against the listing's key, so that cursors of other listings are rejected before they reach a query.
+func Parse(query url.Values, defaultLimit, maxLimit int, validID func(string) error) (Page, error) {
+	page := Page{Limit: defaultLimit}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxLimit {
+			return page, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+		}
+		page.Limit = n
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := Decode(value)
+		if err != nil || validID(cursor.ID) != nil {
+			return page, ErrInvalidCursor
+		}
+		page.After = &cursor
+	}
+
+	return page, nil
+}
+
+// Fetch returns the number of items to fetch for the page
+// It is one more than the limit, so Next can tell whether another page follows.
+func (p Page) Fetch() int {
+	return p.Limit + 1
+}
+
+// Next trims the extra item fetched for the page and returns the cursor of the following page,
+// or "" if items holds the last page
+func Next[T any](page Page, items []T, cursor func(T) Cursor) ([]T, string) {
+	if len(items) <= page.Limit {
+		return items, ""
+	}
+	items = items[:page.Limit]
+	return items, cursor(items[len(items)-1]).Encode()
+}
+
+// Binder appends a value to a query's arguments and returns its placeholder, e.g. "$3" or "?"
+type Binder func(value any) string
+
+// Keyset describes the order of a listing: by TimeColumn, then IDColumn, newest first unless Ascending
+type Keyset struct {
+	TimeColumn string
+	IDColumn   string
+	Ascending  bool
+}
+
+// Apply returns the condition selecting the items following the after cursor, binding the
+// cursor's time and ID, or "" when after is nil
+// The condition compares row values, so both columns must sort in the keyset's direction.
+func (k Keyset) Apply(after *Cursor, bind Binder) string {
+	if after == nil {
+		return ""
+	}
+
+	operator := "<"
+	if k.Ascending {
+		operator = ">"
+	}
+	timeParam := bind(after.Time)
+	idParam := bind(after.ID)
+	return fmt.Sprintf("(%s, %s) %s (%s, %s)", k.TimeColumn, k.IDColumn, operator, timeParam, idParam)
+}
+
+// OrderBy returns the ORDER BY expressions matching the keyset, without the keywords
+func (k Keyset) OrderBy() string {
+	direction := "DESC"
+	if k.Ascending {
+		direction = "ASC"
+	}
+	return k.TimeColumn + " " + direction + ", " + k.IDColumn + " " + direction
+}
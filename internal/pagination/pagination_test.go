@@ -0,0 +1,121 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{Time: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC), ID: uuid.New().String()}
+
+	decoded, err := Decode(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.Time.Equal(decoded.Time))
+	assert.Equal(t, cursor.ID, decoded.ID)
+}
+
+func TestDecode_TelemetryCursors(t *testing.T) {
+	// Cursors issued before this package existed keep working
+	recorded := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	legacy := base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(recorded.UnixNano(), 10) + ":99"))
+
+	cursor, err := Decode(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "99", cursor.ID)
+	assert.True(t, recorded.Equal(cursor.Time))
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("no-separator")),
+		base64.RawURLEncoding.EncodeToString([]byte("abc:1")),
+		base64.RawURLEncoding.EncodeToString([]byte("123:")),
+	} {
+		_, err := Decode(value)
+		assert.ErrorIs(t, err, ErrInvalidCursor, value)
+	}
+}
+
+func TestParse(t *testing.T) {
+	valid := Cursor{Time: time.Now(), ID: "42"}.Encode()
+
+	tests := []struct {
+		name        string
+		query       string
+		expectLimit int
+		expectAfter bool
+		expectErr   bool
+	}{
+		{name: "defaults", query: "", expectLimit: 50},
+		{name: "limit", query: "limit=10", expectLimit: 10},
+		{name: "cursor", query: "cursor=" + valid, expectLimit: 50, expectAfter: true},
+		{name: "limit zero", query: "limit=0", expectErr: true},
+		{name: "limit too large", query: "limit=101", expectErr: true},
+		{name: "malformed cursor", query: "cursor=garbage", expectErr: true},
+		{name: "cursor of another listing", query: "cursor=" + Cursor{Time: time.Now(), ID: uuid.New().String()}.Encode(), expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			page, err := Parse(query, 50, 100, IntegerID)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectLimit, page.Limit)
+			assert.Equal(t, tt.expectAfter, page.After != nil)
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	items := []int{3, 2, 1}
+	cursor := func(n int) Cursor {
+		return Cursor{Time: base.Add(time.Duration(n) * time.Hour), ID: strconv.Itoa(n)}
+	}
+	page := Page{Limit: 2}
+	assert.Equal(t, 3, page.Fetch())
+
+	trimmed, next := Next(page, items, cursor)
+	assert.Equal(t, []int{3, 2}, trimmed)
+	decoded, err := Decode(next)
+	require.NoError(t, err)
+	assert.Equal(t, "2", decoded.ID)
+
+	trimmed, next = Next(page, items[:2], cursor)
+	assert.Equal(t, []int{3, 2}, trimmed)
+	assert.Empty(t, next, "the last page has no next cursor")
+}
+
+func TestKeyset(t *testing.T) {
+	var args []any
+	bind := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+	after := &Cursor{Time: time.Now(), ID: "7"}
+
+	newest := Keyset{TimeColumn: "started_at", IDColumn: "id"}
+	assert.Empty(t, newest.Apply(nil, bind))
+	assert.Empty(t, args, "the first page binds nothing")
+	assert.Equal(t, "(started_at, id) < ($1, $2)", newest.Apply(after, bind))
+	assert.Equal(t, []any{after.Time, "7"}, args)
+	assert.Equal(t, "started_at DESC, id DESC", newest.OrderBy())
+
+	oldest := Keyset{TimeColumn: "claimed_at", IDColumn: "id", Ascending: true}
+	assert.Equal(t, "(claimed_at, id) > ($3, $4)", oldest.Apply(after, bind))
+	assert.Equal(t, "claimed_at ASC, id ASC", oldest.OrderBy())
+}
@@ -0,0 +1,199 @@
+// Package presence tracks devices coming online and going offline and broadcasts the transitions.
+package presence
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// sweepInterval is how often online devices are checked for going offline
+	sweepInterval = time.Minute
+
+	// subscriberBuffer bounds the events waiting for a slow subscriber before new ones are dropped
+	subscriberBuffer = 64
+)
+
+// device is the last known state of an online device
+type device struct {
+	id       uuid.UUID
+	userID   uuid.UUID
+	orgID    *uuid.UUID
+	lastSeen time.Time
+}
+
+// Tracker follows the last_seen updates of devices and emits online and offline transitions
+// A device comes online when it is seen while not tracked as online, and goes offline once it
+// has not been seen for models.DeviceOnlineWindow. Devices seen within the window before startup
+// are loaded as online, so restarts do not emit spurious transitions. Uploads handled by other
+// server instances are only noticed through last_seen_at when a device is about to go offline,
+// so each instance only emits online events for the uploads it handles.
+type Tracker struct {
+	repo   repository.DeviceRepository
+	window time.Duration
+	now    func() time.Time
+
+	mu          sync.Mutex
+	online      map[string]*device // Keyed by hardware device ID
+	subscribers map[chan *models.DevicePresenceEvent]struct{}
+}
+
+// NewTracker creates a new device presence tracker
+func NewTracker(repo repository.DeviceRepository) *Tracker {
+	return &Tracker{
+		repo:        repo,
+		window:      models.DeviceOnlineWindow,
+		now:         time.Now,
+		online:      make(map[string]*device),
+		subscribers: make(map[chan *models.DevicePresenceEvent]struct{}),
+	}
+}
+
+// Load marks the devices seen within the online window as online without emitting events
+func (t *Tracker) Load(ctx context.Context) error {
+	devices, err := t.repo.ListSeenSince(ctx, t.now().Add(-t.window))
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, d := range devices {
+		if _, ok := t.online[d.DeviceID]; !ok && d.LastSeenAt != nil {
+			t.online[d.DeviceID] = &device{id: d.ID, userID: d.UserID, orgID: d.OrgID, lastSeen: *d.LastSeenAt}
+		}
+	}
+	return nil
+}
+
+// Seen records that a device was just seen, emitting an online event if it was offline
+func (t *Tracker) Seen(d *models.Device) {
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.online[d.DeviceID]
+	if !ok {
+		state = &device{}
+		t.online[d.DeviceID] = state
+	}
+	// Ownership is refreshed on every sighting, so events follow reassigned and shared devices
+	state.id, state.userID, state.orgID, state.lastSeen = d.ID, d.UserID, d.OrgID, now
+
+	if !ok {
+		t.publish(event(models.DeviceEventOnline, d.DeviceID, state, now))
+	}
+}
+
+// Subscribe registers a subscriber for presence events
+// Events are dropped for subscribers that fall too far behind. Call the returned function to
+// unsubscribe; it closes the channel.
+func (t *Tracker) Subscribe() (<-chan *models.DevicePresenceEvent, func()) {
+	ch := make(chan *models.DevicePresenceEvent, subscriberBuffer)
+
+	t.mu.Lock()
+	t.subscribers[ch] = struct{}{}
+	t.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			t.mu.Lock()
+			delete(t.subscribers, ch)
+			t.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Run loads the online devices and emits offline transitions until the context is cancelled
+func (t *Tracker) Run(ctx context.Context) {
+	if err := t.Load(ctx); err != nil {
+		slog.Error("Error loading online devices", "error", err)
+	}
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.sweep(ctx)
+		}
+	}
+}
+
+// sweep emits offline events for devices not seen within the online window
+// Candidates are checked against the stored last_seen_at first, in case another instance saw them.
+func (t *Tracker) sweep(ctx context.Context) {
+	cutoff := t.now().Add(-t.window)
+
+	t.mu.Lock()
+	var stale []string
+	for deviceID, state := range t.online {
+		if !state.lastSeen.After(cutoff) {
+			stale = append(stale, deviceID)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, deviceID := range stale {
+		var lastSeen *time.Time
+		stored, err := t.repo.GetByDeviceID(ctx, deviceID)
+		switch {
+		case err == nil:
+			lastSeen = stored.LastSeenAt
+		case !errors.Is(err, repository.ErrDeviceNotFound):
+			slog.Error("Error checking device last_seen", "device_id", deviceID, "error", err)
+			continue
+		}
+
+		t.mu.Lock()
+		state, ok := t.online[deviceID]
+		switch {
+		case !ok:
+		case lastSeen != nil && lastSeen.After(cutoff):
+			if lastSeen.After(state.lastSeen) {
+				state.lastSeen = *lastSeen
+			}
+		case !state.lastSeen.After(cutoff):
+			delete(t.online, deviceID)
+			t.publish(event(models.DeviceEventOffline, deviceID, state, t.now()))
+		}
+		t.mu.Unlock()
+	}
+}
+
+// publish sends an event to every subscriber without blocking; the caller must hold t.mu
+func (t *Tracker) publish(e *models.DevicePresenceEvent) {
+	for ch := range t.subscribers {
+		select {
+		case ch <- e:
+		default:
+			slog.Warn("Device presence subscriber is behind, dropping event", "device_id", e.DeviceID, "type", e.Type)
+		}
+	}
+}
+
+// event builds a presence event for a tracked device
+func event(eventType, deviceID string, state *device, at time.Time) *models.DevicePresenceEvent {
+	return &models.DevicePresenceEvent{
+		Type:       eventType,
+		ID:         state.id,
+		DeviceID:   deviceID,
+		UserID:     state.userID,
+		OrgID:      state.orgID,
+		LastSeenAt: state.lastSeen,
+		At:         at,
+	}
+}
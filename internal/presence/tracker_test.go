@@ -0,0 +1,120 @@
+package presence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// newTestTracker creates a tracker whose clock is controlled by the returned pointer
+func newTestTracker(repo repository.DeviceRepository) (*Tracker, *time.Time) {
+	now := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	tracker := NewTracker(repo)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+// drain returns the events waiting on a subscription
+func drain(events <-chan *models.DevicePresenceEvent) []*models.DevicePresenceEvent {
+	var received []*models.DevicePresenceEvent
+	for {
+		select {
+		case e := <-events:
+			received = append(received, e)
+		default:
+			return received
+		}
+	}
+}
+
+func TestTracker_Seen(t *testing.T) {
+	tracker, _ := newTestTracker(repository.NewMockDeviceRepository())
+	events, unsubscribe := tracker.Subscribe()
+	defer unsubscribe()
+
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: uuid.New()}
+	tracker.Seen(device)
+	tracker.Seen(device)
+
+	received := drain(events)
+	require.Len(t, received, 1, "only the first sighting is a transition")
+	assert.Equal(t, models.DeviceEventOnline, received[0].Type)
+	assert.Equal(t, "RACEBOX-001", received[0].DeviceID)
+	assert.Equal(t, device.UserID, received[0].UserID)
+}
+
+func TestTracker_Sweep(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMockDeviceRepository()
+	tracker, now := newTestTracker(repo)
+	events, unsubscribe := tracker.Subscribe()
+	defer unsubscribe()
+
+	device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: uuid.New()}
+	seen := *now
+	tracker.Seen(device)
+	drain(events)
+
+	// Still inside the online window
+	*now = now.Add(models.DeviceOnlineWindow - time.Minute)
+	tracker.sweep(ctx)
+	assert.Empty(t, drain(events))
+
+	// Another instance saw the device more recently
+	*now = now.Add(2 * time.Minute)
+	fresher := now.Add(-time.Minute)
+	repo.GetByDeviceIDFunc = func(_ context.Context, _ string) (*models.Device, error) {
+		return &models.Device{DeviceID: "RACEBOX-001", LastSeenAt: &fresher}, nil
+	}
+	tracker.sweep(ctx)
+	assert.Empty(t, drain(events))
+
+	// Not seen anywhere for the whole window
+	*now = fresher.Add(models.DeviceOnlineWindow)
+	tracker.sweep(ctx)
+	received := drain(events)
+	require.Len(t, received, 1)
+	assert.Equal(t, models.DeviceEventOffline, received[0].Type)
+	assert.Equal(t, fresher, received[0].LastSeenAt)
+	assert.True(t, received[0].LastSeenAt.After(seen))
+
+	// Seen again: back online
+	tracker.Seen(device)
+	received = drain(events)
+	require.Len(t, received, 1)
+	assert.Equal(t, models.DeviceEventOnline, received[0].Type)
+}
+
+func TestTracker_Load(t *testing.T) {
+	repo := repository.NewMockDeviceRepository()
+	tracker, now := newTestTracker(repo)
+	lastSeen := now.Add(-10 * time.Minute)
+	repo.ListSeenSinceFunc = func(_ context.Context, since time.Time) ([]*models.Device, error) {
+		assert.Equal(t, now.Add(-models.DeviceOnlineWindow), since)
+		return []*models.Device{{ID: uuid.New(), DeviceID: "RACEBOX-001", LastSeenAt: &lastSeen}}, nil
+	}
+	events, unsubscribe := tracker.Subscribe()
+	defer unsubscribe()
+
+	require.NoError(t, tracker.Load(context.Background()))
+	tracker.Seen(&models.Device{DeviceID: "RACEBOX-001"})
+	assert.Empty(t, drain(events), "devices online before startup do not come online again")
+}
+
+func TestTracker_Unsubscribe(t *testing.T) {
+	tracker, _ := newTestTracker(repository.NewMockDeviceRepository())
+	events, unsubscribe := tracker.Subscribe()
+	unsubscribe()
+	unsubscribe()
+
+	_, open := <-events
+	assert.False(t, open)
+	tracker.Seen(&models.Device{DeviceID: "RACEBOX-001"})
+}
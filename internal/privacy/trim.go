@@ -0,0 +1,144 @@
+// Package privacy anonymizes telemetry that leaves its owner's hands, such as shared sessions and exports.
+package privacy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// MaxTrimMeters caps how much of each end of a track can be hidden
+const MaxTrimMeters = 5000
+
+// pendingRecord is a record held back until enough of the track follows it
+type pendingRecord struct {
+	record  *models.TelemetryData
+	advance float64 // Distance from the previous located record, in meters
+}
+
+// trimIterator hides the first and last meters of the track of the records it wraps
+type trimIterator struct {
+	repository.TelemetryIterator
+	meters   float64
+	leading  bool    // Still inside the hidden start of the track
+	traveled float64 // Distance covered in the hidden start
+	last     *geo.Point
+	pending  []pendingRecord
+	after    float64 // Distance covered after the first pending record
+	current  *models.TelemetryData
+}
+
+// Trim wraps it so records within meters of the start or end of the track are left out
+// Distance is measured along the path through records with a valid GPS fix; records without one
+// are kept or hidden with their neighbours. Records are held back until the track has covered
+// meters after them, so only that stretch of the track is buffered. A zero distance returns it
+// unchanged.
+func Trim(it repository.TelemetryIterator, meters float64) repository.TelemetryIterator {
+	if meters <= 0 {
+		return it
+	}
+	return &trimIterator{TelemetryIterator: it, meters: meters, leading: true}
+}
+
+// Next advances to the next record outside the hidden ends of the track
+func (t *trimIterator) Next() bool {
+	for {
+		if len(t.pending) > 0 && t.after >= t.meters {
+			t.current = t.pending[0].record
+			t.pending = t.pending[1:]
+			if len(t.pending) > 0 {
+				t.after -= t.pending[0].advance
+			}
+			return true
+		}
+
+		// Whatever is still pending when the records run out is the hidden end of the track
+		if !t.TelemetryIterator.Next() {
+			t.pending = nil
+			return false
+		}
+
+		record := t.TelemetryIterator.Telemetry()
+		var advance float64
+		if record.GPS.IsFixValid {
+			point := geo.Point{Latitude: record.GPS.Latitude, Longitude: record.GPS.Longitude}
+			if t.last != nil {
+				advance = geo.Distance(*t.last, point)
+			}
+			t.last = &point
+		}
+
+		if t.leading {
+			t.traveled += advance
+			if t.traveled < t.meters {
+				continue
+			}
+			t.leading = false
+		}
+
+		if len(t.pending) > 0 {
+			t.after += advance
+		}
+		t.pending = append(t.pending, pendingRecord{record: record, advance: advance})
+	}
+}
+
+// Telemetry returns the current record
+func (t *trimIterator) Telemetry() *models.TelemetryData {
+	return t.current
+}
+
+// lineString is a GeoJSON LineString geometry
+type lineString struct {
+	Type        string      `json:"type"`
+	Coordinates [][]float64 `json:"coordinates"`
+}
+
+// TrimLine hides the first and last meters of a GeoJSON LineString
+// It returns nil when fewer than two vertices remain. A zero distance returns the geometry unchanged.
+func TrimLine(geometry json.RawMessage, meters float64) (json.RawMessage, int, error) {
+	var line lineString
+	if err := json.Unmarshal(geometry, &line); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse track geometry: %w", err)
+	}
+	if meters <= 0 {
+		return geometry, len(line.Coordinates), nil
+	}
+
+	// Distance along the line to each vertex
+	cumulative := make([]float64, len(line.Coordinates))
+	for i := 1; i < len(line.Coordinates); i++ {
+		cumulative[i] = cumulative[i-1] + geo.Distance(vertex(line.Coordinates[i-1]), vertex(line.Coordinates[i]))
+	}
+
+	var kept [][]float64
+	if n := len(cumulative); n > 0 {
+		total := cumulative[n-1]
+		for i, coordinates := range line.Coordinates {
+			if cumulative[i] >= meters && total-cumulative[i] >= meters {
+				kept = append(kept, coordinates)
+			}
+		}
+	}
+	if len(kept) < 2 {
+		return nil, len(kept), nil
+	}
+
+	line.Coordinates = kept
+	trimmed, err := json.Marshal(line)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encode track geometry: %w", err)
+	}
+	return trimmed, len(kept), nil
+}
+
+// vertex converts a GeoJSON [longitude, latitude] position to a point
+func vertex(position []float64) geo.Point {
+	if len(position) < 2 {
+		return geo.Point{}
+	}
+	return geo.Point{Latitude: position[1], Longitude: position[0]}
+}
@@ -0,0 +1,87 @@
+package privacy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+// northbound returns n records heading north about 111 meters apart
+func northbound(n int) []*models.TelemetryData {
+	records := make([]*models.TelemetryData, n)
+	for i := range records {
+		records[i] = &models.TelemetryData{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			GPS:       models.GpsData{Latitude: 40 + float64(i)*0.001, Longitude: -3.7, IsFixValid: true},
+		}
+	}
+	return records
+}
+
+// collect drains an iterator into the seconds since start of its records
+func collect(t *testing.T, it repository.TelemetryIterator) []int {
+	var seconds []int
+	for it.Next() {
+		seconds = append(seconds, int(it.Telemetry().Timestamp.Sub(start).Seconds()))
+	}
+	require.NoError(t, it.Err())
+	return seconds
+}
+
+func TestTrim(t *testing.T) {
+	t.Run("hides both ends", func(t *testing.T) {
+		it := Trim(repository.NewSliceTelemetryIterator(northbound(10)), 200)
+		assert.Equal(t, []int{2, 3, 4, 5, 6, 7}, collect(t, it))
+	})
+
+	t.Run("zero distance keeps everything", func(t *testing.T) {
+		it := Trim(repository.NewSliceTelemetryIterator(northbound(3)), 0)
+		assert.Equal(t, []int{0, 1, 2}, collect(t, it))
+	})
+
+	t.Run("short tracks are hidden entirely", func(t *testing.T) {
+		it := Trim(repository.NewSliceTelemetryIterator(northbound(4)), 200)
+		assert.Empty(t, collect(t, it))
+	})
+
+	t.Run("records without a fix follow their neighbours", func(t *testing.T) {
+		records := northbound(10)
+		records[1].GPS = models.GpsData{}
+		records[5].GPS = models.GpsData{}
+		records[8].GPS = models.GpsData{}
+
+		// Without fixes at 1 and 8, the ends are measured from 0 to 2 and 7 to 9
+		it := Trim(repository.NewSliceTelemetryIterator(records), 200)
+		assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8}, collect(t, it))
+	})
+}
+
+func TestTrimLine(t *testing.T) {
+	coordinates := make([][]float64, 10)
+	for i := range coordinates {
+		coordinates[i] = []float64{-3.7, 40 + float64(i)*0.001}
+	}
+	geometry, err := json.Marshal(lineString{Type: "LineString", Coordinates: coordinates})
+	require.NoError(t, err)
+
+	trimmed, vertices, err := TrimLine(geometry, 200)
+	require.NoError(t, err)
+	assert.Equal(t, 6, vertices)
+
+	var line lineString
+	require.NoError(t, json.Unmarshal(trimmed, &line))
+	assert.Equal(t, "LineString", line.Type)
+	assert.Equal(t, coordinates[2:8], line.Coordinates)
+
+	trimmed, vertices, err = TrimLine(geometry, 600)
+	require.NoError(t, err)
+	assert.Nil(t, trimmed)
+	assert.Zero(t, vertices)
+}
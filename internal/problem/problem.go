@@ -0,0 +1,181 @@
+// Package problem defines the API's error type and renders it as RFC 7807 problem details.
+package problem
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of problem responses
+const ContentType = "application/problem+json"
+
+// Context keys read when rendering problems
+const (
+	// TraceIDKey holds the trace ID exposed to clients, set by the tracing middleware
+	TraceIDKey = "TraceID"
+
+	// RendererKey holds a Renderer replacing the problem+json body, set per API version
+	RendererKey = "ProblemRenderer"
+
+	// requestIDKey holds the request ID, set by the request ID middleware
+	requestIDKey = "RequestID"
+)
+
+// Renderer writes a problem body
+type Renderer func(c *gin.Context, status int, body map[string]any)
+
+// Error is an API error
+// Status, Code and Detail are rendered; the optional cause is only logged.
+type Error struct {
+	Status     int            // HTTP status
+	Code       string         // Stable machine-readable error code, e.g. "device_not_found"
+	Detail     string         // Human-readable explanation of this occurrence
+	Extensions map[string]any // Additional members, e.g. the violated limit
+	cause      error
+}
+
+// New creates an error with the given status, code and detail
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// BadRequest creates a 400 error
+func BadRequest(code, detail string) *Error {
+	return New(http.StatusBadRequest, code, detail)
+}
+
+// Unauthorized creates a 401 error
+func Unauthorized(code, detail string) *Error {
+	return New(http.StatusUnauthorized, code, detail)
+}
+
+// Forbidden creates a 403 error
+func Forbidden(code, detail string) *Error {
+	return New(http.StatusForbidden, code, detail)
+}
+
+// NotFound creates a 404 error
+func NotFound(code, detail string) *Error {
+	return New(http.StatusNotFound, code, detail)
+}
+
+// Conflict creates a 409 error
+func Conflict(code, detail string) *Error {
+	return New(http.StatusConflict, code, detail)
+}
+
+// TooManyRequests creates a 429 error
+func TooManyRequests(code, detail string) *Error {
+	return New(http.StatusTooManyRequests, code, detail)
+}
+
+// ServiceUnavailable creates a 503 error
+func ServiceUnavailable(code, detail string) *Error {
+	return New(http.StatusServiceUnavailable, code, detail)
+}
+
+// Internal creates a 500 error with the internal_error code
+func Internal(detail string) *Error {
+	return New(http.StatusInternalServerError, "internal_error", detail)
+}
+
+// With adds an extension member to the rendered problem
+func (e *Error) With(key string, value any) *Error {
+	if e.Extensions == nil {
+		e.Extensions = make(map[string]any)
+	}
+	e.Extensions[key] = value
+	return e
+}
+
+// WithCause records the underlying error, which is logged but never shown to clients
+func (e *Error) WithCause(err error) *Error {
+	e.cause = err
+	return e
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Code + ": " + e.Detail + ": " + e.cause.Error()
+	}
+	return e.Code + ": " + e.Detail
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// From returns err as an *Error, wrapping errors of other types as internal errors
+func From(err error) *Error {
+	var problem *Error
+	if errors.As(err, &problem) {
+		return problem
+	}
+	return Internal("An unexpected error occurred").WithCause(err)
+}
+
+// Abort stops the handler chain and responds with the error
+// The error is also attached to the context, so the error middleware and tracing can report it.
+func Abort(c *gin.Context, err error) {
+	c.Abort()
+	Write(c, err)
+}
+
+// Write responds with the error as an application/problem+json document
+// Besides the RFC 7807 members it carries the error code and a traceId for looking the request up:
+// the trace ID when it is exposed, and the request ID otherwise.
+// A Renderer in the context may wrap the document in another body.
+func Write(c *gin.Context, err error) {
+	problem := From(err)
+	_ = c.Error(problem)
+
+	body := make(map[string]any, len(problem.Extensions)+7)
+	for key, value := range problem.Extensions {
+		body[key] = value
+	}
+	body["type"] = "about:blank"
+	body["title"] = http.StatusText(problem.Status)
+	body["status"] = problem.Status
+	body["code"] = problem.Code
+	if problem.Detail != "" {
+		body["detail"] = problem.Detail
+	}
+	if c.Request != nil {
+		body["instance"] = c.Request.URL.Path
+	}
+	if traceID := traceID(c); traceID != "" {
+		body["traceId"] = traceID
+	}
+
+	if render, ok := c.Value(RendererKey).(Renderer); ok {
+		render(c, problem.Status, body)
+		return
+	}
+	Render(c, problem.Status, body)
+}
+
+// Render writes a problem body as application/problem+json
+func Render(c *gin.Context, status int, body map[string]any) {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(body); err != nil {
+		c.Status(status)
+		return
+	}
+	c.Data(status, ContentType, bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+}
+
+// traceID returns the ID clients can quote to look the request up
+func traceID(c *gin.Context) string {
+	if id := c.GetString(TraceIDKey); id != "" {
+		return id
+	}
+	return c.GetString(requestIDKey)
+}
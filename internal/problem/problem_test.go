@@ -0,0 +1,86 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/devices/abc", nil)
+	c.Set("RequestID", "req-123")
+
+	Abort(c, NotFound("device_not_found", "Device <abc> not found").With("deviceId", "abc"))
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<abc>")
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "about:blank", body["type"])
+	assert.Equal(t, "Not Found", body["title"])
+	assert.Equal(t, float64(http.StatusNotFound), body["status"])
+	assert.Equal(t, "device_not_found", body["code"])
+	assert.Equal(t, "Device <abc> not found", body["detail"])
+	assert.Equal(t, "/api/v1/devices/abc", body["instance"])
+	assert.Equal(t, "req-123", body["traceId"])
+	assert.Equal(t, "abc", body["deviceId"])
+	require.Len(t, c.Errors, 1)
+}
+
+func TestWrite_TraceIDPreferred(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Set("RequestID", "req-123")
+	c.Set(TraceIDKey, "0af7651916cd43dd8448eb211c80319c")
+
+	Write(c, BadRequest("invalid_request", "bad"))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", body["traceId"])
+}
+
+func TestWrite_UntypedError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	cause := errors.New("connection refused")
+	Write(c, cause)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "connection refused")
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "internal_error", body["code"])
+	assert.ErrorIs(t, c.Errors.Last().Err, cause)
+}
+
+func TestFrom(t *testing.T) {
+	cause := errors.New("boom")
+	err := Conflict("email_taken", "Email already registered").WithCause(cause)
+	wrapped := errors.Join(errors.New("context"), err)
+
+	assert.Same(t, err, From(wrapped))
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "email_taken: Email already registered: boom", err.Error())
+}
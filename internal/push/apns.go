@@ -0,0 +1,163 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// APNs endpoints for production and development builds of the app
+	apnsEndpoint        = "https://api.push.apple.com"
+	apnsSandboxEndpoint = "https://api.sandbox.push.apple.com"
+
+	// providerTokenLifetime is how long a provider token is reused; APNs rejects tokens older than an hour
+	providerTokenLifetime = 50 * time.Minute
+)
+
+// APNsProvider sends messages through the Apple Push Notification service
+// It authenticates with a signing key (.p8) using provider tokens.
+type APNsProvider struct {
+	key        *ecdsa.PrivateKey
+	keyID      string
+	teamID     string
+	topic      string
+	endpoint   string
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	providerToken string
+	issuedAt      time.Time
+}
+
+// NewAPNsProvider creates an APNs provider from a PEM-encoded signing key
+// topic is the app's bundle ID; sandbox selects the development environment.
+func NewAPNsProvider(keyPEM, keyID, teamID, topic string, sandbox bool) (*APNsProvider, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(keyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse APNs signing key: %w", err)
+	}
+
+	endpoint := apnsEndpoint
+	if sandbox {
+		endpoint = apnsSandboxEndpoint
+	}
+
+	return &APNsProvider{
+		key:        key,
+		keyID:      keyID,
+		teamID:     teamID,
+		topic:      topic,
+		endpoint:   endpoint,
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+// WithEndpoint sets the base URL of the APNs API, e.g. for tests
+func (p *APNsProvider) WithEndpoint(endpoint string) *APNsProvider {
+	p.endpoint = strings.TrimSuffix(endpoint, "/")
+	return p
+}
+
+// WithHTTPClient sets the HTTP client used for APNs requests
+// APNs only accepts HTTP/2, which the default transport negotiates over TLS.
+func (p *APNsProvider) WithHTTPClient(client *http.Client) *APNsProvider {
+	p.httpClient = client
+	return p
+}
+
+// Send implements Provider.Send
+// Tokens APNs reports as unregistered or malformed return ErrInvalidToken.
+func (p *APNsProvider) Send(ctx context.Context, token string, message Message) error {
+	providerToken, err := p.token()
+	if err != nil {
+		return err
+	}
+
+	payload := make(map[string]any, len(message.Data)+1)
+	for key, value := range message.Data {
+		payload[key] = value
+	}
+	payload["aps"] = map[string]any{
+		"alert": map[string]string{"title": message.Title, "body": message.Body},
+		"sound": "default",
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode APNs payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/3/device/"+url.PathEscape(token), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create APNs request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", p.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("APNs request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure)
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		failure.Reason == "BadDeviceToken",
+		failure.Reason == "Unregistered",
+		failure.Reason == "DeviceTokenNotForTopic":
+		return ErrInvalidToken
+	case failure.Reason == "ExpiredProviderToken" || failure.Reason == "InvalidProviderToken":
+		p.mu.Lock()
+		p.providerToken = "" // Sign a new token on the next send
+		p.mu.Unlock()
+	}
+	return fmt.Errorf("APNs returned status %d: %s", resp.StatusCode, failure.Reason)
+}
+
+// token returns the current provider token, signing a new one when it is due for renewal
+func (p *APNsProvider) token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.providerToken != "" && now.Sub(p.issuedAt) < providerTokenLifetime {
+		return p.providerToken, nil
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.keyID
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign APNs provider token: %w", err)
+	}
+
+	p.providerToken = signed
+	p.issuedAt = now
+	return signed, nil
+}
@@ -0,0 +1,89 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPNsProvider_Send(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "com.example.avt", r.Header.Get("apns-topic"))
+		assert.Equal(t, "alert", r.Header.Get("apns-push-type"))
+
+		token, err := jwt.Parse(strings.TrimPrefix(r.Header.Get("Authorization"), "bearer "), func(*jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "KEY123", token.Header["kid"])
+		issuer, _ := token.Claims.GetIssuer()
+		assert.Equal(t, "TEAM456", issuer)
+
+		switch strings.TrimPrefix(r.URL.Path, "/3/device/") {
+		case "uninstalled":
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"reason":"Unregistered"}`))
+			return
+		case "malformed":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"reason":"BadDeviceToken"}`))
+			return
+		case "throttled":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"reason":"TooManyRequests"}`))
+			return
+		}
+
+		var payload map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	provider, err := NewAPNsProvider(keyPEM, "KEY123", "TEAM456", "com.example.avt", true)
+	require.NoError(t, err)
+	assert.Equal(t, apnsSandboxEndpoint, provider.endpoint)
+	provider.WithEndpoint(server.URL)
+
+	ctx := context.Background()
+	message := Message{Title: "kart-7 battery is low", Body: "12% left", Data: map[string]string{"deviceId": "kart-7"}}
+	require.NoError(t, provider.Send(ctx, "iphone", message))
+
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "kart-7", payloads[0]["deviceId"])
+	aps, ok := payloads[0]["aps"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"title": "kart-7 battery is low", "body": "12% left"}, aps["alert"])
+
+	assert.ErrorIs(t, provider.Send(ctx, "uninstalled", message), ErrInvalidToken)
+	assert.ErrorIs(t, provider.Send(ctx, "malformed", message), ErrInvalidToken)
+
+	err = provider.Send(ctx, "throttled", message)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+	assert.Contains(t, err.Error(), "TooManyRequests")
+}
+
+func TestNewAPNsProvider_InvalidKey(t *testing.T) {
+	_, err := NewAPNsProvider("not a key", "KEY123", "TEAM456", "com.example.avt", false)
+	assert.Error(t, err)
+}
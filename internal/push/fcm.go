@@ -0,0 +1,243 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// fcmEndpoint is the base URL of the FCM HTTP v1 API
+	fcmEndpoint = "https://fcm.googleapis.com"
+
+	// fcmScope is the OAuth scope needed to send messages
+	fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+	// accessTokenMargin is how long before expiry an access token is renewed
+	accessTokenMargin = time.Minute
+)
+
+// serviceAccount holds the fields of a Google service account JSON key used to authenticate
+type serviceAccount struct {
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// FCMProvider sends messages through the Firebase Cloud Messaging HTTP v1 API
+// It signs in with a service account, exchanging a signed JWT for an OAuth access token that is
+// reused until shortly before it expires.
+type FCMProvider struct {
+	projectID  string
+	account    serviceAccount
+	key        *rsa.PrivateKey
+	endpoint   string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMProvider creates an FCM provider from a service account JSON key
+// An empty projectID uses the project of the service account.
+func NewFCMProvider(credentials []byte, projectID string) (*FCMProvider, error) {
+	var account serviceAccount
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return nil, fmt.Errorf("failed to parse FCM service account: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" || account.TokenURI == "" {
+		return nil, errors.New("FCM service account is missing client_email, private_key or token_uri")
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FCM service account key: %w", err)
+	}
+
+	if projectID == "" {
+		projectID = account.ProjectID
+	}
+	if projectID == "" {
+		return nil, errors.New("FCM project ID is not set and the service account has none")
+	}
+
+	return &FCMProvider{
+		projectID:  projectID,
+		account:    account,
+		key:        key,
+		endpoint:   fcmEndpoint,
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+// WithEndpoint sets the base URL of the FCM API, e.g. for tests
+func (p *FCMProvider) WithEndpoint(endpoint string) *FCMProvider {
+	p.endpoint = strings.TrimSuffix(endpoint, "/")
+	return p
+}
+
+// WithHTTPClient sets the HTTP client used for FCM and OAuth requests
+func (p *FCMProvider) WithHTTPClient(client *http.Client) *FCMProvider {
+	p.httpClient = client
+	return p
+}
+
+// fcmRequest is the body of an FCM send request
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// fcmError is the error body of a failed FCM request
+type fcmError struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send implements Provider.Send
+// Tokens FCM reports as unregistered return ErrInvalidToken.
+func (p *FCMProvider) Send(ctx context.Context, token string, message Message) error {
+	accessToken, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(fcmRequest{Message: fcmMessage{
+		Token:        token,
+		Notification: fcmNotification{Title: message.Title, Body: message.Body},
+		Data:         message.Data,
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to encode FCM message: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/projects/%s/messages:send", p.endpoint, p.projectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create FCM request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("FCM request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure fcmError
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure)
+	for _, detail := range failure.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return ErrInvalidToken
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrInvalidToken
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		p.mu.Lock()
+		p.accessToken = "" // Sign in again on the next send
+		p.mu.Unlock()
+	}
+	return fmt.Errorf("FCM returned status %d: %s %s", resp.StatusCode, failure.Error.Status, failure.Error.Message)
+}
+
+// token returns a valid OAuth access token, signing in again when the current one is about to expire
+func (p *FCMProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.accessToken != "" && now.Add(accessTokenMargin).Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   p.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   p.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+	assertion := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if p.account.PrivateKeyID != "" {
+		assertion.Header["kid"] = p.account.PrivateKeyID
+	}
+	signed, err := assertion.SignedString(p.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign FCM token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {signed},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create FCM token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("FCM token request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("FCM token request returned status %d", resp.StatusCode)
+	}
+
+	var grant struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&grant); err != nil {
+		return "", fmt.Errorf("failed to decode FCM token response: %w", err)
+	}
+	if grant.AccessToken == "" {
+		return "", errors.New("FCM token response has no access token")
+	}
+
+	p.accessToken = grant.AccessToken
+	p.expiresAt = now.Add(time.Duration(grant.ExpiresIn) * time.Second)
+	return p.accessToken, nil
+}
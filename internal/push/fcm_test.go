@@ -0,0 +1,120 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fcmServer fakes the Google token endpoint and the FCM API
+type fcmServer struct {
+	*httptest.Server
+	key         *rsa.PrivateKey
+	tokenGrants int
+	messages    []fcmMessage
+}
+
+func newFCMServer(t *testing.T) *fcmServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	s := &fcmServer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.PostForm.Get("grant_type"))
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(r.PostForm.Get("assertion"), claims, func(*jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "push@avt-test.iam.gserviceaccount.com", claims["iss"])
+		assert.Equal(t, fcmScope, claims["scope"])
+
+		s.tokenGrants++
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "access-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("POST /v1/projects/avt-test/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+
+		var req fcmRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Message.Token == "uninstalled" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+			return
+		}
+		if req.Message.Token == "overloaded" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"code":503,"status":"UNAVAILABLE","message":"try again"}}`))
+			return
+		}
+		s.messages = append(s.messages, req.Message)
+		_, _ = w.Write([]byte(`{"name":"projects/avt-test/messages/1"}`))
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// credentials returns a service account key pointing at the fake token endpoint
+func (s *fcmServer) credentials(t *testing.T) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(s.key)
+	require.NoError(t, err)
+
+	credentials, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "avt-test",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "push@avt-test.iam.gserviceaccount.com",
+		"token_uri":      s.URL + "/token",
+	})
+	require.NoError(t, err)
+	return credentials
+}
+
+func TestFCMProvider_Send(t *testing.T) {
+	server := newFCMServer(t)
+	provider, err := NewFCMProvider(server.credentials(t), "")
+	require.NoError(t, err)
+	provider.WithEndpoint(server.URL)
+
+	ctx := context.Background()
+	message := Message{Title: "Session complete", Body: "12.4 km", Data: map[string]string{"sessionId": "abc"}}
+	require.NoError(t, provider.Send(ctx, "android", message))
+	require.NoError(t, provider.Send(ctx, "tablet", message))
+
+	// The access token is reused between sends
+	assert.Equal(t, 1, server.tokenGrants)
+	require.Len(t, server.messages, 2)
+	assert.Equal(t, "android", server.messages[0].Token)
+	assert.Equal(t, "Session complete", server.messages[0].Notification.Title)
+	assert.Equal(t, "abc", server.messages[0].Data["sessionId"])
+
+	assert.ErrorIs(t, provider.Send(ctx, "uninstalled", message), ErrInvalidToken)
+
+	err = provider.Send(ctx, "overloaded", message)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestNewFCMProvider_InvalidCredentials(t *testing.T) {
+	_, err := NewFCMProvider([]byte("not json"), "avt-test")
+	assert.Error(t, err)
+
+	_, err = NewFCMProvider([]byte(`{"client_email":"push@example.com","token_uri":"https://oauth2.googleapis.com/token"}`), "avt-test")
+	assert.Error(t, err)
+}
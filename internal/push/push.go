@@ -0,0 +1,97 @@
+// Package push sends push notifications to the mobile app installations users registered.
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+// requestTimeout caps how long a single provider request may take
+const requestTimeout = 10 * time.Second
+
+// ErrInvalidToken is returned by providers for tokens that will never be delivered to again,
+// e.g. because the app was uninstalled
+var ErrInvalidToken = errors.New("push token is no longer valid")
+
+// Message is a push notification
+type Message struct {
+	Title string
+	Body  string
+	Data  map[string]string // Delivered to the app alongside the alert, e.g. for deep links
+}
+
+// Provider delivers messages to device tokens of one push service
+type Provider interface {
+	Send(ctx context.Context, token string, message Message) error
+}
+
+// newHTTPClient creates the HTTP client used for provider requests
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// Sender sends messages to all of a user's registered installations
+// Tokens of providers that are not configured are skipped, and tokens a provider rejects as
+// invalid are deleted.
+type Sender struct {
+	tokens    repository.PushTokenRepository
+	providers map[models.PushProvider]Provider
+}
+
+// NewSender creates a sender without providers
+func NewSender(tokens repository.PushTokenRepository) *Sender {
+	return &Sender{
+		tokens:    tokens,
+		providers: make(map[models.PushProvider]Provider),
+	}
+}
+
+// WithProvider sets the provider used for tokens of the given kind
+func (s *Sender) WithProvider(kind models.PushProvider, provider Provider) *Sender {
+	s.providers[kind] = provider
+	return s
+}
+
+// Enabled reports whether any provider is configured
+func (s *Sender) Enabled() bool {
+	return len(s.providers) > 0
+}
+
+// Send delivers the message to every installation of the user
+// It returns the errors of failed deliveries other than invalid tokens, joined.
+func (s *Sender) Send(ctx context.Context, userID uuid.UUID, message Message) error {
+	tokens, err := s.tokens.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list push tokens: %w", err)
+	}
+
+	var errs []error
+	for _, token := range tokens {
+		provider, ok := s.providers[token.Provider]
+		if !ok {
+			continue
+		}
+
+		err := provider.Send(ctx, token.Token, message)
+		if errors.Is(err, ErrInvalidToken) {
+			slog.Info("Removing invalid push token", "user_id", userID, "token_id", token.ID, "provider", token.Provider)
+			if err := s.tokens.DeleteByToken(ctx, token.Token); err != nil {
+				slog.Error("Error removing invalid push token", "token_id", token.ID, "error", err)
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s push to token %s: %w", token.Provider, token.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// providerFunc adapts a function to the Provider interface
+type providerFunc func(ctx context.Context, token string, message Message) error
+
+func (f providerFunc) Send(ctx context.Context, token string, message Message) error {
+	return f(ctx, token, message)
+}
+
+func TestSender_Send(t *testing.T) {
+	userID := uuid.New()
+	tokens := repository.NewMockPushTokenRepository()
+	tokens.ListByUserIDFunc = func(_ context.Context, id uuid.UUID) ([]*models.PushToken, error) {
+		assert.Equal(t, userID, id)
+		return []*models.PushToken{
+			{ID: uuid.New(), Provider: models.PushProviderFCM, Token: "android"},
+			{ID: uuid.New(), Provider: models.PushProviderFCM, Token: "uninstalled"},
+			{ID: uuid.New(), Provider: models.PushProviderFCM, Token: "failing"},
+			{ID: uuid.New(), Provider: models.PushProviderAPNs, Token: "iphone"},
+		}, nil
+	}
+	var deleted []string
+	tokens.DeleteByTokenFunc = func(_ context.Context, token string) error {
+		deleted = append(deleted, token)
+		return nil
+	}
+
+	var sent []string
+	fcm := providerFunc(func(_ context.Context, token string, message Message) error {
+		assert.Equal(t, "Session complete", message.Title)
+		switch token {
+		case "uninstalled":
+			return ErrInvalidToken
+		case "failing":
+			return errors.New("service unavailable")
+		}
+		sent = append(sent, token)
+		return nil
+	})
+
+	// APNs is not configured, so the iPhone is skipped
+	sender := NewSender(tokens).WithProvider(models.PushProviderFCM, fcm)
+	require.True(t, sender.Enabled())
+
+	err := sender.Send(context.Background(), userID, Message{Title: "Session complete"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service unavailable")
+	assert.Equal(t, []string{"android"}, sent)
+	assert.Equal(t, []string{"uninstalled"}, deleted)
+}
+
+func TestSender_Enabled(t *testing.T) {
+	assert.False(t, NewSender(repository.NewMockPushTokenRepository()).Enabled())
+}
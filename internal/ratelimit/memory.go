@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval controls how often idle buckets are evicted from memory
+const sweepInterval = time.Minute
+
+// bucket tracks the token count of a single key
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	idleTime time.Duration // Time after which the bucket is full again and can be dropped
+}
+
+// MemoryStore is an in-process Store suitable for single-instance deployments
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates a new in-memory token bucket store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take implements Store.Take
+func (s *MemoryStore) Take(_ context.Context, key string, policy Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Burst), updated: now, idleTime: policy.refillTime()}
+		s.buckets[key] = b
+	}
+
+	// Refill based on elapsed time; the policy may have been reloaded since the bucket was created
+	b.idleTime = policy.refillTime()
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(policy.Burst), b.tokens+elapsed*policy.Rate)
+		b.updated = now
+	}
+
+	return takeToken(&b.tokens, policy), nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.idleTime {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// takeToken consumes a token from a refilled bucket and reports the result
+func takeToken(tokens *float64, policy Policy) Result {
+	result := Result{Limit: policy.Burst}
+
+	if *tokens >= 1 {
+		*tokens--
+		result.Allowed = true
+		result.Remaining = int(*tokens)
+		return result
+	}
+
+	if policy.Rate > 0 {
+		result.RetryAfter = time.Duration((1 - *tokens) / policy.Rate * float64(time.Second))
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemoryStore(now *time.Time) *MemoryStore {
+	store := NewMemoryStore()
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestMemoryStore_BurstThenLimit(t *testing.T) {
+	now := time.Now()
+	store := newTestMemoryStore(&now)
+	policy := PerMinute(60, 3) // 1 token/s, burst 3
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		result, err := store.Take(ctx, "ip:1.2.3.4", policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed, "request %d should be allowed", i)
+		assert.Equal(t, 2-i, result.Remaining)
+		assert.Equal(t, 3, result.Limit)
+	}
+
+	result, err := store.Take(ctx, "ip:1.2.3.4", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	// Other keys have their own bucket
+	result, err = store.Take(ctx, "ip:5.6.7.8", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestMemoryStore_Refill(t *testing.T) {
+	now := time.Now()
+	store := newTestMemoryStore(&now)
+	policy := PerMinute(60, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, _ = store.Take(ctx, "user:1", policy)
+	}
+
+	result, _ := store.Take(ctx, "user:1", policy)
+	assert.False(t, result.Allowed)
+
+	// Half a token is not enough
+	now = now.Add(500 * time.Millisecond)
+	result, _ = store.Take(ctx, "user:1", policy)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	result, _ = store.Take(ctx, "user:1", policy)
+	assert.True(t, result.Allowed)
+
+	// Refill never exceeds the burst
+	now = now.Add(time.Hour)
+	result, _ = store.Take(ctx, "user:1", policy)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestMemoryStore_SweepsIdleBuckets(t *testing.T) {
+	now := time.Now()
+	store := newTestMemoryStore(&now)
+	policy := PerMinute(60, 5)
+	ctx := context.Background()
+
+	_, _ = store.Take(ctx, "ip:idle", policy)
+	assert.Len(t, store.buckets, 1)
+
+	now = now.Add(2 * sweepInterval)
+	_, _ = store.Take(ctx, "ip:active", policy)
+
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "ip:active")
+}
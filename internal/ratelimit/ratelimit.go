@@ -0,0 +1,71 @@
+// Package ratelimit provides token bucket rate limiting with pluggable storage.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Policy describes a token bucket: Burst tokens of capacity, refilled at Rate tokens per second
+type Policy struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute creates a policy allowing requests per minute with the given burst capacity
+func PerMinute(requests, burst int) Policy {
+	return Policy{
+		Rate:  float64(requests) / 60,
+		Burst: burst,
+	}
+}
+
+// PolicyVar holds a policy that can be replaced while limiters are using it
+// It is safe for concurrent use.
+type PolicyVar struct {
+	mu     sync.RWMutex
+	policy Policy
+}
+
+// NewPolicyVar creates a PolicyVar holding policy
+func NewPolicyVar(policy Policy) *PolicyVar {
+	return &PolicyVar{policy: policy}
+}
+
+// Policy returns the current policy
+func (v *PolicyVar) Policy() Policy {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.policy
+}
+
+// Set replaces the policy; buckets keep their tokens and refill at the new rate
+func (v *PolicyVar) Set(policy Policy) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.policy = policy
+}
+
+// refillTime returns how long an empty bucket takes to refill completely
+func (p Policy) refillTime() time.Duration {
+	if p.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(p.Burst) / p.Rate * float64(time.Second))
+}
+
+// Result is the outcome of a single rate limit check
+type Result struct {
+	Allowed    bool
+	Limit      int           // Bucket capacity
+	Remaining  int           // Whole tokens left after this request
+	RetryAfter time.Duration // How long until a token is available (zero when allowed)
+}
+
+// Store takes tokens from named buckets
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Take removes one token from the bucket identified by key
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
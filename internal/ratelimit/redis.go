@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces rate limit buckets in Redis
+const redisKeyPrefix = "ratelimit:"
+
+// takeScript atomically refills and consumes a token bucket stored as a hash
+// KEYS[1] = bucket key; ARGV = rate (tokens/s), burst, now (ms), ttl (ms)
+// Returns {allowed, remaining, retry_after_ms}
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = burst
+  ts = now
+end
+
+local elapsed = math.max(0, now - ts) / 1000
+tokens = math.min(burst, tokens + elapsed * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+elseif rate > 0 then
+  retry = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], ttl)
+
+return {allowed, math.floor(tokens), retry}
+`)
+
+// RedisStore is a Store backed by Redis, sharing buckets across service instances
+type RedisStore struct {
+	client redis.Scripter
+}
+
+// NewRedisStore creates a new Redis-backed token bucket store
+func NewRedisStore(client redis.Scripter) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Take implements Store.Take
+func (s *RedisStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	// Keep idle buckets only as long as they need to refill, plus a small margin
+	ttl := policy.refillTime() + time.Second
+
+	values, err := takeScript.Run(ctx, s.client,
+		[]string{redisKeyPrefix + key},
+		policy.Rate, policy.Burst, time.Now().UnixMilli(), ttl.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      policy.Burst,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,141 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// DefaultSweepInterval is how often expired reports are deleted
+	DefaultSweepInterval = time.Hour
+
+	// queueSize bounds the number of reports waiting to be generated
+	queueSize = 16
+
+	// interruptedMessage is recorded on reports left unfinished by a restart
+	interruptedMessage = "Report generation was interrupted by a service restart; request it again"
+)
+
+// ErrQueueFull is returned when too many reports are already waiting
+var ErrQueueFull = errors.New("report queue is full")
+
+// Generator renders requested reports in the background and deletes them once they expire
+// Reports are generated one at a time, in the order they were requested. Reports that were
+// queued or running when the service stopped are failed by FailInterrupted.
+type Generator struct {
+	repo     repository.ReportRepository
+	interval time.Duration
+	queue    chan *models.Report
+	now      func() time.Time
+}
+
+// NewGenerator creates a new report generator
+func NewGenerator(repo repository.ReportRepository, interval time.Duration) *Generator {
+	if interval <= 0 {
+		interval = DefaultSweepInterval
+	}
+
+	return &Generator{
+		repo:     repo,
+		interval: interval,
+		queue:    make(chan *models.Report, queueSize),
+		now:      time.Now,
+	}
+}
+
+// Submit schedules a report for generation without blocking
+func (g *Generator) Submit(report *models.Report) error {
+	select {
+	case g.queue <- report:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// FailInterrupted marks reports left queued or running by a previous process as failed
+// It must run before new reports are accepted, since they start out pending too.
+func (g *Generator) FailInterrupted(ctx context.Context) error {
+	failed, err := g.repo.FailIncomplete(ctx, interruptedMessage)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		slog.Info("Marked interrupted reports as failed", "count", failed)
+	}
+	return nil
+}
+
+// Run generates queued reports and deletes expired ones until the context is cancelled
+func (g *Generator) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	g.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.sweep(ctx)
+		case report := <-g.queue:
+			if err := g.Process(ctx, report); err != nil {
+				slog.Error("Error generating report", "report_id", report.ID, "error", err)
+			}
+		}
+	}
+}
+
+// Process summarizes the report's range, renders it and stores the file on the report
+func (g *Generator) Process(ctx context.Context, report *models.Report) error {
+	if err := g.repo.UpdateStatus(ctx, report.ID, models.ReportProcessing, nil); err != nil {
+		return fmt.Errorf("failed to start report: %w", err)
+	}
+
+	// The range covers whole days, so it ends at the start of the day after To
+	rows, err := g.repo.Summarize(ctx, report.OrgID, report.From, report.To.AddDate(0, 0, 1))
+	if err != nil {
+		g.fail(ctx, report, "Failed to summarize sessions")
+		return fmt.Errorf("failed to summarize sessions: %w", err)
+	}
+
+	var content bytes.Buffer
+	if err := Render(&content, report.Format, rows); err != nil {
+		g.fail(ctx, report, "Failed to render report")
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+
+	if err := g.repo.Complete(ctx, report.ID, len(rows), content.Bytes()); err != nil {
+		return fmt.Errorf("failed to complete report: %w", err)
+	}
+
+	return nil
+}
+
+// fail marks the report failed with a client-facing message
+// The update ignores cancellation so failures during shutdown are still recorded.
+func (g *Generator) fail(ctx context.Context, report *models.Report, message string) {
+	if err := g.repo.UpdateStatus(context.WithoutCancel(ctx), report.ID, models.ReportFailed, &message); err != nil {
+		slog.Error("Error failing report", "report_id", report.ID, "error", err)
+	}
+}
+
+// sweep deletes reports past their expiry, with their files
+func (g *Generator) sweep(ctx context.Context) {
+	deleted, err := g.repo.DeleteExpired(ctx, g.now())
+	if err != nil {
+		slog.Error("Error deleting expired reports", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("Deleted expired reports", "count", deleted)
+	}
+}
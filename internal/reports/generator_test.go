@@ -0,0 +1,124 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Process(t *testing.T) {
+	repo := repository.NewMockReportRepository()
+	generator := NewGenerator(repo, time.Hour)
+	orgID := uuid.New()
+	report := &models.Report{
+		ID:     uuid.New(),
+		OrgID:  &orgID,
+		Format: models.ReportCSV,
+		From:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	var statuses []models.ReportStatus
+	repo.UpdateStatusFunc = func(_ context.Context, _ uuid.UUID, status models.ReportStatus, _ *string) error {
+		statuses = append(statuses, status)
+		return nil
+	}
+	repo.SummarizeFunc = func(_ context.Context, scope *uuid.UUID, from, to time.Time) ([]*models.ReportRow, error) {
+		assert.Equal(t, &orgID, scope)
+		assert.Equal(t, report.From, from)
+		assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), to, "the last day is included")
+		return sampleRows(), nil
+	}
+	var stored string
+	var storedRows int
+	repo.CompleteFunc = func(_ context.Context, id uuid.UUID, rows int, content []byte) error {
+		assert.Equal(t, report.ID, id)
+		storedRows = rows
+		stored = string(content)
+		return nil
+	}
+
+	require.NoError(t, generator.Process(context.Background(), report))
+
+	assert.Equal(t, []models.ReportStatus{models.ReportProcessing}, statuses)
+	assert.Equal(t, 2, storedRows)
+	assert.Contains(t, stored, "AVT-001,2024-05-13,2,15.250,1.50,140.2")
+}
+
+func TestGenerator_Process_SummarizeError(t *testing.T) {
+	repo := repository.NewMockReportRepository()
+	generator := NewGenerator(repo, time.Hour)
+
+	var failure *string
+	repo.UpdateStatusFunc = func(_ context.Context, _ uuid.UUID, status models.ReportStatus, errMsg *string) error {
+		if status == models.ReportFailed {
+			failure = errMsg
+		}
+		return nil
+	}
+	repo.SummarizeFunc = func(_ context.Context, _ *uuid.UUID, _, _ time.Time) ([]*models.ReportRow, error) {
+		return nil, errors.New("database unavailable")
+	}
+	repo.CompleteFunc = func(_ context.Context, _ uuid.UUID, _ int, _ []byte) error {
+		t.Fatal("failed reports must not be completed")
+		return nil
+	}
+
+	require.Error(t, generator.Process(context.Background(), &models.Report{ID: uuid.New(), Format: models.ReportXLSX}))
+	require.NotNil(t, failure)
+	assert.Equal(t, "Failed to summarize sessions", *failure)
+}
+
+func TestGenerator_Submit_QueueFull(t *testing.T) {
+	generator := NewGenerator(repository.NewMockReportRepository(), time.Hour)
+
+	for range queueSize {
+		require.NoError(t, generator.Submit(&models.Report{ID: uuid.New()}))
+	}
+	assert.ErrorIs(t, generator.Submit(&models.Report{ID: uuid.New()}), ErrQueueFull)
+}
+
+func TestGenerator_RunSweepsAndProcesses(t *testing.T) {
+	repo := repository.NewMockReportRepository()
+	generator := NewGenerator(repo, time.Hour)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	generator.now = func() time.Time { return now }
+
+	swept := make(chan time.Time, 1)
+	repo.DeleteExpiredFunc = func(_ context.Context, before time.Time) (int64, error) {
+		swept <- before
+		return 1, nil
+	}
+	completed := make(chan uuid.UUID, 1)
+	repo.CompleteFunc = func(_ context.Context, id uuid.UUID, _ int, _ []byte) error {
+		completed <- id
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go generator.Run(ctx)
+
+	select {
+	case before := <-swept:
+		assert.Equal(t, now, before)
+	case <-time.After(time.Second):
+		t.Fatal("expired reports were not deleted on start")
+	}
+
+	report := &models.Report{ID: uuid.New(), Format: models.ReportCSV}
+	require.NoError(t, generator.Submit(report))
+	select {
+	case id := <-completed:
+		assert.Equal(t, report.ID, id)
+	case <-time.After(time.Second):
+		t.Fatal("queued report was not generated")
+	}
+}
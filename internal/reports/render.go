@@ -0,0 +1,148 @@
+// Package reports generates fleet usage reports in the background and signs their download links.
+package reports
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// header lists the report columns
+var header = []string{"device_id", "week_start", "sessions", "distance_km", "usage_hours", "max_speed_kmh"}
+
+// numeric marks the columns stored as numbers in XLSX reports
+var numeric = []bool{false, false, true, true, true, true}
+
+// Render writes the rows to w in the given format, after a header row
+func Render(w io.Writer, format models.ReportFormat, rows []*models.ReportRow) error {
+	switch format {
+	case models.ReportCSV:
+		return WriteCSV(w, rows)
+	case models.ReportXLSX:
+		return WriteXLSX(w, rows)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
+
+// records converts the rows to their cell values
+func records(rows []*models.ReportRow) [][]string {
+	out := make([][]string, len(rows))
+	for i, row := range rows {
+		out[i] = []string{
+			row.DeviceID,
+			row.WeekStart.Format("2006-01-02"),
+			strconv.Itoa(row.Sessions),
+			strconv.FormatFloat(row.DistanceMeters/1000, 'f', 3, 64),
+			strconv.FormatFloat(row.UsageHours, 'f', 2, 64),
+			strconv.FormatFloat(row.MaxSpeed, 'f', 1, 64),
+		}
+	}
+	return out
+}
+
+// WriteCSV writes the rows as CSV
+func WriteCSV(w io.Writer, rows []*models.ReportRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(records(rows)); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// xlsxParts are the fixed parts of a single-sheet workbook
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Report" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// WriteXLSX writes the rows as an Excel workbook with a single sheet
+// Text is stored as inline strings, so the workbook needs no shared string table or styles.
+func WriteXLSX(w io.Writer, rows []*models.ReportRow) error {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		fw, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return err
+		}
+	}
+
+	fw, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeSheet(fw, rows); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// writeSheet writes the worksheet holding the header and rows
+func writeSheet(w io.Writer, rows []*models.ReportRow) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+
+	if _, err := bw.WriteString(sheetRow(1, header, false)); err != nil {
+		return err
+	}
+	for i, values := range records(rows) {
+		if _, err := bw.WriteString(sheetRow(i+2, values, true)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := bw.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// sheetRow renders one worksheet row; typed rows store the numeric columns as numbers
+func sheetRow(index int, values []string, typed bool) string {
+	var row strings.Builder
+	fmt.Fprintf(&row, `<row r="%d">`, index)
+	for col, value := range values {
+		ref := string(rune('A'+col)) + strconv.Itoa(index)
+		if typed && numeric[col] {
+			fmt.Fprintf(&row, `<c r="%s"><v>%s</v></c>`, ref, value)
+			continue
+		}
+		fmt.Fprintf(&row, `<c r="%s" t="inlineStr"><is><t>`, ref)
+		_ = xml.EscapeText(&row, []byte(value))
+		row.WriteString(`</t></is></c>`)
+	}
+	row.WriteString(`</row>`)
+	return row.String()
+}
@@ -0,0 +1,59 @@
+package reports
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleRows() []*models.ReportRow {
+	week := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+	return []*models.ReportRow{
+		{DeviceID: "AVT-001", WeekStart: week, Sessions: 2, DistanceMeters: 15250, UsageHours: 1.5, MaxSpeed: 140.25},
+		{DeviceID: "AVT<&>", WeekStart: week.AddDate(0, 0, 7), Sessions: 1, DistanceMeters: 800, UsageHours: 0.25, MaxSpeed: 60},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, models.ReportCSV, sampleRows()))
+
+	assert.Equal(t, "device_id,week_start,sessions,distance_km,usage_hours,max_speed_kmh\n"+
+		"AVT-001,2024-05-13,2,15.250,1.50,140.2\n"+
+		"AVT<&>,2024-05-20,1,0.800,0.25,60.0\n", out.String())
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, models.ReportXLSX, sampleRows()))
+
+	archive, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		parts[f.Name] = string(content)
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts, "xl/workbook.xml")
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr"><is><t>device_id</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="C2"><v>2</v></c>`)
+	assert.Contains(t, sheet, `<c r="D2"><v>15.250</v></c>`)
+	assert.Contains(t, sheet, `<c r="A3" t="inlineStr"><is><t>AVT&lt;&amp;&gt;</t></is></c>`)
+}
+
+func TestRender_UnsupportedFormat(t *testing.T) {
+	assert.Error(t, Render(io.Discard, models.ReportFormat("pdf"), nil))
+}
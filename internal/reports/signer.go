@@ -0,0 +1,64 @@
+package reports
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// signingContext separates download signatures from other uses of the secret
+const signingContext = "avt-report-download"
+
+// KeySource provides the keys derived for a purpose, the key new values are signed with first
+// auth.JWTService derives them from the JWT secret, so links follow its rotation.
+type KeySource interface {
+	DeriveKeys(purpose string) [][]byte
+}
+
+// Signer signs and verifies time-limited report download links
+// Links carry their expiry and an HMAC-SHA256 of the report ID and expiry, so reports can be
+// downloaded without credentials until the link expires. Links signed before a key rotation stay
+// valid as long as the source still provides the previous key.
+type Signer struct {
+	keys KeySource
+}
+
+// NewSigner creates a signer whose keys are derived by keys
+func NewSigner(keys KeySource) *Signer {
+	return &Signer{keys: keys}
+}
+
+// URL returns the download path of the report under the API prefix, e.g. "/api/v1", valid until expires
+func (s *Signer) URL(prefix string, id uuid.UUID, expires time.Time) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", hex.EncodeToString(sign(s.keys.DeriveKeys(signingContext)[0], id, expires.Unix())))
+	return prefix + "/reports/" + id.String() + "/download?" + query.Encode()
+}
+
+// Verify checks that signature was issued for the report and expiry, given as Unix seconds
+// It does not check whether the link has expired.
+func (s *Signer) Verify(id uuid.UUID, expires int64, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	for _, key := range s.keys.DeriveKeys(signingContext) {
+		if hmac.Equal(sign(key, id, expires), expected) {
+			return true
+		}
+	}
+	return false
+}
+
+// sign computes the signature of a report ID and expiry
+func sign(key []byte, id uuid.UUID, expires int64) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id.String() + "." + strconv.FormatInt(expires, 10)))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,43 @@
+package reports
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticKeys is a KeySource whose keys are the purpose suffixed to each secret
+type staticKeys []string
+
+func (k staticKeys) DeriveKeys(purpose string) [][]byte {
+	keys := make([][]byte, len(k))
+	for i, secret := range k {
+		keys[i] = []byte(secret + purpose)
+	}
+	return keys
+}
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner(staticKeys{"secret"})
+	id := uuid.New()
+	expires := time.Unix(1715600000, 0)
+
+	link, err := url.Parse(signer.URL("/api/v2", id, expires))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v2/reports/"+id.String()+"/download", link.Path)
+	assert.Equal(t, "1715600000", link.Query().Get("expires"))
+
+	signature := link.Query().Get("signature")
+	assert.True(t, signer.Verify(id, expires.Unix(), signature))
+	assert.False(t, signer.Verify(uuid.New(), expires.Unix(), signature), "another report")
+	assert.False(t, signer.Verify(id, expires.Unix()+3600, signature), "extended expiry")
+	assert.False(t, signer.Verify(id, expires.Unix(), strings.Repeat("0", len(signature))), "forged signature")
+	assert.False(t, signer.Verify(id, expires.Unix(), "not-hex"))
+	assert.False(t, NewSigner(staticKeys{"other"}).Verify(id, expires.Unix(), signature), "another secret")
+	assert.True(t, NewSigner(staticKeys{"other", "secret"}).Verify(id, expires.Unix(), signature), "the previous secret")
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// AlertEventFilter restricts which alert events are returned
+type AlertEventFilter struct {
+	UserID   uuid.UUID  // Required: events are always scoped to their owner
+	RuleID   *uuid.UUID // Optional
+	DeviceID string     // Optional
+	Limit    int
+}
+
+// AlertRuleRepository defines the interface for alert rule data access
+type AlertRuleRepository interface {
+	// Create stores a new alert rule
+	Create(ctx context.Context, rule *models.AlertRule) error
+
+	// GetByID retrieves an alert rule by its UUID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.AlertRule, error)
+
+	// ListByUserID retrieves all alert rules owned by a user, most recent first
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.AlertRule, error)
+
+	// ListActive retrieves the user's active rules for a device for evaluation
+	ListActive(ctx context.Context, userID uuid.UUID, deviceID string) ([]*models.AlertRule, error)
+
+	// Update stores a rule's name, device, metric, threshold, cooldown, webhook URL and active flag
+	Update(ctx context.Context, rule *models.AlertRule) error
+
+	// Delete removes an alert rule together with its events
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// RecordEvents stores alert events and advances their rules' last trigger time in one transaction
+	// A rule's last trigger time never moves back, so out-of-order uploads cannot reopen a cooldown.
+	RecordEvents(ctx context.Context, events []*models.AlertEvent) error
+
+	// ListEvents retrieves alert events matching the filter, most recent first
+	ListEvents(ctx context.Context, filter AlertEventFilter) ([]*models.AlertEvent, error)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ArchiveFilter selects the archives of a user's telemetry overlapping a time range
+type ArchiveFilter struct {
+	// UserID restricts results to archives holding the user's records (required)
+	UserID uuid.UUID
+
+	// DeviceID optionally restricts results to one device
+	DeviceID string
+
+	// From and To bound the archived recorded_at range (inclusive)
+	From time.Time
+	To   time.Time
+}
+
+// ArchiveRepository defines the interface for moving old telemetry to object storage
+// Only owned telemetry from known devices is archived; anonymous records stay in the hypertable
+// so device adoption can still claim them.
+type ArchiveRepository interface {
+	// ListPendingChunks returns device days recorded before the given time that still have
+	// telemetry in the hypertable, oldest first
+	ListPendingChunks(ctx context.Context, before time.Time, limit int) ([]models.ArchiveChunk, error)
+
+	// IterateChunk streams a chunk's telemetry in chronological order
+	IterateChunk(ctx context.Context, chunk models.ArchiveChunk) (TelemetryIterator, error)
+
+	// Complete stores an archive's manifest and deletes the archived records, with their processed
+	// telemetry, in one transaction
+	// Records of the chunk with IDs above archive.MaxID are left for a later archive. Returns
+	// ErrArchiveChanged if the remaining records do not match archive.RowCount.
+	Complete(ctx context.Context, archive *models.TelemetryArchive) error
+
+	// List returns the archives matching the filter, oldest first
+	List(ctx context.Context, filter ArchiveFilter) ([]*models.TelemetryArchive, error)
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/cache"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// CachedDeviceRepository caches device listings in front of another DeviceRepository
+// Creating, updating, sharing, reassigning or tagging a device or recording a new firmware version
+// through this repository invalidates every cached listing, since shared devices appear in other users'
+// lists. Last-seen updates do not, so lastSeenAt and the online filter may lag by up to the cache TTL.
+type CachedDeviceRepository struct {
+	DeviceRepository
+	store cache.Cache
+	lists cache.Namespace
+	ttl   time.Duration
+}
+
+// cachedDeviceList is a cached device listing page and its total
+type cachedDeviceList struct {
+	Devices []*models.Device `json:"devices"`
+	Total   int              `json:"total"`
+}
+
+// NewCachedDeviceRepository wraps repo with a cache whose entries expire after ttl
+func NewCachedDeviceRepository(repo DeviceRepository, c cache.Cache, ttl time.Duration) *CachedDeviceRepository {
+	return &CachedDeviceRepository{
+		DeviceRepository: repo,
+		store:            c,
+		lists:            cache.NewNamespace(c, "devices"),
+		ttl:              ttl,
+	}
+}
+
+// Create implements DeviceRepository.Create
+func (r *CachedDeviceRepository) Create(ctx context.Context, device *models.Device) error {
+	if err := r.DeviceRepository.Create(ctx, device); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.lists)
+	return nil
+}
+
+// Claim implements DeviceRepository.Claim
+func (r *CachedDeviceRepository) Claim(ctx context.Context, device *models.Device) (*models.Device, error) {
+	claimed, err := r.DeviceRepository.Claim(ctx, device)
+	if err == nil && claimed.ID == device.ID {
+		cacheInvalidate(ctx, r.lists)
+	}
+	return claimed, err
+}
+
+// ClaimAll implements DeviceRepository.ClaimAll
+func (r *CachedDeviceRepository) ClaimAll(ctx context.Context, devices []*models.Device) ([]*models.Device, error) {
+	stored, err := r.DeviceRepository.ClaimAll(ctx, devices)
+	if err != nil {
+		return nil, err
+	}
+	for i, device := range stored {
+		if device.ID == devices[i].ID {
+			cacheInvalidate(ctx, r.lists)
+			break
+		}
+	}
+	return stored, nil
+}
+
+// ListByUserID implements DeviceRepository.ListByUserID
+func (r *CachedDeviceRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Device, error) {
+	key := cacheKey(ctx, r.lists, "user", userID.String())
+	if key != "" {
+		var cached []*models.Device
+		if cacheLookup(ctx, r.store, key, &cached) {
+			return cached, nil
+		}
+	}
+
+	devices, err := r.DeviceRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		cacheStore(ctx, r.store, key, devices, r.ttl)
+	}
+	return devices, nil
+}
+
+// List implements DeviceRepository.List
+func (r *CachedDeviceRepository) List(ctx context.Context, filter DeviceFilter) ([]*models.Device, int, error) {
+	var key string
+	if encoded, err := json.Marshal(filter); err == nil {
+		key = cacheKey(ctx, r.lists, "list", string(encoded))
+	}
+	if key != "" {
+		var cached cachedDeviceList
+		if cacheLookup(ctx, r.store, key, &cached) {
+			return cached.Devices, cached.Total, nil
+		}
+	}
+
+	devices, total, err := r.DeviceRepository.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if key != "" {
+		cacheStore(ctx, r.store, key, cachedDeviceList{Devices: devices, Total: total}, r.ttl)
+	}
+	return devices, total, nil
+}
+
+// Update implements DeviceRepository.Update
+func (r *CachedDeviceRepository) Update(ctx context.Context, device *models.Device) error {
+	if err := r.DeviceRepository.Update(ctx, device); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.lists)
+	return nil
+}
+
+// UpdateFirmware implements DeviceRepository.UpdateFirmware
+func (r *CachedDeviceRepository) UpdateFirmware(ctx context.Context, id uuid.UUID, version string) error {
+	if err := r.DeviceRepository.UpdateFirmware(ctx, id, version); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.lists)
+	return nil
+}
+
+// SetOrganization implements DeviceRepository.SetOrganization
+func (r *CachedDeviceRepository) SetOrganization(ctx context.Context, id uuid.UUID, orgID *uuid.UUID) error {
+	if err := r.DeviceRepository.SetOrganization(ctx, id, orgID); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.lists)
+	return nil
+}
+
+// Reassign implements DeviceRepository.Reassign
+func (r *CachedDeviceRepository) Reassign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if err := r.DeviceRepository.Reassign(ctx, id, userID); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.lists)
+	return nil
+}
+
+// SetTags implements DeviceRepository.SetTags
+func (r *CachedDeviceRepository) SetTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	if err := r.DeviceRepository.SetTags(ctx, id, tags); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.lists)
+	return nil
+}
+
+// AddTag implements DeviceRepository.AddTag
+func (r *CachedDeviceRepository) AddTag(ctx context.Context, id uuid.UUID, tag string) error {
+	if err := r.DeviceRepository.AddTag(ctx, id, tag); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.lists)
+	return nil
+}
+
+// RemoveTag implements DeviceRepository.RemoveTag
+func (r *CachedDeviceRepository) RemoveTag(ctx context.Context, id uuid.UUID, tag string) error {
+	if err := r.DeviceRepository.RemoveTag(ctx, id, tag); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.lists)
+	return nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/cache"
+)
+
+// cacheLookup reads a cached query result into dest
+// The cache only saves database load, so errors are logged and treated as misses.
+func cacheLookup(ctx context.Context, c cache.Cache, key string, dest interface{}) bool {
+	found, err := c.Get(ctx, key, dest)
+	if err != nil {
+		slog.Error("Error reading cache key", "key", key, "error", err)
+		return false
+	}
+	return found
+}
+
+// cacheStore caches a query result, logging failures
+func cacheStore(ctx context.Context, c cache.Cache, key string, value interface{}, ttl time.Duration) {
+	if err := c.Set(ctx, key, value, ttl); err != nil {
+		slog.Error("Error writing cache key", "key", key, "error", err)
+	}
+}
+
+// cacheKey returns a namespace key, logging failures; an empty key skips the cache
+func cacheKey(ctx context.Context, namespace cache.Namespace, parts ...string) string {
+	key, err := namespace.Key(ctx, parts...)
+	if err != nil {
+		slog.Error("Error reading cache version", "error", err)
+		return ""
+	}
+	return key
+}
+
+// cacheInvalidate invalidates a namespace after a write, logging failures
+// A failed invalidation leaves stale results for at most the cache TTL.
+func cacheInvalidate(ctx context.Context, namespace cache.Namespace) {
+	if err := namespace.Invalidate(ctx); err != nil {
+		slog.Error("Error invalidating cache", "error", err)
+	}
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/cache"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedTelemetryRepository_GetRecent(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockRepository()
+	reads := 0
+	mock.GetRecentFunc = func(_ context.Context, limit int) ([]*models.TelemetryData, error) {
+		reads++
+		return []*models.TelemetryData{{ID: int64(reads), DeviceID: "RACEBOX-001"}}, nil
+	}
+	repo := NewCachedTelemetryRepository(mock, cache.NewMemoryCache(), time.Minute)
+
+	first, err := repo.GetRecent(ctx, 10)
+	require.NoError(t, err)
+	second, err := repo.GetRecent(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reads, "the second read should be served from the cache")
+	assert.Equal(t, first[0].ID, second[0].ID)
+
+	_, err = repo.GetRecent(ctx, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 2, reads, "each limit is cached separately")
+
+	require.NoError(t, repo.Save(ctx, &models.TelemetryData{DeviceID: "RACEBOX-001"}))
+	third, err := repo.GetRecent(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reads, "saves invalidate cached results")
+	assert.Equal(t, int64(3), third[0].ID)
+}
+
+func TestCachedDeviceRepository_List(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	mock := NewMockDeviceRepository()
+	reads := 0
+	mock.ListFunc = func(_ context.Context, filter DeviceFilter) ([]*models.Device, int, error) {
+		reads++
+		return []*models.Device{{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: filter.UserID}}, 1, nil
+	}
+	repo := NewCachedDeviceRepository(mock, cache.NewMemoryCache(), time.Minute)
+
+	filter := DeviceFilter{UserID: userID, Limit: 50}
+	devices, total, err := repo.List(ctx, filter)
+	require.NoError(t, err)
+	_, _, err = repo.List(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, userID, devices[0].UserID)
+
+	_, _, err = repo.List(ctx, DeviceFilter{UserID: userID, Limit: 50, Offset: 50})
+	require.NoError(t, err)
+	assert.Equal(t, 2, reads, "each page is cached separately")
+
+	require.NoError(t, repo.Update(ctx, devices[0]))
+	_, _, err = repo.List(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reads, "device updates invalidate every listing")
+
+	_, err = repo.Claim(ctx, &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-002", UserID: userID})
+	require.NoError(t, err)
+	_, _, err = repo.List(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, 4, reads, "claiming a new device invalidates every listing")
+}
+
+func TestCachedSessionRepository_GetByID(t *testing.T) {
+	ctx := context.Background()
+	sessionID := uuid.New()
+	distance := 1200.0
+	mock := NewMockSessionRepository()
+	reads := 0
+	mock.GetByIDFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		reads++
+		return &models.Session{ID: id, DeviceID: "RACEBOX-001"}, nil
+	}
+	mock.UpdateSummaryFunc = func(_ context.Context, id uuid.UUID) (*models.Session, error) {
+		return &models.Session{ID: id, DeviceID: "RACEBOX-001", TotalDistance: &distance}, nil
+	}
+	repo := NewCachedSessionRepository(mock, cache.NewMemoryCache(), time.Minute)
+
+	_, err := repo.GetByID(ctx, sessionID)
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reads)
+
+	_, err = repo.UpdateSummary(ctx, sessionID)
+	require.NoError(t, err)
+	session, err := repo.GetByID(ctx, sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reads, "recomputed summaries refresh the cached session")
+	require.NotNil(t, session.TotalDistance)
+	assert.Equal(t, distance, *session.TotalDistance)
+
+	require.NoError(t, repo.End(ctx, sessionID, time.Now()))
+	_, err = repo.GetByID(ctx, sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, reads, "ending a session evicts it")
+
+	mock.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.Session, error) {
+		return nil, ErrSessionNotFound
+	}
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
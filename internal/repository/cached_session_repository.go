@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/cache"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// CachedSessionRepository caches sessions, including their summary aggregates, in front of another SessionRepository
+// Ending a session, editing its details or recomputing its summary through this repository refreshes the cached copy;
+// changes made elsewhere (e.g., assigning an adopted device's sessions) show up once it expires.
+type CachedSessionRepository struct {
+	SessionRepository
+	store cache.Cache
+	ttl   time.Duration
+}
+
+// NewCachedSessionRepository wraps repo with a cache whose entries expire after ttl
+func NewCachedSessionRepository(repo SessionRepository, c cache.Cache, ttl time.Duration) *CachedSessionRepository {
+	return &CachedSessionRepository{
+		SessionRepository: repo,
+		store:             c,
+		ttl:               ttl,
+	}
+}
+
+// GetByID implements SessionRepository.GetByID
+func (r *CachedSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	key := sessionCacheKey(id)
+
+	var cached models.Session
+	if cacheLookup(ctx, r.store, key, &cached) {
+		return &cached, nil
+	}
+
+	session, err := r.SessionRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cacheStore(ctx, r.store, key, session, r.ttl)
+	return session, nil
+}
+
+// UpdateDetails implements SessionRepository.UpdateDetails
+func (r *CachedSessionRepository) UpdateDetails(ctx context.Context, session *models.Session) error {
+	err := r.SessionRepository.UpdateDetails(ctx, session)
+	r.evict(ctx, session.ID)
+	return err
+}
+
+// End implements SessionRepository.End
+func (r *CachedSessionRepository) End(ctx context.Context, id uuid.UUID, endedAt time.Time) error {
+	err := r.SessionRepository.End(ctx, id, endedAt)
+	r.evict(ctx, id)
+	return err
+}
+
+// UpdateSummary implements SessionRepository.UpdateSummary
+func (r *CachedSessionRepository) UpdateSummary(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	session, err := r.SessionRepository.UpdateSummary(ctx, id)
+	if err != nil {
+		r.evict(ctx, id)
+		return nil, err
+	}
+	cacheStore(ctx, r.store, sessionCacheKey(id), session, r.ttl)
+	return session, nil
+}
+
+// evict drops a session's cached copy, logging failures
+func (r *CachedSessionRepository) evict(ctx context.Context, id uuid.UUID) {
+	if err := r.store.Delete(ctx, sessionCacheKey(id)); err != nil {
+		slog.Error("Error evicting cached session", "session_id", id, "error", err)
+	}
+}
+
+func sessionCacheKey(id uuid.UUID) string {
+	return "session:" + id.String()
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/cache"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// CachedTelemetryRepository caches the most recent telemetry in front of another TelemetryRepository
+// Saves through this repository invalidate the cached results; telemetry written elsewhere
+// (e.g., by the MQTT bridge on another instance) shows up once they expire.
+type CachedTelemetryRepository struct {
+	TelemetryRepository
+	store  cache.Cache
+	recent cache.Namespace
+	ttl    time.Duration
+}
+
+// NewCachedTelemetryRepository wraps repo with a cache whose entries expire after ttl
+func NewCachedTelemetryRepository(repo TelemetryRepository, c cache.Cache, ttl time.Duration) *CachedTelemetryRepository {
+	return &CachedTelemetryRepository{
+		TelemetryRepository: repo,
+		store:               c,
+		recent:              cache.NewNamespace(c, "telemetry:recent"),
+		ttl:                 ttl,
+	}
+}
+
+// Save implements TelemetryRepository.Save
+func (r *CachedTelemetryRepository) Save(ctx context.Context, data *models.TelemetryData) error {
+	if err := r.TelemetryRepository.Save(ctx, data); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.recent)
+	return nil
+}
+
+// SaveBatch implements TelemetryRepository.SaveBatch
+func (r *CachedTelemetryRepository) SaveBatch(ctx context.Context, data []*models.TelemetryData) error {
+	if err := r.TelemetryRepository.SaveBatch(ctx, data); err != nil {
+		return err
+	}
+	cacheInvalidate(ctx, r.recent)
+	return nil
+}
+
+// GetRecent implements TelemetryRepository.GetRecent
+func (r *CachedTelemetryRepository) GetRecent(ctx context.Context, limit int) ([]*models.TelemetryData, error) {
+	key := cacheKey(ctx, r.recent, strconv.Itoa(limit))
+	if key != "" {
+		var cached []*models.TelemetryData
+		if cacheLookup(ctx, r.store, key, &cached) {
+			return cached, nil
+		}
+	}
+
+	recent, err := r.TelemetryRepository.GetRecent(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		cacheStore(ctx, r.store, key, recent, r.ttl)
+	}
+	return recent, nil
+}
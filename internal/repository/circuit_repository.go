@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// CircuitRepository defines the interface for circuit catalog access
+type CircuitRepository interface {
+	// Nearby returns up to limit circuits within radiusMeters of a point, closest first
+	Nearby(ctx context.Context, point geo.Point, radiusMeters float64, limit int) ([]*models.Circuit, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// DerivedChannelRepository fills in the derived channels of telemetry stored without them
+type DerivedChannelRepository interface {
+	// Backfill derives the channels of up to limit records recorded in [from, to) that have none,
+	// returning how many were updated
+	// Deltas are taken from the device's previous record within models.MaxDerivedGap, wherever it lies.
+	Backfill(ctx context.Context, from, to time.Time, limit int) (int64, error)
+
+	// OldestRecordedAt returns when the oldest stored telemetry was recorded, or nil when there is none
+	OldestRecordedAt(ctx context.Context) (*time.Time, error)
+}
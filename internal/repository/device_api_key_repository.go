@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// DeviceAPIKeyRepository defines the interface for device API key data access
+type DeviceAPIKeyRepository interface {
+	// Create stores a new device API key
+	Create(ctx context.Context, key *models.DeviceAPIKey) error
+
+	// GetByID retrieves a device API key by its UUID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DeviceAPIKey, error)
+
+	// GetByHash retrieves an active (non-revoked) device API key by its hash
+	GetByHash(ctx context.Context, hash string) (*models.DeviceAPIKey, error)
+
+	// ListByDeviceID retrieves all keys minted for a device, newest first
+	ListByDeviceID(ctx context.Context, deviceID uuid.UUID) ([]*models.DeviceAPIKey, error)
+
+	// Revoke marks a device API key as revoked by its ID
+	Revoke(ctx context.Context, id uuid.UUID) error
+
+	// UpdateLastUsed updates the last_used_at timestamp for a key
+	UpdateLastUsed(ctx context.Context, id uuid.UUID) error
+}
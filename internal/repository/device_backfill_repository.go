@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// DeviceBackfillRepository defines the interface for backfills of claimed devices' anonymous history
+type DeviceBackfillRepository interface {
+	// Create stores a new pending backfill
+	// Returns ErrDeviceBackfillInProgress if the device already has an unfinished backfill.
+	Create(ctx context.Context, backfill *models.DeviceBackfill) error
+
+	// GetLatest retrieves the device's most recent backfill
+	GetLatest(ctx context.Context, deviceID uuid.UUID) (*models.DeviceBackfill, error)
+
+	// ListIncomplete returns the IDs of pending or processing backfills, oldest first
+	ListIncomplete(ctx context.Context, limit int) ([]uuid.UUID, error)
+
+	// Start marks a pending or processing backfill as processing and counts the records left to assign
+	// Returns ErrDeviceBackfillNotFound if the backfill does not exist or has finished.
+	Start(ctx context.Context, id uuid.UUID) (*models.DeviceBackfill, error)
+
+	// AssignBatch assigns up to limit of the device's anonymous telemetry records to its owner,
+	// adding them to the backfill's progress, and returns how many were assigned
+	AssignBatch(ctx context.Context, id uuid.UUID, limit int) (int64, error)
+
+	// Complete assigns the device's anonymous sessions and upload batches and marks the backfill completed
+	Complete(ctx context.Context, id uuid.UUID) error
+
+	// Fail marks the backfill as failed with the given error
+	Fail(ctx context.Context, id uuid.UUID, errMsg string) error
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrDeviceCommandNotFound is returned when a command does not exist for the device
+	ErrDeviceCommandNotFound = errors.New("device command not found")
+
+	// ErrDeviceCommandFinished is returned when a command can no longer be acknowledged or cancelled
+	ErrDeviceCommandFinished = errors.New("device command already finished")
+)
+
+// DeviceCommandFilter narrows a device's command history
+type DeviceCommandFilter struct {
+	Status models.DeviceCommandStatus // Empty lists every status
+	Limit  int
+}
+
+// DeviceCommandRepository defines the interface for the remote actions queued for devices
+// Commands that were not acknowledged before ExpiresAt are marked expired when they are read.
+type DeviceCommandRepository interface {
+	// Create queues a command for its device
+	Create(ctx context.Context, command *models.DeviceCommand) error
+
+	// Get retrieves one of the device's commands
+	// Returns ErrDeviceCommandNotFound if the command does not exist for the device.
+	Get(ctx context.Context, deviceID, id uuid.UUID) (*models.DeviceCommand, error)
+
+	// List retrieves the device's commands matching the filter, most recent first
+	List(ctx context.Context, deviceID uuid.UUID, filter DeviceCommandFilter) ([]*models.DeviceCommand, error)
+
+	// Poll returns the device's unacknowledged commands, oldest first, and marks them delivered
+	// Commands stay outstanding until acknowledged, so a device that loses a poll response gets them again.
+	Poll(ctx context.Context, deviceID uuid.UUID, limit int) ([]*models.DeviceCommand, error)
+
+	// Acknowledge records the outcome the device reported for a command
+	// Returns ErrDeviceCommandNotFound if the command does not exist for the device and
+	// ErrDeviceCommandFinished if it was already acknowledged, cancelled or expired.
+	Acknowledge(ctx context.Context, deviceID, id uuid.UUID, status models.DeviceCommandStatus, message string) (*models.DeviceCommand, error)
+
+	// Cancel withdraws a command the device has not acknowledged
+	// Returns ErrDeviceCommandNotFound if the command does not exist for the device and
+	// ErrDeviceCommandFinished if it was already acknowledged, cancelled or expired.
+	Cancel(ctx context.Context, deviceID, id uuid.UUID) (*models.DeviceCommand, error)
+}
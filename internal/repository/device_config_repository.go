@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// DeviceConfigRepository defines the interface for the configuration pushed to devices
+type DeviceConfigRepository interface {
+	// Get retrieves the device's desired configuration
+	// Returns ErrDeviceConfigNotFound if none has been set.
+	Get(ctx context.Context, deviceID uuid.UUID) (*models.DeviceConfig, error)
+
+	// Put stores the device's desired configuration, replacing any earlier one
+	// The version and update time are set on config.
+	Put(ctx context.Context, config *models.DeviceConfig) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// DeviceHealthRepository defines the interface for device health data access
+type DeviceHealthRepository interface {
+	// GetState retrieves the latest known health of a device
+	GetState(ctx context.Context, deviceID string) (*models.DeviceHealthState, error)
+
+	// Record stores the new device state, merges the snapshots into existing buckets and
+	// inserts the events, all in one transaction
+	// A state older than the stored one is ignored so out-of-order uploads cannot rewind it.
+	Record(ctx context.Context, state *models.DeviceHealthState, snapshots []*models.DeviceHealthSnapshot, events []*models.DeviceHealthEvent) error
+
+	// ListSnapshots retrieves a device's snapshots with buckets starting at or after since, oldest first
+	ListSnapshots(ctx context.Context, deviceID string, since time.Time) ([]*models.DeviceHealthSnapshot, error)
+
+	// ListEvents retrieves a device's most recent health events, newest first
+	ListEvents(ctx context.Context, deviceID string, limit int) ([]*models.DeviceHealthEvent, error)
+}
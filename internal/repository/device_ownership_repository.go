@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// DeviceOwnershipRepository defines the interface for the append-only device ownership history
+type DeviceOwnershipRepository interface {
+	// Record appends an ownership change, setting its ID and timestamp
+	Record(ctx context.Context, change *models.DeviceOwnershipChange) error
+
+	// ListByDevice retrieves a device's ownership changes, oldest first
+	ListByDevice(ctx context.Context, deviceID uuid.UUID) ([]*models.DeviceOwnershipChange, error)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// DeviceRegistrationRepository defines the interface for device pre-registration data access
+type DeviceRegistrationRepository interface {
+	// Create stores a new pre-registration
+	// ErrDeviceRegistrationExists is returned if the device has already registered.
+	Create(ctx context.Context, registration *models.DeviceRegistration) error
+
+	// GetByDeviceID retrieves the pre-registration of a hardware device ID
+	GetByDeviceID(ctx context.Context, deviceID string) (*models.DeviceRegistration, error)
+
+	// Adopt marks the registration as adopted by the device's owner and creates the device, in one transaction
+	// ErrDeviceRegistrationAdopted is returned if it was already adopted and ErrDeviceExists if the
+	// device has been claimed some other way.
+	Adopt(ctx context.Context, device *models.Device) error
+
+	// ListPendingBackfills returns adopted devices whose history has not been backfilled yet, oldest first
+	ListPendingBackfills(ctx context.Context, limit int) ([]string, error)
+
+	// Backfill assigns the device's telemetry, sessions and upload batches without an owner to its adopter
+	// and returns the number of telemetry records updated
+	Backfill(ctx context.Context, deviceID string) (int64, error)
+}
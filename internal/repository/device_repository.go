@@ -2,16 +2,79 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
 )
 
+// DeviceSort identifies a device listing sort key
+type DeviceSort string
+
+// Supported device sort keys
+const (
+	DeviceSortClaimedAt  DeviceSort = "claimedAt"
+	DeviceSortLastSeenAt DeviceSort = "lastSeenAt"
+	DeviceSortName       DeviceSort = "name"
+	DeviceSortDeviceID   DeviceSort = "deviceId"
+)
+
+// IsValid checks if the sort key is supported
+func (s DeviceSort) IsValid() bool {
+	switch s {
+	case DeviceSortClaimedAt, DeviceSortLastSeenAt, DeviceSortName, DeviceSortDeviceID:
+		return true
+	default:
+		return false
+	}
+}
+
+// DeviceFilter describes a filtered, sorted, paginated device listing
+type DeviceFilter struct {
+	// UserID restricts results to the owner's devices
+	UserID uuid.UUID
+
+	// OrgIDs optionally adds devices shared with any of these organizations
+	OrgIDs []uuid.UUID
+
+	// IsActive optionally restricts results by device status
+	IsActive *bool
+
+	// Online optionally restricts results to devices seen (or not seen) within models.DeviceOnlineWindow
+	Online *bool
+
+	// Tag optionally restricts results to devices carrying the tag
+	Tag string
+
+	// Sort selects the sort key (default claimedAt) and Ascending its direction (default descending)
+	Sort      DeviceSort
+	Ascending bool
+
+	// Limit and Offset paginate the results
+	Limit  int
+	Offset int
+
+	// After resumes the listing after the given position (exclusive)
+	// Only the claimedAt sort supports cursors; other sorts ignore it.
+	After *pagination.Cursor
+}
+
 // DeviceRepository defines the interface for device data access
 type DeviceRepository interface {
 	// Create stores a new device
 	Create(ctx context.Context, device *models.Device) error
 
+	// Claim atomically stores a new device unless its device_id is already taken, and returns the
+	// stored device: the new one, or the existing one if another request claimed it first.
+	// ErrDeviceClaimed is returned alongside the existing device when it belongs to another user.
+	Claim(ctx context.Context, device *models.Device) (*models.Device, error)
+
+	// ClaimAll claims devices like Claim in a single transaction and returns the stored devices in
+	// the same order. Devices belonging to another user do not fail the others; callers compare IDs
+	// and owners to tell new, already owned and conflicting devices apart.
+	ClaimAll(ctx context.Context, devices []*models.Device) ([]*models.Device, error)
+
 	// GetByID retrieves a device by its UUID
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Device, error)
 
@@ -21,9 +84,44 @@ type DeviceRepository interface {
 	// ListByUserID retrieves all devices owned by a user
 	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Device, error)
 
+	// List retrieves a page of a user's devices and the total number matching the filter
+	List(ctx context.Context, filter DeviceFilter) ([]*models.Device, int, error)
+
 	// Update updates a device's information
 	Update(ctx context.Context, device *models.Device) error
 
 	// UpdateLastSeen updates the last_seen_at timestamp for a device
 	UpdateLastSeen(ctx context.Context, deviceID string) error
+
+	// UpdateFirmware records the firmware version a device reports
+	UpdateFirmware(ctx context.Context, id uuid.UUID, version string) error
+
+	// FirmwareDistribution counts active devices per reported firmware version, most common first
+	FirmwareDistribution(ctx context.Context) ([]*models.FirmwareCount, error)
+
+	// ListSeenSince retrieves all devices whose last_seen_at is at or after since
+	ListSeenSince(ctx context.Context, since time.Time) ([]*models.Device, error)
+
+	// SetOrganization shares a device with an organization, or makes it personal again when orgID is nil
+	SetOrganization(ctx context.Context, id uuid.UUID, orgID *uuid.UUID) error
+
+	// Reassign transfers a device to another user and revokes its API keys
+	Reassign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+
+	// SetTags replaces a device's tags
+	SetTags(ctx context.Context, id uuid.UUID, tags []string) error
+
+	// AddTag tags a device; adding a tag the device already carries is a no-op
+	AddTag(ctx context.Context, id uuid.UUID, tag string) error
+
+	// RemoveTag removes a tag from a device, returning ErrDeviceTagNotFound when the device does not carry it
+	RemoveTag(ctx context.Context, id uuid.UUID, tag string) error
+
+	// TagsByDevice retrieves the tags of the given devices in alphabetical order, keyed by device ID
+	// Devices without tags are left out of the map.
+	TagsByDevice(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error)
+
+	// TagCounts counts the devices carrying each tag among those the filter's UserID and OrgIDs can
+	// list, in alphabetical order. The other filter fields are ignored.
+	TagCounts(ctx context.Context, filter DeviceFilter) ([]*models.DeviceTagCount, error)
 }
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// EventOutboxRepository defines the interface for the outbox of domain events
+type EventOutboxRepository interface {
+	// Append stores events for delivery within tx, setting their IDs
+	// The events are delivered once tx commits, and never if it rolls back.
+	Append(ctx context.Context, tx *sql.Tx, events ...*models.Event) error
+
+	// ClaimDue returns up to limit undelivered events whose next attempt is due, oldest first
+	// Claimed events are leased until lease from now so other dispatchers skip them, and their
+	// attempts are incremented.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.Event, error)
+
+	// MarkDelivered records that every subscriber handled the event
+	MarkDelivered(ctx context.Context, id int64) error
+
+	// MarkFailed records a failed delivery, retried at retryAt
+	// A nil retryAt abandons the event, which is then never retried.
+	MarkFailed(ctx context.Context, id int64, errMsg string, retryAt *time.Time) error
+
+	// DeleteDelivered deletes events delivered before the given time, returning how many were deleted
+	DeleteDelivered(ctx context.Context, before time.Time) (int64, error)
+}
+
+// eventsKey is the context key of the function building the events stored with a change
+type eventsKey struct{}
+
+// WithEvents returns a context under which the change a repository stores also stores the events
+// build returns, in the outbox and in the same transaction
+// build is called inside the transaction with the records the change stored: the telemetry left
+// after duplicates are skipped, the devices newly claimed, and so on. Nothing is stored when the
+// change stores no record. The events are thus delivered if and only if the change commits. Only
+// the PostgreSQL repositories of changes that publish events store them; derive the context for a
+// single call so other changes made with it do not store the events again.
+func WithEvents[T any](ctx context.Context, build func(stored []T) ([]*models.Event, error)) context.Context {
+	return context.WithValue(ctx, eventsKey{}, build)
+}
+
+// BuildEvents returns the events the context of a change builds for the records it stored
+// Repositories call it inside the change's transaction; mocks call it to capture the events.
+func BuildEvents[T any](ctx context.Context, stored []T) ([]*models.Event, error) {
+	value := ctx.Value(eventsKey{})
+	if value == nil || len(stored) == 0 {
+		return nil, nil
+	}
+	build, ok := value.(func([]T) ([]*models.Event, error))
+	if !ok {
+		return nil, fmt.Errorf("events are not built from %T records", stored)
+	}
+	return build(stored)
+}
+
+// hasEvents reports whether changes stored under ctx publish events
+func hasEvents(ctx context.Context) bool {
+	return ctx.Value(eventsKey{}) != nil
+}
+
+// appendEvents stores the events of ctx for the records a change stored within tx
+func appendEvents[T any](ctx context.Context, tx *sql.Tx, stored []T) error {
+	events, err := BuildEvents(ctx, stored)
+	if err != nil {
+		return fmt.Errorf("failed to build events: %w", err)
+	}
+	return insertEvents(ctx, tx, events)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// GeofenceEventFilter restricts which geofence events are returned
+type GeofenceEventFilter struct {
+	UserID     uuid.UUID  // Required: events are always scoped to their owner
+	GeofenceID *uuid.UUID // Optional
+	DeviceID   string     // Optional
+	Limit      int
+}
+
+// GeofenceRepository defines the interface for geofence data access
+type GeofenceRepository interface {
+	// Create stores a new geofence
+	Create(ctx context.Context, geofence *models.Geofence) error
+
+	// GetByID retrieves a geofence by its UUID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Geofence, error)
+
+	// ListByUserID retrieves all geofences owned by a user, most recent first
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Geofence, error)
+
+	// ListActiveByUserID retrieves the user's active geofences for evaluation
+	ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Geofence, error)
+
+	// Delete removes a geofence together with its states and events
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// GetStates returns the last known inside/outside state of a device for each geofence that has one
+	GetStates(ctx context.Context, deviceID string, geofenceIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+
+	// RecordTransitions stores the new device states and the events they produced in one transaction
+	// States older than the stored ones are ignored so out-of-order uploads cannot rewind them.
+	RecordTransitions(ctx context.Context, states []models.GeofenceState, events []*models.GeofenceEvent) error
+
+	// ListEvents retrieves geofence events matching the filter, most recent first
+	ListEvents(ctx context.Context, filter GeofenceEventFilter) ([]*models.GeofenceEvent, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ImportJobRepository defines the interface for import job data access
+type ImportJobRepository interface {
+	// Create stores a new import job
+	Create(ctx context.Context, job *models.ImportJob) error
+
+	// GetByID retrieves an import job by its UUID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ImportJob, error)
+
+	// UpdateProgress records the job's status and the number of rows stored so far
+	// Jobs moving to a terminal status also get their completion time set.
+	UpdateProgress(ctx context.Context, id uuid.UUID, status models.ImportStatus, importedRows int, errMsg *string) error
+
+	// FailIncomplete marks every pending or processing job as failed, returning how many were affected
+	FailIncomplete(ctx context.Context, errMsg string) (int64, error)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// IngestAuditFilter narrows an ingest audit log query
+type IngestAuditFilter struct {
+	// UserID optionally restricts entries to one user's uploads
+	UserID *uuid.UUID
+
+	// DeviceID optionally restricts entries to one hardware device
+	DeviceID string
+
+	// SourceIP optionally restricts entries to one client address
+	SourceIP string
+
+	// Result optionally restricts entries to one outcome
+	Result models.IngestResult
+
+	// From and To optionally bound created_at (inclusive and exclusive)
+	From *time.Time
+	To   *time.Time
+
+	// Limit and Offset paginate the results
+	Limit  int
+	Offset int
+}
+
+// IngestAuditRepository defines the interface for the append-only ingest audit log
+type IngestAuditRepository interface {
+	// InsertBatch appends entries to the audit log, setting their IDs
+	InsertBatch(ctx context.Context, entries []*models.IngestAuditEntry) error
+
+	// List retrieves the entries matching the filter, newest first
+	List(ctx context.Context, filter IngestAuditFilter) ([]*models.IngestAuditEntry, error)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// LoginAttemptRepository defines the interface for failed login tracking and account lockouts
+type LoginAttemptRepository interface {
+	// RecordFailure stores a failed login attempt
+	RecordFailure(ctx context.Context, attempt *models.LoginAttempt) error
+
+	// CountFailuresForUser counts the user's failed attempts since the given time
+	CountFailuresForUser(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+
+	// CountFailuresForIP counts failed attempts from an IP address since the given time
+	CountFailuresForIP(ctx context.Context, ipAddress string, since time.Time) (int, error)
+
+	// Lock locks the user's account until the given time, extending any existing lock
+	Lock(ctx context.Context, userID uuid.UUID, until time.Time) error
+
+	// GetLockedUntil returns when the user's lock expires, or nil if the account is not locked
+	GetLockedUntil(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+
+	// Reset clears the user's failed attempts and lock
+	Reset(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+)
+
+// LoginEventFilter narrows a login audit trail query
+type LoginEventFilter struct {
+	// UserID optionally restricts events to one account
+	UserID *uuid.UUID
+
+	// Email optionally restricts events to attempts with one email address
+	Email string
+
+	// IPAddress optionally restricts events to one client address
+	IPAddress string
+
+	// Method optionally restricts events to one login method
+	Method models.LoginMethod
+
+	// Success optionally restricts events to successful or failed attempts
+	Success *bool
+
+	// From and To optionally bound created_at (inclusive and exclusive)
+	From *time.Time
+	To   *time.Time
+
+	// Limit caps the number of events returned
+	Limit int
+
+	// After resumes the listing after the given position (exclusive)
+	After *pagination.Cursor
+}
+
+// loginEventKeyset is the order of login event listings
+var loginEventKeyset = pagination.Keyset{TimeColumn: "created_at", IDColumn: "id"}
+
+// LoginEventRepository defines the interface for the login audit trail
+type LoginEventRepository interface {
+	// Record appends a login attempt to the audit trail, setting its ID
+	Record(ctx context.Context, event *models.LoginEvent) error
+
+	// List retrieves the events matching the filter, newest first
+	List(ctx context.Context, filter LoginEventFilter) ([]*models.LoginEvent, error)
+}
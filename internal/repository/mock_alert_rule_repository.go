@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockAlertRuleRepository is a mock implementation of AlertRuleRepository for testing
+type MockAlertRuleRepository struct {
+	CreateFunc       func(ctx context.Context, rule *models.AlertRule) error
+	GetByIDFunc      func(ctx context.Context, id uuid.UUID) (*models.AlertRule, error)
+	ListByUserIDFunc func(ctx context.Context, userID uuid.UUID) ([]*models.AlertRule, error)
+	ListActiveFunc   func(ctx context.Context, userID uuid.UUID, deviceID string) ([]*models.AlertRule, error)
+	UpdateFunc       func(ctx context.Context, rule *models.AlertRule) error
+	DeleteFunc       func(ctx context.Context, id uuid.UUID) error
+	RecordEventsFunc func(ctx context.Context, events []*models.AlertEvent) error
+	ListEventsFunc   func(ctx context.Context, filter AlertEventFilter) ([]*models.AlertEvent, error)
+}
+
+// NewMockAlertRuleRepository creates a new mock alert rule repository
+func NewMockAlertRuleRepository() *MockAlertRuleRepository {
+	return &MockAlertRuleRepository{
+		CreateFunc: func(_ context.Context, _ *models.AlertRule) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.AlertRule, error) {
+			return nil, ErrAlertRuleNotFound
+		},
+		ListByUserIDFunc: func(_ context.Context, _ uuid.UUID) ([]*models.AlertRule, error) {
+			return []*models.AlertRule{}, nil
+		},
+		ListActiveFunc: func(_ context.Context, _ uuid.UUID, _ string) ([]*models.AlertRule, error) {
+			return []*models.AlertRule{}, nil
+		},
+		UpdateFunc: func(_ context.Context, _ *models.AlertRule) error {
+			return nil
+		},
+		DeleteFunc: func(_ context.Context, _ uuid.UUID) error {
+			return nil
+		},
+		RecordEventsFunc: func(_ context.Context, _ []*models.AlertEvent) error {
+			return nil
+		},
+		ListEventsFunc: func(_ context.Context, _ AlertEventFilter) ([]*models.AlertEvent, error) {
+			return []*models.AlertEvent{}, nil
+		},
+	}
+}
+
+// Create implements AlertRuleRepository.Create
+func (m *MockAlertRuleRepository) Create(ctx context.Context, rule *models.AlertRule) error {
+	return m.CreateFunc(ctx, rule)
+}
+
+// GetByID implements AlertRuleRepository.GetByID
+func (m *MockAlertRuleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AlertRule, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// ListByUserID implements AlertRuleRepository.ListByUserID
+func (m *MockAlertRuleRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.AlertRule, error) {
+	return m.ListByUserIDFunc(ctx, userID)
+}
+
+// ListActive implements AlertRuleRepository.ListActive
+func (m *MockAlertRuleRepository) ListActive(ctx context.Context, userID uuid.UUID, deviceID string) ([]*models.AlertRule, error) {
+	return m.ListActiveFunc(ctx, userID, deviceID)
+}
+
+// Update implements AlertRuleRepository.Update
+func (m *MockAlertRuleRepository) Update(ctx context.Context, rule *models.AlertRule) error {
+	return m.UpdateFunc(ctx, rule)
+}
+
+// Delete implements AlertRuleRepository.Delete
+func (m *MockAlertRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.DeleteFunc(ctx, id)
+}
+
+// RecordEvents implements AlertRuleRepository.RecordEvents
+func (m *MockAlertRuleRepository) RecordEvents(ctx context.Context, events []*models.AlertEvent) error {
+	return m.RecordEventsFunc(ctx, events)
+}
+
+// ListEvents implements AlertRuleRepository.ListEvents
+func (m *MockAlertRuleRepository) ListEvents(ctx context.Context, filter AlertEventFilter) ([]*models.AlertEvent, error) {
+	return m.ListEventsFunc(ctx, filter)
+}
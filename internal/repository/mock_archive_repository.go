@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockArchiveRepository is a mock implementation of ArchiveRepository for testing
+type MockArchiveRepository struct {
+	ListPendingChunksFunc func(ctx context.Context, before time.Time, limit int) ([]models.ArchiveChunk, error)
+	IterateChunkFunc      func(ctx context.Context, chunk models.ArchiveChunk) (TelemetryIterator, error)
+	CompleteFunc          func(ctx context.Context, archive *models.TelemetryArchive) error
+	ListFunc              func(ctx context.Context, filter ArchiveFilter) ([]*models.TelemetryArchive, error)
+}
+
+// NewMockArchiveRepository creates a new mock archive repository
+func NewMockArchiveRepository() *MockArchiveRepository {
+	return &MockArchiveRepository{
+		ListPendingChunksFunc: func(_ context.Context, _ time.Time, _ int) ([]models.ArchiveChunk, error) {
+			return []models.ArchiveChunk{}, nil
+		},
+		IterateChunkFunc: func(_ context.Context, _ models.ArchiveChunk) (TelemetryIterator, error) {
+			return NewSliceTelemetryIterator(nil), nil
+		},
+		CompleteFunc: func(_ context.Context, _ *models.TelemetryArchive) error {
+			return nil
+		},
+		ListFunc: func(_ context.Context, _ ArchiveFilter) ([]*models.TelemetryArchive, error) {
+			return []*models.TelemetryArchive{}, nil
+		},
+	}
+}
+
+// ListPendingChunks implements ArchiveRepository.ListPendingChunks
+func (m *MockArchiveRepository) ListPendingChunks(ctx context.Context, before time.Time, limit int) ([]models.ArchiveChunk, error) {
+	return m.ListPendingChunksFunc(ctx, before, limit)
+}
+
+// IterateChunk implements ArchiveRepository.IterateChunk
+func (m *MockArchiveRepository) IterateChunk(ctx context.Context, chunk models.ArchiveChunk) (TelemetryIterator, error) {
+	return m.IterateChunkFunc(ctx, chunk)
+}
+
+// Complete implements ArchiveRepository.Complete
+func (m *MockArchiveRepository) Complete(ctx context.Context, archive *models.TelemetryArchive) error {
+	return m.CompleteFunc(ctx, archive)
+}
+
+// List implements ArchiveRepository.List
+func (m *MockArchiveRepository) List(ctx context.Context, filter ArchiveFilter) ([]*models.TelemetryArchive, error) {
+	return m.ListFunc(ctx, filter)
+}
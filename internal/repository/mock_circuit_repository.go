@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockCircuitRepository is a mock implementation of CircuitRepository for testing
+type MockCircuitRepository struct {
+	NearbyFunc func(ctx context.Context, point geo.Point, radiusMeters float64, limit int) ([]*models.Circuit, error)
+}
+
+// NewMockCircuitRepository creates a new mock circuit repository
+func NewMockCircuitRepository() *MockCircuitRepository {
+	return &MockCircuitRepository{
+		NearbyFunc: func(_ context.Context, _ geo.Point, _ float64, _ int) ([]*models.Circuit, error) {
+			return []*models.Circuit{}, nil
+		},
+	}
+}
+
+// Nearby implements CircuitRepository.Nearby
+func (m *MockCircuitRepository) Nearby(ctx context.Context, point geo.Point, radiusMeters float64, limit int) ([]*models.Circuit, error) {
+	return m.NearbyFunc(ctx, point, radiusMeters, limit)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// MockDerivedChannelRepository is a mock implementation of DerivedChannelRepository for testing
+type MockDerivedChannelRepository struct {
+	BackfillFunc         func(ctx context.Context, from, to time.Time, limit int) (int64, error)
+	OldestRecordedAtFunc func(ctx context.Context) (*time.Time, error)
+}
+
+// NewMockDerivedChannelRepository creates a new mock derived channel repository
+func NewMockDerivedChannelRepository() *MockDerivedChannelRepository {
+	return &MockDerivedChannelRepository{
+		BackfillFunc: func(_ context.Context, _, _ time.Time, _ int) (int64, error) {
+			return 0, nil
+		},
+		OldestRecordedAtFunc: func(_ context.Context) (*time.Time, error) {
+			return nil, nil
+		},
+	}
+}
+
+// Backfill implements DerivedChannelRepository.Backfill
+func (m *MockDerivedChannelRepository) Backfill(ctx context.Context, from, to time.Time, limit int) (int64, error) {
+	return m.BackfillFunc(ctx, from, to, limit)
+}
+
+// OldestRecordedAt implements DerivedChannelRepository.OldestRecordedAt
+func (m *MockDerivedChannelRepository) OldestRecordedAt(ctx context.Context) (*time.Time, error) {
+	return m.OldestRecordedAtFunc(ctx)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockDeviceAPIKeyRepository is a mock implementation of DeviceAPIKeyRepository for testing
+type MockDeviceAPIKeyRepository struct {
+	CreateFunc         func(ctx context.Context, key *models.DeviceAPIKey) error
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.DeviceAPIKey, error)
+	GetByHashFunc      func(ctx context.Context, hash string) (*models.DeviceAPIKey, error)
+	ListByDeviceIDFunc func(ctx context.Context, deviceID uuid.UUID) ([]*models.DeviceAPIKey, error)
+	RevokeFunc         func(ctx context.Context, id uuid.UUID) error
+	UpdateLastUsedFunc func(ctx context.Context, id uuid.UUID) error
+}
+
+// NewMockDeviceAPIKeyRepository creates a new mock device API key repository
+func NewMockDeviceAPIKeyRepository() *MockDeviceAPIKeyRepository {
+	return &MockDeviceAPIKeyRepository{
+		CreateFunc: func(_ context.Context, _ *models.DeviceAPIKey) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.DeviceAPIKey, error) {
+			return nil, ErrDeviceAPIKeyNotFound
+		},
+		GetByHashFunc: func(_ context.Context, _ string) (*models.DeviceAPIKey, error) {
+			return nil, ErrDeviceAPIKeyNotFound
+		},
+		ListByDeviceIDFunc: func(_ context.Context, _ uuid.UUID) ([]*models.DeviceAPIKey, error) {
+			return []*models.DeviceAPIKey{}, nil
+		},
+		RevokeFunc: func(_ context.Context, _ uuid.UUID) error {
+			return nil
+		},
+		UpdateLastUsedFunc: func(_ context.Context, _ uuid.UUID) error {
+			return nil
+		},
+	}
+}
+
+// Create implements DeviceAPIKeyRepository.Create
+func (m *MockDeviceAPIKeyRepository) Create(ctx context.Context, key *models.DeviceAPIKey) error {
+	return m.CreateFunc(ctx, key)
+}
+
+// GetByID implements DeviceAPIKeyRepository.GetByID
+func (m *MockDeviceAPIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DeviceAPIKey, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// GetByHash implements DeviceAPIKeyRepository.GetByHash
+func (m *MockDeviceAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.DeviceAPIKey, error) {
+	return m.GetByHashFunc(ctx, hash)
+}
+
+// ListByDeviceID implements DeviceAPIKeyRepository.ListByDeviceID
+func (m *MockDeviceAPIKeyRepository) ListByDeviceID(ctx context.Context, deviceID uuid.UUID) ([]*models.DeviceAPIKey, error) {
+	return m.ListByDeviceIDFunc(ctx, deviceID)
+}
+
+// Revoke implements DeviceAPIKeyRepository.Revoke
+func (m *MockDeviceAPIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return m.RevokeFunc(ctx, id)
+}
+
+// UpdateLastUsed implements DeviceAPIKeyRepository.UpdateLastUsed
+func (m *MockDeviceAPIKeyRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID) error {
+	return m.UpdateLastUsedFunc(ctx, id)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockDeviceBackfillRepository is a mock implementation of DeviceBackfillRepository for testing
+type MockDeviceBackfillRepository struct {
+	CreateFunc         func(ctx context.Context, backfill *models.DeviceBackfill) error
+	GetLatestFunc      func(ctx context.Context, deviceID uuid.UUID) (*models.DeviceBackfill, error)
+	ListIncompleteFunc func(ctx context.Context, limit int) ([]uuid.UUID, error)
+	StartFunc          func(ctx context.Context, id uuid.UUID) (*models.DeviceBackfill, error)
+	AssignBatchFunc    func(ctx context.Context, id uuid.UUID, limit int) (int64, error)
+	CompleteFunc       func(ctx context.Context, id uuid.UUID) error
+	FailFunc           func(ctx context.Context, id uuid.UUID, errMsg string) error
+}
+
+// NewMockDeviceBackfillRepository creates a new mock device backfill repository
+func NewMockDeviceBackfillRepository() *MockDeviceBackfillRepository {
+	return &MockDeviceBackfillRepository{
+		CreateFunc: func(_ context.Context, _ *models.DeviceBackfill) error {
+			return nil
+		},
+		GetLatestFunc: func(_ context.Context, _ uuid.UUID) (*models.DeviceBackfill, error) {
+			return nil, ErrDeviceBackfillNotFound
+		},
+		ListIncompleteFunc: func(_ context.Context, _ int) ([]uuid.UUID, error) {
+			return []uuid.UUID{}, nil
+		},
+		StartFunc: func(_ context.Context, _ uuid.UUID) (*models.DeviceBackfill, error) {
+			return nil, ErrDeviceBackfillNotFound
+		},
+		AssignBatchFunc: func(_ context.Context, _ uuid.UUID, _ int) (int64, error) {
+			return 0, nil
+		},
+		CompleteFunc: func(_ context.Context, _ uuid.UUID) error {
+			return nil
+		},
+		FailFunc: func(_ context.Context, _ uuid.UUID, _ string) error {
+			return nil
+		},
+	}
+}
+
+// Create implements DeviceBackfillRepository.Create
+func (m *MockDeviceBackfillRepository) Create(ctx context.Context, backfill *models.DeviceBackfill) error {
+	return m.CreateFunc(ctx, backfill)
+}
+
+// GetLatest implements DeviceBackfillRepository.GetLatest
+func (m *MockDeviceBackfillRepository) GetLatest(ctx context.Context, deviceID uuid.UUID) (*models.DeviceBackfill, error) {
+	return m.GetLatestFunc(ctx, deviceID)
+}
+
+// ListIncomplete implements DeviceBackfillRepository.ListIncomplete
+func (m *MockDeviceBackfillRepository) ListIncomplete(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return m.ListIncompleteFunc(ctx, limit)
+}
+
+// Start implements DeviceBackfillRepository.Start
+func (m *MockDeviceBackfillRepository) Start(ctx context.Context, id uuid.UUID) (*models.DeviceBackfill, error) {
+	return m.StartFunc(ctx, id)
+}
+
+// AssignBatch implements DeviceBackfillRepository.AssignBatch
+func (m *MockDeviceBackfillRepository) AssignBatch(ctx context.Context, id uuid.UUID, limit int) (int64, error) {
+	return m.AssignBatchFunc(ctx, id, limit)
+}
+
+// Complete implements DeviceBackfillRepository.Complete
+func (m *MockDeviceBackfillRepository) Complete(ctx context.Context, id uuid.UUID) error {
+	return m.CompleteFunc(ctx, id)
+}
+
+// Fail implements DeviceBackfillRepository.Fail
+func (m *MockDeviceBackfillRepository) Fail(ctx context.Context, id uuid.UUID, errMsg string) error {
+	return m.FailFunc(ctx, id, errMsg)
+}
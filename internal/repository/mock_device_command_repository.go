@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockDeviceCommandRepository is a mock implementation of DeviceCommandRepository for testing
+type MockDeviceCommandRepository struct {
+	CreateFunc      func(ctx context.Context, command *models.DeviceCommand) error
+	GetFunc         func(ctx context.Context, deviceID, id uuid.UUID) (*models.DeviceCommand, error)
+	ListFunc        func(ctx context.Context, deviceID uuid.UUID, filter DeviceCommandFilter) ([]*models.DeviceCommand, error)
+	PollFunc        func(ctx context.Context, deviceID uuid.UUID, limit int) ([]*models.DeviceCommand, error)
+	AcknowledgeFunc func(ctx context.Context, deviceID, id uuid.UUID, status models.DeviceCommandStatus, message string) (*models.DeviceCommand, error)
+	CancelFunc      func(ctx context.Context, deviceID, id uuid.UUID) (*models.DeviceCommand, error)
+}
+
+// NewMockDeviceCommandRepository creates a new mock device command repository
+func NewMockDeviceCommandRepository() *MockDeviceCommandRepository {
+	return &MockDeviceCommandRepository{
+		CreateFunc: func(_ context.Context, command *models.DeviceCommand) error {
+			if command.ID == uuid.Nil {
+				command.ID = uuid.New()
+			}
+			return nil
+		},
+		GetFunc: func(_ context.Context, _, _ uuid.UUID) (*models.DeviceCommand, error) {
+			return nil, ErrDeviceCommandNotFound
+		},
+		ListFunc: func(_ context.Context, _ uuid.UUID, _ DeviceCommandFilter) ([]*models.DeviceCommand, error) {
+			return []*models.DeviceCommand{}, nil
+		},
+		PollFunc: func(_ context.Context, _ uuid.UUID, _ int) ([]*models.DeviceCommand, error) {
+			return []*models.DeviceCommand{}, nil
+		},
+		AcknowledgeFunc: func(_ context.Context, _, _ uuid.UUID, _ models.DeviceCommandStatus, _ string) (*models.DeviceCommand, error) {
+			return nil, ErrDeviceCommandNotFound
+		},
+		CancelFunc: func(_ context.Context, _, _ uuid.UUID) (*models.DeviceCommand, error) {
+			return nil, ErrDeviceCommandNotFound
+		},
+	}
+}
+
+// Create implements DeviceCommandRepository.Create
+func (m *MockDeviceCommandRepository) Create(ctx context.Context, command *models.DeviceCommand) error {
+	return m.CreateFunc(ctx, command)
+}
+
+// Get implements DeviceCommandRepository.Get
+func (m *MockDeviceCommandRepository) Get(ctx context.Context, deviceID, id uuid.UUID) (*models.DeviceCommand, error) {
+	return m.GetFunc(ctx, deviceID, id)
+}
+
+// List implements DeviceCommandRepository.List
+func (m *MockDeviceCommandRepository) List(ctx context.Context, deviceID uuid.UUID, filter DeviceCommandFilter) ([]*models.DeviceCommand, error) {
+	return m.ListFunc(ctx, deviceID, filter)
+}
+
+// Poll implements DeviceCommandRepository.Poll
+func (m *MockDeviceCommandRepository) Poll(ctx context.Context, deviceID uuid.UUID, limit int) ([]*models.DeviceCommand, error) {
+	return m.PollFunc(ctx, deviceID, limit)
+}
+
+// Acknowledge implements DeviceCommandRepository.Acknowledge
+func (m *MockDeviceCommandRepository) Acknowledge(ctx context.Context, deviceID, id uuid.UUID, status models.DeviceCommandStatus, message string) (*models.DeviceCommand, error) {
+	return m.AcknowledgeFunc(ctx, deviceID, id, status, message)
+}
+
+// Cancel implements DeviceCommandRepository.Cancel
+func (m *MockDeviceCommandRepository) Cancel(ctx context.Context, deviceID, id uuid.UUID) (*models.DeviceCommand, error) {
+	return m.CancelFunc(ctx, deviceID, id)
+}
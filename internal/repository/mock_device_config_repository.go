@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockDeviceConfigRepository is a mock implementation of DeviceConfigRepository for testing
+type MockDeviceConfigRepository struct {
+	GetFunc func(ctx context.Context, deviceID uuid.UUID) (*models.DeviceConfig, error)
+	PutFunc func(ctx context.Context, config *models.DeviceConfig) error
+}
+
+// NewMockDeviceConfigRepository creates a new mock device config repository
+func NewMockDeviceConfigRepository() *MockDeviceConfigRepository {
+	return &MockDeviceConfigRepository{
+		GetFunc: func(_ context.Context, _ uuid.UUID) (*models.DeviceConfig, error) {
+			return nil, ErrDeviceConfigNotFound
+		},
+		PutFunc: func(_ context.Context, config *models.DeviceConfig) error {
+			config.Version++
+			return nil
+		},
+	}
+}
+
+// Get implements DeviceConfigRepository.Get
+func (m *MockDeviceConfigRepository) Get(ctx context.Context, deviceID uuid.UUID) (*models.DeviceConfig, error) {
+	return m.GetFunc(ctx, deviceID)
+}
+
+// Put implements DeviceConfigRepository.Put
+func (m *MockDeviceConfigRepository) Put(ctx context.Context, config *models.DeviceConfig) error {
+	return m.PutFunc(ctx, config)
+}
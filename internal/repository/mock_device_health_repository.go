@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockDeviceHealthRepository is a mock implementation of DeviceHealthRepository for testing
+type MockDeviceHealthRepository struct {
+	GetStateFunc      func(ctx context.Context, deviceID string) (*models.DeviceHealthState, error)
+	RecordFunc        func(ctx context.Context, state *models.DeviceHealthState, snapshots []*models.DeviceHealthSnapshot, events []*models.DeviceHealthEvent) error
+	ListSnapshotsFunc func(ctx context.Context, deviceID string, since time.Time) ([]*models.DeviceHealthSnapshot, error)
+	ListEventsFunc    func(ctx context.Context, deviceID string, limit int) ([]*models.DeviceHealthEvent, error)
+}
+
+// NewMockDeviceHealthRepository creates a new mock device health repository
+func NewMockDeviceHealthRepository() *MockDeviceHealthRepository {
+	return &MockDeviceHealthRepository{
+		GetStateFunc: func(_ context.Context, _ string) (*models.DeviceHealthState, error) {
+			return nil, ErrDeviceHealthNotFound
+		},
+		RecordFunc: func(_ context.Context, _ *models.DeviceHealthState, _ []*models.DeviceHealthSnapshot, _ []*models.DeviceHealthEvent) error {
+			return nil
+		},
+		ListSnapshotsFunc: func(_ context.Context, _ string, _ time.Time) ([]*models.DeviceHealthSnapshot, error) {
+			return []*models.DeviceHealthSnapshot{}, nil
+		},
+		ListEventsFunc: func(_ context.Context, _ string, _ int) ([]*models.DeviceHealthEvent, error) {
+			return []*models.DeviceHealthEvent{}, nil
+		},
+	}
+}
+
+// GetState implements DeviceHealthRepository.GetState
+func (m *MockDeviceHealthRepository) GetState(ctx context.Context, deviceID string) (*models.DeviceHealthState, error) {
+	return m.GetStateFunc(ctx, deviceID)
+}
+
+// Record implements DeviceHealthRepository.Record
+func (m *MockDeviceHealthRepository) Record(ctx context.Context, state *models.DeviceHealthState, snapshots []*models.DeviceHealthSnapshot, events []*models.DeviceHealthEvent) error {
+	return m.RecordFunc(ctx, state, snapshots, events)
+}
+
+// ListSnapshots implements DeviceHealthRepository.ListSnapshots
+func (m *MockDeviceHealthRepository) ListSnapshots(ctx context.Context, deviceID string, since time.Time) ([]*models.DeviceHealthSnapshot, error) {
+	return m.ListSnapshotsFunc(ctx, deviceID, since)
+}
+
+// ListEvents implements DeviceHealthRepository.ListEvents
+func (m *MockDeviceHealthRepository) ListEvents(ctx context.Context, deviceID string, limit int) ([]*models.DeviceHealthEvent, error) {
+	return m.ListEventsFunc(ctx, deviceID, limit)
+}
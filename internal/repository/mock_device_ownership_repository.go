@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockDeviceOwnershipRepository is a mock implementation of DeviceOwnershipRepository for testing
+type MockDeviceOwnershipRepository struct {
+	RecordFunc       func(ctx context.Context, change *models.DeviceOwnershipChange) error
+	ListByDeviceFunc func(ctx context.Context, deviceID uuid.UUID) ([]*models.DeviceOwnershipChange, error)
+}
+
+// NewMockDeviceOwnershipRepository creates a new mock device ownership repository
+func NewMockDeviceOwnershipRepository() *MockDeviceOwnershipRepository {
+	return &MockDeviceOwnershipRepository{
+		RecordFunc: func(_ context.Context, change *models.DeviceOwnershipChange) error {
+			change.ID = uuid.New()
+			return nil
+		},
+		ListByDeviceFunc: func(_ context.Context, _ uuid.UUID) ([]*models.DeviceOwnershipChange, error) {
+			return []*models.DeviceOwnershipChange{}, nil
+		},
+	}
+}
+
+// Record implements DeviceOwnershipRepository.Record
+func (m *MockDeviceOwnershipRepository) Record(ctx context.Context, change *models.DeviceOwnershipChange) error {
+	return m.RecordFunc(ctx, change)
+}
+
+// ListByDevice implements DeviceOwnershipRepository.ListByDevice
+func (m *MockDeviceOwnershipRepository) ListByDevice(ctx context.Context, deviceID uuid.UUID) ([]*models.DeviceOwnershipChange, error) {
+	return m.ListByDeviceFunc(ctx, deviceID)
+}
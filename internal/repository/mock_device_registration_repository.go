@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockDeviceRegistrationRepository is a mock implementation of DeviceRegistrationRepository for testing
+type MockDeviceRegistrationRepository struct {
+	CreateFunc               func(ctx context.Context, registration *models.DeviceRegistration) error
+	GetByDeviceIDFunc        func(ctx context.Context, deviceID string) (*models.DeviceRegistration, error)
+	AdoptFunc                func(ctx context.Context, device *models.Device) error
+	ListPendingBackfillsFunc func(ctx context.Context, limit int) ([]string, error)
+	BackfillFunc             func(ctx context.Context, deviceID string) (int64, error)
+}
+
+// NewMockDeviceRegistrationRepository creates a new mock device registration repository
+func NewMockDeviceRegistrationRepository() *MockDeviceRegistrationRepository {
+	return &MockDeviceRegistrationRepository{
+		CreateFunc: func(_ context.Context, _ *models.DeviceRegistration) error {
+			return nil
+		},
+		GetByDeviceIDFunc: func(_ context.Context, _ string) (*models.DeviceRegistration, error) {
+			return nil, ErrDeviceRegistrationNotFound
+		},
+		AdoptFunc: func(_ context.Context, _ *models.Device) error {
+			return nil
+		},
+		ListPendingBackfillsFunc: func(_ context.Context, _ int) ([]string, error) {
+			return []string{}, nil
+		},
+		BackfillFunc: func(_ context.Context, _ string) (int64, error) {
+			return 0, nil
+		},
+	}
+}
+
+// Create implements DeviceRegistrationRepository.Create
+func (m *MockDeviceRegistrationRepository) Create(ctx context.Context, registration *models.DeviceRegistration) error {
+	return m.CreateFunc(ctx, registration)
+}
+
+// GetByDeviceID implements DeviceRegistrationRepository.GetByDeviceID
+func (m *MockDeviceRegistrationRepository) GetByDeviceID(ctx context.Context, deviceID string) (*models.DeviceRegistration, error) {
+	return m.GetByDeviceIDFunc(ctx, deviceID)
+}
+
+// Adopt implements DeviceRegistrationRepository.Adopt
+func (m *MockDeviceRegistrationRepository) Adopt(ctx context.Context, device *models.Device) error {
+	return m.AdoptFunc(ctx, device)
+}
+
+// ListPendingBackfills implements DeviceRegistrationRepository.ListPendingBackfills
+func (m *MockDeviceRegistrationRepository) ListPendingBackfills(ctx context.Context, limit int) ([]string, error) {
+	return m.ListPendingBackfillsFunc(ctx, limit)
+}
+
+// Backfill implements DeviceRegistrationRepository.Backfill
+func (m *MockDeviceRegistrationRepository) Backfill(ctx context.Context, deviceID string) (int64, error) {
+	return m.BackfillFunc(ctx, deviceID)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sebasr/avt-service/internal/models"
@@ -9,12 +10,25 @@ import (
 
 // MockDeviceRepository is a mock implementation of DeviceRepository for testing
 type MockDeviceRepository struct {
-	CreateFunc         func(ctx context.Context, device *models.Device) error
-	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.Device, error)
-	GetByDeviceIDFunc  func(ctx context.Context, deviceID string) (*models.Device, error)
-	ListByUserIDFunc   func(ctx context.Context, userID uuid.UUID) ([]*models.Device, error)
-	UpdateFunc         func(ctx context.Context, device *models.Device) error
-	UpdateLastSeenFunc func(ctx context.Context, deviceID string) error
+	CreateFunc               func(ctx context.Context, device *models.Device) error
+	ClaimFunc                func(ctx context.Context, device *models.Device) (*models.Device, error)
+	ClaimAllFunc             func(ctx context.Context, devices []*models.Device) ([]*models.Device, error)
+	GetByIDFunc              func(ctx context.Context, id uuid.UUID) (*models.Device, error)
+	GetByDeviceIDFunc        func(ctx context.Context, deviceID string) (*models.Device, error)
+	ListByUserIDFunc         func(ctx context.Context, userID uuid.UUID) ([]*models.Device, error)
+	ListFunc                 func(ctx context.Context, filter DeviceFilter) ([]*models.Device, int, error)
+	UpdateFunc               func(ctx context.Context, device *models.Device) error
+	UpdateLastSeenFunc       func(ctx context.Context, deviceID string) error
+	UpdateFirmwareFunc       func(ctx context.Context, id uuid.UUID, version string) error
+	FirmwareDistributionFunc func(ctx context.Context) ([]*models.FirmwareCount, error)
+	ListSeenSinceFunc        func(ctx context.Context, since time.Time) ([]*models.Device, error)
+	SetOrganizationFunc      func(ctx context.Context, id uuid.UUID, orgID *uuid.UUID) error
+	ReassignFunc             func(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	SetTagsFunc              func(ctx context.Context, id uuid.UUID, tags []string) error
+	AddTagFunc               func(ctx context.Context, id uuid.UUID, tag string) error
+	RemoveTagFunc            func(ctx context.Context, id uuid.UUID, tag string) error
+	TagsByDeviceFunc         func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error)
+	TagCountsFunc            func(ctx context.Context, filter DeviceFilter) ([]*models.DeviceTagCount, error)
 }
 
 // NewMockDeviceRepository creates a new mock device repository
@@ -23,6 +37,12 @@ func NewMockDeviceRepository() *MockDeviceRepository {
 		CreateFunc: func(_ context.Context, _ *models.Device) error {
 			return nil
 		},
+		ClaimFunc: func(_ context.Context, device *models.Device) (*models.Device, error) {
+			return device, nil
+		},
+		ClaimAllFunc: func(_ context.Context, devices []*models.Device) ([]*models.Device, error) {
+			return devices, nil
+		},
 		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.Device, error) {
 			return nil, ErrDeviceNotFound
 		},
@@ -32,12 +52,45 @@ func NewMockDeviceRepository() *MockDeviceRepository {
 		ListByUserIDFunc: func(_ context.Context, _ uuid.UUID) ([]*models.Device, error) {
 			return []*models.Device{}, nil
 		},
+		ListFunc: func(_ context.Context, _ DeviceFilter) ([]*models.Device, int, error) {
+			return []*models.Device{}, 0, nil
+		},
 		UpdateFunc: func(_ context.Context, _ *models.Device) error {
 			return nil
 		},
 		UpdateLastSeenFunc: func(_ context.Context, _ string) error {
 			return nil
 		},
+		UpdateFirmwareFunc: func(_ context.Context, _ uuid.UUID, _ string) error {
+			return nil
+		},
+		FirmwareDistributionFunc: func(_ context.Context) ([]*models.FirmwareCount, error) {
+			return []*models.FirmwareCount{}, nil
+		},
+		ListSeenSinceFunc: func(_ context.Context, _ time.Time) ([]*models.Device, error) {
+			return []*models.Device{}, nil
+		},
+		SetOrganizationFunc: func(_ context.Context, _ uuid.UUID, _ *uuid.UUID) error {
+			return nil
+		},
+		ReassignFunc: func(_ context.Context, _ uuid.UUID, _ uuid.UUID) error {
+			return nil
+		},
+		SetTagsFunc: func(_ context.Context, _ uuid.UUID, _ []string) error {
+			return nil
+		},
+		AddTagFunc: func(_ context.Context, _ uuid.UUID, _ string) error {
+			return nil
+		},
+		RemoveTagFunc: func(_ context.Context, _ uuid.UUID, _ string) error {
+			return ErrDeviceTagNotFound
+		},
+		TagsByDeviceFunc: func(_ context.Context, _ []uuid.UUID) (map[uuid.UUID][]string, error) {
+			return map[uuid.UUID][]string{}, nil
+		},
+		TagCountsFunc: func(_ context.Context, _ DeviceFilter) ([]*models.DeviceTagCount, error) {
+			return []*models.DeviceTagCount{}, nil
+		},
 	}
 }
 
@@ -46,6 +99,16 @@ func (m *MockDeviceRepository) Create(ctx context.Context, device *models.Device
 	return m.CreateFunc(ctx, device)
 }
 
+// Claim implements DeviceRepository.Claim
+func (m *MockDeviceRepository) Claim(ctx context.Context, device *models.Device) (*models.Device, error) {
+	return m.ClaimFunc(ctx, device)
+}
+
+// ClaimAll implements DeviceRepository.ClaimAll
+func (m *MockDeviceRepository) ClaimAll(ctx context.Context, devices []*models.Device) ([]*models.Device, error) {
+	return m.ClaimAllFunc(ctx, devices)
+}
+
 // GetByID implements DeviceRepository.GetByID
 func (m *MockDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Device, error) {
 	return m.GetByIDFunc(ctx, id)
@@ -61,6 +124,11 @@ func (m *MockDeviceRepository) ListByUserID(ctx context.Context, userID uuid.UUI
 	return m.ListByUserIDFunc(ctx, userID)
 }
 
+// List implements DeviceRepository.List
+func (m *MockDeviceRepository) List(ctx context.Context, filter DeviceFilter) ([]*models.Device, int, error) {
+	return m.ListFunc(ctx, filter)
+}
+
 // Update implements DeviceRepository.Update
 func (m *MockDeviceRepository) Update(ctx context.Context, device *models.Device) error {
 	return m.UpdateFunc(ctx, device)
@@ -70,3 +138,53 @@ func (m *MockDeviceRepository) Update(ctx context.Context, device *models.Device
 func (m *MockDeviceRepository) UpdateLastSeen(ctx context.Context, deviceID string) error {
 	return m.UpdateLastSeenFunc(ctx, deviceID)
 }
+
+// UpdateFirmware implements DeviceRepository.UpdateFirmware
+func (m *MockDeviceRepository) UpdateFirmware(ctx context.Context, id uuid.UUID, version string) error {
+	return m.UpdateFirmwareFunc(ctx, id, version)
+}
+
+// FirmwareDistribution implements DeviceRepository.FirmwareDistribution
+func (m *MockDeviceRepository) FirmwareDistribution(ctx context.Context) ([]*models.FirmwareCount, error) {
+	return m.FirmwareDistributionFunc(ctx)
+}
+
+// ListSeenSince implements DeviceRepository.ListSeenSince
+func (m *MockDeviceRepository) ListSeenSince(ctx context.Context, since time.Time) ([]*models.Device, error) {
+	return m.ListSeenSinceFunc(ctx, since)
+}
+
+// SetOrganization implements DeviceRepository.SetOrganization
+func (m *MockDeviceRepository) SetOrganization(ctx context.Context, id uuid.UUID, orgID *uuid.UUID) error {
+	return m.SetOrganizationFunc(ctx, id, orgID)
+}
+
+// Reassign implements DeviceRepository.Reassign
+func (m *MockDeviceRepository) Reassign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	return m.ReassignFunc(ctx, id, userID)
+}
+
+// SetTags implements DeviceRepository.SetTags
+func (m *MockDeviceRepository) SetTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	return m.SetTagsFunc(ctx, id, tags)
+}
+
+// AddTag implements DeviceRepository.AddTag
+func (m *MockDeviceRepository) AddTag(ctx context.Context, id uuid.UUID, tag string) error {
+	return m.AddTagFunc(ctx, id, tag)
+}
+
+// RemoveTag implements DeviceRepository.RemoveTag
+func (m *MockDeviceRepository) RemoveTag(ctx context.Context, id uuid.UUID, tag string) error {
+	return m.RemoveTagFunc(ctx, id, tag)
+}
+
+// TagsByDevice implements DeviceRepository.TagsByDevice
+func (m *MockDeviceRepository) TagsByDevice(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	return m.TagsByDeviceFunc(ctx, ids)
+}
+
+// TagCounts implements DeviceRepository.TagCounts
+func (m *MockDeviceRepository) TagCounts(ctx context.Context, filter DeviceFilter) ([]*models.DeviceTagCount, error) {
+	return m.TagCountsFunc(ctx, filter)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockEventOutboxRepository is a mock implementation of EventOutboxRepository for testing
+type MockEventOutboxRepository struct {
+	AppendFunc          func(ctx context.Context, tx *sql.Tx, events ...*models.Event) error
+	ClaimDueFunc        func(ctx context.Context, limit int, lease time.Duration) ([]*models.Event, error)
+	MarkDeliveredFunc   func(ctx context.Context, id int64) error
+	MarkFailedFunc      func(ctx context.Context, id int64, errMsg string, retryAt *time.Time) error
+	DeleteDeliveredFunc func(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NewMockEventOutboxRepository creates a new mock event outbox repository
+func NewMockEventOutboxRepository() *MockEventOutboxRepository {
+	return &MockEventOutboxRepository{
+		AppendFunc: func(_ context.Context, _ *sql.Tx, _ ...*models.Event) error {
+			return nil
+		},
+		ClaimDueFunc: func(_ context.Context, _ int, _ time.Duration) ([]*models.Event, error) {
+			return []*models.Event{}, nil
+		},
+		MarkDeliveredFunc: func(_ context.Context, _ int64) error {
+			return nil
+		},
+		MarkFailedFunc: func(_ context.Context, _ int64, _ string, _ *time.Time) error {
+			return nil
+		},
+		DeleteDeliveredFunc: func(_ context.Context, _ time.Time) (int64, error) {
+			return 0, nil
+		},
+	}
+}
+
+// Append implements EventOutboxRepository.Append
+func (m *MockEventOutboxRepository) Append(ctx context.Context, tx *sql.Tx, events ...*models.Event) error {
+	return m.AppendFunc(ctx, tx, events...)
+}
+
+// ClaimDue implements EventOutboxRepository.ClaimDue
+func (m *MockEventOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.Event, error) {
+	return m.ClaimDueFunc(ctx, limit, lease)
+}
+
+// MarkDelivered implements EventOutboxRepository.MarkDelivered
+func (m *MockEventOutboxRepository) MarkDelivered(ctx context.Context, id int64) error {
+	return m.MarkDeliveredFunc(ctx, id)
+}
+
+// MarkFailed implements EventOutboxRepository.MarkFailed
+func (m *MockEventOutboxRepository) MarkFailed(ctx context.Context, id int64, errMsg string, retryAt *time.Time) error {
+	return m.MarkFailedFunc(ctx, id, errMsg, retryAt)
+}
+
+// DeleteDelivered implements EventOutboxRepository.DeleteDelivered
+func (m *MockEventOutboxRepository) DeleteDelivered(ctx context.Context, before time.Time) (int64, error) {
+	return m.DeleteDeliveredFunc(ctx, before)
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockGeofenceRepository is a mock implementation of GeofenceRepository for testing
+type MockGeofenceRepository struct {
+	CreateFunc             func(ctx context.Context, geofence *models.Geofence) error
+	GetByIDFunc            func(ctx context.Context, id uuid.UUID) (*models.Geofence, error)
+	ListByUserIDFunc       func(ctx context.Context, userID uuid.UUID) ([]*models.Geofence, error)
+	ListActiveByUserIDFunc func(ctx context.Context, userID uuid.UUID) ([]*models.Geofence, error)
+	DeleteFunc             func(ctx context.Context, id uuid.UUID) error
+	GetStatesFunc          func(ctx context.Context, deviceID string, geofenceIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	RecordTransitionsFunc  func(ctx context.Context, states []models.GeofenceState, events []*models.GeofenceEvent) error
+	ListEventsFunc         func(ctx context.Context, filter GeofenceEventFilter) ([]*models.GeofenceEvent, error)
+}
+
+// NewMockGeofenceRepository creates a new mock geofence repository
+func NewMockGeofenceRepository() *MockGeofenceRepository {
+	return &MockGeofenceRepository{
+		CreateFunc: func(_ context.Context, _ *models.Geofence) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.Geofence, error) {
+			return nil, ErrGeofenceNotFound
+		},
+		ListByUserIDFunc: func(_ context.Context, _ uuid.UUID) ([]*models.Geofence, error) {
+			return []*models.Geofence{}, nil
+		},
+		ListActiveByUserIDFunc: func(_ context.Context, _ uuid.UUID) ([]*models.Geofence, error) {
+			return []*models.Geofence{}, nil
+		},
+		DeleteFunc: func(_ context.Context, _ uuid.UUID) error {
+			return nil
+		},
+		GetStatesFunc: func(_ context.Context, _ string, _ []uuid.UUID) (map[uuid.UUID]bool, error) {
+			return map[uuid.UUID]bool{}, nil
+		},
+		RecordTransitionsFunc: func(_ context.Context, _ []models.GeofenceState, _ []*models.GeofenceEvent) error {
+			return nil
+		},
+		ListEventsFunc: func(_ context.Context, _ GeofenceEventFilter) ([]*models.GeofenceEvent, error) {
+			return []*models.GeofenceEvent{}, nil
+		},
+	}
+}
+
+// Create implements GeofenceRepository.Create
+func (m *MockGeofenceRepository) Create(ctx context.Context, geofence *models.Geofence) error {
+	return m.CreateFunc(ctx, geofence)
+}
+
+// GetByID implements GeofenceRepository.GetByID
+func (m *MockGeofenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Geofence, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// ListByUserID implements GeofenceRepository.ListByUserID
+func (m *MockGeofenceRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Geofence, error) {
+	return m.ListByUserIDFunc(ctx, userID)
+}
+
+// ListActiveByUserID implements GeofenceRepository.ListActiveByUserID
+func (m *MockGeofenceRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Geofence, error) {
+	return m.ListActiveByUserIDFunc(ctx, userID)
+}
+
+// Delete implements GeofenceRepository.Delete
+func (m *MockGeofenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.DeleteFunc(ctx, id)
+}
+
+// GetStates implements GeofenceRepository.GetStates
+func (m *MockGeofenceRepository) GetStates(ctx context.Context, deviceID string, geofenceIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return m.GetStatesFunc(ctx, deviceID, geofenceIDs)
+}
+
+// RecordTransitions implements GeofenceRepository.RecordTransitions
+func (m *MockGeofenceRepository) RecordTransitions(ctx context.Context, states []models.GeofenceState, events []*models.GeofenceEvent) error {
+	return m.RecordTransitionsFunc(ctx, states, events)
+}
+
+// ListEvents implements GeofenceRepository.ListEvents
+func (m *MockGeofenceRepository) ListEvents(ctx context.Context, filter GeofenceEventFilter) ([]*models.GeofenceEvent, error) {
+	return m.ListEventsFunc(ctx, filter)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockImportJobRepository is a mock implementation of ImportJobRepository for testing
+type MockImportJobRepository struct {
+	CreateFunc         func(ctx context.Context, job *models.ImportJob) error
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.ImportJob, error)
+	UpdateProgressFunc func(ctx context.Context, id uuid.UUID, status models.ImportStatus, importedRows int, errMsg *string) error
+	FailIncompleteFunc func(ctx context.Context, errMsg string) (int64, error)
+}
+
+// NewMockImportJobRepository creates a new mock import job repository
+func NewMockImportJobRepository() *MockImportJobRepository {
+	return &MockImportJobRepository{
+		CreateFunc: func(_ context.Context, _ *models.ImportJob) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.ImportJob, error) {
+			return nil, ErrImportJobNotFound
+		},
+		UpdateProgressFunc: func(_ context.Context, _ uuid.UUID, _ models.ImportStatus, _ int, _ *string) error {
+			return nil
+		},
+		FailIncompleteFunc: func(_ context.Context, _ string) (int64, error) {
+			return 0, nil
+		},
+	}
+}
+
+// Create implements ImportJobRepository.Create
+func (m *MockImportJobRepository) Create(ctx context.Context, job *models.ImportJob) error {
+	return m.CreateFunc(ctx, job)
+}
+
+// GetByID implements ImportJobRepository.GetByID
+func (m *MockImportJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ImportJob, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// UpdateProgress implements ImportJobRepository.UpdateProgress
+func (m *MockImportJobRepository) UpdateProgress(ctx context.Context, id uuid.UUID, status models.ImportStatus, importedRows int, errMsg *string) error {
+	return m.UpdateProgressFunc(ctx, id, status, importedRows, errMsg)
+}
+
+// FailIncomplete implements ImportJobRepository.FailIncomplete
+func (m *MockImportJobRepository) FailIncomplete(ctx context.Context, errMsg string) (int64, error) {
+	return m.FailIncompleteFunc(ctx, errMsg)
+}
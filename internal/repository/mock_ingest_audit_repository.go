@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockIngestAuditRepository is a mock implementation of IngestAuditRepository for testing
+type MockIngestAuditRepository struct {
+	InsertBatchFunc func(ctx context.Context, entries []*models.IngestAuditEntry) error
+	ListFunc        func(ctx context.Context, filter IngestAuditFilter) ([]*models.IngestAuditEntry, error)
+}
+
+// NewMockIngestAuditRepository creates a new mock ingest audit repository
+func NewMockIngestAuditRepository() *MockIngestAuditRepository {
+	return &MockIngestAuditRepository{
+		InsertBatchFunc: func(_ context.Context, _ []*models.IngestAuditEntry) error {
+			return nil
+		},
+		ListFunc: func(_ context.Context, _ IngestAuditFilter) ([]*models.IngestAuditEntry, error) {
+			return []*models.IngestAuditEntry{}, nil
+		},
+	}
+}
+
+// InsertBatch implements IngestAuditRepository.InsertBatch
+func (m *MockIngestAuditRepository) InsertBatch(ctx context.Context, entries []*models.IngestAuditEntry) error {
+	return m.InsertBatchFunc(ctx, entries)
+}
+
+// List implements IngestAuditRepository.List
+func (m *MockIngestAuditRepository) List(ctx context.Context, filter IngestAuditFilter) ([]*models.IngestAuditEntry, error) {
+	return m.ListFunc(ctx, filter)
+}
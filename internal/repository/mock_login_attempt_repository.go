@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockLoginAttemptRepository is a mock implementation of LoginAttemptRepository for testing
+type MockLoginAttemptRepository struct {
+	RecordFailureFunc        func(ctx context.Context, attempt *models.LoginAttempt) error
+	CountFailuresForUserFunc func(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	CountFailuresForIPFunc   func(ctx context.Context, ipAddress string, since time.Time) (int, error)
+	LockFunc                 func(ctx context.Context, userID uuid.UUID, until time.Time) error
+	GetLockedUntilFunc       func(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+	ResetFunc                func(ctx context.Context, userID uuid.UUID) error
+}
+
+// NewMockLoginAttemptRepository creates a new mock login attempt repository
+func NewMockLoginAttemptRepository() *MockLoginAttemptRepository {
+	return &MockLoginAttemptRepository{
+		RecordFailureFunc: func(_ context.Context, _ *models.LoginAttempt) error {
+			return nil
+		},
+		CountFailuresForUserFunc: func(_ context.Context, _ uuid.UUID, _ time.Time) (int, error) {
+			return 0, nil
+		},
+		CountFailuresForIPFunc: func(_ context.Context, _ string, _ time.Time) (int, error) {
+			return 0, nil
+		},
+		LockFunc: func(_ context.Context, _ uuid.UUID, _ time.Time) error {
+			return nil
+		},
+		GetLockedUntilFunc: func(_ context.Context, _ uuid.UUID) (*time.Time, error) {
+			return nil, nil
+		},
+		ResetFunc: func(_ context.Context, _ uuid.UUID) error {
+			return nil
+		},
+	}
+}
+
+// RecordFailure implements LoginAttemptRepository.RecordFailure
+func (m *MockLoginAttemptRepository) RecordFailure(ctx context.Context, attempt *models.LoginAttempt) error {
+	return m.RecordFailureFunc(ctx, attempt)
+}
+
+// CountFailuresForUser implements LoginAttemptRepository.CountFailuresForUser
+func (m *MockLoginAttemptRepository) CountFailuresForUser(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	return m.CountFailuresForUserFunc(ctx, userID, since)
+}
+
+// CountFailuresForIP implements LoginAttemptRepository.CountFailuresForIP
+func (m *MockLoginAttemptRepository) CountFailuresForIP(ctx context.Context, ipAddress string, since time.Time) (int, error) {
+	return m.CountFailuresForIPFunc(ctx, ipAddress, since)
+}
+
+// Lock implements LoginAttemptRepository.Lock
+func (m *MockLoginAttemptRepository) Lock(ctx context.Context, userID uuid.UUID, until time.Time) error {
+	return m.LockFunc(ctx, userID, until)
+}
+
+// GetLockedUntil implements LoginAttemptRepository.GetLockedUntil
+func (m *MockLoginAttemptRepository) GetLockedUntil(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	return m.GetLockedUntilFunc(ctx, userID)
+}
+
+// Reset implements LoginAttemptRepository.Reset
+func (m *MockLoginAttemptRepository) Reset(ctx context.Context, userID uuid.UUID) error {
+	return m.ResetFunc(ctx, userID)
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockLoginEventRepository is a mock implementation of LoginEventRepository for testing
+type MockLoginEventRepository struct {
+	RecordFunc func(ctx context.Context, event *models.LoginEvent) error
+	ListFunc   func(ctx context.Context, filter LoginEventFilter) ([]*models.LoginEvent, error)
+}
+
+// NewMockLoginEventRepository creates a new mock login event repository
+func NewMockLoginEventRepository() *MockLoginEventRepository {
+	return &MockLoginEventRepository{
+		RecordFunc: func(_ context.Context, _ *models.LoginEvent) error {
+			return nil
+		},
+		ListFunc: func(_ context.Context, _ LoginEventFilter) ([]*models.LoginEvent, error) {
+			return []*models.LoginEvent{}, nil
+		},
+	}
+}
+
+// Record implements LoginEventRepository.Record
+func (m *MockLoginEventRepository) Record(ctx context.Context, event *models.LoginEvent) error {
+	return m.RecordFunc(ctx, event)
+}
+
+// List implements LoginEventRepository.List
+func (m *MockLoginEventRepository) List(ctx context.Context, filter LoginEventFilter) ([]*models.LoginEvent, error) {
+	return m.ListFunc(ctx, filter)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockNotificationRepository is a mock implementation of NotificationRepository for testing
+type MockNotificationRepository struct {
+	CreateFunc                  func(ctx context.Context, notification *models.Notification) error
+	ListFunc                    func(ctx context.Context, userID uuid.UUID, filter NotificationFilter) ([]*models.Notification, error)
+	CountUnreadFunc             func(ctx context.Context, userID uuid.UUID) (int, error)
+	MarkReadFunc                func(ctx context.Context, userID, id uuid.UUID) error
+	MarkAllReadFunc             func(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetPreferencesFunc          func(ctx context.Context, userID uuid.UUID) ([]models.NotificationPreference, error)
+	SetPreferencesFunc          func(ctx context.Context, userID uuid.UUID, preferences []models.NotificationPreference) error
+	NotificationsEnabledFunc    func(ctx context.Context, userID uuid.UUID) (bool, error)
+	SetNotificationsEnabledFunc func(ctx context.Context, userID uuid.UUID, enabled bool) error
+}
+
+// NewMockNotificationRepository creates a new mock notification repository
+func NewMockNotificationRepository() *MockNotificationRepository {
+	return &MockNotificationRepository{
+		CreateFunc: func(_ context.Context, _ *models.Notification) error {
+			return nil
+		},
+		ListFunc: func(_ context.Context, _ uuid.UUID, _ NotificationFilter) ([]*models.Notification, error) {
+			return []*models.Notification{}, nil
+		},
+		CountUnreadFunc: func(_ context.Context, _ uuid.UUID) (int, error) {
+			return 0, nil
+		},
+		MarkReadFunc: func(_ context.Context, _, _ uuid.UUID) error {
+			return ErrNotificationNotFound
+		},
+		MarkAllReadFunc: func(_ context.Context, _ uuid.UUID) (int64, error) {
+			return 0, nil
+		},
+		GetPreferencesFunc: func(_ context.Context, _ uuid.UUID) ([]models.NotificationPreference, error) {
+			return []models.NotificationPreference{}, nil
+		},
+		SetPreferencesFunc: func(_ context.Context, _ uuid.UUID, _ []models.NotificationPreference) error {
+			return nil
+		},
+		NotificationsEnabledFunc: func(_ context.Context, _ uuid.UUID) (bool, error) {
+			return true, nil
+		},
+		SetNotificationsEnabledFunc: func(_ context.Context, _ uuid.UUID, _ bool) error {
+			return nil
+		},
+	}
+}
+
+// Create implements NotificationRepository.Create
+func (m *MockNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	return m.CreateFunc(ctx, notification)
+}
+
+// List implements NotificationRepository.List
+func (m *MockNotificationRepository) List(ctx context.Context, userID uuid.UUID, filter NotificationFilter) ([]*models.Notification, error) {
+	return m.ListFunc(ctx, userID, filter)
+}
+
+// CountUnread implements NotificationRepository.CountUnread
+func (m *MockNotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	return m.CountUnreadFunc(ctx, userID)
+}
+
+// MarkRead implements NotificationRepository.MarkRead
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	return m.MarkReadFunc(ctx, userID, id)
+}
+
+// MarkAllRead implements NotificationRepository.MarkAllRead
+func (m *MockNotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return m.MarkAllReadFunc(ctx, userID)
+}
+
+// GetPreferences implements NotificationRepository.GetPreferences
+func (m *MockNotificationRepository) GetPreferences(ctx context.Context, userID uuid.UUID) ([]models.NotificationPreference, error) {
+	return m.GetPreferencesFunc(ctx, userID)
+}
+
+// SetPreferences implements NotificationRepository.SetPreferences
+func (m *MockNotificationRepository) SetPreferences(ctx context.Context, userID uuid.UUID, preferences []models.NotificationPreference) error {
+	return m.SetPreferencesFunc(ctx, userID, preferences)
+}
+
+// NotificationsEnabled implements NotificationRepository.NotificationsEnabled
+func (m *MockNotificationRepository) NotificationsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return m.NotificationsEnabledFunc(ctx, userID)
+}
+
+// SetNotificationsEnabled implements NotificationRepository.SetNotificationsEnabled
+func (m *MockNotificationRepository) SetNotificationsEnabled(ctx context.Context, userID uuid.UUID, enabled bool) error {
+	return m.SetNotificationsEnabledFunc(ctx, userID, enabled)
+}
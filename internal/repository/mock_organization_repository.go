@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockOrganizationRepository is a mock implementation of OrganizationRepository for testing
+type MockOrganizationRepository struct {
+	CreateFunc                   func(ctx context.Context, org *models.Organization, ownerID uuid.UUID) error
+	GetByIDFunc                  func(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	ListByUserIDFunc             func(ctx context.Context, userID uuid.UUID) ([]*models.OrganizationMembership, error)
+	GetMemberFunc                func(ctx context.Context, orgID, userID uuid.UUID) (*models.OrganizationMember, error)
+	ListMembersFunc              func(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error)
+	UpdateMemberRoleFunc         func(ctx context.Context, orgID, userID uuid.UUID, role models.OrgRole) error
+	RemoveMemberFunc             func(ctx context.Context, orgID, userID uuid.UUID) error
+	CreateInvitationFunc         func(ctx context.Context, invitation *models.OrganizationInvitation) error
+	GetInvitationByTokenHashFunc func(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error)
+	AcceptInvitationFunc         func(ctx context.Context, invitationID, userID uuid.UUID) (*models.OrganizationMember, error)
+}
+
+// NewMockOrganizationRepository creates a new mock organization repository
+func NewMockOrganizationRepository() *MockOrganizationRepository {
+	return &MockOrganizationRepository{
+		CreateFunc: func(_ context.Context, _ *models.Organization, _ uuid.UUID) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.Organization, error) {
+			return nil, ErrOrganizationNotFound
+		},
+		ListByUserIDFunc: func(_ context.Context, _ uuid.UUID) ([]*models.OrganizationMembership, error) {
+			return []*models.OrganizationMembership{}, nil
+		},
+		GetMemberFunc: func(_ context.Context, _, _ uuid.UUID) (*models.OrganizationMember, error) {
+			return nil, ErrOrgMemberNotFound
+		},
+		ListMembersFunc: func(_ context.Context, _ uuid.UUID) ([]*models.OrganizationMember, error) {
+			return []*models.OrganizationMember{}, nil
+		},
+		UpdateMemberRoleFunc: func(_ context.Context, _, _ uuid.UUID, _ models.OrgRole) error {
+			return nil
+		},
+		RemoveMemberFunc: func(_ context.Context, _, _ uuid.UUID) error {
+			return nil
+		},
+		CreateInvitationFunc: func(_ context.Context, _ *models.OrganizationInvitation) error {
+			return nil
+		},
+		GetInvitationByTokenHashFunc: func(_ context.Context, _ string) (*models.OrganizationInvitation, error) {
+			return nil, ErrInvitationNotFound
+		},
+		AcceptInvitationFunc: func(_ context.Context, _, _ uuid.UUID) (*models.OrganizationMember, error) {
+			return nil, ErrInvitationNotFound
+		},
+	}
+}
+
+// Create implements OrganizationRepository.Create
+func (m *MockOrganizationRepository) Create(ctx context.Context, org *models.Organization, ownerID uuid.UUID) error {
+	return m.CreateFunc(ctx, org, ownerID)
+}
+
+// GetByID implements OrganizationRepository.GetByID
+func (m *MockOrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// ListByUserID implements OrganizationRepository.ListByUserID
+func (m *MockOrganizationRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.OrganizationMembership, error) {
+	return m.ListByUserIDFunc(ctx, userID)
+}
+
+// GetMember implements OrganizationRepository.GetMember
+func (m *MockOrganizationRepository) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	return m.GetMemberFunc(ctx, orgID, userID)
+}
+
+// ListMembers implements OrganizationRepository.ListMembers
+func (m *MockOrganizationRepository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error) {
+	return m.ListMembersFunc(ctx, orgID)
+}
+
+// UpdateMemberRole implements OrganizationRepository.UpdateMemberRole
+func (m *MockOrganizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, role models.OrgRole) error {
+	return m.UpdateMemberRoleFunc(ctx, orgID, userID, role)
+}
+
+// RemoveMember implements OrganizationRepository.RemoveMember
+func (m *MockOrganizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	return m.RemoveMemberFunc(ctx, orgID, userID)
+}
+
+// CreateInvitation implements OrganizationRepository.CreateInvitation
+func (m *MockOrganizationRepository) CreateInvitation(ctx context.Context, invitation *models.OrganizationInvitation) error {
+	return m.CreateInvitationFunc(ctx, invitation)
+}
+
+// GetInvitationByTokenHash implements OrganizationRepository.GetInvitationByTokenHash
+func (m *MockOrganizationRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error) {
+	return m.GetInvitationByTokenHashFunc(ctx, tokenHash)
+}
+
+// AcceptInvitation implements OrganizationRepository.AcceptInvitation
+func (m *MockOrganizationRepository) AcceptInvitation(ctx context.Context, invitationID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	return m.AcceptInvitationFunc(ctx, invitationID, userID)
+}
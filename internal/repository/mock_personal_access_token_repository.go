@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockPersonalAccessTokenRepository is a mock implementation of PersonalAccessTokenRepository for testing
+type MockPersonalAccessTokenRepository struct {
+	CreateFunc         func(ctx context.Context, token *models.PersonalAccessToken) error
+	GetByHashFunc      func(ctx context.Context, hash string) (*models.PersonalAccessToken, error)
+	ListByUserIDFunc   func(ctx context.Context, userID uuid.UUID) ([]*models.PersonalAccessToken, error)
+	RevokeFunc         func(ctx context.Context, userID, id uuid.UUID) error
+	UpdateLastUsedFunc func(ctx context.Context, id uuid.UUID) error
+}
+
+// NewMockPersonalAccessTokenRepository creates a new mock personal access token repository
+func NewMockPersonalAccessTokenRepository() *MockPersonalAccessTokenRepository {
+	return &MockPersonalAccessTokenRepository{
+		CreateFunc: func(_ context.Context, _ *models.PersonalAccessToken) error {
+			return nil
+		},
+		GetByHashFunc: func(_ context.Context, _ string) (*models.PersonalAccessToken, error) {
+			return nil, ErrPersonalAccessTokenNotFound
+		},
+		ListByUserIDFunc: func(_ context.Context, _ uuid.UUID) ([]*models.PersonalAccessToken, error) {
+			return []*models.PersonalAccessToken{}, nil
+		},
+		RevokeFunc: func(_ context.Context, _, _ uuid.UUID) error {
+			return ErrPersonalAccessTokenNotFound
+		},
+		UpdateLastUsedFunc: func(_ context.Context, _ uuid.UUID) error {
+			return nil
+		},
+	}
+}
+
+// Create implements PersonalAccessTokenRepository.Create
+func (m *MockPersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	return m.CreateFunc(ctx, token)
+}
+
+// GetByHash implements PersonalAccessTokenRepository.GetByHash
+func (m *MockPersonalAccessTokenRepository) GetByHash(ctx context.Context, hash string) (*models.PersonalAccessToken, error) {
+	return m.GetByHashFunc(ctx, hash)
+}
+
+// ListByUserID implements PersonalAccessTokenRepository.ListByUserID
+func (m *MockPersonalAccessTokenRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	return m.ListByUserIDFunc(ctx, userID)
+}
+
+// Revoke implements PersonalAccessTokenRepository.Revoke
+func (m *MockPersonalAccessTokenRepository) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	return m.RevokeFunc(ctx, userID, id)
+}
+
+// UpdateLastUsed implements PersonalAccessTokenRepository.UpdateLastUsed
+func (m *MockPersonalAccessTokenRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID) error {
+	return m.UpdateLastUsedFunc(ctx, id)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockProcessedTelemetryRepository is a mock implementation of ProcessedTelemetryRepository for testing
+type MockProcessedTelemetryRepository struct {
+	ReplaceSessionFunc func(ctx context.Context, sessionID uuid.UUID, points []*models.ProcessedTelemetry) error
+	ListPendingFunc    func(ctx context.Context, limit int) ([]uuid.UUID, error)
+}
+
+// NewMockProcessedTelemetryRepository creates a new mock processed telemetry repository
+func NewMockProcessedTelemetryRepository() *MockProcessedTelemetryRepository {
+	return &MockProcessedTelemetryRepository{
+		ReplaceSessionFunc: func(_ context.Context, _ uuid.UUID, _ []*models.ProcessedTelemetry) error {
+			return nil
+		},
+		ListPendingFunc: func(_ context.Context, _ int) ([]uuid.UUID, error) {
+			return []uuid.UUID{}, nil
+		},
+	}
+}
+
+// ReplaceSession implements ProcessedTelemetryRepository.ReplaceSession
+func (m *MockProcessedTelemetryRepository) ReplaceSession(ctx context.Context, sessionID uuid.UUID, points []*models.ProcessedTelemetry) error {
+	return m.ReplaceSessionFunc(ctx, sessionID, points)
+}
+
+// ListPending implements ProcessedTelemetryRepository.ListPending
+func (m *MockProcessedTelemetryRepository) ListPending(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return m.ListPendingFunc(ctx, limit)
+}
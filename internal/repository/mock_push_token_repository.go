@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockPushTokenRepository is a mock implementation of PushTokenRepository for testing
+type MockPushTokenRepository struct {
+	RegisterFunc      func(ctx context.Context, token *models.PushToken) error
+	ListByUserIDFunc  func(ctx context.Context, userID uuid.UUID) ([]*models.PushToken, error)
+	DeleteFunc        func(ctx context.Context, userID, id uuid.UUID) error
+	DeleteByTokenFunc func(ctx context.Context, token string) error
+}
+
+// NewMockPushTokenRepository creates a new mock push token repository
+func NewMockPushTokenRepository() *MockPushTokenRepository {
+	return &MockPushTokenRepository{
+		RegisterFunc: func(_ context.Context, token *models.PushToken) error {
+			if token.ID == uuid.Nil {
+				token.ID = uuid.New()
+			}
+			return nil
+		},
+		ListByUserIDFunc: func(_ context.Context, _ uuid.UUID) ([]*models.PushToken, error) {
+			return []*models.PushToken{}, nil
+		},
+		DeleteFunc: func(_ context.Context, _, _ uuid.UUID) error {
+			return ErrPushTokenNotFound
+		},
+		DeleteByTokenFunc: func(_ context.Context, _ string) error {
+			return nil
+		},
+	}
+}
+
+// Register implements PushTokenRepository.Register
+func (m *MockPushTokenRepository) Register(ctx context.Context, token *models.PushToken) error {
+	return m.RegisterFunc(ctx, token)
+}
+
+// ListByUserID implements PushTokenRepository.ListByUserID
+func (m *MockPushTokenRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.PushToken, error) {
+	return m.ListByUserIDFunc(ctx, userID)
+}
+
+// Delete implements PushTokenRepository.Delete
+func (m *MockPushTokenRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return m.DeleteFunc(ctx, userID, id)
+}
+
+// DeleteByToken implements PushTokenRepository.DeleteByToken
+func (m *MockPushTokenRepository) DeleteByToken(ctx context.Context, token string) error {
+	return m.DeleteByTokenFunc(ctx, token)
+}
@@ -9,12 +9,15 @@ import (
 
 // MockRefreshTokenRepository is a mock implementation of RefreshTokenRepository for testing
 type MockRefreshTokenRepository struct {
-	CreateFunc           func(ctx context.Context, token *models.RefreshToken) error
-	GetByHashFunc        func(ctx context.Context, hash string) (*models.RefreshToken, error)
-	RevokeFunc           func(ctx context.Context, id uuid.UUID) error
-	RevokeByHashFunc     func(ctx context.Context, hash string) error
-	RevokeAllForUserFunc func(ctx context.Context, userID uuid.UUID) error
-	DeleteExpiredFunc    func(ctx context.Context) (int64, error)
+	CreateFunc             func(ctx context.Context, token *models.RefreshToken) error
+	GetByHashFunc          func(ctx context.Context, hash string) (*models.RefreshToken, error)
+	RevokeFunc             func(ctx context.Context, id uuid.UUID) error
+	RevokeByHashFunc       func(ctx context.Context, hash string) error
+	RevokeAllForUserFunc   func(ctx context.Context, userID uuid.UUID) error
+	ListActiveSessionsFunc func(ctx context.Context, userID uuid.UUID) ([]*models.LoginSession, error)
+	ListCountriesFunc      func(ctx context.Context, userID uuid.UUID) ([]string, error)
+	RevokeForUserFunc      func(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	DeleteExpiredFunc      func(ctx context.Context) (int64, error)
 }
 
 // NewMockRefreshTokenRepository creates a new mock refresh token repository
@@ -35,6 +38,15 @@ func NewMockRefreshTokenRepository() *MockRefreshTokenRepository {
 		RevokeAllForUserFunc: func(_ context.Context, _ uuid.UUID) error {
 			return nil
 		},
+		ListActiveSessionsFunc: func(_ context.Context, _ uuid.UUID) ([]*models.LoginSession, error) {
+			return []*models.LoginSession{}, nil
+		},
+		ListCountriesFunc: func(_ context.Context, _ uuid.UUID) ([]string, error) {
+			return []string{}, nil
+		},
+		RevokeForUserFunc: func(_ context.Context, _ uuid.UUID, _ uuid.UUID) error {
+			return ErrRefreshTokenNotFound
+		},
 		DeleteExpiredFunc: func(_ context.Context) (int64, error) {
 			return 0, nil
 		},
@@ -66,6 +78,21 @@ func (m *MockRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userI
 	return m.RevokeAllForUserFunc(ctx, userID)
 }
 
+// ListActiveSessions implements RefreshTokenRepository.ListActiveSessions
+func (m *MockRefreshTokenRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*models.LoginSession, error) {
+	return m.ListActiveSessionsFunc(ctx, userID)
+}
+
+// ListCountries implements RefreshTokenRepository.ListCountries
+func (m *MockRefreshTokenRepository) ListCountries(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return m.ListCountriesFunc(ctx, userID)
+}
+
+// RevokeForUser implements RefreshTokenRepository.RevokeForUser
+func (m *MockRefreshTokenRepository) RevokeForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	return m.RevokeForUserFunc(ctx, id, userID)
+}
+
 // DeleteExpired implements RefreshTokenRepository.DeleteExpired
 func (m *MockRefreshTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	return m.DeleteExpiredFunc(ctx)
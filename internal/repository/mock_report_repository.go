@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockReportRepository is a mock implementation of ReportRepository for testing
+type MockReportRepository struct {
+	CreateFunc         func(ctx context.Context, report *models.Report) error
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.Report, error)
+	GetContentFunc     func(ctx context.Context, id uuid.UUID) ([]byte, error)
+	UpdateStatusFunc   func(ctx context.Context, id uuid.UUID, status models.ReportStatus, errMsg *string) error
+	CompleteFunc       func(ctx context.Context, id uuid.UUID, rows int, content []byte) error
+	FailIncompleteFunc func(ctx context.Context, errMsg string) (int64, error)
+	DeleteExpiredFunc  func(ctx context.Context, before time.Time) (int64, error)
+	SummarizeFunc      func(ctx context.Context, orgID *uuid.UUID, from, to time.Time) ([]*models.ReportRow, error)
+}
+
+// NewMockReportRepository creates a new mock report repository
+func NewMockReportRepository() *MockReportRepository {
+	return &MockReportRepository{
+		CreateFunc: func(_ context.Context, _ *models.Report) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.Report, error) {
+			return nil, ErrReportNotFound
+		},
+		GetContentFunc: func(_ context.Context, _ uuid.UUID) ([]byte, error) {
+			return nil, ErrReportNotFound
+		},
+		UpdateStatusFunc: func(_ context.Context, _ uuid.UUID, _ models.ReportStatus, _ *string) error {
+			return nil
+		},
+		CompleteFunc: func(_ context.Context, _ uuid.UUID, _ int, _ []byte) error {
+			return nil
+		},
+		FailIncompleteFunc: func(_ context.Context, _ string) (int64, error) {
+			return 0, nil
+		},
+		DeleteExpiredFunc: func(_ context.Context, _ time.Time) (int64, error) {
+			return 0, nil
+		},
+		SummarizeFunc: func(_ context.Context, _ *uuid.UUID, _, _ time.Time) ([]*models.ReportRow, error) {
+			return nil, nil
+		},
+	}
+}
+
+// Create implements ReportRepository.Create
+func (m *MockReportRepository) Create(ctx context.Context, report *models.Report) error {
+	return m.CreateFunc(ctx, report)
+}
+
+// GetByID implements ReportRepository.GetByID
+func (m *MockReportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Report, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// GetContent implements ReportRepository.GetContent
+func (m *MockReportRepository) GetContent(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	return m.GetContentFunc(ctx, id)
+}
+
+// UpdateStatus implements ReportRepository.UpdateStatus
+func (m *MockReportRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.ReportStatus, errMsg *string) error {
+	return m.UpdateStatusFunc(ctx, id, status, errMsg)
+}
+
+// Complete implements ReportRepository.Complete
+func (m *MockReportRepository) Complete(ctx context.Context, id uuid.UUID, rows int, content []byte) error {
+	return m.CompleteFunc(ctx, id, rows, content)
+}
+
+// FailIncomplete implements ReportRepository.FailIncomplete
+func (m *MockReportRepository) FailIncomplete(ctx context.Context, errMsg string) (int64, error) {
+	return m.FailIncompleteFunc(ctx, errMsg)
+}
+
+// DeleteExpired implements ReportRepository.DeleteExpired
+func (m *MockReportRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return m.DeleteExpiredFunc(ctx, before)
+}
+
+// Summarize implements ReportRepository.Summarize
+func (m *MockReportRepository) Summarize(ctx context.Context, orgID *uuid.UUID, from, to time.Time) ([]*models.ReportRow, error) {
+	return m.SummarizeFunc(ctx, orgID, from, to)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
 )
 
 // MockRepository is a mock implementation of TelemetryRepository for testing
@@ -15,8 +16,20 @@ type MockRepository struct {
 	GetBySessionFunc       func(ctx context.Context, sessionID string, limit int) ([]*models.TelemetryData, error)
 	GetRecentFunc          func(ctx context.Context, limit int) ([]*models.TelemetryData, error)
 	GetByDeviceFunc        func(ctx context.Context, deviceID string, limit int) ([]*models.TelemetryData, error)
+	IterateBySessionFunc   func(ctx context.Context, sessionID string) (TelemetryIterator, error)
+	IterateSessionPageFunc func(ctx context.Context, sessionID string, after *pagination.Cursor, limit int) (TelemetryIterator, error)
+	QueryFunc              func(ctx context.Context, filter TelemetryFilter) ([]*models.TelemetryData, error)
+	AggregateFunc          func(ctx context.Context, filter TelemetryFilter, bucket AggregateBucket) ([]*models.TelemetryBucket, error)
+	HeatmapFunc            func(ctx context.Context, filter TelemetryFilter, bbox models.BoundingBox, zoom int) ([]*models.HeatmapCell, error)
+	WithinFunc             func(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) ([]*models.TelemetryData, error)
+	WithinStatsFunc        func(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) (*models.AreaStats, error)
+	LatestPositionsFunc    func(ctx context.Context, devices []DeviceOwner) ([]*models.DevicePosition, error)
+	PreviousRecordsFunc    func(ctx context.Context, records []*models.TelemetryData) ([]*models.TelemetryData, error)
 	IsBatchProcessedFunc   func(ctx context.Context, batchID string) (bool, error)
 	MarkBatchProcessedFunc func(ctx context.Context, batchID string, recordCount int, deviceID string, sessionID *string) error
+	IngestStatsFunc        func(ctx context.Context, since time.Time) (*models.IngestStats, error)
+	OrphanedTelemetryFunc  func(ctx context.Context, limit int) ([]*models.OrphanedTelemetry, error)
+	SessionTrackFunc       func(ctx context.Context, sessionID string, toleranceMeters float64) (*models.TrackGeometry, error)
 }
 
 // NewMockRepository creates a new mock repository with default implementations
@@ -40,12 +53,48 @@ func NewMockRepository() *MockRepository {
 		GetByDeviceFunc: func(_ context.Context, _ string, _ int) ([]*models.TelemetryData, error) {
 			return []*models.TelemetryData{}, nil
 		},
+		IterateBySessionFunc: func(_ context.Context, _ string) (TelemetryIterator, error) {
+			return NewSliceTelemetryIterator(nil), nil
+		},
+		IterateSessionPageFunc: func(_ context.Context, _ string, _ *pagination.Cursor, _ int) (TelemetryIterator, error) {
+			return NewSliceTelemetryIterator(nil), nil
+		},
+		QueryFunc: func(_ context.Context, _ TelemetryFilter) ([]*models.TelemetryData, error) {
+			return []*models.TelemetryData{}, nil
+		},
+		AggregateFunc: func(_ context.Context, _ TelemetryFilter, _ AggregateBucket) ([]*models.TelemetryBucket, error) {
+			return []*models.TelemetryBucket{}, nil
+		},
+		HeatmapFunc: func(_ context.Context, _ TelemetryFilter, _ models.BoundingBox, _ int) ([]*models.HeatmapCell, error) {
+			return []*models.HeatmapCell{}, nil
+		},
+		WithinFunc: func(_ context.Context, _ TelemetryFilter, _ models.GeoPolygon) ([]*models.TelemetryData, error) {
+			return []*models.TelemetryData{}, nil
+		},
+		WithinStatsFunc: func(_ context.Context, _ TelemetryFilter, _ models.GeoPolygon) (*models.AreaStats, error) {
+			return &models.AreaStats{}, nil
+		},
+		LatestPositionsFunc: func(_ context.Context, _ []DeviceOwner) ([]*models.DevicePosition, error) {
+			return []*models.DevicePosition{}, nil
+		},
+		PreviousRecordsFunc: func(_ context.Context, records []*models.TelemetryData) ([]*models.TelemetryData, error) {
+			return make([]*models.TelemetryData, len(records)), nil
+		},
 		IsBatchProcessedFunc: func(_ context.Context, _ string) (bool, error) {
 			return false, nil
 		},
 		MarkBatchProcessedFunc: func(_ context.Context, _ string, _ int, _ string, _ *string) error {
 			return nil
 		},
+		IngestStatsFunc: func(_ context.Context, since time.Time) (*models.IngestStats, error) {
+			return &models.IngestStats{Since: since}, nil
+		},
+		OrphanedTelemetryFunc: func(_ context.Context, _ int) ([]*models.OrphanedTelemetry, error) {
+			return []*models.OrphanedTelemetry{}, nil
+		},
+		SessionTrackFunc: func(_ context.Context, _ string, _ float64) (*models.TrackGeometry, error) {
+			return &models.TrackGeometry{}, nil
+		},
 	}
 }
 
@@ -79,6 +128,41 @@ func (m *MockRepository) GetByDevice(ctx context.Context, deviceID string, limit
 	return m.GetByDeviceFunc(ctx, deviceID, limit)
 }
 
+// Query implements TelemetryRepository.Query
+func (m *MockRepository) Query(ctx context.Context, filter TelemetryFilter) ([]*models.TelemetryData, error) {
+	return m.QueryFunc(ctx, filter)
+}
+
+// Aggregate implements TelemetryRepository.Aggregate
+func (m *MockRepository) Aggregate(ctx context.Context, filter TelemetryFilter, bucket AggregateBucket) ([]*models.TelemetryBucket, error) {
+	return m.AggregateFunc(ctx, filter, bucket)
+}
+
+// Heatmap implements TelemetryRepository.Heatmap
+func (m *MockRepository) Heatmap(ctx context.Context, filter TelemetryFilter, bbox models.BoundingBox, zoom int) ([]*models.HeatmapCell, error) {
+	return m.HeatmapFunc(ctx, filter, bbox, zoom)
+}
+
+// Within implements TelemetryRepository.Within
+func (m *MockRepository) Within(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) ([]*models.TelemetryData, error) {
+	return m.WithinFunc(ctx, filter, area)
+}
+
+// WithinStats implements TelemetryRepository.WithinStats
+func (m *MockRepository) WithinStats(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) (*models.AreaStats, error) {
+	return m.WithinStatsFunc(ctx, filter, area)
+}
+
+// LatestPositions implements TelemetryRepository.LatestPositions
+func (m *MockRepository) LatestPositions(ctx context.Context, devices []DeviceOwner) ([]*models.DevicePosition, error) {
+	return m.LatestPositionsFunc(ctx, devices)
+}
+
+// PreviousRecords implements TelemetryRepository.PreviousRecords
+func (m *MockRepository) PreviousRecords(ctx context.Context, records []*models.TelemetryData) ([]*models.TelemetryData, error) {
+	return m.PreviousRecordsFunc(ctx, records)
+}
+
 // IsBatchProcessed implements TelemetryRepository.IsBatchProcessed
 func (m *MockRepository) IsBatchProcessed(ctx context.Context, batchID string) (bool, error) {
 	return m.IsBatchProcessedFunc(ctx, batchID)
@@ -88,3 +172,65 @@ func (m *MockRepository) IsBatchProcessed(ctx context.Context, batchID string) (
 func (m *MockRepository) MarkBatchProcessed(ctx context.Context, batchID string, recordCount int, deviceID string, sessionID *string) error {
 	return m.MarkBatchProcessedFunc(ctx, batchID, recordCount, deviceID, sessionID)
 }
+
+// IterateBySession implements TelemetryRepository.IterateBySession
+func (m *MockRepository) IterateBySession(ctx context.Context, sessionID string) (TelemetryIterator, error) {
+	return m.IterateBySessionFunc(ctx, sessionID)
+}
+
+// IterateSessionPage implements TelemetryRepository.IterateSessionPage
+func (m *MockRepository) IterateSessionPage(ctx context.Context, sessionID string, after *pagination.Cursor, limit int) (TelemetryIterator, error) {
+	return m.IterateSessionPageFunc(ctx, sessionID, after, limit)
+}
+
+// IngestStats implements TelemetryRepository.IngestStats
+func (m *MockRepository) IngestStats(ctx context.Context, since time.Time) (*models.IngestStats, error) {
+	return m.IngestStatsFunc(ctx, since)
+}
+
+// SliceTelemetryIterator is a TelemetryIterator over an in-memory slice for testing
+type SliceTelemetryIterator struct {
+	data   []*models.TelemetryData
+	index  int
+	Closed bool
+}
+
+// NewSliceTelemetryIterator creates an iterator over the given telemetry
+func NewSliceTelemetryIterator(data []*models.TelemetryData) *SliceTelemetryIterator {
+	return &SliceTelemetryIterator{data: data, index: -1}
+}
+
+// Next implements TelemetryIterator.Next
+func (it *SliceTelemetryIterator) Next() bool {
+	if it.index+1 >= len(it.data) {
+		return false
+	}
+	it.index++
+	return true
+}
+
+// Telemetry implements TelemetryIterator.Telemetry
+func (it *SliceTelemetryIterator) Telemetry() *models.TelemetryData {
+	return it.data[it.index]
+}
+
+// Err implements TelemetryIterator.Err
+func (it *SliceTelemetryIterator) Err() error {
+	return nil
+}
+
+// Close implements TelemetryIterator.Close
+func (it *SliceTelemetryIterator) Close() error {
+	it.Closed = true
+	return nil
+}
+
+// OrphanedTelemetry implements TelemetryRepository.OrphanedTelemetry
+func (m *MockRepository) OrphanedTelemetry(ctx context.Context, limit int) ([]*models.OrphanedTelemetry, error) {
+	return m.OrphanedTelemetryFunc(ctx, limit)
+}
+
+// SessionTrack implements TelemetryRepository.SessionTrack
+func (m *MockRepository) SessionTrack(ctx context.Context, sessionID string, toleranceMeters float64) (*models.TrackGeometry, error) {
+	return m.SessionTrackFunc(ctx, sessionID, toleranceMeters)
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockSessionRepository is a mock implementation of SessionRepository for testing
+type MockSessionRepository struct {
+	CreateFunc               func(ctx context.Context, session *models.Session) error
+	GetByIDFunc              func(ctx context.Context, id uuid.UUID) (*models.Session, error)
+	ListByUserIDFunc         func(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Session, error)
+	ListAccessibleFunc       func(ctx context.Context, filter SessionFilter) ([]*models.Session, int, error)
+	UpdateDetailsFunc        func(ctx context.Context, session *models.Session) error
+	EndFunc                  func(ctx context.Context, id uuid.UUID, endedAt time.Time) error
+	UpdateSummaryFunc        func(ctx context.Context, id uuid.UUID) (*models.Session, error)
+	GetStatsFunc             func(ctx context.Context, id uuid.UUID) (*models.SessionStats, error)
+	UpdateStatsFunc          func(ctx context.Context, id uuid.UUID) (*models.SessionStats, error)
+	ListPendingSummariesFunc func(ctx context.Context, limit int) ([]uuid.UUID, error)
+	AddMarkerFunc            func(ctx context.Context, marker *models.SessionMarker) error
+	ListMarkersFunc          func(ctx context.Context, sessionID uuid.UUID) ([]*models.SessionMarker, error)
+	DeleteMarkerFunc         func(ctx context.Context, sessionID, markerID uuid.UUID) error
+}
+
+// NewMockSessionRepository creates a new mock session repository
+func NewMockSessionRepository() *MockSessionRepository {
+	return &MockSessionRepository{
+		CreateFunc: func(_ context.Context, _ *models.Session) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.Session, error) {
+			return nil, ErrSessionNotFound
+		},
+		ListByUserIDFunc: func(_ context.Context, _ uuid.UUID, _, _ int) ([]*models.Session, error) {
+			return []*models.Session{}, nil
+		},
+		ListAccessibleFunc: func(_ context.Context, _ SessionFilter) ([]*models.Session, int, error) {
+			return []*models.Session{}, 0, nil
+		},
+		UpdateDetailsFunc: func(_ context.Context, _ *models.Session) error {
+			return nil
+		},
+		EndFunc: func(_ context.Context, _ uuid.UUID, _ time.Time) error {
+			return nil
+		},
+		UpdateSummaryFunc: func(_ context.Context, _ uuid.UUID) (*models.Session, error) {
+			return nil, ErrSessionNotFound
+		},
+		GetStatsFunc: func(_ context.Context, _ uuid.UUID) (*models.SessionStats, error) {
+			return nil, ErrSessionStatsNotFound
+		},
+		UpdateStatsFunc: func(_ context.Context, _ uuid.UUID) (*models.SessionStats, error) {
+			return nil, ErrSessionNotFound
+		},
+		ListPendingSummariesFunc: func(_ context.Context, _ int) ([]uuid.UUID, error) {
+			return []uuid.UUID{}, nil
+		},
+		AddMarkerFunc: func(_ context.Context, _ *models.SessionMarker) error {
+			return nil
+		},
+		ListMarkersFunc: func(_ context.Context, _ uuid.UUID) ([]*models.SessionMarker, error) {
+			return []*models.SessionMarker{}, nil
+		},
+		DeleteMarkerFunc: func(_ context.Context, _, _ uuid.UUID) error {
+			return ErrSessionMarkerNotFound
+		},
+	}
+}
+
+// Create implements SessionRepository.Create
+func (m *MockSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	return m.CreateFunc(ctx, session)
+}
+
+// GetByID implements SessionRepository.GetByID
+func (m *MockSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// ListByUserID implements SessionRepository.ListByUserID
+func (m *MockSessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Session, error) {
+	return m.ListByUserIDFunc(ctx, userID, limit, offset)
+}
+
+// ListAccessible implements SessionRepository.ListAccessible
+func (m *MockSessionRepository) ListAccessible(ctx context.Context, filter SessionFilter) ([]*models.Session, int, error) {
+	return m.ListAccessibleFunc(ctx, filter)
+}
+
+// UpdateDetails implements SessionRepository.UpdateDetails
+func (m *MockSessionRepository) UpdateDetails(ctx context.Context, session *models.Session) error {
+	return m.UpdateDetailsFunc(ctx, session)
+}
+
+// End implements SessionRepository.End
+func (m *MockSessionRepository) End(ctx context.Context, id uuid.UUID, endedAt time.Time) error {
+	return m.EndFunc(ctx, id, endedAt)
+}
+
+// UpdateSummary implements SessionRepository.UpdateSummary
+func (m *MockSessionRepository) UpdateSummary(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	return m.UpdateSummaryFunc(ctx, id)
+}
+
+// GetStats implements SessionRepository.GetStats
+func (m *MockSessionRepository) GetStats(ctx context.Context, id uuid.UUID) (*models.SessionStats, error) {
+	return m.GetStatsFunc(ctx, id)
+}
+
+// UpdateStats implements SessionRepository.UpdateStats
+func (m *MockSessionRepository) UpdateStats(ctx context.Context, id uuid.UUID) (*models.SessionStats, error) {
+	return m.UpdateStatsFunc(ctx, id)
+}
+
+// ListPendingSummaries implements SessionRepository.ListPendingSummaries
+func (m *MockSessionRepository) ListPendingSummaries(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return m.ListPendingSummariesFunc(ctx, limit)
+}
+
+// AddMarker implements SessionRepository.AddMarker
+func (m *MockSessionRepository) AddMarker(ctx context.Context, marker *models.SessionMarker) error {
+	return m.AddMarkerFunc(ctx, marker)
+}
+
+// ListMarkers implements SessionRepository.ListMarkers
+func (m *MockSessionRepository) ListMarkers(ctx context.Context, sessionID uuid.UUID) ([]*models.SessionMarker, error) {
+	return m.ListMarkersFunc(ctx, sessionID)
+}
+
+// DeleteMarker implements SessionRepository.DeleteMarker
+func (m *MockSessionRepository) DeleteMarker(ctx context.Context, sessionID, markerID uuid.UUID) error {
+	return m.DeleteMarkerFunc(ctx, sessionID, markerID)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockTelemetryDeletionRepository is a mock implementation of TelemetryDeletionRepository for testing
+type MockTelemetryDeletionRepository struct {
+	CreateFunc      func(ctx context.Context, deletion *models.TelemetryDeletion) error
+	GetByIDFunc     func(ctx context.Context, id uuid.UUID) (*models.TelemetryDeletion, error)
+	ListByUserFunc  func(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.TelemetryDeletion, error)
+	UndoFunc        func(ctx context.Context, id uuid.UUID) (*models.TelemetryDeletion, error)
+	ListExpiredFunc func(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+	PurgeFunc       func(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+// NewMockTelemetryDeletionRepository creates a new mock telemetry deletion repository
+func NewMockTelemetryDeletionRepository() *MockTelemetryDeletionRepository {
+	return &MockTelemetryDeletionRepository{
+		CreateFunc: func(_ context.Context, _ *models.TelemetryDeletion) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.TelemetryDeletion, error) {
+			return nil, ErrTelemetryDeletionNotFound
+		},
+		ListByUserFunc: func(_ context.Context, _ uuid.UUID, _, _ int) ([]*models.TelemetryDeletion, error) {
+			return []*models.TelemetryDeletion{}, nil
+		},
+		UndoFunc: func(_ context.Context, _ uuid.UUID) (*models.TelemetryDeletion, error) {
+			return nil, ErrTelemetryDeletionNotFound
+		},
+		ListExpiredFunc: func(_ context.Context, _ time.Time, _ int) ([]uuid.UUID, error) {
+			return []uuid.UUID{}, nil
+		},
+		PurgeFunc: func(_ context.Context, _ uuid.UUID) (int64, error) {
+			return 0, nil
+		},
+	}
+}
+
+// Create implements TelemetryDeletionRepository.Create
+func (m *MockTelemetryDeletionRepository) Create(ctx context.Context, deletion *models.TelemetryDeletion) error {
+	return m.CreateFunc(ctx, deletion)
+}
+
+// GetByID implements TelemetryDeletionRepository.GetByID
+func (m *MockTelemetryDeletionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TelemetryDeletion, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// ListByUser implements TelemetryDeletionRepository.ListByUser
+func (m *MockTelemetryDeletionRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.TelemetryDeletion, error) {
+	return m.ListByUserFunc(ctx, userID, limit, offset)
+}
+
+// Undo implements TelemetryDeletionRepository.Undo
+func (m *MockTelemetryDeletionRepository) Undo(ctx context.Context, id uuid.UUID) (*models.TelemetryDeletion, error) {
+	return m.UndoFunc(ctx, id)
+}
+
+// ListExpired implements TelemetryDeletionRepository.ListExpired
+func (m *MockTelemetryDeletionRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	return m.ListExpiredFunc(ctx, now, limit)
+}
+
+// Purge implements TelemetryDeletionRepository.Purge
+func (m *MockTelemetryDeletionRepository) Purge(ctx context.Context, id uuid.UUID) (int64, error) {
+	return m.PurgeFunc(ctx, id)
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockTelemetryPurgeRepository is a mock implementation of TelemetryPurgeRepository for testing
+type MockTelemetryPurgeRepository struct {
+	PlanFunc  func(ctx context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error)
+	PurgeFunc func(ctx context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error)
+}
+
+// NewMockTelemetryPurgeRepository creates a new mock telemetry purge repository
+func NewMockTelemetryPurgeRepository() *MockTelemetryPurgeRepository {
+	return &MockTelemetryPurgeRepository{
+		PlanFunc: func(_ context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+			return &models.TelemetryPurge{DeviceID: filter.DeviceID, From: filter.From, To: filter.To, DryRun: true, Chunks: []models.TelemetryChunkPurge{}}, nil
+		},
+		PurgeFunc: func(_ context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+			return &models.TelemetryPurge{DeviceID: filter.DeviceID, From: filter.From, To: filter.To, Chunks: []models.TelemetryChunkPurge{}}, nil
+		},
+	}
+}
+
+// Plan implements TelemetryPurgeRepository.Plan
+func (m *MockTelemetryPurgeRepository) Plan(ctx context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+	return m.PlanFunc(ctx, filter)
+}
+
+// Purge implements TelemetryPurgeRepository.Purge
+func (m *MockTelemetryPurgeRepository) Purge(ctx context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+	return m.PurgeFunc(ctx, filter)
+}
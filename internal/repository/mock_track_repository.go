@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockTrackRepository is a mock implementation of TrackRepository for testing
+type MockTrackRepository struct {
+	CreateFunc       func(ctx context.Context, track *models.Track) error
+	GetByIDFunc      func(ctx context.Context, id uuid.UUID) (*models.Track, error)
+	ListByUserIDFunc func(ctx context.Context, userID uuid.UUID) ([]*models.Track, error)
+}
+
+// NewMockTrackRepository creates a new mock track repository
+func NewMockTrackRepository() *MockTrackRepository {
+	return &MockTrackRepository{
+		CreateFunc: func(_ context.Context, _ *models.Track) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.Track, error) {
+			return nil, ErrTrackNotFound
+		},
+		ListByUserIDFunc: func(_ context.Context, _ uuid.UUID) ([]*models.Track, error) {
+			return []*models.Track{}, nil
+		},
+	}
+}
+
+// Create implements TrackRepository.Create
+func (m *MockTrackRepository) Create(ctx context.Context, track *models.Track) error {
+	return m.CreateFunc(ctx, track)
+}
+
+// GetByID implements TrackRepository.GetByID
+func (m *MockTrackRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Track, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// ListByUserID implements TrackRepository.ListByUserID
+func (m *MockTrackRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Track, error) {
+	return m.ListByUserIDFunc(ctx, userID)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockUploadRepository is a mock implementation of UploadRepository for testing
+type MockUploadRepository struct {
+	CreateFunc          func(ctx context.Context, upload *models.Upload) error
+	GetByIDFunc         func(ctx context.Context, id uuid.UUID) (*models.Upload, error)
+	AppendChunkFunc     func(ctx context.Context, id uuid.UUID, offset int64, data []byte) (int64, error)
+	FinalizeFunc        func(ctx context.Context, id uuid.UUID) (*models.Upload, error)
+	ClaimNextFunc       func(ctx context.Context, staleBefore time.Time) (*models.Upload, error)
+	GetChunkFunc        func(ctx context.Context, id uuid.UUID, offset int64) (int64, []byte, error)
+	UpdateProgressFunc  func(ctx context.Context, upload *models.Upload) error
+	DeleteAbandonedFunc func(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NewMockUploadRepository creates a new mock upload repository
+func NewMockUploadRepository() *MockUploadRepository {
+	return &MockUploadRepository{
+		CreateFunc: func(_ context.Context, _ *models.Upload) error {
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, _ uuid.UUID) (*models.Upload, error) {
+			return nil, ErrUploadNotFound
+		},
+		AppendChunkFunc: func(_ context.Context, _ uuid.UUID, offset int64, data []byte) (int64, error) {
+			return offset + int64(len(data)), nil
+		},
+		FinalizeFunc: func(_ context.Context, _ uuid.UUID) (*models.Upload, error) {
+			return nil, ErrUploadNotFound
+		},
+		ClaimNextFunc: func(_ context.Context, _ time.Time) (*models.Upload, error) {
+			return nil, nil
+		},
+		GetChunkFunc: func(_ context.Context, _ uuid.UUID, _ int64) (int64, []byte, error) {
+			return 0, nil, ErrUploadChunkNotFound
+		},
+		UpdateProgressFunc: func(_ context.Context, _ *models.Upload) error {
+			return nil
+		},
+		DeleteAbandonedFunc: func(_ context.Context, _ time.Time) (int64, error) {
+			return 0, nil
+		},
+	}
+}
+
+// Create implements UploadRepository.Create
+func (m *MockUploadRepository) Create(ctx context.Context, upload *models.Upload) error {
+	return m.CreateFunc(ctx, upload)
+}
+
+// GetByID implements UploadRepository.GetByID
+func (m *MockUploadRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Upload, error) {
+	return m.GetByIDFunc(ctx, id)
+}
+
+// AppendChunk implements UploadRepository.AppendChunk
+func (m *MockUploadRepository) AppendChunk(ctx context.Context, id uuid.UUID, offset int64, data []byte) (int64, error) {
+	return m.AppendChunkFunc(ctx, id, offset, data)
+}
+
+// Finalize implements UploadRepository.Finalize
+func (m *MockUploadRepository) Finalize(ctx context.Context, id uuid.UUID) (*models.Upload, error) {
+	return m.FinalizeFunc(ctx, id)
+}
+
+// ClaimNext implements UploadRepository.ClaimNext
+func (m *MockUploadRepository) ClaimNext(ctx context.Context, staleBefore time.Time) (*models.Upload, error) {
+	return m.ClaimNextFunc(ctx, staleBefore)
+}
+
+// GetChunk implements UploadRepository.GetChunk
+func (m *MockUploadRepository) GetChunk(ctx context.Context, id uuid.UUID, offset int64) (int64, []byte, error) {
+	return m.GetChunkFunc(ctx, id, offset)
+}
+
+// UpdateProgress implements UploadRepository.UpdateProgress
+func (m *MockUploadRepository) UpdateProgress(ctx context.Context, upload *models.Upload) error {
+	return m.UpdateProgressFunc(ctx, upload)
+}
+
+// DeleteAbandoned implements UploadRepository.DeleteAbandoned
+func (m *MockUploadRepository) DeleteAbandoned(ctx context.Context, before time.Time) (int64, error) {
+	return m.DeleteAbandonedFunc(ctx, before)
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockUsageRepository is a mock implementation of UsageRepository for testing
+type MockUsageRepository struct {
+	GetPlanFunc            func(ctx context.Context, userID uuid.UUID) (models.Plan, error)
+	SetPlanFunc            func(ctx context.Context, userID uuid.UUID, plan models.Plan) error
+	GetTelemetryPointsFunc func(ctx context.Context, userID uuid.UUID, periodStart time.Time) (int64, error)
+	AddTelemetryPointsFunc func(ctx context.Context, userID uuid.UUID, periodStart time.Time, points int64) error
+}
+
+// NewMockUsageRepository creates a new mock usage repository
+func NewMockUsageRepository() *MockUsageRepository {
+	return &MockUsageRepository{
+		GetPlanFunc: func(_ context.Context, _ uuid.UUID) (models.Plan, error) {
+			return models.PlanFree, nil
+		},
+		SetPlanFunc: func(_ context.Context, _ uuid.UUID, _ models.Plan) error {
+			return nil
+		},
+		GetTelemetryPointsFunc: func(_ context.Context, _ uuid.UUID, _ time.Time) (int64, error) {
+			return 0, nil
+		},
+		AddTelemetryPointsFunc: func(_ context.Context, _ uuid.UUID, _ time.Time, _ int64) error {
+			return nil
+		},
+	}
+}
+
+// GetPlan implements UsageRepository.GetPlan
+func (m *MockUsageRepository) GetPlan(ctx context.Context, userID uuid.UUID) (models.Plan, error) {
+	return m.GetPlanFunc(ctx, userID)
+}
+
+// SetPlan implements UsageRepository.SetPlan
+func (m *MockUsageRepository) SetPlan(ctx context.Context, userID uuid.UUID, plan models.Plan) error {
+	return m.SetPlanFunc(ctx, userID, plan)
+}
+
+// GetTelemetryPoints implements UsageRepository.GetTelemetryPoints
+func (m *MockUsageRepository) GetTelemetryPoints(ctx context.Context, userID uuid.UUID, periodStart time.Time) (int64, error) {
+	return m.GetTelemetryPointsFunc(ctx, userID, periodStart)
+}
+
+// AddTelemetryPoints implements UsageRepository.AddTelemetryPoints
+func (m *MockUsageRepository) AddTelemetryPoints(ctx context.Context, userID uuid.UUID, periodStart time.Time, points int64) error {
+	return m.AddTelemetryPointsFunc(ctx, userID, periodStart, points)
+}
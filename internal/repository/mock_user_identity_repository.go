@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// MockUserIdentityRepository is a mock implementation of UserIdentityRepository for testing
+type MockUserIdentityRepository struct {
+	CreateFunc       func(ctx context.Context, identity *models.UserIdentity) error
+	GetBySubjectFunc func(ctx context.Context, issuer, subject string) (*models.UserIdentity, error)
+	RecordLoginFunc  func(ctx context.Context, id uuid.UUID, email string) error
+}
+
+// NewMockUserIdentityRepository creates a new mock user identity repository
+func NewMockUserIdentityRepository() *MockUserIdentityRepository {
+	return &MockUserIdentityRepository{
+		CreateFunc: func(_ context.Context, _ *models.UserIdentity) error {
+			return nil
+		},
+		GetBySubjectFunc: func(_ context.Context, _, _ string) (*models.UserIdentity, error) {
+			return nil, ErrUserIdentityNotFound
+		},
+		RecordLoginFunc: func(_ context.Context, _ uuid.UUID, _ string) error {
+			return nil
+		},
+	}
+}
+
+// Create implements UserIdentityRepository.Create
+func (m *MockUserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return m.CreateFunc(ctx, identity)
+}
+
+// GetBySubject implements UserIdentityRepository.GetBySubject
+func (m *MockUserIdentityRepository) GetBySubject(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	return m.GetBySubjectFunc(ctx, issuer, subject)
+}
+
+// RecordLogin implements UserIdentityRepository.RecordLogin
+func (m *MockUserIdentityRepository) RecordLogin(ctx context.Context, id uuid.UUID, email string) error {
+	return m.RecordLoginFunc(ctx, id, email)
+}
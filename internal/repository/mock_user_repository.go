@@ -20,7 +20,16 @@ type MockUserRepository struct {
 	SetResetTokenFunc           func(ctx context.Context, id uuid.UUID, token string, expiresAt *time.Time) error
 	GetByResetTokenFunc         func(ctx context.Context, token string) (*models.User, error)
 	ClearResetTokenFunc         func(ctx context.Context, id uuid.UUID) error
+	SetMagicLinkTokenFunc       func(ctx context.Context, id uuid.UUID, token string, expiresAt time.Time) error
+	ConsumeMagicLinkTokenFunc   func(ctx context.Context, token string) (*models.User, error)
 	UpdateLastLoginFunc         func(ctx context.Context, id uuid.UUID) error
+	ListFunc                    func(ctx context.Context, filter UserFilter) ([]*models.User, error)
+	SetActiveFunc               func(ctx context.Context, id uuid.UUID, active bool) error
+	GetUnitsPreferenceFunc      func(ctx context.Context, id uuid.UUID) (string, error)
+	GetPrivacyTrimMetersFunc    func(ctx context.Context, id uuid.UUID) (int, error)
+	SetPrivacyTrimMetersFunc    func(ctx context.Context, id uuid.UUID, meters int) error
+	GetTimezoneFunc             func(ctx context.Context, id uuid.UUID) (string, error)
+	SetTimezoneFunc             func(ctx context.Context, id uuid.UUID, timezone string) error
 }
 
 // NewMockUserRepository creates a new mock user repository
@@ -56,9 +65,36 @@ func NewMockUserRepository() *MockUserRepository {
 		ClearResetTokenFunc: func(_ context.Context, _ uuid.UUID) error {
 			return nil
 		},
+		SetMagicLinkTokenFunc: func(_ context.Context, _ uuid.UUID, _ string, _ time.Time) error {
+			return nil
+		},
+		ConsumeMagicLinkTokenFunc: func(_ context.Context, _ string) (*models.User, error) {
+			return nil, ErrUserNotFound
+		},
 		UpdateLastLoginFunc: func(_ context.Context, _ uuid.UUID) error {
 			return nil
 		},
+		ListFunc: func(_ context.Context, _ UserFilter) ([]*models.User, error) {
+			return []*models.User{}, nil
+		},
+		SetActiveFunc: func(_ context.Context, _ uuid.UUID, _ bool) error {
+			return nil
+		},
+		GetUnitsPreferenceFunc: func(_ context.Context, _ uuid.UUID) (string, error) {
+			return "", nil
+		},
+		GetPrivacyTrimMetersFunc: func(_ context.Context, _ uuid.UUID) (int, error) {
+			return 0, nil
+		},
+		SetPrivacyTrimMetersFunc: func(_ context.Context, _ uuid.UUID, _ int) error {
+			return nil
+		},
+		GetTimezoneFunc: func(_ context.Context, _ uuid.UUID) (string, error) {
+			return "", nil
+		},
+		SetTimezoneFunc: func(_ context.Context, _ uuid.UUID, _ string) error {
+			return nil
+		},
 	}
 }
 
@@ -112,7 +148,52 @@ func (m *MockUserRepository) ClearResetToken(ctx context.Context, id uuid.UUID)
 	return m.ClearResetTokenFunc(ctx, id)
 }
 
+// SetMagicLinkToken implements UserRepository.SetMagicLinkToken
+func (m *MockUserRepository) SetMagicLinkToken(ctx context.Context, id uuid.UUID, token string, expiresAt time.Time) error {
+	return m.SetMagicLinkTokenFunc(ctx, id, token, expiresAt)
+}
+
+// ConsumeMagicLinkToken implements UserRepository.ConsumeMagicLinkToken
+func (m *MockUserRepository) ConsumeMagicLinkToken(ctx context.Context, token string) (*models.User, error) {
+	return m.ConsumeMagicLinkTokenFunc(ctx, token)
+}
+
 // UpdateLastLogin implements UserRepository.UpdateLastLogin
 func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) error {
 	return m.UpdateLastLoginFunc(ctx, id)
 }
+
+// List implements UserRepository.List
+func (m *MockUserRepository) List(ctx context.Context, filter UserFilter) ([]*models.User, error) {
+	return m.ListFunc(ctx, filter)
+}
+
+// SetActive implements UserRepository.SetActive
+func (m *MockUserRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	return m.SetActiveFunc(ctx, id, active)
+}
+
+// GetUnitsPreference implements UserRepository.GetUnitsPreference
+func (m *MockUserRepository) GetUnitsPreference(ctx context.Context, id uuid.UUID) (string, error) {
+	return m.GetUnitsPreferenceFunc(ctx, id)
+}
+
+// GetPrivacyTrimMeters implements UserRepository.GetPrivacyTrimMeters
+func (m *MockUserRepository) GetPrivacyTrimMeters(ctx context.Context, id uuid.UUID) (int, error) {
+	return m.GetPrivacyTrimMetersFunc(ctx, id)
+}
+
+// SetPrivacyTrimMeters implements UserRepository.SetPrivacyTrimMeters
+func (m *MockUserRepository) SetPrivacyTrimMeters(ctx context.Context, id uuid.UUID, meters int) error {
+	return m.SetPrivacyTrimMetersFunc(ctx, id, meters)
+}
+
+// GetTimezone implements UserRepository.GetTimezone
+func (m *MockUserRepository) GetTimezone(ctx context.Context, id uuid.UUID) (string, error) {
+	return m.GetTimezoneFunc(ctx, id)
+}
+
+// SetTimezone implements UserRepository.SetTimezone
+func (m *MockUserRepository) SetTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	return m.SetTimezoneFunc(ctx, id, timezone)
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// NotificationFilter restricts which notifications are listed
+type NotificationFilter struct {
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// NotificationRepository defines the interface for notification inbox and preference data access
+type NotificationRepository interface {
+	// Create stores a new notification in the user's inbox
+	Create(ctx context.Context, notification *models.Notification) error
+
+	// List retrieves the user's notifications matching the filter, most recent first
+	List(ctx context.Context, userID uuid.UUID, filter NotificationFilter) ([]*models.Notification, error)
+
+	// CountUnread counts the user's unread notifications
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+
+	// MarkRead marks one of the user's notifications as read
+	// Returns ErrNotificationNotFound if the user has no such notification.
+	MarkRead(ctx context.Context, userID, id uuid.UUID) error
+
+	// MarkAllRead marks all of the user's notifications as read, returning how many were unread
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// GetPreferences retrieves the preferences the user has set, which may not cover every type
+	GetPreferences(ctx context.Context, userID uuid.UUID) ([]models.NotificationPreference, error)
+
+	// SetPreferences creates or replaces the user's preferences for the given types
+	SetPreferences(ctx context.Context, userID uuid.UUID, preferences []models.NotificationPreference) error
+
+	// NotificationsEnabled reports the notifications_enabled flag of the user's profile
+	// Users without a profile have notifications enabled.
+	NotificationsEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+
+	// SetNotificationsEnabled sets the notifications_enabled flag, creating the profile if needed
+	SetNotificationsEnabled(ctx context.Context, userID uuid.UUID, enabled bool) error
+}
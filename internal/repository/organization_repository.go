@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// OrganizationRepository defines the interface for organization, membership and invitation data access
+type OrganizationRepository interface {
+	// Create stores a new organization with ownerID as its first owner
+	Create(ctx context.Context, org *models.Organization, ownerID uuid.UUID) error
+
+	// GetByID retrieves an organization by its UUID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+
+	// ListByUserID retrieves the organizations a user belongs to, with the user's role
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.OrganizationMembership, error)
+
+	// GetMember retrieves a user's membership in an organization
+	GetMember(ctx context.Context, orgID, userID uuid.UUID) (*models.OrganizationMember, error)
+
+	// ListMembers retrieves an organization's members, owners first
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error)
+
+	// UpdateMemberRole changes a member's role
+	// Returns ErrLastOrgOwner if the change would leave the organization without an owner.
+	UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, role models.OrgRole) error
+
+	// RemoveMember removes a user from an organization
+	// Returns ErrLastOrgOwner if the user is the organization's only owner.
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+
+	// CreateInvitation stores a new invitation
+	CreateInvitation(ctx context.Context, invitation *models.OrganizationInvitation) error
+
+	// GetInvitationByTokenHash retrieves an invitation by the hash of its token
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error)
+
+	// AcceptInvitation marks a pending invitation accepted and adds the user as a member
+	// Users who are already members keep their current role.
+	AcceptInvitation(ctx context.Context, invitationID, userID uuid.UUID) (*models.OrganizationMember, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PersonalAccessTokenRepository defines the interface for personal access token data access
+type PersonalAccessTokenRepository interface {
+	// Create stores a new personal access token
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+
+	// GetByHash retrieves a non-revoked personal access token by its hash
+	// Expired tokens are returned; callers check expiry against their own clock.
+	GetByHash(ctx context.Context, hash string) (*models.PersonalAccessToken, error)
+
+	// ListByUserID retrieves all tokens a user minted, newest first
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.PersonalAccessToken, error)
+
+	// Revoke marks one of the user's tokens as revoked
+	// Returns ErrPersonalAccessTokenNotFound if the user has no such token.
+	Revoke(ctx context.Context, userID, id uuid.UUID) error
+
+	// UpdateLastUsed updates the last_used_at timestamp for a token
+	UpdateLastUsed(ctx context.Context, id uuid.UUID) error
+}
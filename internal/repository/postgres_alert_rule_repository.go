@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrAlertRuleNotFound is returned when an alert rule is not found
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+// alertRuleColumns is the column list used by all alert rule SELECT queries
+const alertRuleColumns = `
+	id, user_id, device_id, name, metric, threshold,
+	cooldown_seconds, webhook_url, is_active, last_triggered_at,
+	created_at, updated_at
+`
+
+// alertEventColumns is the column list used by all alert event SELECT queries
+const alertEventColumns = `
+	id, rule_id, user_id, device_id, session_id,
+	metric, threshold, value, recorded_at,
+	latitude, longitude, created_at
+`
+
+// defaultAlertEventLimit caps event listings when no limit is given
+const defaultAlertEventLimit = 100
+
+// PostgresAlertRuleRepository implements AlertRuleRepository using PostgreSQL
+type PostgresAlertRuleRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAlertRuleRepository creates a new PostgreSQL alert rule repository
+func NewPostgresAlertRuleRepository(db *sql.DB) *PostgresAlertRuleRepository {
+	return &PostgresAlertRuleRepository{db: db}
+}
+
+// Create stores a new alert rule
+func (r *PostgresAlertRuleRepository) Create(ctx context.Context, rule *models.AlertRule) error {
+	query := `
+		INSERT INTO alert_rules (
+			id, user_id, device_id, name, metric, threshold,
+			cooldown_seconds, webhook_url, is_active,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+
+	now := time.Now()
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = now
+	}
+	if rule.UpdatedAt.IsZero() {
+		rule.UpdatedAt = now
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		rule.ID,
+		rule.UserID,
+		rule.DeviceID,
+		rule.Name,
+		rule.Metric,
+		rule.Threshold,
+		rule.CooldownSeconds,
+		rule.WebhookURL,
+		rule.IsActive,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert alert rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an alert rule by its UUID
+func (r *PostgresAlertRuleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE id = $1`
+
+	rule, err := scanAlertRule(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlertRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// ListByUserID retrieves all alert rules owned by a user, most recent first
+func (r *PostgresAlertRuleRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.AlertRule, error) {
+	return r.list(ctx, `WHERE user_id = $1`, userID)
+}
+
+// ListActive retrieves the user's active rules for a device for evaluation
+func (r *PostgresAlertRuleRepository) ListActive(ctx context.Context, userID uuid.UUID, deviceID string) ([]*models.AlertRule, error) {
+	return r.list(ctx, `WHERE user_id = $1 AND device_id = $2 AND is_active = TRUE`, userID, deviceID)
+}
+
+// list retrieves alert rules matching the where clause, most recent first
+func (r *PostgresAlertRuleRepository) list(ctx context.Context, where string, args ...interface{}) ([]*models.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules ` + where + ` ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*models.AlertRule, 0)
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert rule row: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert rule rows: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Update stores a rule's name, device, metric, threshold, cooldown, webhook URL and active flag
+func (r *PostgresAlertRuleRepository) Update(ctx context.Context, rule *models.AlertRule) error {
+	query := `
+		UPDATE alert_rules
+		SET device_id = $2, name = $3, metric = $4, threshold = $5,
+			cooldown_seconds = $6, webhook_url = $7, is_active = $8
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		rule.ID,
+		rule.DeviceID,
+		rule.Name,
+		rule.Metric,
+		rule.Threshold,
+		rule.CooldownSeconds,
+		rule.WebhookURL,
+		rule.IsActive,
+	).Scan(&rule.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAlertRuleNotFound
+		}
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an alert rule together with its events
+func (r *PostgresAlertRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// RecordEvents stores alert events and advances their rules' last trigger time in one transaction
+func (r *PostgresAlertRuleRepository) RecordEvents(ctx context.Context, events []*models.AlertEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, event := range events {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO alert_events (
+				rule_id, user_id, device_id, session_id,
+				metric, threshold, value, recorded_at,
+				latitude, longitude
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, created_at
+		`,
+			event.RuleID, event.UserID, event.DeviceID, event.SessionID,
+			event.Metric, event.Threshold, event.Value, event.RecordedAt,
+			event.Latitude, event.Longitude,
+		).Scan(&event.ID, &event.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert alert event: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE alert_rules
+			SET last_triggered_at = GREATEST(COALESCE(last_triggered_at, $2), $2)
+			WHERE id = $1
+		`, event.RuleID, event.RecordedAt)
+		if err != nil {
+			return fmt.Errorf("failed to update alert rule trigger time: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit alert events: %w", err)
+	}
+
+	return nil
+}
+
+// ListEvents retrieves alert events matching the filter, most recent first
+func (r *PostgresAlertRuleRepository) ListEvents(ctx context.Context, filter AlertEventFilter) ([]*models.AlertEvent, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAlertEventLimit
+	}
+
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{filter.UserID}
+
+	if filter.RuleID != nil {
+		args = append(args, *filter.RuleID)
+		conditions = append(conditions, fmt.Sprintf("rule_id = $%d", len(args)))
+	}
+	if filter.DeviceID != "" {
+		args = append(args, filter.DeviceID)
+		conditions = append(conditions, fmt.Sprintf("device_id = $%d", len(args)))
+	}
+
+	args = append(args, limit)
+	query := `
+		SELECT ` + alertEventColumns + `
+		FROM alert_events
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY recorded_at DESC, id DESC
+		LIMIT $` + fmt.Sprint(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*models.AlertEvent, 0)
+	for rows.Next() {
+		var event models.AlertEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.RuleID,
+			&event.UserID,
+			&event.DeviceID,
+			&event.SessionID,
+			&event.Metric,
+			&event.Threshold,
+			&event.Value,
+			&event.RecordedAt,
+			&event.Latitude,
+			&event.Longitude,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan alert event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert events: %w", err)
+	}
+
+	return events, nil
+}
+
+// scanAlertRule scans a single alert rule row selected with alertRuleColumns
+func scanAlertRule(row rowScanner) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.DeviceID,
+		&rule.Name,
+		&rule.Metric,
+		&rule.Threshold,
+		&rule.CooldownSeconds,
+		&rule.WebhookURL,
+		&rule.IsActive,
+		&rule.LastTriggeredAt,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
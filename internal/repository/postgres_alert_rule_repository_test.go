@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresAlertRuleRepository_CRUD(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresAlertRuleRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "alert-rules@example.com")
+
+	webhook := "https://example.com/hooks/alerts"
+	speed := &models.AlertRule{
+		UserID:          user.ID,
+		DeviceID:        "AVT-001",
+		Name:            "Speeding",
+		Metric:          models.AlertMetricSpeed,
+		Threshold:       180,
+		CooldownSeconds: 300,
+		WebhookURL:      &webhook,
+		IsActive:        true,
+		CreatedAt:       time.Now().Add(-time.Hour),
+	}
+	cornering := &models.AlertRule{
+		UserID:    user.ID,
+		DeviceID:  "AVT-001",
+		Name:      "Hard cornering",
+		Metric:    models.AlertMetricLateralG,
+		Threshold: 1.3,
+		IsActive:  false,
+	}
+	require.NoError(t, repo.Create(ctx, speed))
+	require.NoError(t, repo.Create(ctx, cornering))
+	assert.NotEqual(t, uuid.Nil, speed.ID)
+
+	retrieved, err := repo.GetByID(ctx, speed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Speeding", retrieved.Name)
+	assert.Equal(t, models.AlertMetricSpeed, retrieved.Metric)
+	assert.Equal(t, 300, retrieved.CooldownSeconds)
+	require.NotNil(t, retrieved.WebhookURL)
+	assert.Equal(t, webhook, *retrieved.WebhookURL)
+	assert.Nil(t, retrieved.LastTriggeredAt)
+
+	all, err := repo.ListByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, cornering.ID, all[0].ID, "most recent rule first")
+
+	active, err := repo.ListActive(ctx, user.ID, "AVT-001")
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, speed.ID, active[0].ID)
+
+	active, err = repo.ListActive(ctx, user.ID, "AVT-002")
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	cornering.IsActive = true
+	cornering.Threshold = 1.1
+	require.NoError(t, repo.Update(ctx, cornering))
+	retrieved, err = repo.GetByID(ctx, cornering.ID)
+	require.NoError(t, err)
+	assert.True(t, retrieved.IsActive)
+	assert.InDelta(t, 1.1, retrieved.Threshold, 1e-9)
+
+	require.NoError(t, repo.Delete(ctx, cornering.ID))
+	_, err = repo.GetByID(ctx, cornering.ID)
+	assert.ErrorIs(t, err, ErrAlertRuleNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, cornering.ID), ErrAlertRuleNotFound)
+	assert.ErrorIs(t, repo.Update(ctx, cornering), ErrAlertRuleNotFound)
+}
+
+func TestPostgresAlertRuleRepository_RecordEvents(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresAlertRuleRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "alert-events@example.com")
+
+	rule := &models.AlertRule{
+		UserID:    user.ID,
+		DeviceID:  "AVT-001",
+		Name:      "Speeding",
+		Metric:    models.AlertMetricSpeed,
+		Threshold: 180,
+		IsActive:  true,
+	}
+	require.NoError(t, repo.Create(ctx, rule))
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	latitude, longitude := 42.6977, 23.3219
+	require.NoError(t, repo.RecordEvents(ctx, []*models.AlertEvent{{
+		RuleID: rule.ID, UserID: user.ID, DeviceID: "AVT-001",
+		Metric: models.AlertMetricSpeed, Threshold: 180, Value: 192.5,
+		RecordedAt: now, Latitude: &latitude, Longitude: &longitude,
+	}}))
+
+	// An older event must not move the trigger time back
+	require.NoError(t, repo.RecordEvents(ctx, []*models.AlertEvent{{
+		RuleID: rule.ID, UserID: user.ID, DeviceID: "AVT-001",
+		Metric: models.AlertMetricSpeed, Threshold: 180, Value: 185,
+		RecordedAt: now.Add(-time.Hour),
+	}}))
+
+	retrieved, err := repo.GetByID(ctx, rule.ID)
+	require.NoError(t, err)
+	require.NotNil(t, retrieved.LastTriggeredAt)
+	assert.True(t, now.Equal(*retrieved.LastTriggeredAt))
+
+	events, err := repo.ListEvents(ctx, AlertEventFilter{UserID: user.ID, RuleID: &rule.ID})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.InDelta(t, 192.5, events[0].Value, 1e-9, "most recent event first")
+	require.NotNil(t, events[0].Latitude)
+	assert.Nil(t, events[1].Latitude)
+
+	events, err = repo.ListEvents(ctx, AlertEventFilter{UserID: user.ID, DeviceID: "other-device"})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
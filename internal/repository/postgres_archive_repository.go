@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrArchiveChanged is returned when a chunk's records changed while it was being archived
+var ErrArchiveChanged = errors.New("archived telemetry changed during archival")
+
+// PostgresArchiveRepository implements ArchiveRepository using PostgreSQL
+type PostgresArchiveRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresArchiveRepository creates a new PostgreSQL archive repository
+func NewPostgresArchiveRepository(db *sql.DB) *PostgresArchiveRepository {
+	return &PostgresArchiveRepository{db: db}
+}
+
+// archivableTelemetry restricts telemetry to the records archival moves
+// Deleted records awaiting purge are left for the purge, so they are never archived.
+const archivableTelemetry = `device_id IS NOT NULL AND user_id IS NOT NULL AND ` + visibleTelemetry
+
+// ListPendingChunks implements ArchiveRepository.ListPendingChunks
+func (r *PostgresArchiveRepository) ListPendingChunks(ctx context.Context, before time.Time, limit int) ([]models.ArchiveChunk, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT device_id, time_bucket(INTERVAL '1 day', recorded_at) AS day, COUNT(*)
+		FROM telemetry
+		WHERE recorded_at < $1 AND ` + archivableTelemetry + `
+		GROUP BY 1, 2
+		ORDER BY day ASC, device_id ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archivable telemetry: %w", err)
+	}
+	defer rows.Close()
+
+	chunks := make([]models.ArchiveChunk, 0)
+	for rows.Next() {
+		var chunk models.ArchiveChunk
+		if err := rows.Scan(&chunk.DeviceID, &chunk.Day, &chunk.Rows); err != nil {
+			return nil, fmt.Errorf("failed to scan archivable chunk: %w", err)
+		}
+		chunk.Day = chunk.Day.UTC()
+		chunks = append(chunks, chunk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archivable chunks: %w", err)
+	}
+
+	return chunks, nil
+}
+
+// IterateChunk implements ArchiveRepository.IterateChunk
+func (r *PostgresArchiveRepository) IterateChunk(ctx context.Context, chunk models.ArchiveChunk) (TelemetryIterator, error) {
+	query := `
+		SELECT ` + telemetryColumns + `
+		FROM telemetry
+		WHERE device_id = $1 AND recorded_at >= $2 AND recorded_at < $3 AND ` + archivableTelemetry + `
+		ORDER BY recorded_at ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, chunk.DeviceID, chunk.Day, chunk.End())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chunk telemetry: %w", err)
+	}
+
+	return &rowsTelemetryIterator{rows: rows}, nil
+}
+
+// Complete implements ArchiveRepository.Complete
+func (r *PostgresArchiveRepository) Complete(ctx context.Context, archive *models.TelemetryArchive) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	var deleted int64
+	err = tx.QueryRowContext(ctx, `
+		WITH archived AS (
+			DELETE FROM telemetry
+			WHERE device_id = $1 AND recorded_at >= $2 AND recorded_at < $3 AND id <= $4
+			  AND `+archivableTelemetry+`
+			RETURNING id, recorded_at
+		), processed AS (
+			DELETE FROM telemetry_processed
+			USING archived
+			WHERE telemetry_id = archived.id AND telemetry_recorded_at = archived.recorded_at
+		)
+		SELECT COUNT(*) FROM archived
+	`, archive.DeviceID, archive.Day, archive.Day.AddDate(0, 0, 1), archive.MaxID).Scan(&deleted)
+	if err != nil {
+		return fmt.Errorf("failed to delete archived telemetry: %w", err)
+	}
+	if deleted != archive.RowCount {
+		return ErrArchiveChanged
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO telemetry_archives (
+			device_id, day, object_key, regions, user_ids, row_count, size_bytes,
+			first_recorded_at, last_recorded_at
+		) VALUES ($1, $2, $3, $4::text[], $5::uuid[], $6, $7, $8, $9)
+		RETURNING id, archived_at
+	`,
+		archive.DeviceID, archive.Day, archive.ObjectKey, archive.Regions, uuidStrings(archive.UserIDs),
+		archive.RowCount, archive.SizeBytes, archive.FirstRecordedAt, archive.LastRecordedAt,
+	).Scan(&archive.ID, &archive.ArchivedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store archive manifest: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// List implements ArchiveRepository.List
+func (r *PostgresArchiveRepository) List(ctx context.Context, filter ArchiveFilter) ([]*models.TelemetryArchive, error) {
+	conditions := []string{"user_ids @> ARRAY[$1::uuid]", "last_recorded_at >= $2", "first_recorded_at <= $3"}
+	args := []interface{}{filter.UserID, filter.From, filter.To}
+
+	if filter.DeviceID != "" {
+		args = append(args, filter.DeviceID)
+		conditions = append(conditions, fmt.Sprintf("device_id = $%d", len(args)))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, device_id, day, object_key, array_to_string(regions, ','), row_count, size_bytes,
+			first_recorded_at, last_recorded_at, archived_at
+		FROM telemetry_archives
+		WHERE %s
+		ORDER BY first_recorded_at ASC, id ASC
+	`, strings.Join(conditions, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry archives: %w", err)
+	}
+	defer rows.Close()
+
+	archives := make([]*models.TelemetryArchive, 0)
+	for rows.Next() {
+		archive := &models.TelemetryArchive{}
+		var regions string
+		if err := rows.Scan(
+			&archive.ID, &archive.DeviceID, &archive.Day, &archive.ObjectKey, &regions,
+			&archive.RowCount, &archive.SizeBytes,
+			&archive.FirstRecordedAt, &archive.LastRecordedAt, &archive.ArchivedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry archive: %w", err)
+		}
+		archive.Regions = strings.Split(regions, ",")
+		archives = append(archives, archive)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry archives: %w", err)
+	}
+
+	return archives, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresArchiveRepository_ArchiveChunk(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresArchiveRepository(db.DB)
+	telemetryRepo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "archive@example.com")
+
+	day := time.Now().UTC().AddDate(0, 0, -100).Truncate(24 * time.Hour)
+	for i := range 3 {
+		point := createSampleTelemetry(day.Add(time.Duration(i)*time.Hour), "RACEBOX-001")
+		point.UserID = &user.ID
+		require.NoError(t, telemetryRepo.Save(ctx, point))
+	}
+
+	// Anonymous telemetry is left for device adoption
+	anonymous := createSampleTelemetry(day.Add(time.Hour), "RACEBOX-002")
+	require.NoError(t, telemetryRepo.Save(ctx, anonymous))
+
+	chunks, err := repo.ListPendingChunks(ctx, day.AddDate(0, 0, 1), 10)
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	chunk := chunks[0]
+	assert.Equal(t, "RACEBOX-001", chunk.DeviceID)
+	assert.True(t, chunk.Day.Equal(day))
+	assert.Equal(t, int64(3), chunk.Rows)
+
+	// Days that are not over yet are not archivable
+	chunks, err = repo.ListPendingChunks(ctx, day, 10)
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	it, err := repo.IterateChunk(ctx, chunk)
+	require.NoError(t, err)
+	archive := &models.TelemetryArchive{
+		DeviceID:  chunk.DeviceID,
+		Day:       chunk.Day,
+		ObjectKey: "telemetry/RACEBOX-001/test.parquet",
+		Regions:   []string{"eu-west-1", "us-east-1"},
+		UserIDs:   []uuid.UUID{user.ID},
+		SizeBytes: 1024,
+	}
+	for it.Next() {
+		record := it.Telemetry()
+		if archive.RowCount == 0 {
+			archive.FirstRecordedAt = record.Timestamp
+		}
+		archive.RowCount++
+		archive.LastRecordedAt = record.Timestamp
+		archive.MaxID = max(archive.MaxID, record.ID)
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	assert.Equal(t, int64(3), archive.RowCount)
+
+	// A count that no longer matches leaves everything in place
+	stale := *archive
+	stale.RowCount = 4
+	assert.ErrorIs(t, repo.Complete(ctx, &stale), ErrArchiveChanged)
+
+	require.NoError(t, repo.Complete(ctx, archive))
+	assert.NotEqual(t, uuid.Nil, archive.ID)
+
+	chunks, err = repo.ListPendingChunks(ctx, day.AddDate(0, 0, 1), 10)
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	remaining, err := telemetryRepo.Query(ctx, TelemetryFilter{DeviceID: "RACEBOX-001", UserID: user.ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	archives, err := repo.List(ctx, ArchiveFilter{UserID: user.ID, From: day, To: day.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, archives, 1)
+	assert.Equal(t, archive.ObjectKey, archives[0].ObjectKey)
+	assert.Equal(t, []string{"eu-west-1", "us-east-1"}, archives[0].Regions)
+
+	archives, err = repo.List(ctx, ArchiveFilter{UserID: uuid.New(), From: day, To: day.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, archives)
+
+	archives, err = repo.List(ctx, ArchiveFilter{UserID: user.ID, DeviceID: "RACEBOX-002", From: day, To: day.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, archives)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PostgresCircuitRepository implements CircuitRepository using PostgreSQL
+type PostgresCircuitRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresCircuitRepository creates a new PostgreSQL circuit repository
+func NewPostgresCircuitRepository(db *sql.DB) *PostgresCircuitRepository {
+	return &PostgresCircuitRepository{db: db}
+}
+
+// Nearby returns up to limit circuits within radiusMeters of a point, closest first
+// Distance is measured to the circuit boundary, so a point inside a circuit is 0 meters away.
+func (r *PostgresCircuitRepository) Nearby(ctx context.Context, point geo.Point, radiusMeters float64, limit int) ([]*models.Circuit, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	query := `
+		WITH origin AS (
+			SELECT ST_SetSRID(ST_MakePoint($2::float8, $1::float8), 4326)::geography AS location
+		)
+		SELECT
+			c.id, c.name, c.country,
+			ST_Y(ST_Centroid(c.boundary::geometry)), ST_X(ST_Centroid(c.boundary::geometry)),
+			ST_Distance(c.boundary, origin.location)
+		FROM circuits c, origin
+		WHERE ST_DWithin(c.boundary, origin.location, $3::float8)
+		ORDER BY ST_Distance(c.boundary, origin.location), c.name
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, point.Latitude, point.Longitude, radiusMeters, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nearby circuits: %w", err)
+	}
+	defer rows.Close()
+
+	circuits := make([]*models.Circuit, 0)
+	for rows.Next() {
+		var circuit models.Circuit
+		if err := rows.Scan(
+			&circuit.ID,
+			&circuit.Name,
+			&circuit.Country,
+			&circuit.Center.Latitude,
+			&circuit.Center.Longitude,
+			&circuit.DistanceMeters,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan circuit: %w", err)
+		}
+		circuits = append(circuits, &circuit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating circuits: %w", err)
+	}
+
+	return circuits, nil
+}
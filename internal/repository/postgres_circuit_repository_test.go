@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestCircuit inserts a catalog circuit covering the given envelope and returns its ID
+func createTestCircuit(t *testing.T, db *database.DB, name string, minLat, minLon, maxLat, maxLon float64) string {
+	t.Helper()
+
+	var id string
+	err := db.QueryRow(`
+		INSERT INTO circuits (name, country, boundary)
+		VALUES ($1, 'GR', ST_MakeEnvelope($2, $3, $4, $5, 4326)::geography)
+		RETURNING id
+	`, name, minLon, minLat, maxLon, maxLat).Scan(&id)
+	require.NoError(t, err)
+
+	return id
+}
+
+// clearCircuitCatalog removes the circuits seeded by the migrations so tests control the catalog
+func clearCircuitCatalog(t *testing.T, db *database.DB) {
+	t.Helper()
+
+	_, err := db.Exec(`DELETE FROM circuits`)
+	require.NoError(t, err)
+}
+
+func TestPostgresCircuitRepository_Nearby(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresCircuitRepository(db.DB)
+	ctx := context.Background()
+
+	clearCircuitCatalog(t, db)
+	createTestCircuit(t, db, "Serres Racing Circuit", 41.068, 23.506, 41.076, 23.518)
+	createTestCircuit(t, db, "Kalambaka Circuit", 39.700, 21.600, 39.706, 21.610)
+
+	// Inside the Serres boundary
+	circuits, err := repo.Nearby(ctx, geo.Point{Latitude: 41.072, Longitude: 23.512}, 50000, 10)
+	require.NoError(t, err)
+	require.Len(t, circuits, 1)
+	assert.Equal(t, "Serres Racing Circuit", circuits[0].Name)
+	assert.Equal(t, "GR", circuits[0].Country)
+	assert.InDelta(t, 0, circuits[0].DistanceMeters, 0.001)
+	assert.InDelta(t, 41.072, circuits[0].Center.Latitude, 0.001)
+	assert.InDelta(t, 23.512, circuits[0].Center.Longitude, 0.001)
+
+	// A wide radius returns both, closest first
+	circuits, err = repo.Nearby(ctx, geo.Point{Latitude: 41.0, Longitude: 23.5}, 500000, 10)
+	require.NoError(t, err)
+	require.Len(t, circuits, 2)
+	assert.Equal(t, "Serres Racing Circuit", circuits[0].Name)
+	assert.Greater(t, circuits[0].DistanceMeters, 0.0)
+	assert.Less(t, circuits[0].DistanceMeters, circuits[1].DistanceMeters)
+
+	// Nothing within a small radius of open sea
+	circuits, err = repo.Nearby(ctx, geo.Point{Latitude: 38.0, Longitude: 25.0}, 1000, 10)
+	require.NoError(t, err)
+	assert.Empty(t, circuits)
+}
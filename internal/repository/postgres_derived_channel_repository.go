@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PostgresDerivedChannelRepository implements DerivedChannelRepository using PostgreSQL
+type PostgresDerivedChannelRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDerivedChannelRepository creates a new PostgreSQL derived channel repository
+func NewPostgresDerivedChannelRepository(db *sql.DB) *PostgresDerivedChannelRepository {
+	return &PostgresDerivedChannelRepository{db: db}
+}
+
+// Backfill derives the channels of up to limit records recorded in [from, to) that have none,
+// returning how many were updated
+// The previous record of each device is found with a lateral join on idx_telemetry_device_time.
+// Distances are measured on the same sphere as geo.Distance, so they match the ones derived on ingest.
+// Missing g-force axes count as zero, as they do on ingest, so every updated record gets a total g
+// and leaves the pending set.
+func (r *PostgresDerivedChannelRepository) Backfill(ctx context.Context, from, to time.Time, limit int) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		WITH pending AS (
+			SELECT id, recorded_at, device_id, speed, msl_altitude, location, g_force_x, g_force_y, g_force_z
+			FROM telemetry
+			WHERE recorded_at >= $1 AND recorded_at < $2 AND total_g IS NULL
+			LIMIT $3
+		), derived AS (
+			SELECT
+				p.id,
+				p.recorded_at,
+				sqrt(COALESCE(p.g_force_x, 0) ^ 2 + COALESCE(p.g_force_y, 0) ^ 2 + COALESCE(p.g_force_z, 0) ^ 2) AS total_g,
+				p.speed - prev.speed AS speed_delta,
+				(p.msl_altitude - prev.msl_altitude) / EXTRACT(EPOCH FROM p.recorded_at - prev.recorded_at) AS vertical_speed,
+				ST_Distance(p.location, prev.location, false) AS distance_increment
+			FROM pending p
+			LEFT JOIN LATERAL (
+				SELECT speed, msl_altitude, location, recorded_at
+				FROM telemetry q
+				WHERE q.device_id = p.device_id
+					AND q.recorded_at < p.recorded_at
+					AND q.recorded_at >= p.recorded_at - $4 * INTERVAL '1 second'
+					AND q.deletion_id IS NULL
+				ORDER BY q.recorded_at DESC
+				LIMIT 1
+			) prev ON true
+		)
+		UPDATE telemetry t
+		SET total_g = d.total_g,
+			speed_delta = d.speed_delta,
+			vertical_speed = d.vertical_speed,
+			distance_increment = d.distance_increment
+		FROM derived d
+		WHERE t.id = d.id AND t.recorded_at = d.recorded_at
+	`, from, to, limit, models.MaxDerivedGap.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to backfill derived channels: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count backfilled records: %w", err)
+	}
+	return updated, nil
+}
+
+// OldestRecordedAt returns when the oldest stored telemetry was recorded, or nil when there is none
+func (r *PostgresDerivedChannelRepository) OldestRecordedAt(ctx context.Context) (*time.Time, error) {
+	var oldest sql.NullTime
+	if err := r.db.QueryRowContext(ctx, `SELECT MIN(recorded_at) FROM telemetry`).Scan(&oldest); err != nil {
+		return nil, fmt.Errorf("failed to find oldest telemetry: %w", err)
+	}
+	if !oldest.Valid {
+		return nil, nil
+	}
+	return &oldest.Time, nil
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrDeviceAPIKeyNotFound is returned when a device API key is not found
+	ErrDeviceAPIKeyNotFound = errors.New("device API key not found")
+
+	// ErrDeviceAPIKeyRevoked is returned when a device API key has been revoked
+	ErrDeviceAPIKeyRevoked = errors.New("device API key has been revoked")
+)
+
+// deviceAPIKeyColumns lists the device_api_keys columns in scan order
+const deviceAPIKeyColumns = `
+	id, device_id, user_id, name, key_prefix, key_hash,
+	created_at, last_used_at, revoked_at, signing_secret`
+
+// PostgresDeviceAPIKeyRepository implements DeviceAPIKeyRepository using PostgreSQL
+type PostgresDeviceAPIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDeviceAPIKeyRepository creates a new PostgreSQL device API key repository
+func NewPostgresDeviceAPIKeyRepository(db *sql.DB) *PostgresDeviceAPIKeyRepository {
+	return &PostgresDeviceAPIKeyRepository{db: db}
+}
+
+// Create stores a new device API key
+func (r *PostgresDeviceAPIKeyRepository) Create(ctx context.Context, key *models.DeviceAPIKey) error {
+	query := `
+		INSERT INTO device_api_keys (
+			id, device_id, user_id, name, key_prefix, key_hash, created_at, signing_secret
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		key.ID,
+		key.DeviceID,
+		key.UserID,
+		key.Name,
+		key.KeyPrefix,
+		key.KeyHash,
+		key.CreatedAt,
+		sql.NullString{String: key.SigningSecret, Valid: key.SigningSecret != ""},
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert device API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a device API key by its UUID
+func (r *PostgresDeviceAPIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DeviceAPIKey, error) {
+	query := `SELECT ` + deviceAPIKeyColumns + ` FROM device_api_keys WHERE id = $1`
+
+	key, err := scanDeviceAPIKey(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get device API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetByHash retrieves an active (non-revoked) device API key by its hash
+func (r *PostgresDeviceAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.DeviceAPIKey, error) {
+	query := `SELECT ` + deviceAPIKeyColumns + ` FROM device_api_keys WHERE key_hash = $1`
+
+	key, err := scanDeviceAPIKey(r.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get device API key: %w", err)
+	}
+
+	if key.IsRevoked() {
+		return nil, ErrDeviceAPIKeyRevoked
+	}
+
+	return key, nil
+}
+
+// ListByDeviceID retrieves all keys minted for a device, newest first
+func (r *PostgresDeviceAPIKeyRepository) ListByDeviceID(ctx context.Context, deviceID uuid.UUID) ([]*models.DeviceAPIKey, error) {
+	query := `SELECT ` + deviceAPIKeyColumns + `
+		FROM device_api_keys
+		WHERE device_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.DeviceAPIKey
+	for rows.Next() {
+		key, err := scanDeviceAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke marks a device API key as revoked by its ID
+func (r *PostgresDeviceAPIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE device_api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke device API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// Distinguish between a missing key and one that was already revoked
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrDeviceAPIKeyRevoked
+	}
+
+	return nil
+}
+
+// UpdateLastUsed updates the last_used_at timestamp for a key
+func (r *PostgresDeviceAPIKeyRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE device_api_keys SET last_used_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update device API key last used: %w", err)
+	}
+
+	return nil
+}
+
+// scanDeviceAPIKey scans a single device_api_keys row
+func scanDeviceAPIKey(row rowScanner) (*models.DeviceAPIKey, error) {
+	var key models.DeviceAPIKey
+	var name, signingSecret sql.NullString
+	var lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.DeviceID,
+		&key.UserID,
+		&name,
+		&key.KeyPrefix,
+		&key.KeyHash,
+		&key.CreatedAt,
+		&lastUsedAt,
+		&revokedAt,
+		&signingSecret,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if name.Valid {
+		key.Name = &name.String
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	key.SigningSecret = signingSecret.String
+
+	return &key, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDeviceAPIKeyRepository_Lifecycle(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user := createTestUser(t, db, "devicekeys@example.com")
+
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "RACEBOX-KEY-001",
+		UserID:    user.ID,
+		ClaimedAt: time.Now(),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, NewPostgresDeviceRepository(db.DB).Create(ctx, device))
+
+	repo := NewPostgresDeviceAPIKeyRepository(db.DB)
+
+	key := &models.DeviceAPIKey{
+		ID:            uuid.New(),
+		DeviceID:      device.ID,
+		UserID:        user.ID,
+		Name:          stringPtr("Garage logger"),
+		KeyPrefix:     "avtdk_abc123",
+		KeyHash:       "hash-of-key",
+		SigningSecret: "sealed-signing-secret",
+		CreatedAt:     time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, key))
+
+	// Lookup by hash
+	retrieved, err := repo.GetByHash(ctx, "hash-of-key")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, retrieved.ID)
+	assert.Equal(t, "Garage logger", *retrieved.Name)
+	assert.Equal(t, "sealed-signing-secret", retrieved.SigningSecret)
+	assert.Nil(t, retrieved.LastUsedAt)
+
+	// Track usage
+	require.NoError(t, repo.UpdateLastUsed(ctx, key.ID))
+	retrieved, err = repo.GetByID(ctx, key.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, retrieved.LastUsedAt)
+
+	// List by device
+	keys, err := repo.ListByDeviceID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	// Revoke
+	require.NoError(t, repo.Revoke(ctx, key.ID))
+	assert.ErrorIs(t, repo.Revoke(ctx, key.ID), ErrDeviceAPIKeyRevoked)
+	assert.ErrorIs(t, repo.Revoke(ctx, uuid.New()), ErrDeviceAPIKeyNotFound)
+
+	_, err = repo.GetByHash(ctx, "hash-of-key")
+	assert.ErrorIs(t, err, ErrDeviceAPIKeyRevoked)
+
+	_, err = repo.GetByHash(ctx, "unknown-hash")
+	assert.ErrorIs(t, err, ErrDeviceAPIKeyNotFound)
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrDeviceBackfillNotFound is returned when a device backfill is not found
+	ErrDeviceBackfillNotFound = errors.New("device backfill not found")
+
+	// ErrDeviceBackfillInProgress is returned when requesting a backfill for a device that already has one running
+	ErrDeviceBackfillInProgress = errors.New("device backfill already in progress")
+)
+
+// deviceBackfillColumns is the column list used by all device backfill SELECT queries
+const deviceBackfillColumns = `
+	id, device_id, requested_by, status,
+	total_records, assigned_records, error,
+	created_at, updated_at, completed_at
+`
+
+// backfillTarget resolves a backfill's hardware device ID and the device's current owner
+const backfillTarget = `
+	SELECT d.device_id, d.user_id
+	FROM device_backfills b
+	JOIN devices d ON d.id = b.device_id
+	WHERE b.id = $1
+`
+
+// PostgresDeviceBackfillRepository implements DeviceBackfillRepository using PostgreSQL
+type PostgresDeviceBackfillRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDeviceBackfillRepository creates a new PostgreSQL device backfill repository
+func NewPostgresDeviceBackfillRepository(db *sql.DB) *PostgresDeviceBackfillRepository {
+	return &PostgresDeviceBackfillRepository{db: db}
+}
+
+// Create stores a new pending backfill
+func (r *PostgresDeviceBackfillRepository) Create(ctx context.Context, backfill *models.DeviceBackfill) error {
+	if backfill.ID == uuid.Nil {
+		backfill.ID = uuid.New()
+	}
+	backfill.Status = models.BackfillPending
+
+	now := time.Now()
+	backfill.CreatedAt = now
+	backfill.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO device_backfills (id, device_id, requested_by, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, backfill.ID, backfill.DeviceID, backfill.RequestedBy, backfill.Status, backfill.CreatedAt, backfill.UpdatedAt)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrDeviceBackfillInProgress
+		}
+		return fmt.Errorf("failed to insert device backfill: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatest retrieves the device's most recent backfill
+func (r *PostgresDeviceBackfillRepository) GetLatest(ctx context.Context, deviceID uuid.UUID) (*models.DeviceBackfill, error) {
+	query := `SELECT ` + deviceBackfillColumns + ` FROM device_backfills WHERE device_id = $1 ORDER BY created_at DESC LIMIT 1`
+	return r.get(ctx, query, deviceID)
+}
+
+// ListIncomplete returns the IDs of pending or processing backfills, oldest first
+func (r *PostgresDeviceBackfillRepository) ListIncomplete(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id
+		FROM device_backfills
+		WHERE status IN ('pending', 'processing')
+		ORDER BY created_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incomplete device backfills: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan device backfill: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device backfills: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Start marks a pending or processing backfill as processing and counts the records left to assign
+// A backfill resumed after a restart keeps the records it already assigned in its total.
+func (r *PostgresDeviceBackfillRepository) Start(ctx context.Context, id uuid.UUID) (*models.DeviceBackfill, error) {
+	result, err := r.db.ExecContext(ctx, `
+		WITH target AS (`+backfillTarget+`)
+		UPDATE device_backfills
+		SET status = 'processing',
+			total_records = assigned_records + (
+				SELECT COUNT(*)
+				FROM telemetry, target
+				WHERE telemetry.device_id = target.device_id AND telemetry.user_id IS NULL
+			)
+		WHERE id = $1 AND status IN ('pending', 'processing')
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device backfill: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, ErrDeviceBackfillNotFound
+	}
+
+	return r.get(ctx, `SELECT `+deviceBackfillColumns+` FROM device_backfills WHERE id = $1`, id)
+}
+
+// AssignBatch assigns up to limit of the device's anonymous telemetry records to its owner
+// Only records without an owner are touched, so repeating a batch after a crash is harmless.
+func (r *PostgresDeviceBackfillRepository) AssignBatch(ctx context.Context, id uuid.UUID, limit int) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	result, err := tx.ExecContext(ctx, `
+		WITH target AS (`+backfillTarget+`),
+		batch AS (
+			SELECT telemetry.recorded_at, telemetry.id
+			FROM telemetry, target
+			WHERE telemetry.device_id = target.device_id AND telemetry.user_id IS NULL
+			LIMIT $2
+		)
+		UPDATE telemetry
+		SET user_id = target.user_id
+		FROM batch, target
+		WHERE telemetry.recorded_at = batch.recorded_at AND telemetry.id = batch.id
+	`, id, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign telemetry: %w", err)
+	}
+
+	assigned, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE device_backfills SET assigned_records = assigned_records + $2 WHERE id = $1`, id, assigned); err != nil {
+		return 0, fmt.Errorf("failed to record device backfill progress: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return assigned, nil
+}
+
+// Complete assigns the device's anonymous sessions and upload batches and marks the backfill completed
+func (r *PostgresDeviceBackfillRepository) Complete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	for _, table := range []string{"sessions", "upload_batches"} {
+		if _, err := tx.ExecContext(ctx, `
+			WITH target AS (`+backfillTarget+`)
+			UPDATE `+table+`
+			SET user_id = target.user_id
+			FROM target
+			WHERE `+table+`.device_id = target.device_id AND `+table+`.user_id IS NULL
+		`, id); err != nil {
+			return fmt.Errorf("failed to assign %s: %w", table, err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE device_backfills
+		SET status = 'completed', error = NULL, completed_at = NOW()
+		WHERE id = $1 AND status = 'processing'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete device backfill: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrDeviceBackfillNotFound
+	}
+
+	return tx.Commit()
+}
+
+// Fail marks the backfill as failed with the given error
+func (r *PostgresDeviceBackfillRepository) Fail(ctx context.Context, id uuid.UUID, errMsg string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE device_backfills
+		SET status = 'failed', error = $2, completed_at = NOW()
+		WHERE id = $1
+	`, id, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to fail device backfill: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrDeviceBackfillNotFound
+	}
+
+	return nil
+}
+
+// get scans the single backfill selected by query
+func (r *PostgresDeviceBackfillRepository) get(ctx context.Context, query string, args ...interface{}) (*models.DeviceBackfill, error) {
+	var backfill models.DeviceBackfill
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&backfill.ID,
+		&backfill.DeviceID,
+		&backfill.RequestedBy,
+		&backfill.Status,
+		&backfill.TotalRecords,
+		&backfill.AssignedRecords,
+		&backfill.Error,
+		&backfill.CreatedAt,
+		&backfill.UpdatedAt,
+		&backfill.CompletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceBackfillNotFound
+		}
+		return nil, fmt.Errorf("failed to get device backfill: %w", err)
+	}
+
+	return &backfill, nil
+}
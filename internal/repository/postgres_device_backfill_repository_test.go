@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDeviceBackfillRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceBackfillRepository(db.DB)
+	telemetryRepo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "backfill-owner@example.com")
+
+	// The device uploaded anonymously before its owner claimed it
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, telemetryRepo.SaveBatch(ctx, []*models.TelemetryData{
+		createSampleTelemetry(now.Add(-3*time.Minute), "LEGACY-001"),
+		createSampleTelemetry(now.Add(-2*time.Minute), "LEGACY-001"),
+		createSampleTelemetry(now.Add(-1*time.Minute), "LEGACY-001"),
+		createSampleTelemetry(now, "LEGACY-002"),
+	}))
+
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "LEGACY-001",
+		UserID:    user.ID,
+		ClaimedAt: time.Now(),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, NewPostgresDeviceRepository(db.DB).Create(ctx, device))
+
+	_, err := repo.GetLatest(ctx, device.ID)
+	assert.ErrorIs(t, err, ErrDeviceBackfillNotFound)
+
+	backfill := &models.DeviceBackfill{DeviceID: device.ID, RequestedBy: &user.ID}
+	require.NoError(t, repo.Create(ctx, backfill))
+	assert.Equal(t, models.BackfillPending, backfill.Status)
+	assert.ErrorIs(t, repo.Create(ctx, &models.DeviceBackfill{DeviceID: device.ID}), ErrDeviceBackfillInProgress)
+
+	incomplete, err := repo.ListIncomplete(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{backfill.ID}, incomplete)
+
+	started, err := repo.Start(ctx, backfill.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BackfillProcessing, started.Status)
+	assert.Equal(t, int64(3), started.TotalRecords)
+
+	assigned, err := repo.AssignBatch(ctx, backfill.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), assigned)
+
+	// A restart resumes with the assigned records counted in the total
+	resumed, err := repo.Start(ctx, backfill.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resumed.TotalRecords)
+	assert.Equal(t, int64(2), resumed.AssignedRecords)
+
+	assigned, err = repo.AssignBatch(ctx, backfill.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), assigned)
+
+	require.NoError(t, repo.Complete(ctx, backfill.ID))
+
+	latest, err := repo.GetLatest(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BackfillCompleted, latest.Status)
+	assert.Equal(t, int64(3), latest.AssignedRecords)
+	assert.NotNil(t, latest.CompletedAt)
+
+	// Only the claimed device's telemetry changes hands
+	orphans, err := telemetryRepo.OrphanedTelemetry(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, orphans, 1)
+	assert.Equal(t, "LEGACY-002", orphans[0].DeviceID)
+
+	_, err = repo.Start(ctx, backfill.ID)
+	assert.ErrorIs(t, err, ErrDeviceBackfillNotFound)
+
+	// A finished backfill does not block a new request
+	again := &models.DeviceBackfill{DeviceID: device.ID}
+	require.NoError(t, repo.Create(ctx, again))
+	require.NoError(t, repo.Fail(ctx, again.ID, "interrupted"))
+
+	latest, err = repo.GetLatest(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, again.ID, latest.ID)
+	assert.Equal(t, models.BackfillFailed, latest.Status)
+	require.NotNil(t, latest.Error)
+	assert.Equal(t, "interrupted", *latest.Error)
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+const deviceCommandColumns = `id, device_id, type, params, status, COALESCE(message, ''), created_by, created_at, expires_at, delivered_at, acknowledged_at`
+
+// defaultDeviceCommandLimit caps command listings and polls when no limit is given
+const defaultDeviceCommandLimit = 50
+
+// PostgresDeviceCommandRepository implements DeviceCommandRepository using PostgreSQL
+type PostgresDeviceCommandRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDeviceCommandRepository creates a new PostgreSQL device command repository
+func NewPostgresDeviceCommandRepository(db *sql.DB) *PostgresDeviceCommandRepository {
+	return &PostgresDeviceCommandRepository{db: db}
+}
+
+// Create queues a command for its device
+func (r *PostgresDeviceCommandRepository) Create(ctx context.Context, command *models.DeviceCommand) error {
+	if command.ID == uuid.Nil {
+		command.ID = uuid.New()
+	}
+	if command.CreatedAt.IsZero() {
+		command.CreatedAt = time.Now()
+	}
+	if command.Status == "" {
+		command.Status = models.DeviceCommandPending
+	}
+
+	var params []byte
+	if len(command.Params) > 0 {
+		var err error
+		params, err = json.Marshal(command.Params)
+		if err != nil {
+			return fmt.Errorf("failed to encode device command params: %w", err)
+		}
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO device_commands (id, device_id, type, params, status, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, command.ID, command.DeviceID, command.Type, params, command.Status, command.CreatedBy, command.CreatedAt, command.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert device command: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves one of the device's commands
+func (r *PostgresDeviceCommandRepository) Get(ctx context.Context, deviceID, id uuid.UUID) (*models.DeviceCommand, error) {
+	if err := r.expire(ctx, deviceID); err != nil {
+		return nil, err
+	}
+
+	command, err := scanDeviceCommand(r.db.QueryRowContext(ctx,
+		`SELECT `+deviceCommandColumns+` FROM device_commands WHERE device_id = $1 AND id = $2`, deviceID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceCommandNotFound
+		}
+		return nil, fmt.Errorf("failed to get device command: %w", err)
+	}
+
+	return command, nil
+}
+
+// List retrieves the device's commands matching the filter, most recent first
+func (r *PostgresDeviceCommandRepository) List(ctx context.Context, deviceID uuid.UUID, filter DeviceCommandFilter) ([]*models.DeviceCommand, error) {
+	if err := r.expire(ctx, deviceID); err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultDeviceCommandLimit
+	}
+
+	query := `SELECT ` + deviceCommandColumns + ` FROM device_commands WHERE device_id = $1`
+	args := []interface{}{deviceID, limit}
+	if filter.Status != "" {
+		query += ` AND status = $3`
+		args = append(args, filter.Status)
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT $2`
+
+	return r.queryDeviceCommands(ctx, query, args...)
+}
+
+// Poll returns the device's unacknowledged commands, oldest first, and marks them delivered
+func (r *PostgresDeviceCommandRepository) Poll(ctx context.Context, deviceID uuid.UUID, limit int) ([]*models.DeviceCommand, error) {
+	if err := r.expire(ctx, deviceID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeviceCommandLimit
+	}
+
+	commands, err := r.queryDeviceCommands(ctx, `
+		UPDATE device_commands
+		SET status = 'delivered', delivered_at = COALESCE(delivered_at, NOW())
+		WHERE id IN (
+			SELECT id FROM device_commands
+			WHERE device_id = $1 AND status IN ('pending', 'delivered')
+			ORDER BY created_at, id
+			LIMIT $2
+		)
+		RETURNING `+deviceCommandColumns,
+		deviceID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the subquery's order
+	slices.SortFunc(commands, func(a, b *models.DeviceCommand) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return commands, nil
+}
+
+// Acknowledge records the outcome the device reported for a command
+func (r *PostgresDeviceCommandRepository) Acknowledge(ctx context.Context, deviceID, id uuid.UUID, status models.DeviceCommandStatus, message string) (*models.DeviceCommand, error) {
+	return r.finish(ctx, deviceID, id, `
+		UPDATE device_commands
+		SET status = $3, message = NULLIF($4, ''), acknowledged_at = NOW()
+		WHERE device_id = $1 AND id = $2 AND status IN ('pending', 'delivered') AND expires_at > NOW()
+		RETURNING `+deviceCommandColumns,
+		deviceID, id, status, message)
+}
+
+// Cancel withdraws a command the device has not acknowledged
+func (r *PostgresDeviceCommandRepository) Cancel(ctx context.Context, deviceID, id uuid.UUID) (*models.DeviceCommand, error) {
+	return r.finish(ctx, deviceID, id, `
+		UPDATE device_commands
+		SET status = 'cancelled'
+		WHERE device_id = $1 AND id = $2 AND status IN ('pending', 'delivered') AND expires_at > NOW()
+		RETURNING `+deviceCommandColumns,
+		deviceID, id)
+}
+
+// finish runs an update moving an outstanding command to a terminal state
+// When the update matches nothing, the command is looked up to tell a missing command from a finished one.
+func (r *PostgresDeviceCommandRepository) finish(ctx context.Context, deviceID, id uuid.UUID, query string, args ...interface{}) (*models.DeviceCommand, error) {
+	command, err := scanDeviceCommand(r.db.QueryRowContext(ctx, query, args...))
+	if err == nil {
+		return command, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to update device command: %w", err)
+	}
+
+	if _, err := r.Get(ctx, deviceID, id); err != nil {
+		return nil, err
+	}
+	return nil, ErrDeviceCommandFinished
+}
+
+// expire marks the device's outstanding commands past their expiry as expired
+func (r *PostgresDeviceCommandRepository) expire(ctx context.Context, deviceID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE device_commands
+		SET status = 'expired'
+		WHERE device_id = $1 AND status IN ('pending', 'delivered') AND expires_at <= NOW()
+	`, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to expire device commands: %w", err)
+	}
+	return nil
+}
+
+// queryDeviceCommands runs a query returning device command rows
+func (r *PostgresDeviceCommandRepository) queryDeviceCommands(ctx context.Context, query string, args ...interface{}) ([]*models.DeviceCommand, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device commands: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	commands := make([]*models.DeviceCommand, 0)
+	for rows.Next() {
+		command, err := scanDeviceCommand(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device command: %w", err)
+		}
+		commands = append(commands, command)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device commands: %w", err)
+	}
+
+	return commands, nil
+}
+
+// scanDeviceCommand scans a row selected with deviceCommandColumns
+func scanDeviceCommand(row rowScanner) (*models.DeviceCommand, error) {
+	command := &models.DeviceCommand{}
+	var params []byte
+
+	err := row.Scan(
+		&command.ID,
+		&command.DeviceID,
+		&command.Type,
+		&params,
+		&command.Status,
+		&command.Message,
+		&command.CreatedBy,
+		&command.CreatedAt,
+		&command.ExpiresAt,
+		&command.DeliveredAt,
+		&command.AcknowledgedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &command.Params); err != nil {
+			return nil, fmt.Errorf("failed to decode device command params: %w", err)
+		}
+	}
+
+	return command, nil
+}
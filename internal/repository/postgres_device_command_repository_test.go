@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDeviceCommandRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceCommandRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "command-owner@example.com")
+
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "COMMAND-001",
+		UserID:    user.ID,
+		ClaimedAt: time.Now(),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, NewPostgresDeviceRepository(db.DB).Create(ctx, device))
+
+	newCommand := func(commandType models.DeviceCommandType, params map[string]interface{}, createdAt time.Time, ttl time.Duration) *models.DeviceCommand {
+		command := &models.DeviceCommand{
+			DeviceID:  device.ID,
+			Type:      commandType,
+			Params:    params,
+			CreatedBy: &user.ID,
+			CreatedAt: createdAt,
+			ExpiresAt: createdAt.Add(ttl),
+		}
+		require.NoError(t, repo.Create(ctx, command))
+		return command
+	}
+
+	now := time.Now()
+	flush := newCommand(models.DeviceCommandFlushBuffer, nil, now.Add(-2*time.Minute), time.Hour)
+	start := newCommand(models.DeviceCommandStartSession, map[string]interface{}{"name": "Track day"}, now.Add(-time.Minute), time.Hour)
+	stale := newCommand(models.DeviceCommandReboot, nil, now.Add(-2*time.Hour), time.Hour)
+
+	// Polls return outstanding commands oldest first, and return them again until acknowledged
+	for range 2 {
+		polled, err := repo.Poll(ctx, device.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, polled, 2)
+		assert.Equal(t, flush.ID, polled[0].ID)
+		assert.Equal(t, start.ID, polled[1].ID)
+		assert.Equal(t, "Track day", polled[1].Params["name"])
+		assert.Equal(t, models.DeviceCommandDelivered, polled[0].Status)
+		assert.NotNil(t, polled[0].DeliveredAt)
+	}
+
+	expired, err := repo.Get(ctx, device.ID, stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeviceCommandExpired, expired.Status)
+
+	acked, err := repo.Acknowledge(ctx, device.ID, flush.ID, models.DeviceCommandFailed, "buffer empty")
+	require.NoError(t, err)
+	assert.Equal(t, models.DeviceCommandFailed, acked.Status)
+	assert.Equal(t, "buffer empty", acked.Message)
+	assert.NotNil(t, acked.AcknowledgedAt)
+
+	_, err = repo.Acknowledge(ctx, device.ID, flush.ID, models.DeviceCommandSucceeded, "")
+	assert.ErrorIs(t, err, ErrDeviceCommandFinished)
+	_, err = repo.Cancel(ctx, device.ID, stale.ID)
+	assert.ErrorIs(t, err, ErrDeviceCommandFinished)
+	_, err = repo.Cancel(ctx, uuid.New(), start.ID)
+	assert.ErrorIs(t, err, ErrDeviceCommandNotFound)
+
+	cancelled, err := repo.Cancel(ctx, device.ID, start.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeviceCommandCancelled, cancelled.Status)
+
+	polled, err := repo.Poll(ctx, device.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, polled)
+
+	history, err := repo.List(ctx, device.ID, DeviceCommandFilter{})
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, start.ID, history[0].ID)
+
+	history, err = repo.List(ctx, device.ID, DeviceCommandFilter{Status: models.DeviceCommandExpired})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, stale.ID, history[0].ID)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrDeviceConfigNotFound is returned when a device has no desired configuration
+var ErrDeviceConfigNotFound = errors.New("device config not found")
+
+// PostgresDeviceConfigRepository implements DeviceConfigRepository using PostgreSQL
+type PostgresDeviceConfigRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDeviceConfigRepository creates a new PostgreSQL device config repository
+func NewPostgresDeviceConfigRepository(db *sql.DB) *PostgresDeviceConfigRepository {
+	return &PostgresDeviceConfigRepository{db: db}
+}
+
+// Get retrieves the device's desired configuration
+func (r *PostgresDeviceConfigRepository) Get(ctx context.Context, deviceID uuid.UUID) (*models.DeviceConfig, error) {
+	var config models.DeviceConfig
+	err := r.db.QueryRowContext(ctx, `
+		SELECT device_id, sample_rate_hz, upload_interval_seconds, version, updated_by, updated_at
+		FROM device_configs
+		WHERE device_id = $1
+	`, deviceID).Scan(
+		&config.DeviceID,
+		&config.SampleRateHz,
+		&config.UploadIntervalSeconds,
+		&config.Version,
+		&config.UpdatedBy,
+		&config.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceConfigNotFound
+		}
+		return nil, fmt.Errorf("failed to get device config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Put stores the device's desired configuration, incrementing its version
+func (r *PostgresDeviceConfigRepository) Put(ctx context.Context, config *models.DeviceConfig) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO device_configs (device_id, sample_rate_hz, upload_interval_seconds, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (device_id) DO UPDATE SET
+			sample_rate_hz = EXCLUDED.sample_rate_hz,
+			upload_interval_seconds = EXCLUDED.upload_interval_seconds,
+			updated_by = EXCLUDED.updated_by,
+			version = device_configs.version + 1,
+			updated_at = NOW()
+		RETURNING version, updated_at
+	`, config.DeviceID, config.SampleRateHz, config.UploadIntervalSeconds, config.UpdatedBy).Scan(&config.Version, &config.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store device config: %w", err)
+	}
+
+	return nil
+}
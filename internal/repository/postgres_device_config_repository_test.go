@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDeviceConfigRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceConfigRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "config-owner@example.com")
+
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "CONFIG-001",
+		UserID:    user.ID,
+		ClaimedAt: time.Now(),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, NewPostgresDeviceRepository(db.DB).Create(ctx, device))
+
+	_, err := repo.Get(ctx, device.ID)
+	assert.ErrorIs(t, err, ErrDeviceConfigNotFound)
+
+	config := &models.DeviceConfig{DeviceID: device.ID, SampleRateHz: 10, UploadIntervalSeconds: 30, UpdatedBy: &user.ID}
+	require.NoError(t, repo.Put(ctx, config))
+	assert.Equal(t, int64(1), config.Version)
+
+	// Every change gets a new version
+	config = &models.DeviceConfig{DeviceID: device.ID, SampleRateHz: 25, UploadIntervalSeconds: 5, UpdatedBy: &user.ID}
+	require.NoError(t, repo.Put(ctx, config))
+	assert.Equal(t, int64(2), config.Version)
+
+	stored, err := repo.Get(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 25, stored.SampleRateHz)
+	assert.Equal(t, 5, stored.UploadIntervalSeconds)
+	assert.Equal(t, int64(2), stored.Version)
+	assert.Equal(t, `"2"`, stored.ETag())
+}
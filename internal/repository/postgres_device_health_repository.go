@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrDeviceHealthNotFound is returned when no health has been recorded for a device
+var ErrDeviceHealthNotFound = errors.New("device health not found")
+
+// deviceHealthSnapshotColumns is the column list used by all device health snapshot SELECT queries
+const deviceHealthSnapshotColumns = `
+	device_id, bucket_start, samples, fix_samples,
+	battery_min, battery_max, battery_last, is_charging,
+	satellites_sum, horizontal_accuracy_sum,
+	skew_samples, skew_sum_ms, skew_abs_max_ms, skewed_samples, last_recorded_at
+`
+
+// deviceHealthEventColumns is the column list used by all device health event SELECT queries
+const deviceHealthEventColumns = `
+	id, device_id, user_id, event_type,
+	recorded_at, battery, no_fix_since, created_at
+`
+
+// defaultDeviceHealthEventLimit caps event listings when no limit is given
+const defaultDeviceHealthEventLimit = 20
+
+// PostgresDeviceHealthRepository implements DeviceHealthRepository using PostgreSQL
+type PostgresDeviceHealthRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDeviceHealthRepository creates a new PostgreSQL device health repository
+func NewPostgresDeviceHealthRepository(db *sql.DB) *PostgresDeviceHealthRepository {
+	return &PostgresDeviceHealthRepository{db: db}
+}
+
+// GetState retrieves the latest known health of a device
+func (r *PostgresDeviceHealthRepository) GetState(ctx context.Context, deviceID string) (*models.DeviceHealthState, error) {
+	query := `
+		SELECT
+			device_id, user_id, battery, is_charging,
+			last_recorded_at, last_fix_at, no_fix_since,
+			low_battery_alerted, no_fix_alerted,
+			signal_dbm, last_heartbeat_at, updated_at
+		FROM device_health_states
+		WHERE device_id = $1
+	`
+
+	var state models.DeviceHealthState
+	err := r.db.QueryRowContext(ctx, query, deviceID).Scan(
+		&state.DeviceID,
+		&state.UserID,
+		&state.Battery,
+		&state.IsCharging,
+		&state.LastRecordedAt,
+		&state.LastFixAt,
+		&state.NoFixSince,
+		&state.LowBatteryAlerted,
+		&state.NoFixAlerted,
+		&state.SignalDbm,
+		&state.LastHeartbeatAt,
+		&state.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceHealthNotFound
+		}
+		return nil, fmt.Errorf("failed to get device health: %w", err)
+	}
+
+	return &state, nil
+}
+
+// Record stores the new device state, snapshots and events in one transaction
+func (r *PostgresDeviceHealthRepository) Record(ctx context.Context, state *models.DeviceHealthState, snapshots []*models.DeviceHealthSnapshot, events []*models.DeviceHealthEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if state != nil {
+		if state.UpdatedAt.IsZero() {
+			state.UpdatedAt = time.Now()
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO device_health_states (
+				device_id, user_id, battery, is_charging,
+				last_recorded_at, last_fix_at, no_fix_since,
+				low_battery_alerted, no_fix_alerted,
+				signal_dbm, last_heartbeat_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (device_id) DO UPDATE SET
+				user_id = EXCLUDED.user_id,
+				battery = EXCLUDED.battery,
+				is_charging = EXCLUDED.is_charging,
+				last_recorded_at = EXCLUDED.last_recorded_at,
+				last_fix_at = EXCLUDED.last_fix_at,
+				no_fix_since = EXCLUDED.no_fix_since,
+				low_battery_alerted = EXCLUDED.low_battery_alerted,
+				no_fix_alerted = EXCLUDED.no_fix_alerted,
+				signal_dbm = EXCLUDED.signal_dbm,
+				last_heartbeat_at = EXCLUDED.last_heartbeat_at,
+				updated_at = EXCLUDED.updated_at
+			WHERE device_health_states.last_recorded_at <= EXCLUDED.last_recorded_at
+		`,
+			state.DeviceID, state.UserID, state.Battery, state.IsCharging,
+			state.LastRecordedAt, state.LastFixAt, state.NoFixSince,
+			state.LowBatteryAlerted, state.NoFixAlerted,
+			state.SignalDbm, state.LastHeartbeatAt, state.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store device health state: %w", err)
+		}
+	}
+
+	for _, snapshot := range snapshots {
+		// Buckets already holding data are merged so each upload only contributes its own samples
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO device_health_snapshots (
+				device_id, bucket_start, samples, fix_samples,
+				battery_min, battery_max, battery_last, is_charging,
+				satellites_sum, horizontal_accuracy_sum,
+				skew_samples, skew_sum_ms, skew_abs_max_ms, skewed_samples, last_recorded_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			ON CONFLICT (device_id, bucket_start) DO UPDATE SET
+				samples = device_health_snapshots.samples + EXCLUDED.samples,
+				fix_samples = device_health_snapshots.fix_samples + EXCLUDED.fix_samples,
+				battery_min = LEAST(device_health_snapshots.battery_min, EXCLUDED.battery_min),
+				battery_max = GREATEST(device_health_snapshots.battery_max, EXCLUDED.battery_max),
+				battery_last = CASE WHEN EXCLUDED.last_recorded_at >= device_health_snapshots.last_recorded_at
+					THEN EXCLUDED.battery_last ELSE device_health_snapshots.battery_last END,
+				is_charging = CASE WHEN EXCLUDED.last_recorded_at >= device_health_snapshots.last_recorded_at
+					THEN EXCLUDED.is_charging ELSE device_health_snapshots.is_charging END,
+				satellites_sum = device_health_snapshots.satellites_sum + EXCLUDED.satellites_sum,
+				horizontal_accuracy_sum = device_health_snapshots.horizontal_accuracy_sum + EXCLUDED.horizontal_accuracy_sum,
+				skew_samples = device_health_snapshots.skew_samples + EXCLUDED.skew_samples,
+				skew_sum_ms = device_health_snapshots.skew_sum_ms + EXCLUDED.skew_sum_ms,
+				skew_abs_max_ms = GREATEST(device_health_snapshots.skew_abs_max_ms, EXCLUDED.skew_abs_max_ms),
+				skewed_samples = device_health_snapshots.skewed_samples + EXCLUDED.skewed_samples,
+				last_recorded_at = GREATEST(device_health_snapshots.last_recorded_at, EXCLUDED.last_recorded_at)
+		`,
+			snapshot.DeviceID, snapshot.BucketStart, snapshot.Samples, snapshot.FixSamples,
+			snapshot.BatteryMin, snapshot.BatteryMax, snapshot.BatteryLast, snapshot.IsCharging,
+			snapshot.SatellitesSum, snapshot.HorizontalAccuracySum,
+			snapshot.SkewSamples, snapshot.SkewSumMs, snapshot.SkewAbsMaxMs, snapshot.SkewedSamples, snapshot.LastRecordedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store device health snapshot: %w", err)
+		}
+	}
+
+	for _, event := range events {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO device_health_events (
+				device_id, user_id, event_type,
+				recorded_at, battery, no_fix_since
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at
+		`,
+			event.DeviceID, event.UserID, event.Type,
+			event.RecordedAt, event.Battery, event.NoFixSince,
+		).Scan(&event.ID, &event.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert device health event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit device health: %w", err)
+	}
+
+	return nil
+}
+
+// ListSnapshots retrieves a device's snapshots with buckets starting at or after since, oldest first
+func (r *PostgresDeviceHealthRepository) ListSnapshots(ctx context.Context, deviceID string, since time.Time) ([]*models.DeviceHealthSnapshot, error) {
+	query := `
+		SELECT ` + deviceHealthSnapshotColumns + `
+		FROM device_health_snapshots
+		WHERE device_id = $1 AND bucket_start >= $2
+		ORDER BY bucket_start ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, deviceID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device health snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := make([]*models.DeviceHealthSnapshot, 0)
+	for rows.Next() {
+		var snapshot models.DeviceHealthSnapshot
+		if err := rows.Scan(
+			&snapshot.DeviceID,
+			&snapshot.BucketStart,
+			&snapshot.Samples,
+			&snapshot.FixSamples,
+			&snapshot.BatteryMin,
+			&snapshot.BatteryMax,
+			&snapshot.BatteryLast,
+			&snapshot.IsCharging,
+			&snapshot.SatellitesSum,
+			&snapshot.HorizontalAccuracySum,
+			&snapshot.SkewSamples,
+			&snapshot.SkewSumMs,
+			&snapshot.SkewAbsMaxMs,
+			&snapshot.SkewedSamples,
+			&snapshot.LastRecordedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan device health snapshot: %w", err)
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device health snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// ListEvents retrieves a device's most recent health events, newest first
+func (r *PostgresDeviceHealthRepository) ListEvents(ctx context.Context, deviceID string, limit int) ([]*models.DeviceHealthEvent, error) {
+	if limit <= 0 {
+		limit = defaultDeviceHealthEventLimit
+	}
+
+	query := `
+		SELECT ` + deviceHealthEventColumns + `
+		FROM device_health_events
+		WHERE device_id = $1
+		ORDER BY recorded_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, deviceID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device health events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*models.DeviceHealthEvent, 0)
+	for rows.Next() {
+		var event models.DeviceHealthEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.DeviceID,
+			&event.UserID,
+			&event.Type,
+			&event.RecordedAt,
+			&event.Battery,
+			&event.NoFixSince,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan device health event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device health events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDeviceHealthRepository_Record(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceHealthRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "health@example.com")
+
+	_, err := repo.GetState(ctx, "HEALTH-001")
+	assert.ErrorIs(t, err, ErrDeviceHealthNotFound)
+
+	bucket := time.Now().Truncate(time.Hour).UTC()
+	first := &models.DeviceHealthSnapshot{
+		DeviceID:              "HEALTH-001",
+		BucketStart:           bucket,
+		Samples:               10,
+		FixSamples:            8,
+		BatteryMin:            40,
+		BatteryMax:            45,
+		BatteryLast:           40,
+		SatellitesSum:         80,
+		HorizontalAccuracySum: 16,
+		LastRecordedAt:        bucket.Add(5 * time.Minute),
+	}
+	state := &models.DeviceHealthState{
+		DeviceID:       "HEALTH-001",
+		UserID:         user.ID,
+		Battery:        40,
+		LastRecordedAt: bucket.Add(5 * time.Minute),
+	}
+	require.NoError(t, repo.Record(ctx, state, []*models.DeviceHealthSnapshot{first}, nil))
+
+	// A second upload for the same bucket is merged into the existing snapshot
+	noFixSince := bucket.Add(6 * time.Minute)
+	heartbeatAt := bucket.Add(10 * time.Minute)
+	signal := -87
+	second := &models.DeviceHealthSnapshot{
+		DeviceID:       "HEALTH-001",
+		BucketStart:    bucket,
+		Samples:        5,
+		FixSamples:     0,
+		BatteryMin:     18,
+		BatteryMax:     39,
+		BatteryLast:    18,
+		LastRecordedAt: bucket.Add(10 * time.Minute),
+	}
+	event := &models.DeviceHealthEvent{
+		DeviceID:   "HEALTH-001",
+		UserID:     user.ID,
+		Type:       models.DeviceHealthLowBattery,
+		RecordedAt: bucket.Add(9 * time.Minute),
+		Battery:    19,
+	}
+	state = &models.DeviceHealthState{
+		DeviceID:          "HEALTH-001",
+		UserID:            user.ID,
+		Battery:           18,
+		LastRecordedAt:    bucket.Add(10 * time.Minute),
+		NoFixSince:        &noFixSince,
+		LowBatteryAlerted: true,
+		SignalDbm:         &signal,
+		LastHeartbeatAt:   &heartbeatAt,
+	}
+	require.NoError(t, repo.Record(ctx, state, []*models.DeviceHealthSnapshot{second}, []*models.DeviceHealthEvent{event}))
+	assert.NotZero(t, event.ID)
+
+	// A late upload must not rewind the stored state
+	stale := &models.DeviceHealthState{
+		DeviceID:       "HEALTH-001",
+		UserID:         user.ID,
+		Battery:        90,
+		LastRecordedAt: bucket.Add(time.Minute),
+	}
+	require.NoError(t, repo.Record(ctx, stale, nil, nil))
+
+	stored, err := repo.GetState(ctx, "HEALTH-001")
+	require.NoError(t, err)
+	assert.Equal(t, 18.0, stored.Battery)
+	assert.True(t, stored.LowBatteryAlerted)
+	require.NotNil(t, stored.NoFixSince)
+	assert.True(t, stored.NoFixSince.Equal(noFixSince))
+	require.NotNil(t, stored.SignalDbm)
+	assert.Equal(t, -87, *stored.SignalDbm)
+	require.NotNil(t, stored.LastHeartbeatAt)
+	assert.True(t, stored.LastHeartbeatAt.Equal(heartbeatAt))
+
+	snapshots, err := repo.ListSnapshots(ctx, "HEALTH-001", bucket.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, 15, snapshots[0].Samples)
+	assert.Equal(t, 8, snapshots[0].FixSamples)
+	assert.Equal(t, 18.0, snapshots[0].BatteryMin)
+	assert.Equal(t, 45.0, snapshots[0].BatteryMax)
+	assert.Equal(t, 18.0, snapshots[0].BatteryLast)
+	assert.Equal(t, int64(80), snapshots[0].SatellitesSum)
+
+	events, err := repo.ListEvents(ctx, "HEALTH-001", 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.DeviceHealthLowBattery, events[0].Type)
+	assert.Nil(t, events[0].NoFixSince)
+}
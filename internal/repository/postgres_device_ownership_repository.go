@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PostgresDeviceOwnershipRepository implements DeviceOwnershipRepository using PostgreSQL
+type PostgresDeviceOwnershipRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDeviceOwnershipRepository creates a new PostgreSQL device ownership repository
+func NewPostgresDeviceOwnershipRepository(db *sql.DB) *PostgresDeviceOwnershipRepository {
+	return &PostgresDeviceOwnershipRepository{db: db}
+}
+
+// Record appends an ownership change
+func (r *PostgresDeviceOwnershipRepository) Record(ctx context.Context, change *models.DeviceOwnershipChange) error {
+	query := `
+		INSERT INTO device_ownership_history (device_id, action, from_user_id, to_user_id, actor_id, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		change.DeviceID, change.Action, change.FromUserID, change.ToUserID, change.ActorID, change.Reason,
+	).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record ownership change: %w", err)
+	}
+
+	return nil
+}
+
+// ListByDevice retrieves a device's ownership changes, oldest first
+func (r *PostgresDeviceOwnershipRepository) ListByDevice(ctx context.Context, deviceID uuid.UUID) ([]*models.DeviceOwnershipChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, device_id, action, from_user_id, to_user_id, actor_id, reason, created_at
+		FROM device_ownership_history
+		WHERE device_id = $1
+		ORDER BY created_at, id
+	`, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ownership history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]*models.DeviceOwnershipChange, 0)
+	for rows.Next() {
+		var change models.DeviceOwnershipChange
+		var reason sql.NullString
+		if err := rows.Scan(
+			&change.ID,
+			&change.DeviceID,
+			&change.Action,
+			&change.FromUserID,
+			&change.ToUserID,
+			&change.ActorID,
+			&reason,
+			&change.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ownership change: %w", err)
+		}
+		if reason.Valid {
+			change.Reason = &reason.String
+		}
+		changes = append(changes, &change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ownership history: %w", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDeviceOwnershipRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := createTestUser(t, db, "first-owner@example.com")
+	buyer := createTestUser(t, db, "second-owner@example.com")
+	admin := createTestUser(t, db, "ownership-admin@example.com")
+
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "RACEBOX-OWNER-001",
+		UserID:    owner.ID,
+		ClaimedAt: time.Now(),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, NewPostgresDeviceRepository(db.DB).Create(ctx, device))
+
+	repo := NewPostgresDeviceOwnershipRepository(db.DB)
+
+	claim := &models.DeviceOwnershipChange{DeviceID: device.ID, Action: models.OwnershipActionClaim, ToUserID: &owner.ID, ActorID: &owner.ID}
+	require.NoError(t, repo.Record(ctx, claim))
+	assert.NotEqual(t, uuid.Nil, claim.ID)
+	assert.False(t, claim.CreatedAt.IsZero())
+
+	reason := "Sold the car"
+	reassign := &models.DeviceOwnershipChange{
+		DeviceID:   device.ID,
+		Action:     models.OwnershipActionReassign,
+		FromUserID: &owner.ID,
+		ToUserID:   &buyer.ID,
+		ActorID:    &admin.ID,
+		Reason:     &reason,
+	}
+	require.NoError(t, repo.Record(ctx, reassign))
+
+	history, err := repo.ListByDevice(ctx, device.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, models.OwnershipActionClaim, history[0].Action)
+	assert.Nil(t, history[0].FromUserID)
+	assert.Nil(t, history[0].Reason)
+	assert.Equal(t, models.OwnershipActionReassign, history[1].Action)
+	require.NotNil(t, history[1].FromUserID)
+	assert.Equal(t, owner.ID, *history[1].FromUserID)
+	require.NotNil(t, history[1].ActorID)
+	assert.Equal(t, admin.ID, *history[1].ActorID)
+	require.NotNil(t, history[1].Reason)
+	assert.Equal(t, "Sold the car", *history[1].Reason)
+
+	history, err = repo.ListByDevice(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
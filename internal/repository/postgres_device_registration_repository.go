@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrDeviceRegistrationNotFound is returned when a device has not pre-registered
+	ErrDeviceRegistrationNotFound = errors.New("device registration not found")
+
+	// ErrDeviceRegistrationExists is returned when a device pre-registers a second time
+	ErrDeviceRegistrationExists = errors.New("device registration already exists")
+
+	// ErrDeviceRegistrationAdopted is returned when adopting a device that already has an adopter
+	ErrDeviceRegistrationAdopted = errors.New("device registration already adopted")
+)
+
+// PostgresDeviceRegistrationRepository implements DeviceRegistrationRepository using PostgreSQL
+type PostgresDeviceRegistrationRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDeviceRegistrationRepository creates a new PostgreSQL device registration repository
+func NewPostgresDeviceRegistrationRepository(db *sql.DB) *PostgresDeviceRegistrationRepository {
+	return &PostgresDeviceRegistrationRepository{db: db}
+}
+
+// Create stores a new pre-registration
+func (r *PostgresDeviceRegistrationRepository) Create(ctx context.Context, registration *models.DeviceRegistration) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO device_registrations (device_id, claim_code_hash)
+		VALUES ($1, $2)
+		RETURNING created_at
+	`, registration.DeviceID, registration.ClaimCodeHash).Scan(&registration.CreatedAt)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrDeviceRegistrationExists
+		}
+		return fmt.Errorf("failed to create device registration: %w", err)
+	}
+
+	return nil
+}
+
+// GetByDeviceID retrieves the pre-registration of a hardware device ID
+func (r *PostgresDeviceRegistrationRepository) GetByDeviceID(ctx context.Context, deviceID string) (*models.DeviceRegistration, error) {
+	registration := &models.DeviceRegistration{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT device_id, claim_code_hash, created_at, adopted_by, adopted_at, backfilled_at, backfilled_records
+		FROM device_registrations
+		WHERE device_id = $1
+	`, deviceID).Scan(
+		&registration.DeviceID,
+		&registration.ClaimCodeHash,
+		&registration.CreatedAt,
+		&registration.AdoptedBy,
+		&registration.AdoptedAt,
+		&registration.BackfilledAt,
+		&registration.BackfilledRecords,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceRegistrationNotFound
+		}
+		return nil, fmt.Errorf("failed to get device registration: %w", err)
+	}
+
+	return registration, nil
+}
+
+// Adopt marks the registration as adopted by the device's owner and creates the device, in one transaction
+func (r *PostgresDeviceRegistrationRepository) Adopt(ctx context.Context, device *models.Device) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	// Only an unadopted registration can be adopted, so concurrent adoptions cannot both succeed
+	result, err := tx.ExecContext(ctx, `
+		UPDATE device_registrations
+		SET adopted_by = $1, adopted_at = NOW()
+		WHERE device_id = $2 AND adopted_at IS NULL
+	`, device.UserID, device.DeviceID)
+	if err != nil {
+		return fmt.Errorf("failed to adopt device registration: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM device_registrations WHERE device_id = $1)`, device.DeviceID,
+		).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrDeviceRegistrationNotFound
+		}
+		return ErrDeviceRegistrationAdopted
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO devices (
+			id, device_id, user_id, org_id, device_name, device_model,
+			claimed_at, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		device.ID,
+		device.DeviceID,
+		device.UserID,
+		device.OrgID,
+		device.DeviceName,
+		device.DeviceModel,
+		device.ClaimedAt,
+		device.IsActive,
+		device.CreatedAt,
+		device.UpdatedAt,
+	); err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrDeviceExists
+		}
+		return fmt.Errorf("failed to create adopted device: %w", err)
+	}
+
+	if err := appendEvents(ctx, tx, []*models.Device{device}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListPendingBackfills returns adopted devices whose history has not been backfilled yet, oldest first
+func (r *PostgresDeviceRegistrationRepository) ListPendingBackfills(ctx context.Context, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT device_id
+		FROM device_registrations
+		WHERE adopted_at IS NOT NULL AND backfilled_at IS NULL
+		ORDER BY adopted_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending backfills: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	deviceIDs := []string{}
+	for rows.Next() {
+		var deviceID string
+		if err := rows.Scan(&deviceID); err != nil {
+			return nil, fmt.Errorf("failed to scan pending backfill: %w", err)
+		}
+		deviceIDs = append(deviceIDs, deviceID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending backfills: %w", err)
+	}
+
+	return deviceIDs, nil
+}
+
+// Backfill assigns the device's telemetry, sessions and upload batches without an owner to its adopter
+// Everything runs in one transaction so a failed backfill is retried from scratch by the next sweep.
+func (r *PostgresDeviceRegistrationRepository) Backfill(ctx context.Context, deviceID string) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	var adopterID sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT adopted_by
+		FROM device_registrations
+		WHERE device_id = $1 AND adopted_at IS NOT NULL AND backfilled_at IS NULL
+		FOR UPDATE
+	`, deviceID).Scan(&adopterID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrDeviceRegistrationNotFound
+		}
+		return 0, fmt.Errorf("failed to lock device registration: %w", err)
+	}
+
+	// An adopter who deleted their account leaves nothing to backfill
+	var records int64
+	if adopterID.Valid {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE telemetry SET user_id = $1 WHERE device_id = $2 AND user_id IS NULL`, adopterID.String, deviceID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to backfill telemetry: %w", err)
+		}
+		if records, err = result.RowsAffected(); err != nil {
+			return 0, err
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE sessions SET user_id = $1 WHERE device_id = $2 AND user_id IS NULL`, adopterID.String, deviceID); err != nil {
+			return 0, fmt.Errorf("failed to backfill sessions: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE upload_batches SET user_id = $1 WHERE device_id = $2 AND user_id IS NULL`, adopterID.String, deviceID); err != nil {
+			return 0, fmt.Errorf("failed to backfill upload batches: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE device_registrations
+		SET backfilled_at = NOW(), backfilled_records = $1
+		WHERE device_id = $2
+	`, records, deviceID); err != nil {
+		return 0, fmt.Errorf("failed to mark backfill complete: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return records, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDeviceRegistrationRepository_AdoptAndBackfill(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceRegistrationRepository(db.DB)
+	telemetryRepo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "adopter@example.com")
+
+	registration := &models.DeviceRegistration{DeviceID: "ANON-001", ClaimCodeHash: "hash"}
+	require.NoError(t, repo.Create(ctx, registration))
+	assert.False(t, registration.CreatedAt.IsZero())
+	assert.ErrorIs(t, repo.Create(ctx, &models.DeviceRegistration{DeviceID: "ANON-001", ClaimCodeHash: "other"}), ErrDeviceRegistrationExists)
+
+	// The device uploads anonymously before anyone adopts it
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, telemetryRepo.SaveBatch(ctx, []*models.TelemetryData{
+		createSampleTelemetry(now.Add(-2*time.Minute), "ANON-001"),
+		createSampleTelemetry(now.Add(-1*time.Minute), "ANON-001"),
+		createSampleTelemetry(now, "ANON-002"),
+	}))
+
+	pending, err := repo.ListPendingBackfills(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "ANON-001",
+		UserID:    user.ID,
+		ClaimedAt: time.Now(),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, repo.Adopt(ctx, device))
+	assert.ErrorIs(t, repo.Adopt(ctx, device), ErrDeviceRegistrationAdopted)
+	assert.ErrorIs(t, repo.Adopt(ctx, &models.Device{ID: uuid.New(), DeviceID: "ANON-404", UserID: user.ID}), ErrDeviceRegistrationNotFound)
+
+	created, err := NewPostgresDeviceRepository(db.DB).GetByDeviceID(ctx, "ANON-001")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, created.UserID)
+
+	pending, err = repo.ListPendingBackfills(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ANON-001"}, pending)
+
+	records, err := repo.Backfill(ctx, "ANON-001")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), records)
+
+	stored, err := repo.GetByDeviceID(ctx, "ANON-001")
+	require.NoError(t, err)
+	assert.True(t, stored.IsAdopted())
+	assert.Equal(t, user.ID, *stored.AdoptedBy)
+	assert.NotNil(t, stored.BackfilledAt)
+	assert.Equal(t, int64(2), stored.BackfilledRecords)
+
+	// Only the adopted device's telemetry changes hands
+	orphans, err := telemetryRepo.OrphanedTelemetry(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, orphans, 1)
+	assert.Equal(t, "ANON-002", orphans[0].DeviceID)
+
+	_, err = repo.Backfill(ctx, "ANON-001")
+	assert.ErrorIs(t, err, ErrDeviceRegistrationNotFound)
+}
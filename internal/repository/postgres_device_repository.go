@@ -5,10 +5,12 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
 )
 
 var (
@@ -17,6 +19,12 @@ var (
 
 	// ErrDeviceExists is returned when trying to create a device with an existing device_id
 	ErrDeviceExists = errors.New("device already exists")
+
+	// ErrDeviceClaimed is returned when claiming a device that belongs to another user
+	ErrDeviceClaimed = errors.New("device is claimed by another user")
+
+	// ErrDeviceTagNotFound is returned when removing a tag a device does not carry
+	ErrDeviceTagNotFound = errors.New("device tag not found")
 )
 
 // PostgresDeviceRepository implements DeviceRepository using PostgreSQL
@@ -33,10 +41,10 @@ func NewPostgresDeviceRepository(db *sql.DB) *PostgresDeviceRepository {
 func (r *PostgresDeviceRepository) Create(ctx context.Context, device *models.Device) error {
 	query := `
 		INSERT INTO devices (
-			id, device_id, user_id, device_name, device_model,
+			id, device_id, user_id, org_id, device_name, device_model,
 			claimed_at, last_seen_at, is_active, metadata,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			created_at, updated_at, firmware_version, firmware_updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	var metadataJSON []byte
@@ -54,6 +62,7 @@ func (r *PostgresDeviceRepository) Create(ctx context.Context, device *models.De
 		device.ID,
 		device.DeviceID,
 		device.UserID,
+		device.OrgID,
 		device.DeviceName,
 		device.DeviceModel,
 		device.ClaimedAt,
@@ -62,6 +71,8 @@ func (r *PostgresDeviceRepository) Create(ctx context.Context, device *models.De
 		metadataJSON,
 		device.CreatedAt,
 		device.UpdatedAt,
+		device.FirmwareVersion,
+		device.FirmwareUpdatedAt,
 	)
 
 	if err != nil {
@@ -75,78 +86,113 @@ func (r *PostgresDeviceRepository) Create(ctx context.Context, device *models.De
 	return nil
 }
 
-// GetByID retrieves a device by its UUID
-func (r *PostgresDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Device, error) {
-	query := `
-		SELECT 
-			id, device_id, user_id, device_name, device_model,
-			claimed_at, last_seen_at, is_active, metadata,
-			created_at, updated_at
-		FROM devices
-		WHERE id = $1
-	`
-
-	var device models.Device
-	var metadataJSON []byte
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&device.ID,
-		&device.DeviceID,
-		&device.UserID,
-		&device.DeviceName,
-		&device.DeviceModel,
-		&device.ClaimedAt,
-		&device.LastSeenAt,
-		&device.IsActive,
-		&metadataJSON,
-		&device.CreatedAt,
-		&device.UpdatedAt,
-	)
-
+// Claim atomically stores a new device unless its device_id is already taken
+// Concurrent first uploads from a device race to insert it; ON CONFLICT makes the losers read the
+// winner's row instead of failing on the unique index.
+func (r *PostgresDeviceRepository) Claim(ctx context.Context, device *models.Device) (*models.Device, error) {
+	stored, err := r.ClaimAll(ctx, []*models.Device{device})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrDeviceNotFound
-		}
 		return nil, err
 	}
+	if stored[0].UserID != device.UserID {
+		return stored[0], ErrDeviceClaimed
+	}
+	return stored[0], nil
+}
 
-	if len(metadataJSON) > 0 {
-		if err := json.Unmarshal(metadataJSON, &device.Metadata); err != nil {
+// ClaimAll claims devices in a single transaction, like Claim does for one device
+func (r *PostgresDeviceRepository) ClaimAll(ctx context.Context, devices []*models.Device) ([]*models.Device, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	stored := make([]*models.Device, len(devices))
+	var claimed []*models.Device
+	for i, device := range devices {
+		existing, err := claimDevice(ctx, tx, device)
+		if err != nil && !errors.Is(err, ErrDeviceClaimed) {
 			return nil, err
 		}
+		stored[i] = existing
+		if existing.ID == device.ID {
+			claimed = append(claimed, existing)
+		}
+	}
+
+	if err := appendEvents(ctx, tx, claimed); err != nil {
+		return nil, err
 	}
 
-	return &device, nil
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit device claims: %w", err)
+	}
+	return stored, nil
 }
 
-// GetByDeviceID retrieves a device by its hardware device ID
-func (r *PostgresDeviceRepository) GetByDeviceID(ctx context.Context, deviceID string) (*models.Device, error) {
+// claimDevice inserts a device unless its device_id is taken, and returns the stored device
+func claimDevice(ctx context.Context, q queryRower, device *models.Device) (*models.Device, error) {
 	query := `
-		SELECT 
-			id, device_id, user_id, device_name, device_model,
+		INSERT INTO devices (
+			id, device_id, user_id, org_id, device_name, device_model,
 			claimed_at, last_seen_at, is_active, metadata,
-			created_at, updated_at
-		FROM devices
-		WHERE device_id = $1
-	`
+			created_at, updated_at, firmware_version, firmware_updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (device_id) DO NOTHING
+		RETURNING ` + deviceColumns
 
-	var device models.Device
 	var metadataJSON []byte
+	if device.Metadata != nil {
+		var err error
+		metadataJSON, err = json.Marshal(device.Metadata)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	err := r.db.QueryRowContext(ctx, query, deviceID).Scan(
-		&device.ID,
-		&device.DeviceID,
-		&device.UserID,
-		&device.DeviceName,
-		&device.DeviceModel,
-		&device.ClaimedAt,
-		&device.LastSeenAt,
-		&device.IsActive,
-		&metadataJSON,
-		&device.CreatedAt,
-		&device.UpdatedAt,
-	)
+	claimed, err := scanDevice(q.QueryRowContext(
+		ctx,
+		query,
+		device.ID,
+		device.DeviceID,
+		device.UserID,
+		device.OrgID,
+		device.DeviceName,
+		device.DeviceModel,
+		device.ClaimedAt,
+		device.LastSeenAt,
+		device.IsActive,
+		metadataJSON,
+		device.CreatedAt,
+		device.UpdatedAt,
+		device.FirmwareVersion,
+		device.FirmwareUpdatedAt,
+	))
+	if err == nil {
+		return claimed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// The device already exists. Read it in a new statement, which sees the row committed by the
+	// conflicting insert.
+	existing, err := scanDevice(q.QueryRowContext(ctx, `SELECT `+deviceColumns+` FROM devices WHERE device_id = $1`, device.DeviceID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read claimed device: %w", err)
+	}
+	if existing.UserID != device.UserID {
+		return existing, ErrDeviceClaimed
+	}
+	return existing, nil
+}
 
+// GetByID retrieves a device by its UUID
+func (r *PostgresDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Device, error) {
+	device, err := scanDevice(r.db.QueryRowContext(ctx, `SELECT `+deviceColumns+` FROM devices WHERE id = $1`, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrDeviceNotFound
@@ -154,28 +200,25 @@ func (r *PostgresDeviceRepository) GetByDeviceID(ctx context.Context, deviceID s
 		return nil, err
 	}
 
-	if len(metadataJSON) > 0 {
-		if err := json.Unmarshal(metadataJSON, &device.Metadata); err != nil {
-			return nil, err
+	return device, nil
+}
+
+// GetByDeviceID retrieves a device by its hardware device ID
+func (r *PostgresDeviceRepository) GetByDeviceID(ctx context.Context, deviceID string) (*models.Device, error) {
+	device, err := scanDevice(r.db.QueryRowContext(ctx, `SELECT `+deviceColumns+` FROM devices WHERE device_id = $1`, deviceID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceNotFound
 		}
+		return nil, err
 	}
 
-	return &device, nil
+	return device, nil
 }
 
 // ListByUserID retrieves all devices owned by a user
 func (r *PostgresDeviceRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Device, error) {
-	query := `
-		SELECT 
-			id, device_id, user_id, device_name, device_model,
-			claimed_at, last_seen_at, is_active, metadata,
-			created_at, updated_at
-		FROM devices
-		WHERE user_id = $1
-		ORDER BY claimed_at DESC
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := r.db.QueryContext(ctx, `SELECT `+deviceColumns+` FROM devices WHERE user_id = $1 ORDER BY claimed_at DESC`, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -183,40 +226,147 @@ func (r *PostgresDeviceRepository) ListByUserID(ctx context.Context, userID uuid
 
 	var devices []*models.Device
 	for rows.Next() {
-		var device models.Device
-		var metadataJSON []byte
-
-		err := rows.Scan(
-			&device.ID,
-			&device.DeviceID,
-			&device.UserID,
-			&device.DeviceName,
-			&device.DeviceModel,
-			&device.ClaimedAt,
-			&device.LastSeenAt,
-			&device.IsActive,
-			&metadataJSON,
-			&device.CreatedAt,
-			&device.UpdatedAt,
-		)
+		device, err := scanDevice(rows)
 		if err != nil {
 			return nil, err
 		}
+		devices = append(devices, device)
+	}
 
-		if len(metadataJSON) > 0 {
-			if err := json.Unmarshal(metadataJSON, &device.Metadata); err != nil {
-				return nil, err
-			}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// deviceColumns lists the columns read by scanDevice
+const deviceColumns = `id, device_id, user_id, org_id, device_name, device_model,
+	claimed_at, last_seen_at, is_active, metadata,
+	created_at, updated_at, firmware_version, firmware_updated_at, require_signed_uploads`
+
+// deviceSortColumns maps sort keys to their ORDER BY expressions
+var deviceSortColumns = map[DeviceSort]string{
+	DeviceSortClaimedAt:  "claimed_at",
+	DeviceSortLastSeenAt: "last_seen_at",
+	DeviceSortName:       "device_name",
+	DeviceSortDeviceID:   "device_id",
+}
+
+// deviceOrder returns the ORDER BY expressions of a device listing and the condition resuming it
+// after filter.After, or "" without a cursor
+// Claim times are never null, so the claimedAt sort is a keyset; the others order nulls last and
+// ignore cursors.
+func deviceOrder(filter DeviceFilter, bind pagination.Binder) (string, string) {
+	column, ok := deviceSortColumns[filter.Sort]
+	if !ok || filter.Sort == DeviceSortClaimedAt {
+		keyset := pagination.Keyset{TimeColumn: "claimed_at", IDColumn: "id", Ascending: filter.Ascending}
+		return keyset.OrderBy(), keyset.Apply(filter.After, bind)
+	}
+
+	direction := "DESC"
+	if filter.Ascending {
+		direction = "ASC"
+	}
+	return column + " " + direction + " NULLS LAST, id", ""
+}
+
+// List retrieves a page of a user's devices and the total number matching the filter
+func (r *PostgresDeviceRepository) List(ctx context.Context, filter DeviceFilter) ([]*models.Device, int, error) {
+	where := ` WHERE user_id = $1`
+	args := []interface{}{filter.UserID}
+
+	if len(filter.OrgIDs) > 0 {
+		args = append(args, uuidStrings(filter.OrgIDs))
+		where = fmt.Sprintf(` WHERE (user_id = $1 OR org_id = ANY($%d::uuid[]))`, len(args))
+	}
+
+	if filter.IsActive != nil {
+		args = append(args, *filter.IsActive)
+		where += fmt.Sprintf(" AND is_active = $%d", len(args))
+	}
+	if filter.Online != nil {
+		args = append(args, time.Now().Add(-models.DeviceOnlineWindow))
+		if *filter.Online {
+			where += fmt.Sprintf(" AND last_seen_at > $%d", len(args))
+		} else {
+			where += fmt.Sprintf(" AND (last_seen_at IS NULL OR last_seen_at <= $%d)", len(args))
 		}
+	}
 
-		devices = append(devices, &device)
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		where += fmt.Sprintf(" AND id IN (SELECT device_id FROM device_tags WHERE tag = $%d)", len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM devices`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count devices: %w", err)
+	}
+
+	orderBy, after := deviceOrder(filter, postgresBinder(&args))
+	if after != "" {
+		where += " AND " + after
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := `SELECT ` + deviceColumns + ` FROM devices` + where +
+		fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderBy, len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list devices: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	devices := []*models.Device{}
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan device: %w", err)
+		}
+		devices = append(devices, device)
 	}
 
 	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate devices: %w", err)
+	}
+
+	return devices, total, nil
+}
+
+// scanDevice scans a single device row selected with deviceColumns
+func scanDevice(row rowScanner) (*models.Device, error) {
+	device := &models.Device{}
+	var metadataJSON []byte
+
+	if err := row.Scan(
+		&device.ID,
+		&device.DeviceID,
+		&device.UserID,
+		&device.OrgID,
+		&device.DeviceName,
+		&device.DeviceModel,
+		&device.ClaimedAt,
+		&device.LastSeenAt,
+		&device.IsActive,
+		&metadataJSON,
+		&device.CreatedAt,
+		&device.UpdatedAt,
+		&device.FirmwareVersion,
+		&device.FirmwareUpdatedAt,
+		&device.RequireSignedUploads,
+	); err != nil {
 		return nil, err
 	}
 
-	return devices, nil
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &device.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return device, nil
 }
 
 // Update updates a device's information
@@ -229,8 +379,9 @@ func (r *PostgresDeviceRepository) Update(ctx context.Context, device *models.De
 			last_seen_at = $3,
 			is_active = $4,
 			metadata = $5,
-			updated_at = $6
-		WHERE id = $7
+			require_signed_uploads = $6,
+			updated_at = $7
+		WHERE id = $8
 	`
 
 	var metadataJSON []byte
@@ -252,6 +403,7 @@ func (r *PostgresDeviceRepository) Update(ctx context.Context, device *models.De
 		device.LastSeenAt,
 		device.IsActive,
 		metadataJSON,
+		device.RequireSignedUploads,
 		device.UpdatedAt,
 		device.ID,
 	)
@@ -297,6 +449,335 @@ func (r *PostgresDeviceRepository) UpdateLastSeen(ctx context.Context, deviceID
 	return nil
 }
 
+// UpdateFirmware records the firmware version a device reports
+// firmware_updated_at is only moved when the version changes.
+func (r *PostgresDeviceRepository) UpdateFirmware(ctx context.Context, id uuid.UUID, version string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE devices
+		SET firmware_version = $1,
+			firmware_updated_at = CASE WHEN firmware_version IS DISTINCT FROM $1 THEN NOW() ELSE firmware_updated_at END,
+			updated_at = NOW()
+		WHERE id = $2
+	`, version, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// FirmwareDistribution counts active devices per reported firmware version, most common first
+func (r *PostgresDeviceRepository) FirmwareDistribution(ctx context.Context) ([]*models.FirmwareCount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT firmware_version, COUNT(*), COUNT(*) FILTER (WHERE last_seen_at > $1)
+		FROM devices
+		WHERE is_active = TRUE
+		GROUP BY firmware_version
+		ORDER BY COUNT(*) DESC, firmware_version NULLS LAST
+	`, time.Now().Add(-models.DeviceOnlineWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count firmware versions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := []*models.FirmwareCount{}
+	for rows.Next() {
+		count := &models.FirmwareCount{}
+		if err := rows.Scan(&count.Version, &count.Devices, &count.Online); err != nil {
+			return nil, fmt.Errorf("failed to scan firmware count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate firmware counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ListSeenSince retrieves all devices whose last_seen_at is at or after since
+func (r *PostgresDeviceRepository) ListSeenSince(ctx context.Context, since time.Time) ([]*models.Device, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+deviceColumns+` FROM devices WHERE last_seen_at >= $1 ORDER BY last_seen_at DESC`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []*models.Device
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// SetOrganization shares a device with an organization, or makes it personal again when orgID is nil
+func (r *PostgresDeviceRepository) SetOrganization(ctx context.Context, id uuid.UUID, orgID *uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE devices
+		SET org_id = $1, updated_at = NOW()
+		WHERE id = $2
+	`, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// uuidStrings converts UUIDs to strings for uuid[] query parameters
+func uuidStrings(ids []uuid.UUID) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return values
+}
+
+// Reassign transfers a device to another user and revokes its API keys
+// Keys are revoked in the same transaction so the previous owner loses ingest access immediately.
+func (r *PostgresDeviceRepository) Reassign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE devices
+		SET user_id = $1, claimed_at = NOW(), updated_at = NOW()
+		WHERE id = $2
+	`, userID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE device_api_keys
+		SET revoked_at = NOW()
+		WHERE device_id = $1 AND revoked_at IS NULL
+	`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetTags replaces a device's tags
+func (r *PostgresDeviceRepository) SetTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if err := touchDevice(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM device_tags WHERE device_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to clear device tags: %w", err)
+	}
+
+	if len(tags) > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO device_tags (device_id, tag)
+			SELECT $1, unnest($2::text[])
+			ON CONFLICT DO NOTHING
+		`, id, tags); err != nil {
+			return fmt.Errorf("failed to insert device tags: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// AddTag tags a device; adding a tag the device already carries is a no-op
+func (r *PostgresDeviceRepository) AddTag(ctx context.Context, id uuid.UUID, tag string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if err := touchDevice(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO device_tags (device_id, tag)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, id, tag); err != nil {
+		return fmt.Errorf("failed to insert device tag: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// RemoveTag removes a tag from a device, returning ErrDeviceTagNotFound when the device does not carry it
+func (r *PostgresDeviceRepository) RemoveTag(ctx context.Context, id uuid.UUID, tag string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM device_tags WHERE device_id = $1 AND tag = $2`, id, tag)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceTagNotFound
+	}
+
+	return nil
+}
+
+// TagsByDevice retrieves the tags of the given devices in alphabetical order, keyed by device ID
+func (r *PostgresDeviceRepository) TagsByDevice(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	tags := make(map[uuid.UUID][]string)
+	if len(ids) == 0 {
+		return tags, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT device_id, tag
+		FROM device_tags
+		WHERE device_id = ANY($1::uuid[])
+		ORDER BY tag
+	`, uuidStrings(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device tags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanDeviceTags(rows, tags)
+}
+
+// TagCounts counts the devices carrying each tag among those the filter's owner can list
+func (r *PostgresDeviceRepository) TagCounts(ctx context.Context, filter DeviceFilter) ([]*models.DeviceTagCount, error) {
+	where := `d.user_id = $2`
+	args := []interface{}{time.Now().Add(-models.DeviceOnlineWindow), filter.UserID}
+	if len(filter.OrgIDs) > 0 {
+		args = append(args, uuidStrings(filter.OrgIDs))
+		where = `(d.user_id = $2 OR d.org_id = ANY($3::uuid[]))`
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT dt.tag, COUNT(*), COUNT(*) FILTER (WHERE d.is_active), COUNT(*) FILTER (WHERE d.last_seen_at > $1)
+		FROM device_tags dt
+		JOIN devices d ON d.id = dt.device_id
+		WHERE `+where+`
+		GROUP BY dt.tag
+		ORDER BY dt.tag
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count device tags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanDeviceTagCounts(rows)
+}
+
+// touchDevice bumps a device's updated_at within a transaction, returning ErrDeviceNotFound when it does not exist
+// Locking the device row also serializes concurrent tag changes.
+func touchDevice(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	result, err := tx.ExecContext(ctx, `UPDATE devices SET updated_at = $1 WHERE id = $2`, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// scanDeviceTags adds device_id, tag rows to tags
+func scanDeviceTags(rows *sql.Rows, tags map[uuid.UUID][]string) (map[uuid.UUID][]string, error) {
+	for rows.Next() {
+		var id uuid.UUID
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan device tag: %w", err)
+		}
+		tags[id] = append(tags[id], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate device tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// scanDeviceTagCounts scans tag, devices, active, online rows
+func scanDeviceTagCounts(rows *sql.Rows) ([]*models.DeviceTagCount, error) {
+	counts := []*models.DeviceTagCount{}
+	for rows.Next() {
+		count := &models.DeviceTagCount{}
+		if err := rows.Scan(&count.Tag, &count.Devices, &count.Active, &count.Online); err != nil {
+			return nil, fmt.Errorf("failed to scan device tag count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate device tag counts: %w", err)
+	}
+
+	return counts, nil
+}
+
 // isUniqueViolation checks if the error is a PostgreSQL unique constraint violation
 func isUniqueViolation(err error) bool {
 	if err == nil {
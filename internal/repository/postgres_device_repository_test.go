@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -107,6 +108,137 @@ func TestPostgresDeviceRepository_Create_DuplicateDeviceID(t *testing.T) {
 	assert.ErrorIs(t, err, ErrDeviceExists)
 }
 
+func TestPostgresDeviceRepository_Claim(t *testing.T) {
+	db, cleanup := setupDeviceTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceRepository(db.DB)
+	userRepo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	owner := &models.User{ID: uuid.New(), Email: "claim@example.com", PasswordHash: "hash", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	other := &models.User{ID: uuid.New(), Email: "claim-other@example.com", PasswordHash: "hash", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	require.NoError(t, userRepo.Create(ctx, owner))
+	require.NoError(t, userRepo.Create(ctx, other))
+
+	newClaim := func(userID uuid.UUID) *models.Device {
+		now := time.Now()
+		return &models.Device{
+			ID:         uuid.New(),
+			DeviceID:   "RACEBOX-CLAIM",
+			UserID:     userID,
+			ClaimedAt:  now,
+			LastSeenAt: &now,
+			IsActive:   true,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+
+	first := newClaim(owner.ID)
+	claimed, err := repo.Claim(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, claimed.ID)
+
+	// A second claim by the owner returns the existing device
+	claimed, err = repo.Claim(ctx, newClaim(owner.ID))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, claimed.ID)
+
+	// A claim by another user reports the conflict along with the existing device
+	claimed, err = repo.Claim(ctx, newClaim(other.ID))
+	assert.ErrorIs(t, err, ErrDeviceClaimed)
+	require.NotNil(t, claimed)
+	assert.Equal(t, owner.ID, claimed.UserID)
+}
+
+func TestPostgresDeviceRepository_ClaimAll(t *testing.T) {
+	db, cleanup := setupDeviceTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceRepository(db.DB)
+	userRepo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	owner := &models.User{ID: uuid.New(), Email: "bulk@example.com", PasswordHash: "hash", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	other := &models.User{ID: uuid.New(), Email: "bulk-other@example.com", PasswordHash: "hash", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	require.NoError(t, userRepo.Create(ctx, owner))
+	require.NoError(t, userRepo.Create(ctx, other))
+
+	newClaim := func(deviceID string, userID uuid.UUID) *models.Device {
+		now := time.Now()
+		return &models.Device{ID: uuid.New(), DeviceID: deviceID, UserID: userID, ClaimedAt: now, IsActive: true, CreatedAt: now, UpdatedAt: now}
+	}
+
+	owned := newClaim("RACEBOX-BULK-OWNED", owner.ID)
+	taken := newClaim("RACEBOX-BULK-TAKEN", other.ID)
+	require.NoError(t, repo.Create(ctx, owned))
+	require.NoError(t, repo.Create(ctx, taken))
+
+	claims := []*models.Device{
+		newClaim("RACEBOX-BULK-NEW", owner.ID),
+		newClaim(owned.DeviceID, owner.ID),
+		newClaim(taken.DeviceID, owner.ID),
+	}
+	stored, err := repo.ClaimAll(ctx, claims)
+	require.NoError(t, err, "devices of other users do not fail the other claims")
+	require.Len(t, stored, 3)
+	assert.Equal(t, claims[0].ID, stored[0].ID)
+	assert.Equal(t, owned.ID, stored[1].ID)
+	assert.Equal(t, other.ID, stored[2].UserID)
+
+	devices, err := repo.ListByUserID(ctx, owner.ID)
+	require.NoError(t, err)
+	assert.Len(t, devices, 2)
+}
+
+func TestPostgresDeviceRepository_Claim_Concurrent(t *testing.T) {
+	db, cleanup := setupDeviceTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceRepository(db.DB)
+	userRepo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{ID: uuid.New(), Email: "concurrent@example.com", PasswordHash: "hash", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	const claims = 10
+	results := make(chan *models.Device, claims)
+	errs := make(chan error, claims)
+	for range claims {
+		go func() {
+			now := time.Now()
+			device, err := repo.Claim(ctx, &models.Device{
+				ID:        uuid.New(),
+				DeviceID:  "RACEBOX-RACE",
+				UserID:    user.ID,
+				ClaimedAt: now,
+				IsActive:  true,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+			results <- device
+			errs <- err
+		}()
+	}
+
+	// Every claim succeeds and sees the same device
+	var id uuid.UUID
+	for i := range claims {
+		require.NoError(t, <-errs)
+		device := <-results
+		if i == 0 {
+			id = device.ID
+		}
+		assert.Equal(t, id, device.ID)
+	}
+
+	devices, err := repo.ListByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, devices, 1)
+}
+
 func TestPostgresDeviceRepository_GetByID(t *testing.T) {
 	db, cleanup := setupDeviceTestDB(t)
 	defer cleanup()
@@ -303,6 +435,78 @@ func TestPostgresDeviceRepository_ListByUserID_Empty(t *testing.T) {
 	assert.Empty(t, devices)
 }
 
+func TestPostgresDeviceRepository_List(t *testing.T) {
+	db, cleanup := setupDeviceTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceRepository(db.DB)
+	userRepo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{
+		ID:           uuid.New(),
+		Email:        "fleet@example.com",
+		PasswordHash: "hash",
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	recently := time.Now().Add(-5 * time.Minute)
+	longAgo := time.Now().Add(-48 * time.Hour)
+	names := []string{"Charlie", "Alpha", "Bravo"}
+	seen := []*time.Time{&recently, &longAgo, nil}
+	for i, name := range names {
+		require.NoError(t, repo.Create(ctx, &models.Device{
+			ID:         uuid.New(),
+			DeviceID:   fmt.Sprintf("RACEBOX-FLEET-%d", i),
+			UserID:     user.ID,
+			DeviceName: &name,
+			ClaimedAt:  time.Now().Add(-time.Duration(i) * time.Hour),
+			LastSeenAt: seen[i],
+			IsActive:   i != 2,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}))
+	}
+
+	// Newest claim first, paginated
+	devices, total, err := repo.List(ctx, DeviceFilter{UserID: user.ID, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "RACEBOX-FLEET-0", devices[0].DeviceID)
+
+	devices, total, err = repo.List(ctx, DeviceFilter{UserID: user.ID, Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "RACEBOX-FLEET-2", devices[0].DeviceID)
+
+	// Sort by name
+	devices, _, err = repo.List(ctx, DeviceFilter{UserID: user.ID, Sort: DeviceSortName, Ascending: true, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, devices, 3)
+	assert.Equal(t, "Alpha", *devices[0].DeviceName)
+	assert.Equal(t, "Charlie", *devices[2].DeviceName)
+
+	// Filters
+	online, offline, inactive := true, false, false
+	devices, total, err = repo.List(ctx, DeviceFilter{UserID: user.ID, Online: &online, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "RACEBOX-FLEET-0", devices[0].DeviceID)
+
+	_, total, err = repo.List(ctx, DeviceFilter{UserID: user.ID, Online: &offline, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total, "never-seen devices count as offline")
+
+	devices, total, err = repo.List(ctx, DeviceFilter{UserID: user.ID, IsActive: &inactive, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "RACEBOX-FLEET-2", devices[0].DeviceID)
+}
+
 func TestPostgresDeviceRepository_Update(t *testing.T) {
 	db, cleanup := setupDeviceTestDB(t)
 	defer cleanup()
@@ -430,7 +634,138 @@ func TestPostgresDeviceRepository_UpdateLastSeen_NotFound(t *testing.T) {
 	assert.ErrorIs(t, err, ErrDeviceNotFound)
 }
 
+func TestPostgresDeviceRepository_UpdateFirmware(t *testing.T) {
+	db, cleanup := setupDeviceTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "firmware@example.com")
+
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "RACEBOX-FW",
+		UserID:    user.ID,
+		ClaimedAt: time.Now(),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, device))
+
+	require.NoError(t, repo.UpdateFirmware(ctx, device.ID, "2.1.0"))
+	first, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	require.NotNil(t, first.FirmwareVersion)
+	assert.Equal(t, "2.1.0", *first.FirmwareVersion)
+	require.NotNil(t, first.FirmwareUpdatedAt)
+
+	// Reporting the same version again keeps the time it was first reported
+	require.NoError(t, repo.UpdateFirmware(ctx, device.ID, "2.1.0"))
+	again, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.True(t, first.FirmwareUpdatedAt.Equal(*again.FirmwareUpdatedAt))
+
+	err = repo.UpdateFirmware(ctx, uuid.New(), "2.1.0")
+	assert.ErrorIs(t, err, ErrDeviceNotFound)
+}
+
+func TestPostgresDeviceRepository_FirmwareDistribution(t *testing.T) {
+	db, cleanup := setupDeviceTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "fleet@example.com")
+
+	now := time.Now()
+	old := now.Add(-2 * models.DeviceOnlineWindow)
+	v2, v1 := "2.0.0", "1.0.0"
+	devices := []struct {
+		version  *string
+		lastSeen *time.Time
+		active   bool
+	}{
+		{&v2, &now, true},
+		{&v2, &old, true},
+		{&v1, &now, true},
+		{nil, nil, true},
+		{&v1, &now, false}, // Inactive devices are not counted
+	}
+	for i, d := range devices {
+		require.NoError(t, repo.Create(ctx, &models.Device{
+			ID:              uuid.New(),
+			DeviceID:        fmt.Sprintf("RACEBOX-FLEET-%d", i),
+			UserID:          user.ID,
+			ClaimedAt:       now,
+			LastSeenAt:      d.lastSeen,
+			IsActive:        d.active,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+			FirmwareVersion: d.version,
+		}))
+	}
+
+	counts, err := repo.FirmwareDistribution(ctx)
+	require.NoError(t, err)
+	require.Len(t, counts, 3)
+
+	assert.Equal(t, "2.0.0", *counts[0].Version)
+	assert.Equal(t, int64(2), counts[0].Devices)
+	assert.Equal(t, int64(1), counts[0].Online)
+	assert.Equal(t, "1.0.0", *counts[1].Version)
+	assert.Equal(t, int64(1), counts[1].Devices)
+	assert.Nil(t, counts[2].Version)
+	assert.Equal(t, int64(1), counts[2].Devices)
+}
+
 // setupDeviceTestDB creates a test database with the necessary tables
+func TestPostgresDeviceRepository_Reassign(t *testing.T) {
+	db, cleanup := setupDeviceTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresDeviceRepository(db.DB)
+	keyRepo := NewPostgresDeviceAPIKeyRepository(db.DB)
+	ctx := context.Background()
+
+	previousOwner := createTestUser(t, db, "previous@example.com")
+	newOwner := createTestUser(t, db, "new@example.com")
+
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "RACEBOX-MOVE",
+		UserID:    previousOwner.ID,
+		ClaimedAt: time.Now().Add(-24 * time.Hour),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, device))
+
+	key := &models.DeviceAPIKey{
+		ID:        uuid.New(),
+		DeviceID:  device.ID,
+		UserID:    previousOwner.ID,
+		KeyPrefix: "avtdk_move",
+		KeyHash:   "reassign-hash",
+		CreatedAt: time.Now(),
+	}
+	require.NoError(t, keyRepo.Create(ctx, key))
+
+	require.NoError(t, repo.Reassign(ctx, device.ID, newOwner.ID))
+
+	retrieved, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, newOwner.ID, retrieved.UserID)
+	assert.True(t, retrieved.ClaimedAt.After(device.ClaimedAt))
+
+	_, err = keyRepo.GetByHash(ctx, "reassign-hash")
+	assert.ErrorIs(t, err, ErrDeviceAPIKeyRevoked)
+
+	err = repo.Reassign(ctx, uuid.New(), newOwner.ID)
+	assert.ErrorIs(t, err, ErrDeviceNotFound)
+}
+
 func setupDeviceTestDB(t *testing.T) (*database.DB, func()) {
 	t.Helper()
 	return setupTestDB(t)
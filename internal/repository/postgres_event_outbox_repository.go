@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrEventNotFound is returned when an outbox event is not found
+var ErrEventNotFound = errors.New("event not found")
+
+// PostgresEventOutboxRepository implements EventOutboxRepository using PostgreSQL
+type PostgresEventOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresEventOutboxRepository creates a new PostgreSQL event outbox repository
+func NewPostgresEventOutboxRepository(db *sql.DB) *PostgresEventOutboxRepository {
+	return &PostgresEventOutboxRepository{db: db}
+}
+
+// Append stores events for delivery within tx, setting their IDs
+func (r *PostgresEventOutboxRepository) Append(ctx context.Context, tx *sql.Tx, events ...*models.Event) error {
+	return insertEvents(ctx, tx, events)
+}
+
+// insertEvents writes events to the outbox within tx, setting their IDs
+func insertEvents(ctx context.Context, tx *sql.Tx, events []*models.Event) error {
+	query := `
+		INSERT INTO event_outbox (event_type, payload, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	for _, event := range events {
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = time.Now()
+		}
+		if err := tx.QueryRowContext(ctx, query, event.Type, []byte(event.Payload), event.CreatedAt).Scan(&event.ID); err != nil {
+			return fmt.Errorf("failed to insert %s event: %w", event.Type, err)
+		}
+	}
+	return nil
+}
+
+// ClaimDue returns up to limit undelivered events whose next attempt is due, oldest first
+// SKIP LOCKED lets several service instances dispatch without claiming the same events.
+func (r *PostgresEventOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.Event, error) {
+	query := `
+		UPDATE event_outbox
+		SET attempts = attempts + 1, next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM event_outbox
+			WHERE delivered_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, payload, created_at, attempts
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due events: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	events := make([]*models.Event, 0)
+	for rows.Next() {
+		var event models.Event
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.Type, &payload, &event.CreatedAt, &event.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.Payload = payload
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events: %w", err)
+	}
+
+	// UPDATE ... RETURNING does not keep the subquery's order
+	slices.SortFunc(events, func(a, b *models.Event) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return events, nil
+}
+
+// MarkDelivered records that every subscriber handled the event
+func (r *PostgresEventOutboxRepository) MarkDelivered(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE event_outbox SET delivered_at = NOW(), last_error = NULL WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark event delivered: %w", err)
+	}
+	return eventAffected(result)
+}
+
+// MarkFailed records a failed delivery, retried at retryAt
+func (r *PostgresEventOutboxRepository) MarkFailed(ctx context.Context, id int64, errMsg string, retryAt *time.Time) error {
+	query := `
+		UPDATE event_outbox
+		SET last_error = $2, next_attempt_at = COALESCE($3, 'infinity'::timestamptz)
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, errMsg, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark event failed: %w", err)
+	}
+	return eventAffected(result)
+}
+
+// DeleteDelivered deletes events delivered before the given time, returning how many were deleted
+func (r *PostgresEventOutboxRepository) DeleteDelivered(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM event_outbox WHERE delivered_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete delivered events: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// eventAffected maps an UPDATE that matched no event to ErrEventNotFound
+func eventAffected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrEventNotFound
+	}
+	return nil
+}
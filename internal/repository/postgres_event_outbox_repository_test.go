@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresEventOutboxRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresEventOutboxRepository(db.DB)
+	ctx := context.Background()
+
+	first, err := models.NewEvent(models.EventDeviceClaimed, models.DeviceClaimed{DeviceID: "AVT-001"})
+	require.NoError(t, err)
+	second, err := models.NewEvent(models.EventSessionEnded, models.SessionEnded{DeviceID: "AVT-001"})
+	require.NoError(t, err)
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.Append(ctx, tx, first, second))
+	require.NoError(t, tx.Commit())
+	assert.NotZero(t, first.ID)
+	assert.Greater(t, second.ID, first.ID)
+
+	claimed, err := repo.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, first.ID, claimed[0].ID)
+	assert.Equal(t, models.EventDeviceClaimed, claimed[0].Type)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	var payload models.DeviceClaimed
+	require.NoError(t, claimed[0].Decode(&payload))
+	assert.Equal(t, "AVT-001", payload.DeviceID)
+
+	// Leased events are not claimed again
+	claimed, err = repo.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	require.NoError(t, repo.MarkDelivered(ctx, first.ID))
+	retryAt := time.Now().Add(-time.Second)
+	require.NoError(t, repo.MarkFailed(ctx, second.ID, "webhook returned status 500", &retryAt))
+
+	claimed, err = repo.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "only the failed event is retried")
+	assert.Equal(t, second.ID, claimed[0].ID)
+	assert.Equal(t, 2, claimed[0].Attempts)
+
+	// Abandoned events are never claimed again
+	require.NoError(t, repo.MarkFailed(ctx, second.ID, "giving up", nil))
+	claimed, err = repo.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	assert.ErrorIs(t, repo.MarkDelivered(ctx, 0), ErrEventNotFound)
+
+	deleted, err := repo.DeleteDelivered(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestPostgresEventOutboxRepository_StoredWithChange(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	outbox := NewPostgresEventOutboxRepository(db.DB)
+	telemetry := NewPostgresRepository(db)
+	ctx := context.Background()
+	deviceID := "OUTBOX-" + time.Now().Format("150405.000000")
+
+	batchSaved := func(err error) func([]*models.TelemetryData) ([]*models.Event, error) {
+		return func(stored []*models.TelemetryData) ([]*models.Event, error) {
+			if err != nil {
+				return nil, err
+			}
+			event, err := models.NewEvent(models.EventTelemetryBatchSaved, models.TelemetryBatchSaved{DeviceID: deviceID, Records: len(stored)})
+			return []*models.Event{event}, err
+		}
+	}
+
+	// An event that cannot be stored rolls the change back
+	record := &models.TelemetryData{DeviceID: deviceID, Timestamp: time.Now().UTC().Truncate(time.Millisecond)}
+	failing := WithEvents(ctx, batchSaved(errors.New("payload")))
+	require.Error(t, telemetry.SaveBatch(failing, []*models.TelemetryData{record}))
+	stored, err := telemetry.GetByDevice(ctx, deviceID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+
+	require.NoError(t, telemetry.SaveBatch(WithEvents(ctx, batchSaved(nil)), []*models.TelemetryData{record}))
+	claimed, err := outbox.ClaimDue(ctx, 100, time.Minute)
+	require.NoError(t, err)
+	var batches []models.TelemetryBatchSaved
+	for _, event := range claimed {
+		var payload models.TelemetryBatchSaved
+		require.NoError(t, event.Decode(&payload))
+		if payload.DeviceID == deviceID {
+			batches = append(batches, payload)
+		}
+	}
+	require.Len(t, batches, 1, "the event is stored with the change")
+	assert.Equal(t, 1, batches[0].Records)
+}
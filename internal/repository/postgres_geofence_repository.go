@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrGeofenceNotFound is returned when a geofence is not found
+var ErrGeofenceNotFound = errors.New("geofence not found")
+
+// geofenceColumns is the column list used by all geofence SELECT queries
+const geofenceColumns = `
+	id, user_id, name, kind,
+	center_lat, center_lon, radius_m, polygon,
+	notify_email, webhook_url, is_active,
+	created_at, updated_at
+`
+
+// geofenceEventColumns is the column list used by all geofence event SELECT queries
+const geofenceEventColumns = `
+	id, geofence_id, user_id, device_id, event_type,
+	recorded_at, latitude, longitude, created_at
+`
+
+// defaultGeofenceEventLimit caps event listings when no limit is given
+const defaultGeofenceEventLimit = 100
+
+// PostgresGeofenceRepository implements GeofenceRepository using PostgreSQL
+type PostgresGeofenceRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresGeofenceRepository creates a new PostgreSQL geofence repository
+func NewPostgresGeofenceRepository(db *sql.DB) *PostgresGeofenceRepository {
+	return &PostgresGeofenceRepository{db: db}
+}
+
+// Create stores a new geofence
+func (r *PostgresGeofenceRepository) Create(ctx context.Context, geofence *models.Geofence) error {
+	query := `
+		INSERT INTO geofences (
+			id, user_id, name, kind,
+			center_lat, center_lon, radius_m, polygon,
+			notify_email, webhook_url, is_active,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	if geofence.ID == uuid.Nil {
+		geofence.ID = uuid.New()
+	}
+
+	now := time.Now()
+	if geofence.CreatedAt.IsZero() {
+		geofence.CreatedAt = now
+	}
+	if geofence.UpdatedAt.IsZero() {
+		geofence.UpdatedAt = now
+	}
+
+	var centerLat, centerLon sql.NullFloat64
+	if geofence.Center != nil {
+		centerLat = sql.NullFloat64{Float64: geofence.Center.Latitude, Valid: true}
+		centerLon = sql.NullFloat64{Float64: geofence.Center.Longitude, Valid: true}
+	}
+
+	var polygonJSON []byte
+	if len(geofence.Polygon) > 0 {
+		var err error
+		polygonJSON, err = json.Marshal(geofence.Polygon)
+		if err != nil {
+			return fmt.Errorf("failed to encode polygon: %w", err)
+		}
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		geofence.ID,
+		geofence.UserID,
+		geofence.Name,
+		geofence.Kind,
+		centerLat,
+		centerLon,
+		geofence.RadiusMeters,
+		polygonJSON,
+		geofence.NotifyEmail,
+		geofence.WebhookURL,
+		geofence.IsActive,
+		geofence.CreatedAt,
+		geofence.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert geofence: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a geofence by its UUID
+func (r *PostgresGeofenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Geofence, error) {
+	query := `SELECT ` + geofenceColumns + ` FROM geofences WHERE id = $1`
+
+	geofence, err := scanGeofence(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGeofenceNotFound
+		}
+		return nil, fmt.Errorf("failed to get geofence: %w", err)
+	}
+
+	return geofence, nil
+}
+
+// ListByUserID retrieves all geofences owned by a user, most recent first
+func (r *PostgresGeofenceRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Geofence, error) {
+	return r.list(ctx, `WHERE user_id = $1`, userID)
+}
+
+// ListActiveByUserID retrieves the user's active geofences for evaluation
+func (r *PostgresGeofenceRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Geofence, error) {
+	return r.list(ctx, `WHERE user_id = $1 AND is_active = TRUE`, userID)
+}
+
+// list retrieves geofences matching the where clause, most recent first
+func (r *PostgresGeofenceRepository) list(ctx context.Context, where string, args ...interface{}) ([]*models.Geofence, error) {
+	query := `SELECT ` + geofenceColumns + ` FROM geofences ` + where + ` ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query geofences: %w", err)
+	}
+	defer rows.Close()
+
+	geofences := make([]*models.Geofence, 0)
+	for rows.Next() {
+		geofence, err := scanGeofence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan geofence row: %w", err)
+		}
+		geofences = append(geofences, geofence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating geofence rows: %w", err)
+	}
+
+	return geofences, nil
+}
+
+// Delete removes a geofence together with its states and events
+func (r *PostgresGeofenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM geofences WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete geofence: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrGeofenceNotFound
+	}
+
+	return nil
+}
+
+// GetStates returns the last known inside/outside state of a device for each geofence that has one
+func (r *PostgresGeofenceRepository) GetStates(ctx context.Context, deviceID string, geofenceIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	states := make(map[uuid.UUID]bool, len(geofenceIDs))
+	if len(geofenceIDs) == 0 {
+		return states, nil
+	}
+
+	ids := make([]string, len(geofenceIDs))
+	for i, id := range geofenceIDs {
+		ids[i] = id.String()
+	}
+
+	query := `
+		SELECT geofence_id, inside
+		FROM geofence_states
+		WHERE device_id = $1 AND geofence_id = ANY($2::uuid[])
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, deviceID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query geofence states: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var inside bool
+		if err := rows.Scan(&id, &inside); err != nil {
+			return nil, fmt.Errorf("failed to scan geofence state: %w", err)
+		}
+		states[id] = inside
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating geofence states: %w", err)
+	}
+
+	return states, nil
+}
+
+// RecordTransitions stores the new device states and the events they produced in one transaction
+func (r *PostgresGeofenceRepository) RecordTransitions(ctx context.Context, states []models.GeofenceState, events []*models.GeofenceEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, state := range states {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO geofence_states (geofence_id, device_id, inside, recorded_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (geofence_id, device_id) DO UPDATE
+			SET inside = EXCLUDED.inside, recorded_at = EXCLUDED.recorded_at
+			WHERE geofence_states.recorded_at <= EXCLUDED.recorded_at
+		`, state.GeofenceID, state.DeviceID, state.Inside, state.RecordedAt)
+		if err != nil {
+			return fmt.Errorf("failed to store geofence state: %w", err)
+		}
+	}
+
+	for _, event := range events {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO geofence_events (
+				geofence_id, user_id, device_id, event_type,
+				recorded_at, latitude, longitude
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at
+		`,
+			event.GeofenceID, event.UserID, event.DeviceID, event.Type,
+			event.RecordedAt, event.Latitude, event.Longitude,
+		).Scan(&event.ID, &event.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert geofence event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit geofence transitions: %w", err)
+	}
+
+	return nil
+}
+
+// ListEvents retrieves geofence events matching the filter, most recent first
+func (r *PostgresGeofenceRepository) ListEvents(ctx context.Context, filter GeofenceEventFilter) ([]*models.GeofenceEvent, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultGeofenceEventLimit
+	}
+
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{filter.UserID}
+
+	if filter.GeofenceID != nil {
+		args = append(args, *filter.GeofenceID)
+		conditions = append(conditions, fmt.Sprintf("geofence_id = $%d", len(args)))
+	}
+	if filter.DeviceID != "" {
+		args = append(args, filter.DeviceID)
+		conditions = append(conditions, fmt.Sprintf("device_id = $%d", len(args)))
+	}
+
+	args = append(args, limit)
+	query := `
+		SELECT ` + geofenceEventColumns + `
+		FROM geofence_events
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY recorded_at DESC, id DESC
+		LIMIT $` + fmt.Sprint(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query geofence events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*models.GeofenceEvent, 0)
+	for rows.Next() {
+		var event models.GeofenceEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.GeofenceID,
+			&event.UserID,
+			&event.DeviceID,
+			&event.Type,
+			&event.RecordedAt,
+			&event.Latitude,
+			&event.Longitude,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan geofence event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating geofence events: %w", err)
+	}
+
+	return events, nil
+}
+
+// scanGeofence scans a single geofence row selected with geofenceColumns
+func scanGeofence(row rowScanner) (*models.Geofence, error) {
+	var geofence models.Geofence
+	var centerLat, centerLon sql.NullFloat64
+	var polygonJSON []byte
+
+	err := row.Scan(
+		&geofence.ID,
+		&geofence.UserID,
+		&geofence.Name,
+		&geofence.Kind,
+		&centerLat,
+		&centerLon,
+		&geofence.RadiusMeters,
+		&polygonJSON,
+		&geofence.NotifyEmail,
+		&geofence.WebhookURL,
+		&geofence.IsActive,
+		&geofence.CreatedAt,
+		&geofence.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if centerLat.Valid && centerLon.Valid {
+		geofence.Center = &geo.Point{Latitude: centerLat.Float64, Longitude: centerLon.Float64}
+	}
+	if len(polygonJSON) > 0 {
+		if err := json.Unmarshal(polygonJSON, &geofence.Polygon); err != nil {
+			return nil, fmt.Errorf("failed to decode polygon: %w", err)
+		}
+	}
+
+	return &geofence, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresGeofenceRepository_CreateGetListDelete(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresGeofenceRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "geofences@example.com")
+
+	radius := 150.0
+	webhook := "https://example.com/hooks/avt"
+	circle := &models.Geofence{
+		UserID:       user.ID,
+		Name:         "Paddock",
+		Kind:         models.GeofenceCircle,
+		Center:       &geo.Point{Latitude: 42.6977, Longitude: 23.3219},
+		RadiusMeters: &radius,
+		NotifyEmail:  true,
+		WebhookURL:   &webhook,
+		IsActive:     true,
+		CreatedAt:    time.Now().Add(-time.Hour),
+	}
+	polygon := &models.Geofence{
+		UserID: user.ID,
+		Name:   "Pit Lane",
+		Kind:   models.GeofencePolygon,
+		Polygon: []geo.Point{
+			{Latitude: 42.69, Longitude: 23.32},
+			{Latitude: 42.70, Longitude: 23.32},
+			{Latitude: 42.70, Longitude: 23.33},
+		},
+		IsActive: false,
+	}
+	require.NoError(t, repo.Create(ctx, circle))
+	require.NoError(t, repo.Create(ctx, polygon))
+	assert.NotEqual(t, uuid.Nil, circle.ID)
+
+	retrieved, err := repo.GetByID(ctx, circle.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Paddock", retrieved.Name)
+	require.NotNil(t, retrieved.Center)
+	assert.InDelta(t, 42.6977, retrieved.Center.Latitude, 1e-9)
+	require.NotNil(t, retrieved.WebhookURL)
+	assert.Equal(t, webhook, *retrieved.WebhookURL)
+
+	retrieved, err = repo.GetByID(ctx, polygon.ID)
+	require.NoError(t, err)
+	assert.Len(t, retrieved.Polygon, 3)
+	assert.Nil(t, retrieved.Center)
+
+	all, err := repo.ListByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, polygon.ID, all[0].ID, "most recent geofence first")
+
+	active, err := repo.ListActiveByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, circle.ID, active[0].ID)
+
+	require.NoError(t, repo.Delete(ctx, polygon.ID))
+	_, err = repo.GetByID(ctx, polygon.ID)
+	assert.ErrorIs(t, err, ErrGeofenceNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, polygon.ID), ErrGeofenceNotFound)
+}
+
+func TestPostgresGeofenceRepository_Transitions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresGeofenceRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "transitions@example.com")
+
+	radius := 100.0
+	fence := &models.Geofence{
+		UserID:       user.ID,
+		Name:         "Paddock",
+		Kind:         models.GeofenceCircle,
+		Center:       &geo.Point{Latitude: 42.6977, Longitude: 23.3219},
+		RadiusMeters: &radius,
+		IsActive:     true,
+	}
+	require.NoError(t, repo.Create(ctx, fence))
+
+	states, err := repo.GetStates(ctx, "device-1", []uuid.UUID{fence.ID})
+	require.NoError(t, err)
+	assert.Empty(t, states)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.RecordTransitions(ctx,
+		[]models.GeofenceState{{GeofenceID: fence.ID, DeviceID: "device-1", Inside: true, RecordedAt: now}},
+		[]*models.GeofenceEvent{{
+			GeofenceID: fence.ID, UserID: user.ID, DeviceID: "device-1",
+			Type: models.GeofenceEnter, RecordedAt: now, Latitude: 42.6977, Longitude: 23.3219,
+		}},
+	))
+
+	// An older state must not overwrite the newer one
+	require.NoError(t, repo.RecordTransitions(ctx,
+		[]models.GeofenceState{{GeofenceID: fence.ID, DeviceID: "device-1", Inside: false, RecordedAt: now.Add(-time.Minute)}},
+		nil,
+	))
+
+	states, err = repo.GetStates(ctx, "device-1", []uuid.UUID{fence.ID})
+	require.NoError(t, err)
+	assert.True(t, states[fence.ID])
+
+	events, err := repo.ListEvents(ctx, GeofenceEventFilter{UserID: user.ID, GeofenceID: &fence.ID})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.GeofenceEnter, events[0].Type)
+	assert.NotZero(t, events[0].ID)
+
+	events, err = repo.ListEvents(ctx, GeofenceEventFilter{UserID: user.ID, DeviceID: "other-device"})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrImportJobNotFound is returned when an import job is not found
+var ErrImportJobNotFound = errors.New("import job not found")
+
+// importJobColumns is the column list used by all import job SELECT queries
+const importJobColumns = `
+	id, user_id, session_id, device_id, filename, status,
+	total_rows, imported_rows, error,
+	created_at, updated_at, completed_at
+`
+
+// PostgresImportJobRepository implements ImportJobRepository using PostgreSQL
+type PostgresImportJobRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresImportJobRepository creates a new PostgreSQL import job repository
+func NewPostgresImportJobRepository(db *sql.DB) *PostgresImportJobRepository {
+	return &PostgresImportJobRepository{db: db}
+}
+
+// Create stores a new import job
+func (r *PostgresImportJobRepository) Create(ctx context.Context, job *models.ImportJob) error {
+	query := `
+		INSERT INTO import_jobs (
+			id, user_id, session_id, device_id, filename, status,
+			total_rows, imported_rows, error,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if job.Status == "" {
+		job.Status = models.ImportPending
+	}
+
+	now := time.Now()
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = now
+	}
+	if job.UpdatedAt.IsZero() {
+		job.UpdatedAt = now
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		job.ID,
+		job.UserID,
+		job.SessionID,
+		job.DeviceID,
+		job.Filename,
+		job.Status,
+		job.TotalRows,
+		job.ImportedRows,
+		job.Error,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert import job: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an import job by its UUID
+func (r *PostgresImportJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ImportJob, error) {
+	query := `SELECT ` + importJobColumns + ` FROM import_jobs WHERE id = $1`
+
+	var job models.ImportJob
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&job.ID,
+		&job.UserID,
+		&job.SessionID,
+		&job.DeviceID,
+		&job.Filename,
+		&job.Status,
+		&job.TotalRows,
+		&job.ImportedRows,
+		&job.Error,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.CompletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// UpdateProgress records the job's status and the number of rows stored so far
+func (r *PostgresImportJobRepository) UpdateProgress(ctx context.Context, id uuid.UUID, status models.ImportStatus, importedRows int, errMsg *string) error {
+	query := `
+		UPDATE import_jobs
+		SET status = $2,
+			imported_rows = $3,
+			error = $4,
+			completed_at = CASE WHEN $5 THEN NOW() ELSE NULL END
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, status, importedRows, errMsg, status.IsFinished())
+	if err != nil {
+		return fmt.Errorf("failed to update import job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrImportJobNotFound
+	}
+
+	return nil
+}
+
+// FailIncomplete marks every pending or processing job as failed, returning how many were affected
+func (r *PostgresImportJobRepository) FailIncomplete(ctx context.Context, errMsg string) (int64, error) {
+	query := `
+		UPDATE import_jobs
+		SET status = 'failed', error = $1, completed_at = NOW()
+		WHERE status IN ('pending', 'processing')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, errMsg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail incomplete import jobs: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresImportJobRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresImportJobRepository(db.DB)
+	sessionRepo := NewPostgresSessionRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "import@example.com")
+
+	newJob := func() *models.ImportJob {
+		session := &models.Session{DeviceID: "RACEBOX-IMPORT", UserID: &user.ID, StartedAt: time.Now().UTC().Truncate(time.Second)}
+		require.NoError(t, sessionRepo.Create(ctx, session))
+
+		job := &models.ImportJob{
+			UserID:    user.ID,
+			SessionID: session.ID,
+			DeviceID:  session.DeviceID,
+			Filename:  "serres.csv",
+			TotalRows: 3000,
+		}
+		require.NoError(t, repo.Create(ctx, job))
+		return job
+	}
+
+	job := newJob()
+	got, err := repo.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportPending, got.Status)
+	assert.Equal(t, 3000, got.TotalRows)
+	assert.Equal(t, "serres.csv", got.Filename)
+	assert.Nil(t, got.CompletedAt)
+
+	require.NoError(t, repo.UpdateProgress(ctx, job.ID, models.ImportProcessing, 1000, nil))
+	got, err = repo.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportProcessing, got.Status)
+	assert.Equal(t, 1000, got.ImportedRows)
+	assert.Nil(t, got.CompletedAt)
+
+	require.NoError(t, repo.UpdateProgress(ctx, job.ID, models.ImportCompleted, 3000, nil))
+	got, err = repo.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportCompleted, got.Status)
+	assert.NotNil(t, got.CompletedAt)
+
+	// Only unfinished jobs are failed on restart
+	interrupted := newJob()
+	failed, err := repo.FailIncomplete(ctx, "interrupted")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), failed)
+
+	got, err = repo.GetByID(ctx, interrupted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportFailed, got.Status)
+	require.NotNil(t, got.Error)
+	assert.Equal(t, "interrupted", *got.Error)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrImportJobNotFound)
+	assert.ErrorIs(t, repo.UpdateProgress(ctx, uuid.New(), models.ImportFailed, 0, nil), ErrImportJobNotFound)
+}
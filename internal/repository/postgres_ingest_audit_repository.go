@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ingestAuditColumns is the column list used by all ingest audit log SELECT queries
+const ingestAuditColumns = `
+	id, created_at, source, user_id, COALESCE(device_id, ''), api_key_id, records,
+	COALESCE(source_ip, ''), COALESCE(status, 0), result, COALESCE(error, ''), latency_ms
+`
+
+// PostgresIngestAuditRepository implements IngestAuditRepository using PostgreSQL
+type PostgresIngestAuditRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresIngestAuditRepository creates a new PostgreSQL ingest audit repository
+func NewPostgresIngestAuditRepository(db *sql.DB) *PostgresIngestAuditRepository {
+	return &PostgresIngestAuditRepository{db: db}
+}
+
+// InsertBatch appends entries to the audit log in a single statement
+func (r *PostgresIngestAuditRepository) InsertBatch(ctx context.Context, entries []*models.IngestAuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	const columnsPerEntry = 11
+	values := make([]string, len(entries))
+	args := make([]interface{}, 0, len(entries)*columnsPerEntry)
+	for i, entry := range entries {
+		n := i * columnsPerEntry
+		values[i] = fmt.Sprintf("($%d, $%d, $%d, NULLIF($%d, ''), $%d, $%d, NULLIF($%d, ''), NULLIF($%d, 0), $%d, NULLIF($%d, ''), $%d)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11)
+		args = append(args,
+			entry.CreatedAt, entry.Source, entry.UserID, entry.DeviceID, entry.APIKeyID, entry.Records,
+			entry.SourceIP, entry.Status, entry.Result, entry.Error, entry.LatencyMS,
+		)
+	}
+
+	query := `
+		INSERT INTO ingest_audit_log (
+			created_at, source, user_id, device_id, api_key_id, records,
+			source_ip, status, result, error, latency_ms
+		) VALUES ` + strings.Join(values, ", ") + `
+		RETURNING id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert ingest audit entries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	// RETURNING yields rows in VALUES order for a plain INSERT
+	for i := 0; rows.Next(); i++ {
+		if err := rows.Scan(&entries[i].ID); err != nil {
+			return fmt.Errorf("failed to scan ingest audit entry id: %w", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to insert ingest audit entries: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves the entries matching the filter, newest first
+func (r *PostgresIngestAuditRepository) List(ctx context.Context, filter IngestAuditFilter) ([]*models.IngestAuditEntry, error) {
+	query := `SELECT ` + ingestAuditColumns + ` FROM ingest_audit_log WHERE 1=1`
+	args := []interface{}{}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if filter.DeviceID != "" {
+		args = append(args, filter.DeviceID)
+		query += fmt.Sprintf(" AND device_id = $%d", len(args))
+	}
+	if filter.SourceIP != "" {
+		args = append(args, filter.SourceIP)
+		query += fmt.Sprintf(" AND source_ip = $%d", len(args))
+	}
+	if filter.Result != "" {
+		args = append(args, filter.Result)
+		query += fmt.Sprintf(" AND result = $%d", len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingest audit entries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := []*models.IngestAuditEntry{}
+	for rows.Next() {
+		var entry models.IngestAuditEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.CreatedAt,
+			&entry.Source,
+			&entry.UserID,
+			&entry.DeviceID,
+			&entry.APIKeyID,
+			&entry.Records,
+			&entry.SourceIP,
+			&entry.Status,
+			&entry.Result,
+			&entry.Error,
+			&entry.LatencyMS,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ingest audit entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ingest audit entries: %w", err)
+	}
+
+	return entries, nil
+}
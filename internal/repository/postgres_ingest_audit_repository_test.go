@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresIngestAuditRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresIngestAuditRepository(db.DB)
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	entries := []*models.IngestAuditEntry{
+		{
+			CreatedAt: now.Add(-2 * time.Minute),
+			Source:    models.IngestSourceBatch,
+			UserID:    &userID,
+			DeviceID:  "AUDIT-001",
+			Records:   50,
+			SourceIP:  "203.0.113.7",
+			Status:    201,
+			Result:    models.IngestResultAccepted,
+			LatencyMS: 12.5,
+		},
+		{
+			CreatedAt: now.Add(-time.Minute),
+			Source:    models.IngestSourceSingle,
+			SourceIP:  "198.51.100.1",
+			Status:    400,
+			Result:    models.IngestResultRejected,
+			LatencyMS: 0.4,
+		},
+		{
+			CreatedAt: now,
+			Source:    models.IngestSourceMQTT,
+			DeviceID:  "AUDIT-001",
+			Records:   1,
+			Result:    models.IngestResultRejected,
+			Error:     "invalid telemetry payload",
+			LatencyMS: 1,
+		},
+	}
+	require.NoError(t, repo.InsertBatch(ctx, entries))
+	for _, entry := range entries {
+		assert.NotZero(t, entry.ID)
+	}
+
+	all, err := repo.List(ctx, IngestAuditFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, models.IngestSourceMQTT, all[0].Source)
+	assert.Equal(t, "invalid telemetry payload", all[0].Error)
+	assert.Zero(t, all[0].Status)
+	assert.Empty(t, all[0].SourceIP)
+	assert.Nil(t, all[1].UserID)
+	assert.Empty(t, all[1].DeviceID)
+
+	byDevice, err := repo.List(ctx, IngestAuditFilter{DeviceID: "AUDIT-001", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, byDevice, 2)
+
+	byUser, err := repo.List(ctx, IngestAuditFilter{UserID: &userID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, byUser, 1)
+	assert.Equal(t, 50, byUser[0].Records)
+	assert.Equal(t, "203.0.113.7", byUser[0].SourceIP)
+
+	rejected, err := repo.List(ctx, IngestAuditFilter{Result: models.IngestResultRejected, SourceIP: "198.51.100.1", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, rejected, 1)
+
+	from := now.Add(-90 * time.Second)
+	recent, err := repo.List(ctx, IngestAuditFilter{From: &from, To: &now, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, models.IngestSourceSingle, recent[0].Source)
+
+	// The log is append-only
+	_, err = db.ExecContext(ctx, `UPDATE ingest_audit_log SET records = 0`)
+	assert.Error(t, err)
+	_, err = db.ExecContext(ctx, `DELETE FROM ingest_audit_log`)
+	assert.Error(t, err)
+}
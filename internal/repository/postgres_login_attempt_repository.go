@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PostgresLoginAttemptRepository implements LoginAttemptRepository using PostgreSQL
+type PostgresLoginAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresLoginAttemptRepository creates a new PostgreSQL login attempt repository
+func NewPostgresLoginAttemptRepository(db *sql.DB) *PostgresLoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{db: db}
+}
+
+// RecordFailure stores a failed login attempt
+func (r *PostgresLoginAttemptRepository) RecordFailure(ctx context.Context, attempt *models.LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempts (user_id, email, ip_address, attempted_at)
+		VALUES ($1, $2, NULLIF($3, '')::inet, $4)
+		RETURNING id
+	`
+
+	if attempt.AttemptedAt.IsZero() {
+		attempt.AttemptedAt = time.Now()
+	}
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		attempt.UserID,
+		attempt.Email,
+		attempt.IPAddress,
+		attempt.AttemptedAt,
+	).Scan(&attempt.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert login attempt: %w", err)
+	}
+
+	return nil
+}
+
+// CountFailuresForUser counts the user's failed attempts since the given time
+func (r *PostgresLoginAttemptRepository) CountFailuresForUser(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM login_attempts WHERE user_id = $1 AND attempted_at >= $2`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count login attempts: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountFailuresForIP counts failed attempts from an IP address since the given time
+func (r *PostgresLoginAttemptRepository) CountFailuresForIP(ctx context.Context, ipAddress string, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM login_attempts WHERE ip_address = $1::inet AND attempted_at >= $2`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, ipAddress, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count login attempts: %w", err)
+	}
+
+	return count, nil
+}
+
+// Lock locks the user's account until the given time, extending any existing lock
+func (r *PostgresLoginAttemptRepository) Lock(ctx context.Context, userID uuid.UUID, until time.Time) error {
+	query := `
+		INSERT INTO account_lockouts (user_id, locked_until)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET locked_until = GREATEST(account_lockouts.locked_until, EXCLUDED.locked_until)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, until); err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	return nil
+}
+
+// GetLockedUntil returns when the user's lock expires, or nil if the account is not locked
+func (r *PostgresLoginAttemptRepository) GetLockedUntil(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	query := `SELECT locked_until FROM account_lockouts WHERE user_id = $1 AND locked_until > NOW()`
+
+	var lockedUntil time.Time
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get account lock: %w", err)
+	}
+
+	return &lockedUntil, nil
+}
+
+// Reset clears the user's failed attempts and lock
+func (r *PostgresLoginAttemptRepository) Reset(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM login_attempts WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear login attempts: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM account_lockouts WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear account lock: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresLoginAttemptRepository_CountAndReset(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresLoginAttemptRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "lockout@example.com")
+	now := time.Now()
+
+	attempts := []*models.LoginAttempt{
+		{UserID: &user.ID, Email: user.Email, IPAddress: "198.51.100.4", AttemptedAt: now.Add(-time.Hour)},
+		{UserID: &user.ID, Email: user.Email, IPAddress: "198.51.100.4", AttemptedAt: now.Add(-5 * time.Minute)},
+		{UserID: &user.ID, Email: user.Email, IPAddress: "203.0.113.9", AttemptedAt: now.Add(-time.Minute)},
+		{Email: "nobody@example.com", IPAddress: "198.51.100.4", AttemptedAt: now.Add(-time.Minute)},
+		{Email: "nobody@example.com"},
+	}
+	for _, attempt := range attempts {
+		require.NoError(t, repo.RecordFailure(ctx, attempt))
+		assert.NotZero(t, attempt.ID)
+	}
+
+	since := now.Add(-15 * time.Minute)
+	count, err := repo.CountFailuresForUser(ctx, user.ID, since)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "attempts outside the window are not counted")
+
+	count, err = repo.CountFailuresForIP(ctx, "198.51.100.4", since)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "unknown emails count toward the IP")
+
+	require.NoError(t, repo.Reset(ctx, user.ID))
+	count, err = repo.CountFailuresForUser(ctx, user.ID, time.Time{})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	count, err = repo.CountFailuresForIP(ctx, "198.51.100.4", since)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestPostgresLoginAttemptRepository_Lock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresLoginAttemptRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "locked@example.com")
+
+	lockedUntil, err := repo.GetLockedUntil(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, lockedUntil)
+
+	until := time.Now().Add(15 * time.Minute)
+	require.NoError(t, repo.Lock(ctx, user.ID, until))
+
+	// A shorter lock does not cut an existing one short
+	require.NoError(t, repo.Lock(ctx, user.ID, time.Now().Add(time.Minute)))
+
+	lockedUntil, err = repo.GetLockedUntil(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, lockedUntil)
+	assert.WithinDuration(t, until, *lockedUntil, time.Second)
+
+	require.NoError(t, repo.Reset(ctx, user.ID))
+	lockedUntil, err = repo.GetLockedUntil(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, lockedUntil)
+
+	// Expired locks are ignored
+	require.NoError(t, repo.Lock(ctx, user.ID, time.Now().Add(-time.Minute)))
+	lockedUntil, err = repo.GetLockedUntil(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, lockedUntil)
+}
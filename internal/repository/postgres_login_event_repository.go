@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// loginEventColumns is the column list used by all login event SELECT queries
+const loginEventColumns = `
+	id, user_id, COALESCE(email, ''), method, success, COALESCE(failure_reason, ''),
+	COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at
+`
+
+// PostgresLoginEventRepository implements LoginEventRepository using PostgreSQL
+type PostgresLoginEventRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresLoginEventRepository creates a new PostgreSQL login event repository
+func NewPostgresLoginEventRepository(db *sql.DB) *PostgresLoginEventRepository {
+	return &PostgresLoginEventRepository{db: db}
+}
+
+// Record appends a login attempt to the audit trail
+func (r *PostgresLoginEventRepository) Record(ctx context.Context, event *models.LoginEvent) error {
+	query := `
+		INSERT INTO login_events (user_id, email, method, success, failure_reason, ip_address, user_agent, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		event.UserID,
+		event.Email,
+		event.Method,
+		event.Success,
+		event.FailureReason,
+		event.IPAddress,
+		event.UserAgent,
+		event.CreatedAt,
+	).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert login event: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves the events matching the filter, newest first
+func (r *PostgresLoginEventRepository) List(ctx context.Context, filter LoginEventFilter) ([]*models.LoginEvent, error) {
+	query := `SELECT ` + loginEventColumns + ` FROM login_events WHERE 1=1`
+	args := []interface{}{}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if filter.Email != "" {
+		args = append(args, filter.Email)
+		query += fmt.Sprintf(" AND email = $%d", len(args))
+	}
+	if filter.IPAddress != "" {
+		args = append(args, filter.IPAddress)
+		query += fmt.Sprintf(" AND ip_address = $%d", len(args))
+	}
+	if filter.Method != "" {
+		args = append(args, filter.Method)
+		query += fmt.Sprintf(" AND method = $%d", len(args))
+	}
+	if filter.Success != nil {
+		args = append(args, *filter.Success)
+		query += fmt.Sprintf(" AND success = $%d", len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if after := loginEventKeyset.Apply(filter.After, postgresBinder(&args)); after != "" {
+		query += " AND " + after
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d", loginEventKeyset.OrderBy(), len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	events := []*models.LoginEvent{}
+	for rows.Next() {
+		var event models.LoginEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.UserID,
+			&event.Email,
+			&event.Method,
+			&event.Success,
+			&event.FailureReason,
+			&event.IPAddress,
+			&event.UserAgent,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan login event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate login events: %w", err)
+	}
+
+	return events, nil
+}
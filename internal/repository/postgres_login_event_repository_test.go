@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresLoginEventRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresLoginEventRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "login-events@example.com")
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	events := []*models.LoginEvent{
+		{
+			UserID:        &user.ID,
+			Email:         user.Email,
+			Method:        models.LoginMethodPassword,
+			FailureReason: models.LoginFailureInvalidCredentials,
+			IPAddress:     "203.0.113.7",
+			UserAgent:     "curl/8.5.0",
+			CreatedAt:     now.Add(-2 * time.Minute),
+		},
+		{
+			Email:         "nobody@example.com",
+			Method:        models.LoginMethodPassword,
+			FailureReason: models.LoginFailureUnknownAccount,
+			IPAddress:     "203.0.113.7",
+			CreatedAt:     now.Add(-time.Minute),
+		},
+		{
+			UserID:    &user.ID,
+			Email:     user.Email,
+			Method:    models.LoginMethodMagicLink,
+			Success:   true,
+			IPAddress: "198.51.100.1",
+			UserAgent: "AVT/2.1 (iOS 17.4)",
+			CreatedAt: now,
+		},
+	}
+	for _, event := range events {
+		require.NoError(t, repo.Record(ctx, event))
+		assert.NotZero(t, event.ID)
+	}
+
+	all, err := repo.List(ctx, LoginEventFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, models.LoginMethodMagicLink, all[0].Method)
+	assert.True(t, all[0].Success)
+	assert.Empty(t, all[0].FailureReason)
+	assert.Nil(t, all[1].UserID)
+	assert.Empty(t, all[1].UserAgent)
+
+	byUser, err := repo.List(ctx, LoginEventFilter{UserID: &user.ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, byUser, 2)
+
+	failed := false
+	byIP, err := repo.List(ctx, LoginEventFilter{IPAddress: "203.0.113.7", Success: &failed, Method: models.LoginMethodPassword, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, byIP, 2)
+
+	from := now.Add(-90 * time.Second)
+	recent, err := repo.List(ctx, LoginEventFilter{From: &from, To: &now, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, "nobody@example.com", recent[0].Email)
+
+	// Pages continue after the cursor of the previous page's last event
+	first, err := repo.List(ctx, LoginEventFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	last := first[1]
+	rest, err := repo.List(ctx, LoginEventFilter{Limit: 2, After: &pagination.Cursor{Time: last.CreatedAt, ID: strconv.FormatInt(last.ID, 10)}})
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Equal(t, events[0].ID, rest[0].ID)
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrNotificationNotFound is returned when a notification is not found
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// notificationColumns is the column list used by all notification SELECT queries
+const notificationColumns = `id, user_id, type, title, body, data, read_at, created_at`
+
+// defaultNotificationLimit caps inbox listings when no limit is given
+const defaultNotificationLimit = 50
+
+// PostgresNotificationRepository implements NotificationRepository using PostgreSQL
+type PostgresNotificationRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresNotificationRepository creates a new PostgreSQL notification repository
+func NewPostgresNotificationRepository(db *sql.DB) *PostgresNotificationRepository {
+	return &PostgresNotificationRepository{db: db}
+}
+
+// Create stores a new notification in the user's inbox
+func (r *PostgresNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	if notification.ID == uuid.Nil {
+		notification.ID = uuid.New()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	var data []byte
+	if len(notification.Data) > 0 {
+		var err error
+		data, err = json.Marshal(notification.Data)
+		if err != nil {
+			return fmt.Errorf("failed to encode notification data: %w", err)
+		}
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO notifications (id, user_id, type, title, body, data, read_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		notification.ID,
+		notification.UserID,
+		notification.Type,
+		notification.Title,
+		notification.Body,
+		data,
+		notification.ReadAt,
+		notification.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert notification: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves the user's notifications matching the filter, most recent first
+func (r *PostgresNotificationRepository) List(ctx context.Context, userID uuid.UUID, filter NotificationFilter) ([]*models.Notification, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultNotificationLimit
+	}
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = $1`
+	if filter.UnreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, max(filter.Offset, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]*models.Notification, 0)
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// CountUnread counts the user's unread notifications
+func (r *PostgresNotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL
+	`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+// Notifications already read keep their original read time.
+func (r *PostgresNotificationRepository) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications as read, returning how many were unread
+func (r *PostgresNotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL
+	`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// GetPreferences retrieves the preferences the user has set, which may not cover every type
+func (r *PostgresNotificationRepository) GetPreferences(ctx context.Context, userID uuid.UUID) ([]models.NotificationPreference, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT type, email, push FROM notification_preferences WHERE user_id = $1 ORDER BY type
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	preferences := make([]models.NotificationPreference, 0)
+	for rows.Next() {
+		var preference models.NotificationPreference
+		if err := rows.Scan(&preference.Type, &preference.Email, &preference.Push); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		preferences = append(preferences, preference)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification preferences: %w", err)
+	}
+
+	return preferences, nil
+}
+
+// SetPreferences creates or replaces the user's preferences for the given types
+func (r *PostgresNotificationRepository) SetPreferences(ctx context.Context, userID uuid.UUID, preferences []models.NotificationPreference) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	for _, preference := range preferences {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_preferences (user_id, type, email, push, updated_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			ON CONFLICT (user_id, type) DO UPDATE
+			SET email = EXCLUDED.email, push = EXCLUDED.push, updated_at = NOW()
+		`, userID, preference.Type, preference.Email, preference.Push); err != nil {
+			return fmt.Errorf("failed to store notification preference: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// NotificationsEnabled reports the notifications_enabled flag of the user's profile
+func (r *PostgresNotificationRepository) NotificationsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var enabled sql.NullBool
+	err := r.db.QueryRowContext(ctx, `SELECT notifications_enabled FROM user_profiles WHERE user_id = $1`, userID).Scan(&enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get notifications enabled: %w", err)
+	}
+
+	// The column defaults to TRUE, so only an explicit FALSE disables notifications
+	return !enabled.Valid || enabled.Bool, nil
+}
+
+// SetNotificationsEnabled sets the notifications_enabled flag, creating the profile if needed
+func (r *PostgresNotificationRepository) SetNotificationsEnabled(ctx context.Context, userID uuid.UUID, enabled bool) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, notifications_enabled)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET notifications_enabled = EXCLUDED.notifications_enabled
+	`, userID, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to set notifications enabled: %w", err)
+	}
+
+	return nil
+}
+
+// scanNotification scans a single notification row selected with notificationColumns
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	notification := &models.Notification{}
+	var data []byte
+	var readAt sql.NullTime
+
+	err := row.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.Type,
+		&notification.Title,
+		&notification.Body,
+		&data,
+		&readAt,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &notification.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode notification data: %w", err)
+		}
+	}
+	if readAt.Valid {
+		notification.ReadAt = &readAt.Time
+	}
+
+	return notification, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresNotificationRepository_Inbox(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresNotificationRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "notifications@example.com")
+	other := createTestUser(t, db, "other-notifications@example.com")
+
+	older := &models.Notification{
+		UserID:    user.ID,
+		Type:      models.NotificationLowBattery,
+		Title:     "kart-7 battery is low",
+		Body:      "Battery at 12%",
+		Data:      map[string]string{"deviceId": "kart-7"},
+		CreatedAt: time.Now().Add(-time.Hour),
+	}
+	newer := &models.Notification{
+		UserID: user.ID,
+		Type:   models.NotificationSessionSummary,
+		Title:  "Session summary ready",
+		Body:   "12.4 km, top speed 98 km/h",
+	}
+	require.NoError(t, repo.Create(ctx, older))
+	require.NoError(t, repo.Create(ctx, newer))
+	require.NoError(t, repo.Create(ctx, &models.Notification{UserID: other.ID, Type: models.NotificationNewLogin, Title: "New sign-in", Body: "From France"}))
+	assert.NotEqual(t, uuid.Nil, older.ID)
+
+	notifications, err := repo.List(ctx, user.ID, NotificationFilter{})
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, newer.ID, notifications[0].ID)
+	assert.Nil(t, notifications[0].Data)
+	assert.Equal(t, map[string]string{"deviceId": "kart-7"}, notifications[1].Data)
+
+	page, err := repo.List(ctx, user.ID, NotificationFilter{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, older.ID, page[0].ID)
+
+	// Users can only mark their own notifications
+	assert.ErrorIs(t, repo.MarkRead(ctx, other.ID, older.ID), ErrNotificationNotFound)
+	require.NoError(t, repo.MarkRead(ctx, user.ID, older.ID))
+
+	unread, err := repo.List(ctx, user.ID, NotificationFilter{UnreadOnly: true})
+	require.NoError(t, err)
+	require.Len(t, unread, 1)
+	assert.Equal(t, newer.ID, unread[0].ID)
+
+	count, err := repo.CountUnread(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	marked, err := repo.MarkAllRead(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), marked)
+
+	count, err = repo.CountUnread(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	count, err = repo.CountUnread(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestPostgresNotificationRepository_Preferences(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresNotificationRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "notification-preferences@example.com")
+
+	preferences, err := repo.GetPreferences(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, preferences)
+
+	require.NoError(t, repo.SetPreferences(ctx, user.ID, []models.NotificationPreference{
+		{Type: models.NotificationLowBattery, Email: false, Push: true},
+	}))
+	require.NoError(t, repo.SetPreferences(ctx, user.ID, []models.NotificationPreference{
+		{Type: models.NotificationLowBattery, Email: true, Push: false},
+		{Type: models.NotificationNewLogin, Email: false, Push: false},
+	}))
+
+	preferences, err = repo.GetPreferences(ctx, user.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []models.NotificationPreference{
+		{Type: models.NotificationLowBattery, Email: true, Push: false},
+		{Type: models.NotificationNewLogin, Email: false, Push: false},
+	}, preferences)
+
+	// Users without a profile have notifications enabled
+	enabled, err := repo.NotificationsEnabled(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	require.NoError(t, repo.SetNotificationsEnabled(ctx, user.ID, false))
+	enabled, err = repo.NotificationsEnabled(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	require.NoError(t, repo.SetNotificationsEnabled(ctx, user.ID, true))
+	enabled, err = repo.NotificationsEnabled(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrOrganizationNotFound is returned when an organization is not found
+	ErrOrganizationNotFound = errors.New("organization not found")
+
+	// ErrOrgMemberNotFound is returned when a user is not a member of an organization
+	ErrOrgMemberNotFound = errors.New("organization member not found")
+
+	// ErrLastOrgOwner is returned when a change would leave an organization without an owner
+	ErrLastOrgOwner = errors.New("organization must keep at least one owner")
+
+	// ErrInvitationNotFound is returned when an invitation does not exist, has expired or was already accepted
+	ErrInvitationNotFound = errors.New("invitation not found")
+)
+
+// organizationMemberColumns is the column list used by all member SELECT queries
+const organizationMemberColumns = `
+	m.org_id, m.user_id, u.email, m.role, m.created_at, m.updated_at
+`
+
+// PostgresOrganizationRepository implements OrganizationRepository using PostgreSQL
+type PostgresOrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresOrganizationRepository creates a new PostgreSQL organization repository
+func NewPostgresOrganizationRepository(db *sql.DB) *PostgresOrganizationRepository {
+	return &PostgresOrganizationRepository{db: db}
+}
+
+// Create stores a new organization with ownerID as its first owner
+func (r *PostgresOrganizationRepository) Create(ctx context.Context, org *models.Organization, ownerID uuid.UUID) error {
+	if org.ID == uuid.Nil {
+		org.ID = uuid.New()
+	}
+	now := time.Now()
+	if org.CreatedAt.IsZero() {
+		org.CreatedAt = now
+	}
+	if org.UpdatedAt.IsZero() {
+		org.UpdatedAt = now
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO organizations (id, name, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, org.ID, org.Name, org.CreatedBy, org.CreatedAt, org.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to insert organization: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO organization_members (org_id, user_id, role)
+		VALUES ($1, $2, $3)
+	`, org.ID, ownerID, models.OrgRoleOwner); err != nil {
+		return fmt.Errorf("failed to insert organization owner: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves an organization by its UUID
+func (r *PostgresOrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	var org models.Organization
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, created_by, created_at, updated_at
+		FROM organizations
+		WHERE id = $1
+	`, id).Scan(&org.ID, &org.Name, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return &org, nil
+}
+
+// ListByUserID retrieves the organizations a user belongs to, with the user's role
+func (r *PostgresOrganizationRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.OrganizationMembership, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT o.id, o.name, o.created_by, o.created_at, o.updated_at, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.org_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name, o.id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	memberships := []*models.OrganizationMembership{}
+	for rows.Next() {
+		var membership models.OrganizationMembership
+		if err := rows.Scan(
+			&membership.ID,
+			&membership.Name,
+			&membership.CreatedBy,
+			&membership.CreatedAt,
+			&membership.UpdatedAt,
+			&membership.Role,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		memberships = append(memberships, &membership)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate organizations: %w", err)
+	}
+
+	return memberships, nil
+}
+
+// GetMember retrieves a user's membership in an organization
+func (r *PostgresOrganizationRepository) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	return getOrganizationMember(ctx, r.db, orgID, userID)
+}
+
+// ListMembers retrieves an organization's members, owners first
+func (r *PostgresOrganizationRepository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+organizationMemberColumns+`
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, u.email
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	members := []*models.OrganizationMember{}
+	for rows.Next() {
+		member, err := scanOrganizationMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate organization members: %w", err)
+	}
+
+	return members, nil
+}
+
+// UpdateMemberRole changes a member's role
+func (r *PostgresOrganizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, role models.OrgRole) error {
+	return r.changeMember(ctx, orgID, userID, role != models.OrgRoleOwner, func(tx *sql.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, `
+			UPDATE organization_members SET role = $1
+			WHERE org_id = $2 AND user_id = $3
+		`, role, orgID, userID)
+	})
+}
+
+// RemoveMember removes a user from an organization
+func (r *PostgresOrganizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	return r.changeMember(ctx, orgID, userID, true, func(tx *sql.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, `
+			DELETE FROM organization_members
+			WHERE org_id = $1 AND user_id = $2
+		`, orgID, userID)
+	})
+}
+
+// changeMember applies a membership change, refusing to demote or remove the last owner
+// The organization row is locked so concurrent changes cannot remove every owner between them.
+func (r *PostgresOrganizationRepository) changeMember(ctx context.Context, orgID, userID uuid.UUID, dropsOwner bool, change func(tx *sql.Tx) (sql.Result, error)) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM organizations WHERE id = $1 FOR UPDATE`, orgID); err != nil {
+		return fmt.Errorf("failed to lock organization: %w", err)
+	}
+
+	member, err := getOrganizationMember(ctx, tx, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	if dropsOwner && member.Role == models.OrgRoleOwner {
+		var owners int
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM organization_members WHERE org_id = $1 AND role = 'owner'
+		`, orgID).Scan(&owners); err != nil {
+			return fmt.Errorf("failed to count organization owners: %w", err)
+		}
+		if owners <= 1 {
+			return ErrLastOrgOwner
+		}
+	}
+
+	if _, err := change(tx); err != nil {
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// CreateInvitation stores a new invitation
+func (r *PostgresOrganizationRepository) CreateInvitation(ctx context.Context, invitation *models.OrganizationInvitation) error {
+	if invitation.ID == uuid.Nil {
+		invitation.ID = uuid.New()
+	}
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO organization_invitations (
+			id, org_id, email, role, token_hash, invited_by, expires_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		invitation.ID,
+		invitation.OrgID,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+		invitation.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert invitation: %w", err)
+	}
+
+	return nil
+}
+
+// GetInvitationByTokenHash retrieves an invitation by the hash of its token
+func (r *PostgresOrganizationRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, org_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+		FROM organization_invitations
+		WHERE token_hash = $1
+	`, tokenHash).Scan(
+		&invitation.ID,
+		&invitation.OrgID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+// AcceptInvitation marks a pending invitation accepted and adds the user as a member
+func (r *PostgresOrganizationRepository) AcceptInvitation(ctx context.Context, invitationID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	// Claiming the invitation first makes a token usable only once
+	var orgID uuid.UUID
+	var role models.OrgRole
+	err = tx.QueryRowContext(ctx, `
+		UPDATE organization_invitations
+		SET accepted_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		RETURNING org_id, role
+	`, invitationID).Scan(&orgID, &role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO organization_members (org_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (org_id, user_id) DO NOTHING
+	`, orgID, userID, role); err != nil {
+		return nil, fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	member, err := getOrganizationMember(ctx, tx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit invitation: %w", err)
+	}
+
+	return member, nil
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// getOrganizationMember retrieves a membership with the member's email
+func getOrganizationMember(ctx context.Context, q queryRower, orgID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	member, err := scanOrganizationMember(q.QueryRowContext(ctx, `
+		SELECT `+organizationMemberColumns+`
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = $1 AND m.user_id = $2
+	`, orgID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrgMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	return member, nil
+}
+
+// scanOrganizationMember scans a single member row selected with organizationMemberColumns
+func scanOrganizationMember(row rowScanner) (*models.OrganizationMember, error) {
+	member := &models.OrganizationMember{}
+	if err := row.Scan(
+		&member.OrgID,
+		&member.UserID,
+		&member.Email,
+		&member.Role,
+		&member.CreatedAt,
+		&member.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresOrganizationRepository_Members(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresOrganizationRepository(db.DB)
+	ctx := context.Background()
+	owner := createTestUser(t, db, "owner@example.com")
+	member := createTestUser(t, db, "member@example.com")
+
+	org := &models.Organization{Name: "Team Racing", CreatedBy: &owner.ID}
+	require.NoError(t, repo.Create(ctx, org, owner.ID))
+
+	memberships, err := repo.ListByUserID(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, "Team Racing", memberships[0].Name)
+	assert.Equal(t, models.OrgRoleOwner, memberships[0].Role)
+
+	// The only owner can be neither demoted nor removed
+	assert.ErrorIs(t, repo.UpdateMemberRole(ctx, org.ID, owner.ID, models.OrgRoleAdmin), ErrLastOrgOwner)
+	assert.ErrorIs(t, repo.RemoveMember(ctx, org.ID, owner.ID), ErrLastOrgOwner)
+
+	invitation := &models.OrganizationInvitation{
+		OrgID:     org.ID,
+		Email:     member.Email,
+		Role:      models.OrgRoleMember,
+		TokenHash: "invite-hash",
+		InvitedBy: &owner.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, repo.CreateInvitation(ctx, invitation))
+
+	retrieved, err := repo.GetInvitationByTokenHash(ctx, "invite-hash")
+	require.NoError(t, err)
+	assert.True(t, retrieved.IsPending())
+
+	joined, err := repo.AcceptInvitation(ctx, invitation.ID, member.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrgRoleMember, joined.Role)
+	assert.Equal(t, member.Email, joined.Email)
+
+	// Invitations can only be accepted once
+	_, err = repo.AcceptInvitation(ctx, invitation.ID, member.ID)
+	assert.ErrorIs(t, err, ErrInvitationNotFound)
+
+	members, err := repo.ListMembers(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, owner.ID, members[0].UserID)
+
+	// With a second owner the first one may step down
+	require.NoError(t, repo.UpdateMemberRole(ctx, org.ID, member.ID, models.OrgRoleOwner))
+	require.NoError(t, repo.RemoveMember(ctx, org.ID, owner.ID))
+
+	_, err = repo.GetMember(ctx, org.ID, owner.ID)
+	assert.ErrorIs(t, err, ErrOrgMemberNotFound)
+}
+
+func TestPostgresOrganizationRepository_SharedDevicesAndSessions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	orgRepo := NewPostgresOrganizationRepository(db.DB)
+	deviceRepo := NewPostgresDeviceRepository(db.DB)
+	sessionRepo := NewPostgresSessionRepository(db.DB)
+	ctx := context.Background()
+	owner := createTestUser(t, db, "fleet@example.com")
+	teammate := createTestUser(t, db, "teammate@example.com")
+
+	org := &models.Organization{Name: "Fleet"}
+	require.NoError(t, orgRepo.Create(ctx, org, owner.ID))
+
+	device := &models.Device{DeviceID: "SHARED-001", UserID: owner.ID, IsActive: true}
+	require.NoError(t, deviceRepo.Create(ctx, device))
+	require.NoError(t, deviceRepo.SetOrganization(ctx, device.ID, &org.ID))
+
+	session := &models.Session{DeviceID: device.DeviceID, UserID: &owner.ID, OrgID: &org.ID, StartedAt: time.Now()}
+	require.NoError(t, sessionRepo.Create(ctx, session))
+
+	// Without the organization the teammate sees nothing
+	devices, total, err := deviceRepo.List(ctx, DeviceFilter{UserID: teammate.ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+	assert.Equal(t, 0, total)
+
+	devices, total, err = deviceRepo.List(ctx, DeviceFilter{UserID: teammate.ID, OrgIDs: []uuid.UUID{org.ID}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, org.ID, *devices[0].OrgID)
+
+	sessions, total, err := sessionRepo.ListAccessible(ctx, SessionFilter{UserID: teammate.ID, OrgIDs: []uuid.UUID{org.ID}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, session.ID, sessions[0].ID)
+
+	sessions, err = sessionRepo.ListByUserID(ctx, teammate.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	require.NoError(t, deviceRepo.SetOrganization(ctx, device.ID, nil))
+	unshared, err := deviceRepo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Nil(t, unshared.OrgID)
+}
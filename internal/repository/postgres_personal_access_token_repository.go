@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrPersonalAccessTokenNotFound is returned when a personal access token is not found
+	ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+
+	// ErrPersonalAccessTokenRevoked is returned when a personal access token has been revoked
+	ErrPersonalAccessTokenRevoked = errors.New("personal access token has been revoked")
+)
+
+// personalAccessTokenColumns lists the personal_access_tokens columns in scan order
+const personalAccessTokenColumns = `
+	id, user_id, name, token_prefix, token_hash, array_to_string(scopes, ','),
+	expires_at, created_at, last_used_at, revoked_at`
+
+// PostgresPersonalAccessTokenRepository implements PersonalAccessTokenRepository using PostgreSQL
+type PostgresPersonalAccessTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresPersonalAccessTokenRepository creates a new PostgreSQL personal access token repository
+func NewPostgresPersonalAccessTokenRepository(db *sql.DB) *PostgresPersonalAccessTokenRepository {
+	return &PostgresPersonalAccessTokenRepository{db: db}
+}
+
+// Create stores a new personal access token
+func (r *PostgresPersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	scopes := make([]string, len(token.Scopes))
+	for i, scope := range token.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO personal_access_tokens (
+			id, user_id, name, token_prefix, token_hash, scopes, expires_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		token.ID,
+		token.UserID,
+		token.Name,
+		token.TokenPrefix,
+		token.TokenHash,
+		scopes,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert personal access token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash retrieves a non-revoked personal access token by its hash
+func (r *PostgresPersonalAccessTokenRepository) GetByHash(ctx context.Context, hash string) (*models.PersonalAccessToken, error) {
+	query := `SELECT ` + personalAccessTokenColumns + ` FROM personal_access_tokens WHERE token_hash = $1`
+
+	token, err := scanPersonalAccessToken(r.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPersonalAccessTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get personal access token: %w", err)
+	}
+
+	if token.IsRevoked() {
+		return nil, ErrPersonalAccessTokenRevoked
+	}
+
+	return token, nil
+}
+
+// ListByUserID retrieves all tokens a user minted, newest first
+func (r *PostgresPersonalAccessTokenRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	query := `SELECT ` + personalAccessTokenColumns + `
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personal access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*models.PersonalAccessToken, 0)
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan personal access token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating personal access tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke marks one of the user's tokens as revoked
+func (r *PostgresPersonalAccessTokenRepository) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// Distinguish between a missing token and one that was already revoked
+		var exists bool
+		err := r.db.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM personal_access_tokens WHERE id = $1 AND user_id = $2)`,
+			id, userID,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to get personal access token: %w", err)
+		}
+		if !exists {
+			return ErrPersonalAccessTokenNotFound
+		}
+		return ErrPersonalAccessTokenRevoked
+	}
+
+	return nil
+}
+
+// UpdateLastUsed updates the last_used_at timestamp for a token
+func (r *PostgresPersonalAccessTokenRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE personal_access_tokens SET last_used_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update personal access token last used: %w", err)
+	}
+
+	return nil
+}
+
+// scanPersonalAccessToken scans a single personal_access_tokens row
+func scanPersonalAccessToken(row rowScanner) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenPrefix,
+		&token.TokenHash,
+		&scopes,
+		&expiresAt,
+		&token.CreatedAt,
+		&lastUsedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range strings.Split(scopes, ",") {
+		if scope != "" {
+			token.Scopes = append(token.Scopes, models.Scope(scope))
+		}
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
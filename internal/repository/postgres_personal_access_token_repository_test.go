@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresPersonalAccessTokenRepository_Lifecycle(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user := createTestUser(t, db, "tokens@example.com")
+	other := createTestUser(t, db, "other-tokens@example.com")
+
+	repo := NewPostgresPersonalAccessTokenRepository(db.DB)
+
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Microsecond)
+	token := &models.PersonalAccessToken{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Name:        "Grafana",
+		TokenPrefix: "avtpat_abc123",
+		TokenHash:   "hash-of-token",
+		Scopes:      []models.Scope{models.ScopeTelemetryRead, models.ScopeTelemetryWrite},
+		ExpiresAt:   &expiresAt,
+		CreatedAt:   time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, token))
+
+	// Lookup by hash
+	retrieved, err := repo.GetByHash(ctx, "hash-of-token")
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, retrieved.ID)
+	assert.Equal(t, user.ID, retrieved.UserID)
+	assert.Equal(t, token.Scopes, retrieved.Scopes)
+	require.NotNil(t, retrieved.ExpiresAt)
+	assert.True(t, expiresAt.Equal(*retrieved.ExpiresAt))
+	assert.Nil(t, retrieved.LastUsedAt)
+
+	// Track usage
+	require.NoError(t, repo.UpdateLastUsed(ctx, token.ID))
+	tokens, err := repo.ListByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.NotNil(t, tokens[0].LastUsedAt)
+
+	tokens, err = repo.ListByUserID(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	// Only the owner can revoke
+	assert.ErrorIs(t, repo.Revoke(ctx, other.ID, token.ID), ErrPersonalAccessTokenNotFound)
+	require.NoError(t, repo.Revoke(ctx, user.ID, token.ID))
+	assert.ErrorIs(t, repo.Revoke(ctx, user.ID, token.ID), ErrPersonalAccessTokenRevoked)
+
+	_, err = repo.GetByHash(ctx, "hash-of-token")
+	assert.ErrorIs(t, err, ErrPersonalAccessTokenRevoked)
+
+	_, err = repo.GetByHash(ctx, "unknown-hash")
+	assert.ErrorIs(t, err, ErrPersonalAccessTokenNotFound)
+}
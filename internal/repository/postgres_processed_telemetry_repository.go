@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PostgresProcessedTelemetryRepository implements ProcessedTelemetryRepository using PostgreSQL
+type PostgresProcessedTelemetryRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresProcessedTelemetryRepository creates a new PostgreSQL processed telemetry repository
+func NewPostgresProcessedTelemetryRepository(db *sql.DB) *PostgresProcessedTelemetryRepository {
+	return &PostgresProcessedTelemetryRepository{db: db}
+}
+
+// ReplaceSession implements ProcessedTelemetryRepository.ReplaceSession
+// Returns ErrSessionNotFound if the session has been deleted.
+func (r *PostgresProcessedTelemetryRepository) ReplaceSession(ctx context.Context, sessionID uuid.UUID, points []*models.ProcessedTelemetry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	// Lock the session first so a concurrent run for the same session waits for this one
+	result, err := tx.ExecContext(ctx, `UPDATE sessions SET processed_at = NOW() WHERE id = $1`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to mark session processed: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrSessionNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM telemetry_processed WHERE session_id = $1`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete processed telemetry: %w", err)
+	}
+
+	if len(points) > 0 {
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO telemetry_processed (
+				telemetry_id, telemetry_recorded_at, session_id, latitude, longitude, speed
+			) VALUES ($1, $2, $3, $4, $5, $6)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, point := range points {
+			if _, err := stmt.ExecContext(ctx,
+				point.TelemetryID, point.RecordedAt, sessionID, point.Latitude, point.Longitude, point.Speed,
+			); err != nil {
+				return fmt.Errorf("failed to insert processed telemetry: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListPending implements ProcessedTelemetryRepository.ListPending
+func (r *PostgresProcessedTelemetryRepository) ListPending(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT id FROM sessions
+		WHERE ended_at IS NOT NULL
+		  AND (processed_at IS NULL OR processed_at < ended_at)
+		ORDER BY ended_at ASC
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions pending processing: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session ids: %w", err)
+	}
+
+	return ids, nil
+}
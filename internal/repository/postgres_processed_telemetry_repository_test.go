@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresProcessedTelemetryRepository_ReplaceSession(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresProcessedTelemetryRepository(db.DB)
+	sessionRepo := NewPostgresSessionRepository(db.DB)
+	telemetryRepo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "processed@example.com")
+
+	startedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	session := &models.Session{DeviceID: "RACEBOX-001", UserID: &user.ID, StartedAt: startedAt}
+	require.NoError(t, sessionRepo.Create(ctx, session))
+
+	sessionID := session.ID.String()
+	var records []*models.TelemetryData
+	for i := range 3 {
+		point := createSampleTelemetry(startedAt.Add(time.Duration(i)*time.Second), "RACEBOX-001")
+		point.UserID = &user.ID
+		point.SessionID = &sessionID
+		point.GPS.Speed = 100
+		require.NoError(t, telemetryRepo.Save(ctx, point))
+		records = append(records, point)
+	}
+
+	// Sessions are pending once ended
+	pending, err := repo.ListPending(ctx, 10)
+	require.NoError(t, err)
+	assert.NotContains(t, pending, session.ID)
+
+	require.NoError(t, sessionRepo.End(ctx, session.ID, startedAt.Add(time.Minute)))
+	pending, err = repo.ListPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Contains(t, pending, session.ID)
+
+	// Unprocessed sessions have no processed telemetry
+	filter := TelemetryFilter{UserID: user.ID, SessionID: sessionID, Limit: 10, Processed: true}
+	processed, err := telemetryRepo.Query(ctx, filter)
+	require.NoError(t, err)
+	assert.Empty(t, processed)
+
+	points := make([]*models.ProcessedTelemetry, len(records))
+	for i, record := range records {
+		points[i] = &models.ProcessedTelemetry{
+			TelemetryID: record.ID,
+			RecordedAt:  record.Timestamp,
+			Latitude:    record.GPS.Latitude + 0.0001,
+			Longitude:   record.GPS.Longitude,
+			Speed:       95,
+		}
+	}
+	require.NoError(t, repo.ReplaceSession(ctx, session.ID, points))
+
+	pending, err = repo.ListPending(ctx, 10)
+	require.NoError(t, err)
+	assert.NotContains(t, pending, session.ID)
+
+	processed, err = telemetryRepo.Query(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, processed, 3)
+	for _, record := range processed {
+		assert.Equal(t, 95.0, record.GPS.Speed)
+		assert.InDelta(t, records[0].GPS.Latitude+0.0001, record.GPS.Latitude, 1e-9)
+		assert.Equal(t, "RACEBOX-001", record.DeviceID)
+	}
+
+	buckets, err := telemetryRepo.Aggregate(ctx, TelemetryFilter{UserID: user.ID, SessionID: sessionID, Processed: true}, Bucket1m)
+	require.NoError(t, err)
+	require.NotEmpty(t, buckets)
+	for _, bucket := range buckets {
+		assert.Equal(t, 95.0, bucket.MaxSpeed)
+	}
+
+	// Replacing again drops the previous points
+	require.NoError(t, repo.ReplaceSession(ctx, session.ID, points[:1]))
+	processed, err = telemetryRepo.Query(ctx, filter)
+	require.NoError(t, err)
+	assert.Len(t, processed, 1)
+
+	assert.ErrorIs(t, repo.ReplaceSession(ctx, uuid.New(), nil), ErrSessionNotFound)
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrPushTokenNotFound is returned when a push token is not found
+var ErrPushTokenNotFound = errors.New("push token not found")
+
+// PostgresPushTokenRepository implements PushTokenRepository using PostgreSQL
+type PostgresPushTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresPushTokenRepository creates a new PostgreSQL push token repository
+func NewPostgresPushTokenRepository(db *sql.DB) *PostgresPushTokenRepository {
+	return &PostgresPushTokenRepository{db: db}
+}
+
+// Register stores a push token, or moves an already registered token to the token's user
+func (r *PostgresPushTokenRepository) Register(ctx context.Context, token *models.PushToken) error {
+	query := `
+		INSERT INTO push_tokens (user_id, provider, token, device_name)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (token) DO UPDATE
+		SET user_id = EXCLUDED.user_id,
+		    provider = EXCLUDED.provider,
+		    device_name = EXCLUDED.device_name,
+		    updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, token.UserID, token.Provider, token.Token, token.DeviceName).
+		Scan(&token.ID, &token.CreatedAt, &token.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to register push token: %w", err)
+	}
+
+	return nil
+}
+
+// ListByUserID retrieves all push tokens of a user, most recently registered first
+func (r *PostgresPushTokenRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.PushToken, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, provider, token, device_name, created_at, updated_at
+		FROM push_tokens
+		WHERE user_id = $1
+		ORDER BY updated_at DESC, id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*models.PushToken, 0)
+	for rows.Next() {
+		var token models.PushToken
+		var deviceName sql.NullString
+		if err := rows.Scan(
+			&token.ID,
+			&token.UserID,
+			&token.Provider,
+			&token.Token,
+			&deviceName,
+			&token.CreatedAt,
+			&token.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan push token: %w", err)
+		}
+		if deviceName.Valid {
+			token.DeviceName = &deviceName.String
+		}
+		tokens = append(tokens, &token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating push tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Delete removes one of the user's push tokens
+func (r *PostgresPushTokenRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM push_tokens WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete push token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrPushTokenNotFound
+	}
+
+	return nil
+}
+
+// DeleteByToken removes a token the push provider reported as no longer valid
+func (r *PostgresPushTokenRepository) DeleteByToken(ctx context.Context, token string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM push_tokens WHERE token = $1`, token); err != nil {
+		return fmt.Errorf("failed to delete push token: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresPushTokenRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresPushTokenRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "push@example.com")
+	other := createTestUser(t, db, "other-push@example.com")
+
+	pixel := "Pixel 8"
+	android := &models.PushToken{UserID: user.ID, Provider: models.PushProviderFCM, Token: "fcm-token-1", DeviceName: &pixel}
+	iphone := &models.PushToken{UserID: user.ID, Provider: models.PushProviderAPNs, Token: "apns-token-1"}
+	require.NoError(t, repo.Register(ctx, android))
+	require.NoError(t, repo.Register(ctx, iphone))
+	assert.NotEqual(t, uuid.Nil, android.ID)
+
+	// Registering the same token again keeps its ID
+	again := &models.PushToken{UserID: user.ID, Provider: models.PushProviderFCM, Token: "fcm-token-1", DeviceName: &pixel}
+	require.NoError(t, repo.Register(ctx, again))
+	assert.Equal(t, android.ID, again.ID)
+
+	tokens, err := repo.ListByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "fcm-token-1", tokens[0].Token)
+	require.NotNil(t, tokens[0].DeviceName)
+	assert.Equal(t, "Pixel 8", *tokens[0].DeviceName)
+
+	// A token registered by another user moves to them
+	require.NoError(t, repo.Register(ctx, &models.PushToken{UserID: other.ID, Provider: models.PushProviderAPNs, Token: "apns-token-1"}))
+	tokens, err = repo.ListByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+
+	assert.ErrorIs(t, repo.Delete(ctx, other.ID, android.ID), ErrPushTokenNotFound)
+	require.NoError(t, repo.Delete(ctx, user.ID, android.ID))
+
+	require.NoError(t, repo.DeleteByToken(ctx, "apns-token-1"))
+	tokens, err = repo.ListByUserID(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+}
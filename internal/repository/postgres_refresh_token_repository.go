@@ -34,11 +34,36 @@ func (r *PostgresRefreshTokenRepository) Create(ctx context.Context, token *mode
 	query := `
 		INSERT INTO refresh_tokens (
 			id, user_id, token_hash, expires_at, created_at,
-			revoked_at, replaced_by, user_agent, ip_address
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			revoked_at, replaced_by, user_agent, ip_address,
+			remember_me, session_started_at,
+			geo_country_code, geo_country, geo_region, geo_city,
+			client_fingerprint
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
-	_, err := r.db.ExecContext(
+	// A token without a session start begins a new session
+	sessionStartedAt := token.SessionStartedAt
+	if sessionStartedAt.IsZero() {
+		sessionStartedAt = token.CreatedAt
+	}
+
+	var countryCode, country, region, city *string
+	if token.Location != nil {
+		countryCode = &token.Location.CountryCode
+		country = nullIfEmpty(token.Location.Country)
+		region = nullIfEmpty(token.Location.Region)
+		city = nullIfEmpty(token.Location.City)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	_, err = tx.ExecContext(
 		ctx,
 		query,
 		token.ID,
@@ -50,12 +75,26 @@ func (r *PostgresRefreshTokenRepository) Create(ctx context.Context, token *mode
 		token.ReplacedBy,
 		token.UserAgent,
 		token.IPAddress,
+		token.RememberMe,
+		sessionStartedAt,
+		countryCode,
+		country,
+		region,
+		city,
+		nullIfEmpty(token.ClientFingerprint),
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to insert refresh token: %w", err)
 	}
 
+	if err := appendEvents(ctx, tx, []*models.RefreshToken{token}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit refresh token: %w", err)
+	}
 	return nil
 }
 
@@ -64,7 +103,10 @@ func (r *PostgresRefreshTokenRepository) GetByHash(ctx context.Context, hash str
 	query := `
 		SELECT 
 			id, user_id, token_hash, expires_at, created_at,
-			revoked_at, replaced_by, user_agent, ip_address
+			revoked_at, replaced_by, user_agent, ip_address,
+			remember_me, session_started_at,
+			geo_country_code, geo_country, geo_region, geo_city,
+			client_fingerprint
 		FROM refresh_tokens
 		WHERE token_hash = $1
 	`
@@ -72,6 +114,8 @@ func (r *PostgresRefreshTokenRepository) GetByHash(ctx context.Context, hash str
 	var token models.RefreshToken
 	var revokedAt sql.NullTime
 	var replacedBy *uuid.UUID
+	var location geoColumns
+	var fingerprint sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, hash).Scan(
 		&token.ID,
@@ -83,6 +127,13 @@ func (r *PostgresRefreshTokenRepository) GetByHash(ctx context.Context, hash str
 		&replacedBy,
 		&token.UserAgent,
 		&token.IPAddress,
+		&token.RememberMe,
+		&token.SessionStartedAt,
+		&location.countryCode,
+		&location.country,
+		&location.region,
+		&location.city,
+		&fingerprint,
 	)
 
 	if err != nil {
@@ -96,6 +147,8 @@ func (r *PostgresRefreshTokenRepository) GetByHash(ctx context.Context, hash str
 		token.RevokedAt = &revokedAt.Time
 	}
 	token.ReplacedBy = replacedBy
+	token.Location = location.toLocation()
+	token.ClientFingerprint = fingerprint.String
 
 	// Check if token is revoked
 	if token.RevokedAt != nil {
@@ -172,6 +225,122 @@ func (r *PostgresRefreshTokenRepository) RevokeAllForUser(ctx context.Context, u
 	return err
 }
 
+// ListActiveSessions retrieves the user's signed-in clients, most recently used first
+// Each refresh rotates the token and links the new one to its predecessor through replaced_by,
+// so the sign-in time is found by walking the chain back from the active token.
+func (r *PostgresRefreshTokenRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*models.LoginSession, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id AS session_id, replaced_by, created_at
+			FROM refresh_tokens
+			WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+			UNION ALL
+			SELECT c.session_id, t.replaced_by, t.created_at
+			FROM chain c
+			JOIN refresh_tokens t ON t.id = c.replaced_by
+		)
+		SELECT t.id, t.user_agent, t.ip_address, MIN(c.created_at), t.created_at, t.expires_at, t.remember_me,
+			t.geo_country_code, t.geo_country, t.geo_region, t.geo_city
+		FROM refresh_tokens t
+		JOIN chain c ON c.session_id = t.id
+		GROUP BY t.id
+		ORDER BY t.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	sessions := []*models.LoginSession{}
+	for rows.Next() {
+		session := &models.LoginSession{}
+		var userAgent, ipAddress sql.NullString
+		var location geoColumns
+		if err := rows.Scan(
+			&session.ID,
+			&userAgent,
+			&ipAddress,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+			&session.RememberMe,
+			&location.countryCode,
+			&location.country,
+			&location.region,
+			&location.city,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		session.UserAgent = userAgent.String
+		session.IPAddress = ipAddress.String
+		session.Location = location.toLocation()
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ListCountries retrieves the distinct countries the user's stored refresh tokens were issued in
+// Expired tokens are deleted by DeleteExpired, so countries not seen for a while drop out.
+func (r *PostgresRefreshTokenRepository) ListCountries(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT geo_country_code
+		FROM refresh_tokens
+		WHERE user_id = $1 AND geo_country_code IS NOT NULL
+		ORDER BY geo_country_code
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login countries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	countries := []string{}
+	for rows.Next() {
+		var country string
+		if err := rows.Scan(&country); err != nil {
+			return nil, fmt.Errorf("failed to scan login country: %w", err)
+		}
+		countries = append(countries, country)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate login countries: %w", err)
+	}
+
+	return countries, nil
+}
+
+// RevokeForUser revokes an active refresh token owned by the user
+func (r *PostgresRefreshTokenRepository) RevokeForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRefreshTokenNotFound
+	}
+
+	return nil
+}
+
 // DeleteExpired removes all expired tokens and returns the count
 func (r *PostgresRefreshTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	query := `
@@ -191,3 +360,29 @@ func (r *PostgresRefreshTokenRepository) DeleteExpired(ctx context.Context) (int
 
 	return rowsAffected, nil
 }
+
+// geoColumns scans the nullable GeoIP location columns of a refresh token
+type geoColumns struct {
+	countryCode, country, region, city sql.NullString
+}
+
+// toLocation returns the scanned location, or nil if the token has none
+func (g geoColumns) toLocation() *models.GeoLocation {
+	if !g.countryCode.Valid {
+		return nil
+	}
+	return &models.GeoLocation{
+		CountryCode: g.countryCode.String,
+		Country:     g.country.String,
+		Region:      g.region.String,
+		City:        g.city.String,
+	}
+}
+
+// nullIfEmpty stores empty strings as NULL
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
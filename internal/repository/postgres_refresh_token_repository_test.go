@@ -55,6 +55,98 @@ func TestPostgresRefreshTokenRepository_Create(t *testing.T) {
 	assert.Equal(t, token.IPAddress, retrieved.IPAddress)
 }
 
+func TestPostgresRefreshTokenRepository_Create_RememberMe(t *testing.T) {
+	db, cleanup := setupRefreshTokenTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRefreshTokenRepository(db.DB)
+	userRepo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{ID: uuid.New(), Email: "remember@example.com", PasswordHash: "hash", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	// A rotated token carries the session start of the login
+	startedAt := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Microsecond)
+	rotated := &models.RefreshToken{
+		ID:               uuid.New(),
+		UserID:           user.ID,
+		TokenHash:        "remember-hash",
+		ExpiresAt:        time.Now().Add(24 * time.Hour),
+		CreatedAt:        time.Now(),
+		RememberMe:       true,
+		SessionStartedAt: startedAt,
+	}
+	require.NoError(t, repo.Create(ctx, rotated))
+
+	retrieved, err := repo.GetByHash(ctx, rotated.TokenHash)
+	require.NoError(t, err)
+	assert.True(t, retrieved.RememberMe)
+	assert.True(t, startedAt.Equal(retrieved.SessionStartedAt))
+
+	// Without a session start the token begins a new session
+	created := time.Now().UTC().Truncate(time.Microsecond)
+	login := &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: "login-hash",
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+		CreatedAt: created,
+	}
+	require.NoError(t, repo.Create(ctx, login))
+
+	retrieved, err = repo.GetByHash(ctx, login.TokenHash)
+	require.NoError(t, err)
+	assert.False(t, retrieved.RememberMe)
+	assert.True(t, created.Equal(retrieved.SessionStartedAt))
+}
+
+func TestPostgresRefreshTokenRepository_Location(t *testing.T) {
+	db, cleanup := setupRefreshTokenTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRefreshTokenRepository(db.DB)
+	userRepo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{ID: uuid.New(), Email: "located@example.com", PasswordHash: "hash", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	countries, err := repo.ListCountries(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, countries)
+
+	tokens := []*models.RefreshToken{
+		{TokenHash: "paris", Location: &models.GeoLocation{CountryCode: "FR", Country: "France", Region: "Ile-de-France", City: "Paris"}},
+		{TokenHash: "london", Location: &models.GeoLocation{CountryCode: "GB"}},
+		{TokenHash: "lyon", Location: &models.GeoLocation{CountryCode: "FR", Country: "France", City: "Lyon"}},
+		{TokenHash: "unknown"},
+	}
+	for _, token := range tokens {
+		token.ID = uuid.New()
+		token.UserID = user.ID
+		token.ExpiresAt = time.Now().Add(24 * time.Hour)
+		token.CreatedAt = time.Now()
+		require.NoError(t, repo.Create(ctx, token))
+	}
+
+	retrieved, err := repo.GetByHash(ctx, "paris")
+	require.NoError(t, err)
+	assert.Equal(t, tokens[0].Location, retrieved.Location)
+
+	retrieved, err = repo.GetByHash(ctx, "london")
+	require.NoError(t, err)
+	assert.Equal(t, &models.GeoLocation{CountryCode: "GB"}, retrieved.Location)
+
+	retrieved, err = repo.GetByHash(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, retrieved.Location)
+
+	countries, err = repo.ListCountries(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FR", "GB"}, countries)
+}
+
 func TestPostgresRefreshTokenRepository_GetByHash(t *testing.T) {
 	db, cleanup := setupRefreshTokenTestDB(t)
 	defer cleanup()
@@ -434,6 +526,79 @@ func TestPostgresRefreshTokenRepository_DeleteExpired(t *testing.T) {
 }
 
 // setupRefreshTokenTestDB creates a test database with the necessary tables
+func TestPostgresRefreshTokenRepository_ListActiveSessions(t *testing.T) {
+	db, cleanup := setupRefreshTokenTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRefreshTokenRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "sessions@example.com")
+	other := createTestUser(t, db, "other-sessions@example.com")
+
+	// Laptop: signed in three days ago and refreshed twice since
+	signedIn := time.Now().Add(-72 * time.Hour).Truncate(time.Microsecond)
+	login := &models.RefreshToken{
+		ID: uuid.New(), UserID: user.ID, TokenHash: "laptop-1",
+		ExpiresAt: time.Now().Add(24 * time.Hour), CreatedAt: signedIn,
+		UserAgent: "Firefox", IPAddress: "203.0.113.1",
+	}
+	require.NoError(t, repo.Create(ctx, login))
+	require.NoError(t, repo.Revoke(ctx, login.ID))
+	refreshed := &models.RefreshToken{
+		ID: uuid.New(), UserID: user.ID, TokenHash: "laptop-2",
+		ExpiresAt: time.Now().Add(24 * time.Hour), CreatedAt: time.Now().Add(-24 * time.Hour),
+		ReplacedBy: &login.ID, UserAgent: "Firefox", IPAddress: "203.0.113.1",
+	}
+	require.NoError(t, repo.Create(ctx, refreshed))
+	require.NoError(t, repo.Revoke(ctx, refreshed.ID))
+	laptop := &models.RefreshToken{
+		ID: uuid.New(), UserID: user.ID, TokenHash: "laptop-3",
+		ExpiresAt: time.Now().Add(24 * time.Hour), CreatedAt: time.Now().Add(-time.Hour),
+		ReplacedBy: &refreshed.ID, UserAgent: "Firefox", IPAddress: "203.0.113.2",
+	}
+	require.NoError(t, repo.Create(ctx, laptop))
+
+	// Phone: signed in once, more recently used
+	phone := &models.RefreshToken{
+		ID: uuid.New(), UserID: user.ID, TokenHash: "phone-1",
+		ExpiresAt: time.Now().Add(24 * time.Hour), CreatedAt: time.Now().Add(-time.Minute),
+		UserAgent: "RaceBox/2.1 (iOS)", IPAddress: "198.51.100.4",
+	}
+	require.NoError(t, repo.Create(ctx, phone))
+
+	// Expired and other users' tokens are not listed
+	require.NoError(t, repo.Create(ctx, &models.RefreshToken{
+		ID: uuid.New(), UserID: user.ID, TokenHash: "expired",
+		ExpiresAt: time.Now().Add(-time.Hour), CreatedAt: time.Now().Add(-48 * time.Hour),
+	}))
+	require.NoError(t, repo.Create(ctx, &models.RefreshToken{
+		ID: uuid.New(), UserID: other.ID, TokenHash: "other",
+		ExpiresAt: time.Now().Add(24 * time.Hour), CreatedAt: time.Now(),
+	}))
+
+	sessions, err := repo.ListActiveSessions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	assert.Equal(t, phone.ID, sessions[0].ID, "most recently used first")
+	assert.Equal(t, "RaceBox/2.1 (iOS)", sessions[0].UserAgent)
+
+	assert.Equal(t, laptop.ID, sessions[1].ID)
+	assert.Equal(t, "203.0.113.2", sessions[1].IPAddress, "metadata comes from the latest refresh")
+	assert.WithinDuration(t, signedIn, sessions[1].CreatedAt, time.Millisecond, "sign-in time comes from the start of the chain")
+	assert.WithinDuration(t, laptop.CreatedAt, sessions[1].LastUsedAt, time.Millisecond)
+
+	// Revoke one session
+	assert.ErrorIs(t, repo.RevokeForUser(ctx, phone.ID, other.ID), ErrRefreshTokenNotFound)
+	require.NoError(t, repo.RevokeForUser(ctx, phone.ID, user.ID))
+	assert.ErrorIs(t, repo.RevokeForUser(ctx, phone.ID, user.ID), ErrRefreshTokenNotFound)
+
+	sessions, err = repo.ListActiveSessions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, laptop.ID, sessions[0].ID)
+}
+
 func setupRefreshTokenTestDB(t *testing.T) (*database.DB, func()) {
 	t.Helper()
 	return setupTestDB(t)
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrReportNotFound is returned when a report is not found
+	ErrReportNotFound = errors.New("report not found")
+
+	// ErrReportNotReady is returned when the file of a report that has not completed is requested
+	ErrReportNotReady = errors.New("report not ready")
+)
+
+// reportColumns is the column list used by all report SELECT queries
+// The file is only read by GetContent.
+const reportColumns = `
+	id, requested_by, org_id, format, status,
+	range_start, range_end, row_count, error,
+	created_at, completed_at, expires_at
+`
+
+// PostgresReportRepository implements ReportRepository using PostgreSQL
+type PostgresReportRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresReportRepository creates a new PostgreSQL report repository
+func NewPostgresReportRepository(db *sql.DB) *PostgresReportRepository {
+	return &PostgresReportRepository{db: db}
+}
+
+// Create stores a new report
+func (r *PostgresReportRepository) Create(ctx context.Context, report *models.Report) error {
+	query := `
+		INSERT INTO reports (
+			id, requested_by, org_id, format, status,
+			range_start, range_end, created_at, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	if report.ID == uuid.Nil {
+		report.ID = uuid.New()
+	}
+	if report.Status == "" {
+		report.Status = models.ReportPending
+	}
+	if report.CreatedAt.IsZero() {
+		report.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		report.ID,
+		report.RequestedBy,
+		report.OrgID,
+		report.Format,
+		report.Status,
+		report.From,
+		report.To,
+		report.CreatedAt,
+		report.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert report: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a report by its UUID, without its file
+func (r *PostgresReportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE id = $1`
+
+	var report models.Report
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&report.ID,
+		&report.RequestedBy,
+		&report.OrgID,
+		&report.Format,
+		&report.Status,
+		&report.From,
+		&report.To,
+		&report.Rows,
+		&report.Error,
+		&report.CreatedAt,
+		&report.CompletedAt,
+		&report.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// GetContent retrieves the file of a completed report
+func (r *PostgresReportRepository) GetContent(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	query := `SELECT status, content FROM reports WHERE id = $1`
+
+	var status models.ReportStatus
+	var content []byte
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&status, &content); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get report content: %w", err)
+	}
+	if status != models.ReportCompleted {
+		return nil, ErrReportNotReady
+	}
+
+	return content, nil
+}
+
+// UpdateStatus records the report's status
+func (r *PostgresReportRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.ReportStatus, errMsg *string) error {
+	query := `
+		UPDATE reports
+		SET status = $2,
+			error = $3,
+			completed_at = CASE WHEN $4 THEN NOW() ELSE NULL END
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, status, errMsg, status.IsFinished())
+	if err != nil {
+		return fmt.Errorf("failed to update report: %w", err)
+	}
+
+	return reportAffected(result)
+}
+
+// Complete stores the report's file and marks it completed
+func (r *PostgresReportRepository) Complete(ctx context.Context, id uuid.UUID, rows int, content []byte) error {
+	query := `
+		UPDATE reports
+		SET status = 'completed', row_count = $2, content = $3, error = NULL, completed_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, rows, content)
+	if err != nil {
+		return fmt.Errorf("failed to complete report: %w", err)
+	}
+
+	return reportAffected(result)
+}
+
+// FailIncomplete marks every pending or processing report as failed, returning how many were affected
+func (r *PostgresReportRepository) FailIncomplete(ctx context.Context, errMsg string) (int64, error) {
+	query := `
+		UPDATE reports
+		SET status = 'failed', error = $1, completed_at = NOW()
+		WHERE status IN ('pending', 'processing')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, errMsg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail incomplete reports: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// DeleteExpired deletes reports that expired before the given time, returning how many were deleted
+func (r *PostgresReportRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reports WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired reports: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// Summarize aggregates the sessions started from from up to to, exclusive, per device and week
+// Sessions still in progress count towards usage up to now.
+func (r *PostgresReportRepository) Summarize(ctx context.Context, orgID *uuid.UUID, from, to time.Time) ([]*models.ReportRow, error) {
+	query := `
+		SELECT
+			s.device_id,
+			date_trunc('week', s.started_at AT TIME ZONE 'UTC') AS week_start,
+			COUNT(*),
+			COALESCE(SUM(s.total_distance), 0),
+			COALESCE(SUM(EXTRACT(EPOCH FROM (COALESCE(s.ended_at, NOW()) - s.started_at))), 0) / 3600,
+			COALESCE(MAX(s.max_speed), 0)
+		FROM sessions s
+		WHERE s.started_at >= $1 AND s.started_at < $2
+			AND ($3::uuid IS NULL
+				OR s.org_id = $3
+				OR s.device_id IN (SELECT d.device_id FROM devices d WHERE d.org_id = $3))
+		GROUP BY s.device_id, week_start
+		ORDER BY s.device_id, week_start
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var summary []*models.ReportRow
+	for rows.Next() {
+		var row models.ReportRow
+		if err := rows.Scan(&row.DeviceID, &row.WeekStart, &row.Sessions, &row.DistanceMeters, &row.UsageHours, &row.MaxSpeed); err != nil {
+			return nil, fmt.Errorf("failed to scan report row: %w", err)
+		}
+		row.WeekStart = time.Date(row.WeekStart.Year(), row.WeekStart.Month(), row.WeekStart.Day(), 0, 0, 0, 0, time.UTC)
+		summary = append(summary, &row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating report rows: %w", err)
+	}
+
+	return summary, nil
+}
+
+// reportAffected returns ErrReportNotFound if an update matched no report
+func reportAffected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrReportNotFound
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresReportRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresReportRepository(db.DB)
+	sessionRepo := NewPostgresSessionRepository(db.DB)
+	orgRepo := NewPostgresOrganizationRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "reports@example.com")
+
+	org := &models.Organization{Name: "Team"}
+	require.NoError(t, orgRepo.Create(ctx, org, user.ID))
+
+	// Two sessions in one week and one in the next; the organization only shares the first
+	monday := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+	addSession := func(deviceID string, start time.Time, duration time.Duration, distance, maxSpeed float64, orgID *uuid.UUID) {
+		end := start.Add(duration)
+		session := &models.Session{DeviceID: deviceID, UserID: &user.ID, StartedAt: start, EndedAt: &end, OrgID: orgID}
+		require.NoError(t, sessionRepo.Create(ctx, session))
+		_, err := db.ExecContext(ctx, `UPDATE sessions SET total_distance = $2, max_speed = $3 WHERE id = $1`, session.ID, distance, maxSpeed)
+		require.NoError(t, err)
+	}
+	addSession("AVT-001", monday.Add(10*time.Hour), time.Hour, 10000, 120, &org.ID)
+	addSession("AVT-001", monday.Add(58*time.Hour), 30*time.Minute, 5000, 140, nil)
+	addSession("AVT-001", monday.Add(8*24*time.Hour), 2*time.Hour, 20000, 110, nil)
+	addSession("AVT-002", monday.Add(12*time.Hour), time.Hour, 8000, 90, nil)
+
+	rows, err := repo.Summarize(ctx, nil, monday, monday.Add(14*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "AVT-001", rows[0].DeviceID)
+	assert.Equal(t, monday, rows[0].WeekStart)
+	assert.Equal(t, 2, rows[0].Sessions)
+	assert.InDelta(t, 15000, rows[0].DistanceMeters, 0.001)
+	assert.InDelta(t, 1.5, rows[0].UsageHours, 0.001)
+	assert.InDelta(t, 140, rows[0].MaxSpeed, 0.001)
+	assert.Equal(t, monday.Add(7*24*time.Hour), rows[1].WeekStart)
+	assert.Equal(t, "AVT-002", rows[2].DeviceID)
+
+	rows, err = repo.Summarize(ctx, &org.ID, monday, monday.Add(14*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 1, rows[0].Sessions)
+
+	report := &models.Report{
+		RequestedBy: user.ID,
+		OrgID:       &org.ID,
+		Format:      models.ReportCSV,
+		From:        monday,
+		To:          monday.Add(13 * 24 * time.Hour),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, report))
+
+	got, err := repo.GetByID(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportPending, got.Status)
+	assert.Equal(t, monday, got.From.UTC())
+	_, err = repo.GetContent(ctx, report.ID)
+	assert.ErrorIs(t, err, ErrReportNotReady)
+
+	require.NoError(t, repo.Complete(ctx, report.ID, 1, []byte("deviceId\nAVT-001\n")))
+	got, err = repo.GetByID(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportCompleted, got.Status)
+	assert.Equal(t, 1, got.Rows)
+	assert.NotNil(t, got.CompletedAt)
+	content, err := repo.GetContent(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "deviceId\nAVT-001\n", string(content))
+
+	// Only unfinished reports are failed on restart
+	interrupted := &models.Report{RequestedBy: user.ID, Format: models.ReportXLSX, From: monday, To: monday, ExpiresAt: time.Now().Add(-time.Minute)}
+	require.NoError(t, repo.Create(ctx, interrupted))
+	failed, err := repo.FailIncomplete(ctx, "interrupted")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), failed)
+
+	deleted, err := repo.DeleteExpired(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	_, err = repo.GetByID(ctx, interrupted.ID)
+	assert.ErrorIs(t, err, ErrReportNotFound)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrReportNotFound)
+	assert.ErrorIs(t, repo.UpdateStatus(ctx, uuid.New(), models.ReportFailed, nil), ErrReportNotFound)
+}
@@ -3,16 +3,81 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/sebasr/avt-service/internal/database"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
 )
 
+// telemetryColumns is the column list used by all telemetry SELECT queries
+const telemetryColumns = `
+	id, recorded_at, device_id, session_id, user_id, itow, time_accuracy, validity_flags,
+	latitude, longitude, wgs_altitude, msl_altitude, speed, heading,
+	num_satellites, fix_status, is_fix_valid,
+	horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
+	g_force_x, g_force_y, g_force_z,
+	rotation_x, rotation_y, rotation_z,
+	battery, is_charging, schema_version, extras,
+	rpm, throttle, brake_pressure, coolant_temp, gear, client_id, quality_flags,
+	received_at, clock_skew_ms, device_recorded_at,
+	total_g, speed_delta, vertical_speed, distance_increment
+`
+
+// processedTelemetryColumns is telemetryColumns with the smoothed position and speed in place of the raw ones
+// It selects from telemetry joined with processedTelemetryJoin.
+const processedTelemetryColumns = `
+	id, recorded_at, device_id, session_id, user_id, itow, time_accuracy, validity_flags,
+	processed_latitude, processed_longitude, wgs_altitude, msl_altitude, processed_speed, heading,
+	num_satellites, fix_status, is_fix_valid,
+	horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
+	g_force_x, g_force_y, g_force_z,
+	rotation_x, rotation_y, rotation_z,
+	battery, is_charging, schema_version, extras,
+	rpm, throttle, brake_pressure, coolant_temp, gear, client_id, quality_flags,
+	received_at, clock_skew_ms, device_recorded_at,
+	total_g, speed_delta, vertical_speed, distance_increment
+`
+
+// visibleTelemetry excludes records deleted by a telemetry correction that have not been purged yet
+const visibleTelemetry = "deletion_id IS NULL"
+
+// taggedDeviceCondition restricts telemetry to the hardware IDs of the devices carrying a tag
+const taggedDeviceCondition = "device_id IN (SELECT d.device_id FROM devices d JOIN device_tags dt ON dt.device_id = d.id WHERE dt.tag = $%d)"
+
+// postgresBinder binds paging values as positional parameters appended to args
+func postgresBinder(args *[]interface{}) pagination.Binder {
+	return func(value any) string {
+		*args = append(*args, value)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+}
+
+// processedTelemetryJoin joins each telemetry record to its smoothed counterpart
+// Only renamed columns of telemetry_processed are exposed, so the unqualified telemetry column
+// names used by queries and conditions stay unambiguous.
+const processedTelemetryJoin = `
+	JOIN (
+		SELECT telemetry_id, telemetry_recorded_at,
+			latitude AS processed_latitude, longitude AS processed_longitude, speed AS processed_speed
+		FROM telemetry_processed
+	) processed ON processed.telemetry_id = telemetry.id AND processed.telemetry_recorded_at = telemetry.recorded_at
+`
+
+// telemetryDedupIndex is the unique index backing telemetry deduplication
+const telemetryDedupIndex = "idx_telemetry_dedup"
+
 // PostgresRepository implements TelemetryRepository using PostgreSQL/TimescaleDB
+// Telemetry history reads go to the read replica when one is configured and healthy.
+// Writes and lookups that must see them, such as batch idempotency checks, stay on the primary.
 type PostgresRepository struct {
-	db *database.DB
+	db    *database.DB
+	dedup bool // Skip records that match an existing (device_id, itow, recorded_at) row
 }
 
 // NewPostgresRepository creates a new PostgreSQL telemetry repository
@@ -20,34 +85,117 @@ func NewPostgresRepository(db *database.DB) *PostgresRepository {
 	return &PostgresRepository{db: db}
 }
 
+// WithDeduplication enables skipping duplicate records on insert
+// Call ApplyDeduplication at startup so the unique index matches the setting.
+func (r *PostgresRepository) WithDeduplication(enabled bool) *PostgresRepository {
+	r.dedup = enabled
+	return r
+}
+
+// ApplyDeduplication creates or drops the unique index used to detect duplicate telemetry
+// Creating the index first removes existing duplicates, keeping the earliest stored copy.
+func (r *PostgresRepository) ApplyDeduplication(ctx context.Context) error {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_indexes WHERE tablename = 'telemetry' AND indexname = $1)`,
+		telemetryDedupIndex,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check deduplication index: %w", err)
+	}
+
+	switch {
+	case r.dedup && !exists:
+		result, err := r.db.ExecContext(ctx, `
+			DELETE FROM telemetry a
+			USING telemetry b
+			WHERE a.device_id = b.device_id AND a.itow = b.itow AND a.recorded_at = b.recorded_at
+				AND a.id > b.id
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to remove duplicate telemetry: %w", err)
+		}
+		if removed, _ := result.RowsAffected(); removed > 0 {
+			slog.Info("Removed duplicate telemetry records", "count", removed)
+		}
+
+		query := `CREATE UNIQUE INDEX ` + telemetryDedupIndex + ` ON telemetry (device_id, itow, recorded_at)`
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to create deduplication index: %w", err)
+		}
+		slog.Info("Enabled telemetry deduplication")
+	case !r.dedup && exists:
+		if _, err := r.db.ExecContext(ctx, `DROP INDEX `+telemetryDedupIndex); err != nil {
+			return fmt.Errorf("failed to drop deduplication index: %w", err)
+		}
+		slog.Info("Disabled telemetry deduplication")
+	}
+
+	return nil
+}
+
+// scanInsertedID reads the ID returned by a telemetry insert, flagging rows skipped as duplicates
+// Inserts skip rows that conflict with the deduplication index or with a stored clientId.
+func (r *PostgresRepository) scanInsertedID(row *sql.Row, data *models.TelemetryData) error {
+	err := row.Scan(&data.ID)
+	if (r.dedup || data.ClientID != nil) && errors.Is(err, sql.ErrNoRows) {
+		data.Duplicate = true
+		return nil
+	}
+	return err
+}
+
 // Save saves a single telemetry data point
+// A record saved with events is saved as a batch of one, so its events share its transaction.
 func (r *PostgresRepository) Save(ctx context.Context, data *models.TelemetryData) error {
+	if hasEvents(ctx) {
+		if err := r.SaveBatch(ctx, []*models.TelemetryData{data}); err != nil {
+			return err
+		}
+		if data.Duplicate && data.ClientID != nil {
+			return r.scanClientRecordID(ctx, data)
+		}
+		return nil
+	}
+
 	// Try with PostGIS first, fall back to without if PostGIS is not available
 	query := `
 		INSERT INTO telemetry (
-			recorded_at, device_id, session_id, itow, time_accuracy, validity_flags,
+			recorded_at, device_id, session_id, user_id, itow, time_accuracy, validity_flags,
 			latitude, longitude, location,
 			wgs_altitude, msl_altitude, speed, heading,
 			num_satellites, fix_status, is_fix_valid,
 			horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
 			g_force_x, g_force_y, g_force_z,
 			rotation_x, rotation_y, rotation_z,
-			battery, is_charging
+			battery, is_charging, schema_version, extras,
+			rpm, throttle, brake_pressure, coolant_temp, gear, client_id, quality_flags,
+			received_at, clock_skew_ms, device_recorded_at,
+			total_g, speed_delta, vertical_speed, distance_increment
 		) VALUES (
-			$1, $2, $3, $4, $5, $6,
-			$7, $8, ST_SetSRID(ST_MakePoint($8, $7), 4326)::geography,
-			$9, $10, $11, $12,
-			$13, $14, $15,
-			$16, $17, $18, $19, $20,
-			$21, $22, $23,
-			$24, $25, $26,
-			$27, $28
-		)
+			$1, $2, $3, $4, $5, $6, $7,
+			$8, $9, ST_SetSRID(ST_MakePoint($9, $8), 4326)::geography,
+			$10, $11, $12, $13,
+			$14, $15, $16,
+			$17, $18, $19, $20, $21,
+			$22, $23, $24,
+			$25, $26, $27,
+			$28, $29, $30, $31,
+			$32, $33, $34, $35, $36, $37, $38,
+			$39, $40, $41,
+			$42, $43, $44, $45
+		) ON CONFLICT DO NOTHING
 		RETURNING id
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
-		data.Timestamp, data.DeviceID, data.SessionID,
+	version, extras, err := telemetrySchemaArgs(data)
+	if err != nil {
+		return err
+	}
+	vehicle, derived := vehicleArgs(data), derivedArgs(data)
+
+	row := r.db.QueryRowContext(ctx, query,
+		data.Timestamp, data.DeviceID, data.SessionID, data.UserID,
 		data.ITOW, data.TimeAccuracy, data.ValidityFlags,
 		data.GPS.Latitude, data.GPS.Longitude,
 		data.GPS.WgsAltitude, data.GPS.MslAltitude, data.GPS.Speed, data.GPS.Heading,
@@ -56,37 +204,47 @@ func (r *PostgresRepository) Save(ctx context.Context, data *models.TelemetryDat
 		data.GPS.SpeedAccuracy, data.GPS.HeadingAccuracy, data.GPS.PDOP,
 		data.Motion.GForceX, data.Motion.GForceY, data.Motion.GForceZ,
 		data.Motion.RotationX, data.Motion.RotationY, data.Motion.RotationZ,
-		data.Battery, data.IsCharging,
-	).Scan(&data.ID)
+		data.Battery, data.IsCharging, version, extras,
+		vehicle.RPM, vehicle.Throttle, vehicle.BrakePressure, vehicle.CoolantTemp, vehicle.Gear, data.ClientID, data.QualityFlags,
+		data.ReceivedAt, data.ClockSkewMs, data.DeviceTimestamp,
+		derived.TotalG, derived.SpeedDelta, derived.VerticalSpeed, derived.DistanceIncrement,
+	)
+	err = r.scanInsertedID(row, data)
 
 	// If PostGIS functions are not available, try without location column
 	if err != nil && (err.Error() == "pq: type \"geography\" does not exist" ||
 		err.Error() == "ERROR: type \"geography\" does not exist (SQLSTATE 42704)") {
 		queryNoLocation := `
 			INSERT INTO telemetry (
-				recorded_at, device_id, session_id, itow, time_accuracy, validity_flags,
+				recorded_at, device_id, session_id, user_id, itow, time_accuracy, validity_flags,
 				latitude, longitude,
 				wgs_altitude, msl_altitude, speed, heading,
 				num_satellites, fix_status, is_fix_valid,
 				horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
 				g_force_x, g_force_y, g_force_z,
 				rotation_x, rotation_y, rotation_z,
-				battery, is_charging
+				battery, is_charging, schema_version, extras,
+				rpm, throttle, brake_pressure, coolant_temp, gear, client_id, quality_flags,
+				received_at, clock_skew_ms, device_recorded_at,
+				total_g, speed_delta, vertical_speed, distance_increment
 			) VALUES (
-				$1, $2, $3, $4, $5, $6,
-				$7, $8,
-				$9, $10, $11, $12,
-				$13, $14, $15,
-				$16, $17, $18, $19, $20,
-				$21, $22, $23,
-				$24, $25, $26,
-				$27, $28
-			)
+				$1, $2, $3, $4, $5, $6, $7,
+				$8, $9,
+				$10, $11, $12, $13,
+				$14, $15, $16,
+				$17, $18, $19, $20, $21,
+				$22, $23, $24,
+				$25, $26, $27,
+				$28, $29, $30, $31,
+				$32, $33, $34, $35, $36, $37, $38,
+				$39, $40, $41,
+				$42, $43, $44, $45
+			) ON CONFLICT DO NOTHING
 			RETURNING id
 		`
 
-		err = r.db.QueryRowContext(ctx, queryNoLocation,
-			data.Timestamp, data.DeviceID, data.SessionID,
+		row = r.db.QueryRowContext(ctx, queryNoLocation,
+			data.Timestamp, data.DeviceID, data.SessionID, data.UserID,
 			data.ITOW, data.TimeAccuracy, data.ValidityFlags,
 			data.GPS.Latitude, data.GPS.Longitude,
 			data.GPS.WgsAltitude, data.GPS.MslAltitude, data.GPS.Speed, data.GPS.Heading,
@@ -95,14 +253,38 @@ func (r *PostgresRepository) Save(ctx context.Context, data *models.TelemetryDat
 			data.GPS.SpeedAccuracy, data.GPS.HeadingAccuracy, data.GPS.PDOP,
 			data.Motion.GForceX, data.Motion.GForceY, data.Motion.GForceZ,
 			data.Motion.RotationX, data.Motion.RotationY, data.Motion.RotationZ,
-			data.Battery, data.IsCharging,
-		).Scan(&data.ID)
+			data.Battery, data.IsCharging, version, extras,
+			vehicle.RPM, vehicle.Throttle, vehicle.BrakePressure, vehicle.CoolantTemp, vehicle.Gear, data.ClientID, data.QualityFlags,
+			data.ReceivedAt, data.ClockSkewMs, data.DeviceTimestamp,
+			derived.TotalG, derived.SpeedDelta, derived.VerticalSpeed, derived.DistanceIncrement,
+		)
+		err = r.scanInsertedID(row, data)
 	}
 
 	if err != nil {
 		return fmt.Errorf("failed to insert telemetry: %w", err)
 	}
 
+	// A replayed upload reports the ID of the row stored by the original one
+	if data.Duplicate && data.ClientID != nil {
+		if err := r.scanClientRecordID(ctx, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanClientRecordID sets the ID of the stored record with the same clientId and timestamp
+// The ID stays zero when the insert was skipped by the deduplication index instead.
+func (r *PostgresRepository) scanClientRecordID(ctx context.Context, data *models.TelemetryData) error {
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id FROM telemetry WHERE client_id = $1 AND recorded_at = $2`,
+		data.ClientID, data.Timestamp,
+	).Scan(&data.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to look up replayed telemetry: %w", err)
+	}
 	return nil
 }
 
@@ -123,24 +305,30 @@ func (r *PostgresRepository) SaveBatch(ctx context.Context, dataPoints []*models
 	// Try with PostGIS first
 	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO telemetry (
-			recorded_at, device_id, session_id, itow, time_accuracy, validity_flags,
+			recorded_at, device_id, session_id, user_id, itow, time_accuracy, validity_flags,
 			latitude, longitude, location,
 			wgs_altitude, msl_altitude, speed, heading,
 			num_satellites, fix_status, is_fix_valid,
 			horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
 			g_force_x, g_force_y, g_force_z,
 			rotation_x, rotation_y, rotation_z,
-			battery, is_charging
+			battery, is_charging, schema_version, extras,
+			rpm, throttle, brake_pressure, coolant_temp, gear, client_id, quality_flags,
+			received_at, clock_skew_ms, device_recorded_at,
+			total_g, speed_delta, vertical_speed, distance_increment
 		) VALUES (
-			$1, $2, $3, $4, $5, $6,
-			$7, $8, ST_SetSRID(ST_MakePoint($8, $7), 4326)::geography,
-			$9, $10, $11, $12,
-			$13, $14, $15,
-			$16, $17, $18, $19, $20,
-			$21, $22, $23,
-			$24, $25, $26,
-			$27, $28
-		)
+			$1, $2, $3, $4, $5, $6, $7,
+			$8, $9, ST_SetSRID(ST_MakePoint($9, $8), 4326)::geography,
+			$10, $11, $12, $13,
+			$14, $15, $16,
+			$17, $18, $19, $20, $21,
+			$22, $23, $24,
+			$25, $26, $27,
+			$28, $29, $30, $31,
+			$32, $33, $34, $35, $36, $37, $38,
+			$39, $40, $41,
+			$42, $43, $44, $45
+		) ON CONFLICT DO NOTHING
 		RETURNING id
 	`)
 
@@ -148,24 +336,30 @@ func (r *PostgresRepository) SaveBatch(ctx context.Context, dataPoints []*models
 	if err != nil {
 		stmt, err = tx.PrepareContext(ctx, `
 			INSERT INTO telemetry (
-				recorded_at, device_id, session_id, itow, time_accuracy, validity_flags,
+				recorded_at, device_id, session_id, user_id, itow, time_accuracy, validity_flags,
 				latitude, longitude,
 				wgs_altitude, msl_altitude, speed, heading,
 				num_satellites, fix_status, is_fix_valid,
 				horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
 				g_force_x, g_force_y, g_force_z,
 				rotation_x, rotation_y, rotation_z,
-				battery, is_charging
+				battery, is_charging, schema_version, extras,
+				rpm, throttle, brake_pressure, coolant_temp, gear, client_id, quality_flags,
+				received_at, clock_skew_ms, device_recorded_at,
+				total_g, speed_delta, vertical_speed, distance_increment
 			) VALUES (
-				$1, $2, $3, $4, $5, $6,
-				$7, $8,
-				$9, $10, $11, $12,
-				$13, $14, $15,
-				$16, $17, $18, $19, $20,
-				$21, $22, $23,
-				$24, $25, $26,
-				$27, $28
-			)
+				$1, $2, $3, $4, $5, $6, $7,
+				$8, $9,
+				$10, $11, $12, $13,
+				$14, $15, $16,
+				$17, $18, $19, $20, $21,
+				$22, $23, $24,
+				$25, $26, $27,
+				$28, $29, $30, $31,
+				$32, $33, $34, $35, $36, $37, $38,
+				$39, $40, $41,
+				$42, $43, $44, $45
+			) ON CONFLICT DO NOTHING
 			RETURNING id
 		`)
 	}
@@ -174,9 +368,15 @@ func (r *PostgresRepository) SaveBatch(ctx context.Context, dataPoints []*models
 	}
 	defer stmt.Close()
 
+	var stored []*models.TelemetryData
 	for _, data := range dataPoints {
-		err := stmt.QueryRowContext(ctx,
-			data.Timestamp, data.DeviceID, data.SessionID,
+		version, extras, err := telemetrySchemaArgs(data)
+		if err != nil {
+			return err
+		}
+		vehicle, derived := vehicleArgs(data), derivedArgs(data)
+		row := stmt.QueryRowContext(ctx,
+			data.Timestamp, data.DeviceID, data.SessionID, data.UserID,
 			data.ITOW, data.TimeAccuracy, data.ValidityFlags,
 			data.GPS.Latitude, data.GPS.Longitude,
 			data.GPS.WgsAltitude, data.GPS.MslAltitude, data.GPS.Speed, data.GPS.Heading,
@@ -185,11 +385,21 @@ func (r *PostgresRepository) SaveBatch(ctx context.Context, dataPoints []*models
 			data.GPS.SpeedAccuracy, data.GPS.HeadingAccuracy, data.GPS.PDOP,
 			data.Motion.GForceX, data.Motion.GForceY, data.Motion.GForceZ,
 			data.Motion.RotationX, data.Motion.RotationY, data.Motion.RotationZ,
-			data.Battery, data.IsCharging,
-		).Scan(&data.ID)
-		if err != nil {
+			data.Battery, data.IsCharging, version, extras,
+			vehicle.RPM, vehicle.Throttle, vehicle.BrakePressure, vehicle.CoolantTemp, vehicle.Gear, data.ClientID, data.QualityFlags,
+			data.ReceivedAt, data.ClockSkewMs, data.DeviceTimestamp,
+			derived.TotalG, derived.SpeedDelta, derived.VerticalSpeed, derived.DistanceIncrement,
+		)
+		if err := r.scanInsertedID(row, data); err != nil {
 			return fmt.Errorf("failed to insert telemetry in batch: %w", err)
 		}
+		if !data.Duplicate {
+			stored = append(stored, data)
+		}
+	}
+
+	if err := appendEvents(ctx, tx, stored); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -199,6 +409,40 @@ func (r *PostgresRepository) SaveBatch(ctx context.Context, dataPoints []*models
 	return nil
 }
 
+// telemetrySchemaArgs returns the schema version and encoded extra channels stored with a record
+// Records built without a decoder, such as imports, are stored as version 1.
+func telemetrySchemaArgs(data *models.TelemetryData) (int, []byte, error) {
+	version := data.SchemaVersion
+	if version == 0 {
+		version = models.TelemetrySchemaV1
+	}
+
+	if len(data.Extras) == 0 {
+		return version, nil, nil
+	}
+	extras, err := json.Marshal(data.Extras)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to encode telemetry extras: %w", err)
+	}
+	return version, extras, nil
+}
+
+// vehicleArgs returns the vehicle channels stored with a record, all null when it has none
+func vehicleArgs(data *models.TelemetryData) models.VehicleData {
+	if data.Vehicle == nil {
+		return models.VehicleData{}
+	}
+	return *data.Vehicle
+}
+
+// derivedArgs returns the derived channels stored with a record, all null when they were not computed
+func derivedArgs(data *models.TelemetryData) models.DerivedChannels {
+	if data.Derived == nil {
+		return models.DerivedChannels{}
+	}
+	return *data.Derived
+}
+
 // GetByTimeRange retrieves telemetry data within a time range
 func (r *PostgresRepository) GetByTimeRange(ctx context.Context, start, end time.Time, limit int) ([]*models.TelemetryData, error) {
 	if limit <= 0 {
@@ -206,21 +450,14 @@ func (r *PostgresRepository) GetByTimeRange(ctx context.Context, start, end time
 	}
 
 	query := `
-		SELECT 
-			id, recorded_at, device_id, session_id, itow, time_accuracy, validity_flags,
-			latitude, longitude, wgs_altitude, msl_altitude, speed, heading,
-			num_satellites, fix_status, is_fix_valid,
-			horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
-			g_force_x, g_force_y, g_force_z,
-			rotation_x, rotation_y, rotation_z,
-			battery, is_charging
+		SELECT ` + telemetryColumns + `
 		FROM telemetry
-		WHERE recorded_at BETWEEN $1 AND $2
+		WHERE recorded_at BETWEEN $1 AND $2 AND ` + visibleTelemetry + `
 		ORDER BY recorded_at DESC
 		LIMIT $3
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, start, end, limit)
+	rows, err := r.db.Reader().QueryContext(ctx, query, start, end, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query telemetry by time range: %w", err)
 	}
@@ -236,21 +473,14 @@ func (r *PostgresRepository) GetBySession(ctx context.Context, sessionID string,
 	}
 
 	query := `
-		SELECT 
-			id, recorded_at, device_id, session_id, itow, time_accuracy, validity_flags,
-			latitude, longitude, wgs_altitude, msl_altitude, speed, heading,
-			num_satellites, fix_status, is_fix_valid,
-			horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
-			g_force_x, g_force_y, g_force_z,
-			rotation_x, rotation_y, rotation_z,
-			battery, is_charging
+		SELECT ` + telemetryColumns + `
 		FROM telemetry
-		WHERE session_id = $1
+		WHERE session_id = $1 AND ` + visibleTelemetry + `
 		ORDER BY recorded_at ASC
 		LIMIT $2
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, sessionID, limit)
+	rows, err := r.db.Reader().QueryContext(ctx, query, sessionID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query telemetry by session: %w", err)
 	}
@@ -266,20 +496,14 @@ func (r *PostgresRepository) GetRecent(ctx context.Context, limit int) ([]*model
 	}
 
 	query := `
-		SELECT 
-			id, recorded_at, device_id, session_id, itow, time_accuracy, validity_flags,
-			latitude, longitude, wgs_altitude, msl_altitude, speed, heading,
-			num_satellites, fix_status, is_fix_valid,
-			horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
-			g_force_x, g_force_y, g_force_z,
-			rotation_x, rotation_y, rotation_z,
-			battery, is_charging
+		SELECT ` + telemetryColumns + `
 		FROM telemetry
+		WHERE ` + visibleTelemetry + `
 		ORDER BY recorded_at DESC
 		LIMIT $1
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, limit)
+	rows, err := r.db.Reader().QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query recent telemetry: %w", err)
 	}
@@ -295,21 +519,14 @@ func (r *PostgresRepository) GetByDevice(ctx context.Context, deviceID string, l
 	}
 
 	query := `
-		SELECT 
-			id, recorded_at, device_id, session_id, itow, time_accuracy, validity_flags,
-			latitude, longitude, wgs_altitude, msl_altitude, speed, heading,
-			num_satellites, fix_status, is_fix_valid,
-			horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
-			g_force_x, g_force_y, g_force_z,
-			rotation_x, rotation_y, rotation_z,
-			battery, is_charging
+		SELECT ` + telemetryColumns + `
 		FROM telemetry
-		WHERE device_id = $1
+		WHERE device_id = $1 AND ` + visibleTelemetry + `
 		ORDER BY recorded_at DESC
 		LIMIT $2
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, deviceID, limit)
+	rows, err := r.db.Reader().QueryContext(ctx, query, deviceID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query telemetry by device: %w", err)
 	}
@@ -318,34 +535,539 @@ func (r *PostgresRepository) GetByDevice(ctx context.Context, deviceID string, l
 	return r.scanTelemetryRows(rows)
 }
 
+// Query retrieves telemetry data matching the given filter
+func (r *PostgresRepository) Query(ctx context.Context, filter TelemetryFilter) ([]*models.TelemetryData, error) {
+	return r.queryTelemetry(ctx, filter, nil)
+}
+
+// Within retrieves the located telemetry inside the area matching the given filter, ordered
+// like Query
+// The GIST index on location narrows the rows to the area before the filter applies.
+func (r *PostgresRepository) Within(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) ([]*models.TelemetryData, error) {
+	filter.Processed = false
+	return r.queryTelemetry(ctx, filter, &area)
+}
+
+// queryTelemetry retrieves telemetry matching the filter, restricted to the area when one is given
+func (r *PostgresRepository) queryTelemetry(ctx context.Context, filter TelemetryFilter, area *models.GeoPolygon) ([]*models.TelemetryData, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	conditions := []string{"user_id = $1", visibleTelemetry}
+	args := []interface{}{filter.UserID}
+
+	addCondition := func(format string, values ...interface{}) {
+		placeholders := make([]interface{}, len(values))
+		for i, v := range values {
+			args = append(args, v)
+			placeholders[i] = len(args)
+		}
+		conditions = append(conditions, fmt.Sprintf(format, placeholders...))
+	}
+
+	if filter.DeviceID != "" {
+		addCondition("device_id = $%d", filter.DeviceID)
+	}
+	if filter.SessionID != "" {
+		addCondition("session_id = $%d", filter.SessionID)
+	}
+	if filter.Tag != "" {
+		addCondition(taggedDeviceCondition, filter.Tag)
+	}
+	if filter.From != nil {
+		addCondition("recorded_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("recorded_at <= $%d", *filter.To)
+	}
+	if area != nil {
+		addCondition(areaCondition, area.GeoJSON())
+	}
+	if after := telemetryKeyset.Apply(filter.After, postgresBinder(&args)); after != "" {
+		conditions = append(conditions, after)
+	}
+	if filter.CleanOnly {
+		conditions = append(conditions, "quality_flags = 0")
+	}
+
+	columns, source := telemetryColumns, "telemetry"
+	if filter.Processed {
+		columns, source = processedTelemetryColumns, "telemetry"+processedTelemetryJoin
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d
+	`, columns, source, strings.Join(conditions, " AND "), telemetryKeyset.OrderBy(), len(args))
+
+	rows, err := r.db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanTelemetryRows(rows)
+}
+
+// aggregateViews maps each aggregation interval to its continuous aggregate
+var aggregateViews = map[AggregateBucket]string{
+	Bucket1s:  "telemetry_1s",
+	Bucket1m:  "telemetry_1m",
+	Bucket10m: "telemetry_10m",
+}
+
+// aggregateIntervals maps each aggregation interval to its time_bucket width
+var aggregateIntervals = map[AggregateBucket]string{
+	Bucket1s:  "1 second",
+	Bucket1m:  "1 minute",
+	Bucket10m: "10 minutes",
+}
+
+// rawAggregateSource buckets raw telemetry with the columns of the continuous aggregates
+// Its verbs are the bucket interval, the speed column, the joined tables and the row condition.
+// The continuous aggregates include flagged points and only hold raw speeds, so clean and
+// processed charts are computed on the fly.
+const rawAggregateSource = `(
+	SELECT
+		time_bucket(INTERVAL '%s', recorded_at) AS bucket,
+		user_id,
+		device_id,
+		session_id,
+		COUNT(*) AS sample_count,
+		AVG(%[2]s) AS avg_speed,
+		MAX(%[2]s) AS max_speed,
+		AVG(g_force_x) AS avg_g_force_x,
+		AVG(g_force_y) AS avg_g_force_y,
+		AVG(g_force_z) AS avg_g_force_z,
+		MAX(SQRT(g_force_x * g_force_x + g_force_y * g_force_y)) AS max_g_force,
+		AVG(battery) AS avg_battery,
+		MIN(battery) AS min_battery,
+		COUNT(rpm) AS rpm_count,
+		AVG(rpm) AS avg_rpm,
+		MAX(rpm) AS max_rpm,
+		COUNT(throttle) AS throttle_count,
+		AVG(throttle) AS avg_throttle,
+		MAX(brake_pressure) AS max_brake_pressure,
+		MAX(coolant_temp) AS max_coolant_temp
+	FROM %[3]s
+	WHERE %[4]s
+	GROUP BY 1, user_id, device_id, session_id
+) AS raw_telemetry`
+
+// Aggregate retrieves downsampled telemetry matching the given filter in chronological order
+// Rows for several devices or sessions in the same bucket are merged, weighting averages by sample count.
+// Vehicle averages are weighted by the number of samples that carried the channel.
+func (r *PostgresRepository) Aggregate(ctx context.Context, filter TelemetryFilter, bucket AggregateBucket) ([]*models.TelemetryBucket, error) {
+	view, ok := aggregateViews[bucket]
+	if !ok {
+		return nil, fmt.Errorf("unsupported aggregate bucket %q", bucket)
+	}
+	if filter.CleanOnly || filter.Processed {
+		speed, source, condition := "speed", "telemetry", visibleTelemetry
+		if filter.Processed {
+			speed, source = "processed_speed", "telemetry"+processedTelemetryJoin
+		}
+		if filter.CleanOnly {
+			condition += " AND quality_flags = 0"
+		}
+		view = fmt.Sprintf(rawAggregateSource, aggregateIntervals[bucket], speed, source, condition)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{filter.UserID}
+
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.DeviceID != "" {
+		addCondition("device_id = $%d", filter.DeviceID)
+	}
+	if filter.SessionID != "" {
+		addCondition("session_id = $%d", filter.SessionID)
+	}
+	if filter.Tag != "" {
+		addCondition(taggedDeviceCondition, filter.Tag)
+	}
+	if filter.From != nil {
+		addCondition("bucket >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("bucket <= $%d", *filter.To)
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		SELECT
+			bucket,
+			SUM(sample_count),
+			SUM(avg_speed * sample_count) / SUM(sample_count),
+			MAX(max_speed),
+			SUM(avg_g_force_x * sample_count) / SUM(sample_count),
+			SUM(avg_g_force_y * sample_count) / SUM(sample_count),
+			SUM(avg_g_force_z * sample_count) / SUM(sample_count),
+			MAX(max_g_force),
+			SUM(avg_battery * sample_count) / SUM(sample_count),
+			MIN(min_battery),
+			SUM(avg_rpm * rpm_count) / NULLIF(SUM(rpm_count), 0),
+			MAX(max_rpm),
+			SUM(avg_throttle * throttle_count) / NULLIF(SUM(throttle_count), 0),
+			MAX(max_brake_pressure),
+			MAX(max_coolant_temp)
+		FROM %s
+		WHERE %s
+		GROUP BY bucket
+		ORDER BY bucket ASC
+		LIMIT $%d
+	`, view, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry aggregate: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*models.TelemetryBucket
+	for rows.Next() {
+		b := &models.TelemetryBucket{}
+		var avgSpeed, maxSpeed, avgGX, avgGY, avgGZ, maxG, avgBattery, minBattery sql.NullFloat64
+		if err := rows.Scan(
+			&b.Bucket, &b.Samples,
+			&avgSpeed, &maxSpeed,
+			&avgGX, &avgGY, &avgGZ, &maxG,
+			&avgBattery, &minBattery,
+			&b.AvgRPM, &b.MaxRPM, &b.AvgThrottle, &b.MaxBrakePressure, &b.MaxCoolantTemp,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry aggregate row: %w", err)
+		}
+
+		b.AvgSpeed = avgSpeed.Float64
+		b.MaxSpeed = maxSpeed.Float64
+		b.AvgGForceX = avgGX.Float64
+		b.AvgGForceY = avgGY.Float64
+		b.AvgGForceZ = avgGZ.Float64
+		b.MaxGForce = maxG.Float64
+		b.AvgBattery = avgBattery.Float64
+		b.MinBattery = minBattery.Float64
+
+		results = append(results, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry aggregate rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// Heatmap grids the located telemetry within the bounding box into Web Mercator cells sized for
+// the zoom level, densest first, up to the filter's limit
+// Points are binned in PostGIS after the GIST index on location narrows them to the box, so only
+// the cells leave the database.
+func (r *PostgresRepository) Heatmap(ctx context.Context, filter TelemetryFilter, bbox models.BoundingBox, zoom int) ([]*models.HeatmapCell, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	args := []interface{}{filter.UserID, models.HeatmapCellSize(zoom), bbox.West, bbox.South, bbox.East, bbox.North}
+	conditions := []string{
+		"user_id = $1",
+		"location && ST_MakeEnvelope($3, $4, $5, $6, 4326)::geography",
+		"longitude BETWEEN $3 AND $5",
+		"latitude BETWEEN $4 AND $6",
+		visibleTelemetry,
+	}
+
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.DeviceID != "" {
+		addCondition("device_id = $%d", filter.DeviceID)
+	}
+	if filter.SessionID != "" {
+		addCondition("session_id = $%d", filter.SessionID)
+	}
+	if filter.Tag != "" {
+		addCondition(taggedDeviceCondition, filter.Tag)
+	}
+	if filter.From != nil {
+		addCondition("recorded_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("recorded_at <= $%d", *filter.To)
+	}
+	if filter.CleanOnly {
+		conditions = append(conditions, "quality_flags = 0")
+	}
+
+	point, speed, source := "location::geometry", "speed", "telemetry"
+	if filter.Processed {
+		point = "ST_SetSRID(ST_MakePoint(processed_longitude, processed_latitude), 4326)"
+		speed, source = "processed_speed", "telemetry"+processedTelemetryJoin
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		WITH cells AS (
+			SELECT
+				floor(ST_X(point) / $2::float8) AS cell_x,
+				floor(ST_Y(point) / $2::float8) AS cell_y,
+				COUNT(*) AS points,
+				AVG(speed) AS avg_speed,
+				MAX(speed) AS max_speed
+			FROM (
+				SELECT ST_Transform(%s, 3857) AS point, %s AS speed
+				FROM %s
+				WHERE %s
+			) located
+			GROUP BY cell_x, cell_y
+			ORDER BY points DESC, cell_x, cell_y
+			LIMIT $%d
+		)
+		SELECT ST_Y(center), ST_X(center), points, avg_speed, max_speed
+		FROM (
+			SELECT
+				ST_Transform(ST_SetSRID(ST_MakePoint((cell_x + 0.5) * $2::float8, (cell_y + 0.5) * $2::float8), 3857), 4326) AS center,
+				points, avg_speed, max_speed
+			FROM cells
+		) centered
+		ORDER BY points DESC
+	`, point, speed, source, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry heatmap: %w", err)
+	}
+	defer rows.Close()
+
+	cells := []*models.HeatmapCell{}
+	for rows.Next() {
+		cell := &models.HeatmapCell{}
+		var avgSpeed, maxSpeed sql.NullFloat64
+		if err := rows.Scan(&cell.Latitude, &cell.Longitude, &cell.Points, &avgSpeed, &maxSpeed); err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry heatmap row: %w", err)
+		}
+		cell.AvgSpeed = avgSpeed.Float64
+		cell.MaxSpeed = maxSpeed.Float64
+		cells = append(cells, cell)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry heatmap rows: %w", err)
+	}
+
+	return cells, nil
+}
+
+// areaCondition matches telemetry located inside a GeoJSON polygon
+// Testing the geography column lets the planner use the GIST index on location.
+const areaCondition = "ST_Intersects(location, ST_SetSRID(ST_GeomFromGeoJSON($%d::text), 4326)::geography)"
+
+// WithinStats summarizes the located telemetry inside the area matching the given filter
+func (r *PostgresRepository) WithinStats(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) (*models.AreaStats, error) {
+	conditions := []string{"user_id = $1", visibleTelemetry}
+	args := []interface{}{filter.UserID}
+
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	addCondition(areaCondition, area.GeoJSON())
+	if filter.DeviceID != "" {
+		addCondition("device_id = $%d", filter.DeviceID)
+	}
+	if filter.SessionID != "" {
+		addCondition("session_id = $%d", filter.SessionID)
+	}
+	if filter.Tag != "" {
+		addCondition(taggedDeviceCondition, filter.Tag)
+	}
+	if filter.From != nil {
+		addCondition("recorded_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("recorded_at <= $%d", *filter.To)
+	}
+	if filter.CleanOnly {
+		conditions = append(conditions, "quality_flags = 0")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*),
+			COUNT(DISTINCT device_id),
+			COUNT(DISTINCT session_id),
+			MIN(recorded_at),
+			MAX(recorded_at),
+			AVG(speed),
+			MAX(speed)
+		FROM telemetry
+		WHERE %s
+	`, strings.Join(conditions, " AND "))
+
+	stats := &models.AreaStats{}
+	var first, last sql.NullTime
+	var avgSpeed, maxSpeed sql.NullFloat64
+	err := r.db.Reader().QueryRowContext(ctx, query, args...).Scan(
+		&stats.Points, &stats.Devices, &stats.Sessions, &first, &last, &avgSpeed, &maxSpeed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry area stats: %w", err)
+	}
+
+	if first.Valid {
+		stats.FirstRecordedAt = &first.Time
+	}
+	if last.Valid {
+		stats.LastRecordedAt = &last.Time
+	}
+	stats.AvgSpeed = avgSpeed.Float64
+	stats.MaxSpeed = maxSpeed.Float64
+	return stats, nil
+}
+
+// LatestPositions returns the last known position of each device recorded by its owner
+// A lateral join walks idx_telemetry_device_time backwards once per device, so the cost grows with
+// the number of devices rather than their history.
+func (r *PostgresRepository) LatestPositions(ctx context.Context, devices []DeviceOwner) ([]*models.DevicePosition, error) {
+	positions := []*models.DevicePosition{}
+	if len(devices) == 0 {
+		return positions, nil
+	}
+
+	deviceIDs := make([]string, len(devices))
+	userIDs := make([]string, len(devices))
+	for i, device := range devices {
+		deviceIDs[i] = device.DeviceID
+		userIDs[i] = device.UserID.String()
+	}
+
+	rows, err := r.db.Reader().QueryContext(ctx, `
+		SELECT d.device_id, t.recorded_at, t.latitude, t.longitude, t.speed, t.heading
+		FROM unnest($1::text[], $2::uuid[]) AS d(device_id, user_id)
+		CROSS JOIN LATERAL (
+			SELECT recorded_at, latitude, longitude, speed, heading
+			FROM telemetry
+			WHERE telemetry.device_id = d.device_id
+				AND telemetry.user_id = d.user_id
+				AND quality_flags = 0
+				AND `+visibleTelemetry+`
+			ORDER BY recorded_at DESC
+			LIMIT 1
+		) t
+		ORDER BY d.device_id
+	`, deviceIDs, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device positions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		position := &models.DevicePosition{}
+		var speed, heading sql.NullFloat64
+		if err := rows.Scan(&position.DeviceID, &position.RecordedAt, &position.Latitude, &position.Longitude, &speed, &heading); err != nil {
+			return nil, fmt.Errorf("failed to scan device position: %w", err)
+		}
+		position.Speed = speed.Float64
+		position.Heading = heading.Float64
+		positions = append(positions, position)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device positions: %w", err)
+	}
+
+	return positions, nil
+}
+
+// PreviousRecords returns, for each record, the latest stored record of its device recorded before
+// it and at most models.MaxDerivedGap earlier, or nil when there is none
+// A lateral join walks idx_telemetry_device_time backwards once per record. The primary is read so
+// that records saved moments ago by another instance are found.
+func (r *PostgresRepository) PreviousRecords(ctx context.Context, records []*models.TelemetryData) ([]*models.TelemetryData, error) {
+	previous := make([]*models.TelemetryData, len(records))
+
+	deviceIDs := make([]string, 0, len(records))
+	recordedAt := make([]time.Time, 0, len(records))
+	indexes := make([]int, 0, len(records))
+	for i, record := range records {
+		if record.DeviceID == "" {
+			continue
+		}
+		deviceIDs = append(deviceIDs, record.DeviceID)
+		recordedAt = append(recordedAt, record.Timestamp)
+		indexes = append(indexes, i)
+	}
+	if len(indexes) == 0 {
+		return previous, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.idx, r.device_id, t.recorded_at, t.latitude, t.longitude, t.msl_altitude, t.speed
+		FROM unnest($1::text[], $2::timestamptz[]) WITH ORDINALITY AS r(device_id, recorded_at, idx)
+		CROSS JOIN LATERAL (
+			SELECT recorded_at, latitude, longitude, msl_altitude, speed
+			FROM telemetry
+			WHERE telemetry.device_id = r.device_id
+				AND telemetry.recorded_at < r.recorded_at
+				AND telemetry.recorded_at >= r.recorded_at - $3 * INTERVAL '1 second'
+				AND `+visibleTelemetry+`
+			ORDER BY recorded_at DESC
+			LIMIT 1
+		) t
+	`, deviceIDs, recordedAt, models.MaxDerivedGap.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query previous telemetry: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			idx                                  int
+			latitude, longitude, altitude, speed sql.NullFloat64
+			record                               = &models.TelemetryData{}
+		)
+		if err := rows.Scan(&idx, &record.DeviceID, &record.Timestamp, &latitude, &longitude, &altitude, &speed); err != nil {
+			return nil, fmt.Errorf("failed to scan previous telemetry: %w", err)
+		}
+		record.GPS.Latitude, record.GPS.Longitude = latitude.Float64, longitude.Float64
+		record.GPS.MslAltitude, record.GPS.Speed = altitude.Float64, speed.Float64
+		previous[indexes[idx-1]] = record
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating previous telemetry: %w", err)
+	}
+
+	return previous, nil
+}
+
 // scanTelemetryRows scans database rows into TelemetryData structs
 func (r *PostgresRepository) scanTelemetryRows(rows *sql.Rows) ([]*models.TelemetryData, error) {
 	var results []*models.TelemetryData
 
 	for rows.Next() {
-		data := &models.TelemetryData{}
-		var sessionID sql.NullString
-
-		err := rows.Scan(
-			&data.ID, &data.Timestamp, &data.DeviceID, &sessionID,
-			&data.ITOW, &data.TimeAccuracy, &data.ValidityFlags,
-			&data.GPS.Latitude, &data.GPS.Longitude,
-			&data.GPS.WgsAltitude, &data.GPS.MslAltitude, &data.GPS.Speed, &data.GPS.Heading,
-			&data.GPS.NumSatellites, &data.GPS.FixStatus, &data.GPS.IsFixValid,
-			&data.GPS.HorizontalAccuracy, &data.GPS.VerticalAccuracy,
-			&data.GPS.SpeedAccuracy, &data.GPS.HeadingAccuracy, &data.GPS.PDOP,
-			&data.Motion.GForceX, &data.Motion.GForceY, &data.Motion.GForceZ,
-			&data.Motion.RotationX, &data.Motion.RotationY, &data.Motion.RotationZ,
-			&data.Battery, &data.IsCharging,
-		)
+		data, err := scanTelemetry(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan telemetry row: %w", err)
 		}
 
-		if sessionID.Valid {
-			data.SessionID = &sessionID.String
-		}
-
 		results = append(results, data)
 	}
 
@@ -356,6 +1078,147 @@ func (r *PostgresRepository) scanTelemetryRows(rows *sql.Rows) ([]*models.Teleme
 	return results, nil
 }
 
+// scanTelemetry scans a single telemetry row selected with telemetryColumns
+func scanTelemetry(row rowScanner) (*models.TelemetryData, error) {
+	data := &models.TelemetryData{}
+	var sessionID sql.NullString
+	var extras []byte
+	var vehicle models.VehicleData
+	var derived models.DerivedChannels
+	var receivedAt, deviceTimestamp sql.NullTime
+
+	err := row.Scan(
+		&data.ID, &data.Timestamp, &data.DeviceID, &sessionID, &data.UserID,
+		&data.ITOW, &data.TimeAccuracy, &data.ValidityFlags,
+		&data.GPS.Latitude, &data.GPS.Longitude,
+		&data.GPS.WgsAltitude, &data.GPS.MslAltitude, &data.GPS.Speed, &data.GPS.Heading,
+		&data.GPS.NumSatellites, &data.GPS.FixStatus, &data.GPS.IsFixValid,
+		&data.GPS.HorizontalAccuracy, &data.GPS.VerticalAccuracy,
+		&data.GPS.SpeedAccuracy, &data.GPS.HeadingAccuracy, &data.GPS.PDOP,
+		&data.Motion.GForceX, &data.Motion.GForceY, &data.Motion.GForceZ,
+		&data.Motion.RotationX, &data.Motion.RotationY, &data.Motion.RotationZ,
+		&data.Battery, &data.IsCharging, &data.SchemaVersion, &extras,
+		&vehicle.RPM, &vehicle.Throttle, &vehicle.BrakePressure, &vehicle.CoolantTemp, &vehicle.Gear, &data.ClientID, &data.QualityFlags,
+		&receivedAt, &data.ClockSkewMs, &deviceTimestamp,
+		&derived.TotalG, &derived.SpeedDelta, &derived.VerticalSpeed, &derived.DistanceIncrement,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if sessionID.Valid {
+		data.SessionID = &sessionID.String
+	}
+
+	if vehicle != (models.VehicleData{}) {
+		data.Vehicle = &vehicle
+	}
+
+	if derived != (models.DerivedChannels{}) {
+		data.Derived = &derived
+	}
+
+	if receivedAt.Valid {
+		data.ReceivedAt = &receivedAt.Time
+	}
+	if deviceTimestamp.Valid {
+		data.DeviceTimestamp = &deviceTimestamp.Time
+	}
+
+	if len(extras) > 0 {
+		if err := json.Unmarshal(extras, &data.Extras); err != nil {
+			return nil, fmt.Errorf("failed to decode telemetry extras: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// IterateBySession streams a session's telemetry in chronological order
+// The caller must Close the iterator.
+func (r *PostgresRepository) IterateBySession(ctx context.Context, sessionID string) (TelemetryIterator, error) {
+	query := `
+		SELECT ` + telemetryColumns + `
+		FROM telemetry
+		WHERE session_id = $1 AND ` + visibleTelemetry + `
+		ORDER BY recorded_at ASC, id ASC
+	`
+
+	rows, err := r.db.Reader().QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session telemetry: %w", err)
+	}
+
+	return &rowsTelemetryIterator{rows: rows}, nil
+}
+
+// IterateSessionPage streams up to limit of a session's telemetry records in chronological order,
+// starting after the given position (exclusive) when set
+// The caller must Close the iterator.
+func (r *PostgresRepository) IterateSessionPage(ctx context.Context, sessionID string, after *pagination.Cursor, limit int) (TelemetryIterator, error) {
+	args := []interface{}{sessionID}
+	conditions := []string{"session_id = $1", visibleTelemetry}
+	if cond := sessionTelemetryKeyset.Apply(after, postgresBinder(&args)); cond != "" {
+		conditions = append(conditions, cond)
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM telemetry
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d
+	`, telemetryColumns, strings.Join(conditions, " AND "), sessionTelemetryKeyset.OrderBy(), len(args))
+
+	rows, err := r.db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session telemetry: %w", err)
+	}
+
+	return &rowsTelemetryIterator{rows: rows}, nil
+}
+
+// rowsTelemetryIterator adapts *sql.Rows to TelemetryIterator
+type rowsTelemetryIterator struct {
+	rows    *sql.Rows
+	current *models.TelemetryData
+	err     error
+}
+
+// Next implements TelemetryIterator.Next
+func (it *rowsTelemetryIterator) Next() bool {
+	if it.err != nil || !it.rows.Next() {
+		return false
+	}
+
+	it.current, it.err = scanTelemetry(it.rows)
+	if it.err != nil {
+		it.err = fmt.Errorf("failed to scan telemetry row: %w", it.err)
+		return false
+	}
+
+	return true
+}
+
+// Telemetry implements TelemetryIterator.Telemetry
+func (it *rowsTelemetryIterator) Telemetry() *models.TelemetryData {
+	return it.current
+}
+
+// Err implements TelemetryIterator.Err
+func (it *rowsTelemetryIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+// Close implements TelemetryIterator.Close
+func (it *rowsTelemetryIterator) Close() error {
+	return it.rows.Close()
+}
+
 // IsBatchProcessed checks if a batch with the given ID has already been processed
 func (r *PostgresRepository) IsBatchProcessed(ctx context.Context, batchID string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM upload_batches WHERE batch_id = $1)`
@@ -384,3 +1247,114 @@ func (r *PostgresRepository) MarkBatchProcessed(ctx context.Context, batchID str
 
 	return nil
 }
+
+// IngestStats summarizes telemetry ingested since the given time
+func (r *PostgresRepository) IngestStats(ctx context.Context, since time.Time) (*models.IngestStats, error) {
+	stats := &models.IngestStats{Since: since}
+
+	telemetryQuery := `
+		SELECT COUNT(*), COUNT(DISTINCT device_id), COUNT(DISTINCT user_id), MAX(recorded_at)
+		FROM telemetry
+		WHERE recorded_at >= $1
+	`
+
+	var lastRecordedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, telemetryQuery, since).Scan(
+		&stats.DataPoints, &stats.ActiveDevices, &stats.ActiveUsers, &lastRecordedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute telemetry stats: %w", err)
+	}
+	if lastRecordedAt.Valid {
+		stats.LastRecordedAt = &lastRecordedAt.Time
+	}
+
+	batchQuery := `
+		SELECT COUNT(*), COALESCE(SUM(record_count), 0)
+		FROM upload_batches
+		WHERE uploaded_at >= $1
+	`
+
+	if err := r.db.QueryRowContext(ctx, batchQuery, since).Scan(&stats.Batches, &stats.BatchRecords); err != nil {
+		return nil, fmt.Errorf("failed to compute batch stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// OrphanedTelemetry summarizes telemetry without an owner per device, largest first
+func (r *PostgresRepository) OrphanedTelemetry(ctx context.Context, limit int) ([]*models.OrphanedTelemetry, error) {
+	query := `
+		SELECT COALESCE(t.device_id, ''), COUNT(*), MIN(t.recorded_at), MAX(t.recorded_at), d.user_id
+		FROM telemetry t
+		LEFT JOIN devices d ON d.device_id = t.device_id
+		WHERE t.user_id IS NULL AND t.` + visibleTelemetry + `
+		GROUP BY t.device_id, d.user_id
+		ORDER BY COUNT(*) DESC, t.device_id
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphaned telemetry: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	orphans := []*models.OrphanedTelemetry{}
+	for rows.Next() {
+		var orphan models.OrphanedTelemetry
+		if err := rows.Scan(
+			&orphan.DeviceID, &orphan.DataPoints,
+			&orphan.FirstRecordedAt, &orphan.LastRecordedAt, &orphan.ClaimedBy,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan orphaned telemetry: %w", err)
+		}
+		orphans = append(orphans, &orphan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orphaned telemetry: %w", err)
+	}
+
+	return orphans, nil
+}
+
+// SessionTrack returns a session's path simplified with Douglas-Peucker to the given tolerance in meters
+// The line is simplified in Web Mercator, with the tolerance scaled by the latitude of the track's
+// centroid so that it approximates ground distance. A zero tolerance returns every located point.
+func (r *PostgresRepository) SessionTrack(ctx context.Context, sessionID string, toleranceMeters float64) (*models.TrackGeometry, error) {
+	query := `
+		WITH line AS (
+			SELECT ST_Transform(ST_MakeLine(location::geometry ORDER BY recorded_at), 3857) AS geom, COUNT(*) AS points
+			FROM telemetry
+			WHERE session_id = $1 AND location IS NOT NULL AND ` + visibleTelemetry + `
+		),
+		simplified AS (
+			SELECT CASE
+				WHEN points < 2 THEN NULL
+				WHEN $2::float8 <= 0 THEN ST_Transform(geom, 4326)
+				ELSE ST_Transform(
+					ST_Simplify(geom, $2::float8 / cos(radians(ST_Y(ST_Transform(ST_Centroid(geom), 4326)))), true),
+					4326
+				)
+			END AS geom, points
+			FROM line
+		)
+		SELECT ST_AsGeoJSON(geom, 7), points, COALESCE(ST_NPoints(geom), 0)
+		FROM simplified
+	`
+
+	track := &models.TrackGeometry{}
+	var geometry sql.NullString
+	err := r.db.Reader().QueryRowContext(ctx, query, sessionID, toleranceMeters).Scan(&geometry, &track.Points, &track.SimplifiedPoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build session track: %w", err)
+	}
+	if geometry.Valid {
+		track.Geometry = json.RawMessage(geometry.String)
+	}
+
+	return track, nil
+}
@@ -3,17 +3,21 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"encoding/json"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/database/migrations"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
 )
 
 // setupTestDB sets up a TimescaleDB test container and returns a database connection
@@ -79,135 +83,14 @@ func setupTestDB(t *testing.T) (*database.DB, func()) {
 	return db, cleanup
 }
 
-// runTestMigrations runs the database migrations for testing
+// runTestMigrations applies the same embedded migrations the server runs in production
 func runTestMigrations(db *database.DB) error {
-	migrations := []string{
-		// Enable extensions
-		`CREATE EXTENSION IF NOT EXISTS timescaledb;`,
-		`CREATE EXTENSION IF NOT EXISTS postgis;`,
-
-		// Create users table (needed for foreign keys)
-		`CREATE TABLE users (
-			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			email VARCHAR(255) UNIQUE NOT NULL,
-			password_hash VARCHAR(255) NOT NULL,
-			email_verified BOOLEAN DEFAULT FALSE,
-			verification_token VARCHAR(255),
-			verification_token_expires_at TIMESTAMPTZ,
-			reset_token VARCHAR(255),
-			reset_token_expires_at TIMESTAMPTZ,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			last_login_at TIMESTAMPTZ,
-			is_active BOOLEAN DEFAULT TRUE
-		);`,
-
-		// Create refresh_tokens table
-		`CREATE TABLE refresh_tokens (
-			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			token_hash VARCHAR(255) NOT NULL,
-			expires_at TIMESTAMPTZ NOT NULL,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			revoked_at TIMESTAMPTZ,
-			replaced_by UUID REFERENCES refresh_tokens(id),
-			user_agent TEXT,
-			ip_address INET
-		);`,
-
-		// Create devices table
-		`CREATE TABLE devices (
-			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			device_id VARCHAR(50) UNIQUE NOT NULL,
-			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			device_name VARCHAR(255),
-			device_model VARCHAR(100),
-			claimed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			last_seen_at TIMESTAMPTZ,
-			is_active BOOLEAN DEFAULT TRUE,
-			metadata JSONB,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-		);`,
-
-		// Create telemetry table
-		`CREATE TABLE telemetry (
-			id BIGSERIAL,
-			recorded_at TIMESTAMPTZ NOT NULL,
-			device_id VARCHAR(50),
-			session_id UUID,
-			user_id UUID,
-			itow BIGINT,
-			time_accuracy BIGINT,
-			validity_flags INTEGER,
-			latitude DOUBLE PRECISION NOT NULL,
-			longitude DOUBLE PRECISION NOT NULL,
-			location GEOGRAPHY(POINT, 4326),
-			wgs_altitude DOUBLE PRECISION,
-			msl_altitude DOUBLE PRECISION,
-			speed DOUBLE PRECISION,
-			heading DOUBLE PRECISION,
-			num_satellites SMALLINT,
-			fix_status SMALLINT,
-			is_fix_valid BOOLEAN,
-			horizontal_accuracy DOUBLE PRECISION,
-			vertical_accuracy DOUBLE PRECISION,
-			speed_accuracy DOUBLE PRECISION,
-			heading_accuracy DOUBLE PRECISION,
-			pdop DOUBLE PRECISION,
-			g_force_x DOUBLE PRECISION,
-			g_force_y DOUBLE PRECISION,
-			g_force_z DOUBLE PRECISION,
-			rotation_x DOUBLE PRECISION,
-			rotation_y DOUBLE PRECISION,
-			rotation_z DOUBLE PRECISION,
-			battery DOUBLE PRECISION,
-			is_charging BOOLEAN,
-			PRIMARY KEY (recorded_at, id)
-		);`,
-
-		// Convert to hypertable
-		`SELECT create_hypertable('telemetry', 'recorded_at');`,
-
-		// Add foreign key constraint for user_id (after hypertable creation)
-		`ALTER TABLE telemetry ADD CONSTRAINT fk_telemetry_user
-			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;`,
-
-		// Create indexes
-		`CREATE INDEX idx_telemetry_device_time ON telemetry (device_id, recorded_at DESC);`,
-		`CREATE INDEX idx_telemetry_session ON telemetry (session_id, recorded_at DESC) WHERE session_id IS NOT NULL;`,
-		`CREATE INDEX idx_telemetry_user ON telemetry(user_id, recorded_at DESC) WHERE user_id IS NOT NULL;`,
-
-		// Create upload_batches table for idempotency
-		`CREATE TABLE upload_batches (
-			batch_id VARCHAR(36) PRIMARY KEY,
-			record_count INTEGER NOT NULL,
-			uploaded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			server_response TEXT,
-			device_id VARCHAR(50),
-			session_id UUID,
-			user_id UUID
-		);`,
-
-		// Add foreign key for upload_batches
-		`ALTER TABLE upload_batches ADD CONSTRAINT fk_upload_batches_user
-			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;`,
-
-		// Create indexes for upload_batches
-		`CREATE INDEX idx_upload_batches_uploaded_at ON upload_batches (uploaded_at DESC);`,
-		`CREATE INDEX idx_upload_batches_device ON upload_batches (device_id, uploaded_at DESC) WHERE device_id IS NOT NULL;`,
-		`CREATE INDEX idx_upload_batches_session ON upload_batches (session_id, uploaded_at DESC) WHERE session_id IS NOT NULL;`,
-		`CREATE INDEX idx_upload_batches_user ON upload_batches(user_id, uploaded_at DESC) WHERE user_id IS NOT NULL;`,
-	}
-
-	ctx := context.Background()
-	for _, migration := range migrations {
-		if _, err := db.ExecContext(ctx, migration); err != nil {
-			return fmt.Errorf("migration failed: %w", err)
-		}
+	migrator, err := migrations.NewMigrator(db.DB)
+	if err != nil {
+		return err
 	}
-
-	return nil
+	_, err = migrator.Up(context.Background())
+	return err
 }
 
 // createSampleTelemetry creates a sample telemetry data for testing
@@ -274,6 +157,127 @@ func TestPostgresRepository_Save(t *testing.T) {
 	t.Logf("Successfully saved telemetry with ID: %d", telemetry.ID)
 }
 
+func TestPostgresRepository_SchemaVersionAndExtras(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	plain := createSampleTelemetry(now.Add(-time.Second), "device-001")
+	extended := createSampleTelemetry(now, "device-001")
+	extended.SchemaVersion = 2
+	extended.Extras = map[string]interface{}{"wheelSpeed": 101.5}
+	if err := repo.SaveBatch(ctx, []*models.TelemetryData{plain, extended}); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+
+	records, err := repo.GetByDevice(ctx, "device-001", 10)
+	if err != nil {
+		t.Fatalf("Failed to read telemetry: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	// Newest first
+	if records[0].SchemaVersion != 2 || records[0].Extras["wheelSpeed"] != 101.5 {
+		t.Errorf("Expected version 2 with wheelSpeed extra, got %d %v", records[0].SchemaVersion, records[0].Extras)
+	}
+	if records[1].SchemaVersion != models.TelemetrySchemaV1 || records[1].Extras != nil {
+		t.Errorf("Expected version 1 without extras, got %d %v", records[1].SchemaVersion, records[1].Extras)
+	}
+}
+
+func TestPostgresRepository_VehicleChannels(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+
+	rpm, throttle, gear := 7200.0, 85.5, 4
+	now := time.Now().UTC().Truncate(time.Second)
+	withVehicle := createSampleTelemetry(now, "device-001")
+	withVehicle.SchemaVersion = models.TelemetrySchemaV2
+	withVehicle.Vehicle = &models.VehicleData{RPM: &rpm, Throttle: &throttle, Gear: &gear}
+	if err := repo.SaveBatch(ctx, []*models.TelemetryData{createSampleTelemetry(now.Add(-time.Second), "device-001"), withVehicle}); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+
+	records, err := repo.GetByDevice(ctx, "device-001", 10)
+	if err != nil {
+		t.Fatalf("Failed to read telemetry: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	// Newest first
+	vehicle := records[0].Vehicle
+	if vehicle == nil || *vehicle.RPM != rpm || *vehicle.Throttle != throttle || *vehicle.Gear != gear || vehicle.BrakePressure != nil {
+		t.Errorf("Expected stored vehicle channels, got %+v", vehicle)
+	}
+	if records[1].Vehicle != nil {
+		t.Errorf("Expected no vehicle block, got %+v", records[1].Vehicle)
+	}
+}
+
+func TestPostgresRepository_ClientIDReplay(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	clientID := uuid.New()
+	original := createSampleTelemetry(now, "device-001")
+	original.ClientID = &clientID
+	if err := repo.Save(ctx, original); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+	if original.Duplicate {
+		t.Fatal("First upload should not be a duplicate")
+	}
+
+	replay := createSampleTelemetry(now, "device-001")
+	replay.ClientID = &clientID
+	if err := repo.Save(ctx, replay); err != nil {
+		t.Fatalf("Failed to save replayed telemetry: %v", err)
+	}
+	if !replay.Duplicate {
+		t.Error("Expected replay to be marked duplicate")
+	}
+	if replay.ID != original.ID {
+		t.Errorf("Expected replay to report ID %d, got %d", original.ID, replay.ID)
+	}
+
+	records, err := repo.GetByDevice(ctx, "device-001", 10)
+	if err != nil {
+		t.Fatalf("Failed to read telemetry: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 stored record, got %d", len(records))
+	}
+	if records[0].ClientID == nil || *records[0].ClientID != clientID {
+		t.Errorf("Expected stored clientId %s, got %v", clientID, records[0].ClientID)
+	}
+}
+
 func TestPostgresRepository_SaveBatch(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -480,3 +484,447 @@ func TestPostgresRepository_GetByDevice(t *testing.T) {
 
 	t.Logf("Retrieved %d records for device-001", len(results))
 }
+
+func TestPostgresRepository_Query(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "query@example.com")
+
+	// Insert owned telemetry for two devices plus anonymous telemetry
+	baseTime := time.Now().UTC().Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		owned := createSampleTelemetry(baseTime.Add(time.Duration(i)*time.Second), "device-001")
+		owned.UserID = &user.ID
+		if err := repo.Save(ctx, owned); err != nil {
+			t.Fatalf("Failed to save telemetry: %v", err)
+		}
+
+		other := createSampleTelemetry(baseTime.Add(time.Duration(i)*time.Second), "device-002")
+		other.UserID = &user.ID
+		if err := repo.Save(ctx, other); err != nil {
+			t.Fatalf("Failed to save telemetry: %v", err)
+		}
+
+		anonymous := createSampleTelemetry(baseTime.Add(time.Duration(i)*time.Second), "device-001")
+		if err := repo.Save(ctx, anonymous); err != nil {
+			t.Fatalf("Failed to save telemetry: %v", err)
+		}
+	}
+
+	// Filter by device, scoped to the user
+	results, err := repo.Query(ctx, TelemetryFilter{UserID: user.ID, DeviceID: "device-001", Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to query telemetry: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 records, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.UserID == nil || *r.UserID != user.ID {
+			t.Errorf("Expected user ID %s, got %v", user.ID, r.UserID)
+		}
+	}
+
+	// Paginate with a cursor
+	firstPage, err := repo.Query(ctx, TelemetryFilter{UserID: user.ID, DeviceID: "device-001", Limit: 3})
+	if err != nil {
+		t.Fatalf("Failed to query first page: %v", err)
+	}
+	last := firstPage[len(firstPage)-1]
+	secondPage, err := repo.Query(ctx, TelemetryFilter{
+		UserID:   user.ID,
+		DeviceID: "device-001",
+		Limit:    3,
+		After:    &pagination.Cursor{Time: last.Timestamp, ID: strconv.FormatInt(last.ID, 10)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to query second page: %v", err)
+	}
+	if len(secondPage) != 2 {
+		t.Errorf("Expected 2 records on second page, got %d", len(secondPage))
+	}
+
+	// Time range filter
+	from := baseTime.Add(3 * time.Second)
+	ranged, err := repo.Query(ctx, TelemetryFilter{UserID: user.ID, From: &from, Limit: 100})
+	if err != nil {
+		t.Fatalf("Failed to query time range: %v", err)
+	}
+	if len(ranged) != 4 {
+		t.Errorf("Expected 4 records in range, got %d", len(ranged))
+	}
+}
+
+func TestPostgresRepository_IngestStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "stats@example.com")
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// Two recent points from two devices, one old point outside the window
+	recent := []*models.TelemetryData{
+		createSampleTelemetry(now.Add(-10*time.Minute), "device-001"),
+		createSampleTelemetry(now.Add(-5*time.Minute), "device-002"),
+	}
+	for _, data := range recent {
+		data.UserID = &user.ID
+	}
+	if err := repo.SaveBatch(ctx, recent); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+	if err := repo.Save(ctx, createSampleTelemetry(now.Add(-48*time.Hour), "device-003")); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+	if err := repo.MarkBatchProcessed(ctx, uuid.New().String(), 2, "device-001", nil); err != nil {
+		t.Fatalf("Failed to mark batch: %v", err)
+	}
+
+	stats, err := repo.IngestStats(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to compute ingest stats: %v", err)
+	}
+
+	if stats.DataPoints != 2 {
+		t.Errorf("Expected 2 data points, got %d", stats.DataPoints)
+	}
+	if stats.ActiveDevices != 2 {
+		t.Errorf("Expected 2 active devices, got %d", stats.ActiveDevices)
+	}
+	if stats.ActiveUsers != 1 {
+		t.Errorf("Expected 1 active user, got %d", stats.ActiveUsers)
+	}
+	if stats.Batches != 1 || stats.BatchRecords != 2 {
+		t.Errorf("Expected 1 batch with 2 records, got %d batches with %d records", stats.Batches, stats.BatchRecords)
+	}
+	if stats.LastRecordedAt == nil || !stats.LastRecordedAt.Equal(now.Add(-5*time.Minute)) {
+		t.Errorf("Expected last recorded at %v, got %v", now.Add(-5*time.Minute), stats.LastRecordedAt)
+	}
+}
+
+func TestPostgresRepository_Deduplication(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// Duplicates stored before deduplication is enabled are removed when the index is built
+	plain := NewPostgresRepository(db)
+	for i := 0; i < 2; i++ {
+		if err := plain.Save(ctx, createSampleTelemetry(now, "device-001")); err != nil {
+			t.Fatalf("Failed to save telemetry: %v", err)
+		}
+	}
+
+	repo := NewPostgresRepository(db).WithDeduplication(true)
+	if err := repo.ApplyDeduplication(ctx); err != nil {
+		t.Fatalf("Failed to apply deduplication: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM telemetry`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count telemetry: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected existing duplicates to be removed, got %d rows", count)
+	}
+
+	batch := []*models.TelemetryData{
+		createSampleTelemetry(now, "device-001"),
+		createSampleTelemetry(now.Add(time.Second), "device-001"),
+		createSampleTelemetry(now, "device-002"),
+	}
+	if err := repo.SaveBatch(ctx, batch); err != nil {
+		t.Fatalf("Failed to save batch: %v", err)
+	}
+	if !batch[0].Duplicate || batch[0].ID != 0 {
+		t.Errorf("Expected first record to be skipped as a duplicate, got ID %d", batch[0].ID)
+	}
+	if batch[1].Duplicate || batch[2].Duplicate {
+		t.Error("Expected new records to be inserted")
+	}
+
+	single := createSampleTelemetry(now.Add(time.Second), "device-001")
+	if err := repo.Save(ctx, single); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+	if !single.Duplicate {
+		t.Error("Expected single record to be skipped as a duplicate")
+	}
+
+	// Disabling drops the index again
+	if err := NewPostgresRepository(db).ApplyDeduplication(ctx); err != nil {
+		t.Fatalf("Failed to disable deduplication: %v", err)
+	}
+	if err := plain.Save(ctx, createSampleTelemetry(now, "device-001")); err != nil {
+		t.Errorf("Expected duplicate insert to succeed without deduplication: %v", err)
+	}
+}
+
+func TestPostgresRepository_SessionTrack(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	sessionID := uuid.New().String()
+	start := time.Now().UTC().Truncate(time.Second)
+
+	// Ten points along a straight northbound line, then one point ~80 m east
+	var batch []*models.TelemetryData
+	for i := 0; i < 10; i++ {
+		data := createSampleTelemetry(start.Add(time.Duration(i)*time.Second), "device-001")
+		data.SessionID = &sessionID
+		data.GPS.Latitude = 42.0 + float64(i)*0.0001
+		data.GPS.Longitude = 23.0
+		batch = append(batch, data)
+	}
+	corner := createSampleTelemetry(start.Add(10*time.Second), "device-001")
+	corner.SessionID = &sessionID
+	corner.GPS.Latitude = 42.0009
+	corner.GPS.Longitude = 23.001
+	batch = append(batch, corner)
+	if err := repo.SaveBatch(ctx, batch); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+
+	track, err := repo.SessionTrack(ctx, sessionID, 5)
+	if err != nil {
+		t.Fatalf("Failed to build session track: %v", err)
+	}
+	if track.Points != 11 {
+		t.Errorf("Expected 11 points, got %d", track.Points)
+	}
+	if track.SimplifiedPoints != 3 {
+		t.Errorf("Expected the straight segment to collapse to 3 vertices, got %d", track.SimplifiedPoints)
+	}
+
+	var geometry struct {
+		Type        string       `json:"type"`
+		Coordinates [][2]float64 `json:"coordinates"`
+	}
+	if err := json.Unmarshal(track.Geometry, &geometry); err != nil {
+		t.Fatalf("Failed to parse geometry: %v", err)
+	}
+	if geometry.Type != "LineString" || len(geometry.Coordinates) != 3 {
+		t.Fatalf("Expected a 3-vertex LineString, got %s with %d vertices", geometry.Type, len(geometry.Coordinates))
+	}
+	if geometry.Coordinates[0] != [2]float64{23.0, 42.0} {
+		t.Errorf("Expected the line to start at the first point, got %v", geometry.Coordinates[0])
+	}
+
+	// Zero tolerance keeps every vertex
+	full, err := repo.SessionTrack(ctx, sessionID, 0)
+	if err != nil {
+		t.Fatalf("Failed to build session track: %v", err)
+	}
+	if full.SimplifiedPoints != 11 {
+		t.Errorf("Expected 11 vertices without simplification, got %d", full.SimplifiedPoints)
+	}
+
+	empty, err := repo.SessionTrack(ctx, uuid.New().String(), 5)
+	if err != nil {
+		t.Fatalf("Failed to build session track: %v", err)
+	}
+	if empty.Geometry != nil || empty.Points != 0 {
+		t.Errorf("Expected no geometry for an empty session, got %s with %d points", empty.Geometry, empty.Points)
+	}
+}
+
+func TestPostgresRepository_OrphanedTelemetry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "orphans@example.com")
+
+	// device-001 was claimed after uploading anonymously
+	device := &models.Device{
+		ID:        uuid.New(),
+		DeviceID:  "device-001",
+		UserID:    user.ID,
+		ClaimedAt: time.Now(),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := NewPostgresDeviceRepository(db.DB).Create(ctx, device); err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	anonymous := []*models.TelemetryData{
+		createSampleTelemetry(now.Add(-3*time.Minute), "device-001"),
+		createSampleTelemetry(now.Add(-2*time.Minute), "device-001"),
+		createSampleTelemetry(now.Add(-1*time.Minute), "device-002"),
+	}
+	if err := repo.SaveBatch(ctx, anonymous); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+	owned := createSampleTelemetry(now, "device-001")
+	owned.UserID = &user.ID
+	if err := repo.Save(ctx, owned); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+
+	orphans, err := repo.OrphanedTelemetry(ctx, 10)
+	if err != nil {
+		t.Fatalf("Failed to list orphaned telemetry: %v", err)
+	}
+
+	if len(orphans) != 2 {
+		t.Fatalf("Expected 2 devices with orphaned telemetry, got %d", len(orphans))
+	}
+	if orphans[0].DeviceID != "device-001" || orphans[0].DataPoints != 2 {
+		t.Errorf("Expected device-001 with 2 points first, got %s with %d", orphans[0].DeviceID, orphans[0].DataPoints)
+	}
+	if orphans[0].ClaimedBy == nil || *orphans[0].ClaimedBy != user.ID {
+		t.Errorf("Expected device-001 to be claimed by %s, got %v", user.ID, orphans[0].ClaimedBy)
+	}
+	if !orphans[0].FirstRecordedAt.Equal(now.Add(-3*time.Minute)) || !orphans[0].LastRecordedAt.Equal(now.Add(-2*time.Minute)) {
+		t.Errorf("Unexpected range for device-001: %v - %v", orphans[0].FirstRecordedAt, orphans[0].LastRecordedAt)
+	}
+	if orphans[1].ClaimedBy != nil {
+		t.Errorf("Expected device-002 to be unclaimed, got %v", orphans[1].ClaimedBy)
+	}
+
+	limited, err := repo.OrphanedTelemetry(ctx, 1)
+	if err != nil {
+		t.Fatalf("Failed to list orphaned telemetry: %v", err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("Expected limit to cap results at 1, got %d", len(limited))
+	}
+}
+
+func TestPostgresRepository_Aggregate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "aggregate@example.com")
+
+	base := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	// Three points in the first minute and one in the second
+	var batch []*models.TelemetryData
+	for i, offset := range []time.Duration{0, 10 * time.Second, 20 * time.Second, 70 * time.Second} {
+		data := createSampleTelemetry(base.Add(offset), "device-001")
+		data.UserID = &user.ID
+		data.GPS.Speed = float64(100 + i*10)
+		batch = append(batch, data)
+	}
+	if err := repo.SaveBatch(ctx, batch); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+
+	buckets, err := repo.Aggregate(ctx, TelemetryFilter{UserID: user.ID, DeviceID: "device-001"}, Bucket1m)
+	if err != nil {
+		t.Fatalf("Failed to aggregate telemetry: %v", err)
+	}
+
+	if len(buckets) != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", len(buckets))
+	}
+	if !buckets[0].Bucket.Equal(base) || buckets[0].Samples != 3 {
+		t.Errorf("Unexpected first bucket: %+v", buckets[0])
+	}
+	if buckets[0].AvgSpeed != 110 || buckets[0].MaxSpeed != 120 {
+		t.Errorf("Expected avg 110 and max 120 in first bucket, got %v and %v", buckets[0].AvgSpeed, buckets[0].MaxSpeed)
+	}
+	if buckets[1].Samples != 1 || buckets[1].MaxSpeed != 130 {
+		t.Errorf("Unexpected second bucket: %+v", buckets[1])
+	}
+
+	// Telemetry of other users is never aggregated
+	other, err := repo.Aggregate(ctx, TelemetryFilter{UserID: uuid.New()}, Bucket1m)
+	if err != nil {
+		t.Fatalf("Failed to aggregate telemetry: %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("Expected no buckets for another user, got %d", len(other))
+	}
+}
+
+func TestPostgresRepository_QualityFlags(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "quality@example.com")
+
+	base := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	var batch []*models.TelemetryData
+	for i := range 3 {
+		data := createSampleTelemetry(base.Add(time.Duration(i)*time.Second), "device-001")
+		data.UserID = &user.ID
+		batch = append(batch, data)
+	}
+	batch[1].GPS.Speed = 900
+	batch[1].QualityFlags = models.QualityImpossibleSpeed | models.QualityPositionJump
+	if err := repo.SaveBatch(ctx, batch); err != nil {
+		t.Fatalf("Failed to save telemetry: %v", err)
+	}
+
+	all, err := repo.Query(ctx, TelemetryFilter{UserID: user.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to query telemetry: %v", err)
+	}
+	if len(all) != 3 || all[1].QualityFlags != models.QualityImpossibleSpeed|models.QualityPositionJump {
+		t.Fatalf("Expected 3 records with the flagged one in the middle, got %+v", all)
+	}
+
+	clean, err := repo.Query(ctx, TelemetryFilter{UserID: user.ID, Limit: 10, CleanOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to query telemetry: %v", err)
+	}
+	if len(clean) != 2 {
+		t.Errorf("Expected 2 clean records, got %d", len(clean))
+	}
+
+	buckets, err := repo.Aggregate(ctx, TelemetryFilter{UserID: user.ID, CleanOnly: true}, Bucket1m)
+	if err != nil {
+		t.Fatalf("Failed to aggregate telemetry: %v", err)
+	}
+	if len(buckets) != 1 || buckets[0].Samples != 2 || buckets[0].MaxSpeed != 125.5 {
+		t.Errorf("Expected one bucket of the 2 clean points, got %+v", buckets)
+	}
+}
@@ -0,0 +1,553 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrSessionNotFound is returned when a session is not found
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrSessionAlreadyEnded is returned when trying to end a session that has already ended
+	ErrSessionAlreadyEnded = errors.New("session already ended")
+
+	// ErrSessionStatsNotFound is returned when a session's stats have not been computed yet
+	ErrSessionStatsNotFound = errors.New("session stats not found")
+
+	// ErrSessionMarkerNotFound is returned when a session marker is not found
+	ErrSessionMarkerNotFound = errors.New("session marker not found")
+)
+
+// maxStatsSampleGap caps the time a single telemetry point contributes to its speed band,
+// so recording pauses do not count as time spent at the speed before the pause
+const maxStatsSampleGap = 5 * time.Second
+
+// sessionColumns is the column list used by all session SELECT queries
+const sessionColumns = `
+	id, device_id, user_id, started_at, ended_at,
+	name, location, notes, circuit_id,
+	total_distance, max_speed, avg_speed, max_g_force, data_points_count,
+	summary_computed_at, org_id, created_at, updated_at
+`
+
+// PostgresSessionRepository implements SessionRepository using PostgreSQL
+type PostgresSessionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSessionRepository creates a new PostgreSQL session repository
+func NewPostgresSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+// Create stores a new session
+func (r *PostgresSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (
+			id, device_id, user_id, started_at, ended_at,
+			name, location, notes, org_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+
+	now := time.Now()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	if session.UpdatedAt.IsZero() {
+		session.UpdatedAt = now
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		session.ID,
+		session.DeviceID,
+		session.UserID,
+		session.StartedAt,
+		session.EndedAt,
+		session.Name,
+		session.Location,
+		session.Notes,
+		session.OrgID,
+		session.CreatedAt,
+		session.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a session by its UUID
+func (r *PostgresSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+// ListByUserID retrieves sessions owned by a user, most recent first
+func (r *PostgresSessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Session, error) {
+	sessions, _, err := r.ListAccessible(ctx, SessionFilter{UserID: userID, Limit: limit, Offset: offset})
+	return sessions, err
+}
+
+// ListAccessible retrieves sessions owned by the filter's user or shared with any of its
+// organizations, most recent first, and the total number matching the filter
+func (r *PostgresSessionRepository) ListAccessible(ctx context.Context, filter SessionFilter) ([]*models.Session, int, error) {
+	limit, offset := filter.Limit, filter.Offset
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	where := `(user_id = $1 OR org_id = ANY($2::uuid[]))`
+	args := []interface{}{filter.UserID, uuidStrings(filter.OrgIDs)}
+	bind := postgresBinder(&args)
+	if filter.StartedFrom != nil {
+		where += " AND started_at >= " + bind(*filter.StartedFrom)
+	}
+	if filter.StartedBefore != nil {
+		where += " AND started_at < " + bind(*filter.StartedBefore)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count sessions: %w", err)
+	}
+
+	if condition := sessionKeyset.Apply(filter.After, bind); condition != "" {
+		where += " AND " + condition
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM sessions
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, sessionColumns, where, sessionKeyset.OrderBy(), len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*models.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan session row: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating session rows: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
+// UpdateDetails stores a session's name, location and notes
+func (r *PostgresSessionRepository) UpdateDetails(ctx context.Context, session *models.Session) error {
+	query := `
+		UPDATE sessions
+		SET name = $2, location = $3, notes = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, session.ID, session.Name, session.Location, session.Notes).Scan(&session.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return nil
+}
+
+// End marks a session as ended at the given time
+func (r *PostgresSessionRepository) End(ctx context.Context, id uuid.UUID, endedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	query := `
+		UPDATE sessions
+		SET ended_at = $1, updated_at = NOW()
+		WHERE id = $2 AND ended_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, endedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		// Distinguish between a missing session and one that already ended
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrSessionAlreadyEnded
+	}
+
+	if err := appendEvents(ctx, tx, []uuid.UUID{id}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session end: %w", err)
+	}
+	return nil
+}
+
+// UpdateSummary recomputes a session's cached aggregates from its telemetry
+// Distance is the sum of great-circle distances between consecutive points in
+// meters; max g-force is the peak horizontal (lateral/longitudinal) magnitude.
+// The session is tagged with the catalog circuit containing most of its points,
+// which also fills in the location when the user has not set one.
+func (r *PostgresSessionRepository) UpdateSummary(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	query := `
+		WITH points AS (
+			SELECT
+				speed, g_force_x, g_force_y, location,
+				LAG(location) OVER (ORDER BY recorded_at, id) AS prev_location
+			FROM telemetry
+			WHERE session_id = $1 AND ` + visibleTelemetry + `
+		), stats AS (
+			SELECT
+				COALESCE(SUM(ST_Distance(location, prev_location)), 0) AS agg_distance,
+				MAX(speed) AS agg_max_speed,
+				AVG(speed) AS agg_avg_speed,
+				MAX(SQRT(g_force_x * g_force_x + g_force_y * g_force_y)) AS agg_max_g_force,
+				COUNT(*) AS agg_count
+			FROM points
+		), detected AS (
+			SELECT c.id, c.name
+			FROM circuits c
+			JOIN telemetry t ON ST_Intersects(c.boundary, t.location)
+			WHERE t.session_id = $1 AND t.` + visibleTelemetry + `
+			GROUP BY c.id, c.name
+			HAVING COUNT(*) * 2 > (SELECT agg_count FROM stats)
+			ORDER BY COUNT(*) DESC
+			LIMIT 1
+		)
+		UPDATE sessions
+		SET circuit_id = (SELECT id FROM detected),
+			location = COALESCE(sessions.location, (SELECT name FROM detected)),
+			total_distance = stats.agg_distance,
+			max_speed = stats.agg_max_speed,
+			avg_speed = stats.agg_avg_speed,
+			max_g_force = stats.agg_max_g_force,
+			data_points_count = stats.agg_count,
+			summary_computed_at = NOW()
+		FROM stats
+		WHERE sessions.id = $1
+		RETURNING ` + sessionColumns
+
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to update session summary: %w", err)
+	}
+
+	return session, nil
+}
+
+// ListPendingSummaries returns IDs of ended sessions whose aggregates have not
+// been computed since they ended, oldest first
+func (r *PostgresSessionRepository) ListPendingSummaries(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT id FROM sessions
+		WHERE ended_at IS NOT NULL
+		  AND (summary_computed_at IS NULL OR summary_computed_at < ended_at)
+		ORDER BY ended_at ASC
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending session summaries: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+// rowScanner abstracts *sql.Row and *sql.Rows for shared scan helpers
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// GetStats returns a session's cached telemetry statistics
+func (r *PostgresSessionRepository) GetStats(ctx context.Context, id uuid.UUID) (*models.SessionStats, error) {
+	var (
+		raw        []byte
+		computedAt sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, `SELECT stats, stats_computed_at FROM sessions WHERE id = $1`, id).Scan(&raw, &computedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session stats: %w", err)
+	}
+	if raw == nil || !computedAt.Valid {
+		return nil, ErrSessionStatsNotFound
+	}
+
+	return decodeSessionStats(id, raw, computedAt.Time)
+}
+
+// UpdateStats recomputes a session's telemetry statistics and caches them on the session row
+// Everything is computed by the database against the telemetry hypertable; time in each speed
+// band is the sum of the gaps to the next point, each capped at maxStatsSampleGap.
+func (r *PostgresSessionRepository) UpdateStats(ctx context.Context, id uuid.UUID) (*models.SessionStats, error) {
+	query := `
+		WITH points AS (
+			SELECT
+				speed, g_force_x, g_force_y,
+				msl_altitude - LAG(msl_altitude) OVER w AS climb,
+				LEAST(EXTRACT(EPOCH FROM (LEAD(recorded_at) OVER w - recorded_at))::float8, $3::float8) AS dt
+			FROM telemetry
+			WHERE session_id = $1 AND ` + visibleTelemetry + `
+			WINDOW w AS (ORDER BY recorded_at, id)
+		), agg AS (
+			SELECT
+				COUNT(*) AS samples,
+				percentile_cont(ARRAY[0.5, 0.75, 0.9, 0.95, 0.99]) WITHIN GROUP (ORDER BY speed) AS pct,
+				MAX(ABS(g_force_y)) AS max_lat,
+				AVG(ABS(g_force_y)) AS avg_lat,
+				MAX(ABS(g_force_x)) AS max_lon,
+				AVG(ABS(g_force_x)) AS avg_lon,
+				COALESCE(SUM(GREATEST(climb, 0)), 0) AS gain
+			FROM points
+		), band_time AS (
+			SELECT width_bucket(speed, $2::float8[]) AS band, SUM(dt) AS seconds
+			FROM points
+			WHERE speed IS NOT NULL AND dt IS NOT NULL
+			GROUP BY 1
+		), bands AS (
+			SELECT jsonb_agg(jsonb_build_object(
+				'minSpeed', COALESCE(($2::float8[])[b], 0),
+				'maxSpeed', ($2::float8[])[b + 1],
+				'seconds', COALESCE(band_time.seconds, 0)
+			) ORDER BY b) AS doc
+			FROM generate_series(0, array_length($2::float8[], 1)) AS b
+			LEFT JOIN band_time ON band_time.band = b
+		)
+		UPDATE sessions
+		SET stats = jsonb_build_object(
+				'samples', agg.samples,
+				'speed', jsonb_build_object(
+					'p50', agg.pct[1], 'p75', agg.pct[2], 'p90', agg.pct[3],
+					'p95', agg.pct[4], 'p99', agg.pct[5]
+				),
+				'maxLateralG', agg.max_lat,
+				'avgLateralG', agg.avg_lat,
+				'maxLongitudinalG', agg.max_lon,
+				'avgLongitudinalG', agg.avg_lon,
+				'altitudeGain', agg.gain,
+				'timeInSpeedBands', bands.doc
+			),
+			stats_computed_at = NOW()
+		FROM agg, bands
+		WHERE sessions.id = $1
+		RETURNING stats, stats_computed_at
+	`
+
+	var (
+		raw        []byte
+		computedAt time.Time
+	)
+	err := r.db.QueryRowContext(ctx, query, id, models.SpeedBandThresholds, maxStatsSampleGap.Seconds()).Scan(&raw, &computedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to update session stats: %w", err)
+	}
+
+	return decodeSessionStats(id, raw, computedAt)
+}
+
+// AddMarker stores a new marker on a session
+func (r *PostgresSessionRepository) AddMarker(ctx context.Context, marker *models.SessionMarker) error {
+	query := `
+		INSERT INTO session_markers (id, session_id, user_id, type, label, marked_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	if marker.ID == uuid.Nil {
+		marker.ID = uuid.New()
+	}
+	if marker.CreatedAt.IsZero() {
+		marker.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		marker.ID, marker.SessionID, marker.UserID, marker.Type, marker.Label, marker.MarkedAt, marker.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert session marker: %w", err)
+	}
+
+	return nil
+}
+
+// ListMarkers retrieves a session's markers in time order
+func (r *PostgresSessionRepository) ListMarkers(ctx context.Context, sessionID uuid.UUID) ([]*models.SessionMarker, error) {
+	query := `
+		SELECT id, session_id, user_id, type, label, marked_at, created_at
+		FROM session_markers
+		WHERE session_id = $1
+		ORDER BY marked_at, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session markers: %w", err)
+	}
+	defer rows.Close()
+
+	markers := make([]*models.SessionMarker, 0)
+	for rows.Next() {
+		var marker models.SessionMarker
+		if err := rows.Scan(
+			&marker.ID,
+			&marker.SessionID,
+			&marker.UserID,
+			&marker.Type,
+			&marker.Label,
+			&marker.MarkedAt,
+			&marker.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan session marker row: %w", err)
+		}
+		markers = append(markers, &marker)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session marker rows: %w", err)
+	}
+
+	return markers, nil
+}
+
+// DeleteMarker removes a marker from a session
+func (r *PostgresSessionRepository) DeleteMarker(ctx context.Context, sessionID, markerID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM session_markers WHERE id = $1 AND session_id = $2`, markerID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session marker: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrSessionMarkerNotFound
+	}
+
+	return nil
+}
+
+// decodeSessionStats decodes the stats document stored on a session row
+func decodeSessionStats(id uuid.UUID, raw []byte, computedAt time.Time) (*models.SessionStats, error) {
+	var stats models.SessionStats
+	if err := json.Unmarshal(raw, &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode session stats: %w", err)
+	}
+	stats.SessionID = id
+	stats.ComputedAt = computedAt
+	return &stats, nil
+}
+
+// scanSession scans a single session row
+func scanSession(row rowScanner) (*models.Session, error) {
+	var session models.Session
+	var dataPointsCount sql.NullInt64
+
+	err := row.Scan(
+		&session.ID,
+		&session.DeviceID,
+		&session.UserID,
+		&session.StartedAt,
+		&session.EndedAt,
+		&session.Name,
+		&session.Location,
+		&session.Notes,
+		&session.CircuitID,
+		&session.TotalDistance,
+		&session.MaxSpeed,
+		&session.AvgSpeed,
+		&session.MaxGForce,
+		&dataPointsCount,
+		&session.SummaryComputedAt,
+		&session.OrgID,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	session.DataPointsCount = dataPointsCount.Int64
+
+	return &session, nil
+}
@@ -0,0 +1,431 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresSessionRepository_CreateAndGet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresSessionRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "session@example.com")
+
+	session := &models.Session{
+		DeviceID:  "RACEBOX-001",
+		UserID:    &user.ID,
+		StartedAt: time.Now().UTC().Truncate(time.Millisecond),
+		Name:      stringPtr("Track Day"),
+	}
+
+	require.NoError(t, repo.Create(ctx, session))
+	assert.NotEqual(t, uuid.Nil, session.ID)
+
+	retrieved, err := repo.GetByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, session.DeviceID, retrieved.DeviceID)
+	assert.Equal(t, user.ID, *retrieved.UserID)
+	assert.Equal(t, "Track Day", *retrieved.Name)
+	assert.True(t, retrieved.IsActive())
+	assert.Equal(t, int64(0), retrieved.DataPointsCount)
+}
+
+func TestPostgresSessionRepository_GetByID_NotFound(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresSessionRepository(db.DB)
+
+	_, err := repo.GetByID(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestPostgresSessionRepository_End(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresSessionRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "end@example.com")
+
+	session := &models.Session{
+		DeviceID:  "RACEBOX-001",
+		UserID:    &user.ID,
+		StartedAt: time.Now().Add(-time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, session))
+
+	endedAt := time.Now().UTC()
+	require.NoError(t, repo.End(ctx, session.ID, endedAt))
+
+	retrieved, err := repo.GetByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.False(t, retrieved.IsActive())
+
+	// Ending twice is rejected
+	assert.ErrorIs(t, repo.End(ctx, session.ID, endedAt), ErrSessionAlreadyEnded)
+
+	// Ending an unknown session reports not found
+	assert.ErrorIs(t, repo.End(ctx, uuid.New(), endedAt), ErrSessionNotFound)
+}
+
+func TestPostgresSessionRepository_UpdateDetails(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresSessionRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "details@example.com")
+
+	session := &models.Session{
+		DeviceID:  "RACEBOX-001",
+		UserID:    &user.ID,
+		StartedAt: time.Now().Add(-time.Hour),
+		Name:      stringPtr("Session 1"),
+		Notes:     stringPtr("Dry"),
+	}
+	require.NoError(t, repo.Create(ctx, session))
+	createdUpdatedAt := session.UpdatedAt
+
+	session.Name = stringPtr("Qualifying")
+	session.Location = stringPtr("Silverstone")
+	session.Notes = nil
+	require.NoError(t, repo.UpdateDetails(ctx, session))
+	assert.False(t, session.UpdatedAt.Before(createdUpdatedAt))
+
+	retrieved, err := repo.GetByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Qualifying", *retrieved.Name)
+	assert.Equal(t, "Silverstone", *retrieved.Location)
+	assert.Nil(t, retrieved.Notes)
+
+	// Updating an unknown session reports not found
+	assert.ErrorIs(t, repo.UpdateDetails(ctx, &models.Session{ID: uuid.New()}), ErrSessionNotFound)
+}
+
+func TestPostgresSessionRepository_Markers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresSessionRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "markers@example.com")
+
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond)
+	session := &models.Session{DeviceID: "RACEBOX-001", UserID: &user.ID, StartedAt: start}
+	require.NoError(t, repo.Create(ctx, session))
+
+	incident := &models.SessionMarker{SessionID: session.ID, UserID: &user.ID, Type: models.SessionMarkerIncident, MarkedAt: start.Add(20 * time.Minute)}
+	pitStop := &models.SessionMarker{SessionID: session.ID, UserID: &user.ID, Type: models.SessionMarkerPitStop, Label: stringPtr("Tyres"), MarkedAt: start.Add(10 * time.Minute)}
+	require.NoError(t, repo.AddMarker(ctx, incident))
+	require.NoError(t, repo.AddMarker(ctx, pitStop))
+	assert.NotEqual(t, uuid.Nil, incident.ID)
+
+	// Markers are listed in time order
+	markers, err := repo.ListMarkers(ctx, session.ID)
+	require.NoError(t, err)
+	require.Len(t, markers, 2)
+	assert.Equal(t, pitStop.ID, markers[0].ID)
+	assert.Equal(t, "Tyres", *markers[0].Label)
+	assert.True(t, pitStop.MarkedAt.Equal(markers[0].MarkedAt))
+	assert.Equal(t, models.SessionMarkerIncident, markers[1].Type)
+
+	// Markers can only be deleted through their own session
+	assert.ErrorIs(t, repo.DeleteMarker(ctx, uuid.New(), pitStop.ID), ErrSessionMarkerNotFound)
+	require.NoError(t, repo.DeleteMarker(ctx, session.ID, pitStop.ID))
+	assert.ErrorIs(t, repo.DeleteMarker(ctx, session.ID, pitStop.ID), ErrSessionMarkerNotFound)
+
+	markers, err = repo.ListMarkers(ctx, session.ID)
+	require.NoError(t, err)
+	require.Len(t, markers, 1)
+	assert.Equal(t, incident.ID, markers[0].ID)
+}
+
+func TestPostgresSessionRepository_ListByUserID(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresSessionRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "list@example.com")
+	other := createTestUser(t, db, "other@example.com")
+
+	base := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.Create(ctx, &models.Session{
+			DeviceID:  "RACEBOX-001",
+			UserID:    &user.ID,
+			StartedAt: base.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+	require.NoError(t, repo.Create(ctx, &models.Session{
+		DeviceID:  "RACEBOX-002",
+		UserID:    &other.ID,
+		StartedAt: base,
+	}))
+
+	sessions, err := repo.ListByUserID(ctx, user.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+	assert.True(t, sessions[0].StartedAt.After(sessions[1].StartedAt))
+
+	page, err := repo.ListByUserID(ctx, user.ID, 2, 2)
+	require.NoError(t, err)
+	assert.Len(t, page, 1)
+
+	// Cursors resume after the given session
+	last := sessions[1]
+	page, total, err := repo.ListAccessible(ctx, SessionFilter{UserID: user.ID, Limit: 10, After: &pagination.Cursor{Time: last.StartedAt, ID: last.ID.String()}})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, 3, total, "the total ignores the cursor")
+	assert.Equal(t, sessions[2].ID, page[0].ID)
+
+	// Start time bounds include from and exclude before
+	from, before := base.Add(time.Hour), base.Add(2*time.Hour)
+	page, total, err = repo.ListAccessible(ctx, SessionFilter{UserID: user.ID, StartedFrom: &from, StartedBefore: &before})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, sessions[1].ID, page[0].ID)
+}
+
+// createTestUser creates an active user row to satisfy ownership foreign keys
+func createTestUser(t *testing.T, db *database.DB, email string) *models.User {
+	t.Helper()
+
+	user := &models.User{
+		ID:           uuid.New(),
+		Email:        email,
+		PasswordHash: "hash",
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		IsActive:     true,
+	}
+	require.NoError(t, NewPostgresUserRepository(db).Create(context.Background(), user))
+
+	return user
+}
+
+func TestPostgresSessionRepository_UpdateSummary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresSessionRepository(db.DB)
+	telemetryRepo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "summary@example.com")
+
+	startedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	session := &models.Session{DeviceID: "RACEBOX-001", UserID: &user.ID, StartedAt: startedAt}
+	require.NoError(t, repo.Create(ctx, session))
+
+	// Three points roughly 111m apart heading north
+	sessionID := session.ID.String()
+	speeds := []float64{100, 150, 200}
+	for i, speed := range speeds {
+		point := createSampleTelemetry(startedAt.Add(time.Duration(i)*time.Second), "RACEBOX-001")
+		point.SessionID = &sessionID
+		point.GPS.Latitude = 42.0 + float64(i)*0.001
+		point.GPS.Longitude = 23.0
+		point.GPS.Speed = speed
+		point.Motion.GForceX = 0.3 * float64(i)
+		point.Motion.GForceY = 0.4 * float64(i)
+		require.NoError(t, telemetryRepo.Save(ctx, point))
+	}
+
+	// Ended sessions without a summary are pending
+	require.NoError(t, repo.End(ctx, session.ID, startedAt.Add(time.Minute)))
+	pending, err := repo.ListPendingSummaries(ctx, 10)
+	require.NoError(t, err)
+	assert.Contains(t, pending, session.ID)
+
+	summarized, err := repo.UpdateSummary(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), summarized.DataPointsCount)
+	assert.InDelta(t, 222, *summarized.TotalDistance, 2)
+	assert.Equal(t, 200.0, *summarized.MaxSpeed)
+	assert.Equal(t, 150.0, *summarized.AvgSpeed)
+	assert.InDelta(t, 1.0, *summarized.MaxGForce, 0.001)
+	assert.NotNil(t, summarized.SummaryComputedAt)
+
+	pending, err = repo.ListPendingSummaries(ctx, 10)
+	require.NoError(t, err)
+	assert.NotContains(t, pending, session.ID)
+
+	_, err = repo.UpdateSummary(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestPostgresSessionRepository_UpdateStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresSessionRepository(db.DB)
+	telemetryRepo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "stats@example.com")
+
+	startedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	session := &models.Session{DeviceID: "RACEBOX-001", UserID: &user.ID, StartedAt: startedAt}
+	require.NoError(t, repo.Create(ctx, session))
+
+	_, err := repo.GetStats(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrSessionStatsNotFound)
+
+	// One point per second, climbing 2m and then descending 1m; the 30s pause before the
+	// last point only counts as maxStatsSampleGap
+	sessionID := session.ID.String()
+	points := []struct {
+		offset   time.Duration
+		speed    float64
+		altitude float64
+		gx, gy   float64
+	}{
+		{0, 40, 100, 0.2, -0.5},
+		{time.Second, 80, 101, -0.8, 0.3},
+		{2 * time.Second, 120, 102, 0.1, 1.1},
+		{3 * time.Second, 120, 101, 0.1, 0.1},
+		{33 * time.Second, 210, 101, 0.4, 0.2},
+	}
+	for _, p := range points {
+		point := createSampleTelemetry(startedAt.Add(p.offset), "RACEBOX-001")
+		point.SessionID = &sessionID
+		point.GPS.Speed = p.speed
+		point.GPS.MslAltitude = p.altitude
+		point.Motion.GForceX = p.gx
+		point.Motion.GForceY = p.gy
+		require.NoError(t, telemetryRepo.Save(ctx, point))
+	}
+
+	stats, err := repo.UpdateStats(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, stats.SessionID)
+	assert.Equal(t, int64(5), stats.Samples)
+	require.NotNil(t, stats.Speed.P50)
+	assert.InDelta(t, 120, *stats.Speed.P50, 0.001)
+	assert.InDelta(t, 1.1, *stats.MaxLateralG, 0.001)
+	assert.InDelta(t, 0.8, *stats.MaxLongitudinalG, 0.001)
+	assert.InDelta(t, 2, stats.AltitudeGain, 0.001)
+
+	require.Len(t, stats.TimeInSpeedBands, len(models.SpeedBandThresholds)+1)
+	assert.Equal(t, 0.0, stats.TimeInSpeedBands[0].MinSpeed)
+	assert.InDelta(t, 1, stats.TimeInSpeedBands[0].Seconds, 0.001) // 40 km/h
+	assert.InDelta(t, 1, stats.TimeInSpeedBands[1].Seconds, 0.001) // 80 km/h
+	assert.InDelta(t, 6, stats.TimeInSpeedBands[2].Seconds, 0.001) // 120 km/h, pause capped
+	assert.InDelta(t, 0, stats.TimeInSpeedBands[4].Seconds, 0.001) // last point has no successor
+	assert.Nil(t, stats.TimeInSpeedBands[4].MaxSpeed)
+
+	cached, err := repo.GetStats(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stats.Samples, cached.Samples)
+	assert.WithinDuration(t, stats.ComputedAt, cached.ComputedAt, time.Millisecond)
+
+	_, err = repo.UpdateStats(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestPostgresSessionRepository_UpdateSummary_DetectsCircuit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresSessionRepository(db.DB)
+	telemetryRepo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "circuit@example.com")
+	clearCircuitCatalog(t, db)
+	circuitID := createTestCircuit(t, db, "Serres Racing Circuit", 41.068, 23.506, 41.076, 23.518)
+
+	record := func(session *models.Session, lat, lon float64) {
+		startedAt := session.StartedAt
+		sessionID := session.ID.String()
+		for i := range 4 {
+			point := createSampleTelemetry(startedAt.Add(time.Duration(i)*time.Second), session.DeviceID)
+			point.SessionID = &sessionID
+			point.GPS.Latitude = lat + float64(i)*0.001
+			point.GPS.Longitude = lon
+			require.NoError(t, telemetryRepo.Save(ctx, point))
+		}
+	}
+
+	startedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+
+	// Recorded at Serres without a location: tagged and named after the circuit
+	atCircuit := &models.Session{DeviceID: "RACEBOX-001", UserID: &user.ID, StartedAt: startedAt}
+	require.NoError(t, repo.Create(ctx, atCircuit))
+	record(atCircuit, 41.069, 23.512)
+
+	summarized, err := repo.UpdateSummary(ctx, atCircuit.ID)
+	require.NoError(t, err)
+	require.NotNil(t, summarized.CircuitID)
+	assert.Equal(t, circuitID, summarized.CircuitID.String())
+	require.NotNil(t, summarized.Location)
+	assert.Equal(t, "Serres Racing Circuit", *summarized.Location)
+
+	// A user-provided location is kept
+	location := "Serres (club day)"
+	named := &models.Session{DeviceID: "RACEBOX-002", UserID: &user.ID, StartedAt: startedAt, Location: &location}
+	require.NoError(t, repo.Create(ctx, named))
+	record(named, 41.069, 23.512)
+
+	summarized, err = repo.UpdateSummary(ctx, named.ID)
+	require.NoError(t, err)
+	require.NotNil(t, summarized.CircuitID)
+	assert.Equal(t, location, *summarized.Location)
+
+	// Recorded elsewhere: no circuit detected
+	elsewhere := &models.Session{DeviceID: "RACEBOX-003", UserID: &user.ID, StartedAt: startedAt}
+	require.NoError(t, repo.Create(ctx, elsewhere))
+	record(elsewhere, 42.0, 23.0)
+
+	summarized, err = repo.UpdateSummary(ctx, elsewhere.ID)
+	require.NoError(t, err)
+	assert.Nil(t, summarized.CircuitID)
+	assert.Nil(t, summarized.Location)
+}
@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrTelemetryDeletionNotFound is returned when a telemetry correction is not found
+	ErrTelemetryDeletionNotFound = errors.New("telemetry deletion not found")
+
+	// ErrTelemetryDeletionExpired is returned when undoing a correction that can no longer be undone
+	ErrTelemetryDeletionExpired = errors.New("telemetry deletion can no longer be undone")
+
+	// ErrNoTelemetryToDelete is returned when a correction matches no visible telemetry records
+	ErrNoTelemetryToDelete = errors.New("no telemetry to delete")
+)
+
+// telemetryDeletionColumns is the column list used by all telemetry deletion SELECT queries
+const telemetryDeletionColumns = `
+	id, user_id, requested_by, device_id, session_id, kind,
+	starts_at, ends_at, points, first_recorded_at, last_recorded_at,
+	undo_until, undone_at, purged_at, created_at
+`
+
+// aggregateRefreshAlignment is the widest continuous aggregate bucket
+// Refresh windows are widened to it so every bucket holding a changed record is recomputed.
+const aggregateRefreshAlignment = 10 * time.Minute
+
+// PostgresTelemetryDeletionRepository implements TelemetryDeletionRepository using PostgreSQL
+type PostgresTelemetryDeletionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresTelemetryDeletionRepository creates a new PostgreSQL telemetry deletion repository
+func NewPostgresTelemetryDeletionRepository(db *sql.DB) *PostgresTelemetryDeletionRepository {
+	return &PostgresTelemetryDeletionRepository{db: db}
+}
+
+// Create flags the records selected by the correction as deleted and stores it
+func (r *PostgresTelemetryDeletionRepository) Create(ctx context.Context, deletion *models.TelemetryDeletion) error {
+	if deletion.ID == uuid.Nil {
+		deletion.ID = uuid.New()
+	}
+	deletion.CreatedAt = time.Now()
+
+	var selection string
+	var args []interface{}
+	switch deletion.Kind {
+	case models.TelemetryDeletionTrim:
+		if deletion.SessionID == nil {
+			return fmt.Errorf("trim requires a session")
+		}
+		selection = `session_id = $2 AND (($3::timestamptz IS NOT NULL AND recorded_at < $3) OR ($4::timestamptz IS NOT NULL AND recorded_at > $4))`
+		args = []interface{}{deletion.ID, *deletion.SessionID, deletion.StartsAt, deletion.EndsAt}
+	case models.TelemetryDeletionRange:
+		if deletion.StartsAt == nil || deletion.EndsAt == nil {
+			return fmt.Errorf("range deletion requires a start and an end")
+		}
+		selection = `device_id = $2 AND user_id = $3 AND recorded_at BETWEEN $4 AND $5`
+		args = []interface{}{deletion.ID, deletion.DeviceID, deletion.UserID, *deletion.StartsAt, *deletion.EndsAt}
+	default:
+		return fmt.Errorf("unknown telemetry deletion kind %q", deletion.Kind)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	// deletion_id has no foreign key, so records are flagged before the correction is stored
+	var first, last sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		WITH flagged AS (
+			UPDATE telemetry
+			SET deletion_id = $1
+			WHERE `+selection+` AND `+visibleTelemetry+`
+			RETURNING recorded_at
+		)
+		SELECT COUNT(*), MIN(recorded_at), MAX(recorded_at) FROM flagged
+	`, args...).Scan(&deletion.Points, &first, &last)
+	if err != nil {
+		return fmt.Errorf("failed to flag telemetry: %w", err)
+	}
+	if deletion.Points == 0 {
+		return ErrNoTelemetryToDelete
+	}
+	deletion.FirstRecordedAt = &first.Time
+	deletion.LastRecordedAt = &last.Time
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO telemetry_deletions (
+			id, user_id, requested_by, device_id, session_id, kind,
+			starts_at, ends_at, points, first_recorded_at, last_recorded_at,
+			undo_until, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, deletion.ID, deletion.UserID, deletion.RequestedBy, deletion.DeviceID, deletion.SessionID, deletion.Kind,
+		deletion.StartsAt, deletion.EndsAt, deletion.Points, deletion.FirstRecordedAt, deletion.LastRecordedAt,
+		deletion.UndoUntil, deletion.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert telemetry deletion: %w", err)
+	}
+
+	if err := markSessionsStale(ctx, tx, deletion.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	refreshAggregates(ctx, r.db, *deletion.FirstRecordedAt, *deletion.LastRecordedAt)
+	return nil
+}
+
+// GetByID retrieves a correction by its ID
+func (r *PostgresTelemetryDeletionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TelemetryDeletion, error) {
+	deletion, err := scanTelemetryDeletion(r.db.QueryRowContext(ctx,
+		`SELECT `+telemetryDeletionColumns+` FROM telemetry_deletions WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTelemetryDeletionNotFound
+		}
+		return nil, fmt.Errorf("failed to get telemetry deletion: %w", err)
+	}
+	return deletion, nil
+}
+
+// ListByUser returns the corrections of records the user owns or requested, most recent first
+func (r *PostgresTelemetryDeletionRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.TelemetryDeletion, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+telemetryDeletionColumns+`
+		FROM telemetry_deletions
+		WHERE user_id = $1 OR requested_by = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list telemetry deletions: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	deletions := []*models.TelemetryDeletion{}
+	for rows.Next() {
+		deletion, err := scanTelemetryDeletion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry deletion: %w", err)
+		}
+		deletions = append(deletions, deletion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry deletions: %w", err)
+	}
+
+	return deletions, nil
+}
+
+// Undo restores the correction's records
+func (r *PostgresTelemetryDeletionRepository) Undo(ctx context.Context, id uuid.UUID) (*models.TelemetryDeletion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	// Locking the correction row keeps the purge from deleting its records while they are restored
+	deletion, err := scanTelemetryDeletion(tx.QueryRowContext(ctx, `
+		UPDATE telemetry_deletions
+		SET undone_at = NOW()
+		WHERE id = $1 AND undone_at IS NULL AND purged_at IS NULL AND undo_until > NOW()
+		RETURNING `+telemetryDeletionColumns, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := r.GetByID(ctx, id); err != nil {
+				return nil, err
+			}
+			return nil, ErrTelemetryDeletionExpired
+		}
+		return nil, fmt.Errorf("failed to undo telemetry deletion: %w", err)
+	}
+
+	// Sessions are marked before the flags are cleared, while the records still point at the correction
+	if err := markSessionsStale(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE telemetry SET deletion_id = NULL WHERE deletion_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to restore telemetry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if deletion.FirstRecordedAt != nil && deletion.LastRecordedAt != nil {
+		refreshAggregates(ctx, r.db, *deletion.FirstRecordedAt, *deletion.LastRecordedAt)
+	}
+	return deletion, nil
+}
+
+// ListExpired returns the IDs of corrections whose undo window closed before now and that have not been purged
+func (r *PostgresTelemetryDeletionRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id
+		FROM telemetry_deletions
+		WHERE undone_at IS NULL AND purged_at IS NULL AND undo_until <= $1
+		ORDER BY undo_until
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired telemetry deletions: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry deletion: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry deletions: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Purge permanently deletes the correction's records and their smoothed counterparts
+func (r *PostgresTelemetryDeletionRepository) Purge(ctx context.Context, id uuid.UUID) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE telemetry_deletions
+		SET purged_at = NOW()
+		WHERE id = $1 AND undone_at IS NULL AND purged_at IS NULL
+	`, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge telemetry deletion: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return 0, ErrTelemetryDeletionNotFound
+	}
+
+	var deleted int64
+	err = tx.QueryRowContext(ctx, `
+		WITH purged AS (
+			DELETE FROM telemetry
+			WHERE deletion_id = $1
+			RETURNING id, recorded_at
+		), processed AS (
+			DELETE FROM telemetry_processed
+			USING purged
+			WHERE telemetry_id = purged.id AND telemetry_recorded_at = purged.recorded_at
+		)
+		SELECT COUNT(*) FROM purged
+	`, id).Scan(&deleted)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete telemetry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// refreshAggregates recomputes the continuous aggregate buckets covering from to to
+// Refreshing cannot run inside a transaction, so it follows the commit; a failure only leaves the
+// aggregates stale until their refresh policy next covers the range, and is logged.
+func refreshAggregates(ctx context.Context, db *sql.DB, from, to time.Time) {
+	start := from.Truncate(aggregateRefreshAlignment)
+	end := to.Truncate(aggregateRefreshAlignment).Add(aggregateRefreshAlignment)
+	for _, view := range aggregateViews {
+		if _, err := db.ExecContext(ctx, `CALL refresh_continuous_aggregate($1, $2::timestamptz, $3::timestamptz)`, view, start, end); err != nil {
+			slog.Warn("Failed to refresh continuous aggregate after telemetry deletion",
+				"view", view, "start", start, "end", end, "error", err)
+		}
+	}
+}
+
+// markSessionsStale clears the summaries and stats of sessions holding the correction's records
+// Summaries are recomputed by the next aggregation sweep and stats on their next request.
+func markSessionsStale(ctx context.Context, tx *sql.Tx, deletionID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE sessions
+		SET summary_computed_at = NULL, stats = NULL, stats_computed_at = NULL
+		WHERE id IN (SELECT DISTINCT session_id FROM telemetry WHERE deletion_id = $1 AND session_id IS NOT NULL)
+	`, deletionID)
+	if err != nil {
+		return fmt.Errorf("failed to mark sessions for recomputation: %w", err)
+	}
+	return nil
+}
+
+// scanTelemetryDeletion scans a telemetry deletion row in telemetryDeletionColumns order
+func scanTelemetryDeletion(row rowScanner) (*models.TelemetryDeletion, error) {
+	var deletion models.TelemetryDeletion
+	err := row.Scan(
+		&deletion.ID,
+		&deletion.UserID,
+		&deletion.RequestedBy,
+		&deletion.DeviceID,
+		&deletion.SessionID,
+		&deletion.Kind,
+		&deletion.StartsAt,
+		&deletion.EndsAt,
+		&deletion.Points,
+		&deletion.FirstRecordedAt,
+		&deletion.LastRecordedAt,
+		&deletion.UndoUntil,
+		&deletion.UndoneAt,
+		&deletion.PurgedAt,
+		&deletion.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
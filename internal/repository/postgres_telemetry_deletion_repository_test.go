@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresTelemetryDeletionRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresTelemetryDeletionRepository(db.DB)
+	telemetryRepo := NewPostgresRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "corrections@example.com")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	records := make([]*models.TelemetryData, 0, 5)
+	for i := 5; i > 0; i-- {
+		record := createSampleTelemetry(now.Add(-time.Duration(i)*time.Minute), "CORRECT-001")
+		record.UserID = &user.ID
+		records = append(records, record)
+	}
+	require.NoError(t, telemetryRepo.SaveBatch(ctx, records))
+
+	// Nothing matches a range without records
+	start, end := now.Add(-time.Hour), now.Add(-50*time.Minute)
+	err := repo.Create(ctx, &models.TelemetryDeletion{
+		UserID: user.ID, DeviceID: "CORRECT-001", Kind: models.TelemetryDeletionRange,
+		StartsAt: &start, EndsAt: &end, UndoUntil: now.Add(time.Hour),
+	})
+	assert.ErrorIs(t, err, ErrNoTelemetryToDelete)
+
+	// Deleting the middle three minutes hides their records
+	start, end = now.Add(-4*time.Minute), now.Add(-2*time.Minute)
+	deletion := &models.TelemetryDeletion{
+		UserID: user.ID, RequestedBy: &user.ID, DeviceID: "CORRECT-001", Kind: models.TelemetryDeletionRange,
+		StartsAt: &start, EndsAt: &end, UndoUntil: now.Add(time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, deletion))
+	assert.Equal(t, int64(3), deletion.Points)
+	require.NotNil(t, deletion.FirstRecordedAt)
+	assert.True(t, deletion.FirstRecordedAt.Equal(start))
+
+	visible, err := telemetryRepo.GetByDevice(ctx, "CORRECT-001", 10)
+	require.NoError(t, err)
+	assert.Len(t, visible, 2)
+
+	listed, err := repo.ListByUser(ctx, user.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, deletion.ID, listed[0].ID)
+
+	// Undoing restores the records, and only once
+	undone, err := repo.Undo(ctx, deletion.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, undone.UndoneAt)
+	_, err = repo.Undo(ctx, deletion.ID)
+	assert.ErrorIs(t, err, ErrTelemetryDeletionExpired)
+	_, err = repo.Undo(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrTelemetryDeletionNotFound)
+
+	visible, err = telemetryRepo.GetByDevice(ctx, "CORRECT-001", 10)
+	require.NoError(t, err)
+	assert.Len(t, visible, 5)
+
+	// An expired correction is purged
+	expired := &models.TelemetryDeletion{
+		UserID: user.ID, DeviceID: "CORRECT-001", Kind: models.TelemetryDeletionRange,
+		StartsAt: &start, EndsAt: &end, UndoUntil: now.Add(-time.Minute),
+	}
+	require.NoError(t, repo.Create(ctx, expired))
+
+	ids, err := repo.ListExpired(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{expired.ID}, ids)
+
+	purged, err := repo.Purge(ctx, expired.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+
+	ids, err = repo.ListExpired(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	stored, err := repo.GetByID(ctx, expired.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.PurgedAt)
+	assert.False(t, stored.CanUndo(time.Now()))
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PostgresTelemetryPurgeRepository implements TelemetryPurgeRepository using TimescaleDB chunk operations
+type PostgresTelemetryPurgeRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresTelemetryPurgeRepository creates a new PostgreSQL telemetry purge repository
+func NewPostgresTelemetryPurgeRepository(db *sql.DB) *PostgresTelemetryPurgeRepository {
+	return &PostgresTelemetryPurgeRepository{db: db}
+}
+
+// Plan reports the chunks and records a purge would remove without changing anything
+func (r *PostgresTelemetryPurgeRepository) Plan(ctx context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	purge, err := planTelemetryPurge(ctx, tx, filter)
+	if err != nil {
+		return nil, err
+	}
+	purge.DryRun = true
+
+	return purge, nil
+}
+
+// Purge drops the chunks lying entirely within the range and deletes the matching records of the others
+func (r *PostgresTelemetryPurgeRepository) Purge(ctx context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	purge, err := planTelemetryPurge(ctx, tx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCondition, args := "", []interface{}{filter.From, filter.To}
+	if filter.DeviceID != "" {
+		deviceCondition = " AND device_id = $3"
+		args = append(args, filter.DeviceID)
+	}
+
+	// Smoothed records have no foreign key to the raw ones, so they are removed first by time
+	processedCondition := ""
+	if filter.DeviceID != "" {
+		processedCondition = " AND session_id IN (SELECT id FROM sessions WHERE device_id = $3)"
+	}
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM telemetry_processed
+		WHERE telemetry_recorded_at >= $1 AND telemetry_recorded_at < $2`+processedCondition,
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete processed telemetry: %w", err)
+	}
+
+	if purge.ChunksDropped > 0 {
+		var dropped int
+		err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM drop_chunks('telemetry', older_than => $2::timestamptz, newer_than => $1::timestamptz)
+		`, filter.From, filter.To).Scan(&dropped)
+		if err != nil {
+			return nil, fmt.Errorf("failed to drop telemetry chunks: %w", err)
+		}
+		if dropped != purge.ChunksDropped {
+			slog.Warn("Dropped a different number of telemetry chunks than planned",
+				"planned", purge.ChunksDropped, "dropped", dropped)
+		}
+	}
+
+	// Dropped chunks are gone, so this only reaches the chunks the range covers in part
+	var deleted int64
+	if purge.ChunksTrimmed > 0 {
+		result, err := tx.ExecContext(ctx, `
+			DELETE FROM telemetry
+			WHERE recorded_at >= $1 AND recorded_at < $2`+deviceCondition,
+			args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete telemetry: %w", err)
+		}
+		if deleted, err = result.RowsAffected(); err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+	}
+
+	// Report the records actually deleted in place of the planned count
+	for _, chunk := range purge.Chunks {
+		if chunk.Action == models.TelemetryPurgeDelete {
+			purge.Rows -= chunk.Rows
+		}
+	}
+	purge.Rows += deleted
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sessions
+		SET summary_computed_at = NULL, stats = NULL, stats_computed_at = NULL
+		WHERE started_at < $2 AND (ended_at IS NULL OR ended_at >= $1)`+deviceCondition,
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark sessions for recomputation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// Continuous aggregates keep their buckets for dropped chunks, as with the retention policy
+	if deleted > 0 {
+		refreshAggregates(ctx, r.db, filter.From, filter.To)
+	}
+	return purge, nil
+}
+
+// planTelemetryPurge lists the telemetry chunks overlapping the range and how each would be purged
+// Chunks that are dropped whole get a row estimate from planner statistics; the matching records
+// of the others are counted.
+func planTelemetryPurge(ctx context.Context, tx *sql.Tx, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT format('%I.%I', chunk_schema, chunk_name), range_start, range_end, is_compressed
+		FROM timescaledb_information.chunks
+		WHERE hypertable_name = 'telemetry' AND range_end > $1 AND range_start < $2
+		ORDER BY range_start
+	`, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list telemetry chunks: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	chunks := []models.TelemetryChunkPurge{}
+	for rows.Next() {
+		var chunk models.TelemetryChunkPurge
+		if err := rows.Scan(&chunk.Chunk, &chunk.RangeStart, &chunk.RangeEnd, &chunk.Compressed); err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry chunk: %w", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry chunks: %w", err)
+	}
+
+	purge := &models.TelemetryPurge{
+		DeviceID: filter.DeviceID,
+		From:     filter.From,
+		To:       filter.To,
+		Chunks:   []models.TelemetryChunkPurge{},
+	}
+
+	for _, chunk := range chunks {
+		whole := filter.DeviceID == "" && !chunk.RangeStart.Before(filter.From) && !chunk.RangeEnd.After(filter.To)
+		if whole {
+			chunk.Action = models.TelemetryPurgeDropChunk
+			chunk.Estimated = true
+			err := tx.QueryRowContext(ctx,
+				`SELECT GREATEST(reltuples, 0)::bigint FROM pg_class WHERE oid = $1::regclass`, chunk.Chunk).Scan(&chunk.Rows)
+			if err != nil {
+				return nil, fmt.Errorf("failed to estimate rows of %s: %w", chunk.Chunk, err)
+			}
+		} else {
+			chunk.Action = models.TelemetryPurgeDelete
+			rows, err := countPurgedTelemetry(ctx, tx, filter.DeviceID, latest(chunk.RangeStart, filter.From), earliest(chunk.RangeEnd, filter.To))
+			if err != nil {
+				return nil, err
+			}
+			if rows == 0 {
+				continue
+			}
+			chunk.Rows = rows
+		}
+		purge.AddChunk(chunk)
+	}
+
+	return purge, nil
+}
+
+// countPurgedTelemetry counts the records recorded from from to to, of one device if deviceID is set
+func countPurgedTelemetry(ctx context.Context, tx *sql.Tx, deviceID string, from, to time.Time) (int64, error) {
+	query := `SELECT COUNT(*) FROM telemetry WHERE recorded_at >= $1 AND recorded_at < $2`
+	args := []interface{}{from, to}
+	if deviceID != "" {
+		query += ` AND device_id = $3`
+		args = append(args, deviceID)
+	}
+
+	var count int64
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count telemetry: %w", err)
+	}
+	return count, nil
+}
+
+// latest returns the later of two times
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// earliest returns the earlier of two times
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresTelemetryPurgeRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresTelemetryPurgeRepository(db.DB)
+	telemetryRepo := NewPostgresRepository(db)
+	ctx := context.Background()
+
+	// Recent records share their chunk with telemetry outside the purged range
+	now := time.Now().UTC().Truncate(time.Second)
+	var records []*models.TelemetryData
+	for i := 1; i <= 5; i++ {
+		records = append(records,
+			createSampleTelemetry(now.Add(-time.Duration(i)*time.Minute), "PURGE-001"),
+			createSampleTelemetry(now.Add(-time.Duration(i)*time.Minute), "PURGE-002"))
+	}
+	// Old records fill chunks of their own
+	old := time.Date(2001, 1, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		records = append(records, createSampleTelemetry(old.Add(time.Duration(i)*time.Hour), "PURGE-001"))
+	}
+	require.NoError(t, telemetryRepo.SaveBatch(ctx, records))
+
+	recent := TelemetryPurgeFilter{DeviceID: "PURGE-001", From: now.Add(-3*time.Minute - time.Second), To: now}
+	plan, err := repo.Plan(ctx, recent)
+	require.NoError(t, err)
+	assert.True(t, plan.DryRun)
+	assert.Equal(t, int64(3), plan.Rows)
+	assert.False(t, plan.RowsEstimated)
+	assert.Zero(t, plan.ChunksDropped, "chunks are never dropped for a single device")
+	require.NotEmpty(t, plan.Chunks)
+	assert.Equal(t, models.TelemetryPurgeDelete, plan.Chunks[0].Action)
+
+	remaining, err := telemetryRepo.GetByDevice(ctx, "PURGE-001", 20)
+	require.NoError(t, err)
+	assert.Len(t, remaining, 8, "a dry run deletes nothing")
+
+	purged, err := repo.Purge(ctx, recent)
+	require.NoError(t, err)
+	assert.False(t, purged.DryRun)
+	assert.Equal(t, int64(3), purged.Rows)
+
+	remaining, err = telemetryRepo.GetByDevice(ctx, "PURGE-001", 20)
+	require.NoError(t, err)
+	assert.Len(t, remaining, 5)
+	other, err := telemetryRepo.GetByDevice(ctx, "PURGE-002", 20)
+	require.NoError(t, err)
+	assert.Len(t, other, 5, "other devices are untouched")
+
+	// A range covering whole chunks drops them
+	historic := TelemetryPurgeFilter{From: time.Date(2000, 12, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2001, 3, 1, 0, 0, 0, 0, time.UTC)}
+	plan, err = repo.Plan(ctx, historic)
+	require.NoError(t, err)
+	assert.Equal(t, 1, plan.ChunksDropped)
+	assert.Zero(t, plan.ChunksTrimmed)
+	assert.True(t, plan.RowsEstimated)
+
+	purged, err = repo.Purge(ctx, historic)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged.ChunksDropped)
+
+	remaining, err = telemetryRepo.GetByDevice(ctx, "PURGE-001", 20)
+	require.NoError(t, err)
+	assert.Len(t, remaining, 2)
+
+	plan, err = repo.Plan(ctx, historic)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Chunks)
+}
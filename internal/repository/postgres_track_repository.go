@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ErrTrackNotFound is returned when a track is not found
+var ErrTrackNotFound = errors.New("track not found")
+
+// trackColumns is the column list used by all track SELECT queries
+const trackColumns = `
+	id, user_id, name,
+	line_start_lat, line_start_lon, line_end_lat, line_end_lon,
+	created_at, updated_at
+`
+
+// PostgresTrackRepository implements TrackRepository using PostgreSQL
+type PostgresTrackRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresTrackRepository creates a new PostgreSQL track repository
+func NewPostgresTrackRepository(db *sql.DB) *PostgresTrackRepository {
+	return &PostgresTrackRepository{db: db}
+}
+
+// Create stores a new track
+func (r *PostgresTrackRepository) Create(ctx context.Context, track *models.Track) error {
+	query := `
+		INSERT INTO tracks (
+			id, user_id, name,
+			line_start_lat, line_start_lon, line_end_lat, line_end_lon,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	if track.ID == uuid.Nil {
+		track.ID = uuid.New()
+	}
+
+	now := time.Now()
+	if track.CreatedAt.IsZero() {
+		track.CreatedAt = now
+	}
+	if track.UpdatedAt.IsZero() {
+		track.UpdatedAt = now
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		track.ID,
+		track.UserID,
+		track.Name,
+		track.LineStart.Latitude,
+		track.LineStart.Longitude,
+		track.LineEnd.Latitude,
+		track.LineEnd.Longitude,
+		track.CreatedAt,
+		track.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert track: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a track by its UUID
+func (r *PostgresTrackRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Track, error) {
+	query := `SELECT ` + trackColumns + ` FROM tracks WHERE id = $1`
+
+	track, err := scanTrack(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTrackNotFound
+		}
+		return nil, fmt.Errorf("failed to get track: %w", err)
+	}
+
+	return track, nil
+}
+
+// ListByUserID retrieves all tracks owned by a user, most recent first
+func (r *PostgresTrackRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Track, error) {
+	query := `
+		SELECT ` + trackColumns + `
+		FROM tracks
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tracks: %w", err)
+	}
+	defer rows.Close()
+
+	tracks := make([]*models.Track, 0)
+	for rows.Next() {
+		track, err := scanTrack(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan track row: %w", err)
+		}
+		tracks = append(tracks, track)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating track rows: %w", err)
+	}
+
+	return tracks, nil
+}
+
+// scanTrack scans a single track row selected with trackColumns
+func scanTrack(row rowScanner) (*models.Track, error) {
+	var track models.Track
+
+	err := row.Scan(
+		&track.ID,
+		&track.UserID,
+		&track.Name,
+		&track.LineStart.Latitude,
+		&track.LineStart.Longitude,
+		&track.LineEnd.Latitude,
+		&track.LineEnd.Longitude,
+		&track.CreatedAt,
+		&track.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &track, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresTrackRepository_CreateGetAndList(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresTrackRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "tracks@example.com")
+	other := createTestUser(t, db, "other-tracks@example.com")
+
+	first := &models.Track{
+		UserID:    user.ID,
+		Name:      "Serres Circuit",
+		LineStart: geo.Point{Latitude: 41.0717, Longitude: 23.5112},
+		LineEnd:   geo.Point{Latitude: 41.0719, Longitude: 23.5112},
+		CreatedAt: time.Now().Add(-time.Hour),
+	}
+	second := &models.Track{
+		UserID:    user.ID,
+		Name:      "Kart Track",
+		LineStart: geo.Point{Latitude: 42.6500, Longitude: 23.3800},
+		LineEnd:   geo.Point{Latitude: 42.6501, Longitude: 23.3800},
+	}
+	require.NoError(t, repo.Create(ctx, first))
+	require.NoError(t, repo.Create(ctx, second))
+	require.NoError(t, repo.Create(ctx, &models.Track{
+		UserID:    other.ID,
+		Name:      "Someone Else's",
+		LineStart: geo.Point{Latitude: 50.4372, Longitude: 5.9714},
+		LineEnd:   geo.Point{Latitude: 50.4374, Longitude: 5.9714},
+	}))
+	assert.NotEqual(t, uuid.Nil, first.ID)
+
+	retrieved, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Serres Circuit", retrieved.Name)
+	assert.Equal(t, user.ID, retrieved.UserID)
+	assert.InDelta(t, 41.0719, retrieved.LineEnd.Latitude, 1e-9)
+
+	tracks, err := repo.ListByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, tracks, 2)
+	assert.Equal(t, second.ID, tracks[0].ID, "most recent track first")
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrTrackNotFound)
+}
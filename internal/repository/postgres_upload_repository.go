@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrUploadNotFound is returned when an upload is not found
+	ErrUploadNotFound = errors.New("upload not found")
+
+	// ErrUploadNotOpen is returned when chunks are sent to or an upload is finalized after finalizing
+	ErrUploadNotOpen = errors.New("upload is not open")
+
+	// ErrUploadOffsetMismatch is returned when a chunk does not start at the bytes received so far
+	ErrUploadOffsetMismatch = errors.New("chunk offset does not match received bytes")
+
+	// ErrUploadSizeExceeded is returned when a chunk would extend past the declared upload size
+	ErrUploadSizeExceeded = errors.New("chunk exceeds declared upload size")
+
+	// ErrUploadIncomplete is returned when an upload is finalized before every byte was received
+	ErrUploadIncomplete = errors.New("upload is incomplete")
+
+	// ErrUploadChunkNotFound is returned when no received chunk holds the requested offset
+	ErrUploadChunkNotFound = errors.New("upload chunk not found")
+)
+
+// uploadColumns is the column list used by all upload SELECT queries
+const uploadColumns = `
+	id, user_id, device_id, size, received_bytes, processed_bytes, status,
+	lines, accepted, skipped, rejected, line_errors, error,
+	created_at, updated_at, completed_at
+`
+
+// PostgresUploadRepository implements UploadRepository using PostgreSQL
+type PostgresUploadRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresUploadRepository creates a new PostgreSQL upload repository
+func NewPostgresUploadRepository(db *sql.DB) *PostgresUploadRepository {
+	return &PostgresUploadRepository{db: db}
+}
+
+// Create stores a new open upload
+func (r *PostgresUploadRepository) Create(ctx context.Context, upload *models.Upload) error {
+	query := `
+		INSERT INTO uploads (id, user_id, device_id, size, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	if upload.ID == uuid.Nil {
+		upload.ID = uuid.New()
+	}
+	upload.Status = models.UploadOpen
+	if upload.Errors == nil {
+		upload.Errors = []models.UploadLineError{}
+	}
+
+	now := time.Now()
+	if upload.CreatedAt.IsZero() {
+		upload.CreatedAt = now
+	}
+	if upload.UpdatedAt.IsZero() {
+		upload.UpdatedAt = now
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		upload.ID,
+		upload.UserID,
+		upload.DeviceID,
+		upload.Size,
+		upload.Status,
+		upload.CreatedAt,
+		upload.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert upload: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an upload by its UUID
+func (r *PostgresUploadRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Upload, error) {
+	query := `SELECT ` + uploadColumns + ` FROM uploads WHERE id = $1`
+
+	upload, err := scanUpload(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+
+	return upload, nil
+}
+
+// AppendChunk stores the next chunk of an open upload, returning the bytes received so far
+func (r *PostgresUploadRepository) AppendChunk(ctx context.Context, id uuid.UUID, offset int64, data []byte) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	// Lock the upload so concurrent chunks for the same offset cannot both be stored
+	var size, received int64
+	var status models.UploadStatus
+	err = tx.QueryRowContext(ctx, `
+		SELECT size, received_bytes, status FROM uploads WHERE id = $1 FOR UPDATE
+	`, id).Scan(&size, &received, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrUploadNotFound
+		}
+		return 0, fmt.Errorf("failed to lock upload: %w", err)
+	}
+
+	switch {
+	case status != models.UploadOpen:
+		return received, ErrUploadNotOpen
+	case offset != received:
+		return received, ErrUploadOffsetMismatch
+	case offset+int64(len(data)) > size:
+		return received, ErrUploadSizeExceeded
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO upload_chunks (upload_id, byte_offset, data) VALUES ($1, $2, $3)
+	`, id, offset, data); err != nil {
+		return 0, fmt.Errorf("failed to insert upload chunk: %w", err)
+	}
+
+	received += int64(len(data))
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE uploads SET received_bytes = $2 WHERE id = $1
+	`, id, received); err != nil {
+		return 0, fmt.Errorf("failed to update upload: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit upload chunk: %w", err)
+	}
+
+	return received, nil
+}
+
+// Finalize marks a fully received open upload as pending ingestion
+func (r *PostgresUploadRepository) Finalize(ctx context.Context, id uuid.UUID) (*models.Upload, error) {
+	query := `
+		UPDATE uploads SET status = 'pending'
+		WHERE id = $1 AND status = 'open' AND received_bytes = size
+		RETURNING ` + uploadColumns
+
+	upload, err := scanUpload(r.db.QueryRowContext(ctx, query, id))
+	if err == nil {
+		return upload, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to finalize upload: %w", err)
+	}
+
+	// Nothing was updated; report why
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != models.UploadOpen {
+		return current, ErrUploadNotOpen
+	}
+	return current, ErrUploadIncomplete
+}
+
+// ClaimNext marks the oldest pending upload as processing and returns it
+func (r *PostgresUploadRepository) ClaimNext(ctx context.Context, staleBefore time.Time) (*models.Upload, error) {
+	query := `
+		UPDATE uploads SET status = 'processing'
+		WHERE id = (
+			SELECT id FROM uploads
+			WHERE status = 'pending' OR (status = 'processing' AND updated_at < $1)
+			ORDER BY updated_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + uploadColumns
+
+	upload, err := scanUpload(r.db.QueryRowContext(ctx, query, staleBefore))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim upload: %w", err)
+	}
+
+	return upload, nil
+}
+
+// GetChunk retrieves the chunk holding the byte at the given offset and the offset it starts at
+func (r *PostgresUploadRepository) GetChunk(ctx context.Context, id uuid.UUID, offset int64) (int64, []byte, error) {
+	query := `
+		SELECT byte_offset, data
+		FROM upload_chunks
+		WHERE upload_id = $1 AND byte_offset <= $2
+		ORDER BY byte_offset DESC
+		LIMIT 1
+	`
+
+	var start int64
+	var data []byte
+	err := r.db.QueryRowContext(ctx, query, id, offset).Scan(&start, &data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil, ErrUploadChunkNotFound
+		}
+		return 0, nil, fmt.Errorf("failed to get upload chunk: %w", err)
+	}
+	if offset >= start+int64(len(data)) {
+		return 0, nil, ErrUploadChunkNotFound
+	}
+
+	return start, data, nil
+}
+
+// UpdateProgress records the ingestion status, counters and errors of an upload
+func (r *PostgresUploadRepository) UpdateProgress(ctx context.Context, upload *models.Upload) error {
+	lineErrors, err := json.Marshal(upload.Errors)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload errors: %w", err)
+	}
+	if upload.Errors == nil {
+		lineErrors = []byte("[]")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	query := `
+		UPDATE uploads
+		SET status = $2,
+			processed_bytes = $3,
+			lines = $4,
+			accepted = $5,
+			skipped = $6,
+			rejected = $7,
+			line_errors = $8,
+			error = $9,
+			completed_at = CASE WHEN $10 THEN NOW() ELSE NULL END
+		WHERE id = $1
+		RETURNING updated_at, completed_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		upload.ID,
+		upload.Status,
+		upload.ProcessedBytes,
+		upload.Lines,
+		upload.Accepted,
+		upload.Skipped,
+		upload.Rejected,
+		lineErrors,
+		upload.Error,
+		upload.Status.IsFinished(),
+	).Scan(&upload.UpdatedAt, &upload.CompletedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUploadNotFound
+		}
+		return fmt.Errorf("failed to update upload: %w", err)
+	}
+
+	// The data is no longer needed once ingestion has finished
+	if upload.Status.IsFinished() {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM upload_chunks WHERE upload_id = $1`, upload.ID); err != nil {
+			return fmt.Errorf("failed to delete upload chunks: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit upload progress: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAbandoned deletes open uploads not updated since before, returning how many were deleted
+func (r *PostgresUploadRepository) DeleteAbandoned(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM uploads WHERE status = 'open' AND updated_at < $1
+	`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete abandoned uploads: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// scanUpload scans a row selected with uploadColumns
+func scanUpload(row *sql.Row) (*models.Upload, error) {
+	var upload models.Upload
+	var lineErrors []byte
+	err := row.Scan(
+		&upload.ID,
+		&upload.UserID,
+		&upload.DeviceID,
+		&upload.Size,
+		&upload.ReceivedBytes,
+		&upload.ProcessedBytes,
+		&upload.Status,
+		&upload.Lines,
+		&upload.Accepted,
+		&upload.Skipped,
+		&upload.Rejected,
+		&lineErrors,
+		&upload.Error,
+		&upload.CreatedAt,
+		&upload.UpdatedAt,
+		&upload.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(lineErrors, &upload.Errors); err != nil {
+		return nil, fmt.Errorf("failed to decode upload errors: %w", err)
+	}
+	if upload.Errors == nil {
+		upload.Errors = []models.UploadLineError{}
+	}
+
+	return &upload, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresUploadRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresUploadRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "upload@example.com")
+
+	upload := &models.Upload{UserID: user.ID, DeviceID: "RACEBOX-001", Size: 10}
+	require.NoError(t, repo.Create(ctx, upload))
+
+	got, err := repo.GetByID(ctx, upload.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.UploadOpen, got.Status)
+	assert.Equal(t, int64(10), got.Size)
+	assert.Empty(t, got.Errors)
+
+	// Chunks must be sent in order and within the declared size
+	received, err := repo.AppendChunk(ctx, upload.ID, 0, []byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), received)
+
+	received, err = repo.AppendChunk(ctx, upload.ID, 0, []byte("hello"))
+	assert.ErrorIs(t, err, ErrUploadOffsetMismatch)
+	assert.Equal(t, int64(5), received)
+
+	_, err = repo.AppendChunk(ctx, upload.ID, 5, []byte("world!"))
+	assert.ErrorIs(t, err, ErrUploadSizeExceeded)
+
+	_, err = repo.Finalize(ctx, upload.ID)
+	assert.ErrorIs(t, err, ErrUploadIncomplete)
+
+	_, err = repo.AppendChunk(ctx, upload.ID, 5, []byte("world"))
+	require.NoError(t, err)
+
+	finalized, err := repo.Finalize(ctx, upload.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.UploadPending, finalized.Status)
+	assert.Equal(t, int64(10), finalized.ReceivedBytes)
+
+	_, err = repo.Finalize(ctx, upload.ID)
+	assert.ErrorIs(t, err, ErrUploadNotOpen)
+	_, err = repo.AppendChunk(ctx, upload.ID, 10, []byte("x"))
+	assert.ErrorIs(t, err, ErrUploadNotOpen)
+
+	// Chunks are found by any offset they hold
+	start, data, err := repo.GetChunk(ctx, upload.ID, 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), start)
+	assert.Equal(t, []byte("world"), data)
+	_, _, err = repo.GetChunk(ctx, upload.ID, 10)
+	assert.ErrorIs(t, err, ErrUploadChunkNotFound)
+
+	// A pending upload is claimed once; a stale processing one is claimed again
+	claimed, err := repo.ClaimNext(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, upload.ID, claimed.ID)
+	assert.Equal(t, models.UploadProcessing, claimed.Status)
+
+	none, err := repo.ClaimNext(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	reclaimed, err := repo.ClaimNext(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, reclaimed)
+	assert.Equal(t, upload.ID, reclaimed.ID)
+
+	claimed.Lines, claimed.Accepted, claimed.Rejected = 3, 2, 1
+	claimed.ProcessedBytes = 10
+	claimed.Errors = []models.UploadLineError{{Line: 2, Error: "invalid JSON"}}
+	claimed.Status = models.UploadCompleted
+	require.NoError(t, repo.UpdateProgress(ctx, claimed))
+	assert.NotNil(t, claimed.CompletedAt)
+
+	got, err = repo.GetByID(ctx, upload.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.UploadCompleted, got.Status)
+	assert.Equal(t, 2, got.Accepted)
+	assert.Equal(t, []models.UploadLineError{{Line: 2, Error: "invalid JSON"}}, got.Errors)
+
+	// Finishing deletes the chunks
+	_, _, err = repo.GetChunk(ctx, upload.ID, 0)
+	assert.ErrorIs(t, err, ErrUploadChunkNotFound)
+
+	// Only open uploads are abandoned
+	open := &models.Upload{UserID: user.ID, DeviceID: "RACEBOX-001", Size: 10}
+	require.NoError(t, repo.Create(ctx, open))
+	deleted, err := repo.DeleteAbandoned(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	_, err = repo.GetByID(ctx, open.ID)
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+	_, err = repo.GetByID(ctx, upload.ID)
+	assert.NoError(t, err)
+}
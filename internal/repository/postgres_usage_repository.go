@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PostgresUsageRepository implements UsageRepository using PostgreSQL
+type PostgresUsageRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresUsageRepository creates a new PostgreSQL usage repository
+func NewPostgresUsageRepository(db *sql.DB) *PostgresUsageRepository {
+	return &PostgresUsageRepository{db: db}
+}
+
+// GetPlan returns the user's plan, or models.PlanFree if none was assigned
+func (r *PostgresUsageRepository) GetPlan(ctx context.Context, userID uuid.UUID) (models.Plan, error) {
+	query := `SELECT plan FROM user_plans WHERE user_id = $1`
+
+	var plan models.Plan
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&plan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PlanFree, nil
+		}
+		return "", fmt.Errorf("failed to get user plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// SetPlan assigns a plan to the user
+func (r *PostgresUsageRepository) SetPlan(ctx context.Context, userID uuid.UUID, plan models.Plan) error {
+	query := `
+		INSERT INTO user_plans (user_id, plan, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET plan = EXCLUDED.plan, updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, plan); err != nil {
+		return fmt.Errorf("failed to set user plan: %w", err)
+	}
+
+	return nil
+}
+
+// GetTelemetryPoints returns the telemetry points counted for the period starting at periodStart
+func (r *PostgresUsageRepository) GetTelemetryPoints(ctx context.Context, userID uuid.UUID, periodStart time.Time) (int64, error) {
+	query := `SELECT telemetry_points FROM usage_counters WHERE user_id = $1 AND period_start = $2`
+
+	var points int64
+	err := r.db.QueryRowContext(ctx, query, userID, periodStart).Scan(&points)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get usage counter: %w", err)
+	}
+
+	return points, nil
+}
+
+// AddTelemetryPoints adds to the telemetry points counted for the period starting at periodStart
+func (r *PostgresUsageRepository) AddTelemetryPoints(ctx context.Context, userID uuid.UUID, periodStart time.Time, points int64) error {
+	query := `
+		INSERT INTO usage_counters (user_id, period_start, telemetry_points, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, period_start) DO UPDATE
+		SET telemetry_points = usage_counters.telemetry_points + EXCLUDED.telemetry_points,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, periodStart, points); err != nil {
+		return fmt.Errorf("failed to update usage counter: %w", err)
+	}
+
+	return nil
+}
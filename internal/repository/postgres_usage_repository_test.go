@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresUsageRepository_Plan(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresUsageRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "plan@example.com")
+
+	plan, err := repo.GetPlan(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PlanFree, plan, "users without a plan are on the free plan")
+
+	require.NoError(t, repo.SetPlan(ctx, user.ID, models.PlanPro))
+	plan, err = repo.GetPlan(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PlanPro, plan)
+
+	require.NoError(t, repo.SetPlan(ctx, user.ID, models.PlanFree))
+	plan, err = repo.GetPlan(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PlanFree, plan)
+}
+
+func TestPostgresUsageRepository_TelemetryPoints(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresUsageRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "usage@example.com")
+
+	march, _ := models.UsagePeriod(time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC))
+	april, _ := models.UsagePeriod(time.Date(2024, 4, 2, 8, 0, 0, 0, time.UTC))
+
+	points, err := repo.GetTelemetryPoints(ctx, user.ID, march)
+	require.NoError(t, err)
+	assert.Zero(t, points)
+
+	require.NoError(t, repo.AddTelemetryPoints(ctx, user.ID, march, 100))
+	require.NoError(t, repo.AddTelemetryPoints(ctx, user.ID, march, 25))
+	require.NoError(t, repo.AddTelemetryPoints(ctx, user.ID, april, 7))
+
+	points, err = repo.GetTelemetryPoints(ctx, user.ID, march)
+	require.NoError(t, err)
+	assert.Equal(t, int64(125), points)
+
+	points, err = repo.GetTelemetryPoints(ctx, user.ID, april)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), points, "each month is counted separately")
+}
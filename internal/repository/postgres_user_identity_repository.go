@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PostgresUserIdentityRepository implements UserIdentityRepository using PostgreSQL
+type PostgresUserIdentityRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresUserIdentityRepository creates a new PostgreSQL user identity repository
+func NewPostgresUserIdentityRepository(db *sql.DB) *PostgresUserIdentityRepository {
+	return &PostgresUserIdentityRepository{db: db}
+}
+
+// Create links an identity provider account to a user
+func (r *PostgresUserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	if identity.ID == uuid.Nil {
+		identity.ID = uuid.New()
+	}
+	if identity.CreatedAt.IsZero() {
+		identity.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_identities (id, user_id, issuer, subject, email, created_at, last_login_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`, identity.ID, identity.UserID, identity.Issuer, identity.Subject, identity.Email, identity.CreatedAt, identity.LastLoginAt)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrUserIdentityExists
+		}
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+
+	return nil
+}
+
+// GetBySubject retrieves the link of the issuer's subject
+func (r *PostgresUserIdentityRepository) GetBySubject(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, issuer, subject, COALESCE(email, ''), created_at, last_login_at
+		FROM user_identities
+		WHERE issuer = $1 AND subject = $2
+	`, issuer, subject).Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+		&identity.LastLoginAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserIdentityNotFound
+		}
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	return &identity, nil
+}
+
+// RecordLogin stores the email the provider reported and the time of the sign-in
+func (r *PostgresUserIdentityRepository) RecordLogin(ctx context.Context, id uuid.UUID, email string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_identities
+		SET email = COALESCE(NULLIF($2, ''), email), last_login_at = NOW()
+		WHERE id = $1
+	`, id, email)
+	if err != nil {
+		return fmt.Errorf("failed to record user identity login: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrUserIdentityNotFound
+	}
+
+	return nil
+}
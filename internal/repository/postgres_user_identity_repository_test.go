@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresUserIdentityRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresUserIdentityRepository(db.DB)
+	ctx := context.Background()
+	user := createTestUser(t, db, "sso@example.com")
+
+	_, err := repo.GetBySubject(ctx, "https://idp.example.com", "00u1")
+	assert.ErrorIs(t, err, ErrUserIdentityNotFound)
+
+	identity := &models.UserIdentity{UserID: user.ID, Issuer: "https://idp.example.com", Subject: "00u1", Email: "sso@example.com"}
+	require.NoError(t, repo.Create(ctx, identity))
+	assert.NotEqual(t, uuid.Nil, identity.ID)
+
+	err = repo.Create(ctx, &models.UserIdentity{UserID: user.ID, Issuer: "https://idp.example.com", Subject: "00u1"})
+	assert.ErrorIs(t, err, ErrUserIdentityExists)
+
+	// The same subject at another provider is a different account
+	require.NoError(t, repo.Create(ctx, &models.UserIdentity{UserID: user.ID, Issuer: "https://other.example.com", Subject: "00u1"}))
+
+	require.NoError(t, repo.RecordLogin(ctx, identity.ID, "renamed@example.com"))
+	found, err := repo.GetBySubject(ctx, "https://idp.example.com", "00u1")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.UserID)
+	assert.Equal(t, "renamed@example.com", found.Email)
+	assert.NotNil(t, found.LastLoginAt)
+
+	assert.ErrorIs(t, repo.RecordLogin(ctx, uuid.New(), ""), ErrUserIdentityNotFound)
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,13 @@ var (
 	ErrUserExists = errors.New("user with this email already exists")
 )
 
+// userColumns is the column list used by all user SELECT queries
+const userColumns = `
+	id, email, password_hash, email_verified,
+	verification_token, verification_token_expires_at,
+	reset_token, reset_token_expires_at,
+	created_at, updated_at, last_login_at, is_active, role`
+
 // PostgresUserRepository implements UserRepository using PostgreSQL
 type PostgresUserRepository struct {
 	db *database.DB
@@ -36,9 +44,9 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User)
 			id, email, password_hash, email_verified,
 			verification_token, verification_token_expires_at,
 			reset_token, reset_token_expires_at,
-			created_at, updated_at, last_login_at, is_active
+			created_at, updated_at, last_login_at, is_active, role
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 		)
 	`
 
@@ -55,12 +63,15 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User)
 	if user.UpdatedAt.IsZero() {
 		user.UpdatedAt = now
 	}
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
 
 	_, err := r.db.ExecContext(ctx, query,
 		user.ID, user.Email, user.PasswordHash, user.EmailVerified,
 		user.VerificationToken, user.VerificationTokenExpiresAt,
 		user.ResetToken, user.ResetTokenExpiresAt,
-		user.CreatedAt, user.UpdatedAt, user.LastLoginAt, user.IsActive,
+		user.CreatedAt, user.UpdatedAt, user.LastLoginAt, user.IsActive, user.Role,
 	)
 
 	if err != nil {
@@ -77,26 +88,12 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User)
 // GetByID retrieves a user by their ID
 func (r *PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	query := `
-		SELECT 
-			id, email, password_hash, email_verified,
-			verification_token, verification_token_expires_at,
-			reset_token, reset_token_expires_at,
-			created_at, updated_at, last_login_at, is_active
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
 
-	user := &models.User{}
-	var verificationToken, resetToken sql.NullString
-	var verificationTokenExpiresAt, resetTokenExpiresAt, lastLoginAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.EmailVerified,
-		&verificationToken, &verificationTokenExpiresAt,
-		&resetToken, &resetTokenExpiresAt,
-		&user.CreatedAt, &user.UpdatedAt, &lastLoginAt, &user.IsActive,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
@@ -104,49 +101,18 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
 	}
 
-	// Handle nullable fields
-	if verificationToken.Valid {
-		user.VerificationToken = &verificationToken.String
-	}
-	if verificationTokenExpiresAt.Valid {
-		user.VerificationTokenExpiresAt = &verificationTokenExpiresAt.Time
-	}
-	if resetToken.Valid {
-		user.ResetToken = &resetToken.String
-	}
-	if resetTokenExpiresAt.Valid {
-		user.ResetTokenExpiresAt = &resetTokenExpiresAt.Time
-	}
-	if lastLoginAt.Valid {
-		user.LastLoginAt = &lastLoginAt.Time
-	}
-
 	return user, nil
 }
 
 // GetByEmail retrieves a user by their email address
 func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT 
-			id, email, password_hash, email_verified,
-			verification_token, verification_token_expires_at,
-			reset_token, reset_token_expires_at,
-			created_at, updated_at, last_login_at, is_active
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1
 	`
 
-	user := &models.User{}
-	var verificationToken, resetToken sql.NullString
-	var verificationTokenExpiresAt, resetTokenExpiresAt, lastLoginAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.EmailVerified,
-		&verificationToken, &verificationTokenExpiresAt,
-		&resetToken, &resetTokenExpiresAt,
-		&user.CreatedAt, &user.UpdatedAt, &lastLoginAt, &user.IsActive,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
@@ -154,23 +120,6 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
-	// Handle nullable fields
-	if verificationToken.Valid {
-		user.VerificationToken = &verificationToken.String
-	}
-	if verificationTokenExpiresAt.Valid {
-		user.VerificationTokenExpiresAt = &verificationTokenExpiresAt.Time
-	}
-	if resetToken.Valid {
-		user.ResetToken = &resetToken.String
-	}
-	if resetTokenExpiresAt.Valid {
-		user.ResetTokenExpiresAt = &resetTokenExpiresAt.Time
-	}
-	if lastLoginAt.Valid {
-		user.LastLoginAt = &lastLoginAt.Time
-	}
-
 	return user, nil
 }
 
@@ -188,7 +137,8 @@ func (r *PostgresUserRepository) Update(ctx context.Context, user *models.User)
 			reset_token_expires_at = $8,
 			updated_at = $9,
 			last_login_at = $10,
-			is_active = $11
+			is_active = $11,
+			role = $12
 		WHERE id = $1
 	`
 
@@ -198,7 +148,7 @@ func (r *PostgresUserRepository) Update(ctx context.Context, user *models.User)
 		user.ID, user.Email, user.PasswordHash, user.EmailVerified,
 		user.VerificationToken, user.VerificationTokenExpiresAt,
 		user.ResetToken, user.ResetTokenExpiresAt,
-		user.UpdatedAt, user.LastLoginAt, user.IsActive,
+		user.UpdatedAt, user.LastLoginAt, user.IsActive, user.Role,
 	)
 
 	if err != nil {
@@ -333,26 +283,12 @@ func (r *PostgresUserRepository) SetResetToken(ctx context.Context, id uuid.UUID
 // GetByResetToken retrieves a user by their password reset token
 func (r *PostgresUserRepository) GetByResetToken(ctx context.Context, token string) (*models.User, error) {
 	query := `
-		SELECT
-			id, email, password_hash, email_verified,
-			verification_token, verification_token_expires_at,
-			reset_token, reset_token_expires_at,
-			created_at, updated_at, last_login_at, is_active
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE reset_token = $1
 	`
 
-	user := &models.User{}
-	var verificationToken, resetToken sql.NullString
-	var verificationTokenExpiresAt, resetTokenExpiresAt, lastLoginAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, token).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.EmailVerified,
-		&verificationToken, &verificationTokenExpiresAt,
-		&resetToken, &resetTokenExpiresAt,
-		&user.CreatedAt, &user.UpdatedAt, &lastLoginAt, &user.IsActive,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, token))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
@@ -360,23 +296,6 @@ func (r *PostgresUserRepository) GetByResetToken(ctx context.Context, token stri
 		return nil, fmt.Errorf("failed to get user by reset token: %w", err)
 	}
 
-	// Handle nullable fields
-	if verificationToken.Valid {
-		user.VerificationToken = &verificationToken.String
-	}
-	if verificationTokenExpiresAt.Valid {
-		user.VerificationTokenExpiresAt = &verificationTokenExpiresAt.Time
-	}
-	if resetToken.Valid {
-		user.ResetToken = &resetToken.String
-	}
-	if resetTokenExpiresAt.Valid {
-		user.ResetTokenExpiresAt = &resetTokenExpiresAt.Time
-	}
-	if lastLoginAt.Valid {
-		user.LastLoginAt = &lastLoginAt.Time
-	}
-
 	return user, nil
 }
 
@@ -408,6 +327,57 @@ func (r *PostgresUserRepository) ClearResetToken(ctx context.Context, id uuid.UU
 	return nil
 }
 
+// SetMagicLinkToken sets the passwordless sign-in token and expiry
+func (r *PostgresUserRepository) SetMagicLinkToken(ctx context.Context, id uuid.UUID, token string, expiresAt time.Time) error {
+	query := `
+		UPDATE users
+		SET
+			magic_link_token = $2,
+			magic_link_expires_at = $3,
+			updated_at = $4
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, token, expiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set magic link token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ConsumeMagicLinkToken clears an unexpired sign-in token and returns its user
+// Clearing and reading in one statement ensures concurrent requests cannot both use the token.
+func (r *PostgresUserRepository) ConsumeMagicLinkToken(ctx context.Context, token string) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET
+			magic_link_token = NULL,
+			magic_link_expires_at = NULL,
+			updated_at = $2
+		WHERE magic_link_token = $1 AND magic_link_expires_at > $2
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, token, time.Now()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to consume magic link token: %w", err)
+	}
+
+	return user, nil
+}
+
 // UpdateLastLogin updates the user's last login timestamp
 func (r *PostgresUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -433,3 +403,186 @@ func (r *PostgresUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UU
 
 	return nil
 }
+
+// List retrieves users matching the given filter, ordered by creation time (newest first)
+func (r *PostgresUserRepository) List(ctx context.Context, filter UserFilter) ([]*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE 1=1`
+	args := []interface{}{}
+
+	if filter.Search != "" {
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+		query += fmt.Sprintf(" AND email ILIKE $%d", len(args))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		query += fmt.Sprintf(" AND role = $%d", len(args))
+	}
+	if filter.IsActive != nil {
+		args = append(args, *filter.IsActive)
+		query += fmt.Sprintf(" AND is_active = $%d", len(args))
+	}
+
+	if after := userKeyset.Apply(filter.After, postgresBinder(&args)); after != "" {
+		query += " AND " + after
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", userKeyset.OrderBy(), len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetActive activates or deactivates a user account
+func (r *PostgresUserRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	query := `
+		UPDATE users
+		SET is_active = $2, updated_at = $3
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, active, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set user active status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// GetUnitsPreference retrieves the units preference from the user's profile
+func (r *PostgresUserRepository) GetUnitsPreference(ctx context.Context, id uuid.UUID) (string, error) {
+	var preference sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT units_preference FROM user_profiles WHERE user_id = $1`, id).Scan(&preference)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get units preference: %w", err)
+	}
+
+	return preference.String, nil
+}
+
+// GetPrivacyTrimMeters retrieves the privacy trim from the user's profile
+func (r *PostgresUserRepository) GetPrivacyTrimMeters(ctx context.Context, id uuid.UUID) (int, error) {
+	var meters int
+	err := r.db.QueryRowContext(ctx, `SELECT privacy_trim_meters FROM user_profiles WHERE user_id = $1`, id).Scan(&meters)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get privacy trim: %w", err)
+	}
+
+	return meters, nil
+}
+
+// SetPrivacyTrimMeters sets the privacy trim, creating the profile if needed
+func (r *PostgresUserRepository) SetPrivacyTrimMeters(ctx context.Context, id uuid.UUID, meters int) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, privacy_trim_meters)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET privacy_trim_meters = EXCLUDED.privacy_trim_meters
+	`, id, meters)
+	if err != nil {
+		return fmt.Errorf("failed to set privacy trim: %w", err)
+	}
+
+	return nil
+}
+
+// GetTimezone retrieves the time zone from the user's profile
+func (r *PostgresUserRepository) GetTimezone(ctx context.Context, id uuid.UUID) (string, error) {
+	var timezone sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT timezone FROM user_profiles WHERE user_id = $1`, id).Scan(&timezone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get timezone: %w", err)
+	}
+
+	return timezone.String, nil
+}
+
+// SetTimezone sets the time zone, creating the profile if needed
+func (r *PostgresUserRepository) SetTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, timezone)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET timezone = EXCLUDED.timezone
+	`, id, timezone)
+	if err != nil {
+		return fmt.Errorf("failed to set timezone: %w", err)
+	}
+
+	return nil
+}
+
+// scanUser scans a single user row selected with userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	var verificationToken, resetToken sql.NullString
+	var verificationTokenExpiresAt, resetTokenExpiresAt, lastLoginAt sql.NullTime
+
+	err := row.Scan(
+		&user.ID, &user.Email, &user.PasswordHash, &user.EmailVerified,
+		&verificationToken, &verificationTokenExpiresAt,
+		&resetToken, &resetTokenExpiresAt,
+		&user.CreatedAt, &user.UpdatedAt, &lastLoginAt, &user.IsActive, &user.Role,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle nullable fields
+	if verificationToken.Valid {
+		user.VerificationToken = &verificationToken.String
+	}
+	if verificationTokenExpiresAt.Valid {
+		user.VerificationTokenExpiresAt = &verificationTokenExpiresAt.Time
+	}
+	if resetToken.Valid {
+		user.ResetToken = &resetToken.String
+	}
+	if resetTokenExpiresAt.Valid {
+		user.ResetTokenExpiresAt = &resetTokenExpiresAt.Time
+	}
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
+
+	return user, nil
+}
+
+// escapeLike escapes LIKE/ILIKE wildcard characters so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -465,3 +465,93 @@ func TestPostgresUserRepository_ClearResetToken_NotFound(t *testing.T) {
 	err := repo.ClearResetToken(ctx, nonExistentID)
 	assert.ErrorIs(t, err, ErrUserNotFound)
 }
+
+func TestPostgresUserRepository_Create_DefaultsRole(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, db, "role@example.com")
+	assert.Equal(t, models.RoleUser, user.Role)
+
+	user.Role = models.RoleAdmin
+	require.NoError(t, repo.Update(ctx, user))
+
+	retrieved, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, retrieved.IsAdmin())
+}
+
+func TestPostgresUserRepository_List(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	createTestUser(t, db, "alice@example.com")
+	createTestUser(t, db, "bob@example.com")
+	inactive := createTestUser(t, db, "alice_old@example.org")
+	require.NoError(t, repo.SetActive(ctx, inactive.ID, false))
+
+	users, err := repo.List(ctx, UserFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, users, 3)
+
+	users, err = repo.List(ctx, UserFilter{Search: "ALICE", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	// Wildcards in the search term match literally
+	users, err = repo.List(ctx, UserFilter{Search: "alice_", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, inactive.ID, users[0].ID)
+
+	active := true
+	users, err = repo.List(ctx, UserFilter{Search: "alice", IsActive: &active, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "alice@example.com", users[0].Email)
+
+	users, err = repo.List(ctx, UserFilter{Role: models.RoleAdmin, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	users, err = repo.List(ctx, UserFilter{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+}
+
+func TestPostgresUserRepository_SetActive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, db, "deactivate@example.com")
+
+	require.NoError(t, repo.SetActive(ctx, user.ID, false))
+
+	retrieved, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, retrieved.IsActive)
+
+	err = repo.SetActive(ctx, uuid.New(), false)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
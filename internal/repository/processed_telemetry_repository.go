@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ProcessedTelemetryRepository defines the interface for smoothed session telemetry
+// Processed telemetry is read through TelemetryRepository queries with TelemetryFilter.Processed.
+type ProcessedTelemetryRepository interface {
+	// ReplaceSession stores a session's processed telemetry in place of any earlier result and
+	// records when the session was processed, in one transaction
+	ReplaceSession(ctx context.Context, sessionID uuid.UUID, points []*models.ProcessedTelemetry) error
+
+	// ListPending returns IDs of ended sessions not processed since they ended, oldest first
+	ListPending(ctx context.Context, limit int) ([]uuid.UUID, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// PushTokenRepository defines the interface for push token data access
+type PushTokenRepository interface {
+	// Register stores a push token, or moves an already registered token to the token's user
+	// The token's ID and CreatedAt are set from the stored row.
+	Register(ctx context.Context, token *models.PushToken) error
+
+	// ListByUserID retrieves all push tokens of a user, most recently registered first
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.PushToken, error)
+
+	// Delete removes one of the user's push tokens
+	// Returns ErrPushTokenNotFound if the user has no such token.
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+
+	// DeleteByToken removes a token the push provider reported as no longer valid
+	DeleteByToken(ctx context.Context, token string) error
+}
@@ -24,6 +24,16 @@ type RefreshTokenRepository interface {
 	// RevokeAllForUser revokes all active refresh tokens for a specific user
 	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
 
+	// ListActiveSessions retrieves the user's signed-in clients, most recently used first
+	ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*models.LoginSession, error)
+
+	// ListCountries retrieves the distinct countries the user's stored refresh tokens were issued in
+	ListCountries(ctx context.Context, userID uuid.UUID) ([]string, error)
+
+	// RevokeForUser revokes an active refresh token owned by the user
+	// Returns ErrRefreshTokenNotFound if the token does not exist, belongs to someone else or is no longer active.
+	RevokeForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+
 	// DeleteExpired removes all expired tokens and returns the count
 	DeleteExpired(ctx context.Context) (int64, error)
 }
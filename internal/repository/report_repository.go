@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// ReportRepository defines the interface for fleet report data access
+type ReportRepository interface {
+	// Create stores a new report
+	Create(ctx context.Context, report *models.Report) error
+
+	// GetByID retrieves a report by its UUID, without its file
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Report, error)
+
+	// GetContent retrieves the file of a completed report
+	GetContent(ctx context.Context, id uuid.UUID) ([]byte, error)
+
+	// UpdateStatus records the report's status
+	// Reports moving to a terminal status also get their completion time set.
+	UpdateStatus(ctx context.Context, id uuid.UUID, status models.ReportStatus, errMsg *string) error
+
+	// Complete stores the report's file and marks it completed
+	Complete(ctx context.Context, id uuid.UUID, rows int, content []byte) error
+
+	// FailIncomplete marks every pending or processing report as failed, returning how many were affected
+	FailIncomplete(ctx context.Context, errMsg string) (int64, error)
+
+	// DeleteExpired deletes reports that expired before the given time, returning how many were deleted
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+
+	// Summarize aggregates the sessions started from from up to to, exclusive, per device and week
+	// A nil orgID covers every device; otherwise only sessions shared with the organization or
+	// recorded by its devices are included. Rows are ordered by device and week.
+	Summarize(ctx context.Context, orgID *uuid.UUID, from, to time.Time) ([]*models.ReportRow, error)
+}
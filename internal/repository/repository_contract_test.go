@@ -0,0 +1,613 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backendRepositories are the repositories every database backend implements
+type backendRepositories struct {
+	telemetry     TelemetryRepository
+	users         UserRepository
+	refreshTokens RefreshTokenRepository
+	devices       DeviceRepository
+}
+
+// forEachBackend runs a test suite against a fresh database of each backend
+// PostgreSQL needs Docker and is skipped in short mode; SQLite always runs.
+func forEachBackend(t *testing.T, suite func(t *testing.T, repos backendRepositories)) {
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := database.NewSQLite(&config.DatabaseConfig{
+			Driver:             "sqlite",
+			SQLitePath:         filepath.Join(t.TempDir(), "avt.db"),
+			MaxConnections:     4,
+			MaxIdleConnections: 4,
+		})
+		require.NoError(t, err)
+		defer db.Close()
+
+		suite(t, backendRepositories{
+			telemetry:     NewSQLiteRepository(db.DB),
+			users:         NewSQLiteUserRepository(db.DB),
+			refreshTokens: NewSQLiteRefreshTokenRepository(db.DB),
+			devices:       NewSQLiteDeviceRepository(db.DB),
+		})
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("Skipping integration test in short mode")
+		}
+
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		suite(t, backendRepositories{
+			telemetry:     NewPostgresRepository(db),
+			users:         NewPostgresUserRepository(db),
+			refreshTokens: NewPostgresRefreshTokenRepository(db.DB),
+			devices:       NewPostgresDeviceRepository(db.DB),
+		})
+	})
+}
+
+// createContractUser stores an active user with the given email
+func createContractUser(t *testing.T, repos backendRepositories, email string) *models.User {
+	t.Helper()
+
+	user := &models.User{Email: email, PasswordHash: "hash", IsActive: true}
+	require.NoError(t, repos.users.Create(context.Background(), user))
+	return user
+}
+
+func TestUserRepositoryContract(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos backendRepositories) {
+		ctx := context.Background()
+
+		user := createContractUser(t, repos, "driver@example.com")
+		assert.NotEqual(t, uuid.Nil, user.ID)
+		assert.Equal(t, models.RoleUser, user.Role)
+
+		err := repos.users.Create(ctx, &models.User{Email: "driver@example.com", PasswordHash: "hash"})
+		assert.ErrorIs(t, err, ErrUserExists)
+
+		byEmail, err := repos.users.GetByEmail(ctx, "driver@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, byEmail.ID)
+		assert.True(t, byEmail.IsActive)
+		assert.Nil(t, byEmail.LastLoginAt)
+
+		_, err = repos.users.GetByID(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		// Reset tokens round-trip with their expiry
+		expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+		require.NoError(t, repos.users.SetResetToken(ctx, user.ID, "reset-token", &expiresAt))
+		byToken, err := repos.users.GetByResetToken(ctx, "reset-token")
+		require.NoError(t, err)
+		require.NotNil(t, byToken.ResetTokenExpiresAt)
+		assert.True(t, expiresAt.Equal(*byToken.ResetTokenExpiresAt))
+
+		require.NoError(t, repos.users.ClearResetToken(ctx, user.ID))
+		_, err = repos.users.GetByResetToken(ctx, "reset-token")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		// Magic link tokens can be used once and only before they expire
+		require.NoError(t, repos.users.SetMagicLinkToken(ctx, user.ID, "magic-token", time.Now().Add(time.Hour)))
+		consumed, err := repos.users.ConsumeMagicLinkToken(ctx, "magic-token")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, consumed.ID)
+		_, err = repos.users.ConsumeMagicLinkToken(ctx, "magic-token")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		require.NoError(t, repos.users.SetMagicLinkToken(ctx, user.ID, "expired-token", time.Now().Add(-time.Minute)))
+		_, err = repos.users.ConsumeMagicLinkToken(ctx, "expired-token")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		require.NoError(t, repos.users.UpdateLastLogin(ctx, user.ID))
+		require.NoError(t, repos.users.UpdateEmailVerification(ctx, user.ID, true))
+		verified, err := repos.users.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.True(t, verified.EmailVerified)
+		assert.NotNil(t, verified.LastLoginAt)
+
+		assert.ErrorIs(t, repos.users.UpdatePassword(ctx, uuid.New(), "hash"), ErrUserNotFound)
+
+		// Search is case-insensitive and treats wildcards literally
+		createContractUser(t, repos, "Other_Driver@Example.com")
+		require.NoError(t, repos.users.SetActive(ctx, user.ID, false))
+
+		users, err := repos.users.List(ctx, UserFilter{Search: "other_", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, "Other_Driver@Example.com", users[0].Email)
+
+		inactive := false
+		users, err = repos.users.List(ctx, UserFilter{IsActive: &inactive, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, user.ID, users[0].ID)
+
+		// Cursors page newest first
+		users, err = repos.users.List(ctx, UserFilter{Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		first := users[0]
+		users, err = repos.users.List(ctx, UserFilter{Limit: 10, After: &pagination.Cursor{Time: first.CreatedAt, ID: first.ID.String()}})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.NotEqual(t, first.ID, users[0].ID)
+
+		preference, err := repos.users.GetUnitsPreference(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, preference)
+
+		// The privacy trim defaults to zero and creates the profile when set
+		trim, err := repos.users.GetPrivacyTrimMeters(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Zero(t, trim)
+		require.NoError(t, repos.users.SetPrivacyTrimMeters(ctx, user.ID, 250))
+		trim, err = repos.users.GetPrivacyTrimMeters(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 250, trim)
+
+		// Profiles default to UTC
+		timezone, err := repos.users.GetTimezone(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "UTC", timezone)
+		require.NoError(t, repos.users.SetTimezone(ctx, user.ID, "Europe/Madrid"))
+		timezone, err = repos.users.GetTimezone(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Europe/Madrid", timezone)
+		trim, err = repos.users.GetPrivacyTrimMeters(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 250, trim, "setting the time zone keeps the rest of the profile")
+	})
+}
+
+func TestRefreshTokenRepositoryContract(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos backendRepositories) {
+		ctx := context.Background()
+		user := createContractUser(t, repos, "tokens@example.com")
+
+		login := &models.RefreshToken{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			TokenHash: "login-hash",
+			ExpiresAt: time.Now().Add(24 * time.Hour),
+			CreatedAt: time.Now().Add(-time.Hour),
+			UserAgent: "Mozilla/5.0",
+			IPAddress: "192.168.1.1",
+			Location:  &models.GeoLocation{CountryCode: "BG", Country: "Bulgaria", City: "Sofia"},
+		}
+		require.NoError(t, repos.refreshTokens.Create(ctx, login))
+
+		// Rotating the token keeps the session's sign-in time
+		rotated := &models.RefreshToken{
+			ID:               uuid.New(),
+			UserID:           user.ID,
+			TokenHash:        "rotated-hash",
+			ExpiresAt:        time.Now().Add(24 * time.Hour),
+			CreatedAt:        time.Now(),
+			SessionStartedAt: login.CreatedAt,
+			RememberMe:       true,
+		}
+		require.NoError(t, repos.refreshTokens.Create(ctx, rotated))
+		require.NoError(t, repos.refreshTokens.Revoke(ctx, login.ID))
+
+		_, err := repos.refreshTokens.GetByHash(ctx, "login-hash")
+		assert.ErrorIs(t, err, ErrRefreshTokenRevoked)
+
+		retrieved, err := repos.refreshTokens.GetByHash(ctx, "rotated-hash")
+		require.NoError(t, err)
+		assert.Equal(t, rotated.ID, retrieved.ID)
+		assert.True(t, retrieved.RememberMe)
+		assert.WithinDuration(t, login.CreatedAt, retrieved.SessionStartedAt, time.Millisecond)
+
+		sessions, err := repos.refreshTokens.ListActiveSessions(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, rotated.ID, sessions[0].ID)
+
+		countries, err := repos.refreshTokens.ListCountries(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"BG"}, countries)
+
+		assert.ErrorIs(t, repos.refreshTokens.RevokeForUser(ctx, rotated.ID, uuid.New()), ErrRefreshTokenNotFound)
+		require.NoError(t, repos.refreshTokens.RevokeAllForUser(ctx, user.ID))
+		_, err = repos.refreshTokens.GetByHash(ctx, "rotated-hash")
+		assert.ErrorIs(t, err, ErrRefreshTokenRevoked)
+
+		expired := &models.RefreshToken{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			TokenHash: "expired-hash",
+			ExpiresAt: time.Now().Add(-time.Hour),
+			CreatedAt: time.Now().Add(-2 * time.Hour),
+		}
+		require.NoError(t, repos.refreshTokens.Create(ctx, expired))
+		_, err = repos.refreshTokens.GetByHash(ctx, "expired-hash")
+		assert.ErrorIs(t, err, ErrRefreshTokenNotFound)
+
+		deleted, err := repos.refreshTokens.DeleteExpired(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+}
+
+func TestDeviceRepositoryContract(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos backendRepositories) {
+		ctx := context.Background()
+		owner := createContractUser(t, repos, "owner@example.com")
+		other := createContractUser(t, repos, "other@example.com")
+
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		name := "Track car"
+		device := &models.Device{
+			ID:         uuid.New(),
+			DeviceID:   "RACEBOX-001",
+			UserID:     owner.ID,
+			DeviceName: &name,
+			ClaimedAt:  now,
+			IsActive:   true,
+			Metadata:   map[string]interface{}{"color": "red"},
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		require.NoError(t, repos.devices.Create(ctx, device))
+
+		duplicate := *device
+		duplicate.ID = uuid.New()
+		assert.ErrorIs(t, repos.devices.Create(ctx, &duplicate), ErrDeviceExists)
+
+		retrieved, err := repos.devices.GetByDeviceID(ctx, "RACEBOX-001")
+		require.NoError(t, err)
+		assert.Equal(t, device.ID, retrieved.ID)
+		assert.Equal(t, "Track car", *retrieved.DeviceName)
+		assert.Equal(t, "red", retrieved.Metadata["color"])
+		assert.True(t, now.Equal(retrieved.ClaimedAt))
+		assert.Nil(t, retrieved.LastSeenAt)
+
+		_, err = repos.devices.GetByID(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrDeviceNotFound)
+
+		// Claiming a taken device returns the stored one
+		claim := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: other.ID, ClaimedAt: now, IsActive: true, CreatedAt: now, UpdatedAt: now}
+		claimed, err := repos.devices.Claim(ctx, claim)
+		assert.ErrorIs(t, err, ErrDeviceClaimed)
+		require.NotNil(t, claimed)
+		assert.Equal(t, device.ID, claimed.ID)
+
+		second := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-002", UserID: owner.ID, ClaimedAt: now.Add(time.Minute), IsActive: true, CreatedAt: now, UpdatedAt: now}
+		claimed, err = repos.devices.Claim(ctx, second)
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, claimed.ID)
+
+		// Signed upload enforcement round-trips through Update
+		assert.False(t, claimed.RequireSignedUploads)
+		second.RequireSignedUploads = true
+		require.NoError(t, repos.devices.Update(ctx, second))
+		enforced, err := repos.devices.GetByID(ctx, second.ID)
+		require.NoError(t, err)
+		assert.True(t, enforced.RequireSignedUploads)
+
+		require.NoError(t, repos.devices.UpdateLastSeen(ctx, "RACEBOX-001"))
+		assert.ErrorIs(t, repos.devices.UpdateLastSeen(ctx, "UNKNOWN"), ErrDeviceNotFound)
+		require.NoError(t, repos.devices.UpdateFirmware(ctx, device.ID, "1.2.0"))
+
+		online := true
+		devices, total, err := repos.devices.List(ctx, DeviceFilter{UserID: owner.ID, Online: &online, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, devices, 1)
+		assert.Equal(t, device.ID, devices[0].ID)
+		assert.Equal(t, "1.2.0", *devices[0].FirmwareVersion)
+
+		// Devices that were never seen sort last
+		devices, total, err = repos.devices.List(ctx, DeviceFilter{UserID: owner.ID, Sort: DeviceSortLastSeenAt, Ascending: true, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, devices, 2)
+		assert.Equal(t, []uuid.UUID{device.ID, second.ID}, []uuid.UUID{devices[0].ID, devices[1].ID})
+
+		// Claim time cursors resume after the given device; the total ignores them
+		devices, total, err = repos.devices.List(ctx, DeviceFilter{
+			UserID: owner.ID, Sort: DeviceSortClaimedAt, Limit: 10,
+			After: &pagination.Cursor{Time: second.ClaimedAt, ID: second.ID.String()},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, devices, 1)
+		assert.Equal(t, device.ID, devices[0].ID)
+
+		seen, err := repos.devices.ListSeenSince(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Len(t, seen, 1)
+
+		distribution, err := repos.devices.FirmwareDistribution(ctx)
+		require.NoError(t, err)
+		require.Len(t, distribution, 2)
+
+		// Tags
+		require.NoError(t, repos.devices.SetTags(ctx, device.ID, []string{"rental-fleet-A", "kart"}))
+		require.NoError(t, repos.devices.AddTag(ctx, second.ID, "kart"))
+		require.NoError(t, repos.devices.AddTag(ctx, second.ID, "kart"))
+		assert.ErrorIs(t, repos.devices.SetTags(ctx, uuid.New(), []string{"kart"}), ErrDeviceNotFound)
+
+		tags, err := repos.devices.TagsByDevice(ctx, []uuid.UUID{device.ID, second.ID})
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID][]string{device.ID: {"kart", "rental-fleet-A"}, second.ID: {"kart"}}, tags)
+
+		devices, total, err = repos.devices.List(ctx, DeviceFilter{UserID: owner.ID, Tag: "rental-fleet-A", Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, devices, 1)
+		assert.Equal(t, device.ID, devices[0].ID)
+
+		counts, err := repos.devices.TagCounts(ctx, DeviceFilter{UserID: owner.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []*models.DeviceTagCount{
+			{Tag: "kart", Devices: 2, Active: 2, Online: 1},
+			{Tag: "rental-fleet-A", Devices: 1, Active: 1, Online: 1},
+		}, counts)
+
+		require.NoError(t, repos.devices.RemoveTag(ctx, device.ID, "kart"))
+		assert.ErrorIs(t, repos.devices.RemoveTag(ctx, device.ID, "kart"), ErrDeviceTagNotFound)
+
+		require.NoError(t, repos.devices.Reassign(ctx, second.ID, other.ID))
+		owned, err := repos.devices.ListByUserID(ctx, other.ID)
+		require.NoError(t, err)
+		require.Len(t, owned, 1)
+		assert.Equal(t, second.ID, owned[0].ID)
+	})
+}
+
+func TestTelemetryRepositoryContract(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos backendRepositories) {
+		ctx := context.Background()
+		user := createContractUser(t, repos, "telemetry@example.com")
+		sessionID := uuid.New().String()
+
+		start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+		var batch []*models.TelemetryData
+		for i := 0; i < 4; i++ {
+			point := createSampleTelemetry(start.Add(time.Duration(i)*15*time.Second), "RACEBOX-001")
+			point.UserID = &user.ID
+			point.SessionID = &sessionID
+			point.ITOW = int64(i)
+			point.GPS.Latitude += float64(i) * 0.001
+			point.GPS.Speed = float64(100 + i*10)
+			point.Extras = map[string]interface{}{"lap": float64(1)}
+			batch = append(batch, point)
+		}
+		batch[3].QualityFlags = models.QualityImpossibleSpeed
+		receivedAt, reported, skew := start.Add(time.Minute), start.Add(35*time.Second), int64(5000)
+		batch[2].ReceivedAt, batch[2].DeviceTimestamp, batch[2].ClockSkewMs = &receivedAt, &reported, &skew
+		batch[1].DeriveChannels(batch[0])
+		require.NoError(t, repos.telemetry.SaveBatch(ctx, batch))
+		for _, point := range batch {
+			assert.NotZero(t, point.ID)
+		}
+
+		// Previous records are the latest of the device within models.MaxDerivedGap
+		previous, err := repos.telemetry.PreviousRecords(ctx, []*models.TelemetryData{
+			createSampleTelemetry(start.Add(50*time.Second), "RACEBOX-001"),
+			createSampleTelemetry(start.Add(20*time.Second), "RACEBOX-001"),
+			createSampleTelemetry(start.Add(10*time.Minute), "RACEBOX-001"),
+			createSampleTelemetry(start.Add(50*time.Second), "RACEBOX-002"),
+		})
+		require.NoError(t, err)
+		require.Len(t, previous, 4)
+		require.NotNil(t, previous[0])
+		assert.True(t, batch[3].Timestamp.Equal(previous[0].Timestamp))
+		assert.Equal(t, batch[3].GPS.Speed, previous[0].GPS.Speed)
+		assert.InDelta(t, batch[3].GPS.Latitude, previous[0].GPS.Latitude, 1e-9)
+		require.NotNil(t, previous[1])
+		assert.True(t, batch[1].Timestamp.Equal(previous[1].Timestamp))
+		assert.Nil(t, previous[2])
+		assert.Nil(t, previous[3])
+
+		// Replaying a record with a stored clientId reports the original row
+		clientID := uuid.New()
+		original := createSampleTelemetry(start.Add(time.Hour), "RACEBOX-001")
+		original.UserID = &user.ID
+		original.ClientID = &clientID
+		require.NoError(t, repos.telemetry.Save(ctx, original))
+		replay := *original
+		replay.ID = 0
+		require.NoError(t, repos.telemetry.Save(ctx, &replay))
+		assert.True(t, replay.Duplicate)
+		assert.Equal(t, original.ID, replay.ID)
+
+		points, err := repos.telemetry.GetBySession(ctx, sessionID, 0)
+		require.NoError(t, err)
+		require.Len(t, points, 4)
+		assert.True(t, start.Equal(points[0].Timestamp))
+		assert.Equal(t, float64(1), points[0].Extras["lap"])
+		assert.Nil(t, points[0].ReceivedAt)
+		require.NotNil(t, points[2].ReceivedAt)
+		require.NotNil(t, points[2].DeviceTimestamp)
+		assert.True(t, receivedAt.Equal(*points[2].ReceivedAt))
+		assert.True(t, reported.Equal(*points[2].DeviceTimestamp))
+		assert.Equal(t, &skew, points[2].ClockSkewMs)
+		assert.Nil(t, points[0].Derived)
+		require.NotNil(t, points[1].Derived)
+		assert.Equal(t, batch[1].Derived, points[1].Derived)
+
+		// Query pages newest first with a cursor and leaves out flagged points
+		page, err := repos.telemetry.Query(ctx, TelemetryFilter{UserID: user.ID, SessionID: sessionID, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, batch[3].ID, page[0].ID)
+		page, err = repos.telemetry.Query(ctx, TelemetryFilter{
+			UserID: user.ID, SessionID: sessionID, Limit: 2,
+			After: &pagination.Cursor{Time: page[1].Timestamp, ID: strconv.FormatInt(page[1].ID, 10)},
+		})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, batch[1].ID, page[0].ID)
+
+		clean, err := repos.telemetry.Query(ctx, TelemetryFilter{UserID: user.ID, SessionID: sessionID, CleanOnly: true})
+		require.NoError(t, err)
+		assert.Len(t, clean, 3)
+
+		to := start.Add(40 * time.Second)
+		ranged, err := repos.telemetry.Query(ctx, TelemetryFilter{UserID: user.ID, From: &start, To: &to})
+		require.NoError(t, err)
+		assert.Len(t, ranged, 3)
+
+		buckets, err := repos.telemetry.Aggregate(ctx, TelemetryFilter{UserID: user.ID, SessionID: sessionID, CleanOnly: true}, Bucket1m)
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.True(t, start.Equal(buckets[0].Bucket))
+		assert.Equal(t, int64(3), buckets[0].Samples)
+		assert.InDelta(t, 110, buckets[0].AvgSpeed, 0.001)
+		assert.InDelta(t, 120, buckets[0].MaxSpeed, 0.001)
+
+		// Tag filters match the telemetry of the devices carrying the tag
+		now := time.Now().UTC()
+		device := &models.Device{ID: uuid.New(), DeviceID: "RACEBOX-001", UserID: user.ID, ClaimedAt: now, IsActive: true, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, repos.devices.Create(ctx, device))
+		require.NoError(t, repos.devices.AddTag(ctx, device.ID, "kart"))
+		tagged, err := repos.telemetry.Query(ctx, TelemetryFilter{UserID: user.ID, Tag: "kart"})
+		require.NoError(t, err)
+		assert.Len(t, tagged, 5)
+		tagged, err = repos.telemetry.Query(ctx, TelemetryFilter{UserID: user.ID, Tag: "boat"})
+		require.NoError(t, err)
+		assert.Empty(t, tagged)
+		buckets, err = repos.telemetry.Aggregate(ctx, TelemetryFilter{UserID: user.ID, Tag: "kart", To: &to}, Bucket1m)
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.Equal(t, int64(4), buckets[0].Samples)
+
+		iterator, err := repos.telemetry.IterateBySession(ctx, sessionID)
+		require.NoError(t, err)
+		var iterated []int64
+		for iterator.Next() {
+			iterated = append(iterated, iterator.Telemetry().ID)
+		}
+		require.NoError(t, iterator.Err())
+		require.NoError(t, iterator.Close())
+		assert.Len(t, iterated, 4)
+
+		// Paging resumes after the last record of each page, in the same order
+		paged, err := IterateSessionPages(ctx, repos.telemetry, sessionID, 3)
+		require.NoError(t, err)
+		var pagedIDs []int64
+		for paged.Next() {
+			pagedIDs = append(pagedIDs, paged.Telemetry().ID)
+		}
+		require.NoError(t, paged.Err())
+		require.NoError(t, paged.Close())
+		assert.Equal(t, iterated, pagedIDs)
+
+		track, err := repos.telemetry.SessionTrack(ctx, sessionID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), track.Points)
+		assert.Equal(t, 4, track.SimplifiedPoints)
+
+		// The points lie on a meridian, so simplification keeps only the ends
+		track, err = repos.telemetry.SessionTrack(ctx, sessionID, 5)
+		require.NoError(t, err)
+		assert.Equal(t, 2, track.SimplifiedPoints)
+		assert.JSONEq(t, `{"type":"LineString","coordinates":[[23.2887238,42.6719035],[23.2887238,42.6749035]]}`, string(track.Geometry))
+
+		// At zoom 10 the session's points share one cell; the flagged point is left out
+		around := models.BoundingBox{West: 23.28, South: 42.67, East: 23.30, North: 42.68}
+		cells, err := repos.telemetry.Heatmap(ctx, TelemetryFilter{UserID: user.ID, SessionID: sessionID, CleanOnly: true}, around, 10)
+		require.NoError(t, err)
+		require.Len(t, cells, 1)
+		assert.Equal(t, int64(3), cells[0].Points)
+		assert.InDelta(t, 110, cells[0].AvgSpeed, 0.001)
+		assert.InDelta(t, 120, cells[0].MaxSpeed, 0.001)
+		assert.InDelta(t, 23.2887, cells[0].Longitude, 0.02)
+		assert.InDelta(t, 42.673, cells[0].Latitude, 0.02)
+		elsewhere := models.BoundingBox{West: 5.95, South: 50.42, East: 5.99, North: 50.45}
+		cells, err = repos.telemetry.Heatmap(ctx, TelemetryFilter{UserID: user.ID}, elsewhere, 10)
+		require.NoError(t, err)
+		assert.Empty(t, cells)
+
+		// The area holds the session's last three points; the flagged one is left out and other owners see nothing
+		area, err := models.ParseGeoPolygon(`{"type":"Polygon","coordinates":[[[23.28,42.6725],[23.30,42.6725],[23.29,42.6760],[23.28,42.6725]]]}`)
+		require.NoError(t, err)
+		inside, err := repos.telemetry.Within(ctx, TelemetryFilter{UserID: user.ID, CleanOnly: true, Limit: 1}, area)
+		require.NoError(t, err)
+		require.Len(t, inside, 1)
+		assert.Equal(t, batch[2].ID, inside[0].ID)
+		inside, err = repos.telemetry.Within(ctx, TelemetryFilter{
+			UserID: user.ID, CleanOnly: true,
+			After: &pagination.Cursor{Time: inside[0].Timestamp, ID: strconv.FormatInt(inside[0].ID, 10)},
+		}, area)
+		require.NoError(t, err)
+		require.Len(t, inside, 1)
+		assert.Equal(t, batch[1].ID, inside[0].ID)
+		inside, err = repos.telemetry.Within(ctx, TelemetryFilter{UserID: uuid.New()}, area)
+		require.NoError(t, err)
+		assert.Empty(t, inside)
+
+		areaStats, err := repos.telemetry.WithinStats(ctx, TelemetryFilter{UserID: user.ID, CleanOnly: true}, area)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), areaStats.Points)
+		assert.Equal(t, int64(1), areaStats.Devices)
+		assert.Equal(t, int64(1), areaStats.Sessions)
+		require.NotNil(t, areaStats.FirstRecordedAt)
+		assert.True(t, batch[1].Timestamp.Equal(*areaStats.FirstRecordedAt))
+		assert.True(t, batch[2].Timestamp.Equal(*areaStats.LastRecordedAt))
+		assert.InDelta(t, 115, areaStats.AvgSpeed, 0.001)
+		assert.InDelta(t, 120, areaStats.MaxSpeed, 0.001)
+		areaStats, err = repos.telemetry.WithinStats(ctx, TelemetryFilter{UserID: user.ID}, models.GeoPolygon{
+			Type: "Polygon", Coordinates: [][][2]float64{{{5.95, 50.42}, {5.99, 50.42}, {5.99, 50.45}, {5.95, 50.42}}},
+		})
+		require.NoError(t, err)
+		assert.Zero(t, areaStats.Points)
+		assert.Nil(t, areaStats.FirstRecordedAt)
+
+		// The latest unflagged point is the replayed record an hour later; other owners' points are not shown
+		positions, err := repos.telemetry.LatestPositions(ctx, []DeviceOwner{
+			{DeviceID: "RACEBOX-001", UserID: user.ID},
+			{DeviceID: "RACEBOX-001", UserID: uuid.New()},
+			{DeviceID: "RACEBOX-002", UserID: user.ID},
+		})
+		require.NoError(t, err)
+		require.Len(t, positions, 1)
+		assert.Equal(t, "RACEBOX-001", positions[0].DeviceID)
+		assert.True(t, original.Timestamp.Equal(positions[0].RecordedAt))
+		assert.InDelta(t, original.GPS.Latitude, positions[0].Latitude, 1e-9)
+		assert.InDelta(t, original.GPS.Speed, positions[0].Speed, 1e-9)
+		assert.InDelta(t, original.GPS.Heading, positions[0].Heading, 1e-9)
+
+		processed, err := repos.telemetry.IsBatchProcessed(ctx, "batch-1")
+		require.NoError(t, err)
+		assert.False(t, processed)
+		require.NoError(t, repos.telemetry.MarkBatchProcessed(ctx, "batch-1", 4, "RACEBOX-001", &sessionID))
+		processed, err = repos.telemetry.IsBatchProcessed(ctx, "batch-1")
+		require.NoError(t, err)
+		assert.True(t, processed)
+
+		stats, err := repos.telemetry.IngestStats(ctx, start)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), stats.DataPoints)
+		assert.Equal(t, int64(1), stats.Batches)
+		require.NotNil(t, stats.LastRecordedAt)
+		assert.True(t, original.Timestamp.Equal(*stats.LastRecordedAt))
+
+		orphan := createSampleTelemetry(start, "RACEBOX-404")
+		require.NoError(t, repos.telemetry.Save(ctx, orphan))
+		orphans, err := repos.telemetry.OrphanedTelemetry(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, orphans, 1)
+		assert.Equal(t, "RACEBOX-404", orphans[0].DeviceID)
+		assert.True(t, start.Equal(orphans[0].FirstRecordedAt))
+	})
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+)
+
+// sessionPageIterator streams a session's telemetry one bounded page at a time
+type sessionPageIterator struct {
+	ctx       context.Context
+	repo      TelemetryRepository
+	sessionID string
+	pageSize  int
+	page      []*models.TelemetryData
+	after     *pagination.Cursor
+	last      bool // The page read last was short, so no records follow it
+	current   *models.TelemetryData
+	err       error
+}
+
+// IterateSessionPages streams a session's telemetry in chronological order, pageSize records at a time
+// Each page is read in full and its query closed before its records are returned, so callers that
+// pause between records, like paced replays, only hold a database connection while a page is read.
+// The first page is read before returning, so a failing query is reported here rather than by Err.
+func IterateSessionPages(ctx context.Context, repo TelemetryRepository, sessionID string, pageSize int) (TelemetryIterator, error) {
+	it := &sessionPageIterator{ctx: ctx, repo: repo, sessionID: sessionID, pageSize: pageSize}
+	if err := it.readPage(); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// Next implements TelemetryIterator.Next
+func (it *sessionPageIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.page) == 0 {
+		if it.last {
+			return false
+		}
+		if it.err = it.readPage(); it.err != nil || len(it.page) == 0 {
+			return false
+		}
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// readPage reads the page following the last record read
+func (it *sessionPageIterator) readPage() error {
+	rows, err := it.repo.IterateSessionPage(it.ctx, it.sessionID, it.after, it.pageSize)
+	if err != nil {
+		return err
+	}
+
+	page := make([]*models.TelemetryData, 0, it.pageSize)
+	for rows.Next() {
+		page = append(page, rows.Telemetry())
+	}
+	err = rows.Err()
+	if closeErr := rows.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close session telemetry page: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	it.page, it.last = page, len(page) < it.pageSize
+	if len(page) > 0 {
+		record := page[len(page)-1]
+		it.after = &pagination.Cursor{Time: record.Timestamp, ID: strconv.FormatInt(record.ID, 10)}
+	}
+	return nil
+}
+
+// Telemetry implements TelemetryIterator.Telemetry
+func (it *sessionPageIterator) Telemetry() *models.TelemetryData {
+	return it.current
+}
+
+// Err implements TelemetryIterator.Err
+func (it *sessionPageIterator) Err() error {
+	return it.err
+}
+
+// Close implements TelemetryIterator.Close
+// Pages are closed as soon as they are read, so there is nothing left to release.
+func (it *sessionPageIterator) Close() error {
+	return nil
+}
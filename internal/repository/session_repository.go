@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+)
+
+// sessionKeyset is the order of session listings
+var sessionKeyset = pagination.Keyset{TimeColumn: "started_at", IDColumn: "id"}
+
+// SessionFilter describes a listing of the sessions a user can access, most recent first
+type SessionFilter struct {
+	// UserID includes the user's own sessions
+	UserID uuid.UUID
+
+	// OrgIDs optionally adds sessions shared with any of these organizations
+	OrgIDs []uuid.UUID
+
+	// StartedFrom and StartedBefore optionally restrict results to sessions started at or after
+	// StartedFrom and before StartedBefore
+	StartedFrom   *time.Time
+	StartedBefore *time.Time
+
+	// Limit and Offset paginate the results
+	Limit  int
+	Offset int
+
+	// After resumes the listing after the given position (exclusive)
+	After *pagination.Cursor
+}
+
+// SessionRepository defines the interface for recording session data access
+type SessionRepository interface {
+	// Create stores a new session
+	Create(ctx context.Context, session *models.Session) error
+
+	// GetByID retrieves a session by its UUID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error)
+
+	// ListByUserID retrieves sessions owned by a user, most recent first
+	ListByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Session, error)
+
+	// ListAccessible retrieves a page of the sessions matching the filter and the total number matching it
+	ListAccessible(ctx context.Context, filter SessionFilter) ([]*models.Session, int, error)
+
+	// UpdateDetails stores a session's name, location and notes, setting its updated_at
+	UpdateDetails(ctx context.Context, session *models.Session) error
+
+	// End marks a session as ended at the given time
+	End(ctx context.Context, id uuid.UUID, endedAt time.Time) error
+
+	// UpdateSummary recomputes a session's cached aggregates from its telemetry
+	// and tags the session with the known circuit it was recorded at, if any
+	UpdateSummary(ctx context.Context, id uuid.UUID) (*models.Session, error)
+
+	// GetStats returns a session's cached telemetry statistics
+	// ErrSessionStatsNotFound is returned when they have not been computed yet.
+	GetStats(ctx context.Context, id uuid.UUID) (*models.SessionStats, error)
+
+	// UpdateStats recomputes a session's telemetry statistics and caches them on the session
+	UpdateStats(ctx context.Context, id uuid.UUID) (*models.SessionStats, error)
+
+	// ListPendingSummaries returns IDs of ended sessions whose aggregates are missing or stale
+	ListPendingSummaries(ctx context.Context, limit int) ([]uuid.UUID, error)
+
+	// AddMarker stores a new marker on a session
+	AddMarker(ctx context.Context, marker *models.SessionMarker) error
+
+	// ListMarkers retrieves a session's markers in time order
+	ListMarkers(ctx context.Context, sessionID uuid.UUID) ([]*models.SessionMarker, error)
+
+	// DeleteMarker removes a marker from a session, returning ErrSessionMarkerNotFound when the session has no such marker
+	DeleteMarker(ctx context.Context, sessionID, markerID uuid.UUID) error
+}
@@ -0,0 +1,502 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// SQLiteDeviceRepository implements DeviceRepository using SQLite
+type SQLiteDeviceRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteDeviceRepository creates a new SQLite device repository
+func NewSQLiteDeviceRepository(db *sql.DB) *SQLiteDeviceRepository {
+	return &SQLiteDeviceRepository{db: db}
+}
+
+// sqliteDeviceInsert inserts a device with the arguments returned by deviceArgs
+const sqliteDeviceInsert = `
+	INSERT INTO devices (
+		id, device_id, user_id, org_id, device_name, device_model,
+		claimed_at, last_seen_at, is_active, metadata,
+		created_at, updated_at, firmware_version, firmware_updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+// deviceArgs returns the insert arguments of a device, encoding its metadata as JSON
+func deviceArgs(device *models.Device) ([]interface{}, error) {
+	var metadataJSON []byte
+	if device.Metadata != nil {
+		var err error
+		metadataJSON, err = json.Marshal(device.Metadata)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []interface{}{
+		device.ID, device.DeviceID, device.UserID, device.OrgID, device.DeviceName, device.DeviceModel,
+		sqliteTime(device.ClaimedAt), sqliteNullTime(device.LastSeenAt), device.IsActive, metadataJSON,
+		sqliteTime(device.CreatedAt), sqliteTime(device.UpdatedAt), device.FirmwareVersion, sqliteNullTime(device.FirmwareUpdatedAt),
+	}, nil
+}
+
+// Create stores a new device
+func (r *SQLiteDeviceRepository) Create(ctx context.Context, device *models.Device) error {
+	args, err := deviceArgs(device)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqliteDeviceInsert, args...); err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrDeviceExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Claim atomically stores a new device unless its device_id is already taken
+// SQLite serializes writers, so the losers of a race to insert read the winner's row.
+func (r *SQLiteDeviceRepository) Claim(ctx context.Context, device *models.Device) (*models.Device, error) {
+	return sqliteClaimDevice(ctx, r.db, device)
+}
+
+// ClaimAll claims devices in a single transaction, like Claim does for one device
+func (r *SQLiteDeviceRepository) ClaimAll(ctx context.Context, devices []*models.Device) ([]*models.Device, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	stored := make([]*models.Device, len(devices))
+	for i, device := range devices {
+		claimed, err := sqliteClaimDevice(ctx, tx, device)
+		if err != nil && !errors.Is(err, ErrDeviceClaimed) {
+			return nil, err
+		}
+		stored[i] = claimed
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit device claims: %w", err)
+	}
+	return stored, nil
+}
+
+// sqliteClaimDevice inserts a device unless its device_id is taken, and returns the stored device
+func sqliteClaimDevice(ctx context.Context, q queryRower, device *models.Device) (*models.Device, error) {
+	args, err := deviceArgs(device)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed, err := scanDevice(q.QueryRowContext(ctx,
+		sqliteDeviceInsert+` ON CONFLICT (device_id) DO NOTHING RETURNING `+deviceColumns, args...))
+	if err == nil {
+		return claimed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	existing, err := scanDevice(q.QueryRowContext(ctx, `SELECT `+deviceColumns+` FROM devices WHERE device_id = ?`, device.DeviceID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read claimed device: %w", err)
+	}
+	if existing.UserID != device.UserID {
+		return existing, ErrDeviceClaimed
+	}
+	return existing, nil
+}
+
+// GetByID retrieves a device by its UUID
+func (r *SQLiteDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Device, error) {
+	device, err := scanDevice(r.db.QueryRowContext(ctx, `SELECT `+deviceColumns+` FROM devices WHERE id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// GetByDeviceID retrieves a device by its hardware device ID
+func (r *SQLiteDeviceRepository) GetByDeviceID(ctx context.Context, deviceID string) (*models.Device, error) {
+	device, err := scanDevice(r.db.QueryRowContext(ctx, `SELECT `+deviceColumns+` FROM devices WHERE device_id = ?`, deviceID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// ListByUserID retrieves all devices owned by a user
+func (r *SQLiteDeviceRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Device, error) {
+	return r.list(ctx, `SELECT `+deviceColumns+` FROM devices WHERE user_id = ? ORDER BY claimed_at DESC`, userID)
+}
+
+// List retrieves a page of a user's devices and the total number matching the filter
+func (r *SQLiteDeviceRepository) List(ctx context.Context, filter DeviceFilter) ([]*models.Device, int, error) {
+	where := ` WHERE user_id = ?`
+	args := []interface{}{filter.UserID}
+
+	if len(filter.OrgIDs) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(filter.OrgIDs)), ", ")
+		where = ` WHERE (user_id = ? OR org_id IN (` + placeholders + `))`
+		for _, id := range filter.OrgIDs {
+			args = append(args, id)
+		}
+	}
+
+	if filter.IsActive != nil {
+		where += " AND is_active = ?"
+		args = append(args, *filter.IsActive)
+	}
+	if filter.Online != nil {
+		if *filter.Online {
+			where += " AND last_seen_at > ?"
+		} else {
+			where += " AND (last_seen_at IS NULL OR last_seen_at <= ?)"
+		}
+		args = append(args, sqliteTime(time.Now().Add(-models.DeviceOnlineWindow)))
+	}
+
+	if filter.Tag != "" {
+		where += " AND id IN (SELECT device_id FROM device_tags WHERE tag = ?)"
+		args = append(args, filter.Tag)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM devices`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count devices: %w", err)
+	}
+
+	orderBy, after := deviceOrder(filter, sqliteBinder(&args))
+	if after != "" {
+		where += " AND " + after
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := `SELECT ` + deviceColumns + ` FROM devices` + where + ` ORDER BY ` + orderBy + ` LIMIT ? OFFSET ?`
+
+	devices, err := r.list(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list devices: %w", err)
+	}
+	if devices == nil {
+		devices = []*models.Device{}
+	}
+
+	return devices, total, nil
+}
+
+// Update updates a device's information
+func (r *SQLiteDeviceRepository) Update(ctx context.Context, device *models.Device) error {
+	var metadataJSON []byte
+	if device.Metadata != nil {
+		var err error
+		metadataJSON, err = json.Marshal(device.Metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	device.UpdatedAt = time.Now()
+
+	return r.update(ctx, `
+		UPDATE devices
+		SET device_name = ?, device_model = ?, last_seen_at = ?, is_active = ?, metadata = ?,
+			require_signed_uploads = ?, updated_at = ?
+		WHERE id = ?
+	`, device.DeviceName, device.DeviceModel, sqliteNullTime(device.LastSeenAt), device.IsActive, metadataJSON,
+		device.RequireSignedUploads, sqliteTime(device.UpdatedAt), device.ID)
+}
+
+// UpdateLastSeen updates the last_seen_at timestamp for a device
+func (r *SQLiteDeviceRepository) UpdateLastSeen(ctx context.Context, deviceID string) error {
+	return r.update(ctx, `UPDATE devices SET last_seen_at = ?1, updated_at = ?1 WHERE device_id = ?2`,
+		sqliteTime(time.Now()), deviceID)
+}
+
+// UpdateFirmware records the firmware version a device reports
+// firmware_updated_at is only moved when the version changes.
+func (r *SQLiteDeviceRepository) UpdateFirmware(ctx context.Context, id uuid.UUID, version string) error {
+	return r.update(ctx, `
+		UPDATE devices
+		SET firmware_version = ?1,
+			firmware_updated_at = CASE WHEN firmware_version IS NOT ?1 THEN ?2 ELSE firmware_updated_at END,
+			updated_at = ?2
+		WHERE id = ?3
+	`, version, sqliteTime(time.Now()), id)
+}
+
+// FirmwareDistribution counts active devices per reported firmware version, most common first
+func (r *SQLiteDeviceRepository) FirmwareDistribution(ctx context.Context) ([]*models.FirmwareCount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT firmware_version, COUNT(*), COUNT(*) FILTER (WHERE last_seen_at > ?)
+		FROM devices
+		WHERE is_active = TRUE
+		GROUP BY firmware_version
+		ORDER BY COUNT(*) DESC, firmware_version NULLS LAST
+	`, sqliteTime(time.Now().Add(-models.DeviceOnlineWindow)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count firmware versions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := []*models.FirmwareCount{}
+	for rows.Next() {
+		count := &models.FirmwareCount{}
+		if err := rows.Scan(&count.Version, &count.Devices, &count.Online); err != nil {
+			return nil, fmt.Errorf("failed to scan firmware count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate firmware counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ListSeenSince retrieves all devices whose last_seen_at is at or after since
+func (r *SQLiteDeviceRepository) ListSeenSince(ctx context.Context, since time.Time) ([]*models.Device, error) {
+	return r.list(ctx, `SELECT `+deviceColumns+` FROM devices WHERE last_seen_at >= ? ORDER BY last_seen_at DESC`, sqliteTime(since))
+}
+
+// SetOrganization shares a device with an organization, or makes it personal again when orgID is nil
+func (r *SQLiteDeviceRepository) SetOrganization(ctx context.Context, id uuid.UUID, orgID *uuid.UUID) error {
+	return r.update(ctx, `UPDATE devices SET org_id = ?, updated_at = ? WHERE id = ?`, orgID, sqliteTime(time.Now()), id)
+}
+
+// Reassign transfers a device to another user and revokes its API keys
+// Keys are revoked in the same transaction so the previous owner loses ingest access immediately.
+func (r *SQLiteDeviceRepository) Reassign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	now := sqliteTime(time.Now())
+	result, err := tx.ExecContext(ctx, `UPDATE devices SET user_id = ?1, claimed_at = ?2, updated_at = ?2 WHERE id = ?3`, userID, now, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE device_api_keys SET revoked_at = ? WHERE device_id = ? AND revoked_at IS NULL`, now, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetTags replaces a device's tags
+func (r *SQLiteDeviceRepository) SetTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	return r.changeTags(ctx, id, func(tx *sql.Tx, now string) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM device_tags WHERE device_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to clear device tags: %w", err)
+		}
+		for _, tag := range tags {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO device_tags (device_id, tag, created_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+				id, tag, now); err != nil {
+				return fmt.Errorf("failed to insert device tag: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// AddTag tags a device; adding a tag the device already carries is a no-op
+func (r *SQLiteDeviceRepository) AddTag(ctx context.Context, id uuid.UUID, tag string) error {
+	return r.changeTags(ctx, id, func(tx *sql.Tx, now string) error {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO device_tags (device_id, tag, created_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+			id, tag, now); err != nil {
+			return fmt.Errorf("failed to insert device tag: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveTag removes a tag from a device, returning ErrDeviceTagNotFound when the device does not carry it
+func (r *SQLiteDeviceRepository) RemoveTag(ctx context.Context, id uuid.UUID, tag string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM device_tags WHERE device_id = ? AND tag = ?`, id, tag)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceTagNotFound
+	}
+
+	return nil
+}
+
+// TagsByDevice retrieves the tags of the given devices in alphabetical order, keyed by device ID
+func (r *SQLiteDeviceRepository) TagsByDevice(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	tags := make(map[uuid.UUID][]string)
+	if len(ids) == 0 {
+		return tags, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT device_id, tag
+		FROM device_tags
+		WHERE device_id IN (`+placeholders+`)
+		ORDER BY tag
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device tags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanDeviceTags(rows, tags)
+}
+
+// TagCounts counts the devices carrying each tag among those the filter's owner can list
+func (r *SQLiteDeviceRepository) TagCounts(ctx context.Context, filter DeviceFilter) ([]*models.DeviceTagCount, error) {
+	where := `d.user_id = ?`
+	args := []interface{}{sqliteTime(time.Now().Add(-models.DeviceOnlineWindow)), filter.UserID}
+	if len(filter.OrgIDs) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(filter.OrgIDs)), ", ")
+		where = `(d.user_id = ? OR d.org_id IN (` + placeholders + `))`
+		for _, id := range filter.OrgIDs {
+			args = append(args, id)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT dt.tag, COUNT(*), COUNT(*) FILTER (WHERE d.is_active), COUNT(*) FILTER (WHERE d.last_seen_at > ?)
+		FROM device_tags dt
+		JOIN devices d ON d.id = dt.device_id
+		WHERE `+where+`
+		GROUP BY dt.tag
+		ORDER BY dt.tag
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count device tags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanDeviceTagCounts(rows)
+}
+
+// changeTags runs a tag change in a transaction that also bumps the device's updated_at,
+// returning ErrDeviceNotFound when the device does not exist
+func (r *SQLiteDeviceRepository) changeTags(ctx context.Context, id uuid.UUID, change func(tx *sql.Tx, now string) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	now := sqliteTime(time.Now())
+	result, err := tx.ExecContext(ctx, `UPDATE devices SET updated_at = ? WHERE id = ?`, now, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+
+	if err := change(tx, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// list runs a device SELECT of deviceColumns
+func (r *SQLiteDeviceRepository) list(ctx context.Context, query string, args ...interface{}) ([]*models.Device, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var devices []*models.Device
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// update runs a single-device UPDATE, returning ErrDeviceNotFound when no row matched
+func (r *SQLiteDeviceRepository) update(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+
+	return nil
+}
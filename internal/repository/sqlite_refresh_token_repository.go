@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// SQLiteRefreshTokenRepository implements RefreshTokenRepository using SQLite
+type SQLiteRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRefreshTokenRepository creates a new SQLite refresh token repository
+func NewSQLiteRefreshTokenRepository(db *sql.DB) *SQLiteRefreshTokenRepository {
+	return &SQLiteRefreshTokenRepository{db: db}
+}
+
+// Create stores a new refresh token
+func (r *SQLiteRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (
+			id, user_id, token_hash, expires_at, created_at,
+			revoked_at, replaced_by, user_agent, ip_address,
+			remember_me, session_started_at,
+			geo_country_code, geo_country, geo_region, geo_city,
+			client_fingerprint
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	// A token without a session start begins a new session
+	sessionStartedAt := token.SessionStartedAt
+	if sessionStartedAt.IsZero() {
+		sessionStartedAt = token.CreatedAt
+	}
+
+	var countryCode, country, region, city *string
+	if token.Location != nil {
+		countryCode = &token.Location.CountryCode
+		country = nullIfEmpty(token.Location.Country)
+		region = nullIfEmpty(token.Location.Region)
+		city = nullIfEmpty(token.Location.City)
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		token.ID, token.UserID, token.TokenHash, sqliteTime(token.ExpiresAt), sqliteTime(token.CreatedAt),
+		sqliteNullTime(token.RevokedAt), token.ReplacedBy, token.UserAgent, token.IPAddress,
+		token.RememberMe, sqliteTime(sessionStartedAt),
+		countryCode, country, region, city,
+		nullIfEmpty(token.ClientFingerprint),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash retrieves a refresh token by its hash
+func (r *SQLiteRefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	query := `
+		SELECT
+			id, user_id, token_hash, expires_at, created_at,
+			revoked_at, replaced_by, user_agent, ip_address,
+			remember_me, session_started_at,
+			geo_country_code, geo_country, geo_region, geo_city,
+			client_fingerprint
+		FROM refresh_tokens
+		WHERE token_hash = ?
+	`
+
+	var token models.RefreshToken
+	var revokedAt sql.NullTime
+	var userAgent, ipAddress, fingerprint sql.NullString
+	var location geoColumns
+
+	err := r.db.QueryRowContext(ctx, query, hash).Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt,
+		&revokedAt, &token.ReplacedBy, &userAgent, &ipAddress,
+		&token.RememberMe, &token.SessionStartedAt,
+		&location.countryCode, &location.country, &location.region, &location.city,
+		&fingerprint,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	token.UserAgent = userAgent.String
+	token.IPAddress = ipAddress.String
+	token.Location = location.toLocation()
+	token.ClientFingerprint = fingerprint.String
+
+	// Check if token is revoked
+	if token.RevokedAt != nil {
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	// Check if token is expired
+	if token.ExpiresAt.Before(time.Now()) {
+		return nil, ErrRefreshTokenNotFound
+	}
+
+	return &token, nil
+}
+
+// Revoke marks a refresh token as revoked by its ID
+func (r *SQLiteRefreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.revoke(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`,
+		sqliteTime(time.Now()), id)
+}
+
+// RevokeByHash marks a refresh token as revoked by its hash
+func (r *SQLiteRefreshTokenRepository) RevokeByHash(ctx context.Context, hash string) error {
+	return r.revoke(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE token_hash = ? AND revoked_at IS NULL`,
+		sqliteTime(time.Now()), hash)
+}
+
+// RevokeAllForUser revokes all active refresh tokens for a specific user
+func (r *SQLiteRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`,
+		sqliteTime(time.Now()), userID)
+	return err
+}
+
+// ListActiveSessions retrieves the user's signed-in clients, most recently used first
+// The sign-in time is found by walking the replaced_by chain back from the active token, as in PostgreSQL.
+func (r *SQLiteRefreshTokenRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*models.LoginSession, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id AS session_id, replaced_by, created_at
+			FROM refresh_tokens
+			WHERE user_id = ?1 AND revoked_at IS NULL AND expires_at > ?2
+			UNION ALL
+			SELECT c.session_id, t.replaced_by, t.created_at
+			FROM chain c
+			JOIN refresh_tokens t ON t.id = c.replaced_by
+		)
+		SELECT t.id, t.user_agent, t.ip_address, MIN(c.created_at), t.created_at, t.expires_at, t.remember_me,
+			t.geo_country_code, t.geo_country, t.geo_region, t.geo_city
+		FROM refresh_tokens t
+		JOIN chain c ON c.session_id = t.id
+		GROUP BY t.id
+		ORDER BY t.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, sqliteTime(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	sessions := []*models.LoginSession{}
+	for rows.Next() {
+		session := &models.LoginSession{}
+		var userAgent, ipAddress sql.NullString
+		var createdAt sqliteTimestamp
+		var location geoColumns
+		if err := rows.Scan(
+			&session.ID, &userAgent, &ipAddress,
+			&createdAt, &session.LastUsedAt, &session.ExpiresAt, &session.RememberMe,
+			&location.countryCode, &location.country, &location.region, &location.city,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		session.UserAgent = userAgent.String
+		session.IPAddress = ipAddress.String
+		session.CreatedAt = createdAt.Time
+		session.Location = location.toLocation()
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ListCountries retrieves the distinct countries the user's stored refresh tokens were issued in
+func (r *SQLiteRefreshTokenRepository) ListCountries(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT geo_country_code
+		FROM refresh_tokens
+		WHERE user_id = ? AND geo_country_code IS NOT NULL
+		ORDER BY geo_country_code
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login countries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	countries := []string{}
+	for rows.Next() {
+		var country string
+		if err := rows.Scan(&country); err != nil {
+			return nil, fmt.Errorf("failed to scan login country: %w", err)
+		}
+		countries = append(countries, country)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate login countries: %w", err)
+	}
+
+	return countries, nil
+}
+
+// RevokeForUser revokes an active refresh token owned by the user
+func (r *SQLiteRefreshTokenRepository) RevokeForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	now := sqliteTime(time.Now())
+	return r.revoke(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = ?1
+		WHERE id = ?2 AND user_id = ?3 AND revoked_at IS NULL AND expires_at > ?1
+	`, now, id, userID)
+}
+
+// DeleteExpired removes all expired tokens and returns the count
+func (r *SQLiteRefreshTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at < ?`, sqliteTime(time.Now()))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// revoke runs a revoking UPDATE, returning ErrRefreshTokenNotFound when no active token matched
+func (r *SQLiteRefreshTokenRepository) revoke(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRefreshTokenNotFound
+	}
+
+	return nil
+}
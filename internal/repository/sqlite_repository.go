@@ -0,0 +1,1107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
+)
+
+// ErrProcessedUnsupported is returned by the SQLite backend for queries of smoothed telemetry
+var ErrProcessedUnsupported = errors.New("processed telemetry requires PostgreSQL")
+
+// sqliteTime formats a timestamp argument for the SQLite backend
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(database.SQLiteTimeFormat)
+}
+
+// sqliteNullTime formats an optional timestamp argument for the SQLite backend
+func sqliteNullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return sqliteTime(*t)
+}
+
+// sqliteBinder binds paging values as SQLite parameters appended to args
+// Times are formatted like stored timestamps so they compare as text.
+func sqliteBinder(args *[]interface{}) pagination.Binder {
+	return func(value any) string {
+		if t, ok := value.(time.Time); ok {
+			value = sqliteTime(t)
+		}
+		*args = append(*args, value)
+		return "?"
+	}
+}
+
+// sqliteTimestamp scans a timestamp computed by an SQLite expression, such as MIN or MAX
+// The driver only parses values of columns declared DATETIME; computed values come back as text.
+type sqliteTimestamp struct {
+	Time  time.Time
+	Valid bool
+}
+
+// Scan implements sql.Scanner
+func (t *sqliteTimestamp) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		t.Time, t.Valid = time.Time{}, false
+		return nil
+	case time.Time:
+		t.Time, t.Valid = v, true
+		return nil
+	case string:
+		return t.parse(v)
+	case []byte:
+		return t.parse(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into a timestamp", src)
+	}
+}
+
+func (t *sqliteTimestamp) parse(value string) error {
+	parsed, err := time.ParseInLocation("2006-01-02 15:04:05.999999999", value, time.UTC)
+	if err != nil {
+		return err
+	}
+	t.Time, t.Valid = parsed, true
+	return nil
+}
+
+// sqliteTelemetryInsert inserts a telemetry record with the arguments returned by sqliteTelemetryArgs
+// SQLite has no PostGIS, so the location column is left out and tracks are built from latitude and longitude.
+const sqliteTelemetryInsert = `
+	INSERT INTO telemetry (
+		recorded_at, device_id, session_id, user_id, itow, time_accuracy, validity_flags,
+		latitude, longitude,
+		wgs_altitude, msl_altitude, speed, heading,
+		num_satellites, fix_status, is_fix_valid,
+		horizontal_accuracy, vertical_accuracy, speed_accuracy, heading_accuracy, pdop,
+		g_force_x, g_force_y, g_force_z,
+		rotation_x, rotation_y, rotation_z,
+		battery, is_charging, schema_version, extras,
+		rpm, throttle, brake_pressure, coolant_temp, gear, client_id, quality_flags,
+		received_at, clock_skew_ms, device_recorded_at,
+		total_g, speed_delta, vertical_speed, distance_increment
+	) VALUES (
+		?, ?, ?, ?, ?, ?, ?,
+		?, ?,
+		?, ?, ?, ?,
+		?, ?, ?,
+		?, ?, ?, ?, ?,
+		?, ?, ?,
+		?, ?, ?,
+		?, ?, ?, ?,
+		?, ?, ?, ?, ?, ?, ?,
+		?, ?, ?,
+		?, ?, ?, ?
+	) ON CONFLICT DO NOTHING
+	RETURNING id
+`
+
+// sqliteTelemetryArgs returns the insert arguments of a telemetry record
+func sqliteTelemetryArgs(data *models.TelemetryData) ([]interface{}, error) {
+	version, extras, err := telemetrySchemaArgs(data)
+	if err != nil {
+		return nil, err
+	}
+	vehicle, derived := vehicleArgs(data), derivedArgs(data)
+
+	return []interface{}{
+		sqliteTime(data.Timestamp), data.DeviceID, data.SessionID, data.UserID,
+		data.ITOW, data.TimeAccuracy, data.ValidityFlags,
+		data.GPS.Latitude, data.GPS.Longitude,
+		data.GPS.WgsAltitude, data.GPS.MslAltitude, data.GPS.Speed, data.GPS.Heading,
+		data.GPS.NumSatellites, data.GPS.FixStatus, data.GPS.IsFixValid,
+		data.GPS.HorizontalAccuracy, data.GPS.VerticalAccuracy,
+		data.GPS.SpeedAccuracy, data.GPS.HeadingAccuracy, data.GPS.PDOP,
+		data.Motion.GForceX, data.Motion.GForceY, data.Motion.GForceZ,
+		data.Motion.RotationX, data.Motion.RotationY, data.Motion.RotationZ,
+		data.Battery, data.IsCharging, version, extras,
+		vehicle.RPM, vehicle.Throttle, vehicle.BrakePressure, vehicle.CoolantTemp, vehicle.Gear, data.ClientID, data.QualityFlags,
+		sqliteNullTime(data.ReceivedAt), data.ClockSkewMs, sqliteNullTime(data.DeviceTimestamp),
+		derived.TotalG, derived.SpeedDelta, derived.VerticalSpeed, derived.DistanceIncrement,
+	}, nil
+}
+
+// SQLiteRepository implements TelemetryRepository using SQLite
+// Downsampling and track simplification are computed from raw rows, as SQLite has neither
+// continuous aggregates nor PostGIS. Smoothed telemetry is not supported.
+type SQLiteRepository struct {
+	db    *sql.DB
+	dedup bool // Skip records that match an existing (device_id, itow, recorded_at) row
+}
+
+// NewSQLiteRepository creates a new SQLite telemetry repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// WithDeduplication enables skipping duplicate records on insert
+// Call ApplyDeduplication at startup so the unique index matches the setting.
+func (r *SQLiteRepository) WithDeduplication(enabled bool) *SQLiteRepository {
+	r.dedup = enabled
+	return r
+}
+
+// ApplyDeduplication creates or drops the unique index used to detect duplicate telemetry
+// Creating the index first removes existing duplicates, keeping the earliest stored copy.
+func (r *SQLiteRepository) ApplyDeduplication(ctx context.Context) error {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'index' AND tbl_name = 'telemetry' AND name = ?)`,
+		telemetryDedupIndex,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check deduplication index: %w", err)
+	}
+
+	switch {
+	case r.dedup && !exists:
+		result, err := r.db.ExecContext(ctx, `
+			DELETE FROM telemetry
+			WHERE EXISTS (
+				SELECT 1 FROM telemetry b
+				WHERE b.device_id = telemetry.device_id AND b.itow = telemetry.itow
+					AND b.recorded_at = telemetry.recorded_at AND b.id < telemetry.id
+			)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to remove duplicate telemetry: %w", err)
+		}
+		if removed, _ := result.RowsAffected(); removed > 0 {
+			slog.Info("Removed duplicate telemetry records", "count", removed)
+		}
+
+		query := `CREATE UNIQUE INDEX ` + telemetryDedupIndex + ` ON telemetry (device_id, itow, recorded_at)`
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to create deduplication index: %w", err)
+		}
+		slog.Info("Enabled telemetry deduplication")
+	case !r.dedup && exists:
+		if _, err := r.db.ExecContext(ctx, `DROP INDEX `+telemetryDedupIndex); err != nil {
+			return fmt.Errorf("failed to drop deduplication index: %w", err)
+		}
+		slog.Info("Disabled telemetry deduplication")
+	}
+
+	return nil
+}
+
+// scanInsertedID reads the ID returned by a telemetry insert, flagging rows skipped as duplicates
+func (r *SQLiteRepository) scanInsertedID(row *sql.Row, data *models.TelemetryData) error {
+	err := row.Scan(&data.ID)
+	if (r.dedup || data.ClientID != nil) && errors.Is(err, sql.ErrNoRows) {
+		data.Duplicate = true
+		return nil
+	}
+	return err
+}
+
+// Save saves a single telemetry data point
+func (r *SQLiteRepository) Save(ctx context.Context, data *models.TelemetryData) error {
+	args, err := sqliteTelemetryArgs(data)
+	if err != nil {
+		return err
+	}
+
+	if err := r.scanInsertedID(r.db.QueryRowContext(ctx, sqliteTelemetryInsert, args...), data); err != nil {
+		return fmt.Errorf("failed to insert telemetry: %w", err)
+	}
+
+	// A replayed upload reports the ID of the row stored by the original one
+	if data.Duplicate && data.ClientID != nil {
+		if err := r.scanClientRecordID(ctx, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanClientRecordID sets the ID of the stored record with the same clientId and timestamp
+// The ID stays zero when the insert was skipped by the deduplication index instead.
+func (r *SQLiteRepository) scanClientRecordID(ctx context.Context, data *models.TelemetryData) error {
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id FROM telemetry WHERE client_id = ? AND recorded_at = ?`,
+		data.ClientID, sqliteTime(data.Timestamp),
+	).Scan(&data.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to look up replayed telemetry: %w", err)
+	}
+	return nil
+}
+
+// SaveBatch saves multiple telemetry data points in a single transaction
+func (r *SQLiteRepository) SaveBatch(ctx context.Context, dataPoints []*models.TelemetryData) error {
+	if len(dataPoints) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, sqliteTelemetryInsert)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, data := range dataPoints {
+		args, err := sqliteTelemetryArgs(data)
+		if err != nil {
+			return err
+		}
+		if err := r.scanInsertedID(stmt.QueryRowContext(ctx, args...), data); err != nil {
+			return fmt.Errorf("failed to insert telemetry in batch: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTimeRange retrieves telemetry data within a time range
+func (r *SQLiteRepository) GetByTimeRange(ctx context.Context, start, end time.Time, limit int) ([]*models.TelemetryData, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	query := `
+		SELECT ` + telemetryColumns + `
+		FROM telemetry
+		WHERE recorded_at BETWEEN ? AND ?
+		ORDER BY recorded_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sqliteTime(start), sqliteTime(end), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry by time range: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSQLiteTelemetryRows(rows)
+}
+
+// GetBySession retrieves telemetry data for a specific session
+func (r *SQLiteRepository) GetBySession(ctx context.Context, sessionID string, limit int) ([]*models.TelemetryData, error) {
+	if limit <= 0 {
+		limit = 10000
+	}
+
+	query := `
+		SELECT ` + telemetryColumns + `
+		FROM telemetry
+		WHERE session_id = ?
+		ORDER BY recorded_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry by session: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSQLiteTelemetryRows(rows)
+}
+
+// GetRecent retrieves the most recent telemetry data points
+func (r *SQLiteRepository) GetRecent(ctx context.Context, limit int) ([]*models.TelemetryData, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+telemetryColumns+` FROM telemetry ORDER BY recorded_at DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent telemetry: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSQLiteTelemetryRows(rows)
+}
+
+// GetByDevice retrieves telemetry data for a specific device
+func (r *SQLiteRepository) GetByDevice(ctx context.Context, deviceID string, limit int) ([]*models.TelemetryData, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	query := `
+		SELECT ` + telemetryColumns + `
+		FROM telemetry
+		WHERE device_id = ?
+		ORDER BY recorded_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, deviceID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry by device: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSQLiteTelemetryRows(rows)
+}
+
+// telemetryConditions builds the owner, device, session, tag and quality conditions shared by Query and Aggregate
+// Time bounds are left to the callers, which compare them against different columns.
+func telemetryConditions(filter TelemetryFilter) ([]string, []interface{}) {
+	conditions := []string{"user_id = ?"}
+	args := []interface{}{filter.UserID}
+
+	if filter.DeviceID != "" {
+		conditions = append(conditions, "device_id = ?")
+		args = append(args, filter.DeviceID)
+	}
+	if filter.SessionID != "" {
+		conditions = append(conditions, "session_id = ?")
+		args = append(args, filter.SessionID)
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, "device_id IN (SELECT d.device_id FROM devices d JOIN device_tags dt ON dt.device_id = d.id WHERE dt.tag = ?)")
+		args = append(args, filter.Tag)
+	}
+	if filter.CleanOnly {
+		conditions = append(conditions, "quality_flags = 0")
+	}
+
+	return conditions, args
+}
+
+// Query retrieves telemetry data matching the given filter
+func (r *SQLiteRepository) Query(ctx context.Context, filter TelemetryFilter) ([]*models.TelemetryData, error) {
+	if filter.Processed {
+		return nil, ErrProcessedUnsupported
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	conditions, args := telemetryConditions(filter)
+	if filter.From != nil {
+		conditions = append(conditions, "recorded_at >= ?")
+		args = append(args, sqliteTime(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "recorded_at <= ?")
+		args = append(args, sqliteTime(*filter.To))
+	}
+	if after := telemetryKeyset.Apply(filter.After, sqliteBinder(&args)); after != "" {
+		conditions = append(conditions, after)
+	}
+
+	args = append(args, limit)
+	query := `
+		SELECT ` + telemetryColumns + `
+		FROM telemetry
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + telemetryKeyset.OrderBy() + `
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSQLiteTelemetryRows(rows)
+}
+
+// sqliteBucketSeconds maps each aggregation interval to its width in seconds
+var sqliteBucketSeconds = map[AggregateBucket]int64{
+	Bucket1s:  1,
+	Bucket1m:  60,
+	Bucket10m: 600,
+}
+
+// Aggregate retrieves downsampled telemetry matching the given filter in chronological order
+// Buckets are computed from raw rows, which gives the same sample-weighted averages as merging
+// the PostgreSQL continuous aggregates. Buckets start at multiples of their width since the Unix epoch.
+func (r *SQLiteRepository) Aggregate(ctx context.Context, filter TelemetryFilter, bucket AggregateBucket) ([]*models.TelemetryBucket, error) {
+	width, ok := sqliteBucketSeconds[bucket]
+	if !ok {
+		return nil, fmt.Errorf("unsupported aggregate bucket %q", bucket)
+	}
+	if filter.Processed {
+		return nil, ErrProcessedUnsupported
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	conditions, args := telemetryConditions(filter)
+	args = append([]interface{}{width}, args...)
+
+	having := []string{"1 = 1"}
+	if filter.From != nil {
+		having = append(having, "bucket >= ?")
+		args = append(args, filter.From.Unix())
+	}
+	if filter.To != nil {
+		having = append(having, "bucket <= ?")
+		args = append(args, filter.To.Unix())
+	}
+
+	args = append(args, limit)
+	query := `
+		SELECT
+			CAST(unixepoch(recorded_at) / ?1 AS INTEGER) * ?1 AS bucket,
+			COUNT(*),
+			AVG(speed),
+			MAX(speed),
+			AVG(g_force_x),
+			AVG(g_force_y),
+			AVG(g_force_z),
+			MAX(g_force_x * g_force_x + g_force_y * g_force_y),
+			AVG(battery),
+			MIN(battery),
+			AVG(rpm),
+			MAX(rpm),
+			AVG(throttle),
+			MAX(brake_pressure),
+			MAX(coolant_temp)
+		FROM telemetry
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY bucket
+		HAVING ` + strings.Join(having, " AND ") + `
+		ORDER BY bucket ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry aggregate: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*models.TelemetryBucket
+	for rows.Next() {
+		b := &models.TelemetryBucket{}
+		var start int64
+		var avgSpeed, maxSpeed, avgGX, avgGY, avgGZ, maxGSquared, avgBattery, minBattery sql.NullFloat64
+		if err := rows.Scan(
+			&start, &b.Samples,
+			&avgSpeed, &maxSpeed,
+			&avgGX, &avgGY, &avgGZ, &maxGSquared,
+			&avgBattery, &minBattery,
+			&b.AvgRPM, &b.MaxRPM, &b.AvgThrottle, &b.MaxBrakePressure, &b.MaxCoolantTemp,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry aggregate row: %w", err)
+		}
+
+		b.Bucket = time.Unix(start, 0).UTC()
+		b.AvgSpeed = avgSpeed.Float64
+		b.MaxSpeed = maxSpeed.Float64
+		b.AvgGForceX = avgGX.Float64
+		b.AvgGForceY = avgGY.Float64
+		b.AvgGForceZ = avgGZ.Float64
+		b.MaxGForce = math.Sqrt(maxGSquared.Float64)
+		b.AvgBattery = avgBattery.Float64
+		b.MinBattery = minBattery.Float64
+
+		results = append(results, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry aggregate rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// scanSQLiteTelemetryRows scans database rows into TelemetryData structs
+func scanSQLiteTelemetryRows(rows *sql.Rows) ([]*models.TelemetryData, error) {
+	var results []*models.TelemetryData
+
+	for rows.Next() {
+		data, err := scanTelemetry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry row: %w", err)
+		}
+
+		results = append(results, data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// IterateBySession streams a session's telemetry in chronological order
+// The caller must Close the iterator.
+func (r *SQLiteRepository) IterateBySession(ctx context.Context, sessionID string) (TelemetryIterator, error) {
+	query := `
+		SELECT ` + telemetryColumns + `
+		FROM telemetry
+		WHERE session_id = ?
+		ORDER BY recorded_at ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session telemetry: %w", err)
+	}
+
+	return &rowsTelemetryIterator{rows: rows}, nil
+}
+
+// IterateSessionPage streams up to limit of a session's telemetry records in chronological order,
+// starting after the given position (exclusive) when set
+// The caller must Close the iterator.
+func (r *SQLiteRepository) IterateSessionPage(ctx context.Context, sessionID string, after *pagination.Cursor, limit int) (TelemetryIterator, error) {
+	args := []interface{}{sessionID}
+	conditions := []string{"session_id = ?"}
+	if cond := sessionTelemetryKeyset.Apply(after, sqliteBinder(&args)); cond != "" {
+		conditions = append(conditions, cond)
+	}
+
+	args = append(args, limit)
+	query := `
+		SELECT ` + telemetryColumns + `
+		FROM telemetry
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + sessionTelemetryKeyset.OrderBy() + `
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session telemetry: %w", err)
+	}
+
+	return &rowsTelemetryIterator{rows: rows}, nil
+}
+
+// Heatmap grids the located telemetry within the bounding box into Web Mercator cells sized for
+// the zoom level, densest first, up to the filter's limit
+// SQLite has no spatial functions, so the points in the box are projected and binned here.
+func (r *SQLiteRepository) Heatmap(ctx context.Context, filter TelemetryFilter, bbox models.BoundingBox, zoom int) ([]*models.HeatmapCell, error) {
+	if filter.Processed {
+		return nil, ErrProcessedUnsupported
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	conditions, args := telemetryConditions(filter)
+	conditions = append(conditions, "longitude BETWEEN ? AND ?", "latitude BETWEEN ? AND ?")
+	args = append(args, bbox.West, bbox.East, bbox.South, bbox.North)
+	if filter.From != nil {
+		conditions = append(conditions, "recorded_at >= ?")
+		args = append(args, sqliteTime(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "recorded_at <= ?")
+		args = append(args, sqliteTime(*filter.To))
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT longitude, latitude, speed
+		FROM telemetry
+		WHERE `+strings.Join(conditions, " AND "), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry heatmap: %w", err)
+	}
+	defer rows.Close()
+
+	type cellKey struct{ x, y int64 }
+	type cellStats struct {
+		points   int64
+		sumSpeed float64
+		maxSpeed float64
+	}
+
+	size := models.HeatmapCellSize(zoom)
+	grid := make(map[cellKey]*cellStats)
+	for rows.Next() {
+		var longitude, latitude, speed float64
+		if err := rows.Scan(&longitude, &latitude, &speed); err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry heatmap row: %w", err)
+		}
+
+		x, y := mercator(longitude, latitude)
+		key := cellKey{int64(math.Floor(x / size)), int64(math.Floor(y / size))}
+		stats, ok := grid[key]
+		if !ok {
+			stats = &cellStats{maxSpeed: speed}
+			grid[key] = stats
+		}
+		stats.points++
+		stats.sumSpeed += speed
+		stats.maxSpeed = math.Max(stats.maxSpeed, speed)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry heatmap rows: %w", err)
+	}
+
+	keys := make([]cellKey, 0, len(grid))
+	for key := range grid {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := grid[keys[i]], grid[keys[j]]
+		if a.points != b.points {
+			return a.points > b.points
+		}
+		if keys[i].x != keys[j].x {
+			return keys[i].x < keys[j].x
+		}
+		return keys[i].y < keys[j].y
+	})
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	cells := make([]*models.HeatmapCell, 0, len(keys))
+	for _, key := range keys {
+		stats := grid[key]
+		longitude, latitude := inverseMercator((float64(key.x)+0.5)*size, (float64(key.y)+0.5)*size)
+		cells = append(cells, &models.HeatmapCell{
+			Latitude:  latitude,
+			Longitude: longitude,
+			Points:    stats.points,
+			AvgSpeed:  stats.sumSpeed / float64(stats.points),
+			MaxSpeed:  stats.maxSpeed,
+		})
+	}
+
+	return cells, nil
+}
+
+// mercatorRadius is the sphere radius of the Web Mercator projection (EPSG:3857)
+const mercatorRadius = 6378137
+
+// mercator projects a longitude and latitude in degrees to Web Mercator meters
+func mercator(longitude, latitude float64) (x, y float64) {
+	x = mercatorRadius * longitude * math.Pi / 180
+	y = mercatorRadius * math.Log(math.Tan(math.Pi/4+latitude*math.Pi/360))
+	return x, y
+}
+
+// inverseMercator converts Web Mercator meters back to a longitude and latitude in degrees
+func inverseMercator(x, y float64) (longitude, latitude float64) {
+	longitude = x / mercatorRadius * 180 / math.Pi
+	latitude = (2*math.Atan(math.Exp(y/mercatorRadius)) - math.Pi/2) * 180 / math.Pi
+	return longitude, latitude
+}
+
+// Within retrieves the located telemetry inside the area matching the given filter, ordered
+// like Query
+// SQLite has no spatial functions, so the rows in the area's bounding box are tested here.
+func (r *SQLiteRepository) Within(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) ([]*models.TelemetryData, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := r.queryArea(ctx, filter, area, true)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*models.TelemetryData
+	for len(results) < limit && rows.Next() {
+		data, err := scanTelemetry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry row: %w", err)
+		}
+		if area.Contains(data.GPS.Latitude, data.GPS.Longitude) {
+			results = append(results, data)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// WithinStats summarizes the located telemetry inside the area matching the given filter
+func (r *SQLiteRepository) WithinStats(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) (*models.AreaStats, error) {
+	rows, err := r.queryArea(ctx, filter, area, false)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := &models.AreaStats{}
+	devices, sessions := make(map[string]struct{}), make(map[string]struct{})
+	var sumSpeed float64
+	for rows.Next() {
+		data, err := scanTelemetry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry row: %w", err)
+		}
+		if !area.Contains(data.GPS.Latitude, data.GPS.Longitude) {
+			continue
+		}
+
+		stats.Points++
+		devices[data.DeviceID] = struct{}{}
+		if data.SessionID != nil {
+			sessions[*data.SessionID] = struct{}{}
+		}
+		if stats.FirstRecordedAt == nil || data.Timestamp.Before(*stats.FirstRecordedAt) {
+			recordedAt := data.Timestamp
+			stats.FirstRecordedAt = &recordedAt
+		}
+		if stats.LastRecordedAt == nil || data.Timestamp.After(*stats.LastRecordedAt) {
+			recordedAt := data.Timestamp
+			stats.LastRecordedAt = &recordedAt
+		}
+		sumSpeed += data.GPS.Speed
+		stats.MaxSpeed = math.Max(stats.MaxSpeed, data.GPS.Speed)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry rows: %w", err)
+	}
+
+	stats.Devices = int64(len(devices))
+	stats.Sessions = int64(len(sessions))
+	if stats.Points > 0 {
+		stats.AvgSpeed = sumSpeed / float64(stats.Points)
+	}
+	return stats, nil
+}
+
+// queryArea selects the telemetry matching the filter within the area's bounding box, ordered
+// like Query and resuming after the filter's cursor when paged is set
+// The caller must test each row against the area and close the rows.
+func (r *SQLiteRepository) queryArea(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon, paged bool) (*sql.Rows, error) {
+	bounds := area.Bounds()
+	conditions, args := telemetryConditions(filter)
+	conditions = append(conditions, "longitude BETWEEN ? AND ?", "latitude BETWEEN ? AND ?")
+	args = append(args, bounds.West, bounds.East, bounds.South, bounds.North)
+	if filter.From != nil {
+		conditions = append(conditions, "recorded_at >= ?")
+		args = append(args, sqliteTime(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "recorded_at <= ?")
+		args = append(args, sqliteTime(*filter.To))
+	}
+	if paged {
+		if after := telemetryKeyset.Apply(filter.After, sqliteBinder(&args)); after != "" {
+			conditions = append(conditions, after)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+telemetryColumns+`
+		FROM telemetry
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY `+telemetryKeyset.OrderBy(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry in area: %w", err)
+	}
+	return rows, nil
+}
+
+// LatestPositions returns the last known position of each device recorded by its owner
+// Each device is looked up with its own query on idx_telemetry_device_time.
+func (r *SQLiteRepository) LatestPositions(ctx context.Context, devices []DeviceOwner) ([]*models.DevicePosition, error) {
+	positions := []*models.DevicePosition{}
+	for _, device := range devices {
+		position := &models.DevicePosition{DeviceID: device.DeviceID}
+		var speed, heading sql.NullFloat64
+		err := r.db.QueryRowContext(ctx, `
+			SELECT recorded_at, latitude, longitude, speed, heading
+			FROM telemetry
+			WHERE device_id = ? AND user_id = ? AND quality_flags = 0
+			ORDER BY recorded_at DESC
+			LIMIT 1
+		`, device.DeviceID, device.UserID.String()).Scan(&position.RecordedAt, &position.Latitude, &position.Longitude, &speed, &heading)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query device position: %w", err)
+		}
+		position.Speed = speed.Float64
+		position.Heading = heading.Float64
+		positions = append(positions, position)
+	}
+
+	sort.Slice(positions, func(i, j int) bool { return positions[i].DeviceID < positions[j].DeviceID })
+	return positions, nil
+}
+
+// PreviousRecords returns, for each record, the latest stored record of its device recorded before
+// it and at most models.MaxDerivedGap earlier, or nil when there is none
+// Each record is looked up with its own query on idx_telemetry_device_time.
+func (r *SQLiteRepository) PreviousRecords(ctx context.Context, records []*models.TelemetryData) ([]*models.TelemetryData, error) {
+	previous := make([]*models.TelemetryData, len(records))
+	for i, record := range records {
+		if record.DeviceID == "" {
+			continue
+		}
+
+		prev := &models.TelemetryData{DeviceID: record.DeviceID}
+		var latitude, longitude, altitude, speed sql.NullFloat64
+		err := r.db.QueryRowContext(ctx, `
+			SELECT recorded_at, latitude, longitude, msl_altitude, speed
+			FROM telemetry
+			WHERE device_id = ? AND recorded_at < ? AND recorded_at >= ?
+			ORDER BY recorded_at DESC
+			LIMIT 1
+		`, record.DeviceID, sqliteTime(record.Timestamp), sqliteTime(record.Timestamp.Add(-models.MaxDerivedGap))).
+			Scan(&prev.Timestamp, &latitude, &longitude, &altitude, &speed)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query previous telemetry: %w", err)
+		}
+		prev.GPS.Latitude, prev.GPS.Longitude = latitude.Float64, longitude.Float64
+		prev.GPS.MslAltitude, prev.GPS.Speed = altitude.Float64, speed.Float64
+		previous[i] = prev
+	}
+
+	return previous, nil
+}
+
+// IsBatchProcessed checks if a batch with the given ID has already been processed
+func (r *SQLiteRepository) IsBatchProcessed(ctx context.Context, batchID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM upload_batches WHERE batch_id = ?)`, batchID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check batch status: %w", err)
+	}
+
+	return exists, nil
+}
+
+// MarkBatchProcessed marks a batch as processed for idempotency
+func (r *SQLiteRepository) MarkBatchProcessed(ctx context.Context, batchID string, recordCount int, deviceID string, sessionID *string) error {
+	query := `
+		INSERT INTO upload_batches (batch_id, record_count, uploaded_at, device_id, session_id)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (batch_id) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, batchID, recordCount, sqliteTime(time.Now()), deviceID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to mark batch as processed: %w", err)
+	}
+
+	return nil
+}
+
+// IngestStats summarizes telemetry ingested since the given time
+func (r *SQLiteRepository) IngestStats(ctx context.Context, since time.Time) (*models.IngestStats, error) {
+	stats := &models.IngestStats{Since: since}
+
+	telemetryQuery := `
+		SELECT COUNT(*), COUNT(DISTINCT device_id), COUNT(DISTINCT user_id), MAX(recorded_at)
+		FROM telemetry
+		WHERE recorded_at >= ?
+	`
+
+	var lastRecordedAt sqliteTimestamp
+	err := r.db.QueryRowContext(ctx, telemetryQuery, sqliteTime(since)).Scan(
+		&stats.DataPoints, &stats.ActiveDevices, &stats.ActiveUsers, &lastRecordedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute telemetry stats: %w", err)
+	}
+	if lastRecordedAt.Valid {
+		stats.LastRecordedAt = &lastRecordedAt.Time
+	}
+
+	batchQuery := `
+		SELECT COUNT(*), COALESCE(SUM(record_count), 0)
+		FROM upload_batches
+		WHERE uploaded_at >= ?
+	`
+
+	if err := r.db.QueryRowContext(ctx, batchQuery, sqliteTime(since)).Scan(&stats.Batches, &stats.BatchRecords); err != nil {
+		return nil, fmt.Errorf("failed to compute batch stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// OrphanedTelemetry summarizes telemetry without an owner per device, largest first
+func (r *SQLiteRepository) OrphanedTelemetry(ctx context.Context, limit int) ([]*models.OrphanedTelemetry, error) {
+	query := `
+		SELECT COALESCE(t.device_id, ''), COUNT(*), MIN(t.recorded_at), MAX(t.recorded_at), d.user_id
+		FROM telemetry t
+		LEFT JOIN devices d ON d.device_id = t.device_id
+		WHERE t.user_id IS NULL
+		GROUP BY t.device_id, d.user_id
+		ORDER BY COUNT(*) DESC, t.device_id
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphaned telemetry: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	orphans := []*models.OrphanedTelemetry{}
+	for rows.Next() {
+		var orphan models.OrphanedTelemetry
+		var first, last sqliteTimestamp
+		if err := rows.Scan(&orphan.DeviceID, &orphan.DataPoints, &first, &last, &orphan.ClaimedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan orphaned telemetry: %w", err)
+		}
+		orphan.FirstRecordedAt, orphan.LastRecordedAt = first.Time, last.Time
+		orphans = append(orphans, &orphan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orphaned telemetry: %w", err)
+	}
+
+	return orphans, nil
+}
+
+// SessionTrack returns a session's path simplified with Douglas-Peucker to the given tolerance in meters
+// Points are projected onto a plane around the track's mean latitude, which is accurate to well
+// within the tolerance over the extent of a session. A zero tolerance returns every located point.
+func (r *SQLiteRepository) SessionTrack(ctx context.Context, sessionID string, toleranceMeters float64) (*models.TrackGeometry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT longitude, latitude
+		FROM telemetry
+		WHERE session_id = ?
+		ORDER BY recorded_at ASC, id ASC
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build session track: %w", err)
+	}
+	defer rows.Close()
+
+	var points [][2]float64
+	for rows.Next() {
+		var point [2]float64
+		if err := rows.Scan(&point[0], &point[1]); err != nil {
+			return nil, fmt.Errorf("failed to build session track: %w", err)
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to build session track: %w", err)
+	}
+
+	track := &models.TrackGeometry{Points: int64(len(points))}
+	if len(points) < 2 {
+		return track, nil
+	}
+
+	if toleranceMeters > 0 {
+		points = simplifyTrack(points, toleranceMeters)
+	}
+	track.SimplifiedPoints = len(points)
+
+	geometry, err := json.Marshal(map[string]interface{}{"type": "LineString", "coordinates": roundCoordinates(points)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session track: %w", err)
+	}
+	track.Geometry = geometry
+
+	return track, nil
+}
+
+// earthRadiusMeters is the mean Earth radius used to project track points
+const earthRadiusMeters = 6371008.8
+
+// simplifyTrack applies Douglas-Peucker to [longitude, latitude] points with a tolerance in meters
+func simplifyTrack(points [][2]float64, toleranceMeters float64) [][2]float64 {
+	var meanLat float64
+	for _, p := range points {
+		meanLat += p[1]
+	}
+	meanLat /= float64(len(points))
+
+	// Equirectangular projection to meters around the mean latitude
+	scaleX := earthRadiusMeters * math.Pi / 180 * math.Cos(meanLat*math.Pi/180)
+	scaleY := earthRadiusMeters * math.Pi / 180
+	projected := make([][2]float64, len(points))
+	for i, p := range points {
+		projected[i] = [2]float64{p[0] * scaleX, p[1] * scaleY}
+	}
+
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+
+	// Iterative to avoid deep recursion on long sessions
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		farthest, maxDistance := -1, toleranceMeters
+		for i := span[0] + 1; i < span[1]; i++ {
+			if d := segmentDistance(projected[i], projected[span[0]], projected[span[1]]); d > maxDistance {
+				farthest, maxDistance = i, d
+			}
+		}
+		if farthest >= 0 {
+			keep[farthest] = true
+			stack = append(stack, [2]int{span[0], farthest}, [2]int{farthest, span[1]})
+		}
+	}
+
+	simplified := make([][2]float64, 0, len(points))
+	for i, p := range points {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
+
+// segmentDistance returns the distance from p to the segment between a and b
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
+
+// roundCoordinates rounds coordinates to 7 decimal places, matching ST_AsGeoJSON in PostgreSQL
+func roundCoordinates(points [][2]float64) [][2]float64 {
+	rounded := make([][2]float64, len(points))
+	for i, p := range points {
+		rounded[i] = [2]float64{math.Round(p[0]*1e7) / 1e7, math.Round(p[1]*1e7) / 1e7}
+	}
+	return rounded
+}
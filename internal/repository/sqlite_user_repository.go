@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/database"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// SQLiteUserRepository implements UserRepository using SQLite
+type SQLiteUserRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteUserRepository creates a new SQLite user repository
+func NewSQLiteUserRepository(db *sql.DB) *SQLiteUserRepository {
+	return &SQLiteUserRepository{db: db}
+}
+
+// Create creates a new user
+func (r *SQLiteUserRepository) Create(ctx context.Context, user *models.User) error {
+	query := `
+		INSERT INTO users (
+			id, email, password_hash, email_verified,
+			verification_token, verification_token_expires_at,
+			reset_token, reset_token_expires_at,
+			created_at, updated_at, last_login_at, is_active, role
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	// Generate UUID if not provided
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+
+	// Set timestamps if not provided
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		user.ID, user.Email, user.PasswordHash, user.EmailVerified,
+		user.VerificationToken, sqliteNullTime(user.VerificationTokenExpiresAt),
+		user.ResetToken, sqliteNullTime(user.ResetTokenExpiresAt),
+		sqliteTime(user.CreatedAt), sqliteTime(user.UpdatedAt), sqliteNullTime(user.LastLoginAt), user.IsActive, user.Role,
+	)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrUserExists
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a user by their ID
+func (r *SQLiteUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by ID: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByEmail retrieves a user by their email address
+func (r *SQLiteUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = ?`, email))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	return user, nil
+}
+
+// Update updates an existing user's information
+func (r *SQLiteUserRepository) Update(ctx context.Context, user *models.User) error {
+	query := `
+		UPDATE users
+		SET
+			email = ?,
+			password_hash = ?,
+			email_verified = ?,
+			verification_token = ?,
+			verification_token_expires_at = ?,
+			reset_token = ?,
+			reset_token_expires_at = ?,
+			updated_at = ?,
+			last_login_at = ?,
+			is_active = ?,
+			role = ?
+		WHERE id = ?
+	`
+
+	user.UpdatedAt = time.Now()
+
+	err := r.update(ctx, "update user", query,
+		user.Email, user.PasswordHash, user.EmailVerified,
+		user.VerificationToken, sqliteNullTime(user.VerificationTokenExpiresAt),
+		user.ResetToken, sqliteNullTime(user.ResetTokenExpiresAt),
+		sqliteTime(user.UpdatedAt), sqliteNullTime(user.LastLoginAt), user.IsActive, user.Role,
+		user.ID,
+	)
+	if database.IsUniqueViolation(err) {
+		return ErrUserExists
+	}
+	return err
+}
+
+// UpdatePassword updates a user's password hash
+func (r *SQLiteUserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return r.update(ctx, "update password",
+		`UPDATE users SET password_hash = ?, updated_at = ? WHERE id = ?`,
+		passwordHash, sqliteTime(time.Now()), id,
+	)
+}
+
+// UpdateEmailVerification updates email verification status and clears verification token
+func (r *SQLiteUserRepository) UpdateEmailVerification(ctx context.Context, id uuid.UUID, verified bool) error {
+	query := `
+		UPDATE users
+		SET
+			email_verified = ?,
+			verification_token = NULL,
+			verification_token_expires_at = NULL,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	return r.update(ctx, "update email verification", query, verified, sqliteTime(time.Now()), id)
+}
+
+// SetVerificationToken sets the email verification token and expiry
+func (r *SQLiteUserRepository) SetVerificationToken(ctx context.Context, id uuid.UUID, token string, expiresAt *time.Time) error {
+	return r.update(ctx, "set verification token",
+		`UPDATE users SET verification_token = ?, verification_token_expires_at = ?, updated_at = ? WHERE id = ?`,
+		token, sqliteNullTime(expiresAt), sqliteTime(time.Now()), id,
+	)
+}
+
+// SetResetToken sets the password reset token and expiry
+func (r *SQLiteUserRepository) SetResetToken(ctx context.Context, id uuid.UUID, token string, expiresAt *time.Time) error {
+	return r.update(ctx, "set reset token",
+		`UPDATE users SET reset_token = ?, reset_token_expires_at = ?, updated_at = ? WHERE id = ?`,
+		token, sqliteNullTime(expiresAt), sqliteTime(time.Now()), id,
+	)
+}
+
+// GetByResetToken retrieves a user by their password reset token
+func (r *SQLiteUserRepository) GetByResetToken(ctx context.Context, token string) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE reset_token = ?`, token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by reset token: %w", err)
+	}
+
+	return user, nil
+}
+
+// ClearResetToken clears the password reset token and expiry
+func (r *SQLiteUserRepository) ClearResetToken(ctx context.Context, id uuid.UUID) error {
+	return r.update(ctx, "clear reset token",
+		`UPDATE users SET reset_token = NULL, reset_token_expires_at = NULL, updated_at = ? WHERE id = ?`,
+		sqliteTime(time.Now()), id,
+	)
+}
+
+// SetMagicLinkToken sets the passwordless sign-in token and expiry
+func (r *SQLiteUserRepository) SetMagicLinkToken(ctx context.Context, id uuid.UUID, token string, expiresAt time.Time) error {
+	return r.update(ctx, "set magic link token",
+		`UPDATE users SET magic_link_token = ?, magic_link_expires_at = ?, updated_at = ? WHERE id = ?`,
+		token, sqliteTime(expiresAt), sqliteTime(time.Now()), id,
+	)
+}
+
+// ConsumeMagicLinkToken clears an unexpired sign-in token and returns its user
+func (r *SQLiteUserRepository) ConsumeMagicLinkToken(ctx context.Context, token string) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET magic_link_token = NULL, magic_link_expires_at = NULL, updated_at = ?1
+		WHERE magic_link_token = ?2 AND magic_link_expires_at > ?1
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, sqliteTime(time.Now()), token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to consume magic link token: %w", err)
+	}
+
+	return user, nil
+}
+
+// UpdateLastLogin updates the user's last login timestamp
+func (r *SQLiteUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) error {
+	now := sqliteTime(time.Now())
+	return r.update(ctx, "update last login",
+		`UPDATE users SET last_login_at = ?, updated_at = ? WHERE id = ?`,
+		now, now, id,
+	)
+}
+
+// List retrieves users matching the given filter, ordered by creation time (newest first)
+// SQLite's LIKE is case-insensitive for ASCII, matching ILIKE for email addresses.
+func (r *SQLiteUserRepository) List(ctx context.Context, filter UserFilter) ([]*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE 1=1`
+	args := []interface{}{}
+
+	if filter.Search != "" {
+		query += ` AND email LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+	}
+	if filter.Role != "" {
+		query += " AND role = ?"
+		args = append(args, filter.Role)
+	}
+	if filter.IsActive != nil {
+		query += " AND is_active = ?"
+		args = append(args, *filter.IsActive)
+	}
+
+	if after := userKeyset.Apply(filter.After, sqliteBinder(&args)); after != "" {
+		query += " AND " + after
+	}
+
+	query += " ORDER BY " + userKeyset.OrderBy() + " LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetActive activates or deactivates a user account
+func (r *SQLiteUserRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	return r.update(ctx, "set user active status",
+		`UPDATE users SET is_active = ?, updated_at = ? WHERE id = ?`,
+		active, sqliteTime(time.Now()), id,
+	)
+}
+
+// GetUnitsPreference retrieves the units preference from the user's profile
+func (r *SQLiteUserRepository) GetUnitsPreference(ctx context.Context, id uuid.UUID) (string, error) {
+	var preference sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT units_preference FROM user_profiles WHERE user_id = ?`, id).Scan(&preference)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get units preference: %w", err)
+	}
+
+	return preference.String, nil
+}
+
+// GetPrivacyTrimMeters retrieves the privacy trim from the user's profile
+func (r *SQLiteUserRepository) GetPrivacyTrimMeters(ctx context.Context, id uuid.UUID) (int, error) {
+	var meters int
+	err := r.db.QueryRowContext(ctx, `SELECT privacy_trim_meters FROM user_profiles WHERE user_id = ?`, id).Scan(&meters)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get privacy trim: %w", err)
+	}
+
+	return meters, nil
+}
+
+// SetPrivacyTrimMeters sets the privacy trim, creating the profile if needed
+func (r *SQLiteUserRepository) SetPrivacyTrimMeters(ctx context.Context, id uuid.UUID, meters int) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, privacy_trim_meters)
+		VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET privacy_trim_meters = EXCLUDED.privacy_trim_meters
+	`, id, meters)
+	if err != nil {
+		return fmt.Errorf("failed to set privacy trim: %w", err)
+	}
+
+	return nil
+}
+
+// GetTimezone retrieves the time zone from the user's profile
+func (r *SQLiteUserRepository) GetTimezone(ctx context.Context, id uuid.UUID) (string, error) {
+	var timezone sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT timezone FROM user_profiles WHERE user_id = ?`, id).Scan(&timezone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get timezone: %w", err)
+	}
+
+	return timezone.String, nil
+}
+
+// SetTimezone sets the time zone, creating the profile if needed
+func (r *SQLiteUserRepository) SetTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, timezone)
+		VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET timezone = EXCLUDED.timezone
+	`, id, timezone)
+	if err != nil {
+		return fmt.Errorf("failed to set timezone: %w", err)
+	}
+
+	return nil
+}
+
+// update runs a single-user UPDATE, returning ErrUserNotFound when no row matched
+func (r *SQLiteUserRepository) update(ctx context.Context, action, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
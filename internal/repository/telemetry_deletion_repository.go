@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// TelemetryDeletionRepository defines the interface for telemetry corrections
+type TelemetryDeletionRepository interface {
+	// Create flags the records selected by the correction as deleted and stores it
+	// Points and the recorded range of the flagged records are set on the correction, and the
+	// summaries and stats of the affected sessions are marked for recomputation.
+	// Returns ErrNoTelemetryToDelete if no visible records match.
+	Create(ctx context.Context, deletion *models.TelemetryDeletion) error
+
+	// GetByID retrieves a correction by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.TelemetryDeletion, error)
+
+	// ListByUser returns the corrections of records the user owns or requested, most recent first
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.TelemetryDeletion, error)
+
+	// Undo restores the correction's records
+	// Returns ErrTelemetryDeletionExpired if it was undone or purged, or its undo window has closed.
+	Undo(ctx context.Context, id uuid.UUID) (*models.TelemetryDeletion, error)
+
+	// ListExpired returns the IDs of corrections whose undo window closed before now and that
+	// have not been purged, oldest first
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+
+	// Purge permanently deletes the correction's records and returns how many were deleted
+	Purge(ctx context.Context, id uuid.UUID) (int64, error)
+}
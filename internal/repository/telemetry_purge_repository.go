@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// TelemetryPurgeFilter selects the telemetry a purge deletes
+type TelemetryPurgeFilter struct {
+	DeviceID string    // Empty matches every device
+	From     time.Time // Inclusive
+	To       time.Time // Exclusive
+}
+
+// TelemetryPurgeRepository permanently deletes telemetry by time range, working chunk by chunk
+type TelemetryPurgeRepository interface {
+	// Plan reports the chunks and records a purge would remove without changing anything
+	Plan(ctx context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error)
+
+	// Purge drops the chunks lying entirely within the range and deletes the matching records
+	// of the others, together with their smoothed counterparts
+	// Chunks are only dropped when no device is given, as they hold every device's telemetry.
+	Purge(ctx context.Context, filter TelemetryPurgeFilter) (*models.TelemetryPurge, error)
+}
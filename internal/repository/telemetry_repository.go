@@ -5,15 +5,100 @@ import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
 )
 
+// TelemetryFilter describes a filtered, paginated telemetry query.
+// Results are ordered by recorded_at descending (newest first), with id as a tiebreaker.
+type TelemetryFilter struct {
+	// UserID scopes the query to a single owner (required)
+	UserID uuid.UUID
+
+	// DeviceID optionally restricts results to one hardware device
+	DeviceID string
+
+	// SessionID optionally restricts results to one session
+	SessionID string
+
+	// Tag optionally restricts results to devices carrying the tag
+	Tag string
+
+	// From and To optionally bound recorded_at (inclusive)
+	From *time.Time
+	To   *time.Time
+
+	// Limit is the maximum number of rows to return
+	Limit int
+
+	// After resumes the listing after the given position (exclusive)
+	After *pagination.Cursor
+
+	// CleanOnly leaves out points flagged by the ingest quality checks
+	CleanOnly bool
+
+	// Processed returns smoothed positions and speeds, leaving out records of sessions that have
+	// not been post-processed
+	Processed bool
+}
+
+// telemetryKeyset is the order of telemetry listings
+var telemetryKeyset = pagination.Keyset{TimeColumn: "recorded_at", IDColumn: "id"}
+
+// sessionTelemetryKeyset is the chronological order of a session's telemetry
+var sessionTelemetryKeyset = pagination.Keyset{TimeColumn: "recorded_at", IDColumn: "id", Ascending: true}
+
+// DeviceOwner identifies the telemetry one owner recorded with a device
+// Telemetry recorded before a device changed owner stays with the previous owner.
+type DeviceOwner struct {
+	DeviceID string
+	UserID   uuid.UUID
+}
+
+// AggregateBucket identifies a downsampling interval backed by a continuous aggregate
+type AggregateBucket string
+
+const (
+	// Bucket1s aggregates telemetry into one-second buckets
+	Bucket1s AggregateBucket = "1s"
+	// Bucket1m aggregates telemetry into one-minute buckets
+	Bucket1m AggregateBucket = "1m"
+	// Bucket10m aggregates telemetry into ten-minute buckets
+	Bucket10m AggregateBucket = "10m"
+)
+
+// IsValid checks if the bucket is a supported aggregation interval
+func (b AggregateBucket) IsValid() bool {
+	return b == Bucket1s || b == Bucket1m || b == Bucket10m
+}
+
+// TelemetryIterator iterates over telemetry rows one at a time without loading them all into memory
+// Usage mirrors sql.Rows: call Next until it returns false, then check Err, and always Close.
+type TelemetryIterator interface {
+	// Next advances to the next row, returning false when done or on error
+	Next() bool
+
+	// Telemetry returns the current row
+	Telemetry() *models.TelemetryData
+
+	// Err returns the error, if any, encountered during iteration
+	Err() error
+
+	// Close releases the underlying resources
+	Close() error
+}
+
 // TelemetryRepository defines the interface for telemetry data access
 type TelemetryRepository interface {
 	// Save saves a single telemetry data point
+	// With deduplication enabled, a record matching a stored one is skipped and marked Duplicate.
+	// A record repeating a stored clientId and timestamp is skipped too, and gets the stored row's ID.
 	Save(ctx context.Context, data *models.TelemetryData) error
 
 	// SaveBatch saves multiple telemetry data points in a single transaction
+	// With deduplication enabled, records matching stored ones are skipped and marked Duplicate,
+	// as are records repeating a stored clientId and timestamp.
 	SaveBatch(ctx context.Context, data []*models.TelemetryData) error
 
 	// GetByTimeRange retrieves telemetry data within a time range
@@ -28,9 +113,56 @@ type TelemetryRepository interface {
 	// GetByDevice retrieves telemetry data for a specific device
 	GetByDevice(ctx context.Context, deviceID string, limit int) ([]*models.TelemetryData, error)
 
+	// IterateBySession streams a session's telemetry in chronological order
+	IterateBySession(ctx context.Context, sessionID string) (TelemetryIterator, error)
+
+	// IterateSessionPage streams up to limit of a session's telemetry records in chronological
+	// order, starting after the given position (exclusive) when set
+	IterateSessionPage(ctx context.Context, sessionID string, after *pagination.Cursor, limit int) (TelemetryIterator, error)
+
+	// Query retrieves telemetry data matching the given filter
+	Query(ctx context.Context, filter TelemetryFilter) ([]*models.TelemetryData, error)
+
+	// Aggregate retrieves downsampled telemetry matching the given filter in chronological order
+	// The filter's After cursor is ignored. CleanOnly and Processed aggregate raw rows instead of the
+	// continuous aggregates, which include flagged points and only hold raw speeds.
+	Aggregate(ctx context.Context, filter TelemetryFilter, bucket AggregateBucket) ([]*models.TelemetryBucket, error)
+
+	// Heatmap grids the located telemetry within the bounding box into Web Mercator cells sized for
+	// the zoom level, densest first, up to the filter's limit
+	// The filter's After cursor is ignored.
+	Heatmap(ctx context.Context, filter TelemetryFilter, bbox models.BoundingBox, zoom int) ([]*models.HeatmapCell, error)
+
+	// Within retrieves the located telemetry inside the area matching the given filter, ordered
+	// like Query
+	// Areas are matched on recorded positions, so the filter's Processed flag is ignored.
+	Within(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) ([]*models.TelemetryData, error)
+
+	// WithinStats summarizes the located telemetry inside the area matching the given filter
+	// The filter's Limit, After cursor and Processed flag are ignored.
+	WithinStats(ctx context.Context, filter TelemetryFilter, area models.GeoPolygon) (*models.AreaStats, error)
+
+	// LatestPositions returns the last known position of each device recorded by its owner
+	// Flagged points are skipped, and devices without a position are left out.
+	LatestPositions(ctx context.Context, devices []DeviceOwner) ([]*models.DevicePosition, error)
+
+	// PreviousRecords returns, for each record, the latest stored record of its device recorded
+	// before it and at most models.MaxDerivedGap earlier, or nil when there is none
+	// Only the fields derived channels are computed from are set. Records without a device get nil.
+	PreviousRecords(ctx context.Context, records []*models.TelemetryData) ([]*models.TelemetryData, error)
+
 	// IsBatchProcessed checks if a batch with the given ID has already been processed
 	IsBatchProcessed(ctx context.Context, batchID string) (bool, error)
 
 	// MarkBatchProcessed marks a batch as processed for idempotency
 	MarkBatchProcessed(ctx context.Context, batchID string, recordCount int, deviceID string, sessionID *string) error
+
+	// IngestStats summarizes telemetry ingested since the given time
+	IngestStats(ctx context.Context, since time.Time) (*models.IngestStats, error)
+
+	// OrphanedTelemetry summarizes telemetry without an owner per device, largest first
+	OrphanedTelemetry(ctx context.Context, limit int) ([]*models.OrphanedTelemetry, error)
+
+	// SessionTrack returns a session's path simplified with Douglas-Peucker to the given tolerance in meters
+	SessionTrack(ctx context.Context, sessionID string, toleranceMeters float64) (*models.TrackGeometry, error)
 }
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// TrackRepository defines the interface for track data access
+type TrackRepository interface {
+	// Create stores a new track
+	Create(ctx context.Context, track *models.Track) error
+
+	// GetByID retrieves a track by its UUID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Track, error)
+
+	// ListByUserID retrieves all tracks owned by a user, most recent first
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Track, error)
+}
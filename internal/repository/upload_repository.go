@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// UploadRepository defines the interface for resumable upload data access
+type UploadRepository interface {
+	// Create stores a new open upload
+	Create(ctx context.Context, upload *models.Upload) error
+
+	// GetByID retrieves an upload by its UUID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Upload, error)
+
+	// AppendChunk stores the next chunk of an open upload, returning the bytes received so far
+	// The chunk must start at the bytes received so far (ErrUploadOffsetMismatch) and stay within
+	// the declared size (ErrUploadSizeExceeded); finalized uploads return ErrUploadNotOpen.
+	AppendChunk(ctx context.Context, id uuid.UUID, offset int64, data []byte) (int64, error)
+
+	// Finalize marks a fully received open upload as pending ingestion
+	// It returns ErrUploadIncomplete if bytes are missing and ErrUploadNotOpen if already finalized.
+	Finalize(ctx context.Context, id uuid.UUID) (*models.Upload, error)
+
+	// ClaimNext marks the oldest pending upload as processing and returns it, or nil if there is none
+	// Processing uploads not updated since staleBefore are claimed again, so uploads interrupted by
+	// a restart are resumed. Concurrent callers never claim the same upload.
+	ClaimNext(ctx context.Context, staleBefore time.Time) (*models.Upload, error)
+
+	// GetChunk retrieves the chunk holding the byte at the given offset and the offset it starts at
+	// It returns ErrUploadChunkNotFound past the end of the received data.
+	GetChunk(ctx context.Context, id uuid.UUID, offset int64) (int64, []byte, error)
+
+	// UpdateProgress records the ingestion status, counters and errors of an upload
+	// Uploads moving to a terminal status get their completion time set and their chunks deleted.
+	UpdateProgress(ctx context.Context, upload *models.Upload) error
+
+	// DeleteAbandoned deletes open uploads not updated since before, returning how many were deleted
+	DeleteAbandoned(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// UsageRepository defines the interface for user plans and usage counters
+type UsageRepository interface {
+	// GetPlan returns the user's plan, or models.PlanFree if none was assigned
+	GetPlan(ctx context.Context, userID uuid.UUID) (models.Plan, error)
+
+	// SetPlan assigns a plan to the user
+	SetPlan(ctx context.Context, userID uuid.UUID, plan models.Plan) error
+
+	// GetTelemetryPoints returns the telemetry points counted for the period starting at periodStart
+	GetTelemetryPoints(ctx context.Context, userID uuid.UUID, periodStart time.Time) (int64, error)
+
+	// AddTelemetryPoints adds to the telemetry points counted for the period starting at periodStart
+	AddTelemetryPoints(ctx context.Context, userID uuid.UUID, periodStart time.Time, points int64) error
+}
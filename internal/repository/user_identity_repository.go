@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+var (
+	// ErrUserIdentityNotFound is returned when no user is linked to an identity provider account
+	ErrUserIdentityNotFound = errors.New("user identity not found")
+
+	// ErrUserIdentityExists is returned when an identity provider account is already linked to a user
+	ErrUserIdentityExists = errors.New("user identity already exists")
+)
+
+// UserIdentityRepository defines the interface for links between users and identity provider accounts
+type UserIdentityRepository interface {
+	// Create links an identity provider account to a user
+	// Returns ErrUserIdentityExists if the issuer's subject is already linked.
+	Create(ctx context.Context, identity *models.UserIdentity) error
+
+	// GetBySubject retrieves the link of the issuer's subject
+	// Returns ErrUserIdentityNotFound if the account is not linked to a user.
+	GetBySubject(ctx context.Context, issuer, subject string) (*models.UserIdentity, error)
+
+	// RecordLogin stores the email the provider reported and the time of the sign-in
+	RecordLogin(ctx context.Context, id uuid.UUID, email string) error
+}
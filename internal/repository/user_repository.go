@@ -6,8 +6,31 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/pagination"
 )
 
+// UserFilter describes a filtered, paginated user listing
+type UserFilter struct {
+	// Search optionally matches a case-insensitive substring of the email address
+	Search string
+
+	// Role optionally restricts results to one role
+	Role models.Role
+
+	// IsActive optionally restricts results by account status
+	IsActive *bool
+
+	// Limit and Offset paginate the results
+	Limit  int
+	Offset int
+
+	// After resumes the listing after the given position (exclusive)
+	After *pagination.Cursor
+}
+
+// userKeyset is the order of user listings
+var userKeyset = pagination.Keyset{TimeColumn: "created_at", IDColumn: "id"}
+
 // UserRepository defines the interface for user data access
 type UserRepository interface {
 	// Create creates a new user
@@ -40,6 +63,38 @@ type UserRepository interface {
 	// ClearResetToken clears the password reset token and expiry
 	ClearResetToken(ctx context.Context, id uuid.UUID) error
 
+	// SetMagicLinkToken sets the passwordless sign-in token and expiry, replacing any earlier one
+	SetMagicLinkToken(ctx context.Context, id uuid.UUID, token string, expiresAt time.Time) error
+
+	// ConsumeMagicLinkToken clears an unexpired sign-in token and returns its user
+	// It returns ErrUserNotFound when the token is unknown, expired or already used.
+	ConsumeMagicLinkToken(ctx context.Context, token string) (*models.User, error)
+
 	// UpdateLastLogin updates the user's last login timestamp
 	UpdateLastLogin(ctx context.Context, id uuid.UUID) error
+
+	// List retrieves users matching the given filter
+	List(ctx context.Context, filter UserFilter) ([]*models.User, error)
+
+	// SetActive activates or deactivates a user account
+	SetActive(ctx context.Context, id uuid.UUID, active bool) error
+
+	// GetUnitsPreference retrieves the units preference from the user's profile
+	// It returns an empty string when the user has no profile.
+	GetUnitsPreference(ctx context.Context, id uuid.UUID) (string, error)
+
+	// GetPrivacyTrimMeters retrieves how much of each end of a track the user hides when sharing
+	// and exporting sessions
+	// It returns 0 when the user has no profile.
+	GetPrivacyTrimMeters(ctx context.Context, id uuid.UUID) (int, error)
+
+	// SetPrivacyTrimMeters sets the privacy trim of the user's profile, creating the profile if needed
+	SetPrivacyTrimMeters(ctx context.Context, id uuid.UUID, meters int) error
+
+	// GetTimezone retrieves the IANA time zone name from the user's profile
+	// It returns an empty string when the user has no profile.
+	GetTimezone(ctx context.Context, id uuid.UUID) (string, error)
+
+	// SetTimezone sets the time zone of the user's profile, creating the profile if needed
+	SetTimezone(ctx context.Context, id uuid.UUID, timezone string) error
 }
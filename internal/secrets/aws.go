@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// secretsManagerAPI is the part of the Secrets Manager client the provider uses
+type secretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// AWSProvider reads secrets from AWS Secrets Manager
+// References are a secret name or ARN, optionally followed by #key to read one key of a JSON secret.
+type AWSProvider struct {
+	client secretsManagerAPI
+}
+
+// NewAWSProvider creates a provider using the default AWS credential chain
+// An empty region uses the region from the AWS environment or shared config.
+func NewAWSProvider(ctx context.Context, region string) (*AWSProvider, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	return &AWSProvider{client: secretsmanager.NewFromConfig(cfg)}, nil
+}
+
+// Get implements Provider.Get
+func (p *AWSProvider) Get(ctx context.Context, ref string) (string, error) {
+	name, key := splitRef(ref)
+
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	if out.SecretString == nil {
+		return "", errors.New("binary secrets are not supported")
+	}
+	if key == "" {
+		return *out.SecretString, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(*out.SecretString), &values); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	value, ok := values[key].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: key %q", ErrNotFound, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretsManager serves secret strings by name
+type fakeSecretsManager map[string]string
+
+func (f fakeSecretsManager) GetSecretValue(_ context.Context, params *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	value, ok := f[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("not found")}
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)}, nil
+}
+
+func TestAWSProvider_Get(t *testing.T) {
+	provider := &AWSProvider{client: fakeSecretsManager{
+		"avt/jwt":      "plain-secret",
+		"avt/database": `{"username":"telemetry_user","password":"db-secret"}`,
+	}}
+	ctx := context.Background()
+
+	value, err := provider.Get(ctx, "avt/jwt")
+	require.NoError(t, err)
+	assert.Equal(t, "plain-secret", value)
+
+	value, err = provider.Get(ctx, "avt/database#password")
+	require.NoError(t, err)
+	assert.Equal(t, "db-secret", value)
+
+	_, err = provider.Get(ctx, "avt/database#token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = provider.Get(ctx, "avt/jwt#password")
+	assert.Error(t, err, "keys require a JSON secret")
+
+	_, err = provider.Get(ctx, "avt/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
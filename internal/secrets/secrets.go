@@ -0,0 +1,67 @@
+// Package secrets reads secrets from AWS Secrets Manager or HashiCorp Vault and watches them for rotation.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+// ErrNotFound is returned when a referenced secret or key does not exist
+var ErrNotFound = errors.New("secret not found")
+
+// Provider reads secrets from a secret manager
+type Provider interface {
+	// Get returns the secret named by ref, written as name or name#key
+	Get(ctx context.Context, ref string) (string, error)
+}
+
+// NewProvider creates the configured provider, or returns nil when none is configured
+func NewProvider(ctx context.Context, cfg config.SecretsConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "aws":
+		return NewAWSProvider(ctx, cfg.AWSRegion)
+	case "vault":
+		return NewVaultProvider(cfg.VaultAddress, cfg.VaultToken, cfg.VaultMount), nil
+	default:
+		return nil, nil
+	}
+}
+
+// Resolve replaces the secrets of cfg that have a reference with the provider's values
+// A nil provider leaves cfg unchanged.
+func Resolve(ctx context.Context, provider Provider, cfg *config.Config) error {
+	if provider == nil {
+		return nil
+	}
+
+	refs := []struct {
+		setting string
+		ref     string
+		target  *string
+	}{
+		{"JWT_SECRET_REF", cfg.Secrets.JWTSecretRef, &cfg.Auth.JWTSecret},
+		{"DB_PASSWORD_REF", cfg.Secrets.DBPasswordRef, &cfg.Database.Password},
+		{"MAILGUN_API_KEY_REF", cfg.Secrets.MailgunKeyRef, &cfg.Email.MailgunAPIKey},
+	}
+	for _, r := range refs {
+		if r.ref == "" {
+			continue
+		}
+		value, err := provider.Get(ctx, r.ref)
+		if err != nil {
+			return fmt.Errorf("failed to read %s %q: %w", r.setting, r.ref, err)
+		}
+		*r.target = value
+	}
+	return nil
+}
+
+// splitRef splits a reference into the secret name and the optional key within it
+func splitRef(ref string) (name, key string) {
+	name, key, _ = strings.Cut(ref, "#")
+	return name, key
+}
@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+// mapProvider serves secrets from a map
+type mapProvider map[string]string
+
+func (p mapProvider) Get(_ context.Context, ref string) (string, error) {
+	value, ok := p[ref]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func TestResolve(t *testing.T) {
+	cfg := &config.Config{
+		Auth:     config.AuthConfig{JWTSecret: "from-env"},
+		Database: config.DatabaseConfig{Password: "from-env"},
+		Email:    config.EmailConfig{MailgunAPIKey: "from-env"},
+		Secrets: config.SecretsConfig{
+			JWTSecretRef:  "avt/production#jwt_secret",
+			DBPasswordRef: "avt/production#db_password",
+		},
+	}
+	provider := mapProvider{
+		"avt/production#jwt_secret":  "jwt-from-vault",
+		"avt/production#db_password": "db-from-vault",
+	}
+
+	require.NoError(t, Resolve(context.Background(), provider, cfg))
+	assert.Equal(t, "jwt-from-vault", cfg.Auth.JWTSecret)
+	assert.Equal(t, "db-from-vault", cfg.Database.Password)
+	assert.Equal(t, "from-env", cfg.Email.MailgunAPIKey, "secrets without a reference are left alone")
+
+	cfg.Secrets.MailgunKeyRef = "avt/production#missing"
+	err := Resolve(context.Background(), provider, cfg)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Contains(t, err.Error(), "MAILGUN_API_KEY_REF")
+
+	// Without a provider nothing is resolved
+	assert.NoError(t, Resolve(context.Background(), nil, cfg))
+}
+
+func TestWatcher_Check(t *testing.T) {
+	provider := mapProvider{"jwt": "first"}
+	var rotated []string
+	watcher := NewWatcher(provider, "jwt", "first", 0, func(value string) {
+		rotated = append(rotated, value)
+	})
+
+	watcher.check(context.Background())
+	assert.Empty(t, rotated, "unchanged secrets are not reported")
+
+	provider["jwt"] = "second"
+	watcher.check(context.Background())
+	watcher.check(context.Background())
+	assert.Equal(t, []string{"second"}, rotated)
+
+	// Read failures keep the current secret
+	watcher = NewWatcher(providerFunc(func(context.Context, string) (string, error) {
+		return "", errors.New("vault sealed")
+	}), "jwt", "second", 0, func(string) { t.Fatal("failed reads should not rotate the secret") })
+	watcher.check(context.Background())
+}
+
+// providerFunc adapts a function to Provider
+type providerFunc func(ctx context.Context, ref string) (string, error)
+
+func (f providerFunc) Get(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
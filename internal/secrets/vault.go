@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// vaultTimeout caps how long a single secret read may take
+const vaultTimeout = 10 * time.Second
+
+// defaultVaultKey is the key read from a Vault secret when the reference names none
+const defaultVaultKey = "value"
+
+// VaultProvider reads secrets from a HashiCorp Vault KV version 2 secrets engine
+// References are written as path#key, e.g. avt/production#jwt_secret; the key defaults to "value".
+type VaultProvider struct {
+	address    string
+	token      string
+	mount      string
+	httpClient *http.Client
+}
+
+// NewVaultProvider creates a provider reading from the KV v2 engine mounted at mount
+func NewVaultProvider(address, token, mount string) *VaultProvider {
+	return &VaultProvider{
+		address:    strings.TrimRight(address, "/"),
+		token:      token,
+		mount:      strings.Trim(mount, "/"),
+		httpClient: &http.Client{Timeout: vaultTimeout},
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for secret reads
+func (p *VaultProvider) WithHTTPClient(client *http.Client) *VaultProvider {
+	p.httpClient = client
+	return p
+}
+
+// Get implements Provider.Get
+func (p *VaultProvider) Get(ctx context.Context, ref string) (string, error) {
+	path, key := splitRef(ref)
+	if key == "" {
+		key = defaultVaultKey
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s/data/%s", p.address, url.PathEscape(p.mount), strings.Trim(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode Vault response: %w", err)
+	}
+
+	value, ok := body.Data.Data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: key %q", ErrNotFound, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider_Get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/avt/production":
+			_, _ = w.Write([]byte(`{"data":{"data":{"jwt_secret":"s3cret","value":"default"},"metadata":{"version":3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewVaultProvider(server.URL+"/", "vault-token", "/kv/")
+	ctx := context.Background()
+
+	value, err := provider.Get(ctx, "avt/production#jwt_secret")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	value, err = provider.Get(ctx, "avt/production")
+	require.NoError(t, err)
+	assert.Equal(t, "default", value)
+
+	_, err = provider.Get(ctx, "avt/production#missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = provider.Get(ctx, "avt/staging#jwt_secret")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = NewVaultProvider(server.URL, "wrong-token", "kv").Get(ctx, "avt/production#jwt_secret")
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package secrets
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Watcher re-reads a secret periodically and reports when it changes
+type Watcher struct {
+	provider Provider
+	ref      string
+	interval time.Duration
+	current  string
+	onChange func(value string)
+}
+
+// NewWatcher creates a watcher for ref, whose value is currently current
+func NewWatcher(provider Provider, ref, current string, interval time.Duration, onChange func(value string)) *Watcher {
+	return &Watcher{
+		provider: provider,
+		ref:      ref,
+		interval: interval,
+		current:  current,
+		onChange: onChange,
+	}
+}
+
+// Run checks the secret every interval until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check(ctx)
+		}
+	}
+}
+
+// check reads the secret and reports a changed value; read errors keep the current value
+func (w *Watcher) check(ctx context.Context) {
+	value, err := w.provider.Get(ctx, w.ref)
+	if err != nil {
+		slog.Error("Error reading secret", "ref", w.ref, "error", err)
+		return
+	}
+	if value == "" || value == w.current {
+		return
+	}
+
+	w.current = value
+	w.onChange(value)
+	slog.Info("Secret rotated", "ref", w.ref)
+}
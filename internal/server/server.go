@@ -3,6 +3,7 @@ package server
 
 import (
 	_ "embed"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -14,17 +15,27 @@ import (
 	mgin "github.com/ulule/limiter/v3/drivers/middleware/gin"
 	"github.com/ulule/limiter/v3/drivers/store/memory"
 
+	"github.com/sebasr/avt-service/internal/api"
 	"github.com/sebasr/avt-service/internal/auth"
+	"github.com/sebasr/avt-service/internal/cache"
 	"github.com/sebasr/avt-service/internal/config"
 	"github.com/sebasr/avt-service/internal/email"
 	"github.com/sebasr/avt-service/internal/handlers"
+	"github.com/sebasr/avt-service/internal/ingest"
 	"github.com/sebasr/avt-service/internal/middleware"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/ratelimit"
+	"github.com/sebasr/avt-service/internal/reports"
 	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/sebasr/avt-service/internal/tracing"
 )
 
 //go:embed static/reset-password.html
 var resetPasswordHTML []byte
 
+//go:embed static/dev-console.html
+var devConsoleHTML []byte
+
 // RequestIDMiddleware adds a unique request ID to each request
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -59,7 +70,7 @@ func NewRateLimitMiddleware() gin.HandlerFunc {
 	instance := limiter.New(store, rate)
 
 	// Create and return Gin middleware
-	middleware := mgin.NewMiddleware(instance)
+	middleware := mgin.NewMiddleware(instance, mgin.WithLimitReachedHandler(middleware.RateLimitReached))
 
 	return middleware
 }
@@ -70,8 +81,135 @@ type Dependencies struct {
 	TelemetryRepo    repository.TelemetryRepository
 	UserRepo         repository.UserRepository
 	RefreshTokenRepo repository.RefreshTokenRepository
+	LoginAttemptRepo repository.LoginAttemptRepository // Optional: nil disables login lockout
+	UsageRepo        repository.UsageRepository        // Optional: nil disables usage quotas
 	DeviceRepo       repository.DeviceRepository
-	EmailService     email.Service // Optional: nil if email not configured
+	SessionRepo      repository.SessionRepository             // Optional: nil disables sessions and historical imports
+	DeviceAPIKeyRepo repository.DeviceAPIKeyRepository        // Optional: nil disables device API keys
+	TrackRepo        repository.TrackRepository               // Optional: nil disables tracks and lap timing
+	CircuitRepo      repository.CircuitRepository             // Optional: nil disables nearby circuit lookup
+	GeofenceRepo     repository.GeofenceRepository            // Optional: nil disables geofences
+	AlertRuleRepo    repository.AlertRuleRepository           // Optional: nil disables alert rules
+	ImportJobRepo    repository.ImportJobRepository           // Optional: nil disables historical imports
+	OrganizationRepo repository.OrganizationRepository        // Optional: nil disables organizations and sharing
+	DeviceHealthRepo repository.DeviceHealthRepository        // Optional: nil disables the device health endpoint
+	RegistrationRepo repository.DeviceRegistrationRepository  // Optional: nil disables device pre-registration and adoption
+	NotificationRepo repository.NotificationRepository        // Optional: nil disables the notification inbox
+	PushTokenRepo    repository.PushTokenRepository           // Optional: nil disables push token registration
+	OwnershipRepo    repository.DeviceOwnershipRepository     // Optional: nil disables device ownership history
+	BackfillRepo     repository.DeviceBackfillRepository      // Optional: nil disables backfills of claimed devices' history
+	DeletionRepo     repository.TelemetryDeletionRepository   // Optional: nil disables session trims and telemetry range deletion
+	DeviceConfigRepo repository.DeviceConfigRepository        // Optional: nil disables device configuration
+	CommandRepo      repository.DeviceCommandRepository       // Optional: nil disables the device command queue
+	ReportRepo       repository.ReportRepository              // Optional: nil disables fleet reports
+	AccessTokenRepo  repository.PersonalAccessTokenRepository // Optional: nil disables personal access tokens
+	EmailService     email.Service                            // Optional: nil if email not configured
+	Notifier         handlers.Notifier                        // Optional: nil emails login alerts directly
+	GeoIP            handlers.GeoIPProvider                   // Optional: nil leaves sessions without locations
+	PasswordPolicy   *auth.PasswordPolicy                     // Optional: nil only enforces password length
+	JWTService       *auth.JWTService                         // Optional: nil creates one from Config
+	RateLimitStore   ratelimit.Store                          // Optional: defaults to an in-memory store
+	RateLimits       *RateLimits                              // Optional: nil fixes the rate limits from Config at startup
+	DenylistStore    cache.Cache                              // Optional: defaults to an in-memory store of revoked access tokens
+	NonceStore       cache.Cache                              // Optional: defaults to an in-memory store of used upload signature nonces
+	SigningSecrets   *auth.SecretBox                          // Optional: nil mints device keys without signing secrets and rejects signed uploads
+	Summarizer       handlers.SessionSummarizer               // Optional: nil disables summarizing on session end
+	PostProcessor    handlers.SessionPostProcessor            // Optional: nil disables smoothing on session end
+	Archive          handlers.TelemetryArchive                // Optional: nil disables archived telemetry queries
+	PolicyInspector  handlers.StoragePolicyInspector          // Optional: nil disables the storage policy endpoint
+	Geofences        handlers.GeofenceEvaluator               // Optional: nil disables geofence evaluation on ingest
+	HealthMonitor    handlers.HealthMonitor                   // Optional: nil disables device health tracking on ingest
+	Alerts           handlers.AlertEvaluator                  // Optional: nil disables alert rule evaluation on ingest
+	Presence         handlers.DevicePresence                  // Optional: nil disables device online/offline events
+	TelemetryWriter  handlers.TelemetryWriter                 // Optional: nil writes uploads synchronously
+	IngestPressure   handlers.IngestPressure                  // Optional: nil only sheds load when the write-behind buffer is full
+	ClockPolicy      *ingest.ClockPolicy                      // Optional: nil leaves device clock checks to TelemetryRepo
+	Importer         handlers.TelemetryImporter               // Optional: nil disables historical imports
+	Backfiller       handlers.DeviceBackfiller                // Optional: nil leaves adopted device backfills to the periodic sweep
+	ClaimBackfiller  handlers.ClaimBackfillQueue              // Optional: nil leaves requested claim backfills to the periodic sweep
+	IngestAudit      middleware.IngestAuditRecorder           // Optional: nil disables the ingest audit log
+	IngestAuditRepo  repository.IngestAuditRepository         // Optional: nil disables the ingest audit query endpoint
+	LoginEventRepo   repository.LoginEventRepository          // Optional: nil disables the login audit trail
+	PurgeRepo        repository.TelemetryPurgeRepository      // Optional: nil disables admin telemetry purges
+	IdentityRepo     repository.UserIdentityRepository        // Optional: nil disables single sign-on
+	OIDC             handlers.OIDCProvider                    // Optional: nil disables single sign-on
+	UploadRepo       repository.UploadRepository              // Optional: nil disables resumable uploads
+	Uploads          handlers.UploadNotifier                  // Optional: nil leaves completed uploads to the processor's polling
+	Reports          handlers.ReportQueue                     // Optional: nil disables fleet reports
+	Events           handlers.EventPublisher                  // Optional: nil calls session end consumers and login alerts directly
+}
+
+// RateLimits holds the per-route rate limit policies so they can be updated while serving
+type RateLimits struct {
+	login          *ratelimit.PolicyVar
+	forgotPassword *ratelimit.PolicyVar
+	ingest         *ratelimit.PolicyVar
+}
+
+// NewRateLimits creates the per-route policies from configuration
+func NewRateLimits(cfg config.RateLimitConfig) *RateLimits {
+	return &RateLimits{
+		login:          ratelimit.NewPolicyVar(ratelimit.PerMinute(cfg.LoginPerMinute, cfg.LoginBurst)),
+		forgotPassword: ratelimit.NewPolicyVar(ratelimit.PerMinute(cfg.ForgotPasswordPerMinute, cfg.ForgotPasswordBurst)),
+		ingest:         ratelimit.NewPolicyVar(ratelimit.PerMinute(cfg.IngestPerMinute, cfg.IngestBurst)),
+	}
+}
+
+// Update replaces the per-route policies; rate limiting cannot be enabled or disabled while serving
+func (r *RateLimits) Update(cfg config.RateLimitConfig) {
+	r.login.Set(ratelimit.PerMinute(cfg.LoginPerMinute, cfg.LoginBurst))
+	r.forgotPassword.Set(ratelimit.PerMinute(cfg.ForgotPasswordPerMinute, cfg.ForgotPasswordBurst))
+	r.ingest.Set(ratelimit.PerMinute(cfg.IngestPerMinute, cfg.IngestBurst))
+}
+
+// routeRateLimiters holds the per-route token bucket limiters
+type routeRateLimiters struct {
+	login          gin.HandlerFunc
+	forgotPassword gin.HandlerFunc
+	ingest         gin.HandlerFunc
+}
+
+// newRouteRateLimiters builds the per-route limiters from the policies
+// When rate limiting is disabled every limiter is a no-op.
+func newRouteRateLimiters(cfg config.RateLimitConfig, store ratelimit.Store, limits *RateLimits) routeRateLimiters {
+	if !cfg.Enabled {
+		noop := func(c *gin.Context) { c.Next() }
+		return routeRateLimiters{login: noop, forgotPassword: noop, ingest: noop}
+	}
+
+	if store == nil {
+		store = ratelimit.NewMemoryStore()
+	}
+	if limits == nil {
+		limits = NewRateLimits(cfg)
+	}
+
+	return routeRateLimiters{
+		login:          middleware.NewReloadableRateLimitMiddleware(store, "login", limits.login, middleware.KeyByIP),
+		forgotPassword: middleware.NewReloadableRateLimitMiddleware(store, "forgot-password", limits.forgotPassword, middleware.KeyByIP),
+		ingest:         middleware.NewReloadableRateLimitMiddleware(store, "ingest", limits.ingest, middleware.KeyByUserOrIP),
+	}
+}
+
+// planLimits converts a plan's configured quotas to handler limits
+func planLimits(cfg config.PlanQuotaConfig) models.PlanLimits {
+	return models.PlanLimits{
+		TelemetryPointsPerMonth: int64(cfg.TelemetryPointsPerMonth),
+		MaxDevices:              int64(cfg.MaxDevices),
+	}
+}
+
+// configureClientIP makes c.ClientIP() read forwarding headers only on requests from trusted proxies
+// Without trusted proxies it is the connection's remote address, so clients cannot pick the IP
+// that rate limits, login lockouts and refresh tokens see by sending X-Forwarded-For themselves.
+func configureClientIP(router *gin.Engine, cfg config.ServerConfig) {
+	router.ForwardedByClientIP = len(cfg.TrustedProxies) > 0
+	router.RemoteIPHeaders = cfg.ClientIPHeaders
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		// Config.Validate rejects malformed entries, so this only happens with unvalidated configurations
+		slog.Error("Invalid trusted proxies, trusting none", "error", err)
+		router.ForwardedByClientIP = false
+	}
 }
 
 // New creates a new Gin router with all routes configured
@@ -82,49 +220,195 @@ func New(deps *Dependencies) *gin.Engine {
 	// Use gin.New() instead of gin.Default() to have explicit control over middleware
 	// gin.Default() includes colored logging which contaminates HTTP responses with ANSI codes
 	router := gin.New()
+	configureClientIP(router, deps.Config.Server)
+
+	// Log requests through the structured logger; it runs first so it sees the final status of
+	// failures rendered by the problem middleware
+	if logCfg := deps.Config.Logging; logCfg.AccessLog {
+		router.Use(middleware.NewAccessLogMiddleware(middleware.AccessLog{
+			SampleRate: logCfg.AccessSampleRate,
+			SkipPaths:  []string{"/api/v1/health", "/api/v2/health"}, // Skip health check logging
+		}))
+	}
 
-	// Add recovery middleware (without colored output)
-	router.Use(gin.Recovery())
-
-	// Add logger middleware without colored output
-	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{
-		Formatter: func(_ gin.LogFormatterParams) string {
-			// Custom log format without ANSI color codes
-			return ""
-		},
-		Output:    nil,                        // Disable output to prevent any log contamination
-		SkipPaths: []string{"/api/v1/health"}, // Skip health check logging
-	}))
-
-	// Add CORS middleware for web client support
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Content-Encoding", "Authorization", "X-Request-ID", "X-Batch-ID"},
-		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
-		AllowCredentials: false,
-		MaxAge:           12 * time.Hour,
-	}))
+	// Render failures as problem details, recovering from panics
+	router.Use(middleware.NewProblemMiddleware())
+	// Pick the response format of the API version the path addresses
+	router.Use(api.Middleware())
+	router.NoRoute(middleware.NotFoundHandler)
+
+	// Add CORS middleware for browser dashboards on other origins
+	if corsCfg := deps.Config.CORS; len(corsCfg.AllowedOrigins) > 0 {
+		router.Use(cors.New(cors.Config{
+			AllowOrigins:     corsCfg.AllowedOrigins,
+			AllowMethods:     corsCfg.AllowedMethods,
+			AllowHeaders:     corsCfg.AllowedHeaders,
+			ExposeHeaders:    []string{"Content-Length", "Location", "X-Request-ID", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining"},
+			AllowCredentials: corsCfg.AllowCredentials,
+			MaxAge:           corsCfg.MaxAge,
+		}))
+	}
+
+	// Add security headers
+	if securityCfg := deps.Config.Security; securityCfg.Enabled {
+		router.Use(middleware.NewSecurityHeadersMiddleware(middleware.SecurityHeaders{
+			HSTSMaxAge:            securityCfg.HSTSMaxAge,
+			HSTSIncludeSubdomains: securityCfg.HSTSIncludeSubdomains,
+			FrameOptions:          securityCfg.FrameOptions,
+			ReferrerPolicy:        securityCfg.ReferrerPolicy,
+			ContentSecurityPolicy: securityCfg.ContentSecurityPolicy,
+		}))
+	}
 
 	// Add middlewares
 	router.Use(RequestIDMiddleware())
 	router.Use(NewRateLimitMiddleware())
 	router.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithDecompressFn(gzip.DefaultDecompressHandle)))
+	router.Use(middleware.NewTracingMiddleware(tracing.Tracer(), deps.Config.Server.DevMode))
 
 	// Initialize JWT service
-	jwtService := auth.NewJWTService(
-		deps.Config.Auth.JWTSecret,
-		deps.Config.Auth.JWTAccessTokenTTL,
-		deps.Config.Auth.JWTRefreshTokenTTL,
-	)
+	jwtService := deps.JWTService
+	if jwtService == nil {
+		jwtService = auth.NewJWTService(
+			deps.Config.Auth.JWTSecret,
+			deps.Config.Auth.JWTAccessTokenTTL,
+			deps.Config.Auth.JWTRefreshTokenTTL,
+		)
+	}
+
+	// Revoked access tokens are rejected before they expire
+	denylistStore := deps.DenylistStore
+	if denylistStore == nil {
+		denylistStore = cache.NewMemoryCache()
+	}
+	denylist := auth.NewDenylist(denylistStore, deps.Config.Auth.JWTAccessTokenTTL)
 
 	// Initialize auth middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtService)
+	authMiddleware := middleware.NewAuthMiddleware(jwtService).WithDenylist(denylist)
+	if deps.AccessTokenRepo != nil {
+		authMiddleware = authMiddleware.WithPersonalAccessTokens(deps.AccessTokenRepo, deps.UserRepo)
+	}
+	// Credentials only reach the telemetry routes their scopes grant
+	ingestAuth := authMiddleware.Optional(models.ScopeTelemetryWrite)
+	telemetryReadAuth := authMiddleware.RequireScope(models.ScopeTelemetryRead)
 	authRateLimiter := middleware.NewAuthRateLimitMiddleware()
+	deviceKeyAuth := func(c *gin.Context) { c.Next() }
+	var deviceKeys *middleware.DeviceKeyMiddleware
+	if deps.DeviceAPIKeyRepo != nil {
+		deviceKeys = middleware.NewDeviceKeyMiddleware(deps.DeviceAPIKeyRepo, deps.DeviceRepo)
+		deviceKeyAuth = deviceKeys.Authenticate()
+	}
+	limiters := newRouteRateLimiters(deps.Config.RateLimit, deps.RateLimitStore, deps.RateLimits)
+
+	// Device uploads may be HMAC-signed with the signing secret minted with their API key; devices can require it
+	nonceStore := deps.NonceStore
+	if nonceStore == nil {
+		nonceStore = cache.NewMemoryCache()
+	}
+	signatures := middleware.NewDeviceSignatureMiddleware(deviceKeys, deps.SigningSecrets, nonceStore, deps.Config.Ingest.SignatureMaxAge)
+	signed := signatures.Verify()
+
+	// Limit single and batch upload bodies; streams are bounded per line instead, so long sessions fit in one request
+	bodyLimit := middleware.NewBodyLimitMiddleware(deps.Config.Server.MaxBodyBytes)
+
+	// Uploads report their firmware version; outdated firmware is warned or rejected before authentication
+	var minFirmware *models.FirmwareVersion
+	if version, err := models.ParseFirmwareVersion(deps.Config.Ingest.MinFirmwareVersion); err == nil {
+		minFirmware = &version
+	}
+	firmware := middleware.NewFirmwareMiddleware(minFirmware, deps.Config.Ingest.RequireMinFirmware)
+
+	// Ingest routes are audited before authentication so rejected uploads are recorded too
+	ingestAudit := func(source models.IngestSource) gin.HandlerFunc {
+		if deps.IngestAudit == nil {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return middleware.NewIngestAuditMiddleware(deps.IngestAudit, source)
+	}
+
+	// Initialize usage quotas
+	var quotas *handlers.Quotas
+	if deps.UsageRepo != nil && deps.Config.Quota.Enabled {
+		quotas = handlers.NewQuotas(deps.UsageRepo, deps.DeviceRepo, handlers.QuotaPolicy{
+			Plans: map[models.Plan]models.PlanLimits{
+				models.PlanFree: planLimits(deps.Config.Quota.Free),
+				models.PlanPro:  planLimits(deps.Config.Quota.Pro),
+			},
+		})
+	}
 
 	// Initialize handlers
-	telemetryHandler := handlers.NewTelemetryHandler(deps.TelemetryRepo, deps.DeviceRepo)
-	authHandler := handlers.NewAuthHandler(deps.UserRepo, deps.RefreshTokenRepo, jwtService)
+	telemetryHandler := handlers.NewTelemetryHandler(deps.TelemetryRepo, deps.DeviceRepo).
+		WithUnitPreferences(deps.UserRepo).
+		WithStrictOwnership(deps.Config.Ingest.StrictOwnership).
+		WithLenientValidation(deps.Config.Server.LenientValidation)
+	if n := deps.Config.Server.MaxBatchRecords; n > 0 {
+		telemetryHandler = telemetryHandler.WithMaxBatchRecords(n)
+	}
+	if quotas != nil {
+		telemetryHandler = telemetryHandler.WithQuotas(quotas)
+	}
+	if deps.Geofences != nil {
+		telemetryHandler = telemetryHandler.WithGeofenceEvaluator(deps.Geofences)
+	}
+	if deps.HealthMonitor != nil {
+		telemetryHandler = telemetryHandler.WithHealthMonitor(deps.HealthMonitor)
+	}
+	if deps.Alerts != nil {
+		telemetryHandler = telemetryHandler.WithAlertEvaluator(deps.Alerts)
+	}
+	if deps.Presence != nil {
+		telemetryHandler = telemetryHandler.WithPresence(deps.Presence)
+	}
+	if deps.TelemetryWriter != nil {
+		telemetryHandler = telemetryHandler.WithWriter(deps.TelemetryWriter)
+	}
+	if deps.IngestPressure != nil {
+		telemetryHandler = telemetryHandler.WithPressure(deps.IngestPressure)
+	}
+	if deps.ClockPolicy != nil {
+		telemetryHandler = telemetryHandler.WithClockPolicy(deps.ClockPolicy)
+	}
+	if deps.UploadRepo != nil {
+		telemetryHandler = telemetryHandler.WithUploads(deps.UploadRepo, deps.Uploads)
+	}
+	if deps.Archive != nil {
+		telemetryHandler = telemetryHandler.WithArchive(deps.Archive)
+	}
+	authHandler := handlers.NewAuthHandler(deps.UserRepo, deps.RefreshTokenRepo, jwtService).
+		WithPasswordPolicy(deps.PasswordPolicy).
+		WithDenylist(denylist).
+		WithSessionPolicy(handlers.SessionPolicy{
+			TTL:           deps.Config.Auth.JWTRefreshTokenTTL,
+			RememberMeTTL: deps.Config.Auth.RememberMeTTL,
+			MaxAge:        deps.Config.Auth.SessionMaxAge,
+			Binding:       deps.Config.Auth.RefreshTokenBinding,
+		})
+
+	if deps.GeoIP != nil {
+		authHandler = authHandler.WithGeoIP(deps.GeoIP)
+	}
+	if deps.Notifier != nil {
+		authHandler = authHandler.WithNotifier(deps.Notifier)
+	}
+
+	if deps.LoginAttemptRepo != nil && deps.Config.Lockout.Enabled {
+		authHandler = authHandler.WithLockout(deps.LoginAttemptRepo, handlers.LockoutPolicy{
+			MaxAttempts:      deps.Config.Lockout.MaxAttempts,
+			MaxAttemptsPerIP: deps.Config.Lockout.MaxAttemptsPerIP,
+			Window:           deps.Config.Lockout.Window,
+			Duration:         deps.Config.Lockout.Duration,
+		})
+	}
+
+	if deps.LoginEventRepo != nil {
+		authHandler = authHandler.WithLoginEvents(deps.LoginEventRepo)
+	}
+	if deps.OIDC != nil && deps.IdentityRepo != nil {
+		// The mapping was checked when the configuration was validated
+		roles, _ := deps.Config.OIDC.ParseRoleMapping()
+		authHandler = authHandler.WithOIDC(deps.OIDC, deps.IdentityRepo, roles)
+	}
 
 	// Configure email service if available
 	if deps.EmailService != nil {
@@ -132,24 +416,207 @@ func New(deps *Dependencies) *gin.Engine {
 		if deps.Config.Email.ResetTokenTTL > 0 {
 			authHandler = authHandler.WithResetTokenTTL(deps.Config.Email.ResetTokenTTL)
 		}
+		if deps.Config.Email.MagicLinkTTL > 0 {
+			authHandler = authHandler.WithMagicLinkTTL(deps.Config.Email.MagicLinkTTL)
+		}
 	}
 
 	userHandler := handlers.NewUserHandler(deps.UserRepo).
-		WithRefreshTokenRepo(deps.RefreshTokenRepo)
+		WithRefreshTokenRepo(deps.RefreshTokenRepo).
+		WithDenylist(denylist).
+		WithPasswordPolicy(deps.PasswordPolicy)
 
 	// Configure email service for user handler if available
 	if deps.EmailService != nil {
 		userHandler = userHandler.WithEmailService(deps.EmailService)
 	}
+	if quotas != nil {
+		userHandler = userHandler.WithQuotas(quotas)
+	}
+	if deps.PushTokenRepo != nil {
+		userHandler = userHandler.WithPushTokens(deps.PushTokenRepo)
+	}
+	if deps.AccessTokenRepo != nil {
+		userHandler = userHandler.WithPersonalAccessTokens(deps.AccessTokenRepo)
+	}
+	if deps.LoginEventRepo != nil {
+		userHandler = userHandler.WithLoginEvents(deps.LoginEventRepo)
+	}
 
 	deviceHandler := handlers.NewDeviceHandler(deps.DeviceRepo)
+	if deps.DeviceHealthRepo != nil {
+		deviceHandler = deviceHandler.WithHealthRepo(deps.DeviceHealthRepo)
+	}
+	if deps.Presence != nil {
+		deviceHandler = deviceHandler.WithPresence(deps.Presence)
+	}
+	if quotas != nil {
+		deviceHandler = deviceHandler.WithQuotas(quotas)
+	}
+	adminHandler := handlers.NewAdminHandler(deps.UserRepo, deps.DeviceRepo, deps.TelemetryRepo).
+		WithRefreshTokenRepo(deps.RefreshTokenRepo).
+		WithDenylist(denylist).
+		WithMinFirmwareVersion(minFirmware)
+	if deps.PolicyInspector != nil {
+		adminHandler = adminHandler.WithPolicyInspector(deps.PolicyInspector)
+	}
+	if quotas != nil {
+		adminHandler = adminHandler.WithUsageRepo(deps.UsageRepo)
+	}
+	if deps.IngestAuditRepo != nil {
+		adminHandler = adminHandler.WithIngestAuditRepo(deps.IngestAuditRepo)
+	}
+	if deps.LoginEventRepo != nil {
+		adminHandler = adminHandler.WithLoginEventRepo(deps.LoginEventRepo)
+	}
+	if deps.PurgeRepo != nil {
+		adminHandler = adminHandler.WithTelemetryPurgeRepo(deps.PurgeRepo)
+	}
+	var deviceKeyHandler *handlers.DeviceKeyHandler
+	if deps.DeviceAPIKeyRepo != nil {
+		deviceKeyHandler = handlers.NewDeviceKeyHandler(deps.DeviceAPIKeyRepo, deps.DeviceRepo)
+		if deps.SigningSecrets != nil {
+			deviceKeyHandler = deviceKeyHandler.WithSigningSecrets(deps.SigningSecrets)
+		}
+	}
+	var sessionHandler *handlers.SessionHandler
+	if deps.SessionRepo != nil {
+		sessionHandler = handlers.NewSessionHandler(deps.SessionRepo, deps.DeviceRepo).
+			WithTelemetryRepo(deps.TelemetryRepo).
+			WithUnitPreferences(deps.UserRepo).
+			WithPrivacyDefaults(deps.UserRepo).
+			WithTimezones(deps.UserRepo)
+		if deps.TrackRepo != nil {
+			sessionHandler = sessionHandler.WithTrackRepo(deps.TrackRepo)
+		}
+		if deps.Summarizer != nil {
+			sessionHandler = sessionHandler.WithSummarizer(deps.Summarizer)
+		}
+		if deps.PostProcessor != nil {
+			sessionHandler = sessionHandler.WithPostProcessor(deps.PostProcessor)
+		}
+	}
+	var trackHandler *handlers.TrackHandler
+	if deps.TrackRepo != nil {
+		trackHandler = handlers.NewTrackHandler(deps.TrackRepo)
+		if deps.CircuitRepo != nil {
+			trackHandler = trackHandler.WithCircuitRepo(deps.CircuitRepo)
+		}
+	}
+	var geofenceHandler *handlers.GeofenceHandler
+	if deps.GeofenceRepo != nil {
+		geofenceHandler = handlers.NewGeofenceHandler(deps.GeofenceRepo)
+	}
+	var alertRuleHandler *handlers.AlertRuleHandler
+	if deps.AlertRuleRepo != nil {
+		alertRuleHandler = handlers.NewAlertRuleHandler(deps.AlertRuleRepo)
+	}
+	var notificationHandler *handlers.NotificationHandler
+	if deps.NotificationRepo != nil {
+		notificationHandler = handlers.NewNotificationHandler(deps.NotificationRepo)
+	}
+	var registrationHandler *handlers.DeviceRegistrationHandler
+	if deps.RegistrationRepo != nil {
+		registrationHandler = handlers.NewDeviceRegistrationHandler(deps.RegistrationRepo)
+		if deps.Backfiller != nil {
+			registrationHandler = registrationHandler.WithBackfiller(deps.Backfiller)
+		}
+		if quotas != nil {
+			registrationHandler = registrationHandler.WithQuotas(quotas)
+		}
+	}
+	// Every path that gives a device a new owner records it in the ownership history
+	if deps.OwnershipRepo != nil {
+		telemetryHandler = telemetryHandler.WithOwnershipHistory(deps.OwnershipRepo)
+		deviceHandler = deviceHandler.WithOwnershipHistory(deps.OwnershipRepo)
+		adminHandler = adminHandler.WithOwnershipHistory(deps.OwnershipRepo)
+		if registrationHandler != nil {
+			registrationHandler = registrationHandler.WithOwnershipHistory(deps.OwnershipRepo)
+		}
+	}
+	// Ingest, auth, device and session handlers publish domain events to the bus's subscribers
+	if deps.Events != nil {
+		telemetryHandler = telemetryHandler.WithEvents(deps.Events)
+		if deps.Config.Stream.Enabled() {
+			// Streamed batches carry their records so consumers need not fetch them from the API
+			telemetryHandler = telemetryHandler.WithEventTelemetry()
+		}
+		authHandler = authHandler.WithEvents(deps.Events)
+		deviceHandler = deviceHandler.WithEvents(deps.Events)
+		if registrationHandler != nil {
+			registrationHandler = registrationHandler.WithEvents(deps.Events)
+		}
+		if sessionHandler != nil {
+			sessionHandler = sessionHandler.WithEvents(deps.Events)
+		}
+	}
+	if deps.BackfillRepo != nil {
+		deviceHandler = deviceHandler.WithBackfills(deps.BackfillRepo, deps.ClaimBackfiller)
+	}
+	if deps.DeviceConfigRepo != nil {
+		deviceHandler = deviceHandler.WithConfigRepo(deps.DeviceConfigRepo)
+	}
+	if deps.CommandRepo != nil {
+		deviceHandler = deviceHandler.WithCommandRepo(deps.CommandRepo)
+	}
+	// Trimmed and deleted telemetry is hidden at once and can be restored until the undo window closes
+	var deletionHandler *handlers.TelemetryDeletionHandler
+	if deps.DeletionRepo != nil {
+		deletionHandler = handlers.NewTelemetryDeletionHandler(deps.DeletionRepo)
+		deviceHandler = deviceHandler.WithTelemetryDeletions(deps.DeletionRepo, deps.Config.Workers.TelemetryUndoWindow)
+		if sessionHandler != nil {
+			sessionHandler = sessionHandler.WithTelemetryDeletions(deps.DeletionRepo, deps.Config.Workers.TelemetryUndoWindow)
+		}
+	}
+	var importHandler *handlers.ImportHandler
+	if deps.ImportJobRepo != nil && deps.SessionRepo != nil {
+		importHandler = handlers.NewImportHandler(deps.ImportJobRepo, deps.SessionRepo, deps.DeviceRepo)
+		if deps.Importer != nil {
+			importHandler = importHandler.WithImporter(deps.Importer)
+		}
+		if quotas != nil {
+			importHandler = importHandler.WithQuotas(quotas)
+		}
+	}
+
+	// Fleet reports are generated in the background and downloaded through signed URLs
+	var reportHandler *handlers.ReportHandler
+	if deps.ReportRepo != nil && deps.Reports != nil {
+		reportHandler = handlers.NewReportHandler(deps.ReportRepo, deps.Reports, reports.NewSigner(jwtService),
+			deps.Config.Reports.RetainFor, deps.Config.Reports.URLTTL)
+	}
+
+	// Organizations share devices and sessions between their members
+	var orgHandler *handlers.OrganizationHandler
+	if deps.OrganizationRepo != nil {
+		orgHandler = handlers.NewOrganizationHandler(deps.OrganizationRepo, deps.DeviceRepo)
+		if deps.EmailService != nil {
+			orgHandler = orgHandler.WithEmailService(deps.EmailService)
+		}
+		telemetryHandler = telemetryHandler.WithOrganizations(deps.OrganizationRepo)
+		deviceHandler = deviceHandler.WithOrganizations(deps.OrganizationRepo)
+		if deviceKeyHandler != nil {
+			deviceKeyHandler = deviceKeyHandler.WithOrganizations(deps.OrganizationRepo)
+		}
+		if sessionHandler != nil {
+			sessionHandler = sessionHandler.WithOrganizations(deps.OrganizationRepo)
+		}
+		if importHandler != nil {
+			importHandler = importHandler.WithOrganizations(deps.OrganizationRepo)
+		}
+		if reportHandler != nil {
+			reportHandler = reportHandler.WithOrganizations(deps.OrganizationRepo)
+		}
+	}
+
+	// API routes, mounted once per version
+	// Every version shares the handlers and middleware; only the serializer rendering the responses differs.
+	for _, version := range api.Versions {
+		routes := router.Group(version.Path())
 
-	// API v1 routes
-	v1 := router.Group("/api/v1")
-	{
 		// Health check endpoint for network quality detection
-		v1.GET("/health", func(c *gin.Context) {
-			c.PureJSON(http.StatusOK, gin.H{
+		routes.GET("/health", func(c *gin.Context) {
+			api.Respond(c, http.StatusOK, gin.H{
 				"status":    "healthy",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 				"version":   "1.0.0",
@@ -157,50 +624,291 @@ func New(deps *Dependencies) *gin.Engine {
 		})
 
 		// Auth routes (with stricter rate limiting)
-		authGroup := v1.Group("/auth")
+		authGroup := routes.Group("/auth")
 		authGroup.Use(authRateLimiter)
 		{
 			authGroup.POST("/register", authHandler.Register)
-			authGroup.POST("/login", authHandler.Login)
+			authGroup.POST("/login", limiters.login, authHandler.Login)
 			authGroup.POST("/refresh", authHandler.RefreshToken)
 			authGroup.POST("/logout", authHandler.Logout)
-			authGroup.POST("/forgot-password", authHandler.ForgotPassword)
+			authGroup.POST("/forgot-password", limiters.forgotPassword, authHandler.ForgotPassword)
 			authGroup.POST("/reset-password", authHandler.ResetPassword)
+			// Magic links send email too, so they share the forgot-password limit
+			authGroup.POST("/magic-link", limiters.forgotPassword, authHandler.RequestMagicLink)
+			authGroup.POST("/magic-login", authHandler.MagicLogin)
+			authGroup.GET("/oidc/authorize", authHandler.OIDCAuthorize)
+			authGroup.POST("/oidc/callback", authHandler.OIDCCallback)
 		}
 
 		// Telemetry routes (optional auth for backward compatibility)
-		v1.POST("/telemetry", authMiddleware.Optional(), telemetryHandler.HandlePost)
-		v1.POST("/telemetry/batch", authMiddleware.Optional(), telemetryHandler.HandleBatchPost)
+		// Devices may authenticate with X-Device-Key instead of a user JWT, and scripts with a personal access token
+		routes.POST("/telemetry", ingestAudit(models.IngestSourceSingle), bodyLimit, firmware, ingestAuth, deviceKeyAuth, limiters.ingest, signed, telemetryHandler.HandlePost)
+		routes.POST("/telemetry/batch", ingestAudit(models.IngestSourceBatch), bodyLimit, firmware, ingestAuth, deviceKeyAuth, limiters.ingest, signed, telemetryHandler.HandleBatchPost)
+		routes.POST("/telemetry/stream", ingestAudit(models.IngestSourceStream), firmware, ingestAuth, deviceKeyAuth, limiters.ingest, signatures.RejectRequired(), telemetryHandler.HandleStream)
+		routes.GET("/telemetry", telemetryReadAuth, telemetryHandler.HandleQuery)
+		routes.GET("/telemetry/aggregate", telemetryReadAuth, telemetryHandler.HandleAggregate)
+		routes.GET("/telemetry/heatmap", telemetryReadAuth, telemetryHandler.HandleHeatmap)
+		routes.GET("/telemetry/within", telemetryReadAuth, telemetryHandler.HandleWithin)
+		routes.GET("/telemetry/archive", telemetryReadAuth, telemetryHandler.HandleArchiveQuery)
+		if deletionHandler != nil {
+			routes.GET("/telemetry/deletions", authMiddleware.Required(), deletionHandler.ListTelemetryDeletions)
+			routes.POST("/telemetry/deletions/:id/undo", authMiddleware.Required(), deletionHandler.UndoTelemetryDeletion)
+		}
+		routes.GET("/ingest/status", telemetryHandler.HandleIngestStatus)
+
+		// Resumable uploads accept device keys like the other ingest endpoints; chunks can be signed
+		routes.POST("/uploads", firmware, ingestAuth, deviceKeyAuth, limiters.ingest, telemetryHandler.CreateUpload)
+		routes.GET("/uploads/:id", ingestAuth, deviceKeyAuth, telemetryHandler.GetUpload)
+		routes.PUT("/uploads/:id", bodyLimit, firmware, ingestAuth, deviceKeyAuth, limiters.ingest, signed, telemetryHandler.UploadChunk)
+		routes.POST("/uploads/:id/complete", firmware, ingestAuth, deviceKeyAuth, limiters.ingest, telemetryHandler.CompleteUpload)
 
 		// Protected user routes
-		users := v1.Group("/users")
+		users := routes.Group("/users")
 		users.Use(authMiddleware.Required())
 		{
 			users.GET("/me", userHandler.GetProfile)
 			users.PATCH("/me", userHandler.UpdateProfile)
 			users.POST("/me/change-password", userHandler.ChangePassword)
+			users.GET("/me/sessions", userHandler.ListSessions)
+			users.GET("/me/usage", userHandler.GetUsage)
+			users.GET("/me/activity", userHandler.GetActivity)
+			users.DELETE("/me/sessions/:id", userHandler.RevokeSession)
+			users.POST("/me/push-tokens", userHandler.RegisterPushToken)
+			users.GET("/me/push-tokens", userHandler.ListPushTokens)
+			users.DELETE("/me/push-tokens/:id", userHandler.DeletePushToken)
+			users.POST("/me/tokens", userHandler.CreateAccessToken)
+			users.GET("/me/tokens", userHandler.ListAccessTokens)
+			users.DELETE("/me/tokens/:id", userHandler.RevokeAccessToken)
 		}
 
 		// Protected device routes
-		devices := v1.Group("/devices")
-		devices.Use(authMiddleware.Required())
+		devices := routes.Group("/devices")
+		devices.Use(authMiddleware.RequireScope(models.ScopeDevicesManage))
 		{
 			devices.GET("", deviceHandler.ListDevices)
+			devices.POST("/bulk", deviceHandler.RegisterDevices)
+			devices.GET("/events", deviceHandler.StreamDeviceEvents)
+			devices.GET("/tags", deviceHandler.ListTags)
+			devices.GET("/positions", telemetryHandler.HandleDevicePositions)
 			devices.GET("/:id", deviceHandler.GetDevice)
+			devices.GET("/:id/health", deviceHandler.GetDeviceHealth)
+			if deps.OwnershipRepo != nil {
+				devices.GET("/:id/history", deviceHandler.GetDeviceHistory)
+			}
+			if deps.BackfillRepo != nil {
+				devices.POST("/:id/backfill", deviceHandler.BackfillDevice)
+				devices.GET("/:id/backfill", deviceHandler.GetDeviceBackfill)
+			}
+			if deps.DeletionRepo != nil {
+				devices.POST("/:id/telemetry/deletions", deviceHandler.DeleteTelemetryRange)
+			}
+			if deps.DeviceConfigRepo != nil {
+				devices.PUT("/:id/config", deviceHandler.UpdateDeviceConfig)
+			}
+			if deps.CommandRepo != nil {
+				devices.POST("/:id/commands", deviceHandler.CreateDeviceCommand)
+				devices.GET("/:id/commands/:commandId", deviceHandler.GetDeviceCommand)
+				devices.DELETE("/:id/commands/:commandId", deviceHandler.CancelDeviceCommand)
+			}
 			devices.PATCH("/:id", deviceHandler.UpdateDevice)
 			devices.DELETE("/:id", deviceHandler.DeactivateDevice)
+			devices.PUT("/:id/tags", deviceHandler.SetDeviceTags)
+			devices.POST("/:id/tags", deviceHandler.AddDeviceTag)
+			devices.DELETE("/:id/tags/:tag", deviceHandler.RemoveDeviceTag)
+			if deviceKeyHandler != nil {
+				devices.POST("/:id/keys", deviceKeyHandler.CreateKey)
+				devices.GET("/:id/keys", deviceKeyHandler.ListKeys)
+				devices.DELETE("/:id/keys/:keyId", deviceKeyHandler.RevokeKey)
+			}
+			if orgHandler != nil {
+				devices.PUT("/:id/organization", orgHandler.SetDeviceOrganization)
+			}
+			if registrationHandler != nil {
+				devices.POST("/:id/adopt", registrationHandler.AdoptDevice)
+			}
+		}
+
+		// Device telemetry needs the telemetry read scope rather than device management
+		routes.GET("/devices/:id/telemetry", telemetryReadAuth, telemetryHandler.HandleDeviceTelemetry)
+
+		// Devices poll their configuration with their API key; owners can read it with a bearer token
+		if deps.DeviceConfigRepo != nil {
+			routes.GET("/devices/:id/config", authMiddleware.Optional(), deviceKeyAuth, deviceHandler.GetDeviceConfig)
+		}
+
+		// Devices poll their queued commands and acknowledge them with their API key; owners can list them with a bearer token
+		if deps.CommandRepo != nil {
+			routes.GET("/devices/:id/commands", authMiddleware.Optional(), deviceKeyAuth, deviceHandler.ListDeviceCommands)
+			routes.POST("/devices/:id/commands/:commandId/ack", deviceKeyAuth, deviceHandler.AcknowledgeDeviceCommand)
+		}
+
+		// Devices without telemetry to upload, such as those without a GPS fix, report their status with their API key
+		if deps.DeviceAPIKeyRepo != nil {
+			routes.POST("/devices/:id/heartbeat", bodyLimit, firmware, deviceKeyAuth, limiters.ingest, signed, telemetryHandler.HandleHeartbeat)
+		}
+
+		// Anonymous device pre-registration; the device shows the returned claim code to its future owner
+		if registrationHandler != nil {
+			routes.POST("/devices/register", authRateLimiter, registrationHandler.RegisterDevice)
+		}
+
+		// Protected track routes
+		if trackHandler != nil {
+			tracks := routes.Group("/tracks")
+			tracks.Use(authMiddleware.Required())
+			{
+				tracks.POST("", trackHandler.CreateTrack)
+				tracks.GET("", trackHandler.ListTracks)
+				tracks.GET("/nearby", trackHandler.NearbyTracks)
+			}
+		}
+
+		// Protected geofence routes
+		if geofenceHandler != nil {
+			geofences := routes.Group("/geofences")
+			geofences.Use(authMiddleware.Required())
+			{
+				geofences.POST("", geofenceHandler.CreateGeofence)
+				geofences.GET("", geofenceHandler.ListGeofences)
+				geofences.GET("/events", geofenceHandler.ListGeofenceEvents)
+				geofences.DELETE("/:id", geofenceHandler.DeleteGeofence)
+			}
+		}
+
+		// Protected alert rule routes
+		if alertRuleHandler != nil {
+			alertRules := routes.Group("/alert-rules")
+			alertRules.Use(authMiddleware.Required())
+			{
+				alertRules.POST("", alertRuleHandler.CreateAlertRule)
+				alertRules.GET("", alertRuleHandler.ListAlertRules)
+				alertRules.GET("/events", alertRuleHandler.ListAlertEvents)
+				alertRules.GET("/:id", alertRuleHandler.GetAlertRule)
+				alertRules.PATCH("/:id", alertRuleHandler.UpdateAlertRule)
+				alertRules.DELETE("/:id", alertRuleHandler.DeleteAlertRule)
+			}
+		}
+
+		// Protected notification routes
+		if notificationHandler != nil {
+			notifications := routes.Group("/notifications")
+			notifications.Use(authMiddleware.Required())
+			{
+				notifications.GET("", notificationHandler.ListNotifications)
+				notifications.POST("/read-all", notificationHandler.MarkAllNotificationsRead)
+				notifications.GET("/preferences", notificationHandler.GetPreferences)
+				notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+				notifications.POST("/:id/read", notificationHandler.MarkNotificationRead)
+			}
+		}
+
+		// Protected session routes
+		if sessionHandler != nil {
+			sessions := routes.Group("/sessions")
+			sessions.Use(authMiddleware.Required())
+			{
+				sessions.POST("", sessionHandler.CreateSession)
+				sessions.GET("", sessionHandler.ListSessions)
+				sessions.GET("/compare", sessionHandler.CompareSessions)
+				sessions.GET("/:id", sessionHandler.GetSession)
+				sessions.PATCH("/:id", sessionHandler.UpdateSession)
+				sessions.GET("/:id/summary", sessionHandler.GetSessionSummary)
+				sessions.GET("/:id/stats", sessionHandler.GetSessionStats)
+				sessions.GET("/:id/export", sessionHandler.ExportSession)
+				sessions.GET("/:id/replay", sessionHandler.ReplaySession)
+				sessions.GET("/:id/track.geojson", sessionHandler.GetSessionTrack)
+				sessions.GET("/:id/laps", sessionHandler.GetSessionLaps)
+				sessions.POST("/:id/markers", sessionHandler.CreateSessionMarker)
+				sessions.GET("/:id/markers", sessionHandler.ListSessionMarkers)
+				sessions.DELETE("/:id/markers/:markerId", sessionHandler.DeleteSessionMarker)
+				if deps.DeletionRepo != nil {
+					sessions.POST("/:id/trim", sessionHandler.TrimSession)
+				}
+				sessions.PATCH("/:id/end", sessionHandler.EndSession)
+			}
+		}
+
+		// Protected import routes
+		if importHandler != nil {
+			imports := routes.Group("/import")
+			imports.Use(authMiddleware.Required())
+			{
+				imports.POST("/racebox-csv", importHandler.ImportRaceBoxCSV)
+				imports.GET("/jobs/:id", importHandler.GetImportJob)
+			}
+		}
+
+		// Protected organization routes
+		if orgHandler != nil {
+			orgs := routes.Group("/orgs")
+			orgs.Use(authMiddleware.Required())
+			{
+				orgs.POST("", orgHandler.CreateOrganization)
+				orgs.GET("", orgHandler.ListOrganizations)
+				orgs.GET("/:id", orgHandler.GetOrganization)
+				orgs.GET("/:id/members", orgHandler.ListMembers)
+				orgs.PUT("/:id/members/:userId", orgHandler.UpdateMemberRole)
+				orgs.DELETE("/:id/members/:userId", orgHandler.RemoveMember)
+				orgs.POST("/:id/invitations", orgHandler.CreateInvitation)
+				if reportHandler != nil {
+					orgs.POST("/:id/reports", reportHandler.CreateOrganizationReport)
+				}
+			}
+			routes.POST("/invitations/accept", authMiddleware.Required(), orgHandler.AcceptInvitation)
+		}
+
+		// Report routes; downloads are authorized by their signed URL instead of a token
+		if reportHandler != nil {
+			routes.GET("/reports/:id", authMiddleware.Required(), reportHandler.GetReport)
+			routes.GET("/reports/:id/download", reportHandler.DownloadReport)
+		}
+
+		// Admin-only routes
+		admin := routes.Group("/admin")
+		admin.Use(authMiddleware.RequireScope(models.ScopeAdmin))
+		{
+			admin.GET("/users", adminHandler.ListUsers)
+			admin.PATCH("/users/:id/deactivate", adminHandler.DeactivateUser)
+			admin.POST("/users/:id/revoke-tokens", adminHandler.RevokeUserTokens)
+			admin.PUT("/users/:id/plan", adminHandler.SetUserPlan)
+			admin.PUT("/devices/:id/owner", adminHandler.ReassignDevice)
+			admin.GET("/devices/firmware", adminHandler.GetFirmwareDistribution)
+			admin.GET("/stats/ingest", adminHandler.GetIngestStats)
+			admin.GET("/telemetry/orphans", adminHandler.GetOrphanedTelemetry)
+			admin.POST("/telemetry/purge", adminHandler.PurgeTelemetry)
+			admin.GET("/storage/policies", adminHandler.GetStoragePolicies)
+			admin.GET("/ingest/audit", adminHandler.ListIngestAudit)
+			admin.GET("/logins", adminHandler.ListLoginEvents)
+			if reportHandler != nil {
+				admin.POST("/reports", reportHandler.CreateFleetReport)
+			}
 		}
 	}
 
 	// Legacy routes (for backward compatibility)
-	router.POST("/api/telemetry", authMiddleware.Optional(), telemetryHandler.HandlePost)
-	router.POST("/api/telemetry/batch", authMiddleware.Optional(), telemetryHandler.HandleBatchPost)
+	router.POST("/api/telemetry", ingestAudit(models.IngestSourceSingle), bodyLimit, firmware, ingestAuth, deviceKeyAuth, limiters.ingest, signed, telemetryHandler.HandlePost)
+	router.POST("/api/telemetry/batch", ingestAudit(models.IngestSourceBatch), bodyLimit, firmware, ingestAuth, deviceKeyAuth, limiters.ingest, signed, telemetryHandler.HandleBatchPost)
 
-	// Development-only routes (password reset UI)
+	// Development-only routes (password reset UI and dev console); none are authenticated
 	if deps.Config.Server.DevMode {
 		router.GET("/reset-password", func(c *gin.Context) {
 			c.Data(http.StatusOK, "text/html; charset=utf-8", resetPasswordHTML)
 		})
+
+		devConsoleHandler := handlers.NewDevConsoleHandler(deps.TelemetryRepo, deps.UserRepo, deps.DeviceRepo)
+		if emails, ok := deps.EmailService.(handlers.SentEmails); ok {
+			devConsoleHandler = devConsoleHandler.WithEmails(emails)
+		}
+		router.GET("/dev", func(c *gin.Context) {
+			c.Data(http.StatusOK, "text/html; charset=utf-8", devConsoleHTML)
+		})
+		devAPI := router.Group("/dev/api")
+		{
+			devAPI.GET("/telemetry", devConsoleHandler.ListTelemetry)
+			devAPI.GET("/users", devConsoleHandler.ListUsers)
+			devAPI.GET("/devices", devConsoleHandler.ListDevices)
+			devAPI.GET("/emails", devConsoleHandler.ListEmails)
+		}
 	}
 
 	return router
@@ -2,16 +2,22 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/sebasr/avt-service/internal/auth"
 	"github.com/sebasr/avt-service/internal/config"
+	"github.com/sebasr/avt-service/internal/email"
 	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/reports"
 	"github.com/sebasr/avt-service/internal/repository"
 )
 
@@ -36,6 +42,21 @@ func newTestDeps() *Dependencies {
 		UserRepo:         &repository.MockUserRepository{},
 		RefreshTokenRepo: &repository.MockRefreshTokenRepository{},
 		DeviceRepo:       &repository.MockDeviceRepository{},
+		SessionRepo:      repository.NewMockSessionRepository(),
+		DeviceAPIKeyRepo: repository.NewMockDeviceAPIKeyRepository(),
+		TrackRepo:        repository.NewMockTrackRepository(),
+		GeofenceRepo:     repository.NewMockGeofenceRepository(),
+		ImportJobRepo:    repository.NewMockImportJobRepository(),
+		OrganizationRepo: repository.NewMockOrganizationRepository(),
+		DeviceHealthRepo: repository.NewMockDeviceHealthRepository(),
+		RegistrationRepo: repository.NewMockDeviceRegistrationRepository(),
+		OwnershipRepo:    repository.NewMockDeviceOwnershipRepository(),
+		BackfillRepo:     repository.NewMockDeviceBackfillRepository(),
+		DeviceConfigRepo: repository.NewMockDeviceConfigRepository(),
+		CommandRepo:      repository.NewMockDeviceCommandRepository(),
+		ReportRepo:       repository.NewMockReportRepository(),
+		AccessTokenRepo:  repository.NewMockPersonalAccessTokenRepository(),
+		Reports:          reports.NewGenerator(repository.NewMockReportRepository(), time.Hour),
 	}
 }
 
@@ -124,6 +145,173 @@ func TestNonExistentRoute(t *testing.T) {
 	}
 }
 
+func TestAPIV2Routes(t *testing.T) {
+	deps := newTestDeps()
+	router := New(deps)
+
+	// Successes are wrapped in the envelope
+	req, _ := http.NewRequest("GET", "/api/v2/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	data, ok := response["data"].(map[string]interface{})
+	if !ok || data["status"] != "healthy" {
+		t.Errorf("Expected health status in data, got %v", response)
+	}
+	if _, ok := response["meta"].(map[string]interface{}); !ok {
+		t.Errorf("Expected meta object, got %v", response["meta"])
+	}
+
+	// So are errors, including those raised before routing
+	req, _ = http.NewRequest("GET", "/api/v2/nonexistent", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	response = nil
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	problemBody, ok := response["error"].(map[string]interface{})
+	if !ok || problemBody["code"] != "route_not_found" {
+		t.Errorf("Expected route_not_found error, got %v", response)
+	}
+	if response["data"] != nil {
+		t.Errorf("Expected null data, got %v", response["data"])
+	}
+}
+
+func TestDeviceRegistrationRoutes(t *testing.T) {
+	deps := newTestDeps()
+	router := New(deps)
+
+	// Registration is anonymous
+	req, _ := http.NewRequest("POST", "/api/v1/devices/register", bytes.NewBufferString(`{"deviceId":"ANON-001"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// Adoption requires a user
+	req, _ = http.NewRequest("POST", "/api/v1/devices/ANON-001/adopt", bytes.NewBufferString(`{"claimCode":"ABCD"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestReportRoutes(t *testing.T) {
+	router := New(newTestDeps())
+
+	// Report status needs a token, downloads only a signature
+	for path, expected := range map[string]int{
+		"/api/v1/reports/" + uuid.NewString():               http.StatusUnauthorized,
+		"/api/v1/reports/" + uuid.NewString() + "/download": http.StatusForbidden,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != expected {
+			t.Errorf("%s: expected status %d, got %d", path, expected, w.Code)
+		}
+	}
+}
+
+func TestPostgresOnlyRoutesDisabled(t *testing.T) {
+	// The SQLite backend only provides telemetry, user, refresh token and device repositories
+	deps := newTestDeps()
+	deps.SessionRepo = nil
+	deps.DeviceAPIKeyRepo = nil
+	deps.TrackRepo = nil
+	deps.GeofenceRepo = nil
+	deps.ImportJobRepo = nil
+	deps.OrganizationRepo = nil
+	deps.DeviceHealthRepo = nil
+	deps.RegistrationRepo = nil
+	deps.OwnershipRepo = nil
+	deps.BackfillRepo = nil
+	deps.DeviceConfigRepo = nil
+	deps.CommandRepo = nil
+	deps.ReportRepo = nil
+	router := New(deps)
+
+	for _, path := range []string{"/api/v1/sessions", "/api/v1/tracks", "/api/v1/geofences", "/api/v1/import/jobs/1", "/api/v1/devices/1/keys", "/api/v1/devices/1/history", "/api/v1/devices/1/backfill", "/api/v1/devices/1/config", "/api/v1/devices/1/commands", "/api/v1/reports/1/download"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status %d, got %d", path, http.StatusNotFound, w.Code)
+		}
+	}
+
+	// Device keys are ignored rather than failing uploads
+	req, _ := http.NewRequest("POST", "/api/v1/telemetry", bytes.NewBufferString(`{"iTOW":1,"timestamp":"2026-01-01T00:00:00Z","gps":{"latitude":42.67,"longitude":23.28}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Device-Key", "unknown")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+}
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	deps := newTestDeps()
+	plain, _, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	user := &models.User{ID: uuid.New(), Email: "scripts@example.com", IsActive: true, Role: models.RoleUser}
+	tokens := repository.NewMockPersonalAccessTokenRepository()
+	tokens.GetByHashFunc = func(_ context.Context, hash string) (*models.PersonalAccessToken, error) {
+		if hash != auth.HashToken(plain) {
+			return nil, repository.ErrPersonalAccessTokenNotFound
+		}
+		return &models.PersonalAccessToken{ID: uuid.New(), UserID: user.ID, Scopes: []models.Scope{models.ScopeTelemetryRead}}, nil
+	}
+	users := repository.NewMockUserRepository()
+	users.GetByIDFunc = func(_ context.Context, _ uuid.UUID) (*models.User, error) {
+		return user, nil
+	}
+	deps.AccessTokenRepo = tokens
+	deps.UserRepo = users
+	router := New(deps)
+
+	// A read-only token queries telemetry but cannot upload it, manage tokens or reach other routes
+	tests := []struct {
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{method: "GET", path: "/api/v1/telemetry", expectedStatus: http.StatusOK},
+		{method: "POST", path: "/api/v1/telemetry", expectedStatus: http.StatusForbidden},
+		{method: "GET", path: "/api/v1/users/me/tokens", expectedStatus: http.StatusForbidden},
+		{method: "GET", path: "/api/v1/devices", expectedStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer "+plain)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.expectedStatus {
+			t.Errorf("%s %s: expected status %d, got %d: %s", tt.method, tt.path, tt.expectedStatus, w.Code, w.Body.String())
+		}
+	}
+}
+
 func TestBatchTelemetryEndpoint(t *testing.T) {
 	deps := newTestDeps()
 	router := New(deps)
@@ -270,7 +458,7 @@ func TestBatchTelemetryEndpointValidation(t *testing.T) {
 			name:           "empty batch",
 			batch:          []models.TelemetryData{},
 			expectedStatus: http.StatusBadRequest,
-			expectedError:  "Empty batch",
+			expectedError:  "empty_batch",
 		},
 		{
 			name: "missing timestamp",
@@ -282,13 +470,13 @@ func TestBatchTelemetryEndpointValidation(t *testing.T) {
 				},
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedError:  "Validation failed for record 0",
+			expectedError:  "validation_failed",
 		},
 		{
 			name:           "invalid JSON",
 			batch:          "not valid json",
 			expectedStatus: http.StatusBadRequest,
-			expectedError:  "Invalid JSON payload",
+			expectedError:  "invalid_json",
 		},
 	}
 
@@ -323,9 +511,12 @@ func TestBatchTelemetryEndpointValidation(t *testing.T) {
 				t.Fatalf("Failed to unmarshal response: %v", err)
 			}
 
-			// Check error message
-			if errMsg, ok := response["error"].(string); !ok || errMsg != tt.expectedError {
-				t.Errorf("Expected error '%s', got %v", tt.expectedError, response["error"])
+			// Check error code
+			if code, ok := response["code"].(string); !ok || code != tt.expectedError {
+				t.Errorf("Expected error '%s', got %v", tt.expectedError, response["code"])
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != "application/problem+json" {
+				t.Errorf("Expected problem+json Content-Type, got %q", contentType)
 			}
 		})
 	}
@@ -394,7 +585,285 @@ func TestBatchTelemetryEndpointLargePayload(t *testing.T) {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
 	expectedError := "Batch too large (max 1000 records)"
-	if errMsg, ok := response["error"].(string); !ok || errMsg != expectedError {
-		t.Errorf("Expected error '%s', got %v", expectedError, response["error"])
+	if detail, ok := response["detail"].(string); !ok || detail != expectedError {
+		t.Errorf("Expected error '%s', got %v", expectedError, response["detail"])
+	}
+}
+
+func TestIngestRateLimiting(t *testing.T) {
+	deps := newTestDeps()
+	deps.Config.RateLimit = config.RateLimitConfig{
+		Enabled:                 true,
+		Store:                   "memory",
+		LoginPerMinute:          10,
+		LoginBurst:              5,
+		ForgotPasswordPerMinute: 3,
+		ForgotPasswordBurst:     3,
+		IngestPerMinute:         60,
+		IngestBurst:             2,
+	}
+	router := New(deps)
+
+	body, err := json.Marshal(models.TelemetryData{
+		Timestamp: time.Now().UTC(),
+		GPS:       models.GpsData{Latitude: 42.0, Longitude: 23.0},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal telemetry: %v", err)
+	}
+
+	send := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/telemetry", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := send(); w.Code != http.StatusCreated {
+			t.Fatalf("Request %d: expected status %d, got %d", i, http.StatusCreated, w.Code)
+		}
+	}
+
+	w := send()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header on 429 response")
+	}
+}
+
+func TestIngestRateLimiting_Update(t *testing.T) {
+	deps := newTestDeps()
+	deps.Config.RateLimit = config.RateLimitConfig{
+		Enabled:                 true,
+		Store:                   "memory",
+		LoginPerMinute:          10,
+		LoginBurst:              5,
+		ForgotPasswordPerMinute: 3,
+		ForgotPasswordBurst:     3,
+		IngestPerMinute:         1,
+		IngestBurst:             1,
+	}
+	deps.RateLimits = NewRateLimits(deps.Config.RateLimit)
+	router := New(deps)
+
+	body, err := json.Marshal(models.TelemetryData{
+		Timestamp: time.Now().UTC(),
+		GPS:       models.GpsData{Latitude: 42.0, Longitude: 23.0},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal telemetry: %v", err)
+	}
+
+	send := func() int {
+		req, _ := http.NewRequest("POST", "/api/v1/telemetry", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send(); code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, code)
+	}
+	if code := send(); code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, code)
+	}
+
+	// A reloaded limit refills the bucket at the new rate
+	updated := deps.Config.RateLimit
+	updated.IngestPerMinute = 600000
+	deps.RateLimits.Update(updated)
+	time.Sleep(10 * time.Millisecond)
+
+	if code := send(); code != http.StatusCreated {
+		t.Fatalf("Expected status %d after raising the limit, got %d", http.StatusCreated, code)
+	}
+}
+
+func TestConfigureClientIP(t *testing.T) {
+	proxies := config.ServerConfig{
+		TrustedProxies:  []string{"10.0.0.0/8"},
+		ClientIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+	}
+
+	tests := []struct {
+		name         string
+		cfg          config.ServerConfig
+		remoteAddr   string
+		forwardedFor string
+		expected     string
+	}{
+		{name: "no trusted proxies ignores forwarding headers", remoteAddr: "10.0.0.5:1234", forwardedFor: "203.0.113.9", expected: "10.0.0.5"},
+		{name: "trusted proxy", cfg: proxies, remoteAddr: "10.0.0.5:1234", forwardedFor: "203.0.113.9", expected: "203.0.113.9"},
+		{name: "chain of trusted proxies", cfg: proxies, remoteAddr: "10.0.0.5:1234", forwardedFor: "203.0.113.9, 10.0.0.7", expected: "203.0.113.9"},
+		{name: "spoofed header behind a trusted proxy", cfg: proxies, remoteAddr: "10.0.0.5:1234", forwardedFor: "198.51.100.1, 203.0.113.9", expected: "203.0.113.9"},
+		{name: "untrusted client", cfg: proxies, remoteAddr: "192.0.2.1:1234", forwardedFor: "203.0.113.9", expected: "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			configureClientIP(router, tt.cfg)
+			router.GET("/ip", func(c *gin.Context) {
+				c.String(http.StatusOK, c.ClientIP())
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.expected {
+				t.Errorf("Expected client IP %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIngestRateLimiting_BehindProxy(t *testing.T) {
+	body, err := json.Marshal(models.TelemetryData{
+		Timestamp: time.Now().UTC(),
+		GPS:       models.GpsData{Latitude: 42.0, Longitude: 23.0},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal telemetry: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		expectedStatus int
+	}{
+		// Clients behind the proxy are limited separately
+		{name: "trusted proxy", trustedProxies: []string{"10.0.0.1"}, expectedStatus: http.StatusCreated},
+		// Without trusted proxies, every request comes from the proxy
+		{name: "untrusted proxy", expectedStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestDeps()
+			deps.Config.Server.TrustedProxies = tt.trustedProxies
+			deps.Config.Server.ClientIPHeaders = []string{"X-Forwarded-For"}
+			deps.Config.RateLimit = config.RateLimitConfig{
+				Enabled:                 true,
+				Store:                   "memory",
+				LoginPerMinute:          10,
+				LoginBurst:              5,
+				ForgotPasswordPerMinute: 3,
+				ForgotPasswordBurst:     3,
+				IngestPerMinute:         1,
+				IngestBurst:             1,
+			}
+			router := New(deps)
+
+			send := func(clientIP string) int {
+				req, _ := http.NewRequest("POST", "/api/v1/telemetry", bytes.NewBuffer(body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-Forwarded-For", clientIP)
+				req.RemoteAddr = "10.0.0.1:4000"
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w.Code
+			}
+
+			if code := send("203.0.113.1"); code != http.StatusCreated {
+				t.Fatalf("Expected status %d, got %d", http.StatusCreated, code)
+			}
+			if code := send("203.0.113.2"); code != tt.expectedStatus {
+				t.Errorf("Expected status %d for a second client, got %d", tt.expectedStatus, code)
+			}
+		})
+	}
+}
+
+func TestCORSAndSecurityHeaders(t *testing.T) {
+	deps := newTestDeps()
+	deps.Config.CORS = config.CORSConfig{
+		AllowedOrigins: []string{"https://dashboard.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:         time.Hour,
+	}
+	deps.Config.Security = config.SecurityHeadersConfig{
+		Enabled:      true,
+		HSTSMaxAge:   24 * time.Hour,
+		FrameOptions: "DENY",
+	}
+	router := New(deps)
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodOptions, "/api/v1/telemetry", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type, Authorization")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := preflight("https://dashboard.example.com")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d for allowed origin, got %d", http.StatusNoContent, w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://dashboard.example.com" {
+		t.Errorf("Expected allowed origin to be echoed, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "3600" {
+		t.Errorf("Expected preflight max age 3600, got %q", got)
+	}
+
+	if w := preflight("https://evil.example.com"); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for disallowed origin, got %d", http.StatusForbidden, w.Code)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("Expected X-Content-Type-Options nosniff, got %q", got)
+	}
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=86400" {
+		t.Errorf("Expected Strict-Transport-Security max-age=86400, got %q", got)
+	}
+	if got := w.Header().Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("Expected X-Frame-Options DENY, got %q", got)
+	}
+}
+
+func TestDevConsoleRoutes(t *testing.T) {
+	// The console is not registered outside development mode
+	router := New(newTestDeps())
+	for _, target := range []string{"/dev", "/dev/api/emails"} {
+		req, _ := http.NewRequest("GET", target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusNotFound, target, w.Code)
+		}
+	}
+
+	deps := newTestDeps()
+	deps.Config.Server.DevMode = true
+	deps.EmailService = email.NewConsoleService("noreply@example.com", "AVT", "http://localhost:8080")
+	router = New(deps)
+
+	req, _ := http.NewRequest("GET", "/dev", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/dev/api/emails", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"available":true`) {
+		t.Errorf("Expected console emails to be available, got %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -0,0 +1,166 @@
+// Package smoothing post-processes ended sessions into a Kalman-filtered copy of their telemetry.
+package smoothing
+
+import (
+	"math"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+const (
+	// accelerationNoise in m/s² is the process noise of the constant-velocity model, the typical
+	// unmodelled acceleration between two samples
+	// Race cars peak at about 1.5 g under braking but spend most of a lap well below half of that.
+	accelerationNoise = 5.0
+
+	// minPositionAccuracy and minSpeedAccuracy floor the reported accuracies, in meters and m/s,
+	// so a receiver claiming perfect accuracy cannot pin the filter to its measurements
+	minPositionAccuracy = 0.5
+	minSpeedAccuracy    = 0.1
+
+	// maxGap restarts the filter after a pause in the recording, e.g. a stop in the pits with the
+	// logger switched off
+	maxGap = 10 * time.Second
+
+	// initialVelocityVariance in (m/s)² reflects that the first reported velocity may be well off
+	initialVelocityVariance = 100.0
+
+	kmhPerMps = 3.6
+)
+
+// axis is a one-dimensional constant-velocity Kalman filter over position and velocity
+type axis struct {
+	pos, vel float64
+	p        [2][2]float64 // State covariance
+}
+
+// predict advances the state by dt seconds
+func (a *axis) predict(dt float64) {
+	a.pos += a.vel * dt
+
+	q := accelerationNoise * accelerationNoise
+	dt2 := dt * dt
+	p00 := a.p[0][0] + dt*(a.p[1][0]+a.p[0][1]) + dt2*a.p[1][1] + q*dt2*dt2/4
+	p01 := a.p[0][1] + dt*a.p[1][1] + q*dt2*dt/2
+	p10 := a.p[1][0] + dt*a.p[1][1] + q*dt2*dt/2
+	p11 := a.p[1][1] + q*dt2
+	a.p = [2][2]float64{{p00, p01}, {p10, p11}}
+}
+
+// update corrects the state with a position measurement of the given variance
+func (a *axis) update(measured, variance float64) {
+	residual := measured - a.pos
+	s := a.p[0][0] + variance
+	k0 := a.p[0][0] / s
+	k1 := a.p[1][0] / s
+
+	a.pos += k0 * residual
+	a.vel += k1 * residual
+	a.p = [2][2]float64{
+		{(1 - k0) * a.p[0][0], (1 - k0) * a.p[0][1]},
+		{a.p[1][0] - k1*a.p[0][0], a.p[1][1] - k1*a.p[0][1]},
+	}
+}
+
+// scalar is a one-dimensional random-walk Kalman filter
+type scalar struct {
+	value, variance float64
+}
+
+// predict lets the value drift for dt seconds
+func (s *scalar) predict(dt float64) {
+	drift := accelerationNoise * dt
+	s.variance += drift * drift
+}
+
+// update corrects the value with a measurement of the given variance
+func (s *scalar) update(measured, variance float64) {
+	k := s.variance / (s.variance + variance)
+	s.value += k * (measured - s.value)
+	s.variance *= 1 - k
+}
+
+// Smooth filters the positions and speeds of a session's telemetry, given in chronological order
+// Positions are filtered with a constant-velocity model in a local planar frame, weighted by each
+// record's horizontal accuracy. Speeds are filtered separately, weighted by the speed accuracy,
+// because the receiver's Doppler speed is far more precise than differentiated positions.
+// Records without a valid fix are skipped. Records flagged as position jumps or impossible speeds
+// only advance the filter, so their smoothed values follow the surrounding clean points.
+func Smooth(records []*models.TelemetryData) []*models.ProcessedTelemetry {
+	var (
+		out        []*models.ProcessedTelemetry
+		projection geo.Projection
+		x, y       axis
+		speed      scalar
+		last       time.Time
+		started    bool
+	)
+
+	for _, record := range records {
+		if !record.GPS.IsFixValid {
+			continue
+		}
+
+		point := geo.Point{Latitude: record.GPS.Latitude, Longitude: record.GPS.Longitude}
+		dt := record.Timestamp.Sub(last)
+		if !started || dt > maxGap || dt < 0 {
+			projection = geo.NewProjection(point)
+			x, y, speed = startAxes(record)
+			last = record.Timestamp
+			started = true
+			out = append(out, processed(record, projection, x, y, speed))
+			continue
+		}
+
+		seconds := dt.Seconds()
+		x.predict(seconds)
+		y.predict(seconds)
+		speed.predict(seconds)
+		last = record.Timestamp
+
+		if record.QualityFlags&models.QualityPositionJump == 0 {
+			px, py := projection.Project(point)
+			variance := square(max(record.GPS.HorizontalAccuracy, minPositionAccuracy))
+			x.update(px, variance)
+			y.update(py, variance)
+		}
+		if record.QualityFlags&models.QualityImpossibleSpeed == 0 {
+			speed.update(record.GPS.Speed/kmhPerMps, square(max(record.GPS.SpeedAccuracy/kmhPerMps, minSpeedAccuracy)))
+		}
+
+		out = append(out, processed(record, projection, x, y, speed))
+	}
+
+	return out
+}
+
+// startAxes initializes the filters at a record, with the velocity it reports
+func startAxes(record *models.TelemetryData) (axis, axis, scalar) {
+	positionVariance := square(max(record.GPS.HorizontalAccuracy, minPositionAccuracy))
+	mps := record.GPS.Speed / kmhPerMps
+	heading := record.GPS.Heading * math.Pi / 180
+	covariance := [2][2]float64{{positionVariance, 0}, {0, initialVelocityVariance}}
+
+	x := axis{vel: mps * math.Sin(heading), p: covariance}
+	y := axis{vel: mps * math.Cos(heading), p: covariance}
+	speed := scalar{value: mps, variance: square(max(record.GPS.SpeedAccuracy/kmhPerMps, minSpeedAccuracy))}
+	return x, y, speed
+}
+
+// processed converts the filter state back to a processed record
+func processed(record *models.TelemetryData, projection geo.Projection, x, y axis, speed scalar) *models.ProcessedTelemetry {
+	point := projection.Unproject(x.pos, y.pos)
+	return &models.ProcessedTelemetry{
+		TelemetryID: record.ID,
+		RecordedAt:  record.Timestamp,
+		Latitude:    point.Latitude,
+		Longitude:   point.Longitude,
+		Speed:       max(speed.value, 0) * kmhPerMps,
+	}
+}
+
+func square(v float64) float64 {
+	return v * v
+}
@@ -0,0 +1,110 @@
+package smoothing
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/geo"
+	"github.com/sebasr/avt-service/internal/models"
+)
+
+// straightLine builds a 10 Hz track heading north at 36 km/h, with alternating position and speed
+// noise and the given GPS accuracies
+func straightLine(n int, positionNoise, speedNoise float64) []*models.TelemetryData {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	origin := geo.NewProjection(geo.Point{Latitude: 51.5, Longitude: -0.1})
+
+	records := make([]*models.TelemetryData, n)
+	for i := range n {
+		sign := float64(1 - 2*(i%2))
+		point := origin.Unproject(sign*positionNoise, float64(i)+sign*positionNoise)
+		records[i] = &models.TelemetryData{
+			ID:        int64(i + 1),
+			Timestamp: start.Add(time.Duration(i) * 100 * time.Millisecond),
+			GPS: models.GpsData{
+				Latitude:           point.Latitude,
+				Longitude:          point.Longitude,
+				Speed:              36 + sign*speedNoise,
+				IsFixValid:         true,
+				HorizontalAccuracy: 3,
+				SpeedAccuracy:      5,
+			},
+		}
+	}
+	return records
+}
+
+// offTrack returns the largest east-west distance in meters of points from the track
+func offTrack(points []*models.ProcessedTelemetry) float64 {
+	origin := geo.NewProjection(geo.Point{Latitude: 51.5, Longitude: -0.1})
+	var worst float64
+	for _, p := range points {
+		x, _ := origin.Project(geo.Point{Latitude: p.Latitude, Longitude: p.Longitude})
+		worst = max(worst, math.Abs(x))
+	}
+	return worst
+}
+
+func TestSmooth_ReducesNoise(t *testing.T) {
+	records := straightLine(100, 2, 3)
+
+	points := Smooth(records)
+	require.Len(t, points, len(records))
+
+	for i, p := range points {
+		assert.Equal(t, records[i].ID, p.TelemetryID)
+		assert.Equal(t, records[i].Timestamp, p.RecordedAt)
+	}
+
+	// Once settled, positions hug the track and speeds the true 36 km/h
+	settled := points[20:]
+	assert.Less(t, offTrack(settled), 1.0)
+	for _, p := range settled {
+		assert.InDelta(t, 36, p.Speed, 1.5)
+	}
+}
+
+func TestSmooth_IgnoresFlaggedMeasurements(t *testing.T) {
+	records := straightLine(50, 0, 0)
+
+	jump := records[30]
+	far := geo.NewProjection(geo.Point{Latitude: 51.5, Longitude: -0.1}).Unproject(500, 30)
+	jump.GPS.Latitude, jump.GPS.Longitude = far.Latitude, far.Longitude
+	jump.GPS.Speed = 900
+	jump.QualityFlags = models.QualityPositionJump | models.QualityImpossibleSpeed
+
+	points := Smooth(records)
+	require.Len(t, points, len(records))
+
+	assert.Less(t, offTrack(points), 1.0, "flagged position should not pull the track")
+	assert.InDelta(t, 36, points[30].Speed, 1, "flagged speed should not be used")
+}
+
+func TestSmooth_SkipsInvalidFixesAndRestartsAfterGaps(t *testing.T) {
+	records := straightLine(20, 0, 0)
+	records[5].GPS.IsFixValid = false
+
+	// A stop in the pits: the second stint starts 10 minutes later, 2 km away
+	resume := geo.NewProjection(geo.Point{Latitude: 51.5, Longitude: -0.1}).Unproject(2000, 0)
+	for i, r := range records[10:] {
+		r.Timestamp = r.Timestamp.Add(10 * time.Minute)
+		point := geo.NewProjection(resume).Unproject(0, float64(i))
+		r.GPS.Latitude, r.GPS.Longitude = point.Latitude, point.Longitude
+	}
+
+	points := Smooth(records)
+	require.Len(t, points, len(records)-1)
+
+	// The first point after the gap starts over at its own measurement
+	first := points[9]
+	assert.Equal(t, records[10].ID, first.TelemetryID)
+	assert.InDelta(t, 0, geo.Distance(resume, geo.Point{Latitude: first.Latitude, Longitude: first.Longitude}), 0.01)
+}
+
+func TestSmooth_Empty(t *testing.T) {
+	assert.Empty(t, Smooth(nil))
+}
@@ -0,0 +1,126 @@
+package smoothing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// DefaultSweepInterval is how often ended sessions without processed telemetry are picked up
+	DefaultSweepInterval = 5 * time.Minute
+
+	// sweepBatchSize caps the number of sessions processed per sweep
+	sweepBatchSize = 100
+
+	// queueSize bounds the number of sessions waiting for on-demand processing
+	queueSize = 256
+)
+
+// Processor stores a smoothed copy of the telemetry of ended sessions
+// Sessions are processed when enqueued on session end and by a periodic sweep that catches
+// sessions missed by the queue, including sessions that received late uploads after processing.
+type Processor struct {
+	telemetryRepo repository.TelemetryRepository
+	processedRepo repository.ProcessedTelemetryRepository
+	sweepInterval time.Duration
+	queue         chan uuid.UUID
+}
+
+// NewProcessor creates a new smoothing processor
+func NewProcessor(telemetryRepo repository.TelemetryRepository, processedRepo repository.ProcessedTelemetryRepository, sweepInterval time.Duration) *Processor {
+	if sweepInterval <= 0 {
+		sweepInterval = DefaultSweepInterval
+	}
+
+	return &Processor{
+		telemetryRepo: telemetryRepo,
+		processedRepo: processedRepo,
+		sweepInterval: sweepInterval,
+		queue:         make(chan uuid.UUID, queueSize),
+	}
+}
+
+// Enqueue schedules a session for processing without blocking
+// If the queue is full the session is left for the next sweep.
+func (p *Processor) Enqueue(sessionID uuid.UUID) {
+	select {
+	case p.queue <- sessionID:
+	default:
+		slog.Warn("Session smoothing queue full, deferring session to next sweep", "session_id", sessionID)
+	}
+}
+
+// Process smooths the telemetry of a single session and replaces its processed telemetry
+func (p *Processor) Process(ctx context.Context, sessionID uuid.UUID) error {
+	it, err := p.telemetryRepo.IterateBySession(ctx, sessionID.String())
+	if err != nil {
+		return fmt.Errorf("failed to read session telemetry: %w", err)
+	}
+	defer func() { _ = it.Close() }()
+
+	var records []*models.TelemetryData
+	for it.Next() {
+		records = append(records, it.Telemetry())
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("failed to read session telemetry: %w", err)
+	}
+
+	return p.processedRepo.ReplaceSession(ctx, sessionID, Smooth(records))
+}
+
+// Run processes queued sessions and periodic sweeps until the context is cancelled
+func (p *Processor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.sweepInterval)
+	defer ticker.Stop()
+
+	// Catch up on sessions that ended while the service was down
+	p.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sessionID := <-p.queue:
+			p.process(ctx, sessionID)
+		case <-ticker.C:
+			p.sweep(ctx)
+		}
+	}
+}
+
+// process runs Process and logs its failure
+// Sessions deleted in the meantime are skipped silently.
+func (p *Processor) process(ctx context.Context, sessionID uuid.UUID) {
+	err := p.Process(ctx, sessionID)
+	if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		slog.Error("Error smoothing session", "session_id", sessionID, "error", err)
+	}
+}
+
+// sweep processes ended sessions whose processed telemetry is missing or stale
+func (p *Processor) sweep(ctx context.Context) {
+	ids, err := p.processedRepo.ListPending(ctx, sweepBatchSize)
+	if err != nil {
+		slog.Error("Error listing sessions pending smoothing", "error", err)
+		return
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		p.process(ctx, id)
+	}
+
+	if len(ids) > 0 {
+		slog.Info("Session smoothing: processed sessions", "count", len(ids))
+	}
+}
@@ -0,0 +1,101 @@
+package smoothing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+func TestProcessor_Process(t *testing.T) {
+	sessionID := uuid.New()
+	records := straightLine(10, 0, 0)
+
+	telemetryRepo := repository.NewMockRepository()
+	telemetryRepo.IterateBySessionFunc = func(_ context.Context, id string) (repository.TelemetryIterator, error) {
+		assert.Equal(t, sessionID.String(), id)
+		return repository.NewSliceTelemetryIterator(records), nil
+	}
+
+	var stored []*models.ProcessedTelemetry
+	processedRepo := repository.NewMockProcessedTelemetryRepository()
+	processedRepo.ReplaceSessionFunc = func(_ context.Context, id uuid.UUID, points []*models.ProcessedTelemetry) error {
+		assert.Equal(t, sessionID, id)
+		stored = points
+		return nil
+	}
+
+	processor := NewProcessor(telemetryRepo, processedRepo, time.Hour)
+	require.NoError(t, processor.Process(context.Background(), sessionID))
+	assert.Len(t, stored, len(records))
+}
+
+func TestProcessor_ProcessReadError(t *testing.T) {
+	telemetryRepo := repository.NewMockRepository()
+	telemetryRepo.IterateBySessionFunc = func(_ context.Context, _ string) (repository.TelemetryIterator, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	processedRepo := repository.NewMockProcessedTelemetryRepository()
+	processedRepo.ReplaceSessionFunc = func(_ context.Context, _ uuid.UUID, _ []*models.ProcessedTelemetry) error {
+		t.Fatal("nothing should be stored when the telemetry cannot be read")
+		return nil
+	}
+
+	processor := NewProcessor(telemetryRepo, processedRepo, time.Hour)
+	assert.Error(t, processor.Process(context.Background(), uuid.New()))
+}
+
+func TestProcessor_RunProcessesSweepAndQueue(t *testing.T) {
+	pendingID := uuid.New()
+	queuedID := uuid.New()
+
+	var mu sync.Mutex
+	processed := make(map[uuid.UUID]bool)
+	done := make(chan struct{}, 2)
+
+	processedRepo := repository.NewMockProcessedTelemetryRepository()
+	processedRepo.ListPendingFunc = func(_ context.Context, _ int) ([]uuid.UUID, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if processed[pendingID] {
+			return []uuid.UUID{}, nil
+		}
+		return []uuid.UUID{pendingID}, nil
+	}
+	processedRepo.ReplaceSessionFunc = func(_ context.Context, id uuid.UUID, _ []*models.ProcessedTelemetry) error {
+		mu.Lock()
+		processed[id] = true
+		mu.Unlock()
+		done <- struct{}{}
+		return nil
+	}
+
+	processor := NewProcessor(repository.NewMockRepository(), processedRepo, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go processor.Run(ctx)
+	processor.Enqueue(queuedID)
+
+	for range 2 {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for smoothing")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, processed[pendingID], "startup sweep should process pending sessions")
+	assert.True(t, processed[queuedID], "queued session should be processed")
+}
@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaPublisher_Publish(t *testing.T) {
+	var path, contentType, user, pass string
+	var request struct {
+		Records []struct {
+			Key   string          `json:"key"`
+			Value json.RawMessage `json:"value"`
+		} `json:"records"`
+	}
+	response := `{"offsets":[{"partition":0,"offset":12}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		user, pass, _ = r.BasicAuth()
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", kafkaAccept)
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	publisher, err := NewKafkaPublisher(strings.Replace(server.URL, "http://", "http://avt:secret@", 1) + "/")
+	require.NoError(t, err)
+
+	require.NoError(t, publisher.Publish(context.Background(), "avt.telemetry", "AVT-001", []byte(`{"id":1}`)))
+	assert.Equal(t, "/topics/avt.telemetry", path)
+	assert.Equal(t, kafkaContentType, contentType)
+	assert.Equal(t, "avt", user)
+	assert.Equal(t, "secret", pass)
+	require.Len(t, request.Records, 1)
+	assert.Equal(t, "AVT-001", request.Records[0].Key)
+	assert.JSONEq(t, `{"id":1}`, string(request.Records[0].Value))
+
+	response = `{"offsets":[{"partition":null,"offset":null,"error_code":50003,"error":"Leader not available"}]}`
+	err = publisher.Publish(context.Background(), "avt.telemetry", "AVT-001", []byte(`{"id":1}`))
+	require.Error(t, err, "records Kafka rejects fail the publish")
+	assert.Contains(t, err.Error(), "Leader not available")
+}
+
+func TestKafkaPublisher_PublishErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"error_code":40401,"message":"Topic not found."}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	publisher, err := NewKafkaPublisher(server.URL)
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), "missing", "", []byte(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 404")
+}
+
+func TestNewKafkaPublisher_InvalidURL(t *testing.T) {
+	_, err := NewKafkaPublisher("kafka:9092")
+	assert.Error(t, err)
+}
@@ -0,0 +1,202 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// natsDefaultPort is the port of nats:// URLs without one
+const natsDefaultPort = "4222"
+
+// NATSPublisher publishes messages to NATS subjects over the core client protocol
+// Every publish is followed by a PING, and returns once the server's PONG confirms it processed
+// the message. A failed publish drops the connection, and the next one reconnects.
+type NATSPublisher struct {
+	addr       string
+	tls        bool
+	user       string
+	pass       string
+	token      string
+	mu         sync.Mutex
+	conn       net.Conn
+	reader     *bufio.Reader
+	maxPayload int64
+}
+
+// natsInfo is the part of the server's INFO message the publisher uses
+type natsInfo struct {
+	TLSRequired bool  `json:"tls_required"`
+	MaxPayload  int64 `json:"max_payload"`
+}
+
+// natsConnect is the CONNECT message sent after the server's INFO
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+	Token    string `json:"auth_token,omitempty"`
+}
+
+// NewNATSPublisher creates a publisher for the NATS server at rawURL
+// tls:// URLs connect over TLS. A URL with a username and password authenticates with them, and
+// one with only a username uses it as the auth token. The connection is opened on first publish.
+func NewNATSPublisher(rawURL string) (*NATSPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", rawURL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = natsDefaultPort
+	}
+	p := &NATSPublisher{addr: net.JoinHostPort(u.Hostname(), port), tls: u.Scheme == "tls"}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			p.user, p.pass = u.User.Username(), pass
+		} else {
+			p.token = u.User.Username()
+		}
+	}
+	return p, nil
+}
+
+// Publish publishes payload to the subject topic; NATS has no partitions, so key is ignored
+func (p *NATSPublisher) Publish(ctx context.Context, topic, _ string, payload []byte) error {
+	if topic == "" || strings.ContainsAny(topic, " \t\r\n") {
+		return fmt.Errorf("invalid NATS subject %q", topic)
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if p.maxPayload > 0 && int64(len(payload)) > p.maxPayload {
+		return fmt.Errorf("message of %d bytes exceeds the NATS server's limit of %d", len(payload), p.maxPayload)
+	}
+
+	if err := p.exchange(ctx, fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", topic, len(payload), payload)); err != nil {
+		_ = p.closeConn()
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection to the server
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closeConn()
+}
+
+// connect dials the server and authenticates, confirming the CONNECT with a PING
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	line, err := p.reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		_ = p.closeConn()
+		return errors.New("failed to connect to NATS: expected INFO from server")
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "INFO "))), &info); err != nil {
+		_ = p.closeConn()
+		return fmt.Errorf("failed to decode NATS server INFO: %w", err)
+	}
+	if info.TLSRequired && !p.tls {
+		_ = p.closeConn()
+		return errors.New("the NATS server requires TLS; use a tls:// URL")
+	}
+	p.maxPayload = info.MaxPayload
+
+	if p.tls {
+		host, _, _ := net.SplitHostPort(p.addr)
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = p.closeConn()
+			return fmt.Errorf("failed TLS handshake with NATS: %w", err)
+		}
+		p.conn = tlsConn
+		p.reader = bufio.NewReader(tlsConn)
+	}
+
+	connect, err := json.Marshal(natsConnect{Name: "avt-service", Lang: "go", User: p.user, Pass: p.pass, Token: p.token})
+	if err != nil {
+		_ = p.closeConn()
+		return fmt.Errorf("failed to encode NATS CONNECT: %w", err)
+	}
+	if err := p.exchange(ctx, "CONNECT "+string(connect)+"\r\nPING\r\n"); err != nil {
+		_ = p.closeConn()
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	return nil
+}
+
+// exchange writes commands ending with a PING and waits for the server's PONG
+// Errors the server reports in the meantime, such as a failed authorization, are returned.
+func (p *NATSPublisher) exchange(ctx context.Context, commands string) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = p.conn.SetDeadline(deadline)
+	}
+	if _, err := p.conn.Write([]byte(commands)); err != nil {
+		return err
+	}
+
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+		default:
+			// +OK and asynchronous INFO updates need no reply
+		}
+	}
+}
+
+// closeConn closes and forgets the connection, if any
+func (p *NATSPublisher) closeConn() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	p.reader = nil
+	return err
+}
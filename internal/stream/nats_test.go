@@ -0,0 +1,139 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// natsMessage is a message received by fakeNATS
+type natsMessage struct {
+	subject string
+	payload string
+}
+
+// fakeNATS is a NATS server speaking enough of the protocol to accept publishes
+type fakeNATS struct {
+	listener  net.Listener
+	info      string
+	messages  chan natsMessage
+	connects  chan string
+	rejectPub atomic.Bool // Reply to publishes with -ERR instead of PONG
+}
+
+func newFakeNATS(t *testing.T, info string) *fakeNATS {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	s := &fakeNATS{listener: listener, info: info, messages: make(chan natsMessage, 10), connects: make(chan string, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeNATS) url() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+func (s *fakeNATS) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_, _ = conn.Write([]byte("INFO " + s.info + "\r\n"))
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "CONNECT":
+			s.connects <- strings.TrimSpace(strings.TrimPrefix(line, "CONNECT"))
+		case fields[0] == "PING":
+			_, _ = conn.Write([]byte("PONG\r\n"))
+		case fields[0] == "PUB" && len(fields) == 3:
+			size, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			if s.rejectPub.Load() {
+				_, _ = conn.Write([]byte("-ERR 'Permissions Violation for Publish to " + fields[1] + "'\r\n"))
+				return
+			}
+			s.messages <- natsMessage{subject: fields[1], payload: string(payload[:size])}
+		}
+	}
+}
+
+func TestNATSPublisher_Publish(t *testing.T) {
+	server := newFakeNATS(t, `{"server_id":"test","max_payload":1048576}`)
+	publisher, err := NewNATSPublisher(strings.Replace(server.url(), "nats://", "nats://avt:secret@", 1))
+	require.NoError(t, err)
+	defer func() { _ = publisher.Close() }()
+
+	require.NoError(t, publisher.Publish(context.Background(), "avt.telemetry", "AVT-001", []byte(`{"id":1}`)))
+	require.NoError(t, publisher.Publish(context.Background(), "avt.telemetry", "AVT-002", []byte(`{"id":2}`)))
+
+	assert.Contains(t, <-server.connects, `"user":"avt","pass":"secret"`)
+	assert.Len(t, server.connects, 0, "publishes share the connection")
+	assert.Equal(t, natsMessage{subject: "avt.telemetry", payload: `{"id":1}`}, <-server.messages)
+	assert.Equal(t, natsMessage{subject: "avt.telemetry", payload: `{"id":2}`}, <-server.messages)
+}
+
+func TestNATSPublisher_PublishRejected(t *testing.T) {
+	server := newFakeNATS(t, `{"server_id":"test"}`)
+	server.rejectPub.Store(true)
+	publisher, err := NewNATSPublisher(server.url())
+	require.NoError(t, err)
+	defer func() { _ = publisher.Close() }()
+
+	err = publisher.Publish(context.Background(), "avt.telemetry", "", []byte(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Permissions Violation")
+
+	server.rejectPub.Store(false)
+	assert.NoError(t, publisher.Publish(context.Background(), "avt.telemetry", "", []byte(`{}`)), "the next publish reconnects")
+}
+
+func TestNATSPublisher_PublishLimits(t *testing.T) {
+	server := newFakeNATS(t, `{"server_id":"test","max_payload":4}`)
+	publisher, err := NewNATSPublisher(server.url())
+	require.NoError(t, err)
+	defer func() { _ = publisher.Close() }()
+
+	assert.Error(t, publisher.Publish(context.Background(), "avt telemetry", "", []byte(`{}`)), "subjects cannot contain spaces")
+	assert.Error(t, publisher.Publish(context.Background(), "avt.telemetry", "", []byte(`{"id":1}`)), "payloads over the server limit are refused")
+}
+
+func TestNATSPublisher_RequiresTLS(t *testing.T) {
+	server := newFakeNATS(t, `{"server_id":"test","tls_required":true}`)
+	publisher, err := NewNATSPublisher(server.url())
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), "avt.telemetry", "", []byte(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tls://")
+}
+
+func TestNewNATSPublisher_InvalidURL(t *testing.T) {
+	_, err := NewNATSPublisher("http://nats.internal:4222")
+	assert.Error(t, err)
+}
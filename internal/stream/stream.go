@@ -0,0 +1,45 @@
+// Package stream forwards telemetry to message brokers for downstream consumers.
+package stream
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+// requestTimeout caps how long a single publish may take when the context has no deadline
+const requestTimeout = 10 * time.Second
+
+// Publisher sends messages to a topic of a message broker
+// Publish returns once the broker has accepted the message, so a nil error means it was delivered
+// to the broker at least once.
+type Publisher interface {
+	// Publish sends payload to topic; brokers that partition topics use key to keep a device's
+	// messages in order
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+
+	// Close releases the connection to the broker
+	Close() error
+}
+
+// New creates the publisher for the configured driver
+func New(cfg config.StreamConfig) (Publisher, error) {
+	switch cfg.Driver {
+	case "kafka":
+		return NewKafkaPublisher(cfg.URL)
+	case "nats":
+		return NewNATSPublisher(cfg.URL)
+	default:
+		return nil, fmt.Errorf("unknown stream driver %q", cfg.Driver)
+	}
+}
+
+// withTimeout applies requestTimeout to contexts without a deadline
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, requestTimeout)
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// repositoryPackage is the import path whose callers name query spans
+	repositoryPackage = "github.com/sebasr/avt-service/internal/repository."
+
+	// maxStatementLength caps the SQL recorded on a span
+	maxStatementLength = 2048
+
+	// maxCallerDepth bounds the stack walk looking for the calling repository method
+	maxCallerDepth = 48
+)
+
+// QueryTracer records a client span for every query run through pgx
+// Spans are named after the repository method that issued the query (for example
+// PostgresUserRepository.GetByEmail) so slow queries can be traced back to their caller.
+// Query arguments are never recorded, since they include credentials and personal data.
+type QueryTracer struct {
+	tracer trace.Tracer
+}
+
+// NewQueryTracer creates a pgx query tracer using the given tracer, or the service tracer when nil
+func NewQueryTracer(tracer trace.Tracer) *QueryTracer {
+	return &QueryTracer{tracer: tracer}
+}
+
+// TraceQueryStart implements pgx.QueryTracer.TraceQueryStart
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	tracer := t.tracer
+	if tracer == nil {
+		tracer = Tracer()
+	}
+
+	ctx, span := tracer.Start(ctx, "db.query", trace.WithSpanKind(trace.SpanKindClient))
+	if !span.IsRecording() {
+		return ctx
+	}
+
+	statement := strings.TrimSpace(data.SQL)
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength]
+	}
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", statement),
+	)
+	if caller := repositoryCaller(); caller != "" {
+		span.SetName(caller)
+		span.SetAttributes(attribute.String("code.function", caller))
+	}
+
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.TraceQueryEnd
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+		if data.Err != nil {
+			span.RecordError(data.Err)
+			span.SetStatus(codes.Error, data.Err.Error())
+		}
+	}
+	span.End()
+}
+
+// repositoryCaller returns the repository method on the current stack, such as PostgresRepository.SaveBatch
+func repositoryCaller() string {
+	pcs := make([]uintptr, maxCallerDepth)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if name, ok := strings.CutPrefix(frame.Function, repositoryPackage); ok {
+			// Methods are reported as (*Type).Method, and closures as Method.func1
+			name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+			if i := strings.Index(name, ".func"); i > 0 {
+				name = name[:i]
+			}
+			return name
+		}
+		if !more {
+			return ""
+		}
+	}
+}
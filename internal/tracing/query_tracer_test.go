@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/sebasr/avt-service/internal/config"
+)
+
+func TestQueryTracer_RecordsQuerySpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewQueryTracer(provider.Tracer("test"))
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL:  "  SELECT id FROM users WHERE email = $1  ",
+		Args: []any{"driver@example.com"},
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "DELETE FROM sessions"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	// Queries issued outside the repository package keep the generic name
+	assert.Equal(t, "db.query", spans[0].Name())
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	statement, _ := attrs.Value("db.statement")
+	assert.Equal(t, "SELECT id FROM users WHERE email = $1", statement.AsString())
+	rows, _ := attrs.Value("db.rows_affected")
+	assert.Equal(t, int64(1), rows.AsInt64())
+	for _, kv := range spans[0].Attributes() {
+		assert.NotContains(t, kv.Value.Emit(), "driver@example.com", "query arguments must not be recorded")
+	}
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "connection reset", spans[1].Status().Description)
+}
+
+func TestSetup_DisabledIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Enabled: false})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, span := Tracer().Start(context.Background(), "noop")
+	assert.False(t, span.IsRecording())
+	span.End()
+}
@@ -0,0 +1,82 @@
+// Package units converts the metric values stored by the service into a user's preferred unit system.
+package units
+
+import "fmt"
+
+// System is a unit system for API responses
+type System string
+
+// Supported unit systems; telemetry is always stored in metric units
+const (
+	Metric   System = "metric"
+	Imperial System = "imperial"
+)
+
+// Conversion factors from metric units
+const (
+	kmhToMph   = 0.621371192
+	metersToMi = 1 / 1609.344
+	metersToFt = 1 / 0.3048
+)
+
+// Labels names the units used by a response, so clients never have to guess
+type Labels struct {
+	System   System `json:"system"`
+	Speed    string `json:"speed"`
+	Distance string `json:"distance"`
+	Altitude string `json:"altitude"`
+}
+
+// Parse parses a unit system name; an empty name is metric
+func Parse(name string) (System, error) {
+	switch System(name) {
+	case "":
+		return Metric, nil
+	case Metric, Imperial:
+		return System(name), nil
+	default:
+		return "", fmt.Errorf("unknown unit system %q: must be metric or imperial", name)
+	}
+}
+
+// Labels returns the unit names for the system
+// Metric responses keep the stored units: speed in km/h, distance and altitude in meters.
+func (s System) Labels() Labels {
+	if s == Imperial {
+		return Labels{System: Imperial, Speed: "mph", Distance: "mi", Altitude: "ft"}
+	}
+	return Labels{System: Metric, Speed: "km/h", Distance: "m", Altitude: "m"}
+}
+
+// Speed converts a speed in km/h
+func (s System) Speed(kmh float64) float64 {
+	if s == Imperial {
+		return kmh * kmhToMph
+	}
+	return kmh
+}
+
+// Distance converts a distance in meters
+func (s System) Distance(meters float64) float64 {
+	if s == Imperial {
+		return meters * metersToMi
+	}
+	return meters
+}
+
+// Altitude converts an altitude or climb in meters
+func (s System) Altitude(meters float64) float64 {
+	if s == Imperial {
+		return meters * metersToFt
+	}
+	return meters
+}
+
+// SpeedPtr converts an optional speed in km/h
+func (s System) SpeedPtr(kmh *float64) *float64 {
+	if kmh == nil {
+		return nil
+	}
+	converted := s.Speed(*kmh)
+	return &converted
+}
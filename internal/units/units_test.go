@@ -0,0 +1,39 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	system, err := Parse("")
+	require.NoError(t, err)
+	assert.Equal(t, Metric, system)
+
+	system, err = Parse("imperial")
+	require.NoError(t, err)
+	assert.Equal(t, Imperial, system)
+
+	_, err = Parse("nautical")
+	assert.Error(t, err)
+}
+
+func TestSystem_Convert(t *testing.T) {
+	assert.Equal(t, 100.0, Metric.Speed(100))
+	assert.Equal(t, 1609.344, Metric.Distance(1609.344))
+
+	assert.InDelta(t, 62.137, Imperial.Speed(100), 0.001)
+	assert.InDelta(t, 1, Imperial.Distance(1609.344), 1e-9)
+	assert.InDelta(t, 1000, Imperial.Altitude(304.8), 1e-9)
+
+	assert.Nil(t, Imperial.SpeedPtr(nil))
+	speed := 160.9344
+	assert.InDelta(t, 100, *Imperial.SpeedPtr(&speed), 0.001)
+}
+
+func TestSystem_Labels(t *testing.T) {
+	assert.Equal(t, Labels{System: Metric, Speed: "km/h", Distance: "m", Altitude: "m"}, Metric.Labels())
+	assert.Equal(t, Labels{System: Imperial, Speed: "mph", Distance: "mi", Altitude: "ft"}, Imperial.Labels())
+}
@@ -0,0 +1,323 @@
+// Package upload ingests resumable telemetry uploads in the background.
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+)
+
+const (
+	// pollInterval is how often pending uploads are looked for when no worker was notified
+	pollInterval = 30 * time.Second
+
+	// staleAfter is how long a processing upload may go without progress before it is resumed
+	// Progress is saved after every chunk of records, so only uploads whose worker stopped go stale.
+	staleAfter = 5 * time.Minute
+
+	// AbandonAfter is how long an open upload may go without receiving a chunk before it is deleted
+	AbandonAfter = 24 * time.Hour
+
+	// cleanupInterval is how often abandoned uploads are deleted
+	cleanupInterval = time.Hour
+
+	// chunkSize is the number of valid records written per SaveBatch call
+	chunkSize = 1000
+
+	// MaxLineSize caps the length of a single NDJSON line
+	MaxLineSize = 1 << 20
+
+	// maxLineErrors caps the rejected lines reported on an upload; the count includes all of them
+	maxLineErrors = 100
+)
+
+// Decoder parses a single uploaded telemetry record
+type Decoder func(raw []byte) (*models.TelemetryData, error)
+
+// Processor ingests finalized uploads in the background
+// Uploads are claimed from the database, so any instance may ingest an upload received by another,
+// and each is read back chunk by chunk and stored in batches like a telemetry stream. Progress is
+// saved after every batch; an upload whose worker stopped, e.g. on restart, is resumed from its
+// last saved batch once it goes stale. Records stored after that batch are written again on
+// resumption, and skipped as duplicates when ingest deduplication is enabled.
+type Processor struct {
+	uploads   repository.UploadRepository
+	telemetry repository.TelemetryRepository
+	usage     repository.UsageRepository // Optional: nil does not count stored points against quotas
+	decode    Decoder
+	lenient   bool
+	wake      chan struct{}
+	now       func() time.Time
+}
+
+// NewProcessor creates a new background upload processor
+func NewProcessor(uploads repository.UploadRepository, telemetry repository.TelemetryRepository, decode Decoder) *Processor {
+	return &Processor{
+		uploads:   uploads,
+		telemetry: telemetry,
+		decode:    decode,
+		wake:      make(chan struct{}, 1),
+		now:       time.Now,
+	}
+}
+
+// WithLenientValidation accepts records whose only problems are out-of-range sensor readings
+func (p *Processor) WithLenientValidation(lenient bool) *Processor {
+	p.lenient = lenient
+	return p
+}
+
+// WithUsage counts stored records against the owner's monthly telemetry quota
+func (p *Processor) WithUsage(usage repository.UsageRepository) *Processor {
+	p.usage = usage
+	return p
+}
+
+// Notify wakes the processor to look for pending uploads without blocking
+func (p *Processor) Notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run ingests pending uploads and deletes abandoned ones until the context is cancelled
+func (p *Processor) Run(ctx context.Context) {
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	p.processPending(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+			p.processPending(ctx)
+		case <-poll.C:
+			p.processPending(ctx)
+		case <-cleanup.C:
+			p.deleteAbandoned(ctx)
+		}
+	}
+}
+
+// processPending ingests claimable uploads until none are left
+func (p *Processor) processPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		upload, err := p.uploads.ClaimNext(ctx, p.now().Add(-staleAfter))
+		if err != nil {
+			slog.Error("Error claiming upload", "error", err)
+			return
+		}
+		if upload == nil {
+			return
+		}
+		if err := p.Process(ctx, upload); err != nil {
+			slog.Error("Error ingesting upload", "upload_id", upload.ID, "error", err)
+		}
+	}
+}
+
+// Process ingests a claimed upload from its last saved progress and records the outcome
+// Cancellation leaves the upload processing, so it is resumed once it goes stale.
+func (p *Processor) Process(ctx context.Context, upload *models.Upload) error {
+	consumed := upload.ProcessedBytes
+	scanner := bufio.NewScanner(&chunkReader{ctx: ctx, uploads: p.uploads, id: upload.ID, offset: consumed})
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxLineSize)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		consumed += int64(advance)
+		return advance, token, err
+	})
+
+	batch := make([]*models.TelemetryData, 0, chunkSize)
+	for scanner.Scan() {
+		upload.Lines++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		record, err := p.parse(upload, line)
+		if err != nil {
+			reject(upload, err)
+			continue
+		}
+
+		batch = append(batch, record)
+		if len(batch) == chunkSize {
+			if err := p.flush(ctx, upload, batch, consumed); err != nil {
+				return err
+			}
+			batch = make([]*models.TelemetryData, 0, chunkSize)
+		}
+	}
+
+	// Records read before a broken line are still valid
+	if err := p.flush(ctx, upload, batch, consumed); err != nil {
+		return err
+	}
+
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		message := "Failed to read upload"
+		if errors.Is(err, bufio.ErrTooLong) {
+			message = fmt.Sprintf("Line %d exceeds %d bytes", upload.Lines+1, MaxLineSize)
+		}
+		p.fail(ctx, upload, message)
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	if upload.Accepted+upload.Skipped == 0 {
+		p.fail(ctx, upload, "No valid records in upload")
+		return nil
+	}
+
+	upload.Status = models.UploadCompleted
+	if err := p.uploads.UpdateProgress(ctx, upload); err != nil {
+		return fmt.Errorf("failed to complete upload: %w", err)
+	}
+
+	slog.Info("Upload ingested", "upload_id", upload.ID, "accepted", upload.Accepted, "skipped", upload.Skipped, "rejected", upload.Rejected)
+	return nil
+}
+
+// parse decodes and validates a single line and assigns it to the upload's owner and device
+func (p *Processor) parse(upload *models.Upload, line []byte) (*models.TelemetryData, error) {
+	record, err := p.decode(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if record.DeviceID == "" {
+		record.DeviceID = upload.DeviceID
+	} else if record.DeviceID != upload.DeviceID {
+		return nil, errors.New("deviceId does not match the upload's device")
+	}
+
+	validate := record.Validate
+	if p.lenient {
+		validate = record.ValidateBasic
+	}
+	if err := validate(); err != nil {
+		return nil, err
+	}
+
+	record.UserID = &upload.UserID
+	return record, nil
+}
+
+// reject records a rejected line
+func reject(upload *models.Upload, err error) {
+	upload.Rejected++
+	if len(upload.Errors) < maxLineErrors {
+		upload.Errors = append(upload.Errors, models.UploadLineError{Line: upload.Lines, Error: err.Error()})
+	}
+}
+
+// flush stores a batch of records and saves the upload's progress up to the consumed offset
+// An empty batch only advances the offset, which is saved with the upload's final status.
+func (p *Processor) flush(ctx context.Context, upload *models.Upload, batch []*models.TelemetryData, consumed int64) error {
+	if len(batch) == 0 {
+		upload.ProcessedBytes = consumed
+		return nil
+	}
+
+	if err := p.telemetry.SaveBatch(ctx, batch); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		p.fail(ctx, upload, "Failed to store telemetry")
+		return fmt.Errorf("failed to save telemetry: %w", err)
+	}
+
+	stored := 0
+	for _, record := range batch {
+		if !record.Duplicate {
+			stored++
+		}
+	}
+	upload.Accepted += stored
+	upload.Skipped += len(batch) - stored
+	p.recordUsage(ctx, upload.UserID, stored)
+
+	upload.ProcessedBytes = consumed
+	if err := p.uploads.UpdateProgress(ctx, upload); err != nil {
+		slog.Error("Error updating upload progress", "upload_id", upload.ID, "error", err)
+	}
+	return nil
+}
+
+// recordUsage adds stored points to the owner's usage for this period
+func (p *Processor) recordUsage(ctx context.Context, userID uuid.UUID, n int) {
+	if p.usage == nil || n == 0 {
+		return
+	}
+	start, _ := models.UsagePeriod(p.now())
+	if err := p.usage.AddTelemetryPoints(ctx, userID, start, int64(n)); err != nil {
+		slog.Error("Error recording usage", "user_id", userID, "error", err)
+	}
+}
+
+// fail marks the upload failed with a client-facing message
+// The update ignores cancellation so failures during shutdown are still recorded.
+func (p *Processor) fail(ctx context.Context, upload *models.Upload, message string) {
+	upload.Status = models.UploadFailed
+	upload.Error = &message
+	if err := p.uploads.UpdateProgress(context.WithoutCancel(ctx), upload); err != nil {
+		slog.Error("Error failing upload", "upload_id", upload.ID, "error", err)
+	}
+}
+
+// deleteAbandoned deletes open uploads that stopped receiving chunks
+func (p *Processor) deleteAbandoned(ctx context.Context) {
+	deleted, err := p.uploads.DeleteAbandoned(ctx, p.now().Add(-AbandonAfter))
+	if err != nil {
+		slog.Error("Error deleting abandoned uploads", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("Deleted abandoned uploads", "count", deleted)
+	}
+}
+
+// chunkReader reads an upload's stored chunks in order, starting at an offset
+type chunkReader struct {
+	ctx     context.Context
+	uploads repository.UploadRepository
+	id      uuid.UUID
+	offset  int64
+	buf     []byte
+}
+
+// Read implements io.Reader, fetching the next chunk once the current one is consumed
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		start, data, err := r.uploads.GetChunk(r.ctx, r.id, r.offset)
+		if errors.Is(err, repository.ErrUploadChunkNotFound) {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		r.buf = data[r.offset-start:]
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	r.offset += int64(n)
+	return n, nil
+}
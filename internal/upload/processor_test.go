@@ -0,0 +1,264 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebasr/avt-service/internal/models"
+	"github.com/sebasr/avt-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeJSON is a Decoder for plain version 1 records
+func decodeJSON(raw []byte) (*models.TelemetryData, error) {
+	var record models.TelemetryData
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// line renders a valid record as an NDJSON line
+func line(t *testing.T, i int, deviceID string) string {
+	t.Helper()
+	raw, err := json.Marshal(models.TelemetryData{
+		Timestamp: time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC).Add(time.Duration(i) * 40 * time.Millisecond),
+		DeviceID:  deviceID,
+		GPS:       models.GpsData{Latitude: 42.0, Longitude: 23.0, Speed: 100},
+	})
+	require.NoError(t, err)
+	return string(raw)
+}
+
+// storeChunks serves body from the mock repository, split into chunks of chunkBytes
+func storeChunks(repo *repository.MockUploadRepository, body string, chunkBytes int) {
+	repo.GetChunkFunc = func(_ context.Context, _ uuid.UUID, offset int64) (int64, []byte, error) {
+		if offset >= int64(len(body)) {
+			return 0, nil, repository.ErrUploadChunkNotFound
+		}
+		start := offset - offset%int64(chunkBytes)
+		end := min(start+int64(chunkBytes), int64(len(body)))
+		return start, []byte(body[start:end]), nil
+	}
+}
+
+func setupProcessor() (*Processor, *repository.MockUploadRepository, *repository.MockRepository, *[]models.Upload) {
+	uploadRepo := repository.NewMockUploadRepository()
+	telemetryRepo := repository.NewMockRepository()
+
+	updates := &[]models.Upload{}
+	uploadRepo.UpdateProgressFunc = func(_ context.Context, upload *models.Upload) error {
+		*updates = append(*updates, *upload)
+		return nil
+	}
+
+	return NewProcessor(uploadRepo, telemetryRepo, decodeJSON), uploadRepo, telemetryRepo, updates
+}
+
+func TestProcessor_Process(t *testing.T) {
+	processor, uploadRepo, telemetryRepo, updates := setupProcessor()
+
+	lines := make([]string, 0, 2503)
+	for i := range 2500 {
+		deviceID := ""
+		if i%2 == 0 {
+			deviceID = "RACEBOX-001"
+		}
+		lines = append(lines, line(t, i, deviceID))
+	}
+	lines = append(lines, "not json", "", line(t, 0, "RACEBOX-002"))
+	body := strings.Join(lines, "\n") + "\n"
+	storeChunks(uploadRepo, body, 4096)
+
+	userID := uuid.New()
+	var saved []*models.TelemetryData
+	telemetryRepo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+		saved = append(saved, data...)
+		data[0].Duplicate = true
+		return nil
+	}
+
+	upload := &models.Upload{ID: uuid.New(), UserID: userID, DeviceID: "RACEBOX-001", Size: int64(len(body)), Status: models.UploadProcessing}
+	require.NoError(t, processor.Process(context.Background(), upload))
+
+	require.Len(t, saved, 2500)
+	for _, record := range saved {
+		assert.Equal(t, "RACEBOX-001", record.DeviceID)
+		assert.Equal(t, userID, *record.UserID)
+	}
+
+	assert.Equal(t, models.UploadCompleted, upload.Status)
+	assert.Equal(t, 2503, upload.Lines)
+	assert.Equal(t, 2497, upload.Accepted)
+	assert.Equal(t, 3, upload.Skipped)
+	assert.Equal(t, 2, upload.Rejected)
+	assert.Equal(t, int64(len(body)), upload.ProcessedBytes)
+	require.Len(t, upload.Errors, 2)
+	assert.Equal(t, 2501, upload.Errors[0].Line)
+	assert.Equal(t, models.UploadLineError{Line: 2503, Error: "deviceId does not match the upload's device"}, upload.Errors[1])
+
+	// Progress is saved after every batch, at the end of its last line
+	require.Len(t, *updates, 4)
+	assert.Equal(t, int64(len(strings.Join(lines[:1000], "\n"))+1), (*updates)[0].ProcessedBytes)
+	assert.Equal(t, 1000, (*updates)[0].Lines)
+	assert.Equal(t, models.UploadProcessing, (*updates)[2].Status)
+	assert.Equal(t, models.UploadCompleted, (*updates)[3].Status)
+}
+
+func TestProcessor_Process_Resumes(t *testing.T) {
+	processor, uploadRepo, telemetryRepo, _ := setupProcessor()
+
+	lines := []string{line(t, 0, ""), line(t, 1, ""), line(t, 2, "")}
+	body := strings.Join(lines, "\n")
+	storeChunks(uploadRepo, body, 100)
+
+	var saved []*models.TelemetryData
+	telemetryRepo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+		saved = append(saved, data...)
+		return nil
+	}
+
+	// The first line was stored before the worker stopped
+	upload := &models.Upload{
+		ID:             uuid.New(),
+		DeviceID:       "RACEBOX-001",
+		Size:           int64(len(body)),
+		ProcessedBytes: int64(len(lines[0]) + 1),
+		Lines:          1,
+		Accepted:       1,
+		Status:         models.UploadProcessing,
+	}
+	require.NoError(t, processor.Process(context.Background(), upload))
+
+	require.Len(t, saved, 2)
+	assert.True(t, saved[0].Timestamp.Equal(time.Date(2024, 5, 14, 10, 0, 0, 40*int(time.Millisecond), time.UTC)))
+	assert.Equal(t, 3, upload.Lines)
+	assert.Equal(t, 3, upload.Accepted)
+	assert.Equal(t, models.UploadCompleted, upload.Status)
+}
+
+func TestProcessor_Process_SaveError(t *testing.T) {
+	processor, uploadRepo, telemetryRepo, updates := setupProcessor()
+
+	body := line(t, 0, "RACEBOX-001")
+	storeChunks(uploadRepo, body, 1024)
+	telemetryRepo.SaveBatchFunc = func(_ context.Context, _ []*models.TelemetryData) error {
+		return errors.New("database error")
+	}
+
+	upload := &models.Upload{ID: uuid.New(), DeviceID: "RACEBOX-001", Size: int64(len(body)), Status: models.UploadProcessing}
+	assert.Error(t, processor.Process(context.Background(), upload))
+
+	require.Len(t, *updates, 1)
+	assert.Equal(t, models.UploadFailed, (*updates)[0].Status)
+	assert.Equal(t, "Failed to store telemetry", *(*updates)[0].Error)
+}
+
+func TestProcessor_Process_Cancelled(t *testing.T) {
+	processor, uploadRepo, telemetryRepo, updates := setupProcessor()
+
+	body := line(t, 0, "RACEBOX-001")
+	storeChunks(uploadRepo, body, 1024)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	telemetryRepo.SaveBatchFunc = func(ctx context.Context, _ []*models.TelemetryData) error {
+		cancel()
+		return ctx.Err()
+	}
+
+	upload := &models.Upload{ID: uuid.New(), DeviceID: "RACEBOX-001", Size: int64(len(body)), Status: models.UploadProcessing}
+	assert.ErrorIs(t, processor.Process(ctx, upload), context.Canceled)
+
+	// The upload is left processing so it is resumed later
+	assert.Empty(t, *updates)
+	assert.Equal(t, models.UploadProcessing, upload.Status)
+}
+
+func TestProcessor_Process_NoValidRecords(t *testing.T) {
+	processor, uploadRepo, _, updates := setupProcessor()
+
+	body := "not json\n{}\n"
+	storeChunks(uploadRepo, body, 1024)
+
+	upload := &models.Upload{ID: uuid.New(), DeviceID: "RACEBOX-001", Size: int64(len(body)), Status: models.UploadProcessing}
+	require.NoError(t, processor.Process(context.Background(), upload))
+
+	require.Len(t, *updates, 1)
+	assert.Equal(t, models.UploadFailed, (*updates)[0].Status)
+	assert.Equal(t, 2, (*updates)[0].Rejected)
+}
+
+func TestProcessor_Process_LineTooLong(t *testing.T) {
+	processor, uploadRepo, telemetryRepo, updates := setupProcessor()
+
+	body := line(t, 0, "") + "\n" + strings.Repeat("x", MaxLineSize+1) + "\n"
+	storeChunks(uploadRepo, body, 64*1024)
+	saved := 0
+	telemetryRepo.SaveBatchFunc = func(_ context.Context, data []*models.TelemetryData) error {
+		saved += len(data)
+		return nil
+	}
+
+	upload := &models.Upload{ID: uuid.New(), DeviceID: "RACEBOX-001", Size: int64(len(body)), Status: models.UploadProcessing}
+	assert.Error(t, processor.Process(context.Background(), upload))
+
+	assert.Equal(t, 1, saved)
+	last := (*updates)[len(*updates)-1]
+	assert.Equal(t, models.UploadFailed, last.Status)
+	assert.Equal(t, fmt.Sprintf("Line 2 exceeds %d bytes", MaxLineSize), *last.Error)
+}
+
+func TestProcessor_ProcessPending(t *testing.T) {
+	processor, uploadRepo, _, updates := setupProcessor()
+
+	body := line(t, 0, "")
+	storeChunks(uploadRepo, body, 1024)
+
+	pending := []*models.Upload{
+		{ID: uuid.New(), DeviceID: "RACEBOX-001", Size: int64(len(body))},
+		{ID: uuid.New(), DeviceID: "RACEBOX-002", Size: int64(len(body))},
+	}
+	uploadRepo.ClaimNextFunc = func(_ context.Context, staleBefore time.Time) (*models.Upload, error) {
+		assert.WithinDuration(t, time.Now().Add(-staleAfter), staleBefore, time.Second)
+		if len(pending) == 0 {
+			return nil, nil
+		}
+		next := pending[0]
+		pending = pending[1:]
+		next.Status = models.UploadProcessing
+		return next, nil
+	}
+
+	processor.processPending(context.Background())
+
+	require.Len(t, *updates, 4)
+	assert.Equal(t, models.UploadCompleted, (*updates)[1].Status)
+	assert.Equal(t, "RACEBOX-002", (*updates)[3].DeviceID)
+	assert.Equal(t, models.UploadCompleted, (*updates)[3].Status)
+}
+
+func TestProcessor_RecordsUsage(t *testing.T) {
+	processor, uploadRepo, _, _ := setupProcessor()
+	usageRepo := repository.NewMockUsageRepository()
+	processor.WithUsage(usageRepo)
+
+	var added int64
+	usageRepo.AddTelemetryPointsFunc = func(_ context.Context, _ uuid.UUID, _ time.Time, n int64) error {
+		added += n
+		return nil
+	}
+
+	body := line(t, 0, "") + "\n" + line(t, 1, "")
+	storeChunks(uploadRepo, body, 1024)
+
+	upload := &models.Upload{ID: uuid.New(), UserID: uuid.New(), DeviceID: "RACEBOX-001", Size: int64(len(body))}
+	require.NoError(t, processor.Process(context.Background(), upload))
+	assert.Equal(t, int64(2), added)
+}
@@ -0,0 +1,111 @@
+// Package webhook delivers JSON event notifications to user-configured URLs.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// DefaultTimeout caps how long a single webhook delivery may take
+const DefaultTimeout = 5 * time.Second
+
+// ErrAddressNotAllowed is returned when a webhook URL resolves to an internal address
+var ErrAddressNotAllowed = errors.New("webhook address not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, which some clouds use for metadata services
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewClient creates an HTTP client suitable for webhook delivery
+// Webhook URLs are chosen by users, so the client refuses to connect to loopback, private,
+// link-local, shared and unspecified addresses, such as cloud metadata services. Addresses are
+// checked when dialing, after DNS resolution and on every redirect. allowed lists the networks of
+// internal receivers the client may connect to anyway. Proxies from the environment are not used,
+// as they would connect on the client's behalf.
+func NewClient(allowed ...netip.Prefix) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   DefaultTimeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return checkAddress(address, allowed)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: DefaultTimeout, Transport: transport}
+}
+
+// ParseNetworks parses IPs and CIDRs, such as the allowed networks of NewClient
+// A single IP is a network of that address alone.
+func ParseNetworks(entries []string) ([]netip.Prefix, error) {
+	networks := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			networks = append(networks, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q (must be an IP address or CIDR)", entry)
+		}
+		networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return networks, nil
+}
+
+// checkAddress rejects a dialed host:port whose IP is internal and outside the allowed networks
+func checkAddress(address string, allowed []netip.Prefix) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, address)
+	}
+
+	addr := addrPort.Addr().Unmap()
+	for _, network := range allowed {
+		if network.Contains(addr) {
+			return nil
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addr)
+	}
+	return nil
+}
+
+// Post sends payload as a JSON body to url
+// Any non-2xx response is reported as an error.
+func Post(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPost(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	require.NoError(t, Post(context.Background(), server.Client(), server.URL, map[string]string{"event": "test"}))
+	assert.Equal(t, "test", received["event"])
+}
+
+func TestPost_RejectsNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := Post(context.Background(), server.Client(), server.URL, struct{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
+
+func TestCheckAddress(t *testing.T) {
+	allowed := []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}
+
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", true},
+		{"10.1.2.3:8080", true}, // In an allowed network
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"10.0.0.1:80", false},
+		{"192.168.1.10:80", false},
+		{"172.16.0.1:80", false},
+		{"169.254.169.254:80", false}, // Cloud metadata
+		{"100.100.100.200:80", false},
+		{"[fe80::1%eth0]:80", false},
+		{"[fd00::1]:80", false},
+		{"0.0.0.0:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+	}
+	for _, tt := range tests {
+		err := checkAddress(tt.address, allowed)
+		if tt.allowed {
+			assert.NoError(t, err, tt.address)
+		} else {
+			assert.ErrorIs(t, err, ErrAddressNotAllowed, tt.address)
+		}
+	}
+}
+
+func TestNewClient_RejectsInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := Post(context.Background(), NewClient(), server.URL, struct{}{})
+	assert.ErrorIs(t, err, ErrAddressNotAllowed)
+
+	// Hosts are checked after they resolve, so a name pointing at loopback is refused too
+	localhost := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	err = Post(context.Background(), NewClient(), localhost, struct{}{})
+	assert.ErrorIs(t, err, ErrAddressNotAllowed)
+
+	// Internal receivers can be allowed explicitly
+	allowed, err := ParseNetworks([]string{"127.0.0.1"})
+	require.NoError(t, err)
+	assert.NoError(t, Post(context.Background(), NewClient(allowed...), server.URL, struct{}{}))
+}
+
+func TestNewClient_RejectsRedirectsToInternalAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data/", http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	// The receiver is allowed, but the address it redirects to is checked before it is dialed
+	client := NewClient(netip.MustParsePrefix("127.0.0.0/8"))
+	err := Post(context.Background(), client, receiver.URL, struct{}{})
+	assert.ErrorIs(t, err, ErrAddressNotAllowed)
+}
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := ParseNetworks([]string{"10.0.0.0/8", "192.0.2.10", "2001:db8::/32"})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.0.2.10/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}, networks)
+
+	_, err = ParseNetworks([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
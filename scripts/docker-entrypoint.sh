@@ -12,17 +12,9 @@ done
 
 echo "Database is ready!"
 
-# Run migrations
+# Run migrations embedded in the server binary
 echo "Running database migrations..."
-export DATABASE_URL="postgres://${DB_USER}:${DB_PASSWORD}@${DB_HOST}:${DB_PORT}/${DB_NAME}?sslmode=disable"
-export MIGRATIONS_DIR="internal/database/migrations"
-
-if [ -f "./run-migrations.sh" ]; then
-    ./run-migrations.sh
-else
-    echo "Warning: Migration script not found, running migrations directly..."
-    migrate -path "$MIGRATIONS_DIR" -database "$DATABASE_URL" up
-fi
+./server --migrate
 
 echo "Migrations completed successfully!"
 